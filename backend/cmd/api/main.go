@@ -2,21 +2,33 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"html/template"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/gabriel/cross-site-tracker/backend/internal/backup"
 	"github.com/gabriel/cross-site-tracker/backend/internal/config"
 	connectordefaults "github.com/gabriel/cross-site-tracker/backend/internal/connectors/defaults"
 	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/digest"
 	apihttp "github.com/gabriel/cross-site-tracker/backend/internal/http"
+	"github.com/gabriel/cross-site-tracker/backend/internal/http/handlers"
+	"github.com/gabriel/cross-site-tracker/backend/internal/mangadexsync"
 	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/retention"
 	"github.com/gabriel/cross-site-tracker/backend/internal/scheduler"
+	"github.com/gabriel/cross-site-tracker/backend/internal/selfcheck"
 )
 
 func main() {
+	skipSelfCheck := flag.Bool("skip-selfcheck", false, "Start without checking the template, asset, migration and sqlite paths")
+	flag.Parse()
+
 	cfg, err := config.Load()
 	if err != nil {
 		slog.Error("failed to load config", "error", err)
@@ -27,6 +39,23 @@ func main() {
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 
+	var templates *template.Template
+	if !*skipSelfCheck {
+		report := selfcheck.Run(selfcheck.Paths{
+			TemplatesGlob: handlers.TemplatesGlob,
+			MigrationsDir: cfg.MigrationsPath,
+			AssetsDir:     apihttp.AssetsDir,
+			SQLitePath:    cfg.SQLitePath,
+		}, func(glob string) (*template.Template, error) {
+			return handlers.ParseTemplates(glob, cfg.BasePath)
+		})
+		if !report.OK() {
+			fmt.Fprint(os.Stderr, report)
+			os.Exit(1)
+		}
+		templates = report.Templates
+	}
+
 	db, err := database.Open(cfg.SQLitePath)
 	if err != nil {
 		slog.Error("failed to open sqlite", "path", cfg.SQLitePath, "error", err)
@@ -46,9 +75,50 @@ func main() {
 		}
 	}
 
-	connectorRegistry := connectordefaults.NewRegistry()
+	if profiles, err := repository.NewProfileRepository(db).ListNonConformingKeys(context.Background()); err != nil {
+		slog.Warn("failed to check profile keys", "error", err)
+	} else {
+		for _, profile := range profiles {
+			slog.Warn("profile key is not canonical and is refused in URLs; rename it with cmd/rename-profile-keys", "profile_id", profile.ID, "key", profile.Key)
+		}
+	}
 
-	app := apihttp.NewServerWithRegistry(cfg, db, connectorRegistry)
+	if updated, err := repository.NewTrackerRepository(db).BackfillTagNamesNormalized(context.Background()); err != nil {
+		slog.Warn("failed to backfill normalized tag names", "error", err)
+	} else if updated > 0 {
+		slog.Info("backfilled normalized tag names", "count", updated)
+	}
+
+	connectorRegistry, err := connectordefaults.NewRegistryWithProxies(connectordefaults.ProxyOptions{Default: cfg.ConnectorProxy, Overrides: cfg.ConnectorProxies})
+	if err != nil {
+		slog.Error("failed to set up connector proxies", "error", err)
+		os.Exit(1)
+	}
+	connectorRegistry.SetStrictMediaHosts(cfg.StrictMediaHosts)
+	if err := database.SyncSourceSearchModes(db, connectorRegistry.SearchModes()); err != nil {
+		slog.Error("failed to sync source search modes", "error", err)
+		os.Exit(1)
+	}
+
+	var pacer *scheduler.Pacer
+	if cfg.PollingMaxDelayMS > 0 {
+		pacer = scheduler.NewPacer(scheduler.PacerConfig{
+			MinDelay:      time.Duration(cfg.PollingMinDelayMS) * time.Millisecond,
+			MaxDelay:      time.Duration(cfg.PollingMaxDelayMS) * time.Millisecond,
+			LoadThreshold: cfg.PollingLoadThreshold,
+		})
+	}
+
+	retentionConfig, err := retention.ConfigFrom(cfg)
+	if err != nil {
+		slog.Error("invalid retention settings", "error", err)
+		os.Exit(1)
+	}
+
+	var activity scheduler.ActivityState
+	if cfg.PollingAutoPause {
+		activity = repository.NewSettingsRepository(db)
+	}
 
 	pollerCtx, pollerCancel := context.WithCancel(context.Background())
 	poller := scheduler.NewPoller(
@@ -57,6 +127,14 @@ func main() {
 		scheduler.PollerConfig{
 			Interval:     time.Duration(cfg.PollingMinutes) * time.Minute,
 			IdleInterval: time.Duration(cfg.PollingIdleMinutes) * time.Minute,
+			Pause:        repository.NewSettingsRepository(db),
+			SourceNotes:  repository.NewSourceRepository(db),
+			Pacer:        pacer,
+			Pruner:       retention.NewPruner(db, retentionConfig, slog.Default()),
+
+			Activity:       activity,
+			InactiveAfter:  time.Duration(cfg.PollingAutoPauseDays) * 24 * time.Hour,
+			InactiveFactor: cfg.PollingAutoPauseFactor,
 		},
 		slog.Default(),
 	)
@@ -64,6 +142,51 @@ func main() {
 		poller.Start(pollerCtx)
 	}
 
+	app := apihttp.NewServerWithTemplates(cfg, db, connectorRegistry, poller, templates)
+
+	var digestJob *digest.Job
+	if cfg.SMTPConfigured() {
+		digestJob = digest.NewJob(
+			repository.NewDigestRepository(db),
+			repository.NewProfileRepository(db),
+			digest.NewSMTPSender(digest.SMTPConfigFrom(cfg)),
+			digest.JobConfig{CheckInterval: 5 * time.Minute},
+			slog.Default(),
+		)
+		digestJob.Start(pollerCtx)
+	}
+
+	var mangaDexJob *mangadexsync.Job
+	if cfg.MangaDexConfigured() {
+		job, err := mangadexsync.NewJobFromConfig(db, cfg, slog.Default())
+		if err != nil {
+			slog.Warn("mangadex sync disabled", "error", err)
+		} else {
+			mangaDexJob = job
+			mangaDexJob.Start(pollerCtx)
+		}
+	}
+
+	var canaryJob *scheduler.CanaryJob
+	if cfg.DeepHealthCheckEnabled {
+		canaryJob = scheduler.NewCanaryJob(
+			connectorRegistry,
+			scheduler.CanaryJobConfig{
+				Interval: time.Duration(cfg.DeepHealthCheckHours) * time.Hour,
+				Pause:    repository.NewSettingsRepository(db),
+				Notes:    repository.NewSourceRepository(db),
+			},
+			slog.Default(),
+		)
+		canaryJob.Start(pollerCtx)
+	}
+
+	var backupJob *backup.Job
+	if cfg.BackupEnabled {
+		backupJob = backup.NewJob(db, backup.JobConfigFrom(cfg), slog.Default())
+		backupJob.Start(pollerCtx)
+	}
+
 	go func() {
 		if err := app.Listen(":" + cfg.Port); err != nil {
 			slog.Error("server stopped", "error", err)
@@ -79,6 +202,18 @@ func main() {
 	slog.Info("shutting down server")
 	pollerCancel()
 	poller.StopWait(2 * time.Second)
+	if digestJob != nil {
+		digestJob.StopWait(2 * time.Second)
+	}
+	if mangaDexJob != nil {
+		mangaDexJob.StopWait(2 * time.Second)
+	}
+	if canaryJob != nil {
+		canaryJob.StopWait(2 * time.Second)
+	}
+	if backupJob != nil {
+		backupJob.StopWait(2 * time.Second)
+	}
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := app.ShutdownWithContext(shutdownCtx); err != nil {
@@ -16,6 +16,7 @@ import (
 	connectordefaults "github.com/gabriel/cross-site-tracker/backend/internal/connectors/defaults"
 	"github.com/gabriel/cross-site-tracker/backend/internal/database"
 	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
+	"github.com/gabriel/cross-site-tracker/backend/internal/selfcheck"
 )
 
 type trackerRecord struct {
@@ -25,6 +26,9 @@ type trackerRecord struct {
 	SourceURL     string
 	SourceKey     string
 	RelatedTitles []string
+	// RelatedTitlesTrimmed is set when the stored list broke the related
+	// title limits, so the row is rewritten even if nothing else changed.
+	RelatedTitlesTrimmed bool
 }
 
 type summary struct {
@@ -42,6 +46,7 @@ func main() {
 		limit          = flag.Int("limit", 0, "Limit number of trackers processed (0 = all)")
 		resolveTimeout = flag.Duration("resolve-timeout", 12*time.Second, "Per-tracker resolve timeout")
 		dryRun         = flag.Bool("dry-run", false, "Preview updates without writing to DB")
+		skipSelfCheck  = flag.Bool("skip-selfcheck", false, "Run without checking the migration and sqlite paths")
 	)
 	flag.Parse()
 
@@ -55,6 +60,13 @@ func main() {
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 
+	if !*skipSelfCheck {
+		if report := selfcheck.Run(selfcheck.Paths{MigrationsDir: cfg.MigrationsPath, SQLitePath: cfg.SQLitePath}, nil); !report.OK() {
+			fmt.Fprint(os.Stderr, report)
+			os.Exit(1)
+		}
+	}
+
 	db, err := database.Open(cfg.SQLitePath)
 	if err != nil {
 		slog.Error("failed to open sqlite", "path", cfg.SQLitePath, "error", err)
@@ -67,7 +79,11 @@ func main() {
 		os.Exit(1)
 	}
 
-	registry := connectordefaults.NewRegistry()
+	registry, err := connectordefaults.NewRegistryWithProxies(connectordefaults.ProxyOptions{Default: cfg.ConnectorProxy, Overrides: cfg.ConnectorProxies})
+	if err != nil {
+		slog.Error("failed to set up connector proxies", "error", err)
+		os.Exit(1)
+	}
 
 	items, err := listTrackersForBackfill(db, *profileID, *limit)
 	if err != nil {
@@ -106,11 +122,19 @@ func main() {
 			} else {
 				slog.Warn("resolve returned empty result; skipping tracker", "tracker_id", item.ID, "source_key", item.SourceKey)
 			}
+			if item.RelatedTitlesTrimmed && !*dryRun {
+				if err := updateTrackerRelatedTitles(db, item.ID, item.RelatedTitles); err != nil {
+					slog.Warn("failed to trim stored related titles", "tracker_id", item.ID, "error", err)
+				}
+			}
 			continue
 		}
 
-		newRelatedTitles := buildStoredRelatedTitles(item.Title, resolved.Title, resolved.RelatedTitles)
-		if relatedTitleListsEqual(item.RelatedTitles, newRelatedTitles) {
+		newRelatedTitles, dropped := buildStoredRelatedTitles(item.Title, resolved.Title, resolved.RelatedTitles)
+		if dropped > 0 {
+			slog.Info("related titles trimmed", "tracker_id", item.ID, "kept", len(newRelatedTitles), "dropped", dropped)
+		}
+		if relatedTitleListsEqual(item.RelatedTitles, newRelatedTitles) && !item.RelatedTitlesTrimmed {
 			stats.Unchanged++
 			continue
 		}
@@ -191,7 +215,10 @@ func listTrackersForBackfill(db *sql.DB, profileID int64, limit int) ([]trackerR
 		); err != nil {
 			return nil, fmt.Errorf("scan tracker row: %w", err)
 		}
-		item.RelatedTitles = decodeStoredRelatedTitles(relatedTitlesRaw)
+		item.RelatedTitles, item.RelatedTitlesTrimmed = decodeStoredRelatedTitles(relatedTitlesRaw)
+		if item.RelatedTitlesTrimmed {
+			slog.Info("stored related titles exceed limits", "tracker_id", item.ID, "kept", len(item.RelatedTitles))
+		}
 		trackers = append(trackers, item)
 	}
 
@@ -221,10 +248,13 @@ func updateTrackerRelatedTitles(db *sql.DB, trackerID int64, relatedTitles []str
 	return nil
 }
 
-func buildStoredRelatedTitles(trackerTitle string, resolvedTitle string, resolvedRelatedTitles []string) []string {
+// buildStoredRelatedTitles keeps the resolved related titles that are not one
+// of the main titles, within searchutil's related title limits, and returns
+// how many the limits dropped.
+func buildStoredRelatedTitles(trackerTitle string, resolvedTitle string, resolvedRelatedTitles []string) ([]string, int) {
 	filtered := searchutil.FilterEnglishAlphabetNames(resolvedRelatedTitles)
 	if len(filtered) == 0 {
-		return nil
+		return nil, 0
 	}
 
 	normalizedMainTitles := map[string]struct{}{}
@@ -247,29 +277,28 @@ func buildStoredRelatedTitles(trackerTitle string, resolvedTitle string, resolve
 		relatedOnly = append(relatedOnly, candidate)
 	}
 
-	if len(relatedOnly) == 0 {
-		return nil
-	}
-
-	return relatedOnly
+	return searchutil.LimitRelatedTitles(relatedOnly)
 }
 
-func decodeStoredRelatedTitles(raw string) []string {
+// decodeStoredRelatedTitles also reports whether the stored list broke the
+// related title limits and was trimmed.
+func decodeStoredRelatedTitles(raw string) ([]string, bool) {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
-		return nil
+		return nil, false
 	}
 
 	var values []string
 	if err := json.Unmarshal([]byte(trimmed), &values); err != nil {
-		return nil
+		return nil, false
 	}
 
-	return searchutil.FilterEnglishAlphabetNames(values)
+	titles, dropped := searchutil.LimitRelatedTitles(values)
+	return titles, dropped > 0
 }
 
 func encodeStoredRelatedTitles(values []string) string {
-	sanitized := searchutil.FilterEnglishAlphabetNames(values)
+	sanitized, _ := searchutil.LimitRelatedTitles(values)
 	if len(sanitized) == 0 {
 		return ""
 	}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
 )
 
 func TestBuildStoredRelatedTitlesExcludesMainTitles(t *testing.T) {
-	got := buildStoredRelatedTitles(
+	got, _ := buildStoredRelatedTitles(
 		"The Devil Butler",
 		"The Devil Butler",
 		[]string{"The Devil Butler", "Demonic Emperor", "Mo Huang Da Guan Jia"},
@@ -19,7 +24,7 @@ func TestBuildStoredRelatedTitlesExcludesMainTitles(t *testing.T) {
 }
 
 func TestBuildStoredRelatedTitlesExcludesTrackerAndResolvedMainTitles(t *testing.T) {
-	got := buildStoredRelatedTitles(
+	got, _ := buildStoredRelatedTitles(
 		"Solo Leveling",
 		"Solo Leveling: Ragnarok",
 		[]string{"Solo Leveling", "Solo Leveling: Ragnarok", "Leveling Up Alone"},
@@ -32,7 +37,7 @@ func TestBuildStoredRelatedTitlesExcludesTrackerAndResolvedMainTitles(t *testing
 }
 
 func TestBuildStoredRelatedTitlesFiltersNonEnglishAndEmptyResult(t *testing.T) {
-	got := buildStoredRelatedTitles(
+	got, _ := buildStoredRelatedTitles(
 		"Nano Machine",
 		"Nano Machine",
 		[]string{"Nano Machine", "나노마신"},
@@ -42,3 +47,45 @@ func TestBuildStoredRelatedTitlesFiltersNonEnglishAndEmptyResult(t *testing.T) {
 		t.Fatalf("expected nil related titles, got %v", got)
 	}
 }
+
+func TestBuildStoredRelatedTitlesAppliesLimits(t *testing.T) {
+	resolved := []string{"Tower of God", strings.Repeat("x", searchutil.MaxRelatedTitleLength+1)}
+	for index := 1; index <= 30; index++ {
+		resolved = append(resolved, fmt.Sprintf("Alt Tower %d", index))
+	}
+
+	got, dropped := buildStoredRelatedTitles("Tower of God", "Tower of God", resolved)
+	if len(got) != searchutil.MaxRelatedTitles || got[0] != "Alt Tower 1" || got[len(got)-1] != fmt.Sprintf("Alt Tower %d", searchutil.MaxRelatedTitles) {
+		t.Fatalf("expected the first %d alt titles, got %v", searchutil.MaxRelatedTitles, got)
+	}
+	if want := 1 + 30 - searchutil.MaxRelatedTitles; dropped != want {
+		t.Fatalf("expected %d dropped titles, got %d", want, dropped)
+	}
+}
+
+func TestDecodeStoredRelatedTitlesFlagsOversizedRows(t *testing.T) {
+	titles := make([]string, 0, 20)
+	for index := 1; index <= 20; index++ {
+		titles = append(titles, fmt.Sprintf("Alt Tower %d", index))
+	}
+	raw := encodeRawTitles(t, titles)
+
+	got, trimmed := decodeStoredRelatedTitles(raw)
+	if !trimmed || len(got) != searchutil.MaxRelatedTitles {
+		t.Fatalf("expected oversized row to be trimmed to %d, got %d (trimmed=%v)", searchutil.MaxRelatedTitles, len(got), trimmed)
+	}
+
+	got, trimmed = decodeStoredRelatedTitles(encodeRawTitles(t, titles[:3]))
+	if trimmed || len(got) != 3 {
+		t.Fatalf("expected small row to be kept as is, got %v (trimmed=%v)", got, trimmed)
+	}
+}
+
+func encodeRawTitles(t *testing.T, titles []string) string {
+	t.Helper()
+	encoded, err := json.Marshal(titles)
+	if err != nil {
+		t.Fatalf("encode titles: %v", err)
+	}
+	return string(encoded)
+}
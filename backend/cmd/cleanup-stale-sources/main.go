@@ -2,27 +2,30 @@ package main
 
 import (
 	"database/sql"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/config"
 	connectordefaults "github.com/gabriel/cross-site-tracker/backend/internal/connectors/defaults"
 	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/selfcheck"
 )
 
 type sourceUsage struct {
-	ID              int64
-	Key             string
-	Name            string
-	ConnectorKind   string
-	Enabled         bool
-	PrimaryTrackers int64
-	LinkedSources   int64
-	ProfileLogos    int64
+	ID              int64  `json:"id"`
+	Key             string `json:"key"`
+	Name            string `json:"name"`
+	ConnectorKind   string `json:"connectorKind"`
+	Enabled         bool   `json:"enabled"`
+	PrimaryTrackers int64  `json:"primaryTrackers"`
+	LinkedSources   int64  `json:"linkedSources"`
+	ProfileLogos    int64  `json:"profileLogos"`
 }
 
 type linkedSourceCandidate struct {
@@ -33,19 +36,44 @@ type linkedSourceCandidate struct {
 }
 
 type trackerPromotion struct {
-	TrackerID       int64
-	OldSourceID     int64
-	OldSourceKey    string
-	NewSourceID     int64
-	NewSourceKey    string
-	NewSourceURL    string
-	NewSourceItemID *string
+	TrackerID       int64   `json:"trackerId"`
+	OldSourceID     int64   `json:"oldSourceId"`
+	OldSourceKey    string  `json:"oldSourceKey"`
+	OldSourceURL    string  `json:"oldSourceUrl"`
+	OldSourceItemID *string `json:"oldSourceItemId"`
+	NewSourceID     int64   `json:"newSourceId"`
+	NewSourceKey    string  `json:"newSourceKey"`
+	NewSourceURL    string  `json:"newSourceUrl"`
+	NewSourceItemID *string `json:"newSourceItemId"`
 }
 
 type stalePrimaryTracker struct {
+	TrackerID int64  `json:"trackerId"`
+	SourceID  int64  `json:"sourceId"`
+	SourceKey string `json:"sourceKey"`
+}
+
+type cleanupOptions struct {
+	Apply bool
+	// ReportPath, when set, is where the plan is written as JSON before
+	// anything is applied.
+	ReportPath string
+	// TrackerID limits the run to a single tracker; 0 cleans every tracker.
+	// A limited run promotes or deletes only that tracker and removes only
+	// its stale links, leaving the stale sources for a full run.
 	TrackerID int64
-	SourceID  int64
-	SourceKey string
+}
+
+// cleanupReport is the plan written by -report.
+type cleanupReport struct {
+	GeneratedAt        time.Time             `json:"generatedAt"`
+	Apply              bool                  `json:"apply"`
+	TrackerID          int64                 `json:"trackerId,omitempty"`
+	StaleSources       []sourceUsage         `json:"staleSources"`
+	Promotions         []trackerPromotion    `json:"promotions"`
+	TrackersToDelete   []stalePrimaryTracker `json:"trackersToDelete"`
+	LinkedRowsToDelete int64                 `json:"linkedRowsToDelete"`
+	SourcesToDelete    []int64               `json:"sourcesToDelete"`
 }
 
 type cleanupOutcome struct {
@@ -57,8 +85,11 @@ type cleanupOutcome struct {
 }
 
 func main() {
-	var apply bool
-	flag.BoolVar(&apply, "apply", false, "Apply cleanup changes. Without this flag, the command is a dry-run preview.")
+	var options cleanupOptions
+	flag.BoolVar(&options.Apply, "apply", false, "Apply cleanup changes. Without this flag, the command is a dry-run preview.")
+	flag.StringVar(&options.ReportPath, "report", "", "Write the promotion/deletion plan as JSON to this path before applying")
+	flag.Int64Var(&options.TrackerID, "tracker-id", 0, "Only clean up a single tracker id (0 = all)")
+	skipSelfCheck := flag.Bool("skip-selfcheck", false, "Run without checking the migration and sqlite paths")
 	flag.Parse()
 
 	cfg, err := config.Load()
@@ -71,6 +102,13 @@ func main() {
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
 
+	if !*skipSelfCheck {
+		if report := selfcheck.Run(selfcheck.Paths{MigrationsDir: cfg.MigrationsPath, SQLitePath: cfg.SQLitePath}, nil); !report.OK() {
+			fmt.Fprint(os.Stderr, report)
+			os.Exit(1)
+		}
+	}
+
 	db, err := database.Open(cfg.SQLitePath)
 	if err != nil {
 		slog.Error("failed to open sqlite", "path", cfg.SQLitePath, "error", err)
@@ -83,18 +121,20 @@ func main() {
 		os.Exit(1)
 	}
 
-	activeSourceKeys := buildActiveSourceKeySet()
+	if _, err := runCleanup(db, buildActiveSourceKeySet(), options, time.Now().UTC()); err != nil {
+		slog.Error("stale source cleanup failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// runCleanup plans the cleanup, writes the report when asked to, and applies
+// the plan with -apply. runAt is recorded on every source migration.
+func runCleanup(db *sql.DB, activeSourceKeys map[string]struct{}, options cleanupOptions, runAt time.Time) (cleanupOutcome, error) {
 	slog.Info("loaded active source keys from registry", "count", len(activeSourceKeys), "keys", sortedMapKeys(activeSourceKeys))
 
 	staleSources, staleSourceKeyByID, err := listStaleSources(db, activeSourceKeys)
 	if err != nil {
-		slog.Error("failed to list stale sources", "error", err)
-		os.Exit(1)
-	}
-
-	if len(staleSources) == 0 {
-		slog.Info("no stale sources found; nothing to clean")
-		return
+		return cleanupOutcome{}, fmt.Errorf("list stale sources: %w", err)
 	}
 
 	for _, source := range staleSources {
@@ -116,10 +156,9 @@ func main() {
 		staleSourceIDs[source.ID] = struct{}{}
 	}
 
-	promotions, orphanedTrackers, err := planTrackerPrimarySourcePromotions(db, staleSourceIDs, staleSourceKeyByID)
+	promotions, orphanedTrackers, err := planTrackerPrimarySourcePromotions(db, staleSourceIDs, staleSourceKeyByID, options.TrackerID)
 	if err != nil {
-		slog.Error("failed to plan tracker promotions", "error", err)
-		os.Exit(1)
+		return cleanupOutcome{}, fmt.Errorf("plan tracker promotions: %w", err)
 	}
 
 	for _, promotion := range promotions {
@@ -142,32 +181,79 @@ func main() {
 		)
 	}
 
-	if !apply {
+	linkedRowsToDelete, err := countLinkedRowsToDelete(db, staleSources, options.TrackerID)
+	if err != nil {
+		return cleanupOutcome{}, err
+	}
+
+	sourcesToDelete := []int64{}
+	if options.TrackerID <= 0 {
+		sourcesToDelete = sortedInt64MapKeys(staleSourceIDs)
+	}
+
+	if options.ReportPath != "" {
+		report := cleanupReport{
+			GeneratedAt:        runAt,
+			Apply:              options.Apply,
+			TrackerID:          options.TrackerID,
+			StaleSources:       staleSources,
+			Promotions:         promotions,
+			TrackersToDelete:   orphanedTrackers,
+			LinkedRowsToDelete: linkedRowsToDelete,
+			SourcesToDelete:    sourcesToDelete,
+		}
+		if err := writeReport(options.ReportPath, report); err != nil {
+			return cleanupOutcome{}, err
+		}
+		slog.Info("cleanup plan written", "path", options.ReportPath)
+	}
+
+	if len(staleSources) == 0 {
+		slog.Info("no stale sources found; nothing to clean")
+		return cleanupOutcome{}, nil
+	}
+
+	if !options.Apply {
 		slog.Info(
 			"dry-run complete",
 			"stale_sources", len(staleSources),
+			"tracker_id", options.TrackerID,
 			"trackers_to_promote", len(promotions),
 			"trackers_to_delete", len(orphanedTrackers),
-			"linked_rows_to_delete", sumLinkedRowCounts(staleSources),
+			"linked_rows_to_delete", linkedRowsToDelete,
+			"sources_to_delete", len(sourcesToDelete),
 		)
-		return
+		return cleanupOutcome{}, nil
 	}
 
-	outcome, err := applyCleanup(db, staleSources, promotions)
+	outcome, err := applyCleanup(db, sourcesToDelete, sortedInt64MapKeys(staleSourceIDs), promotions, options.TrackerID, runAt)
 	if err != nil {
-		slog.Error("failed to apply stale source cleanup", "error", err)
-		os.Exit(1)
+		return cleanupOutcome{}, fmt.Errorf("apply stale source cleanup: %w", err)
 	}
 
 	slog.Info(
 		"cleanup completed",
 		"stale_sources", len(staleSources),
+		"tracker_id", options.TrackerID,
 		"promoted_trackers", outcome.PromotedTrackers,
 		"deleted_trackers", outcome.DeletedTrackers,
 		"deleted_tracker_sources", outcome.DeletedLinks,
 		"deleted_profile_source_logos", outcome.DeletedSourceLogos,
 		"deleted_sources", outcome.DeletedSources,
 	)
+
+	return outcome, nil
+}
+
+func writeReport(path string, report cleanupReport) error {
+	payload, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cleanup report: %w", err)
+	}
+	if err := os.WriteFile(path, append(payload, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write cleanup report: %w", err)
+	}
+	return nil
 }
 
 func buildActiveSourceKeySet() map[string]struct{} {
@@ -240,20 +326,28 @@ func listStaleSources(db *sql.DB, activeSourceKeys map[string]struct{}) ([]sourc
 	return stale, byID, nil
 }
 
-func planTrackerPrimarySourcePromotions(db *sql.DB, staleSourceIDs map[int64]struct{}, staleSourceKeyByID map[int64]string) ([]trackerPromotion, []stalePrimaryTracker, error) {
+func planTrackerPrimarySourcePromotions(db *sql.DB, staleSourceIDs map[int64]struct{}, staleSourceKeyByID map[int64]string, trackerID int64) ([]trackerPromotion, []stalePrimaryTracker, error) {
 	ids := sortedInt64MapKeys(staleSourceIDs)
 	if len(ids) == 0 {
 		return []trackerPromotion{}, []stalePrimaryTracker{}, nil
 	}
 
+	args := int64SliceToAny(ids)
+	trackerFilter := ""
+	if trackerID > 0 {
+		trackerFilter = "AND id = ?"
+		args = append(args, trackerID)
+	}
+
 	query := fmt.Sprintf(`
-		SELECT id, source_id
+		SELECT id, source_id, source_url, source_item_id
 		FROM trackers
 		WHERE source_id IN (%s)
+		  %s
 		ORDER BY id ASC
-	`, placeholders(len(ids)))
+	`, placeholders(len(ids)), trackerFilter)
 
-	rows, err := db.Query(query, int64SliceToAny(ids)...)
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("query trackers with stale primary source: %w", err)
 	}
@@ -263,9 +357,13 @@ func planTrackerPrimarySourcePromotions(db *sql.DB, staleSourceIDs map[int64]str
 	orphaned := make([]stalePrimaryTracker, 0)
 
 	for rows.Next() {
-		var trackerID int64
-		var staleSourceID int64
-		if err := rows.Scan(&trackerID, &staleSourceID); err != nil {
+		var (
+			trackerID       int64
+			staleSourceID   int64
+			staleSourceURL  string
+			staleSourceItem sql.NullString
+		)
+		if err := rows.Scan(&trackerID, &staleSourceID, &staleSourceURL, &staleSourceItem); err != nil {
 			return nil, nil, fmt.Errorf("scan tracker stale primary row: %w", err)
 		}
 
@@ -287,6 +385,8 @@ func planTrackerPrimarySourcePromotions(db *sql.DB, staleSourceIDs map[int64]str
 			TrackerID:       trackerID,
 			OldSourceID:     staleSourceID,
 			OldSourceKey:    staleSourceKeyByID[staleSourceID],
+			OldSourceURL:    staleSourceURL,
+			OldSourceItemID: nullableTrimmed(staleSourceItem),
 			NewSourceID:     candidate.SourceID,
 			NewSourceKey:    candidate.SourceKey,
 			NewSourceURL:    candidate.SourceURL,
@@ -301,6 +401,8 @@ func planTrackerPrimarySourcePromotions(db *sql.DB, staleSourceIDs map[int64]str
 	return promotions, orphaned, nil
 }
 
+// firstActiveLinkedSource picks the tracker's first linked source, in the
+// order the user arranged them, that is not on a stale source.
 func firstActiveLinkedSource(db *sql.DB, trackerID int64, staleSourceIDs map[int64]struct{}) (*linkedSourceCandidate, error) {
 	rows, err := db.Query(`
 		SELECT
@@ -311,7 +413,7 @@ func firstActiveLinkedSource(db *sql.DB, trackerID int64, staleSourceIDs map[int
 		FROM tracker_sources ts
 		INNER JOIN sources s ON s.id = ts.source_id
 		WHERE ts.tracker_id = ?
-		ORDER BY ts.id ASC
+		ORDER BY ts.position ASC, ts.id ASC
 	`, trackerID)
 	if err != nil {
 		return nil, fmt.Errorf("query tracker linked sources: %w", err)
@@ -338,19 +440,11 @@ func firstActiveLinkedSource(db *sql.DB, trackerID int64, staleSourceIDs map[int
 			continue
 		}
 
-		var itemID *string
-		if sourceItemID.Valid {
-			trimmedItemID := strings.TrimSpace(sourceItemID.String)
-			if trimmedItemID != "" {
-				itemID = &trimmedItemID
-			}
-		}
-
 		return &linkedSourceCandidate{
 			SourceID:     sourceID,
 			SourceKey:    sourceKey,
 			SourceURL:    trimmedURL,
-			SourceItemID: itemID,
+			SourceItemID: nullableTrimmed(sourceItemID),
 		}, nil
 	}
 
@@ -361,18 +455,49 @@ func firstActiveLinkedSource(db *sql.DB, trackerID int64, staleSourceIDs map[int
 	return nil, nil
 }
 
-func applyCleanup(db *sql.DB, staleSources []sourceUsage, promotions []trackerPromotion) (cleanupOutcome, error) {
+func nullableTrimmed(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	trimmed := strings.TrimSpace(value.String)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// countLinkedRowsToDelete counts the tracker_sources rows on stale sources,
+// only the given tracker's when trackerID is set.
+func countLinkedRowsToDelete(db *sql.DB, staleSources []sourceUsage, trackerID int64) (int64, error) {
+	if trackerID <= 0 {
+		return sumLinkedRowCounts(staleSources), nil
+	}
 	if len(staleSources) == 0 {
-		return cleanupOutcome{}, nil
+		return 0, nil
 	}
 
-	sourceIDs := make([]int64, 0, len(staleSources))
+	args := make([]any, 0, len(staleSources)+1)
+	args = append(args, trackerID)
 	for _, source := range staleSources {
-		sourceIDs = append(sourceIDs, source.ID)
+		args = append(args, source.ID)
+	}
+
+	var count int64
+	query := fmt.Sprintf(`SELECT COUNT(1) FROM tracker_sources WHERE tracker_id = ? AND source_id IN (%s)`, placeholders(len(staleSources)))
+	if err := db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count tracker linked stale sources: %w", err)
+	}
+	return count, nil
+}
+
+// applyCleanup promotes the planned trackers, recording each replaced primary
+// source in source_migrations and its URL in tracker_url_history, then
+// deletes what is left on the stale sources. With trackerID set only that
+// tracker's rows are deleted and sourcesToDelete is expected to be empty.
+func applyCleanup(db *sql.DB, sourcesToDelete []int64, staleSourceIDs []int64, promotions []trackerPromotion, trackerID int64, runAt time.Time) (cleanupOutcome, error) {
+	if len(staleSourceIDs) == 0 {
+		return cleanupOutcome{}, nil
 	}
-	sort.Slice(sourceIDs, func(i, j int) bool {
-		return sourceIDs[i] < sourceIDs[j]
-	})
 
 	tx, err := db.Begin()
 	if err != nil {
@@ -394,13 +519,14 @@ func applyCleanup(db *sql.DB, staleSources []sourceUsage, promotions []trackerPr
 			}
 		}
 
+		// updated_at is left alone: the tracker's reading state did not
+		// change, only where it is polled from.
 		result, err := tx.Exec(`
 			UPDATE trackers
 			SET
 				source_id = ?,
 				source_item_id = ?,
-				source_url = ?,
-				updated_at = CURRENT_TIMESTAMP
+				source_url = ?
 			WHERE id = ?
 			  AND source_id = ?
 		`, promotion.NewSourceID, sourceItemID, strings.TrimSpace(promotion.NewSourceURL), promotion.TrackerID, promotion.OldSourceID)
@@ -414,28 +540,64 @@ func applyCleanup(db *sql.DB, staleSources []sourceUsage, promotions []trackerPr
 			rollback()
 			return cleanupOutcome{}, fmt.Errorf("promotion rows affected tracker %d: %w", promotion.TrackerID, err)
 		}
+		if rowsAffected == 0 {
+			continue
+		}
 		outcome.PromotedTrackers += rowsAffected
-	}
 
-	outcome.DeletedLinks, err = deleteBySourceID(tx, "tracker_sources", "source_id", sourceIDs)
-	if err != nil {
-		rollback()
-		return cleanupOutcome{}, err
+		var oldSourceItemID any
+		if promotion.OldSourceItemID != nil {
+			oldSourceItemID = *promotion.OldSourceItemID
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO source_migrations (tracker_id, old_source_key, old_source_url, old_source_item_id, new_source_id, migrated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, promotion.TrackerID, promotion.OldSourceKey, promotion.OldSourceURL, oldSourceItemID, promotion.NewSourceID, runAt); err != nil {
+			rollback()
+			return cleanupOutcome{}, fmt.Errorf("record source migration tracker %d: %w", promotion.TrackerID, err)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO tracker_url_history (tracker_id, source_id, old_url, replaced_at, reason)
+			VALUES (?, ?, ?, ?, 'promotion')
+		`, promotion.TrackerID, promotion.OldSourceID, strings.TrimSpace(promotion.OldSourceURL), runAt); err != nil {
+			rollback()
+			return cleanupOutcome{}, fmt.Errorf("record url history tracker %d: %w", promotion.TrackerID, err)
+		}
 	}
 
-	outcome.DeletedTrackers, err = deleteBySourceID(tx, "trackers", "source_id", sourceIDs)
-	if err != nil {
-		rollback()
-		return cleanupOutcome{}, err
+	if trackerID > 0 {
+		outcome.DeletedLinks, err = deleteTrackerRowsBySourceID(tx, "tracker_sources", "tracker_id", trackerID, staleSourceIDs)
+		if err != nil {
+			rollback()
+			return cleanupOutcome{}, err
+		}
+
+		outcome.DeletedTrackers, err = deleteTrackerRowsBySourceID(tx, "trackers", "id", trackerID, staleSourceIDs)
+		if err != nil {
+			rollback()
+			return cleanupOutcome{}, err
+		}
+	} else {
+		outcome.DeletedLinks, err = deleteBySourceID(tx, "tracker_sources", "source_id", staleSourceIDs)
+		if err != nil {
+			rollback()
+			return cleanupOutcome{}, err
+		}
+
+		outcome.DeletedTrackers, err = deleteBySourceID(tx, "trackers", "source_id", staleSourceIDs)
+		if err != nil {
+			rollback()
+			return cleanupOutcome{}, err
+		}
 	}
 
-	outcome.DeletedSourceLogos, err = deleteBySourceID(tx, "profile_source_logos", "source_id", sourceIDs)
+	outcome.DeletedSourceLogos, err = deleteBySourceID(tx, "profile_source_logos", "source_id", sourcesToDelete)
 	if err != nil {
 		rollback()
 		return cleanupOutcome{}, err
 	}
 
-	outcome.DeletedSources, err = deleteBySourceID(tx, "sources", "id", sourceIDs)
+	outcome.DeletedSources, err = deleteBySourceID(tx, "sources", "id", sourcesToDelete)
 	if err != nil {
 		rollback()
 		return cleanupOutcome{}, err
@@ -448,6 +610,26 @@ func applyCleanup(db *sql.DB, staleSources []sourceUsage, promotions []trackerPr
 	return outcome, nil
 }
 
+func deleteTrackerRowsBySourceID(tx *sql.Tx, table string, trackerColumn string, trackerID int64, sourceIDs []int64) (int64, error) {
+	if len(sourceIDs) == 0 {
+		return 0, nil
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = ? AND source_id IN (%s)", table, trackerColumn, placeholders(len(sourceIDs)))
+	args := append([]any{trackerID}, int64SliceToAny(sourceIDs)...)
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete tracker %d from %s: %w", trackerID, table, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected for %s delete: %w", table, err)
+	}
+
+	return rowsAffected, nil
+}
+
 func deleteBySourceID(tx *sql.Tx, table string, column string, sourceIDs []int64) (int64, error) {
 	if len(sourceIDs) == 0 {
 		return 0, nil
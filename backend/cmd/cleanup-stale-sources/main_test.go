@@ -0,0 +1,251 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+)
+
+type seededCleanup struct {
+	db            *sql.DB
+	staleSourceID int64
+	mangadexID    int64
+	promotedID    int64
+	orphanedID    int64
+}
+
+// setupCleanupTestDB seeds a "retiredsite" source that is not in the registry,
+// with one tracker that also links MangaDex and one that links nothing else.
+func setupCleanupTestDB(t *testing.T) seededCleanup {
+	t.Helper()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "cleanup.sqlite"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := database.ApplyMigrations(db, filepath.Join("..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+
+	seeded := seededCleanup{db: db}
+	result, err := db.Exec(`INSERT INTO sources (key, name, connector_kind, enabled) VALUES ('retiredsite', 'Retired Site', 'native', 1)`)
+	if err != nil {
+		t.Fatalf("seed stale source: %v", err)
+	}
+	seeded.staleSourceID, _ = result.LastInsertId()
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&seeded.mangadexID); err != nil {
+		t.Fatalf("find mangadex source: %v", err)
+	}
+
+	seeded.promotedID = insertCleanupTracker(t, db, "Promoted Blade", seeded.staleSourceID, "https://retired.example/series/promoted", "retired-42")
+	seeded.orphanedID = insertCleanupTracker(t, db, "Orphaned Blade", seeded.staleSourceID, "https://retired.example/series/orphaned", "")
+	if _, err := db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_item_id, source_url)
+		VALUES (?, ?, 'retired-42', 'https://retired.example/series/promoted'),
+		       (?, ?, 'md-7', 'https://mangadex.org/title/md-7')
+	`, seeded.promotedID, seeded.staleSourceID, seeded.promotedID, seeded.mangadexID); err != nil {
+		t.Fatalf("seed linked sources: %v", err)
+	}
+
+	return seeded
+}
+
+func insertCleanupTracker(t *testing.T, db *sql.DB, title string, sourceID int64, sourceURL string, itemID string) int64 {
+	t.Helper()
+
+	var sourceItemID any
+	if itemID != "" {
+		sourceItemID = itemID
+	}
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_item_id, source_url, status, updated_at)
+		VALUES (1, ?, ?, ?, ?, 'reading', '2020-01-02 03:04:05')
+	`, title, sourceID, sourceItemID, sourceURL)
+	if err != nil {
+		t.Fatalf("seed tracker %q: %v", title, err)
+	}
+	id, _ := result.LastInsertId()
+	return id
+}
+
+func countRows(t *testing.T, db *sql.DB, query string, args ...any) int64 {
+	t.Helper()
+
+	var count int64
+	if err := db.QueryRow(query, args...).Scan(&count); err != nil {
+		t.Fatalf("count rows: %v", err)
+	}
+	return count
+}
+
+func TestRunCleanupRecordsSourceMigrations(t *testing.T) {
+	seeded := setupCleanupTestDB(t)
+	runAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	outcome, err := runCleanup(seeded.db, buildActiveSourceKeySet(), cleanupOptions{Apply: true}, runAt)
+	if err != nil {
+		t.Fatalf("run cleanup: %v", err)
+	}
+	if outcome.PromotedTrackers != 1 || outcome.DeletedTrackers != 1 || outcome.DeletedSources != 1 {
+		t.Fatalf("unexpected outcome: %+v", outcome)
+	}
+
+	if count := countRows(t, seeded.db, `SELECT COUNT(1) FROM source_migrations`); count != 1 {
+		t.Fatalf("expected one source migration, got %d", count)
+	}
+	var (
+		trackerID   int64
+		oldKey      string
+		oldURL      string
+		oldItemID   sql.NullString
+		newSourceID int64
+		migratedAt  time.Time
+	)
+	if err := seeded.db.QueryRow(`
+		SELECT tracker_id, old_source_key, old_source_url, old_source_item_id, new_source_id, migrated_at
+		FROM source_migrations
+	`).Scan(&trackerID, &oldKey, &oldURL, &oldItemID, &newSourceID, &migratedAt); err != nil {
+		t.Fatalf("read source migration: %v", err)
+	}
+	if trackerID != seeded.promotedID || oldKey != "retiredsite" || oldURL != "https://retired.example/series/promoted" {
+		t.Fatalf("unexpected source migration: tracker=%d key=%q url=%q", trackerID, oldKey, oldURL)
+	}
+	if !oldItemID.Valid || oldItemID.String != "retired-42" || newSourceID != seeded.mangadexID || !migratedAt.Equal(runAt) {
+		t.Fatalf("unexpected source migration: item=%v new_source=%d migrated_at=%v", oldItemID, newSourceID, migratedAt)
+	}
+
+	var (
+		sourceID  int64
+		sourceURL string
+		updatedAt time.Time
+	)
+	if err := seeded.db.QueryRow(`SELECT source_id, source_url, updated_at FROM trackers WHERE id = ?`, seeded.promotedID).Scan(&sourceID, &sourceURL, &updatedAt); err != nil {
+		t.Fatalf("read promoted tracker: %v", err)
+	}
+	if sourceID != seeded.mangadexID || sourceURL != "https://mangadex.org/title/md-7" {
+		t.Fatalf("expected tracker to be promoted to mangadex, got source=%d url=%q", sourceID, sourceURL)
+	}
+	if want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC); !updatedAt.Equal(want) {
+		t.Fatalf("expected promotion to keep updated_at, got %v", updatedAt)
+	}
+
+	var (
+		historyURL    string
+		historyReason string
+		replacedAt    time.Time
+	)
+	if err := seeded.db.QueryRow(`
+		SELECT old_url, reason, replaced_at FROM tracker_url_history WHERE tracker_id = ?
+	`, seeded.promotedID).Scan(&historyURL, &historyReason, &replacedAt); err != nil {
+		t.Fatalf("read url history: %v", err)
+	}
+	if historyURL != "https://retired.example/series/promoted" || historyReason != "promotion" || !replacedAt.Equal(runAt) {
+		t.Fatalf("unexpected url history: url=%q reason=%q replaced_at=%v", historyURL, historyReason, replacedAt)
+	}
+}
+
+func TestRunCleanupWritesReportWithoutApplying(t *testing.T) {
+	seeded := setupCleanupTestDB(t)
+	reportPath := filepath.Join(t.TempDir(), "plan.json")
+
+	if _, err := runCleanup(seeded.db, buildActiveSourceKeySet(), cleanupOptions{ReportPath: reportPath}, time.Now().UTC()); err != nil {
+		t.Fatalf("run cleanup: %v", err)
+	}
+
+	raw, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var report cleanupReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.Apply || len(report.StaleSources) != 1 || len(report.Promotions) != 1 || len(report.TrackersToDelete) != 1 {
+		t.Fatalf("unexpected report: %s", raw)
+	}
+	promotion := report.Promotions[0]
+	if promotion.TrackerID != seeded.promotedID || promotion.OldSourceURL != "https://retired.example/series/promoted" || promotion.NewSourceID != seeded.mangadexID {
+		t.Fatalf("unexpected planned promotion: %+v", promotion)
+	}
+	if report.TrackersToDelete[0].TrackerID != seeded.orphanedID || report.LinkedRowsToDelete != 1 || len(report.SourcesToDelete) != 1 {
+		t.Fatalf("unexpected planned deletions: %s", raw)
+	}
+
+	if count := countRows(t, seeded.db, `SELECT COUNT(1) FROM source_migrations`); count != 0 {
+		t.Fatalf("expected a dry run to record nothing, got %d source migrations", count)
+	}
+	if count := countRows(t, seeded.db, `SELECT COUNT(1) FROM trackers WHERE source_id = ?`, seeded.staleSourceID); count != 2 {
+		t.Fatalf("expected a dry run to keep both trackers on the stale source, got %d", count)
+	}
+}
+
+func TestRunCleanupTrackerIDLimitsTheRun(t *testing.T) {
+	seeded := setupCleanupTestDB(t)
+	options := cleanupOptions{Apply: true, TrackerID: seeded.promotedID}
+
+	outcome, err := runCleanup(seeded.db, buildActiveSourceKeySet(), options, time.Now().UTC())
+	if err != nil {
+		t.Fatalf("run cleanup: %v", err)
+	}
+	if outcome.PromotedTrackers != 1 || outcome.DeletedLinks != 1 || outcome.DeletedTrackers != 0 || outcome.DeletedSources != 0 {
+		t.Fatalf("unexpected outcome: %+v", outcome)
+	}
+
+	if count := countRows(t, seeded.db, `SELECT COUNT(1) FROM source_migrations WHERE tracker_id = ?`, seeded.promotedID); count != 1 {
+		t.Fatalf("expected the promotion to be recorded, got %d", count)
+	}
+	if count := countRows(t, seeded.db, `SELECT COUNT(1) FROM trackers WHERE id = ? AND source_id = ?`, seeded.orphanedID, seeded.staleSourceID); count != 1 {
+		t.Fatalf("expected the other tracker to be left alone")
+	}
+	if count := countRows(t, seeded.db, `SELECT COUNT(1) FROM sources WHERE id = ?`, seeded.staleSourceID); count != 1 {
+		t.Fatalf("expected the stale source to be kept for a full run")
+	}
+}
+
+func TestPlanPromotionsFollowsTheLinkedSourceOrder(t *testing.T) {
+	seeded := setupCleanupTestDB(t)
+
+	var mangafireID int64
+	if err := seeded.db.QueryRow(`SELECT id FROM sources WHERE key = 'mangafire'`).Scan(&mangafireID); err != nil {
+		t.Fatalf("find mangafire source: %v", err)
+	}
+	// MangaFire is linked last but placed before MangaDex.
+	if _, err := seeded.db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_item_id, source_url, position)
+		VALUES (?, ?, 'blade.x1', 'https://mangafire.to/manga/blade.x1', 0)
+	`, seeded.promotedID, mangafireID); err != nil {
+		t.Fatalf("seed mangafire link: %v", err)
+	}
+	if _, err := seeded.db.Exec(`UPDATE tracker_sources SET position = 1 WHERE tracker_id = ? AND source_id = ?`, seeded.promotedID, seeded.mangadexID); err != nil {
+		t.Fatalf("move mangadex link: %v", err)
+	}
+
+	staleSourceIDs := map[int64]struct{}{seeded.staleSourceID: {}}
+	promotions, _, err := planTrackerPrimarySourcePromotions(seeded.db, staleSourceIDs, map[int64]string{seeded.staleSourceID: "retiredsite"}, seeded.promotedID)
+	if err != nil {
+		t.Fatalf("plan promotions: %v", err)
+	}
+	if len(promotions) != 1 || promotions[0].NewSourceID != mangafireID || promotions[0].NewSourceURL != "https://mangafire.to/manga/blade.x1" {
+		t.Fatalf("expected the first linked source in order to be promoted, got %+v", promotions)
+	}
+
+	if _, err := seeded.db.Exec(`UPDATE tracker_sources SET position = 2 WHERE tracker_id = ? AND source_id = ?`, seeded.promotedID, mangafireID); err != nil {
+		t.Fatalf("move mangafire link: %v", err)
+	}
+	promotions, _, err = planTrackerPrimarySourcePromotions(seeded.db, staleSourceIDs, map[int64]string{seeded.staleSourceID: "retiredsite"}, seeded.promotedID)
+	if err != nil {
+		t.Fatalf("plan promotions: %v", err)
+	}
+	if len(promotions) != 1 || promotions[0].NewSourceID != seeded.mangadexID {
+		t.Fatalf("expected mangadex promoted once it is first, got %+v", promotions)
+	}
+}
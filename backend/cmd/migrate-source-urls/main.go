@@ -0,0 +1,306 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	connectordefaults "github.com/gabriel/cross-site-tracker/backend/internal/connectors/defaults"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/selfcheck"
+)
+
+type migrateOptions struct {
+	Apply bool
+	// SourceKey limits the run to one source; "" migrates every source.
+	SourceKey string
+}
+
+// urlRewrite is one stored URL the rules move, in trackers or
+// tracker_sources.
+type urlRewrite struct {
+	Table     string
+	RowID     int64
+	TrackerID int64
+	SourceKey string
+	OldURL    string
+	NewURL    string
+	Rules     []string
+}
+
+type migrateOutcome struct {
+	// RuleCounts is how many stored URLs each rule moves, keyed by
+	// "<source key>: <rule>".
+	RuleCounts      map[string]int
+	PlannedTrackers int
+	PlannedLinks    int
+	UpdatedTrackers int64
+	UpdatedLinks    int64
+	MergedLinks     int64
+}
+
+func main() {
+	var options migrateOptions
+	flag.BoolVar(&options.Apply, "apply", false, "Apply the URL rewrites. Without this flag, the command is a dry-run preview.")
+	flag.StringVar(&options.SourceKey, "source", "", "Only migrate URLs of a single source key (empty = all)")
+	skipSelfCheck := flag.Bool("skip-selfcheck", false, "Run without checking the migration and sqlite paths")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	if !*skipSelfCheck {
+		if report := selfcheck.Run(selfcheck.Paths{MigrationsDir: cfg.MigrationsPath, SQLitePath: cfg.SQLitePath}, nil); !report.OK() {
+			fmt.Fprint(os.Stderr, report)
+			os.Exit(1)
+		}
+	}
+
+	db, err := database.Open(cfg.SQLitePath)
+	if err != nil {
+		slog.Error("failed to open sqlite", "path", cfg.SQLitePath, "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if err := database.ApplyMigrations(db, cfg.MigrationsPath); err != nil {
+		slog.Error("failed to apply migrations", "error", err)
+		os.Exit(1)
+	}
+
+	if _, err := runMigration(db, connectordefaults.NewRegistry().AllURLMigrationRules(), options); err != nil {
+		slog.Error("source url migration failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// runMigration plans the rewrites the connectors' URL migration rules make to
+// stored tracker and linked source URLs, logs per-rule counts, and applies
+// them with -apply.
+func runMigration(db *sql.DB, rules []connectors.URLMigrationRules, options migrateOptions) (migrateOutcome, error) {
+	rulesByKey := make(map[string]connectors.URLMigrationRules, len(rules))
+	for _, item := range rules {
+		key := normalizeSourceKey(item.SourceKey)
+		if options.SourceKey != "" && key != normalizeSourceKey(options.SourceKey) {
+			continue
+		}
+		rulesByKey[key] = item
+	}
+	slog.Info("loaded url migration rules from registry", "sources", len(rulesByKey), "keys", sortedKeys(rulesByKey))
+
+	trackerRewrites, err := planRewrites(db, rulesByKey, `
+		SELECT t.id, t.id, s.key, t.source_url
+		FROM trackers t
+		INNER JOIN sources s ON s.id = t.source_id
+		ORDER BY t.id ASC
+	`, "trackers")
+	if err != nil {
+		return migrateOutcome{}, err
+	}
+	linkRewrites, err := planRewrites(db, rulesByKey, `
+		SELECT ts.id, ts.tracker_id, s.key, ts.source_url
+		FROM tracker_sources ts
+		INNER JOIN sources s ON s.id = ts.source_id
+		ORDER BY ts.id ASC
+	`, "tracker_sources")
+	if err != nil {
+		return migrateOutcome{}, err
+	}
+
+	outcome := migrateOutcome{
+		RuleCounts:      map[string]int{},
+		PlannedTrackers: len(trackerRewrites),
+		PlannedLinks:    len(linkRewrites),
+	}
+	for _, rewrite := range append(append([]urlRewrite{}, trackerRewrites...), linkRewrites...) {
+		for _, rule := range rewrite.Rules {
+			outcome.RuleCounts[rewrite.SourceKey+": "+rule]++
+		}
+	}
+	for _, rule := range sortedKeys(outcome.RuleCounts) {
+		slog.Info("url migration rule matches", "rule", rule, "urls", outcome.RuleCounts[rule])
+	}
+
+	if len(trackerRewrites) == 0 && len(linkRewrites) == 0 {
+		slog.Info("no stored urls need migrating")
+		return outcome, nil
+	}
+
+	if !options.Apply {
+		slog.Info(
+			"dry-run complete",
+			"trackers_to_update", len(trackerRewrites),
+			"tracker_sources_to_update", len(linkRewrites),
+		)
+		return outcome, nil
+	}
+
+	if err := applyRewrites(db, trackerRewrites, linkRewrites, &outcome); err != nil {
+		return migrateOutcome{}, fmt.Errorf("apply url migration: %w", err)
+	}
+
+	slog.Info(
+		"url migration completed",
+		"updated_trackers", outcome.UpdatedTrackers,
+		"updated_tracker_sources", outcome.UpdatedLinks,
+		"merged_tracker_sources", outcome.MergedLinks,
+	)
+	return outcome, nil
+}
+
+// planRewrites runs query, which selects (row id, tracker id, source key,
+// url), and returns the rows whose URL the rules move.
+func planRewrites(db *sql.DB, rulesByKey map[string]connectors.URLMigrationRules, query string, table string) ([]urlRewrite, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("query %s urls: %w", table, err)
+	}
+	defer rows.Close()
+
+	rewrites := make([]urlRewrite, 0)
+	for rows.Next() {
+		var rewrite urlRewrite
+		if err := rows.Scan(&rewrite.RowID, &rewrite.TrackerID, &rewrite.SourceKey, &rewrite.OldURL); err != nil {
+			return nil, fmt.Errorf("scan %s url: %w", table, err)
+		}
+		rules, ok := rulesByKey[normalizeSourceKey(rewrite.SourceKey)]
+		if !ok {
+			continue
+		}
+		rewrite.NewURL, rewrite.Rules = rules.Migrate(rewrite.OldURL)
+		if len(rewrite.Rules) == 0 {
+			continue
+		}
+		rewrite.Table = table
+		rewrites = append(rewrites, rewrite)
+		slog.Info(
+			"stored url will be migrated",
+			"table", table,
+			"row_id", rewrite.RowID,
+			"tracker_id", rewrite.TrackerID,
+			"source_key", rewrite.SourceKey,
+			"from", rewrite.OldURL,
+			"to", rewrite.NewURL,
+		)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s urls: %w", table, err)
+	}
+	return rewrites, nil
+}
+
+// applyRewrites stores every planned URL in one transaction. A linked row
+// whose new URL is already stored for the same tracker and source is dropped
+// in favour of that row.
+func applyRewrites(db *sql.DB, trackerRewrites []urlRewrite, linkRewrites []urlRewrite, outcome *migrateOutcome) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin url migration tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, rewrite := range trackerRewrites {
+		// updated_at is left alone: only where the tracker is polled from
+		// moved.
+		result, err := tx.Exec(`UPDATE trackers SET source_url = ? WHERE id = ? AND source_url = ?`, rewrite.NewURL, rewrite.RowID, rewrite.OldURL)
+		if err != nil {
+			return fmt.Errorf("migrate tracker %d url: %w", rewrite.RowID, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("tracker %d url rows affected: %w", rewrite.RowID, err)
+		}
+		outcome.UpdatedTrackers += rowsAffected
+		if rowsAffected > 0 {
+			if err := recordReplacedURL(tx, rewrite); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, rewrite := range linkRewrites {
+		result, err := tx.Exec(`
+			UPDATE OR IGNORE tracker_sources
+			SET source_url = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND source_url = ?
+		`, rewrite.NewURL, rewrite.RowID, rewrite.OldURL)
+		if err != nil {
+			return fmt.Errorf("migrate tracker source %d url: %w", rewrite.RowID, err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("tracker source %d url rows affected: %w", rewrite.RowID, err)
+		}
+		if rowsAffected > 0 {
+			outcome.UpdatedLinks += rowsAffected
+			if err := recordReplacedURL(tx, rewrite); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, err = tx.Exec(`DELETE FROM tracker_sources WHERE id = ? AND source_url = ?`, rewrite.RowID, rewrite.OldURL)
+		if err != nil {
+			return fmt.Errorf("merge tracker source %d: %w", rewrite.RowID, err)
+		}
+		rowsAffected, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("tracker source %d merge rows affected: %w", rewrite.RowID, err)
+		}
+		outcome.MergedLinks += rowsAffected
+		if rowsAffected > 0 {
+			if err := recordReplacedURL(tx, rewrite); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit url migration tx: %w", err)
+	}
+	return nil
+}
+
+// recordReplacedURL keeps the rewritten URL in the tracker's URL history,
+// once per tracker and source: a primary URL and its linked row move alike.
+func recordReplacedURL(tx *sql.Tx, rewrite urlRewrite) error {
+	if _, err := tx.Exec(`
+		INSERT INTO tracker_url_history (tracker_id, source_id, old_url, reason)
+		SELECT ?, s.id, ?, 'domain_migration'
+		FROM sources s
+		WHERE s.key = ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM tracker_url_history h
+			WHERE h.tracker_id = ? AND h.source_id = s.id AND LOWER(h.old_url) = LOWER(?)
+		  )
+	`, rewrite.TrackerID, rewrite.OldURL, rewrite.SourceKey, rewrite.TrackerID, rewrite.OldURL); err != nil {
+		return fmt.Errorf("record tracker %d url history: %w", rewrite.TrackerID, err)
+	}
+	return nil
+}
+
+func normalizeSourceKey(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	connectordefaults "github.com/gabriel/cross-site-tracker/backend/internal/connectors/defaults"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+)
+
+type seededMigration struct {
+	db        *sql.DB
+	asuraID   int64
+	dexID     int64
+	manualID  int64
+	asuraSrc  int64
+	dexSrc    int64
+	manualSrc int64
+}
+
+// setupMigrationTestDB seeds an AsuraComic tracker still on the old
+// asuracomic.net host over http, whose links include the new URL already, a
+// MangaDex tracker on http, and a manual tracker no rule applies to.
+func setupMigrationTestDB(t *testing.T) seededMigration {
+	t.Helper()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "migrate.sqlite"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := database.ApplyMigrations(db, filepath.Join("..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+
+	seeded := seededMigration{db: db}
+	for key, id := range map[string]*int64{"asuracomic": &seeded.asuraSrc, "mangadex": &seeded.dexSrc, "manual": &seeded.manualSrc} {
+		if err := db.QueryRow(`SELECT id FROM sources WHERE key = ?`, key).Scan(id); err != nil {
+			t.Fatalf("find %s source: %v", key, err)
+		}
+	}
+
+	seeded.asuraID = insertTracker(t, db, "Moved Blade", seeded.asuraSrc, "http://asuracomic.net/series/moved")
+	seeded.dexID = insertTracker(t, db, "Plain Blade", seeded.dexSrc, "http://mangadex.org/title/plain")
+	seeded.manualID = insertTracker(t, db, "Manual Blade", seeded.manualSrc, "http://forum.example/thread/1")
+	if _, err := db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_url)
+		VALUES (?, ?, 'http://asuracomic.net/series/moved'),
+		       (?, ?, 'https://asurascans.com/series/moved'),
+		       (?, ?, 'http://mangadex.org/title/plain'),
+		       (?, ?, 'http://forum.example/thread/1')
+	`, seeded.asuraID, seeded.asuraSrc, seeded.asuraID, seeded.asuraSrc, seeded.dexID, seeded.dexSrc, seeded.manualID, seeded.manualSrc); err != nil {
+		t.Fatalf("seed linked sources: %v", err)
+	}
+
+	return seeded
+}
+
+func insertTracker(t *testing.T, db *sql.DB, title string, sourceID int64, sourceURL string) int64 {
+	t.Helper()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, updated_at)
+		VALUES (1, ?, ?, ?, 'reading', '2020-01-02 03:04:05')
+	`, title, sourceID, sourceURL)
+	if err != nil {
+		t.Fatalf("seed tracker %q: %v", title, err)
+	}
+	id, _ := result.LastInsertId()
+	return id
+}
+
+func trackerURL(t *testing.T, db *sql.DB, id int64) string {
+	t.Helper()
+
+	var sourceURL string
+	if err := db.QueryRow(`SELECT source_url FROM trackers WHERE id = ?`, id).Scan(&sourceURL); err != nil {
+		t.Fatalf("load tracker %d url: %v", id, err)
+	}
+	return sourceURL
+}
+
+func linkURLs(t *testing.T, db *sql.DB, trackerID int64) []string {
+	t.Helper()
+
+	rows, err := db.Query(`SELECT source_url FROM tracker_sources WHERE tracker_id = ? ORDER BY source_url ASC`, trackerID)
+	if err != nil {
+		t.Fatalf("load tracker %d links: %v", trackerID, err)
+	}
+	defer rows.Close()
+	urls := make([]string, 0)
+	for rows.Next() {
+		var sourceURL string
+		if err := rows.Scan(&sourceURL); err != nil {
+			t.Fatalf("scan link: %v", err)
+		}
+		urls = append(urls, sourceURL)
+	}
+	return urls
+}
+
+func TestRunMigrationDryRunReportsPerRuleCounts(t *testing.T) {
+	seeded := setupMigrationTestDB(t)
+
+	outcome, err := runMigration(seeded.db, connectordefaults.NewRegistry().AllURLMigrationRules(), migrateOptions{})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+
+	want := map[string]int{
+		"asuracomic: asuracomic.net → asurascans.com": 2,
+		"asuracomic: http → https":                    2,
+		"mangadex: http → https":                      2,
+	}
+	if len(outcome.RuleCounts) != len(want) {
+		t.Fatalf("expected rule counts %v, got %v", want, outcome.RuleCounts)
+	}
+	for rule, count := range want {
+		if outcome.RuleCounts[rule] != count {
+			t.Fatalf("expected %d urls for %q, got %v", count, rule, outcome.RuleCounts)
+		}
+	}
+	if outcome.PlannedTrackers != 2 || outcome.PlannedLinks != 2 || outcome.UpdatedTrackers != 0 {
+		t.Fatalf("unexpected dry-run outcome: %+v", outcome)
+	}
+	if got := trackerURL(t, seeded.db, seeded.asuraID); got != "http://asuracomic.net/series/moved" {
+		t.Fatalf("expected a dry run to leave urls alone, got %q", got)
+	}
+}
+
+func countHistory(t *testing.T, db *sql.DB) int64 {
+	t.Helper()
+
+	var count int64
+	if err := db.QueryRow(`SELECT COUNT(1) FROM tracker_url_history`).Scan(&count); err != nil {
+		t.Fatalf("count url history: %v", err)
+	}
+	return count
+}
+
+func TestRunMigrationApplyRewritesAndMergesLinks(t *testing.T) {
+	seeded := setupMigrationTestDB(t)
+
+	outcome, err := runMigration(seeded.db, connectordefaults.NewRegistry().AllURLMigrationRules(), migrateOptions{Apply: true})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if outcome.UpdatedTrackers != 2 || outcome.UpdatedLinks != 1 || outcome.MergedLinks != 1 {
+		t.Fatalf("unexpected apply outcome: %+v", outcome)
+	}
+
+	if got := trackerURL(t, seeded.db, seeded.asuraID); got != "https://asurascans.com/series/moved" {
+		t.Fatalf("expected the asura tracker on the new host, got %q", got)
+	}
+	if got := linkURLs(t, seeded.db, seeded.asuraID); len(got) != 1 || got[0] != "https://asurascans.com/series/moved" {
+		t.Fatalf("expected the old link merged into the new one, got %v", got)
+	}
+	if got := trackerURL(t, seeded.db, seeded.dexID); got != "https://mangadex.org/title/plain" {
+		t.Fatalf("expected the mangadex tracker on https, got %q", got)
+	}
+	if got := trackerURL(t, seeded.db, seeded.manualID); got != "http://forum.example/thread/1" {
+		t.Fatalf("expected the manual tracker untouched, got %q", got)
+	}
+
+	// A primary URL and its linked row moved alike are kept once.
+	var history int64
+	if err := seeded.db.QueryRow(`
+		SELECT COUNT(1) FROM tracker_url_history
+		WHERE reason = 'domain_migration'
+		  AND ((tracker_id = ? AND source_id = ? AND old_url = 'http://asuracomic.net/series/moved')
+		    OR (tracker_id = ? AND source_id = ? AND old_url = 'http://mangadex.org/title/plain'))
+	`, seeded.asuraID, seeded.asuraSrc, seeded.dexID, seeded.dexSrc).Scan(&history); err != nil {
+		t.Fatalf("count url history: %v", err)
+	}
+	if total := countHistory(t, seeded.db); history != 2 || total != 2 {
+		t.Fatalf("expected each old URL in the history once, got %d of %d", history, total)
+	}
+
+	again, err := runMigration(seeded.db, connectordefaults.NewRegistry().AllURLMigrationRules(), migrateOptions{Apply: true})
+	if err != nil {
+		t.Fatalf("second apply: %v", err)
+	}
+	if again.PlannedTrackers != 0 || again.PlannedLinks != 0 {
+		t.Fatalf("expected a second run to find nothing, got %+v", again)
+	}
+}
+
+func TestRunMigrationLimitsToSource(t *testing.T) {
+	seeded := setupMigrationTestDB(t)
+
+	if _, err := runMigration(seeded.db, connectordefaults.NewRegistry().AllURLMigrationRules(), migrateOptions{Apply: true, SourceKey: "mangadex"}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if got := trackerURL(t, seeded.db, seeded.dexID); got != "https://mangadex.org/title/plain" {
+		t.Fatalf("expected the mangadex tracker migrated, got %q", got)
+	}
+	if got := trackerURL(t, seeded.db, seeded.asuraID); got != "http://asuracomic.net/series/moved" {
+		t.Fatalf("expected other sources left alone, got %q", got)
+	}
+}
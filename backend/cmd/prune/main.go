@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/retention"
+	"github.com/gabriel/cross-site-tracker/backend/internal/selfcheck"
+)
+
+func main() {
+	var (
+		apply         = flag.Bool("apply", false, "Delete the rows. Without this flag, the command is a dry-run preview.")
+		batchSize     = flag.Int("batch-size", 0, "Rows deleted per statement (0 = RETENTION_BATCH_SIZE)")
+		skipSelfCheck = flag.Bool("skip-selfcheck", false, "Run without checking the migration and sqlite paths")
+	)
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	if !*skipSelfCheck {
+		if report := selfcheck.Run(selfcheck.Paths{MigrationsDir: cfg.MigrationsPath, SQLitePath: cfg.SQLitePath}, nil); !report.OK() {
+			fmt.Fprint(os.Stderr, report)
+			os.Exit(1)
+		}
+	}
+
+	retentionConfig, err := retention.ConfigFrom(cfg)
+	if err != nil {
+		slog.Error("invalid retention settings", "error", err)
+		os.Exit(1)
+	}
+	if *batchSize > 0 {
+		retentionConfig.BatchSize = *batchSize
+	}
+
+	db, err := database.Open(cfg.SQLitePath)
+	if err != nil {
+		slog.Error("failed to open sqlite", "path", cfg.SQLitePath, "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if err := database.ApplyMigrations(db, cfg.MigrationsPath); err != nil {
+		slog.Error("failed to apply migrations", "error", err)
+		os.Exit(1)
+	}
+
+	if _, err := runPrune(context.Background(), retention.NewPruner(db, retentionConfig, logger), retentionConfig.Policies, *apply); err != nil {
+		slog.Error("prune failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// runPrune logs each table's retention and what falls outside it, then
+// deletes those rows with -apply.
+func runPrune(ctx context.Context, pruner *retention.Pruner, policies []retention.Policy, apply bool) ([]retention.Result, error) {
+	for _, policy := range policies {
+		if !policy.Enabled() {
+			slog.Info("table kept in full", "table", policy.Table.Name)
+		}
+	}
+
+	pending, err := pruner.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	total := 0
+	for _, result := range pending {
+		total += result.Rows()
+		slog.Info("rows outside retention", "table", result.Table, "expired", result.Expired, "over_cap", result.Trimmed)
+	}
+	if !apply {
+		slog.Info("dry-run complete", "rows_to_prune", total)
+		return pending, nil
+	}
+	if total == 0 {
+		slog.Info("nothing to prune")
+		return pending, nil
+	}
+	return pruner.Prune(ctx)
+}
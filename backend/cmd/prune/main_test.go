@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/retention"
+)
+
+func setupPruneTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "prune.sqlite"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := database.ApplyMigrations(db, filepath.Join("..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	now := time.Now().UTC()
+	if _, err := db.Exec(`INSERT INTO link_cache (kind, cache_key, url, expires_at) VALUES
+		('search', 'stale', 'https://example.com', ?),
+		('search', 'fresh', 'https://example.com', ?)`,
+		now.AddDate(0, 0, -30), now.Add(time.Hour)); err != nil {
+		t.Fatalf("seed link cache: %v", err)
+	}
+	return db
+}
+
+func linkCacheCount(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(1) FROM link_cache`).Scan(&count); err != nil {
+		t.Fatalf("count link cache: %v", err)
+	}
+	return count
+}
+
+func TestRunPruneDryRunThenApply(t *testing.T) {
+	db := setupPruneTestDB(t)
+	retentionConfig, err := retention.ConfigFrom(config.Config{})
+	if err != nil {
+		t.Fatalf("retention config: %v", err)
+	}
+	pruner := retention.NewPruner(db, retentionConfig, nil)
+
+	results, err := runPrune(context.Background(), pruner, retentionConfig.Policies, false)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	expired := 0
+	for _, result := range results {
+		expired += result.Expired
+	}
+	if expired != 1 || linkCacheCount(t, db) != 2 {
+		t.Fatalf("expected a dry run to find the stale row and keep it, got %+v", results)
+	}
+
+	if _, err := runPrune(context.Background(), pruner, retentionConfig.Policies, true); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if count := linkCacheCount(t, db); count != 1 {
+		t.Fatalf("expected only the fresh row left, got %d rows", count)
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/profilekey"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/selfcheck"
+)
+
+type renameOptions struct {
+	Apply bool
+	// Interactive asks for each non-conforming key's new key on the
+	// prompt, offering the suggestion as the default.
+	Interactive bool
+	// Renames maps current keys to the new keys given with -rename. They
+	// win over suggestions and may also rename conforming keys.
+	Renames map[string]string
+}
+
+type keyRename struct {
+	ProfileID int64
+	OldKey    string
+	NewKey    string
+}
+
+// renameFlags collects repeated -rename old=new flags.
+type renameFlags map[string]string
+
+func (f renameFlags) String() string {
+	pairs := make([]string, 0, len(f))
+	for oldKey, newKey := range f {
+		pairs = append(pairs, oldKey+"="+newKey)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f renameFlags) Set(value string) error {
+	oldKey, newKey, ok := strings.Cut(value, "=")
+	if !ok || oldKey == "" {
+		return fmt.Errorf("expected old=new, got %q", value)
+	}
+	f[oldKey] = profilekey.Normalize(newKey)
+	return nil
+}
+
+func main() {
+	options := renameOptions{Renames: renameFlags{}}
+	flag.BoolVar(&options.Apply, "apply", false, "Apply the renames. Without this flag, the command is a dry-run preview.")
+	flag.BoolVar(&options.Interactive, "interactive", false, "Ask for each non-conforming key's new key (enter keeps the suggestion, - skips the profile)")
+	flag.Var(renameFlags(options.Renames), "rename", "Rename one key as old=new instead of using the suggestion (repeatable)")
+	skipSelfCheck := flag.Bool("skip-selfcheck", false, "Run without checking the migration and sqlite paths")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	if !*skipSelfCheck {
+		if report := selfcheck.Run(selfcheck.Paths{MigrationsDir: cfg.MigrationsPath, SQLitePath: cfg.SQLitePath}, nil); !report.OK() {
+			fmt.Fprint(os.Stderr, report)
+			os.Exit(1)
+		}
+	}
+
+	db, err := database.Open(cfg.SQLitePath)
+	if err != nil {
+		slog.Error("failed to open sqlite", "path", cfg.SQLitePath, "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if err := database.ApplyMigrations(db, cfg.MigrationsPath); err != nil {
+		slog.Error("failed to apply migrations", "error", err)
+		os.Exit(1)
+	}
+
+	if _, err := runRename(context.Background(), repository.NewProfileRepository(db), options, os.Stdin, os.Stdout); err != nil {
+		slog.Error("profile key rename failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// runRename plans a canonical key for every profile whose key is not, plus
+// the explicit renames, logs the plan and applies it with -apply. With
+// -interactive the new keys are read from in, one line per prompt written
+// to out.
+func runRename(ctx context.Context, repo *repository.ProfileRepository, options renameOptions, in io.Reader, out io.Writer) ([]keyRename, error) {
+	profiles, err := repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make(map[string]bool, len(profiles))
+	known := make(map[string]bool, len(profiles))
+	for _, profile := range profiles {
+		taken[profile.Key] = true
+		known[profile.Key] = true
+	}
+	for oldKey := range options.Renames {
+		if !known[oldKey] {
+			return nil, fmt.Errorf("no profile has the key %q", oldKey)
+		}
+	}
+
+	reader := bufio.NewReader(in)
+	plan := make([]keyRename, 0)
+	for _, profile := range profiles {
+		newKey, explicit := options.Renames[profile.Key]
+		if !explicit && profilekey.Validate(profile.Key) == nil {
+			continue
+		}
+		if !explicit {
+			newKey = profilekey.Suggest(profile.Key, func(key string) bool { return taken[key] })
+			if options.Interactive {
+				newKey, err = promptKey(reader, out, profile, newKey, taken)
+				if err != nil {
+					return nil, err
+				}
+				if newKey == "" {
+					slog.Info("profile key left as is", "profile_id", profile.ID, "key", profile.Key)
+					continue
+				}
+			}
+		}
+		if err := profilekey.Validate(newKey); err != nil {
+			return nil, err
+		}
+		if newKey != profile.Key && taken[newKey] {
+			return nil, fmt.Errorf("profile key %q is already taken", newKey)
+		}
+
+		taken[newKey] = true
+		plan = append(plan, keyRename{ProfileID: profile.ID, OldKey: profile.Key, NewKey: newKey})
+		slog.Info("profile key will be renamed", "profile_id", profile.ID, "from", profile.Key, "to", newKey)
+	}
+
+	if len(plan) == 0 {
+		slog.Info("every profile key is canonical")
+		return plan, nil
+	}
+	if !options.Apply {
+		slog.Info("dry-run complete", "profiles_to_rename", len(plan))
+		return plan, nil
+	}
+
+	renamed := 0
+	for _, item := range plan {
+		changed, err := repo.RenameKey(ctx, item.ProfileID, item.NewKey)
+		if err != nil {
+			return nil, fmt.Errorf("rename profile %d key: %w", item.ProfileID, err)
+		}
+		if changed {
+			renamed++
+		}
+	}
+	slog.Info("profile key rename completed", "renamed_profiles", renamed)
+	return plan, nil
+}
+
+// promptKey asks for the new key of profile until it gets a free canonical
+// one. An empty answer takes the suggestion and "-" returns "" to skip the
+// profile.
+func promptKey(reader *bufio.Reader, out io.Writer, profile models.Profile, suggestion string, taken map[string]bool) (string, error) {
+	for {
+		fmt.Fprintf(out, "New key for profile %d %q (%s) [%s]: ", profile.ID, profile.Key, profile.Name, suggestion)
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", fmt.Errorf("read new key for profile %d: %w", profile.ID, err)
+		}
+
+		answer := profilekey.Normalize(line)
+		switch {
+		case answer == "":
+			return suggestion, nil
+		case answer == "-":
+			return "", nil
+		case profilekey.Validate(answer) != nil:
+			fmt.Fprintln(out, profilekey.Validate(answer))
+		case taken[answer]:
+			fmt.Fprintf(out, "%q is already taken\n", answer)
+		default:
+			return answer, nil
+		}
+	}
+}
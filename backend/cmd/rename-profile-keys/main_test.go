@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// setupRenameTestDB seeds the default profiles plus two stored before keys
+// were validated: one with spaces and capitals and one too short.
+func setupRenameTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "rename.sqlite"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := database.ApplyMigrations(db, filepath.Join("..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO profiles (id, key, name) VALUES (3, 'Night Reads', 'Night'), (4, 'x', 'Short')`); err != nil {
+		t.Fatalf("seed profiles: %v", err)
+	}
+	return db
+}
+
+func profileKeys(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	profiles, err := repository.NewProfileRepository(db).List(context.Background())
+	if err != nil {
+		t.Fatalf("list profiles: %v", err)
+	}
+	keys := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		keys = append(keys, profile.Key)
+	}
+	return keys
+}
+
+func TestRunRenameDryRunPlansSuggestionsWithoutWriting(t *testing.T) {
+	db := setupRenameTestDB(t)
+	repo := repository.NewProfileRepository(db)
+
+	plan, err := runRename(context.Background(), repo, renameOptions{}, strings.NewReader(""), &strings.Builder{})
+	if err != nil {
+		t.Fatalf("run rename: %v", err)
+	}
+	if len(plan) != 2 || plan[0].NewKey != "night-reads" || plan[1].NewKey != "x-profile" {
+		t.Fatalf("expected suggestions for the two non-conforming keys, got %+v", plan)
+	}
+	if got := strings.Join(profileKeys(t, db), ","); got != "profile1,profile2,Night Reads,x" {
+		t.Fatalf("expected a dry run to leave keys alone, got %s", got)
+	}
+
+	pending, err := repo.ListNonConformingKeys(context.Background())
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("expected two non-conforming keys reported, got %+v (err %v)", pending, err)
+	}
+}
+
+func TestRunRenameAppliesPromptedAndFlaggedKeys(t *testing.T) {
+	db := setupRenameTestDB(t)
+	repo := repository.NewProfileRepository(db)
+
+	// The first answer is malformed and the second taken, so the prompt
+	// repeats until it gets "evening"; the short key is skipped.
+	var prompts strings.Builder
+	options := renameOptions{
+		Apply:       true,
+		Interactive: true,
+		Renames:     map[string]string{"profile2": "second"},
+	}
+	plan, err := runRename(context.Background(), repo, options, strings.NewReader("Bad Key!\nprofile1\nevening\n-\n"), &prompts)
+	if err != nil {
+		t.Fatalf("run rename: %v", err)
+	}
+	if len(plan) != 2 {
+		t.Fatalf("expected the flagged and the prompted rename, got %+v", plan)
+	}
+	if got := strings.Join(profileKeys(t, db), ","); got != "profile1,second,evening,x" {
+		t.Fatalf("expected renamed keys, got %s", got)
+	}
+	if strings.Count(prompts.String(), `New key for profile 3 "Night Reads"`) != 3 || !strings.Contains(prompts.String(), `"profile1" is already taken`) {
+		t.Fatalf("expected the prompt to repeat for bad answers, got %s", prompts.String())
+	}
+
+	if _, err := runRename(context.Background(), repo, renameOptions{Renames: map[string]string{"missing": "found"}}, strings.NewReader(""), &strings.Builder{}); err == nil {
+		t.Fatal("expected renaming an unknown key to fail")
+	}
+	if _, err := runRename(context.Background(), repo, renameOptions{Renames: map[string]string{"x": "evening"}}, strings.NewReader(""), &strings.Builder{}); err == nil {
+		t.Fatal("expected renaming onto a taken key to fail")
+	}
+}
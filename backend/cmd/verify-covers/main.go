@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	connectordefaults "github.com/gabriel/cross-site-tracker/backend/internal/connectors/defaults"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/imageprobe"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+	"github.com/gabriel/cross-site-tracker/backend/internal/pacing"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/selfcheck"
+)
+
+// coverRecord is one stored cover to check. Trackers sharing a series share
+// its cover, so each cover key is listed once.
+type coverRecord struct {
+	TrackerID    int64
+	SourceKey    string
+	SourceURL    string
+	SourceItemID *string
+	CoverKey     string
+}
+
+type outcome int
+
+const (
+	// outcomeSkipped: nothing stored, the stored cover expired, or it was
+	// verified after -older-than.
+	outcomeSkipped outcome = iota
+	outcomeOK
+	outcomeRefreshed
+	outcomeUnresolvable
+	// outcomeUnchecked: the CDN or the source could not be reached, so the
+	// cover is left for a later run.
+	outcomeUnchecked
+)
+
+type summary struct {
+	Covers       int
+	Skipped      int
+	OK           int
+	Refreshed    int
+	Unresolvable int
+	Unchecked    int
+}
+
+func (s *summary) add(result outcome) {
+	s.Covers++
+	switch result {
+	case outcomeSkipped:
+		s.Skipped++
+	case outcomeOK:
+		s.OK++
+	case outcomeRefreshed:
+		s.Refreshed++
+	case outcomeUnresolvable:
+		s.Unresolvable++
+	case outcomeUnchecked:
+		s.Unchecked++
+	}
+}
+
+type verifier struct {
+	resolver *linkcache.Resolver
+	prober   *imageprobe.Prober
+	limiter  *pacing.Limiter
+	// olderThan, when set, skips covers verified at or after it.
+	olderThan time.Time
+	dryRun    bool
+	now       func() time.Time
+}
+
+func main() {
+	var (
+		profileID      = flag.Int64("profile-id", 0, "Only verify covers of a single profile id (0 = all)")
+		olderThan      = flag.String("older-than", "", "Only verify covers not verified since this date (YYYY-MM-DD or RFC 3339; empty = all)")
+		workers        = flag.Int("workers", 4, "Number of covers verified concurrently")
+		hostInterval   = flag.Duration("host-interval", time.Second, "Minimum time between requests to the same image host")
+		sourceInterval = flag.Duration("source-interval", time.Second, "Minimum time between cover lookups against the same source")
+		dryRun         = flag.Bool("dry-run", false, "Check and report without changing the stored covers")
+		progressEvery  = flag.Int("progress-every", 25, "Log progress after every N covers (0 = never)")
+		skipSelfCheck  = flag.Bool("skip-selfcheck", false, "Run without checking the migration and sqlite paths")
+	)
+	flag.Parse()
+
+	cutoff, err := parseOlderThan(*olderThan)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	if !*skipSelfCheck {
+		if report := selfcheck.Run(selfcheck.Paths{MigrationsDir: cfg.MigrationsPath, SQLitePath: cfg.SQLitePath}, nil); !report.OK() {
+			fmt.Fprint(os.Stderr, report)
+			os.Exit(1)
+		}
+	}
+
+	db, err := database.Open(cfg.SQLitePath)
+	if err != nil {
+		slog.Error("failed to open sqlite", "path", cfg.SQLitePath, "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if err := database.ApplyMigrations(db, cfg.MigrationsPath); err != nil {
+		slog.Error("failed to apply migrations", "error", err)
+		os.Exit(1)
+	}
+
+	registry, err := connectordefaults.NewRegistryWithProxies(connectordefaults.ProxyOptions{Default: cfg.ConnectorProxy, Overrides: cfg.ConnectorProxies})
+	if err != nil {
+		slog.Error("failed to set up connector proxies", "error", err)
+		os.Exit(1)
+	}
+	registry.SetStrictMediaHosts(cfg.StrictMediaHosts)
+	settingsRepo := repository.NewSettingsRepository(db)
+	resolver := linkcache.NewResolver(registry, repository.NewLinkCacheRepository(db), func() error {
+		paused, err := settingsRepo.ScrapingPaused()
+		if err != nil || !paused {
+			return nil
+		}
+		return connectors.ErrScrapingPaused
+	})
+
+	items, err := listStoredCovers(db, *profileID)
+	if err != nil {
+		slog.Error("failed to list trackers", "error", err)
+		os.Exit(1)
+	}
+	if len(items) == 0 {
+		slog.Info("no covers found to verify", "profile_id", *profileID)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	v := &verifier{
+		resolver:  resolver,
+		prober:    imageprobe.New(nil, *hostInterval),
+		limiter:   pacing.NewLimiter(*sourceInterval),
+		olderThan: cutoff,
+		dryRun:    *dryRun,
+		now:       time.Now,
+	}
+	startedAt := time.Now()
+	stats := v.verifyAll(ctx, items, max(*workers, 1), *progressEvery)
+	slog.Info(
+		"verify completed",
+		"covers", stats.Covers,
+		"skipped", stats.Skipped,
+		"ok", stats.OK,
+		"refreshed", stats.Refreshed,
+		"unresolvable", stats.Unresolvable,
+		"unchecked", stats.Unchecked,
+		"dry_run", *dryRun,
+		"interrupted", ctx.Err() != nil,
+		"duration", time.Since(startedAt).Round(time.Second),
+	)
+}
+
+// parseOlderThan reads -older-than as a UTC date or an RFC 3339 time. An
+// empty value is the zero time, which verifies every cover.
+func parseOlderThan(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse(time.DateOnly, raw); err == nil {
+		return date, nil
+	}
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("-older-than: expected YYYY-MM-DD or an RFC 3339 time, got %q", raw)
+	}
+	return at.UTC(), nil
+}
+
+func (v *verifier) verifyAll(ctx context.Context, items []coverRecord, workers int, progressEvery int) summary {
+	jobs := make(chan coverRecord)
+	var (
+		mu    sync.Mutex
+		stats summary
+		wg    sync.WaitGroup
+	)
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				result := v.verifyCover(ctx, item)
+
+				mu.Lock()
+				stats.add(result)
+				if progressEvery > 0 && stats.Covers%progressEvery == 0 {
+					slog.Info("verify progress", "done", stats.Covers, "total", len(items), "refreshed", stats.Refreshed, "unresolvable", stats.Unresolvable)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, item := range items {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- item:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return stats
+}
+
+// verifyCover checks one stored cover. A dead cover is looked up again
+// through the connector and replaced when the source now gives a working
+// one, or dropped from the store when it does not, so the dashboard stops
+// showing a broken image and resolves it afresh later.
+func (v *verifier) verifyCover(ctx context.Context, item coverRecord) outcome {
+	entry, ok, err := v.resolver.StoredCover(item.CoverKey)
+	if err != nil {
+		slog.Warn("read stored cover failed", "tracker_id", item.TrackerID, "error", err)
+		return outcomeUnchecked
+	}
+	now := v.now().UTC()
+	if !ok || now.After(entry.ExpiresAt) {
+		return outcomeSkipped
+	}
+	if !v.olderThan.IsZero() && entry.LastVerifiedAt != nil && !entry.LastVerifiedAt.Before(v.olderThan) {
+		return outcomeSkipped
+	}
+
+	result, err := v.prober.Check(ctx, entry.URL)
+	switch result {
+	case imageprobe.Alive:
+		v.markVerified(item, now)
+		return outcomeOK
+	case imageprobe.Unknown:
+		slog.Debug("cover check inconclusive", "tracker_id", item.TrackerID, "url", entry.URL, "error", err)
+		return outcomeUnchecked
+	}
+	slog.Info("cover is dead", "tracker_id", item.TrackerID, "source_key", item.SourceKey, "url", entry.URL, "reason", err)
+
+	if err := v.limiter.Wait(ctx, item.SourceKey); err != nil {
+		return outcomeUnchecked
+	}
+	freshURL, err := v.resolver.ResolveCover(ctx, item.SourceKey, item.SourceURL)
+	if errors.Is(err, connectors.ErrScrapingPaused) || ctx.Err() != nil {
+		return outcomeUnchecked
+	}
+	if err == nil && freshURL != entry.URL {
+		if freshResult, _ := v.prober.Check(ctx, freshURL); freshResult == imageprobe.Alive {
+			if !v.dryRun {
+				v.resolver.SetCover(item.CoverKey, freshURL, true, linkcache.FoundTTL)
+				v.markVerified(item, now)
+			}
+			slog.Info("cover refreshed", "tracker_id", item.TrackerID, "url", freshURL)
+			return outcomeRefreshed
+		}
+	}
+
+	if !v.dryRun {
+		if err := v.resolver.ForgetCover(item.CoverKey); err != nil {
+			slog.Warn("drop dead cover failed", "tracker_id", item.TrackerID, "error", err)
+		}
+	}
+	slog.Info("cover unresolvable", "tracker_id", item.TrackerID, "source_key", item.SourceKey, "source_url", item.SourceURL)
+	return outcomeUnresolvable
+}
+
+func (v *verifier) markVerified(item coverRecord, at time.Time) {
+	if v.dryRun {
+		return
+	}
+	if err := v.resolver.MarkCoverVerified(item.CoverKey, at); err != nil {
+		slog.Warn("mark cover verified failed", "tracker_id", item.TrackerID, "error", err)
+	}
+}
+
+// listStoredCovers lists the cover of every tracker on an enabled source,
+// once per cover key.
+func listStoredCovers(db *sql.DB, profileID int64) ([]coverRecord, error) {
+	query := `
+		SELECT t.id, s.key, t.source_url, t.source_item_id
+		FROM trackers t
+		INNER JOIN sources s ON s.id = t.source_id
+		WHERE s.enabled = 1
+	`
+	args := make([]any, 0, 1)
+	if profileID > 0 {
+		query += ` AND t.profile_id = ?`
+		args = append(args, profileID)
+	}
+	query += ` ORDER BY t.id ASC`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query trackers: %w", err)
+	}
+	defer rows.Close()
+
+	covers := make([]coverRecord, 0)
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var (
+			item         coverRecord
+			sourceItemID sql.NullString
+		)
+		if err := rows.Scan(&item.TrackerID, &item.SourceKey, &item.SourceURL, &sourceItemID); err != nil {
+			return nil, fmt.Errorf("scan tracker row: %w", err)
+		}
+		if value := strings.TrimSpace(sourceItemID.String); sourceItemID.Valid && value != "" {
+			item.SourceItemID = &value
+		}
+		if strings.TrimSpace(item.SourceURL) == "" {
+			continue
+		}
+		item.CoverKey = linkcache.CoverKey(item.SourceKey, item.SourceURL, item.SourceItemID)
+		if seen[item.CoverKey] {
+			continue
+		}
+		seen[item.CoverKey] = true
+		covers = append(covers, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tracker rows: %w", err)
+	}
+
+	return covers, nil
+}
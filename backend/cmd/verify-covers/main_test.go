@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/imageprobe"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+	"github.com/gabriel/cross-site-tracker/backend/internal/pacing"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// coverConnectorStub resolves each series to the cover its covers map
+// names, by the series URL's last path segment.
+type coverConnectorStub struct {
+	covers map[string]string
+}
+
+func (coverConnectorStub) Key() string                       { return "mgeko" }
+func (coverConnectorStub) Name() string                      { return "Mgeko" }
+func (coverConnectorStub) Kind() string                      { return connectors.KindNative }
+func (coverConnectorStub) HealthCheck(context.Context) error { return nil }
+
+func (s coverConnectorStub) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	slug := rawURL[strings.LastIndex(strings.TrimSuffix(rawURL, "/"), "/")+1:]
+	return &connectors.MangaResult{SourceKey: "mgeko", URL: rawURL, CoverImageURL: s.covers[strings.TrimSuffix(slug, "/")]}, nil
+}
+
+func (coverConnectorStub) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+
+func setupVerifyTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "verify.sqlite"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := database.ApplyMigrations(db, filepath.Join("..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+	return db
+}
+
+func TestVerifyCoversRefreshesOrDropsDeadCovers(t *testing.T) {
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/live/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/webp")
+	}))
+	defer cdn.Close()
+
+	db := setupVerifyTestDB(t)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	verifiedRecently := now.Add(-24 * time.Hour)
+	stored := []struct {
+		slug       string
+		profileID  int64
+		cover      string
+		verifiedAt *time.Time
+	}{
+		{slug: "alive", profileID: 1, cover: cdn.URL + "/live/alive.webp"},
+		{slug: "rotated", profileID: 1, cover: cdn.URL + "/old/rotated.webp"},
+		{slug: "gone", profileID: 1, cover: cdn.URL + "/old/gone.webp"},
+		{slug: "recent", profileID: 1, cover: cdn.URL + "/old/recent.webp", verifiedAt: &verifiedRecently},
+		{slug: "other", profileID: 2, cover: cdn.URL + "/old/other.webp"},
+	}
+	for _, item := range stored {
+		sourceURL := "https://www.mgeko.cc/manga/" + item.slug + "/"
+		if _, err := db.Exec(`
+			INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+			VALUES (?, ?, (SELECT id FROM sources WHERE key = 'mgeko'), ?, 'reading')
+		`, item.profileID, item.slug, sourceURL); err != nil {
+			t.Fatalf("insert %s tracker: %v", item.slug, err)
+		}
+		if _, err := db.Exec(`
+			INSERT INTO link_cache (kind, cache_key, url, expires_at, last_verified_at) VALUES ('cover', ?, ?, ?, ?)
+		`, linkcache.CoverKey("mgeko", sourceURL, nil), item.cover, now.Add(48*time.Hour), item.verifiedAt); err != nil {
+			t.Fatalf("store %s cover: %v", item.slug, err)
+		}
+	}
+
+	registry := connectors.NewRegistry()
+	if err := registry.Register(coverConnectorStub{covers: map[string]string{
+		"rotated": cdn.URL + "/live/rotated-v2.webp",
+		"gone":    cdn.URL + "/old/gone.webp",
+	}}); err != nil {
+		t.Fatalf("register stub: %v", err)
+	}
+	store := repository.NewLinkCacheRepository(db)
+	// The stub CDN is on loopback, whose links the registry drops as
+	// unsafe, so the resolver is only given the registry's lookup.
+	lookup := struct{ linkcache.Connectors }{registry}
+	newVerifier := func(dryRun bool) *verifier {
+		return &verifier{
+			resolver:  linkcache.NewResolver(lookup, store, nil),
+			prober:    imageprobe.New(cdn.Client(), 0),
+			limiter:   pacing.NewLimiter(0),
+			olderThan: now.Add(-7 * 24 * time.Hour),
+			dryRun:    dryRun,
+			now:       func() time.Time { return now },
+		}
+	}
+	storedCover := func(slug string) repository.LinkCacheEntry {
+		t.Helper()
+		entry, _, err := store.GetEntry("cover", linkcache.CoverKey("mgeko", "https://www.mgeko.cc/manga/"+slug+"/", nil))
+		if err != nil {
+			t.Fatalf("read %s cover: %v", slug, err)
+		}
+		return entry
+	}
+
+	items, err := listStoredCovers(db, 1)
+	if err != nil {
+		t.Fatalf("list covers: %v", err)
+	}
+	if len(items) != 4 {
+		t.Fatalf("expected profile 1's four covers, got %+v", items)
+	}
+
+	want := summary{Covers: 4, Skipped: 1, OK: 1, Refreshed: 1, Unresolvable: 1}
+	if got := newVerifier(true).verifyAll(context.Background(), items, 2, 0); got != want {
+		t.Fatalf("dry run: expected %+v, got %+v", want, got)
+	}
+	if entry := storedCover("rotated"); entry.URL != cdn.URL+"/old/rotated.webp" || entry.LastVerifiedAt != nil {
+		t.Fatalf("expected a dry run to leave the store alone, got %+v", entry)
+	}
+
+	if got := newVerifier(false).verifyAll(context.Background(), items, 2, 0); got != want {
+		t.Fatalf("run: expected %+v, got %+v", want, got)
+	}
+	if entry := storedCover("alive"); entry.LastVerifiedAt == nil || !entry.LastVerifiedAt.Equal(now) {
+		t.Fatalf("expected the live cover marked verified, got %+v", entry)
+	}
+	if entry := storedCover("rotated"); entry.URL != cdn.URL+"/live/rotated-v2.webp" || entry.LastVerifiedAt == nil {
+		t.Fatalf("expected the rotated cover replaced and verified, got %+v", entry)
+	}
+	if entry := storedCover("gone"); entry.URL != "" {
+		t.Fatalf("expected the unresolvable cover dropped, got %+v", entry)
+	}
+	if entry := storedCover("recent"); entry.URL != cdn.URL+"/old/recent.webp" {
+		t.Fatalf("expected the recently verified cover left unchecked, got %+v", entry)
+	}
+
+	// A second run skips what was just verified or dropped.
+	again := summary{Covers: 4, Skipped: 4}
+	if got := newVerifier(false).verifyAll(context.Background(), items, 1, 0); got != again {
+		t.Fatalf("second run: expected %+v, got %+v", again, got)
+	}
+}
+
+func TestParseOlderThan(t *testing.T) {
+	if at, err := parseOlderThan(""); err != nil || !at.IsZero() {
+		t.Fatalf("expected empty to verify everything, got %v %v", at, err)
+	}
+	if at, err := parseOlderThan("2026-09-01"); err != nil || !at.Equal(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a UTC date, got %v %v", at, err)
+	}
+	if at, err := parseOlderThan("2026-09-01T10:00:00+02:00"); err != nil || !at.Equal(time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected an RFC 3339 time, got %v %v", at, err)
+	}
+	if _, err := parseOlderThan("last week"); err == nil {
+		t.Fatalf("expected an error for an unparseable date")
+	}
+}
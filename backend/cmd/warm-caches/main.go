@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	connectordefaults "github.com/gabriel/cross-site-tracker/backend/internal/connectors/defaults"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+	"github.com/gabriel/cross-site-tracker/backend/internal/pacing"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/selfcheck"
+)
+
+type trackerRecord struct {
+	ID                 int64
+	SourceKey          string
+	SourceURL          string
+	SourceItemID       *string
+	LatestKnownChapter *float64
+	LastReadChapter    *float64
+}
+
+// warmResult counts what one tracker's lookups did.
+type warmResult struct {
+	Cached   int
+	Resolved int
+	Missed   int
+}
+
+type summary struct {
+	Trackers int
+	Skipped  int
+	Cached   int
+	Resolved int
+	Missed   int
+}
+
+func main() {
+	var (
+		profileID      = flag.Int64("profile-id", 0, "Only warm a single profile id (0 = all)")
+		status         = flag.String("status", "", "Only warm trackers with this status (empty = all)")
+		limit          = flag.Int("limit", 0, "Limit number of trackers processed (0 = all)")
+		workers        = flag.Int("workers", 4, "Number of trackers warmed concurrently")
+		sourceInterval = flag.Duration("source-interval", time.Second, "Minimum time between lookups against the same source")
+		progressEvery  = flag.Int("progress-every", 25, "Log progress after every N trackers (0 = never)")
+		skipSelfCheck  = flag.Bool("skip-selfcheck", false, "Run without checking the migration and sqlite paths")
+	)
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+
+	if !*skipSelfCheck {
+		if report := selfcheck.Run(selfcheck.Paths{MigrationsDir: cfg.MigrationsPath, SQLitePath: cfg.SQLitePath}, nil); !report.OK() {
+			fmt.Fprint(os.Stderr, report)
+			os.Exit(1)
+		}
+	}
+
+	db, err := database.Open(cfg.SQLitePath)
+	if err != nil {
+		slog.Error("failed to open sqlite", "path", cfg.SQLitePath, "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if err := database.ApplyMigrations(db, cfg.MigrationsPath); err != nil {
+		slog.Error("failed to apply migrations", "error", err)
+		os.Exit(1)
+	}
+
+	store := repository.NewLinkCacheRepository(db)
+	if removed, err := store.DeleteExpired(time.Now().UTC()); err != nil {
+		slog.Warn("failed to prune expired links", "error", err)
+	} else if removed > 0 {
+		slog.Info("pruned expired links", "removed", removed)
+	}
+
+	registry, err := connectordefaults.NewRegistryWithProxies(connectordefaults.ProxyOptions{Default: cfg.ConnectorProxy, Overrides: cfg.ConnectorProxies})
+	if err != nil {
+		slog.Error("failed to set up connector proxies", "error", err)
+		os.Exit(1)
+	}
+	registry.SetStrictMediaHosts(cfg.StrictMediaHosts)
+	settingsRepo := repository.NewSettingsRepository(db)
+	resolver := linkcache.NewResolver(registry, store, func() error {
+		paused, err := settingsRepo.ScrapingPaused()
+		if err != nil || !paused {
+			return nil
+		}
+		return connectors.ErrScrapingPaused
+	})
+
+	items, err := listTrackersForWarming(db, *profileID, strings.TrimSpace(*status), *limit)
+	if err != nil {
+		slog.Error("failed to list trackers", "error", err)
+		os.Exit(1)
+	}
+	if len(items) == 0 {
+		slog.Info("no trackers found to warm", "profile_id", *profileID, "status", *status, "limit", *limit)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	startedAt := time.Now()
+	stats := warmAll(ctx, resolver, pacing.NewLimiter(*sourceInterval), items, max(*workers, 1), *progressEvery)
+	slog.Info(
+		"warm completed",
+		"trackers", stats.Trackers,
+		"skipped", stats.Skipped,
+		"already_cached", stats.Cached,
+		"resolved", stats.Resolved,
+		"missed", stats.Missed,
+		"interrupted", ctx.Err() != nil,
+		"duration", time.Since(startedAt).Round(time.Second),
+	)
+}
+
+func warmAll(ctx context.Context, resolver *linkcache.Resolver, limiter *pacing.Limiter, items []trackerRecord, workers int, progressEvery int) summary {
+	jobs := make(chan trackerRecord)
+	var (
+		mu    sync.Mutex
+		stats summary
+		wg    sync.WaitGroup
+	)
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				result, ok := warmTracker(ctx, resolver, limiter, item)
+
+				mu.Lock()
+				stats.Trackers++
+				if !ok {
+					stats.Skipped++
+				}
+				stats.Cached += result.Cached
+				stats.Resolved += result.Resolved
+				stats.Missed += result.Missed
+				if progressEvery > 0 && stats.Trackers%progressEvery == 0 {
+					slog.Info("warm progress", "done", stats.Trackers, "total", len(items), "resolved", stats.Resolved, "missed", stats.Missed)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, item := range items {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- item:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return stats
+}
+
+// warmTracker resolves the cover and the latest and last-read chapter URLs of
+// one tracker. ok is false when the tracker has nothing to look up.
+func warmTracker(ctx context.Context, resolver *linkcache.Resolver, limiter *pacing.Limiter, item trackerRecord) (warmResult, bool) {
+	var result warmResult
+	sourceURL := strings.TrimSpace(item.SourceURL)
+	if item.SourceKey == "" || sourceURL == "" {
+		return result, false
+	}
+
+	record := func(cached bool, err error) {
+		switch {
+		case cached:
+			result.Cached++
+		case err == nil:
+			result.Resolved++
+		default:
+			result.Missed++
+		}
+	}
+
+	coverKey := linkcache.CoverKey(item.SourceKey, sourceURL, item.SourceItemID)
+	_, _, cached := resolver.CachedCover(coverKey)
+	if !cached && limiter.Wait(ctx, item.SourceKey) != nil {
+		return result, true
+	}
+	_, err := resolver.Cover(ctx, item.SourceKey, sourceURL, item.SourceItemID)
+	record(cached, err)
+	if err != nil {
+		slog.Debug("cover lookup failed", "tracker_id", item.ID, "source_key", item.SourceKey, "error", err)
+	}
+
+	chapters := make([]float64, 0, 2)
+	for _, chapter := range []*float64{item.LatestKnownChapter, item.LastReadChapter} {
+		if chapter == nil || (len(chapters) > 0 && chapters[0] == *chapter) {
+			continue
+		}
+		chapters = append(chapters, *chapter)
+	}
+	for _, chapter := range chapters {
+		_, _, cached := resolver.CachedChapterURL(linkcache.ChapterURLKey(item.SourceKey, sourceURL, chapter))
+		if !cached && limiter.Wait(ctx, item.SourceKey) != nil {
+			return result, true
+		}
+		_, err := resolver.ChapterURL(ctx, item.SourceKey, sourceURL, chapter)
+		record(cached, err)
+		if err != nil {
+			slog.Debug("chapter url lookup failed", "tracker_id", item.ID, "source_key", item.SourceKey, "chapter", chapter, "error", err)
+		}
+	}
+
+	return result, true
+}
+
+func listTrackersForWarming(db *sql.DB, profileID int64, status string, limit int) ([]trackerRecord, error) {
+	queryBuilder := strings.Builder{}
+	queryBuilder.WriteString(`
+		SELECT
+			t.id,
+			s.key,
+			t.source_url,
+			t.source_item_id,
+			t.latest_known_chapter,
+			t.last_read_chapter
+		FROM trackers t
+		INNER JOIN sources s ON s.id = t.source_id
+		WHERE s.enabled = 1
+	`)
+
+	args := make([]any, 0, 3)
+	if profileID > 0 {
+		queryBuilder.WriteString(` AND t.profile_id = ?`)
+		args = append(args, profileID)
+	}
+	if status != "" {
+		queryBuilder.WriteString(` AND t.status = ?`)
+		args = append(args, status)
+	}
+
+	queryBuilder.WriteString(` ORDER BY t.updated_at DESC, t.id ASC`)
+	if limit > 0 {
+		queryBuilder.WriteString(` LIMIT ?`)
+		args = append(args, limit)
+	}
+
+	rows, err := db.Query(queryBuilder.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query trackers: %w", err)
+	}
+	defer rows.Close()
+
+	trackers := make([]trackerRecord, 0)
+	for rows.Next() {
+		var (
+			item         trackerRecord
+			sourceItemID sql.NullString
+			latest       sql.NullFloat64
+			lastRead     sql.NullFloat64
+		)
+		if err := rows.Scan(&item.ID, &item.SourceKey, &item.SourceURL, &sourceItemID, &latest, &lastRead); err != nil {
+			return nil, fmt.Errorf("scan tracker row: %w", err)
+		}
+		if value := strings.TrimSpace(sourceItemID.String); sourceItemID.Valid && value != "" {
+			item.SourceItemID = &value
+		}
+		if latest.Valid {
+			item.LatestKnownChapter = &latest.Float64
+		}
+		if lastRead.Valid {
+			item.LastReadChapter = &lastRead.Float64
+		}
+		trackers = append(trackers, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tracker rows: %w", err)
+	}
+
+	return trackers, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+	"github.com/gabriel/cross-site-tracker/backend/internal/pacing"
+)
+
+type warmConnectorStub struct {
+	calls *atomic.Int64
+}
+
+func (warmConnectorStub) Key() string                       { return "mgeko" }
+func (warmConnectorStub) Name() string                      { return "Mgeko" }
+func (warmConnectorStub) Kind() string                      { return connectors.KindNative }
+func (warmConnectorStub) HealthCheck(context.Context) error { return nil }
+
+func (s warmConnectorStub) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	s.calls.Add(1)
+	return &connectors.MangaResult{SourceKey: "mgeko", URL: rawURL, CoverImageURL: rawURL + "cover.jpg"}, nil
+}
+
+func (warmConnectorStub) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+
+func (s warmConnectorStub) ResolveChapterURL(_ context.Context, rawURL string, _ float64) (string, error) {
+	s.calls.Add(1)
+	return rawURL + "chapter/", nil
+}
+
+func TestWarmTrackerResolvesCoverAndChapterURLsOnce(t *testing.T) {
+	var calls atomic.Int64
+	registry := connectors.NewRegistry()
+	if err := registry.Register(warmConnectorStub{calls: &calls}); err != nil {
+		t.Fatalf("register stub: %v", err)
+	}
+	resolver := linkcache.NewResolver(registry, nil, nil)
+	latest, lastRead := 12.0, 10.0
+	item := trackerRecord{
+		ID:                 1,
+		SourceKey:          "mgeko",
+		SourceURL:          "https://www.mgeko.cc/manga/warm-series/",
+		LatestKnownChapter: &latest,
+		LastReadChapter:    &lastRead,
+	}
+
+	result, ok := warmTracker(context.Background(), resolver, pacing.NewLimiter(0), item)
+	if !ok || result.Resolved != 3 || result.Cached != 0 || result.Missed != 0 {
+		t.Fatalf("expected cover and two chapter urls resolved, got %+v ok=%v", result, ok)
+	}
+	if _, found, cached := resolver.CachedChapterURL(linkcache.ChapterURLKey("mgeko", item.SourceURL, 10)); !cached || !found {
+		t.Fatalf("expected last-read chapter url to be cached")
+	}
+
+	result, _ = warmTracker(context.Background(), resolver, pacing.NewLimiter(0), item)
+	if result.Cached != 3 || result.Resolved != 0 {
+		t.Fatalf("expected second pass to hit the cache, got %+v", result)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 connector calls, got %d", got)
+	}
+
+	if _, ok := warmTracker(context.Background(), resolver, pacing.NewLimiter(0), trackerRecord{ID: 2, SourceKey: "mgeko"}); ok {
+		t.Fatalf("expected tracker without a source url to be skipped")
+	}
+}
@@ -0,0 +1,228 @@
+// Package backup writes timestamped copies of the SQLite database with
+// VACUUM INTO, on a schedule or on demand, and prunes the oldest copies.
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+)
+
+// ErrAlreadyRunning is returned when a backup is requested while another one
+// is still being written.
+var ErrAlreadyRunning = errors.New("a backup is already running")
+
+const (
+	filePrefix = "backup-"
+	fileSuffix = ".sqlite"
+	// nameLayout sorts lexically in time order; milliseconds keep two
+	// on-demand backups in the same second apart.
+	nameLayout = "20060102-150405.000"
+)
+
+// runMu is shared by every Job, so the scheduled job and the on-demand
+// endpoint never write backups at the same time.
+var runMu sync.Mutex
+
+// File is one backup on disk.
+type File struct {
+	Name      string    `json:"name"`
+	SizeBytes int64     `json:"sizeBytes"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Job struct {
+	db        *sql.DB
+	dir       string
+	interval  time.Duration
+	keepCount int
+	logger    *slog.Logger
+	now       func() time.Time
+	stopCh    chan struct{}
+}
+
+type JobConfig struct {
+	// Dir is where backup files are written; it is created when missing.
+	Dir string
+	// Interval is the time between scheduled backups.
+	Interval time.Duration
+	// KeepCount is how many of the newest backups are kept; older ones are
+	// deleted after each backup.
+	KeepCount int
+}
+
+func JobConfigFrom(cfg config.Config) JobConfig {
+	return JobConfig{
+		Dir:       cfg.BackupDir,
+		Interval:  time.Duration(cfg.BackupIntervalHours) * time.Hour,
+		KeepCount: cfg.BackupKeepCount,
+	}
+}
+
+func NewJob(db *sql.DB, cfg JobConfig, logger *slog.Logger) *Job {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	if cfg.KeepCount <= 0 {
+		cfg.KeepCount = 7
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Job{
+		db:        db,
+		dir:       cfg.Dir,
+		interval:  cfg.Interval,
+		keepCount: cfg.KeepCount,
+		logger:    logger,
+		now:       time.Now,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start runs a backup every interval. When the newest backup is already older
+// than the interval (or there is none), the first one is written right away.
+func (j *Job) Start(ctx context.Context) {
+	j.logger.Info("backup job started", "interval", j.interval.String(), "dir", j.dir, "keep", j.keepCount)
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		if j.due() {
+			j.runLogged(ctx)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				j.logger.Info("backup job stopped")
+				close(j.stopCh)
+				return
+			case <-ticker.C:
+				j.runLogged(ctx)
+			}
+		}
+	}()
+}
+
+func (j *Job) StopWait(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	select {
+	case <-j.stopCh:
+	case <-time.After(timeout):
+	}
+}
+
+func (j *Job) due() bool {
+	files, err := j.List()
+	if err != nil || len(files) == 0 {
+		return true
+	}
+	return j.now().Sub(files[0].CreatedAt) >= j.interval
+}
+
+func (j *Job) runLogged(ctx context.Context) {
+	file, err := j.RunOnce(ctx)
+	if err != nil {
+		j.logger.Warn("database backup failed", "error", err)
+		return
+	}
+	j.logger.Info("database backup written", "file", file.Name, "bytes", file.SizeBytes)
+}
+
+// RunOnce writes one backup and prunes the oldest beyond the keep count. It
+// returns ErrAlreadyRunning instead of waiting when a backup is in progress.
+func (j *Job) RunOnce(ctx context.Context) (File, error) {
+	if !runMu.TryLock() {
+		return File{}, ErrAlreadyRunning
+	}
+	defer runMu.Unlock()
+
+	if err := os.MkdirAll(j.dir, 0o755); err != nil {
+		return File{}, fmt.Errorf("create backup dir: %w", err)
+	}
+
+	createdAt := j.now().UTC()
+	name := filePrefix + createdAt.Format(nameLayout) + fileSuffix
+	target := filepath.Join(j.dir, name)
+	// VACUUM INTO writes a partial file, so it goes to a temporary name that
+	// List and pruning ignore until it is complete.
+	partial := target + ".partial"
+	_ = os.Remove(partial)
+	if _, err := j.db.ExecContext(ctx, `VACUUM INTO ?`, partial); err != nil {
+		_ = os.Remove(partial)
+		return File{}, fmt.Errorf("write backup: %w", err)
+	}
+	if err := os.Rename(partial, target); err != nil {
+		_ = os.Remove(partial)
+		return File{}, fmt.Errorf("finish backup: %w", err)
+	}
+
+	info, err := os.Stat(target)
+	if err != nil {
+		return File{}, fmt.Errorf("stat backup: %w", err)
+	}
+
+	if err := j.prune(); err != nil {
+		j.logger.Warn("prune backups failed", "error", err)
+	}
+
+	return File{Name: name, SizeBytes: info.Size(), CreatedAt: createdAt}, nil
+}
+
+// List returns the backups in the directory, newest first.
+func (j *Job) List() ([]File, error) {
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []File{}, nil
+		}
+		return nil, fmt.Errorf("read backup dir: %w", err)
+	}
+
+	files := make([]File, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		createdAt, err := time.Parse(nameLayout, strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, File{Name: name, SizeBytes: info.Size(), CreatedAt: createdAt.UTC()})
+	}
+
+	sort.Slice(files, func(a, b int) bool {
+		return files[a].CreatedAt.After(files[b].CreatedAt)
+	})
+	return files, nil
+}
+
+func (j *Job) prune() error {
+	files, err := j.List()
+	if err != nil {
+		return err
+	}
+	for _, file := range files[min(j.keepCount, len(files)):] {
+		if err := os.Remove(filepath.Join(j.dir, file.Name)); err != nil {
+			return fmt.Errorf("remove old backup: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+)
+
+func openSourceDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := database.Open(filepath.Join(t.TempDir(), "app.sqlite"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	if _, err := db.Exec(`CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT NOT NULL)`); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO notes (body) VALUES ('first'), ('second')`); err != nil {
+		t.Fatalf("seed notes: %v", err)
+	}
+	return db
+}
+
+func TestRunOnceWritesOpenableBackup(t *testing.T) {
+	db := openSourceDB(t)
+	dir := filepath.Join(t.TempDir(), "backups")
+	job := NewJob(db, JobConfig{Dir: dir, KeepCount: 3}, nil)
+
+	file, err := job.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("run backup: %v", err)
+	}
+	if file.SizeBytes <= 0 {
+		t.Fatalf("expected a non-empty backup, got %+v", file)
+	}
+
+	backupDB, err := sql.Open("sqlite", filepath.Join(dir, file.Name))
+	if err != nil {
+		t.Fatalf("open backup: %v", err)
+	}
+	defer backupDB.Close()
+
+	var integrity string
+	if err := backupDB.QueryRow(`PRAGMA integrity_check`).Scan(&integrity); err != nil || integrity != "ok" {
+		t.Fatalf("expected backup to pass integrity check, got %q %v", integrity, err)
+	}
+	var count int
+	if err := backupDB.QueryRow(`SELECT COUNT(*) FROM notes`).Scan(&count); err != nil || count != 2 {
+		t.Fatalf("expected 2 rows in backup, got %d %v", count, err)
+	}
+
+	files, err := job.List()
+	if err != nil || len(files) != 1 || files[0].Name != file.Name {
+		t.Fatalf("expected the backup to be listed, got %+v %v", files, err)
+	}
+}
+
+func TestRunOncePrunesBeyondKeepCount(t *testing.T) {
+	db := openSourceDB(t)
+	dir := t.TempDir()
+	job := NewJob(db, JobConfig{Dir: dir, KeepCount: 2}, nil)
+
+	start := time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC)
+	names := make([]string, 0, 4)
+	for index := 0; index < 4; index++ {
+		at := start.Add(time.Duration(index) * time.Hour)
+		job.now = func() time.Time { return at }
+		file, err := job.RunOnce(context.Background())
+		if err != nil {
+			t.Fatalf("run backup %d: %v", index, err)
+		}
+		names = append(names, file.Name)
+	}
+	// Unrelated files in the directory are left alone.
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep me"), 0o644); err != nil {
+		t.Fatalf("write unrelated file: %v", err)
+	}
+
+	files, err := job.List()
+	if err != nil {
+		t.Fatalf("list backups: %v", err)
+	}
+	if len(files) != 2 || files[0].Name != names[3] || files[1].Name != names[2] {
+		t.Fatalf("expected the two newest backups, got %+v", files)
+	}
+	if _, err := os.Stat(filepath.Join(dir, names[0])); !os.IsNotExist(err) {
+		t.Fatalf("expected oldest backup to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatalf("expected unrelated file to survive, got %v", err)
+	}
+}
+
+func TestRunOnceRefusesConcurrentRuns(t *testing.T) {
+	db := openSourceDB(t)
+	job := NewJob(db, JobConfig{Dir: t.TempDir()}, nil)
+
+	runMu.Lock()
+	_, err := job.RunOnce(context.Background())
+	runMu.Unlock()
+	if !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("expected ErrAlreadyRunning, got %v", err)
+	}
+}
+
+func TestDueFollowsNewestBackup(t *testing.T) {
+	db := openSourceDB(t)
+	job := NewJob(db, JobConfig{Dir: t.TempDir(), Interval: 6 * time.Hour}, nil)
+	if !job.due() {
+		t.Fatalf("expected a backup to be due with none written")
+	}
+
+	written := time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC)
+	job.now = func() time.Time { return written }
+	if _, err := job.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run backup: %v", err)
+	}
+
+	job.now = func() time.Time { return written.Add(time.Hour) }
+	if job.due() {
+		t.Fatalf("expected no backup due an hour after the last one")
+	}
+	job.now = func() time.Time { return written.Add(7 * time.Hour) }
+	if !job.due() {
+		t.Fatalf("expected a backup due once the interval passed")
+	}
+}
@@ -3,16 +3,23 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"net"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Environment     string
-	AppName         string
-	Port            string
+	Environment string
+	AppName     string
+	Port        string
+	// BasePath is the URL prefix the app is mounted under (for example
+	// "/tracker" behind a reverse proxy). Empty means the root.
+	BasePath        string
 	LogLevel        slog.Level
 	SQLitePath      string
 	MigrationsPath  string
@@ -22,6 +29,99 @@ type Config struct {
 	// PollingIdleMinutes is the minimum minutes between polls for trackers
 	// that are not in "reading" status.
 	PollingIdleMinutes int
+	SMTPHost           string
+	SMTPPort           int
+	SMTPUsername       string
+	SMTPPassword       string
+	SMTPFrom           string
+	// DashboardPassword, when set, requires a login before the dashboard
+	// can be used. Empty keeps the dashboard open.
+	DashboardPassword string
+	// SessionSecret signs dashboard session cookies. When empty a random
+	// secret is generated at startup, so sessions end on restart.
+	SessionSecret string
+	// ReadOnly blocks every change made through the dashboard and API, for
+	// instances shown on a shared screen. The poller and background jobs
+	// still run. A single browser can be put in read-only mode with
+	// ?kiosk=1 instead.
+	ReadOnly bool
+	// ScrapeRateLimitPerMinute caps how many requests each client IP may
+	// make per minute to endpoints that search or resolve on a source. 0
+	// disables the limit.
+	ScrapeRateLimitPerMinute int
+	// TrustedProxies are the reverse proxies, as IPs or CIDR ranges, whose
+	// X-Forwarded-For header gives the client IP, which the rate limit and
+	// request log use. Empty uses the connection's address.
+	TrustedProxies []string
+	// CoverThumbnailStorage is where grid-view cover thumbnails are kept:
+	// "off" (covers are hotlinked), "disk" (CoverThumbnailDir) or "db" (the
+	// SQLite database, for read-only containers).
+	CoverThumbnailStorage string
+	CoverThumbnailDir     string
+	// BackupEnabled starts a job that copies the database into BackupDir
+	// every BackupIntervalHours, keeping the newest BackupKeepCount copies.
+	// On-demand backups through the API work either way.
+	BackupEnabled       bool
+	BackupDir           string
+	BackupIntervalHours int
+	BackupKeepCount     int
+	// MangaDexClientID and MangaDexClientSecret are a MangaDex personal API
+	// client, used to link profiles to MangaDex accounts and pull their
+	// read chapters. MangaDexTokenSecret encrypts the stored refresh
+	// tokens; all three are needed for the integration.
+	MangaDexClientID     string
+	MangaDexClientSecret string
+	MangaDexTokenSecret  string
+	MangaDexSyncHours    int
+	// DeepHealthCheckEnabled starts a job that resolves each connector's
+	// canary series every DeepHealthCheckHours and notes the sources that
+	// fail. GET /v1/connectors/health?deep=1 works either way.
+	DeepHealthCheckEnabled bool
+	DeepHealthCheckHours   int
+	// ConnectorProxy is the proxy connectors send their requests through
+	// (CONNECTOR_PROXY); nil connects direct. ConnectorProxies overrides it
+	// per connector key from CONNECTOR_PROXY_<KEY>, where a nil entry, set
+	// with "direct", skips the proxy for that connector.
+	ConnectorProxy   *url.URL
+	ConnectorProxies map[string]*url.URL
+	// StrictMediaHosts also drops cover and chapter links a connector
+	// scraped that are not on the site's own hosts or the CDNs it declares,
+	// so a tampered page cannot point the app at another host. Links that
+	// are not http(s) or point at the local network are always dropped.
+	StrictMediaHosts bool
+	// PollingMinDelayMS and PollingMaxDelayMS bound the wait between the
+	// trackers of a poll cycle, which grows while the host's load per CPU
+	// is at or above PollingLoadThreshold. A max of 0 turns pacing off.
+	PollingMinDelayMS    int
+	PollingMaxDelayMS    int
+	PollingLoadThreshold float64
+	// PollingAutoPause slows polling down on installs nobody uses: after
+	// PollingAutoPauseDays without a dashboard or API request, cycles run
+	// every PollingAutoPauseFactor intervals, or not at all when the factor
+	// is 0, until the next request.
+	PollingAutoPause       bool
+	PollingAutoPauseDays   int
+	PollingAutoPauseFactor int
+	// RecentAdditionsDays is how long a new tracker without tags or a
+	// second linked source is listed in the dashboard's recently added
+	// strip. 0 turns the strip off.
+	RecentAdditionsDays int
+	// DebugTools shows troubleshooting views in the dashboard, such as why
+	// a tracker is or is not in the filtered list.
+	DebugTools bool
+	// RetentionBatchSize caps the rows one pruning statement deletes, so a
+	// large backlog is cleared in short write locks. Retention overrides
+	// the registry defaults per table from RETENTION_<TABLE>_DAYS and
+	// RETENTION_<TABLE>_MAX_ROWS; see the retention package.
+	RetentionBatchSize int
+	Retention          map[string]RetentionOverride
+}
+
+// RetentionOverride is one table's retention as set in the environment.
+// A nil field keeps the table's default and 0 turns that limit off.
+type RetentionOverride struct {
+	Days    *int
+	MaxRows *int
 }
 
 func Load() (Config, error) {
@@ -31,13 +131,44 @@ func Load() (Config, error) {
 		Environment:        getEnv("APP_ENV", "development"),
 		AppName:            getEnv("APP_NAME", "cross-site-tracker"),
 		Port:               getEnv("APP_PORT", "8080"),
+		BasePath:           normalizeBasePath(getEnv("BASE_PATH", "")),
 		SQLitePath:         getEnv("SQLITE_PATH", "./data/app.sqlite"),
 		MigrationsPath:     getEnv("MIGRATIONS_PATH", "./migrations"),
 		SeedDefaultData:    getEnvAsBool("SEED_DEFAULT_DATA", true),
 		PollingEnabled:     getEnvAsBool("POLLING_ENABLED", true),
 		PollingMinutes:     getEnvAsInt("POLLING_MINUTES", 30),
 		PollingIdleMinutes: getEnvAsInt("POLLING_IDLE_MINUTES", 720),
+		SMTPHost:           getEnv("SMTP_HOST", ""),
+		SMTPPort:           getEnvAsInt("SMTP_PORT", 587),
+		SMTPUsername:       getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:       getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:           getEnv("SMTP_FROM", ""),
+		DashboardPassword:  getEnv("DASHBOARD_PASSWORD", ""),
+		SessionSecret:      getEnv("SESSION_SECRET", ""),
+		ReadOnly:           getEnvAsBool("READ_ONLY", false),
 	}
+	cfg.PollingAutoPause = getEnvAsBool("POLLING_AUTO_PAUSE", false)
+	cfg.PollingAutoPauseDays = getEnvAsInt("POLLING_AUTO_PAUSE_DAYS", 7)
+	cfg.PollingAutoPauseFactor = getEnvAsInt("POLLING_AUTO_PAUSE_FACTOR", 4)
+	cfg.PollingMinDelayMS = getEnvAsInt("POLLING_MIN_DELAY_MS", 0)
+	cfg.PollingMaxDelayMS = getEnvAsInt("POLLING_MAX_DELAY_MS", 5000)
+	cfg.PollingLoadThreshold = getEnvAsFloat("POLLING_LOAD_THRESHOLD", 0.8)
+	cfg.ScrapeRateLimitPerMinute = getEnvAsInt("SCRAPE_RATE_LIMIT_PER_MINUTE", 0)
+	cfg.CoverThumbnailDir = getEnv("COVER_THUMBNAIL_DIR", "./data/thumbnails")
+	cfg.BackupEnabled = getEnvAsBool("BACKUP_ENABLED", false)
+	cfg.BackupDir = getEnv("BACKUP_DIR", "./data/backups")
+	cfg.BackupIntervalHours = getEnvAsInt("BACKUP_INTERVAL_HOURS", 24)
+	cfg.BackupKeepCount = getEnvAsInt("BACKUP_KEEP_COUNT", 7)
+	cfg.MangaDexClientID = getEnv("MANGADEX_CLIENT_ID", "")
+	cfg.MangaDexClientSecret = getEnv("MANGADEX_CLIENT_SECRET", "")
+	cfg.MangaDexTokenSecret = getEnv("MANGADEX_TOKEN_SECRET", "")
+	cfg.MangaDexSyncHours = getEnvAsInt("MANGADEX_SYNC_HOURS", 6)
+	cfg.DeepHealthCheckEnabled = getEnvAsBool("DEEP_HEALTH_CHECK_ENABLED", false)
+	cfg.DeepHealthCheckHours = getEnvAsInt("DEEP_HEALTH_CHECK_HOURS", 24)
+	cfg.StrictMediaHosts = getEnvAsBool("STRICT_MEDIA_HOSTS", false)
+	cfg.RecentAdditionsDays = getEnvAsInt("RECENT_ADDITIONS_DAYS", 7)
+	cfg.DebugTools = getEnvAsBool("DEBUG_TOOLS", false)
+	cfg.RetentionBatchSize = getEnvAsInt("RETENTION_BATCH_SIZE", 500)
 
 	if cfg.PollingMinutes <= 0 {
 		cfg.PollingMinutes = 30
@@ -45,6 +176,42 @@ func Load() (Config, error) {
 	if cfg.PollingIdleMinutes <= 0 {
 		cfg.PollingIdleMinutes = 720
 	}
+	if cfg.PollingAutoPauseDays <= 0 {
+		cfg.PollingAutoPauseDays = 7
+	}
+	if cfg.PollingAutoPauseFactor < 0 {
+		cfg.PollingAutoPauseFactor = 0
+	}
+	if cfg.PollingMinDelayMS < 0 {
+		cfg.PollingMinDelayMS = 0
+	}
+	if cfg.PollingMaxDelayMS < 0 {
+		cfg.PollingMaxDelayMS = 0
+	}
+	if cfg.PollingLoadThreshold <= 0 {
+		cfg.PollingLoadThreshold = 0.8
+	}
+	if cfg.SMTPPort <= 0 {
+		cfg.SMTPPort = 587
+	}
+	if cfg.BackupIntervalHours <= 0 {
+		cfg.BackupIntervalHours = 24
+	}
+	if cfg.BackupKeepCount <= 0 {
+		cfg.BackupKeepCount = 7
+	}
+	if cfg.MangaDexSyncHours <= 0 {
+		cfg.MangaDexSyncHours = 6
+	}
+	if cfg.DeepHealthCheckHours <= 0 {
+		cfg.DeepHealthCheckHours = 24
+	}
+	if cfg.RecentAdditionsDays < 0 {
+		cfg.RecentAdditionsDays = 0
+	}
+	if cfg.RetentionBatchSize <= 0 {
+		cfg.RetentionBatchSize = 500
+	}
 
 	level, err := parseLogLevel(getEnv("LOG_LEVEL", "INFO"))
 	if err != nil {
@@ -52,9 +219,62 @@ func Load() (Config, error) {
 	}
 	cfg.LogLevel = level
 
+	storage, err := parseCoverThumbnailStorage(getEnv("COVER_THUMBNAIL_STORAGE", "off"))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.CoverThumbnailStorage = storage
+
+	if raw := strings.TrimSpace(getEnv("CONNECTOR_PROXY", "")); raw != "" {
+		proxy, err := parseProxyURL("CONNECTOR_PROXY", raw)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.ConnectorProxy = proxy
+	}
+	proxies, err := parseConnectorProxies(os.Environ())
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.ConnectorProxies = proxies
+
+	retention, err := parseRetentionOverrides(os.Environ())
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Retention = retention
+
+	trustedProxies, err := parseTrustedProxies(getEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.TrustedProxies = trustedProxies
+
 	return cfg, nil
 }
 
+// SMTPConfigured reports whether enough SMTP settings are present to send
+// email digests.
+func (c Config) SMTPConfigured() bool {
+	return strings.TrimSpace(c.SMTPHost) != "" && strings.TrimSpace(c.SMTPFrom) != ""
+}
+
+// MangaDexConfigured reports whether profiles can be linked to MangaDex
+// accounts.
+func (c Config) MangaDexConfigured() bool {
+	return strings.TrimSpace(c.MangaDexClientID) != "" &&
+		strings.TrimSpace(c.MangaDexClientSecret) != "" &&
+		strings.TrimSpace(c.MangaDexTokenSecret) != ""
+}
+
+func normalizeBasePath(raw string) string {
+	trimmed := strings.Trim(strings.TrimSpace(raw), "/")
+	if trimmed == "" {
+		return ""
+	}
+	return "/" + trimmed
+}
+
 func parseLogLevel(raw string) (slog.Level, error) {
 	switch raw {
 	case "DEBUG":
@@ -70,6 +290,133 @@ func parseLogLevel(raw string) (slog.Level, error) {
 	}
 }
 
+func parseCoverThumbnailStorage(raw string) (string, error) {
+	switch value := strings.ToLower(strings.TrimSpace(raw)); value {
+	case "off", "disk", "db":
+		return value, nil
+	default:
+		return "off", fmt.Errorf("invalid COVER_THUMBNAIL_STORAGE %q, expected off|disk|db", raw)
+	}
+}
+
+// parseTrustedProxies reads a comma-separated list of IPs and CIDR ranges.
+func parseTrustedProxies(raw string) ([]string, error) {
+	proxies := make([]string, 0)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q, expected an IP or CIDR range", entry)
+			}
+		} else if net.ParseIP(entry) == nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q, expected an IP or CIDR range", entry)
+		}
+		proxies = append(proxies, entry)
+	}
+	return proxies, nil
+}
+
+const connectorProxyEnvPrefix = "CONNECTOR_PROXY_"
+
+// parseConnectorProxies reads the per-connector proxy overrides out of
+// environ, keyed by the lower-cased connector key.
+func parseConnectorProxies(environ []string) (map[string]*url.URL, error) {
+	proxies := map[string]*url.URL{}
+	names := make([]string, 0)
+	values := map[string]string{}
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(name, connectorProxyEnvPrefix) || strings.TrimSpace(value) == "" {
+			continue
+		}
+		names = append(names, name)
+		values[name] = strings.TrimSpace(value)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimPrefix(name, connectorProxyEnvPrefix))
+		if key == "" {
+			continue
+		}
+		if strings.EqualFold(values[name], "direct") {
+			proxies[key] = nil
+			continue
+		}
+		proxy, err := parseProxyURL(name, values[name])
+		if err != nil {
+			return nil, err
+		}
+		proxies[key] = proxy
+	}
+	return proxies, nil
+}
+
+// parseProxyURL accepts http, https, socks5 and socks5h proxy URLs with a
+// host and nothing after it. Errors name the setting but not the URL, which
+// may hold a password.
+func parseProxyURL(name string, raw string) (*url.URL, error) {
+	proxy, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: not a URL", name)
+	}
+	switch strings.ToLower(proxy.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid %s: expected an http, https, socks5 or socks5h URL", name)
+	}
+	if proxy.Hostname() == "" {
+		return nil, fmt.Errorf("invalid %s: proxy host is required", name)
+	}
+	if strings.Trim(proxy.Path, "/") != "" || proxy.RawQuery != "" || proxy.Fragment != "" {
+		return nil, fmt.Errorf("invalid %s: proxy URL must not have a path or query", name)
+	}
+	return proxy, nil
+}
+
+const retentionEnvPrefix = "RETENTION_"
+
+// parseRetentionOverrides reads RETENTION_<TABLE>_DAYS and
+// RETENTION_<TABLE>_MAX_ROWS out of environ, keyed by the lower-cased
+// table name. Whether the table exists is left to the pruner.
+func parseRetentionOverrides(environ []string) (map[string]RetentionOverride, error) {
+	overrides := map[string]RetentionOverride{}
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(name, retentionEnvPrefix) || strings.TrimSpace(value) == "" {
+			continue
+		}
+		rest := strings.TrimPrefix(name, retentionEnvPrefix)
+		table, isDays := strings.CutSuffix(rest, "_DAYS")
+		if !isDays {
+			var isMaxRows bool
+			if table, isMaxRows = strings.CutSuffix(rest, "_MAX_ROWS"); !isMaxRows {
+				continue
+			}
+		}
+		if table == "" {
+			continue
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid %s %q, expected a whole number of 0 or more", name, value)
+		}
+
+		key := strings.ToLower(table)
+		override := overrides[key]
+		if isDays {
+			override.Days = &limit
+		} else {
+			override.MaxRows = &limit
+		}
+		overrides[key] = override
+	}
+	return overrides, nil
+}
+
 func getEnv(key string, fallback string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -101,3 +448,15 @@ func getEnvAsInt(key string, fallback int) int {
 	}
 	return parsed
 }
+
+func getEnvAsFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
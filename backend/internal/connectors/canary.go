@@ -0,0 +1,78 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Canary is a long-running series a connector should always resolve. A deep
+// health check resolves URL and checks the result against the invariants
+// below, which catches a site whose HTML changed under a parser that still
+// returns without an error.
+type Canary struct {
+	URL string `json:"url"`
+	// TitleContains, when set, must appear in the resolved title (ignoring
+	// case), so a placeholder title built from the URL does not pass.
+	TitleContains string `json:"titleContains,omitempty"`
+	// MinLatestChapter is the lowest latest chapter the series can plausibly
+	// report; anything below it, or no chapter at all, fails the check.
+	MinLatestChapter float64 `json:"minLatestChapter"`
+}
+
+// CanaryProvider is implemented by connectors that declare a Canary.
+type CanaryProvider interface {
+	Canary() Canary
+}
+
+// Canary invariants, named in CanaryResult.Invariant when one fails.
+const (
+	CanaryInvariantResolve       = "resolve"
+	CanaryInvariantTitle         = "title"
+	CanaryInvariantLatestChapter = "latest_chapter"
+)
+
+// CanaryResult is the outcome of resolving a connector's canary. Invariant
+// and Error describe the first invariant that failed.
+type CanaryResult struct {
+	URL       string `json:"url"`
+	Passed    bool   `json:"passed"`
+	Invariant string `json:"invariant,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CheckCanary resolves canary.URL with connector and checks the invariants
+// in order: the lookup succeeds, the title is not empty and contains
+// canary.TitleContains, and the latest chapter is at least
+// canary.MinLatestChapter.
+func CheckCanary(ctx context.Context, connector Connector, canary Canary) CanaryResult {
+	result := CanaryResult{URL: canary.URL}
+	fail := func(invariant string, format string, args ...any) CanaryResult {
+		result.Invariant = invariant
+		result.Error = fmt.Sprintf(format, args...)
+		return result
+	}
+
+	resolved, err := connector.ResolveByURL(ctx, canary.URL)
+	if err != nil {
+		return fail(CanaryInvariantResolve, "resolve failed: %v", err)
+	}
+	if resolved == nil {
+		return fail(CanaryInvariantResolve, "resolve returned no result")
+	}
+	if strings.TrimSpace(resolved.Title) == "" {
+		return fail(CanaryInvariantTitle, "title is empty")
+	}
+	if want := strings.TrimSpace(canary.TitleContains); want != "" && !strings.Contains(strings.ToLower(resolved.Title), strings.ToLower(want)) {
+		return fail(CanaryInvariantTitle, "title %q does not contain %q", resolved.Title, want)
+	}
+	if resolved.LatestChapter == nil {
+		return fail(CanaryInvariantLatestChapter, "latest chapter is missing")
+	}
+	if *resolved.LatestChapter < canary.MinLatestChapter {
+		return fail(CanaryInvariantLatestChapter, "latest chapter %g is below %g", *resolved.LatestChapter, canary.MinLatestChapter)
+	}
+
+	result.Passed = true
+	return result
+}
@@ -0,0 +1,91 @@
+package connectors_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+type canaryConnector struct {
+	fakeConnector
+	result *connectors.MangaResult
+	err    error
+	delay  time.Duration
+}
+
+func (c *canaryConnector) Canary() connectors.Canary {
+	return connectors.Canary{URL: "https://example.com/series/canary", TitleContains: "Canary", MinLatestChapter: 50}
+}
+
+func (c *canaryConnector) ResolveByURL(ctx context.Context, _ string) (*connectors.MangaResult, error) {
+	if c.delay > 0 {
+		select {
+		case <-time.After(c.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return c.result, c.err
+}
+
+func TestCheckCanaryNamesTheFailedInvariant(t *testing.T) {
+	chapter := func(value float64) *float64 { return &value }
+	for _, tc := range []struct {
+		name      string
+		result    *connectors.MangaResult
+		err       error
+		invariant string
+	}{
+		{name: "passes", result: &connectors.MangaResult{Title: "The Canary Song", LatestChapter: chapter(80)}},
+		{name: "resolve error", err: errors.New("unexpected status: 503"), invariant: connectors.CanaryInvariantResolve},
+		{name: "no result", invariant: connectors.CanaryInvariantResolve},
+		{name: "blank title", result: &connectors.MangaResult{Title: "  ", LatestChapter: chapter(80)}, invariant: connectors.CanaryInvariantTitle},
+		{name: "other title", result: &connectors.MangaResult{Title: "Series 123", LatestChapter: chapter(80)}, invariant: connectors.CanaryInvariantTitle},
+		{name: "no chapter", result: &connectors.MangaResult{Title: "canary song"}, invariant: connectors.CanaryInvariantLatestChapter},
+		{name: "chapter below floor", result: &connectors.MangaResult{Title: "Canary Song", LatestChapter: chapter(2)}, invariant: connectors.CanaryInvariantLatestChapter},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := &canaryConnector{result: tc.result, err: tc.err}
+			result := connectors.CheckCanary(context.Background(), conn, conn.Canary())
+			if result.Passed != (tc.invariant == "") || result.Invariant != tc.invariant {
+				t.Fatalf("expected failed invariant %q, got %+v", tc.invariant, result)
+			}
+			if !result.Passed && result.Error == "" {
+				t.Fatalf("expected a failure message, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestRegistryDeepHealthTimesOutEachConnector(t *testing.T) {
+	chapter := 80.0
+	r := connectors.NewRegistry()
+	_ = r.Register(&canaryConnector{fakeConnector: fakeConnector{key: "fast", kind: connectors.KindNative}, result: &connectors.MangaResult{Title: "Canary", LatestChapter: &chapter}})
+	_ = r.Register(&canaryConnector{fakeConnector: fakeConnector{key: "slow", kind: connectors.KindNative}, delay: time.Second})
+	_ = r.Register(&fakeConnector{key: "plain", kind: connectors.KindNative})
+
+	started := time.Now()
+	items := r.DeepHealth(context.Background(), 50*time.Millisecond)
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the slow connector to be cut off, took %v", elapsed)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 health items, got %d", len(items))
+	}
+	byKey := map[string]connectors.HealthStatus{}
+	for _, item := range items {
+		byKey[item.Key] = item
+	}
+	if fast := byKey["fast"]; !fast.Healthy || fast.Canary == nil || !fast.Canary.Passed {
+		t.Fatalf("expected the fast canary to pass, got %+v", fast)
+	}
+	if slow := byKey["slow"]; slow.Healthy || slow.Canary == nil || slow.Canary.Invariant != connectors.CanaryInvariantResolve {
+		t.Fatalf("expected the slow canary to fail to resolve, got %+v", slow)
+	}
+	if plain := byKey["plain"]; !plain.Healthy || plain.Canary != nil {
+		t.Fatalf("expected no canary for a connector without one, got %+v", plain)
+	}
+}
@@ -0,0 +1,110 @@
+package connectors
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxChapterNumber caps parsed chapter numbers. Anything larger is almost
+// certainly scraping garbage, such as an ID or a timestamp read as a chapter.
+const MaxChapterNumber = 100000
+
+// chapterGroupSeparators are the thousands separators ParseChapterNumber
+// accepts: comma, space, no-break space, thin space and narrow no-break space.
+const chapterGroupSeparators = ", \u00a0\u2009\u202f"
+
+// ParseChapterNumber parses a chapter number as typed by users or scraped from
+// sites. It accepts thousands separators ("1,047", "1 047") and a trailing
+// period ("1047."), and rejects signs, exponents, malformed grouping, more
+// than one decimal point and values above MaxChapterNumber.
+func ParseChapterNumber(raw string) (float64, error) {
+	value := strings.TrimSuffix(strings.TrimSpace(raw), ".")
+	whole, fraction, hasFraction := strings.Cut(value, ".")
+	if whole == "" || (hasFraction && (fraction == "" || !allDigits(fraction))) {
+		return 0, fmt.Errorf("invalid chapter number %q", raw)
+	}
+
+	groups := []string{""}
+	for _, r := range whole {
+		switch {
+		case r >= '0' && r <= '9':
+			groups[len(groups)-1] += string(r)
+		case strings.ContainsRune(chapterGroupSeparators, r) && groups[len(groups)-1] != "":
+			groups = append(groups, "")
+		default:
+			return 0, fmt.Errorf("invalid chapter number %q", raw)
+		}
+	}
+	if len(groups) > 1 {
+		for index, group := range groups {
+			if (index == 0 && len(group) > 3) || (index > 0 && len(group) != 3) {
+				return 0, fmt.Errorf("invalid chapter number %q", raw)
+			}
+		}
+	}
+
+	normalized := strings.Join(groups, "")
+	if hasFraction {
+		normalized += "." + fraction
+	}
+	parsed, err := strconv.ParseFloat(normalized, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chapter number %q", raw)
+	}
+	if parsed > MaxChapterNumber {
+		return 0, fmt.Errorf("chapter number %q exceeds %d", raw, MaxChapterNumber)
+	}
+	return parsed, nil
+}
+
+func allDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewestChaptersFirst orders chapters by number descending, keeps the first
+// entry seen for each chapter number, and trims the result to limit when
+// limit is positive. Release time breaks ties between duplicate numbers so
+// the most recent upload wins.
+func NewestChaptersFirst(items []ChapterInfo, limit int) []ChapterInfo {
+	sorted := make([]ChapterInfo, 0, len(items))
+	for _, item := range items {
+		if math.IsNaN(item.Number) || math.IsInf(item.Number, 0) || item.URL == "" {
+			continue
+		}
+		sorted = append(sorted, item)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Number != sorted[j].Number {
+			return sorted[i].Number > sorted[j].Number
+		}
+		if sorted[i].ReleasedAt == nil || sorted[j].ReleasedAt == nil {
+			return sorted[i].ReleasedAt != nil
+		}
+		return sorted[i].ReleasedAt.After(*sorted[j].ReleasedAt)
+	})
+
+	out := make([]ChapterInfo, 0, len(sorted))
+	for _, item := range sorted {
+		if len(out) > 0 && math.Abs(out[len(out)-1].Number-item.Number) <= 1e-9 {
+			continue
+		}
+		out = append(out, item)
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,57 @@
+package connectors_test
+
+import (
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+func TestParseChapterNumber(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    float64
+		wantErr bool
+	}{
+		{raw: "12", want: 12},
+		{raw: " 12.5 ", want: 12.5},
+		{raw: "1,047", want: 1047},
+		{raw: "1 047", want: 1047},
+		{raw: "1\u2009047", want: 1047},
+		{raw: "1\u202f047.5", want: 1047.5},
+		{raw: "12,345,6", wantErr: true},
+		{raw: "1047.", want: 1047},
+		{raw: "2026", want: 2026},
+		{raw: "100000", want: 100000},
+		{raw: "100001", wantErr: true},
+		{raw: "12..5", wantErr: true},
+		{raw: "1,04", wantErr: true},
+		{raw: "1234,567", wantErr: true},
+		{raw: ",047", wantErr: true},
+		{raw: "1,,047", wantErr: true},
+		{raw: ".", wantErr: true},
+		{raw: ".5", wantErr: true},
+		{raw: "-3", wantErr: true},
+		{raw: "1e3", wantErr: true},
+		{raw: "NaN", wantErr: true},
+		{raw: "12a", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.raw, func(t *testing.T) {
+			got, err := connectors.ParseChapterNumber(testCase.raw)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != testCase.want {
+				t.Fatalf("expected %v, got %v", testCase.want, got)
+			}
+		})
+	}
+}
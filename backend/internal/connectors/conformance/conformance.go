@@ -0,0 +1,249 @@
+// Package conformance holds the behaviour every native connector is expected
+// to share, as a test suite each connector's tests run against their own
+// fixtures. Site-specific parsing stays in the connector's own tests; the
+// suite only covers the contract the rest of the app relies on.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+// Fixture is one canned response served by the fake site.
+type Fixture struct {
+	// Status defaults to 200.
+	Status int
+	// ContentType defaults to text/html.
+	ContentType string
+	Body        string
+}
+
+// FixtureSet seeds the fake site and names the URLs the suite exercises.
+// Series URLs use the connector's real host; the factory is responsible for
+// pointing requests at the fake site's base URL.
+type FixtureSet struct {
+	// Routes maps request paths to responses. A key of the form
+	// "/path?query" matches that exact encoded query and wins over a bare
+	// "/path" key, which matches any query. Unlisted paths get a 404.
+	Routes map[string]Fixture
+	// SeriesURL resolves against Routes.
+	SeriesURL string
+	// SeriesTitle is what SeriesURL must resolve to. Fixtures should give the
+	// raw title HTML entities and stray whitespace so sanitization is checked.
+	SeriesTitle string
+	// MissingURL is a well-formed series URL whose pages are not in Routes.
+	MissingURL string
+	// WrongHostURL is a series-shaped URL on some other site.
+	WrongHostURL string
+	// SearchQuery must match at least two series in the search fixtures.
+	SearchQuery string
+}
+
+var leftoverEntityPattern = regexp.MustCompile(`&(?:#[0-9]+|#[xX][0-9a-fA-F]+|[a-zA-Z]+);`)
+
+// RunConformanceSuite runs the shared connector contract as subtests of t.
+// factory is called once per subtest with the fake site's base URL.
+func RunConformanceSuite(t *testing.T, factory func(baseURL string) connectors.Connector, fixtures FixtureSet) {
+	t.Helper()
+
+	newSite := func(t *testing.T) (connectors.Connector, *atomic.Int64) {
+		t.Helper()
+		var hits atomic.Int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			fixture, ok := fixtures.Routes[r.URL.Path+"?"+r.URL.RawQuery]
+			if !ok {
+				fixture, ok = fixtures.Routes[r.URL.Path]
+			}
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			contentType := fixture.ContentType
+			if contentType == "" {
+				contentType = "text/html; charset=utf-8"
+			}
+			status := fixture.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(fixture.Body))
+		}))
+		t.Cleanup(server.Close)
+		return factory(server.URL), &hits
+	}
+
+	t.Run("ResolveByURL", func(t *testing.T) {
+		conn, _ := newSite(t)
+		result, err := conn.ResolveByURL(context.Background(), fixtures.SeriesURL)
+		if err != nil {
+			t.Fatalf("resolve %s: %v", fixtures.SeriesURL, err)
+		}
+		if result == nil {
+			t.Fatalf("expected a result for %s", fixtures.SeriesURL)
+		}
+		if result.SourceKey != conn.Key() {
+			t.Fatalf("expected source key %q, got %q", conn.Key(), result.SourceKey)
+		}
+		if result.SourceItemID == "" || result.URL == "" {
+			t.Fatalf("expected source item id and url, got %+v", result)
+		}
+		if result.Title != fixtures.SeriesTitle {
+			t.Fatalf("expected title %q, got %q", fixtures.SeriesTitle, result.Title)
+		}
+	})
+
+	t.Run("ResolveByURLRejectsWrongHost", func(t *testing.T) {
+		conn, hits := newSite(t)
+		if _, err := conn.ResolveByURL(context.Background(), fixtures.WrongHostURL); err == nil {
+			t.Fatalf("expected %s to be rejected", fixtures.WrongHostURL)
+		}
+		if hits.Load() != 0 {
+			t.Fatalf("expected wrong-host url to be rejected without a request, got %d", hits.Load())
+		}
+	})
+
+	t.Run("CanaryIsOwnSeriesURL", func(t *testing.T) {
+		conn, hits := newSite(t)
+		provider, ok := conn.(connectors.CanaryProvider)
+		if !ok {
+			t.Skip("connector declares no canary")
+		}
+		canary := provider.Canary()
+		if canary.MinLatestChapter <= 0 {
+			t.Fatalf("expected a positive latest chapter floor, got %v", canary.MinLatestChapter)
+		}
+		if validator, ok := conn.(connectors.URLValidator); ok {
+			if _, err := validator.ValidateURL(canary.URL); err != nil {
+				t.Fatalf("expected canary %s to be a valid series url: %v", canary.URL, err)
+			}
+		}
+		if hits.Load() != 0 {
+			t.Fatalf("expected the canary to be checked without a request, got %d", hits.Load())
+		}
+	})
+
+	t.Run("ResolveByURLMissingPage", func(t *testing.T) {
+		conn, _ := newSite(t)
+		result, err := conn.ResolveByURL(context.Background(), fixtures.MissingURL)
+		if err == nil {
+			t.Fatalf("expected an error for missing series, got %+v", result)
+		}
+	})
+
+	t.Run("SearchByTitleRequiresQuery", func(t *testing.T) {
+		conn, hits := newSite(t)
+		for _, query := range []string{"", "   ", "?!"} {
+			if _, err := conn.SearchByTitle(context.Background(), query, 5); err == nil {
+				t.Fatalf("expected an error for query %q", query)
+			}
+		}
+		if hits.Load() != 0 {
+			t.Fatalf("expected empty queries to be rejected without a request, got %d", hits.Load())
+		}
+	})
+
+	t.Run("SearchByTitleClampsLimit", func(t *testing.T) {
+		conn, _ := newSite(t)
+		one, err := conn.SearchByTitle(context.Background(), fixtures.SearchQuery, 1)
+		if err != nil {
+			t.Fatalf("search with limit 1: %v", err)
+		}
+		if len(one) != 1 {
+			t.Fatalf("expected exactly 1 result with limit 1, got %d", len(one))
+		}
+
+		defaulted, err := conn.SearchByTitle(context.Background(), fixtures.SearchQuery, 0)
+		if err != nil {
+			t.Fatalf("search with limit 0: %v", err)
+		}
+		if len(defaulted) < 2 {
+			t.Fatalf("expected limit 0 to fall back to the default limit, got %d results", len(defaulted))
+		}
+
+		huge, err := conn.SearchByTitle(context.Background(), fixtures.SearchQuery, 10000)
+		if err != nil {
+			t.Fatalf("search with oversized limit: %v", err)
+		}
+		if len(huge) > 50 {
+			t.Fatalf("expected oversized limit to be capped at 50, got %d results", len(huge))
+		}
+		for _, item := range huge {
+			if item.SourceKey != conn.Key() {
+				t.Fatalf("expected source key %q, got %q", conn.Key(), item.SourceKey)
+			}
+		}
+	})
+
+	t.Run("ResolveChapterURLRejectsInvalidChapter", func(t *testing.T) {
+		conn, hits := newSite(t)
+		resolver, ok := conn.(connectors.ChapterURLResolver)
+		if !ok {
+			t.Skip("connector does not resolve chapter urls")
+		}
+		for _, chapter := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+			if _, err := resolver.ResolveChapterURL(context.Background(), fixtures.SeriesURL, chapter); err == nil {
+				t.Fatalf("expected chapter %v to be rejected", chapter)
+			}
+		}
+		if hits.Load() != 0 {
+			t.Fatalf("expected invalid chapters to be rejected without a request, got %d", hits.Load())
+		}
+	})
+
+	t.Run("HonoursContextCancellation", func(t *testing.T) {
+		conn, _ := newSite(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := conn.ResolveByURL(ctx, fixtures.SeriesURL); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected resolve to fail with context.Canceled, got %v", err)
+		}
+		if _, err := conn.SearchByTitle(ctx, fixtures.SearchQuery, 5); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected search to fail with context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("SanitizesTitles", func(t *testing.T) {
+		conn, _ := newSite(t)
+		titles := []string{}
+		if result, err := conn.ResolveByURL(context.Background(), fixtures.SeriesURL); err == nil && result != nil {
+			titles = append(titles, result.Title)
+			titles = append(titles, result.RelatedTitles...)
+		}
+		results, err := conn.SearchByTitle(context.Background(), fixtures.SearchQuery, 10)
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		for _, item := range results {
+			titles = append(titles, item.Title)
+			titles = append(titles, item.RelatedTitles...)
+		}
+		if len(titles) == 0 {
+			t.Fatalf("expected titles to check")
+		}
+
+		for _, title := range titles {
+			if title == "" || title != strings.TrimSpace(title) {
+				t.Fatalf("expected trimmed non-empty title, got %q", title)
+			}
+			if strings.ContainsAny(title, "\n\t<>") || strings.Contains(title, "  ") {
+				t.Fatalf("expected title without markup or runs of whitespace, got %q", title)
+			}
+			if leftoverEntityPattern.MatchString(title) {
+				t.Fatalf("expected html entities to be decoded, got %q", title)
+			}
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package conformance
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// ParseTimeLimit bounds one parse of a fuzz input. The regexes are linear,
+// so even a page at connectors.MaxPageBytes parses well inside it.
+const ParseTimeLimit = 5 * time.Second
+
+// AddAdversarialSeeds seeds f with fixtures plus pages that have broken
+// regex-based parsers before: unterminated tags, multi-byte text pressed
+// against link, and megabyte-long attribute values. link is a series or
+// chapter anchor in the connector's own markup.
+func AddAdversarialSeeds(f *testing.F, link string, fixtures ...string) {
+	f.Helper()
+	for _, fixture := range fixtures {
+		f.Add(fixture)
+	}
+
+	huge := strings.Repeat("x", 1<<20)
+	for _, page := range []string{
+		"",
+		link,
+		strings.TrimSuffix(link, ">"),
+		"<a href=\"" + huge,
+		"<div class=\"" + huge + "\">" + link + "</div>",
+		"<img src=\"" + huge + "\">" + link,
+		"漫画" + link + "第一話",
+		link + "ééé" + strings.Repeat(" ", 600) + "Chapter 1 047",
+		strings.Repeat(link, 2000),
+		strings.Repeat("<li class=\"", 5000),
+		"\xff\xfe" + link + "\xc3",
+	} {
+		f.Add(page)
+	}
+}
+
+// CheckParse runs parse on body and fails t if it panics or runs past
+// ParseTimeLimit.
+func CheckParse(t *testing.T, body string, parse func()) {
+	t.Helper()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			t.Fatalf("parse panicked on %q: %v", preview(body), recovered)
+		}
+	}()
+
+	started := time.Now()
+	parse()
+	if elapsed := time.Since(started); elapsed > ParseTimeLimit {
+		t.Fatalf("parse took %s on %d bytes: %q", elapsed, len(body), preview(body))
+	}
+}
+
+// CheckText fails t when body is valid UTF-8 but a string parsed out of it
+// is not, which means a slice split a multi-byte character.
+func CheckText(t *testing.T, body string, field string, value string) {
+	t.Helper()
+	if utf8.ValidString(body) && !utf8.ValidString(value) {
+		t.Fatalf("%s %q is not valid UTF-8, parsed from %q", field, value, preview(body))
+	}
+}
+
+func preview(body string) string {
+	if len(body) <= 200 {
+		return body
+	}
+	return body[:200] + "..."
+}
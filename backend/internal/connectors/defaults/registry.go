@@ -1,7 +1,13 @@
 package defaults
 
 import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/manual"
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/native/asuracomic"
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/native/flamecomics"
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/native/freewebnovel"
@@ -11,15 +17,91 @@ import (
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/native/webtoons"
 )
 
+// ProxyOptions routes the connectors' requests through proxies.
+type ProxyOptions struct {
+	// Default is the proxy of every connector without an override; nil
+	// sends their requests direct.
+	Default *url.URL
+	// Overrides maps connector keys to their own proxy. A nil entry sends
+	// that connector's requests direct even when Default is set.
+	Overrides map[string]*url.URL
+}
+
+// proxyFor returns the proxy of the connector registered as key, and
+// whether it was set for that connector rather than taken from Default.
+func (o ProxyOptions) proxyFor(key string) (*url.URL, bool) {
+	if proxy, ok := o.Overrides[key]; ok {
+		return proxy, true
+	}
+	return o.Default, false
+}
+
 func NewRegistry() *connectors.Registry {
+	// Without proxies every connector is registered as is.
+	registry, _ := NewRegistryWithProxies(ProxyOptions{})
+	return registry
+}
+
+// NewRegistryWithProxies registers the default connectors, each with its
+// requests going through the proxy options give it. It fails on an
+// override for an unknown connector and on a connector that cannot go
+// through the proxy set for it, rather than letting it connect direct. A
+// connector that cannot go through the default proxy connects direct
+// instead, with a warning and a note in its health status.
+func NewRegistryWithProxies(options ProxyOptions) (*connectors.Registry, error) {
+	list := []connectors.Connector{
+		mangadex.NewConnector(),
+		mangafire.NewConnector(),
+		asuracomic.NewConnector(),
+		flamecomics.NewConnector(),
+		mgeko.NewConnector(),
+		webtoons.NewConnector(),
+		freewebnovel.NewConnector(),
+		manual.NewConnector(),
+	}
+
+	known := make(map[string]bool, len(list))
+	for _, connector := range list {
+		known[connector.Key()] = true
+	}
+	unknown := make([]string, 0)
+	for key := range options.Overrides {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("proxy set for unknown connectors: %v", unknown)
+	}
+
 	registry := connectors.NewRegistry()
-	_ = registry.Register(mangadex.NewConnector())
-	_ = registry.Register(mangafire.NewConnector())
-	_ = registry.Register(asuracomic.NewConnector())
-	_ = registry.Register(flamecomics.NewConnector())
-	_ = registry.Register(mgeko.NewConnector())
-	_ = registry.Register(webtoons.NewConnector())
-	_ = registry.Register(freewebnovel.NewConnector())
+	for _, connector := range list {
+		if proxy, explicit := options.proxyFor(connector.Key()); proxy != nil && connector.Kind() != connectors.KindManual {
+			err := routeThroughProxy(connector, proxy)
+			switch {
+			case err == nil:
+				registry.SetProxy(connector.Key(), proxy)
+			case explicit:
+				return nil, err
+			default:
+				slog.Warn("connector skips the default proxy", "connector", connector.Key(), "error", err)
+				registry.SetProxyNote(connector.Key(), "connects direct: it cannot go through the default proxy")
+			}
+		}
+		_ = registry.Register(connector)
+	}
 
-	return registry
+	return registry, nil
+}
+
+func routeThroughProxy(connector connectors.Connector, proxy *url.URL) error {
+	provider, ok := connector.(connectors.HTTPClientProvider)
+	if !ok {
+		return fmt.Errorf("connector %q cannot go through a proxy; set its proxy to direct", connector.Key())
+	}
+	if err := connectors.RouteThroughProxy(provider.HTTPClient(), proxy); err != nil {
+		return fmt.Errorf("proxy connector %q: %w", connector.Key(), err)
+	}
+	return nil
 }
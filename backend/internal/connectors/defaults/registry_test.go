@@ -0,0 +1,123 @@
+package defaults
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+func TestNewRegistryWithProxiesRoutesOnlyTheConfiguredConnector(t *testing.T) {
+	var mu sync.Mutex
+	tunnels := []string{}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tunnels = append(tunnels, r.Method+" "+r.Host)
+		mu.Unlock()
+		http.Error(w, "blocked by test proxy", http.StatusForbidden)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	registry, err := NewRegistryWithProxies(ProxyOptions{Overrides: map[string]*url.URL{"mangadex": proxyURL}})
+	if err != nil {
+		t.Fatalf("new registry with proxies: %v", err)
+	}
+
+	mangadex, _ := registry.Get("mangadex")
+	if _, err := mangadex.SearchByTitle(context.Background(), "blade", 5); err == nil {
+		t.Fatalf("expected the blocking proxy to fail the search")
+	}
+	mu.Lock()
+	got := append([]string(nil), tunnels...)
+	mu.Unlock()
+	if len(got) == 0 || got[0] != "CONNECT api.mangadex.org:443" {
+		t.Fatalf("expected the search to tunnel through the proxy, got %v", got)
+	}
+
+	asura, _ := registry.Get("asuracomic")
+	transport, _ := asura.(connectors.HTTPClientProvider).HTTPClient().Transport.(*http.Transport)
+	if transport != nil && transport.Proxy != nil {
+		t.Fatalf("expected connectors without a proxy to keep their transport")
+	}
+}
+
+func TestNewRegistryWithProxiesRefusesWhatItCannotRoute(t *testing.T) {
+	proxyURL, _ := url.Parse("http://127.0.0.1:3128")
+
+	if _, err := NewRegistryWithProxies(ProxyOptions{Overrides: map[string]*url.URL{"nosuchsite": proxyURL}}); err == nil {
+		t.Fatalf("expected an override for an unknown connector to fail")
+	}
+	// freewebnovel dials TLS itself, so a proxy set for it cannot be honoured.
+	if _, err := NewRegistryWithProxies(ProxyOptions{Overrides: map[string]*url.URL{"freewebnovel": proxyURL}}); err == nil {
+		t.Fatalf("expected a proxy set for a connector that cannot use it to fail")
+	}
+	registry, err := NewRegistryWithProxies(ProxyOptions{Default: proxyURL, Overrides: map[string]*url.URL{"freewebnovel": nil}})
+	if err != nil {
+		t.Fatalf("expected a direct override to allow the default proxy: %v", err)
+	}
+	proxied := 0
+	for _, status := range registry.Health(canceledContext()) {
+		if status.Proxy != "" {
+			proxied++
+		}
+	}
+	if proxied != 6 {
+		t.Fatalf("expected the six native http connectors behind the proxy, got %d", proxied)
+	}
+}
+
+func TestDefaultProxySkipsConnectorsThatCannotUseIt(t *testing.T) {
+	proxyURL, _ := url.Parse("http://127.0.0.1:3128")
+
+	registry, err := NewRegistryWithProxies(ProxyOptions{Default: proxyURL})
+	if err != nil {
+		t.Fatalf("expected a default proxy to start without freewebnovel behind it: %v", err)
+	}
+	proxied := 0
+	for _, status := range registry.Health(canceledContext()) {
+		if status.Proxy != "" {
+			proxied++
+		}
+		if status.Key == "freewebnovel" && (status.Proxy != "" || status.ProxyNote == "") {
+			t.Fatalf("expected freewebnovel direct with a note, got proxy %q note %q", status.Proxy, status.ProxyNote)
+		}
+		if status.Key != "freewebnovel" && status.ProxyNote != "" {
+			t.Fatalf("expected no note for %s, got %q", status.Key, status.ProxyNote)
+		}
+	}
+	if proxied != 6 {
+		t.Fatalf("expected the other native http connectors behind the proxy, got %d", proxied)
+	}
+}
+
+func canceledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func TestDefaultConnectorsDeclareTheirMediaHosts(t *testing.T) {
+	for _, descriptor := range NewRegistry().List() {
+		if descriptor.Kind != connectors.KindNative {
+			continue
+		}
+		if len(descriptor.MediaHosts) == 0 {
+			t.Fatalf("expected %s to declare its media hosts", descriptor.Key)
+		}
+	}
+
+	registry := NewRegistry()
+	registry.SetStrictMediaHosts(true)
+	cover := "https://static.mfcdn.nl/i/o/op.jpg"
+	if got := registry.MediaURL("mangafire", cover); got != cover {
+		t.Fatalf("expected MangaFire's cover CDN to be allowed, got %q", got)
+	}
+	if got := registry.MediaURL("mangadex", cover); got != "" {
+		t.Fatalf("expected another site's CDN to be dropped, got %q", got)
+	}
+}
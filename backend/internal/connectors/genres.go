@@ -0,0 +1,36 @@
+package connectors
+
+import (
+	"strings"
+)
+
+// maxGenres caps how many genre labels a result carries; sites that tag a
+// series with dozens of themes would otherwise crowd the tag suggestions.
+const maxGenres = 20
+
+// CleanGenres trims the genre labels a site lists for a series, collapses
+// their inner whitespace and drops blanks and case-insensitive repeats,
+// keeping the site's order. It returns nil when no label is left.
+func CleanGenres(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	genres := make([]string, 0, len(values))
+	for _, value := range values {
+		genre := strings.Join(strings.Fields(value), " ")
+		if genre == "" {
+			continue
+		}
+		key := strings.ToLower(genre)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		genres = append(genres, genre)
+		if len(genres) == maxGenres {
+			break
+		}
+	}
+	if len(genres) == 0 {
+		return nil
+	}
+	return genres
+}
@@ -0,0 +1,42 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultLanguage is the translation resolved for linked sources without a
+// language preference.
+const DefaultLanguage = "en"
+
+// languagePattern accepts the codes sites use for translations: a two or
+// three letter language with an optional region or script, e.g. "pt-br".
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(?:-[a-z0-9]{2,8})?$`)
+
+// NormalizeLanguage lowercases a language code, turning "" into
+// DefaultLanguage and "_" separators into "-".
+func NormalizeLanguage(raw string) (string, error) {
+	value := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(raw)), "_", "-")
+	if value == "" {
+		return DefaultLanguage, nil
+	}
+	if !languagePattern.MatchString(value) {
+		return "", fmt.Errorf("invalid language %q", raw)
+	}
+	return value, nil
+}
+
+// ResolveByURLWithLang resolves rawURL in lang when the connector implements
+// LanguageAwareResolver and falls back to ResolveByURL otherwise.
+func ResolveByURLWithLang(ctx context.Context, connector Connector, rawURL string, lang string) (*MangaResult, error) {
+	if resolver, ok := connector.(LanguageAwareResolver); ok {
+		normalized, err := NormalizeLanguage(lang)
+		if err != nil {
+			normalized = DefaultLanguage
+		}
+		return resolver.ResolveByURLWithLang(ctx, rawURL, normalized)
+	}
+	return connector.ResolveByURL(ctx, rawURL)
+}
@@ -0,0 +1,73 @@
+package connectors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":       "en",
+		" EN ":   "en",
+		"pt_BR":  "pt-br",
+		"es-la":  "es-la",
+		"zh-hk":  "zh-hk",
+		"ja-ro":  "ja-ro",
+		"fil":    "fil",
+		"en-gb ": "en-gb",
+	}
+	for raw, want := range cases {
+		got, err := connectors.NormalizeLanguage(raw)
+		if err != nil {
+			t.Fatalf("normalize %q: %v", raw, err)
+		}
+		if got != want {
+			t.Fatalf("normalize %q: expected %q, got %q", raw, want, got)
+		}
+	}
+
+	for _, raw := range []string{"english", "e", "pt-", "en-us-x", "1e", "en gb"} {
+		if _, err := connectors.NormalizeLanguage(raw); err == nil {
+			t.Fatalf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+type langAwareConnector struct {
+	*fakeConnector
+	lang *string
+}
+
+func (c langAwareConnector) ResolveByURLWithLang(_ context.Context, rawURL string, lang string) (*connectors.MangaResult, error) {
+	*c.lang = lang
+	return &connectors.MangaResult{URL: rawURL, Title: "in " + lang}, nil
+}
+
+func TestResolveByURLWithLangPrefersLanguageAwareConnectors(t *testing.T) {
+	var lang string
+	aware := langAwareConnector{fakeConnector: &fakeConnector{key: "aware"}, lang: &lang}
+
+	result, err := connectors.ResolveByURLWithLang(context.Background(), aware, "https://example.com/a", "ES_LA")
+	if err != nil || result.Title != "in es-la" || lang != "es-la" {
+		t.Fatalf("expected the language-aware resolve, got %+v %v (lang %q)", result, err, lang)
+	}
+	if _, err := connectors.ResolveByURLWithLang(context.Background(), aware, "https://example.com/a", "??"); err != nil || lang != "en" {
+		t.Fatalf("expected an invalid language to fall back to en, got %q %v", lang, err)
+	}
+
+	// fakeConnector's plain ResolveByURL returns no result.
+	plain := &fakeConnector{key: "plain"}
+	result, err = connectors.ResolveByURLWithLang(context.Background(), plain, "https://example.com/b", "fr")
+	if err != nil || result != nil {
+		t.Fatalf("expected the plain resolve, got %+v %v", result, err)
+	}
+
+	registry := connectors.NewRegistry()
+	_ = registry.Register(aware)
+	_ = registry.Register(plain)
+	if !registry.LanguageAware("aware") || registry.LanguageAware("plain") || registry.LanguageAware("missing") {
+		t.Fatalf("unexpected LanguageAware results")
+	}
+}
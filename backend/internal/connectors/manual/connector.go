@@ -0,0 +1,98 @@
+// Package manual is the connector for series on sites no connector can
+// scrape. It never makes a request: resolving a URL echoes it back, title
+// search finds nothing, and the tracker's chapters are logged by hand from
+// the dashboard.
+package manual
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+// Key is the source key of the manual connector and its seeded source.
+const Key = "manual"
+
+type Connector struct{}
+
+func NewConnector() *Connector {
+	return &Connector{}
+}
+
+func (c *Connector) Key() string {
+	return Key
+}
+
+func (c *Connector) Name() string {
+	return "Manual"
+}
+
+func (c *Connector) Kind() string {
+	return connectors.KindManual
+}
+
+// SearchMode implements connectors.SearchModeProvider; there is nothing to
+// search, so the add-tracker box takes the series URL.
+func (c *Connector) SearchMode() string {
+	return connectors.SearchModeURLOnly
+}
+
+func (c *Connector) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// ResolveByURL echoes the URL back with a title read from its last path
+// segment. It carries no chapter or release date, so callers keep the ones
+// stored on the tracker.
+func (c *Connector) ResolveByURL(ctx context.Context, rawURL string) (*connectors.MangaResult, error) {
+	canonicalURL, err := c.ValidateURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &connectors.MangaResult{
+		SourceKey:    Key,
+		SourceItemID: canonicalURL,
+		Title:        titleFromURL(canonicalURL),
+		URL:          canonicalURL,
+	}, nil
+}
+
+func (c *Connector) SearchByTitle(ctx context.Context, title string, limit int) ([]connectors.MangaResult, error) {
+	return []connectors.MangaResult{}, nil
+}
+
+// ValidateURL implements connectors.URLValidator. Any http(s) URL is
+// accepted, since manual trackers live on sites no other connector claims.
+func (c *Connector) ValidateURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return "", fmt.Errorf("not an http(s) URL")
+	}
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Fragment = ""
+	return parsed.String(), nil
+}
+
+func titleFromURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	segment := path.Base(strings.TrimRight(parsed.Path, "/"))
+	if segment == "." || segment == "/" {
+		return parsed.Hostname()
+	}
+
+	words := strings.FieldsFunc(segment, func(r rune) bool {
+		return r == '-' || r == '_' || r == '+' || r == ' '
+	})
+	for index, word := range words {
+		words[index] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
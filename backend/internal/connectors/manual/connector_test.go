@@ -0,0 +1,51 @@
+package manual_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	connectordefaults "github.com/gabriel/cross-site-tracker/backend/internal/connectors/defaults"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/manual"
+)
+
+func TestDefaultRegistryIncludesManualConnector(t *testing.T) {
+	registry := connectordefaults.NewRegistry()
+	connector, ok := registry.Get(manual.Key)
+	if !ok {
+		t.Fatalf("expected the manual connector to be registered")
+	}
+	if connector.Kind() != connectors.KindManual {
+		t.Fatalf("expected kind %q, got %q", connectors.KindManual, connector.Kind())
+	}
+	if mode := registry.SearchModes()[manual.Key]; mode != connectors.SearchModeURLOnly {
+		t.Fatalf("expected url-only search, got %q", mode)
+	}
+}
+
+func TestManualConnectorEchoesURLWithoutChapterData(t *testing.T) {
+	connector := manual.NewConnector()
+
+	resolved, err := connector.ResolveByURL(context.Background(), " https://Walled.Example/series/the-quiet-blade/#top ")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if resolved.URL != "https://walled.example/series/the-quiet-blade/" || resolved.SourceItemID != resolved.URL {
+		t.Fatalf("expected the canonical URL to be echoed back, got %+v", resolved)
+	}
+	if resolved.Title != "The Quiet Blade" {
+		t.Fatalf("expected a title from the URL slug, got %q", resolved.Title)
+	}
+	if resolved.LatestChapter != nil || resolved.LastUpdatedAt != nil {
+		t.Fatalf("expected no chapter data, got %+v", resolved)
+	}
+
+	results, err := connector.SearchByTitle(context.Background(), "the quiet blade", 5)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("expected an empty search, got %v %v", results, err)
+	}
+
+	if _, err := connector.ResolveByURL(context.Background(), "ftp://walled.example/series"); err == nil {
+		t.Fatalf("expected a non-http URL to be rejected")
+	}
+}
@@ -0,0 +1,104 @@
+package connectors
+
+import (
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// MediaHostProvider is implemented by connectors that declare the hosts
+// their cover and chapter links may point at: the site's own hosts and the
+// CDNs it serves images from. Subdomains of a host are included.
+type MediaHostProvider interface {
+	MediaHosts() []string
+}
+
+// MediaURLSafe reports whether rawURL is an absolute http(s) URL that
+// does not point at this machine or the local network: loopback, private
+// and link-local addresses and "localhost" are refused, since a cover
+// fetched server-side from them would reach internal services.
+func MediaURLSafe(rawURL string) bool {
+	_, ok := safeMediaHost(rawURL)
+	return ok
+}
+
+// MediaURLAllowed reports whether rawURL is safe, as MediaURLSafe, and on
+// one of hosts. Listing an internal address does not make it allowed.
+func MediaURLAllowed(rawURL string, hosts []string) bool {
+	host, ok := safeMediaHost(rawURL)
+	return ok && hostAllowed(host, hosts)
+}
+
+// safeMediaHost returns the lower-cased host of rawURL when MediaURLSafe
+// would accept it.
+func safeMediaHost(rawURL string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.User != nil {
+		return "", false
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return "", false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return "", false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+			return "", false
+		}
+	}
+	return host, true
+}
+
+func mediaHostsOf(connector Connector) []string {
+	provider, ok := connector.(MediaHostProvider)
+	if !ok {
+		return nil
+	}
+	return provider.MediaHosts()
+}
+
+// SetStrictMediaHosts makes MediaURL also drop cover and chapter links
+// that are not on their connector's media hosts. It is off by default.
+func (r *Registry) SetStrictMediaHosts(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.strictMedia = enabled
+}
+
+// MediaURL returns rawURL, a cover or chapter link the connector registered
+// as key scraped, or "" when the link is not safe to follow, as
+// MediaURLSafe, or strict media hosts are on and it is not on one of the
+// connector's media hosts. Dropped links are logged.
+func (r *Registry) MediaURL(key string, rawURL string) string {
+	if strings.TrimSpace(rawURL) == "" {
+		return rawURL
+	}
+	host, ok := safeMediaHost(rawURL)
+	if !ok {
+		slog.Warn("dropped unsafe media url", "source_key", key, "url", rawURL)
+		return ""
+	}
+
+	r.mu.RLock()
+	strict := r.strictMedia
+	r.mu.RUnlock()
+	if !strict {
+		return rawURL
+	}
+
+	var hosts []string
+	if connector, ok := r.Get(key); ok {
+		hosts = mediaHostsOf(connector)
+	}
+	if hostAllowed(host, hosts) {
+		return rawURL
+	}
+	slog.Warn("dropped media url off the connector's hosts", "source_key", key, "url", rawURL)
+	return ""
+}
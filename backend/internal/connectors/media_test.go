@@ -0,0 +1,118 @@
+package connectors_test
+
+import (
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+type mediaHostConnector struct {
+	fakeConnector
+	hosts []string
+}
+
+func (m *mediaHostConnector) MediaHosts() []string { return m.hosts }
+
+func TestMediaURLAllowed(t *testing.T) {
+	hosts := []string{"mangafire.to", "mfcdn.nl"}
+	cases := []struct {
+		raw  string
+		want bool
+	}{
+		{raw: "https://static.mfcdn.nl/covers/op.jpg", want: true},
+		{raw: "https://mangafire.to/read/dkw-one-piece/en/chapter-1187", want: true},
+		{raw: "http://MFCDN.nl/op.jpg", want: true},
+		{raw: "https://mangafire.to.evil.example/op.jpg", want: false},
+		{raw: "https://evilmfcdn.nl/op.jpg", want: false},
+		{raw: "https://mangafire.to@evil.example/op.jpg", want: false},
+		{raw: "//static.mfcdn.nl/op.jpg", want: false},
+		{raw: "/covers/op.jpg", want: false},
+		{raw: "file:///etc/passwd", want: false},
+		{raw: "ftp://mfcdn.nl/op.jpg", want: false},
+		{raw: "http://169.254.169.254/latest/meta-data/", want: false},
+		{raw: "http://localhost:8080/op.jpg", want: false},
+		{raw: "http://127.0.0.1/op.jpg", want: false},
+		{raw: "http://[::1]/op.jpg", want: false},
+		{raw: "", want: false},
+	}
+	for _, tc := range cases {
+		if got := connectors.MediaURLAllowed(tc.raw, hosts); got != tc.want {
+			t.Fatalf("MediaURLAllowed(%q) = %v, want %v", tc.raw, got, tc.want)
+		}
+	}
+
+	for _, tc := range cases {
+		if tc.want && !connectors.MediaURLSafe(tc.raw) {
+			t.Fatalf("expected %q to be safe", tc.raw)
+		}
+	}
+	if !connectors.MediaURLSafe("https://tracker.evil.example/pixel.gif") {
+		t.Fatalf("expected a public host off the list to be safe")
+	}
+
+	// Listing an internal address does not make it reachable.
+	for _, raw := range []string{"http://127.0.0.1/op.jpg", "http://10.0.0.5/op.jpg", "http://localhost/op.jpg"} {
+		if connectors.MediaURLAllowed(raw, []string{"127.0.0.1", "10.0.0.5", "localhost"}) {
+			t.Fatalf("expected %q to be refused even when listed", raw)
+		}
+	}
+}
+
+func TestRegistryMediaURLAlwaysDropsUnsafeLinks(t *testing.T) {
+	r := connectors.NewRegistry()
+	if err := r.Register(&mediaHostConnector{fakeConnector: fakeConnector{key: "site", kind: connectors.KindNative}, hosts: []string{"site.example", "127.0.0.1"}}); err != nil {
+		t.Fatalf("register site: %v", err)
+	}
+
+	for _, raw := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://127.0.0.1/cover.jpg",
+		"http://10.0.0.5/cover.jpg",
+		"http://localhost:8080/cover.jpg",
+		"file:///etc/passwd",
+		"javascript:alert(1)",
+		"/covers/op.jpg",
+	} {
+		if got := r.MediaURL("site", raw); got != "" {
+			t.Fatalf("expected %q dropped with strict mode off, got %q", raw, got)
+		}
+	}
+}
+
+func TestRegistryMediaURLDropsOffHostLinksInStrictMode(t *testing.T) {
+	r := connectors.NewRegistry()
+	if err := r.Register(&mediaHostConnector{fakeConnector: fakeConnector{key: "site", kind: connectors.KindNative}, hosts: []string{"site.example", "cdn.example"}}); err != nil {
+		t.Fatalf("register site: %v", err)
+	}
+	if err := r.Register(&fakeConnector{key: "bare", kind: connectors.KindNative}); err != nil {
+		t.Fatalf("register bare: %v", err)
+	}
+
+	if list := r.List(); len(list[1].MediaHosts) != 2 || list[0].MediaHosts != nil {
+		t.Fatalf("expected only the declaring connector to list media hosts, got %+v", list)
+	}
+
+	offHost := "https://tracker.evil.example/pixel.gif"
+	if got := r.MediaURL("site", offHost); got != offHost {
+		t.Fatalf("expected links kept while strict mode is off, got %q", got)
+	}
+
+	r.SetStrictMediaHosts(true)
+	cases := []struct {
+		key  string
+		raw  string
+		want string
+	}{
+		{key: "site", raw: "https://img.cdn.example/cover.jpg", want: "https://img.cdn.example/cover.jpg"},
+		{key: "site", raw: offHost, want: ""},
+		{key: "site", raw: "http://169.254.169.254/cover.jpg", want: ""},
+		{key: "site", raw: "", want: ""},
+		{key: "bare", raw: "https://site.example/cover.jpg", want: ""},
+		{key: "missing", raw: "https://site.example/cover.jpg", want: ""},
+	}
+	for _, tc := range cases {
+		if got := r.MediaURL(tc.key, tc.raw); got != tc.want {
+			t.Fatalf("MediaURL(%q, %q) = %q, want %q", tc.key, tc.raw, got, tc.want)
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"html"
-	"io"
 	"math"
 	"net/http"
 	"net/url"
@@ -48,6 +47,11 @@ func (e *httpStatusError) StatusCode() int {
 	return e.statusCode
 }
 
+// Is reports a 404 as connectors.ErrNotFound.
+func (e *httpStatusError) Is(target error) bool {
+	return target == connectors.ErrNotFound && e.statusCode == http.StatusNotFound
+}
+
 type Connector struct {
 	baseURL     string
 	allowedHost []string
@@ -74,6 +78,11 @@ func NewConnectorWithOptions(baseURL string, allowedHost []string, client *http.
 	return &Connector{baseURL: strings.TrimRight(baseURL, "/"), allowedHost: allowedHost, httpClient: client}
 }
 
+// HTTPClient implements connectors.HTTPClientProvider.
+func (c *Connector) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
 func (c *Connector) Key() string {
 	return "asuracomic"
 }
@@ -86,6 +95,16 @@ func (c *Connector) Kind() string {
 	return connectors.KindNative
 }
 
+// SearchPageURL implements connectors.SearchPageLinker.
+func (c *Connector) SearchPageURL(query string) string {
+	return c.searchPageURL(query)
+}
+
+// Canary implements connectors.CanaryProvider with Nano Machine; the legacy URL also exercises the slug search fallback.
+func (c *Connector) Canary() connectors.Canary {
+	return connectors.Canary{URL: "https://asuracomic.net/series/nano-machine-11b89554", TitleContains: "Nano Machine", MinLatestChapter: 200}
+}
+
 func (c *Connector) HealthCheck(ctx context.Context) error {
 	_, err := c.fetchPage(ctx, c.searchPageURL("nano"))
 	return err
@@ -323,12 +342,12 @@ func (c *Connector) fetchPage(ctx context.Context, endpoint string) (string, err
 		return "", &httpStatusError{statusCode: res.StatusCode}
 	}
 
-	rawBody, err := io.ReadAll(res.Body)
+	body, err := connectors.ReadPage(res.Body)
 	if err != nil {
 		return "", fmt.Errorf("read response body: %w", err)
 	}
 
-	return string(rawBody), nil
+	return body, nil
 }
 
 func isHTTPStatus(err error, statusCode int) bool {
@@ -339,6 +358,28 @@ func isHTTPStatus(err error, statusCode int) bool {
 	return statusErr.StatusCode() == statusCode
 }
 
+// MediaHosts implements connectors.MediaHostProvider. Covers are served
+// from the site's own hosts.
+func (c *Connector) MediaHosts() []string {
+	return append([]string(nil), c.allowedHost...)
+}
+
+// ValidateURL implements connectors.URLValidator.
+func (c *Connector) ValidateURL(rawURL string) (string, error) {
+	return connectors.CanonicalHostURL(rawURL, c.allowedHost)
+}
+
+// CanonicalScheme implements connectors.URLMigrator.
+func (c *Connector) CanonicalScheme() string {
+	return "https"
+}
+
+// HostMigrations implements connectors.URLMigrator. Trackers added while the
+// site lived on asuracomic.net are moved to asurascans.com.
+func (c *Connector) HostMigrations() []connectors.HostMigration {
+	return []connectors.HostMigration{{From: "asuracomic.net", To: "asurascans.com"}}
+}
+
 func (c *Connector) isAllowedHost(host string) bool {
 	host = strings.ToLower(strings.TrimSpace(host))
 	for _, allowed := range c.allowedHost {
@@ -418,7 +459,7 @@ func extractAnchorTextForSeriesID(body string, seriesID string) string {
 }
 
 func extractTitle(body string) string {
-	title := strings.TrimSpace(html.UnescapeString(firstSubmatch(metaTitlePattern, body)))
+	title := cleanText(firstSubmatch(metaTitlePattern, body))
 	if title == "" {
 		title = strings.TrimSpace(html.UnescapeString(cleanText(firstSubmatch(titleTagPattern, body))))
 	}
@@ -455,6 +496,7 @@ func extractLatestChapterAndReleaseAt(body string, seriesID string) (*float64, *
 		return nil, nil
 	}
 
+	dateIndexes := monthDayOrdinalYearPattern.FindAllStringIndex(body, -1)
 	var latestByPair *float64
 	var releaseAtByPair *time.Time
 	for _, loc := range chapterIndexes {
@@ -468,19 +510,11 @@ func extractLatestChapterAndReleaseAt(body string, seriesID string) (*float64, *
 			continue
 		}
 
-		segmentStart := loc[0]
-		segmentEnd := segmentStart + 2200
-		if segmentEnd > len(body) {
-			segmentEnd = len(body)
-		}
-		if segmentStart < 0 || segmentStart >= len(body) || segmentStart >= segmentEnd {
-			continue
+		var parsedDate *time.Time
+		if date := connectors.MatchWithin(dateIndexes, loc[0], loc[0]+2200); date != nil {
+			parsedDate = parseAsuraDate(body[date[0]:date[1]])
 		}
 
-		segment := body[segmentStart:segmentEnd]
-		dateRaw := monthDayOrdinalYearPattern.FindString(segment)
-		parsedDate := parseAsuraDate(dateRaw)
-
 		if latestByPair == nil || parsedChapter > *latestByPair {
 			chapterValue := parsedChapter
 			latestByPair = &chapterValue
@@ -6,6 +6,9 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
 )
 
 func TestAsuraComicConnectorResolveAndSearch(t *testing.T) {
@@ -377,3 +380,40 @@ func TestAsuraComicConnectorFallsBackFromLegacySeriesURL(t *testing.T) {
 		t.Fatalf("unexpected chapter url: %s", chapterURL)
 	}
 }
+
+func TestAsuraComicConnectorConformance(t *testing.T) {
+	seriesPage := func(title string, seriesID string) conformance.Fixture {
+		return conformance.Fixture{Body: `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta property="og:title" content="` + title + ` - Asura Scans">
+</head>
+<body>
+  <a href="/comics/` + seriesID + `/chapter/12">Chapter 12</a>
+</body>
+</html>`}
+	}
+
+	conformance.RunConformanceSuite(t, func(baseURL string) connectors.Connector {
+		return NewConnectorWithOptions(baseURL, []string{"asurascans.com", "asuracomic.net"}, &http.Client{Timeout: 5 * time.Second})
+	}, conformance.FixtureSet{
+		Routes: map[string]conformance.Fixture{
+			"/browse": {Body: `
+<!DOCTYPE html>
+<html>
+<body>
+  <a href="/comics/tom-and-jerrys-blade-1a2b3c4d">Tom &amp; Jerry&#39;s Blade</a>
+  <a href="/comics/second-blade-5e6f7a8b">Second Blade</a>
+</body>
+</html>`},
+			"/comics/tom-and-jerrys-blade-1a2b3c4d": seriesPage("  Tom &amp; Jerry&#39;s\n   Blade ", "tom-and-jerrys-blade-1a2b3c4d"),
+			"/comics/second-blade-5e6f7a8b":         seriesPage("Second Blade", "second-blade-5e6f7a8b"),
+		},
+		SeriesURL:    "https://asurascans.com/comics/tom-and-jerrys-blade-1a2b3c4d",
+		SeriesTitle:  "Tom & Jerry's Blade",
+		MissingURL:   "https://asurascans.com/comics/missing-series-9c8d7e6f",
+		WrongHostURL: "https://example.com/comics/tom-and-jerrys-blade-1a2b3c4d",
+		SearchQuery:  "blade",
+	})
+}
@@ -0,0 +1,33 @@
+package asuracomic
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
+)
+
+const fuzzSeriesPage = `
+<!DOCTYPE html>
+<html>
+<body>
+  <h3>Updated On</h3><h3>March 3rd 2026</h3>
+  <a href="/comics/tom-and-jerrys-blade-1a2b3c4d/chapter/12"><span>Chapter 12</span><span>March 3rd 2026</span></a>
+  <a href="/comics/tom-and-jerrys-blade-1a2b3c4d/chapter/11.5"><span>Chapter 11.5</span><span>February 24th 2026</span></a>
+  <script>self.__next_f.push([1,"{\"name\":12,\"published_at\":\"2026-03-03T10:00:00Z\"}"])</script>
+</body>
+</html>`
+
+func FuzzExtractLatestChapterAndReleaseAt(f *testing.F) {
+	conformance.AddAdversarialSeeds(f, `<a href="/comics/tom-and-jerrys-blade-1a2b3c4d/chapter/12">`, fuzzSeriesPage)
+	f.Fuzz(func(t *testing.T, body string) {
+		conformance.CheckParse(t, body, func() {
+			for _, seriesID := range []string{"tom-and-jerrys-blade-1a2b3c4d", ""} {
+				chapter, _ := extractLatestChapterAndReleaseAt(body, seriesID)
+				if chapter != nil && (math.IsNaN(*chapter) || math.IsInf(*chapter, 0) || *chapter < 0) {
+					t.Fatalf("unexpected chapter %v", *chapter)
+				}
+			}
+		})
+	})
+}
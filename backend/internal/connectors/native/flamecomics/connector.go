@@ -4,13 +4,11 @@ import (
 	"context"
 	"fmt"
 	"html"
-	"io"
 	"math"
 	"net/http"
 	"net/url"
 	"path"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
@@ -26,7 +24,7 @@ var (
 	metaImagePattern              = regexp.MustCompile(`(?is)<meta\s+[^>]*(?:property=["']og:image["']|name=["']twitter:image["'])[^>]*content=["']([^\"]+)["']`)
 	chapterBySeriesPattern        = regexp.MustCompile(`(?i)/series/(\d+)/[a-z0-9]+`)
 	chapterAnchorPattern          = regexp.MustCompile(`(?is)<a[^>]+href=["']((?:https?://[^"']+)?/series/(\d+)/[^"']+)["'][^>]*>(.*?)</a>`)
-	chapterNumberPattern          = regexp.MustCompile(`(?i)Chapter(?:\s|<!--\s*-->|&nbsp;)+([0-9]+(?:\.[0-9]+)?)`)
+	chapterNumberPattern          = regexp.MustCompile(`(?i)Chapter(?:\s|<!--\s*-->|&nbsp;)+([0-9]{1,3}(?:[,\x{2009}\x{202F}][0-9]{3})+(?:\.[0-9]+)?|[0-9]+(?:\.[0-9]+)?)`)
 	fullDateTimePattern           = regexp.MustCompile(`(?i)(Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|Jun(?:e)?|Jul(?:y)?|Aug(?:ust)?|Sep(?:t(?:ember)?)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)\s+\d{1,2},\s+\d{4}(?:\s+\d{1,2}:\d{2}\s*(?:AM|PM))?`)
 	htmlTagPattern                = regexp.MustCompile(`(?is)<[^>]+>`)
 	whitespacePattern             = regexp.MustCompile(`\s+`)
@@ -59,6 +57,11 @@ func NewConnectorWithOptions(baseURL string, allowedHost []string, client *http.
 	return &Connector{baseURL: strings.TrimRight(baseURL, "/"), allowedHost: allowedHost, httpClient: client}
 }
 
+// HTTPClient implements connectors.HTTPClientProvider.
+func (c *Connector) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
 func (c *Connector) Key() string {
 	return "flamecomics"
 }
@@ -71,6 +74,16 @@ func (c *Connector) Kind() string {
 	return connectors.KindNative
 }
 
+// SearchPageURL implements connectors.SearchPageLinker.
+func (c *Connector) SearchPageURL(query string) string {
+	return c.baseURL + "/browse?search=" + url.QueryEscape(strings.TrimSpace(query))
+}
+
+// Canary implements connectors.CanaryProvider with The Novel's Extra (Remake).
+func (c *Connector) Canary() connectors.Canary {
+	return connectors.Canary{URL: "https://flamecomics.xyz/series/83", TitleContains: "Extra", MinLatestChapter: 100}
+}
+
 func (c *Connector) HealthCheck(ctx context.Context) error {
 	_, err := c.fetchPage(ctx, c.baseURL+"/latest")
 	return err
@@ -212,20 +225,13 @@ func (c *Connector) ResolveChapterURL(ctx context.Context, rawURL string, chapte
 
 		chapterRaw := firstSubmatch(chapterNumberPattern, innerHTML)
 		if chapterRaw == "" {
-			segmentEnd := match[1] + 500
-			if segmentEnd > len(body) {
-				segmentEnd = len(body)
-			}
-			if match[0] < segmentEnd {
-				segment := body[match[0]:segmentEnd]
-				chapterRaw = firstSubmatch(chapterNumberPattern, segment)
-			}
+			chapterRaw = firstSubmatch(chapterNumberPattern, connectors.Window(body, match[0], match[1]+500))
 		}
 		if chapterRaw == "" {
 			continue
 		}
 
-		parsedChapter, parseErr := strconv.ParseFloat(strings.TrimSpace(chapterRaw), 64)
+		parsedChapter, parseErr := connectors.ParseChapterNumber(chapterRaw)
 		if parseErr != nil {
 			continue
 		}
@@ -233,16 +239,107 @@ func (c *Connector) ResolveChapterURL(ctx context.Context, rawURL string, chapte
 			continue
 		}
 
-		if strings.HasPrefix(hrefRaw, "http://") || strings.HasPrefix(hrefRaw, "https://") {
-			return hrefRaw, nil
+		return absoluteFlameURL(hrefRaw), nil
+	}
+
+	return "", fmt.Errorf("chapter %.3f not found", chapter)
+}
+
+func (c *Connector) ListChapters(ctx context.Context, rawURL string, limit int) ([]connectors.ChapterInfo, error) {
+	trimmed := strings.TrimSpace(rawURL)
+	if trimmed == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if !c.isAllowedHost(parsed.Hostname()) {
+		return nil, fmt.Errorf("url does not belong to flamecomics")
+	}
+
+	segments := strings.Split(strings.Trim(path.Clean(parsed.Path), "/"), "/")
+	if len(segments) < 2 || segments[0] != "series" {
+		return nil, fmt.Errorf("flamecomics url must match /series/{id}")
+	}
+
+	seriesID := strings.TrimSpace(segments[1])
+	if !seriesIDPattern.MatchString(seriesID) {
+		return nil, fmt.Errorf("invalid flamecomics series id")
+	}
+
+	body, err := c.fetchPage(ctx, c.baseURL+"/series/"+seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch series page: %w", err)
+	}
+
+	return connectors.NewestChaptersFirst(extractChapterList(body, seriesID), limit), nil
+}
+
+// extractChapterList reads every chapter anchor of the series page in
+// document order. The release date sits next to the anchor rather than inside
+// it, so it is looked up in the markup between this anchor and the next one.
+func extractChapterList(body string, seriesID string) []connectors.ChapterInfo {
+	matches := chapterAnchorPattern.FindAllStringSubmatchIndex(body, -1)
+	chapters := make([]connectors.ChapterInfo, 0, len(matches))
+	seenURL := make(map[string]struct{}, len(matches))
+
+	for index, match := range matches {
+		if len(match) < 8 {
+			continue
+		}
+
+		hrefRaw := strings.TrimSpace(html.UnescapeString(body[match[2]:match[3]]))
+		candidateSeriesID := strings.TrimSpace(body[match[4]:match[5]])
+		innerHTML := body[match[6]:match[7]]
+		if candidateSeriesID != seriesID || hrefRaw == "" {
+			continue
+		}
+
+		segmentEnd := match[1] + 500
+		if index+1 < len(matches) && matches[index+1][0] < segmentEnd {
+			segmentEnd = matches[index+1][0]
 		}
-		if strings.HasPrefix(hrefRaw, "/") {
-			return "https://flamecomics.xyz" + hrefRaw, nil
+		segment := connectors.Window(body, match[0], segmentEnd)
+
+		chapterRaw := firstSubmatch(chapterNumberPattern, innerHTML)
+		if chapterRaw == "" {
+			chapterRaw = firstSubmatch(chapterNumberPattern, segment)
+		}
+		if chapterRaw == "" {
+			continue
+		}
+
+		number, parseErr := connectors.ParseChapterNumber(chapterRaw)
+		if parseErr != nil {
+			continue
+		}
+
+		chapterURL := absoluteFlameURL(hrefRaw)
+		if _, exists := seenURL[chapterURL]; exists {
+			continue
 		}
-		return "https://flamecomics.xyz/" + hrefRaw, nil
+		seenURL[chapterURL] = struct{}{}
+
+		chapters = append(chapters, connectors.ChapterInfo{
+			Number:     number,
+			URL:        chapterURL,
+			ReleasedAt: parseFlameDate(fullDateTimePattern.FindString(segment)),
+		})
 	}
 
-	return "", fmt.Errorf("chapter %.3f not found", chapter)
+	return chapters
+}
+
+func absoluteFlameURL(href string) string {
+	if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
+		return href
+	}
+	if strings.HasPrefix(href, "/") {
+		return "https://flamecomics.xyz" + href
+	}
+	return "https://flamecomics.xyz/" + href
 }
 
 type seriesEntry struct {
@@ -320,6 +417,8 @@ func extractLatestChapterAndReleaseAt(body string, seriesID string) (*float64, *
 		return nil, nil
 	}
 
+	chapterIndexes := chapterNumberPattern.FindAllStringSubmatchIndex(body, -1)
+	dateIndexes := fullDateTimePattern.FindAllStringIndex(body, -1)
 	var latestChapter *float64
 	var latestReleaseAt *time.Time
 
@@ -333,27 +432,21 @@ func extractLatestChapterAndReleaseAt(body string, seriesID string) (*float64, *
 			continue
 		}
 
-		segmentStart := loc[0]
-		segmentEnd := segmentStart + 1800
-		if segmentEnd > len(body) {
-			segmentEnd = len(body)
-		}
-		if segmentStart >= segmentEnd {
-			continue
-		}
-
-		segment := body[segmentStart:segmentEnd]
-		chapterRaw := firstSubmatch(chapterNumberPattern, segment)
-		if chapterRaw == "" {
+		segmentEnd := loc[0] + 1800
+		chapterLoc := connectors.MatchWithin(chapterIndexes, loc[0], segmentEnd)
+		if chapterLoc == nil {
 			continue
 		}
 
-		parsedChapter, parseChapterErr := strconv.ParseFloat(strings.TrimSpace(chapterRaw), 64)
+		parsedChapter, parseChapterErr := connectors.ParseChapterNumber(body[chapterLoc[2]:chapterLoc[3]])
 		if parseChapterErr != nil {
 			continue
 		}
 
-		parsedDate := parseFlameDate(fullDateTimePattern.FindString(segment))
+		var parsedDate *time.Time
+		if date := connectors.MatchWithin(dateIndexes, loc[0], segmentEnd); date != nil {
+			parsedDate = parseFlameDate(body[date[0]:date[1]])
+		}
 		if latestChapter == nil || parsedChapter > *latestChapter {
 			chapterCopy := parsedChapter
 			latestChapter = &chapterCopy
@@ -400,7 +493,7 @@ func parseFlameDate(raw string) *time.Time {
 }
 
 func extractTitle(body string) string {
-	title := strings.TrimSpace(html.UnescapeString(firstSubmatch(metaTitlePattern, body)))
+	title := cleanText(firstSubmatch(metaTitlePattern, body))
 	if title == "" {
 		title = strings.TrimSpace(html.UnescapeString(cleanText(firstSubmatch(titleTagPattern, body))))
 	}
@@ -487,16 +580,40 @@ func (c *Connector) fetchPage(ctx context.Context, endpoint string) (string, err
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("unexpected status: %d: %w", res.StatusCode, connectors.ErrNotFound)
+	}
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		return "", fmt.Errorf("unexpected status: %d", res.StatusCode)
 	}
 
-	rawBody, err := io.ReadAll(res.Body)
+	body, err := connectors.ReadPage(res.Body)
 	if err != nil {
 		return "", fmt.Errorf("read response body: %w", err)
 	}
 
-	return string(rawBody), nil
+	return body, nil
+}
+
+// MediaHosts implements connectors.MediaHostProvider. Covers are served
+// from the site's own hosts.
+func (c *Connector) MediaHosts() []string {
+	return append([]string(nil), c.allowedHost...)
+}
+
+// ValidateURL implements connectors.URLValidator.
+func (c *Connector) ValidateURL(rawURL string) (string, error) {
+	return connectors.CanonicalHostURL(rawURL, c.allowedHost)
+}
+
+// CanonicalScheme implements connectors.URLMigrator.
+func (c *Connector) CanonicalScheme() string {
+	return "https"
+}
+
+// HostMigrations implements connectors.URLMigrator.
+func (c *Connector) HostMigrations() []connectors.HostMigration {
+	return nil
 }
 
 func (c *Connector) isAllowedHost(host string) bool {
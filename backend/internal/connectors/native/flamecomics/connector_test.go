@@ -6,6 +6,9 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
 )
 
 func TestFlameComicsConnectorResolveAndSearch(t *testing.T) {
@@ -116,3 +119,146 @@ func TestFlameComicsResolveChapterURLWithNestedAnchorMarkup(t *testing.T) {
 		t.Fatalf("unexpected chapter url: %s", chapterURL)
 	}
 }
+
+func TestFlameComicsListChapters(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/series/83", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`
+<!DOCTYPE html>
+<html>
+<body>
+  <a href="/series/83/4824f4f6a5dfb9ea">Chapter 145</a>
+  <span>February 9, 2026 5:10 PM</span>
+  <a href="/series/83/cd9daeaf1eb9b6ca"><span>Chapter 146</span><span> February 16, 2026 3:49 PM</span></a>
+  <a href="/series/83/0f0f0f0f0f0f0f0f">Chapter 144</a>
+  <a href="/series/99/aaaaaaaaaaaaaaaa">Chapter 900</a>
+</body>
+</html>`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	conn := NewConnectorWithOptions(server.URL, []string{"flamecomics.xyz"}, &http.Client{Timeout: 5 * time.Second})
+
+	chapters, err := conn.ListChapters(context.Background(), "https://flamecomics.xyz/series/83", 0)
+	if err != nil {
+		t.Fatalf("list chapters failed: %v", err)
+	}
+	if len(chapters) != 3 {
+		t.Fatalf("expected 3 chapters for series 83, got %d", len(chapters))
+	}
+	if chapters[0].Number != 146 || chapters[0].URL != "https://flamecomics.xyz/series/83/cd9daeaf1eb9b6ca" {
+		t.Fatalf("unexpected newest chapter: %+v", chapters[0])
+	}
+	if chapters[0].ReleasedAt == nil || chapters[0].ReleasedAt.Format("2006-01-02") != "2026-02-16" {
+		t.Fatalf("unexpected newest release date: %v", chapters[0].ReleasedAt)
+	}
+	if chapters[1].ReleasedAt == nil || chapters[1].ReleasedAt.Format("2006-01-02") != "2026-02-09" {
+		t.Fatalf("expected sibling release date for chapter 145, got %v", chapters[1].ReleasedAt)
+	}
+	if chapters[2].ReleasedAt != nil {
+		t.Fatalf("did not expect release date for chapter 144, got %v", chapters[2].ReleasedAt)
+	}
+}
+
+func TestFlameComicsListChaptersParsesThousandsSeparators(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/series/83", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`
+<html>
+<body>
+  <a href="/series/83/aaaaaaaaaaaaaaaa">Chapter 1,047</a>
+  <a href="/series/83/bbbbbbbbbbbbbbbb">Chapter 1,046.5</a>
+</body>
+</html>`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	conn := NewConnectorWithOptions(server.URL, []string{"flamecomics.xyz"}, &http.Client{Timeout: 5 * time.Second})
+
+	chapters, err := conn.ListChapters(context.Background(), "https://flamecomics.xyz/series/83", 0)
+	if err != nil {
+		t.Fatalf("list chapters failed: %v", err)
+	}
+	if len(chapters) != 2 || chapters[0].Number != 1047 || chapters[1].Number != 1046.5 {
+		t.Fatalf("expected chapters 1047 and 1046.5, got %+v", chapters)
+	}
+}
+
+func TestFlameComicsConnectorConformance(t *testing.T) {
+	seriesPage := func(title string, seriesID string) conformance.Fixture {
+		return conformance.Fixture{Body: `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta property="og:title" content="` + title + ` - Flame Comics">
+</head>
+<body>
+  <a href="/series/` + seriesID + `/cd9daeaf1eb9b6ca">Chapter 12</a>
+</body>
+</html>`}
+	}
+
+	conformance.RunConformanceSuite(t, func(baseURL string) connectors.Connector {
+		return NewConnectorWithOptions(baseURL, []string{"flamecomics.xyz"}, &http.Client{Timeout: 5 * time.Second})
+	}, conformance.FixtureSet{
+		Routes: map[string]conformance.Fixture{
+			"/latest": {Body: `
+<!DOCTYPE html>
+<html>
+<body>
+  <a href="/series/83">Tom &amp; Jerry&#39;s Blade</a>
+  <a href="/series/159">Second Blade</a>
+</body>
+</html>`},
+			"/series/83":  seriesPage("  Tom &amp; Jerry&#39;s\n   Blade ", "83"),
+			"/series/159": seriesPage("Second Blade", "159"),
+		},
+		SeriesURL:    "https://flamecomics.xyz/series/83",
+		SeriesTitle:  "Tom & Jerry's Blade",
+		MissingURL:   "https://flamecomics.xyz/series/404",
+		WrongHostURL: "https://example.com/series/83",
+		SearchQuery:  "blade",
+	})
+}
+
+func TestFlameComicsCanaryReportsBrokenPages(t *testing.T) {
+	seriesPage := func(title string, chapters string) string {
+		return `<!DOCTYPE html><html><head><meta property="og:title" content="` + title + `"></head><body>` + chapters + `</body></html>`
+	}
+	for _, tc := range []struct {
+		name      string
+		status    int
+		page      string
+		invariant string
+	}{
+		{name: "healthy", page: seriesPage("The Novel's Extra (Remake) - Flame Comics", `<a href="/series/83/cd9daeaf1eb9b6ca">Chapter 146</a>`)},
+		{name: "missing page", status: http.StatusNotFound, page: "gone", invariant: connectors.CanaryInvariantResolve},
+		{name: "placeholder title", page: seriesPage("- Flame Comics", `<a href="/series/83/cd9daeaf1eb9b6ca">Chapter 146</a>`), invariant: connectors.CanaryInvariantTitle},
+		{name: "no chapters", page: seriesPage("The Novel's Extra (Remake) - Flame Comics", `<p>Chapters moved</p>`), invariant: connectors.CanaryInvariantLatestChapter},
+		{name: "chapter below floor", page: seriesPage("The Novel's Extra (Remake) - Flame Comics", `<a href="/series/83/cd9daeaf1eb9b6ca">Chapter 3</a>`), invariant: connectors.CanaryInvariantLatestChapter},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/series/83" {
+					http.NotFound(w, r)
+					return
+				}
+				if tc.status != 0 {
+					w.WriteHeader(tc.status)
+				}
+				_, _ = w.Write([]byte(tc.page))
+			}))
+			defer server.Close()
+
+			conn := NewConnectorWithOptions(server.URL, []string{"flamecomics.xyz"}, &http.Client{Timeout: 5 * time.Second})
+			result := connectors.CheckCanary(context.Background(), conn, conn.Canary())
+			if result.Passed != (tc.invariant == "") || result.Invariant != tc.invariant {
+				t.Fatalf("expected failed invariant %q, got %+v", tc.invariant, result)
+			}
+		})
+	}
+}
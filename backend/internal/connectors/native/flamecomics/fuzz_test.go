@@ -0,0 +1,29 @@
+package flamecomics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
+)
+
+const fuzzSeriesPage = `
+<!DOCTYPE html>
+<html>
+<body>
+  <a href="/series/83/cd9daeaf1eb9b6ca"><p>Chapter 1,047</p><p>March 3, 2026 10:00 AM</p></a>
+  <a href="/series/83/0b1d5c3e2f4a6b7c"><p>Chapter <!-- -->1,046.5</p><p>February 24, 2026</p></a>
+</body>
+</html>`
+
+func FuzzExtractLatestChapterAndReleaseAt(f *testing.F) {
+	conformance.AddAdversarialSeeds(f, `<a href="/series/83/cd9daeaf1eb9b6ca">Chapter 12</a>`, fuzzSeriesPage)
+	f.Fuzz(func(t *testing.T, body string) {
+		conformance.CheckParse(t, body, func() {
+			chapter, _ := extractLatestChapterAndReleaseAt(body, "83")
+			if chapter != nil && (math.IsNaN(*chapter) || math.IsInf(*chapter, 0) || *chapter < 0) {
+				t.Fatalf("unexpected chapter %v", *chapter)
+			}
+		})
+	})
+}
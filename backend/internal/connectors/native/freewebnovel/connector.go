@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"html"
-	"io"
 	"math"
 	"net"
 	"net/http"
@@ -154,6 +153,16 @@ func (c *Connector) Kind() string {
 	return connectors.KindNative
 }
 
+// SearchPageURL implements connectors.SearchPageLinker.
+func (c *Connector) SearchPageURL(query string) string {
+	return c.baseURL + "/search?keyword=" + url.QueryEscape(strings.TrimSpace(query))
+}
+
+// Canary implements connectors.CanaryProvider with Trash of the Count's Family.
+func (c *Connector) Canary() connectors.Canary {
+	return connectors.Canary{URL: "https://freewebnovel.com/novel/trash-of-the-counts-family", TitleContains: "Trash of the Count", MinLatestChapter: 800}
+}
+
 func (c *Connector) HealthCheck(ctx context.Context) error {
 	_, err := c.fetchPage(ctx, c.baseURL+"/home", "")
 	if err == nil {
@@ -355,12 +364,12 @@ func parseSearchEntries(body string) []searchEntry {
 }
 
 func extractTitle(body string, slug string) string {
-	title := strings.TrimSpace(html.UnescapeString(firstSubmatch(ogTitlePattern, body)))
+	title := cleanText(firstSubmatch(ogTitlePattern, body))
 	if title != "" {
 		return title
 	}
 
-	title = strings.TrimSpace(html.UnescapeString(firstSubmatch(novelNamePattern, body)))
+	title = cleanText(firstSubmatch(novelNamePattern, body))
 	if title != "" {
 		return title
 	}
@@ -475,16 +484,19 @@ func (c *Connector) fetchPage(ctx context.Context, endpoint string, referer stri
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("unexpected status: %d: %w", res.StatusCode, connectors.ErrNotFound)
+	}
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		return "", fmt.Errorf("unexpected status: %d", res.StatusCode)
 	}
 
-	rawBody, err := io.ReadAll(res.Body)
+	body, err := connectors.ReadPage(res.Body)
 	if err != nil {
 		return "", fmt.Errorf("read response body: %w", err)
 	}
 
-	return string(rawBody), nil
+	return body, nil
 }
 
 func parseLatestChapterFromURL(raw string) *float64 {
@@ -496,7 +508,7 @@ func parseLatestChapterFromURL(raw string) *float64 {
 }
 
 func parseChapterNumber(raw string) *float64 {
-	value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	value, err := connectors.ParseChapterNumber(raw)
 	if err != nil {
 		return nil
 	}
@@ -557,7 +569,7 @@ func prettifySlug(slug string) string {
 		if len(parts[index]) == 0 {
 			continue
 		}
-		parts[index] = strings.ToUpper(parts[index][:1]) + parts[index][1:]
+		parts[index] = connectors.UpperFirst(parts[index])
 	}
 	return strings.Join(parts, " ")
 }
@@ -577,6 +589,27 @@ func firstSubmatch(pattern *regexp.Regexp, raw string) string {
 	return matches[1]
 }
 
+// MediaHosts implements connectors.MediaHostProvider. Covers are served
+// from the site's own hosts.
+func (c *Connector) MediaHosts() []string {
+	return append([]string(nil), c.allowedHost...)
+}
+
+// ValidateURL implements connectors.URLValidator.
+func (c *Connector) ValidateURL(rawURL string) (string, error) {
+	return connectors.CanonicalHostURL(rawURL, c.allowedHost)
+}
+
+// CanonicalScheme implements connectors.URLMigrator.
+func (c *Connector) CanonicalScheme() string {
+	return "https"
+}
+
+// HostMigrations implements connectors.URLMigrator.
+func (c *Connector) HostMigrations() []connectors.HostMigration {
+	return nil
+}
+
 func (c *Connector) isAllowedHost(host string) bool {
 	host = strings.ToLower(strings.TrimSpace(host))
 	for _, allowed := range c.allowedHost {
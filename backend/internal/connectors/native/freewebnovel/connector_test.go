@@ -6,6 +6,9 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
 )
 
 const searchResultsHTML = `
@@ -282,3 +285,42 @@ func contains(values []string, target string) bool {
 	}
 	return false
 }
+
+func TestFreeWebNovelConnectorConformance(t *testing.T) {
+	searchRow := func(slug string, title string) string {
+		return `
+    <div class="li-row">
+      <div class="txt">
+        <h3 class="tit"><a href="/novel/` + slug + `" title="` + title + `">` + title + `</a></h3>
+        <a href="/novel/` + slug + `/chapter-12" class="chapter" title="Chapter 12"><span class="s1">12 Chapters</span></a>
+      </div>
+    </div>`
+	}
+
+	conformance.RunConformanceSuite(t, func(baseURL string) connectors.Connector {
+		return NewConnectorWithOptions(baseURL, []string{"freewebnovel.com"}, &http.Client{Timeout: 5 * time.Second})
+	}, conformance.FixtureSet{
+		Routes: map[string]conformance.Fixture{
+			"/": {Body: `<!DOCTYPE html><html><body>ok</body></html>`},
+			"/search": {Body: `<!DOCTYPE html><html><body><div class="ul-list1">` +
+				searchRow("tom-and-jerrys-blade", "Tom &amp; Jerry&#39;s\n   Blade") +
+				searchRow("second-blade", "Second Blade") +
+				`</div></body></html>`},
+			"/novel/tom-and-jerrys-blade": {Body: `
+<!DOCTYPE html>
+<html>
+<head>
+  <meta property="og:title" content="  Tom &amp; Jerry&#39;s
+   Blade ">
+  <meta property="og:novel:lastest_chapter_url" content="https://freewebnovel.com/novel/tom-and-jerrys-blade/chapter-12">
+</head>
+<body></body>
+</html>`},
+		},
+		SeriesURL:    "https://freewebnovel.com/novel/tom-and-jerrys-blade",
+		SeriesTitle:  "Tom & Jerry's Blade",
+		MissingURL:   "https://freewebnovel.com/novel/missing-novel",
+		WrongHostURL: "https://example.com/novel/tom-and-jerrys-blade",
+		SearchQuery:  "blade",
+	})
+}
@@ -0,0 +1,53 @@
+package freewebnovel
+
+import (
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
+)
+
+const fuzzSearchPage = `<!DOCTYPE html><html><body><div class="ul-list1">
+    <div class="li-row">
+      <div class="pic"><img src="/files/article/image/1/1.jpg"></div>
+      <div class="txt">
+        <h3 class="tit"><a href="/novel/tom-and-jerrys-blade" title="Tom &amp; Jerry's Blade">Tom &amp; Jerry's Blade</a></h3>
+        <a href="/novel/tom-and-jerrys-blade/chapter-12" class="chapter" title="Chapter 12"><span class="s1">12 Chapters</span></a>
+      </div>
+    </div>
+    <div class="li-row">
+      <div class="txt">
+        <h3 class="tit"><a href="/novel/ébène-blade"></a></h3>
+      </div>
+    </div>
+</div></body></html>`
+
+const fuzzNovelPage = `<!DOCTYPE html><html><head>
+  <meta property="og:title" content="Tom &amp; Jerry's Blade">
+</head><body>
+  <div class="m-imgtxt"><div class="item"><span title="Alternative names" class="glyphicon"></span><div class="right"><span class="s1">Blade of Tom, 汤姆之刃, The Blade</span></div></div></div>
+</body></html>`
+
+func FuzzParseSearchEntries(f *testing.F) {
+	conformance.AddAdversarialSeeds(f, `<div class="li-row"><h3 class="tit"><a href="/novel/blade">`, fuzzSearchPage)
+	f.Fuzz(func(t *testing.T, body string) {
+		conformance.CheckParse(t, body, func() {
+			for _, entry := range parseSearchEntries(body) {
+				if entry.Slug == "" || entry.Title == "" {
+					t.Fatalf("expected a slug and title, got %+v", entry)
+				}
+				conformance.CheckText(t, body, "title", entry.Title)
+			}
+		})
+	})
+}
+
+func FuzzExtractRelatedTitles(f *testing.F) {
+	conformance.AddAdversarialSeeds(f, `<span title="Alternative names"></span><div class="right">`, fuzzNovelPage)
+	f.Fuzz(func(t *testing.T, body string) {
+		conformance.CheckParse(t, body, func() {
+			for _, title := range extractRelatedTitles(body, "Tom & Jerry's Blade") {
+				conformance.CheckText(t, body, "related title", title)
+			}
+		})
+	})
+}
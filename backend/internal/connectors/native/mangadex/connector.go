@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"math"
 	"net/http"
 	"net/url"
@@ -17,7 +18,10 @@ import (
 	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
 )
 
-var titleIDPattern = regexp.MustCompile(`^[0-9a-fA-F-]{32,36}$`)
+var (
+	titleIDPattern    = regexp.MustCompile(`^[0-9a-fA-F-]{32,36}$`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
 
 type Connector struct {
 	apiBaseURL  string
@@ -45,6 +49,11 @@ func NewConnectorWithOptions(apiBaseURL string, allowedHost []string, client *ht
 	return &Connector{apiBaseURL: strings.TrimRight(apiBaseURL, "/"), allowedHost: allowedHost, httpClient: client}
 }
 
+// HTTPClient implements connectors.HTTPClientProvider.
+func (c *Connector) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
 func (c *Connector) Key() string {
 	return "mangadex"
 }
@@ -57,6 +66,16 @@ func (c *Connector) Kind() string {
 	return connectors.KindNative
 }
 
+// SearchPageURL implements connectors.SearchPageLinker.
+func (c *Connector) SearchPageURL(query string) string {
+	return "https://mangadex.org/search?q=" + url.QueryEscape(strings.TrimSpace(query))
+}
+
+// Canary implements connectors.CanaryProvider with One Piece.
+func (c *Connector) Canary() connectors.Canary {
+	return connectors.Canary{URL: "https://mangadex.org/title/a1c7c817-4e59-43b7-9365-09675a149a6f", TitleContains: "One Piece", MinLatestChapter: 1}
+}
+
 func (c *Connector) HealthCheck(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiBaseURL+"/ping", nil)
 	if err != nil {
@@ -77,27 +96,22 @@ func (c *Connector) HealthCheck(ctx context.Context) error {
 }
 
 func (c *Connector) ResolveByURL(ctx context.Context, rawURL string) (*connectors.MangaResult, error) {
-	trimmed := strings.TrimSpace(rawURL)
-	if trimmed == "" {
-		return nil, fmt.Errorf("url is required")
-	}
+	return c.ResolveByURLWithLang(ctx, rawURL, connectors.DefaultLanguage)
+}
 
-	parsed, err := url.Parse(trimmed)
+// ResolveByURLWithLang implements connectors.LanguageAwareResolver. The
+// latest chapter and its release time come from the chapters translated to
+// lang.
+func (c *Connector) ResolveByURLWithLang(ctx context.Context, rawURL string, lang string) (*connectors.MangaResult, error) {
+	lang, err := connectors.NormalizeLanguage(lang)
 	if err != nil {
-		return nil, fmt.Errorf("invalid url: %w", err)
-	}
-	if !c.isAllowedHost(parsed.Hostname()) {
-		return nil, fmt.Errorf("url does not belong to mangadex")
-	}
-
-	segments := strings.Split(strings.Trim(path.Clean(parsed.Path), "/"), "/")
-	if len(segments) < 2 || segments[0] != "title" {
-		return nil, fmt.Errorf("mangadex url must match /title/{id}")
+		return nil, err
 	}
 
-	titleID := segments[1]
-	if !titleIDPattern.MatchString(titleID) {
-		return nil, fmt.Errorf("invalid mangadex title id")
+	trimmed := strings.TrimSpace(rawURL)
+	titleID, err := c.titleIDFromURL(trimmed)
+	if err != nil {
+		return nil, err
 	}
 
 	values := url.Values{}
@@ -114,6 +128,9 @@ func (c *Connector) ResolveByURL(ctx context.Context, rawURL string) (*connector
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("mangadex returned status %d: %w", res.StatusCode, connectors.ErrNotFound)
+	}
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		return nil, fmt.Errorf("mangadex returned status %d", res.StatusCode)
 	}
@@ -123,8 +140,131 @@ func (c *Connector) ResolveByURL(ctx context.Context, rawURL string) (*connector
 		return nil, fmt.Errorf("decode mangadex response: %w", err)
 	}
 
-	title := pickBestTitle(payload.Data.Attributes.Title)
-	relatedTitles := collectEnglishRelatedTitles(title, payload.Data.Attributes.Title, payload.Data.Attributes.AltTitles)
+	result := c.mangaResult(payload.Data, trimmed)
+	result.LatestChapter = parseChapterNumber(payload.Data.Attributes.LastChapter)
+	feed, _ := c.fetchLatestChapterFromFeed(ctx, titleID, lang, time.Now())
+	if result.LatestChapter == nil {
+		result.LatestChapter = feed.latest
+	}
+	result.LastUpdatedAt = feed.latestReleaseAt
+	result.NextScheduledChapter = feed.scheduled
+	result.NextScheduledAt = feed.scheduledAt
+
+	return &result, nil
+}
+
+// MaxBatchSize implements connectors.BulkResolver; 100 is the most entries
+// a MangaDex list endpoint returns at once.
+func (c *Connector) MaxBatchSize() int {
+	return 100
+}
+
+// ResolveMany implements connectors.BulkResolver with two requests however
+// many URLs there are: the manga list filtered by id, then the chapter list
+// filtered by each manga's latest uploaded chapter for its number and
+// release time. A manga whose latest upload is not in English, or has no
+// chapter number, is left out so ResolveByURL reads it from the feed, as are
+// URLs that are not MangaDex titles.
+func (c *Connector) ResolveMany(ctx context.Context, urls []string) (map[string]*connectors.MangaResult, error) {
+	urlsByID := make(map[string][]string, len(urls))
+	titleIDs := make([]string, 0, len(urls))
+	for _, rawURL := range urls {
+		titleID, err := c.titleIDFromURL(strings.TrimSpace(rawURL))
+		if err != nil {
+			continue
+		}
+		key := strings.ToLower(titleID)
+		if _, ok := urlsByID[key]; !ok {
+			titleIDs = append(titleIDs, titleID)
+		}
+		urlsByID[key] = append(urlsByID[key], rawURL)
+	}
+	results := make(map[string]*connectors.MangaResult, len(urls))
+	if len(titleIDs) == 0 {
+		return results, nil
+	}
+	if len(titleIDs) > c.MaxBatchSize() {
+		return nil, fmt.Errorf("at most %d mangadex titles can be resolved at once", c.MaxBatchSize())
+	}
+
+	values := url.Values{}
+	values.Set("limit", strconv.Itoa(len(titleIDs)))
+	for _, titleID := range titleIDs {
+		values.Add("ids[]", titleID)
+	}
+	values.Add("includes[]", "cover_art")
+	addContentRatings(values)
+
+	var listed mangaListResponse
+	if err := c.getJSON(ctx, c.apiBaseURL+"/manga?"+values.Encode(), &listed); err != nil {
+		return nil, fmt.Errorf("list manga by ids: %w", err)
+	}
+
+	chapterIDs := make([]string, 0, len(listed.Data))
+	for _, manga := range listed.Data {
+		if chapterID := strings.TrimSpace(manga.Attributes.LatestUploadedChapter); chapterID != "" {
+			chapterIDs = append(chapterIDs, chapterID)
+		}
+	}
+	if len(chapterIDs) == 0 {
+		return results, nil
+	}
+
+	values = url.Values{}
+	values.Set("limit", strconv.Itoa(len(chapterIDs)))
+	for _, chapterID := range chapterIDs {
+		values.Add("ids[]", chapterID)
+	}
+	values.Set("includeExternalUrl", "0")
+	values.Add("translatedLanguage[]", connectors.DefaultLanguage)
+	addContentRatings(values)
+
+	var chapters mangaFeedResponse
+	if err := c.getJSON(ctx, c.apiBaseURL+"/chapter?"+values.Encode(), &chapters); err != nil {
+		return nil, fmt.Errorf("list latest chapters by ids: %w", err)
+	}
+	now := time.Now()
+	chapterByID := make(map[string]int, len(chapters.Data))
+	for index, chapter := range chapters.Data {
+		chapterByID[chapter.ID] = index
+	}
+
+	for _, manga := range listed.Data {
+		index, ok := chapterByID[strings.TrimSpace(manga.Attributes.LatestUploadedChapter)]
+		if !ok {
+			continue
+		}
+		latest := chapters.Data[index].Attributes
+		uploadedChapter := parseChapterNumber(latest.Chapter)
+		if uploadedChapter == nil {
+			continue
+		}
+		latestChapter := parseChapterNumber(manga.Attributes.LastChapter)
+		if latestChapter == nil {
+			latestChapter = uploadedChapter
+		}
+		releaseAt := parseOptionalRFC3339Time(latest.PublishAt, latest.ReadableAt, latest.CreatedAt)
+		if releaseAt != nil && releaseAt.After(now) {
+			// Scheduled ahead of its release; the feed knows the one out.
+			continue
+		}
+
+		for _, rawURL := range urlsByID[strings.ToLower(manga.ID)] {
+			result := c.mangaResult(manga, strings.TrimSpace(rawURL))
+			result.LatestChapter = latestChapter
+			result.LastUpdatedAt = releaseAt
+			results[rawURL] = &result
+		}
+	}
+
+	return results, nil
+}
+
+// mangaResult fills in what a manga entry says about the series itself,
+// leaving the latest chapter and its release time to the caller.
+func (c *Connector) mangaResult(manga mangaData, rawURL string) connectors.MangaResult {
+	title := pickBestTitle(manga.Attributes.Title)
+	relatedTitles := collectEnglishRelatedTitles(title, manga.Attributes.Title, manga.Attributes.AltTitles)
 	if title == "" {
 		if len(relatedTitles) > 0 {
 			title = relatedTitles[0]
@@ -132,24 +272,70 @@ func (c *Connector) ResolveByURL(ctx context.Context, rawURL string) (*connector
 			title = "Untitled"
 		}
 	}
-	relatedTitles = removePrimaryTitle(relatedTitles, title)
-
-	latestChapter := parseChapterNumber(payload.Data.Attributes.LastChapter)
-	feedLatestChapter, latestReleaseAt, _ := c.fetchLatestChapterFromFeed(ctx, titleID)
-	if latestChapter == nil {
-		latestChapter = feedLatestChapter
-	}
 
-	return &connectors.MangaResult{
+	return connectors.MangaResult{
 		SourceKey:     c.Key(),
-		SourceItemID:  payload.Data.ID,
+		SourceItemID:  manga.ID,
 		Title:         title,
-		RelatedTitles: relatedTitles,
-		URL:           trimmed,
-		CoverImageURL: pickCoverImageURL(payload.Data.ID, payload.Data.Relationships),
-		LatestChapter: latestChapter,
-		LastUpdatedAt: latestReleaseAt,
-	}, nil
+		RelatedTitles: removePrimaryTitle(relatedTitles, title),
+		URL:           rawURL,
+		CoverImageURL: pickCoverImageURL(manga.ID, manga.Relationships),
+	}
+}
+
+// titleIDFromURL returns the title id of a mangadex.org/title/{id} URL.
+func (c *Connector) titleIDFromURL(rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", fmt.Errorf("url is required")
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if !c.isAllowedHost(parsed.Hostname()) {
+		return "", fmt.Errorf("url does not belong to mangadex")
+	}
+
+	segments := strings.Split(strings.Trim(path.Clean(parsed.Path), "/"), "/")
+	if len(segments) < 2 || segments[0] != "title" {
+		return "", fmt.Errorf("mangadex url must match /title/{id}")
+	}
+
+	titleID := segments[1]
+	if !titleIDPattern.MatchString(titleID) {
+		return "", fmt.Errorf("invalid mangadex title id")
+	}
+	return titleID, nil
+}
+
+func (c *Connector) getJSON(ctx context.Context, requestURL string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("mangadex returned status %d", res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return fmt.Errorf("decode mangadex response: %w", err)
+	}
+	return nil
+}
+
+// addContentRatings asks for every content rating; MangaDex list endpoints
+// leave pornographic entries out unless told otherwise.
+func addContentRatings(values url.Values) {
+	for _, rating := range []string{"safe", "suggestive", "erotica", "pornographic"} {
+		values.Add("contentRating[]", rating)
+	}
 }
 
 func (c *Connector) SearchByTitle(ctx context.Context, title string, limit int) ([]connectors.MangaResult, error) {
@@ -225,7 +411,8 @@ func (c *Connector) SearchByTitle(ctx context.Context, title string, limit int)
 
 		latestChapter := parseChapterNumber(item.Attributes.LastChapter)
 		if latestChapter == nil {
-			latestChapter, _, _ = c.fetchLatestChapterFromFeed(ctx, item.ID)
+			feed, _ := c.fetchLatestChapterFromFeed(ctx, item.ID, connectors.DefaultLanguage, time.Now())
+			latestChapter = feed.latest
 		}
 
 		items = append(items, connectors.MangaResult{
@@ -326,6 +513,27 @@ func (c *Connector) ResolveChapterURL(ctx context.Context, rawURL string, chapte
 	return "", fmt.Errorf("chapter %.3f not found", chapter)
 }
 
+// MediaHosts implements connectors.MediaHostProvider. Covers are served
+// from the site's own hosts.
+func (c *Connector) MediaHosts() []string {
+	return append([]string(nil), c.allowedHost...)
+}
+
+// ValidateURL implements connectors.URLValidator.
+func (c *Connector) ValidateURL(rawURL string) (string, error) {
+	return connectors.CanonicalHostURL(rawURL, c.allowedHost)
+}
+
+// CanonicalScheme implements connectors.URLMigrator.
+func (c *Connector) CanonicalScheme() string {
+	return "https"
+}
+
+// HostMigrations implements connectors.URLMigrator.
+func (c *Connector) HostMigrations() []connectors.HostMigration {
+	return nil
+}
+
 func (c *Connector) isAllowedHost(host string) bool {
 	host = strings.ToLower(strings.TrimSpace(host))
 	for _, allowed := range c.allowedHost {
@@ -342,26 +550,32 @@ func pickBestTitle(titleMap map[string]string) string {
 		return ""
 	}
 	for _, key := range []string{"en", "ja-ro", "ja", "pt-br", "es"} {
-		if value := strings.TrimSpace(titleMap[key]); value != "" {
+		if value := cleanTitle(titleMap[key]); value != "" {
 			return value
 		}
 	}
 	for _, value := range titleMap {
-		if strings.TrimSpace(value) != "" {
-			return strings.TrimSpace(value)
+		if value = cleanTitle(value); value != "" {
+			return value
 		}
 	}
 	return ""
 }
 
+// cleanTitle decodes the HTML entities and stray line breaks some MangaDex
+// titles carry, since the API returns them exactly as uploaders typed them.
+func cleanTitle(raw string) string {
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(html.UnescapeString(raw), " "))
+}
+
 func collectEnglishRelatedTitles(primaryTitle string, titleMap map[string]string, altTitles []map[string]string) []string {
 	candidates := make([]string, 0, len(titleMap)+(len(altTitles)*2))
 	for _, value := range titleMap {
-		candidates = append(candidates, value)
+		candidates = append(candidates, cleanTitle(value))
 	}
 	for _, altTitleMap := range altTitles {
 		for _, value := range altTitleMap {
-			candidates = append(candidates, value)
+			candidates = append(candidates, cleanTitle(value))
 		}
 	}
 
@@ -433,7 +647,7 @@ func parseChapterNumber(raw string) *float64 {
 		return nil
 	}
 
-	parsed, err := strconv.ParseFloat(value, 64)
+	parsed, err := connectors.ParseChapterNumber(value)
 	if err != nil {
 		return nil
 	}
@@ -441,9 +655,19 @@ func parseChapterNumber(raw string) *float64 {
 	return &parsed
 }
 
-func (c *Connector) fetchLatestChapterFromFeed(ctx context.Context, mangaID string) (*float64, *time.Time, error) {
+// feedLatest is what a title's feed says about its newest chapters: the
+// highest numbered one out by now, and the lowest numbered one above it that
+// is published later, if any.
+type feedLatest struct {
+	latest          *float64
+	latestReleaseAt *time.Time
+	scheduled       *float64
+	scheduledAt     *time.Time
+}
+
+func (c *Connector) fetchLatestChapterFromFeed(ctx context.Context, mangaID string, lang string, now time.Time) (feedLatest, error) {
 	if strings.TrimSpace(mangaID) == "" {
-		return nil, nil, nil
+		return feedLatest{}, nil
 	}
 
 	values := url.Values{}
@@ -451,7 +675,7 @@ func (c *Connector) fetchLatestChapterFromFeed(ctx context.Context, mangaID stri
 	values.Set("offset", "0")
 	values.Set("order[chapter]", "desc")
 	values.Set("includeExternalUrl", "0")
-	values.Add("translatedLanguage[]", "en")
+	values.Add("translatedLanguage[]", lang)
 	values.Add("contentRating[]", "safe")
 	values.Add("contentRating[]", "suggestive")
 	values.Add("contentRating[]", "erotica")
@@ -460,42 +684,61 @@ func (c *Connector) fetchLatestChapterFromFeed(ctx context.Context, mangaID stri
 	feedURL := c.apiBaseURL + "/manga/" + mangaID + "/feed?" + values.Encode()
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
 	if err != nil {
-		return nil, nil, fmt.Errorf("create feed request: %w", err)
+		return feedLatest{}, fmt.Errorf("create feed request: %w", err)
 	}
 
 	res, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, nil, fmt.Errorf("request feed: %w", err)
+		return feedLatest{}, fmt.Errorf("request feed: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return nil, nil, fmt.Errorf("mangadex feed returned status %d", res.StatusCode)
+		return feedLatest{}, fmt.Errorf("mangadex feed returned status %d", res.StatusCode)
 	}
 
 	var payload mangaFeedResponse
 	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
-		return nil, nil, fmt.Errorf("decode feed response: %w", err)
+		return feedLatest{}, fmt.Errorf("decode feed response: %w", err)
 	}
 
-	var latest *float64
-	var latestReleaseAt *time.Time
+	var feed feedLatest
+	type scheduledChapter struct {
+		number float64
+		at     *time.Time
+	}
+	upcoming := make([]scheduledChapter, 0)
 	for _, chapter := range payload.Data {
 		parsed := parseChapterNumber(chapter.Attributes.Chapter)
 		if parsed == nil {
 			continue
 		}
-		if latest == nil || *parsed > *latest {
-			latest = parsed
-			latestReleaseAt = parseOptionalRFC3339Time(
-				chapter.Attributes.PublishAt,
-				chapter.Attributes.ReadableAt,
-				chapter.Attributes.CreatedAt,
-			)
+		releaseAt := parseOptionalRFC3339Time(
+			chapter.Attributes.PublishAt,
+			chapter.Attributes.ReadableAt,
+			chapter.Attributes.CreatedAt,
+		)
+		if releaseAt != nil && releaseAt.After(now) {
+			upcoming = append(upcoming, scheduledChapter{number: *parsed, at: releaseAt})
+			continue
+		}
+		if feed.latest == nil || *parsed > *feed.latest {
+			feed.latest = parsed
+			feed.latestReleaseAt = releaseAt
+		}
+	}
+	for _, chapter := range upcoming {
+		if feed.latest != nil && chapter.number <= *feed.latest {
+			continue
+		}
+		if feed.scheduled == nil || chapter.number < *feed.scheduled {
+			number := chapter.number
+			feed.scheduled = &number
+			feed.scheduledAt = chapter.at
 		}
 	}
 
-	return latest, latestReleaseAt, nil
+	return feed, nil
 }
 
 func parseOptionalRFC3339Time(values ...string) *time.Time {
@@ -513,16 +756,23 @@ func parseOptionalRFC3339Time(values ...string) *time.Time {
 	return nil
 }
 
+type mangaData struct {
+	ID         string `json:"id"`
+	Attributes struct {
+		Title                 map[string]string   `json:"title"`
+		AltTitles             []map[string]string `json:"altTitles"`
+		LastChapter           string              `json:"lastChapter"`
+		LatestUploadedChapter string              `json:"latestUploadedChapter"`
+	} `json:"attributes"`
+	Relationships []mangaRelationship `json:"relationships"`
+}
+
 type mangaByIDResponse struct {
-	Data struct {
-		ID         string `json:"id"`
-		Attributes struct {
-			Title       map[string]string   `json:"title"`
-			AltTitles   []map[string]string `json:"altTitles"`
-			LastChapter string              `json:"lastChapter"`
-		} `json:"attributes"`
-		Relationships []mangaRelationship `json:"relationships"`
-	} `json:"data"`
+	Data mangaData `json:"data"`
+}
+
+type mangaListResponse struct {
+	Data []mangaData `json:"data"`
 }
 
 type mangaSearchResponse struct {
@@ -3,10 +3,18 @@ package mangadex
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
 )
 
 func TestMangaDexConnector(t *testing.T) {
@@ -131,3 +139,214 @@ func TestMangaDexConnector(t *testing.T) {
 		t.Fatalf("expected 0 results for non-English alias query, got %d", len(nonEnglishResults))
 	}
 }
+
+func TestMangaDexConnectorConformance(t *testing.T) {
+	titleJSON := `{"en": "  Tom &amp; Jerry&#39;s\n   Blade "}`
+	conformance.RunConformanceSuite(t, func(baseURL string) connectors.Connector {
+		return NewConnectorWithOptions(baseURL, []string{"mangadex.org"}, &http.Client{Timeout: 5 * time.Second})
+	}, conformance.FixtureSet{
+		Routes: map[string]conformance.Fixture{
+			"/manga/123e4567-e89b-12d3-a456-426614174000": {ContentType: "application/json", Body: `{
+  "data": {
+    "id": "123e4567-e89b-12d3-a456-426614174000",
+    "attributes": {"title": ` + titleJSON + `, "altTitles": [{"en": "Tom &amp; Jerry&#39;s Sword"}], "lastChapter": "12"}
+  }
+}`},
+			"/manga": {ContentType: "application/json", Body: `{
+  "data": [
+    {"id": "123e4567-e89b-12d3-a456-426614174000", "attributes": {"title": ` + titleJSON + `, "lastChapter": "12"}},
+    {"id": "223e4567-e89b-12d3-a456-426614174000", "attributes": {"title": {"en": "Second Blade"}, "lastChapter": "3"}}
+  ]
+}`},
+		},
+		SeriesURL:    "https://mangadex.org/title/123e4567-e89b-12d3-a456-426614174000",
+		SeriesTitle:  "Tom & Jerry's Blade",
+		MissingURL:   "https://mangadex.org/title/ffffffff-e89b-12d3-a456-426614174000",
+		WrongHostURL: "https://example.com/title/123e4567-e89b-12d3-a456-426614174000",
+		SearchQuery:  "blade",
+	})
+}
+
+func TestMangaDexConnectorResolveByURLWithLang(t *testing.T) {
+	const titleID = "123e4567-e89b-12d3-a456-426614174000"
+	requestedLanguages := make([]string, 0)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/manga/"+titleID, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"id": "` + titleID + `", "attributes": {"title": {"en": "Polyglot Blade"}}}}`))
+	})
+	mux.HandleFunc("/manga/"+titleID+"/feed", func(w http.ResponseWriter, r *http.Request) {
+		lang := r.URL.Query().Get("translatedLanguage[]")
+		requestedLanguages = append(requestedLanguages, lang)
+		switch lang {
+		case "en":
+			_, _ = w.Write([]byte(`{"data": [{"attributes": {"chapter": "40", "publishAt": "2026-01-02T10:00:00+00:00"}}]}`))
+		case "pt-br":
+			_, _ = w.Write([]byte(`{"data": [{"attributes": {"chapter": "31", "publishAt": "2025-11-20T08:00:00+00:00"}}, {"attributes": {"chapter": "30"}}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"data": []}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	connector := NewConnectorWithOptions(server.URL, []string{"mangadex.org"}, &http.Client{Timeout: 5 * time.Second})
+	var _ connectors.LanguageAwareResolver = connector
+	seriesURL := "https://mangadex.org/title/" + titleID
+
+	resolved, err := connector.ResolveByURLWithLang(context.Background(), seriesURL, "PT_BR")
+	if err != nil {
+		t.Fatalf("resolve in pt-br failed: %v", err)
+	}
+	if resolved.LatestChapter == nil || *resolved.LatestChapter != 31 {
+		t.Fatalf("expected pt-br latest chapter 31, got %v", resolved.LatestChapter)
+	}
+	if resolved.LastUpdatedAt == nil || !resolved.LastUpdatedAt.Equal(time.Date(2025, 11, 20, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected pt-br release time, got %v", resolved.LastUpdatedAt)
+	}
+
+	resolved, err = connector.ResolveByURL(context.Background(), seriesURL)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if resolved.LatestChapter == nil || *resolved.LatestChapter != 40 {
+		t.Fatalf("expected english latest chapter 40 by default, got %v", resolved.LatestChapter)
+	}
+
+	if len(requestedLanguages) != 2 || requestedLanguages[0] != "pt-br" || requestedLanguages[1] != "en" {
+		t.Fatalf("unexpected feed languages %v", requestedLanguages)
+	}
+
+	if _, err := connector.ResolveByURLWithLang(context.Background(), seriesURL, "not a language"); err == nil {
+		t.Fatalf("expected an invalid language to be rejected")
+	}
+}
+
+func TestMangaDexConnectorReportsMissingTitlesAsNotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/manga/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "00000000-0000-0000-0000-000000000000") {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	connector := NewConnectorWithOptions(server.URL, []string{"mangadex.org"}, &http.Client{Timeout: 5 * time.Second})
+
+	_, err := connector.ResolveByURL(context.Background(), "https://mangadex.org/title/00000000-0000-0000-0000-000000000000")
+	if !errors.Is(err, connectors.ErrNotFound) {
+		t.Fatalf("expected a 404 to match ErrNotFound, got %v", err)
+	}
+	_, err = connector.ResolveByURL(context.Background(), "https://mangadex.org/title/123e4567-e89b-12d3-a456-426614174000")
+	if err == nil || errors.Is(err, connectors.ErrNotFound) {
+		t.Fatalf("expected a 503 to fail without matching ErrNotFound, got %v", err)
+	}
+}
+
+func TestMangaDexConnectorResolveManyBatchesRequests(t *testing.T) {
+	fixture := func(name string) []byte {
+		body, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("read fixture %s: %v", name, err)
+		}
+		return body
+	}
+	mangaBody, chapterBody := fixture("manga_by_ids.json"), fixture("latest_chapters.json")
+
+	requests := make([]*url.URL, 0, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/manga":
+			_, _ = w.Write(mangaBody)
+		case "/chapter":
+			_, _ = w.Write(chapterBody)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	connector := NewConnectorWithOptions(server.URL, []string{"mangadex.org"}, &http.Client{Timeout: 5 * time.Second})
+	var _ connectors.BulkResolver = connector
+	onePiece := "https://mangadex.org/title/a1c7c817-4e59-43b7-9365-09675a149a6f"
+	onePieceSlug := onePiece + "/one-piece"
+	soloLeveling := "https://mangadex.org/title/32d76d19-8a05-4db0-9fc2-e0b0648fe9d0"
+	chainsawMan := "https://mangadex.org/title/d8a959f7-648e-4c8d-8f23-f1f3f8e129f3"
+	missing := "https://mangadex.org/title/ffffffff-e89b-12d3-a456-426614174000"
+	wrongHost := "https://example.com/title/a1c7c817-4e59-43b7-9365-09675a149a6f"
+
+	results, err := connector.ResolveMany(context.Background(), []string{onePiece, onePieceSlug, soloLeveling, chainsawMan, missing, wrongHost})
+	if err != nil {
+		t.Fatalf("resolve many failed: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected one manga and one chapter request for six urls, got %v", requests)
+	}
+	if ids := requests[0].Query()["ids[]"]; len(ids) != 4 {
+		t.Fatalf("expected the four distinct title ids in one request, got %v", ids)
+	}
+	if chapterIDs := requests[1].Query()["ids[]"]; len(chapterIDs) != 3 || requests[1].Query().Get("translatedLanguage[]") != "en" {
+		t.Fatalf("expected the latest uploads looked up in english, got %v", requests[1].Query())
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected both One Piece urls and Solo Leveling resolved, got %v", results)
+	}
+	for _, rawURL := range []string{onePiece, onePieceSlug} {
+		result := results[rawURL]
+		if result == nil || result.Title != "One Piece" || result.URL != rawURL || result.LatestChapter == nil || *result.LatestChapter != 1160 {
+			t.Fatalf("expected %s at chapter 1160, got %+v", rawURL, result)
+		}
+		if result.LastUpdatedAt == nil || !result.LastUpdatedAt.Equal(time.Date(2026, 10, 5, 15, 0, 0, 0, time.UTC)) {
+			t.Fatalf("expected the latest upload's release time, got %v", result.LastUpdatedAt)
+		}
+		if !strings.HasSuffix(result.CoverImageURL, "/one-piece.jpg.256.jpg") {
+			t.Fatalf("expected the cover from the listing, got %q", result.CoverImageURL)
+		}
+	}
+	if result := results[soloLeveling]; result == nil || result.LatestChapter == nil || *result.LatestChapter != 179 {
+		t.Fatalf("expected Solo Leveling at its last chapter, got %+v", result)
+	}
+	for _, rawURL := range []string{chainsawMan, missing, wrongHost} {
+		if _, ok := results[rawURL]; ok {
+			t.Fatalf("expected %s to be left for ResolveByURL", rawURL)
+		}
+	}
+}
+
+func TestMangaDexConnectorReportsScheduledChaptersApart(t *testing.T) {
+	const titleID = "123e4567-e89b-12d3-a456-426614174000"
+	releasedAt := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	nextAt := time.Now().Add(18 * time.Hour).UTC().Truncate(time.Second)
+	laterAt := time.Now().Add(8 * 24 * time.Hour).UTC().Truncate(time.Second)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/manga/"+titleID, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"id": "` + titleID + `", "attributes": {"title": {"en": "Weekly Blade"}}}}`))
+	})
+	mux.HandleFunc("/manga/"+titleID+"/feed", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"data": [
+			{"attributes": {"chapter": "42", "publishAt": "` + laterAt.Format(time.RFC3339) + `"}},
+			{"attributes": {"chapter": "41", "publishAt": "` + nextAt.Format(time.RFC3339) + `"}},
+			{"attributes": {"chapter": "40", "publishAt": "` + releasedAt.Format(time.RFC3339) + `"}}
+		]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	connector := NewConnectorWithOptions(server.URL, []string{"mangadex.org"}, &http.Client{Timeout: 5 * time.Second})
+	resolved, err := connector.ResolveByURL(context.Background(), "https://mangadex.org/title/"+titleID)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if resolved.LatestChapter == nil || *resolved.LatestChapter != 40 || resolved.LastUpdatedAt == nil || !resolved.LastUpdatedAt.Equal(releasedAt) {
+		t.Fatalf("expected chapter 40 as the latest released, got %v at %v", resolved.LatestChapter, resolved.LastUpdatedAt)
+	}
+	if resolved.NextScheduledChapter == nil || *resolved.NextScheduledChapter != 41 || resolved.NextScheduledAt == nil || !resolved.NextScheduledAt.Equal(nextAt) {
+		t.Fatalf("expected chapter 41 as the next scheduled, got %v at %v", resolved.NextScheduledChapter, resolved.NextScheduledAt)
+	}
+}
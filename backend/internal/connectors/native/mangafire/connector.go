@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"math"
 	"net/http"
@@ -17,12 +18,17 @@ import (
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
 	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
+	"github.com/gabriel/cross-site-tracker/backend/internal/timefmt"
 )
 
 // MangaFire rebuilt their site as a SPA backed by a JSON API under /api.
 // Manga pages moved from /manga/{slug}.{hid} to /title/{hid}-{slug} and
 // reader pages from /read/{slug}.{hid}/{lang}/chapter-{n} to /title/{hid}-{slug}/{chapterId}.
-var relativeAgoPattern = regexp.MustCompile(`(?i)^(\d+)\s*(min|mins|mo|mos|m|hrs|hr|h|d|w|yrs|yr|y)\s+ago$`)
+var (
+	whitespacePattern = regexp.MustCompile(`\s+`)
+
+	chapterDateLayouts = []string{"Jan 2, 2006", "January 2, 2006", "2006-01-02"}
+)
 
 type latestReleaseMemo struct {
 	latestChapter float64
@@ -78,6 +84,11 @@ func NewConnectorWithOptions(baseURL string, allowedHost []string, client *http.
 	}
 }
 
+// HTTPClient implements connectors.HTTPClientProvider.
+func (c *Connector) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
 func (c *Connector) Key() string {
 	return "mangafire"
 }
@@ -90,6 +101,11 @@ func (c *Connector) Kind() string {
 	return connectors.KindNative
 }
 
+// SearchPageURL implements connectors.SearchPageLinker.
+func (c *Connector) SearchPageURL(query string) string {
+	return c.baseURL + "/filter?keyword=" + url.QueryEscape(strings.TrimSpace(query))
+}
+
 type apiPoster struct {
 	Small  string `json:"small"`
 	Medium string `json:"medium"`
@@ -104,6 +120,29 @@ type apiTitle struct {
 	LatestChapter    *float64   `json:"latestChapter"`
 	ChapterUpdatedAt string     `json:"chapterUpdatedAt"`
 	AltTitles        []string   `json:"altTitles"`
+	Genres           []apiGenre `json:"genres"`
+}
+
+// apiGenre is a genre of a title, which the API lists either as a bare name
+// or as an object with one.
+type apiGenre struct {
+	Name string `json:"name"`
+}
+
+func (g *apiGenre) UnmarshalJSON(raw []byte) error {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		g.Name = name
+		return nil
+	}
+	var object struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return err
+	}
+	g.Name = object.Name
+	return nil
 }
 
 type apiTitlesResponse struct {
@@ -117,6 +156,7 @@ type apiTitleDetailResponse struct {
 type apiChapter struct {
 	ID        int64   `json:"id"`
 	Number    float64 `json:"number"`
+	Name      string  `json:"name"`
 	Language  string  `json:"language"`
 	CreatedAt int64   `json:"createdAt"`
 }
@@ -134,6 +174,11 @@ type apiChaptersResponse struct {
 	Meta  apiMeta      `json:"meta"`
 }
 
+// Canary implements connectors.CanaryProvider with One Piece.
+func (c *Connector) Canary() connectors.Canary {
+	return connectors.Canary{URL: "https://mangafire.to/manga/one-piecee.dkw", TitleContains: "One Piece", MinLatestChapter: 1100}
+}
+
 func (c *Connector) HealthCheck(ctx context.Context) error {
 	params := url.Values{}
 	params.Set("limit", "1")
@@ -191,7 +236,7 @@ func (c *Connector) ResolveByURL(ctx context.Context, rawURL string) (*connector
 
 func (c *Connector) SearchByTitle(ctx context.Context, title string, limit int) ([]connectors.MangaResult, error) {
 	query := strings.TrimSpace(title)
-	if query == "" {
+	if query == "" || searchutil.Normalize(query) == "" {
 		return nil, fmt.Errorf("title is required")
 	}
 
@@ -269,6 +314,68 @@ func (c *Connector) ResolveChapterURL(ctx context.Context, rawURL string, chapte
 	return "https://mangafire.to/title/" + titleKey(hid, slug) + "/" + strconv.FormatInt(match.ID, 10), nil
 }
 
+// ListChapters returns one entry per chapter number, preferring the English
+// upload like ResolveChapterURL does. Paging stops once a number beyond the
+// limit-th one shows up, since every language variant of a number is
+// contiguous in the newest-first listing.
+func (c *Connector) ListChapters(ctx context.Context, rawURL string, limit int) ([]connectors.ChapterInfo, error) {
+	hid, slug, err := c.parseTitleURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var stop func(page []apiChapter) bool
+	if limit > 0 {
+		seen := map[float64]struct{}{}
+		stop = func(page []apiChapter) bool {
+			for i := range page {
+				seen[page[i].Number] = struct{}{}
+			}
+			return len(seen) > limit
+		}
+	}
+
+	chapters, err := c.fetchChapters(ctx, hid, stop)
+	if err != nil {
+		return nil, fmt.Errorf("fetch chapters: %w", err)
+	}
+
+	if slug == "" && len(chapters) > 0 {
+		detail, detailErr := c.fetchTitleDetail(ctx, hid)
+		if detailErr != nil {
+			return nil, fmt.Errorf("fetch manga detail: %w", detailErr)
+		}
+		slug = detail.Slug
+	}
+
+	items := make([]connectors.ChapterInfo, 0, len(chapters))
+	picked := make(map[float64]struct{}, len(chapters))
+	for _, entry := range chapters {
+		if _, done := picked[entry.Number]; done {
+			continue
+		}
+		picked[entry.Number] = struct{}{}
+
+		match := pickChapterEntry(chapters, entry.Number)
+		if match == nil {
+			continue
+		}
+
+		item := connectors.ChapterInfo{
+			Number: match.Number,
+			Title:  strings.TrimSpace(match.Name),
+			URL:    "https://mangafire.to/title/" + titleKey(hid, slug) + "/" + strconv.FormatInt(match.ID, 10),
+		}
+		if match.CreatedAt > 0 {
+			releasedAt := time.Unix(match.CreatedAt, 0).UTC()
+			item.ReleasedAt = &releasedAt
+		}
+		items = append(items, item)
+	}
+
+	return connectors.NewestChaptersFirst(items, limit), nil
+}
+
 // parseTitleURL extracts the title hid (and slug when present) from both the
 // current /title/{hid}-{slug} URLs and the legacy /manga/{slug}.{hid} and
 // /read/{slug}.{hid}/... URLs that existing trackers still have stored.
@@ -417,7 +524,7 @@ func (c *Connector) fetchLatestReleaseAt(ctx context.Context, hid string, latest
 
 func (c *Connector) resultFromAPITitle(item apiTitle) connectors.MangaResult {
 	key := titleKey(item.HID, item.Slug)
-	title := strings.TrimSpace(item.Title)
+	title := cleanTitle(item.Title)
 	if title == "" {
 		title = prettifySlug(item.Slug)
 	}
@@ -438,10 +545,19 @@ func (c *Connector) resultFromAPITitle(item apiTitle) connectors.MangaResult {
 		URL:           "https://mangafire.to/title/" + key,
 		CoverImageURL: coverImageURL,
 		LatestChapter: item.LatestChapter,
-		LastUpdatedAt: parseRelativeUpdatedAt(item.ChapterUpdatedAt, time.Now().UTC()),
+		LastUpdatedAt: parseChapterUpdatedAt(item.ChapterUpdatedAt, time.Now().UTC()),
+		Genres:        genreNames(item.Genres),
 	}
 }
 
+func genreNames(genres []apiGenre) []string {
+	names := make([]string, 0, len(genres))
+	for _, genre := range genres {
+		names = append(names, genre.Name)
+	}
+	return connectors.CleanGenres(names)
+}
+
 func pickChapterEntry(chapters []apiChapter, chapter float64) *apiChapter {
 	var fallback *apiChapter
 	for index := range chapters {
@@ -475,7 +591,9 @@ func titleKey(hid string, slug string) string {
 func buildRelatedTitles(title string, slug string, altTitles []string) []string {
 	candidates := make([]string, 0, len(altTitles)+1)
 	candidates = append(candidates, prettifySlug(slug))
-	candidates = append(candidates, searchutil.FilterEnglishAlphabetNames(altTitles)...)
+	for _, altTitle := range searchutil.FilterEnglishAlphabetNames(altTitles) {
+		candidates = append(candidates, cleanTitle(altTitle))
+	}
 	candidates = searchutil.UniqueNonEmpty(candidates)
 
 	titleKey := searchutil.Normalize(title)
@@ -496,6 +614,12 @@ func buildRelatedTitles(title string, slug string, altTitles []string) []string
 	return filtered
 }
 
+// cleanTitle decodes HTML entities and stray line breaks in API titles, which
+// the site passes through from its uploaders unescaped.
+func cleanTitle(raw string) string {
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(html.UnescapeString(raw), " "))
+}
+
 func prettifySlug(slug string) string {
 	slug = strings.TrimSpace(strings.ReplaceAll(slug, "-", " "))
 	if slug == "" {
@@ -503,50 +627,27 @@ func prettifySlug(slug string) string {
 	}
 	parts := strings.Fields(slug)
 	for index := range parts {
-		parts[index] = strings.ToUpper(parts[index][:1]) + parts[index][1:]
+		parts[index] = connectors.UpperFirst(parts[index])
 	}
 	return strings.Join(parts, " ")
 }
 
-// parseRelativeUpdatedAt parses the API's coarse relative timestamps such as
-// "just now", "30m ago", "5h ago", "2d ago", "3w ago", "1mo ago", "1yr ago".
-func parseRelativeUpdatedAt(raw string, now time.Time) *time.Time {
-	trimmed := strings.ToLower(strings.TrimSpace(raw))
+// parseChapterUpdatedAt parses the API's chapterUpdatedAt, which is a coarse
+// relative time for recent chapters ("just now", "5h ago", "3 hours ago",
+// "Today", "Yesterday", "2d ago", "1yr ago") and an absolute date such as
+// "Jan 2, 2006" for older ones.
+func parseChapterUpdatedAt(raw string, now time.Time) *time.Time {
+	trimmed := whitespacePattern.ReplaceAllString(strings.TrimSpace(raw), " ")
 	if trimmed == "" {
 		return nil
 	}
-	if trimmed == "just now" {
-		result := now
-		return &result
-	}
-
-	match := relativeAgoPattern.FindStringSubmatch(trimmed)
-	if len(match) < 3 {
-		return nil
-	}
-	quantity, err := strconv.Atoi(match[1])
-	if err != nil || quantity < 0 {
-		return nil
-	}
-
-	result := now
-	switch match[2] {
-	case "m", "min", "mins":
-		result = result.Add(-time.Duration(quantity) * time.Minute)
-	case "h", "hr", "hrs":
-		result = result.Add(-time.Duration(quantity) * time.Hour)
-	case "d":
-		result = result.AddDate(0, 0, -quantity)
-	case "w":
-		result = result.AddDate(0, 0, -7*quantity)
-	case "mo", "mos":
-		result = result.AddDate(0, -quantity, 0)
-	case "y", "yr", "yrs":
-		result = result.AddDate(-quantity, 0, 0)
-	default:
-		return nil
+	for _, layout := range chapterDateLayouts {
+		if parsed, err := time.Parse(layout, trimmed); err == nil {
+			result := parsed.UTC()
+			return &result
+		}
 	}
-	return &result
+	return timefmt.ParseRelative(trimmed, now)
 }
 
 func (c *Connector) fetchJSON(ctx context.Context, endpoint string, target any) error {
@@ -704,6 +805,11 @@ func (e *httpStatusError) Error() string {
 	return fmt.Sprintf("unexpected status: %d", e.StatusCode)
 }
 
+// Is reports a 404 as connectors.ErrNotFound.
+func (e *httpStatusError) Is(target error) bool {
+	return target == connectors.ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
 func computeRetryDelay(attempt int, retryAfter string) time.Duration {
 	if retryAfter != "" {
 		if seconds, err := strconv.Atoi(strings.TrimSpace(retryAfter)); err == nil {
@@ -727,6 +833,33 @@ func computeRetryDelay(attempt int, retryAfter string) time.Duration {
 	}
 }
 
+// SearchMode implements connectors.SearchModeProvider: title search works,
+// but a pasted series URL is the reliable way to pick an exact entry.
+func (c *Connector) SearchMode() string {
+	return connectors.SearchModeTitleOrURL
+}
+
+// MediaHosts implements connectors.MediaHostProvider. Covers are served
+// from the mfcdn.nl CDN rather than the site.
+func (c *Connector) MediaHosts() []string {
+	return append(append([]string(nil), c.allowedHost...), "mfcdn.nl")
+}
+
+// ValidateURL implements connectors.URLValidator.
+func (c *Connector) ValidateURL(rawURL string) (string, error) {
+	return connectors.CanonicalHostURL(rawURL, c.allowedHost)
+}
+
+// CanonicalScheme implements connectors.URLMigrator.
+func (c *Connector) CanonicalScheme() string {
+	return "https"
+}
+
+// HostMigrations implements connectors.URLMigrator.
+func (c *Connector) HostMigrations() []connectors.HostMigration {
+	return nil
+}
+
 func (c *Connector) isAllowedHost(host string) bool {
 	host = strings.ToLower(strings.TrimSpace(host))
 	for _, allowed := range c.allowedHost {
@@ -4,8 +4,12 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
 )
 
 func newFakeAPIServer(t *testing.T) *httptest.Server {
@@ -27,7 +31,7 @@ func newFakeAPIServer(t *testing.T) *httptest.Server {
 	})
 	mux.HandleFunc("/api/titles/dkw", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"data":{"id":1,"hid":"dkw","slug":"one-piece","title":"One Piece","poster":{"small":"https://cdn.example/op@100.jpg","medium":"https://cdn.example/op@280.jpg","large":"https://cdn.example/op.jpg"},"latestChapter":1187,"chapterUpdatedAt":"2d ago","url":"/title/dkw-one-piece","altTitles":["ワンピース","One Piece. Большой куш","Pirate Legacy"]}}`))
+		_, _ = w.Write([]byte(`{"data":{"id":1,"hid":"dkw","slug":"one-piece","title":"One Piece","poster":{"small":"https://cdn.example/op@100.jpg","medium":"https://cdn.example/op@280.jpg","large":"https://cdn.example/op.jpg"},"latestChapter":1187,"chapterUpdatedAt":"2d ago","url":"/title/dkw-one-piece","altTitles":["ワンピース","One Piece. Большой куш","Pirate Legacy"],"genres":[{"id":1,"name":"Action"},"Adventure",{"id":3,"name":" action "},{"id":4,"name":"Slice  of Life"}]}}`))
 	})
 	mux.HandleFunc("/api/titles/dkw/chapters", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -78,6 +82,10 @@ func TestMangaFireConnector(t *testing.T) {
 		t.Fatalf("expected release date %s, got %s", expectedReleaseAt.Format(time.RFC3339), resolved.LastUpdatedAt.Format(time.RFC3339))
 	}
 
+	if got := resolved.Genres; len(got) != 3 || got[0] != "Action" || got[1] != "Adventure" || got[2] != "Slice of Life" {
+		t.Fatalf("expected the genres Action, Adventure and Slice of Life, got %v", got)
+	}
+
 	foundAlias := false
 	for _, related := range resolved.RelatedTitles {
 		if related == resolved.Title {
@@ -152,6 +160,43 @@ func TestMangaFireConnectorSearchByTitle(t *testing.T) {
 	}
 }
 
+// Search results come complete from the titles listing, so a search never
+// waits on per-title detail or chapter fetches, however slow those are.
+func TestMangaFireConnectorSearchSkipsPerResultFetches(t *testing.T) {
+	fixture := newFakeAPIServer(t)
+	defer fixture.Close()
+
+	var listings, others atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/titles" {
+			listings.Add(1)
+			fixture.Config.Handler.ServeHTTP(w, r)
+			return
+		}
+		others.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	connector := NewConnectorWithOptions(server.URL, []string{"mangafire.to"}, &http.Client{Timeout: 10 * time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	results, err := connector.SearchByTitle(ctx, "one", 10)
+	if err != nil {
+		t.Fatalf("expected the search inside its deadline: %v", err)
+	}
+	if len(results) != 2 || results[0].CoverImageURL == "" || results[0].LatestChapter == nil || results[0].LastUpdatedAt == nil {
+		t.Fatalf("expected complete results from the listing, got %+v", results)
+	}
+	if listings.Load() != 1 || others.Load() != 0 {
+		t.Fatalf("expected one listing request and nothing else, got %d listing and %d other", listings.Load(), others.Load())
+	}
+}
+
 func TestMangaFireConnectorResolveChapterURL(t *testing.T) {
 	server := newFakeAPIServer(t)
 	defer server.Close()
@@ -211,7 +256,7 @@ func TestParseTitleURL(t *testing.T) {
 	}
 }
 
-func TestParseRelativeUpdatedAt(t *testing.T) {
+func TestParseChapterUpdatedAt(t *testing.T) {
 	now := time.Date(2026, 7, 5, 12, 0, 0, 0, time.UTC)
 
 	cases := []struct {
@@ -225,12 +270,18 @@ func TestParseRelativeUpdatedAt(t *testing.T) {
 		{raw: "3w ago", want: timePtr(now.AddDate(0, 0, -21))},
 		{raw: "1mo ago", want: timePtr(now.AddDate(0, -1, 0))},
 		{raw: "1yr ago", want: timePtr(now.AddDate(-1, 0, 0))},
+		{raw: "3 hours ago", want: timePtr(now.Add(-3 * time.Hour))},
+		{raw: "an hour ago", want: timePtr(now.Add(-time.Hour))},
+		{raw: "Today", want: timePtr(time.Date(2026, 7, 5, 0, 0, 0, 0, time.UTC))},
+		{raw: "Yesterday", want: timePtr(time.Date(2026, 7, 4, 0, 0, 0, 0, time.UTC))},
+		{raw: "Jun 28, 2026", want: timePtr(time.Date(2026, 6, 28, 0, 0, 0, 0, time.UTC))},
+		{raw: "January 2, 2026", want: timePtr(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC))},
 		{raw: "", want: nil},
 		{raw: "unknown", want: nil},
 	}
 
 	for _, testCase := range cases {
-		got := parseRelativeUpdatedAt(testCase.raw, now)
+		got := parseChapterUpdatedAt(testCase.raw, now)
 		if testCase.want == nil {
 			if got != nil {
 				t.Fatalf("parse %q: expected nil, got %s", testCase.raw, got.Format(time.RFC3339))
@@ -280,6 +331,40 @@ func TestMangaFireConnectorMemoizesLatestReleaseLookup(t *testing.T) {
 	}
 }
 
+func TestMangaFireConnectorFallsBackToRelativeChapterDate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/titles/dkw", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"id":1,"hid":"dkw","slug":"one-piece","title":"One Piece","latestChapter":1187,"chapterUpdatedAt":"Today"}}`))
+	})
+	mux.HandleFunc("/api/titles/dkw/chapters", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items":[{"id":7511775,"number":1187,"language":"en"},{"id":7511774,"number":1186,"language":"en","createdAt":1783047602}]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	connector := NewConnectorWithOptions(server.URL, []string{"mangafire.to"}, &http.Client{Timeout: 5 * time.Second})
+
+	before := time.Now().UTC()
+	resolved, err := connector.ResolveByURL(context.Background(), "https://mangafire.to/title/dkw-one-piece")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	after := time.Now().UTC()
+
+	// The latest chapter has no release timestamp, so "Today" from the title
+	// payload is kept rather than the older chapter's timestamp.
+	if resolved.LastUpdatedAt == nil {
+		t.Fatalf("expected a release date from the relative chapterUpdatedAt")
+	}
+	got := resolved.LastUpdatedAt.Format("2006-01-02")
+	if got != before.Format("2006-01-02") && got != after.Format("2006-01-02") {
+		t.Fatalf("expected today's date, got %s", resolved.LastUpdatedAt.Format(time.RFC3339))
+	}
+}
+
 func TestMangaFireConnectorCoolsDownAfterForbidden(t *testing.T) {
 	requests := 0
 	mux := http.NewServeMux()
@@ -308,3 +393,46 @@ func TestMangaFireConnectorCoolsDownAfterForbidden(t *testing.T) {
 		t.Fatalf("expected no additional requests while cooling down, got %d", requests)
 	}
 }
+
+func TestMangaFireConnectorListChapters(t *testing.T) {
+	server := newFakeAPIServer(t)
+	defer server.Close()
+
+	connector := NewConnectorWithOptions(server.URL, []string{"mangafire.to"}, &http.Client{Timeout: 5 * time.Second})
+
+	chapters, err := connector.ListChapters(context.Background(), "https://mangafire.to/manga/one-piecee.dkw", 10)
+	if err != nil {
+		t.Fatalf("list chapters failed: %v", err)
+	}
+	if len(chapters) != 2 {
+		t.Fatalf("expected one entry per chapter number, got %d", len(chapters))
+	}
+	if chapters[0].Number != 1187 || chapters[1].Number != 1186 {
+		t.Fatalf("expected chapters newest first, got %v then %v", chapters[0].Number, chapters[1].Number)
+	}
+	if chapters[1].URL != "https://mangafire.to/title/dkw-one-piece/7462702" || chapters[1].Title != "One More Time" {
+		t.Fatalf("expected english entry for chapter 1186, got %+v", chapters[1])
+	}
+	if chapters[0].ReleasedAt == nil || !chapters[0].ReleasedAt.Equal(time.Unix(1783047602, 0)) {
+		t.Fatalf("unexpected release time: %v", chapters[0].ReleasedAt)
+	}
+}
+
+func TestMangaFireConnectorConformance(t *testing.T) {
+	conformance.RunConformanceSuite(t, func(baseURL string) connectors.Connector {
+		return NewConnectorWithOptions(baseURL, []string{"mangafire.to"}, &http.Client{Timeout: 5 * time.Second})
+	}, conformance.FixtureSet{
+		Routes: map[string]conformance.Fixture{
+			"/api/titles/tjb": {ContentType: "application/json", Body: `{"data":{"id":1,"hid":"tjb","slug":"tom-and-jerrys-blade","title":"  Tom &amp; Jerry&#39;s\n   Blade ","altTitles":["Tom &amp; Jerry&#39;s Sword"],"latestChapter":12}}`},
+			"/api/titles": {ContentType: "application/json", Body: `{"items":[
+				{"id":1,"hid":"tjb","slug":"tom-and-jerrys-blade","title":"  Tom &amp; Jerry&#39;s\n   Blade ","latestChapter":12},
+				{"id":2,"hid":"sbl","slug":"second-blade","title":"Second Blade","latestChapter":3}
+			],"meta":{"total":2}}`},
+		},
+		SeriesURL:    "https://mangafire.to/title/tjb-tom-and-jerrys-blade",
+		SeriesTitle:  "Tom & Jerry's Blade",
+		MissingURL:   "https://mangafire.to/title/zzz-missing-series",
+		WrongHostURL: "https://example.com/title/tjb-tom-and-jerrys-blade",
+		SearchQuery:  "blade",
+	})
+}
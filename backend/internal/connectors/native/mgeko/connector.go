@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"html"
-	"io"
 	"math"
 	"net/http"
 	"net/url"
@@ -17,6 +16,7 @@ import (
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
 	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
+	"github.com/gabriel/cross-site-tracker/backend/internal/timefmt"
 )
 
 const canonicalBaseURL = "https://www.mgeko.cc"
@@ -35,11 +35,12 @@ var (
 	metaTitlePattern         = regexp.MustCompile(`(?is)<meta\s+[^>]*name=["']title["'][^>]*content=["']([^"']+)["']`)
 	ogImagePattern           = regexp.MustCompile(`(?is)<meta\s+[^>]*property=["']og:image["'][^>]*content=["']([^"']+)["']`)
 	coverDataSrcPattern      = regexp.MustCompile(`(?is)<img[^>]+class=["'][^"']*lazy[^"']*["'][^>]+data-src=["']([^"']*manga_covers[^"']*)["'][^>]*>`)
+	categoriesBlockPattern   = regexp.MustCompile(`(?is)<div[^>]*class=["'][^"']*categories[^"']*["'][^>]*>(.*?)</div>`)
+	categoryAnchorPattern    = regexp.MustCompile(`(?is)<a[^>]*>(.*?)</a>`)
 	chapterAnchorPattern     = regexp.MustCompile(`(?is)<a[^>]+href=["'](/reader/en/[^"']+-chapter-([0-9]+(?:-[0-9]+)?)[^"']*)["'][^>]*>(.*?)</a>`)
 	chapterDatetimePattern   = regexp.MustCompile(`(?is)\bdatetime=["']([^"']+)["']`)
 	chapterStatsPattern      = regexp.MustCompile(`(?is)<span[^>]*class=["'][^"']*chapter-stats[^"']*["'][^>]*>(.*?)</span>`)
 	chapterTokenPattern      = regexp.MustCompile(`\d+(?:-\d+)?`)
-	relativeUnitPattern      = regexp.MustCompile(`(?i)(\d+)\s*(minute|minutes|hour|hours|day|days|week|weeks|month|months|year|years)`)
 	allChaptersSuffixPattern = regexp.MustCompile(`(?i)\s*\[all\s+chapters?\]\s*$`)
 	htmlTagPattern           = regexp.MustCompile(`(?is)<[^>]+>`)
 	whitespacePattern        = regexp.MustCompile(`\s+`)
@@ -61,6 +62,7 @@ type searchEntry struct {
 
 type chapterEntry struct {
 	Chapter   float64
+	Title     string
 	URL       string
 	UpdatedAt *time.Time
 }
@@ -89,6 +91,11 @@ func NewConnectorWithOptions(baseURL string, allowedHost []string, client *http.
 	}
 }
 
+// HTTPClient implements connectors.HTTPClientProvider.
+func (c *Connector) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
 func (c *Connector) Key() string {
 	return "mgeko"
 }
@@ -101,6 +108,16 @@ func (c *Connector) Kind() string {
 	return connectors.KindNative
 }
 
+// SearchPageURL implements connectors.SearchPageLinker.
+func (c *Connector) SearchPageURL(query string) string {
+	return c.baseURL + "/search/?search=" + url.QueryEscape(strings.TrimSpace(query))
+}
+
+// Canary implements connectors.CanaryProvider with The 100 Girlfriends Who Really Love You.
+func (c *Connector) Canary() connectors.Canary {
+	return connectors.Canary{URL: "https://www.mgeko.cc/manga/the-100-girlfriends-who-really-really-really-really-really-love-you/", TitleContains: "100 Girlfriends", MinLatestChapter: 200}
+}
+
 func (c *Connector) HealthCheck(ctx context.Context) error {
 	_, err := c.fetchPage(ctx, c.baseURL+"/browse-comics/")
 	return err
@@ -225,6 +242,43 @@ func (c *Connector) ResolveChapterURL(ctx context.Context, rawURL string, chapte
 	return "", fmt.Errorf("chapter %.3f not found", chapter)
 }
 
+func (c *Connector) ListChapters(ctx context.Context, rawURL string, limit int) ([]connectors.ChapterInfo, error) {
+	trimmed := strings.TrimSpace(rawURL)
+	if trimmed == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if !c.isAllowedHost(parsed.Hostname()) {
+		return nil, fmt.Errorf("url does not belong to mgeko")
+	}
+
+	slug := extractMangaSlugFromPath(parsed.Path)
+	if slug == "" {
+		return nil, fmt.Errorf("mgeko url must match /manga/{id}")
+	}
+
+	entries, err := c.fetchChapterEntries(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	chapters := make([]connectors.ChapterInfo, 0, len(entries))
+	for _, entry := range entries {
+		chapters = append(chapters, connectors.ChapterInfo{
+			Number:     entry.Chapter,
+			Title:      entry.Title,
+			URL:        entry.URL,
+			ReleasedAt: entry.UpdatedAt,
+		})
+	}
+
+	return connectors.NewestChaptersFirst(chapters, limit), nil
+}
+
 func (c *Connector) resolveBySlug(ctx context.Context, slug string) (*connectors.MangaResult, error) {
 	body, err := c.fetchPage(ctx, c.baseURL+"/manga/"+url.PathEscape(slug)+"/")
 	if err != nil {
@@ -261,6 +315,7 @@ func (c *Connector) resolveBySlug(ctx context.Context, slug string) (*connectors
 		CoverImageURL: coverImageURL,
 		LatestChapter: latestChapter,
 		LastUpdatedAt: lastUpdatedAt,
+		Genres:        extractGenres(body),
 	}, nil
 }
 
@@ -338,7 +393,7 @@ func parseSearchEntries(body string, now time.Time) []searchEntry {
 
 		var lastUpdatedAt *time.Time
 		if updatedRaw := cleanText(firstSubmatch(searchUpdatedPattern, block)); updatedRaw != "" {
-			lastUpdatedAt = parseRelativeTime(updatedRaw, now)
+			lastUpdatedAt = timefmt.ParseRelative(updatedRaw, now)
 		}
 
 		existing, exists := entriesBySlug[slug]
@@ -410,11 +465,12 @@ func parseChapterEntries(body string, now time.Time) []chapterEntry {
 		updatedAt := parseMgekoDatetime(datetimeRaw)
 		if updatedAt == nil {
 			statsRaw := cleanText(firstSubmatch(chapterStatsPattern, innerHTML))
-			updatedAt = parseRelativeTime(statsRaw, now)
+			updatedAt = timefmt.ParseRelative(statsRaw, now)
 		}
 
 		entries = append(entries, chapterEntry{
 			Chapter:   *chapter,
+			Title:     strings.TrimSpace(html.UnescapeString(firstSubmatch(anchorTitleAttrPattern, match[0]))),
 			URL:       chapterURL,
 			UpdatedAt: updatedAt,
 		})
@@ -462,7 +518,7 @@ func extractTitle(body string, slug string) string {
 		return title
 	}
 
-	title = strings.TrimSpace(html.UnescapeString(firstSubmatch(metaTitlePattern, body)))
+	title = cleanText(firstSubmatch(metaTitlePattern, body))
 	title = allChaptersSuffixPattern.ReplaceAllString(title, "")
 	title = strings.TrimSpace(title)
 	if title != "" {
@@ -472,6 +528,19 @@ func extractTitle(body string, slug string) string {
 	return prettifySlug(slug)
 }
 
+// extractGenres reads the category links listed on a manga page.
+func extractGenres(body string) []string {
+	block := firstSubmatch(categoriesBlockPattern, body)
+	if block == "" {
+		return nil
+	}
+	genres := make([]string, 0, 8)
+	for _, match := range categoryAnchorPattern.FindAllStringSubmatch(block, -1) {
+		genres = append(genres, cleanText(match[1]))
+	}
+	return connectors.CleanGenres(genres)
+}
+
 func extractRelatedTitles(body string, primaryTitle string) []string {
 	candidates := make([]string, 0, 16)
 
@@ -524,16 +593,19 @@ func (c *Connector) fetchPage(ctx context.Context, endpoint string) (string, err
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("unexpected status: %d: %w", res.StatusCode, connectors.ErrNotFound)
+	}
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		return "", fmt.Errorf("unexpected status: %d", res.StatusCode)
 	}
 
-	rawBody, err := io.ReadAll(res.Body)
+	body, err := connectors.ReadPage(res.Body)
 	if err != nil {
 		return "", fmt.Errorf("read response body: %w", err)
 	}
 
-	return string(rawBody), nil
+	return body, nil
 }
 
 func parseMgekoChapterToken(raw string) *float64 {
@@ -573,52 +645,6 @@ func parseMgekoChapterToken(raw string) *float64 {
 	return &value
 }
 
-func parseRelativeTime(raw string, now time.Time) *time.Time {
-	normalized := strings.TrimSpace(strings.ToLower(strings.ReplaceAll(raw, "\u00a0", " ")))
-	if normalized == "" {
-		return nil
-	}
-	if strings.Contains(normalized, "just now") {
-		result := now.UTC()
-		return &result
-	}
-
-	matches := relativeUnitPattern.FindAllStringSubmatch(normalized, -1)
-	if len(matches) == 0 {
-		return nil
-	}
-
-	result := now.UTC()
-	for _, match := range matches {
-		if len(match) < 3 {
-			continue
-		}
-
-		quantity, err := strconv.Atoi(strings.TrimSpace(match[1]))
-		if err != nil || quantity <= 0 {
-			continue
-		}
-
-		unit := strings.TrimSpace(match[2])
-		switch unit {
-		case "minute", "minutes":
-			result = result.Add(-time.Duration(quantity) * time.Minute)
-		case "hour", "hours":
-			result = result.Add(-time.Duration(quantity) * time.Hour)
-		case "day", "days":
-			result = result.AddDate(0, 0, -quantity)
-		case "week", "weeks":
-			result = result.AddDate(0, 0, -7*quantity)
-		case "month", "months":
-			result = result.AddDate(0, -quantity, 0)
-		case "year", "years":
-			result = result.AddDate(-quantity, 0, 0)
-		}
-	}
-
-	return &result
-}
-
 func parseMgekoDatetime(raw string) *time.Time {
 	normalized := strings.TrimSpace(raw)
 	if normalized == "" {
@@ -714,7 +740,7 @@ func prettifySlug(slug string) string {
 		if len(parts[index]) == 0 {
 			continue
 		}
-		parts[index] = strings.ToUpper(parts[index][:1]) + parts[index][1:]
+		parts[index] = connectors.UpperFirst(parts[index])
 	}
 	return strings.Join(parts, " ")
 }
@@ -734,6 +760,27 @@ func firstSubmatch(pattern *regexp.Regexp, raw string) string {
 	return matches[1]
 }
 
+// MediaHosts implements connectors.MediaHostProvider. Covers are served
+// from imgsrv4.com rather than the site.
+func (c *Connector) MediaHosts() []string {
+	return append(append([]string(nil), c.allowedHost...), "imgsrv4.com")
+}
+
+// ValidateURL implements connectors.URLValidator.
+func (c *Connector) ValidateURL(rawURL string) (string, error) {
+	return connectors.CanonicalHostURL(rawURL, c.allowedHost)
+}
+
+// CanonicalScheme implements connectors.URLMigrator.
+func (c *Connector) CanonicalScheme() string {
+	return "https"
+}
+
+// HostMigrations implements connectors.URLMigrator.
+func (c *Connector) HostMigrations() []connectors.HostMigration {
+	return nil
+}
+
 func (c *Connector) isAllowedHost(host string) bool {
 	host = strings.ToLower(strings.TrimSpace(host))
 	for _, allowed := range c.allowedHost {
@@ -6,6 +6,9 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
 )
 
 func TestMgekoConnectorResolveSearchAndChapterURL(t *testing.T) {
@@ -55,6 +58,14 @@ func TestMgekoConnectorResolveSearchAndChapterURL(t *testing.T) {
   <h2 class="alternative-title text1row">
     100 Kanojo, The 100 Girlfriends Who Really, Really, Really, Really, Really Love You, ???????
   </h2>
+  <div class="categories">
+    <h4>Categories</h4>
+    <ul>
+      <li><a href="/browse-comics/?genre=comedy" class="property-item">Comedy</a></li>
+      <li><a href="/browse-comics/?genre=romance" class="property-item"> Romance </a></li>
+      <li><a href="/browse-comics/?genre=school-life" class="property-item">School Life</a></li>
+    </ul>
+  </div>
 </body>
 </html>`))
 	})
@@ -133,6 +144,9 @@ func TestMgekoConnectorResolveSearchAndChapterURL(t *testing.T) {
 	if contains(resolved.RelatedTitles, resolved.Title) {
 		t.Fatalf("did not expect primary title in related titles: %v", resolved.RelatedTitles)
 	}
+	if got := resolved.Genres; len(got) != 3 || got[0] != "Comedy" || got[1] != "Romance" || got[2] != "School Life" {
+		t.Fatalf("expected the categories as genres, got %v", got)
+	}
 
 	results, err := conn.SearchByTitle(context.Background(), "girlfriends really really", 8)
 	if err != nil {
@@ -206,3 +220,149 @@ func TestMgekoConnectorResolveChapterURLSupportsDecimalChapter(t *testing.T) {
 		t.Fatalf("unexpected chapter url: %s", chapterURL)
 	}
 }
+
+func TestMgekoConnectorListChaptersNewestFirst(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/manga/sample-series/all-chapters/", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`
+<!DOCTYPE html>
+<html>
+<body>
+  <li data-chapterno="1">
+    <a href="/reader/en/sample-series-chapter-67-eng-li/" title="Chapter 67">
+      <strong class="chapter-title">67-eng-li</strong>
+      <time class="chapter-update" datetime="Dec. 28, 2025, 8:00 p.m.">1 month</time>
+    </a>
+  </li>
+  <li data-chapterno="1">
+    <a href="/reader/en/sample-series-chapter-68-eng-li/" title="Chapter 68">
+      <strong class="chapter-title">68-eng-li</strong>
+      <time class="chapter-update" datetime="Jan. 11, 2026, 8:00 p.m.">1 month</time>
+    </a>
+  </li>
+  <li data-chapterno="1">
+    <a href="/reader/en/sample-series-chapter-67-5-eng-li/" title="Chapter 67-5">
+      <strong class="chapter-title">67-5-eng-li</strong>
+      <time class="chapter-update" datetime="Jan. 4, 2026, 8:00 p.m.">1 month</time>
+    </a>
+  </li>
+</body>
+</html>`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	conn := NewConnectorWithOptions(server.URL, []string{"mgeko.cc"}, &http.Client{Timeout: 5 * time.Second})
+
+	chapters, err := conn.ListChapters(context.Background(), "https://www.mgeko.cc/manga/sample-series/", 2)
+	if err != nil {
+		t.Fatalf("list chapters failed: %v", err)
+	}
+	if len(chapters) != 2 {
+		t.Fatalf("expected 2 chapters after limit, got %d", len(chapters))
+	}
+	if chapters[0].Number != 68 || chapters[1].Number != 67.5 {
+		t.Fatalf("expected chapters 68 then 67.5, got %v then %v", chapters[0].Number, chapters[1].Number)
+	}
+	if chapters[0].URL != "https://www.mgeko.cc/reader/en/sample-series-chapter-68-eng-li/" {
+		t.Fatalf("unexpected chapter url: %s", chapters[0].URL)
+	}
+	if chapters[0].Title != "Chapter 68" {
+		t.Fatalf("unexpected chapter title: %q", chapters[0].Title)
+	}
+	if chapters[0].ReleasedAt == nil || chapters[0].ReleasedAt.Format("2006-01-02") != "2026-01-11" {
+		t.Fatalf("unexpected chapter release date: %v", chapters[0].ReleasedAt)
+	}
+}
+
+func TestMgekoConnectorConformance(t *testing.T) {
+	searchItem := func(slug string, title string) string {
+		return `
+    <li class="novel-item">
+      <a href="/manga/` + slug + `/" title="` + title + `">
+        <h4 class="novel-title text2row">` + title + `</h4>
+        <div class="novel-stats"><strong> Chapters 12-eng-li</strong></div>
+      </a>
+    </li>`
+	}
+
+	conformance.RunConformanceSuite(t, func(baseURL string) connectors.Connector {
+		return NewConnectorWithOptions(baseURL, []string{"mgeko.cc"}, &http.Client{Timeout: 5 * time.Second})
+	}, conformance.FixtureSet{
+		Routes: map[string]conformance.Fixture{
+			"/search/": {Body: `<!DOCTYPE html><html><body><ul class="novel-list">` +
+				searchItem("tom-and-jerrys-blade", "Tom &amp; Jerry&#39;s\n   Blade") +
+				searchItem("second-blade", "Second Blade") +
+				`</ul></body></html>`},
+			"/manga/tom-and-jerrys-blade/": {Body: `
+<!DOCTYPE html>
+<html>
+<body>
+  <h1 class="novel-title">  Tom &amp; Jerry&#39;s
+   Blade </h1>
+</body>
+</html>`},
+			"/manga/tom-and-jerrys-blade/all-chapters/": {Body: `
+<!DOCTYPE html>
+<html>
+<body>
+  <li data-chapterno="1">
+    <a href="/reader/en/tom-and-jerrys-blade-chapter-12-eng-li/" title="Chapter 12">
+      <strong class="chapter-title">12-eng-li</strong>
+    </a>
+  </li>
+</body>
+</html>`},
+		},
+		SeriesURL:    "https://www.mgeko.cc/manga/tom-and-jerrys-blade/",
+		SeriesTitle:  "Tom & Jerry's Blade",
+		MissingURL:   "https://www.mgeko.cc/manga/missing-series/",
+		WrongHostURL: "https://example.com/manga/tom-and-jerrys-blade/",
+		SearchQuery:  "blade",
+	})
+}
+
+func TestMgekoChapterEntriesNewestRelativeDateWins(t *testing.T) {
+	now := time.Date(2026, 2, 10, 15, 0, 0, 0, time.UTC)
+	body := `
+<ul class="chapter-list">
+  <li><a href="/reader/en/sample-series-chapter-70-eng-li/" title="Chapter 70">
+    <strong class="chapter-title">70-eng-li</strong>
+    <span class="chapter-stats">3 hours ago</span>
+  </a></li>
+  <li><a href="/reader/en/sample-series-chapter-69-eng-li/" title="Chapter 69">
+    <strong class="chapter-title">69-eng-li</strong>
+    <span class="chapter-stats">Today</span>
+  </a></li>
+  <li><a href="/reader/en/sample-series-chapter-68-eng-li/" title="Chapter 68">
+    <strong class="chapter-title">68-eng-li</strong>
+    <span class="chapter-stats">Yesterday</span>
+  </a></li>
+  <li><a href="/reader/en/sample-series-chapter-67-eng-li/" title="Chapter 67">
+    <strong class="chapter-title">67-eng-li</strong>
+    <time class="chapter-update" datetime="Jan. 11, 2026, 8:00 p.m.">1 month</time>
+  </a></li>
+</ul>`
+
+	entries := parseChapterEntries(body, now)
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 chapter entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.UpdatedAt == nil {
+			t.Fatalf("expected chapter %v to have a date", entry.Chapter)
+		}
+	}
+	if want := time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC); !entries[2].UpdatedAt.Equal(want) {
+		t.Fatalf("expected Yesterday to resolve to %s, got %s", want.Format(time.RFC3339), entries[2].UpdatedAt.Format(time.RFC3339))
+	}
+
+	latest, updatedAt := selectLatestChapter(entries)
+	if latest == nil || *latest != 70 {
+		t.Fatalf("expected chapter 70 to be latest, got %v", latest)
+	}
+	if want := now.Add(-3 * time.Hour); updatedAt == nil || !updatedAt.Equal(want) {
+		t.Fatalf("expected the newest chapter's %s, got %v", want.Format(time.RFC3339), updatedAt)
+	}
+}
@@ -0,0 +1,77 @@
+package mgeko
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
+)
+
+var fuzzNow = time.Date(2026, 2, 10, 15, 0, 0, 0, time.UTC)
+
+const fuzzSearchPage = `<!DOCTYPE html><html><body><ul class="novel-list">
+    <li class="novel-item">
+      <a href="/manga/tom-and-jerrys-blade/" title="Tom &amp; Jerry's Blade">
+        <img class="lazy" data-src="https://imgsrv4.com/avatar/288x412/media/manga_covers/blade.jpg">
+        <h4 class="novel-title text2row">Tom &amp; Jerry's Blade</h4>
+        <div class="novel-stats"><strong> Chapters 12-eng-li</strong></div>
+        <div class="novel-stats"><span><i class="fas fa-clock"></i> 3 hours Ago</span></div>
+      </a>
+    </li>
+    <li class="novel-item"><a href="/manga/ébène-blade/"></a></li>
+</ul></body></html>`
+
+const fuzzChapterPage = `
+<ul class="chapter-list">
+  <li><a href="/reader/en/sample-series-chapter-70-eng-li/" title="Chapter 70">
+    <strong class="chapter-title">70-eng-li</strong>
+    <span class="chapter-stats">3 hours ago</span>
+  </a></li>
+  <li><a href="/reader/en/sample-series-chapter-67-5-eng-li/" title="Chapter 67.5">
+    <strong class="chapter-title">67-5-eng-li</strong>
+    <time class="chapter-update" datetime="Jan. 11, 2026, 8:00 p.m.">1 month</time>
+  </a></li>
+</ul>`
+
+const fuzzMangaPage = `<!DOCTYPE html><html><body>
+  <h1 class="novel-title">Tom &amp; Jerry's Blade</h1>
+  <h2 class="alternative-title">Blade of Tom, 汤姆之刃, The Blade</h2>
+</body></html>`
+
+func FuzzParseSearchEntries(f *testing.F) {
+	conformance.AddAdversarialSeeds(f, `<li class="novel-item"><a href="/manga/blade/">`, fuzzSearchPage)
+	f.Fuzz(func(t *testing.T, body string) {
+		conformance.CheckParse(t, body, func() {
+			for _, entry := range parseSearchEntries(body, fuzzNow) {
+				if entry.Slug == "" || entry.Title == "" {
+					t.Fatalf("expected a slug and title, got %+v", entry)
+				}
+				conformance.CheckText(t, body, "title", entry.Title)
+			}
+		})
+	})
+}
+
+func FuzzParseChapterEntries(f *testing.F) {
+	conformance.AddAdversarialSeeds(f, `<a href="/reader/en/blade-chapter-12-eng-li/" title="Chapter 12">`, fuzzChapterPage)
+	f.Fuzz(func(t *testing.T, body string) {
+		conformance.CheckParse(t, body, func() {
+			for _, entry := range parseChapterEntries(body, fuzzNow) {
+				if entry.URL == "" || entry.Chapter < 0 {
+					t.Fatalf("unexpected chapter entry %+v", entry)
+				}
+			}
+		})
+	})
+}
+
+func FuzzExtractRelatedTitles(f *testing.F) {
+	conformance.AddAdversarialSeeds(f, `<h2 class="alternative-title">`, fuzzMangaPage)
+	f.Fuzz(func(t *testing.T, body string) {
+		conformance.CheckParse(t, body, func() {
+			for _, title := range extractRelatedTitles(body, "Tom & Jerry's Blade") {
+				conformance.CheckText(t, body, "related title", title)
+			}
+		})
+	})
+}
@@ -5,13 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"html"
-	"io"
 	"math"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
@@ -28,14 +29,43 @@ var (
 	episodeItemPattern  = regexp.MustCompile(`(?is)<li[^>]*class=["'][^"']*_episodeItem[^"']*["'][^>]*data-episode-no=["'](\d+)["'][^>]*>(.*?)</li>`)
 	episodeHrefPattern  = regexp.MustCompile(`(?is)href=["']([^"']*episode_no=\d+[^"']*)["']`)
 	episodeDatePattern  = regexp.MustCompile(`(?is)<span[^>]*class=["'][^"']*date[^"']*["'][^>]*>([^<]+)</span>`)
+	episodeTitlePattern = regexp.MustCompile(`(?is)<span[^>]*class=["']subj["'][^>]*>(.*?)</span>`)
+	displayNumberRegex  = regexp.MustCompile(`(?i)\b(?:episode|ep\.?|chapter|ch\.?)\s*(\d+)\b`)
+	dayInfoPattern      = regexp.MustCompile(`(?is)<p[^>]*class=["'][^"']*day_info[^"']*["'][^>]*>(.*?)</p>`)
+	nonLetterPattern    = regexp.MustCompile(`[^A-Za-z]+`)
 )
 
+var weekdayNames = map[time.Weekday]string{
+	time.Sunday:    "SUNDAY",
+	time.Monday:    "MONDAY",
+	time.Tuesday:   "TUESDAY",
+	time.Wednesday: "WEDNESDAY",
+	time.Thursday:  "THURSDAY",
+	time.Friday:    "FRIDAY",
+	time.Saturday:  "SATURDAY",
+}
+
 type Connector struct {
 	baseURL      string
 	searchLocale string
 	allowedHost  []string
 	imageBaseURL string
 	httpClient   *http.Client
+	now          func() time.Time
+
+	// episodeURLsMu guards episodeURLs, the viewer URL of every episode seen
+	// on a title's list pages, keyed by its display number. Webtoons numbers
+	// its viewer pages by episode_no, which drifts from the number in the
+	// episode title once a prologue or notice is posted as an episode.
+	episodeURLsMu sync.Mutex
+	episodeURLs   map[titleRef]map[int]string
+}
+
+// titleRef names a series. Originals and Canvas (formerly Challenge) series
+// are numbered separately and listed under different paths.
+type titleRef struct {
+	no     int
+	canvas bool
 }
 
 type immediateSearchResponse struct {
@@ -52,10 +82,14 @@ type immediateSearchResponse struct {
 	Success bool `json:"success"`
 }
 
+// episodeEntry is one row of an episode list. Number is the display number
+// read from the episode title, or the episode_no when the list titles its
+// episodes without numbers.
 type episodeEntry struct {
-	Number  int
-	URL     string
-	DateRaw string
+	Number    int
+	EpisodeNo int
+	URL       string
+	DateRaw   string
 }
 
 func NewConnector() *Connector {
@@ -67,6 +101,8 @@ func NewConnector() *Connector {
 		httpClient: &http.Client{
 			Timeout: 12 * time.Second,
 		},
+		now:         time.Now,
+		episodeURLs: map[titleRef]map[int]string{},
 	}
 }
 
@@ -83,9 +119,16 @@ func NewConnectorWithOptions(baseURL string, allowedHost []string, client *http.
 		allowedHost:  allowedHost,
 		imageBaseURL: "https://swebtoon-phinf.pstatic.net",
 		httpClient:   client,
+		now:          time.Now,
+		episodeURLs:  map[titleRef]map[int]string{},
 	}
 }
 
+// HTTPClient implements connectors.HTTPClientProvider.
+func (c *Connector) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
 func (c *Connector) Key() string {
 	return "webtoons"
 }
@@ -98,6 +141,16 @@ func (c *Connector) Kind() string {
 	return connectors.KindNative
 }
 
+// SearchPageURL implements connectors.SearchPageLinker.
+func (c *Connector) SearchPageURL(query string) string {
+	return c.baseURL + "/" + c.searchLocale + "/search?keyword=" + url.QueryEscape(strings.TrimSpace(query))
+}
+
+// Canary implements connectors.CanaryProvider with Tower of God.
+func (c *Connector) Canary() connectors.Canary {
+	return connectors.Canary{URL: "https://www.webtoons.com/en/fantasy/tower-of-god/list?title_no=95", TitleContains: "Tower of God", MinLatestChapter: 500}
+}
+
 func (c *Connector) HealthCheck(ctx context.Context) error {
 	_, err := c.searchImmediate(ctx, "webtoon")
 	if err != nil {
@@ -112,12 +165,12 @@ func (c *Connector) ResolveByURL(ctx context.Context, rawURL string) (*connector
 		return nil, err
 	}
 
-	titleNo, err := extractTitleNo(parsedURL)
+	ref, err := extractTitleRef(parsedURL)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.resolveByTitleNo(ctx, titleNo)
+	return c.resolveByTitleRef(ctx, ref)
 }
 
 func (c *Connector) SearchByTitle(ctx context.Context, title string, limit int) ([]connectors.MangaResult, error) {
@@ -163,14 +216,14 @@ func (c *Connector) SearchByTitle(ctx context.Context, title string, limit int)
 		result := connectors.MangaResult{
 			SourceKey:     c.Key(),
 			SourceItemID:  sourceItemID,
-			Title:         strings.TrimSpace(item.Title),
+			Title:         cleanText(item.Title),
 			URL:           c.baseURL + "/episodeList?titleNo=" + sourceItemID,
 			CoverImageURL: c.absoluteImageURL(item.ThumbnailMobile),
 		}
 
 		// Enrich with latest episode/date to improve tracker auto-fill reliability.
 		resolveCtx, cancel := context.WithTimeout(ctx, 6*time.Second)
-		resolved, resolveErr := c.resolveByTitleNo(resolveCtx, item.TitleNo)
+		resolved, resolveErr := c.resolveByTitleRef(resolveCtx, titleRef{no: item.TitleNo})
 		cancel()
 		if resolveErr == nil && resolved != nil {
 			if strings.TrimSpace(resolved.Title) != "" {
@@ -197,6 +250,10 @@ func (c *Connector) SearchByTitle(ctx context.Context, title string, limit int)
 	return results, nil
 }
 
+// ResolveChapterURL maps a display episode number to its viewer URL. Known
+// episodes are answered from the per-title cache; otherwise the list page
+// the episode should be on is fetched, and its neighbours when numbering
+// gaps have moved it.
 func (c *Connector) ResolveChapterURL(ctx context.Context, rawURL string, chapter float64) (string, error) {
 	episodeNo, err := parseEpisodeNumber(chapter)
 	if err != nil {
@@ -208,12 +265,16 @@ func (c *Connector) ResolveChapterURL(ctx context.Context, rawURL string, chapte
 		return "", err
 	}
 
-	titleNo, err := extractTitleNo(parsedURL)
+	ref, err := extractTitleRef(parsedURL)
 	if err != nil {
 		return "", err
 	}
 
-	pageOneEntries, err := c.fetchEpisodeListEntries(ctx, titleNo, 1)
+	if episodeURL, ok := c.cachedEpisodeURL(ref, episodeNo); ok {
+		return episodeURL, nil
+	}
+
+	pageOneEntries, err := c.fetchEpisodeListEntries(ctx, ref, 1)
 	if err != nil {
 		return "", err
 	}
@@ -231,16 +292,16 @@ func (c *Connector) ResolveChapterURL(ctx context.Context, rawURL string, chapte
 	}
 
 	page := ((latestEpisode - episodeNo) / 10) + 1
-	if page < 1 {
-		page = 1
-	}
-
-	if page > 1 {
-		pageEntries, fetchErr := c.fetchEpisodeListEntries(ctx, titleNo, page)
-		if fetchErr == nil {
-			if entry := findEpisodeEntry(pageEntries, episodeNo); entry != nil && strings.TrimSpace(entry.URL) != "" {
-				return strings.TrimSpace(entry.URL), nil
-			}
+	for _, candidate := range []int{page, page + 1, page - 1} {
+		if candidate <= 1 {
+			continue
+		}
+		pageEntries, fetchErr := c.fetchEpisodeListEntries(ctx, ref, candidate)
+		if fetchErr != nil {
+			continue
+		}
+		if entry := findEpisodeEntry(pageEntries, episodeNo); entry != nil && strings.TrimSpace(entry.URL) != "" {
+			return strings.TrimSpace(entry.URL), nil
 		}
 	}
 
@@ -296,8 +357,8 @@ func (c *Connector) searchImmediate(ctx context.Context, query string) (*immedia
 	return &payload, nil
 }
 
-func (c *Connector) resolveByTitleNo(ctx context.Context, titleNo int) (*connectors.MangaResult, error) {
-	endpoint := c.episodeListURL(titleNo, 1)
+func (c *Connector) resolveByTitleRef(ctx context.Context, ref titleRef) (*connectors.MangaResult, error) {
+	endpoint := c.episodeListURL(ref, 1)
 	body, finalURL, err := c.fetchPage(ctx, endpoint)
 	if err != nil {
 		return nil, err
@@ -311,17 +372,18 @@ func (c *Connector) resolveByTitleNo(ctx context.Context, titleNo int) (*connect
 		canonicalURL = endpoint
 	}
 
-	title := strings.TrimSpace(html.UnescapeString(firstSubmatch(metaTitlePattern, body)))
+	title := cleanText(firstSubmatch(metaTitlePattern, body))
 	if title == "" {
 		title = strings.TrimSpace(html.UnescapeString(cleanText(firstSubmatch(titleHeadingPattern, body))))
 	}
 	if title == "" {
-		title = "WEBTOON " + strconv.Itoa(titleNo)
+		title = "WEBTOON " + strconv.Itoa(ref.no)
 	}
 
 	coverImageURL := c.absoluteURL(strings.TrimSpace(html.UnescapeString(firstSubmatch(metaImagePattern, body))))
 
 	entries := extractEpisodeEntries(body, c.baseURL)
+	c.rememberEpisodeURLs(ref, entries)
 	latestEpisodeNo := findLatestEpisodeNumber(entries)
 	var latestChapter *float64
 	var latestUpdatedAt *time.Time
@@ -333,36 +395,72 @@ func (c *Connector) resolveByTitleNo(ctx context.Context, titleNo int) (*connect
 		}
 	}
 
-	sourceItemID := strconv.Itoa(titleNo)
-	return &connectors.MangaResult{
+	result := &connectors.MangaResult{
 		SourceKey:     c.Key(),
-		SourceItemID:  sourceItemID,
+		SourceItemID:  strconv.Itoa(ref.no),
 		Title:         title,
 		URL:           canonicalURL,
 		CoverImageURL: coverImageURL,
 		LatestChapter: latestChapter,
 		LastUpdatedAt: latestUpdatedAt,
-	}, nil
+	}
+	if days := parsePublicationDays(cleanText(firstSubmatch(dayInfoPattern, body))); len(days) > 0 && latestChapter != nil {
+		nextChapter := *latestChapter + 1
+		nextAt := nextPublicationDay(days, latestUpdatedAt, c.now())
+		result.NextScheduledChapter = &nextChapter
+		result.NextScheduledAt = &nextAt
+	}
+	return result, nil
 }
 
-func (c *Connector) fetchEpisodeListEntries(ctx context.Context, titleNo int, page int) ([]episodeEntry, error) {
-	endpoint := c.episodeListURL(titleNo, page)
+func (c *Connector) fetchEpisodeListEntries(ctx context.Context, ref titleRef, page int) ([]episodeEntry, error) {
+	endpoint := c.episodeListURL(ref, page)
 	body, _, err := c.fetchPage(ctx, endpoint)
 	if err != nil {
 		return nil, err
 	}
-	return extractEpisodeEntries(body, c.baseURL), nil
+	entries := extractEpisodeEntries(body, c.baseURL)
+	c.rememberEpisodeURLs(ref, entries)
+	return entries, nil
 }
 
-func (c *Connector) episodeListURL(titleNo int, page int) string {
+func (c *Connector) episodeListURL(ref titleRef, page int) string {
 	values := url.Values{}
-	values.Set("titleNo", strconv.Itoa(titleNo))
+	values.Set("titleNo", strconv.Itoa(ref.no))
 	if page > 1 {
 		values.Set("page", strconv.Itoa(page))
 	}
+	if ref.canvas {
+		return c.baseURL + "/challenge/episodeList?" + values.Encode()
+	}
 	return c.baseURL + "/episodeList?" + values.Encode()
 }
 
+func (c *Connector) cachedEpisodeURL(ref titleRef, episode int) (string, bool) {
+	c.episodeURLsMu.Lock()
+	defer c.episodeURLsMu.Unlock()
+	episodeURL, ok := c.episodeURLs[ref][episode]
+	return episodeURL, ok
+}
+
+func (c *Connector) rememberEpisodeURLs(ref titleRef, entries []episodeEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	c.episodeURLsMu.Lock()
+	defer c.episodeURLsMu.Unlock()
+	urls := c.episodeURLs[ref]
+	if urls == nil {
+		urls = make(map[int]string, len(entries))
+		c.episodeURLs[ref] = urls
+	}
+	for _, entry := range entries {
+		if strings.TrimSpace(entry.URL) != "" {
+			urls[entry.Number] = strings.TrimSpace(entry.URL)
+		}
+	}
+}
+
 func (c *Connector) fetchPage(ctx context.Context, endpoint string) (string, string, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
@@ -379,11 +477,14 @@ func (c *Connector) fetchPage(ctx context.Context, endpoint string) (string, str
 	}
 	defer res.Body.Close()
 
+	if res.StatusCode == http.StatusNotFound {
+		return "", "", fmt.Errorf("webtoons returned status %d: %w", res.StatusCode, connectors.ErrNotFound)
+	}
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		return "", "", fmt.Errorf("webtoons returned status %d", res.StatusCode)
 	}
 
-	rawBody, err := io.ReadAll(res.Body)
+	body, err := connectors.ReadPage(res.Body)
 	if err != nil {
 		return "", "", fmt.Errorf("read response body: %w", err)
 	}
@@ -393,7 +494,28 @@ func (c *Connector) fetchPage(ctx context.Context, endpoint string) (string, str
 		finalURL = res.Request.URL.String()
 	}
 
-	return string(rawBody), finalURL, nil
+	return body, finalURL, nil
+}
+
+// MediaHosts implements connectors.MediaHostProvider. Covers are served
+// from Naver's image CDN rather than the site.
+func (c *Connector) MediaHosts() []string {
+	return append(append([]string(nil), c.allowedHost...), "webtoon-phinf.pstatic.net", "swebtoon-phinf.pstatic.net")
+}
+
+// ValidateURL implements connectors.URLValidator.
+func (c *Connector) ValidateURL(rawURL string) (string, error) {
+	return connectors.CanonicalHostURL(rawURL, c.allowedHost, "title_no", "titleNo")
+}
+
+// CanonicalScheme implements connectors.URLMigrator.
+func (c *Connector) CanonicalScheme() string {
+	return "https"
+}
+
+// HostMigrations implements connectors.URLMigrator.
+func (c *Connector) HostMigrations() []connectors.HostMigration {
+	return nil
 }
 
 func (c *Connector) isAllowedHost(host string) bool {
@@ -444,9 +566,11 @@ func (c *Connector) absoluteImageURL(raw string) string {
 	return c.imageBaseURL + "/" + trimmed
 }
 
-func extractTitleNo(parsedURL *url.URL) (int, error) {
+// extractTitleRef reads the series from a list or viewer URL. Canvas
+// series live under /{locale}/canvas/ or the older /challenge/ paths.
+func extractTitleRef(parsedURL *url.URL) (titleRef, error) {
 	if parsedURL == nil {
-		return 0, fmt.Errorf("invalid webtoons url")
+		return titleRef{}, fmt.Errorf("invalid webtoons url")
 	}
 
 	titleRaw := strings.TrimSpace(parsedURL.Query().Get("title_no"))
@@ -454,15 +578,22 @@ func extractTitleNo(parsedURL *url.URL) (int, error) {
 		titleRaw = strings.TrimSpace(parsedURL.Query().Get("titleNo"))
 	}
 	if titleRaw == "" {
-		return 0, fmt.Errorf("webtoons url must include title_no or titleNo")
+		return titleRef{}, fmt.Errorf("webtoons url must include title_no or titleNo")
 	}
 
 	titleNo, err := strconv.Atoi(titleRaw)
 	if err != nil || titleNo <= 0 {
-		return 0, fmt.Errorf("invalid webtoons title number")
+		return titleRef{}, fmt.Errorf("invalid webtoons title number")
 	}
 
-	return titleNo, nil
+	ref := titleRef{no: titleNo}
+	for _, segment := range strings.Split(parsedURL.Path, "/") {
+		if strings.EqualFold(segment, "canvas") || strings.EqualFold(segment, "challenge") {
+			ref.canvas = true
+			break
+		}
+	}
+	return ref, nil
 }
 
 func parseEpisodeNumber(chapter float64) (int, error) {
@@ -482,6 +613,10 @@ func parseEpisodeNumber(chapter float64) (int, error) {
 	return episode, nil
 }
 
+// extractEpisodeEntries reads an episode list page. Entries are numbered by
+// the "Episode N" in their titles; untitled rows such as a prologue or a
+// hiatus notice are left out. A page whose titles carry no numbers at all
+// falls back to numbering by episode_no.
 func extractEpisodeEntries(body string, baseURL string) []episodeEntry {
 	matches := episodeItemPattern.FindAllStringSubmatch(body, -1)
 	if len(matches) == 0 {
@@ -489,29 +624,90 @@ func extractEpisodeEntries(body string, baseURL string) []episodeEntry {
 	}
 
 	entries := make([]episodeEntry, 0, len(matches))
+	titled := false
 	for _, match := range matches {
 		if len(match) < 3 {
 			continue
 		}
 
-		number, err := strconv.Atoi(strings.TrimSpace(match[1]))
-		if err != nil || number <= 0 {
+		episodeNo, err := strconv.Atoi(strings.TrimSpace(match[1]))
+		if err != nil || episodeNo <= 0 {
 			continue
 		}
 
 		block := match[2]
 		href := strings.TrimSpace(html.UnescapeString(firstSubmatch(episodeHrefPattern, block)))
 		dateRaw := strings.TrimSpace(html.UnescapeString(firstSubmatch(episodeDatePattern, block)))
+		displayNo := parseDisplayNumber(cleanText(firstSubmatch(episodeTitlePattern, block)))
+		if displayNo > 0 {
+			titled = true
+		}
 
 		entry := episodeEntry{
-			Number:  number,
-			URL:     toAbsoluteURL(baseURL, href),
-			DateRaw: dateRaw,
+			Number:    displayNo,
+			EpisodeNo: episodeNo,
+			URL:       toAbsoluteURL(baseURL, href),
+			DateRaw:   dateRaw,
 		}
 		entries = append(entries, entry)
 	}
 
-	return entries
+	if !titled {
+		for index := range entries {
+			entries[index].Number = entries[index].EpisodeNo
+		}
+		return entries
+	}
+	return slices.DeleteFunc(entries, func(entry episodeEntry) bool {
+		return entry.Number <= 0
+	})
+}
+
+func parseDisplayNumber(title string) int {
+	number, err := strconv.Atoi(firstSubmatch(displayNumberRegex, title))
+	if err != nil || number <= 0 {
+		return 0
+	}
+	return number
+}
+
+// parsePublicationDays reads the weekdays of a list page's "UP EVERY
+// SATURDAY" line. Completed series have none.
+func parsePublicationDays(dayInfo string) []time.Weekday {
+	dayInfo = strings.ToUpper(dayInfo)
+	if dayInfo == "" || strings.Contains(dayInfo, "COMPLETED") {
+		return nil
+	}
+
+	days := make([]time.Weekday, 0, 2)
+	for _, word := range nonLetterPattern.Split(dayInfo, -1) {
+		if len(word) < 3 {
+			continue
+		}
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.HasPrefix(weekdayNames[day], word) && !slices.Contains(days, day) {
+				days = append(days, day)
+			}
+		}
+	}
+	return days
+}
+
+// nextPublicationDay returns the start, in UTC, of the first publication day
+// from today on that comes after the latest episode's release date.
+func nextPublicationDay(days []time.Weekday, lastRelease *time.Time, now time.Time) time.Time {
+	now = now.UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if lastRelease != nil && !lastRelease.Before(from) {
+		released := lastRelease.UTC()
+		from = time.Date(released.Year(), released.Month(), released.Day()+1, 0, 0, 0, 0, time.UTC)
+	}
+	for offset := 0; offset < 6; offset++ {
+		if day := from.AddDate(0, 0, offset); slices.Contains(days, day.Weekday()) {
+			return day
+		}
+	}
+	return from.AddDate(0, 0, 6)
 }
 
 func findEpisodeEntry(entries []episodeEntry, episodeNo int) *episodeEntry {
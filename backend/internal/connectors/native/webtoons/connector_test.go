@@ -2,10 +2,15 @@ package webtoons
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/conformance"
 )
 
 func TestWebtoonsConnectorSearchResolveAndChapterURL(t *testing.T) {
@@ -187,9 +192,183 @@ func TestWebtoonsConnectorSearchResolveAndChapterURL(t *testing.T) {
 	}
 }
 
+func TestWebtoonsConnectorMapsCanvasDisplayNumbersToEpisodeNo(t *testing.T) {
+	// A prologue and a hiatus notice were posted as episodes, so "Episode N"
+	// is episode_no N+1 up to the notice and N+2 after it.
+	titles := map[int]string{1: "Prologue", 22: "Hiatus Notice"}
+	for episodeNo := 2; episodeNo <= 21; episodeNo++ {
+		titles[episodeNo] = fmt.Sprintf("Episode %d", episodeNo-1)
+	}
+	titles[23] = "Episode 21"
+	released := time.Date(2026, 2, 18, 0, 0, 0, 0, time.UTC)
+	listPage := func(from int, to int) string {
+		var rows strings.Builder
+		for episodeNo := from; episodeNo >= to; episodeNo-- {
+			fmt.Fprintf(&rows, `
+		<li class="_episodeItem" data-episode-no="%d">
+			<a href="https://www.webtoons.com/en/canvas/night-shift/ep-%d/viewer?title_no=701234&episode_no=%d">
+				<span class="subj"><span>%s</span></span>
+				<span class="date">%s</span>
+				<span class="tx">#%d</span>
+			</a>
+		</li>`, episodeNo, episodeNo, episodeNo, titles[episodeNo], released.AddDate(0, 0, 7*(episodeNo-23)).Format("Jan 02, 2006"), episodeNo)
+		}
+		return `<ul id="_listUl">` + rows.String() + `</ul>`
+	}
+
+	requests := map[string]int{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/episodeList", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected canvas lists under /challenge, got %s", r.URL)
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/challenge/episodeList", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("titleNo") != "701234" {
+			http.NotFound(w, r)
+			return
+		}
+		page := r.URL.Query().Get("page")
+		requests[page]++
+		switch page {
+		case "":
+			_, _ = w.Write([]byte(`
+<html>
+<head>
+	<link rel="canonical" href="https://www.webtoons.com/en/canvas/night-shift/list?title_no=701234" />
+	<meta property="og:title" content="Night Shift" />
+	<meta property="og:image" content="https://swebtoon-phinf.pstatic.net/night-shift.jpg" />
+</head>
+<body>
+	<p class="day_info"><span class="txt_ico_up">UP</span>EVERY WED, SAT</p>
+	` + listPage(23, 14) + `
+</body>
+</html>`))
+		case "2":
+			_, _ = w.Write([]byte(listPage(13, 4)))
+		case "3":
+			_, _ = w.Write([]byte(listPage(3, 1)))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	connector := NewConnectorWithOptions(server.URL, []string{"webtoons.com"}, &http.Client{Timeout: 5 * time.Second})
+	connector.now = func() time.Time { return time.Date(2026, 2, 18, 10, 0, 0, 0, time.UTC) }
+	seriesURL := "https://www.webtoons.com/en/canvas/night-shift/list?title_no=701234"
+
+	resolved, err := connector.ResolveByURL(context.Background(), seriesURL)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if resolved.SourceItemID != "701234" || resolved.Title != "Night Shift" {
+		t.Fatalf("unexpected resolved series: %+v", resolved)
+	}
+	if resolved.CoverImageURL != "https://swebtoon-phinf.pstatic.net/night-shift.jpg" {
+		t.Fatalf("unexpected cover url: %s", resolved.CoverImageURL)
+	}
+	if resolved.LatestChapter == nil || *resolved.LatestChapter != 21 {
+		t.Fatalf("expected the display number 21 as latest chapter, got %v", resolved.LatestChapter)
+	}
+	if resolved.LastUpdatedAt == nil || !resolved.LastUpdatedAt.Equal(released) {
+		t.Fatalf("expected latest release %s, got %v", released, resolved.LastUpdatedAt)
+	}
+	// Episode 21 came out on Wednesday, so the next one is due on Saturday.
+	if resolved.NextScheduledChapter == nil || *resolved.NextScheduledChapter != 22 {
+		t.Fatalf("expected episode 22 to be scheduled, got %v", resolved.NextScheduledChapter)
+	}
+	if expected := time.Date(2026, 2, 21, 0, 0, 0, 0, time.UTC); resolved.NextScheduledAt == nil || !resolved.NextScheduledAt.Equal(expected) {
+		t.Fatalf("expected episode 22 on %s, got %v", expected, resolved.NextScheduledAt)
+	}
+
+	chapterURL, err := connector.ResolveChapterURL(context.Background(), seriesURL, 20)
+	if err != nil {
+		t.Fatalf("resolve episode 20: %v", err)
+	}
+	if !strings.HasSuffix(chapterURL, "episode_no=21") {
+		t.Fatalf("expected episode 20 at episode_no 21, got %s", chapterURL)
+	}
+	if requests[""] != 1 {
+		t.Fatalf("expected episode 20 to come from the cached first page, got %d page 1 requests", requests[""])
+	}
+
+	// Episode 2 is estimated on page 2 but the shift puts it on page 3.
+	chapterURL, err = connector.ResolveChapterURL(context.Background(), seriesURL, 2)
+	if err != nil {
+		t.Fatalf("resolve episode 2: %v", err)
+	}
+	if !strings.HasSuffix(chapterURL, "episode_no=3") {
+		t.Fatalf("expected episode 2 at episode_no 3, got %s", chapterURL)
+	}
+
+	if _, err := connector.ResolveChapterURL(context.Background(), seriesURL, 22); err == nil {
+		t.Fatalf("expected an unreleased episode to fail")
+	}
+}
+
+func TestParsePublicationDays(t *testing.T) {
+	cases := map[string][]time.Weekday{
+		"UP EVERY SATURDAY":     {time.Saturday},
+		"UP EVERY MON, THURS":   {time.Monday, time.Thursday},
+		"EVERY sunday & friday": {time.Sunday, time.Friday},
+		"COMPLETED":             nil,
+		"":                      nil,
+	}
+	for dayInfo, expected := range cases {
+		days := parsePublicationDays(dayInfo)
+		if fmt.Sprint(days) != fmt.Sprint(expected) {
+			t.Fatalf("parsePublicationDays(%q) = %v, want %v", dayInfo, days, expected)
+		}
+	}
+}
+
 func TestWebtoonsConnectorRejectsNonWebtoonsURL(t *testing.T) {
 	connector := NewConnectorWithOptions("https://www.webtoons.com", []string{"webtoons.com"}, &http.Client{Timeout: 5 * time.Second})
 	if _, err := connector.ResolveByURL(context.Background(), "https://example.com/en/romance/maybe-meant-to-be/list?title_no=4208"); err == nil {
 		t.Fatalf("expected non-webtoons url to fail")
 	}
 }
+
+func TestWebtoonsConnectorConformance(t *testing.T) {
+	episodeList := func(title string, titleNo string) conformance.Fixture {
+		return conformance.Fixture{Body: `
+<!DOCTYPE html>
+<html>
+<head>
+	<link rel="canonical" href="https://www.webtoons.com/en/action/series/list?title_no=` + titleNo + `" />
+	<meta property="og:title" content="` + title + `" />
+</head>
+<body>
+	<ul id="_listUl">
+		<li class="_episodeItem" data-episode-no="12">
+			<a href="https://www.webtoons.com/en/action/series/ep-12/viewer?title_no=` + titleNo + `&episode_no=12"><span class="date">Feb 18, 2026</span></a>
+		</li>
+	</ul>
+</body>
+</html>`}
+	}
+
+	conformance.RunConformanceSuite(t, func(baseURL string) connectors.Connector {
+		return NewConnectorWithOptions(baseURL, []string{"webtoons.com"}, &http.Client{Timeout: 5 * time.Second})
+	}, conformance.FixtureSet{
+		Routes: map[string]conformance.Fixture{
+			"/en/search/immediate": {ContentType: "application/json", Body: `{
+  "result": {
+    "searchedList": [
+      {"titleNo": 4208, "title": "Tom &amp; Jerry&#39;s\n   Blade", "searchMode": "TITLE"},
+      {"titleNo": 4209, "title": "Second Blade", "searchMode": "TITLE"}
+    ]
+  },
+  "success": true
+}`},
+			"/episodeList?titleNo=4208": episodeList("  Tom &amp; Jerry&#39;s\n   Blade ", "4208"),
+			"/episodeList?titleNo=4209": episodeList("Second Blade", "4209"),
+		},
+		SeriesURL:    "https://www.webtoons.com/en/action/tom-and-jerrys-blade/list?title_no=4208",
+		SeriesTitle:  "Tom & Jerry's Blade",
+		MissingURL:   "https://www.webtoons.com/en/action/missing-series/list?title_no=9999",
+		WrongHostURL: "https://example.com/en/action/tom-and-jerrys-blade/list?title_no=4208",
+		SearchQuery:  "blade",
+	})
+}
@@ -0,0 +1,70 @@
+package connectors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxPageBytes caps how much of a scraped page is read. Series and search
+// pages are well under a megabyte; anything past the cap is not worth
+// running the parsing regexes over.
+const MaxPageBytes = 4 << 20
+
+// ErrPageTooLarge is returned by ReadPage for a body over MaxPageBytes.
+var ErrPageTooLarge = errors.New("page too large")
+
+// ReadPage reads a scraped page body, failing with ErrPageTooLarge rather
+// than reading more than MaxPageBytes.
+func ReadPage(body io.Reader) (string, error) {
+	raw, err := io.ReadAll(io.LimitReader(body, MaxPageBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if len(raw) > MaxPageBytes {
+		return "", fmt.Errorf("over %d bytes: %w", MaxPageBytes, ErrPageTooLarge)
+	}
+	return string(raw), nil
+}
+
+// Window returns s[start:end] with both bounds clamped to s and moved back
+// onto rune boundaries, so a window taken at a byte offset near multi-byte
+// text never splits a character.
+func Window(s string, start int, end int) string {
+	start = runeStart(s, max(0, min(start, len(s))))
+	end = runeStart(s, max(start, min(end, len(s))))
+	return s[start:end]
+}
+
+func runeStart(s string, index int) int {
+	for index > 0 && index < len(s) && !utf8.RuneStart(s[index]) {
+		index--
+	}
+	return index
+}
+
+// MatchWithin returns the first of locs, match indexes in the ascending
+// order FindAllStringIndex returns them, that lies wholly inside
+// [start, end), or nil. Matching a page once and looking matches up by
+// offset keeps a parser linear, where running a pattern over a window after
+// every link is quadratic on pages dense with links.
+func MatchWithin(locs [][]int, start int, end int) []int {
+	index := sort.Search(len(locs), func(i int) bool { return locs[i][0] >= start })
+	if index < len(locs) && locs[index][1] <= end {
+		return locs[index]
+	}
+	return nil
+}
+
+// UpperFirst upper-cases the first character of word, which may be
+// multi-byte.
+func UpperFirst(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+	if first == utf8.RuneError {
+		return word
+	}
+	return string(unicode.ToUpper(first)) + word[size:]
+}
@@ -0,0 +1,61 @@
+package connectors_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+func TestReadPageRejectsBodiesOverTheCap(t *testing.T) {
+	body, err := connectors.ReadPage(strings.NewReader(strings.Repeat("a", connectors.MaxPageBytes)))
+	if err != nil || len(body) != connectors.MaxPageBytes {
+		t.Fatalf("expected a page at the cap to be read, got %d bytes (%v)", len(body), err)
+	}
+
+	_, err = connectors.ReadPage(strings.NewReader(strings.Repeat("a", connectors.MaxPageBytes+1)))
+	if !errors.Is(err, connectors.ErrPageTooLarge) {
+		t.Fatalf("expected ErrPageTooLarge, got %v", err)
+	}
+}
+
+func TestWindowClampsAndKeepsRunesWhole(t *testing.T) {
+	cases := []struct {
+		s          string
+		start, end int
+		want       string
+	}{
+		{s: "chapter", start: 2, end: 5, want: "apt"},
+		{s: "chapter", start: -3, end: 100, want: "chapter"},
+		{s: "chapter", start: 9, end: 3, want: ""},
+		{s: "ab漫画", start: 0, end: 4, want: "ab"},
+		{s: "ab漫画", start: 3, end: 7, want: "漫"},
+	}
+	for _, tc := range cases {
+		if got := connectors.Window(tc.s, tc.start, tc.end); got != tc.want {
+			t.Fatalf("Window(%q, %d, %d) = %q, want %q", tc.s, tc.start, tc.end, got, tc.want)
+		}
+	}
+}
+
+func TestMatchWithinPicksTheFirstMatchInRange(t *testing.T) {
+	locs := [][]int{{2, 6}, {10, 14}, {20, 30}}
+	if got := connectors.MatchWithin(locs, 3, 20); got == nil || got[0] != 10 {
+		t.Fatalf("expected the match at 10, got %v", got)
+	}
+	if got := connectors.MatchWithin(locs, 11, 25); got != nil {
+		t.Fatalf("expected a match running past the end to be skipped, got %v", got)
+	}
+	if got := connectors.MatchWithin(locs, 31, 40); got != nil {
+		t.Fatalf("expected no match after the last, got %v", got)
+	}
+}
+
+func TestUpperFirstHandlesMultiByteLetters(t *testing.T) {
+	for raw, want := range map[string]string{"blade": "Blade", "ébène": "Ébène", "漫画": "漫画", "": ""} {
+		if got := connectors.UpperFirst(raw); got != want {
+			t.Fatalf("UpperFirst(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
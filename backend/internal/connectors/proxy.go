@@ -0,0 +1,72 @@
+package connectors
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// HTTPClientProvider is implemented by connectors that make their requests
+// with an http.Client, so the bootstrap can route them through a proxy.
+type HTTPClientProvider interface {
+	HTTPClient() *http.Client
+}
+
+// RouteThroughProxy makes client send its requests through proxy. The
+// client's transport is copied rather than changed, so clients sharing it
+// are left alone. Transports that dial TLS themselves cannot be proxied.
+func RouteThroughProxy(client *http.Client, proxy *url.URL) error {
+	if client == nil || proxy == nil {
+		return fmt.Errorf("client and proxy are required")
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("transport %T cannot be given a proxy", base)
+	}
+	if transport.DialTLSContext != nil || transport.DialTLS != nil {
+		return fmt.Errorf("transport dials TLS itself and cannot go through a proxy")
+	}
+
+	proxied := transport.Clone()
+	proxied.Proxy = http.ProxyURL(proxy)
+	client.Transport = proxied
+	return nil
+}
+
+// SetProxy notes that the connector registered as key goes through proxy,
+// so health checks can report it. The password, if any, is not kept.
+func (r *Registry) SetProxy(key string, proxy *url.URL) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.proxies == nil {
+		r.proxies = map[string]string{}
+	}
+	if proxy == nil {
+		delete(r.proxies, key)
+		return
+	}
+	r.proxies[key] = proxy.Redacted()
+}
+
+// SetProxyNote notes why the connector registered as key does not go
+// through the proxy the others use, so health checks can report it. An
+// empty note clears it.
+func (r *Registry) SetProxyNote(key string, note string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.proxyNotes == nil {
+		r.proxyNotes = map[string]string{}
+	}
+	if note == "" {
+		delete(r.proxyNotes, key)
+		return
+	}
+	r.proxyNotes[key] = note
+}
@@ -7,17 +7,43 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Registry struct {
-	mu         sync.RWMutex
-	connectors map[string]Connector
+	mu          sync.RWMutex
+	connectors  map[string]Connector
+	diagnostics []Diagnostic
+	// proxies holds the redacted proxy URL of each connector that uses one.
+	proxies map[string]string
+	// proxyNotes says why a connector goes direct despite a default proxy.
+	proxyNotes map[string]string
+	// strictMedia makes MediaURL drop links off a connector's media hosts.
+	strictMedia bool
+}
+
+const (
+	DiagnosticLoaded  = "loaded"
+	DiagnosticSkipped = "skipped"
+)
+
+// Diagnostic records what happened to one connector handed to Register, so
+// a connector that silently failed to load can still be seen.
+type Diagnostic struct {
+	Key    string `json:"key"`
+	Kind   string `json:"kind,omitempty"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
 }
 
 type Descriptor struct {
-	Key  string `json:"key"`
-	Name string `json:"name"`
-	Kind string `json:"kind"`
+	Key        string `json:"key"`
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	SearchMode string `json:"searchMode"`
+	// MediaHosts are the hosts the connector's cover and chapter links may
+	// be on; see MediaHostProvider.
+	MediaHosts []string `json:"mediaHosts,omitempty"`
 }
 
 type HealthStatus struct {
@@ -26,6 +52,17 @@ type HealthStatus struct {
 	Kind    string `json:"kind"`
 	Healthy bool   `json:"healthy"`
 	Error   string `json:"error,omitempty"`
+	// StatusNote is the source's known-issue note, filled in by the caller
+	// since the registry does not store notes.
+	StatusNote string `json:"statusNote,omitempty"`
+	// Canary is set by DeepHealth for connectors that declare a canary.
+	Canary *CanaryResult `json:"canary,omitempty"`
+	// Proxy is the proxy the connector's requests go through, without its
+	// password; empty when they go direct.
+	Proxy string `json:"proxy,omitempty"`
+	// ProxyNote says why the connector goes direct while the others use a
+	// default proxy.
+	ProxyNote string `json:"proxyNote,omitempty"`
 }
 
 func NewRegistry() *Registry {
@@ -37,22 +74,35 @@ func (r *Registry) Register(connector Connector) error {
 		return fmt.Errorf("connector is nil")
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	key := connector.Key()
 	if key == "" {
-		return fmt.Errorf("connector key is required")
+		err := fmt.Errorf("connector key is required")
+		r.diagnostics = append(r.diagnostics, Diagnostic{Kind: connector.Kind(), Status: DiagnosticSkipped, Reason: err.Error()})
+		return err
 	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	if _, exists := r.connectors[key]; exists {
-		return fmt.Errorf("connector %q already registered", key)
+		err := fmt.Errorf("connector %q already registered", key)
+		r.diagnostics = append(r.diagnostics, Diagnostic{Key: key, Kind: connector.Kind(), Status: DiagnosticSkipped, Reason: err.Error()})
+		return err
 	}
 
 	r.connectors[key] = connector
+	r.diagnostics = append(r.diagnostics, Diagnostic{Key: key, Kind: connector.Kind(), Status: DiagnosticLoaded})
 	return nil
 }
 
+// Diagnostics returns one entry per Register call, in registration order.
+func (r *Registry) Diagnostics() []Diagnostic {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]Diagnostic(nil), r.diagnostics...)
+}
+
 func (r *Registry) Get(key string) (Connector, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -80,6 +130,129 @@ func (r *Registry) Get(key string) (Connector, bool) {
 	return nil, false
 }
 
+// ValidateSourceURL returns rawURL in the canonical form of the connector
+// registered for sourceKey. Connectors that do not implement URLValidator
+// accept any URL unchanged.
+func (r *Registry) ValidateSourceURL(sourceKey string, rawURL string) (string, error) {
+	connector, ok := r.Get(sourceKey)
+	if !ok {
+		return strings.TrimSpace(rawURL), nil
+	}
+	validator, ok := connector.(URLValidator)
+	if !ok {
+		return strings.TrimSpace(rawURL), nil
+	}
+	return validator.ValidateURL(rawURL)
+}
+
+// SearchPageURL returns the source site's search page for query, or "" when
+// the connector registered for sourceKey does not implement SearchPageLinker.
+func (r *Registry) SearchPageURL(sourceKey string, query string) string {
+	connector, ok := r.Get(sourceKey)
+	if !ok {
+		return ""
+	}
+	linker, ok := connector.(SearchPageLinker)
+	if !ok || strings.TrimSpace(query) == "" {
+		return ""
+	}
+	return linker.SearchPageURL(strings.TrimSpace(query))
+}
+
+// LanguageAware reports whether the connector registered for sourceKey
+// implements LanguageAwareResolver, so its linked sources offer a language.
+func (r *Registry) LanguageAware(sourceKey string) bool {
+	connector, ok := r.Get(sourceKey)
+	if !ok {
+		return false
+	}
+	_, ok = connector.(LanguageAwareResolver)
+	return ok
+}
+
+// URLBelongsTo reports whether the connector registered for sourceKey
+// positively claims rawURL. Manual connectors accept any URL, so they never
+// claim one.
+func (r *Registry) URLBelongsTo(sourceKey string, rawURL string) bool {
+	connector, ok := r.Get(sourceKey)
+	if !ok || connector.Kind() == KindManual {
+		return false
+	}
+	validator, ok := connector.(URLValidator)
+	if !ok {
+		return false
+	}
+	_, err := validator.ValidateURL(rawURL)
+	return err == nil
+}
+
+// URLMigrationRules returns the stored-URL rewrites declared by the connector
+// registered for sourceKey. ok is false when it declares none.
+func (r *Registry) URLMigrationRules(sourceKey string) (URLMigrationRules, bool) {
+	connector, ok := r.Get(sourceKey)
+	if !ok {
+		return URLMigrationRules{}, false
+	}
+	return urlMigrationRulesOf(connector)
+}
+
+// AllURLMigrationRules returns every registered connector's stored-URL
+// rewrites, sorted by source key.
+func (r *Registry) AllURLMigrationRules() []URLMigrationRules {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	items := make([]URLMigrationRules, 0, len(r.connectors))
+	for _, connector := range r.connectors {
+		if rules, ok := urlMigrationRulesOf(connector); ok {
+			items = append(items, rules)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].SourceKey < items[j].SourceKey
+	})
+	return items
+}
+
+func urlMigrationRulesOf(connector Connector) (URLMigrationRules, bool) {
+	migrator, ok := connector.(URLMigrator)
+	if !ok {
+		return URLMigrationRules{}, false
+	}
+	rules := URLMigrationRules{
+		SourceKey: connector.Key(),
+		Scheme:    migrator.CanonicalScheme(),
+		Hosts:     migrator.HostMigrations(),
+	}
+	if rules.Scheme == "" && len(rules.Hosts) == 0 {
+		return URLMigrationRules{}, false
+	}
+	return rules, true
+}
+
+// SearchModes maps each registered connector key to its declared search mode,
+// which is what the sources table is synced to at startup.
+func (r *Registry) SearchModes() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	modes := make(map[string]string, len(r.connectors))
+	for key, connector := range r.connectors {
+		modes[key] = searchModeOf(connector)
+	}
+	return modes
+}
+
+func searchModeOf(connector Connector) string {
+	if provider, ok := connector.(SearchModeProvider); ok {
+		switch mode := provider.SearchMode(); mode {
+		case SearchModeTitle, SearchModeURLOnly, SearchModeTitleOrURL:
+			return mode
+		}
+	}
+	return SearchModeTitle
+}
+
 func normalizeConnectorKey(raw string) string {
 	key := strings.TrimSpace(strings.ToLower(raw))
 	if key == "" {
@@ -134,9 +307,11 @@ func (r *Registry) List() []Descriptor {
 	items := make([]Descriptor, 0, len(r.connectors))
 	for _, connector := range r.connectors {
 		items = append(items, Descriptor{
-			Key:  connector.Key(),
-			Name: connector.Name(),
-			Kind: connector.Kind(),
+			Key:        connector.Key(),
+			Name:       connector.Name(),
+			Kind:       connector.Kind(),
+			SearchMode: searchModeOf(connector),
+			MediaHosts: mediaHostsOf(connector),
 		})
 	}
 
@@ -148,11 +323,47 @@ func (r *Registry) List() []Descriptor {
 }
 
 func (r *Registry) Health(ctx context.Context) []HealthStatus {
+	return r.checkAll(func(connector Connector) HealthStatus {
+		return healthStatusOf(connector, connector.HealthCheck(ctx))
+	})
+}
+
+// DeepHealth runs each connector's HealthCheck and, for connectors that
+// implement CanaryProvider, resolves their canary. Each connector gets its
+// own timeout so one slow site does not use up the others' time.
+func (r *Registry) DeepHealth(ctx context.Context, timeout time.Duration) []HealthStatus {
+	return r.checkAll(func(connector Connector) HealthStatus {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		status := healthStatusOf(connector, connector.HealthCheck(checkCtx))
+		if provider, ok := connector.(CanaryProvider); ok {
+			canary := CheckCanary(checkCtx, connector, provider.Canary())
+			status.Canary = &canary
+			if !canary.Passed {
+				status.Healthy = false
+			}
+		}
+		return status
+	})
+}
+
+// checkAll runs check concurrently for every registered connector and
+// returns the statuses sorted by key.
+func (r *Registry) checkAll(check func(Connector) HealthStatus) []HealthStatus {
 	r.mu.RLock()
 	list := make([]Connector, 0, len(r.connectors))
 	for _, connector := range r.connectors {
 		list = append(list, connector)
 	}
+	proxies := make(map[string]string, len(r.proxies))
+	for key, proxy := range r.proxies {
+		proxies[key] = proxy
+	}
+	proxyNotes := make(map[string]string, len(r.proxyNotes))
+	for key, note := range r.proxyNotes {
+		proxyNotes[key] = note
+	}
 	r.mu.RUnlock()
 
 	statuses := make([]HealthStatus, len(list))
@@ -163,18 +374,9 @@ func (r *Registry) Health(ctx context.Context) []HealthStatus {
 		connector := connector
 		go func() {
 			defer wg.Done()
-
-			err := connector.HealthCheck(ctx)
-			status := HealthStatus{
-				Key:     connector.Key(),
-				Name:    connector.Name(),
-				Kind:    connector.Kind(),
-				Healthy: err == nil,
-			}
-			if err != nil {
-				status.Error = err.Error()
-			}
-			statuses[index] = status
+			statuses[index] = check(connector)
+			statuses[index].Proxy = proxies[connector.Key()]
+			statuses[index].ProxyNote = proxyNotes[connector.Key()]
 		}()
 	}
 	wg.Wait()
@@ -185,3 +387,16 @@ func (r *Registry) Health(ctx context.Context) []HealthStatus {
 
 	return statuses
 }
+
+func healthStatusOf(connector Connector, err error) HealthStatus {
+	status := HealthStatus{
+		Key:     connector.Key(),
+		Name:    connector.Name(),
+		Kind:    connector.Kind(),
+		Healthy: err == nil,
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -43,6 +43,9 @@ func TestRegistryRegisterListHealth(t *testing.T) {
 	if list[0].Key != "a" || list[1].Key != "b" {
 		t.Fatalf("expected sorted keys a,b got %s,%s", list[0].Key, list[1].Key)
 	}
+	if list[0].SearchMode != connectors.SearchModeTitle {
+		t.Fatalf("expected default title search mode, got %q", list[0].SearchMode)
+	}
 
 	health := r.Health(context.Background())
 	if len(health) != 2 {
@@ -56,6 +59,35 @@ func TestRegistryRegisterListHealth(t *testing.T) {
 	}
 }
 
+func TestRegistryDiagnosticsRecordSkippedConnectors(t *testing.T) {
+	r := connectors.NewRegistry()
+
+	_ = r.Register(&fakeConnector{key: "a", kind: connectors.KindNative})
+	if err := r.Register(&fakeConnector{key: "a", kind: "custom"}); err == nil {
+		t.Fatalf("expected a duplicate key to be rejected")
+	}
+	if err := r.Register(&fakeConnector{kind: "custom"}); err == nil {
+		t.Fatalf("expected an empty key to be rejected")
+	}
+
+	diagnostics := r.Diagnostics()
+	if len(diagnostics) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %+v", diagnostics)
+	}
+	if diagnostics[0].Status != connectors.DiagnosticLoaded || diagnostics[0].Key != "a" {
+		t.Fatalf("expected a to be loaded, got %+v", diagnostics[0])
+	}
+	if diagnostics[1].Status != connectors.DiagnosticSkipped || diagnostics[1].Kind != "custom" || diagnostics[1].Reason == "" {
+		t.Fatalf("expected the duplicate to be skipped with a reason, got %+v", diagnostics[1])
+	}
+	if diagnostics[2].Status != connectors.DiagnosticSkipped || diagnostics[2].Reason != "connector key is required" {
+		t.Fatalf("expected the keyless connector to be skipped, got %+v", diagnostics[2])
+	}
+	if got, _ := r.Get("a"); got.Kind() != connectors.KindNative {
+		t.Fatalf("expected the first registration to win")
+	}
+}
+
 func TestRegistryGetNormalizesKnownAliasAndFormatting(t *testing.T) {
 	r := connectors.NewRegistry()
 	if err := r.Register(&fakeConnector{key: "mangafire", name: "MangaFire", kind: connectors.KindNative}); err != nil {
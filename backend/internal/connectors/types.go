@@ -2,13 +2,34 @@ package connectors
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
 const (
 	KindNative = "native"
+	// KindManual connectors make no requests: their trackers' chapters are
+	// logged by hand and the poller leaves them alone.
+	KindManual = "manual"
 )
 
+// Search modes describe what the add-tracker search box accepts for a
+// source: title text, a pasted series URL, or either.
+const (
+	SearchModeTitle      = "title"
+	SearchModeURLOnly    = "url_only"
+	SearchModeTitleOrURL = "title_or_url"
+)
+
+// ErrScrapingPaused is returned instead of making an outbound connector
+// request while the global scraping pause switch is on.
+var ErrScrapingPaused = errors.New("scraping is paused")
+
+// ErrNotFound is matched, through errors.Is, by lookup errors that mean the
+// site has no series at the URL, such as an old slug or a mistyped id, as
+// opposed to the site failing to answer.
+var ErrNotFound = errors.New("series not found")
+
 type MangaResult struct {
 	SourceKey     string     `json:"sourceKey"`
 	SourceItemID  string     `json:"sourceItemId"`
@@ -18,6 +39,14 @@ type MangaResult struct {
 	CoverImageURL string     `json:"coverImageUrl,omitempty"`
 	LatestChapter *float64   `json:"latestChapter,omitempty"`
 	LastUpdatedAt *time.Time `json:"lastUpdatedAt,omitempty"`
+	// Genres are the site's own genre labels for the series, passed through
+	// CleanGenres.
+	Genres []string `json:"genres,omitempty"`
+	// NextScheduledChapter is a chapter the site lists ahead of its release,
+	// out at NextScheduledAt. LatestChapter and LastUpdatedAt only ever
+	// describe chapters already out.
+	NextScheduledChapter *float64   `json:"nextScheduledChapter,omitempty"`
+	NextScheduledAt      *time.Time `json:"nextScheduledAt,omitempty"`
 }
 
 type Connector interface {
@@ -29,6 +58,70 @@ type Connector interface {
 	SearchByTitle(ctx context.Context, title string, limit int) ([]MangaResult, error)
 }
 
+// SearchModeProvider is implemented by connectors whose search box should not
+// default to SearchModeTitle.
+type SearchModeProvider interface {
+	SearchMode() string
+}
+
+// SearchPageLinker is implemented by connectors whose site has a search page
+// that can be opened pre-filled with a query, as a fallback when in-app
+// search fails. SearchPageURL returns "" when no link can be built.
+type SearchPageLinker interface {
+	SearchPageURL(query string) string
+}
+
+// LanguageAwareResolver is implemented by connectors whose site serves the
+// same series in several translations. lang is a lowercase language code
+// such as "en" or "pt-br".
+type LanguageAwareResolver interface {
+	ResolveByURLWithLang(ctx context.Context, rawURL string, lang string) (*MangaResult, error)
+}
+
+// BulkResolver is implemented by connectors whose site can look up several
+// series in one request. ResolveMany takes at most MaxBatchSize URLs and
+// returns results, in DefaultLanguage, keyed by the URLs it was given; a URL
+// missing from the map is left for ResolveByURL.
+type BulkResolver interface {
+	ResolveMany(ctx context.Context, urls []string) (map[string]*MangaResult, error)
+	MaxBatchSize() int
+}
+
 type ChapterURLResolver interface {
 	ResolveChapterURL(ctx context.Context, rawURL string, chapter float64) (string, error)
 }
+
+// URLValidator is implemented by connectors that can tell, without a network
+// request, whether a URL belongs to them. ValidateURL returns the URL in the
+// connector's canonical form.
+type URLValidator interface {
+	ValidateURL(rawURL string) (string, error)
+}
+
+// HostMigration moves stored URLs off a host a site has left. Subdomains of
+// From move to the same subdomain of To.
+type HostMigration struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// URLMigrator is implemented by connectors that declare how stored source
+// URLs are rewritten: to CanonicalScheme when it is not "", and off the
+// hosts in HostMigrations.
+type URLMigrator interface {
+	CanonicalScheme() string
+	HostMigrations() []HostMigration
+}
+
+type ChapterInfo struct {
+	Number     float64    `json:"number"`
+	Title      string     `json:"title,omitempty"`
+	URL        string     `json:"url"`
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+}
+
+// ChapterLister is implemented by connectors that can enumerate a series'
+// chapters. Implementations return at most limit entries, newest first.
+type ChapterLister interface {
+	ListChapters(ctx context.Context, rawURL string, limit int) ([]ChapterInfo, error)
+}
@@ -0,0 +1,133 @@
+package connectors
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// HostMismatchError reports a URL whose host is not one of a connector's
+// allowed hosts.
+type HostMismatchError struct {
+	Host          string
+	ExpectedHosts []string
+}
+
+func (e *HostMismatchError) Error() string {
+	return fmt.Sprintf("url host %q is not %s", e.Host, strings.Join(e.ExpectedHosts, " or "))
+}
+
+// CanonicalHostURL checks that rawURL is an http(s) URL on one of
+// allowedHosts (subdomains included) and returns it with https, a lowercase
+// host, no fragment and only the keepQuery parameters.
+func CanonicalHostURL(rawURL string, allowedHosts []string, keepQuery ...string) (string, error) {
+	trimmed := strings.TrimSpace(rawURL)
+	if trimmed == "" {
+		return "", fmt.Errorf("url is required")
+	}
+
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	if (scheme != "http" && scheme != "https") || parsed.Hostname() == "" {
+		return "", fmt.Errorf("url must be an http(s) link")
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if !hostAllowed(host, allowedHosts) {
+		return "", &HostMismatchError{Host: host, ExpectedHosts: allowedHosts}
+	}
+
+	canonical := url.URL{
+		Scheme: "https",
+		Host:   host,
+		Path:   parsed.Path,
+	}
+	if port := parsed.Port(); port != "" && port != "80" && port != "443" {
+		canonical.Host = host + ":" + port
+	}
+	if len(keepQuery) > 0 {
+		source := parsed.Query()
+		kept := url.Values{}
+		for _, key := range keepQuery {
+			if value := strings.TrimSpace(source.Get(key)); value != "" {
+				kept.Set(key, value)
+			}
+		}
+		canonical.RawQuery = kept.Encode()
+	}
+
+	return canonical.String(), nil
+}
+
+func hostAllowed(host string, allowedHosts []string) bool {
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == "" {
+			continue
+		}
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// URLMigrationRules are the stored-URL rewrites one connector declares.
+type URLMigrationRules struct {
+	SourceKey string          `json:"sourceKey"`
+	Scheme    string          `json:"scheme,omitempty"`
+	Hosts     []HostMigration `json:"hosts,omitempty"`
+}
+
+// Migrate returns rawURL with the rules applied and a label such as
+// "http → https" for each rule that changed it. A URL no rule applies to is
+// returned unchanged with no labels.
+func (r URLMigrationRules) Migrate(rawURL string) (string, []string) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Hostname() == "" {
+		return rawURL, nil
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return rawURL, nil
+	}
+
+	var labels []string
+	host := strings.ToLower(parsed.Hostname())
+	port := parsed.Port()
+	for _, migration := range r.Hosts {
+		from := strings.ToLower(strings.TrimSpace(migration.From))
+		to := strings.ToLower(strings.TrimSpace(migration.To))
+		if from == "" || to == "" || from == to {
+			continue
+		}
+		if host != from && !strings.HasSuffix(host, "."+from) {
+			continue
+		}
+		host = strings.TrimSuffix(host, from) + to
+		labels = append(labels, from+" → "+to)
+		break
+	}
+
+	target := strings.ToLower(strings.TrimSpace(r.Scheme))
+	if target != "" && target != scheme {
+		labels = append(labels, scheme+" → "+target)
+		if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+			port = ""
+		}
+		scheme = target
+	}
+	if len(labels) == 0 {
+		return rawURL, nil
+	}
+
+	parsed.Scheme = scheme
+	parsed.Host = host
+	if port != "" {
+		parsed.Host = host + ":" + port
+	}
+	return parsed.String(), labels
+}
@@ -0,0 +1,141 @@
+package connectors_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+func TestCanonicalHostURL(t *testing.T) {
+	cases := []struct {
+		raw       string
+		keepQuery []string
+		want      string
+	}{
+		{raw: "http://MangaDex.org/title/abc?utm_source=x#top", want: "https://mangadex.org/title/abc"},
+		{raw: "  https://www.mangadex.org/title/abc  ", want: "https://www.mangadex.org/title/abc"},
+		{raw: "https://mangadex.org:443/title/abc", want: "https://mangadex.org/title/abc"},
+		{raw: "https://www.webtoons.com/en/fantasy/tower/list?title_no=95&page=2", keepQuery: []string{"title_no"}, want: "https://www.webtoons.com/en/fantasy/tower/list?title_no=95"},
+	}
+	for _, tc := range cases {
+		allowed := []string{"mangadex.org"}
+		if len(tc.keepQuery) > 0 {
+			allowed = []string{"webtoons.com"}
+		}
+		got, err := connectors.CanonicalHostURL(tc.raw, allowed, tc.keepQuery...)
+		if err != nil {
+			t.Fatalf("canonicalize %q: %v", tc.raw, err)
+		}
+		if got != tc.want {
+			t.Fatalf("canonicalize %q: expected %q, got %q", tc.raw, tc.want, got)
+		}
+	}
+}
+
+func TestCanonicalHostURLRejectsOtherHosts(t *testing.T) {
+	_, err := connectors.CanonicalHostURL("https://mangafire.to/manga/abc.123", []string{"mangadex.org"})
+	var mismatch *connectors.HostMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected host mismatch error, got %v", err)
+	}
+	if mismatch.Host != "mangafire.to" || len(mismatch.ExpectedHosts) != 1 || mismatch.ExpectedHosts[0] != "mangadex.org" {
+		t.Fatalf("unexpected mismatch details: %+v", mismatch)
+	}
+
+	if _, err := connectors.CanonicalHostURL("ftp://mangadex.org/title/abc", []string{"mangadex.org"}); err == nil {
+		t.Fatalf("expected non-http url to be rejected")
+	}
+	if _, err := connectors.CanonicalHostURL("https://notmangadex.org/title/abc", []string{"mangadex.org"}); err == nil {
+		t.Fatalf("expected look-alike host to be rejected")
+	}
+}
+
+type validatingConnector struct {
+	fakeConnector
+	hosts []string
+}
+
+func (v *validatingConnector) ValidateURL(rawURL string) (string, error) {
+	return connectors.CanonicalHostURL(rawURL, v.hosts)
+}
+
+func TestRegistryValidateSourceURL(t *testing.T) {
+	r := connectors.NewRegistry()
+	if err := r.Register(&validatingConnector{fakeConnector: fakeConnector{key: "dex", name: "Dex"}, hosts: []string{"mangadex.org"}}); err != nil {
+		t.Fatalf("register dex: %v", err)
+	}
+	if err := r.Register(&fakeConnector{key: "plain", name: "Plain"}); err != nil {
+		t.Fatalf("register plain: %v", err)
+	}
+
+	canonical, err := r.ValidateSourceURL("dex", "http://mangadex.org/title/abc?x=1")
+	if err != nil || canonical != "https://mangadex.org/title/abc" {
+		t.Fatalf("expected canonical dex url, got %q %v", canonical, err)
+	}
+	if _, err := r.ValidateSourceURL("dex", "https://mangafire.to/manga/abc.1"); err == nil {
+		t.Fatalf("expected dex to reject a mangafire url")
+	}
+	if canonical, err := r.ValidateSourceURL("plain", " https://anything.example/x?y=1 "); err != nil || canonical != "https://anything.example/x?y=1" {
+		t.Fatalf("expected connectors without a validator to accept the url unchanged, got %q %v", canonical, err)
+	}
+
+	if !r.URLBelongsTo("dex", "https://mangadex.org/title/abc") {
+		t.Fatalf("expected dex to claim its own url")
+	}
+	if r.URLBelongsTo("plain", "https://anything.example/x") {
+		t.Fatalf("expected connectors without a validator to claim nothing")
+	}
+}
+
+func TestURLMigrationRulesMigrate(t *testing.T) {
+	rules := connectors.URLMigrationRules{
+		SourceKey: "asuracomic",
+		Scheme:    "https",
+		Hosts:     []connectors.HostMigration{{From: "asuracomic.net", To: "asurascans.com"}},
+	}
+	cases := []struct {
+		raw    string
+		want   string
+		labels int
+	}{
+		{raw: "http://asuracomic.net/series/a?x=1", want: "https://asurascans.com/series/a?x=1", labels: 2},
+		{raw: "https://www.asuracomic.net/series/a", want: "https://www.asurascans.com/series/a", labels: 1},
+		{raw: "http://asurascans.com:80/series/a", want: "https://asurascans.com/series/a", labels: 1},
+		{raw: "https://asurascans.com/series/a", want: "https://asurascans.com/series/a", labels: 0},
+		{raw: "https://notasuracomic.net/series/a", want: "https://notasuracomic.net/series/a", labels: 0},
+		{raw: "not a url", want: "not a url", labels: 0},
+	}
+	for _, tc := range cases {
+		got, labels := rules.Migrate(tc.raw)
+		if got != tc.want || len(labels) != tc.labels {
+			t.Fatalf("migrate %q: expected %q with %d rules, got %q with %v", tc.raw, tc.want, tc.labels, got, labels)
+		}
+	}
+}
+
+type migratingConnector struct {
+	fakeConnector
+}
+
+func (*migratingConnector) CanonicalScheme() string { return "https" }
+func (*migratingConnector) HostMigrations() []connectors.HostMigration {
+	return []connectors.HostMigration{{From: "old.example", To: "new.example"}}
+}
+
+func TestRegistryURLMigrationRules(t *testing.T) {
+	r := connectors.NewRegistry()
+	_ = r.Register(&migratingConnector{fakeConnector{key: "moved"}})
+	_ = r.Register(&fakeConnector{key: "plain"})
+
+	rules, ok := r.URLMigrationRules("moved")
+	if !ok || rules.SourceKey != "moved" || rules.Scheme != "https" || len(rules.Hosts) != 1 {
+		t.Fatalf("expected the declared rules, got %+v %v", rules, ok)
+	}
+	if _, ok := r.URLMigrationRules("plain"); ok {
+		t.Fatalf("expected connectors without a migrator to declare no rules")
+	}
+	if all := r.AllURLMigrationRules(); len(all) != 1 || all[0].SourceKey != "moved" {
+		t.Fatalf("expected only the migrating connector, got %+v", all)
+	}
+}
@@ -3,19 +3,24 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
 
+// Open opens the database at sqlitePath, a file path or a file: URI that may
+// carry its own query, such as file:app.sqlite?mode=rwc.
 func Open(sqlitePath string) (*sql.DB, error) {
-	dir := filepath.Dir(sqlitePath)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return nil, fmt.Errorf("create sqlite dir: %w", err)
+	if dir := fileDir(sqlitePath); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create sqlite dir: %w", err)
+		}
 	}
 
-	db, err := sql.Open("sqlite", sqlitePath)
+	db, err := sql.Open("sqlite", dataSourceName(sqlitePath))
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
 	}
@@ -25,11 +30,6 @@ func Open(sqlitePath string) (*sql.DB, error) {
 		return nil, fmt.Errorf("set sqlite WAL: %w", err)
 	}
 
-	if _, err := db.Exec(`PRAGMA foreign_keys = ON;`); err != nil {
-		_ = db.Close()
-		return nil, fmt.Errorf("enable sqlite foreign keys: %w", err)
-	}
-
 	if err := db.Ping(); err != nil {
 		_ = db.Close()
 		return nil, fmt.Errorf("ping sqlite: %w", err)
@@ -37,3 +37,50 @@ func Open(sqlitePath string) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// dataSourceName adds the connection settings the app relies on to
+// sqlitePath's query: writers wait for the lock instead of failing with
+// SQLITE_BUSY, a transaction takes the write lock when it begins, as one
+// that read first could otherwise not upgrade once another connection had
+// committed, and foreign keys are enforced. These are per connection, so
+// they go in the DSN for every connection of the pool to pick up. Settings
+// the path already gives are left to it.
+func dataSourceName(sqlitePath string) string {
+	path, rawQuery, _ := strings.Cut(sqlitePath, "?")
+	query, _ := url.ParseQuery(rawQuery)
+
+	params := make([]string, 0, 4)
+	if rawQuery != "" {
+		params = append(params, rawQuery)
+	}
+	if !setsPragma(query["_pragma"], "busy_timeout") {
+		params = append(params, "_pragma=busy_timeout(5000)")
+	}
+	if !setsPragma(query["_pragma"], "foreign_keys") {
+		params = append(params, "_pragma=foreign_keys(1)")
+	}
+	if query.Get("_txlock") == "" {
+		params = append(params, "_txlock=immediate")
+	}
+	return path + "?" + strings.Join(params, "&")
+}
+
+func setsPragma(pragmas []string, name string) bool {
+	for _, pragma := range pragmas {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(pragma)), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileDir is the directory of the database file, or "" for an in-memory
+// database.
+func fileDir(sqlitePath string) string {
+	path, _, _ := strings.Cut(sqlitePath, "?")
+	path = strings.TrimPrefix(path, "file:")
+	if path == "" || path == ":memory:" {
+		return ""
+	}
+	return filepath.Dir(path)
+}
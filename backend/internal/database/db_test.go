@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestDataSourceNameKeepsThePathsQuery(t *testing.T) {
+	cases := map[string]string{
+		"data/app.sqlite":                    "data/app.sqlite?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_txlock=immediate",
+		"file:data/app.sqlite?mode=rwc":      "file:data/app.sqlite?mode=rwc&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_txlock=immediate",
+		"app.sqlite?_txlock=deferred":        "app.sqlite?_txlock=deferred&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)",
+		"app.sqlite?_pragma=busy_timeout(1)": "app.sqlite?_pragma=busy_timeout(1)&_pragma=foreign_keys(1)&_txlock=immediate",
+		"app.sqlite?_pragma=foreign_keys(0)": "app.sqlite?_pragma=foreign_keys(0)&_pragma=busy_timeout(5000)&_txlock=immediate",
+	}
+	for path, want := range cases {
+		if got := dataSourceName(path); got != want {
+			t.Fatalf("dataSourceName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestOpenAcceptsAFileURIWithAQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "app.sqlite")
+	db, err := Open("file:" + path + "?mode=rwc")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+
+	var timeout int
+	if err := db.QueryRow(`PRAGMA busy_timeout`).Scan(&timeout); err != nil || timeout != 5000 {
+		t.Fatalf("expected the busy timeout set, got %d (err %v)", timeout, err)
+	}
+	if _, err := db.Exec(`CREATE TABLE probe (id INTEGER)`); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+func TestOpenEnforcesForeignKeysOnEveryConnection(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "app.sqlite"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(2)
+
+	if _, err := db.Exec(`
+		CREATE TABLE parents (id INTEGER PRIMARY KEY);
+		CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER REFERENCES parents(id) ON DELETE SET NULL);
+		INSERT INTO parents (id) VALUES (1);
+		INSERT INTO children (id, parent_id) VALUES (1, 1);
+	`); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	// Hold the connection Open used so the delete runs on a second one.
+	held, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("hold connection: %v", err)
+	}
+	defer held.Close()
+
+	if _, err := db.Exec(`DELETE FROM parents WHERE id = 1`); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	var parentID *int64
+	if err := held.QueryRowContext(context.Background(), `SELECT parent_id FROM children WHERE id = 1`).Scan(&parentID); err != nil {
+		t.Fatalf("read child: %v", err)
+	}
+	if parentID != nil {
+		t.Fatalf("expected the delete to clear the reference, got %d", *parentID)
+	}
+}
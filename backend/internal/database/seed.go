@@ -2,39 +2,62 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
 )
 
+// defaultSource is a sources row SeedDefaults keeps in place. formerNames
+// lists names the seeder used to give the row, so a row still carrying one
+// is known to be untouched and can be renamed.
+type defaultSource struct {
+	key         string
+	name        string
+	formerNames []string
+	kind        string
+	searchMode  string
+	enabled     bool
+}
+
+var defaultSources = []defaultSource{
+	{key: "mangadex", name: "MangaDex", kind: "native", searchMode: "title", enabled: true},
+	{key: "mangafire", name: "MangaFire", kind: "native", searchMode: "title_or_url", enabled: true},
+	{key: "asuracomic", name: "AsuraComic", kind: "native", searchMode: "title", enabled: true},
+	{key: "flamecomics", name: "FlameComics", kind: "native", searchMode: "title", enabled: true},
+	{key: "mgeko", name: "Mgeko", kind: "native", searchMode: "title", enabled: true},
+	{key: "webtoons", name: "WEBTOON", kind: "native", searchMode: "title", enabled: true},
+	{key: "freewebnovel", name: "FreeWebNovel", kind: "native", searchMode: "title", enabled: true},
+	// sources.connector_kind only allows 'native' and 'yaml'; the manual
+	// connector is built in, so it is recorded as native.
+	{key: "manual", name: "Manual", kind: "native", searchMode: "url_only", enabled: true},
+}
+
+// Actions SeedDefaults reports for each default source.
+const (
+	seedInserted = "inserted"
+	seedUpdated  = "updated"
+	seedSkipped  = "skipped"
+)
+
+// SeedDefaults inserts the default sources, profiles and settings that are
+// missing. It can be run on every start: an existing source only gets the
+// current default name and connector kind when it has not been edited by
+// hand, and its enabled state is never changed.
 func SeedDefaults(db *sql.DB) error {
 	tx, err := db.Begin()
 	if err != nil {
 		return fmt.Errorf("begin seed tx: %w", err)
 	}
 
-	defaultSources := []struct {
-		key     string
-		name    string
-		kind    string
-		enabled bool
-	}{
-		{key: "mangadex", name: "MangaDex", kind: "native", enabled: true},
-		{key: "mangafire", name: "MangaFire", kind: "native", enabled: true},
-		{key: "asuracomic", name: "AsuraComic", kind: "native", enabled: true},
-		{key: "flamecomics", name: "FlameComics", kind: "native", enabled: true},
-		{key: "mgeko", name: "Mgeko", kind: "native", enabled: true},
-		{key: "webtoons", name: "WEBTOON", kind: "native", enabled: true},
-		{key: "freewebnovel", name: "FreeWebNovel", kind: "native", enabled: true},
-	}
-
+	actions := make(map[string][]string, 3)
 	for _, source := range defaultSources {
-		_, err := tx.Exec(`
-			INSERT OR IGNORE INTO sources (key, name, connector_kind, enabled)
-			VALUES (?, ?, ?, ?)
-		`, source.key, source.name, source.kind, source.enabled)
+		action, err := seedSource(tx, source)
 		if err != nil {
 			tx.Rollback()
 			return fmt.Errorf("seed source %s: %w", source.key, err)
 		}
+		actions[action] = append(actions[action], source.key)
 	}
 
 	_, err = tx.Exec(`
@@ -62,5 +85,70 @@ func SeedDefaults(db *sql.DB) error {
 		return fmt.Errorf("commit seed tx: %w", err)
 	}
 
+	slog.Info("seeded default sources", seedInserted, actions[seedInserted], seedUpdated, actions[seedUpdated], seedSkipped, actions[seedSkipped])
+	return nil
+}
+
+// seedSource inserts source when no row has its key, and otherwise brings an
+// untouched row's name and connector kind up to date. A row is untouched
+// while user_modified is unset and its name is one the seeder gave it; a row
+// renamed by hand, through the UI or directly in the database, is skipped.
+func seedSource(tx *sql.Tx, source defaultSource) (string, error) {
+	var name, kind string
+	var userModified bool
+	err := tx.QueryRow(`SELECT name, connector_kind, user_modified FROM sources WHERE key = ?`, source.key).Scan(&name, &kind, &userModified)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := tx.Exec(`
+			INSERT INTO sources (key, name, connector_kind, search_mode, enabled)
+			VALUES (?, ?, ?, ?, ?)
+		`, source.key, source.name, source.kind, source.searchMode, source.enabled); err != nil {
+			return "", err
+		}
+		return seedInserted, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if userModified || (name != source.name && !slices.Contains(source.formerNames, name)) {
+		return seedSkipped, nil
+	}
+	if name == source.name && kind == source.kind {
+		return seedSkipped, nil
+	}
+	if _, err := tx.Exec(`
+		UPDATE sources
+		SET name = ?, connector_kind = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE key = ?
+	`, source.name, source.kind, source.key); err != nil {
+		return "", err
+	}
+	return seedUpdated, nil
+}
+
+// SyncSourceSearchModes stores each connector's declared search mode on its
+// sources row, so the seeded defaults cannot drift from the connectors.
+// Keys without a sources row are ignored.
+func SyncSourceSearchModes(db *sql.DB, modes map[string]string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin search mode sync tx: %w", err)
+	}
+
+	for key, mode := range modes {
+		if _, err := tx.Exec(`
+			UPDATE sources
+			SET search_mode = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE key = ? AND search_mode <> ?
+		`, mode, key, mode); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("sync search mode for source %s: %w", key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit search mode sync tx: %w", err)
+	}
+
 	return nil
 }
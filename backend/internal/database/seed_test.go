@@ -0,0 +1,149 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func setupSeedTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := Open(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, currentFile, _, _ := runtime.Caller(0)
+	if err := ApplyMigrations(db, filepath.Join(filepath.Dir(currentFile), "..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	return db
+}
+
+type seededSource struct {
+	name         string
+	kind         string
+	enabled      bool
+	userModified bool
+}
+
+func seededSources(t *testing.T, db *sql.DB) map[string]seededSource {
+	t.Helper()
+
+	rows, err := db.Query(`SELECT key, name, connector_kind, enabled, user_modified FROM sources`)
+	if err != nil {
+		t.Fatalf("list sources: %v", err)
+	}
+	defer rows.Close()
+
+	sources := make(map[string]seededSource)
+	for rows.Next() {
+		var key string
+		var source seededSource
+		if err := rows.Scan(&key, &source.name, &source.kind, &source.enabled, &source.userModified); err != nil {
+			t.Fatalf("scan source: %v", err)
+		}
+		if _, ok := sources[key]; ok {
+			t.Fatalf("expected one row per key, got %q twice", key)
+		}
+		sources[key] = source
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("iterate sources: %v", err)
+	}
+	return sources
+}
+
+func TestSeedDefaultsKeepsEditedSourcesAcrossRuns(t *testing.T) {
+	db := setupSeedTestDB(t)
+	if err := SeedDefaults(db); err != nil {
+		t.Fatalf("first seed: %v", err)
+	}
+
+	// mangadex is renamed straight in the database, flamecomics through the
+	// UI, mangafire is switched off, asuracomic still has a name the seeder
+	// used to give it and mgeko has gone missing.
+	if _, err := db.Exec(`
+		UPDATE sources SET name = 'My MangaDex' WHERE key = 'mangadex';
+		UPDATE sources SET name = 'Flame Scans', user_modified = 1 WHERE key = 'flamecomics';
+		UPDATE sources SET enabled = 0 WHERE key = 'mangafire';
+		UPDATE sources SET name = 'Asura Scans' WHERE key = 'asuracomic';
+		DELETE FROM sources WHERE key = 'mgeko';
+	`); err != nil {
+		t.Fatalf("edit sources: %v", err)
+	}
+
+	original := defaultSources
+	t.Cleanup(func() { defaultSources = original })
+	defaultSources = append([]defaultSource(nil), original...)
+	for index := range defaultSources {
+		switch defaultSources[index].key {
+		case "asuracomic", "flamecomics":
+			defaultSources[index].formerNames = []string{"Asura Scans", "Flame Scans"}
+		}
+	}
+
+	for run := 0; run < 2; run++ {
+		if err := SeedDefaults(db); err != nil {
+			t.Fatalf("seed run %d: %v", run+1, err)
+		}
+	}
+
+	sources := seededSources(t, db)
+	if len(sources) != len(defaultSources) {
+		t.Fatalf("expected %d sources, got %d: %+v", len(defaultSources), len(sources), sources)
+	}
+	if got := sources["mangadex"].name; got != "My MangaDex" {
+		t.Fatalf("expected a rename made in the database to survive, got %q", got)
+	}
+	if got := sources["flamecomics"].name; got != "Flame Scans" {
+		t.Fatalf("expected a source edited by hand to keep its name, got %q", got)
+	}
+	if sources["mangafire"].enabled {
+		t.Fatal("expected a disabled source to stay disabled")
+	}
+	if got := sources["asuracomic"].name; got != "AsuraComic" {
+		t.Fatalf("expected a former default name to be brought up to date, got %q", got)
+	}
+	if got, ok := sources["mgeko"]; !ok || got.name != "Mgeko" || !got.enabled {
+		t.Fatalf("expected the missing source inserted again, got %+v", got)
+	}
+}
+
+func TestSeedSourceReportsItsAction(t *testing.T) {
+	db := setupSeedTestDB(t)
+	if err := SeedDefaults(db); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE sources SET name = 'Old Mgeko' WHERE key = 'mgeko'`); err != nil {
+		t.Fatalf("rename mgeko: %v", err)
+	}
+
+	cases := []struct {
+		source defaultSource
+		want   string
+	}{
+		{source: defaultSource{key: "brandnew", name: "Brand New", kind: "native", searchMode: "title", enabled: true}, want: seedInserted},
+		{source: defaultSource{key: "mgeko", name: "Mgeko", formerNames: []string{"Old Mgeko"}, kind: "native", searchMode: "title"}, want: seedUpdated},
+		{source: defaultSource{key: "mangadex", name: "MangaDex", kind: "native", searchMode: "title"}, want: seedSkipped},
+	}
+	for _, testCase := range cases {
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("begin: %v", err)
+		}
+		got, err := seedSource(tx, testCase.source)
+		if err != nil {
+			t.Fatalf("seed %s: %v", testCase.source.key, err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+		if got != testCase.want {
+			t.Fatalf("seed %s: expected %s, got %s", testCase.source.key, testCase.want, got)
+		}
+	}
+}
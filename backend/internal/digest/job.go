@@ -0,0 +1,241 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+var ErrNotConfigured = errors.New("email digest is not configured for this profile")
+
+type digestRepository interface {
+	GetByProfileID(profileID int64) (*models.ProfileEmailDigest, error)
+	ListEnabled() ([]models.ProfileEmailDigest, error)
+	ListEntries(profileID int64, since time.Time, until time.Time) ([]repository.DigestEntry, error)
+	ListProfileTagIDs(profileID int64) (map[int64]bool, error)
+	MarkSent(profileID int64, sentAt time.Time) error
+}
+
+type profileRepository interface {
+	GetByID(ctx context.Context, id int64) (*models.Profile, error)
+}
+
+type Job struct {
+	repo          digestRepository
+	profiles      profileRepository
+	sender        Sender
+	checkInterval time.Duration
+	logger        *slog.Logger
+	now           func() time.Time
+	stopCh        chan struct{}
+
+	// missingTagsMu guards missingTags, the deleted filter tags already
+	// logged, so each is reported once rather than on every cycle.
+	missingTagsMu sync.Mutex
+	missingTags   map[int64]bool
+}
+
+type JobConfig struct {
+	// CheckInterval is how often the job looks for digests that are due.
+	// Digests are sent at most once per day, at their configured UTC hour.
+	CheckInterval time.Duration
+}
+
+func NewJob(repo digestRepository, profiles profileRepository, sender Sender, cfg JobConfig, logger *slog.Logger) *Job {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = 5 * time.Minute
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Job{
+		repo:          repo,
+		profiles:      profiles,
+		sender:        sender,
+		checkInterval: cfg.CheckInterval,
+		logger:        logger,
+		now:           time.Now,
+		stopCh:        make(chan struct{}),
+		missingTags:   make(map[int64]bool),
+	}
+}
+
+func (j *Job) Start(ctx context.Context) {
+	j.logger.Info("email digest job started", "interval", j.checkInterval.String())
+	ticker := time.NewTicker(j.checkInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				j.logger.Info("email digest job stopped")
+				close(j.stopCh)
+				return
+			case <-ticker.C:
+				if err := j.RunOnce(ctx); err != nil {
+					j.logger.Warn("email digest cycle failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (j *Job) StopWait(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	select {
+	case <-j.stopCh:
+	case <-time.After(timeout):
+	}
+}
+
+// RunOnce sends every digest that is due. A failed send is logged and left
+// unmarked so the next cycle retries it; it never aborts the other profiles.
+func (j *Job) RunOnce(ctx context.Context) error {
+	now := j.now().UTC()
+	digests, err := j.repo.ListEnabled()
+	if err != nil {
+		return fmt.Errorf("load email digests: %w", err)
+	}
+
+	for _, item := range digests {
+		if !isDue(item, now) {
+			continue
+		}
+
+		since := now.Add(-24 * time.Hour)
+		if item.LastSentAt != nil && item.LastSentAt.After(since) {
+			since = item.LastSentAt.UTC()
+		}
+
+		entries, err := j.listEntries(item, since, now)
+		if err != nil {
+			j.logger.Warn("email digest load entries failed", "profileId", item.ProfileID, "error", err)
+			continue
+		}
+
+		if len(entries) > 0 {
+			if err := j.send(ctx, item, entries, since, now); err != nil {
+				j.logger.Warn("email digest send failed", "profileId", item.ProfileID, "error", err)
+				continue
+			}
+		}
+
+		if err := j.repo.MarkSent(item.ProfileID, now); err != nil {
+			j.logger.Warn("email digest mark sent failed", "profileId", item.ProfileID, "error", err)
+			continue
+		}
+		j.logger.Info("email digest processed", "profileId", item.ProfileID, "entries", len(entries))
+	}
+
+	return nil
+}
+
+// SendTest sends the last 24 hours of updates for a profile immediately,
+// even when there are none, without touching last_sent_at.
+func (j *Job) SendTest(ctx context.Context, profileID int64) (int, error) {
+	item, err := j.repo.GetByProfileID(profileID)
+	if err != nil {
+		return 0, fmt.Errorf("load email digest: %w", err)
+	}
+	if item == nil || strings.TrimSpace(item.Email) == "" {
+		return 0, ErrNotConfigured
+	}
+
+	now := j.now().UTC()
+	since := now.Add(-24 * time.Hour)
+	entries, err := j.listEntries(*item, since, now)
+	if err != nil {
+		return 0, fmt.Errorf("load digest entries: %w", err)
+	}
+
+	if err := j.send(ctx, *item, entries, since, now); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// listEntries returns the digest's entries in (since, until] that pass its
+// tag filters. A filter tag deleted since it was chosen filters nothing.
+func (j *Job) listEntries(item models.ProfileEmailDigest, since time.Time, until time.Time) ([]repository.DigestEntry, error) {
+	entries, err := j.repo.ListEntries(item.ProfileID, since, until)
+	if err != nil || (item.IncludeTagID == nil && item.ExcludeTagID == nil) {
+		return entries, err
+	}
+
+	tagIDs, err := j.repo.ListProfileTagIDs(item.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+	include := j.existingFilterTag(item.ProfileID, item.IncludeTagID, tagIDs)
+	exclude := j.existingFilterTag(item.ProfileID, item.ExcludeTagID, tagIDs)
+
+	filtered := make([]repository.DigestEntry, 0, len(entries))
+	for _, entry := range entries {
+		if include != nil && !slices.Contains(entry.TagIDs, *include) {
+			continue
+		}
+		if exclude != nil && slices.Contains(entry.TagIDs, *exclude) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered, nil
+}
+
+// existingFilterTag returns tagID when the profile still has that tag. A
+// deleted one is logged the first time it is seen and filters nothing.
+func (j *Job) existingFilterTag(profileID int64, tagID *int64, profileTagIDs map[int64]bool) *int64 {
+	if tagID == nil || profileTagIDs[*tagID] {
+		return tagID
+	}
+	j.missingTagsMu.Lock()
+	defer j.missingTagsMu.Unlock()
+	if !j.missingTags[*tagID] {
+		j.missingTags[*tagID] = true
+		j.logger.Warn("email digest filter tag no longer exists, ignoring it", "profileId", profileID, "tagId", *tagID)
+	}
+	return nil
+}
+
+func (j *Job) send(ctx context.Context, item models.ProfileEmailDigest, entries []repository.DigestEntry, since time.Time, until time.Time) error {
+	profileName := fmt.Sprintf("profile %d", item.ProfileID)
+	if profile, err := j.profiles.GetByID(ctx, item.ProfileID); err == nil && profile != nil {
+		profileName = profile.Name
+	}
+
+	msg, err := buildMessage(item.Email, profileName, entries, since, until)
+	if err != nil {
+		return err
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := j.sender.Send(sendCtx, msg); err != nil {
+		return fmt.Errorf("send email digest: %w", err)
+	}
+	return nil
+}
+
+// isDue reports whether today's send slot has passed and the digest has not
+// been sent since.
+func isDue(item models.ProfileEmailDigest, now time.Time) bool {
+	if !item.Enabled || strings.TrimSpace(item.Email) == "" {
+		return false
+	}
+	slot := time.Date(now.Year(), now.Month(), now.Day(), item.HourUTC, 0, 0, 0, time.UTC)
+	if now.Before(slot) {
+		return false
+	}
+	return item.LastSentAt == nil || item.LastSentAt.Before(slot)
+}
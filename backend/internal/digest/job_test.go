@@ -0,0 +1,324 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+type fakeRepo struct {
+	digests []models.ProfileEmailDigest
+	entries []repository.DigestEntry
+	tagIDs  map[int64]bool
+	marked  map[int64]time.Time
+	since   time.Time
+}
+
+func (f *fakeRepo) GetByProfileID(profileID int64) (*models.ProfileEmailDigest, error) {
+	for _, item := range f.digests {
+		if item.ProfileID == profileID {
+			copied := item
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRepo) ListEnabled() ([]models.ProfileEmailDigest, error) {
+	items := make([]models.ProfileEmailDigest, 0, len(f.digests))
+	for _, item := range f.digests {
+		if item.Enabled {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (f *fakeRepo) ListEntries(_ int64, since time.Time, _ time.Time) ([]repository.DigestEntry, error) {
+	f.since = since
+	return f.entries, nil
+}
+
+func (f *fakeRepo) ListProfileTagIDs(int64) (map[int64]bool, error) {
+	return f.tagIDs, nil
+}
+
+func (f *fakeRepo) MarkSent(profileID int64, sentAt time.Time) error {
+	if f.marked == nil {
+		f.marked = make(map[int64]time.Time)
+	}
+	f.marked[profileID] = sentAt
+	for index := range f.digests {
+		if f.digests[index].ProfileID == profileID {
+			sent := sentAt
+			f.digests[index].LastSentAt = &sent
+		}
+	}
+	return nil
+}
+
+type fakeProfiles struct{}
+
+func (fakeProfiles) GetByID(_ context.Context, id int64) (*models.Profile, error) {
+	return &models.Profile{ID: id, Key: "profile1", Name: "Main"}, nil
+}
+
+type fakeSender struct {
+	sent []Message
+	err  error
+}
+
+func (f *fakeSender) Send(_ context.Context, msg Message) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+func newTestJob(repo *fakeRepo, sender *fakeSender, now time.Time) *Job {
+	job := NewJob(repo, fakeProfiles{}, sender, JobConfig{}, nil)
+	job.now = func() time.Time { return now }
+	return job
+}
+
+func sampleEntries(now time.Time) []repository.DigestEntry {
+	lastRead := 10.0
+	latest := 12.0
+	return []repository.DigestEntry{{
+		TrackerID:          1,
+		Title:              "Blue Lock",
+		SourceName:         "MangaDex",
+		SourceURL:          "https://mangadex.org/title/blue-lock",
+		LastReadChapter:    &lastRead,
+		LatestKnownChapter: &latest,
+		LatestReleaseAt:    now.Add(-2 * time.Hour),
+	}}
+}
+
+func TestJobRunOnce_SendsDueDigestAndMarksSent(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 15, 0, 0, time.UTC)
+	repo := &fakeRepo{
+		digests: []models.ProfileEmailDigest{{ProfileID: 1, Email: "reader@example.com", HourUTC: 9, Enabled: true}},
+		entries: sampleEntries(now),
+	}
+	sender := &fakeSender{}
+
+	if err := newTestJob(repo, sender, now).RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(sender.sent))
+	}
+	msg := sender.sent[0]
+	if msg.To != "reader@example.com" {
+		t.Fatalf("expected recipient reader@example.com, got %q", msg.To)
+	}
+	if !strings.Contains(msg.HTMLBody, "Blue Lock") || !strings.Contains(msg.HTMLBody, "Ch. 10 → 12") {
+		t.Fatalf("expected digest body to list series and chapter jump, got %s", msg.HTMLBody)
+	}
+	if !strings.Contains(msg.HTMLBody, "https://mangadex.org/title/blue-lock") {
+		t.Fatalf("expected digest body to link the source")
+	}
+	if sentAt, ok := repo.marked[1]; !ok || !sentAt.Equal(now) {
+		t.Fatalf("expected last_sent_at to be recorded as %s, got %#v", now, repo.marked)
+	}
+}
+
+func TestJobRunOnce_SkipsBeforeHourAndAfterSendingToday(t *testing.T) {
+	now := time.Date(2026, 3, 10, 8, 59, 0, 0, time.UTC)
+	sentToday := time.Date(2026, 3, 10, 9, 1, 0, 0, time.UTC)
+	repo := &fakeRepo{
+		digests: []models.ProfileEmailDigest{
+			{ProfileID: 1, Email: "early@example.com", HourUTC: 9, Enabled: true},
+			{ProfileID: 2, Email: "done@example.com", HourUTC: 7, Enabled: true, LastSentAt: &sentToday},
+			{ProfileID: 3, Email: "off@example.com", HourUTC: 0, Enabled: false},
+		},
+		entries: sampleEntries(now),
+	}
+	sender := &fakeSender{}
+
+	if err := newTestJob(repo, sender, now).RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no emails, got %d", len(sender.sent))
+	}
+	if len(repo.marked) != 0 {
+		t.Fatalf("expected no digests marked sent, got %#v", repo.marked)
+	}
+}
+
+func TestJobRunOnce_RetriesAfterSMTPFailure(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 15, 0, 0, time.UTC)
+	repo := &fakeRepo{
+		digests: []models.ProfileEmailDigest{{ProfileID: 1, Email: "reader@example.com", HourUTC: 9, Enabled: true}},
+		entries: sampleEntries(now),
+	}
+	sender := &fakeSender{err: errors.New("connection refused")}
+	job := newTestJob(repo, sender, now)
+
+	if err := job.RunOnce(context.Background()); err != nil {
+		t.Fatalf("expected smtp failure to be absorbed, got %v", err)
+	}
+	if len(repo.marked) != 0 {
+		t.Fatalf("expected failed digest to stay unsent, got %#v", repo.marked)
+	}
+
+	sender.err = nil
+	job.now = func() time.Time { return now.Add(5 * time.Minute) }
+	if err := job.RunOnce(context.Background()); err != nil {
+		t.Fatalf("retry run failed: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected retry to send 1 email, got %d", len(sender.sent))
+	}
+	if _, ok := repo.marked[1]; !ok {
+		t.Fatalf("expected retry to record last_sent_at")
+	}
+}
+
+func TestJobRunOnce_MarksSentWithoutEmailWhenNothingNew(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 15, 0, 0, time.UTC)
+	lastSent := now.Add(-6 * time.Hour)
+	repo := &fakeRepo{
+		digests: []models.ProfileEmailDigest{{ProfileID: 1, Email: "reader@example.com", HourUTC: 9, Enabled: true, LastSentAt: &lastSent}},
+	}
+	sender := &fakeSender{}
+
+	if err := newTestJob(repo, sender, now).RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no email for an empty window, got %d", len(sender.sent))
+	}
+	if _, ok := repo.marked[1]; !ok {
+		t.Fatalf("expected empty digest to be marked sent")
+	}
+	if !repo.since.Equal(lastSent) {
+		t.Fatalf("expected window to start at last send %s, got %s", lastSent, repo.since)
+	}
+}
+
+func TestJobSendTest_RequiresConfiguredDigest(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 15, 0, 0, time.UTC)
+	job := newTestJob(&fakeRepo{}, &fakeSender{}, now)
+
+	if _, err := job.SendTest(context.Background(), 1); !errors.Is(err, ErrNotConfigured) {
+		t.Fatalf("expected ErrNotConfigured, got %v", err)
+	}
+}
+
+func TestJobSendTest_SendsWithoutMarking(t *testing.T) {
+	now := time.Date(2026, 3, 10, 3, 0, 0, 0, time.UTC)
+	repo := &fakeRepo{
+		digests: []models.ProfileEmailDigest{{ProfileID: 1, Email: "reader@example.com", HourUTC: 9, Enabled: false}},
+	}
+	sender := &fakeSender{}
+
+	count, err := newTestJob(repo, sender, now).SendTest(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("send test failed: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected 0 entries, got %d", count)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected test email to be sent, got %d", len(sender.sent))
+	}
+	if !strings.Contains(sender.sent[0].HTMLBody, "No new chapters") {
+		t.Fatalf("expected empty-state body in test email")
+	}
+	if len(repo.marked) != 0 {
+		t.Fatalf("expected test send not to touch last_sent_at")
+	}
+}
+
+func taggedEntries(now time.Time) []repository.DigestEntry {
+	latest := 12.0
+	return []repository.DigestEntry{
+		{TrackerID: 1, Title: "Notify Blade", SourceName: "MangaDex", SourceURL: "https://mangadex.org/title/notify", LatestKnownChapter: &latest, LatestReleaseAt: now.Add(-time.Hour), TagIDs: []int64{10}},
+		{TrackerID: 2, Title: "Muted Blade", SourceName: "MangaDex", SourceURL: "https://mangadex.org/title/muted", LatestKnownChapter: &latest, LatestReleaseAt: now.Add(-time.Hour), TagIDs: []int64{10, 20}},
+		{TrackerID: 3, Title: "Plain Blade", SourceName: "MangaDex", SourceURL: "https://mangadex.org/title/plain", LatestKnownChapter: &latest, LatestReleaseAt: now.Add(-time.Hour)},
+	}
+}
+
+func TestJobSendTest_FiltersEntriesByTag(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 15, 0, 0, time.UTC)
+	notify, mute := int64(10), int64(20)
+	cases := []struct {
+		name    string
+		include *int64
+		exclude *int64
+		want    []string
+	}{
+		{name: "include", include: &notify, want: []string{"Notify Blade", "Muted Blade"}},
+		{name: "exclude", exclude: &mute, want: []string{"Notify Blade", "Plain Blade"}},
+		{name: "both", include: &notify, exclude: &mute, want: []string{"Notify Blade"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeRepo{
+				digests: []models.ProfileEmailDigest{{ProfileID: 1, Email: "reader@example.com", HourUTC: 9, Enabled: true, IncludeTagID: tc.include, ExcludeTagID: tc.exclude}},
+				entries: taggedEntries(now),
+				tagIDs:  map[int64]bool{notify: true, mute: true},
+			}
+			sender := &fakeSender{}
+
+			count, err := newTestJob(repo, sender, now).SendTest(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("send test failed: %v", err)
+			}
+			if count != len(tc.want) {
+				t.Fatalf("expected %d entries, got %d", len(tc.want), count)
+			}
+			for _, entry := range taggedEntries(now) {
+				want := slices.Contains(tc.want, entry.Title)
+				if strings.Contains(sender.sent[0].HTMLBody, entry.Title) != want {
+					t.Fatalf("expected %s listed: %v, got body %s", entry.Title, want, sender.sent[0].HTMLBody)
+				}
+			}
+		})
+	}
+}
+
+func TestJobRunOnce_IgnoresDeletedFilterTagAndLogsItOnce(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 15, 0, 0, time.UTC)
+	deleted := int64(10)
+	repo := &fakeRepo{
+		digests: []models.ProfileEmailDigest{{ProfileID: 1, Email: "reader@example.com", HourUTC: 9, Enabled: true, IncludeTagID: &deleted}},
+		entries: taggedEntries(now),
+		tagIDs:  map[int64]bool{20: true},
+	}
+	sender := &fakeSender{}
+	var logs bytes.Buffer
+	job := NewJob(repo, fakeProfiles{}, sender, JobConfig{}, slog.New(slog.NewTextHandler(&logs, nil)))
+	job.now = func() time.Time { return now }
+
+	if err := job.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	if _, err := job.SendTest(context.Background(), 1); err != nil {
+		t.Fatalf("send test failed: %v", err)
+	}
+
+	for _, msg := range sender.sent {
+		if !strings.Contains(msg.HTMLBody, "Plain Blade") {
+			t.Fatalf("expected a deleted include tag to filter nothing, got %s", msg.HTMLBody)
+		}
+	}
+	if got := strings.Count(logs.String(), "filter tag no longer exists"); got != 1 {
+		t.Fatalf("expected the deleted tag to be logged once, got %d times: %s", got, logs.String())
+	}
+}
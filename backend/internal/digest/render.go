@@ -0,0 +1,89 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strconv"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+var digestTemplate = template.Must(template.New("digest").Parse(`<!doctype html>
+<html>
+<body style="font-family: sans-serif; color: #1f1f1f;">
+<h2 style="margin-bottom: 4px;">New chapters for {{.ProfileName}}</h2>
+<p style="margin-top: 0; color: #666;">{{.WindowLabel}}</p>
+{{if .Items}}
+<table cellpadding="6" cellspacing="0" style="border-collapse: collapse;">
+{{range .Items}}
+<tr>
+<td><a href="{{.SourceURL}}">{{.Title}}</a></td>
+<td>{{.ChapterJump}}</td>
+<td style="color: #666;">{{.SourceName}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No new chapters in this window.</p>
+{{end}}
+</body>
+</html>
+`))
+
+type digestItemView struct {
+	Title       string
+	SourceURL   string
+	SourceName  string
+	ChapterJump string
+}
+
+type digestView struct {
+	ProfileName string
+	WindowLabel string
+	Items       []digestItemView
+}
+
+func buildMessage(to string, profileName string, entries []repository.DigestEntry, since time.Time, until time.Time) (Message, error) {
+	view := digestView{
+		ProfileName: profileName,
+		WindowLabel: fmt.Sprintf("%s to %s (UTC)", since.UTC().Format("Jan 2 15:04"), until.UTC().Format("Jan 2 15:04")),
+		Items:       make([]digestItemView, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		view.Items = append(view.Items, digestItemView{
+			Title:       entry.Title,
+			SourceURL:   entry.SourceURL,
+			SourceName:  entry.SourceName,
+			ChapterJump: chapterJumpLabel(entry.LastReadChapter, entry.LatestKnownChapter),
+		})
+	}
+
+	var body bytes.Buffer
+	if err := digestTemplate.Execute(&body, view); err != nil {
+		return Message{}, fmt.Errorf("render digest: %w", err)
+	}
+
+	subject := fmt.Sprintf("%d new chapter updates for %s", len(entries), profileName)
+	if len(entries) == 1 {
+		subject = fmt.Sprintf("1 new chapter update for %s", profileName)
+	}
+
+	return Message{To: to, Subject: subject, HTMLBody: body.String()}, nil
+}
+
+func chapterJumpLabel(lastRead *float64, latest *float64) string {
+	switch {
+	case latest == nil:
+		return "New chapter"
+	case lastRead == nil:
+		return "Ch. " + formatChapter(*latest)
+	default:
+		return "Ch. " + formatChapter(*lastRead) + " → " + formatChapter(*latest)
+	}
+}
+
+func formatChapter(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
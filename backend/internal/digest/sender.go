@@ -0,0 +1,129 @@
+package digest
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+)
+
+type Message struct {
+	To       string
+	Subject  string
+	HTMLBody string
+}
+
+// Sender delivers a rendered digest. The SMTP implementation is used in
+// production; tests swap in an in-memory sink.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+func SMTPConfigFrom(cfg config.Config) SMTPConfig {
+	return SMTPConfig{
+		Host:     strings.TrimSpace(cfg.SMTPHost),
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     strings.TrimSpace(cfg.SMTPFrom),
+	}
+}
+
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	if cfg.Port <= 0 {
+		cfg.Port = 587
+	}
+	return &SMTPSender{cfg: cfg}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	address := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := net.Dialer{Timeout: 15 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("dial smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	// Port 465 expects TLS from the first byte; other ports upgrade with
+	// STARTTLS when the server offers it.
+	if s.cfg.Port == 465 {
+		conn = tls.Client(conn, &tls.Config{ServerName: s.cfg.Host})
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("open smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if s.cfg.Port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+				return fmt.Errorf("smtp starttls: %w", err)
+			}
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("smtp rcpt to: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := writer.Write(buildMIMEMessage(s.cfg.From, msg)); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("write smtp message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("finish smtp message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func buildMIMEMessage(from string, msg Message) []byte {
+	var builder strings.Builder
+	builder.WriteString("From: " + from + "\r\n")
+	builder.WriteString("To: " + msg.To + "\r\n")
+	builder.WriteString("Subject: " + sanitizeHeaderValue(msg.Subject) + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(msg.HTMLBody, "\n", "\r\n"))
+	return []byte(builder.String())
+}
+
+func sanitizeHeaderValue(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
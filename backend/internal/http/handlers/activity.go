@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// activityWriteInterval is how often at most the last activity time is
+// written, so browsing the dashboard does not write on every request.
+const activityWriteInterval = time.Minute
+
+// ActivityStore persists when the install was last used;
+// *repository.SettingsRepository implements it.
+type ActivityStore interface {
+	SetLastActivityAt(at time.Time) error
+}
+
+// ActivityRecorder notes dashboard and API requests so the poller can slow
+// down on installs nobody opens. Health checks do not count, since uptime
+// monitors would otherwise keep every install active.
+type ActivityRecorder struct {
+	store       ActivityStore
+	now         func() time.Time
+	mu          sync.Mutex
+	lastWritten time.Time
+}
+
+func NewActivityRecorder(store ActivityStore) *ActivityRecorder {
+	return &ActivityRecorder{store: store, now: time.Now}
+}
+
+func (r *ActivityRecorder) Middleware(c *fiber.Ctx) error {
+	if r != nil && r.store != nil && !isHealthCheckPath(c.Path()) {
+		r.record()
+	}
+	return c.Next()
+}
+
+func (r *ActivityRecorder) record() {
+	r.mu.Lock()
+	now := r.now().UTC()
+	if !r.lastWritten.IsZero() && now.Sub(r.lastWritten) < activityWriteInterval {
+		r.mu.Unlock()
+		return
+	}
+	previous := r.lastWritten
+	r.lastWritten = now
+	r.mu.Unlock()
+
+	if err := r.store.SetLastActivityAt(now); err != nil {
+		slog.Warn("record last activity failed", "error", err)
+		// Try again on the next request rather than a minute later.
+		r.mu.Lock()
+		if r.lastWritten.Equal(now) {
+			r.lastWritten = previous
+		}
+		r.mu.Unlock()
+	}
+}
+
+// isHealthCheckPath matches /health, /v1/health and /v1/connectors/health,
+// under any base path.
+func isHealthCheckPath(path string) bool {
+	return strings.HasSuffix(strings.TrimRight(path, "/"), "/health")
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type fakeActivityStore struct {
+	writes []time.Time
+	err    error
+}
+
+func (s *fakeActivityStore) SetLastActivityAt(at time.Time) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.writes = append(s.writes, at)
+	return nil
+}
+
+func TestActivityRecorderWritesAtMostOnceAMinute(t *testing.T) {
+	store := &fakeActivityStore{}
+	clock := &fakeClock{now: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)}
+	recorder := NewActivityRecorder(store)
+	recorder.now = clock.Now
+
+	app := fiber.New()
+	app.Use(recorder.Middleware)
+	app.Get("/*", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	get := func(path string) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("get %s: expected 200, got %d", path, resp.StatusCode)
+		}
+	}
+
+	get("/dashboard")
+	clock.now = clock.now.Add(30 * time.Second)
+	get("/v1/trackers")
+	if len(store.writes) != 1 {
+		t.Fatalf("expected one write within a minute, got %v", store.writes)
+	}
+
+	clock.now = clock.now.Add(30 * time.Second)
+	get("/dashboard/trackers")
+	if len(store.writes) != 2 || !store.writes[1].Equal(clock.now) {
+		t.Fatalf("expected a second write a minute later, got %v", store.writes)
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	for _, path := range []string{"/health", "/v1/health", "/tracker/v1/connectors/health"} {
+		get(path)
+	}
+	if len(store.writes) != 2 {
+		t.Fatalf("expected health checks not to count as use, got %v", store.writes)
+	}
+
+	store.err = errors.New("database is locked")
+	get("/dashboard")
+	store.err = nil
+	get("/dashboard")
+	if len(store.writes) != 3 {
+		t.Fatalf("expected a failed write to be retried on the next request, got %v", store.writes)
+	}
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	sessionCookieName     = "cst_session"
+	loginCSRFCookieName   = "cst_login_csrf"
+	sessionLifetime       = 30 * 24 * time.Hour
+	authenticatedLocalKey = "dashboardAuthenticated"
+)
+
+// AuthHandler guards the dashboard behind an optional shared password. With
+// no password configured every request passes through unchanged.
+type AuthHandler struct {
+	password  string
+	key       []byte
+	dashboard *DashboardHandler
+	now       func() time.Time
+}
+
+type loginPageData struct {
+	Next      string
+	CSRFToken string
+	// LeaveKiosk asks for the password to take a kiosk browser out of
+	// read-only mode.
+	LeaveKiosk bool
+	Error      string
+}
+
+func NewAuthHandler(password string, secret string, dashboard *DashboardHandler) *AuthHandler {
+	secretBytes := []byte(secret)
+	if strings.TrimSpace(secret) == "" {
+		secretBytes = make([]byte, 32)
+		_, _ = rand.Read(secretBytes)
+	}
+
+	// Deriving the signing key from the password as well means changing the
+	// password signs everyone out.
+	mac := hmac.New(sha256.New, secretBytes)
+	mac.Write([]byte("dashboard-session|" + password))
+
+	return &AuthHandler{
+		password:  password,
+		key:       mac.Sum(nil),
+		dashboard: dashboard,
+		now:       time.Now,
+	}
+}
+
+func (h *AuthHandler) Enabled() bool {
+	return h.password != ""
+}
+
+// RequireSession redirects requests without a valid session to the login
+// page, carrying the requested URL as the return-to parameter. htmx requests
+// get a 401 with HX-Redirect so the whole page navigates instead of swapping
+// the login form into a fragment.
+func (h *AuthHandler) RequireSession(c *fiber.Ctx) error {
+	if !h.Enabled() {
+		return c.Next()
+	}
+	if h.validSession(c.Cookies(sessionCookieName)) {
+		c.Locals(authenticatedLocalKey, true)
+		return c.Next()
+	}
+
+	return h.redirectToLogin(c, false)
+}
+
+// redirectToLogin sends the browser to the login page, to come back to the
+// requested URL. With leaveKiosk the form is shown even to a browser that
+// is already logged in, and logging in takes it out of kiosk mode.
+func (h *AuthHandler) redirectToLogin(c *fiber.Ctx, leaveKiosk bool) error {
+	loginURL := "/login?next=" + url.QueryEscape(c.OriginalURL())
+	if leaveKiosk {
+		loginURL += "&leave_kiosk=1"
+	}
+	loginURL = h.dashboard.appURL(loginURL)
+	if c.Get("HX-Request") == "true" {
+		c.Set("HX-Redirect", loginURL)
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	return c.Redirect(loginURL, fiber.StatusSeeOther)
+}
+
+// RequireAPISession guards JSON endpoints that must not be open to every
+// client on the network once a dashboard password is set: requests without
+// a valid session get a 401 instead of a redirect to the login page.
+func (h *AuthHandler) RequireAPISession(c *fiber.Ctx) error {
+	if !h.Enabled() {
+		return c.Next()
+	}
+	if h.validSession(c.Cookies(sessionCookieName)) {
+		c.Locals(authenticatedLocalKey, true)
+		return c.Next()
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "login required"})
+}
+
+func (h *AuthHandler) LoginPage(c *fiber.Ctx) error {
+	next := h.safeNext(c.Query("next"))
+	leaveKiosk := c.Query("leave_kiosk") == "1"
+	if !h.Enabled() || (!leaveKiosk && h.validSession(c.Cookies(sessionCookieName))) {
+		return c.Redirect(next, fiber.StatusSeeOther)
+	}
+	return h.renderLogin(c, fiber.StatusOK, next, leaveKiosk, "")
+}
+
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	next := h.safeNext(c.FormValue("next"))
+	if !h.Enabled() {
+		return c.Redirect(next, fiber.StatusSeeOther)
+	}
+	leaveKiosk := c.FormValue("leave_kiosk") == "1"
+
+	formToken := c.FormValue("csrf_token")
+	cookieToken := c.Cookies(loginCSRFCookieName)
+	if formToken == "" || cookieToken == "" || !hmac.Equal([]byte(formToken), []byte(cookieToken)) {
+		return h.renderLogin(c, fiber.StatusForbidden, next, leaveKiosk, "The login form expired. Please try again.")
+	}
+
+	if !h.passwordMatches(c.FormValue("password")) {
+		return h.renderLogin(c, fiber.StatusUnauthorized, next, leaveKiosk, "Incorrect password")
+	}
+
+	expiresAt := h.now().Add(sessionLifetime)
+	c.Cookie(h.cookie(c, sessionCookieName, h.signSession(expiresAt), expiresAt))
+	c.Cookie(h.cookie(c, loginCSRFCookieName, "", time.Unix(0, 0)))
+	if leaveKiosk {
+		// Entering the password is what lets a kiosk browser edit again.
+		c.Cookie(h.cookie(c, kioskCookieName, "", time.Unix(0, 0)))
+	}
+	return c.Redirect(next, fiber.StatusSeeOther)
+}
+
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	c.Cookie(h.cookie(c, sessionCookieName, "", time.Unix(0, 0)))
+	if !h.Enabled() {
+		return c.Redirect(h.dashboard.appURL("/dashboard"), fiber.StatusSeeOther)
+	}
+	return c.Redirect(h.dashboard.appURL("/login"), fiber.StatusSeeOther)
+}
+
+func (h *AuthHandler) renderLogin(c *fiber.Ctx, status int, next string, leaveKiosk bool, message string) error {
+	token := make([]byte, 16)
+	_, _ = rand.Read(token)
+	csrfToken := hex.EncodeToString(token)
+	c.Cookie(h.cookie(c, loginCSRFCookieName, csrfToken, h.now().Add(time.Hour)))
+
+	c.Set("Cache-Control", "no-store")
+	c.Status(status)
+	return h.dashboard.render(c, "login_page.html", loginPageData{
+		Next:       next,
+		CSRFToken:  csrfToken,
+		LeaveKiosk: leaveKiosk,
+		Error:      message,
+	})
+}
+
+func (h *AuthHandler) cookie(c *fiber.Ctx, name string, value string, expires time.Time) *fiber.Cookie {
+	return dashboardCookie(c, h.dashboard.basePath, name, value, expires)
+}
+
+// dashboardCookie scopes a cookie to the app's base path.
+func dashboardCookie(c *fiber.Ctx, basePath string, name string, value string, expires time.Time) *fiber.Cookie {
+	path := basePath
+	if path == "" {
+		path = "/"
+	}
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	}
+}
+
+func (h *AuthHandler) passwordMatches(candidate string) bool {
+	want := sha256.Sum256([]byte(h.password))
+	got := sha256.Sum256([]byte(candidate))
+	return hmac.Equal(want[:], got[:])
+}
+
+// signSession returns "<expiry unix seconds>.<hex hmac>".
+func (h *AuthHandler) signSession(expiresAt time.Time) string {
+	payload := strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + h.sign(payload)
+}
+
+func (h *AuthHandler) validSession(value string) bool {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(h.sign(payload))) {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return false
+	}
+	return h.now().Unix() < expiresAt
+}
+
+func (h *AuthHandler) sign(payload string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// safeNext keeps the return-to target on this app; anything else falls back
+// to the dashboard.
+func (h *AuthHandler) safeNext(raw string) string {
+	fallback := h.dashboard.appURL("/dashboard")
+	next := strings.TrimSpace(raw)
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.ContainsAny(next, "\\\r\n") {
+		return fallback
+	}
+	if base := h.dashboard.basePath; base != "" && next != base && !strings.HasPrefix(next, base+"/") && !strings.HasPrefix(next, base+"?") {
+		return fallback
+	}
+	if path, _, _ := strings.Cut(next, "?"); strings.TrimPrefix(path, h.dashboard.basePath) == "/login" {
+		return fallback
+	}
+	return next
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/backup"
+	"github.com/gofiber/fiber/v2"
+)
+
+type BackupsHandler struct {
+	job *backup.Job
+}
+
+// NewBackupsHandler builds the backup API. It writes to the same directory
+// as the scheduled job and shares its lock, so the two never overlap.
+func NewBackupsHandler(db *sql.DB, cfg backup.JobConfig) *BackupsHandler {
+	return &BackupsHandler{job: backup.NewJob(db, cfg, slog.Default())}
+}
+
+func (h *BackupsHandler) Create(c *fiber.Ctx) error {
+	file, err := h.job.RunOnce(c.UserContext())
+	if err != nil {
+		if errors.Is(err, backup.ErrAlreadyRunning) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": err.Error()})
+		}
+		return serverErrorJSON(c, "failed to write backup", err)
+	}
+
+	requestLogger(c).Info("database backup written", "file", file.Name, "bytes", file.SizeBytes)
+	return c.Status(fiber.StatusCreated).JSON(file)
+}
+
+func (h *BackupsHandler) List(c *fiber.Ctx) error {
+	files, err := h.job.List()
+	if err != nil {
+		return serverErrorJSON(c, "failed to list backups", err)
+	}
+	return c.JSON(fiber.Map{"items": files})
+}
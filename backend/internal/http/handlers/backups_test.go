@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+)
+
+func TestBackupAPICreatesAndListsBackups(t *testing.T) {
+	backupDir := filepath.Join(t.TempDir(), "backups")
+	_, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", BackupEnabled: true, BackupDir: backupDir, BackupKeepCount: 5})
+	defer cleanup()
+
+	status, payload := getJSON(t, app, "/v1/admin/backups")
+	if status != http.StatusOK || len(payload["items"].([]any)) != 0 {
+		t.Fatalf("expected no backups yet, got %d %v", status, payload)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodPost, "/v1/admin/backup", nil), -1)
+	if err != nil {
+		t.Fatalf("backup request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", res.StatusCode)
+	}
+	var created map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&created); err != nil {
+		t.Fatalf("decode backup response: %v", err)
+	}
+	name, _ := created["name"].(string)
+	if info, err := os.Stat(filepath.Join(backupDir, name)); err != nil || info.Size() == 0 {
+		t.Fatalf("expected backup file %q on disk, got %v", name, err)
+	}
+
+	status, payload = getJSON(t, app, "/v1/admin/backups")
+	items := payload["items"].([]any)
+	if status != http.StatusOK || len(items) != 1 {
+		t.Fatalf("expected one listed backup, got %d %v", status, payload)
+	}
+	listed := items[0].(map[string]any)
+	if listed["name"] != name || listed["sizeBytes"].(float64) <= 0 {
+		t.Fatalf("expected listed backup with its size, got %v", listed)
+	}
+}
+
+func TestBackupAPIIsOnlyRegisteredWhenBackupsAreEnabled(t *testing.T) {
+	_, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", BackupDir: filepath.Join(t.TempDir(), "backups")})
+	defer cleanup()
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/v1/admin/backup", nil),
+		httptest.NewRequest(http.MethodGet, "/v1/admin/backups", nil),
+	} {
+		res, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("backup request failed: %v", err)
+		}
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected 404 for %s %s with backups disabled, got %d", req.Method, req.URL.Path, res.StatusCode)
+		}
+	}
+}
+
+func TestBackupAPINeedsASessionWhenAPasswordIsSet(t *testing.T) {
+	backupDir := filepath.Join(t.TempDir(), "backups")
+	_, app, cleanup := setupTestAppWithConfig(t, config.Config{
+		AppName:           "test-app",
+		BackupEnabled:     true,
+		BackupDir:         backupDir,
+		BackupKeepCount:   5,
+		DashboardPassword: "hunter2",
+		SessionSecret:     "test-secret",
+	})
+	defer cleanup()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodPost, "/v1/admin/backup", nil), -1)
+	if err != nil {
+		t.Fatalf("backup request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a session, got %d", res.StatusCode)
+	}
+	if entries, _ := os.ReadDir(backupDir); len(entries) != 0 {
+		t.Fatalf("expected no backup to be written, got %d files", len(entries))
+	}
+
+	session := sessionCookie(postLogin(t, app, openLoginForm(t, app), "hunter2", "/dashboard"))
+	if session == nil {
+		t.Fatalf("expected a session cookie after logging in")
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/admin/backup", nil)
+	req.AddCookie(session)
+	res, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("backup request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 with a session, got %d", res.StatusCode)
+	}
+}
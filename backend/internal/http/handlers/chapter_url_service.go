@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+)
+
+// ChapterURLService is the chapter link counterpart of CoverService: it
+// answers card chapter links from the link cache and queues a background
+// lookup for the ones not cached yet.
+type ChapterURLService struct {
+	links        *linkcache.Resolver
+	allowed      func() error
+	isActivePage func(pageKey string) bool
+	mu           *sync.Mutex
+	inFlight     map[string]bool
+	queue        *fetchQueue
+}
+
+type ChapterURLServiceConfig struct {
+	// Workers bounds concurrent chapter URL lookups.
+	Workers int
+	// Allowed, when set, is checked before a lookup is queued or run.
+	Allowed func() error
+	// IsActivePage reports whether lookups queued for a trackers page are
+	// still wanted; when nil every queued lookup runs.
+	IsActivePage func(pageKey string) bool
+}
+
+func NewChapterURLService(links *linkcache.Resolver, cfg ChapterURLServiceConfig) *ChapterURLService {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 10
+	}
+	return newChapterURLService(links, cfg, new(sync.Mutex), make(map[string]bool), newFetchQueue(cfg.Workers))
+}
+
+// newChapterURLService builds a ChapterURLService over lookup state the
+// caller keeps, as DashboardHandler does; mu guards inFlight.
+func newChapterURLService(links *linkcache.Resolver, cfg ChapterURLServiceConfig, mu *sync.Mutex, inFlight map[string]bool, queue *fetchQueue) *ChapterURLService {
+	return &ChapterURLService{
+		links:        links,
+		allowed:      cfg.Allowed,
+		isActivePage: cfg.IsActivePage,
+		mu:           mu,
+		inFlight:     inFlight,
+		queue:        queue,
+	}
+}
+
+// Fetch looks the chapter URL up right away, through the cache. It falls
+// back to the series URL when the chapter cannot be found.
+func (s *ChapterURLService) Fetch(ctx context.Context, sourceKey, sourceURL string, chapter float64) (string, error) {
+	return s.links.ChapterURL(ctx, sourceKey, sourceURL, chapter)
+}
+
+func (s *ChapterURLService) Cached(cacheKey string) (chapterURL string, found bool, ok bool) {
+	return s.links.CachedChapterURL(cacheKey)
+}
+
+func (s *ChapterURLService) SetCached(cacheKey, chapterURL string, found bool, ttl time.Duration) {
+	s.links.SetChapterURL(cacheKey, chapterURL, found, ttl)
+}
+
+// CachedOrQueue returns the cached chapter URL, or the series URL while a
+// lookup is queued, which it reports pending.
+func (s *ChapterURLService) CachedOrQueue(sourceKey, sourceURL string, chapter float64, pageKey string, row int) (string, bool) {
+	trimmedSourceURL := strings.TrimSpace(sourceURL)
+	if trimmedSourceURL == "" {
+		return "", false
+	}
+
+	trimmedSourceKey := strings.TrimSpace(sourceKey)
+	if trimmedSourceKey == "" {
+		return trimmedSourceURL, false
+	}
+
+	cacheKey := linkcache.ChapterURLKey(trimmedSourceKey, trimmedSourceURL, chapter)
+	if cachedChapterURL, found, ok := s.Cached(cacheKey); ok {
+		if found {
+			return cachedChapterURL, false
+		}
+		return trimmedSourceURL, false
+	}
+	if s.allowed != nil && s.allowed() != nil {
+		return trimmedSourceURL, false
+	}
+
+	s.queueResolve(trimmedSourceKey, trimmedSourceURL, chapter, cacheKey, pageKey, row)
+	return trimmedSourceURL, true
+}
+
+// ForPage is the chapter link counterpart of CoverService.ForPage.
+func (s *ChapterURLService) ForPage(requests []ChapterURLRequest) ChapterURLLookup {
+	cacheKeys := make([]string, 0, len(requests))
+	pageKeys := make([]ChapterURLRequest, 0, len(requests))
+	var series ChapterURLRequest
+	prefix := ""
+	for _, request := range requests {
+		if strings.TrimSpace(request.SourceKey) == "" || strings.TrimSpace(request.SourceURL) == "" {
+			continue
+		}
+		// A card asks for up to two chapters of the same series in a row.
+		if prefix == "" || request.SourceKey != series.SourceKey || request.SourceURL != series.SourceURL {
+			series = request
+			prefix = linkcache.ChapterURLKeyPrefix(request.SourceKey, request.SourceURL)
+		}
+		cacheKeys = append(cacheKeys, prefix+strconv.FormatFloat(request.Chapter, 'f', -1, 64))
+		pageKeys = append(pageKeys, request)
+	}
+
+	page := chapterURLPage{service: s, cached: make(map[ChapterURLRequest]linkcache.Link, len(pageKeys))}
+	for index, link := range s.links.CachedChapterURLs(cacheKeys) {
+		if link.Cached {
+			page.cached[pageKeys[index]] = link
+		}
+	}
+	return page
+}
+
+// chapterURLPage is the ChapterURLLookup of one render, from
+// ChapterURLService.ForPage.
+type chapterURLPage struct {
+	service *ChapterURLService
+	cached  map[ChapterURLRequest]linkcache.Link
+}
+
+func (p chapterURLPage) CachedOrQueue(sourceKey, sourceURL string, chapter float64, pageKey string, row int) (string, bool) {
+	if link, ok := p.cached[ChapterURLRequest{SourceKey: sourceKey, SourceURL: sourceURL, Chapter: chapter}]; ok {
+		if link.Found {
+			return link.URL, false
+		}
+		return strings.TrimSpace(sourceURL), false
+	}
+	return p.service.CachedOrQueue(sourceKey, sourceURL, chapter, pageKey, row)
+}
+
+func (s *ChapterURLService) queueResolve(sourceKey, sourceURL string, chapter float64, cacheKey string, pageKey string, row int) {
+	s.mu.Lock()
+	if s.inFlight[cacheKey] {
+		s.mu.Unlock()
+		return
+	}
+	s.inFlight[cacheKey] = true
+	s.mu.Unlock()
+
+	release := func() {
+		s.mu.Lock()
+		delete(s.inFlight, cacheKey)
+		s.mu.Unlock()
+	}
+
+	s.queue.push(pageKey, row, func() {
+		defer release()
+		if pageKey != "" && s.isActivePage != nil && !s.isActivePage(pageKey) {
+			return
+		}
+
+		_, _ = s.Fetch(context.Background(), sourceKey, sourceURL, chapter)
+	}, release)
+}
+
+// DropStalePages drops lookups still queued for a page other than
+// activePageKey.
+func (s *ChapterURLService) DropStalePages(activePageKey string) {
+	s.queue.dropStalePages(activePageKey)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import "testing"
+
+func (s *ChapterURLService) inFlightCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.inFlight)
+}
+
+func TestChapterURLServiceLinksTheSeriesUntilTheChapterResolves(t *testing.T) {
+	links, calls, gate := newGatedLinks(t)
+	chapterURLs := NewChapterURLService(links, ChapterURLServiceConfig{Workers: 1})
+	sourceURL := "https://fakesite.test/series/one"
+
+	if chapterURL, pending := chapterURLs.CachedOrQueue("fakesite", sourceURL, 7, "", 0); chapterURL != sourceURL || !pending {
+		t.Fatalf("expected the series URL while the chapter is queued, got %q pending=%v", chapterURL, pending)
+	}
+	if _, pending := chapterURLs.CachedOrQueue("fakesite", sourceURL, 7, "", 0); !pending {
+		t.Fatalf("expected the chapter to stay pending")
+	}
+	close(gate)
+	waitFor(t, "the chapter lookup", func() bool { return chapterURLs.inFlightCount() == 0 })
+
+	if chapterURL, pending := chapterURLs.CachedOrQueue("fakesite", sourceURL, 7, "", 0); chapterURL != sourceURL+"/chapter" || pending {
+		t.Fatalf("expected the resolved chapter URL from cache, got %q pending=%v", chapterURL, pending)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected one chapter lookup, got %d", got)
+	}
+}
+
+func TestChapterURLServiceQueuesNothingWithoutSource(t *testing.T) {
+	links, calls, gate := newGatedLinks(t)
+	close(gate)
+	chapterURLs := NewChapterURLService(links, ChapterURLServiceConfig{})
+
+	if chapterURL, pending := chapterURLs.CachedOrQueue("", "https://fakesite.test/series/unknown", 3, "", 0); chapterURL != "https://fakesite.test/series/unknown" || pending {
+		t.Fatalf("expected the series URL without a source key, got %q pending=%v", chapterURL, pending)
+	}
+	if chapterURL, pending := chapterURLs.CachedOrQueue("fakesite", " ", 3, "", 0); chapterURL != "" || pending {
+		t.Fatalf("expected no link without a series URL, got %q pending=%v", chapterURL, pending)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected no lookups, got %d", calls.Load())
+	}
+}
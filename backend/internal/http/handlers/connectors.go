@@ -8,20 +8,73 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+// deepHealthTimeout bounds each connector's part of a deep health check: the
+// homepage check plus resolving its canary series.
+const deepHealthTimeout = 20 * time.Second
+
+// sourceNoteLister maps source keys to their status notes; see
+// repository.SourceRepository.
+type sourceNoteLister interface {
+	ListStatusNotes(ctx context.Context) (map[string]string, error)
+}
+
+// pauseState reports the global scraping pause switch; see
+// repository.SettingsRepository.
+type pauseState interface {
+	ScrapingPaused() (bool, error)
+}
+
 type ConnectorsHandler struct {
-	registry *connectors.Registry
+	registry    *connectors.Registry
+	sourceNotes sourceNoteLister
+	pause       pauseState
 }
 
 func NewConnectorsHandler(registry *connectors.Registry) *ConnectorsHandler {
 	return &ConnectorsHandler{registry: registry}
 }
 
+// SetSourceNotes adds each source's status note to the health report.
+func (h *ConnectorsHandler) SetSourceNotes(notes sourceNoteLister) {
+	h.sourceNotes = notes
+}
+
+// SetPauseState makes deep health checks, which resolve a real series on
+// every source, respect the scraping pause switch.
+func (h *ConnectorsHandler) SetPauseState(pause pauseState) {
+	h.pause = pause
+}
+
 func (h *ConnectorsHandler) List(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"items": h.registry.List()})
 }
 
+// Health checks every connector's homepage. With ?deep=1 it also resolves
+// each connector's canary series and reports the invariant that failed.
 func (h *ConnectorsHandler) Health(c *fiber.Ctx) error {
-	ctx, cancel := context.WithTimeout(c.Context(), 3*time.Second)
-	defer cancel()
-	return c.JSON(fiber.Map{"items": h.registry.Health(ctx)})
+	var items []connectors.HealthStatus
+	if c.Query("deep") == "1" {
+		if h.pause != nil {
+			if paused, err := h.pause.ScrapingPaused(); err != nil {
+				return serverErrorJSON(c, "failed to read scraping pause state", err)
+			} else if paused {
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"message": connectors.ErrScrapingPaused.Error()})
+			}
+		}
+		items = h.registry.DeepHealth(c.UserContext(), deepHealthTimeout)
+	} else {
+		ctx, cancel := context.WithTimeout(c.UserContext(), 3*time.Second)
+		defer cancel()
+		items = h.registry.Health(ctx)
+	}
+	if h.sourceNotes != nil {
+		notes, err := h.sourceNotes.ListStatusNotes(c.UserContext())
+		if err != nil {
+			return serverErrorJSON(c, "failed to load source notes", err)
+		}
+		for index := range items {
+			items[index].StatusNote = notes[items[index].Key]
+		}
+	}
+	return c.JSON(fiber.Map{"items": items, "diagnostics": h.registry.Diagnostics()})
 }
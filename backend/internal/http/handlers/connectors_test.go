@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/config"
@@ -32,6 +33,19 @@ func (f *fakeConnector) SearchByTitle(context.Context, string, int) ([]connector
 	return nil, nil
 }
 
+// canaryFakeConnector resolves its canary to a page whose title parsing broke.
+type canaryFakeConnector struct {
+	fakeConnector
+}
+
+func (f *canaryFakeConnector) Canary() connectors.Canary {
+	return connectors.Canary{URL: "https://example.com/series/canary", MinLatestChapter: 10}
+}
+func (f *canaryFakeConnector) ResolveByURL(context.Context, string) (*connectors.MangaResult, error) {
+	latest := 120.0
+	return &connectors.MangaResult{Title: "", LatestChapter: &latest}, nil
+}
+
 func setupAppForConnectors(t *testing.T) (*sql.DB, *fiber.App, func()) {
 	t.Helper()
 
@@ -52,6 +66,7 @@ func setupAppForConnectors(t *testing.T) (*sql.DB, *fiber.App, func()) {
 	registry := connectors.NewRegistry()
 	_ = registry.Register(&fakeConnector{key: "mangadex"})
 	_ = registry.Register(&fakeConnector{key: "mangafire"})
+	_ = registry.Register(&canaryFakeConnector{fakeConnector{key: "webtoons"}})
 
 	app := apihttp.NewServerWithRegistry(config.Config{AppName: "test"}, db, registry)
 	cleanup := func() {
@@ -101,4 +116,58 @@ func TestConnectorsEndpoints(t *testing.T) {
 	if len(healthItems) < 2 {
 		t.Fatalf("expected at least 2 health items, got %d", len(healthItems))
 	}
+	if diagnostics, ok := healthPayload["diagnostics"].([]any); !ok || len(diagnostics) != len(healthItems) {
+		t.Fatalf("expected one load diagnostic per connector, got %v", healthPayload["diagnostics"])
+	}
+}
+
+func TestDeepConnectorHealthReportsFailedCanaryInvariant(t *testing.T) {
+	_, app, cleanup := setupAppForConnectors(t)
+	defer cleanup()
+
+	decode := func(target string) (int, []connectors.HealthStatus) {
+		t.Helper()
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatalf("health request failed: %v", err)
+		}
+		var payload struct {
+			Items []connectors.HealthStatus `json:"items"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&payload)
+		return res.StatusCode, payload.Items
+	}
+
+	_, shallow := decode("/v1/connectors/health")
+	for _, item := range shallow {
+		if !item.Healthy || item.Canary != nil {
+			t.Fatalf("expected the homepage check alone to pass without canaries, got %+v", item)
+		}
+	}
+
+	status, deep := decode("/v1/connectors/health?deep=1")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	for _, item := range deep {
+		switch item.Key {
+		case "webtoons":
+			if item.Healthy || item.Canary == nil || item.Canary.Invariant != connectors.CanaryInvariantTitle {
+				t.Fatalf("expected the broken title to fail the deep check, got %+v", item)
+			}
+		default:
+			if !item.Healthy || item.Canary != nil {
+				t.Fatalf("expected connectors without a canary to stay healthy, got %+v", item)
+			}
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/settings/scraping-paused", strings.NewReader(`{"paused":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := app.Test(req); err != nil {
+		t.Fatalf("pause request failed: %v", err)
+	}
+	if status, _ := decode("/v1/connectors/health?deep=1"); status != http.StatusServiceUnavailable {
+		t.Fatalf("expected deep checks to respect the scraping pause, got %d", status)
+	}
 }
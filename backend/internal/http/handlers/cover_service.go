@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+	"github.com/gabriel/cross-site-tracker/backend/internal/thumbnails"
+)
+
+// thumbnailRetryDelay keeps a cover that could not be thumbnailed (blocked
+// download, unsupported format) from being fetched on every render.
+const thumbnailRetryDelay = 30 * time.Minute
+
+// CoverService fills card covers: it answers from the link cache and queues
+// a background lookup for covers that are not cached yet. With a thumbnail
+// generator set it also queues a thumbnail for every resolved cover.
+type CoverService struct {
+	links          *linkcache.Resolver
+	allowed        func() error
+	isActivePage   func(pageKey string) bool
+	mu             *sync.Mutex
+	inFlight       map[string]bool
+	queue          *fetchQueue
+	mangafireQueue *fetchQueue
+
+	thumbnails        *thumbnails.Generator
+	thumbnailInFlight map[string]bool
+	thumbnailRetryAt  map[string]time.Time
+}
+
+type CoverServiceConfig struct {
+	// Workers bounds concurrent cover lookups and thumbnail downloads.
+	Workers int
+	// MangaFireWorkers bounds MangaFire cover lookups, which get their own
+	// smaller queue so a slow MangaFire page cannot hold up other sources.
+	MangaFireWorkers int
+	// Allowed, when set, is checked before a lookup is queued or run.
+	Allowed func() error
+	// IsActivePage reports whether lookups queued for a trackers page are
+	// still wanted; when nil every queued lookup runs.
+	IsActivePage func(pageKey string) bool
+}
+
+func NewCoverService(links *linkcache.Resolver, cfg CoverServiceConfig) *CoverService {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 8
+	}
+	if cfg.MangaFireWorkers <= 0 {
+		cfg.MangaFireWorkers = 3
+	}
+	return newCoverService(links, cfg, new(sync.Mutex), make(map[string]bool), newFetchQueue(cfg.Workers), newFetchQueue(cfg.MangaFireWorkers))
+}
+
+// newCoverService builds a CoverService over lookup state the caller keeps,
+// as DashboardHandler does; mu guards inFlight.
+func newCoverService(links *linkcache.Resolver, cfg CoverServiceConfig, mu *sync.Mutex, inFlight map[string]bool, queue, mangafireQueue *fetchQueue) *CoverService {
+	return &CoverService{
+		links:             links,
+		allowed:           cfg.Allowed,
+		isActivePage:      cfg.IsActivePage,
+		mu:                mu,
+		inFlight:          inFlight,
+		queue:             queue,
+		mangafireQueue:    mangafireQueue,
+		thumbnailInFlight: make(map[string]bool),
+		thumbnailRetryAt:  make(map[string]time.Time),
+	}
+}
+
+// SetThumbnails sets the generator for stored cover thumbnails; nil turns
+// thumbnails off.
+func (s *CoverService) SetThumbnails(generator *thumbnails.Generator) {
+	s.thumbnails = generator
+}
+
+func (s *CoverService) Thumbnails() *thumbnails.Generator {
+	return s.thumbnails
+}
+
+// Fetch looks the cover up right away, through the cache.
+func (s *CoverService) Fetch(ctx context.Context, sourceKey, sourceURL string, sourceItemID *string) (string, error) {
+	return s.links.Cover(ctx, sourceKey, sourceURL, sourceItemID)
+}
+
+func (s *CoverService) Cached(cacheKey string) (coverURL string, found bool, ok bool) {
+	return s.links.CachedCover(cacheKey)
+}
+
+func (s *CoverService) SetCached(cacheKey, coverURL string, found bool, ttl time.Duration) {
+	s.links.SetCover(cacheKey, coverURL, found, ttl)
+}
+
+// SetResolved caches the cover of a connector result already resolved for
+// sourceURL; see linkcache.Resolver.SetResolvedCover.
+func (s *CoverService) SetResolved(sourceKey, sourceURL string, sourceItemID *string, coverImageURL string) {
+	s.links.SetResolvedCover(sourceKey, sourceURL, sourceItemID, coverImageURL)
+}
+
+// CachedOrQueue returns the cached cover for a card, or queues a lookup and
+// reports it pending. row is the card's position on the page and sets its
+// place in the fetch queue.
+func (s *CoverService) CachedOrQueue(sourceKey, sourceURL string, sourceItemID *string, pageKey string, row int) (string, bool) {
+	trimmedSourceKey := strings.TrimSpace(sourceKey)
+	if trimmedSourceKey == "" {
+		return "", false
+	}
+
+	cacheKey := linkcache.CoverKey(trimmedSourceKey, sourceURL, sourceItemID)
+	if cachedURL, found, ok := s.Cached(cacheKey); ok {
+		if found {
+			return cachedURL, false
+		}
+		return "", false
+	}
+
+	if strings.TrimSpace(sourceURL) == "" {
+		s.SetCached(cacheKey, "", false, 2*time.Minute)
+		return "", false
+	}
+	if s.lookupAllowed() != nil {
+		return "", false
+	}
+
+	s.queueFetch(trimmedSourceKey, sourceURL, sourceItemID, cacheKey, pageKey, row)
+	return "", true
+}
+
+// coverPageKey identifies a CoverRequest without normalizing it.
+type coverPageKey struct {
+	sourceKey    string
+	sourceURL    string
+	sourceItemID string
+	hasItemID    bool
+}
+
+func coverPageKeyOf(sourceKey, sourceURL string, sourceItemID *string) coverPageKey {
+	key := coverPageKey{sourceKey: sourceKey, sourceURL: sourceURL}
+	if sourceItemID != nil {
+		key.sourceItemID, key.hasItemID = *sourceItemID, true
+	}
+	return key
+}
+
+// ForPage reads the cached covers of requests under one lock of the cache.
+// The returned lookup answers those from that read and hands every other
+// cover, and anything still to be fetched, to CachedOrQueue.
+func (s *CoverService) ForPage(requests []CoverRequest) CoverLookup {
+	cacheKeys := make([]string, 0, len(requests))
+	pageKeys := make([]coverPageKey, 0, len(requests))
+	for _, request := range requests {
+		if strings.TrimSpace(request.SourceKey) == "" {
+			continue
+		}
+		cacheKeys = append(cacheKeys, linkcache.CoverKey(request.SourceKey, request.SourceURL, request.SourceItemID))
+		pageKeys = append(pageKeys, coverPageKeyOf(request.SourceKey, request.SourceURL, request.SourceItemID))
+	}
+
+	page := coverPage{service: s, cached: make(map[coverPageKey]linkcache.Link, len(pageKeys))}
+	for index, link := range s.links.CachedCovers(cacheKeys) {
+		if link.Cached {
+			page.cached[pageKeys[index]] = link
+		}
+	}
+	return page
+}
+
+// coverPage is the CoverLookup of one render, from CoverService.ForPage.
+type coverPage struct {
+	service *CoverService
+	cached  map[coverPageKey]linkcache.Link
+}
+
+func (p coverPage) CachedOrQueue(sourceKey, sourceURL string, sourceItemID *string, pageKey string, row int) (string, bool) {
+	if link, ok := p.cached[coverPageKeyOf(sourceKey, sourceURL, sourceItemID)]; ok {
+		if link.Found {
+			return link.URL, false
+		}
+		return "", false
+	}
+	return p.service.CachedOrQueue(sourceKey, sourceURL, sourceItemID, pageKey, row)
+}
+
+func (p coverPage) ThumbnailURL(trackerID int64, sourceKey, sourceURL string, sourceItemID *string, coverURL string, pageKey string, row int) string {
+	return p.service.ThumbnailURL(trackerID, sourceKey, sourceURL, sourceItemID, coverURL, pageKey, row)
+}
+
+func (s *CoverService) queueFetch(sourceKey, sourceURL string, sourceItemID *string, cacheKey string, pageKey string, row int) {
+	s.mu.Lock()
+	if s.inFlight[cacheKey] {
+		s.mu.Unlock()
+		return
+	}
+	s.inFlight[cacheKey] = true
+	s.mu.Unlock()
+
+	release := func() {
+		s.mu.Lock()
+		delete(s.inFlight, cacheKey)
+		s.mu.Unlock()
+	}
+
+	queue := s.queue
+	if strings.EqualFold(strings.TrimSpace(sourceKey), "mangafire") {
+		queue = s.mangafireQueue
+	}
+	queue.push(pageKey, row, func() {
+		defer release()
+		if !s.pageWanted(pageKey) {
+			return
+		}
+
+		_, _ = s.Fetch(context.Background(), sourceKey, sourceURL, sourceItemID)
+	}, release)
+}
+
+// ThumbnailURL returns the thumbnail URL of a card, or "" when none is
+// stored yet; in that case a known cover is queued for thumbnailing.
+func (s *CoverService) ThumbnailURL(trackerID int64, sourceKey, sourceURL string, sourceItemID *string, coverURL string, pageKey string, row int) string {
+	if s.thumbnails == nil || strings.TrimSpace(sourceKey) == "" {
+		return ""
+	}
+
+	key := thumbnails.KeyFor(sourceKey, sourceURL, sourceItemID)
+	updatedAt, ok, err := s.thumbnails.Store().Stat(key)
+	if err != nil {
+		slog.Warn("read cover thumbnail failed", "tracker_id", trackerID, "error", err)
+		return ""
+	}
+	if ok {
+		return "/covers/thumb/" + strconv.FormatInt(trackerID, 10) + "?v=" + strconv.FormatInt(updatedAt.Unix(), 10)
+	}
+
+	if strings.TrimSpace(coverURL) != "" && s.lookupAllowed() == nil {
+		s.queueThumbnail(key, coverURL, pageKey, row)
+	}
+	return ""
+}
+
+func (s *CoverService) queueThumbnail(key thumbnails.Key, coverURL string, pageKey string, row int) {
+	inFlightKey := key.SourceKey + "|" + key.ItemID
+	s.mu.Lock()
+	if s.thumbnailInFlight[inFlightKey] || time.Now().Before(s.thumbnailRetryAt[inFlightKey]) {
+		s.mu.Unlock()
+		return
+	}
+	s.thumbnailInFlight[inFlightKey] = true
+	s.mu.Unlock()
+
+	release := func() {
+		s.mu.Lock()
+		delete(s.thumbnailInFlight, inFlightKey)
+		s.mu.Unlock()
+	}
+
+	s.queue.push(pageKey, row, func() {
+		defer release()
+		if s.lookupAllowed() != nil {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+		if err := s.thumbnails.Generate(ctx, key, coverURL); err != nil {
+			slog.Debug("cover thumbnail failed", "source_key", key.SourceKey, "error", err)
+			s.mu.Lock()
+			s.thumbnailRetryAt[inFlightKey] = time.Now().Add(thumbnailRetryDelay)
+			s.mu.Unlock()
+		}
+	}, release)
+}
+
+// DropStalePages drops lookups still queued for a page other than
+// activePageKey.
+func (s *CoverService) DropStalePages(activePageKey string) {
+	s.queue.dropStalePages(activePageKey)
+	s.mangafireQueue.dropStalePages(activePageKey)
+}
+
+func (s *CoverService) lookupAllowed() error {
+	if s.allowed == nil {
+		return nil
+	}
+	return s.allowed()
+}
+
+func (s *CoverService) pageWanted(pageKey string) bool {
+	return pageKey == "" || s.isActivePage == nil || s.isActivePage(pageKey)
+}
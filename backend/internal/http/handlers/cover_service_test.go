@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+)
+
+// fakeResolver stands in for the connector registry with a fixed set of
+// connectors.
+type fakeResolver map[string]connectors.Connector
+
+func (r fakeResolver) Get(key string) (connectors.Connector, bool) {
+	connector, ok := r[key]
+	return connector, ok
+}
+
+func (fakeResolver) ValidateSourceURL(_ string, rawURL string) (string, error) { return rawURL, nil }
+func (fakeResolver) SearchPageURL(string, string) string                       { return "" }
+func (fakeResolver) LanguageAware(string) bool                                 { return false }
+func (fakeResolver) URLBelongsTo(string, string) bool                          { return false }
+func (fakeResolver) MediaURL(_ string, rawURL string) string                   { return rawURL }
+
+// gatedLinkConnector answers cover and chapter lookups once gate is closed,
+// counting how often it is asked.
+type gatedLinkConnector struct {
+	calls *atomic.Int64
+	gate  chan struct{}
+}
+
+func (gatedLinkConnector) Key() string                       { return "fakesite" }
+func (gatedLinkConnector) Name() string                      { return "Fake Site" }
+func (gatedLinkConnector) Kind() string                      { return connectors.KindNative }
+func (gatedLinkConnector) HealthCheck(context.Context) error { return nil }
+
+func (c gatedLinkConnector) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	c.calls.Add(1)
+	<-c.gate
+	return &connectors.MangaResult{SourceKey: "fakesite", URL: rawURL, CoverImageURL: rawURL + "/cover.jpg"}, nil
+}
+
+func (gatedLinkConnector) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+
+func (c gatedLinkConnector) ResolveChapterURL(_ context.Context, rawURL string, _ float64) (string, error) {
+	c.calls.Add(1)
+	<-c.gate
+	return rawURL + "/chapter", nil
+}
+
+func newGatedLinks(t *testing.T) (*linkcache.Resolver, *atomic.Int64, chan struct{}) {
+	t.Helper()
+	calls := &atomic.Int64{}
+	gate := make(chan struct{})
+	resolver := fakeResolver{"fakesite": gatedLinkConnector{calls: calls, gate: gate}}
+	return linkcache.NewResolver(resolver, nil, nil), calls, gate
+}
+
+func waitFor(t *testing.T, what string, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (s *CoverService) inFlightCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.inFlight)
+}
+
+func TestCoverServiceQueuesEachMissingCoverOnce(t *testing.T) {
+	links, calls, gate := newGatedLinks(t)
+	covers := NewCoverService(links, CoverServiceConfig{Workers: 2})
+	sourceURL := "https://fakesite.test/series/one"
+
+	for range 3 {
+		if coverURL, pending := covers.CachedOrQueue("fakesite", sourceURL, nil, "", 0); coverURL != "" || !pending {
+			t.Fatalf("expected an uncached cover to be queued, got %q pending=%v", coverURL, pending)
+		}
+	}
+	close(gate)
+	waitFor(t, "the cover lookup", func() bool { return covers.inFlightCount() == 0 })
+
+	coverURL, pending := covers.CachedOrQueue("fakesite", sourceURL, nil, "", 0)
+	if coverURL != sourceURL+"/cover.jpg" || pending {
+		t.Fatalf("expected the resolved cover from cache, got %q pending=%v", coverURL, pending)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected repeated renders to share one lookup, got %d", got)
+	}
+}
+
+func TestCoverServiceQueuesNothingWhileLookupsAreNotAllowed(t *testing.T) {
+	links, calls, gate := newGatedLinks(t)
+	close(gate)
+	covers := NewCoverService(links, CoverServiceConfig{Allowed: func() error { return connectors.ErrScrapingPaused }})
+
+	if coverURL, pending := covers.CachedOrQueue("fakesite", "https://fakesite.test/series/paused", nil, "", 0); coverURL != "" || pending {
+		t.Fatalf("expected no cover and nothing pending while paused, got %q pending=%v", coverURL, pending)
+	}
+	if covers.inFlightCount() != 0 || calls.Load() != 0 {
+		t.Fatalf("expected no lookup while paused, got %d in flight and %d calls", covers.inFlightCount(), calls.Load())
+	}
+}
+
+func TestCoverServiceSkipsLookupsForInactivePages(t *testing.T) {
+	links, calls, gate := newGatedLinks(t)
+	close(gate)
+	covers := NewCoverService(links, CoverServiceConfig{
+		IsActivePage: func(pageKey string) bool { return pageKey == "/dashboard/trackers?page=2" },
+	})
+
+	if _, pending := covers.CachedOrQueue("fakesite", "https://fakesite.test/series/old", nil, "/dashboard/trackers?page=1", 0); !pending {
+		t.Fatalf("expected the cover to be queued")
+	}
+	waitFor(t, "the skipped lookup to be released", func() bool { return covers.inFlightCount() == 0 })
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("expected a lookup for a page no longer shown to be skipped, got %d calls", got)
+	}
+}
+
+func TestDashboardHandlerResolvesCoversThroughInjectedResolver(t *testing.T) {
+	db, _ := setupInternalDashboardHandler(t, connectors.NewRegistry())
+	calls := &atomic.Int64{}
+	gate := make(chan struct{})
+	close(gate)
+	h := NewDashboardHandlerWithResolver(db, fakeResolver{"fakesite": gatedLinkConnector{calls: calls, gate: gate}}, "")
+
+	coverURL, err := h.fetchCoverURL(context.Background(), "fakesite", "https://fakesite.test/series/injected", nil)
+	if err != nil || coverURL != "https://fakesite.test/series/injected/cover.jpg" {
+		t.Fatalf("expected the fake connector's cover, got %q %v", coverURL, err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected one lookup against the fake resolver, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/thumbnails"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetCoverThumbnails makes grid cards show stored cover thumbnails and
+// queues a thumbnail for every resolved cover that has none yet. Without it
+// covers are always hotlinked.
+func (h *DashboardHandler) SetCoverThumbnails(generator *thumbnails.Generator) {
+	h.coverService().SetThumbnails(generator)
+}
+
+// CoverThumbnail serves the stored thumbnail of a tracker's cover. The URL
+// carries the thumbnail's version, so responses can be cached for long.
+func (h *DashboardHandler) CoverThumbnail(c *fiber.Ctx) error {
+	generator := h.coverService().Thumbnails()
+	if generator == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	id, err := strconv.ParseInt(c.Params("trackerId"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+
+	tracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
+	if err != nil {
+		return serverError(c, "Failed to load tracker", err)
+	}
+	if tracker == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	source, err := h.sourceRepo.GetByID(c.UserContext(), tracker.SourceID)
+	if err != nil {
+		return serverError(c, "Failed to load source", err)
+	}
+	if source == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	thumbnail, ok, err := generator.Store().Load(thumbnails.KeyFor(source.Key, tracker.SourceURL, tracker.SourceItemID))
+	if err != nil {
+		return serverError(c, "Failed to load thumbnail", err)
+	}
+	if !ok {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	sum := sha256.Sum256(thumbnail.Data)
+	etag := `"` + hex.EncodeToString(sum[:12]) + `"`
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=604800")
+	c.Set(fiber.HeaderLastModified, thumbnail.UpdatedAt.UTC().Format(time.RFC1123))
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, thumbnail.ContentType)
+	return c.Send(thumbnail.Data)
+}
@@ -0,0 +1,127 @@
+package handlers_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/thumbnails"
+)
+
+func fixtureThumbnail(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 24, 36))
+	for y := 0; y < 36; y++ {
+		for x := 0; x < 24; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 10), G: uint8(y * 7), B: 90, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCoverThumbnailRoundTripFromDatabase(t *testing.T) {
+	db, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", CoverThumbnailStorage: "db"})
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_item_id, source_url, status)
+		VALUES (1, 'Thumbed Series', 1, 'thumbed-series', 'https://asuracomic.net/series/thumbed-series', 'reading')
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	id := toString(int(trackerID))
+
+	itemID := "thumbed-series"
+	key := thumbnails.KeyFor("asuracomic", "https://asuracomic.net/series/thumbed-series", &itemID)
+	data := fixtureThumbnail(t)
+	if err := thumbnails.NewDBStore(repository.NewCoverThumbnailRepository(db)).Save(key, data, thumbnails.ContentType); err != nil {
+		t.Fatalf("store thumbnail: %v", err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/covers/thumb/"+id, nil))
+	if err != nil {
+		t.Fatalf("thumbnail request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	if !bytes.Equal(body, data) || res.Header.Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("expected the stored jpeg back, got %d bytes of %q", len(body), res.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(res.Header.Get("Cache-Control"), "max-age=") || res.Header.Get("Last-Modified") == "" {
+		t.Fatalf("expected cache headers, got %v", res.Header)
+	}
+
+	etag := res.Header.Get("ETag")
+	req := httptest.NewRequest(http.MethodGet, "/covers/thumb/"+id, nil)
+	req.Header.Set("If-None-Match", etag)
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("conditional request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected 304 for matching ETag, got %d", res.StatusCode)
+	}
+
+	html := getTrackersPartial(t, app, "/dashboard/trackers?view=grid")
+	if !strings.Contains(html, `src="/covers/thumb/`+id+`?v=`) {
+		t.Fatalf("expected the grid card to use the thumbnail, got %s", html)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/covers/thumb/"+id+"?profile=profile2", nil))
+	if err != nil {
+		t.Fatalf("cross-profile request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for another profile's tracker, got %d", res.StatusCode)
+	}
+}
+
+func TestCoverThumbnailMissingOrDisabled(t *testing.T) {
+	db, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", CoverThumbnailStorage: "db"})
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+		VALUES (1, 'Bare Series', 1, 'https://asuracomic.net/series/bare-series', 'reading')
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+
+	for _, target := range []string{"/covers/thumb/" + toString(int(trackerID)), "/covers/thumb/999"} {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatalf("thumbnail request failed: %v", err)
+		}
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d", target, res.StatusCode)
+		}
+	}
+
+	_, offApp, offCleanup := setupTestApp(t)
+	defer offCleanup()
+	res, err := offApp.Test(httptest.NewRequest(http.MethodGet, "/covers/thumb/1", nil))
+	if err != nil {
+		t.Fatalf("thumbnail request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 with thumbnails off, got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var cardArticleTag = regexp.MustCompile(`<article id="tracker-card-\d+"[^>]*>`)
+
+func postDashboardForm(t *testing.T, app *fiber.App, target string, form url.Values) string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request %s failed: %v", target, err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from %s, got %d (body: %s)", target, res.StatusCode, string(body))
+	}
+	return string(body)
+}
+
+func TestTrackersPageNumbersCardsWithOneTabStop(t *testing.T) {
+	for _, view := range []string{"grid", "list", "wall"} {
+		t.Run(view, func(t *testing.T) {
+			db, app, cleanup := setupTestApp(t)
+			defer cleanup()
+
+			for _, title := range []string{"Alpha Order", "Beta Order", "Gamma Order"} {
+				seedWallTracker(t, db, title, 1, 2)
+			}
+
+			html := getDashboardHTML(t, app, "/dashboard/trackers?profile=profile1&view="+view)
+			if !strings.Contains(html, `id="cards-container-`+view+`" class="cards-`+view+`" role="list" aria-label="Trackers"`) {
+				t.Fatalf("expected the cards container to be a labelled list")
+			}
+			tags := cardArticleTag.FindAllString(html, -1)
+			if len(tags) != 3 {
+				t.Fatalf("expected 3 cards, got %d", len(tags))
+			}
+			for index, tag := range tags {
+				position := strconv.Itoa(index + 1)
+				for _, want := range []string{`role="listitem"`, `aria-posinset="` + position + `"`, `aria-setsize="3"`, `data-card-position="` + position + `"`} {
+					if !strings.Contains(tag, want) {
+						t.Fatalf("card %d: expected %s in %s", index+1, want, tag)
+					}
+				}
+				wantTabIndex := `tabindex="-1"`
+				if index == 0 {
+					wantTabIndex = `tabindex="0"`
+				}
+				if !strings.Contains(tag, wantTabIndex) {
+					t.Fatalf("card %d: expected %s in %s", index+1, wantTabIndex, tag)
+				}
+			}
+		})
+	}
+}
+
+func TestCardMutationsAnnounceTheirOutcome(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	trackerID := seedWallTracker(t, db, "Blue Lock", 269, 270)
+	id := strconv.FormatInt(trackerID, 10)
+	announced := func(html string, message string) {
+		t.Helper()
+		want := `<div hx-swap-oob="innerHTML:#dashboard-announcer"><p>` + message + `</p></div>`
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected the announcement %q, got %s", message, html)
+		}
+	}
+
+	html := postDashboardForm(t, app, "/dashboard/trackers/"+id+"/set-last-read", url.Values{"view_mode": {"grid"}})
+	announced(html, "Tracker Blue Lock marked as read up to chapter 270")
+	// A card swapped in on its own has no place on the page yet.
+	if tag := cardArticleTag.FindString(html); strings.Contains(tag, "aria-posinset") || !strings.Contains(tag, `tabindex="-1"`) {
+		t.Fatalf("expected the replaced card to leave numbering to the page, got %s", tag)
+	}
+
+	announced(postDashboardForm(t, app, "/dashboard/trackers/"+id+"/rating", url.Values{"rating": {"8.5"}, "view_mode": {"grid"}}), "Tracker Blue Lock rated 8.5")
+	announced(postDashboardForm(t, app, "/dashboard/trackers/"+id+"/rating", url.Values{"clear": {"1"}, "view_mode": {"grid"}}), "Rating cleared for tracker Blue Lock")
+	announced(postDashboardForm(t, app, "/dashboard/trackers/"+id+"/delete", url.Values{}), "Tracker Blue Lock deleted")
+}
+
+func TestModalsNameTheirAutofocusTarget(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	trackerID := seedWallTracker(t, db, "Focus Target", 1, 2)
+
+	cases := map[string]string{
+		"/dashboard/trackers/new?profile=profile1":                                           "tracker-title-input",
+		"/dashboard/trackers/" + strconv.FormatInt(trackerID, 10) + "/edit?profile=profile1": "tracker-title-input",
+		"/dashboard/profile/menu?profile=profile1":                                           "profile-menu-profile-select",
+		"/dashboard/sources/trackers?profile=profile1&role=linked":                           "tracker-sources-filter-linked",
+	}
+	for target, focusID := range cases {
+		html := getDashboardHTML(t, app, target)
+		if !strings.Contains(html, `role="dialog" aria-modal="true" aria-labelledby="modal-title" data-autofocus="`+focusID+`"`) {
+			t.Fatalf("%s: expected a dialog focusing %s, got %s", target, focusID, html)
+		}
+		if !strings.Contains(html, `id="`+focusID+`"`) {
+			t.Fatalf("%s: expected the autofocus target %s to be rendered", target, focusID)
+		}
+	}
+}
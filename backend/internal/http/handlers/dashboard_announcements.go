@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+)
+
+// trackerMutation names a change made from the dashboard whose outcome is
+// read out through the page's aria-live region.
+type trackerMutation string
+
+const (
+	mutationCreated         trackerMutation = "created"
+	mutationUpdated         trackerMutation = "updated"
+	mutationDeleted         trackerMutation = "deleted"
+	mutationMarkedRead      trackerMutation = "marked_read"
+	mutationRated           trackerMutation = "rated"
+	mutationRatingCleared   trackerMutation = "rating_cleared"
+	mutationPrimarySwitched trackerMutation = "primary_switched"
+	mutationReleaseLogged   trackerMutation = "release_logged"
+	mutationRereadStarted   trackerMutation = "reread_started"
+)
+
+// announceTrackerMutation words the outcome of a mutation for screen
+// readers. card is the tracker's card after the change; it is nil once the
+// tracker is deleted, and title names it instead. leftPage is set when the
+// card was taken off the page because it no longer matches the filters.
+func announceTrackerMutation(mutation trackerMutation, title string, card *trackerCardView, leftPage bool) string {
+	var view trackerCardView
+	if card != nil {
+		view = *card
+		title = card.Title
+	}
+	title = strings.TrimSpace(title)
+
+	var message string
+	switch mutation {
+	case mutationCreated:
+		message = "Tracker " + title + " added"
+	case mutationUpdated:
+		message = "Tracker " + title + " saved"
+	case mutationDeleted:
+		message = "Tracker " + title + " deleted"
+	case mutationMarkedRead:
+		if chapter := chapterInputValue(view.LastReadChapterRaw); chapter != "" {
+			message = "Tracker " + title + " marked as read up to chapter " + chapter
+		} else {
+			message = "Tracker " + title + " has no known chapter to mark as read"
+		}
+	case mutationRated:
+		message = "Tracker " + title + " rated " + view.RatingLabel
+	case mutationRatingCleared:
+		message = "Rating cleared for tracker " + title
+	case mutationPrimarySwitched:
+		message = "Tracker " + title + " now follows " + view.SourceLogoLabel
+	case mutationReleaseLogged:
+		message = "Chapter " + chapterInputValue(view.LatestKnownChapterRaw) + " logged for tracker " + title
+	case mutationRereadStarted:
+		message = "Re-read #" + strconv.Itoa(view.RereadNumber) + " started for tracker " + title
+	default:
+		return ""
+	}
+	if leftPage {
+		message += "; it no longer matches the current filters"
+	}
+	return message
+}
+
+// announce sets the response's live region text for mutation, once
+// placeUpdatedCard has settled whether the card stays on the page.
+func (r *trackerOOBResponseData) announce(mutation trackerMutation, title string, card *trackerCardView) {
+	leftPage := mutation != mutationDeleted && r.ReplaceCard == nil && r.DeleteTrackerID > 0
+	r.Announcement = announceTrackerMutation(mutation, title, card, leftPage)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import "testing"
+
+func TestAnnounceTrackerMutationWordsEachMutation(t *testing.T) {
+	lastRead := 270.0
+	latest := 271.5
+	card := &trackerCardView{
+		Title:                 "Blue Lock",
+		LastReadChapterRaw:    &lastRead,
+		LatestKnownChapterRaw: &latest,
+		RatingLabel:           "8.5",
+		SourceLogoLabel:       "MangaDex",
+		RereadNumber:          2,
+	}
+
+	cases := []struct {
+		mutation trackerMutation
+		title    string
+		card     *trackerCardView
+		leftPage bool
+		want     string
+	}{
+		{mutationCreated, "Blue Lock", nil, false, "Tracker Blue Lock added"},
+		{mutationUpdated, "", card, false, "Tracker Blue Lock saved"},
+		{mutationDeleted, " Blue Lock ", nil, false, "Tracker Blue Lock deleted"},
+		{mutationMarkedRead, "", card, false, "Tracker Blue Lock marked as read up to chapter 270"},
+		{mutationMarkedRead, "Blue Lock", nil, false, "Tracker Blue Lock has no known chapter to mark as read"},
+		{mutationRated, "", card, false, "Tracker Blue Lock rated 8.5"},
+		{mutationRatingCleared, "", card, false, "Rating cleared for tracker Blue Lock"},
+		{mutationPrimarySwitched, "", card, false, "Tracker Blue Lock now follows MangaDex"},
+		{mutationReleaseLogged, "", card, false, "Chapter 271.5 logged for tracker Blue Lock"},
+		{mutationRereadStarted, "", card, false, "Re-read #2 started for tracker Blue Lock"},
+		{mutationMarkedRead, "", card, true, "Tracker Blue Lock marked as read up to chapter 270; it no longer matches the current filters"},
+		{trackerMutation("unknown"), "", card, false, ""},
+	}
+	for _, tc := range cases {
+		if got := announceTrackerMutation(tc.mutation, tc.title, tc.card, tc.leftPage); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.mutation, tc.want, got)
+		}
+	}
+}
+
+func TestAnnounceNotesCardsThatLeftThePage(t *testing.T) {
+	card := trackerCardView{Title: "Blue Lock", RatingLabel: "9.0"}
+
+	stayed := trackerOOBResponseData{ReplaceCard: &card}
+	stayed.announce(mutationRated, "", &card)
+	if stayed.Announcement != "Tracker Blue Lock rated 9.0" {
+		t.Fatalf("expected a plain announcement, got %q", stayed.Announcement)
+	}
+
+	left := trackerOOBResponseData{DeleteTrackerID: 1}
+	left.announce(mutationRated, "", &card)
+	if left.Announcement != "Tracker Blue Lock rated 9.0; it no longer matches the current filters" {
+		t.Fatalf("expected the announcement to note the card left the page, got %q", left.Announcement)
+	}
+
+	deleted := trackerOOBResponseData{DeleteTrackerID: 1}
+	deleted.announce(mutationDeleted, "Blue Lock", nil)
+	if deleted.Announcement != "Tracker Blue Lock deleted" {
+		t.Fatalf("expected a deletion not to read as leaving the filters, got %q", deleted.Announcement)
+	}
+}
+
+func TestNumberTrackerCardsGivesOneTabStop(t *testing.T) {
+	cards := make([]trackerCardView, 3)
+	numberTrackerCards(cards)
+	for index, card := range cards {
+		if card.Position != index+1 || card.SetSize != 3 {
+			t.Fatalf("card %d: expected position %d of 3, got %d of %d", index, index+1, card.Position, card.SetSize)
+		}
+		wantTabIndex := -1
+		if index == 0 {
+			wantTabIndex = 0
+		}
+		if card.TabIndex != wantTabIndex {
+			t.Fatalf("card %d: expected tabindex %d, got %d", index, wantTabIndex, card.TabIndex)
+		}
+	}
+}
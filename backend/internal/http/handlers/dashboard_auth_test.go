@@ -0,0 +1,227 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+var csrfTokenPattern = regexp.MustCompile(`name="csrf_token" value="([0-9a-f]+)"`)
+
+type loginForm struct {
+	csrfCookie *http.Cookie
+	csrfToken  string
+}
+
+func openLoginForm(t *testing.T, app *fiber.App) loginForm {
+	t.Helper()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/login", nil), -1)
+	if err != nil {
+		t.Fatalf("login page request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected login page 200, got %d", res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+	match := csrfTokenPattern.FindStringSubmatch(string(body))
+	if match == nil {
+		t.Fatalf("expected csrf token in login form, got %s", body)
+	}
+
+	form := loginForm{csrfToken: match[1]}
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "cst_login_csrf" {
+			form.csrfCookie = cookie
+		}
+	}
+	if form.csrfCookie == nil || form.csrfCookie.Value != form.csrfToken {
+		t.Fatalf("expected csrf cookie matching the form token")
+	}
+	return form
+}
+
+func postLogin(t *testing.T, app *fiber.App, form loginForm, password string, next string) *http.Response {
+	t.Helper()
+
+	values := url.Values{}
+	values.Set("csrf_token", form.csrfToken)
+	values.Set("password", password)
+	values.Set("next", next)
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if form.csrfCookie != nil {
+		req.AddCookie(form.csrfCookie)
+	}
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("login request failed: %v", err)
+	}
+	return res
+}
+
+func sessionCookie(res *http.Response) *http.Cookie {
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "cst_session" && cookie.Value != "" {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestDashboardWithoutPasswordStaysOpen(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard", nil), -1)
+	if err != nil {
+		t.Fatalf("dashboard request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected open dashboard 200, got %d", res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if strings.Contains(string(body), "Log out") {
+		t.Fatalf("expected no logout button without a password")
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/login", nil), -1)
+	if err != nil {
+		t.Fatalf("login page request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusSeeOther || res.Header.Get("Location") != "/dashboard" {
+		t.Fatalf("expected login page to redirect to dashboard, got %d %q", res.StatusCode, res.Header.Get("Location"))
+	}
+}
+
+func TestDashboardPasswordRedirectsUnauthenticatedRequests(t *testing.T) {
+	_, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", DashboardPassword: "hunter2"})
+	defer cleanup()
+
+	for _, path := range []string{"/", "/dashboard", "/dashboard/trackers?status=reading"} {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("request %s failed: %v", path, err)
+		}
+		want := "/login?next=" + url.QueryEscape(path)
+		if res.StatusCode != http.StatusSeeOther || res.Header.Get("Location") != want {
+			t.Fatalf("expected %s to redirect to %q, got %d %q", path, want, res.StatusCode, res.Header.Get("Location"))
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard/trackers", nil)
+	req.Header.Set("HX-Request", "true")
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("htmx request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusUnauthorized || !strings.HasPrefix(res.Header.Get("HX-Redirect"), "/login?next=") {
+		t.Fatalf("expected htmx request to get 401 with HX-Redirect, got %d %q", res.StatusCode, res.Header.Get("HX-Redirect"))
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers", nil), -1)
+	if err != nil {
+		t.Fatalf("api request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected JSON API to be unaffected by dashboard login, got %d", res.StatusCode)
+	}
+}
+
+func TestDashboardLoginFailure(t *testing.T) {
+	_, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", DashboardPassword: "hunter2"})
+	defer cleanup()
+
+	res := postLogin(t, app, openLoginForm(t, app), "wrong", "/dashboard")
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for wrong password, got %d", res.StatusCode)
+	}
+	if sessionCookie(res) != nil {
+		t.Fatalf("expected no session cookie for wrong password")
+	}
+	body, _ := io.ReadAll(res.Body)
+	if !strings.Contains(string(body), "Incorrect password") {
+		t.Fatalf("expected incorrect password message, got %s", body)
+	}
+
+	form := openLoginForm(t, app)
+	form.csrfCookie = nil
+	res = postLogin(t, app, form, "hunter2", "/dashboard")
+	if res.StatusCode != http.StatusForbidden || sessionCookie(res) != nil {
+		t.Fatalf("expected 403 without csrf cookie, got %d", res.StatusCode)
+	}
+}
+
+func TestDashboardLoginSuccessAndLogout(t *testing.T) {
+	_, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", DashboardPassword: "hunter2", SessionSecret: "test-secret"})
+	defer cleanup()
+
+	res := postLogin(t, app, openLoginForm(t, app), "hunter2", "/dashboard?profile=profile2")
+	if res.StatusCode != http.StatusSeeOther || res.Header.Get("Location") != "/dashboard?profile=profile2" {
+		t.Fatalf("expected redirect to return-to target, got %d %q", res.StatusCode, res.Header.Get("Location"))
+	}
+	session := sessionCookie(res)
+	if session == nil {
+		t.Fatalf("expected session cookie")
+	}
+	if !session.HttpOnly || session.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("expected HttpOnly SameSite=Lax session cookie, got %+v", session)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+	req.AddCookie(session)
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("dashboard request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected dashboard 200 with session, got %d", res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if !strings.Contains(string(body), "Log out") {
+		t.Fatalf("expected logout button when signed in")
+	}
+
+	tampered := *session
+	tampered.Value = "9999999999." + strings.Repeat("0", 64)
+	req = httptest.NewRequest(http.MethodGet, "/dashboard", nil)
+	req.AddCookie(&tampered)
+	res, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("tampered dashboard request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected forged session to be rejected, got %d", res.StatusCode)
+	}
+
+	res = postLogin(t, app, openLoginForm(t, app), "hunter2", "//evil.example/phish")
+	if res.Header.Get("Location") != "/dashboard" {
+		t.Fatalf("expected off-site return-to to fall back to dashboard, got %q", res.Header.Get("Location"))
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.AddCookie(session)
+	res, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("logout request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusSeeOther || res.Header.Get("Location") != "/login" {
+		t.Fatalf("expected logout to redirect to login, got %d %q", res.StatusCode, res.Header.Get("Location"))
+	}
+	cleared := false
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "cst_session" && cookie.Value == "" {
+			cleared = true
+		}
+	}
+	if !cleared {
+		t.Fatalf("expected logout to clear the session cookie")
+	}
+}
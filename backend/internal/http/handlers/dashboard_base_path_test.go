@@ -0,0 +1,142 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func setupBasePathTestApp(t *testing.T) (*fiber.App, func()) {
+	t.Helper()
+
+	db, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", BasePath: "/manga"})
+
+	_, err := db.Exec(`
+		INSERT INTO custom_tags (profile_id, name, icon_key)
+		VALUES (?, ?, ?)
+	`, 1, "favorite", "icon_1")
+	if err != nil {
+		cleanup()
+		t.Fatalf("seed custom tag: %v", err)
+	}
+
+	return app, cleanup
+}
+
+func TestDashboardPageUsesBasePathForAssetsAndEndpoints(t *testing.T) {
+	app, cleanup := setupBasePathTestApp(t)
+	defer cleanup()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/manga/dashboard", nil))
+	if err != nil {
+		t.Fatalf("dashboard request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read dashboard body: %v", err)
+	}
+	html := string(body)
+
+	expected := []string{
+		`data-base-path="/manga"`,
+		`href="/manga/assets/dashboard.css"`,
+		`src="/manga/assets/dashboard-core.js"`,
+		`hx-get="/manga/dashboard/trackers"`,
+		`hx-get="/manga/dashboard/profile/menu"`,
+	}
+	for _, fragment := range expected {
+		if !strings.Contains(html, fragment) {
+			t.Fatalf("expected dashboard html to contain %q", fragment)
+		}
+	}
+	if strings.Contains(html, `href="/assets/`) || strings.Contains(html, `hx-get="/dashboard/`) {
+		t.Fatalf("expected no root-relative dashboard urls without base path")
+	}
+}
+
+func TestStaticAssetsAreServedUnderBasePath(t *testing.T) {
+	app, cleanup := setupBasePathTestApp(t)
+	defer cleanup()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/manga/assets/dashboard.css", nil))
+	if err != nil {
+		t.Fatalf("asset request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+	if err != nil {
+		t.Fatalf("unprefixed dashboard request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unprefixed route, got %d", res.StatusCode)
+	}
+}
+
+func TestProfileRedirectsIncludeBasePath(t *testing.T) {
+	app, cleanup := setupBasePathTestApp(t)
+	defer cleanup()
+
+	switchReq := httptest.NewRequest(http.MethodPost, "/manga/dashboard/profile/switch", strings.NewReader("profile=profile2"))
+	switchReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(switchReq)
+	if err != nil {
+		t.Fatalf("switch profile request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", res.StatusCode)
+	}
+	if location := res.Header.Get("Location"); location != "/manga/dashboard?profile=profile2" {
+		t.Fatalf("expected redirect under base path, got %q", location)
+	}
+
+	renameReq := httptest.NewRequest(http.MethodPost, "/manga/dashboard/profile/rename?profile=profile1", strings.NewReader("profile_name=Main"))
+	renameReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err = app.Test(renameReq)
+	if err != nil {
+		t.Fatalf("rename profile request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", res.StatusCode)
+	}
+	if location := res.Header.Get("Location"); location != "/manga/dashboard?profile=profile1" {
+		t.Fatalf("expected redirect under base path, got %q", location)
+	}
+}
+
+func TestProfileMenuTagIconsUseBasePath(t *testing.T) {
+	app, cleanup := setupBasePathTestApp(t)
+	defer cleanup()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/manga/dashboard/profile/menu?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("profile menu request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read profile menu body: %v", err)
+	}
+	html := string(body)
+
+	if !strings.Contains(html, `src="/manga/assets/tag-icons/icon-star-gold.svg"`) {
+		t.Fatalf("expected tag icon asset path under base path")
+	}
+	if !strings.Contains(html, `hx-post="/manga/dashboard/profile/tags?profile=profile1"`) {
+		t.Fatalf("expected tag endpoint under base path")
+	}
+}
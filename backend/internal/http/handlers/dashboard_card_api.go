@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CardJSON serves the computed card view of a single tracker. Chapter URLs
+// and covers come from the same caches as the HTML cards; unresolved values
+// are queued for background resolution and flagged as pending.
+func (h *DashboardHandler) CardJSON(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageErrorJSON(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid tracker id"})
+	}
+
+	tracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load tracker", err)
+	}
+	if tracker == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
+	}
+
+	sourceByID, err := pageCtx.SourceByID()
+	if err != nil {
+		return sendPageErrorJSON(c, err)
+	}
+
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
+	if err != nil {
+		return sendPageErrorJSON(c, err)
+	}
+
+	cards, _ := h.buildTrackerCards(c.UserContext(), []models.Tracker{*tracker}, sourceByID, sourceLogoBySourceID, "")
+	if len(cards) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker card not found"})
+	}
+
+	body, err := json.Marshal(cards[0])
+	if err != nil {
+		return serverErrorJSON(c, "failed to encode tracker card", err)
+	}
+
+	// The card embeds updatedAt and every cache-derived field, so hashing it
+	// changes the tag whenever the tracker or its resolved URLs change.
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(body)
+}
+
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
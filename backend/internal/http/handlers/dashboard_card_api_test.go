@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCardJSONServesCardDataWithETag(t *testing.T) {
+	registry := connectors.NewRegistry()
+	if err := registry.Register(chapterReportingConnectorStub{key: "mgeko", name: "Mgeko", chapter: 12}); err != nil {
+		t.Fatalf("register mgeko stub: %v", err)
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	app := fiber.New()
+	app.Get("/v1/trackers/:id/card", h.CardJSON)
+
+	sourceURL := "https://www.mgeko.cc/manga/card-series/"
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Card Series', (SELECT id FROM sources WHERE key = 'mgeko'), ?, 'reading', 10, 12)
+	`, sourceURL)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	path := "/v1/trackers/" + strconv.FormatInt(trackerID, 10) + "/card"
+
+	h.setCachedChapterURL(linkcache.ChapterURLKey("mgeko", sourceURL, 12), "https://www.mgeko.cc/reader/en/card-series-chapter-12/", true, time.Hour)
+	h.setCachedChapterURL(linkcache.ChapterURLKey("mgeko", sourceURL, 10), "https://www.mgeko.cc/reader/en/card-series-chapter-10/", true, time.Hour)
+	h.setCachedCover(linkcache.CoverKey("mgeko", sourceURL, nil), "https://cdn.example.test/card-series.jpg", true, time.Hour)
+
+	get := func(ifNoneMatch string) (int, string, map[string]any) {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+		}
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("request card: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		var payload map[string]any
+		if resp.StatusCode == fiber.StatusOK {
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("decode card: %v (%s)", err, body)
+			}
+		}
+		return resp.StatusCode, resp.Header.Get(fiber.HeaderETag), payload
+	}
+
+	status, etag, card := get("")
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if etag == "" {
+		t.Fatalf("expected ETag header")
+	}
+	if card["latestKnownChapterUrl"] != "https://www.mgeko.cc/reader/en/card-series-chapter-12/" || card["latestKnownChapterUrlPending"] != false {
+		t.Fatalf("expected cached latest chapter url, got %v (pending %v)", card["latestKnownChapterUrl"], card["latestKnownChapterUrlPending"])
+	}
+	if card["lastReadChapterUrl"] != "https://www.mgeko.cc/reader/en/card-series-chapter-10/" {
+		t.Fatalf("expected cached last read chapter url, got %v", card["lastReadChapterUrl"])
+	}
+	if card["coverUrl"] != "https://cdn.example.test/card-series.jpg" || card["coverPending"] != false {
+		t.Fatalf("expected cached cover url, got %v", card["coverUrl"])
+	}
+	if card["unreadChapters"] != float64(2) {
+		t.Fatalf("expected 2 unread chapters, got %v", card["unreadChapters"])
+	}
+	if value, ok := card["rating"]; !ok || value != nil {
+		t.Fatalf("expected null rating, got %v", value)
+	}
+	if _, ok := card["updatedAt"].(string); !ok {
+		t.Fatalf("expected raw updatedAt timestamp, got %v", card["updatedAt"])
+	}
+
+	if status, _, _ := get(etag); status != fiber.StatusNotModified {
+		t.Fatalf("expected 304 for matching If-None-Match, got %d", status)
+	}
+
+	if _, err := db.Exec(`UPDATE trackers SET last_read_chapter = 11, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, trackerID); err != nil {
+		t.Fatalf("update tracker: %v", err)
+	}
+	status, newETag, card := get(etag)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200 after tracker change, got %d", status)
+	}
+	if newETag == etag {
+		t.Fatalf("expected ETag to change after tracker change")
+	}
+	if card["lastReadChapterUrlPending"] != true || card["lastReadChapterUrl"] != sourceURL {
+		t.Fatalf("expected pending last read chapter url falling back to source url, got %v (pending %v)", card["lastReadChapterUrl"], card["lastReadChapterUrlPending"])
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/v1/trackers/999999/card", nil), -1)
+	if err != nil {
+		t.Fatalf("request missing card: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for unknown tracker, got %d", resp.StatusCode)
+	}
+}
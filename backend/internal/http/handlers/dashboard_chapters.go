@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+const chapterBrowserLimit = 500
+
+type trackerChaptersData struct {
+	Tracker     *models.Tracker
+	ViewMode    string
+	SourceName  string
+	Chapters    []chapterRowView
+	Unsupported bool
+	Error       string
+	// AutofocusID is the id of the element focused once the modal opens:
+	// the chapter list when there is one, the close button otherwise.
+	AutofocusID string
+}
+
+type chapterRowView struct {
+	Number        string
+	Label         string
+	URL           string
+	ReleasedLabel string
+	IsLastRead    bool
+}
+
+// ChaptersModal lists the primary source's chapters for a tracker, newest
+// first. Sources whose connector does not implement connectors.ChapterLister
+// render an explanatory message instead of an error.
+func (h *DashboardHandler) ChaptersModal(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+	viewMode := normalizeViewMode(c.Query("view", "grid"))
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+
+	tracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
+	if err != nil {
+		return serverError(c, "Failed to load tracker", err)
+	}
+	if tracker == nil {
+		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
+	}
+
+	source, err := h.sourceRepo.GetByID(c.UserContext(), tracker.SourceID)
+	if err != nil {
+		return serverError(c, "Failed to load source", err)
+	}
+
+	data := trackerChaptersData{
+		Tracker:     tracker,
+		ViewMode:    viewMode,
+		AutofocusID: "tracker-chapters-close",
+	}
+	if source == nil {
+		data.Unsupported = true
+		return h.render(c, "tracker_chapters_modal.html", data)
+	}
+	data.SourceName = source.Name
+
+	connector, ok := h.registry.Get(source.Key)
+	if !ok || !source.Enabled {
+		data.Unsupported = true
+		return h.render(c, "tracker_chapters_modal.html", data)
+	}
+	lister, ok := connector.(connectors.ChapterLister)
+	if !ok {
+		data.Unsupported = true
+		return h.render(c, "tracker_chapters_modal.html", data)
+	}
+
+	if h.scrapingAllowed() != nil {
+		data.Error = "Updates are paused, so chapters cannot be loaded from " + source.Name
+		return h.render(c, "tracker_chapters_modal.html", data)
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 15*time.Second)
+	defer cancel()
+
+	chapters, err := lister.ListChapters(ctx, tracker.SourceURL, chapterBrowserLimit)
+	if err != nil {
+		data.Error = "Could not load chapters from " + source.Name
+		return h.render(c, "tracker_chapters_modal.html", data)
+	}
+
+	for index := range chapters {
+		chapters[index].URL = h.registry.MediaURL(source.Key, chapters[index].URL)
+	}
+	data.Chapters = buildChapterRows(chapters, tracker.LastReadChapter)
+	if len(data.Chapters) > 0 {
+		data.AutofocusID = "tracker-chapters-list"
+	}
+	return h.render(c, "tracker_chapters_modal.html", data)
+}
+
+func buildChapterRows(chapters []connectors.ChapterInfo, lastRead *float64) []chapterRowView {
+	rows := make([]chapterRowView, 0, len(chapters))
+	for _, chapter := range chapters {
+		label := formatChapterLabel(chapter.Number)
+		if title := strings.TrimSpace(chapter.Title); title != "" && !strings.EqualFold(title, "Chapter "+chapterInputValue(&chapter.Number)) {
+			label += " — " + title
+		}
+
+		row := chapterRowView{
+			Number:        strconv.FormatFloat(chapter.Number, 'f', -1, 64),
+			Label:         label,
+			URL:           chapter.URL,
+			ReleasedLabel: "—",
+			IsLastRead:    lastRead != nil && math.Abs(*lastRead-chapter.Number) <= 1e-9,
+		}
+		if chapter.ReleasedAt != nil {
+			row.ReleasedLabel = chapter.ReleasedAt.UTC().Format("2006-01-02")
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
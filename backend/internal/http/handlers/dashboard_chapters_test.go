@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gofiber/fiber/v2"
+)
+
+type chapterListerStub struct {
+	chapterReportingConnectorStub
+	chapters []connectors.ChapterInfo
+}
+
+func (s chapterListerStub) ListChapters(_ context.Context, _ string, limit int) ([]connectors.ChapterInfo, error) {
+	return connectors.NewestChaptersFirst(s.chapters, limit), nil
+}
+
+func TestChaptersModalListsChaptersAndSetsLastRead(t *testing.T) {
+	releasedAt := time.Date(2026, 2, 16, 15, 49, 0, 0, time.UTC)
+	registry := connectors.NewRegistry()
+	if err := registry.Register(chapterListerStub{
+		chapterReportingConnectorStub: chapterReportingConnectorStub{key: "mgeko", name: "Mgeko", chapter: 12},
+		chapters: []connectors.ChapterInfo{
+			{Number: 10, URL: "https://www.mgeko.cc/reader/en/series-chapter-10/"},
+			{Number: 12, Title: "The Return", URL: "https://www.mgeko.cc/reader/en/series-chapter-12/", ReleasedAt: &releasedAt},
+			{Number: 11, URL: "https://www.mgeko.cc/reader/en/series-chapter-11/"},
+		},
+	}); err != nil {
+		t.Fatalf("register mgeko stub: %v", err)
+	}
+	if err := registry.Register(chapterReportingConnectorStub{key: "mangadex", name: "MangaDex", chapter: 12}); err != nil {
+		t.Fatalf("register mangadex stub: %v", err)
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	app := fiber.New()
+	app.Get("/dashboard/trackers/:id/chapters", h.ChaptersModal)
+	app.Post("/dashboard/trackers/:id/set-last-read", h.SetLastReadFromCard)
+
+	insertTracker := func(sourceKey string, sourceURL string) int64 {
+		t.Helper()
+		result, err := db.Exec(`
+			INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+			VALUES (1, 'Chapter Series', (SELECT id FROM sources WHERE key = ?), ?, 'reading', 10, 12)
+		`, sourceKey, sourceURL)
+		if err != nil {
+			t.Fatalf("insert %s tracker: %v", sourceKey, err)
+		}
+		id, _ := result.LastInsertId()
+		return id
+	}
+	getBody := func(path string) string {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("request %s: %v", path, err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("expected 200 from %s, got %d", path, resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	listedID := insertTracker("mgeko", "https://www.mgeko.cc/manga/series/")
+	body := getBody("/dashboard/trackers/" + strconv.FormatInt(listedID, 10) + "/chapters")
+
+	first := strings.Index(body, "Ch. 12 — The Return")
+	second := strings.Index(body, "Ch. 11")
+	third := strings.Index(body, "Ch. 10")
+	if first < 0 || second < 0 || third < 0 || !(first < second && second < third) {
+		t.Fatalf("expected chapters newest first, got %s", body)
+	}
+	if !strings.Contains(body, "2026-02-16") {
+		t.Fatalf("expected release date in chapter list")
+	}
+	if !strings.Contains(body, "Last read") {
+		t.Fatalf("expected current last read chapter to be marked")
+	}
+
+	form := url.Values{}
+	form.Set("chapter", "11")
+	req := httptest.NewRequest(fiber.MethodPost, "/dashboard/trackers/"+strconv.FormatInt(listedID, 10)+"/set-last-read", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("set last read request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200 from set-last-read, got %d", resp.StatusCode)
+	}
+	var lastRead float64
+	if err := db.QueryRow(`SELECT last_read_chapter FROM trackers WHERE id = ?`, listedID).Scan(&lastRead); err != nil {
+		t.Fatalf("load last read: %v", err)
+	}
+	if lastRead != 11 {
+		t.Fatalf("expected last read chapter 11, got %v", lastRead)
+	}
+
+	unsupportedID := insertTracker("mangadex", "https://mangadex.org/title/series")
+	body = getBody("/dashboard/trackers/" + strconv.FormatInt(unsupportedID, 10) + "/chapters")
+	if !strings.Contains(body, "not supported for this source") {
+		t.Fatalf("expected unsupported message for connector without chapter lister, got %s", body)
+	}
+}
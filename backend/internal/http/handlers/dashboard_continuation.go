@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
+	"github.com/gofiber/fiber/v2"
+)
+
+const continuationOptionLimit = 8
+
+// sequelMarkerPattern matches what a sequel's title adds after the original
+// title once normalized: "season 2", "s2", "part ii", "2nd season", "2".
+var sequelMarkerPattern = regexp.MustCompile(`^(?:(?:season|part|book|vol|volume|s)\s*(?:[2-9]|\d{2,}|ii|iii|iv|v|vi)|(?:[2-9]|\d{2,}|ii|iii|iv|v|vi)|\d+(?:st|nd|rd|th) season)(?:\s|$)`)
+
+type trackerContinuationOption struct {
+	ID    int64
+	Title string
+}
+
+type trackerContinuationOptionsData struct {
+	Items []trackerContinuationOption
+	Query string
+}
+
+// ContinuationOptions lists the profile's other trackers matching the search
+// in the edit modal's "Continues In" picker.
+func (h *DashboardHandler) ContinuationOptions(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+
+	query := strings.TrimSpace(c.Query("continuation_q"))
+	data := trackerContinuationOptionsData{Query: query}
+	if query == "" {
+		return h.render(c, "tracker_continuation_options.html", data)
+	}
+
+	candidates, err := h.trackerRepo.List(c.UserContext(), repository.TrackerListOptions{
+		ProfileID: activeProfile.ID,
+		Query:     query,
+		SortBy:    "title",
+		Order:     "asc",
+		Limit:     continuationOptionLimit + 1,
+	})
+	if err != nil {
+		return serverError(c, "Failed to search trackers", err)
+	}
+	for _, candidate := range candidates {
+		if candidate.ID == id || len(data.Items) == continuationOptionLimit {
+			continue
+		}
+		data.Items = append(data.Items, trackerContinuationOption{ID: candidate.ID, Title: candidate.Title})
+	}
+
+	return h.render(c, "tracker_continuation_options.html", data)
+}
+
+// continuationFormOptions returns the tracker's current continuation for the
+// edit form, or, when it has none, a tracker whose title reads like its
+// sequel.
+func (h *DashboardHandler) continuationFormOptions(ctx context.Context, profileID int64, tracker *models.Tracker) (current *trackerContinuationOption, suggestion *trackerContinuationOption, err error) {
+	if tracker.ContinuedByTrackerID != nil {
+		links, err := h.trackerRepo.ListTrackerLinks(ctx, profileID, []int64{*tracker.ContinuedByTrackerID})
+		if err != nil {
+			return nil, nil, err
+		}
+		if link, ok := links[*tracker.ContinuedByTrackerID]; ok {
+			return &trackerContinuationOption{ID: link.ID, Title: link.Title}, nil, nil
+		}
+	}
+
+	suggestion, err = h.suggestContinuation(ctx, profileID, tracker)
+	return nil, suggestion, err
+}
+
+// suggestContinuation is best-effort: it looks among the profile's trackers
+// matching the title for one whose title or related titles extend one of
+// this tracker's with a sequel marker such as "Season 2".
+func (h *DashboardHandler) suggestContinuation(ctx context.Context, profileID int64, tracker *models.Tracker) (*trackerContinuationOption, error) {
+	candidates, err := h.trackerRepo.List(ctx, repository.TrackerListOptions{
+		ProfileID: profileID,
+		Query:     tracker.Title,
+		SortBy:    "title",
+		Order:     "asc",
+		Limit:     20,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	baseTitles := append([]string{tracker.Title}, tracker.RelatedTitles...)
+	for _, candidate := range candidates {
+		if candidate.ID == tracker.ID {
+			continue
+		}
+		if candidate.ContinuedByTrackerID != nil && *candidate.ContinuedByTrackerID == tracker.ID {
+			continue
+		}
+		candidateTitles := append([]string{candidate.Title}, candidate.RelatedTitles...)
+		if anyTitleLooksLikeSequel(baseTitles, candidateTitles) {
+			return &trackerContinuationOption{ID: candidate.ID, Title: candidate.Title}, nil
+		}
+	}
+	return nil, nil
+}
+
+func anyTitleLooksLikeSequel(baseTitles []string, candidateTitles []string) bool {
+	for _, base := range baseTitles {
+		for _, candidate := range candidateTitles {
+			if looksLikeSequel(base, candidate) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func looksLikeSequel(base string, candidate string) bool {
+	normalizedBase := searchutil.Normalize(base)
+	normalizedCandidate := searchutil.Normalize(candidate)
+	if normalizedBase == "" || !strings.HasPrefix(normalizedCandidate, normalizedBase+" ") {
+		return false
+	}
+	return sequelMarkerPattern.MatchString(strings.TrimSpace(normalizedCandidate[len(normalizedBase):]))
+}
+
+// validateContinuation checks that continuedBy is another tracker of the
+// profile that does not already lead back to trackerID, returning the
+// message to show when it is not.
+func (h *DashboardHandler) validateContinuation(ctx context.Context, profileID int64, trackerID int64, continuedBy *int64) (string, error) {
+	if continuedBy == nil {
+		return "", nil
+	}
+	if *continuedBy == trackerID {
+		return "A tracker cannot continue itself", nil
+	}
+	target, err := h.trackerRepo.GetByID(ctx, profileID, *continuedBy)
+	if err != nil {
+		return "", err
+	}
+	if target == nil {
+		return "Selected continuation does not exist", nil
+	}
+	loops, err := h.trackerRepo.ContinuesInto(ctx, profileID, *continuedBy, trackerID)
+	if err != nil {
+		return "", err
+	}
+	if loops {
+		return "Selected continuation already continues into this tracker", nil
+	}
+	return "", nil
+}
+
+// continuationLinks loads the continuation each card links to.
+func (h *DashboardHandler) continuationLinks(ctx context.Context, items []models.Tracker) map[int64]repository.TrackerLink {
+	ids := make([]int64, 0)
+	for _, item := range items {
+		if item.ContinuedByTrackerID != nil {
+			ids = append(ids, *item.ContinuedByTrackerID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	links, err := h.trackerRepo.ListTrackerLinks(ctx, items[0].ProfileID, ids)
+	if err != nil {
+		return nil
+	}
+	return links
+}
@@ -0,0 +1,205 @@
+package handlers_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func seedContinuationTracker(t *testing.T, db *sql.DB, title string, slug string) string {
+	t.Helper()
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, ?, 1, ?, 'reading', 12, 12)
+	`, title, "https://asuracomic.net/series/"+slug)
+	if err != nil {
+		t.Fatalf("seed tracker %q: %v", title, err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("tracker id: %v", err)
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+func getBody(t *testing.T, app *fiber.App, target string) (int, string) {
+	t.Helper()
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+	if err != nil {
+		t.Fatalf("request %s failed: %v", target, err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	return res.StatusCode, string(body)
+}
+
+func continuationUpdateForm(title string, slug string, continuedBy string) url.Values {
+	form := url.Values{}
+	form.Set("title", title)
+	form.Set("source_id", "1")
+	form.Set("source_url", "https://asuracomic.net/series/"+slug)
+	form.Set("status", "reading")
+	form.Set("last_read_chapter", "12")
+	form.Set("latest_known_chapter", "12")
+	form.Set("continued_by_tracker_id", continuedBy)
+	return form
+}
+
+func TestEditModalSuggestsSequelAsContinuation(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	seasonOne := seedContinuationTracker(t, db, "Zeta Saga", "zeta-saga")
+	seasonTwo := seedContinuationTracker(t, db, "Zeta Saga Season 2", "zeta-saga-season-2")
+	seedContinuationTracker(t, db, "Zeta Saga Side Stories", "zeta-saga-side-stories")
+
+	_, body := getBody(t, app, "/dashboard/trackers/"+seasonOne+"/edit")
+	if !strings.Contains(body, `value="`+seasonTwo+`">`) || !strings.Contains(body, "Zeta Saga Season 2 <span class=\"search-message\">(suggested)</span>") {
+		t.Fatalf("expected season 2 to be suggested, got %s", body)
+	}
+	if strings.Contains(body, "Zeta Saga Side Stories <span") {
+		t.Fatalf("expected side stories not to be suggested, got %s", body)
+	}
+
+	_, body = getBody(t, app, "/dashboard/trackers/"+seasonTwo+"/edit")
+	if strings.Contains(body, "(suggested)") {
+		t.Fatalf("expected no suggestion for the sequel itself, got %s", body)
+	}
+}
+
+func TestUpdateFromFormSavesContinuation(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	seasonOne := seedContinuationTracker(t, db, "Zeta Saga", "zeta-saga")
+	seasonTwo := seedContinuationTracker(t, db, "Zeta Saga Season 2", "zeta-saga-season-2")
+
+	_, body := postCardAction(t, app, "/dashboard/trackers/"+seasonOne+"?view=grid", continuationUpdateForm("Zeta Saga", "zeta-saga", seasonTwo))
+	if !strings.Contains(body, "→ continues in Zeta Saga Season 2") || !strings.Contains(body, `href="https://asuracomic.net/series/zeta-saga-season-2"`) {
+		t.Fatalf("expected the card to link the continuation, got %s", body)
+	}
+
+	var stored sql.NullInt64
+	if err := db.QueryRow(`SELECT continued_by_tracker_id FROM trackers WHERE id = ?`, seasonOne).Scan(&stored); err != nil {
+		t.Fatalf("load continuation: %v", err)
+	}
+	if !stored.Valid || strconv.FormatInt(stored.Int64, 10) != seasonTwo {
+		t.Fatalf("expected continuation %s to be stored, got %+v", seasonTwo, stored)
+	}
+
+	_, body = getBody(t, app, "/dashboard/trackers/"+seasonOne+"/edit")
+	if !strings.Contains(body, `value="`+seasonTwo+`" checked>`) || strings.Contains(body, "(suggested)") {
+		t.Fatalf("expected the saved continuation to be selected, got %s", body)
+	}
+
+	_, body = getBody(t, app, "/v1/trackers/"+seasonOne)
+	if !strings.Contains(body, `"continuedByTrackerId":`+seasonTwo) {
+		t.Fatalf("expected api response to include the continuation, got %s", body)
+	}
+
+	// Posting the form without a continuation clears it.
+	_, body = postCardAction(t, app, "/dashboard/trackers/"+seasonOne+"?view=grid", continuationUpdateForm("Zeta Saga", "zeta-saga", ""))
+	if strings.Contains(body, "continues in") {
+		t.Fatalf("expected the continuation link to be gone, got %s", body)
+	}
+}
+
+func TestDeletingTheContinuationClearsItOnAPooledConnection(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	db.SetMaxOpenConns(4)
+
+	seasonOne := seedContinuationTracker(t, db, "Zeta Saga", "zeta-saga")
+	seasonTwo := seedContinuationTracker(t, db, "Zeta Saga Season 2", "zeta-saga-season-2")
+	if _, err := db.Exec(`UPDATE trackers SET continued_by_tracker_id = ? WHERE id = ?`, seasonTwo, seasonOne); err != nil {
+		t.Fatalf("set continuation: %v", err)
+	}
+
+	// Keep a connection busy so the delete runs on another one of the pool.
+	held, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("hold connection: %v", err)
+	}
+	defer held.Close()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodDelete, "/v1/trackers/"+seasonTwo, nil))
+	if err != nil {
+		t.Fatalf("delete request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", res.StatusCode)
+	}
+
+	var stored sql.NullInt64
+	if err := held.QueryRowContext(context.Background(), `SELECT continued_by_tracker_id FROM trackers WHERE id = ?`, seasonOne).Scan(&stored); err != nil {
+		t.Fatalf("load continuation: %v", err)
+	}
+	if stored.Valid {
+		t.Fatalf("expected the continuation to be cleared, got %d", stored.Int64)
+	}
+}
+
+func TestUpdateFromFormRejectsContinuationLoops(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	seasonOne := seedContinuationTracker(t, db, "Zeta Saga", "zeta-saga")
+	seasonTwo := seedContinuationTracker(t, db, "Zeta Saga Season 2", "zeta-saga-season-2")
+	postCardAction(t, app, "/dashboard/trackers/"+seasonOne+"?view=grid", continuationUpdateForm("Zeta Saga", "zeta-saga", seasonTwo))
+
+	cases := []struct {
+		name        string
+		id          string
+		slug        string
+		title       string
+		continuedBy string
+		message     string
+	}{
+		{name: "loop", id: seasonTwo, slug: "zeta-saga-season-2", title: "Zeta Saga Season 2", continuedBy: seasonOne, message: "Selected continuation already continues into this tracker"},
+		{name: "self", id: seasonOne, slug: "zeta-saga", title: "Zeta Saga", continuedBy: seasonOne, message: "A tracker cannot continue itself"},
+		{name: "missing", id: seasonOne, slug: "zeta-saga", title: "Zeta Saga", continuedBy: "999999", message: "Selected continuation does not exist"},
+		{name: "invalid", id: seasonOne, slug: "zeta-saga", title: "Zeta Saga", continuedBy: "abc", message: "Invalid continuation"},
+	}
+	for _, tc := range cases {
+		form := continuationUpdateForm(tc.title, tc.slug, tc.continuedBy)
+		req := httptest.NewRequest(http.MethodPost, "/dashboard/trackers/"+tc.id, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", tc.name, err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != http.StatusBadRequest || string(body) != tc.message {
+			t.Fatalf("%s: expected 400 %q, got %d %q", tc.name, tc.message, res.StatusCode, string(body))
+		}
+	}
+}
+
+func TestContinuationOptionsSearchesOtherTrackers(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	seasonOne := seedContinuationTracker(t, db, "Zeta Saga", "zeta-saga")
+	seasonTwo := seedContinuationTracker(t, db, "Zeta Saga Season 2", "zeta-saga-season-2")
+	seedContinuationTracker(t, db, "Unrelated Quest", "unrelated-quest")
+
+	status, body := getBody(t, app, "/dashboard/trackers/"+seasonOne+"/continuation-options?continuation_q=zeta")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if !strings.Contains(body, `value="`+seasonTwo+`"`) || strings.Contains(body, `value="`+seasonOne+`"`) || strings.Contains(body, "Unrelated Quest") {
+		t.Fatalf("expected only season 2 among the options, got %s", body)
+	}
+
+	_, body = getBody(t, app, "/dashboard/trackers/"+seasonOne+"/continuation-options?continuation_q=nothing")
+	if !strings.Contains(body, `No other trackers match "nothing".`) {
+		t.Fatalf("expected an empty-result message, got %s", body)
+	}
+}
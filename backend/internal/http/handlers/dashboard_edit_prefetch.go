@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// editFormMemoTTL bounds how long a prefetched edit form is served. It only
+// has to outlive the gap between hovering a card and clicking it; writes the
+// dashboard does not see, such as a poll, are picked up once it runs out.
+const editFormMemoTTL = 5 * time.Second
+
+type editFormKey struct {
+	profileID int64
+	trackerID int64
+}
+
+type editFormEntry struct {
+	data    trackerFormData
+	expires time.Time
+}
+
+// editFormMemo keeps the edit form data a card hover prefetched, so the
+// click that follows renders without going back to the database. Every
+// write to a tracker, or to the tags of its profile, forgets its entry.
+// Generations keep a load that started before such a write from storing
+// what it read.
+type editFormMemo struct {
+	ttl        time.Duration
+	now        func() time.Time
+	mu         sync.Mutex
+	entries    map[editFormKey]editFormEntry
+	trackerGen map[editFormKey]uint64
+	profileGen map[int64]uint64
+}
+
+func newEditFormMemo(ttl time.Duration) *editFormMemo {
+	return &editFormMemo{
+		ttl:        ttl,
+		now:        time.Now,
+		entries:    make(map[editFormKey]editFormEntry),
+		trackerGen: make(map[editFormKey]uint64),
+		profileGen: make(map[int64]uint64),
+	}
+}
+
+// lookup returns the memoized form of key, if any, and the generation to
+// hand to store after loading it afresh.
+func (m *editFormMemo) lookup(key editFormKey) (trackerFormData, uint64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	gen := m.generation(key)
+	entry, ok := m.entries[key]
+	if !ok || !m.now().Before(entry.expires) {
+		return trackerFormData{}, gen, false
+	}
+	return entry.data, gen, true
+}
+
+// store memoizes data for key unless key was forgotten since lookup
+// returned gen.
+func (m *editFormMemo) store(key editFormKey, gen uint64, data trackerFormData) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.generation(key) != gen {
+		return
+	}
+	now := m.now()
+	for existing, entry := range m.entries {
+		if !now.Before(entry.expires) {
+			delete(m.entries, existing)
+		}
+	}
+	m.entries[key] = editFormEntry{data: data, expires: now.Add(m.ttl)}
+}
+
+// forget drops the form of one tracker.
+func (m *editFormMemo) forget(profileID, trackerID int64) {
+	key := editFormKey{profileID: profileID, trackerID: trackerID}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trackerGen[key]++
+	delete(m.entries, key)
+}
+
+// forgetProfile drops every form of a profile, for writes such as a tag
+// rename that show up in all of them.
+func (m *editFormMemo) forgetProfile(profileID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.profileGen[profileID]++
+	for key := range m.entries {
+		if key.profileID == profileID {
+			delete(m.entries, key)
+		}
+	}
+}
+
+func (m *editFormMemo) generation(key editFormKey) uint64 {
+	return m.trackerGen[key] + m.profileGen[key.profileID]
+}
+
+// editFormData returns the edit form of a tracker from the memo, loading
+// and memoizing it on a miss. It returns nil when the profile has no such
+// tracker.
+func (h *DashboardHandler) editFormData(ctx context.Context, profileID, trackerID int64) (*trackerFormData, error) {
+	key := editFormKey{profileID: profileID, trackerID: trackerID}
+	memoized, gen, ok := h.editForms.lookup(key)
+	if ok {
+		return &memoized, nil
+	}
+	data, err := h.loadEditFormData(ctx, profileID, trackerID)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	h.editForms.store(key, gen, *data)
+	return data, nil
+}
+
+// ForgetEditForm drops the prefetched edit form of a tracker. The API
+// handlers call it after writing to the tracker.
+func (h *DashboardHandler) ForgetEditForm(profileID, trackerID int64) {
+	h.editForms.forget(profileID, trackerID)
+}
+
+// ForgetEditForms drops the prefetched edit forms of a profile. The API
+// handlers call it after writes such as tag changes that every form shows.
+func (h *DashboardHandler) ForgetEditForms(profileID int64) {
+	h.editForms.forgetProfile(profileID)
+}
+
+// EditTrackerPrefetch loads the edit form of a tracker when its card is
+// hovered, so the click that opens the modal is served from the memo. The
+// response itself is discarded by the page.
+func (h *DashboardHandler) EditTrackerPrefetch(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+
+	data, err := h.editFormData(c.UserContext(), activeProfile.ID, id)
+	if err != nil {
+		return serverError(c, "Failed to load tracker", err)
+	}
+	if data == nil {
+		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
+	}
+	data.ViewMode = normalizeViewMode(c.Query("view", "grid"))
+	return h.render(c, "tracker_form_modal.html", *data)
+}
@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func getEditForm(t *testing.T, app *fiber.App, path string) (int, string) {
+	t.Helper()
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+	if err != nil {
+		t.Fatalf("edit form request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	return res.StatusCode, string(body)
+}
+
+func TestEditPrefetchIsServedUntilATrackerWrite(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Prefetch Blade', 1, 'https://asuracomic.net/series/prefetch-blade', 'reading', 4, 12)
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	id := strconv.FormatInt(trackerID, 10)
+
+	status, body := getEditForm(t, app, "/dashboard/trackers/"+id+"/edit-prefetch?profile=profile1")
+	if status != http.StatusOK || !strings.Contains(body, "Prefetch Blade") {
+		t.Fatalf("expected the prefetch to render the edit form, got %d: %s", status, body)
+	}
+
+	// A write behind the dashboard's back is not seen while the prefetch is
+	// fresh, which shows the modal comes from the memo.
+	if _, err := db.Exec(`UPDATE trackers SET title = 'Renamed Elsewhere' WHERE id = ?`, trackerID); err != nil {
+		t.Fatalf("rename tracker: %v", err)
+	}
+	_, body = getEditForm(t, app, "/dashboard/trackers/"+id+"/edit?profile=profile1")
+	if !strings.Contains(body, "Prefetch Blade") {
+		t.Fatalf("expected the modal to be served from the prefetch, got %s", body)
+	}
+
+	form := url.Values{}
+	form.Set("view_mode", "grid")
+	form.Set("chapter", "7")
+	postCardAction(t, app, "/dashboard/trackers/"+id+"/set-last-read", form)
+
+	_, body = getEditForm(t, app, "/dashboard/trackers/"+id+"/edit?profile=profile1")
+	if !strings.Contains(body, "Renamed Elsewhere") || !strings.Contains(body, `name="last_read_chapter" value="7"`) {
+		t.Fatalf("expected setting last read to drop the prefetch, got %s", body)
+	}
+
+	status, _ = getEditForm(t, app, "/dashboard/trackers/"+id+"/edit-prefetch?profile=profile2")
+	if status != http.StatusNotFound {
+		t.Fatalf("expected another profile's prefetch to miss the tracker, got %d", status)
+	}
+}
+
+func TestEditPrefetchIsDroppedByTagWrites(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+		VALUES (1, 'Tagged Blade', 1, 'https://asuracomic.net/series/tagged-blade', 'reading')
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	id := strconv.FormatInt(trackerID, 10)
+
+	if status, _ := getEditForm(t, app, "/dashboard/trackers/"+id+"/edit-prefetch?profile=profile1"); status != http.StatusOK {
+		t.Fatalf("expected the prefetch to succeed, got %d", status)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/tags?profile=profile1", strings.NewReader(`{"name":"Prefetched Tag"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("create tag request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 from the tag API, got %d", res.StatusCode)
+	}
+
+	_, body := getEditForm(t, app, "/dashboard/trackers/"+id+"/edit?profile=profile1")
+	if !strings.Contains(body, "Prefetched Tag") {
+		t.Fatalf("expected a new tag to drop the prefetch, got %s", body)
+	}
+}
+
+func TestTrackerCardsPrefetchTheEditFormOnHover(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if _, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+		VALUES (1, 'Hover Blade', 1, 'https://asuracomic.net/series/hover-blade', 'reading')
+	`); err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+
+	for _, view := range []string{"grid", "list"} {
+		_, body := getEditForm(t, app, "/dashboard/trackers?profile=profile1&view="+view)
+		if !strings.Contains(body, `/edit-prefetch"`) || !strings.Contains(body, `hx-trigger="mouseenter once delay:150ms from:closest article"`) {
+			t.Fatalf("expected %s cards to prefetch the edit form on hover, got %s", view, body)
+		}
+	}
+}
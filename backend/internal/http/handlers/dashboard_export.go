@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+const exportViewRowLimit = 500
+
+// ExportView downloads the currently filtered and sorted tracker view as a
+// compact text or CSV list. Pagination is ignored; the row count is capped.
+func (h *DashboardHandler) ExportView(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format", "txt")))
+	if format != "txt" && format != "csv" {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid export format")
+	}
+
+	listOptions, err := h.dashboardListOptions(c, activeProfile.ID)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	if _, err := dropUnknownTagFilters(c.UserContext(), h.trackerRepo, &listOptions); err != nil {
+		return serverError(c, "Failed to load profile tags", err)
+	}
+	listOptions.Limit = exportViewRowLimit
+
+	items, err := h.trackerRepo.List(c.UserContext(), listOptions)
+	if err != nil {
+		return serverError(c, "Failed to load trackers", err)
+	}
+
+	sources, err := pageCtx.Sources()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	sourceNameByID := make(map[int64]string, len(sources))
+	for _, source := range sources {
+		sourceNameByID[source.ID] = source.Name
+	}
+
+	filename := fmt.Sprintf("trackers-%s.%s", activeProfile.Key, format)
+	c.Set("Cache-Control", "no-store")
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == "csv" {
+		c.Set("Content-Type", "text/csv; charset=utf-8")
+		writer := csv.NewWriter(c.Response().BodyWriter())
+		if err := writer.Write([]string{"title", "latest_chapter", "source", "status", "source_url", "first_read_at", "caught_up_at"}); err != nil {
+			return err
+		}
+		for _, item := range items {
+			latest := ""
+			if item.LatestKnownChapter != nil {
+				latest = strconv.FormatFloat(*item.LatestKnownChapter, 'f', -1, 64)
+			}
+			if err := writer.Write([]string{item.Title, latest, sourceNameByID[item.SourceID], item.Status, item.SourceURL, timeInputValue(item.FirstReadAt), timeInputValue(item.CaughtUpAt)}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	c.Set("Content-Type", "text/plain; charset=utf-8")
+	writer := bufio.NewWriter(c.Response().BodyWriter())
+	for _, item := range items {
+		if _, err := writer.WriteString(exportViewLine(item, sourceNameByID[item.SourceID]) + "\n"); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+func exportViewLine(item models.Tracker, sourceName string) string {
+	line := item.Title
+	if item.LatestKnownChapter != nil {
+		line += " — " + formatChapterLabel(*item.LatestKnownChapter)
+	}
+	if strings.TrimSpace(sourceName) != "" {
+		line += " (" + sourceName + ")"
+	}
+	return line
+}
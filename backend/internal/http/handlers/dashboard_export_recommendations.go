@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+type recommendationsPageData struct {
+	Heading string
+	Cards   []trackerCardView
+}
+
+// ExportRecommendations writes the trackers matching the current filters,
+// which must name at least one tag, as a list to share: a standalone HTML
+// page or markdown that pastes cleanly into Discord. The list is ordered by
+// rating, then title; without a status filter every status is included.
+func (h *DashboardHandler) ExportRecommendations(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format", "md")))
+	if format != "md" && format != "html" {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid export format")
+	}
+
+	listOptions := trackerListOptionsFromQuery(c, activeProfile.ID)
+	if strings.TrimSpace(c.Query("status")) == "" {
+		listOptions.Statuses = nil
+	}
+	if _, err := dropUnknownTagFilters(c.UserContext(), h.trackerRepo, &listOptions); err != nil {
+		return serverError(c, "Failed to load profile tags", err)
+	}
+	// A tag that matched nothing was dropped above; exporting the rest of
+	// the library in its place would share far more than asked for.
+	heading := recommendationsHeading(listOptions.TagFilters)
+	if heading == "" {
+		return c.Status(fiber.StatusBadRequest).SendString("Choose a tag to export recommendations")
+	}
+	listOptions.SortBy = "rating"
+	listOptions.Order = "desc"
+	listOptions.Limit = exportViewRowLimit
+
+	items, err := h.trackerRepo.List(c.UserContext(), listOptions)
+	if err != nil {
+		return serverError(c, "Failed to load trackers", err)
+	}
+	sourceByID, err := pageCtx.SourceByID()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	cards, _ := h.buildTrackerCards(c.UserContext(), items, sourceByID, sourceLogoBySourceID, "")
+
+	filename := fmt.Sprintf("recommendations-%s.%s", activeProfile.Key, format)
+	c.Set("Cache-Control", "no-store")
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == "html" {
+		return h.render(c, "tracker_recommendations_page.html", recommendationsPageData{Heading: heading, Cards: cards})
+	}
+
+	c.Set("Content-Type", "text/markdown; charset=utf-8")
+	writer := bufio.NewWriter(c.Response().BodyWriter())
+	if _, err := writer.WriteString(recommendationsMarkdown(heading, cards)); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// recommendationsHeading names the tags the list was filtered to, or returns
+// "" when it was not filtered to any.
+func recommendationsHeading(filters []repository.TagFilter) string {
+	terms := make([]string, 0, len(filters))
+	for _, filter := range filters {
+		if filter.Exclude || len(filter.AnyOf) == 0 {
+			continue
+		}
+		terms = append(terms, strings.Join(filter.AnyOf, " or "))
+	}
+	if len(terms) == 0 {
+		return ""
+	}
+	return "Recommendations: " + strings.Join(terms, ", ")
+}
+
+// recommendationsMarkdown writes one bullet per series. Links are wrapped in
+// angle brackets, which Discord reads as "no embed", so a pasted list does
+// not unfold into a preview per series.
+func recommendationsMarkdown(heading string, cards []trackerCardView) string {
+	var builder strings.Builder
+	builder.WriteString("## " + escapeMarkdown(heading) + "\n\n")
+	for _, card := range cards {
+		line := "- **" + escapeMarkdown(card.Title) + "**"
+		if link := strings.TrimSpace(card.SourceURL); link != "" {
+			line = "- **[" + escapeMarkdown(card.Title) + "](<" + link + ">)**"
+		}
+		if card.Rating != nil {
+			line += " — " + card.RatingLabel + "/10"
+		}
+		line += " · " + card.StatusLabel
+		builder.WriteString(line + "\n")
+	}
+	return builder.String()
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`",
+	"|", `\|`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`,
+)
+
+// escapeMarkdown keeps a title's own asterisks, brackets and the like from
+// being read as formatting.
+func escapeMarkdown(value string) string {
+	return markdownEscaper.Replace(value)
+}
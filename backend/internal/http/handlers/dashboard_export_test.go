@@ -0,0 +1,221 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var trackerCardTitlePattern = regexp.MustCompile(`<h3>([^<]+)</h3>`)
+
+func TestExportViewMatchesTrackersPartialFilters(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	_, err := db.Exec(`
+		INSERT INTO trackers (title, source_id, source_url, status, last_read_chapter, latest_known_chapter, rating)
+		VALUES
+			('Alpha Blade', 1, 'https://mangadex.org/title/alpha', 'reading', 10, 12, 8),
+			('Beta Blade', 2, 'https://mangafire.to/manga/beta', 'reading', 3, 7, 9),
+			('Gamma Tower', 1, 'https://mangadex.org/title/gamma', 'completed', 50, 50, 7),
+			('Delta Tower', 2, 'https://mangafire.to/manga/delta', 'on_hold', 3, 20, NULL)
+	`)
+	if err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, 'favorite', 'favorite')`)
+	if err != nil {
+		t.Fatalf("seed tag: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO tracker_tags (tracker_id, tag_id)
+		SELECT t.id, ct.id FROM trackers t, custom_tags ct
+		WHERE t.title IN ('Alpha Blade', 'Delta Tower') AND ct.name = 'favorite'
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker tags: %v", err)
+	}
+
+	queries := []string{
+		"status=reading&sort=rating&order=desc",
+		"status=all&sort=title&order=asc",
+		"status=all&tags=favorite&sort=title&order=asc",
+		"status=all&sites=2&q=tower",
+	}
+
+	for _, query := range queries {
+		partialTitles := fetchPartialTitles(t, app.Test, "/dashboard/trackers?"+query)
+		exportTitles := fetchExportTitles(t, app.Test, "/dashboard/trackers/export-view?format=txt&"+query)
+
+		if len(partialTitles) == 0 {
+			t.Fatalf("query %q: expected partial to render trackers", query)
+		}
+		if strings.Join(partialTitles, "|") != strings.Join(exportTitles, "|") {
+			t.Fatalf("query %q: expected export %v to match partial %v", query, exportTitles, partialTitles)
+		}
+	}
+}
+
+func TestExportViewWritesCSVWithDownloadHeaders(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	_, err := db.Exec(`
+		INSERT INTO trackers (title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		SELECT 'Comma, Title', id, 'https://mangadex.org/title/comma', 'reading', 1, 123
+		FROM sources WHERE key = 'mangadex'
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/export-view?format=csv&status=reading", nil))
+	if err != nil {
+		t.Fatalf("export request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	if contentType := res.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/csv") {
+		t.Fatalf("expected csv content type, got %q", contentType)
+	}
+	if disposition := res.Header.Get("Content-Disposition"); !strings.Contains(disposition, `filename="trackers-profile1.csv"`) {
+		t.Fatalf("expected attachment filename, got %q", disposition)
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	if !strings.Contains(string(body), `"Comma, Title",123,MangaDex,reading,https://mangadex.org/title/comma`) {
+		t.Fatalf("unexpected csv body: %s", string(body))
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/export-view?format=xml", nil))
+	if err != nil {
+		t.Fatalf("export request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown format, got %d", res.StatusCode)
+	}
+}
+
+func fetchPartialTitles(t *testing.T, do func(*http.Request, ...int) (*http.Response, error), target string) []string {
+	t.Helper()
+
+	res, err := do(httptest.NewRequest(http.MethodGet, target, nil))
+	if err != nil {
+		t.Fatalf("partial request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from partial, got %d", res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+
+	titles := make([]string, 0)
+	for _, match := range trackerCardTitlePattern.FindAllStringSubmatch(string(body), -1) {
+		titles = append(titles, match[1])
+	}
+	return titles
+}
+
+func fetchExportTitles(t *testing.T, do func(*http.Request, ...int) (*http.Response, error), target string) []string {
+	t.Helper()
+
+	res, err := do(httptest.NewRequest(http.MethodGet, target, nil))
+	if err != nil {
+		t.Fatalf("export request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from export, got %d", res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+
+	titles := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+		if line == "" {
+			continue
+		}
+		title := line
+		if index := strings.Index(title, " — "); index >= 0 {
+			title = title[:index]
+		} else if index := strings.LastIndex(title, " ("); index >= 0 {
+			title = title[:index]
+		}
+		titles = append(titles, title)
+	}
+	return titles
+}
+
+func TestExportRecommendationsListsTaggedTrackersByRating(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	_, err := db.Exec(`
+		INSERT INTO trackers (title, source_id, source_url, status, last_read_chapter, latest_known_chapter, rating)
+		VALUES
+			('Alpha Blade', 1, 'https://mangadex.org/title/alpha', 'reading', 10, 12, 8),
+			('Beta *Blade*', 1, 'https://mangadex.org/title/beta', 'completed', 50, 50, 9.5),
+			('Gamma Tower', 1, 'https://mangadex.org/title/gamma', 'on_hold', 3, 20, 8),
+			('Delta Tower', 1, 'https://mangadex.org/title/delta', 'reading', 3, 7, NULL),
+			('Omega Untagged', 1, 'https://mangadex.org/title/omega', 'reading', 1, 2, 10)
+	`)
+	if err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, 'recommend', 'recommend')`); err != nil {
+		t.Fatalf("seed tag: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO tracker_tags (tracker_id, tag_id)
+		SELECT t.id, ct.id FROM trackers t, custom_tags ct
+		WHERE t.title != 'Omega Untagged' AND ct.name = 'recommend'
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker tags: %v", err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/export-recommendations?tags=recommend&format=md", nil))
+	if err != nil {
+		t.Fatalf("export request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/markdown") {
+		t.Fatalf("expected a markdown export, got %d %q", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(res.Body)
+	want := strings.Join([]string{
+		"## Recommendations: recommend",
+		"",
+		`- **[Beta \*Blade\*](<https://mangadex.org/title/beta>)** — 9.5/10 · Completed`,
+		"- **[Alpha Blade](<https://mangadex.org/title/alpha>)** — 8.0/10 · Reading",
+		"- **[Gamma Tower](<https://mangadex.org/title/gamma>)** — 8.0/10 · On hold",
+		"- **[Delta Tower](<https://mangadex.org/title/delta>)** · Reading",
+	}, "\n") + "\n"
+	if string(body) != want {
+		t.Fatalf("unexpected markdown:\n%s\nwant:\n%s", body, want)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/export-recommendations?tags=recommend&status=reading&format=html", nil))
+	if err != nil {
+		t.Fatalf("export request failed: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	html := string(body)
+	alpha, delta := strings.Index(html, `<a href="https://mangadex.org/title/alpha">Alpha Blade</a>`), strings.Index(html, "Delta Tower")
+	if res.StatusCode != http.StatusOK || alpha < 0 || delta < alpha {
+		t.Fatalf("expected the reading recommendations in rating order, got %d %s", res.StatusCode, html)
+	}
+	if strings.Contains(html, "Omega Untagged") || strings.Contains(html, "Beta") {
+		t.Fatalf("expected untagged and filtered out trackers to be left out, got %s", html)
+	}
+
+	for _, query := range []string{"format=md", "tags=missing&format=md", "tags=recommend&format=pdf"} {
+		res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/export-recommendations?"+query, nil))
+		if err != nil {
+			t.Fatalf("export request failed: %v", err)
+		}
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("query %q: expected 400, got %d", query, res.StatusCode)
+		}
+	}
+}
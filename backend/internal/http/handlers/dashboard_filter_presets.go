@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
+	"github.com/gofiber/fiber/v2"
+)
+
+// filterPresetChip is a saved preset in the dashboard header, linking to
+// the dashboard with the preset's filters in the URL.
+type filterPresetChip struct {
+	ID   int64
+	Name string
+	URL  string
+}
+
+type filterPresetsData struct {
+	ProfileKey string
+	Presets    []filterPresetChip
+	ReadOnly   bool
+}
+
+func (h *DashboardHandler) loadFilterPresets(c *fiber.Ctx, profile *models.Profile) (filterPresetsData, error) {
+	presets, err := h.filterPresetRepo.List(c.UserContext(), profile.ID)
+	if err != nil {
+		return filterPresetsData{}, loadFailed("Failed to load filter presets", err)
+	}
+
+	chips := make([]filterPresetChip, 0, len(presets))
+	for _, preset := range presets {
+		link := profileURL("/dashboard", profile.Key)
+		if query := filterPresetQuery(preset.Params); query != "" {
+			link += "&" + query
+		}
+		chips = append(chips, filterPresetChip{ID: preset.ID, Name: preset.Name, URL: link})
+	}
+	return filterPresetsData{ProfileKey: profile.Key, Presets: chips, ReadOnly: isReadOnly(c)}, nil
+}
+
+// SaveFilterPresetFromForm saves the filter form posted with it under
+// preset_name and re-renders the preset chips.
+func (h *DashboardHandler) SaveFilterPresetFromForm(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	name, err := validateFilterPresetName(c.FormValue("preset_name"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Preset " + err.Error())
+	}
+	params := filterPresetParamsFromArgs(c.Request().PostArgs())
+	if err := validateFilterPresetParams(params); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid status or sort")
+	}
+
+	if _, err := h.filterPresetRepo.Create(c.UserContext(), activeProfile.ID, name, params); err != nil {
+		if isUniqueViolation(err) {
+			return c.Status(fiber.StatusBadRequest).SendString("A preset with that name already exists")
+		}
+		return serverError(c, "Failed to save filter preset", err)
+	}
+
+	return h.renderFilterPresets(c, activeProfile)
+}
+
+func (h *DashboardHandler) DeleteFilterPresetFromForm(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid preset")
+	}
+	if _, err := h.filterPresetRepo.Delete(c.UserContext(), activeProfile.ID, id); err != nil {
+		return serverError(c, "Failed to delete filter preset", err)
+	}
+
+	return h.renderFilterPresets(c, activeProfile)
+}
+
+func (h *DashboardHandler) renderFilterPresets(c *fiber.Ctx, profile *models.Profile) error {
+	data, err := h.loadFilterPresets(c, profile)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	return h.render(c, "filter_presets_partial.html", data)
+}
+
+// selectedFilterTags splits tag terms into the profile tags the filter
+// form's checkboxes select, keyed by tag name, and the terms no checkbox
+// stands for, such as "a|b" or "-stale".
+func selectedFilterTags(profileTags []models.CustomTag, terms []string) (map[string]bool, []string) {
+	names := make(map[string]string, len(profileTags))
+	for _, tag := range profileTags {
+		names[searchutil.NormalizeTagName(tag.Name)] = tag.Name
+	}
+
+	selected := make(map[string]bool)
+	extra := make([]string, 0)
+	for _, term := range terms {
+		filters := parseTagFilters(term)
+		if len(filters) == 1 && !filters[0].Exclude && len(filters[0].AnyOf) == 1 {
+			if name, ok := names[searchutil.NormalizeTagName(filters[0].AnyOf[0])]; ok {
+				selected[name] = true
+				continue
+			}
+		}
+		extra = append(extra, term)
+	}
+	return selected, extra
+}
+
+// ignoredSiteNames names the sources of filters left out for being
+// disabled, falling back to the id of a source that no longer exists.
+func (h *DashboardHandler) ignoredSiteNames(ctx context.Context, sourceIDs []int64) ([]string, error) {
+	names := make([]string, 0, len(sourceIDs))
+	for _, sourceID := range sourceIDs {
+		source, err := h.sourceRepo.GetByID(ctx, sourceID)
+		if err != nil {
+			return nil, loadFailed("Failed to load sources", err)
+		}
+		if source == nil {
+			names = append(names, fmt.Sprintf("#%d", sourceID))
+			continue
+		}
+		names = append(names, source.Name)
+	}
+	return names, nil
+}
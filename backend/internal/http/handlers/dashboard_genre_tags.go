@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
+	"github.com/gofiber/fiber/v2"
+)
+
+// genreTagSuggestion is a source genre offered in the edit form's tag
+// section. Tag is the profile tag of the same name, which one click
+// applies; without one the form offers to create a tag from the genre.
+type genreTagSuggestion struct {
+	Genre string
+	Tag   *models.CustomTag
+}
+
+// genreTagSuggestions matches a tracker's source genres against the
+// profile's tags by searchutil.Normalize, so "Sci-Fi" finds a "sci fi" tag.
+// Genres whose tag the tracker already carries are left out.
+func genreTagSuggestions(genres []string, profileTags []models.CustomTag, trackerTags []models.CustomTag) []genreTagSuggestion {
+	tagByName := make(map[string]models.CustomTag, len(profileTags))
+	for _, tag := range profileTags {
+		if key := searchutil.Normalize(tag.Name); key != "" {
+			if _, exists := tagByName[key]; !exists {
+				tagByName[key] = tag
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(genres))
+	suggestions := make([]genreTagSuggestion, 0, len(genres))
+	for _, genre := range genres {
+		key := searchutil.Normalize(genre)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		tag, ok := tagByName[key]
+		if !ok {
+			if len(strings.TrimSpace(genre)) <= maxTagNameLength {
+				suggestions = append(suggestions, genreTagSuggestion{Genre: genre})
+			}
+			continue
+		}
+		if hasTagID(trackerTags, tag.ID) {
+			continue
+		}
+		suggestions = append(suggestions, genreTagSuggestion{Genre: genre, Tag: &tag})
+	}
+	return suggestions
+}
+
+// CreateTagFromGenre creates a profile tag named after a source genre and
+// answers with its checkbox, ticked, for the edit form to save with the
+// tracker. A tag that already matches the genre is reused.
+func (h *DashboardHandler) CreateTagFromGenre(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	genre := strings.Join(strings.Fields(c.FormValue("genre")), " ")
+	if genre == "" {
+		return c.Status(fiber.StatusBadRequest).SendString("Genre is required")
+	}
+	if len(genre) > maxTagNameLength {
+		return c.Status(fiber.StatusBadRequest).SendString(fmt.Sprintf("Tag name must be %d characters or less", maxTagNameLength))
+	}
+
+	tag, err := h.profileTagForGenre(c.UserContext(), activeProfile.ID, genre)
+	if err != nil {
+		return serverError(c, "Failed to load profile tags", err)
+	}
+	if tag == nil {
+		tag, err = h.trackerRepo.CreateProfileTag(c.UserContext(), activeProfile.ID, genre, nil)
+		if err != nil && isUniqueViolation(err) {
+			tag, err = h.profileTagForGenre(c.UserContext(), activeProfile.ID, genre)
+		}
+		if err != nil {
+			return serverError(c, "Failed to save tag", err)
+		}
+		if tag == nil {
+			return c.Status(fiber.StatusBadRequest).SendString("A tag with that name already exists")
+		}
+		h.editForms.forgetProfile(activeProfile.ID)
+		setHXTrigger(c, map[string]any{"profileTagsChanged": true})
+	}
+
+	return h.render(c, "tracker_genre_tag_partial.html", tag)
+}
+
+func (h *DashboardHandler) profileTagForGenre(ctx context.Context, profileID int64, genre string) (*models.CustomTag, error) {
+	profileTags, err := h.trackerRepo.ListProfileTags(ctx, profileID)
+	if err != nil {
+		return nil, err
+	}
+	key := searchutil.Normalize(genre)
+	for index := range profileTags {
+		if searchutil.Normalize(profileTags[index].Name) == key {
+			return &profileTags[index], nil
+		}
+	}
+	return nil, nil
+}
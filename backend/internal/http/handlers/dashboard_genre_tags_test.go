@@ -0,0 +1,83 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEditModalSuggestsTagsFromSourceGenres(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, source_genres)
+		VALUES (1, 'Genre Blade', 1, 'https://asuracomic.net/series/genre-blade', 'reading', '["Sci-Fi","Action","Romance"]')
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	id := strconv.FormatInt(trackerID, 10)
+	tagResult, err := db.Exec(`INSERT INTO custom_tags (profile_id, name) VALUES (1, 'sci fi'), (1, 'action')`)
+	if err != nil {
+		t.Fatalf("seed tags: %v", err)
+	}
+	actionTagID, _ := tagResult.LastInsertId()
+	if _, err := db.Exec(`INSERT INTO tracker_tags (tracker_id, tag_id) VALUES (?, ?)`, trackerID, actionTagID); err != nil {
+		t.Fatalf("seed tracker tag: %v", err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/"+id+"/edit?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("edit modal request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	html := string(body)
+	if !strings.Contains(html, "+ sci fi</button>") {
+		t.Fatalf("expected the Sci-Fi genre to offer the sci fi tag, got %s", html)
+	}
+	if !strings.Contains(html, "+ New tag: Romance</button>") {
+		t.Fatalf("expected the Romance genre to offer a new tag, got %s", html)
+	}
+	if strings.Contains(html, "+ action</button>") || strings.Contains(html, "New tag: Action") {
+		t.Fatalf("did not expect a suggestion for a tag the tracker carries, got %s", html)
+	}
+
+	createFromGenre := func(genre string) string {
+		t.Helper()
+		form := url.Values{}
+		form.Set("genre", genre)
+		req := httptest.NewRequest(http.MethodPost, "/dashboard/profile/tags/from-genre?profile=profile1", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("create tag from genre request failed: %v", err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", res.StatusCode)
+		}
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+
+	created := createFromGenre("Romance")
+	if !strings.Contains(created, `name="tag_ids"`) || !strings.Contains(created, "checked") || !strings.Contains(created, "Romance") {
+		t.Fatalf("expected a ticked checkbox for the new tag, got %s", created)
+	}
+	reused := createFromGenre(" romance! ")
+	if !strings.Contains(reused, "Romance") {
+		t.Fatalf("expected the matching tag to be reused, got %s", reused)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(1) FROM custom_tags WHERE profile_id = 1 AND name LIKE 'romance%'`).Scan(&count); err != nil {
+		t.Fatalf("count tags: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected one Romance tag, got %d", count)
+	}
+}
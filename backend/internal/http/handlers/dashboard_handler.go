@@ -3,48 +3,67 @@ package handlers
 import (
 	"database/sql"
 	"html/template"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
 	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/stats"
 )
 
 type DashboardHandler struct {
-	trackerRepo        *repository.TrackerRepository
-	sourceRepo         *repository.SourceRepository
-	profileRepo        *repository.ProfileRepository
-	profileResolver    *profileContextResolver
-	registry           *connectors.Registry
-	coverCache         map[string]coverCacheEntry
-	cacheMu            sync.RWMutex
-	coverFetchMu       sync.Mutex
-	coverInFlight      map[string]bool
-	coverFetchSem      chan struct{}
-	mangafireCoverSem  chan struct{}
-	chapterURLCache    map[string]chapterURLCacheEntry
-	chapterURLCacheMu  sync.RWMutex
-	chapterURLFetchMu  sync.Mutex
-	chapterURLInFlight map[string]bool
-	chapterURLFetchSem chan struct{}
-	activePageMu       sync.RWMutex
-	activePageKey      string
-	templates          *template.Template
-	templateOnce       sync.Once
-	templateErr        error
-}
-
-type coverCacheEntry struct {
-	CoverURL  string
-	Found     bool
-	ExpiresAt time.Time
-}
-
-type chapterURLCacheEntry struct {
-	ChapterURL string
-	Found      bool
-	ExpiresAt  time.Time
+	trackerRepo       *repository.TrackerRepository
+	sourceRepo        *repository.SourceRepository
+	profileRepo       *repository.ProfileRepository
+	digestRepo        *repository.DigestRepository
+	settingsRepo      *repository.SettingsRepository
+	filterPresetRepo  *repository.FilterPresetRepository
+	profileResolver   *profileContextResolver
+	registry          Resolver
+	basePath          string
+	covers            *CoverService
+	chapterURLs       *ChapterURLService
+	servicesOnce      sync.Once
+	enrichmentRetries *enrichmentRetryQueue
+	editForms         *editFormMemo
+	summaryChips      *summaryChipsCache
+	pollStatus        PollStatusReader
+	activePageMu      sync.RWMutex
+	activePageKey     string
+	templates         *template.Template
+	templateOnce      sync.Once
+	templateErr       error
+
+	// recentAdditionsDays is the recently added strip's window; 0 hides it.
+	recentAdditionsDays int
+	// debugTools shows troubleshooting views such as the filter explanation.
+	debugTools bool
+
+	// The lookup state covers and chapterURLs run on. It is kept here so a
+	// handler built field by field still gets the services; see
+	// coverService.
+	links                *linkcache.Resolver
+	coverFetchMu         sync.Mutex
+	coverInFlight        map[string]bool
+	coverFetchQueue      *fetchQueue
+	mangafireCoverQueue  *fetchQueue
+	chapterURLFetchMu    sync.Mutex
+	chapterURLInFlight   map[string]bool
+	chapterURLFetchQueue *fetchQueue
+}
+
+// Resolver is the part of the connector registry the dashboard uses.
+// *connectors.Registry implements it; tests can pass a fake instead.
+type Resolver interface {
+	Get(key string) (connectors.Connector, bool)
+	ValidateSourceURL(sourceKey string, rawURL string) (string, error)
+	SearchPageURL(sourceKey string, query string) string
+	LanguageAware(sourceKey string) bool
+	URLBelongsTo(sourceKey string, rawURL string) bool
+	MediaURL(sourceKey string, rawURL string) string
 }
 
 var allowedTagIconKeys = map[string]bool{
@@ -58,12 +77,36 @@ var tagIconKeysOrdered = []string{"icon_1", "icon_2", "icon_3"}
 type dashboardPageData struct {
 	Statuses              []string
 	Sorts                 []string
+	ViewModes             []string
 	Profiles              []models.Profile
 	ActiveProfile         models.Profile
 	RenameValue           string
 	ProfileTags           []models.CustomTag
 	LinkedSites           []models.Source
 	SelectedLinkedSiteIDs map[int64]bool
+	ScrapingPaused        bool
+	LogoutEnabled         bool
+	// RecentAdditions shows the recently added strip above the trackers.
+	RecentAdditions bool
+	// ReadOnly hides the controls that change data; see ReadOnlyMode.
+	ReadOnly bool
+
+	// Filters prefill the filter form from the page URL, such as a preset
+	// chip's link. SelectedTags are the tag checkboxes to check, by tag
+	// name, and ExtraTagTerms the tag terms no checkbox stands for, such
+	// as "a|b", kept as hidden inputs.
+	Filters       models.FilterPresetParams
+	SelectedTags  map[string]bool
+	ExtraTagTerms []string
+	// IgnoredTags and IgnoredSites name the URL's tags the profile no
+	// longer has and its sites no longer enabled, left out of Filters.
+	IgnoredTags  []string
+	IgnoredSites []string
+	// FilterPresets are the saved presets shown as chips in the header.
+	FilterPresets filterPresetsData
+	// SortDefaultLabels name the sort each status opens with when the
+	// filter form names none, for the sort select's default option.
+	SortDefaultLabels map[string]string
 }
 
 type trackersPartialData struct {
@@ -80,6 +123,18 @@ type trackersPartialData struct {
 	HasNextPage   bool
 	PendingCovers bool
 	RefreshKey    string
+	// IgnoredTags are requested tag filters that no longer exist and were
+	// left out of the query.
+	IgnoredTags []string
+
+	// When no trackers are listed, IsEmptyLibrary tells a profile with no
+	// trackers at all apart from filters that match nothing, and
+	// ClearFiltersURL reloads the dashboard with only the profile and view
+	// settings kept.
+	IsEmptyLibrary   bool
+	HasActiveFilters bool
+	ClearFiltersURL  string
+	ReadOnly         bool
 }
 
 type trackerOOBResponseData struct {
@@ -87,35 +142,96 @@ type trackerOOBResponseData struct {
 	ReplaceCard     *trackerCardView
 	PrependCard     *trackerCardView
 	DeleteTrackerID int64
+	// Announcement is read out through the page's aria-live region; see
+	// announceTrackerMutation.
+	Announcement string
 }
 
+// trackerCardView is the computed card data shared by the HTML card templates
+// and the /v1/trackers/:id/card JSON endpoint.
 type trackerCardView struct {
-	ID                     int64
-	Title                  string
-	Status                 string
-	StatusLabel            string
-	Tags                   []trackerTagView
-	HiddenTagCount         int
-	TagIcons               []trackerTagIconView
-	SourceURL              string
-	LatestKnownChapterURL  string
-	LastReadChapterURL     string
-	CoverURL               string
-	SourceLogoURL          string
-	SourceLogoLabel        string
-	LatestKnownChapter     string
-	LatestReleaseAgo       string
-	LastCheckedAgo         string
-	LastReadChapter        string
-	LastReadAgo            string
-	RatingLabel            string
-	LatestReleaseFormatted string
-	UpdatedAtFormatted     string
-	LastCheckedFormatted   string
-	SourceItemID           *string
-	Rating                 *float64
-	LatestKnownChapterRaw  *float64
-	LastReadChapterRaw     *float64
+	ID                     int64                `json:"id"`
+	Title                  string               `json:"title"`
+	Status                 string               `json:"status"`
+	StatusLabel            string               `json:"statusLabel"`
+	Tags                   []trackerTagView     `json:"tags"`
+	HiddenTagCount         int                  `json:"hiddenTagCount"`
+	TagIcons               []trackerTagIconView `json:"tagIcons"`
+	SourceID               int64                `json:"sourceId"`
+	SourceURL              string               `json:"sourceUrl"`
+	LatestKnownChapterURL  string               `json:"latestKnownChapterUrl"`
+	LastReadChapterURL     string               `json:"lastReadChapterUrl"`
+	CoverURL               string               `json:"coverUrl"`
+	ThumbnailURL           string               `json:"thumbnailUrl"`
+	SourceLogoURL          string               `json:"sourceLogoUrl"`
+	SourceLogoLabel        string               `json:"sourceLogoLabel"`
+	SourceStatusNote       string               `json:"sourceStatusNote,omitempty"`
+	LatestKnownChapter     string               `json:"latestKnownChapter"`
+	LatestReleaseAgo       string               `json:"latestReleaseAgo"`
+	LatestReleaseAgoShort  string               `json:"latestReleaseAgoShort"`
+	LastCheckedAgo         string               `json:"lastCheckedAgo"`
+	LastReadChapter        string               `json:"lastReadChapter"`
+	LastReadAgo            string               `json:"lastReadAgo"`
+	LastReadAgoShort       string               `json:"lastReadAgoShort"`
+	RatingLabel            string               `json:"ratingLabel"`
+	LatestReleaseFormatted string               `json:"latestReleaseFormatted"`
+	UpdatedAtFormatted     string               `json:"updatedAtFormatted"`
+	LastCheckedFormatted   string               `json:"lastCheckedFormatted"`
+	SourceItemID           *string              `json:"sourceItemId"`
+	Rating                 *float64             `json:"rating"`
+	LatestKnownChapterRaw  *float64             `json:"latestKnownChapterRaw"`
+	LastReadChapterRaw     *float64             `json:"lastReadChapterRaw"`
+	UnreadChapters         *float64             `json:"unreadChapters"`
+	LatestReleaseAtRaw     *time.Time           `json:"latestReleaseAt"`
+	LastCheckedAtRaw       *time.Time           `json:"lastCheckedAt"`
+	LastReadAtRaw          *time.Time           `json:"lastReadAt"`
+	UpdatedAtRaw           time.Time            `json:"updatedAt"`
+
+	// ContinuedByTitle and ContinuedByURL describe the tracker that carries
+	// the series on; both are empty when there is none.
+	ContinuedByID    int64  `json:"continuedByTrackerId,omitempty"`
+	ContinuedByTitle string `json:"continuedByTitle,omitempty"`
+	ContinuedByURL   string `json:"continuedByUrl,omitempty"`
+
+	// SourceBlacklisted is set when the profile has blacklisted the
+	// tracker's primary source; the tracker itself works as usual.
+	SourceBlacklisted bool `json:"sourceBlacklisted,omitempty"`
+
+	// Rereading is set while a completed series is read again;
+	// RereadNumber counts that read-through, from 2 for the first re-read.
+	Rereading    bool `json:"rereading,omitempty"`
+	RereadNumber int  `json:"rereadNumber,omitempty"`
+
+	// LatestReleaseUpcoming is set when the stored release time is still
+	// ahead, so the card reads "releases in" rather than "released".
+	// NextScheduledLabel names the chapter the source announced next and
+	// when it comes out, such as "Ch. 121 in 18 hours"; it is empty when
+	// none is due.
+	LatestReleaseUpcoming bool   `json:"latestReleaseUpcoming,omitempty"`
+	NextScheduledLabel    string `json:"nextScheduledLabel,omitempty"`
+
+	// Position is the card's place on the trackers page, from 1, out of
+	// SetSize cards; both are 0 for a card rendered on its own into a page
+	// already shown. TabIndex gives the page a single tab stop among the
+	// cards.
+	Position int `json:"-"`
+	SetSize  int `json:"-"`
+	TabIndex int `json:"-"`
+
+	// NeverChecked marks a tracker with no chapter data that no lookup has
+	// gone through for yet, as opposed to one checked without finding any.
+	NeverChecked bool `json:"neverChecked"`
+
+	// ChaptersSinceVisit counts the chapters released since the profile's
+	// previous dashboard visit; NewSinceLastVisit is set when there are any.
+	ChaptersSinceVisit int  `json:"chaptersSinceVisit"`
+	NewSinceLastVisit  bool `json:"newSinceLastVisit"`
+
+	// The pending flags are set while the chapter URL or cover is still
+	// being resolved in the background and the field holds a fallback.
+	LatestKnownChapterURLPending bool `json:"latestKnownChapterUrlPending"`
+	LastReadChapterURLPending    bool `json:"lastReadChapterUrlPending"`
+	CoverPending                 bool `json:"coverPending"`
 }
 
 type trackerSiteLinkView struct {
@@ -125,15 +241,15 @@ type trackerSiteLinkView struct {
 }
 
 type trackerTagView struct {
-	ID       int64
-	Name     string
-	IconKey  *string
-	IconPath *string
+	ID       int64   `json:"id"`
+	Name     string  `json:"name"`
+	IconKey  *string `json:"iconKey"`
+	IconPath *string `json:"iconPath"`
 }
 
 type trackerTagIconView struct {
-	TagName  string
-	IconPath string
+	TagName  string `json:"tagName"`
+	IconPath string `json:"iconPath"`
 }
 
 type trackerFormData struct {
@@ -145,6 +261,61 @@ type trackerFormData struct {
 	ProfileTags   []models.CustomTag
 	TrackerTags   []models.CustomTag
 	TagIconKeys   []string
+
+	// GenreSuggestions offer tags from the tracker's source genres in the
+	// edit form.
+	GenreSuggestions []genreTagSuggestion
+
+	// LanguageSourceIDs are the sources whose linked sites offer a language
+	// choice.
+	LanguageSourceIDs []int64
+
+	// BlacklistedSourceIDs are the sources the profile has blacklisted. They
+	// are left out of the source pickers unless the tracker already uses
+	// them.
+	BlacklistedSourceIDs map[int64]bool
+
+	// ManualSource is set when the tracker's primary source is a manual one,
+	// so the edit form offers logging a new chapter by hand.
+	ManualSource bool
+
+	// DebugTools offers the filter explanation in the edit form.
+	DebugTools bool
+
+	// ConfirmPrimarySwitch is set when saving would move the primary source
+	// away from the one chosen in the form; the re-rendered form then posts
+	// confirm_primary_switch=1 to apply PrimarySwitchSummary.
+	ConfirmPrimarySwitch bool
+	PrimarySwitchSummary string
+
+	// PrefillSourceURL and PrefillSourceID seed a new tracker from the
+	// quick-add field shown while the library is empty.
+	PrefillSourceURL string
+	PrefillSourceID  int64
+
+	// URLSuggestion is offered when a new tracker's URL did not resolve but
+	// a title search on the same source found a close match; the re-rendered
+	// form then posts url_suggestion=accept to use it or keep to save the URL
+	// as entered.
+	URLSuggestion *connectors.MangaResult
+
+	// Continuation is the tracker this one continues in, if any; otherwise
+	// ContinuationSuggestion may offer one whose title reads like a sequel.
+	Continuation           *trackerContinuationOption
+	ContinuationSuggestion *trackerContinuationOption
+
+	// ReadSources counts, per source, how often the last read chapter
+	// advanced from that source's links.
+	ReadSources []models.ReadSourceCount
+	// ReadingHistory is the tracker's chapters read per week, drawn as a
+	// sparkline.
+	ReadingHistory []stats.ReadingWeek
+	// ReleaseCadence is the series' chapters released per month over the
+	// last year, drawn as bars, and its pace.
+	ReleaseCadence releaseCadence
+
+	// AutofocusID is the id of the element focused once the modal opens.
+	AutofocusID string
 }
 
 type trackerSearchResultsData struct {
@@ -154,6 +325,9 @@ type trackerSearchResultsData struct {
 	SourceID   int64
 	SourceName string
 	Intent     string
+	// SearchPageURL opens the source's own search pre-filled with Query;
+	// empty when the connector has no search page link.
+	SearchPageURL string
 }
 
 type profileMenuData struct {
@@ -162,14 +336,43 @@ type profileMenuData struct {
 	RenameValue       string
 	LinkedSites       []models.Source
 	SourceLogoURLs    map[int64]string
+	BlacklistedSites  map[int64]bool
 	ProfileTags       []models.CustomTag
+	TagUsageCounts    map[int64]int
+	UnusedTagCount    int
 	TagIconKeys       []string
 	AvailableIconKeys []string
+	Digest            profileDigestView
+	DigestHours       []int
+	Polling           profilePollingView
 	Message           string
+	// SortDefaults are the per-status rows of the default sort form, which
+	// offers SortDefaultSorts.
+	SortDefaults     []profileSortDefaultView
+	SortDefaultSorts []string
+	// AutofocusID is the id of the element focused once the modal opens.
+	AutofocusID string
+}
+
+type profilePollingView struct {
+	Enabled bool
+	// Summary reads like "polling: on, last full check 2h ago".
+	Summary string
+}
+
+type profileDigestView struct {
+	Email         string
+	HourUTC       int
+	Enabled       bool
+	LastSentLabel string
+	// IncludeTagID and ExcludeTagID are the selected filter tags, 0 for none.
+	IncludeTagID int64
+	ExcludeTagID int64
 }
 
 type profileFilterTagsData struct {
-	ProfileTags []models.CustomTag
+	ProfileTags  []models.CustomTag
+	SelectedTags map[string]bool
 }
 
 type profileFilterLinkedSitesData struct {
@@ -177,22 +380,78 @@ type profileFilterLinkedSitesData struct {
 	SelectedSourceIDs map[int64]bool
 }
 
-func NewDashboardHandler(db *sql.DB, registry *connectors.Registry) *DashboardHandler {
+func NewDashboardHandler(db *sql.DB, registry *connectors.Registry, basePath string) *DashboardHandler {
 	if registry == nil {
 		registry = connectors.NewRegistry()
 	}
-	return &DashboardHandler{
-		trackerRepo:        repository.NewTrackerRepository(db),
-		sourceRepo:         repository.NewSourceRepository(db),
-		profileRepo:        repository.NewProfileRepository(db),
-		profileResolver:    newProfileContextResolver(db),
-		registry:           registry,
-		coverCache:         make(map[string]coverCacheEntry),
-		coverInFlight:      make(map[string]bool),
-		coverFetchSem:      make(chan struct{}, 8),
-		mangafireCoverSem:  make(chan struct{}, 3),
-		chapterURLCache:    make(map[string]chapterURLCacheEntry),
-		chapterURLInFlight: make(map[string]bool),
-		chapterURLFetchSem: make(chan struct{}, 10),
+	return NewDashboardHandlerWithResolver(db, registry, basePath)
+}
+
+// NewDashboardHandlerWithResolver builds the dashboard over any Resolver,
+// such as a fake in tests.
+func NewDashboardHandlerWithResolver(db *sql.DB, resolver Resolver, basePath string) *DashboardHandler {
+	if resolver == nil {
+		resolver = connectors.NewRegistry()
+	}
+	h := &DashboardHandler{
+		trackerRepo:     repository.NewTrackerRepository(db),
+		sourceRepo:      repository.NewSourceRepository(db),
+		profileRepo:     repository.NewProfileRepository(db),
+		digestRepo:      repository.NewDigestRepository(db),
+		settingsRepo:    repository.NewSettingsRepository(db),
+		profileResolver: newProfileContextResolver(db),
+		registry:        resolver,
+		basePath:        strings.TrimRight(strings.TrimSpace(basePath), "/"),
+		editForms:       newEditFormMemo(editFormMemoTTL),
+		summaryChips:    newSummaryChipsCache(summaryChipsTTL),
+
+		filterPresetRepo: repository.NewFilterPresetRepository(db),
+
+		coverInFlight:        make(map[string]bool),
+		coverFetchQueue:      newFetchQueue(8),
+		mangafireCoverQueue:  newFetchQueue(3),
+		chapterURLInFlight:   make(map[string]bool),
+		chapterURLFetchQueue: newFetchQueue(10),
+	}
+	h.links = linkcache.NewResolver(resolver, repository.NewLinkCacheRepository(db), h.scrapingAllowed)
+	h.enrichmentRetries = newEnrichmentRetryQueue(enrichmentRetryDelays, h.retryTrackerEnrichment, h.giveUpTrackerEnrichment)
+	return h
+}
+
+// coverService returns the card cover service, or nil when the handler has
+// no cover lookup state.
+func (h *DashboardHandler) coverService() *CoverService {
+	h.servicesOnce.Do(h.buildServices)
+	return h.covers
+}
+
+// chapterURLService returns the chapter link service, or nil when the
+// handler has no chapter URL lookup state.
+func (h *DashboardHandler) chapterURLService() *ChapterURLService {
+	h.servicesOnce.Do(h.buildServices)
+	return h.chapterURLs
+}
+
+// buildServices builds the services the handler was not given over its own
+// lookup state, so the two stay in step.
+func (h *DashboardHandler) buildServices() {
+	if h.links == nil {
+		return
+	}
+	if h.covers == nil && h.coverInFlight != nil && h.coverFetchQueue != nil {
+		mangafireQueue := h.mangafireCoverQueue
+		if mangafireQueue == nil {
+			mangafireQueue = h.coverFetchQueue
+		}
+		h.covers = newCoverService(h.links, CoverServiceConfig{
+			Allowed:      h.scrapingAllowed,
+			IsActivePage: h.isActiveTrackersPageKey,
+		}, &h.coverFetchMu, h.coverInFlight, h.coverFetchQueue, mangafireQueue)
+	}
+	if h.chapterURLs == nil && h.chapterURLInFlight != nil && h.chapterURLFetchQueue != nil {
+		h.chapterURLs = newChapterURLService(h.links, ChapterURLServiceConfig{
+			Allowed:      h.scrapingAllowed,
+			IsActivePage: h.isActiveTrackersPageKey,
+		}, &h.chapterURLFetchMu, h.chapterURLInFlight, h.chapterURLFetchQueue)
 	}
 }
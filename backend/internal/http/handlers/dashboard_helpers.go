@@ -3,47 +3,105 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"html/template"
+	"log/slog"
 	"math"
-	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
+	"github.com/gabriel/cross-site-tracker/backend/internal/timefmt"
 	"github.com/gofiber/fiber/v2"
 )
 
 var valueLabelReplacer = strings.NewReplacer("_", " ", "-", " ")
 
-func parseTagNames(raw string) []string {
+// parseTagFilters reads a tags parameter: comma-separated terms are ANDed,
+// pipes within a term are ORed ("favorite|priority") and a leading minus
+// excludes the term's tags ("-stale"). Dashes inside a name are kept, and
+// dropUnknownTagFilters reads "-name" as a tag name when the profile has a
+// tag by that name but none without the dash.
+func parseTagFilters(raw string) []repository.TagFilter {
 	if strings.TrimSpace(raw) == "" {
 		return nil
 	}
 
 	parts := strings.Split(raw, ",")
-	out := make([]string, 0, len(parts))
+	out := make([]repository.TagFilter, 0, len(parts))
 	seen := make(map[string]struct{}, len(parts))
 	for _, part := range parts {
-		tag := strings.TrimSpace(part)
-		normalized := strings.ToLower(tag)
-		if normalized == "" {
+		term := strings.TrimSpace(part)
+		exclude := false
+		if len(term) > 1 && term[0] == '-' {
+			exclude = true
+			term = term[1:]
+		}
+
+		names := make([]string, 0)
+		seenNames := make(map[string]struct{})
+		for _, alternative := range strings.Split(term, "|") {
+			name := strings.TrimSpace(alternative)
+			normalized := searchutil.NormalizeTagName(name)
+			if normalized == "" {
+				continue
+			}
+			if _, exists := seenNames[normalized]; exists {
+				continue
+			}
+			seenNames[normalized] = struct{}{}
+			names = append(names, name)
+		}
+		if len(names) == 0 {
 			continue
 		}
-		if _, exists := seen[normalized]; exists {
+
+		key := tagFilterKey(repository.TagFilter{AnyOf: names, Exclude: exclude})
+		if _, exists := seen[key]; exists {
 			continue
 		}
-		seen[normalized] = struct{}{}
-		out = append(out, tag)
+		seen[key] = struct{}{}
+		out = append(out, repository.TagFilter{AnyOf: names, Exclude: exclude})
 	}
 	return out
 }
 
-func parseTagNamesFromQuery(c *fiber.Ctx) []string {
-	queryValues := c.Context().QueryArgs().PeekMulti("tags")
+// tagFilterKey identifies a filter regardless of name case, accents and
+// order.
+func tagFilterKey(filter repository.TagFilter) string {
+	names := make([]string, 0, len(filter.AnyOf))
+	for _, name := range filter.AnyOf {
+		names = append(names, searchutil.NormalizeTagName(name))
+	}
+	slices.Sort(names)
+	key := strings.Join(names, "|")
+	if filter.Exclude {
+		key = "-" + key
+	}
+	return key
+}
+
+// filterArgs is the query string or urlencoded form body of a request, so
+// the tracker filters can be read from either.
+type filterArgs interface {
+	Peek(key string) []byte
+	PeekMulti(key string) [][]byte
+}
+
+func parseTagFiltersFromQuery(c *fiber.Ctx) []repository.TagFilter {
+	return parseTagFiltersFromArgs(c.Context().QueryArgs())
+}
+
+// parseTagFiltersFromArgs ANDs repeated tags parameters, each parsed like a
+// single comma-separated one.
+func parseTagFiltersFromArgs(args filterArgs) []repository.TagFilter {
+	queryValues := args.PeekMulti("tags")
 	if len(queryValues) == 0 {
-		return parseTagNames(c.Query("tags"))
+		return parseTagFilters(string(args.Peek("tags")))
 	}
 
 	values := make([]string, 0, len(queryValues))
@@ -59,7 +117,54 @@ func parseTagNamesFromQuery(c *fiber.Ctx) []string {
 		return nil
 	}
 
-	return parseTagNames(strings.Join(values, ","))
+	return parseTagFilters(strings.Join(values, ","))
+}
+
+// dropUnknownTagFilters removes requested tag names that match none of the
+// profile's tags, such as a bookmarked tag that was since deleted, and
+// returns the removed names. Without this a stale tag filter silently
+// matches nothing. A term left without names is dropped.
+func dropUnknownTagFilters(ctx context.Context, repo *repository.TrackerRepository, options *repository.TrackerListOptions) ([]string, error) {
+	ignored := make([]string, 0)
+	if len(options.TagFilters) == 0 {
+		return ignored, nil
+	}
+
+	profileTags, err := repo.ListProfileTags(ctx, options.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]struct{}, len(profileTags))
+	for _, tag := range profileTags {
+		existing[searchutil.NormalizeTagName(tag.Name)] = struct{}{}
+	}
+	known := func(name string) bool {
+		_, ok := existing[searchutil.NormalizeTagName(name)]
+		return ok
+	}
+
+	filters := make([]repository.TagFilter, 0, len(options.TagFilters))
+	for _, filter := range options.TagFilters {
+		// "-name" is a tag of that name rather than an exclusion when only
+		// the dashed name exists.
+		if filter.Exclude && len(filter.AnyOf) == 1 && !known(filter.AnyOf[0]) && known("-"+filter.AnyOf[0]) {
+			filter = repository.TagFilter{AnyOf: []string{"-" + filter.AnyOf[0]}}
+		}
+
+		names := make([]string, 0, len(filter.AnyOf))
+		for _, name := range filter.AnyOf {
+			if known(name) {
+				names = append(names, name)
+			} else {
+				ignored = append(ignored, name)
+			}
+		}
+		if len(names) > 0 {
+			filters = append(filters, repository.TagFilter{AnyOf: names, Exclude: filter.Exclude})
+		}
+	}
+	options.TagFilters = filters
+	return ignored, nil
 }
 
 func parseSourceIDs(raw string) []int64 {
@@ -86,9 +191,13 @@ func parseSourceIDs(raw string) []int64 {
 }
 
 func parseSourceIDsFromQuery(c *fiber.Ctx) []int64 {
-	queryValues := c.Context().QueryArgs().PeekMulti("sites")
+	return parseSourceIDsFromArgs(c.Context().QueryArgs())
+}
+
+func parseSourceIDsFromArgs(args filterArgs) []int64 {
+	queryValues := args.PeekMulti("sites")
 	if len(queryValues) == 0 {
-		return parseSourceIDs(c.Query("sites"))
+		return parseSourceIDs(string(args.Peek("sites")))
 	}
 
 	values := make([]string, 0, len(queryValues))
@@ -118,19 +227,6 @@ func sourceIDFilterMap(sourceIDs []int64) map[int64]bool {
 	return ids
 }
 
-func toTrackerTagView(tags []models.CustomTag) []trackerTagView {
-	items := make([]trackerTagView, 0, len(tags))
-	for _, tag := range tags {
-		items = append(items, trackerTagView{
-			ID:       tag.ID,
-			Name:     tag.Name,
-			IconKey:  tag.IconKey,
-			IconPath: tag.IconPath,
-		})
-	}
-	return items
-}
-
 func toTrackerTagIcons(tags []models.CustomTag) []trackerTagIconView {
 	icons := make([]trackerTagIconView, 0, len(tags))
 	for _, tag := range tags {
@@ -163,34 +259,73 @@ func sourceHomeURLForKey(sourceKey string) string {
 	}
 }
 
-func prioritizeTrackerTags(tags []trackerTagView, maxVisible int) ([]trackerTagView, int) {
+// displayTrackerTags returns up to maxVisible of a card's tags, those with
+// an icon first, and how many were left out.
+func displayTrackerTags(tags []models.CustomTag, maxVisible int) ([]trackerTagView, int) {
+	// An empty slice rather than nil, so card JSON lists no tags as [].
 	if maxVisible <= 0 || len(tags) == 0 {
-		return nil, len(tags)
-	}
-
-	withIcon := make([]trackerTagView, 0, len(tags))
-	withoutIcon := make([]trackerTagView, 0, len(tags))
-	for _, tag := range tags {
-		if tag.IconPath != nil {
-			withIcon = append(withIcon, tag)
-			continue
+		return []trackerTagView{}, len(tags)
+	}
+
+	shown := make([]trackerTagView, 0, min(len(tags), maxVisible))
+	for _, withIcon := range []bool{true, false} {
+		for _, tag := range tags {
+			if len(shown) == maxVisible {
+				return shown, len(tags) - len(shown)
+			}
+			if (tag.IconPath != nil) != withIcon {
+				continue
+			}
+			shown = append(shown, trackerTagView{
+				ID:       tag.ID,
+				Name:     tag.Name,
+				IconKey:  tag.IconKey,
+				IconPath: tag.IconPath,
+			})
 		}
-		withoutIcon = append(withoutIcon, tag)
 	}
+	return shown, len(tags) - len(shown)
+}
 
-	ordered := make([]trackerTagView, 0, len(tags))
-	ordered = append(ordered, withIcon...)
-	ordered = append(ordered, withoutIcon...)
+func formatChapterLabel(chapter float64) string {
+	return "Ch. " + strconv.FormatFloat(chapter, 'f', -1, 64)
+}
 
-	if len(ordered) <= maxVisible {
-		return ordered, 0
+// linkedSourceReliability summarises a linked source's persisted poll
+// statistics; it is empty until the poller has resolved the source once.
+func linkedSourceReliability(source models.TrackerSource) string {
+	if source.SuccessCount+source.FailureCount == 0 {
+		return ""
 	}
+	if source.LastPollFailed {
+		return "✗ failing"
+	}
+	if source.LastLagChapters != nil && *source.LastLagChapters > 1e-9 {
+		return "~ lags by " + strconv.FormatFloat(*source.LastLagChapters, 'f', -1, 64)
+	}
+	return "✓ fresh"
+}
 
-	return ordered[:maxVisible], len(ordered) - maxVisible
+// unreadChapters is how far the latest known chapter is ahead of the last
+// read one; nil when the latest chapter is unknown.
+func unreadChapters(latest *float64, lastRead *float64) *float64 {
+	if latest == nil {
+		return nil
+	}
+	unread := *latest
+	if lastRead != nil {
+		unread = math.Max(0, *latest-*lastRead)
+	}
+	return &unread
 }
 
-func formatChapterLabel(chapter float64) string {
-	return "Ch. " + strconv.FormatFloat(chapter, 'f', -1, 64)
+// unreadBadgeLabel formats the wall tile's unread count, or "" when the
+// tracker is caught up or has no known chapter.
+func unreadBadgeLabel(unread *float64) string {
+	if unread == nil || *unread <= 1e-9 {
+		return ""
+	}
+	return "+" + strconv.FormatFloat(*unread, 'f', -1, 64)
 }
 
 func formatRatingLabel(rating float64) string {
@@ -221,201 +356,146 @@ func timeInputValue(value *time.Time) string {
 	return value.UTC().Format(time.RFC3339)
 }
 
-func relativeTime(value time.Time) string {
-	now := time.Now().UTC()
-	target := value.UTC()
-	if target.After(now) {
-		return "just now"
-	}
-
-	delta := now.Sub(target)
-	if delta < time.Minute {
-		return "just now"
-	}
-	if delta < time.Hour {
-		minutes := int(delta / time.Minute)
-		return fmt.Sprintf("%d min ago", minutes)
-	}
-	if delta < 24*time.Hour {
-		hours := int(delta / time.Hour)
-		return fmt.Sprintf("%d hours ago", hours)
-	}
-	if delta < 30*24*time.Hour {
-		days := int(delta / (24 * time.Hour))
-		return fmt.Sprintf("%d days ago", days)
-	}
-	if delta < 365*24*time.Hour {
-		months := int(delta / (30 * 24 * time.Hour))
-		return fmt.Sprintf("%d months ago", months)
-	}
-	years := int(delta / (365 * 24 * time.Hour))
-	return fmt.Sprintf("%d years ago", years)
-}
-
-func (h *DashboardHandler) fetchCoverURL(parent context.Context, sourceKey, sourceURL string, sourceItemID *string) (string, error) {
-	trimmedSourceKey := strings.TrimSpace(sourceKey)
-	if trimmedSourceKey == "" {
-		return "", fmt.Errorf("missing source key")
-	}
-
-	cacheKey := buildCoverCacheKey(trimmedSourceKey, sourceURL, sourceItemID)
-	if cachedURL, found, ok := h.getCachedCover(cacheKey); ok {
-		if found {
-			return cachedURL, nil
-		}
-		return "", fmt.Errorf("cover not found")
-	}
-
-	resolvedURL := strings.TrimSpace(sourceURL)
-	if resolvedURL == "" {
-		h.setCachedCover(cacheKey, "", false, 2*time.Minute)
-		return "", fmt.Errorf("missing source url")
-	}
-
-	tryKeys := make([]string, 0, 2)
-	tryKeys = append(tryKeys, trimmedSourceKey)
-
-	if fallbackKey := inferSourceKeyFromURL(resolvedURL); fallbackKey != "" && fallbackKey != trimmedSourceKey {
-		tryKeys = append(tryKeys, fallbackKey)
-	}
-
-	for _, key := range tryKeys {
-		coverURL, err := h.resolveCoverFromConnector(parent, key, resolvedURL)
-		if err != nil {
-			continue
-		}
-		if coverURL == "" {
-			continue
-		}
-
-		h.setCachedCover(cacheKey, coverURL, true, 12*time.Hour)
-		return coverURL, nil
+func milestoneDate(value *time.Time) string {
+	if value == nil {
+		return "—"
 	}
-
-	h.setCachedCover(cacheKey, "", false, 2*time.Minute)
-	return "", fmt.Errorf("cover not found")
+	return value.UTC().Format("Jan 2, 2006")
 }
 
-func (h *DashboardHandler) resolveCoverFromConnector(parent context.Context, sourceKey, sourceURL string) (string, error) {
-	connector, ok := h.registry.Get(strings.TrimSpace(sourceKey))
-	if !ok {
-		return "", fmt.Errorf("connector not found")
-	}
-
-	resolveTimeout := 8 * time.Second
-	if key := strings.ToLower(strings.TrimSpace(sourceKey)); key == "mangafire" || key == "freewebnovel" {
-		resolveTimeout = 15 * time.Second
-	}
-	ctx, cancel := context.WithTimeout(parent, resolveTimeout)
-	defer cancel()
-
-	result, err := connector.ResolveByURL(ctx, sourceURL)
-	if err != nil {
-		return "", err
-	}
-	if result == nil {
-		return "", fmt.Errorf("empty result")
+// timeAgo formats value relative to now, e.g. "3 days ago", or "—" when
+// unset.
+func timeAgo(value *time.Time) string {
+	if value == nil {
+		return "—"
 	}
-
-	return strings.TrimSpace(result.CoverImageURL), nil
+	return timefmt.FromNow(*value, timefmt.Long)
 }
 
-func inferSourceKeyFromURL(rawURL string) string {
-	parsed, err := url.Parse(strings.TrimSpace(rawURL))
-	if err != nil {
+// readingSpan describes how long it took from the first recorded read to
+// catching up, or "" until both milestones exist.
+func readingSpan(firstReadAt *time.Time, caughtUpAt *time.Time) string {
+	if firstReadAt == nil || caughtUpAt == nil || caughtUpAt.Before(*firstReadAt) {
 		return ""
 	}
-
-	host := strings.ToLower(strings.TrimSpace(parsed.Hostname()))
+	days := int(caughtUpAt.Sub(*firstReadAt).Hours() / 24)
 	switch {
-	case strings.Contains(host, "mangadex"):
-		return "mangadex"
-	case strings.Contains(host, "mangafire"):
-		return "mangafire"
-	case strings.Contains(host, "mgeko"):
-		return "mgeko"
-	case strings.Contains(host, "asura"):
-		return "asuracomic"
-	case strings.Contains(host, "flame"):
-		return "flamecomics"
-	case strings.Contains(host, "webtoons"):
-		return "webtoons"
-	case strings.Contains(host, "freewebnovel"):
-		return "freewebnovel"
+	case days < 1:
+		return "under a day"
+	case days < 60:
+		return pluralize(days, "day")
+	case days < 730:
+		return pluralize(days/30, "month")
 	default:
-		return ""
+		return pluralize(days/365, "year")
 	}
 }
 
-func buildCoverCacheKey(sourceKey, sourceURL string, sourceItemID *string) string {
-	itemID := ""
-	if sourceItemID != nil {
-		itemID = strings.TrimSpace(*sourceItemID)
+func pluralize(count int, unit string) string {
+	if count == 1 {
+		return "1 " + unit
 	}
+	return strconv.Itoa(count) + " " + unit + "s"
+}
 
-	base := strings.ToLower(strings.TrimSpace(sourceKey)) + "|"
-	if itemID != "" {
-		return base + "item:" + strings.ToLower(itemID)
+// scrapingAllowed returns connectors.ErrScrapingPaused while the global pause
+// switch is on. A failed settings read is logged and allows the request.
+func (h *DashboardHandler) scrapingAllowed() error {
+	if h.settingsRepo == nil {
+		return nil
 	}
-
-	trimmedURL := strings.TrimSpace(sourceURL)
-	if trimmedURL != "" {
-		return base + "url:" + strings.ToLower(trimmedURL)
+	paused, err := h.settingsRepo.ScrapingPaused()
+	if err != nil {
+		slog.Warn("read scraping pause state failed", "error", err)
+		return nil
+	}
+	if paused {
+		return connectors.ErrScrapingPaused
 	}
+	return nil
+}
 
-	return base + "missing"
+func (h *DashboardHandler) fetchCoverURL(parent context.Context, sourceKey, sourceURL string, sourceItemID *string) (string, error) {
+	return h.coverService().Fetch(parent, sourceKey, sourceURL, sourceItemID)
 }
 
-func (h *DashboardHandler) getCachedCover(titleID string) (coverURL string, found bool, ok bool) {
-	h.cacheMu.RLock()
-	entry, exists := h.coverCache[titleID]
-	h.cacheMu.RUnlock()
-	if !exists {
-		return "", false, false
-	}
+func (h *DashboardHandler) getCachedCover(cacheKey string) (coverURL string, found bool, ok bool) {
+	return h.coverService().Cached(cacheKey)
+}
 
-	if time.Now().UTC().After(entry.ExpiresAt) {
-		h.cacheMu.Lock()
-		delete(h.coverCache, titleID)
-		h.cacheMu.Unlock()
-		return "", false, false
-	}
+func (h *DashboardHandler) setCachedCover(cacheKey, coverURL string, found bool, ttl time.Duration) {
+	h.coverService().SetCached(cacheKey, coverURL, found, ttl)
+}
 
-	return entry.CoverURL, entry.Found, true
+// TemplatesGlob matches the dashboard templates, relative to the backend
+// directory the app runs from.
+const TemplatesGlob = "web/templates/*.html"
+
+// ParseTemplates parses the dashboard templates matched by glob for an app
+// mounted under basePath.
+func ParseTemplates(glob string, basePath string) (*template.Template, error) {
+	basePath = strings.TrimRight(strings.TrimSpace(basePath), "/")
+	return template.New("").Funcs(template.FuncMap{
+		"chapterInputValue": chapterInputValue,
+		"textInputValue":    textInputValue,
+		"timeInputValue":    timeInputValue,
+		"milestoneDate":     milestoneDate,
+		"readingSpan":       readingSpan,
+		"readingSparkline":  readingSparkline,
+		"cadenceChart":      cadenceChart,
+		"cadenceSummary":    cadenceSummary,
+		"timeAgo":           timeAgo,
+		"hasTagID":          hasTagID,
+		"tagIconLabel":      tagIconLabel,
+		"tagIconAssetPath":  tagIconAssetPath,
+		"toJSON":            toJSON,
+		"statusLabel":       statusLabel,
+		"sortLabel":         sortLabel,
+		"viewModeLabel":     humanizeValueLabel,
+		"unreadBadgeLabel":  unreadBadgeLabel,
+		"appURL":            func(path string) string { return appURL(basePath, path) },
+		"basePath":          func() string { return basePath },
+	}).ParseGlob(glob)
 }
 
-func (h *DashboardHandler) setCachedCover(titleID, coverURL string, found bool, ttl time.Duration) {
-	h.cacheMu.Lock()
-	h.coverCache[titleID] = coverCacheEntry{
-		CoverURL:  coverURL,
-		Found:     found,
-		ExpiresAt: time.Now().UTC().Add(ttl),
+// SetTemplates hands the handler templates parsed up front, such as by the
+// startup self-check, so the first render does not parse them again. It
+// has no effect once a page has rendered.
+func (h *DashboardHandler) SetTemplates(templates *template.Template) {
+	if templates == nil {
+		return
 	}
-	h.cacheMu.Unlock()
+	h.templateOnce.Do(func() {
+		h.templates = templates
+	})
 }
 
 func (h *DashboardHandler) render(c *fiber.Ctx, templateName string, data any) error {
 	h.templateOnce.Do(func() {
-		h.templates, h.templateErr = template.New("").Funcs(template.FuncMap{
-			"chapterInputValue": chapterInputValue,
-			"textInputValue":    textInputValue,
-			"timeInputValue":    timeInputValue,
-			"hasTagID":          hasTagID,
-			"tagIconLabel":      tagIconLabel,
-			"tagIconAssetPath":  tagIconAssetPath,
-			"toJSON":            toJSON,
-			"statusLabel":       statusLabel,
-			"sortLabel":         sortLabel,
-		}).ParseGlob("web/templates/*.html")
+		h.templates, h.templateErr = ParseTemplates(TemplatesGlob, h.basePath)
 	})
 
 	if h.templateErr != nil || h.templates == nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Template load error")
+		return serverError(c, "Template load error", h.templateErr)
 	}
 	c.Type("html", "utf-8")
 	return h.templates.ExecuteTemplate(c.Response().BodyWriter(), templateName, data)
 }
 
+// appURL prefixes a root-relative path with the configured base path so
+// generated links keep working when the app is mounted under a sub-path.
+// Absolute and protocol-relative URLs are returned unchanged.
+func (h *DashboardHandler) appURL(path string) string {
+	return appURL(h.basePath, path)
+}
+
+func appURL(basePath string, path string) string {
+	if basePath == "" || !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+		return path
+	}
+	return basePath + path
+}
+
 func statusLabel(value string) string {
 	switch strings.TrimSpace(strings.ToLower(value)) {
 	case "all":
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
 )
 
 func TestHasResolvedSourceMetadataRequiresReleaseDate(t *testing.T) {
@@ -37,13 +40,6 @@ func TestSourceHomeURLForKeySupportsMgeko(t *testing.T) {
 	}
 }
 
-func TestInferSourceKeyFromURLSupportsMgeko(t *testing.T) {
-	inferred := inferSourceKeyFromURL("https://www.mgeko.cc/manga/sample-series/")
-	if inferred != "mgeko" {
-		t.Fatalf("expected inferred source key mgeko, got %q", inferred)
-	}
-}
-
 func TestSourceHomeURLForKeySupportsFreeWebNovel(t *testing.T) {
 	homeURL := sourceHomeURLForKey("freewebnovel")
 	if homeURL != "https://freewebnovel.com" {
@@ -51,13 +47,6 @@ func TestSourceHomeURLForKeySupportsFreeWebNovel(t *testing.T) {
 	}
 }
 
-func TestInferSourceKeyFromURLSupportsFreeWebNovel(t *testing.T) {
-	inferred := inferSourceKeyFromURL("https://freewebnovel.com/novel/star-odyssey")
-	if inferred != "freewebnovel" {
-		t.Fatalf("expected inferred source key freewebnovel, got %q", inferred)
-	}
-}
-
 func TestBuildTrackerCardsDoesNotUseLastCheckedAtAsReleaseDate(t *testing.T) {
 	lastCheckedAt := time.Now().UTC()
 	h := &DashboardHandler{}
@@ -71,7 +60,7 @@ func TestBuildTrackerCardsDoesNotUseLastCheckedAtAsReleaseDate(t *testing.T) {
 	}}
 	sourceByID := map[int64]models.Source{1: {ID: 1, Name: "Example"}}
 
-	cards, pending := h.buildTrackerCards(items, sourceByID, map[int64]string{}, "")
+	cards, pending := h.buildTrackerCards(context.Background(), items, sourceByID, map[int64]string{}, "")
 	if pending {
 		t.Fatalf("expected no asynchronous lookups for source without connector key")
 	}
@@ -82,3 +71,123 @@ func TestBuildTrackerCardsDoesNotUseLastCheckedAtAsReleaseDate(t *testing.T) {
 		t.Fatalf("expected unknown release date marker, got %q", cards[0].LatestReleaseAgo)
 	}
 }
+
+func TestLinkedSourceReliability(t *testing.T) {
+	lag := 3.0
+	noLag := 0.0
+	cases := []struct {
+		source models.TrackerSource
+		want   string
+	}{
+		{source: models.TrackerSource{}, want: ""},
+		{source: models.TrackerSource{SuccessCount: 3, FailureCount: 1, LastPollFailed: true}, want: "✗ failing"},
+		{source: models.TrackerSource{SuccessCount: 4, LastLagChapters: &lag}, want: "~ lags by 3"},
+		{source: models.TrackerSource{SuccessCount: 4, LastLagChapters: &noLag}, want: "✓ fresh"},
+	}
+	for _, tc := range cases {
+		if got := linkedSourceReliability(tc.source); got != tc.want {
+			t.Fatalf("source %+v: expected %q, got %q", tc.source, tc.want, got)
+		}
+	}
+}
+
+func TestLooksLikeSequel(t *testing.T) {
+	cases := []struct {
+		base, candidate string
+		want            bool
+	}{
+		{base: "Tower of God", candidate: "Tower of God Season 2", want: true},
+		{base: "Tower of God", candidate: "Tower of God: Part II", want: true},
+		{base: "Tower of God", candidate: "tower-of-god s3", want: true},
+		{base: "Tower of God", candidate: "Tower of God 2nd Season", want: true},
+		{base: "Tower of God", candidate: "Tower of God 2", want: true},
+		{base: "Tower of God", candidate: "Tower of God", want: false},
+		{base: "Tower of God", candidate: "Tower of God Side Story", want: false},
+		{base: "Tower of God", candidate: "Tower of Godslayer Season 2", want: false},
+		{base: "Tower of God", candidate: "Tower of God Season 1", want: false},
+		{base: "", candidate: "Season 2", want: false},
+	}
+	for _, tc := range cases {
+		if got := looksLikeSequel(tc.base, tc.candidate); got != tc.want {
+			t.Fatalf("looksLikeSequel(%q, %q) = %v, want %v", tc.base, tc.candidate, got, tc.want)
+		}
+	}
+}
+
+func TestParseTagFilters(t *testing.T) {
+	got := parseTagFilters(" favorite | Priority|favorite ,-stale, dropped-scanlation,-,,FAVORITE|priority, -stale|old ")
+	want := []repository.TagFilter{
+		{AnyOf: []string{"favorite", "Priority"}},
+		{AnyOf: []string{"stale"}, Exclude: true},
+		{AnyOf: []string{"dropped-scanlation"}},
+		{AnyOf: []string{"-"}},
+		{AnyOf: []string{"stale", "old"}, Exclude: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if filters := parseTagFilters("  "); filters != nil {
+		t.Fatalf("expected no filters for a blank value, got %+v", filters)
+	}
+}
+
+func TestGenreTagSuggestionsIgnoreCaseAndPunctuation(t *testing.T) {
+	profileTags := []models.CustomTag{{ID: 1, Name: "sci fi"}, {ID: 2, Name: "Slice of Life"}, {ID: 3, Name: "Drama"}}
+	trackerTags := []models.CustomTag{{ID: 3, Name: "Drama"}}
+
+	got := genreTagSuggestions([]string{"Sci-Fi", "SLICE_OF_LIFE", "sci fi", "Drama", "Isekai"}, profileTags, trackerTags)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 suggestions, got %+v", got)
+	}
+	if got[0].Tag == nil || got[0].Tag.ID != 1 || got[1].Tag == nil || got[1].Tag.ID != 2 {
+		t.Fatalf("expected Sci-Fi and SLICE_OF_LIFE to match their tags, got %+v", got)
+	}
+	if got[2].Genre != "Isekai" || got[2].Tag != nil {
+		t.Fatalf("expected Isekai to be offered as a new tag, got %+v", got[2])
+	}
+}
+
+func TestEditFormMemoExpiresAndForgets(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	memo := newEditFormMemo(5 * time.Second)
+	memo.now = func() time.Time { return now }
+
+	first := editFormKey{profileID: 1, trackerID: 10}
+	other := editFormKey{profileID: 2, trackerID: 10}
+	_, gen, ok := memo.lookup(first)
+	if ok {
+		t.Fatal("expected an empty memo to miss")
+	}
+	memo.store(first, gen, trackerFormData{Mode: "edit"})
+	if _, _, ok := memo.lookup(first); !ok {
+		t.Fatal("expected a stored form to be served")
+	}
+	if _, _, ok := memo.lookup(other); ok {
+		t.Fatal("expected another profile's form of the same tracker to miss")
+	}
+
+	now = now.Add(5 * time.Second)
+	if _, _, ok := memo.lookup(first); ok {
+		t.Fatal("expected the form to expire after the ttl")
+	}
+
+	// A load that started before a write must not store what it read.
+	_, gen, _ = memo.lookup(first)
+	memo.forget(1, 10)
+	memo.store(first, gen, trackerFormData{Mode: "edit"})
+	if _, _, ok := memo.lookup(first); ok {
+		t.Fatal("expected a form read before a write to be dropped")
+	}
+
+	_, gen, _ = memo.lookup(first)
+	memo.store(first, gen, trackerFormData{Mode: "edit"})
+	_, otherGen, _ := memo.lookup(other)
+	memo.store(other, otherGen, trackerFormData{Mode: "edit"})
+	memo.forgetProfile(1)
+	if _, _, ok := memo.lookup(first); ok {
+		t.Fatal("expected a profile write to drop the profile's forms")
+	}
+	if _, _, ok := memo.lookup(other); !ok {
+		t.Fatal("expected a profile write to keep other profiles' forms")
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+)
+
+// setupInternalDashboardHandler builds a DashboardHandler over a migrated test
+// database with the given connector registry, so handlers can be exercised
+// against stub connectors instead of live sites.
+func setupInternalDashboardHandler(t *testing.T, registry *connectors.Registry) (*sql.DB, *DashboardHandler) {
+	t.Helper()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, currentFile, _, _ := runtime.Caller(0)
+	backendRoot := filepath.Clean(filepath.Join(filepath.Dir(currentFile), "..", "..", ".."))
+	originalWD, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("get working directory: %v", err)
+	}
+	if err := os.Chdir(backendRoot); err != nil {
+		t.Fatalf("set working directory: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(originalWD) })
+
+	if err := database.ApplyMigrations(db, filepath.Join(backendRoot, "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+
+	return db, NewDashboardHandler(db, registry, "")
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// langConnectorStub is a language-aware connector reporting more chapters
+// in English than in other translations.
+type langConnectorStub struct {
+	titledConnectorStub
+	mu    *sync.Mutex
+	langs *[]string
+}
+
+func (s langConnectorStub) ResolveByURLWithLang(ctx context.Context, rawURL string, lang string) (*connectors.MangaResult, error) {
+	s.mu.Lock()
+	*s.langs = append(*s.langs, lang)
+	s.mu.Unlock()
+	result, err := s.ResolveByURL(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	latest := 50.0
+	if lang != "en" {
+		latest = 20
+	}
+	result.LatestChapter = &latest
+	return result, nil
+}
+
+func TestUpdateFromFormSavesLinkedSourceLanguage(t *testing.T) {
+	var langs []string
+	registry := connectors.NewRegistry()
+	if err := registry.Register(langConnectorStub{
+		titledConnectorStub: titledConnectorStub{key: "mangadex", name: "MangaDex", title: "Polyglot Blade"},
+		mu:                  &sync.Mutex{},
+		langs:               &langs,
+	}); err != nil {
+		t.Fatalf("register mangadex stub: %v", err)
+	}
+	if err := registry.Register(titledConnectorStub{key: "mangafire", name: "MangaFire", title: "Polyglot Blade"}); err != nil {
+		t.Fatalf("register mangafire stub: %v", err)
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	var mangaDexID, mangaFireID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&mangaDexID); err != nil {
+		t.Fatalf("load mangadex source: %v", err)
+	}
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangafire'`).Scan(&mangaFireID); err != nil {
+		t.Fatalf("load mangafire source: %v", err)
+	}
+
+	mangaDexURL := "https://mangadex.org/title/polyglot-blade"
+	mangaFireURL := "https://mangafire.to/manga/polyglot-blade.abc"
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+		VALUES (1, 'Polyglot Blade', ?, ?, 'reading')
+	`, mangaDexID, mangaDexURL)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+
+	sources, err := h.sourceRepo.ListEnabled(context.Background())
+	if err != nil {
+		t.Fatalf("list sources: %v", err)
+	}
+	if ids := h.languageSourceIDs(sources); len(ids) != 1 || ids[0] != mangaDexID {
+		t.Fatalf("expected only mangadex to offer a language, got %v", ids)
+	}
+
+	app := fiber.New()
+	app.Post("/dashboard/trackers/:id", h.UpdateFromForm)
+	save := func(lang string) int {
+		t.Helper()
+		form := url.Values{}
+		form.Set("title", "Polyglot Blade")
+		form.Set("source_id", strconv.FormatInt(mangaDexID, 10))
+		form.Set("source_url", mangaDexURL)
+		form.Set("status", "reading")
+		form.Set("linked_sources_json", `[`+
+			`{"sourceId":`+strconv.FormatInt(mangaDexID, 10)+`,"sourceUrl":"`+mangaDexURL+`","lang":"`+lang+`"},`+
+			`{"sourceId":`+strconv.FormatInt(mangaFireID, 10)+`,"sourceUrl":"`+mangaFireURL+`"}]`)
+		req := httptest.NewRequest(fiber.MethodPost, "/dashboard/trackers/"+strconv.FormatInt(trackerID, 10), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("post edit form: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := save("PT_BR"); status != fiber.StatusOK {
+		t.Fatalf("expected save to succeed, got %d", status)
+	}
+
+	storedLang := func(sourceURL string) string {
+		t.Helper()
+		var lang string
+		if err := db.QueryRow(`SELECT lang FROM tracker_sources WHERE tracker_id = ? AND source_url = ?`, trackerID, sourceURL).Scan(&lang); err != nil {
+			t.Fatalf("load linked source lang: %v", err)
+		}
+		return lang
+	}
+	if lang := storedLang(mangaDexURL); lang != "pt-br" {
+		t.Fatalf("expected the mangadex link to follow pt-br, got %q", lang)
+	}
+	if lang := storedLang(mangaFireURL); lang != "en" {
+		t.Fatalf("expected the mangafire link to default to en, got %q", lang)
+	}
+	if len(langs) == 0 || langs[0] != "pt-br" {
+		t.Fatalf("expected the primary to be resolved in pt-br, got %v", langs)
+	}
+	var latest float64
+	if err := db.QueryRow(`SELECT latest_known_chapter FROM trackers WHERE id = ?`, trackerID).Scan(&latest); err != nil {
+		t.Fatalf("load latest chapter: %v", err)
+	}
+	if latest != 20 {
+		t.Fatalf("expected the pt-br latest chapter, got %v", latest)
+	}
+
+	if status := save("portuguese please"); status != fiber.StatusBadRequest {
+		t.Fatalf("expected an invalid language to be rejected, got %d", status)
+	}
+	if lang := storedLang(mangaDexURL); lang != "pt-br" {
+		t.Fatalf("expected the rejected save to keep pt-br, got %q", lang)
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gofiber/fiber/v2"
+)
+
+type titledConnectorStub struct {
+	key   string
+	name  string
+	title string
+}
+
+func (s titledConnectorStub) Key() string                     { return s.key }
+func (s titledConnectorStub) Name() string                    { return s.name }
+func (titledConnectorStub) Kind() string                      { return connectors.KindNative }
+func (titledConnectorStub) HealthCheck(context.Context) error { return nil }
+
+func (s titledConnectorStub) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	return &connectors.MangaResult{
+		SourceKey:    s.key,
+		SourceItemID: s.key + "-item",
+		Title:        s.title,
+		URL:          rawURL,
+	}, nil
+}
+
+func (titledConnectorStub) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+
+func setupLinkedSourceMismatchTest(t *testing.T, linkedTitle string) (*sql.DB, *fiber.App, int64, string) {
+	t.Helper()
+
+	registry := connectors.NewRegistry()
+	if err := registry.Register(titledConnectorStub{key: "mangadex", name: "MangaDex", title: "The Tower Climber's Return"}); err != nil {
+		t.Fatalf("register mangadex stub: %v", err)
+	}
+	if err := registry.Register(titledConnectorStub{key: "mangafire", name: "MangaFire", title: linkedTitle}); err != nil {
+		t.Fatalf("register mangafire stub: %v", err)
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	var mangaDexID, mangaFireID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&mangaDexID); err != nil {
+		t.Fatalf("load mangadex source: %v", err)
+	}
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangafire'`).Scan(&mangaFireID); err != nil {
+		t.Fatalf("load mangafire source: %v", err)
+	}
+
+	mangaDexURL := "https://mangadex.org/title/tower-climber"
+	mangaFireURL := "https://mangafire.to/manga/linked-series.abc"
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+		VALUES (1, 'The Tower Climber''s Return', ?, ?, 'reading')
+	`, mangaDexID, mangaDexURL)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+
+	app := fiber.New()
+	app.Post("/dashboard/trackers/:id", h.UpdateFromForm)
+	app.Post("/dashboard/trackers/:id/linked-sources/:sourceID/dismiss-mismatch", h.DismissLinkedSourceMismatch)
+
+	form := url.Values{}
+	form.Set("title", "The Tower Climber's Return")
+	form.Set("source_id", strconv.FormatInt(mangaDexID, 10))
+	form.Set("source_url", mangaDexURL)
+	form.Set("status", "reading")
+	form.Set("auto_primary", "0")
+	form.Set("linked_sources_json", `[`+
+		`{"sourceId":`+strconv.FormatInt(mangaDexID, 10)+`,"sourceUrl":"`+mangaDexURL+`"},`+
+		`{"sourceId":`+strconv.FormatInt(mangaFireID, 10)+`,"sourceUrl":"`+mangaFireURL+`"}]`)
+	req := httptest.NewRequest(fiber.MethodPost, "/dashboard/trackers/"+strconv.FormatInt(trackerID, 10), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("post edit form: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected save to succeed, got %d", resp.StatusCode)
+	}
+
+	return db, app, trackerID, mangaFireURL
+}
+
+func linkedSourceMismatch(t *testing.T, db *sql.DB, trackerID int64, sourceURL string) (int64, bool) {
+	t.Helper()
+
+	var id int64
+	var suspected bool
+	if err := db.QueryRow(`
+		SELECT id, mismatch_suspected FROM tracker_sources WHERE tracker_id = ? AND source_url = ?
+	`, trackerID, sourceURL).Scan(&id, &suspected); err != nil {
+		t.Fatalf("load linked source: %v", err)
+	}
+	return id, suspected
+}
+
+func TestUpdateFromFormFlagsLinkedSourceWithDifferentTitle(t *testing.T) {
+	db, app, trackerID, linkedURL := setupLinkedSourceMismatchTest(t, "Ragnarok Online Chronicles")
+
+	linkedID, suspected := linkedSourceMismatch(t, db, trackerID, linkedURL)
+	if !suspected {
+		t.Fatalf("expected linked source with an unrelated title to be flagged")
+	}
+
+	dismissURL := "/dashboard/trackers/" + strconv.FormatInt(trackerID, 10) + "/linked-sources/" + strconv.FormatInt(linkedID, 10) + "/dismiss-mismatch"
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, dismissURL, nil), -1)
+	if err != nil {
+		t.Fatalf("dismiss mismatch: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNoContent {
+		t.Fatalf("expected 204 from dismiss, got %d", resp.StatusCode)
+	}
+	if _, suspected := linkedSourceMismatch(t, db, trackerID, linkedURL); suspected {
+		t.Fatalf("expected dismiss to clear the mismatch flag")
+	}
+
+	otherTracker := "/dashboard/trackers/" + strconv.FormatInt(trackerID+100, 10) + "/linked-sources/" + strconv.FormatInt(linkedID, 10) + "/dismiss-mismatch"
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodPost, otherTracker, nil), -1)
+	if err != nil {
+		t.Fatalf("dismiss mismatch on other tracker: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for a linked source of another tracker, got %d", resp.StatusCode)
+	}
+}
+
+func TestUpdateFromFormKeepsMatchingLinkedSourceUnflagged(t *testing.T) {
+	db, _, trackerID, linkedURL := setupLinkedSourceMismatchTest(t, "Tower Climber Return (Official)")
+
+	if _, suspected := linkedSourceMismatch(t, db, trackerID, linkedURL); suspected {
+		t.Fatalf("expected linked source with a matching title to stay unflagged")
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// tiedLinkedSourcesJSON links MangaFire and a second MangaDex entry whose
+// offset puts its chapter 120 level with MangaFire's 124, listed in the
+// given order, leaving out the tracker's primary.
+func (f primarySwitchFixture) tiedLinkedSourcesJSON(mangaFireFirst bool) string {
+	mangaDex := `{"sourceId":` + strconv.FormatInt(f.mangaDexID, 10) + `,"sourceUrl":"https://mangadex.org/title/switch-series-alt","chapterOffset":4}`
+	mangaFire := `{"sourceId":` + strconv.FormatInt(f.mangaFireID, 10) + `,"sourceUrl":"` + f.mangaFireURL + `"}`
+	if mangaFireFirst {
+		return `[` + mangaFire + `,` + mangaDex + `]`
+	}
+	return `[` + mangaDex + `,` + mangaFire + `]`
+}
+
+func TestUpdateFromFormPromotesTheEarlierOfTiedLinkedSources(t *testing.T) {
+	for _, mangaFireFirst := range []bool{true, false} {
+		fixture := setupPrimarySwitchFixture(t)
+
+		status, body := fixture.postEdit(t, url.Values{"linked_sources_json": {fixture.tiedLinkedSourcesJSON(mangaFireFirst)}})
+		if status != fiber.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", status, body)
+		}
+
+		wantSourceID, wantURL := fixture.mangaDexID, "https://mangadex.org/title/switch-series-alt"
+		if mangaFireFirst {
+			wantSourceID, wantURL = fixture.mangaFireID, fixture.mangaFireURL
+		}
+		if sourceID, sourceURL := fixture.storedPrimary(t); sourceID != wantSourceID || sourceURL != wantURL {
+			t.Fatalf("mangaFireFirst=%v: expected the first tied source promoted, got source %d url %q", mangaFireFirst, sourceID, sourceURL)
+		}
+
+		sources, err := repository.NewTrackerRepository(fixture.db).ListTrackerSources(context.Background(), 1, fixture.trackerID)
+		if err != nil {
+			t.Fatalf("list tracker sources: %v", err)
+		}
+		if len(sources) != 2 || sources[0].SourceID != wantSourceID {
+			t.Fatalf("mangaFireFirst=%v: expected the form's order saved, got %+v", mangaFireFirst, sources)
+		}
+	}
+}
+
+func TestReorderLinkedSourcesFromFormNeedsEveryRowOnce(t *testing.T) {
+	db, h := setupInternalDashboardHandler(t, nil)
+	ctx := context.Background()
+
+	var mangaDexID, mangaFireID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&mangaDexID); err != nil {
+		t.Fatalf("load mangadex source: %v", err)
+	}
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangafire'`).Scan(&mangaFireID); err != nil {
+		t.Fatalf("load mangafire source: %v", err)
+	}
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+		VALUES (1, 'Order Series', ?, 'https://mangadex.org/title/order', 'reading')
+	`, mangaDexID)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	if _, err := db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_url, position)
+		VALUES (?, ?, 'https://mangadex.org/title/order', 0),
+		       (?, ?, 'https://mangafire.to/manga/order.x', 1)
+	`, trackerID, mangaDexID, trackerID, mangaFireID); err != nil {
+		t.Fatalf("insert linked sources: %v", err)
+	}
+	sources, err := h.trackerRepo.ListTrackerSources(ctx, 1, trackerID)
+	if err != nil || len(sources) != 2 {
+		t.Fatalf("list tracker sources: %+v %v", sources, err)
+	}
+	mangaDexRow, mangaFireRow := strconv.FormatInt(sources[0].ID, 10), strconv.FormatInt(sources[1].ID, 10)
+
+	app := fiber.New()
+	app.Post("/dashboard/trackers/:id/sources/reorder", h.ReorderLinkedSourcesFromForm)
+	post := func(trackerID int64, ids ...string) int {
+		t.Helper()
+		form := url.Values{"tracker_source_id": ids}
+		req := httptest.NewRequest(fiber.MethodPost, "/dashboard/trackers/"+strconv.FormatInt(trackerID, 10)+"/sources/reorder", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("post reorder: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	for _, ids := range [][]string{{mangaFireRow}, {mangaFireRow, mangaFireRow}, {mangaFireRow, mangaDexRow, "999999"}, {mangaFireRow, "x"}} {
+		if status := post(trackerID, ids...); status != fiber.StatusBadRequest {
+			t.Fatalf("expected %v rejected, got %d", ids, status)
+		}
+	}
+	if status := post(trackerID+1000, mangaFireRow, mangaDexRow); status != fiber.StatusNotFound {
+		t.Fatalf("expected an unknown tracker to be a 404, got %d", status)
+	}
+	if sources, _ := h.trackerRepo.ListTrackerSources(ctx, 1, trackerID); sources[0].SourceID != mangaDexID {
+		t.Fatalf("expected rejected orders to change nothing, got %+v", sources)
+	}
+
+	if status := post(trackerID, mangaFireRow, mangaDexRow); status != fiber.StatusNoContent {
+		t.Fatalf("expected the reorder saved, got %d", status)
+	}
+	sources, err = h.trackerRepo.ListTrackerSources(ctx, 1, trackerID)
+	if err != nil || len(sources) != 2 || sources[0].SourceID != mangaFireID || sources[1].SourceID != mangaDexID {
+		t.Fatalf("expected mangafire first, got %+v %v", sources, err)
+	}
+}
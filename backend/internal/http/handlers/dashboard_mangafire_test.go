@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
 )
 
 type mangaFireChapterResolverStub struct{}
@@ -38,7 +39,7 @@ func (mangaFireChapterResolverStub) ResolveChapterURL(_ context.Context, rawURL
 	return rawURL + "#chapter=" + formatChapterLabel(chapter), nil
 }
 
-func TestExtractMangaFireMangaURL(t *testing.T) {
+func TestExtractSearchURL(t *testing.T) {
 	tests := []struct {
 		name    string
 		query   string
@@ -52,9 +53,9 @@ func TestExtractMangaFireMangaURL(t *testing.T) {
 			wantOK:  true,
 		},
 		{
-			name:    "strips query and fragment",
-			query:   "https://www.mangafire.to/manga/one-piecee.dkw?x=1#top",
-			wantURL: "https://www.mangafire.to/manga/one-piecee.dkw",
+			name:    "strips fragment and keeps query for the connector",
+			query:   "  https://www.webtoons.com/en/action/series/list?title_no=1#top ",
+			wantURL: "https://www.webtoons.com/en/action/series/list?title_no=1",
 			wantOK:  true,
 		},
 		{
@@ -74,20 +75,15 @@ func TestExtractMangaFireMangaURL(t *testing.T) {
 			wantOK: false,
 		},
 		{
-			name:   "rejects non manga path",
-			query:  "https://mangafire.to/read/one-piecee.dkw/en/chapter-1",
-			wantOK: false,
-		},
-		{
-			name:   "rejects other domains",
-			query:  "https://example.com/manga/one-piecee.dkw",
+			name:   "rejects URL without host",
+			query:  "https:///manga/one-piecee.dkw",
 			wantOK: false,
 		},
 	}
 
 	for _, testCase := range tests {
 		t.Run(testCase.name, func(t *testing.T) {
-			gotURL, gotOK := extractMangaFireMangaURL(testCase.query)
+			gotURL, gotOK := extractSearchURL(testCase.query)
 			if gotOK != testCase.wantOK {
 				t.Fatalf("expected ok=%v, got %v", testCase.wantOK, gotOK)
 			}
@@ -105,16 +101,16 @@ func TestGetCachedOrQueueChapterURL_MangaFireQueuesResolver(t *testing.T) {
 	}
 
 	h := &DashboardHandler{
-		registry:           registry,
-		chapterURLCache:    make(map[string]chapterURLCacheEntry),
-		chapterURLInFlight: make(map[string]bool),
-		chapterURLFetchSem: make(chan struct{}, 1),
+		registry:             registry,
+		links:                linkcache.NewResolver(registry, nil, nil),
+		chapterURLInFlight:   make(map[string]bool),
+		chapterURLFetchQueue: newFetchQueue(1),
 	}
 
 	sourceURL := "https://mangafire.to/manga/one-piecee.dkw"
 	chapter := 1173.0
 
-	resolvedURL, waiting := h.getCachedOrQueueChapterURL("mangafire", sourceURL, chapter, "")
+	resolvedURL, waiting := h.getCachedOrQueueChapterURL("mangafire", sourceURL, chapter, "", 0)
 	if resolvedURL != sourceURL {
 		t.Fatalf("expected initial URL %q, got %q", sourceURL, resolvedURL)
 	}
@@ -122,7 +118,7 @@ func TestGetCachedOrQueueChapterURL_MangaFireQueuesResolver(t *testing.T) {
 		t.Fatalf("expected mangafire chapter url resolution to be queued")
 	}
 
-	cacheKey := buildChapterURLCacheKey("mangafire", sourceURL, chapter)
+	cacheKey := linkcache.ChapterURLKey("mangafire", sourceURL, chapter)
 	deadline := time.Now().Add(2 * time.Second)
 	for time.Now().Before(deadline) {
 		if chapterURL, found, ok := h.getCachedChapterURL(cacheKey); ok && found {
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+const manualReleaseDateLayout = "2006-01-02"
+
+// ManualReleaseFromForm logs a chapter by hand for a tracker on a manual
+// source: the chapter becomes the latest known one, released on the posted
+// date. A chapter older than the current latest needs force=1.
+func (h *DashboardHandler) ManualReleaseFromForm(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	viewMode := normalizeViewMode(c.FormValue("view_mode", c.Query("view", "grid")))
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+	defer h.editForms.forget(activeProfile.ID, id)
+
+	tracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
+	if err != nil {
+		return serverError(c, "Failed to load tracker", err)
+	}
+	if tracker == nil {
+		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
+	}
+
+	source, err := h.sourceRepo.GetByID(c.UserContext(), tracker.SourceID)
+	if err != nil {
+		return serverError(c, "Failed to load source", err)
+	}
+	if source == nil || !h.isManualSource(source.Key) {
+		return c.Status(fiber.StatusBadRequest).SendString("Chapters can only be logged by hand for trackers on a manual source")
+	}
+
+	chapter, err := parseOptionalFloat(c.FormValue("chapter"))
+	if err != nil || chapter == nil || *chapter < 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Enter the chapter number")
+	}
+	releasedAt, message := parseManualReleaseDate(c.FormValue("released_on"), time.Now().UTC())
+	if message != "" {
+		return c.Status(fiber.StatusBadRequest).SendString(message)
+	}
+	force := c.FormValue("force") != ""
+	if tracker.LatestKnownChapter != nil && *chapter < *tracker.LatestKnownChapter && !force {
+		return c.Status(fiber.StatusBadRequest).SendString("Chapter " + chapterInputValue(chapter) + " is older than the latest known chapter " +
+			chapterInputValue(tracker.LatestKnownChapter) + "; tick Force to log it anyway")
+	}
+
+	placement := h.newCardPlacement(c, activeProfile.ID, viewMode, id)
+
+	if _, err := h.trackerRepo.SetManualRelease(c.UserContext(), activeProfile.ID, id, *chapter, releasedAt); err != nil {
+		return serverError(c, "Failed to log chapter", err)
+	}
+
+	updatedTracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
+	if err != nil || updatedTracker == nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	sourceByID, err := pageCtx.SourceByID()
+	if err != nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
+	if err != nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	cards, _ := h.buildTrackerCards(c.UserContext(), []models.Tracker{*updatedTracker}, sourceByID, sourceLogoBySourceID, "")
+	if len(cards) == 0 {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	response := trackerOOBResponseData{
+		ViewMode:    viewMode,
+		ReplaceCard: &cards[0],
+	}
+	h.placeUpdatedCard(c, placement, &response)
+	response.announce(mutationReleaseLogged, "", &cards[0])
+	return h.render(c, "tracker_oob_response.html", response)
+}
+
+// parseManualReleaseDate reads the release date field. A blank field or
+// today means now, so the card reads "just now"; earlier days are stored at
+// midnight UTC. It returns the message to show when the date is invalid.
+func parseManualReleaseDate(raw string, now time.Time) (time.Time, string) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return now, ""
+	}
+	day, err := time.Parse(manualReleaseDateLayout, trimmed)
+	if err != nil {
+		return time.Time{}, "Enter the release date as YYYY-MM-DD"
+	}
+	today := now.Format(manualReleaseDateLayout)
+	switch {
+	case trimmed == today:
+		return now, ""
+	case day.After(now):
+		return time.Time{}, "The release date cannot be in the future"
+	}
+	return day, ""
+}
+
+// isManualSource reports whether sourceKey's connector is a manual one,
+// whose chapters are logged by hand instead of polled.
+func (h *DashboardHandler) isManualSource(sourceKey string) bool {
+	if h.registry == nil {
+		return false
+	}
+	connector, ok := h.registry.Get(sourceKey)
+	return ok && connector.Kind() == connectors.KindManual
+}
+
+// manualSourceSelected reports whether the tracker's primary source, looked
+// up in sources, is a manual one.
+func (h *DashboardHandler) manualSourceSelected(sources []models.Source, tracker *models.Tracker) bool {
+	if tracker == nil {
+		return false
+	}
+	for _, source := range sources {
+		if source.ID == tracker.SourceID {
+			return h.isManualSource(source.Key)
+		}
+	}
+	return false
+}
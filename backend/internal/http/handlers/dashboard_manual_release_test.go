@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/manual"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestManualReleaseFromFormLogsChapterForManualTrackers(t *testing.T) {
+	registry := connectors.NewRegistry()
+	if err := registry.Register(manual.NewConnector()); err != nil {
+		t.Fatalf("register manual connector: %v", err)
+	}
+	if err := registry.Register(titledConnectorStub{key: "mangadex", name: "MangaDex", title: "Polled Blade"}); err != nil {
+		t.Fatalf("register mangadex stub: %v", err)
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	insertTracker := func(sourceKey, title, sourceURL string) int64 {
+		t.Helper()
+		result, err := db.Exec(`
+			INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter)
+			VALUES (1, ?, (SELECT id FROM sources WHERE key = ?), ?, 'reading', 12)
+		`, title, sourceKey, sourceURL)
+		if err != nil {
+			t.Fatalf("insert %s tracker: %v", sourceKey, err)
+		}
+		id, _ := result.LastInsertId()
+		return id
+	}
+	manualID := insertTracker("manual", "Hand Blade", "https://scans.example.test/hand-blade")
+	polledID := insertTracker("mangadex", "Polled Blade", "https://mangadex.org/title/polled-blade")
+
+	app := fiber.New()
+	app.Post("/dashboard/trackers/:id/manual-release", h.ManualReleaseFromForm)
+	post := func(trackerID int64, values map[string]string) (int, string) {
+		t.Helper()
+		form := url.Values{}
+		form.Set("view_mode", "grid")
+		for key, value := range values {
+			form.Set(key, value)
+		}
+		req := httptest.NewRequest(fiber.MethodPost, "/dashboard/trackers/"+strconv.FormatInt(trackerID, 10)+"/manual-release", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("post manual release: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read response: %v", err)
+		}
+		return resp.StatusCode, string(body)
+	}
+	latest := func(trackerID int64) (float64, *time.Time) {
+		t.Helper()
+		var chapter float64
+		var releasedAt *time.Time
+		if err := db.QueryRow(`SELECT latest_known_chapter, latest_release_at FROM trackers WHERE id = ?`, trackerID).Scan(&chapter, &releasedAt); err != nil {
+			t.Fatalf("load tracker: %v", err)
+		}
+		return chapter, releasedAt
+	}
+
+	if status, body := post(polledID, map[string]string{"chapter": "13"}); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for a polled source, got %d: %s", status, body)
+	}
+	if status, _ := post(manualID, map[string]string{"chapter": ""}); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 without a chapter, got %d", status)
+	}
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	if status, _ := post(manualID, map[string]string{"chapter": "13", "released_on": tomorrow}); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for a future release date, got %d", status)
+	}
+
+	status, body := post(manualID, map[string]string{"chapter": "13", "released_on": "2026-01-05"})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	if !strings.Contains(body, `id="tracker-card-`+strconv.FormatInt(manualID, 10)+`"`) {
+		t.Fatalf("expected the updated card in the response, got %s", body)
+	}
+	chapter, releasedAt := latest(manualID)
+	if chapter != 13 || releasedAt == nil || !releasedAt.Equal(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected chapter 13 released on 2026-01-05, got %v at %v", chapter, releasedAt)
+	}
+
+	// An older chapter is refused unless forced.
+	if status, body := post(manualID, map[string]string{"chapter": "9"}); status != fiber.StatusBadRequest || !strings.Contains(body, "older than the latest known chapter 13") {
+		t.Fatalf("expected 400 for an older chapter, got %d: %s", status, body)
+	}
+	if chapter, _ := latest(manualID); chapter != 13 {
+		t.Fatalf("expected the refused chapter to leave 13 in place, got %v", chapter)
+	}
+	if status, body := post(manualID, map[string]string{"chapter": "9", "force": "1"}); status != fiber.StatusOK {
+		t.Fatalf("expected forced older chapter to be logged, got %d: %s", status, body)
+	}
+	chapter, releasedAt = latest(manualID)
+	if chapter != 9 || releasedAt == nil || time.Since(*releasedAt) > time.Minute {
+		t.Fatalf("expected chapter 9 released just now, got %v at %v", chapter, releasedAt)
+	}
+}
+
+func TestParseManualReleaseDate(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 4, 0, 0, time.UTC)
+	cases := []struct {
+		raw     string
+		want    time.Time
+		invalid bool
+	}{
+		{raw: "", want: now},
+		{raw: "2026-03-10", want: now},
+		{raw: "2026-03-09", want: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{raw: "2026-03-11", invalid: true},
+		{raw: "03/09/2026", invalid: true},
+	}
+	for _, tc := range cases {
+		got, message := parseManualReleaseDate(tc.raw, now)
+		if tc.invalid {
+			if message == "" {
+				t.Fatalf("expected %q to be rejected, got %v", tc.raw, got)
+			}
+			continue
+		}
+		if message != "" || !got.Equal(tc.want) {
+			t.Fatalf("expected %q to parse as %v, got %v (%q)", tc.raw, tc.want, got, message)
+		}
+	}
+}
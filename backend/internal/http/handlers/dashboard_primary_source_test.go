@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// chapterConnectorStub reports a fixed latest chapter and counts resolves.
+type chapterConnectorStub struct {
+	titledConnectorStub
+	latest   float64
+	resolves *int
+}
+
+func (s chapterConnectorStub) ResolveByURL(ctx context.Context, rawURL string) (*connectors.MangaResult, error) {
+	*s.resolves++
+	result, err := s.titledConnectorStub.ResolveByURL(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	result.LatestChapter = &s.latest
+	return result, nil
+}
+
+type trackerSourceSnapshot struct {
+	ID, SourceID, SuccessCount, FailureCount int64
+	SourceURL, Lang, UpdatedAt               string
+	SourceItemID                             sql.NullString
+}
+
+func trackerSourceSnapshots(t *testing.T, db *sql.DB) map[int64]trackerSourceSnapshot {
+	t.Helper()
+	rows, err := db.Query(`SELECT id, source_id, source_item_id, source_url, lang, success_count, failure_count, updated_at FROM tracker_sources`)
+	if err != nil {
+		t.Fatalf("list tracker sources: %v", err)
+	}
+	defer rows.Close()
+	snapshots := make(map[int64]trackerSourceSnapshot)
+	for rows.Next() {
+		var row trackerSourceSnapshot
+		if err := rows.Scan(&row.ID, &row.SourceID, &row.SourceItemID, &row.SourceURL, &row.Lang, &row.SuccessCount, &row.FailureCount, &row.UpdatedAt); err != nil {
+			t.Fatalf("scan tracker source: %v", err)
+		}
+		snapshots[row.ID] = row
+	}
+	return snapshots
+}
+
+func TestSetPrimarySourceFromFormSwitchesOnlyTheTracker(t *testing.T) {
+	resolves := map[string]*int{"mangadex": new(int), "mangafire": new(int), "webtoons": new(int)}
+	registry := connectors.NewRegistry()
+	for key, latest := range map[string]float64{"mangadex": 30, "mangafire": 34, "webtoons": 41} {
+		if err := registry.Register(chapterConnectorStub{
+			titledConnectorStub: titledConnectorStub{key: key, name: key, title: "Primary Blade"},
+			latest:              latest,
+			resolves:            resolves[key],
+		}); err != nil {
+			t.Fatalf("register %s stub: %v", key, err)
+		}
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	sourceID := func(key string) int64 {
+		t.Helper()
+		var id int64
+		if err := db.QueryRow(`SELECT id FROM sources WHERE key = ?`, key).Scan(&id); err != nil {
+			t.Fatalf("load %s source: %v", key, err)
+		}
+		return id
+	}
+	mangaDexID, mangaFireID, webtoonsID := sourceID("mangadex"), sourceID("mangafire"), sourceID("webtoons")
+
+	mangaDexURL := "https://mangadex.org/title/primary-blade"
+	mangaFireURL := "https://mangafire.to/manga/primary-blade.abc"
+	webtoonsURL := "https://www.webtoons.com/en/action/primary-blade/list?title_no=7"
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter, last_poll_error)
+		VALUES (1, 'Primary Blade', ?, ?, 'reading', 30, 'mangadex timed out')
+	`, mangaDexID, mangaDexURL)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	if _, err := db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_item_id, source_url, success_count, failure_count)
+		VALUES (?, ?, NULL, ?, 5, 1), (?, ?, 'fire-item', ?, 4, 0), (?, ?, NULL, ?, 2, 3)
+	`, trackerID, mangaDexID, mangaDexURL, trackerID, mangaFireID, mangaFireURL, trackerID, webtoonsID, webtoonsURL); err != nil {
+		t.Fatalf("insert linked sources: %v", err)
+	}
+	var mangaFireRowID int64
+	if err := db.QueryRow(`SELECT id FROM tracker_sources WHERE tracker_id = ? AND source_id = ?`, trackerID, mangaFireID).Scan(&mangaFireRowID); err != nil {
+		t.Fatalf("load mangafire row: %v", err)
+	}
+
+	otherResult, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+		VALUES (2, 'Other Profile Blade', ?, 'https://mangafire.to/manga/other.abc', 'reading')
+	`, mangaFireID)
+	if err != nil {
+		t.Fatalf("insert other tracker: %v", err)
+	}
+	otherTrackerID, _ := otherResult.LastInsertId()
+	otherRow, err := db.Exec(`INSERT INTO tracker_sources (tracker_id, source_id, source_url) VALUES (?, ?, 'https://mangafire.to/manga/other.abc')`, otherTrackerID, mangaFireID)
+	if err != nil {
+		t.Fatalf("insert other linked source: %v", err)
+	}
+	otherRowID, _ := otherRow.LastInsertId()
+
+	app := fiber.New()
+	app.Post("/dashboard/trackers/:id/primary-source", h.SetPrimarySourceFromForm)
+	post := func(trackerID int64, trackerSourceID string) (int, string) {
+		t.Helper()
+		form := url.Values{}
+		form.Set("tracker_source_id", trackerSourceID)
+		form.Set("view_mode", "grid")
+		req := httptest.NewRequest(fiber.MethodPost, "/dashboard/trackers/"+strconv.FormatInt(trackerID, 10)+"/primary-source", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("post primary source: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read response: %v", err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	before := trackerSourceSnapshots(t, db)
+
+	if status, _ := post(trackerID, "nope"); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid row id, got %d", status)
+	}
+	if status, _ := post(trackerID, strconv.FormatInt(otherRowID, 10)); status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for another tracker's row, got %d", status)
+	}
+	if status, _ := post(otherTrackerID, strconv.FormatInt(otherRowID, 10)); status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for another profile's tracker, got %d", status)
+	}
+
+	status, body := post(trackerID, strconv.FormatInt(mangaFireRowID, 10))
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	if !strings.Contains(body, `id="tracker-card-`+strconv.FormatInt(trackerID, 10)+`"`) {
+		t.Fatalf("expected the updated card in the response, got %s", body)
+	}
+
+	var gotSourceID int64
+	var gotURL string
+	var gotItemID sql.NullString
+	var gotLatest float64
+	var pollError sql.NullString
+	if err := db.QueryRow(`SELECT source_id, source_url, source_item_id, latest_known_chapter, last_poll_error FROM trackers WHERE id = ?`, trackerID).Scan(&gotSourceID, &gotURL, &gotItemID, &gotLatest, &pollError); err != nil {
+		t.Fatalf("load tracker: %v", err)
+	}
+	if gotSourceID != mangaFireID || gotURL != mangaFireURL || gotItemID.String != "fire-item" {
+		t.Fatalf("expected mangafire as primary, got source %d %s %v", gotSourceID, gotURL, gotItemID)
+	}
+	if gotLatest != 34 || pollError.Valid {
+		t.Fatalf("expected the mangafire chapter and a cleared poll error, got %v %v", gotLatest, pollError)
+	}
+	if *resolves["mangafire"] != 1 || *resolves["mangadex"] != 0 || *resolves["webtoons"] != 0 {
+		t.Fatalf("expected only the new primary resolved, got mangadex=%d mangafire=%d webtoons=%d", *resolves["mangadex"], *resolves["mangafire"], *resolves["webtoons"])
+	}
+
+	after := trackerSourceSnapshots(t, db)
+	if len(after) != len(before) {
+		t.Fatalf("expected %d linked source rows, got %d", len(before), len(after))
+	}
+	for id, row := range before {
+		if id == mangaFireRowID {
+			continue
+		}
+		if after[id] != row {
+			t.Fatalf("expected linked source %d unchanged, got %+v (was %+v)", id, after[id], row)
+		}
+	}
+}
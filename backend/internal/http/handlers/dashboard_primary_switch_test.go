@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gofiber/fiber/v2"
+)
+
+type chapterReportingConnectorStub struct {
+	key     string
+	name    string
+	chapter float64
+}
+
+func (s chapterReportingConnectorStub) Key() string {
+	return s.key
+}
+
+func (s chapterReportingConnectorStub) Name() string {
+	return s.name
+}
+
+func (chapterReportingConnectorStub) Kind() string {
+	return connectors.KindNative
+}
+
+func (chapterReportingConnectorStub) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (s chapterReportingConnectorStub) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	chapter := s.chapter
+	return &connectors.MangaResult{
+		SourceKey:     s.key,
+		SourceItemID:  s.key + "-item",
+		Title:         "Switch Series",
+		URL:           rawURL,
+		LatestChapter: &chapter,
+	}, nil
+}
+
+func (chapterReportingConnectorStub) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+
+type primarySwitchFixture struct {
+	db           *sql.DB
+	app          *fiber.App
+	trackerID    int64
+	mangaDexID   int64
+	mangaFireID  int64
+	mangaDexURL  string
+	mangaFireURL string
+}
+
+func setupPrimarySwitchFixture(t *testing.T) primarySwitchFixture {
+	t.Helper()
+
+	registry := connectors.NewRegistry()
+	if err := registry.Register(chapterReportingConnectorStub{key: "mangadex", name: "MangaDex", chapter: 120}); err != nil {
+		t.Fatalf("register mangadex stub: %v", err)
+	}
+	if err := registry.Register(chapterReportingConnectorStub{key: "mangafire", name: "MangaFire", chapter: 124}); err != nil {
+		t.Fatalf("register mangafire stub: %v", err)
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	fixture := primarySwitchFixture{
+		db:           db,
+		mangaDexURL:  "https://mangadex.org/title/switch-series",
+		mangaFireURL: "https://mangafire.to/manga/switch-series.abc",
+	}
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&fixture.mangaDexID); err != nil {
+		t.Fatalf("load mangadex source: %v", err)
+	}
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangafire'`).Scan(&fixture.mangaFireID); err != nil {
+		t.Fatalf("load mangafire source: %v", err)
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, 1, "Switch Series", fixture.mangaDexID, fixture.mangaDexURL, "reading", 120.0)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	fixture.trackerID, _ = result.LastInsertId()
+
+	app := fiber.New()
+	app.Post("/dashboard/trackers/:id", h.UpdateFromForm)
+	fixture.app = app
+
+	return fixture
+}
+
+func (f primarySwitchFixture) postEdit(t *testing.T, extra url.Values) (int, string) {
+	t.Helper()
+
+	form := url.Values{}
+	form.Set("title", "Switch Series")
+	form.Set("source_id", strconv.FormatInt(f.mangaDexID, 10))
+	form.Set("source_url", f.mangaDexURL)
+	form.Set("status", "reading")
+	form.Set("latest_known_chapter", "120")
+	if _, ok := extra["linked_sources_json"]; !ok {
+		form.Set("linked_sources_json", f.linkedSourcesJSON(0, 0))
+	}
+	for key, values := range extra {
+		for _, value := range values {
+			form.Add(key, value)
+		}
+	}
+
+	req := httptest.NewRequest(fiber.MethodPost, "/dashboard/trackers/"+strconv.FormatInt(f.trackerID, 10), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := f.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("post edit form: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read response body: %v", err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+// linkedSourcesJSON links MangaDex and MangaFire with the given chapter
+// offsets.
+func (f primarySwitchFixture) linkedSourcesJSON(mangaDexOffset float64, mangaFireOffset float64) string {
+	return `[` +
+		`{"sourceId":` + strconv.FormatInt(f.mangaDexID, 10) + `,"sourceUrl":"` + f.mangaDexURL + `","chapterOffset":` + strconv.FormatFloat(mangaDexOffset, 'f', -1, 64) + `},` +
+		`{"sourceId":` + strconv.FormatInt(f.mangaFireID, 10) + `,"sourceUrl":"` + f.mangaFireURL + `","chapterOffset":` + strconv.FormatFloat(mangaFireOffset, 'f', -1, 64) + `}]`
+}
+
+func (f primarySwitchFixture) storedPrimary(t *testing.T) (int64, string) {
+	t.Helper()
+
+	var sourceID int64
+	var sourceURL string
+	if err := f.db.QueryRow(`SELECT source_id, source_url FROM trackers WHERE id = ?`, f.trackerID).Scan(&sourceID, &sourceURL); err != nil {
+		t.Fatalf("load tracker primary: %v", err)
+	}
+	return sourceID, sourceURL
+}
+
+func TestUpdateFromFormAsksBeforeSwitchingPrimary(t *testing.T) {
+	fixture := setupPrimarySwitchFixture(t)
+
+	status, body := fixture.postEdit(t, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	if !strings.Contains(body, "Primary will switch from MangaDex to MangaFire because it reports Ch. 124 vs 120") {
+		t.Fatalf("expected primary switch summary, got %s", body)
+	}
+	if !strings.Contains(body, `name="confirm_primary_switch" value="1"`) {
+		t.Fatalf("expected confirm flag in re-rendered form")
+	}
+	if sourceID, _ := fixture.storedPrimary(t); sourceID != fixture.mangaDexID {
+		t.Fatalf("expected primary to stay unchanged before confirmation, got source %d", sourceID)
+	}
+
+	status, body = fixture.postEdit(t, url.Values{"confirm_primary_switch": {"1"}})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	if strings.Contains(body, "confirm_primary_switch") {
+		t.Fatalf("expected confirmed save to return the card response")
+	}
+	sourceID, sourceURL := fixture.storedPrimary(t)
+	if sourceID != fixture.mangaFireID || sourceURL != fixture.mangaFireURL {
+		t.Fatalf("expected primary to switch to mangafire, got source %d url %q", sourceID, sourceURL)
+	}
+
+	var latest float64
+	if err := fixture.db.QueryRow(`SELECT latest_known_chapter FROM trackers WHERE id = ?`, fixture.trackerID).Scan(&latest); err != nil {
+		t.Fatalf("load latest chapter: %v", err)
+	}
+	if latest != 124 {
+		t.Fatalf("expected latest chapter 124 from new primary, got %v", latest)
+	}
+}
+
+func TestUpdateFromFormPinsChosenPrimary(t *testing.T) {
+	fixture := setupPrimarySwitchFixture(t)
+
+	status, body := fixture.postEdit(t, url.Values{"auto_primary": {"0"}})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	if strings.Contains(body, "confirm_primary_switch") {
+		t.Fatalf("expected pinned save to skip confirmation")
+	}
+
+	sourceID, sourceURL := fixture.storedPrimary(t)
+	if sourceID != fixture.mangaDexID || sourceURL != fixture.mangaDexURL {
+		t.Fatalf("expected pinned mangadex primary, got source %d url %q", sourceID, sourceURL)
+	}
+
+	var linkedCount int
+	if err := fixture.db.QueryRow(`SELECT COUNT(*) FROM tracker_sources WHERE tracker_id = ?`, fixture.trackerID).Scan(&linkedCount); err != nil {
+		t.Fatalf("count linked sources: %v", err)
+	}
+	if linkedCount != 2 {
+		t.Fatalf("expected both linked sources saved, got %d", linkedCount)
+	}
+}
+
+func TestUpdateFromFormRanksPrimaryWithChapterOffsets(t *testing.T) {
+	fixture := setupPrimarySwitchFixture(t)
+
+	// MangaDex's 120 is chapter 125 elsewhere, so it stays ahead of
+	// MangaFire's 124 and no switch is offered.
+	status, body := fixture.postEdit(t, url.Values{"linked_sources_json": {fixture.linkedSourcesJSON(5, 0)}})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	if strings.Contains(body, "confirm_primary_switch") {
+		t.Fatalf("expected the offset to keep mangadex ahead without asking, got %s", body)
+	}
+	if sourceID, _ := fixture.storedPrimary(t); sourceID != fixture.mangaDexID {
+		t.Fatalf("expected mangadex to stay primary, got source %d", sourceID)
+	}
+
+	var offset, latest float64
+	if err := fixture.db.QueryRow(`SELECT chapter_offset FROM tracker_sources WHERE tracker_id = ? AND source_id = ?`, fixture.trackerID, fixture.mangaDexID).Scan(&offset); err != nil {
+		t.Fatalf("load chapter offset: %v", err)
+	}
+	if err := fixture.db.QueryRow(`SELECT latest_known_chapter FROM trackers WHERE id = ?`, fixture.trackerID).Scan(&latest); err != nil {
+		t.Fatalf("load latest chapter: %v", err)
+	}
+	if offset != 5 || latest != 120 {
+		t.Fatalf("expected offset 5 saved and raw latest 120 kept, got %v and %v", offset, latest)
+	}
+}
+
+func TestUpdateFromFormMovesLastReadWithTheSwitchedPrimary(t *testing.T) {
+	fixture := setupPrimarySwitchFixture(t)
+
+	// MangaFire numbers two chapters lower, so its 124 is ahead of
+	// MangaDex's 120 and last read moves into its numbering.
+	status, body := fixture.postEdit(t, url.Values{
+		"linked_sources_json":    {fixture.linkedSourcesJSON(0, 2)},
+		"last_read_chapter":      {"110"},
+		"confirm_primary_switch": {"1"},
+	})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	if sourceID, _ := fixture.storedPrimary(t); sourceID != fixture.mangaFireID {
+		t.Fatalf("expected mangafire to become primary, got source %d", sourceID)
+	}
+	var lastRead float64
+	if err := fixture.db.QueryRow(`SELECT last_read_chapter FROM trackers WHERE id = ?`, fixture.trackerID).Scan(&lastRead); err != nil {
+		t.Fatalf("load last read chapter: %v", err)
+	}
+	if lastRead != 108 {
+		t.Fatalf("expected last read 110 on mangadex to be 108 on mangafire, got %v", lastRead)
+	}
+}
+
+func TestUpdateFromFormRejectsOutOfRangeChapterOffset(t *testing.T) {
+	fixture := setupPrimarySwitchFixture(t)
+
+	status, body := fixture.postEdit(t, url.Values{"linked_sources_json": {fixture.linkedSourcesJSON(5000, 0)}})
+	if status != fiber.StatusBadRequest || !strings.Contains(body, "Invalid linked source chapter offset") {
+		t.Fatalf("expected 400 for an out-of-range offset, got %d: %s", status, body)
+	}
+}
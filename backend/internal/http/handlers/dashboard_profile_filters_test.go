@@ -1,6 +1,8 @@
 package handlers_test
 
 import (
+	"database/sql"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -39,10 +41,12 @@ func TestEditTrackerDeletingOriginalLinkedSourcePromotesRemainingSource(t *testi
 	db, app, cleanup := setupTestApp(t)
 	defer cleanup()
 
+	mangaDexID, _ := sourceMetaByKey(t, db, "mangadex")
+	mangaFireID, _ := sourceMetaByKey(t, db, "mangafire")
 	result, err := db.Exec(`
 		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, 1, "Linked Source Switch", 1, "https://mangadex.org/title/original", "reading", 5.0, 10.0)
+	`, 1, "Linked Source Switch", mangaDexID, "https://mangadex.org/title/original", "reading", 5.0, 10.0)
 	if err != nil {
 		t.Fatalf("seed tracker: %v", err)
 	}
@@ -56,17 +60,17 @@ func TestEditTrackerDeletingOriginalLinkedSourcePromotesRemainingSource(t *testi
 		INSERT INTO tracker_sources (tracker_id, source_id, source_item_id, source_url)
 		VALUES (?, ?, ?, ?), (?, ?, ?, ?)
 	`,
-		trackerID, 1, "original", "https://mangadex.org/title/original",
-		trackerID, 2, "replacement", "https://mangafire.to/manga/100",
+		trackerID, mangaDexID, "original", "https://mangadex.org/title/original",
+		trackerID, mangaFireID, "replacement", "https://mangafire.to/manga/100",
 	)
 	if err != nil {
 		t.Fatalf("seed tracker sources: %v", err)
 	}
 
-	linkedJSON := `[{"sourceId":2,"sourceItemId":"replacement","sourceUrl":"https://mangafire.to/manga/100"}]`
+	linkedJSON := `[{"sourceId":` + strconv.FormatInt(mangaFireID, 10) + `,"sourceItemId":"replacement","sourceUrl":"https://mangafire.to/manga/100"}]`
 	form := url.Values{}
 	form.Set("title", "Linked Source Switch")
-	form.Set("source_id", "1")
+	form.Set("source_id", strconv.FormatInt(mangaDexID, 10))
 	form.Set("source_url", "https://mangadex.org/title/original")
 	form.Set("status", "reading")
 	form.Set("last_read_chapter", "5")
@@ -91,8 +95,8 @@ func TestEditTrackerDeletingOriginalLinkedSourcePromotesRemainingSource(t *testi
 		t.Fatalf("load updated tracker: %v", err)
 	}
 
-	if sourceID != 2 {
-		t.Fatalf("expected tracker source_id to switch to linked source 2, got %d", sourceID)
+	if sourceID != mangaFireID {
+		t.Fatalf("expected tracker source_id to switch to linked source %d, got %d", mangaFireID, sourceID)
 	}
 	if sourceURL != "https://mangafire.to/manga/100" {
 		t.Fatalf("expected tracker source_url to switch to linked source URL, got %s", sourceURL)
@@ -120,8 +124,8 @@ func TestEditTrackerDeletingOriginalLinkedSourcePromotesRemainingSource(t *testi
 	if len(items) != 1 {
 		t.Fatalf("expected exactly 1 linked source after deletion, got %d", len(items))
 	}
-	if items[0].sourceID != 2 {
-		t.Fatalf("expected remaining linked source id 2, got %d", items[0].sourceID)
+	if items[0].sourceID != mangaFireID {
+		t.Fatalf("expected remaining linked source id %d, got %d", mangaFireID, items[0].sourceID)
 	}
 }
 
@@ -156,11 +160,11 @@ func TestDashboardTagFilterUsesAndSemanticsAndRendersIcons(t *testing.T) {
 	}
 
 	_, err = db.Exec(`
-		INSERT INTO custom_tags (profile_id, name, icon_key)
-		VALUES (?, ?, ?), (?, ?, ?)
+		INSERT INTO custom_tags (profile_id, name, name_normalized, icon_key)
+		VALUES (?, ?, ?, ?), (?, ?, ?, ?)
 	`,
-		1, "favorite", "icon_1",
-		1, "priority", nil,
+		1, "favorite", "favorite", "icon_1",
+		1, "priority", "priority", nil,
 	)
 	if err != nil {
 		t.Fatalf("seed custom tags: %v", err)
@@ -238,6 +242,62 @@ func TestDashboardTagFilterUsesAndSemanticsAndRendersIcons(t *testing.T) {
 	}
 }
 
+func TestDashboardTagFilterIgnoresDeletedTags(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?)
+	`,
+		1, "Priority Series", 1, "https://asuracomic.net/series/priority-series", "reading", 1.0, 3.0,
+		1, "Untagged Series", 1, "https://asuracomic.net/series/untagged-series", "reading", 1.0, 3.0,
+	)
+	if err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+	lastID, _ := result.LastInsertId()
+	priorityTrackerID := lastID - 1
+
+	tagResult, err := db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (?, ?, ?)`, 1, "priority", "priority")
+	if err != nil {
+		t.Fatalf("seed custom tag: %v", err)
+	}
+	priorityTagID, _ := tagResult.LastInsertId()
+	if _, err := db.Exec(`INSERT INTO tracker_tags (tracker_id, tag_id) VALUES (?, ?)`, priorityTrackerID, priorityTagID); err != nil {
+		t.Fatalf("seed tracker tag: %v", err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers?status=reading&tags=favorite", nil))
+	if err != nil {
+		t.Fatalf("dashboard trackers request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+	html := string(body)
+	if !strings.Contains(html, "Tag 'favorite' no longer exists") {
+		t.Fatalf("expected deleted tag notice, got %s", html)
+	}
+	if !strings.Contains(html, "Priority Series") || !strings.Contains(html, "Untagged Series") {
+		t.Fatalf("expected deleted tag to be dropped from the filter")
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers?status=reading&tags=favorite&tags=priority", nil))
+	if err != nil {
+		t.Fatalf("dashboard trackers mixed tag request failed: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	html = string(body)
+	if !strings.Contains(html, "Priority Series") || strings.Contains(html, "Untagged Series") {
+		t.Fatalf("expected existing tag to keep filtering when a deleted tag is dropped")
+	}
+	if !strings.Contains(html, "Tag 'favorite' no longer exists") {
+		t.Fatalf("expected deleted tag notice alongside existing tag filter")
+	}
+}
+
 func TestDashboardLinkedSitesFilterSupportsMultipleSelections(t *testing.T) {
 	db, app, cleanup := setupTestApp(t)
 	defer cleanup()
@@ -534,3 +594,305 @@ func TestProfileFilterTagsPrioritizesIconTags(t *testing.T) {
 		t.Fatalf("expected icon tags to render before no-icon tags")
 	}
 }
+
+func seedTagUsage(t *testing.T, db *sql.DB) (usedTagID int64, unusedTagID int64) {
+	t.Helper()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+		VALUES (1, 'Tagged One', 1, 'https://mangadex.org/title/tag-usage-one', 'reading'),
+			(1, 'Tagged Two', 1, 'https://mangadex.org/title/tag-usage-two', 'reading')
+	`)
+	if err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+	lastTrackerID, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("tracker id: %v", err)
+	}
+
+	for _, name := range []string{"used", "unused"} {
+		result, err := db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, ?, ?)`, name, name)
+		if err != nil {
+			t.Fatalf("seed custom tag %s: %v", name, err)
+		}
+		tagID, err := result.LastInsertId()
+		if err != nil {
+			t.Fatalf("custom tag id: %v", err)
+		}
+		if name == "used" {
+			usedTagID = tagID
+		} else {
+			unusedTagID = tagID
+		}
+	}
+	if _, err := db.Exec(`
+		INSERT INTO tracker_tags (tracker_id, tag_id) VALUES (?, ?), (?, ?)
+	`, lastTrackerID-1, usedTagID, lastTrackerID, usedTagID); err != nil {
+		t.Fatalf("seed tracker tags: %v", err)
+	}
+	return usedTagID, unusedTagID
+}
+
+func TestProfileMenuShowsTagUsageCounts(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	seedTagUsage(t, db)
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/profile/menu?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("profile menu request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read profile menu body: %v", err)
+	}
+	html := string(body)
+
+	if !strings.Contains(html, `data-tag-name="used"`) || !strings.Contains(html, `onclick="window.applyProfileTagFilter(this)">2</button>`) {
+		t.Fatalf("expected used tag to show a clickable count of 2, got %s", html)
+	}
+	if !strings.Contains(html, `title="No trackers use this tag">0</span>`) {
+		t.Fatalf("expected unused tag to show a count of 0")
+	}
+	if !strings.Contains(html, "Delete unused (1)") {
+		t.Fatalf("expected delete unused action for the one unused tag")
+	}
+}
+
+func TestDeleteUnusedTagsFromMenuKeepsUsedTags(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	usedTagID, unusedTagID := seedTagUsage(t, db)
+	if _, err := db.Exec(`INSERT INTO custom_tags (profile_id, name) VALUES (2, 'other-profile-unused')`); err != nil {
+		t.Fatalf("seed other profile tag: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/profile/tags/delete-unused?profile=profile1", nil)
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("delete unused tags request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+	}
+	if hxTrigger := res.Header.Get("HX-Trigger"); !strings.Contains(hxTrigger, "\"profileTagsChanged\":true") {
+		t.Fatalf("expected HX-Trigger to include profileTagsChanged, got %q", hxTrigger)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read delete unused body: %v", err)
+	}
+	if html := string(body); !strings.Contains(html, "Deleted 1 unused tag") || strings.Contains(html, "Delete unused (") {
+		t.Fatalf("expected removal count and no remaining unused action, got %s", html)
+	}
+
+	var remaining int
+	if err := db.QueryRow(`SELECT COUNT(1) FROM custom_tags WHERE id = ?`, unusedTagID).Scan(&remaining); err != nil || remaining != 0 {
+		t.Fatalf("expected unused tag to be deleted, count=%d err=%v", remaining, err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(1) FROM tracker_tags WHERE tag_id = ?`, usedTagID).Scan(&remaining); err != nil || remaining != 2 {
+		t.Fatalf("expected used tag to keep both trackers, count=%d err=%v", remaining, err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(1) FROM custom_tags WHERE profile_id = 2`).Scan(&remaining); err != nil || remaining != 1 {
+		t.Fatalf("expected other profile's unused tag to be left alone, count=%d err=%v", remaining, err)
+	}
+
+	again, err := app.Test(httptest.NewRequest(http.MethodPost, "/dashboard/profile/tags/delete-unused?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("repeat delete unused request failed: %v", err)
+	}
+	againBody, _ := io.ReadAll(again.Body)
+	if !strings.Contains(string(againBody), "No unused tags to delete") {
+		t.Fatalf("expected nothing left to delete, got %s", string(againBody))
+	}
+}
+
+func TestCreateTagFromMenuWithQuotedNameSendsValidHXTrigger(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	form := url.Values{}
+	form.Set("tag_name", `the "good" one`)
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/profile/tags?profile=profile1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("create tag request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+	}
+
+	var events map[string]any
+	if err := json.Unmarshal([]byte(res.Header.Get("HX-Trigger")), &events); err != nil {
+		t.Fatalf("expected HX-Trigger to be valid JSON, got %q: %v", res.Header.Get("HX-Trigger"), err)
+	}
+	if events["trackersChanged"] != true || events["profileTagsChanged"] != true {
+		t.Fatalf("expected trackersChanged and profileTagsChanged, got %v", events)
+	}
+}
+
+func TestProfileResolverNormalizesKeysAndRejectsMalformedOnes(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	getTrackers := func(target string, header string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if header != "" {
+			req.Header.Set("X-Profile-Key", header)
+		}
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("list trackers request failed: %v", err)
+		}
+		var payload struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&payload)
+		return res.StatusCode, payload.Message
+	}
+
+	if status, _ := getTrackers("/v1/trackers?profile=%20Profile2%20", ""); status != http.StatusOK {
+		t.Fatalf("expected a key in another case to resolve, got %d", status)
+	}
+	if status, message := getTrackers("/v1/trackers?profile=nobody", ""); status != http.StatusBadRequest || message != "invalid profile" {
+		t.Fatalf("expected an unknown key to be refused as unknown, got %d %q", status, message)
+	}
+	for _, key := range []string{"my%20list", "caf%C3%A9", "ab"} {
+		status, message := getTrackers("/v1/trackers?profile="+key, "")
+		if status != http.StatusBadRequest || !strings.HasPrefix(message, "invalid profile key") {
+			t.Fatalf("expected %s to be refused as malformed, got %d %q", key, status, message)
+		}
+	}
+	if status, message := getTrackers("/v1/trackers", "my list"); status != http.StatusBadRequest || !strings.HasPrefix(message, "invalid profile key") {
+		t.Fatalf("expected a malformed X-Profile-Key to be refused, got %d %q", status, message)
+	}
+
+	form := url.Values{}
+	form.Set("profile", "PROFILE2")
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/profile/switch", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("switch profile request failed: %v", err)
+	}
+	if location := res.Header.Get("Location"); location != "/dashboard?profile=profile2" {
+		t.Fatalf("expected the switch to redirect to the canonical key, got %q", location)
+	}
+}
+
+func TestDashboardFilterPresetsSaveAndPrefillTheFilters(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if _, err := db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, 'priority', 'priority')`); err != nil {
+		t.Fatalf("seed custom tag: %v", err)
+	}
+	mangaFireID, mangaFireName := sourceMetaByKey(t, db, "mangafire")
+	sites := strconv.FormatInt(mangaFireID, 10)
+
+	form := url.Values{}
+	form.Set("preset_name", "Weekend")
+	form.Set("q", "tower")
+	form.Set("status", "all")
+	form.Set("sort", "rating")
+	form.Add("tags", "priority")
+	form.Add("tags", "favorite|priority")
+	form.Add("sites", sites)
+	form.Set("profile", "profile1")
+	form.Set("page", "3")
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/filter-presets?profile=profile1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("save preset request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+	}
+	chipURL := "/dashboard?profile=profile1&amp;q=tower&amp;sites=" + sites + "&amp;sort=rating&amp;status=all&amp;tags=priority&amp;tags=favorite%7Cpriority"
+	if !strings.Contains(string(body), "Weekend") || !strings.Contains(string(body), chipURL) {
+		t.Fatalf("expected the preset chip linking to its filters, got %s", string(body))
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/dashboard/filter-presets?profile=profile1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if res, err = app.Test(req); err != nil || res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a taken name rejected, got %v %v", res, err)
+	}
+
+	// The preset outlived its tag and site: the page keeps the rest and
+	// says what it left out.
+	if _, err := db.Exec(`DELETE FROM custom_tags WHERE name = 'priority'`); err != nil {
+		t.Fatalf("delete tag: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE sources SET enabled = 0 WHERE id = ?`, mangaFireID); err != nil {
+		t.Fatalf("disable source: %v", err)
+	}
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard?profile=profile1&q=tower&sites="+sites+"&sort=rating&status=all&tags=priority&tags=favorite%7Cpriority", nil))
+	if err != nil {
+		t.Fatalf("dashboard request failed: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	html := string(body)
+	for _, want := range []string{
+		`name="q" placeholder="Title" value="tower"`,
+		`<option value="all" title="All statuses" selected>`,
+		`<option value="rating" title="Rating" selected>`,
+		"Tag 'priority' no longer exists",
+		"Tag 'favorite' no longer exists",
+		"Site '" + mangaFireName + "' is no longer enabled",
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q on the page, got %s", want, html)
+		}
+	}
+	if strings.Count(html, "Tag 'priority' no longer exists") != 1 {
+		t.Fatalf("expected a tag named twice reported once")
+	}
+	if strings.Contains(html, `<option value="reading" title="Reading" selected>`) {
+		t.Fatalf("expected the preset's status to replace the default")
+	}
+
+	var presetID int64
+	if err := db.QueryRow(`SELECT id FROM filter_presets WHERE name = 'Weekend'`).Scan(&presetID); err != nil {
+		t.Fatalf("load preset: %v", err)
+	}
+	res, err = app.Test(httptest.NewRequest(http.MethodPost, "/dashboard/filter-presets/"+strconv.FormatInt(presetID, 10)+"/delete?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("delete preset request failed: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || strings.Contains(string(body), "Weekend") {
+		t.Fatalf("expected the chip gone, got %d %s", res.StatusCode, string(body))
+	}
+}
+
+func TestProfileFilterTagsPartialKeepsCheckedTags(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if _, err := db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, 'Priority', 'priority'), (1, 'Later', 'later')`); err != nil {
+		t.Fatalf("seed custom tags: %v", err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/profile/filter-tags?profile=profile1&tags=priority", nil))
+	if err != nil {
+		t.Fatalf("filter tags request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	html := string(body)
+	if !strings.Contains(html, `value="Priority" checked`) || strings.Contains(html, `value="Later" checked`) {
+		t.Fatalf("expected only the requested tag checked, got %s", html)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
-	"net/url"
+	"net/mail"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -13,6 +13,9 @@ import (
 	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/profilekey"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/timefmt"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -24,48 +27,81 @@ const (
 )
 
 func (h *DashboardHandler) Page(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
-	profiles, err := h.profileResolver.ListProfiles()
+	profiles, err := pageCtx.Profiles()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load profiles")
+		return sendPageError(c, err)
 	}
 
-	profileTags, err := h.trackerRepo.ListProfileTags(activeProfile.ID)
+	profileTags, err := pageCtx.ProfileTags()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load profile tags")
+		return sendPageError(c, err)
 	}
 
-	linkedSites, err := h.listLinkedSourcesForProfile(activeProfile.ID)
+	linkedSites, err := pageCtx.LinkedSites()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked sites")
+		return sendPageError(c, err)
 	}
-	selectedLinkedSiteIDs := sourceIDFilterMap(parseSourceIDsFromQuery(c))
+	// A preset chip or bookmark carries the filters in the URL; the ones
+	// that no longer apply are left out and named in a notice.
+	filters, err := applyFilterPreset(c.UserContext(), h.trackerRepo, activeProfile.ID, linkedSites, filterPresetParamsFromArgs(c.Context().QueryArgs()))
+	if err != nil {
+		return sendPageError(c, loadFailed("Failed to load profile tags", err))
+	}
+	ignoredSites, err := h.ignoredSiteNames(c.UserContext(), filters.IgnoredSites)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	selectedTags, extraTagTerms := selectedFilterTags(profileTags, filters.Params.Tags)
+	filterPresets, err := h.loadFilterPresets(c, activeProfile)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	sortDefaults, err := h.profileRepo.StatusSortDefaults(c.UserContext(), activeProfile.ID)
+	if err != nil {
+		return sendPageError(c, loadFailed("Failed to load sort defaults", err))
+	}
+	h.markDashboardSeen(c.UserContext(), activeProfile.ID, isReadOnly(c))
 
 	c.Set("Cache-Control", "no-store, no-cache, must-revalidate")
 	c.Set("Pragma", "no-cache")
 	c.Set("Expires", "0")
 	data := dashboardPageData{
-		Statuses:              []string{"all", "reading", "completed", "on_hold", "dropped", "plan_to_read"},
-		Sorts:                 []string{"latest_known_chapter", "last_read_at", "rating"},
+		Statuses:              dashboardStatuses,
+		Sorts:                 dashboardSorts,
+		ViewModes:             dashboardViewModes,
 		Profiles:              profiles,
 		ActiveProfile:         *activeProfile,
 		RenameValue:           activeProfile.Name,
 		ProfileTags:           profileTags,
 		LinkedSites:           linkedSites,
-		SelectedLinkedSiteIDs: selectedLinkedSiteIDs,
+		SelectedLinkedSiteIDs: sourceIDFilterMap(filters.Params.Sites),
+		ScrapingPaused:        h.scrapingAllowed() != nil,
+		LogoutEnabled:         c.Locals(authenticatedLocalKey) == true,
+		RecentAdditions:       h.recentAdditionsDays > 0,
+		ReadOnly:              isReadOnly(c),
+		Filters:               filters.Params,
+		SelectedTags:          selectedTags,
+		ExtraTagTerms:         extraTagTerms,
+		IgnoredTags:           filters.IgnoredTags,
+		IgnoredSites:          ignoredSites,
+		FilterPresets:         filterPresets,
+		SortDefaultLabels:     sortDefaultLabels(sortDefaults),
 	}
 	return h.render(c, "dashboard_page.html", data)
 }
 
 func (h *DashboardHandler) RenameProfileFromForm(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	name := strings.TrimSpace(c.FormValue("profile_name"))
 	if name == "" {
@@ -75,45 +111,54 @@ func (h *DashboardHandler) RenameProfileFromForm(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).SendString("Profile name must be 40 characters or less")
 	}
 
-	if _, err := h.profileRepo.Rename(activeProfile.ID, name); err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to rename profile")
+	if _, err := h.profileRepo.Rename(c.UserContext(), activeProfile.ID, name); err != nil {
+		return serverError(c, "Failed to rename profile", err)
 	}
 
-	return c.Redirect("/dashboard?profile="+url.QueryEscape(activeProfile.Key), fiber.StatusSeeOther)
+	return c.Redirect(h.appURL(profileURL("/dashboard", activeProfile.Key)), fiber.StatusSeeOther)
 }
 
 func (h *DashboardHandler) ProfileMenuModal(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
 
-	return h.renderProfileMenu(c, activeProfile, "", "")
+	return h.renderProfileMenu(c, pageCtx, "", nil)
 }
 
 func (h *DashboardHandler) ProfileFilterTagsPartial(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
 
-	profileTags, err := h.trackerRepo.ListProfileTags(activeProfile.ID)
+	profileTags, err := pageCtx.ProfileTags()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load profile tags")
+		return sendPageError(c, err)
+	}
+
+	terms := make([]string, 0)
+	for _, filter := range parseTagFiltersFromQuery(c) {
+		terms = append(terms, tagFilterTerm(filter))
 	}
+	selectedTags, _ := selectedFilterTags(profileTags, terms)
 
-	return h.render(c, "profile_filter_tags_partial.html", profileFilterTagsData{ProfileTags: profileTags})
+	return h.render(c, "profile_filter_tags_partial.html", profileFilterTagsData{
+		ProfileTags:  profileTags,
+		SelectedTags: selectedTags,
+	})
 }
 
 func (h *DashboardHandler) ProfileFilterLinkedSitesPartial(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
 
-	linkedSites, err := h.listLinkedSourcesForProfile(activeProfile.ID)
+	linkedSites, err := pageCtx.LinkedSites()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked sites")
+		return sendPageError(c, err)
 	}
 
 	return h.render(c, "profile_filter_linked_sites_partial.html", profileFilterLinkedSitesData{
@@ -123,19 +168,19 @@ func (h *DashboardHandler) ProfileFilterLinkedSitesPartial(c *fiber.Ctx) error {
 }
 
 func (h *DashboardHandler) SwitchProfileFromMenu(c *fiber.Ctx) error {
-	profileKey := strings.TrimSpace(string(c.Request().PostArgs().Peek("profile")))
+	profileKey := profilekey.Normalize(string(c.Request().PostArgs().Peek("profile")))
 	if profileKey == "" {
 		return c.Status(fiber.StatusBadRequest).SendString("Profile is required")
 	}
 
-	profiles, err := h.profileResolver.ListProfiles()
+	profiles, err := h.profileResolver.ListProfiles(c.UserContext())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load profiles")
+		return serverError(c, "Failed to load profiles", err)
 	}
 
 	for _, profile := range profiles {
 		if profile.Key == profileKey {
-			return c.Redirect("/dashboard?profile="+url.QueryEscape(profileKey), fiber.StatusSeeOther)
+			return c.Redirect(h.appURL(profileURL("/dashboard", profileKey)), fiber.StatusSeeOther)
 		}
 	}
 
@@ -143,17 +188,18 @@ func (h *DashboardHandler) SwitchProfileFromMenu(c *fiber.Ctx) error {
 }
 
 func (h *DashboardHandler) CreateTagFromMenu(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	tagName := strings.TrimSpace(c.FormValue("tag_name"))
 	if tagName == "" {
 		return c.Status(fiber.StatusBadRequest).SendString("Tag name is required")
 	}
-	if len(tagName) > 40 {
-		return c.Status(fiber.StatusBadRequest).SendString("Tag name must be 40 characters or less")
+	if len(tagName) > maxTagNameLength {
+		return c.Status(fiber.StatusBadRequest).SendString(fmt.Sprintf("Tag name must be %d characters or less", maxTagNameLength))
 	}
 
 	var iconKey *string
@@ -164,25 +210,26 @@ func (h *DashboardHandler) CreateTagFromMenu(c *fiber.Ctx) error {
 		iconKey = &rawIcon
 	}
 
-	if _, err := h.trackerRepo.CreateProfileTag(activeProfile.ID, tagName, iconKey); err != nil {
-		lowerErr := strings.ToLower(err.Error())
-		if strings.Contains(lowerErr, "unique") {
+	if _, err := h.trackerRepo.CreateProfileTag(c.UserContext(), activeProfile.ID, tagName, iconKey); err != nil {
+		if isUniqueViolation(err) {
 			if iconKey != nil {
 				return c.Status(fiber.StatusBadRequest).SendString("That icon is already used by another tag")
 			}
 			return c.Status(fiber.StatusBadRequest).SendString("A tag with that name already exists")
 		}
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to save tag")
+		return serverError(c, "Failed to save tag", err)
 	}
 
-	return h.renderProfileMenu(c, activeProfile, "Tag saved", `{"trackersChanged":true,"profileTagsChanged":true}`)
+	h.editForms.forgetProfile(activeProfile.ID)
+	return h.renderProfileMenu(c, pageCtx, "Tag saved", map[string]any{"trackersChanged": true, "profileTagsChanged": true})
 }
 
 func (h *DashboardHandler) RenameTagFromMenu(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	tagID, err := strconv.ParseInt(strings.TrimSpace(c.FormValue("tag_id")), 10, 64)
 	if err != nil || tagID <= 0 {
@@ -193,109 +240,298 @@ func (h *DashboardHandler) RenameTagFromMenu(c *fiber.Ctx) error {
 	if tagName == "" {
 		return c.Status(fiber.StatusBadRequest).SendString("Tag name is required")
 	}
-	if len(tagName) > 40 {
-		return c.Status(fiber.StatusBadRequest).SendString("Tag name must be 40 characters or less")
+	if len(tagName) > maxTagNameLength {
+		return c.Status(fiber.StatusBadRequest).SendString(fmt.Sprintf("Tag name must be %d characters or less", maxTagNameLength))
 	}
 
-	renamed, err := h.trackerRepo.RenameProfileTag(activeProfile.ID, tagID, tagName)
+	renamed, err := h.trackerRepo.RenameProfileTag(c.UserContext(), activeProfile.ID, tagID, tagName)
 	if err != nil {
-		lowerErr := strings.ToLower(err.Error())
-		if strings.Contains(lowerErr, "unique") {
+		if isUniqueViolation(err) {
 			return c.Status(fiber.StatusBadRequest).SendString("A tag with that name already exists")
 		}
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to rename tag")
+		return serverError(c, "Failed to rename tag", err)
 	}
 	if !renamed {
 		return c.Status(fiber.StatusBadRequest).SendString("Tag not found")
 	}
 
-	return h.renderProfileMenu(c, activeProfile, "Tag renamed", `{"trackersChanged":true,"profileTagsChanged":true}`)
+	h.editForms.forgetProfile(activeProfile.ID)
+	return h.renderProfileMenu(c, pageCtx, "Tag renamed", map[string]any{"trackersChanged": true, "profileTagsChanged": true})
 }
 
 func (h *DashboardHandler) DeleteTagFromMenu(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	tagID, err := strconv.ParseInt(strings.TrimSpace(c.FormValue("tag_id")), 10, 64)
 	if err != nil || tagID <= 0 {
 		return c.Status(fiber.StatusBadRequest).SendString("Invalid tag")
 	}
 
-	deleted, err := h.trackerRepo.DeleteProfileTag(activeProfile.ID, tagID)
+	deleted, err := h.trackerRepo.DeleteProfileTag(c.UserContext(), activeProfile.ID, tagID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to delete tag")
+		return serverError(c, "Failed to delete tag", err)
 	}
 	if !deleted {
 		return c.Status(fiber.StatusBadRequest).SendString("Tag not found")
 	}
 
-	return h.renderProfileMenu(c, activeProfile, "Tag deleted", `{"trackersChanged":true,"profileTagsChanged":true}`)
+	h.editForms.forgetProfile(activeProfile.ID)
+	return h.renderProfileMenu(c, pageCtx, "Tag deleted", map[string]any{"trackersChanged": true, "profileTagsChanged": true})
+}
+
+func (h *DashboardHandler) DeleteUnusedTagsFromMenu(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	deleted, err := h.trackerRepo.DeleteUnusedProfileTags(c.UserContext(), activeProfile.ID)
+	if err != nil {
+		return serverError(c, "Failed to delete unused tags", err)
+	}
+	if deleted == 0 {
+		return h.renderProfileMenu(c, pageCtx, "No unused tags to delete", nil)
+	}
+
+	message := fmt.Sprintf("Deleted %d unused tags", deleted)
+	if deleted == 1 {
+		message = "Deleted 1 unused tag"
+	}
+	h.editForms.forgetProfile(activeProfile.ID)
+	return h.renderProfileMenu(c, pageCtx, message, map[string]any{"trackersChanged": true, "profileTagsChanged": true})
 }
 
 func (h *DashboardHandler) SaveSourceLogosFromMenu(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
-	linkedSites, err := h.listLinkedSourcesForProfile(activeProfile.ID)
+	linkedSites, err := pageCtx.LinkedSites()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked sites")
+		return sendPageError(c, err)
 	}
 	if len(linkedSites) == 0 {
-		return h.renderProfileMenu(c, activeProfile, "No sites available to configure", "")
+		return h.renderProfileMenu(c, pageCtx, "No sites available to configure", nil)
 	}
 
-	existingLogosBySourceID, err := h.sourceRepo.ListProfileSourceLogoURLs(activeProfile.ID)
+	existingLogosBySourceID, err := pageCtx.SourceLogos()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked site logos")
+		return sendPageError(c, err)
 	}
 
 	logoBySourceID, err := readSourceLogoUpdates(c, activeProfile.ID, linkedSites, existingLogosBySourceID)
 	if err != nil {
-		return h.renderProfileMenu(c, activeProfile, err.Error(), "")
+		return h.renderProfileMenu(c, pageCtx, err.Error(), nil)
 	}
 
-	if err := h.sourceRepo.UpsertProfileSourceLogoURLs(activeProfile.ID, logoBySourceID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to save linked site logos")
+	if err := h.sourceRepo.UpsertProfileSourceLogoURLs(c.UserContext(), activeProfile.ID, logoBySourceID); err != nil {
+		return serverError(c, "Failed to save linked site logos", err)
 	}
+	pageCtx.ForgetSourceLogos()
 
-	return h.renderProfileMenu(c, activeProfile, "Linked site logos saved", `{"trackersChanged":true}`)
+	return h.renderProfileMenu(c, pageCtx, "Linked site logos saved", map[string]any{"trackersChanged": true})
 }
 
-func (h *DashboardHandler) listLinkedSourcesForProfile(_ int64) ([]models.Source, error) {
-	enabledSources, err := h.sourceRepo.ListEnabled()
+// SaveSourceNoteFromMenu sets or clears a site's status note from the
+// profile menu. Notes are shared by all profiles.
+func (h *DashboardHandler) SaveSourceNoteFromMenu(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return nil, fmt.Errorf("list enabled sources: %w", err)
+		return sendPageError(c, err)
 	}
-	return enabledSources, nil
+
+	sourceID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || sourceID <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid site")
+	}
+
+	note, err := validateSourceNote(c.FormValue("status_note"))
+	if err != nil {
+		return h.renderProfileMenu(c, pageCtx, "Site note must be "+strconv.Itoa(maxSourceNoteLength)+" characters or less", nil)
+	}
+
+	updated, err := h.sourceRepo.SetStatusNote(c.UserContext(), sourceID, note)
+	if err != nil {
+		return serverError(c, "Failed to save site note", err)
+	}
+	if !updated {
+		return c.Status(fiber.StatusNotFound).SendString("Site not found")
+	}
+
+	message := "Site note saved"
+	if note == "" {
+		message = "Site note cleared"
+	}
+	return h.renderProfileMenu(c, pageCtx, message, map[string]any{"trackersChanged": true})
 }
 
-func (h *DashboardHandler) renderProfileMenu(c *fiber.Ctx, activeProfile *models.Profile, message string, hxTrigger string) error {
-	profiles, err := h.profileResolver.ListProfiles()
+func (h *DashboardHandler) SaveDigestFromMenu(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load profiles")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
-	profileTags, err := h.trackerRepo.ListProfileTags(activeProfile.ID)
+	email := strings.TrimSpace(c.FormValue("digest_email"))
+	if email == "" {
+		return c.Status(fiber.StatusBadRequest).SendString("Digest email is required")
+	}
+	parsedAddress, err := mail.ParseAddress(email)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load profile tags")
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid digest email")
 	}
 
-	linkedSites, err := h.listLinkedSourcesForProfile(activeProfile.ID)
+	hourUTC, err := strconv.Atoi(strings.TrimSpace(c.FormValue("digest_hour_utc")))
+	if err != nil || hourUTC < 0 || hourUTC > 23 {
+		return c.Status(fiber.StatusBadRequest).SendString("Digest hour must be between 0 and 23")
+	}
+
+	enabled := strings.TrimSpace(c.FormValue("digest_enabled")) == "1"
+
+	tags, message, err := digestTagFilterFromForm(c, pageCtx)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked sites")
+		return sendPageError(c, err)
+	}
+	if message != "" {
+		return c.Status(fiber.StatusBadRequest).SendString(message)
 	}
 
-	sourceLogoURLs, err := h.sourceRepo.ListProfileSourceLogoURLs(activeProfile.ID)
+	if err := h.digestRepo.Upsert(activeProfile.ID, parsedAddress.Address, hourUTC, enabled, tags); err != nil {
+		return serverError(c, "Failed to save email digest", err)
+	}
+
+	return h.renderProfileMenu(c, pageCtx, "Email digest saved", nil)
+}
+
+// digestTagFilterFromForm reads the digest's include and exclude tags; an
+// empty choice filters nothing. It returns a message for the user when a
+// tag is not one of the profile's or the two are the same.
+func digestTagFilterFromForm(c *fiber.Ctx, pageCtx *pageContext) (repository.DigestTagFilter, string, error) {
+	profileTags, err := pageCtx.ProfileTags()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked site logos")
+		return repository.DigestTagFilter{}, "", err
+	}
+	include, ok := parseDigestTag(c.FormValue("digest_include_tag_id"), profileTags)
+	if !ok {
+		return repository.DigestTagFilter{}, "Digest tag not found in this profile", nil
 	}
+	exclude, ok := parseDigestTag(c.FormValue("digest_exclude_tag_id"), profileTags)
+	if !ok {
+		return repository.DigestTagFilter{}, "Digest tag not found in this profile", nil
+	}
+	if include != nil && exclude != nil && *include == *exclude {
+		return repository.DigestTagFilter{}, "Digest include and exclude tags must differ", nil
+	}
+	return repository.DigestTagFilter{IncludeTagID: include, ExcludeTagID: exclude}, "", nil
+}
 
-	if strings.TrimSpace(hxTrigger) != "" {
-		c.Set("HX-Trigger", hxTrigger)
+// parseDigestTag returns the tag id chosen in raw, nil for none. It
+// reports false when raw is not the id of one of profileTags.
+func parseDigestTag(raw string, profileTags []models.CustomTag) (*int64, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, true
+	}
+	tagID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	for _, tag := range profileTags {
+		if tag.ID == tagID {
+			return &tagID, true
+		}
+	}
+	return nil, false
+}
+
+// SavePollingFromMenu turns polling of the active profile's trackers on or
+// off. The global polling switch still applies on top of it.
+func (h *DashboardHandler) SavePollingFromMenu(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	enabled := strings.TrimSpace(c.FormValue("polling_enabled")) == "1"
+	if _, err := h.profileRepo.SetPollingEnabled(c.UserContext(), activeProfile.ID, enabled); err != nil {
+		return serverError(c, "Failed to save polling setting", err)
+	}
+	activeProfile.PollingEnabled = enabled
+
+	message := "Polling turned on"
+	if !enabled {
+		message = "Polling turned off"
+	}
+	return h.renderProfileMenu(c, pageCtx, message, nil)
+}
+
+func (h *DashboardHandler) renderProfileMenu(c *fiber.Ctx, pageCtx *pageContext, message string, hxTrigger map[string]any) error {
+	activeProfile := pageCtx.profile
+	profiles, err := pageCtx.Profiles()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+
+	tagUsage, err := h.trackerRepo.ListProfileTagsWithUsage(c.UserContext(), activeProfile.ID)
+	if err != nil {
+		return serverError(c, "Failed to load profile tags", err)
+	}
+	profileTags := make([]models.CustomTag, 0, len(tagUsage))
+	tagUsageCounts := make(map[int64]int, len(tagUsage))
+	unusedTagCount := 0
+	for _, item := range tagUsage {
+		profileTags = append(profileTags, item.Tag)
+		tagUsageCounts[item.Tag.ID] = item.TrackerCount
+		if item.TrackerCount == 0 {
+			unusedTagCount++
+		}
+	}
+
+	linkedSites, err := pageCtx.LinkedSites()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+
+	sourceLogoURLs, err := pageCtx.SourceLogos()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+
+	blacklistedSites, err := pageCtx.BlacklistedSources()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+
+	emailDigest, err := h.digestRepo.GetByProfileID(activeProfile.ID)
+	if err != nil {
+		return serverError(c, "Failed to load email digest", err)
+	}
+
+	lastPolledAt, err := h.profileRepo.LastPolledAt(c.UserContext(), activeProfile.ID)
+	if err != nil {
+		return serverError(c, "Failed to load polling state", err)
+	}
+
+	sortDefaults, err := h.profileRepo.StatusSortDefaults(c.UserContext(), activeProfile.ID)
+	if err != nil {
+		return serverError(c, "Failed to load sort defaults", err)
+	}
+
+	setHXTrigger(c, hxTrigger)
+
+	// Focus lands on the outcome of the change just made, if any, so it is
+	// read out before the rest of the menu.
+	autofocusID := "profile-menu-profile-select"
+	if message != "" {
+		autofocusID = "profile-feedback"
 	}
 
 	return h.render(c, "profile_menu_modal.html", profileMenuData{
@@ -304,13 +540,70 @@ func (h *DashboardHandler) renderProfileMenu(c *fiber.Ctx, activeProfile *models
 		RenameValue:       activeProfile.Name,
 		LinkedSites:       linkedSites,
 		SourceLogoURLs:    sourceLogoURLs,
+		BlacklistedSites:  blacklistedSites,
 		ProfileTags:       profileTags,
+		TagUsageCounts:    tagUsageCounts,
+		UnusedTagCount:    unusedTagCount,
 		TagIconKeys:       tagIconKeysOrdered,
 		AvailableIconKeys: availableTagIconKeys(profileTags),
+		Digest:            toProfileDigestView(emailDigest),
+		DigestHours:       digestHourOptions(),
+		Polling:           toProfilePollingView(activeProfile.PollingEnabled, lastPolledAt),
 		Message:           message,
+		AutofocusID:       autofocusID,
+		SortDefaults:      toProfileSortDefaultViews(sortDefaults),
+		SortDefaultSorts:  sortDefaultSorts,
 	})
 }
 
+func toProfileDigestView(item *models.ProfileEmailDigest) profileDigestView {
+	if item == nil {
+		return profileDigestView{HourUTC: 8}
+	}
+
+	view := profileDigestView{
+		Email:   item.Email,
+		HourUTC: item.HourUTC,
+		Enabled: item.Enabled,
+	}
+	if item.IncludeTagID != nil {
+		view.IncludeTagID = *item.IncludeTagID
+	}
+	if item.ExcludeTagID != nil {
+		view.ExcludeTagID = *item.ExcludeTagID
+	}
+	if item.LastSentAt != nil {
+		view.LastSentLabel = timefmt.FromNow(*item.LastSentAt, timefmt.Long)
+	}
+	return view
+}
+
+func toProfilePollingView(enabled bool, lastPolledAt *time.Time) profilePollingView {
+	summary := "polling: off"
+	if enabled {
+		summary = "polling: on"
+	}
+	if lastPolledAt == nil {
+		return profilePollingView{Enabled: enabled, Summary: summary + ", not checked yet"}
+	}
+
+	lastCheck := timefmt.FromNow(*lastPolledAt, timefmt.Compact)
+	if lastCheck == "now" {
+		lastCheck = "just now"
+	} else {
+		lastCheck += " ago"
+	}
+	return profilePollingView{Enabled: enabled, Summary: summary + ", last full check " + lastCheck}
+}
+
+func digestHourOptions() []int {
+	hours := make([]int, 24)
+	for hour := range hours {
+		hours[hour] = hour
+	}
+	return hours
+}
+
 func readSourceLogoUpdates(c *fiber.Ctx, profileID int64, linkedSites []models.Source, existingLogosBySourceID map[int64]string) (map[int64]string, error) {
 	logoBySourceID := make(map[int64]string, len(linkedSites))
 	for _, linkedSite := range linkedSites {
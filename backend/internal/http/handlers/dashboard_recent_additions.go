@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/timefmt"
+	"github.com/gofiber/fiber/v2"
+)
+
+// recentAdditionsLimit caps how many trackers the recently added strip
+// lists.
+const recentAdditionsLimit = 12
+
+type recentAdditionsPartialData struct {
+	Days     int
+	Items    []recentAdditionView
+	ReadOnly bool
+}
+
+type recentAdditionView struct {
+	ID        int64
+	Title     string
+	SourceURL string
+	AddedAgo  string
+	// NeedsTags and NeedsSources say what is still missing; either puts the
+	// tracker in the strip.
+	NeedsTags    bool
+	NeedsSources bool
+}
+
+// SetRecentAdditionsDays sets how many days a new tracker stays in the
+// dashboard's recently added strip while it has no tags or only one linked
+// source. 0, the default, turns the strip off.
+func (h *DashboardHandler) SetRecentAdditionsDays(days int) {
+	h.recentAdditionsDays = max(days, 0)
+}
+
+// RecentAdditionsPartial renders the recently added strip above the
+// trackers, or nothing when it is off or no new tracker needs setting up.
+func (h *DashboardHandler) RecentAdditionsPartial(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	data := recentAdditionsPartialData{Days: h.recentAdditionsDays, ReadOnly: isReadOnly(c)}
+	if h.recentAdditionsDays > 0 {
+		since := time.Now().UTC().AddDate(0, 0, -h.recentAdditionsDays)
+		items, err := h.trackerRepo.ListRecentIncomplete(c.UserContext(), activeProfile.ID, since, recentAdditionsLimit)
+		if err != nil {
+			return serverError(c, "Failed to load recently added trackers", err)
+		}
+		data.Items = make([]recentAdditionView, 0, len(items))
+		for _, item := range items {
+			data.Items = append(data.Items, recentAdditionView{
+				ID:           item.ID,
+				Title:        item.Title,
+				SourceURL:    item.SourceURL,
+				AddedAgo:     timefmt.FromNow(item.CreatedAt, timefmt.Compact),
+				NeedsTags:    item.TagCount == 0,
+				NeedsSources: item.LinkedSourceCount <= 1,
+			})
+		}
+	}
+
+	return h.render(c, "recent_additions_partial.html", data)
+}
+
+// DismissRecentAddition marks the tracker's setup complete, so it leaves the
+// recently added strip before its window runs out, and renders the strip
+// again.
+func (h *DashboardHandler) DismissRecentAddition(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+
+	marked, err := h.trackerRepo.MarkSetupComplete(c.UserContext(), activeProfile.ID, id)
+	if err != nil {
+		return serverError(c, "Failed to dismiss tracker", err)
+	}
+	if !marked {
+		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
+	}
+
+	return h.RecentAdditionsPartial(c)
+}
@@ -0,0 +1,141 @@
+package handlers_test
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+)
+
+// seedRecentTracker adds a tracker with tagCount tags and sourceCount
+// tracker_sources rows, created createdAt (an SQLite datetime expression).
+func seedRecentTracker(t *testing.T, db *sql.DB, profileID int64, title string, createdAt string, tagCount int, sourceCount int) int64 {
+	t.Helper()
+	slug := strings.ToLower(strings.ReplaceAll(title, " ", "-"))
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, created_at)
+		VALUES (?, ?, 1, ?, 'reading', `+createdAt+`)
+	`, profileID, title, "https://asuracomic.net/series/"+slug)
+	if err != nil {
+		t.Fatalf("seed tracker %s: %v", title, err)
+	}
+	trackerID, _ := result.LastInsertId()
+	for index := 0; index < tagCount; index++ {
+		tag, err := db.Exec(`INSERT INTO custom_tags (profile_id, name) VALUES (?, ?)`, profileID, slug+"-tag-"+strconv.Itoa(index))
+		if err != nil {
+			t.Fatalf("seed tag: %v", err)
+		}
+		tagID, _ := tag.LastInsertId()
+		if _, err := db.Exec(`INSERT INTO tracker_tags (tracker_id, tag_id) VALUES (?, ?)`, trackerID, tagID); err != nil {
+			t.Fatalf("seed tracker tag: %v", err)
+		}
+	}
+	for index := 0; index < sourceCount; index++ {
+		if _, err := db.Exec(`INSERT INTO tracker_sources (tracker_id, source_id, source_url) VALUES (?, 1, ?)`, trackerID, "https://asuracomic.net/series/"+slug+"-"+strconv.Itoa(index)); err != nil {
+			t.Fatalf("seed tracker source: %v", err)
+		}
+	}
+	return trackerID
+}
+
+func TestRecentAdditionsListNewTrackersThatNeedSetup(t *testing.T) {
+	db, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", RecentAdditionsDays: 7})
+	defer cleanup()
+
+	seedRecentTracker(t, db, 1, "Bare Blade", "CURRENT_TIMESTAMP", 0, 1)
+	seedRecentTracker(t, db, 1, "Finished Blade", "CURRENT_TIMESTAMP", 1, 2)
+	seedRecentTracker(t, db, 1, "Lonely Blade", "datetime('now', '-2 days')", 1, 1)
+	seedRecentTracker(t, db, 1, "Untagged Blade", "datetime('now', '-6 days')", 0, 2)
+	seedRecentTracker(t, db, 1, "Old Blade", "datetime('now', '-30 days')", 0, 0)
+	seedRecentTracker(t, db, 2, "Other Profile Blade", "CURRENT_TIMESTAMP", 0, 0)
+
+	status, body := getEditForm(t, app, "/dashboard/recent-additions?profile=profile1")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	for _, title := range []string{"Bare Blade", "Lonely Blade", "Untagged Blade"} {
+		if !strings.Contains(body, title) {
+			t.Fatalf("expected %s in the strip, got %s", title, body)
+		}
+	}
+	for _, title := range []string{"Finished Blade", "Old Blade", "Other Profile Blade"} {
+		if strings.Contains(body, title) {
+			t.Fatalf("expected %s to be left out of the strip, got %s", title, body)
+		}
+	}
+	if strings.Index(body, "Bare Blade") > strings.Index(body, "Lonely Blade") {
+		t.Fatalf("expected the newest tracker first, got %s", body)
+	}
+	if !strings.Contains(body, "no tags · one site") {
+		t.Fatalf("expected the strip to say what is missing, got %s", body)
+	}
+
+	_, page := getEditForm(t, app, "/dashboard?profile=profile1")
+	if !strings.Contains(page, `hx-get="/dashboard/recent-additions"`) {
+		t.Fatalf("expected the dashboard to load the strip, got %s", page)
+	}
+}
+
+func TestRecentAdditionsAreCappedAndCanBeTurnedOff(t *testing.T) {
+	db, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", RecentAdditionsDays: 7})
+	defer cleanup()
+
+	for index := 0; index < 14; index++ {
+		seedRecentTracker(t, db, 1, "Import "+strconv.Itoa(index), "CURRENT_TIMESTAMP", 0, 0)
+	}
+	_, body := getEditForm(t, app, "/dashboard/recent-additions?profile=profile1")
+	if got := strings.Count(body, `class="recent-addition"`); got != 12 {
+		t.Fatalf("expected the strip to be capped at 12 trackers, got %d", got)
+	}
+
+	_, app, cleanupOff := setupTestApp(t)
+	defer cleanupOff()
+	status, body := getEditForm(t, app, "/dashboard/recent-additions?profile=profile1")
+	if status != http.StatusOK || strings.TrimSpace(body) != "" {
+		t.Fatalf("expected no strip without recent addition days, got %d: %s", status, body)
+	}
+	if _, page := getEditForm(t, app, "/dashboard?profile=profile1"); strings.Contains(page, "recent-additions") {
+		t.Fatalf("expected the dashboard not to load a turned off strip")
+	}
+}
+
+func TestDismissRecentAdditionMarksSetupComplete(t *testing.T) {
+	db, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", RecentAdditionsDays: 7})
+	defer cleanup()
+
+	dismissedID := seedRecentTracker(t, db, 1, "Dismissed Blade", "CURRENT_TIMESTAMP", 0, 0)
+	seedRecentTracker(t, db, 1, "Waiting Blade", "CURRENT_TIMESTAMP", 0, 0)
+	otherID := seedRecentTracker(t, db, 2, "Other Profile Blade", "CURRENT_TIMESTAMP", 0, 0)
+
+	res, err := app.Test(httptest.NewRequest(http.MethodPost, "/dashboard/trackers/"+strconv.FormatInt(dismissedID, 10)+"/setup-complete?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("dismiss request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+
+	var setupComplete bool
+	if err := db.QueryRow(`SELECT setup_complete FROM trackers WHERE id = ?`, dismissedID).Scan(&setupComplete); err != nil {
+		t.Fatalf("load setup flag: %v", err)
+	}
+	if !setupComplete {
+		t.Fatal("expected dismissing to mark the tracker set up")
+	}
+	_, body := getEditForm(t, app, "/dashboard/recent-additions?profile=profile1")
+	if strings.Contains(body, "Dismissed Blade") || !strings.Contains(body, "Waiting Blade") {
+		t.Fatalf("expected only the dismissed tracker to leave the strip, got %s", body)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodPost, "/dashboard/trackers/"+strconv.FormatInt(otherID, 10)+"/setup-complete?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("dismiss request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected another profile's tracker to be 404, got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StartRereadFromCard starts a completed tracker over from chapter 0 and
+// swaps in its card with the re-read badge. The finished read-through is
+// kept on the tracker; catching up again completes the re-read.
+func (h *DashboardHandler) StartRereadFromCard(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	viewMode := normalizeViewMode(c.FormValue("view_mode", c.Query("view", "grid")))
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+	defer h.editForms.forget(activeProfile.ID, id)
+
+	tracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
+	if err != nil {
+		return serverError(c, "Failed to load tracker", err)
+	}
+	if tracker == nil {
+		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
+	}
+	if tracker.Status != "completed" || tracker.Rereading {
+		return c.Status(fiber.StatusBadRequest).SendString("Only completed trackers can be re-read")
+	}
+
+	placement := h.newCardPlacement(c, activeProfile.ID, viewMode, id)
+
+	updatedTracker, err := h.writeAndReload(c, activeProfile.ID, id, func(txRepo *repository.TrackerRepository) error {
+		_, err := txRepo.StartReread(c.UserContext(), activeProfile.ID, id, time.Now())
+		return err
+	})
+	if err != nil {
+		return serverError(c, "Failed to start re-read", err)
+	}
+	if updatedTracker == nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	sourceByID, err := pageCtx.SourceByID()
+	if err != nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
+	if err != nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	cards, _ := h.buildTrackerCards(c.UserContext(), []models.Tracker{*updatedTracker}, sourceByID, sourceLogoBySourceID, "")
+	if len(cards) == 0 {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	response := trackerOOBResponseData{
+		ViewMode:    viewMode,
+		ReplaceCard: &cards[0],
+	}
+	h.placeUpdatedCard(c, placement, &response)
+	response.announce(mutationRereadStarted, "", &cards[0])
+	return h.render(c, "tracker_oob_response.html", response)
+}
@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRereadCycleFromTheDashboard(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	trackerID := seedWallTracker(t, db, "Again Blade", 20, 20)
+	if _, err := db.Exec(`UPDATE trackers SET status = 'completed' WHERE id = ?`, trackerID); err != nil {
+		t.Fatalf("complete tracker: %v", err)
+	}
+	id := strconv.FormatInt(trackerID, 10)
+	rereadButton := `hx-post="/dashboard/trackers/` + id + `/start-reread"`
+
+	if html := getDashboardHTML(t, app, "/dashboard/trackers?profile=profile1&status=completed&view=list"); !strings.Contains(html, rereadButton) {
+		t.Fatalf("expected a completed tracker to offer a re-read, got %s", html)
+	}
+
+	html := postDashboardForm(t, app, "/dashboard/trackers/"+id+"/start-reread", url.Values{"view_mode": {"grid"}})
+	if !strings.Contains(html, `<span class="badge badge--reread" title="Re-reading a completed series">Re-read #2</span>`) {
+		t.Fatalf("expected the card to show the re-read badge, got %s", html)
+	}
+	if strings.Contains(html, rereadButton) {
+		t.Fatalf("expected a tracker being re-read not to offer another re-read")
+	}
+	if !strings.Contains(html, "Re-read #2 started for tracker Again Blade") {
+		t.Fatalf("expected the re-read to be announced, got %s", html)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/trackers/"+id+"/start-reread", strings.NewReader("view_mode=grid"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("start second re-read: %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a tracker already being re-read, got %d", res.StatusCode)
+	}
+
+	if html := getDashboardHTML(t, app, "/dashboard/trackers?profile=profile1&status=reading"); !strings.Contains(html, "Again Blade") {
+		t.Fatalf("expected the reading filter to list the re-read")
+	}
+
+	postDashboardForm(t, app, "/dashboard/trackers/"+id+"/set-last-read", url.Values{"chapter": {"12"}, "view_mode": {"grid"}})
+	html = postDashboardForm(t, app, "/dashboard/trackers/"+id+"/set-last-read", url.Values{"view_mode": {"grid"}})
+	if strings.Contains(html, "badge--reread") || !strings.Contains(html, "badge--status-completed") {
+		t.Fatalf("expected catching up to complete the re-read, got %s", html)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers/"+id, nil), -1)
+	if err != nil {
+		t.Fatalf("get tracker: %v", err)
+	}
+	var tracker struct {
+		Rereading           bool              `json:"rereading"`
+		RereadCount         int               `json:"rereadCount"`
+		PreviousCompletions []json.RawMessage `json:"previousCompletions"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&tracker); err != nil {
+		t.Fatalf("decode tracker: %v", err)
+	}
+	if tracker.Rereading || tracker.RereadCount != 1 || len(tracker.PreviousCompletions) != 1 {
+		t.Fatalf("expected one finished re-read and the earlier completion, got %+v", tracker)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/v1/stats", nil), -1)
+	if err != nil {
+		t.Fatalf("get stats: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	var stats struct {
+		ReadChapters struct {
+			Chapters       float64 `json:"chapters"`
+			RereadChapters float64 `json:"rereadChapters"`
+		} `json:"readChapters"`
+	}
+	if err := json.Unmarshal(body, &stats); err != nil {
+		t.Fatalf("decode stats %s: %v", body, err)
+	}
+	if stats.ReadChapters.Chapters != 0 || stats.ReadChapters.RereadChapters != 20 {
+		t.Fatalf("expected the 20 re-read chapters to be counted apart, got %s", body)
+	}
+}
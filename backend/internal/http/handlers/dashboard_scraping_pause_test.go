@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// countingConnectorStub counts every call that would reach the source.
+type countingConnectorStub struct {
+	calls *atomic.Int64
+}
+
+func (countingConnectorStub) Key() string                       { return "mangadex" }
+func (countingConnectorStub) Name() string                      { return "MangaDex" }
+func (countingConnectorStub) Kind() string                      { return connectors.KindNative }
+func (countingConnectorStub) HealthCheck(context.Context) error { return nil }
+
+func (s countingConnectorStub) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	s.calls.Add(1)
+	chapter := 5.0
+	return &connectors.MangaResult{SourceKey: "mangadex", SourceItemID: "item", Title: "Paused Series", URL: rawURL, CoverImageURL: "https://example.com/cover.jpg", LatestChapter: &chapter}, nil
+}
+
+func (s countingConnectorStub) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	s.calls.Add(1)
+	return nil, nil
+}
+
+func (s countingConnectorStub) ResolveChapterURL(_ context.Context, rawURL string, _ float64) (string, error) {
+	s.calls.Add(1)
+	return rawURL + "/chapter", nil
+}
+
+func (s countingConnectorStub) ListChapters(context.Context, string, int) ([]connectors.ChapterInfo, error) {
+	s.calls.Add(1)
+	return nil, nil
+}
+
+func TestScrapingPauseBlocksOutboundRequests(t *testing.T) {
+	var calls atomic.Int64
+	registry := connectors.NewRegistry()
+	if err := registry.Register(countingConnectorStub{calls: &calls}); err != nil {
+		t.Fatalf("register counting stub: %v", err)
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	if err := repository.NewSettingsRepository(db).SetScrapingPaused(true); err != nil {
+		t.Fatalf("pause scraping: %v", err)
+	}
+
+	var sourceID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&sourceID); err != nil {
+		t.Fatalf("load mangadex source: %v", err)
+	}
+	sourceURL := "https://mangadex.org/title/paused-series"
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Paused Series', ?, ?, 'reading', 3, 4)
+	`, sourceID, sourceURL)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+
+	app := fiber.New()
+	app.Get("/dashboard", h.Page)
+	app.Get("/dashboard/trackers", h.TrackersPartial)
+	app.Get("/dashboard/trackers/search", h.SearchSourceTitles)
+	app.Get("/dashboard/trackers/:id/chapters", h.ChaptersModal)
+	getBody := func(path string) string {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("request %s: %v", path, err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("expected 200 from %s, got %d", path, resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if body := getBody("/dashboard"); !strings.Contains(body, "Updates paused") {
+		t.Fatalf("expected paused banner on dashboard page")
+	}
+	if body := getBody("/dashboard/trackers"); !strings.Contains(body, "Paused Series") {
+		t.Fatalf("expected cached tracker card while paused, got %s", body)
+	}
+	if body := getBody("/dashboard/trackers/search?q=paused&source_id=" + strconv.FormatInt(sourceID, 10)); !strings.Contains(body, "Updates are paused") {
+		t.Fatalf("expected paused search message, got %s", body)
+	}
+	if body := getBody("/dashboard/trackers/" + strconv.FormatInt(trackerID, 10) + "/chapters"); !strings.Contains(body, "Updates are paused") {
+		t.Fatalf("expected paused chapter browser message, got %s", body)
+	}
+
+	tracker := &models.Tracker{SourceID: sourceID, SourceURL: sourceURL}
+	h.enrichTrackerFromSource(context.Background(), tracker, connectors.DefaultLanguage)
+	if _, err := h.resolveLinkedSource(context.Background(), sourceID, sourceURL, connectors.DefaultLanguage); !errors.Is(err, connectors.ErrScrapingPaused) {
+		t.Fatalf("expected ErrScrapingPaused from linked source resolve, got %v", err)
+	}
+	if _, err := h.fetchCoverURL(context.Background(), "mangadex", sourceURL, nil); !errors.Is(err, connectors.ErrScrapingPaused) {
+		t.Fatalf("expected ErrScrapingPaused from cover fetch, got %v", err)
+	}
+	if chapterURL, err := h.fetchChapterURL("mangadex", sourceURL, 4); !errors.Is(err, connectors.ErrScrapingPaused) || chapterURL != sourceURL {
+		t.Fatalf("expected series url fallback and ErrScrapingPaused, got %q %v", chapterURL, err)
+	}
+
+	// Queued fetches run in goroutines; give any that slipped through a
+	// chance to reach the stub before counting.
+	time.Sleep(100 * time.Millisecond)
+	if got := calls.Load(); got != 0 {
+		t.Fatalf("expected no outbound requests while paused, got %d", got)
+	}
+
+	if err := repository.NewSettingsRepository(db).SetScrapingPaused(false); err != nil {
+		t.Fatalf("resume scraping: %v", err)
+	}
+	if _, err := h.fetchCoverURL(context.Background(), "mangadex", sourceURL, nil); err != nil {
+		t.Fatalf("expected cover fetch after resume, got %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("expected one outbound request after resume, got %d", got)
+	}
+}
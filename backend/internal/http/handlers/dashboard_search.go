@@ -11,6 +11,20 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+// SearchRateLimited renders the search results partial for a search the rate
+// limiter rejected, keeping the limiter's 429 status.
+func (h *DashboardHandler) SearchRateLimited(c *fiber.Ctx, retryAfter time.Duration) error {
+	intent := strings.TrimSpace(c.Query("intent"))
+	if intent == "" {
+		intent = "primary"
+	}
+	return h.render(c, "tracker_search_results.html", trackerSearchResultsData{
+		Query:  strings.TrimSpace(c.Query("q")),
+		Error:  "Too many searches in a short time. Try again in " + pluralize(retryAfterSeconds(retryAfter), "second") + ".",
+		Intent: intent,
+	})
+}
+
 func (h *DashboardHandler) SearchSourceTitles(c *fiber.Ctx) error {
 	query := strings.TrimSpace(c.Query("q"))
 	intent := strings.TrimSpace(c.Query("intent"))
@@ -26,7 +40,7 @@ func (h *DashboardHandler) SearchSourceTitles(c *fiber.Ctx) error {
 		return h.render(c, "tracker_search_results.html", trackerSearchResultsData{Query: query, Error: "Select a source first", Intent: intent})
 	}
 
-	source, err := h.sourceRepo.GetByID(sourceID)
+	source, err := h.sourceRepo.GetByID(c.UserContext(), sourceID)
 	if err != nil {
 		return h.render(c, "tracker_search_results.html", trackerSearchResultsData{Query: query, Error: "Failed to resolve source", Intent: intent})
 	}
@@ -34,58 +48,91 @@ func (h *DashboardHandler) SearchSourceTitles(c *fiber.Ctx) error {
 		return h.render(c, "tracker_search_results.html", trackerSearchResultsData{Query: query, Error: "Source not found or disabled", Intent: intent})
 	}
 
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+	blacklisted, err := h.sourceBlacklisted(c.UserContext(), activeProfile.ID, source.ID)
+	if err != nil {
+		return h.render(c, "tracker_search_results.html", trackerSearchResultsData{Query: query, Error: "Failed to resolve source", Intent: intent})
+	}
+	if blacklisted {
+		return h.render(c, "tracker_search_results.html", trackerSearchResultsData{Query: query, Error: source.Name + " is blacklisted for this profile", Intent: intent})
+	}
+
 	connector, ok := h.registry.Get(source.Key)
 	if !ok {
 		return h.render(c, "tracker_search_results.html", trackerSearchResultsData{Query: query, Error: "No connector registered for selected source", Intent: intent})
 	}
+	searchURL, isURL := extractSearchURL(query)
+	data := trackerSearchResultsData{Query: query, SourceID: source.ID, SourceName: source.Name, Intent: intent}
+	if !isURL {
+		data.SearchPageURL = h.registry.SearchPageURL(source.Key, query)
+	}
 
-	searchTimeout := 5 * time.Second
-	if source.Key == "mangafire" || source.Key == "freewebnovel" {
-		// Both sit behind Cloudflare and need extra time: mangafire paces its
-		// API, freewebnovel warms a homepage hit for a clearance cookie before
-		// searching (and may retry once), which adds a round-trip.
-		searchTimeout = 12 * time.Second
+	if h.scrapingAllowed() != nil {
+		data.Error = "Updates are paused, so source search is unavailable"
+		return h.render(c, "tracker_search_results.html", data)
 	}
 
-	ctx, cancel := context.WithTimeout(c.Context(), searchTimeout)
+	ctx, cancel := context.WithTimeout(c.UserContext(), sourceSearchTimeout(source.Key))
 	defer cancel()
 
-	if mangaURL, ok := extractMangaFireMangaURL(query); source.Key == "mangafire" && ok {
-		resolved, resolveErr := connector.ResolveByURL(ctx, mangaURL)
+	switch {
+	case isURL && source.SearchMode != connectors.SearchModeTitle:
+		canonicalURL, validateErr := h.registry.ValidateSourceURL(source.Key, searchURL)
+		if validateErr != nil {
+			data.Error = "Not a valid " + source.Name + " link: " + validateErr.Error()
+			return h.render(c, "tracker_search_results.html", data)
+		}
+		resolved, resolveErr := connector.ResolveByURL(ctx, canonicalURL)
 		if resolveErr != nil || resolved == nil {
-			message := "Failed to resolve MangaFire URL"
+			data.Error = "Failed to resolve " + source.Name + " URL"
 			if resolveErr != nil {
-				message = "Failed to resolve MangaFire URL: " + resolveErr.Error()
+				data.Error += ": " + resolveErr.Error()
 			}
-			return h.render(c, "tracker_search_results.html", trackerSearchResultsData{
-				Query:      query,
-				SourceID:   source.ID,
-				SourceName: source.Name,
-				Intent:     intent,
-				Error:      message,
-			})
+			return h.render(c, "tracker_search_results.html", data)
 		}
 
-		return h.render(c, "tracker_search_results.html", trackerSearchResultsData{
-			Items:      []connectors.MangaResult{*resolved},
-			Query:      query,
-			SourceID:   source.ID,
-			SourceName: source.Name,
-			Intent:     intent,
-		})
+		resolved.CoverImageURL = h.registry.MediaURL(source.Key, resolved.CoverImageURL)
+		data.Items = []connectors.MangaResult{*resolved}
+		return h.render(c, "tracker_search_results.html", data)
+	case source.SearchMode == connectors.SearchModeURLOnly:
+		data.Error = source.Name + " cannot be searched by title; paste the series URL instead"
+		return h.render(c, "tracker_search_results.html", data)
 	}
 
 	results, err := connector.SearchByTitle(ctx, query, 8)
 	if err != nil {
-		return h.render(c, "tracker_search_results.html", trackerSearchResultsData{Query: query, Error: "Search failed for this source: " + err.Error(), SourceID: source.ID, SourceName: source.Name, Intent: intent})
+		data.Error = "Search failed for this source: " + err.Error()
+		return h.render(c, "tracker_search_results.html", data)
 	}
 
-	return h.render(c, "tracker_search_results.html", trackerSearchResultsData{Items: results, Query: query, SourceID: source.ID, SourceName: source.Name, Intent: intent})
+	for index := range results {
+		results[index].CoverImageURL = h.registry.MediaURL(source.Key, results[index].CoverImageURL)
+	}
+	data.Items = results
+	return h.render(c, "tracker_search_results.html", data)
 }
 
-func extractMangaFireMangaURL(query string) (string, bool) {
+// sourceSearchTimeout bounds one search or URL lookup against a source.
+func sourceSearchTimeout(sourceKey string) time.Duration {
+	if sourceKey == "mangafire" || sourceKey == "freewebnovel" {
+		// Both sit behind Cloudflare and need extra time: mangafire paces its
+		// API, freewebnovel warms a homepage hit for a clearance cookie before
+		// searching (and may retry once), which adds a round-trip.
+		return 12 * time.Second
+	}
+	return 5 * time.Second
+}
+
+// extractSearchURL reports whether the search query is a single pasted
+// http(s) URL, returning it without its fragment. Host and path checks are
+// left to the source's connector.
+func extractSearchURL(query string) (string, bool) {
 	trimmed := strings.TrimSpace(query)
-	if trimmed == "" {
+	if trimmed == "" || strings.ContainsAny(trimmed, " \t\n") {
 		return "", false
 	}
 
@@ -94,18 +141,10 @@ func extractMangaFireMangaURL(query string) (string, bool) {
 	}
 
 	parsed, err := url.Parse(trimmed)
-	if err != nil {
-		return "", false
-	}
-	if !strings.EqualFold(parsed.Hostname(), "mangafire.to") && !strings.EqualFold(parsed.Hostname(), "www.mangafire.to") {
-		return "", false
-	}
-	lowerPath := strings.ToLower(parsed.Path)
-	if !strings.HasPrefix(lowerPath, "/manga/") && !strings.HasPrefix(lowerPath, "/title/") {
+	if err != nil || parsed.Hostname() == "" {
 		return "", false
 	}
 
-	parsed.RawQuery = ""
 	parsed.Fragment = ""
 	return parsed.String(), true
 }
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gofiber/fiber/v2"
+)
+
+type searchModeConnectorStub struct {
+	key  string
+	name string
+	mode string
+	host string
+}
+
+func (s searchModeConnectorStub) Key() string {
+	return s.key
+}
+
+func (s searchModeConnectorStub) Name() string {
+	return s.name
+}
+
+func (searchModeConnectorStub) Kind() string {
+	return connectors.KindNative
+}
+
+func (searchModeConnectorStub) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (s searchModeConnectorStub) SearchMode() string {
+	return s.mode
+}
+
+func (s searchModeConnectorStub) ValidateURL(rawURL string) (string, error) {
+	return connectors.CanonicalHostURL(rawURL, []string{s.host})
+}
+
+func (s searchModeConnectorStub) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	return &connectors.MangaResult{SourceKey: s.key, Title: "Resolved by URL", URL: rawURL}, nil
+}
+
+func (s searchModeConnectorStub) SearchByTitle(_ context.Context, title string, _ int) ([]connectors.MangaResult, error) {
+	return []connectors.MangaResult{{SourceKey: s.key, Title: "Title match " + title, URL: "https://" + s.host + "/series/match"}}, nil
+}
+
+func TestSearchSourceTitlesFollowsSourceSearchMode(t *testing.T) {
+	registry := connectors.NewRegistry()
+	for _, stub := range []searchModeConnectorStub{
+		{key: "mangadex", name: "MangaDex", mode: connectors.SearchModeTitle, host: "mangadex.org"},
+		{key: "mgeko", name: "Mgeko", mode: connectors.SearchModeURLOnly, host: "mgeko.cc"},
+		{key: "mangafire", name: "MangaFire", mode: connectors.SearchModeTitleOrURL, host: "mangafire.to"},
+	} {
+		if err := registry.Register(stub); err != nil {
+			t.Fatalf("register %s stub: %v", stub.key, err)
+		}
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	if err := database.SyncSourceSearchModes(db, registry.SearchModes()); err != nil {
+		t.Fatalf("sync search modes: %v", err)
+	}
+	var mgekoMode string
+	if err := db.QueryRow(`SELECT search_mode FROM sources WHERE key = 'mgeko'`).Scan(&mgekoMode); err != nil {
+		t.Fatalf("load mgeko search mode: %v", err)
+	}
+	if mgekoMode != connectors.SearchModeURLOnly {
+		t.Fatalf("expected synced url_only mode for mgeko, got %q", mgekoMode)
+	}
+
+	app := fiber.New()
+	app.Get("/dashboard/trackers/search", h.SearchSourceTitles)
+
+	search := func(sourceKey string, query string) string {
+		t.Helper()
+		var sourceID int64
+		if err := db.QueryRow(`SELECT id FROM sources WHERE key = ?`, sourceKey).Scan(&sourceID); err != nil {
+			t.Fatalf("load %s source id: %v", sourceKey, err)
+		}
+		params := url.Values{}
+		params.Set("source_id", strconv.FormatInt(sourceID, 10))
+		params.Set("q", query)
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/dashboard/trackers/search?"+params.Encode(), nil), -1)
+		if err != nil {
+			t.Fatalf("search %s: %v", sourceKey, err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("expected 200 from %s search, got %d", sourceKey, resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	t.Run("title", func(t *testing.T) {
+		if body := search("mangadex", "https://mangadex.org/title/abc"); !strings.Contains(body, "Title match https://mangadex.org/title/abc") {
+			t.Fatalf("expected title mode to search pasted text by title, got %s", body)
+		}
+	})
+
+	t.Run("url_only", func(t *testing.T) {
+		if body := search("mgeko", "Solo Leveling"); !strings.Contains(body, "cannot be searched by title") {
+			t.Fatalf("expected url_only mode to reject title text, got %s", body)
+		}
+		body := search("mgeko", "https://www.mgeko.cc/manga/solo-leveling/#top")
+		if !strings.Contains(body, "Resolved by URL") || !strings.Contains(body, `data-url="https://www.mgeko.cc/manga/solo-leveling/"`) {
+			t.Fatalf("expected url_only mode to resolve pasted URL, got %s", body)
+		}
+		if body := search("mgeko", "https://example.com/manga/solo-leveling/"); !strings.Contains(body, "Not a valid Mgeko link") {
+			t.Fatalf("expected url_only mode to reject another site's URL, got %s", body)
+		}
+	})
+
+	t.Run("title_or_url", func(t *testing.T) {
+		if body := search("mangafire", "One Piece"); !strings.Contains(body, "Title match One Piece") {
+			t.Fatalf("expected title_or_url mode to search title text, got %s", body)
+		}
+		if body := search("mangafire", "https://mangafire.to/manga/one-piecee.dkw"); !strings.Contains(body, "Resolved by URL") {
+			t.Fatalf("expected title_or_url mode to resolve pasted URL, got %s", body)
+		}
+	})
+}
+
+type searchPageConnectorStub struct {
+	searchModeConnectorStub
+}
+
+func (s searchPageConnectorStub) SearchPageURL(query string) string {
+	return "https://" + s.host + "/filter?keyword=" + url.QueryEscape(query)
+}
+
+func TestSearchSourceTitlesLinksToSourceSearchPage(t *testing.T) {
+	registry := connectors.NewRegistry()
+	if err := registry.Register(searchPageConnectorStub{searchModeConnectorStub{key: "mangafire", name: "MangaFire", mode: connectors.SearchModeTitle, host: "mangafire.to"}}); err != nil {
+		t.Fatalf("register mangafire stub: %v", err)
+	}
+	if err := registry.Register(searchModeConnectorStub{key: "mgeko", name: "Mgeko", mode: connectors.SearchModeTitle, host: "mgeko.cc"}); err != nil {
+		t.Fatalf("register mgeko stub: %v", err)
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	app := fiber.New()
+	app.Get("/dashboard/trackers/search", h.SearchSourceTitles)
+
+	search := func(sourceKey string) string {
+		t.Helper()
+		var sourceID int64
+		if err := db.QueryRow(`SELECT id FROM sources WHERE key = ?`, sourceKey).Scan(&sourceID); err != nil {
+			t.Fatalf("load %s source id: %v", sourceKey, err)
+		}
+		params := url.Values{}
+		params.Set("source_id", strconv.FormatInt(sourceID, 10))
+		params.Set("q", "One Piece")
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/dashboard/trackers/search?"+params.Encode(), nil), -1)
+		if err != nil {
+			t.Fatalf("search %s: %v", sourceKey, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	body := search("mangafire")
+	if !strings.Contains(body, `href="https://mangafire.to/filter?keyword=One&#43;Piece"`) || !strings.Contains(body, "Search on MangaFire") {
+		t.Fatalf("expected search page link for connector that supports it, got %s", body)
+	}
+	if !strings.Contains(body, "Title match One Piece") {
+		t.Fatalf("expected link alongside results, got %s", body)
+	}
+
+	if body := search("mgeko"); strings.Contains(body, "Search on Mgeko") {
+		t.Fatalf("expected no search page link for connector without one, got %s", body)
+	}
+}
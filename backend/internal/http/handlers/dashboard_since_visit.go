@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+// markDashboardSeen records a full page load of the dashboard. Partial
+// refreshes do not call it, so the "since last visit" badges hold for the
+// whole session and clear on the next full load. Read-only screens, such
+// as a kiosk left open, are not the profile's visits and are skipped.
+func (h *DashboardHandler) markDashboardSeen(ctx context.Context, profileID int64, readOnly bool) {
+	if h.profileRepo == nil || readOnly {
+		return
+	}
+	_ = h.profileRepo.MarkDashboardSeen(ctx, profileID, time.Now())
+}
+
+// markChaptersSinceVisit sets each card's count of chapters released since
+// the profile's previous dashboard visit. On a first visit no card gets
+// one. A tracker whose latest release is newer than the visit but whose
+// chapters were not recorded counts as one.
+func (h *DashboardHandler) markChaptersSinceVisit(ctx context.Context, cards []trackerCardView, items []models.Tracker) {
+	if h.profileRepo == nil || len(items) == 0 {
+		return
+	}
+	since, err := h.profileRepo.DashboardSeenBefore(ctx, items[0].ProfileID)
+	if err != nil || since == nil {
+		return
+	}
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	counts, err := h.trackerRepo.CountReleasesSince(ctx, items[0].ProfileID, ids, *since)
+	if err != nil {
+		return
+	}
+
+	for index := range cards {
+		count := counts[cards[index].ID]
+		if count == 0 && cards[index].LatestReleaseAtRaw != nil && cards[index].LatestReleaseAtRaw.After(*since) {
+			count = 1
+		}
+		cards[index].ChaptersSinceVisit = count
+		cards[index].NewSinceLastVisit = count > 0
+	}
+}
@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSinceLastVisitBadgeHoldsUntilTheNextFullLoad(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Visit Blade', 1, 'https://asuracomic.net/series/visit-blade', 'reading', 10, 10)
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	id := strconv.FormatInt(trackerID, 10)
+
+	get := func(path string) string {
+		t.Helper()
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("request %s failed: %v", path, err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 from %s, got %d", path, res.StatusCode)
+		}
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	partial := func() string {
+		t.Helper()
+		return get("/dashboard/trackers?profile=profile1&view=list")
+	}
+
+	get("/dashboard?profile=profile1")
+	if body := partial(); strings.Contains(body, "since last visit") {
+		t.Fatalf("expected no badges on a first visit, got %s", body)
+	}
+
+	releasedAt := time.Now().UTC()
+	for _, chapter := range []int{11, 12, 13, 14} {
+		if _, err := db.Exec(`INSERT INTO chapters (tracker_id, chapter_number, released_at) VALUES (?, ?, ?)`, trackerID, chapter, releasedAt); err != nil {
+			t.Fatalf("seed chapter: %v", err)
+		}
+	}
+	if _, err := db.Exec(`UPDATE trackers SET latest_known_chapter = 14, latest_release_at = ? WHERE id = ?`, releasedAt, trackerID); err != nil {
+		t.Fatalf("update tracker: %v", err)
+	}
+
+	get("/dashboard?profile=profile1")
+	for refresh := 0; refresh < 2; refresh++ {
+		if body := partial(); !strings.Contains(body, "+4 since last visit") {
+			t.Fatalf("expected the badge on refresh %d, got %s", refresh, body)
+		}
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers/"+id+"/card?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("card request failed: %v", err)
+	}
+	var card struct {
+		ChaptersSinceVisit int  `json:"chaptersSinceVisit"`
+		NewSinceLastVisit  bool `json:"newSinceLastVisit"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&card); err != nil {
+		t.Fatalf("decode card: %v", err)
+	}
+	if card.ChaptersSinceVisit != 4 || !card.NewSinceLastVisit {
+		t.Fatalf("expected the card JSON to carry the delta, got %+v", card)
+	}
+
+	get("/dashboard?profile=profile1")
+	if body := partial(); strings.Contains(body, "since last visit") {
+		t.Fatalf("expected the badge to clear on the next full load, got %s", body)
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// globalSortDefault is the dashboard sort of the "all" status and of every
+// status the profile has no sort default for.
+var globalSortDefault = models.TrackerSortDefault{Sort: "latest_known_chapter", Order: "desc"}
+
+// sortDefaultSorts are the sorts a status default can use: the sort
+// select's, plus the ones that only make sense as a status's standing
+// order, such as alphabetical for finished series.
+var sortDefaultSorts = append(slices.Clone(dashboardSorts), "title", "created_at")
+
+// profileSortDefaultView is one status row of the profile menu's default
+// sort form; Sort is empty when the status uses the global default.
+type profileSortDefaultView struct {
+	Status string
+	Sort   string
+	Order  string
+}
+
+// dashboardListOptions reads the dashboard filters from the query and, when
+// the query names no sort, sorts by the profile's default for the status.
+func (h *DashboardHandler) dashboardListOptions(c *fiber.Ctx, profileID int64) (repository.TrackerListOptions, error) {
+	args := c.Context().QueryArgs()
+	options := trackerListOptionsFromArgs(args, profileID)
+
+	defaults, err := h.profileRepo.StatusSortDefaults(c.UserContext(), profileID)
+	if err != nil {
+		return options, loadFailed("Failed to load sort defaults", err)
+	}
+	applyStatusSortDefault(args, defaults, &options)
+	return options, nil
+}
+
+// applyStatusSortDefault sorts options by the default of the status args
+// filter on. An explicit sort in args keeps its own order, and an explicit
+// order still wins over the default's.
+func applyStatusSortDefault(args filterArgs, defaults models.StatusSortDefaults, options *repository.TrackerListOptions) {
+	if strings.TrimSpace(string(args.Peek("sort"))) != "" {
+		return
+	}
+	sortDefault := effectiveSortDefault(defaults, dashboardStatusArg(args))
+	options.SortBy = sortDefault.Sort
+	if strings.TrimSpace(string(args.Peek("order"))) == "" {
+		options.Order = sortDefault.Order
+	}
+}
+
+// effectiveSortDefault is the sort a status opens with. A stored default
+// the dashboard no longer offers falls back to the global one.
+func effectiveSortDefault(defaults models.StatusSortDefaults, status string) models.TrackerSortDefault {
+	if status == "all" {
+		return globalSortDefault
+	}
+	if sortDefault, ok := defaults[status]; ok && validSortDefault(sortDefault) {
+		return sortDefault
+	}
+	return globalSortDefault
+}
+
+func validSortDefault(sortDefault models.TrackerSortDefault) bool {
+	return slices.Contains(sortDefaultSorts, sortDefault.Sort) && (sortDefault.Order == "asc" || sortDefault.Order == "desc")
+}
+
+// sortDefaultLabels names the sort each dashboard status opens with, for
+// the sort select's default option.
+func sortDefaultLabels(defaults models.StatusSortDefaults) map[string]string {
+	labels := make(map[string]string, len(dashboardStatuses))
+	for _, status := range dashboardStatuses {
+		sortDefault := effectiveSortDefault(defaults, status)
+		labels[status] = sortLabel(sortDefault.Sort) + ", " + sortOrderLabel(sortDefault.Order)
+	}
+	return labels
+}
+
+func sortOrderLabel(order string) string {
+	if order == "asc" {
+		return "ascending"
+	}
+	return "descending"
+}
+
+func toProfileSortDefaultViews(defaults models.StatusSortDefaults) []profileSortDefaultView {
+	views := make([]profileSortDefaultView, 0, len(dashboardStatuses)-1)
+	for _, status := range dashboardStatuses {
+		if status == "all" {
+			continue
+		}
+		view := profileSortDefaultView{Status: status, Order: globalSortDefault.Order}
+		if sortDefault, ok := defaults[status]; ok && validSortDefault(sortDefault) {
+			view.Sort = sortDefault.Sort
+			view.Order = sortDefault.Order
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// SaveSortDefaultsFromMenu replaces the active profile's per-status sort
+// defaults. A status left on the global default is not stored.
+func (h *DashboardHandler) SaveSortDefaultsFromMenu(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	defaults := models.StatusSortDefaults{}
+	for _, status := range dashboardStatuses {
+		if status == "all" {
+			continue
+		}
+		sortBy := strings.TrimSpace(c.FormValue("sort_" + status))
+		if sortBy == "" {
+			continue
+		}
+		sortDefault := models.TrackerSortDefault{
+			Sort:  sortBy,
+			Order: strings.TrimSpace(c.FormValue("order_"+status, globalSortDefault.Order)),
+		}
+		if !validSortDefault(sortDefault) {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid default sort for " + statusLabel(status))
+		}
+		defaults[status] = sortDefault
+	}
+
+	if _, err := h.profileRepo.SetStatusSortDefaults(c.UserContext(), activeProfile.ID, defaults); err != nil {
+		return serverError(c, "Failed to save sort defaults", err)
+	}
+
+	return h.renderProfileMenu(c, pageCtx, "Default sorts saved", map[string]any{
+		"trackersChanged":     true,
+		"sortDefaultsChanged": map[string]any{"labels": sortDefaultLabels(defaults)},
+	})
+}
@@ -0,0 +1,136 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func postSortDefaults(t *testing.T, app *fiber.App, form url.Values) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/profile/sort-defaults?profile=profile1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("save sort defaults request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	return res, string(body)
+}
+
+func TestDashboardStatusSortDefaultsPrecedence(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	// Zeta has the newer release, so the global default puts it first while
+	// the completed default of title ascending puts Alpha first.
+	if _, err := db.Exec(`
+		INSERT INTO trackers (title, source_id, source_url, status, latest_known_chapter, latest_release_at)
+		VALUES
+			('Alpha Done', 1, 'https://mangadex.org/title/alpha-done', 'completed', 5, '2026-01-01 00:00:00'),
+			('Zeta Done', 1, 'https://mangadex.org/title/zeta-done', 'completed', 10, '2026-02-01 00:00:00'),
+			('Alpha Later', 1, 'https://mangadex.org/title/alpha-later', 'plan_to_read', 5, '2026-01-01 00:00:00'),
+			('Zeta Later', 1, 'https://mangadex.org/title/zeta-later', 'plan_to_read', 10, '2026-02-01 00:00:00')
+	`); err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+
+	res, body := postSortDefaults(t, app, url.Values{
+		"sort_completed":     {"title"},
+		"order_completed":    {"asc"},
+		"sort_plan_to_read":  {""},
+		"order_plan_to_read": {"asc"},
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, body)
+	}
+	if !strings.Contains(body, "Default sorts saved") {
+		t.Fatalf("expected the saved message in the profile menu, got %s", body)
+	}
+	if trigger := res.Header.Get("HX-Trigger"); !strings.Contains(trigger, `"sortDefaultsChanged"`) || !strings.Contains(trigger, `"completed":"Title (A–Z), ascending"`) {
+		t.Fatalf("expected the new default labels in HX-Trigger, got %q", trigger)
+	}
+
+	cases := []struct {
+		name   string
+		target string
+		first  string
+		second string
+	}{
+		{name: "status default", target: "/dashboard/trackers?status=completed", first: "Alpha Done", second: "Zeta Done"},
+		{name: "blank sort uses the status default", target: "/dashboard/trackers?status=completed&sort=", first: "Alpha Done", second: "Zeta Done"},
+		{name: "explicit sort wins", target: "/dashboard/trackers?status=completed&sort=latest_known_chapter", first: "Zeta Done", second: "Alpha Done"},
+		{name: "explicit order wins over the default's", target: "/dashboard/trackers?status=completed&order=desc", first: "Zeta Done", second: "Alpha Done"},
+		{name: "status without a default", target: "/dashboard/trackers?status=plan_to_read", first: "Zeta Later", second: "Alpha Later"},
+		{name: "all uses the global default", target: "/dashboard/trackers?status=all", first: "Zeta Done", second: "Alpha Done"},
+		{name: "export view", target: "/dashboard/trackers/export-view?status=completed", first: "Alpha Done", second: "Zeta Done"},
+		{name: "json api ignores it", target: "/v1/trackers?status=completed", first: "Zeta Done", second: "Alpha Done"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := getBody(t, app, tc.target)
+			if status != http.StatusOK {
+				t.Fatalf("expected 200, got %d (body: %s)", status, body)
+			}
+			first, second := strings.Index(body, tc.first), strings.Index(body, tc.second)
+			if first < 0 || second < 0 || first > second {
+				t.Fatalf("expected %q before %q, got %s", tc.first, tc.second, body)
+			}
+		})
+	}
+}
+
+func TestDashboardSortSelectShowsTheAppliedDefault(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if res, body := postSortDefaults(t, app, url.Values{"sort_completed": {"created_at"}, "order_completed": {"asc"}}); res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, body)
+	}
+
+	_, page := getBody(t, app, "/dashboard?status=completed")
+	if !strings.Contains(page, `data-sort-default selected>Default: Date added, ascending</option>`) {
+		t.Fatalf("expected the completed default to be the selected sort option, got %s", page)
+	}
+	_, page = getBody(t, app, "/dashboard")
+	if !strings.Contains(page, `>Default: Latest chapter, descending</option>`) {
+		t.Fatalf("expected reading to show the global default, got %s", page)
+	}
+	_, page = getBody(t, app, "/dashboard?status=completed&sort=rating")
+	if strings.Contains(page, "data-sort-default selected") || !strings.Contains(page, `value="rating" title="Rating" selected`) {
+		t.Fatalf("expected the explicit sort to stay selected, got %s", page)
+	}
+
+	_, menu := getBody(t, app, "/dashboard/profile/menu?profile=profile1")
+	if !strings.Contains(menu, `<option value="created_at" selected>Date added</option>`) {
+		t.Fatalf("expected the profile menu to show the saved default, got %s", menu)
+	}
+}
+
+func TestSaveSortDefaultsFromMenuRejectsUnknownSorts(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	for _, form := range []url.Values{
+		{"sort_completed": {"source_url"}},
+		{"sort_completed": {"title"}, "order_completed": {"sideways"}},
+	} {
+		res, body := postSortDefaults(t, app, form)
+		if res.StatusCode != http.StatusBadRequest || !strings.Contains(body, "Invalid default sort for Completed") {
+			t.Fatalf("expected 400 for %v, got %d (body: %s)", form, res.StatusCode, body)
+		}
+	}
+
+	var stored string
+	if err := db.QueryRow(`SELECT status_sort_defaults FROM profiles WHERE id = 1`).Scan(&stored); err != nil {
+		t.Fatalf("load profile: %v", err)
+	}
+	if stored != "{}" {
+		t.Fatalf("expected no defaults to be stored, got %s", stored)
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SaveSourceBlacklistFromMenu blacklists a site for the active profile, or
+// takes it off the blacklist, from the profile menu. A blacklisted site is
+// no longer offered when adding trackers or linked sites, but trackers
+// already on it are left alone.
+func (h *DashboardHandler) SaveSourceBlacklistFromMenu(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	sourceID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || sourceID <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid site")
+	}
+	source, err := h.sourceRepo.GetByID(c.UserContext(), sourceID)
+	if err != nil {
+		return serverError(c, "Failed to load site", err)
+	}
+	if source == nil {
+		return c.Status(fiber.StatusNotFound).SendString("Site not found")
+	}
+
+	blacklisted := c.FormValue("blacklisted") == "1"
+	if err := h.sourceRepo.SetProfileSourceBlacklisted(c.UserContext(), activeProfile.ID, sourceID, blacklisted); err != nil {
+		return serverError(c, "Failed to save site blacklist", err)
+	}
+
+	message := source.Name + " is no longer blacklisted"
+	if blacklisted {
+		message = source.Name + " is blacklisted"
+	}
+	h.editForms.forgetProfile(activeProfile.ID)
+	return h.renderProfileMenu(c, pageCtx, message, map[string]any{"trackersChanged": true})
+}
+
+// sourceBlacklisted reports whether the profile has blacklisted the source.
+func (h *DashboardHandler) sourceBlacklisted(ctx context.Context, profileID int64, sourceID int64) (bool, error) {
+	blacklisted, err := h.sourceRepo.ListProfileBlacklistedSourceIDs(ctx, profileID)
+	if err != nil {
+		return false, err
+	}
+	return blacklisted[sourceID], nil
+}
+
+// markBlacklistedSources flags the cards whose primary source the profile
+// has blacklisted. Cards are left unflagged when the blacklist cannot be
+// read.
+func (h *DashboardHandler) markBlacklistedSources(ctx context.Context, cards []trackerCardView, items []models.Tracker) {
+	if h.sourceRepo == nil || len(items) == 0 {
+		return
+	}
+	blacklisted, err := h.sourceRepo.ListProfileBlacklistedSourceIDs(ctx, items[0].ProfileID)
+	if err != nil || len(blacklisted) == 0 {
+		return
+	}
+	for index := range cards {
+		cards[index].SourceBlacklisted = blacklisted[cards[index].SourceID]
+	}
+}
@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSourceBlacklistHidesSiteFromPickersButKeepsTrackers(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	sourceID, sourceName := sourceMetaByKey(t, db, "mangadex")
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, 1, "Blacklist Seed", sourceID, "https://mangadex.org/title/blacklist-seed", "reading", 1.0, 2.0)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+
+	get := func(target string) string {
+		t.Helper()
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil), -1)
+		if err != nil {
+			t.Fatalf("GET %s: %v", target, err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d (body: %s)", target, res.StatusCode, body)
+		}
+		return string(body)
+	}
+	setBlacklisted := func(value string) string {
+		t.Helper()
+		form := url.Values{"blacklisted": {value}}
+		req := httptest.NewRequest(http.MethodPost, "/dashboard/sources/"+toString(int(sourceID))+"/blacklist?profile=profile1", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("save blacklist: %v", err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("save blacklist: expected 200, got %d (body: %s)", res.StatusCode, body)
+		}
+		return string(body)
+	}
+	option := `<option value="` + toString(int(sourceID)) + `"`
+
+	if body := setBlacklisted("1"); !strings.Contains(body, sourceName+" is blacklisted") {
+		t.Fatalf("expected the blacklist confirmation, got %s", body)
+	}
+
+	if body := get("/dashboard/trackers/new?profile=profile1"); strings.Contains(body, option) {
+		t.Fatalf("expected %s to be left out of the add tracker pickers", sourceName)
+	}
+	if body := get("/dashboard/trackers/new?profile=profile2"); !strings.Contains(body, option) {
+		t.Fatalf("expected the other profile to still offer %s", sourceName)
+	}
+
+	edit := get("/dashboard/trackers/" + toString(int(trackerID)) + "/edit?profile=profile1")
+	if !strings.Contains(edit, sourceName+" (blacklisted)") {
+		t.Fatalf("expected the edit form to keep the tracker's blacklisted source, got %s", edit)
+	}
+
+	search := get("/dashboard/trackers/search?profile=profile1&source_id=" + toString(int(sourceID)) + "&q=solo")
+	if !strings.Contains(search, sourceName+" is blacklisted for this profile") {
+		t.Fatalf("expected the search to refuse the blacklisted source, got %s", search)
+	}
+
+	if body := get("/dashboard/trackers?profile=profile1"); !strings.Contains(body, "Blacklist Seed") || !strings.Contains(body, "tracker-source-blacklisted") {
+		t.Fatalf("expected the tracker to stay listed with a blacklist indicator")
+	}
+	var card map[string]any
+	if err := json.Unmarshal([]byte(get("/v1/trackers/"+toString(int(trackerID))+"/card")), &card); err != nil {
+		t.Fatalf("decode card: %v", err)
+	}
+	if card["sourceBlacklisted"] != true {
+		t.Fatalf("expected the card to flag the blacklisted source, got %v", card["sourceBlacklisted"])
+	}
+
+	if body := setBlacklisted("0"); !strings.Contains(body, sourceName+" is no longer blacklisted") {
+		t.Fatalf("expected the allow confirmation, got %s", body)
+	}
+	if body := get("/dashboard/trackers/new?profile=profile1"); !strings.Contains(body, option) {
+		t.Fatalf("expected %s to be offered again", sourceName)
+	}
+}
+
+func TestSourceBlacklistRejectsUnknownSite(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/sources/999/blacklist?profile=profile1", strings.NewReader("blacklisted=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("save blacklist: %v", err)
+	}
+	if res.StatusCode != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown site, got %d", res.StatusCode)
+	}
+}
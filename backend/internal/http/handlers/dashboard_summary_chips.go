@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// summaryChipsTTL bounds how long the header counts are served from memory.
+// The chips refresh with every trackers list change, so without it each
+// partial refresh would cost another count over the release history.
+const summaryChipsTTL = time.Minute
+
+type summaryChipsEntry struct {
+	counts     repository.NewChapterCounts
+	generation uint64
+	expires    time.Time
+}
+
+// summaryChipsCache keeps the header counts of each profile. An entry is
+// dropped once it expires or once the poller finishes another cycle, which
+// is what brings new chapters in.
+type summaryChipsCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[int64]summaryChipsEntry
+}
+
+func newSummaryChipsCache(ttl time.Duration) *summaryChipsCache {
+	return &summaryChipsCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[int64]summaryChipsEntry),
+	}
+}
+
+func (c *summaryChipsCache) lookup(profileID int64, generation uint64) (repository.NewChapterCounts, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[profileID]
+	if !ok || entry.generation != generation || !c.now().Before(entry.expires) {
+		return repository.NewChapterCounts{}, false
+	}
+	return entry.counts, true
+}
+
+func (c *summaryChipsCache) store(profileID int64, generation uint64, counts repository.NewChapterCounts) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[profileID] = summaryChipsEntry{counts: counts, generation: generation, expires: c.now().Add(c.ttl)}
+}
+
+type summaryChipsPartialData struct {
+	Today int
+	Week  int
+}
+
+// SummaryChipsPartial renders the header line counting the active profile's
+// new chapters today and this week.
+func (h *DashboardHandler) SummaryChipsPartial(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	counts, err := h.newChapterCounts(c.UserContext(), activeProfile.ID)
+	if err != nil {
+		return serverError(c, "Failed to count new chapters", err)
+	}
+	return h.render(c, "summary_chips_partial.html", summaryChipsPartialData{Today: counts.Today, Week: counts.Week})
+}
+
+// newChapterCounts returns the profile's counts from the cache, counting
+// them afresh on a miss.
+func (h *DashboardHandler) newChapterCounts(ctx context.Context, profileID int64) (repository.NewChapterCounts, error) {
+	generation := readPollStatus(h.pollStatus).Generation
+	if counts, ok := h.summaryChips.lookup(profileID, generation); ok {
+		return counts, nil
+	}
+	now := h.summaryChips.now().UTC()
+	dayStart, weekStart := summaryWindows(now)
+	counts, err := h.trackerRepo.CountNewChapters(ctx, profileID, dayStart, weekStart, now)
+	if err != nil {
+		return repository.NewChapterCounts{}, err
+	}
+	h.summaryChips.store(profileID, generation, counts)
+	return counts, nil
+}
+
+// summaryWindows returns the starts of the UTC day and of the UTC week,
+// which begins on Monday, holding now. Profiles have no time zone of their
+// own, so every profile counts in UTC.
+func summaryWindows(now time.Time) (time.Time, time.Time) {
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	sinceMonday := (int(now.Weekday()) + 6) % 7
+	return dayStart, dayStart.AddDate(0, 0, -sinceMonday)
+}
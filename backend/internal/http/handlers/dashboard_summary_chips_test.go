@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/scheduler"
+	"github.com/gofiber/fiber/v2"
+)
+
+type generationPollStatus struct {
+	generation uint64
+}
+
+func (s *generationPollStatus) Status() scheduler.Status {
+	return scheduler.Status{Generation: s.generation}
+}
+
+func TestSummaryChipsCountTodayAndThisWeekUntilTheNextPoll(t *testing.T) {
+	db, h := setupInternalDashboardHandler(t, nil)
+	poll := &generationPollStatus{}
+	h.SetPollStatus(poll)
+	// Tuesday, 30 minutes past midnight UTC.
+	now := time.Date(2026, 3, 10, 0, 30, 0, 0, time.UTC)
+	h.summaryChips.now = func() time.Time { return now }
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter)
+		VALUES (1, 'Chip Blade', 1, 'https://mangadex.org/title/chip-blade', 'reading', 12)
+	`)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	addChapter := func(chapter float64, releasedAt time.Time) {
+		t.Helper()
+		if _, err := db.Exec(`INSERT INTO chapters (tracker_id, chapter_number, released_at) VALUES (?, ?, ?)`, trackerID, chapter, releasedAt); err != nil {
+			t.Fatalf("insert chapter: %v", err)
+		}
+	}
+	addChapter(10, now.Add(-2*time.Hour))
+	addChapter(11, now.Add(-time.Hour))
+	addChapter(12, now.Add(-10*time.Minute))
+
+	app := fiber.New()
+	app.Get("/dashboard/summary-chips", h.SummaryChipsPartial)
+	get := func() string {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/dashboard/summary-chips?profile=profile1", nil), -1)
+		if err != nil {
+			t.Fatalf("get summary chips: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read response: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", resp.StatusCode, body)
+		}
+		return string(body)
+	}
+
+	// Chapters 10 and 11 came out on Monday, before midnight.
+	if body := get(); !strings.Contains(body, "1 new chapter today · 3 this week") {
+		t.Fatalf("expected today's and this week's counts, got %s", body)
+	}
+
+	addChapter(13, now.Add(-5*time.Minute))
+	if body := get(); !strings.Contains(body, "1 new chapter today · 3 this week") {
+		t.Fatalf("expected the cached counts before the next poll, got %s", body)
+	}
+
+	poll.generation++
+	if body := get(); !strings.Contains(body, "2 new chapters today · 4 this week") {
+		t.Fatalf("expected fresh counts once a poll finished, got %s", body)
+	}
+
+	addChapter(14, now.Add(-time.Minute))
+	now = now.Add(summaryChipsTTL)
+	if body := get(); !strings.Contains(body, "3 new chapters today · 5 this week") {
+		t.Fatalf("expected fresh counts once the cache expired, got %s", body)
+	}
+}
+
+func TestSummaryWindowsStartTheWeekOnMonday(t *testing.T) {
+	cases := []struct {
+		now       time.Time
+		dayStart  time.Time
+		weekStart time.Time
+	}{
+		{
+			now:       time.Date(2026, 3, 15, 23, 59, 59, 0, time.UTC),
+			dayStart:  time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+			weekStart: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			now:       time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC),
+			dayStart:  time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC),
+			weekStart: time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Counted in UTC, where it is already Monday.
+			now:       time.Date(2026, 3, 15, 22, 0, 0, 0, time.FixedZone("UTC-3", -3*60*60)),
+			dayStart:  time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC),
+			weekStart: time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range cases {
+		dayStart, weekStart := summaryWindows(tc.now)
+		if !dayStart.Equal(tc.dayStart) || !weekStart.Equal(tc.weekStart) {
+			t.Fatalf("summaryWindows(%s) = %s, %s; want %s, %s", tc.now, dayStart, weekStart, tc.dayStart, tc.weekStart)
+		}
+	}
+}
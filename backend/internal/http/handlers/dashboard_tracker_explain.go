@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+type trackerExplainData struct {
+	Explanation *repository.TrackerListExplanation
+	IgnoredTags []string
+	AutofocusID string
+}
+
+// SetDebugTools shows or hides the dashboard's troubleshooting views. They
+// are off by default.
+func (h *DashboardHandler) SetDebugTools(enabled bool) {
+	h.debugTools = enabled
+}
+
+// ExplainModal shows why the tracker is, or is not, in the list the
+// dashboard's current filters select, one filter clause per row. It is only
+// served while debug tools are on.
+func (h *DashboardHandler) ExplainModal(c *fiber.Ctx) error {
+	if !h.debugTools {
+		return c.Status(fiber.StatusNotFound).SendString("Not found")
+	}
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+
+	listOptions := trackerListOptionsFromQuery(c, activeProfile.ID)
+	ignoredTags, err := dropUnknownTagFilters(c.UserContext(), h.trackerRepo, &listOptions)
+	if err != nil {
+		return serverError(c, "Failed to load profile tags", err)
+	}
+
+	explanation, err := h.trackerRepo.ExplainListMembership(c.UserContext(), id, listOptions)
+	if err != nil {
+		return serverError(c, "Failed to explain tracker filters", err)
+	}
+	if explanation == nil {
+		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
+	}
+
+	return h.render(c, "tracker_explain_modal.html", trackerExplainData{
+		Explanation: explanation,
+		IgnoredTags: ignoredTags,
+		AutofocusID: "tracker-explain-close",
+	})
+}
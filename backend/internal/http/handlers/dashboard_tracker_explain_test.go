@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestExplainModalShowsTheFailingFilterOnlyWithDebugTools(t *testing.T) {
+	db, h := setupInternalDashboardHandler(t, nil)
+	app := fiber.New()
+	app.Get("/dashboard/trackers/:id/explain", h.ExplainModal)
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Caught Up Series', 1, 'https://mangadex.org/title/caught-up', 'reading', 120, 120)
+	`)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	id, _ := result.LastInsertId()
+	path := "/dashboard/trackers/" + strconv.FormatInt(id, 10) + "/explain?status=reading&tags=gone"
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("request %s: %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if status, _ := get(path); status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 while debug tools are off, got %d", status)
+	}
+
+	h.SetDebugTools(true)
+	status, body := get(path)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	for _, want := range []string{
+		"Caught Up Series — Filters",
+		"Hidden by the current filters.",
+		"excluded because last read 120 ≥ latest 120",
+		"Tag 'gone' no longer exists",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in the explanation, got %s", want, body)
+		}
+	}
+
+	if status, _ := get("/dashboard/trackers/999/explain"); status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for a missing tracker, got %d", status)
+	}
+}
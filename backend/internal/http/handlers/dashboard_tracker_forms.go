@@ -3,43 +3,86 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
 	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
 	"github.com/gofiber/fiber/v2"
 )
 
 func (h *DashboardHandler) NewTrackerModal(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
 	viewMode := normalizeViewMode(c.Query("view", "grid"))
 
-	sources, err := h.sourceRepo.ListEnabled()
+	sources, err := pageCtx.Sources()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load sources")
+		return sendPageError(c, err)
+	}
+	blacklistedSourceIDs, err := pageCtx.BlacklistedSources()
+	if err != nil {
+		return sendPageError(c, err)
 	}
 
-	profileTags, err := h.trackerRepo.ListProfileTags(activeProfile.ID)
+	profileTags, err := pageCtx.ProfileTags()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load profile tags")
+		return sendPageError(c, err)
+	}
+
+	data := trackerFormData{
+		Mode:                 "create",
+		ViewMode:             viewMode,
+		Sources:              sources,
+		LinkedSources:        []models.TrackerSource{},
+		ProfileTags:          profileTags,
+		TrackerTags:          []models.CustomTag{},
+		TagIconKeys:          tagIconKeysOrdered,
+		LanguageSourceIDs:    h.languageSourceIDs(sources),
+		BlacklistedSourceIDs: blacklistedSourceIDs,
+		AutofocusID:          "tracker-title-input",
+	}
+	if sourceURL := strings.TrimSpace(c.Query("source_url")); sourceURL != "" {
+		// A URL the profile already tracks, now or before the source moved
+		// it, opens that tracker instead.
+		resolved, err := h.trackerRepo.ResolveTrackerURL(c.UserContext(), pageCtx.profile.ID, sourceURL)
+		if err != nil {
+			return serverError(c, "Failed to look up the URL", err)
+		}
+		if resolved != nil {
+			existing, err := h.editFormData(c.UserContext(), pageCtx.profile.ID, resolved.TrackerID)
+			if err != nil {
+				return serverError(c, "Failed to load tracker", err)
+			}
+			if existing != nil {
+				existing.ViewMode = viewMode
+				existing.DebugTools = h.debugTools
+				return h.render(c, "tracker_form_modal.html", *existing)
+			}
+		}
+
+		data.PrefillSourceURL = sourceURL
+		if sourceKey := linkcache.InferSourceKey(sourceURL); sourceKey != "" {
+			for _, source := range sources {
+				if source.Key == sourceKey {
+					data.PrefillSourceID = source.ID
+					break
+				}
+			}
+		}
 	}
 
-	return h.render(c, "tracker_form_modal.html", trackerFormData{
-		Mode:          "create",
-		ViewMode:      viewMode,
-		Sources:       sources,
-		LinkedSources: []models.TrackerSource{},
-		ProfileTags:   profileTags,
-		TrackerTags:   []models.CustomTag{},
-		TagIconKeys:   tagIconKeysOrdered,
-	})
+	return h.render(c, "tracker_form_modal.html", data)
 }
 
 func (h *DashboardHandler) EmptyModal(c *fiber.Ctx) error {
@@ -47,10 +90,11 @@ func (h *DashboardHandler) EmptyModal(c *fiber.Ctx) error {
 }
 
 func (h *DashboardHandler) EditTrackerModal(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 	viewMode := normalizeViewMode(c.Query("view", "grid"))
 
 	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
@@ -58,22 +102,41 @@ func (h *DashboardHandler) EditTrackerModal(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
 	}
 
-	tracker, err := h.trackerRepo.GetByID(activeProfile.ID, id)
+	data, err := h.editFormData(c.UserContext(), activeProfile.ID, id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load tracker")
+		return serverError(c, "Failed to load tracker", err)
 	}
-	if tracker == nil {
+	if data == nil {
 		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
 	}
+	data.ViewMode = viewMode
+	data.DebugTools = h.debugTools
+	return h.render(c, "tracker_form_modal.html", *data)
+}
+
+// loadEditFormData reads everything the edit form shows of a tracker. It
+// returns nil when the profile has no such tracker.
+func (h *DashboardHandler) loadEditFormData(ctx context.Context, profileID, id int64) (*trackerFormData, error) {
+	tracker, err := h.trackerRepo.GetByID(ctx, profileID, id)
+	if err != nil {
+		return nil, fmt.Errorf("load tracker: %w", err)
+	}
+	if tracker == nil {
+		return nil, nil
+	}
 
-	sources, err := h.sourceRepo.ListEnabled()
+	sources, err := h.sourceRepo.ListEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load sources: %w", err)
+	}
+	blacklistedSourceIDs, err := h.sourceRepo.ListProfileBlacklistedSourceIDs(ctx, profileID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load sources")
+		return nil, fmt.Errorf("load site blacklist: %w", err)
 	}
 
-	linkedSources, err := h.trackerRepo.ListTrackerSources(activeProfile.ID, id)
+	linkedSources, err := h.trackerRepo.ListTrackerSources(ctx, profileID, id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked sources")
+		return nil, fmt.Errorf("load linked sources: %w", err)
 	}
 	if len(linkedSources) == 0 {
 		sourceName := ""
@@ -89,54 +152,106 @@ func (h *DashboardHandler) EditTrackerModal(c *fiber.Ctx) error {
 			SourceName:   sourceName,
 			SourceItemID: tracker.SourceItemID,
 			SourceURL:    tracker.SourceURL,
+			Lang:         connectors.DefaultLanguage,
 		})
 	}
 
-	profileTags, err := h.trackerRepo.ListProfileTags(activeProfile.ID)
+	for index := range linkedSources {
+		linkedSources[index].Reliability = linkedSourceReliability(linkedSources[index])
+	}
+
+	profileTags, err := h.trackerRepo.ListProfileTags(ctx, profileID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load profile tags")
+		return nil, fmt.Errorf("load profile tags: %w", err)
 	}
 
-	return h.render(c, "tracker_form_modal.html", trackerFormData{
-		Mode:          "edit",
-		ViewMode:      viewMode,
-		Tracker:       tracker,
-		Sources:       sources,
-		LinkedSources: linkedSources,
-		ProfileTags:   profileTags,
-		TrackerTags:   tracker.Tags,
-		TagIconKeys:   tagIconKeysOrdered,
-	})
+	continuation, continuationSuggestion, err := h.continuationFormOptions(ctx, profileID, tracker)
+	if err != nil {
+		return nil, fmt.Errorf("load continuation: %w", err)
+	}
+
+	readSources, err := h.trackerRepo.ListTrackerReadSources(ctx, profileID, id)
+	if err != nil {
+		return nil, fmt.Errorf("load read sources: %w", err)
+	}
+	readEvents, err := h.trackerRepo.ListReadEvents(ctx, profileID, id)
+	if err != nil {
+		return nil, fmt.Errorf("load reading history: %w", err)
+	}
+	releases, err := h.trackerRepo.ListChapterReleases(ctx, profileID, id)
+	if err != nil {
+		return nil, fmt.Errorf("load release history: %w", err)
+	}
+
+	return &trackerFormData{
+		Mode:                   "edit",
+		Tracker:                tracker,
+		Sources:                sources,
+		LinkedSources:          linkedSources,
+		ProfileTags:            profileTags,
+		TrackerTags:            tracker.Tags,
+		TagIconKeys:            tagIconKeysOrdered,
+		LanguageSourceIDs:      h.languageSourceIDs(sources),
+		BlacklistedSourceIDs:   blacklistedSourceIDs,
+		ManualSource:           h.manualSourceSelected(sources, tracker),
+		Continuation:           continuation,
+		ContinuationSuggestion: continuationSuggestion,
+		ReadSources:            readSources,
+		ReadingHistory:         readingHistorySeries(readEvents),
+		ReleaseCadence:         releaseCadenceOf(releases, time.Now().UTC()),
+		GenreSuggestions:       genreTagSuggestions(tracker.SourceGenres, profileTags, tracker.Tags),
+		AutofocusID:            "tracker-title-input",
+	}, nil
 }
 
 func (h *DashboardHandler) CreateFromForm(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	tracker, err := parseTrackerFromForm(c)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
 	}
 	tracker.ProfileID = activeProfile.ID
+	viewMode := normalizeViewMode(c.FormValue("view_mode", c.Query("view", "grid")))
 
-	h.enrichTrackerFromSource(c.Context(), tracker)
+	suggestionChoice := strings.TrimSpace(c.FormValue("url_suggestion"))
+	if suggestionChoice == urlSuggestionAccept {
+		var suggestion connectors.MangaResult
+		if err := json.Unmarshal([]byte(c.FormValue("url_suggestion_json")), &suggestion); err != nil || strings.TrimSpace(suggestion.URL) == "" {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid URL suggestion")
+		}
+		applyURLSuggestion(tracker, suggestion)
+	}
 
-	now := time.Now().UTC()
-	tracker.LastCheckedAt = &now
+	if tracker.SourceURL, err = h.canonicalSourceURL(c.UserContext(), tracker.SourceID, tracker.SourceURL); err != nil {
+		return sourceURLErrorText(c, err)
+	}
 
-	exists, err := h.trackerRepo.SourceExists(tracker.SourceID)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to validate source")
+	enrichErr := h.enrichTrackerFromSource(c.UserContext(), tracker, connectors.DefaultLanguage)
+	if errors.Is(enrichErr, connectors.ErrNotFound) && suggestionChoice == "" {
+		if suggestion := h.suggestSourceByTitle(c.UserContext(), tracker); suggestion != nil {
+			return h.renderURLSuggestion(c, pageCtx, viewMode, tracker, suggestion)
+		}
 	}
-	if !exists {
-		return c.Status(fiber.StatusBadRequest).SendString("Selected source does not exist")
+
+	// Only a lookup that went through counts as a check; until then the
+	// card shows the tracker as not yet checked and the poller takes it
+	// first.
+	if enrichErr == nil {
+		now := time.Now().UTC()
+		tracker.LastCheckedAt = &now
 	}
 
-	created, err := h.trackerRepo.Create(tracker)
+	exists, err := h.trackerRepo.SourceExists(c.UserContext(), tracker.SourceID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to create tracker")
+		return serverError(c, "Failed to validate source", err)
+	}
+	if !exists {
+		return c.Status(fiber.StatusBadRequest).SendString("Selected source does not exist")
 	}
 
 	tagIDs, err := parseTagIDsFromForm(c)
@@ -144,18 +259,159 @@ func (h *DashboardHandler) CreateFromForm(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
 	}
 
-	if created != nil {
-		if err := h.trackerRepo.ReplaceTrackerTags(activeProfile.ID, created.ID, tagIDs); err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Failed to save tracker tags")
+	// The tracker and its tags are saved together, so a failed tag write
+	// does not leave an untagged tracker behind.
+	var created *models.Tracker
+	failure := "Failed to create tracker"
+	err = h.trackerRepo.WithTx(c.UserContext(), func(txRepo *repository.TrackerRepository) error {
+		var err error
+		if created, err = txRepo.Create(c.UserContext(), tracker); err != nil || created == nil {
+			return err
 		}
+		failure = "Failed to save tracker tags"
+		return txRepo.ReplaceTrackerTags(c.UserContext(), activeProfile.ID, created.ID, tagIDs)
+	})
+	if err != nil {
+		return serverError(c, failure, err)
 	}
+	// Other trackers' forms can offer the new one as their continuation.
+	h.editForms.forgetProfile(activeProfile.ID)
 	if created == nil {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
+	if enrichErr != nil {
+		slog.Info("tracker source lookup failed, retrying in background", "tracker_id", created.ID, "error", enrichErr)
+		h.QueueEnrichmentRetry(activeProfile.ID, created.ID)
+	}
 
-	c.Set("HX-Trigger", fmt.Sprintf(`{"trackerCreated":{"id":%d}}`, created.ID))
-	return h.render(c, "empty_modal.html", nil)
+	setHXTrigger(c, map[string]any{"trackerCreated": map[string]any{"id": created.ID}})
+	// Nothing but the announcement is rendered, which also closes the modal.
+	response := trackerOOBResponseData{}
+	response.announce(mutationCreated, created.Title, nil)
+	return h.render(c, "tracker_oob_response.html", response)
+}
+
+// urlSuggestionAccept is posted back as url_suggestion to use a suggested
+// match; any other choice, such as "keep", saves the URL as entered.
+const urlSuggestionAccept = "accept"
+
+// urlSuggestionMatchThreshold is the lowest title similarity at which a
+// search result is offered in place of a new tracker's URL that did not
+// resolve.
+const urlSuggestionMatchThreshold = 0.8
+
+// suggestSourceByTitle searches the tracker's source for its title once its
+// URL has not resolved, and returns the top result when its title closely
+// matches the tracker's. It returns nil when there is no such result or the
+// search cannot run.
+func (h *DashboardHandler) suggestSourceByTitle(parent context.Context, tracker *models.Tracker) *connectors.MangaResult {
+	if h.scrapingAllowed() != nil {
+		return nil
+	}
+	source, err := h.sourceRepo.GetByID(parent, tracker.SourceID)
+	if err != nil || source == nil || !source.Enabled {
+		return nil
+	}
+	connector, ok := h.registry.Get(source.Key)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(parent, 8*time.Second)
+	defer cancel()
+
+	results, err := connector.SearchByTitle(ctx, tracker.Title, defaultSourceSearchLimit)
+	if err != nil {
+		slog.Info("title search for unresolved tracker url failed", "source_key", source.Key, "title", tracker.Title, "error", err)
+		return nil
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	top := results[0]
+	if strings.TrimSpace(top.URL) == "" || strings.EqualFold(strings.TrimSpace(top.URL), tracker.SourceURL) {
+		return nil
+	}
+	if searchutil.BestTitleSimilarity([]string{tracker.Title}, append([]string{top.Title}, top.RelatedTitles...)) < urlSuggestionMatchThreshold {
+		return nil
+	}
+	return &top
+}
+
+// applyURLSuggestion points the tracker at an accepted suggestion, taking
+// its chapter data where the form left it out.
+func applyURLSuggestion(tracker *models.Tracker, suggestion connectors.MangaResult) {
+	tracker.SourceURL = strings.TrimSpace(suggestion.URL)
+	tracker.SourceItemID = nil
+	if itemID := strings.TrimSpace(suggestion.SourceItemID); itemID != "" {
+		tracker.SourceItemID = &itemID
+	}
+	if (tracker.LatestKnownChapter == nil || *tracker.LatestKnownChapter <= 0) && suggestion.LatestChapter != nil {
+		tracker.LatestKnownChapter = suggestion.LatestChapter
+	}
+	if tracker.LatestReleaseAt == nil && suggestion.LastUpdatedAt != nil {
+		updatedAt := suggestion.LastUpdatedAt.UTC()
+		tracker.LatestReleaseAt = &updatedAt
+	}
+	if len(tracker.RelatedTitles) == 0 && len(suggestion.RelatedTitles) > 0 {
+		tracker.RelatedTitles = suggestion.RelatedTitles
+	}
+}
+
+// renderURLSuggestion re-renders the create form with the submitted values
+// and the suggested match, so nothing is saved until the form is posted
+// again with url_suggestion set.
+func (h *DashboardHandler) renderURLSuggestion(c *fiber.Ctx, pageCtx *pageContext, viewMode string, tracker *models.Tracker, suggestion *connectors.MangaResult) error {
+	sources, err := pageCtx.Sources()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	blacklistedSourceIDs, err := pageCtx.BlacklistedSources()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	profileTags, err := pageCtx.ProfileTags()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	selectedTags, err := selectedTagsFromForm(c, profileTags)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	return h.render(c, "tracker_form_modal.html", trackerFormData{
+		Mode:                 "create",
+		ViewMode:             viewMode,
+		Tracker:              tracker,
+		Sources:              sources,
+		LinkedSources:        []models.TrackerSource{},
+		ProfileTags:          profileTags,
+		TrackerTags:          selectedTags,
+		TagIconKeys:          tagIconKeysOrdered,
+		LanguageSourceIDs:    h.languageSourceIDs(sources),
+		URLSuggestion:        suggestion,
+		BlacklistedSourceIDs: blacklistedSourceIDs,
+		AutofocusID:          "url-suggestion-accept",
+	})
+}
+
+// selectedTagsFromForm returns the profile tags ticked in the submitted form.
+func selectedTagsFromForm(c *fiber.Ctx, profileTags []models.CustomTag) ([]models.CustomTag, error) {
+	tagIDs, err := parseTagIDsFromForm(c)
+	if err != nil {
+		return nil, err
+	}
+	selectedTags := make([]models.CustomTag, 0, len(tagIDs))
+	for _, tag := range profileTags {
+		for _, tagID := range tagIDs {
+			if tag.ID == tagID {
+				selectedTags = append(selectedTags, tag)
+				break
+			}
+		}
+	}
+	return selectedTags, nil
 }
 
 type trackerCardFragmentData struct {
@@ -164,10 +420,11 @@ type trackerCardFragmentData struct {
 }
 
 func (h *DashboardHandler) CardFragment(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
 	if err != nil || id <= 0 {
@@ -176,25 +433,25 @@ func (h *DashboardHandler) CardFragment(c *fiber.Ctx) error {
 
 	viewMode := normalizeViewMode(c.Query("view", "grid"))
 
-	tracker, err := h.trackerRepo.GetByID(activeProfile.ID, id)
+	tracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load tracker")
+		return serverError(c, "Failed to load tracker", err)
 	}
 	if tracker == nil {
 		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
 	}
 
-	sourceByID, err := h.listSourcesByID()
+	sourceByID, err := pageCtx.SourceByID()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load sources")
+		return sendPageError(c, err)
 	}
 
-	sourceLogoBySourceID, err := h.sourceRepo.ListProfileSourceLogoURLs(activeProfile.ID)
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked site logos")
+		return sendPageError(c, err)
 	}
 
-	cards, _ := h.buildTrackerCards([]models.Tracker{*tracker}, sourceByID, sourceLogoBySourceID, "")
+	cards, _ := h.buildTrackerCards(c.UserContext(), []models.Tracker{*tracker}, sourceByID, sourceLogoBySourceID, "")
 	if len(cards) == 0 {
 		return c.Status(fiber.StatusNotFound).SendString("Tracker card not found")
 	}
@@ -205,30 +462,44 @@ func (h *DashboardHandler) CardFragment(c *fiber.Ctx) error {
 	})
 }
 
-func (h *DashboardHandler) enrichTrackerFromSource(parent context.Context, tracker *models.Tracker) {
+// enrichTrackerFromSource fills in source metadata the form left out. It
+// returns an error only when the source could not be asked or did not answer,
+// which is worth retrying later; trackers without a usable source or that
+// already have the metadata are left alone. lang is the primary source's
+// language.
+func (h *DashboardHandler) enrichTrackerFromSource(parent context.Context, tracker *models.Tracker, lang string) error {
 	if tracker == nil || strings.TrimSpace(tracker.SourceURL) == "" || tracker.SourceID <= 0 {
-		return
+		return nil
 	}
 	if hasResolvedSourceMetadata(tracker) {
-		return
+		return nil
+	}
+	if err := h.scrapingAllowed(); err != nil {
+		return err
 	}
 
-	source, err := h.sourceRepo.GetByID(tracker.SourceID)
-	if err != nil || source == nil || !source.Enabled {
-		return
+	source, err := h.sourceRepo.GetByID(parent, tracker.SourceID)
+	if err != nil {
+		return err
+	}
+	if source == nil || !source.Enabled {
+		return nil
 	}
 
 	connector, ok := h.registry.Get(source.Key)
 	if !ok {
-		return
+		return nil
 	}
 
 	ctx, cancel := context.WithTimeout(parent, 8*time.Second)
 	defer cancel()
 
-	resolved, err := connector.ResolveByURL(ctx, tracker.SourceURL)
-	if err != nil || resolved == nil {
-		return
+	resolved, err := connectors.ResolveByURLWithLang(ctx, connector, tracker.SourceURL, lang)
+	if err != nil {
+		return err
+	}
+	if resolved == nil {
+		return fmt.Errorf("empty result")
 	}
 
 	if tracker.SourceItemID == nil {
@@ -250,8 +521,18 @@ func (h *DashboardHandler) enrichTrackerFromSource(parent context.Context, track
 		tracker.LatestReleaseAt = &updatedAt
 	}
 	if len(resolved.RelatedTitles) > 0 {
-		tracker.RelatedTitles = searchutil.FilterEnglishAlphabetNames(resolved.RelatedTitles)
+		tracker.RelatedTitles = limitRelatedTitles(resolved.RelatedTitles, "source_key", source.Key, "source_url", tracker.SourceURL)
 	}
+	if len(resolved.Genres) > 0 {
+		tracker.SourceGenres = resolved.Genres
+	}
+
+	// The lookup already carries the cover, so the new card does not need a
+	// second request for it.
+	if coverURL := strings.TrimSpace(h.registry.MediaURL(source.Key, resolved.CoverImageURL)); coverURL != "" {
+		h.setCachedCover(linkcache.CoverKey(source.Key, tracker.SourceURL, tracker.SourceItemID), coverURL, true, linkcache.FoundTTL)
+	}
+	return nil
 }
 
 func hasResolvedSourceMetadata(tracker *models.Tracker) bool {
@@ -276,10 +557,11 @@ func hasResolvedSourceMetadata(tracker *models.Tracker) bool {
 }
 
 func (h *DashboardHandler) UpdateFromForm(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	viewMode := normalizeViewMode(c.FormValue("view_mode", c.Query("view", "grid")))
 
@@ -287,10 +569,11 @@ func (h *DashboardHandler) UpdateFromForm(c *fiber.Ctx) error {
 	if err != nil || id <= 0 {
 		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
 	}
+	defer h.editForms.forgetProfile(activeProfile.ID)
 
-	existingTracker, err := h.trackerRepo.GetByID(activeProfile.ID, id)
+	existingTracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load tracker")
+		return serverError(c, "Failed to load tracker", err)
 	}
 	if existingTracker == nil {
 		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
@@ -305,11 +588,26 @@ func (h *DashboardHandler) UpdateFromForm(c *fiber.Ctx) error {
 	tracker.Rating = existingTracker.Rating
 	tracker.ProfileID = activeProfile.ID
 
+	if message, err := h.validateContinuation(c.UserContext(), activeProfile.ID, id, tracker.ContinuedByTrackerID); err != nil {
+		return serverError(c, "Failed to validate continuation", err)
+	} else if message != "" {
+		return c.Status(fiber.StatusBadRequest).SendString(message)
+	}
+
 	linkedSources, err := parseLinkedSourcesFromForm(c)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
 	}
 
+	if tracker.SourceURL, err = h.canonicalSourceURL(c.UserContext(), tracker.SourceID, tracker.SourceURL); err != nil {
+		return sourceURLErrorText(c, err)
+	}
+	for index := range linkedSources {
+		if linkedSources[index].SourceURL, err = h.canonicalSourceURL(c.UserContext(), linkedSources[index].SourceID, linkedSources[index].SourceURL); err != nil {
+			return sourceURLErrorText(c, err)
+		}
+	}
+
 	primaryFromForm := models.TrackerSource{
 		SourceID:     tracker.SourceID,
 		SourceItemID: tracker.SourceItemID,
@@ -322,18 +620,18 @@ func (h *DashboardHandler) UpdateFromForm(c *fiber.Ctx) error {
 	}
 
 	for _, source := range uniqueSources {
-		exists, err := h.trackerRepo.SourceExists(source.SourceID)
+		exists, err := h.trackerRepo.SourceExists(c.UserContext(), source.SourceID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Failed to validate linked source")
+			return serverError(c, "Failed to validate linked source", err)
 		}
 		if !exists {
 			return c.Status(fiber.StatusBadRequest).SendString("One of the linked sources does not exist")
 		}
 	}
 
-	existingSources, err := h.trackerRepo.ListTrackerSources(activeProfile.ID, id)
+	existingSources, err := h.trackerRepo.ListTrackerSources(c.UserContext(), activeProfile.ID, id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked sources")
+		return serverError(c, "Failed to load linked sources", err)
 	}
 	if len(existingSources) == 0 {
 		existingSources = []models.TrackerSource{
@@ -346,8 +644,19 @@ func (h *DashboardHandler) UpdateFromForm(c *fiber.Ctx) error {
 		}
 	}
 
-	if !sameTrackerSources(existingSources, uniqueSources) {
-		primarySource, latestKnownChapter, latestReleaseAt, relatedTitles := h.selectPrimaryTrackerSource(c.Context(), uniqueSources)
+	// The chosen primary can only be pinned, or asked about, while it is still
+	// one of the linked sources; removing it always promotes another one.
+	chosenPrimaryLinked := containsTrackerSource(uniqueSources, primaryFromForm)
+	autoPrimary := !chosenPrimaryLinked || strings.TrimSpace(c.FormValue("auto_primary")) != "0"
+	if autoPrimary && !sameTrackerSources(existingSources, uniqueSources) {
+		primarySource, latestKnownChapter, latestReleaseAt, relatedTitles := h.selectPrimaryTrackerSource(c.UserContext(), uniqueSources)
+		if chosenPrimaryLinked && primarySourceChanged(primaryFromForm, primarySource) && strings.TrimSpace(c.FormValue("confirm_primary_switch")) != "1" {
+			return h.renderPrimarySwitchConfirmation(c, pageCtx, viewMode, id, tracker, uniqueSources, primarySource, latestKnownChapter)
+		}
+		if primarySourceChanged(primaryFromForm, primarySource) {
+			// The last read chapter was given in the old primary's numbering.
+			tracker.LastReadChapter = offsetChapter(tracker.LastReadChapter, trackerSourceChapterOffset(uniqueSources, primaryFromForm)-primarySource.ChapterOffset)
+		}
 		tracker.SourceID = primarySource.SourceID
 		tracker.SourceItemID = primarySource.SourceItemID
 		tracker.SourceURL = primarySource.SourceURL
@@ -362,92 +671,397 @@ func (h *DashboardHandler) UpdateFromForm(c *fiber.Ctx) error {
 		}
 	}
 
-	exists, err := h.trackerRepo.SourceExists(tracker.SourceID)
+	exists, err := h.trackerRepo.SourceExists(c.UserContext(), tracker.SourceID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to validate source")
+		return serverError(c, "Failed to validate source", err)
 	}
 	if !exists {
 		return c.Status(fiber.StatusBadRequest).SendString("Selected source does not exist")
 	}
 
-	updated, err := h.trackerRepo.Update(activeProfile.ID, id, tracker)
+	tagIDs, err := parseTagIDsFromForm(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	// The tracker row, its linked sources, tags and continuation are saved
+	// in one transaction: a failure at any step leaves the tracker as it was
+	// rather than half updated.
+	var updated *models.Tracker
+	failure := "Failed to update tracker"
+	err = h.trackerRepo.WithTx(c.UserContext(), func(txRepo *repository.TrackerRepository) error {
+		var err error
+		if updated, err = txRepo.Update(c.UserContext(), activeProfile.ID, id, tracker); err != nil || updated == nil {
+			return err
+		}
+		recordReadSource(c.UserContext(), txRepo, updated, 0, existingTracker.LastReadChapter, updated.LastReadChapter)
+
+		failure = "Failed to save linked sources"
+		if err := txRepo.ReplaceTrackerSources(c.UserContext(), activeProfile.ID, id, uniqueSources); err != nil {
+			return err
+		}
+		failure = "Failed to save tracker tags"
+		if err := txRepo.ReplaceTrackerTags(c.UserContext(), activeProfile.ID, id, tagIDs); err != nil {
+			return err
+		}
+		failure = "Failed to save continuation"
+		return txRepo.SetContinuation(c.UserContext(), activeProfile.ID, id, tracker.ContinuedByTrackerID)
+	})
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to update tracker")
+		return serverError(c, failure, err)
 	}
 	if updated == nil {
 		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
 	}
+	h.flagMismatchedLinkedSources(c.UserContext(), activeProfile.ID, updated, existingSources, uniqueSources)
 
-	if err := h.trackerRepo.ReplaceTrackerSources(activeProfile.ID, id, uniqueSources); err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to save linked sources")
+	fullTracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
+	if err != nil || fullTracker == nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
 	}
 
-	tagIDs, err := parseTagIDsFromForm(c)
+	sourceByID, err := pageCtx.SourceByID()
+	if err != nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
+	if err != nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	cards, _ := h.buildTrackerCards(c.UserContext(), []models.Tracker{*fullTracker}, sourceByID, sourceLogoBySourceID, "")
+	if len(cards) == 0 {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		return h.render(c, "empty_modal.html", nil)
+	}
+
+	// New tags or linked sites may take the tracker out of the recently
+	// added strip.
+	setHXTrigger(c, map[string]any{"recentAdditionsChanged": true})
+	response := trackerOOBResponseData{
+		ViewMode:    viewMode,
+		ReplaceCard: &cards[0],
+	}
+	response.announce(mutationUpdated, "", &cards[0])
+	return h.render(c, "tracker_oob_response.html", response)
+}
+
+// renderPrimarySwitchConfirmation re-renders the edit form with the submitted
+// values and a summary of the primary source that saving would switch to, so
+// the switch only happens once the form is posted again with
+// confirm_primary_switch=1.
+func (h *DashboardHandler) renderPrimarySwitchConfirmation(
+	c *fiber.Ctx,
+	pageCtx *pageContext,
+	viewMode string,
+	trackerID int64,
+	tracker *models.Tracker,
+	linkedSources []models.TrackerSource,
+	proposed models.TrackerSource,
+	proposedChapter *float64,
+) error {
+	sources, err := pageCtx.Sources()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	blacklistedSourceIDs, err := pageCtx.BlacklistedSources()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	sourceByID, err := pageCtx.SourceByID()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+
+	profileTags, err := pageCtx.ProfileTags()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	selectedTags, err := selectedTagsFromForm(c, profileTags)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
 	}
 
-	if err := h.trackerRepo.ReplaceTrackerTags(activeProfile.ID, id, tagIDs); err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to save tracker tags")
+	for idx := range linkedSources {
+		if source, ok := sourceByID[linkedSources[idx].SourceID]; ok {
+			linkedSources[idx].SourceName = source.Name
+		}
 	}
 
-	fullTracker, err := h.trackerRepo.GetByID(activeProfile.ID, id)
-	if err != nil || fullTracker == nil {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+	tracker.ID = trackerID
+	tracker.Tags = selectedTags
+
+	continuation, continuationSuggestion, err := h.continuationFormOptions(c.UserContext(), pageCtx.profile.ID, tracker)
+	if err != nil {
+		return serverError(c, "Failed to load continuation", err)
+	}
+
+	return h.render(c, "tracker_form_modal.html", trackerFormData{
+		Mode:                   "edit",
+		ViewMode:               viewMode,
+		Tracker:                tracker,
+		Sources:                sources,
+		LinkedSources:          linkedSources,
+		ProfileTags:            profileTags,
+		TrackerTags:            selectedTags,
+		TagIconKeys:            tagIconKeysOrdered,
+		LanguageSourceIDs:      h.languageSourceIDs(sources),
+		BlacklistedSourceIDs:   blacklistedSourceIDs,
+		ManualSource:           h.manualSourceSelected(sources, tracker),
+		ConfirmPrimarySwitch:   true,
+		AutofocusID:            "tracker-save-button",
+		Continuation:           continuation,
+		ContinuationSuggestion: continuationSuggestion,
+		PrimarySwitchSummary: describePrimarySwitch(
+			sourceByID[tracker.SourceID].Name,
+			sourceByID[proposed.SourceID].Name,
+			tracker.LatestKnownChapter,
+			proposedChapter,
+		),
+	})
+}
+
+// DismissLinkedSourceMismatch clears the different-series warning on one of a
+// tracker's linked sources.
+func (h *DashboardHandler) DismissLinkedSourceMismatch(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+	defer h.editForms.forget(activeProfile.ID, id)
+	trackerSourceID, err := strconv.ParseInt(c.Params("sourceID"), 10, 64)
+	if err != nil || trackerSourceID <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid linked source id")
+	}
+
+	dismissed, err := h.trackerRepo.DismissTrackerSourceMismatch(c.UserContext(), activeProfile.ID, id, trackerSourceID)
+	if err != nil {
+		return serverError(c, "Failed to dismiss linked source warning", err)
+	}
+	if !dismissed {
+		return c.Status(fiber.StatusNotFound).SendString("Linked source not found")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ReorderLinkedSourcesFromForm saves the order of the tracker's linked
+// sources from the tracker_source_id values posted, which must name every
+// one of its tracker_sources rows exactly once.
+func (h *DashboardHandler) ReorderLinkedSourcesFromForm(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+	defer h.editForms.forget(activeProfile.ID, id)
+
+	rawValues := c.Context().PostArgs().PeekMulti("tracker_source_id")
+	order := make([]int64, 0, len(rawValues))
+	for _, raw := range rawValues {
+		trackerSourceID, err := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 64)
+		if err != nil || trackerSourceID <= 0 {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid linked source id")
+		}
+		order = append(order, trackerSourceID)
+	}
+
+	existing, err := h.trackerRepo.ListTrackerSources(c.UserContext(), activeProfile.ID, id)
+	if err != nil {
+		return serverError(c, "Failed to load linked sources", err)
+	}
+	if len(existing) == 0 {
+		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
+	}
+
+	reordered, err := h.trackerRepo.ReorderTrackerSources(c.UserContext(), activeProfile.ID, id, order)
+	if err != nil {
+		return serverError(c, "Failed to reorder linked sources", err)
+	}
+	if !reordered {
+		return c.Status(fiber.StatusBadRequest).SendString("The linked sources changed; reopen the tracker and try again")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SetPrimarySourceFromForm makes one of the tracker's linked sources its
+// primary and refreshes the chapter data from that source alone; the other
+// linked sources are neither changed nor resolved.
+func (h *DashboardHandler) SetPrimarySourceFromForm(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	viewMode := normalizeViewMode(c.FormValue("view_mode", c.Query("view", "grid")))
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
+	}
+	defer h.editForms.forget(activeProfile.ID, id)
+	trackerSourceID, err := strconv.ParseInt(strings.TrimSpace(c.FormValue("tracker_source_id")), 10, 64)
+	if err != nil || trackerSourceID <= 0 {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid linked source id")
+	}
+
+	placement := h.newCardPlacement(c, activeProfile.ID, viewMode, id)
+
+	switched, err := h.trackerRepo.SetPrimarySource(c.UserContext(), activeProfile.ID, id, trackerSourceID)
+	if err != nil {
+		return serverError(c, "Failed to set primary source", err)
+	}
+	if !switched {
+		return c.Status(fiber.StatusNotFound).SendString("Linked source not found")
+	}
+
+	if err := h.refreshFromPrimarySource(c.UserContext(), activeProfile.ID, id); err != nil {
+		requestLogger(c).Info("primary source refresh failed", "tracker_id", id, "error", err)
+	}
+
+	updatedTracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
+	if err != nil || updatedTracker == nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	sourceByID, err := h.listSourcesByID()
+	sourceByID, err := pageCtx.SourceByID()
 	if err != nil {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	sourceLogoBySourceID, err := h.sourceRepo.ListProfileSourceLogoURLs(activeProfile.ID)
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
 	if err != nil {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	cards, _ := h.buildTrackerCards([]models.Tracker{*fullTracker}, sourceByID, sourceLogoBySourceID, "")
+	cards, _ := h.buildTrackerCards(c.UserContext(), []models.Tracker{*updatedTracker}, sourceByID, sourceLogoBySourceID, "")
 	if len(cards) == 0 {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	return h.render(c, "tracker_oob_response.html", trackerOOBResponseData{
+	response := trackerOOBResponseData{
 		ViewMode:    viewMode,
 		ReplaceCard: &cards[0],
-	})
+	}
+	h.placeUpdatedCard(c, placement, &response)
+	response.announce(mutationPrimarySwitched, "", &cards[0])
+	return h.render(c, "tracker_oob_response.html", response)
+}
+
+// refreshFromPrimarySource resolves the tracker's primary source once and
+// stores the chapter and release it reports. Failures leave the tracker as
+// it is; the next poll tries again.
+func (h *DashboardHandler) refreshFromPrimarySource(parent context.Context, profileID int64, trackerID int64) error {
+	tracker, err := h.trackerRepo.GetByID(parent, profileID, trackerID)
+	if err != nil || tracker == nil {
+		return err
+	}
+	lang, err := h.primarySourceLang(parent, profileID, tracker)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := h.resolveLinkedSource(parent, tracker.SourceID, tracker.SourceURL, lang)
+	if err != nil {
+		return err
+	}
+	if resolved == nil {
+		return fmt.Errorf("empty result")
+	}
+
+	if tracker.SourceItemID == nil {
+		if resolvedItemID := strings.TrimSpace(resolved.SourceItemID); resolvedItemID != "" {
+			tracker.SourceItemID = &resolvedItemID
+		}
+	}
+	if resolved.LatestChapter != nil {
+		tracker.LatestKnownChapter = resolved.LatestChapter
+	}
+	if resolved.LastUpdatedAt != nil {
+		releasedAt := resolved.LastUpdatedAt.UTC()
+		tracker.LatestReleaseAt = &releasedAt
+	}
+	if len(resolved.Genres) > 0 {
+		tracker.SourceGenres = resolved.Genres
+	}
+
+	if _, err := h.trackerRepo.UpdateResolvedSource(parent, profileID, trackerID, tracker.SourceURL, tracker, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	// The result carries the cover too; caching it keeps the returned card
+	// from resolving the source a second time in the background.
+	if covers := h.coverService(); covers != nil {
+		if source, err := h.sourceRepo.GetByID(parent, tracker.SourceID); err == nil && source != nil {
+			covers.SetResolved(source.Key, tracker.SourceURL, tracker.SourceItemID, resolved.CoverImageURL)
+		}
+	}
+	return nil
 }
 
 func (h *DashboardHandler) DeleteFromForm(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
 	if err != nil || id <= 0 {
 		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
 	}
+	defer h.editForms.forgetProfile(activeProfile.ID)
+
+	// The title is read first so the deletion can be announced by name.
+	tracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
+	if err != nil {
+		return serverError(c, "Failed to load tracker", err)
+	}
+	if tracker == nil {
+		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
+	}
 
-	deleted, err := h.trackerRepo.Delete(activeProfile.ID, id)
+	deleted, err := h.trackerRepo.Delete(c.UserContext(), activeProfile.ID, id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to delete tracker")
+		return serverError(c, "Failed to delete tracker", err)
 	}
 	if !deleted {
 		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
 	}
 
-	return h.render(c, "tracker_oob_response.html", trackerOOBResponseData{DeleteTrackerID: id})
+	setHXTrigger(c, map[string]any{"recentAdditionsChanged": true})
+	response := trackerOOBResponseData{DeleteTrackerID: id}
+	response.announce(mutationDeleted, tracker.Title, nil)
+	return h.render(c, "tracker_oob_response.html", response)
 }
 
 func (h *DashboardHandler) SetLastReadFromCard(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	viewMode := normalizeViewMode(c.FormValue("view_mode", c.Query("view", "grid")))
 
@@ -455,57 +1069,99 @@ func (h *DashboardHandler) SetLastReadFromCard(c *fiber.Ctx) error {
 	if err != nil || id <= 0 {
 		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
 	}
+	defer h.editForms.forget(activeProfile.ID, id)
 
-	tracker, err := h.trackerRepo.GetByID(activeProfile.ID, id)
+	tracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load tracker")
+		return serverError(c, "Failed to load tracker", err)
 	}
 	if tracker == nil {
 		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
 	}
 
-	if tracker.LatestKnownChapter != nil {
-		_, err := h.trackerRepo.UpdateLastReadChapter(activeProfile.ID, id, tracker.LatestKnownChapter)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Failed to update tracker")
+	// source_id is the source whose link the card rendered, so the read can
+	// be counted against the site it happened on.
+	readSourceID, ok := parseReadSourceID(c.FormValue("source_id"))
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid source")
+	}
+
+	// The card button marks the latest known chapter as read; the chapter
+	// browser posts an explicit chapter instead, numbered as on the source
+	// it was read on.
+	lastRead := tracker.LatestKnownChapter
+	if raw := strings.TrimSpace(c.FormValue("chapter")); raw != "" {
+		chapter, err := parseOptionalFloat(raw)
+		if err != nil || chapter == nil || *chapter < 0 {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid chapter")
+		}
+		if lastRead, err = chapterInPrimaryNumbering(c.UserContext(), h.trackerRepo, tracker, readSourceID, chapter); err != nil {
+			return serverError(c, "Failed to load linked sources", err)
 		}
 	}
 
-	updatedTracker, err := h.trackerRepo.GetByID(activeProfile.ID, id)
-	if err != nil || updatedTracker == nil {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+	placement := h.newCardPlacement(c, activeProfile.ID, viewMode, id)
+
+	updatedTracker, err := h.writeAndReload(c, activeProfile.ID, id, func(txRepo *repository.TrackerRepository) error {
+		if lastRead == nil {
+			return nil
+		}
+		changed, err := txRepo.UpdateLastReadChapter(c.UserContext(), activeProfile.ID, id, lastRead)
+		if err != nil {
+			return err
+		}
+		if changed {
+			recordReadSource(c.UserContext(), txRepo, tracker, readSourceID, tracker.LastReadChapter, lastRead)
+		}
+		return nil
+	})
+	if err != nil {
+		return serverError(c, "Failed to update tracker", err)
+	}
+	if updatedTracker == nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	sourceByID, err := h.listSourcesByID()
+	sourceByID, err := pageCtx.SourceByID()
 	if err != nil {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	sourceLogoBySourceID, err := h.sourceRepo.ListProfileSourceLogoURLs(activeProfile.ID)
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
 	if err != nil {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	cards, _ := h.buildTrackerCards([]models.Tracker{*updatedTracker}, sourceByID, sourceLogoBySourceID, "")
+	cards, _ := h.buildTrackerCards(c.UserContext(), []models.Tracker{*updatedTracker}, sourceByID, sourceLogoBySourceID, "")
 	if len(cards) == 0 {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	return h.render(c, "tracker_oob_response.html", trackerOOBResponseData{
+	response := trackerOOBResponseData{
 		ViewMode:    viewMode,
 		ReplaceCard: &cards[0],
-	})
+	}
+	h.placeUpdatedCard(c, placement, &response)
+	if lastRead != nil {
+		response.announce(mutationMarkedRead, "", &cards[0])
+	} else {
+		// Without a chapter to mark nothing changed; the card's own last
+		// read chapter would read as if it had.
+		response.announce(mutationMarkedRead, cards[0].Title, nil)
+	}
+	return h.render(c, "tracker_oob_response.html", response)
 }
 
 func (h *DashboardHandler) SetRatingFromCard(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	viewMode := normalizeViewMode(c.FormValue("view_mode", c.Query("view", "grid")))
 
@@ -513,10 +1169,11 @@ func (h *DashboardHandler) SetRatingFromCard(c *fiber.Ctx) error {
 	if err != nil || id <= 0 {
 		return c.Status(fiber.StatusBadRequest).SendString("Invalid tracker id")
 	}
+	defer h.editForms.forget(activeProfile.ID, id)
 
-	tracker, err := h.trackerRepo.GetByID(activeProfile.ID, id)
+	tracker, err := h.trackerRepo.GetByID(c.UserContext(), activeProfile.ID, id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load tracker")
+		return serverError(c, "Failed to load tracker", err)
 	}
 	if tracker == nil {
 		return c.Status(fiber.StatusNotFound).SendString("Tracker not found")
@@ -540,52 +1197,170 @@ func (h *DashboardHandler) SetRatingFromCard(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
 	}
 
-	if _, err := h.trackerRepo.UpdateRating(activeProfile.ID, id, rating); err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to update rating")
-	}
+	placement := h.newCardPlacement(c, activeProfile.ID, viewMode, id)
 
-	updatedTracker, err := h.trackerRepo.GetByID(activeProfile.ID, id)
-	if err != nil || updatedTracker == nil {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+	updatedTracker, err := h.writeAndReload(c, activeProfile.ID, id, func(txRepo *repository.TrackerRepository) error {
+		_, err := txRepo.UpdateRating(c.UserContext(), activeProfile.ID, id, rating)
+		return err
+	})
+	if err != nil {
+		return serverError(c, "Failed to update rating", err)
+	}
+	if updatedTracker == nil {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	sourceByID, err := h.listSourcesByID()
+	sourceByID, err := pageCtx.SourceByID()
 	if err != nil {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	sourceLogoBySourceID, err := h.sourceRepo.ListProfileSourceLogoURLs(activeProfile.ID)
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
 	if err != nil {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	cards, _ := h.buildTrackerCards([]models.Tracker{*updatedTracker}, sourceByID, sourceLogoBySourceID, "")
+	cards, _ := h.buildTrackerCards(c.UserContext(), []models.Tracker{*updatedTracker}, sourceByID, sourceLogoBySourceID, "")
 	if len(cards) == 0 {
-		c.Set("HX-Trigger", `{"trackersChanged":true}`)
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
 		return h.render(c, "empty_modal.html", nil)
 	}
 
-	return h.render(c, "tracker_oob_response.html", trackerOOBResponseData{
+	response := trackerOOBResponseData{
 		ViewMode:    viewMode,
 		ReplaceCard: &cards[0],
-	})
+	}
+	h.placeUpdatedCard(c, placement, &response)
+	if rating != nil {
+		response.announce(mutationRated, "", &cards[0])
+	} else {
+		response.announce(mutationRatingCleared, "", &cards[0])
+	}
+	return h.render(c, "tracker_oob_response.html", response)
 }
 
-func (h *DashboardHandler) listSourcesByID() (map[int64]models.Source, error) {
-	sources, err := h.sourceRepo.ListEnabled()
+// writeAndReload runs a card action's write and reloads the tracker for its
+// card in one transaction. A poll writing the same tracker meanwhile lands
+// wholly before or after it, so the card shows the action together with
+// the latest chapter rather than one of them stale. A failed reload is only
+// logged and returns a nil tracker: the write itself stands.
+func (h *DashboardHandler) writeAndReload(c *fiber.Ctx, profileID int64, trackerID int64, write func(txRepo *repository.TrackerRepository) error) (*models.Tracker, error) {
+	var reloaded *models.Tracker
+	err := h.trackerRepo.WithTx(c.UserContext(), func(txRepo *repository.TrackerRepository) error {
+		if err := write(txRepo); err != nil {
+			return err
+		}
+		var err error
+		if reloaded, err = txRepo.GetByID(c.UserContext(), profileID, trackerID); err != nil {
+			requestLogger(c).Warn("reload tracker after card action failed", "tracker_id", trackerID, "error", err)
+			reloaded = nil
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return reloaded, nil
+}
 
-	sourceByID := make(map[int64]models.Source, len(sources))
-	for _, source := range sources {
-		sourceByID[source.ID] = source
+// cardPlacement is where an updated card sat on the dashboard page it was
+// changed from, read from the filter params the card actions post.
+type cardPlacement struct {
+	trackerID int64
+	options   repository.TrackerListOptions
+	index     int
+	known     bool
+}
+
+// newCardPlacement records the card's position on the posted page before it
+// is changed. Requests without the filter params, or whose page cannot be
+// listed, get a placement that leaves the plain card replacement alone.
+func (h *DashboardHandler) newCardPlacement(c *fiber.Ctx, profileID int64, viewMode string, trackerID int64) cardPlacement {
+	placement := cardPlacement{trackerID: trackerID, index: -1}
+	args := c.Request().PostArgs()
+	if len(args.Peek("sort")) == 0 {
+		return placement
+	}
+
+	options := trackerListOptionsFromArgs(args, profileID)
+	if _, err := dropUnknownTagFilters(c.UserContext(), h.trackerRepo, &options); err != nil {
+		slog.Warn("load tag filters for card placement failed", "tracker_id", trackerID, "error", err)
+		return placement
+	}
+	pageSize := trackersPageSize(viewMode, string(args.Peek("page_size")))
+	page, err := clampDeepPage(c.UserContext(), h.trackerRepo, options, parsePositiveInt(string(args.Peek("page")), 1), pageSize)
+	if err != nil {
+		slog.Warn("count page for card placement failed", "tracker_id", trackerID, "error", err)
+		return placement
 	}
+	options.Limit = pageSize
+	options.Offset = (page - 1) * pageSize
 
-	return sourceByID, nil
+	index, err := h.trackerPageIndex(c.UserContext(), options, trackerID)
+	if err != nil {
+		slog.Warn("list page for card placement failed", "tracker_id", trackerID, "error", err)
+		return placement
+	}
+	placement.options = options
+	placement.index = index
+	placement.known = true
+	return placement
+}
+
+// placeUpdatedCard turns the card replacement into a removal when the
+// tracker no longer belongs on the page, and sends a trackerMoved trigger
+// when its position changed so the client can refresh the page without
+// losing its scroll position.
+func (h *DashboardHandler) placeUpdatedCard(c *fiber.Ctx, placement cardPlacement, response *trackerOOBResponseData) {
+	if !placement.known {
+		return
+	}
+
+	index, err := h.trackerPageIndex(c.UserContext(), placement.options, placement.trackerID)
+	if err != nil {
+		slog.Warn("list page for card placement failed", "tracker_id", placement.trackerID, "error", err)
+		return
+	}
+	if index == placement.index {
+		return
+	}
+	if index >= 0 {
+		setHXTrigger(c, map[string]any{"trackerMoved": true})
+		return
+	}
+
+	response.ReplaceCard = nil
+	response.DeleteTrackerID = placement.trackerID
+
+	matchOptions := placement.options
+	matchOptions.IDs = []int64{placement.trackerID}
+	matches, err := h.trackerRepo.Count(c.UserContext(), matchOptions)
+	if err != nil {
+		slog.Warn("check card filter match failed", "tracker_id", placement.trackerID, "error", err)
+		return
+	}
+	if matches > 0 {
+		// Still listed, just on another page now.
+		setHXTrigger(c, map[string]any{"trackerMoved": true})
+	}
+}
+
+// trackerPageIndex returns the tracker's position on the page options
+// selects, or -1 when it is not on it.
+func (h *DashboardHandler) trackerPageIndex(ctx context.Context, options repository.TrackerListOptions, trackerID int64) (int, error) {
+	items, _, err := h.trackerRepo.ListWithTotal(ctx, options)
+	if err != nil {
+		return -1, err
+	}
+	for index, item := range items {
+		if item.ID == trackerID {
+			return index, nil
+		}
+	}
+	return -1, nil
 }
 
 func parseTrackerFromForm(c *fiber.Ctx) (*models.Tracker, error) {
@@ -632,6 +1407,15 @@ func parseTrackerFromForm(c *fiber.Ctx) (*models.Tracker, error) {
 		return nil, fmt.Errorf("Invalid related titles")
 	}
 
+	var continuedBy *int64
+	if raw := strings.TrimSpace(c.FormValue("continued_by_tracker_id")); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("Invalid continuation")
+		}
+		continuedBy = &id
+	}
+
 	return &models.Tracker{
 		Title:              title,
 		RelatedTitles:      relatedTitles,
@@ -642,15 +1426,19 @@ func parseTrackerFromForm(c *fiber.Ctx) (*models.Tracker, error) {
 		LastReadChapter:    lastRead,
 		LatestKnownChapter: latestKnown,
 		LatestReleaseAt:    latestReleaseAt,
+
+		ContinuedByTrackerID: continuedBy,
 	}, nil
 }
 
+// parseOptionalFloat parses an optional chapter number field; see
+// connectors.ParseChapterNumber for the accepted formats.
 func parseOptionalFloat(raw string) (*float64, error) {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
 		return nil, nil
 	}
-	value, err := strconv.ParseFloat(trimmed, 64)
+	value, err := connectors.ParseChapterNumber(trimmed)
 	if err != nil {
 		return nil, err
 	}
@@ -681,7 +1469,17 @@ func parseRelatedTitlesFromForm(raw string) ([]string, error) {
 		return nil, err
 	}
 
-	return searchutil.FilterEnglishAlphabetNames(values), nil
+	return limitRelatedTitles(values, "origin", "edit form"), nil
+}
+
+// limitRelatedTitles applies searchutil.LimitRelatedTitles and logs what the
+// limits dropped; attrs say where the titles came from.
+func limitRelatedTitles(values []string, attrs ...any) []string {
+	titles, dropped := searchutil.LimitRelatedTitles(values)
+	if dropped > 0 {
+		slog.Info("related titles trimmed", append([]any{"kept", len(titles), "dropped", dropped}, attrs...)...)
+	}
+	return titles
 }
 
 func parseTagIDsFromForm(c *fiber.Ctx) ([]int64, error) {
@@ -711,6 +1509,10 @@ func parseTagIDsFromForm(c *fiber.Ctx) ([]int64, error) {
 	return ids, nil
 }
 
+// maxLinkedSourceChapterOffset bounds a linked source's chapter offset; a
+// larger one is a typo rather than a numbering difference.
+const maxLinkedSourceChapterOffset = 1000
+
 func parseLinkedSourcesFromForm(c *fiber.Ctx) ([]models.TrackerSource, error) {
 	raw := strings.TrimSpace(c.FormValue("linked_sources_json"))
 	if raw == "" {
@@ -718,9 +1520,11 @@ func parseLinkedSourcesFromForm(c *fiber.Ctx) ([]models.TrackerSource, error) {
 	}
 
 	type linkedSourcePayload struct {
-		SourceID     int64   `json:"sourceId"`
-		SourceItemID *string `json:"sourceItemId"`
-		SourceURL    string  `json:"sourceUrl"`
+		SourceID      int64   `json:"sourceId"`
+		SourceItemID  *string `json:"sourceItemId"`
+		SourceURL     string  `json:"sourceUrl"`
+		Lang          string  `json:"lang"`
+		ChapterOffset float64 `json:"chapterOffset"`
 	}
 
 	var payload []linkedSourcePayload
@@ -737,10 +1541,19 @@ func parseLinkedSourcesFromForm(c *fiber.Ctx) ([]models.TrackerSource, error) {
 		if item.SourceItemID != nil && strings.TrimSpace(*item.SourceItemID) == "" {
 			item.SourceItemID = nil
 		}
+		lang, err := connectors.NormalizeLanguage(item.Lang)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid linked source language")
+		}
+		if math.Abs(item.ChapterOffset) > maxLinkedSourceChapterOffset {
+			return nil, fmt.Errorf("Invalid linked source chapter offset")
+		}
 		items = append(items, models.TrackerSource{
-			SourceID:     item.SourceID,
-			SourceItemID: item.SourceItemID,
-			SourceURL:    sourceURL,
+			SourceID:      item.SourceID,
+			SourceItemID:  item.SourceItemID,
+			SourceURL:     sourceURL,
+			Lang:          lang,
+			ChapterOffset: item.ChapterOffset,
 		})
 	}
 
@@ -776,11 +1589,17 @@ func sameTrackerSources(existing []models.TrackerSource, incoming []models.Track
 		if item.SourceItemID != nil {
 			sourceItemID = strings.ToLower(strings.TrimSpace(*item.SourceItemID))
 		}
+		lang, err := connectors.NormalizeLanguage(item.Lang)
+		if err != nil {
+			lang = item.Lang
+		}
 		return fmt.Sprintf(
-			"%d|%s|%s",
+			"%d|%s|%s|%s|%g",
 			item.SourceID,
 			strings.ToLower(strings.TrimSpace(item.SourceURL)),
 			sourceItemID,
+			lang,
+			item.ChapterOffset,
 		)
 	}
 
@@ -807,6 +1626,99 @@ func sameTrackerSources(existing []models.TrackerSource, incoming []models.Track
 	return true
 }
 
+func containsTrackerSource(items []models.TrackerSource, target models.TrackerSource) bool {
+	for _, item := range items {
+		if item.SourceID == target.SourceID && strings.EqualFold(strings.TrimSpace(item.SourceURL), strings.TrimSpace(target.SourceURL)) {
+			return true
+		}
+	}
+	return false
+}
+
+// trackerSourceChapterOffset returns the chapter offset of target among
+// items, or 0 when it is not one of them.
+func trackerSourceChapterOffset(items []models.TrackerSource, target models.TrackerSource) float64 {
+	for _, item := range items {
+		if item.SourceID == target.SourceID && strings.EqualFold(strings.TrimSpace(item.SourceURL), strings.TrimSpace(target.SourceURL)) {
+			return item.ChapterOffset
+		}
+	}
+	return 0
+}
+
+// offsetChapter moves chapter by delta, never below chapter 0.
+func offsetChapter(chapter *float64, delta float64) *float64 {
+	if chapter == nil || delta == 0 {
+		return chapter
+	}
+	shifted := math.Max(0, *chapter+delta)
+	return &shifted
+}
+
+// linkedSourceMatchThreshold is the lowest title similarity at which a newly
+// linked source is taken to be the same series as its tracker.
+const linkedSourceMatchThreshold = 0.5
+
+// flagMismatchedLinkedSources resolves the sources that were just linked to a
+// tracker and flags the ones whose titles do not resemble the tracker's. The
+// save itself is never blocked; a source that cannot be resolved is left
+// unflagged.
+func (h *DashboardHandler) flagMismatchedLinkedSources(parent context.Context, profileID int64, tracker *models.Tracker, existing []models.TrackerSource, sources []models.TrackerSource) {
+	if tracker == nil || len(sources) < 2 {
+		return
+	}
+
+	trackerTitles := append([]string{tracker.Title}, tracker.RelatedTitles...)
+	for _, source := range sources {
+		if containsTrackerSource(existing, source) {
+			continue
+		}
+		resolved, err := h.resolveLinkedSource(parent, source.SourceID, source.SourceURL, source.Lang)
+		if err != nil || resolved == nil {
+			continue
+		}
+		sourceTitles := append([]string{resolved.Title}, resolved.RelatedTitles...)
+		if searchutil.BestTitleSimilarity(trackerTitles, sourceTitles) >= linkedSourceMatchThreshold {
+			continue
+		}
+		if err := h.trackerRepo.FlagTrackerSourceMismatch(parent, profileID, tracker.ID, source.SourceID, source.SourceURL); err != nil {
+			slog.Warn("flag linked source mismatch failed", "tracker_id", tracker.ID, "source_id", source.SourceID, "error", err)
+		}
+	}
+}
+
+func primarySourceChanged(chosen models.TrackerSource, computed models.TrackerSource) bool {
+	if computed.SourceID <= 0 {
+		return false
+	}
+	if chosen.SourceID != computed.SourceID {
+		return true
+	}
+	return !strings.EqualFold(strings.TrimSpace(chosen.SourceURL), strings.TrimSpace(computed.SourceURL))
+}
+
+func describePrimarySwitch(fromName string, toName string, fromChapter *float64, toChapter *float64) string {
+	if strings.TrimSpace(fromName) == "" {
+		fromName = "the selected source"
+	}
+	if strings.TrimSpace(toName) == "" {
+		toName = "another linked source"
+	}
+
+	summary := fmt.Sprintf("Primary will switch from %s to %s", fromName, toName)
+	switch {
+	case toChapter != nil && fromChapter != nil:
+		return fmt.Sprintf("%s because it reports %s vs %s", summary, formatChapterLabel(*toChapter), strconv.FormatFloat(*fromChapter, 'f', -1, 64))
+	case toChapter != nil:
+		return fmt.Sprintf("%s because it reports %s", summary, formatChapterLabel(*toChapter))
+	default:
+		return summary + " because it is the first linked source"
+	}
+}
+
+// selectPrimaryTrackerSource picks the source reporting the highest chapter,
+// then the most recent release. Sources come in their linked order, so among
+// sources still tied, or when none can be resolved, the earliest wins.
 func (h *DashboardHandler) selectPrimaryTrackerSource(parent context.Context, sources []models.TrackerSource) (models.TrackerSource, *float64, *time.Time, []string) {
 	if len(sources) == 0 {
 		return models.TrackerSource{}, nil, nil, nil
@@ -814,12 +1726,13 @@ func (h *DashboardHandler) selectPrimaryTrackerSource(parent context.Context, so
 
 	bestIndex := 0
 	var bestChapter *float64
+	var bestOffsetChapter float64
 	var bestReleaseAt *time.Time
 	var bestRelatedTitles []string
 
 	for idx := range sources {
 		source := &sources[idx]
-		resolved, err := h.resolveLinkedSource(parent, source.SourceID, source.SourceURL)
+		resolved, err := h.resolveLinkedSource(parent, source.SourceID, source.SourceURL, source.Lang)
 		if err != nil || resolved == nil {
 			continue
 		}
@@ -831,7 +1744,7 @@ func (h *DashboardHandler) selectPrimaryTrackerSource(parent context.Context, so
 		if resolvedURL := strings.TrimSpace(resolved.URL); resolvedURL != "" {
 			source.SourceURL = resolvedURL
 		}
-		resolvedRelatedTitles := searchutil.FilterEnglishAlphabetNames(resolved.RelatedTitles)
+		resolvedRelatedTitles := limitRelatedTitles(resolved.RelatedTitles, "source_id", source.SourceID, "source_url", source.SourceURL)
 		if idx == bestIndex && len(bestRelatedTitles) == 0 && len(resolvedRelatedTitles) > 0 {
 			bestRelatedTitles = resolvedRelatedTitles
 		}
@@ -840,10 +1753,14 @@ func (h *DashboardHandler) selectPrimaryTrackerSource(parent context.Context, so
 			continue
 		}
 
+		// Sources are ranked by their chapters in a shared numbering, but the
+		// primary's latest chapter is kept as that source reports it.
 		resolvedChapter := *resolved.LatestChapter
-		if bestChapter == nil || resolvedChapter > *bestChapter {
+		offsetChapter := resolvedChapter + source.ChapterOffset
+		if bestChapter == nil || offsetChapter > bestOffsetChapter {
 			bestIndex = idx
 			bestChapter = &resolvedChapter
+			bestOffsetChapter = offsetChapter
 			bestRelatedTitles = resolvedRelatedTitles
 			if resolved.LastUpdatedAt != nil {
 				resolvedReleaseAt := resolved.LastUpdatedAt.UTC()
@@ -854,7 +1771,7 @@ func (h *DashboardHandler) selectPrimaryTrackerSource(parent context.Context, so
 			continue
 		}
 
-		if resolvedChapter == *bestChapter && resolved.LastUpdatedAt != nil {
+		if offsetChapter == bestOffsetChapter && resolved.LastUpdatedAt != nil {
 			if bestReleaseAt == nil || resolved.LastUpdatedAt.After(*bestReleaseAt) {
 				bestIndex = idx
 				bestRelatedTitles = resolvedRelatedTitles
@@ -867,12 +1784,27 @@ func (h *DashboardHandler) selectPrimaryTrackerSource(parent context.Context, so
 	return sources[bestIndex], bestChapter, bestReleaseAt, bestRelatedTitles
 }
 
-func (h *DashboardHandler) resolveLinkedSource(parent context.Context, sourceID int64, sourceURL string) (*connectors.MangaResult, error) {
+func (h *DashboardHandler) canonicalSourceURL(ctx context.Context, sourceID int64, sourceURL string) (string, error) {
+	return canonicalSourceURL(ctx, h.registry, h.sourceRepo, sourceID, sourceURL)
+}
+
+func sourceURLErrorText(c *fiber.Ctx, err error) error {
+	var urlErr *sourceURLError
+	if !errors.As(err, &urlErr) {
+		return serverError(c, "Failed to validate source URL", err)
+	}
+	return c.Status(fiber.StatusBadRequest).SendString(urlErr.Error())
+}
+
+func (h *DashboardHandler) resolveLinkedSource(parent context.Context, sourceID int64, sourceURL string, lang string) (*connectors.MangaResult, error) {
 	if sourceID <= 0 || strings.TrimSpace(sourceURL) == "" {
 		return nil, fmt.Errorf("source is incomplete")
 	}
+	if err := h.scrapingAllowed(); err != nil {
+		return nil, err
+	}
 
-	source, err := h.sourceRepo.GetByID(sourceID)
+	source, err := h.sourceRepo.GetByID(parent, sourceID)
 	if err != nil {
 		return nil, err
 	}
@@ -888,10 +1820,37 @@ func (h *DashboardHandler) resolveLinkedSource(parent context.Context, sourceID
 	ctx, cancel := context.WithTimeout(parent, 8*time.Second)
 	defer cancel()
 
-	resolved, err := connector.ResolveByURL(ctx, strings.TrimSpace(sourceURL))
+	resolved, err := connectors.ResolveByURLWithLang(ctx, connector, strings.TrimSpace(sourceURL), lang)
 	if err != nil {
 		return nil, err
 	}
 
 	return resolved, nil
 }
+
+// languageSourceIDs lists the sources whose connector resolves in a chosen
+// language.
+func (h *DashboardHandler) languageSourceIDs(sources []models.Source) []int64 {
+	ids := make([]int64, 0)
+	for _, source := range sources {
+		if h.registry.LanguageAware(source.Key) {
+			ids = append(ids, source.ID)
+		}
+	}
+	return ids
+}
+
+// primarySourceLang returns the language of the tracker's link to its
+// primary source.
+func (h *DashboardHandler) primarySourceLang(ctx context.Context, profileID int64, tracker *models.Tracker) (string, error) {
+	sources, err := h.trackerRepo.ListTrackerSources(ctx, profileID, tracker.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, source := range sources {
+		if source.SourceID == tracker.SourceID && strings.EqualFold(strings.TrimSpace(source.SourceURL), strings.TrimSpace(tracker.SourceURL)) {
+			return source.Lang, nil
+		}
+	}
+	return connectors.DefaultLanguage, nil
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gofiber/fiber/v2"
+)
+
+// resolveCountingConnectorStub counts the live lookups a form save makes.
+type resolveCountingConnectorStub struct {
+	key      string
+	resolved *atomic.Int32
+}
+
+func (s resolveCountingConnectorStub) Key() string {
+	return s.key
+}
+
+func (s resolveCountingConnectorStub) Name() string {
+	return s.key
+}
+
+func (resolveCountingConnectorStub) Kind() string {
+	return connectors.KindNative
+}
+
+func (resolveCountingConnectorStub) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (s resolveCountingConnectorStub) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	s.resolved.Add(1)
+	chapter := 50.0
+	return &connectors.MangaResult{
+		SourceKey:     s.key,
+		SourceItemID:  s.key + "-item",
+		Title:         "Counted Series",
+		URL:           rawURL,
+		LatestChapter: &chapter,
+	}, nil
+}
+
+func (resolveCountingConnectorStub) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+
+func setupResolveCountingHandler(t *testing.T) (*sql.DB, *DashboardHandler, *atomic.Int32) {
+	t.Helper()
+
+	resolved := &atomic.Int32{}
+	registry := connectors.NewRegistry()
+	for _, key := range []string{"mangadex", "mangafire"} {
+		if err := registry.Register(resolveCountingConnectorStub{key: key, resolved: resolved}); err != nil {
+			t.Fatalf("register %s stub: %v", key, err)
+		}
+	}
+	db, h := setupInternalDashboardHandler(t, registry)
+	// Cards queue their covers, which are then looked up in the background
+	// through the same stubs and would land in the counts at random. Without
+	// a cover queue the handler builds no cover service.
+	h.coverFetchQueue = nil
+	return db, h, resolved
+}
+
+func postTrackerForm(t *testing.T, app *fiber.App, target string, form url.Values) int {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("post %s: %v", target, err)
+	}
+	return resp.StatusCode
+}
+
+func TestUpdateFromFormOnlyResolvesChangedLinkedSources(t *testing.T) {
+	db, h, resolved := setupResolveCountingHandler(t)
+
+	var mangaDexID, mangaFireID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&mangaDexID); err != nil {
+		t.Fatalf("load mangadex source: %v", err)
+	}
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangafire'`).Scan(&mangaFireID); err != nil {
+		t.Fatalf("load mangafire source: %v", err)
+	}
+	mangaDexURL := "https://mangadex.org/title/counted-series"
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter, rating)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, 1, "Counted Series", mangaDexID, mangaDexURL, "reading", 50.0, 7.5)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+
+	app := fiber.New()
+	app.Post("/dashboard/trackers/:id", h.UpdateFromForm)
+	target := "/dashboard/trackers/" + strconv.FormatInt(trackerID, 10)
+	form := func(linkedSourcesJSON string) url.Values {
+		return url.Values{
+			"title":                {"Counted Series Renamed"},
+			"source_id":            {strconv.FormatInt(mangaDexID, 10)},
+			"source_url":           {mangaDexURL},
+			"status":               {"reading"},
+			"latest_known_chapter": {"50"},
+			"linked_sources_json":  {linkedSourcesJSON},
+			"view_mode":            {"list"},
+		}
+	}
+	mangaDexLink := `{"sourceId":` + strconv.FormatInt(mangaDexID, 10) + `,"sourceUrl":"` + mangaDexURL + `"}`
+
+	if status := postTrackerForm(t, app, target, form(`[`+mangaDexLink+`]`)); status != fiber.StatusOK {
+		t.Fatalf("expected 200 from an unchanged edit, got %d", status)
+	}
+	if got := resolved.Load(); got != 0 {
+		t.Fatalf("expected an edit that keeps the linked sources not to look them up, got %d lookups", got)
+	}
+
+	var title string
+	var rating sql.NullFloat64
+	if err := db.QueryRow(`SELECT title, rating FROM trackers WHERE id = ?`, trackerID).Scan(&title, &rating); err != nil {
+		t.Fatalf("load tracker: %v", err)
+	}
+	if title != "Counted Series Renamed" {
+		t.Fatalf("expected the edit to be saved, got title %q", title)
+	}
+	if !rating.Valid || rating.Float64 != 7.5 {
+		t.Fatalf("expected the edit form to keep the rating, got %v", rating)
+	}
+
+	mangaFireLink := `{"sourceId":` + strconv.FormatInt(mangaFireID, 10) + `,"sourceUrl":"https://mangafire.to/manga/counted-series.abc"}`
+	if status := postTrackerForm(t, app, target, form(`[`+mangaDexLink+`,`+mangaFireLink+`]`)); status != fiber.StatusOK {
+		t.Fatalf("expected 200 from an edit linking a source, got %d", status)
+	}
+	if got := resolved.Load(); got == 0 {
+		t.Fatalf("expected linking a new source to look the sources up")
+	}
+}
+
+func TestCreateFromFormSkipsLookupWithResolvedMetadata(t *testing.T) {
+	db, h, resolved := setupResolveCountingHandler(t)
+
+	var mangaDexID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&mangaDexID); err != nil {
+		t.Fatalf("load mangadex source: %v", err)
+	}
+
+	app := fiber.New()
+	app.Post("/dashboard/trackers", h.CreateFromForm)
+	form := func(title string, resolvedMetadata bool) url.Values {
+		values := url.Values{
+			"title":      {title},
+			"source_id":  {strconv.FormatInt(mangaDexID, 10)},
+			"source_url": {"https://mangadex.org/title/" + strings.ToLower(strings.ReplaceAll(title, " ", "-"))},
+			"status":     {"reading"},
+		}
+		if resolvedMetadata {
+			values.Set("source_item_id", "picked-from-search")
+			values.Set("latest_known_chapter", "42")
+			values.Set("latest_release_at", "2026-01-02T03:04:05Z")
+		}
+		return values
+	}
+
+	if status := postTrackerForm(t, app, "/dashboard/trackers", form("Picked Series", true)); status != fiber.StatusOK {
+		t.Fatalf("expected 200 from a create with resolved metadata, got %d", status)
+	}
+	if got := resolved.Load(); got != 0 {
+		t.Fatalf("expected a search pick to be saved without a lookup, got %d lookups", got)
+	}
+
+	if status := postTrackerForm(t, app, "/dashboard/trackers", form("Typed Series", false)); status != fiber.StatusOK {
+		t.Fatalf("expected 200 from a create with a pasted URL, got %d", status)
+	}
+	if got := resolved.Load(); got != 1 {
+		t.Fatalf("expected a pasted URL to be looked up once, got %d lookups", got)
+	}
+}
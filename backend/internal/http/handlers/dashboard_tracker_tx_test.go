@@ -0,0 +1,236 @@
+package handlers_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// failTagWrites makes every tag assignment fail, the last write of the
+// tracker forms, until the returned func drops the trigger.
+func failTagWrites(t *testing.T, db *sql.DB) func() {
+	t.Helper()
+	if _, err := db.Exec(`
+		CREATE TRIGGER fail_tracker_tags BEFORE INSERT ON tracker_tags
+		BEGIN
+			SELECT RAISE(ABORT, 'injected tag failure');
+		END
+	`); err != nil {
+		t.Fatalf("create failing trigger: %v", err)
+	}
+	return func() {
+		if _, err := db.Exec(`DROP TRIGGER fail_tracker_tags`); err != nil {
+			t.Fatalf("drop failing trigger: %v", err)
+		}
+	}
+}
+
+func postTrackerForm(t *testing.T, app *fiber.App, path string, form url.Values) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("tracker form request failed: %v", err)
+	}
+	return res.StatusCode
+}
+
+func TestUpdateFromFormRollsBackWhenTagsFailAfterSources(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	mangaFireID, _ := sourceMetaByKey(t, db, "mangafire")
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter)
+		VALUES (1, 'Atomic Blade', 1, 'https://asuracomic.net/series/atomic-blade', 'reading', 3)
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	if _, err := db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_url)
+		VALUES (?, 1, 'https://asuracomic.net/series/atomic-blade'), (?, ?, 'https://mangafire.to/manga/atomic-blade.abc')
+	`, trackerID, trackerID, mangaFireID); err != nil {
+		t.Fatalf("seed tracker sources: %v", err)
+	}
+	tagResult, err := db.Exec(`INSERT INTO custom_tags (profile_id, name) VALUES (1, 'atomic')`)
+	if err != nil {
+		t.Fatalf("seed tag: %v", err)
+	}
+	tagID, _ := tagResult.LastInsertId()
+
+	form := url.Values{}
+	form.Set("title", "Renamed Blade")
+	form.Set("source_id", "1")
+	form.Set("source_url", "https://asuracomic.net/series/atomic-blade")
+	form.Set("status", "reading")
+	form.Set("last_read_chapter", "7")
+	form.Set("auto_primary", "0")
+	// Unlinking MangaFire is the sources step; it needs no lookup.
+	form.Set("linked_sources_json", `[{"sourceId":1,"sourceUrl":"https://asuracomic.net/series/atomic-blade"}]`)
+	form.Set("tag_ids", strconv.FormatInt(tagID, 10))
+	path := "/dashboard/trackers/" + strconv.FormatInt(trackerID, 10)
+
+	restore := failTagWrites(t, db)
+	if status := postTrackerForm(t, app, path, form); status != http.StatusInternalServerError {
+		t.Fatalf("expected 500 when the tag write fails, got %d", status)
+	}
+	var title string
+	var lastRead float64
+	var sources, tags, reads int
+	count := func() {
+		t.Helper()
+		if err := db.QueryRow(`SELECT title, last_read_chapter FROM trackers WHERE id = ?`, trackerID).Scan(&title, &lastRead); err != nil {
+			t.Fatalf("load tracker: %v", err)
+		}
+		if err := db.QueryRow(`SELECT COUNT(1) FROM tracker_sources WHERE tracker_id = ?`, trackerID).Scan(&sources); err != nil {
+			t.Fatalf("count tracker sources: %v", err)
+		}
+		if err := db.QueryRow(`SELECT COUNT(1) FROM tracker_tags WHERE tracker_id = ?`, trackerID).Scan(&tags); err != nil {
+			t.Fatalf("count tracker tags: %v", err)
+		}
+		if err := db.QueryRow(`SELECT COUNT(1) FROM read_events WHERE tracker_id = ?`, trackerID).Scan(&reads); err != nil {
+			t.Fatalf("count read events: %v", err)
+		}
+	}
+	count()
+	if title != "Atomic Blade" || lastRead != 3 || sources != 2 || tags != 0 || reads != 0 {
+		t.Fatalf("expected the failed update to leave the tracker as it was, got title %q, last read %v, %d sources, %d tags, %d reads", title, lastRead, sources, tags, reads)
+	}
+
+	restore()
+	if status := postTrackerForm(t, app, path, form); status != http.StatusOK {
+		t.Fatalf("expected 200 once tags can be written, got %d", status)
+	}
+	count()
+	if title != "Renamed Blade" || lastRead != 7 || sources != 1 || tags != 1 {
+		t.Fatalf("expected the update to land whole, got title %q, last read %v, %d sources, %d tags", title, lastRead, sources, tags)
+	}
+}
+
+func TestCreateFromFormRollsBackWhenTagsFail(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	mangaDexID, _ := sourceMetaByKey(t, db, "mangadex")
+	tagResult, err := db.Exec(`INSERT INTO custom_tags (profile_id, name) VALUES (1, 'atomic')`)
+	if err != nil {
+		t.Fatalf("seed tag: %v", err)
+	}
+	tagID, _ := tagResult.LastInsertId()
+
+	form := url.Values{}
+	form.Set("title", "Half Made Tracker")
+	form.Set("source_id", strconv.FormatInt(mangaDexID, 10))
+	form.Set("source_url", "https://mangadex.org/title/half-made-tracker")
+	form.Set("status", "reading")
+	form.Set("tag_ids", strconv.FormatInt(tagID, 10))
+
+	restore := failTagWrites(t, db)
+	defer restore()
+	if status := postTrackerForm(t, app, "/dashboard/trackers", form); status != http.StatusInternalServerError {
+		t.Fatalf("expected 500 when the tag write fails, got %d", status)
+	}
+	var trackers, sources int
+	if err := db.QueryRow(`SELECT COUNT(1) FROM trackers WHERE title = 'Half Made Tracker'`).Scan(&trackers); err != nil {
+		t.Fatalf("count trackers: %v", err)
+	}
+	if err := db.QueryRow(`SELECT COUNT(1) FROM tracker_sources WHERE source_url = 'https://mangadex.org/title/half-made-tracker'`).Scan(&sources); err != nil {
+		t.Fatalf("count tracker sources: %v", err)
+	}
+	if trackers != 0 || sources != 0 {
+		t.Fatalf("expected no tracker to be left behind, got %d trackers and %d sources", trackers, sources)
+	}
+}
+
+func TestSetLastReadCardsShowConcurrentPollWrites(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	const sourceURL = "https://mangadex.org/title/race-blade"
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Race Blade', 1, ?, 'reading', 0, 1)
+	`, sourceURL)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+
+	// The poller keeps raising the latest chapter while the card is clicked.
+	repo := repository.NewTrackerRepository(db)
+	var polled atomic.Int64
+	polled.Store(1)
+	stop := make(chan struct{})
+	pollDone := make(chan error, 1)
+	go func() {
+		for chapter := int64(2); ; chapter++ {
+			select {
+			case <-stop:
+				pollDone <- nil
+				return
+			default:
+			}
+			latest, checkedAt := float64(chapter), time.Now().UTC()
+			if err := repo.UpdatePollingState(context.Background(), trackerID, 1, sourceURL, nil, sourceURL, &latest, &checkedAt, false, checkedAt); err != nil {
+				pollDone <- err
+				return
+			}
+			polled.Store(chapter)
+			// A poll cycle resolves a source between writes; without the
+			// pause the loop would starve the card's write of the lock.
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	chapterPattern := regexp.MustCompile(`class="tracker-row__chapter[^"]*"[^>]*>Ch\. ([0-9.]+)<`)
+	path := "/dashboard/trackers/" + strconv.FormatInt(trackerID, 10) + "/set-last-read?profile=profile1"
+	shownLatest := 0.0
+	for read := 1; read <= 40; read++ {
+		committedBefore := float64(polled.Load())
+		form := url.Values{"view_mode": {"list"}, "chapter": {strconv.Itoa(read)}}
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("set last read request failed: %v", err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("set last read %d: expected 200, got %d: %s", read, res.StatusCode, body)
+		}
+
+		matches := chapterPattern.FindAllStringSubmatch(string(body), -1)
+		if len(matches) != 2 {
+			t.Fatalf("set last read %d: expected the last read and latest chapters on the card, got %s", read, body)
+		}
+		lastRead, _ := strconv.ParseFloat(matches[0][1], 64)
+		latest, _ := strconv.ParseFloat(matches[1][1], 64)
+		if lastRead != float64(read) {
+			t.Fatalf("set last read %d: card shows last read %v", read, lastRead)
+		}
+		if latest < committedBefore || latest < shownLatest {
+			t.Fatalf("set last read %d: card shows latest %v, but chapter %v was polled before the click and %v shown earlier", read, latest, committedBefore, shownLatest)
+		}
+		shownLatest = latest
+	}
+
+	close(stop)
+	if err := <-pollDone; err != nil {
+		t.Fatalf("poll write failed: %v", err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 func TestDashboardReadingFilterExcludesCaughtUpTrackers(t *testing.T) {
@@ -274,3 +276,66 @@ func TestDashboardSearchMatchesWordsInAnyOrderAndRelatedTitles(t *testing.T) {
 		t.Fatalf("did not expect unrelated tracker in search results")
 	}
 }
+
+func getTrackersPartial(t *testing.T, app *fiber.App, target string) string {
+	t.Helper()
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+	if err != nil {
+		t.Fatalf("dashboard trackers request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+	}
+	return string(body)
+}
+
+func TestDashboardEmptyLibraryRendersOnboarding(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	html := getTrackersPartial(t, app, "/dashboard/trackers?profile=profile1&status=reading&view=grid&page=1")
+	if !strings.Contains(html, "empty-state--onboarding") {
+		t.Fatalf("expected onboarding block for an empty library, got %s", html)
+	}
+	if !strings.Contains(html, `name="source_url"`) || !strings.Contains(html, "/dashboard/trackers/new") {
+		t.Fatalf("expected quick-add url field and add tracker button")
+	}
+	if !strings.Contains(html, "Supported sites:") || !strings.Contains(html, "MangaDex") {
+		t.Fatalf("expected supported source list in onboarding block")
+	}
+	if strings.Contains(html, `id="cards-container-grid"`) || strings.Contains(html, `class="pagination`) {
+		t.Fatalf("expected no grid or pagination for an empty library")
+	}
+
+	html = getTrackersPartial(t, app, "/dashboard/trackers?profile=profile1&status=all")
+	if !strings.Contains(html, "empty-state--onboarding") {
+		t.Fatalf("expected the all-statuses view of an empty library to show onboarding")
+	}
+}
+
+func TestDashboardFilteredEmptyResultOffersClearFilters(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	_, err := db.Exec(`
+		INSERT INTO trackers (title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, "Existing Tracker", 1, "https://asuracomic.net/series/existing", "reading", 1.0, 2.0)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+
+	html := getTrackersPartial(t, app, "/dashboard/trackers?profile=profile1&q=nothing+matches&status=dropped&sites=2&sort=rating&view=list&page=3")
+	if !strings.Contains(html, "empty-state--filtered") || strings.Contains(html, "empty-state--onboarding") {
+		t.Fatalf("expected no-matches block for filtered empty result, got %s", html)
+	}
+	if !strings.Contains(html, `href="/dashboard?profile=profile1&amp;sort=rating&amp;view=list"`) {
+		t.Fatalf("expected clear filters link keeping profile, sort and view, got %s", html)
+	}
+
+	html = getTrackersPartial(t, app, "/dashboard/trackers?profile=profile2&q=nothing")
+	if !strings.Contains(html, "empty-state--filtered") {
+		t.Fatalf("expected a search in an empty library to offer clearing it")
+	}
+}
@@ -1,6 +1,8 @@
 package handlers_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +11,9 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 func TestNewTrackerModalRenders(t *testing.T) {
@@ -39,13 +44,59 @@ func TestNewTrackerModalRenders(t *testing.T) {
 	}
 }
 
+func TestNewTrackerModalOpensTheTrackerOfAnOldURL(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	mangaDexID, _ := sourceMetaByKey(t, db, "mangadex")
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+		VALUES (1, 'Moved Tracker', ?, 'https://mangadex.org/title/moved-new', 'reading')
+	`, mangaDexID)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	if _, err := db.Exec(`
+		INSERT INTO tracker_url_history (tracker_id, source_id, old_url, reason)
+		VALUES (?, ?, 'https://mangadex.org/title/moved-old', 'slug_rotation')
+	`, trackerID, mangaDexID); err != nil {
+		t.Fatalf("insert url history: %v", err)
+	}
+
+	open := func(sourceURL string) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/dashboard/trackers/new?source_url="+url.QueryEscape(sourceURL), nil)
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("new tracker modal request failed: %v", err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+		}
+		return string(body)
+	}
+
+	html := open("http://mangadex.org/title/moved-old/")
+	if !strings.Contains(html, "Edit Tracker") || !strings.Contains(html, fmt.Sprintf("/dashboard/trackers/%d?view=", trackerID)) {
+		t.Fatalf("expected the old URL to open the tracker's edit form, got %s", html)
+	}
+
+	html = open("https://mangadex.org/title/not-tracked")
+	if !strings.Contains(html, "New Tracker") || !strings.Contains(html, "https://mangadex.org/title/not-tracked") {
+		t.Fatalf("expected an untracked URL to prefill the new tracker form")
+	}
+}
+
 func TestCreateTrackerFromFormPrependsWithoutImmediateRefresh(t *testing.T) {
-	_, app, cleanup := setupTestApp(t)
+	db, app, cleanup := setupTestApp(t)
 	defer cleanup()
 
+	mangaDexID, _ := sourceMetaByKey(t, db, "mangadex")
 	form := url.Values{}
 	form.Set("title", "Prepended Tracker")
-	form.Set("source_id", "1")
+	form.Set("source_id", strconv.FormatInt(mangaDexID, 10))
 	form.Set("source_url", "https://mangadex.org/title/prepended-tracker")
 	form.Set("status", "reading")
 	form.Set("view_mode", "grid")
@@ -76,7 +127,12 @@ func TestCreateTrackerFromFormPrependsWithoutImmediateRefresh(t *testing.T) {
 	}
 	html := string(body)
 
-	if strings.TrimSpace(html) != "" {
+	// Only the out-of-band announcement is sent, which empties the modal.
+	announcement := `<div hx-swap-oob="innerHTML:#dashboard-announcer"><p>Tracker Prepended Tracker added</p></div>`
+	if !strings.Contains(html, announcement) {
+		t.Fatalf("expected the creation to be announced, got %q", html)
+	}
+	if rest := strings.Replace(html, announcement, "", 1); strings.TrimSpace(rest) != "" {
 		t.Fatalf("expected empty modal response body, got %q", html)
 	}
 }
@@ -178,3 +234,294 @@ func TestSetRatingFromCardReplacesCard(t *testing.T) {
 		t.Fatalf("expected rated card response to render updated score")
 	}
 }
+
+func TestCreateTrackerFromFormRejectsURLFromAnotherSource(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	mangaDexID, _ := sourceMetaByKey(t, db, "mangadex")
+	form := url.Values{}
+	form.Set("title", "Mismatched Form Tracker")
+	form.Set("source_id", strconv.FormatInt(mangaDexID, 10))
+	form.Set("source_url", "https://mangafire.to/manga/mismatched.abc")
+	form.Set("status", "reading")
+
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/trackers", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("create tracker form request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", res.StatusCode)
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	message := string(body)
+	if !strings.Contains(message, "does not belong to MangaDex (expected mangadex.org)") {
+		t.Fatalf("expected mismatch message naming mangadex.org, got %q", message)
+	}
+	if !strings.Contains(message, "switch the source to MangaFire") {
+		t.Fatalf("expected suggestion to switch to MangaFire, got %q", message)
+	}
+}
+
+func TestReadingMilestonesAreExposed(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter, first_read_at, caught_up_at)
+		VALUES (1, 'Milestone Series', 1, 'https://mangadex.org/title/milestone-series', 'completed', 80, 80, '2023-01-15 10:00:00', '2024-08-20 18:30:00')
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("tracker id: %v", err)
+	}
+	id := strconv.FormatInt(trackerID, 10)
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/"+id+"/edit", nil))
+	if err != nil {
+		t.Fatalf("edit modal request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	html := string(body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, html)
+	}
+	if !strings.Contains(html, "<dd>Jan 15, 2023</dd>") {
+		t.Fatalf("expected edit modal to show the first read date, got %s", html)
+	}
+	if !strings.Contains(html, "<dd>Aug 20, 2024 (19 months)</dd>") {
+		t.Fatalf("expected edit modal to show the caught-up date and reading span, got %s", html)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers/"+id, nil))
+	if err != nil {
+		t.Fatalf("api request failed: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	if !strings.Contains(string(body), `"firstReadAt":"2023-01-15T10:00:00Z"`) || !strings.Contains(string(body), `"caughtUpAt":"2024-08-20T18:30:00Z"`) {
+		t.Fatalf("expected api response to include milestones, got %s", string(body))
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/export-view?format=csv&status=completed", nil))
+	if err != nil {
+		t.Fatalf("export request failed: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	if !strings.Contains(string(body), "first_read_at,caught_up_at") || !strings.Contains(string(body), "2023-01-15T10:00:00Z,2024-08-20T18:30:00Z") {
+		t.Fatalf("expected csv export to include milestones, got %s", string(body))
+	}
+}
+
+func TestNewTrackerModalPrefillsQuickAddURL(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	var mangaDexID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&mangaDexID); err != nil {
+		t.Fatalf("load mangadex source: %v", err)
+	}
+	mangaDexOption := `<option value="` + strconv.FormatInt(mangaDexID, 10) + `"`
+
+	sourceURL := "https://mangadex.org/title/a1c7c817-4e59-43b7-9365-09675a149a6f"
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/new?view=grid&source_url="+url.QueryEscape(sourceURL), nil))
+	if err != nil {
+		t.Fatalf("new tracker modal request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	html := string(body)
+	if !strings.Contains(html, `name="source_url" value="`+sourceURL+`"`) {
+		t.Fatalf("expected source url to be prefilled, got %s", html)
+	}
+	if !strings.Contains(html, mangaDexOption+` data-search-mode="`) || !regexp.MustCompile(regexp.QuoteMeta(mangaDexOption)+`[^>]*selected`).MatchString(html) {
+		t.Fatalf("expected mangadex to be preselected")
+	}
+}
+
+func TestLastPollErrorIsExposed(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	failedAt := time.Now().UTC().Add(-72 * time.Hour).Format("2006-01-02 15:04:05")
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_poll_error, last_poll_error_at)
+		VALUES (1, 'Stalled Series', 1, 'https://asuracomic.net/series/stalled-series', 'reading', 'unexpected status: 404', ?)
+	`, failedAt)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("tracker id: %v", err)
+	}
+	id := strconv.FormatInt(trackerID, 10)
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/"+id+"/edit", nil))
+	if err != nil {
+		t.Fatalf("edit modal request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if !strings.Contains(string(body), "Last update check failed 3 days ago: unexpected status: 404") {
+		t.Fatalf("expected edit modal to show the last poll error, got %s", string(body))
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers/"+id, nil))
+	if err != nil {
+		t.Fatalf("api request failed: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	if !strings.Contains(string(body), `"lastPollError":"unexpected status: 404"`) || !strings.Contains(string(body), `"lastPollErrorAt":"`) {
+		t.Fatalf("expected api response to include the last poll error, got %s", string(body))
+	}
+}
+
+func postCardAction(t *testing.T, app *fiber.App, target string, form url.Values) (*http.Response, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("card action request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+	}
+	return res, string(body)
+}
+
+func TestSetLastReadRemovesCardThatLeavesTheFilter(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Behind Series', 1, 'https://asuracomic.net/series/behind-series', 'reading', 8, 10)
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	id := strconv.FormatInt(trackerID, 10)
+
+	form := url.Values{}
+	form.Set("view_mode", "grid")
+	form.Set("status", "reading")
+	form.Set("sort", "latest_known_chapter")
+	form.Set("page", "1")
+	res, html := postCardAction(t, app, "/dashboard/trackers/"+id+"/set-last-read", form)
+
+	if !strings.Contains(html, `hx-swap-oob="delete:#tracker-card-`+id+`"`) {
+		t.Fatalf("expected caught-up tracker to be removed from the reading view, got %s", html)
+	}
+	if strings.Contains(html, `hx-swap-oob="outerHTML:#tracker-card-`+id+`"`) {
+		t.Fatalf("expected no card replacement for a tracker that left the filter")
+	}
+	if trigger := res.Header.Get("HX-Trigger"); strings.Contains(trigger, "trackerMoved") {
+		t.Fatalf("expected no move hint for a filtered-out tracker, got %q", trigger)
+	}
+
+	form.Set("status", "all")
+	if _, err := db.Exec(`UPDATE trackers SET last_read_chapter = 8 WHERE id = ?`, trackerID); err != nil {
+		t.Fatalf("reset last read: %v", err)
+	}
+	_, html = postCardAction(t, app, "/dashboard/trackers/"+id+"/set-last-read", form)
+	if !strings.Contains(html, `hx-swap-oob="outerHTML:#tracker-card-`+id+`"`) {
+		t.Fatalf("expected tracker still matching the filter to be replaced in place, got %s", html)
+	}
+}
+
+func TestSetRatingHintsMoveOnRatingSort(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	_, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, rating, latest_known_chapter)
+		VALUES
+			(1, 'Top Rated', 1, 'https://asuracomic.net/series/top-rated', 'reading', 8, 10),
+			(1, 'Low Rated', 1, 'https://asuracomic.net/series/low-rated', 'reading', 5, 10)
+	`)
+	if err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+	var lowID int64
+	if err := db.QueryRow(`SELECT id FROM trackers WHERE title = 'Low Rated'`).Scan(&lowID); err != nil {
+		t.Fatalf("load tracker id: %v", err)
+	}
+	id := strconv.FormatInt(lowID, 10)
+
+	form := url.Values{}
+	form.Set("view_mode", "grid")
+	form.Set("status", "reading")
+	form.Set("sort", "rating")
+	form.Set("order", "desc")
+	form.Set("page", "1")
+	form.Set("rating", "9.5")
+	res, html := postCardAction(t, app, "/dashboard/trackers/"+id+"/rating", form)
+	if !strings.Contains(html, `hx-swap-oob="outerHTML:#tracker-card-`+id+`"`) {
+		t.Fatalf("expected moved card to still be replaced, got %s", html)
+	}
+	if trigger := res.Header.Get("HX-Trigger"); !strings.Contains(trigger, "trackerMoved") {
+		t.Fatalf("expected trackerMoved hint after the card changed position, got %q", trigger)
+	}
+
+	form.Set("rating", "9.0")
+	res, _ = postCardAction(t, app, "/dashboard/trackers/"+id+"/rating", form)
+	if trigger := res.Header.Get("HX-Trigger"); trigger != "" {
+		t.Fatalf("expected no hint when the card keeps its position, got %q", trigger)
+	}
+}
+
+func TestUpdateFromFormLimitsRelatedTitles(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	titles := make([]string, 0, 25)
+	titles = append(titles, strings.Repeat("Long Title ", 12))
+	for index := 1; index <= 20; index++ {
+		titles = append(titles, fmt.Sprintf("Alt Title %d", index), fmt.Sprintf("alt-title %d", index))
+	}
+	encoded, err := json.Marshal(titles)
+	if err != nil {
+		t.Fatalf("encode titles: %v", err)
+	}
+
+	// The row starts out oversized, as written before the limits existed.
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, related_titles, source_id, source_url, status)
+		VALUES (1, 'Alt Series', ?, 1, 'https://asuracomic.net/series/alt-series', 'reading')
+	`, string(encoded))
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("tracker id: %v", err)
+	}
+	id := strconv.FormatInt(trackerID, 10)
+
+	form := url.Values{}
+	form.Set("title", "Alt Series")
+	form.Set("source_id", "1")
+	form.Set("source_url", "https://asuracomic.net/series/alt-series")
+	form.Set("status", "reading")
+	form.Set("related_titles_json", string(encoded))
+	postCardAction(t, app, "/dashboard/trackers/"+id+"?view=grid", form)
+
+	var stored string
+	if err := db.QueryRow(`SELECT related_titles FROM trackers WHERE id = ?`, trackerID).Scan(&stored); err != nil {
+		t.Fatalf("load related titles: %v", err)
+	}
+	var got []string
+	if err := json.Unmarshal([]byte(stored), &got); err != nil {
+		t.Fatalf("decode related titles: %v", err)
+	}
+	if len(got) != 15 || got[0] != "Alt Title 1" || got[14] != "Alt Title 15" {
+		t.Fatalf("expected the first 15 distinct titles, got %v", got)
+	}
+}
@@ -2,51 +2,51 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"math"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
 	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
 	"github.com/gofiber/fiber/v2"
 )
 
 func (h *DashboardHandler) TrackersPartial(c *fiber.Ctx) error {
-	activeProfile, err := h.profileResolver.Resolve(c)
+	pageCtx, err := h.pageContext(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).SendString("Invalid profile")
+		return sendPageError(c, err)
 	}
+	activeProfile := pageCtx.profile
 
 	c.Set("Cache-Control", "no-store, no-cache, must-revalidate")
 	c.Set("Pragma", "no-cache")
 	c.Set("Expires", "0")
 
-	status := strings.TrimSpace(c.Query("status", "reading"))
-	statuses := make([]string, 0)
-	if status != "" && status != "all" {
-		statuses = append(statuses, status)
-	}
-
 	viewMode := normalizeViewMode(c.Query("view", "grid"))
 	page := parsePositiveInt(c.Query("page", "1"), 1)
-	const pageSize = 24
+	pageSize := trackersPageSize(viewMode, c.Query("page_size"))
 
-	listOptions := repository.TrackerListOptions{
-		ProfileID: activeProfile.ID,
-		Statuses:  statuses,
-		TagNames:  parseTagNamesFromQuery(c),
-		SourceIDs: parseSourceIDsFromQuery(c),
-		SortBy:    strings.TrimSpace(c.Query("sort", "latest_known_chapter")),
-		Order:     strings.TrimSpace(c.Query("order", "desc")),
-		Query:     strings.TrimSpace(c.Query("q")),
+	listOptions, err := h.dashboardListOptions(c, activeProfile.ID)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	ignoredTags, err := dropUnknownTagFilters(c.UserContext(), h.trackerRepo, &listOptions)
+	if err != nil {
+		return serverError(c, "Failed to load profile tags", err)
 	}
 
-	totalTrackers, err := h.trackerRepo.Count(listOptions)
+	page, err = clampDeepPage(c.UserContext(), h.trackerRepo, listOptions, page, pageSize)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load trackers")
+		return serverError(c, "Failed to count trackers", err)
+	}
+
+	listOptions.Limit = pageSize
+	listOptions.Offset = (page - 1) * pageSize
+	items, totalTrackers, err := h.trackerRepo.ListWithTotal(c.UserContext(), listOptions)
+	if err != nil {
+		return serverError(c, "Failed to load trackers", err)
 	}
 
 	totalPages := int(math.Ceil(float64(totalTrackers) / float64(pageSize)))
@@ -56,42 +56,56 @@ func (h *DashboardHandler) TrackersPartial(c *fiber.Ctx) error {
 
 	if page > totalPages {
 		page = totalPages
+		listOptions.Offset = (page - 1) * pageSize
+		items, totalTrackers, err = h.trackerRepo.ListWithTotal(c.UserContext(), listOptions)
+		if err != nil {
+			return serverError(c, "Failed to load trackers", err)
+		}
 	}
-	offset := (page - 1) * pageSize
-	listOptions.Limit = pageSize
-	listOptions.Offset = offset
-	refreshKey := c.OriginalURL()
+	// The key outlives the request in queued fetch jobs, and Fiber reuses
+	// the buffer OriginalURL points into once the handler returns.
+	refreshKey := strings.Clone(c.OriginalURL())
 	h.setActiveTrackersPageKey(refreshKey)
 
-	items, err := h.trackerRepo.List(listOptions)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load trackers")
-	}
-
 	hasNextPage := page < totalPages
-	linkedSites, err := h.listLinkedSourcesForProfile(activeProfile.ID)
+	linkedSites, err := pageCtx.LinkedSites()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked sites")
+		return sendPageError(c, err)
 	}
 
-	sourceLogoBySourceID, err := h.sourceRepo.ListProfileSourceLogoURLs(activeProfile.ID)
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load linked site logos")
+		return sendPageError(c, err)
 	}
 
-	sources, err := h.sourceRepo.ListEnabled()
+	sourceByID, err := pageCtx.SourceByID()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).SendString("Failed to load sources")
+		return sendPageError(c, err)
 	}
 
-	sourceByID := make(map[int64]models.Source, len(sources))
-	for _, source := range sources {
-		sourceByID[source.ID] = source
+	cards, pendingCovers := h.buildTrackerCards(c.UserContext(), items, sourceByID, sourceLogoBySourceID, refreshKey)
+	if viewMode == "wall" {
+		// Wall tiles show no chapter links, so only missing covers should
+		// keep the page polling.
+		pendingCovers = false
+		for _, card := range cards {
+			if card.CoverPending {
+				pendingCovers = true
+				break
+			}
+		}
 	}
-
-	cards, pendingCovers := h.buildTrackerCards(items, sourceByID, sourceLogoBySourceID, refreshKey)
 	siteLinks := buildTrackerSiteLinks(linkedSites, sourceLogoBySourceID)
 
+	isEmptyLibrary := false
+	if totalTrackers == 0 {
+		libraryTotal, err := h.trackerRepo.Count(c.UserContext(), repository.TrackerListOptions{ProfileID: activeProfile.ID})
+		if err != nil {
+			return serverError(c, "Failed to count trackers", err)
+		}
+		isEmptyLibrary = libraryTotal == 0
+	}
+
 	return h.render(c, "trackers_partial.html", trackersPartialData{
 		Trackers:      cards,
 		SiteLinks:     siteLinks,
@@ -106,15 +120,119 @@ func (h *DashboardHandler) TrackersPartial(c *fiber.Ctx) error {
 		HasNextPage:   hasNextPage,
 		PendingCovers: pendingCovers,
 		RefreshKey:    refreshKey,
+		IgnoredTags:   ignoredTags,
+
+		IsEmptyLibrary:   isEmptyLibrary,
+		HasActiveFilters: len(ignoredTags) > 0 || trackerFiltersActive(listOptions),
+		ReadOnly:         isReadOnly(c),
+		ClearFiltersURL:  clearFiltersURL(c),
 	})
 }
 
+// trackerFiltersActive reports whether the list is narrowed beyond the
+// dashboard defaults. "all" counts as a default since it hides nothing.
+func trackerFiltersActive(options repository.TrackerListOptions) bool {
+	if options.Query != "" || len(options.TagFilters) > 0 || len(options.SourceIDs) > 0 {
+		return true
+	}
+	for _, status := range options.Statuses {
+		if status != "reading" {
+			return true
+		}
+	}
+	return false
+}
+
+// trackerFilterParams are the query parameters clearFiltersURL drops.
+var trackerFilterParams = []string{"q", "status", "tags", "sites", "page"}
+
+// clearFiltersURL returns the dashboard URL for the current request with the
+// filters removed, keeping the profile, view and sort.
+func clearFiltersURL(c *fiber.Ctx) string {
+	params, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		params = url.Values{}
+	}
+	for _, key := range trackerFilterParams {
+		params.Del(key)
+	}
+	if encoded := params.Encode(); encoded != "" {
+		return "/dashboard?" + encoded
+	}
+	return "/dashboard"
+}
+
+// trackerListOptionsFromQuery builds the filter and sort options shared by the
+// trackers partial and the view export, without pagination.
+func trackerListOptionsFromQuery(c *fiber.Ctx, profileID int64) repository.TrackerListOptions {
+	return trackerListOptionsFromArgs(c.Context().QueryArgs(), profileID)
+}
+
+func trackerListOptionsFromArgs(args filterArgs, profileID int64) repository.TrackerListOptions {
+	status := dashboardStatusArg(args)
+	statuses := make([]string, 0)
+	if status != "" && status != "all" {
+		statuses = append(statuses, status)
+	}
+
+	return repository.TrackerListOptions{
+		ProfileID:  profileID,
+		Statuses:   statuses,
+		TagFilters: parseTagFiltersFromArgs(args),
+		SourceIDs:  parseSourceIDsFromArgs(args),
+		SortBy:     strings.TrimSpace(argValue(args, "sort", globalSortDefault.Sort)),
+		Order:      strings.TrimSpace(argValue(args, "order", globalSortDefault.Order)),
+		Query:      strings.TrimSpace(argValue(args, "q", "")),
+	}
+}
+
+// dashboardStatusArg is the status args filter on, "reading" by default.
+func dashboardStatusArg(args filterArgs) string {
+	return strings.TrimSpace(argValue(args, "status", "reading"))
+}
+
+// argValue mirrors fiber's c.Query default handling: an empty or missing
+// value yields fallback.
+func argValue(args filterArgs, key string, fallback string) string {
+	if value := string(args.Peek(key)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+var dashboardViewModes = []string{"grid", "list", "wall"}
+
+// dashboardStatuses and dashboardSorts are the options of the filter form's
+// status and sort selects.
+var (
+	dashboardStatuses = []string{"all", "reading", "completed", "on_hold", "dropped", "plan_to_read"}
+	dashboardSorts    = []string{"latest_known_chapter", "last_read_at", "rating"}
+)
+
 func normalizeViewMode(raw string) string {
 	viewMode := strings.TrimSpace(raw)
-	if viewMode != "grid" && viewMode != "list" {
-		return "grid"
+	for _, mode := range dashboardViewModes {
+		if viewMode == mode {
+			return viewMode
+		}
 	}
-	return viewMode
+	return "grid"
+}
+
+const (
+	defaultTrackersPageSize = 24
+	wallTrackersPageSize    = 60
+	maxTrackersPageSize     = 120
+)
+
+// trackersPageSize returns how many trackers a page holds. The poster wall
+// packs far more tiles per row, so it defaults to a larger page and accepts a
+// page_size override; grid and list keep the fixed size.
+func trackersPageSize(viewMode string, rawPageSize string) int {
+	if viewMode != "wall" {
+		return defaultTrackersPageSize
+	}
+	return min(parsePositiveInt(rawPageSize, wallTrackersPageSize), maxTrackersPageSize)
 }
 
 func parsePositiveInt(raw string, fallback int) int {
@@ -125,6 +243,26 @@ func parsePositiveInt(raw string, fallback int) int {
 	return value
 }
 
+// maxListOffset is the deepest row OFFSET pagination skips to. Past it the
+// dashboard counts first and clamps to the last page, and the JSON API asks
+// for cursor pagination instead.
+const maxListOffset = 10000
+
+// clampDeepPage clamps a page whose offset would pass maxListOffset to the
+// last page before any offset is computed, so a crafted page number neither
+// overflows nor makes SQLite walk every match just to skip it. Shallower pages
+// are left to the caller, whose list query already reports the total.
+func clampDeepPage(ctx context.Context, repo *repository.TrackerRepository, options repository.TrackerListOptions, page int, pageSize int) (int, error) {
+	if page <= maxListOffset/pageSize+1 {
+		return page, nil
+	}
+	total, err := repo.Count(ctx, options)
+	if err != nil {
+		return 0, err
+	}
+	return max(1, min(page, (total+pageSize-1)/pageSize)), nil
+}
+
 func buildPageNumbers(totalPages int, currentPage int) []int {
 	if totalPages <= 0 {
 		return []int{1}
@@ -174,107 +312,41 @@ func buildPageNumbers(totalPages int, currentPage int) []int {
 	return pages
 }
 
-func (h *DashboardHandler) buildTrackerCards(items []models.Tracker, sourceByID map[int64]models.Source, sourceLogoBySourceID map[int64]string, pageKey string) ([]trackerCardView, bool) {
-	cards := make([]trackerCardView, 0, len(items))
-	pendingCovers := false
-	for _, item := range items {
-		tagViews := toTrackerTagView(item.Tags)
-		displayTags, hiddenTagCount := prioritizeTrackerTags(tagViews, 3)
-
-		card := trackerCardView{
-			ID:                     item.ID,
-			Title:                  item.Title,
-			Status:                 item.Status,
-			StatusLabel:            statusLabel(item.Status),
-			Tags:                   displayTags,
-			HiddenTagCount:         hiddenTagCount,
-			TagIcons:               toTrackerTagIcons(item.Tags),
-			SourceURL:              item.SourceURL,
-			LatestKnownChapterURL:  item.SourceURL,
-			LastReadChapterURL:     item.SourceURL,
-			SourceItemID:           item.SourceItemID,
-			Rating:                 item.Rating,
-			LatestKnownChapterRaw:  item.LatestKnownChapter,
-			LastReadChapterRaw:     item.LastReadChapter,
-			LatestReleaseAgo:       "—",
-			LatestReleaseFormatted: "—",
-			UpdatedAtFormatted:     item.UpdatedAt.Format("2006-01-02 15:04"),
-			LastReadAgo:            "—",
-		}
-
-		if item.LastReadAt != nil {
-			card.LastReadAgo = relativeTime(*item.LastReadAt)
-		}
-
-		if item.LastCheckedAt != nil {
-			card.LastCheckedFormatted = item.LastCheckedAt.Format("2006-01-02 15:04")
-			card.LastCheckedAgo = relativeTime(*item.LastCheckedAt)
-		} else {
-			card.LastCheckedFormatted = "—"
-			card.LastCheckedAgo = "—"
-		}
-
-		if item.LatestReleaseAt != nil {
-			card.LatestReleaseFormatted = item.LatestReleaseAt.Format("2006-01-02 15:04")
-			card.LatestReleaseAgo = relativeTime(*item.LatestReleaseAt)
-		}
-
-		if item.LatestKnownChapter != nil {
-			card.LatestKnownChapter = formatChapterLabel(*item.LatestKnownChapter)
-		} else {
-			card.LatestKnownChapter = "—"
-		}
-
-		if item.LastReadChapter != nil {
-			card.LastReadChapter = formatChapterLabel(*item.LastReadChapter)
-		} else {
-			card.LastReadChapter = "—"
-		}
-
-		if item.Rating != nil {
-			card.RatingLabel = formatRatingLabel(*item.Rating)
-		}
-
-		source := sourceByID[item.SourceID]
-		sourceKey := strings.TrimSpace(source.Key)
-		sourceName := strings.TrimSpace(source.Name)
-		if sourceName == "" {
-			if sourceKey != "" {
-				sourceName = humanizeValueLabel(sourceKey)
-			} else {
-				sourceName = "Site"
-			}
-		}
-
-		card.SourceLogoURL = strings.TrimSpace(sourceLogoBySourceID[item.SourceID])
-		card.SourceLogoLabel = sourceName
-
-		if item.LatestKnownChapter != nil {
-			latestChapterURL, waitingLatestChapterURL := h.getCachedOrQueueChapterURL(sourceKey, item.SourceURL, *item.LatestKnownChapter, pageKey)
-			card.LatestKnownChapterURL = latestChapterURL
-			if waitingLatestChapterURL {
-				pendingCovers = true
-			}
-		}
-
-		if item.LastReadChapter != nil {
-			lastReadChapterURL, waitingLastReadChapterURL := h.getCachedOrQueueChapterURL(sourceKey, item.SourceURL, *item.LastReadChapter, pageKey)
-			card.LastReadChapterURL = lastReadChapterURL
-			if waitingLastReadChapterURL {
-				pendingCovers = true
-			}
-		}
+func (h *DashboardHandler) buildTrackerCards(ctx context.Context, items []models.Tracker, sourceByID map[int64]models.Source, sourceLogoBySourceID map[int64]string, pageKey string) ([]trackerCardView, bool) {
+	cards, pending := h.cardBuilder().Build(items, sourceByID, sourceLogoBySourceID, h.continuationLinks(ctx, items), pageKey)
+	h.markChaptersSinceVisit(ctx, cards, items)
+	h.markBlacklistedSources(ctx, cards, items)
+	// Only a trackers page, which passes its key, knows the cards' places.
+	if pageKey != "" {
+		numberTrackerCards(cards)
+	}
+	return cards, pending
+}
 
-		coverURL, waitingCover := h.getCachedOrQueueCover(sourceKey, item.SourceURL, item.SourceItemID, pageKey)
-		card.CoverURL = coverURL
-		if waitingCover {
-			pendingCovers = true
+// numberTrackerCards gives the cards of a trackers page their position on
+// it for screen readers and a roving tabindex: the first card is the page's
+// one tab stop into the cards, and the arrow keys move on from there.
+func numberTrackerCards(cards []trackerCardView) {
+	for index := range cards {
+		cards[index].Position = index + 1
+		cards[index].SetSize = len(cards)
+		cards[index].TabIndex = -1
+		if index == 0 {
+			cards[index].TabIndex = 0
 		}
-
-		cards = append(cards, card)
 	}
+}
 
-	return cards, pendingCovers
+func (h *DashboardHandler) cardBuilder() TrackerCardBuilder {
+	var builder TrackerCardBuilder
+	// Nil services are left out rather than stored as typed nil interfaces.
+	if covers := h.coverService(); covers != nil {
+		builder.Covers = covers
+	}
+	if chapterURLs := h.chapterURLService(); chapterURLs != nil {
+		builder.ChapterURLs = chapterURLs
+	}
+	return builder
 }
 
 func buildTrackerSiteLinks(sources []models.Source, sourceLogoBySourceID map[int64]string) []trackerSiteLinkView {
@@ -298,116 +370,26 @@ func buildTrackerSiteLinks(sources []models.Source, sourceLogoBySourceID map[int
 	return links
 }
 
-func (h *DashboardHandler) getCachedOrQueueCover(sourceKey, sourceURL string, sourceItemID *string, pageKey string) (string, bool) {
-	trimmedSourceKey := strings.TrimSpace(sourceKey)
-	if trimmedSourceKey == "" {
-		return "", false
-	}
-
-	cacheKey := buildCoverCacheKey(trimmedSourceKey, sourceURL, sourceItemID)
-	if cachedURL, found, ok := h.getCachedCover(cacheKey); ok {
-		if found {
-			return cachedURL, false
-		}
-		return "", false
-	}
-
-	if strings.TrimSpace(sourceURL) == "" {
-		h.setCachedCover(cacheKey, "", false, 2*time.Minute)
-		return "", false
-	}
-
-	h.queueCoverFetch(trimmedSourceKey, sourceURL, sourceItemID, cacheKey, pageKey)
-	return "", true
-}
-
-func (h *DashboardHandler) queueCoverFetch(sourceKey, sourceURL string, sourceItemID *string, cacheKey string, pageKey string) {
-	h.coverFetchMu.Lock()
-	if h.coverInFlight[cacheKey] {
-		h.coverFetchMu.Unlock()
-		return
-	}
-	h.coverInFlight[cacheKey] = true
-	h.coverFetchMu.Unlock()
-
-	go func() {
-		isMangafire := strings.EqualFold(strings.TrimSpace(sourceKey), "mangafire")
-		if isMangafire {
-			h.mangafireCoverSem <- struct{}{}
-		} else {
-			h.coverFetchSem <- struct{}{}
-		}
-		defer func() {
-			if isMangafire {
-				<-h.mangafireCoverSem
-			} else {
-				<-h.coverFetchSem
-			}
-			h.coverFetchMu.Lock()
-			delete(h.coverInFlight, cacheKey)
-			h.coverFetchMu.Unlock()
-		}()
-
-		if pageKey != "" && !h.isActiveTrackersPageKey(pageKey) {
-			return
-		}
-
-		_, _ = h.fetchCoverURL(context.Background(), sourceKey, sourceURL, sourceItemID)
-	}()
-}
-func (h *DashboardHandler) getCachedOrQueueChapterURL(sourceKey, sourceURL string, chapter float64, pageKey string) (string, bool) {
-	trimmedSourceURL := strings.TrimSpace(sourceURL)
-	if trimmedSourceURL == "" {
-		return "", false
-	}
-
-	trimmedSourceKey := strings.TrimSpace(sourceKey)
-	if trimmedSourceKey == "" {
-		return trimmedSourceURL, false
-	}
-
-	cacheKey := buildChapterURLCacheKey(trimmedSourceKey, trimmedSourceURL, chapter)
-	if cachedChapterURL, found, ok := h.getCachedChapterURL(cacheKey); ok {
-		if found {
-			return cachedChapterURL, false
-		}
-		return trimmedSourceURL, false
-	}
-
-	h.queueChapterURLResolve(trimmedSourceKey, trimmedSourceURL, chapter, cacheKey, pageKey)
-	return trimmedSourceURL, true
-}
-
-func (h *DashboardHandler) queueChapterURLResolve(sourceKey, sourceURL string, chapter float64, cacheKey string, pageKey string) {
-	h.chapterURLFetchMu.Lock()
-	if h.chapterURLInFlight[cacheKey] {
-		h.chapterURLFetchMu.Unlock()
-		return
-	}
-	h.chapterURLInFlight[cacheKey] = true
-	h.chapterURLFetchMu.Unlock()
-
-	go func() {
-		h.chapterURLFetchSem <- struct{}{}
-		defer func() {
-			<-h.chapterURLFetchSem
-			h.chapterURLFetchMu.Lock()
-			delete(h.chapterURLInFlight, cacheKey)
-			h.chapterURLFetchMu.Unlock()
-		}()
-
-		if pageKey != "" && !h.isActiveTrackersPageKey(pageKey) {
-			return
-		}
-
-		_, _ = h.fetchChapterURL(sourceKey, sourceURL, chapter)
-	}()
+// getCachedOrQueueChapterURL returns the cached chapter URL of a card, or
+// the series URL while a lookup is queued.
+func (h *DashboardHandler) getCachedOrQueueChapterURL(sourceKey, sourceURL string, chapter float64, pageKey string, row int) (string, bool) {
+	return h.chapterURLService().CachedOrQueue(sourceKey, sourceURL, chapter, pageKey, row)
 }
 
+// setActiveTrackersPageKey records the page the dashboard is showing and
+// drops lookups still queued for any other page, so they neither hold up the
+// new page's rows nor keep their cache keys marked in flight.
 func (h *DashboardHandler) setActiveTrackersPageKey(pageKey string) {
 	h.activePageMu.Lock()
 	h.activePageKey = strings.TrimSpace(pageKey)
 	h.activePageMu.Unlock()
+
+	if covers := h.coverService(); covers != nil {
+		covers.DropStalePages(pageKey)
+	}
+	if chapterURLs := h.chapterURLService(); chapterURLs != nil {
+		chapterURLs.DropStalePages(pageKey)
+	}
 }
 
 func (h *DashboardHandler) isActiveTrackersPageKey(pageKey string) bool {
@@ -418,83 +400,13 @@ func (h *DashboardHandler) isActiveTrackersPageKey(pageKey string) bool {
 }
 
 func (h *DashboardHandler) fetchChapterURL(sourceKey, sourceURL string, chapter float64) (string, error) {
-	trimmedSourceURL := strings.TrimSpace(sourceURL)
-	if trimmedSourceURL == "" {
-		return "", fmt.Errorf("missing source url")
-	}
-
-	trimmedSourceKey := strings.TrimSpace(sourceKey)
-	if trimmedSourceKey == "" {
-		return trimmedSourceURL, nil
-	}
-
-	cacheKey := buildChapterURLCacheKey(trimmedSourceKey, trimmedSourceURL, chapter)
-	if cachedChapterURL, found, ok := h.getCachedChapterURL(cacheKey); ok {
-		if found {
-			return cachedChapterURL, nil
-		}
-		return trimmedSourceURL, fmt.Errorf("chapter url not found")
-	}
-
-	connector, ok := h.registry.Get(trimmedSourceKey)
-	if !ok {
-		h.setCachedChapterURL(cacheKey, "", false, 30*time.Minute)
-		return trimmedSourceURL, fmt.Errorf("connector not found")
-	}
-
-	resolver, ok := connector.(connectors.ChapterURLResolver)
-	if !ok {
-		h.setCachedChapterURL(cacheKey, "", false, 30*time.Minute)
-		return trimmedSourceURL, fmt.Errorf("chapter resolver not supported")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
-	defer cancel()
-
-	chapterURL, err := resolver.ResolveChapterURL(ctx, trimmedSourceURL, chapter)
-	if err != nil {
-		h.setCachedChapterURL(cacheKey, "", false, 2*time.Minute)
-		return trimmedSourceURL, fmt.Errorf("resolve chapter url: %w", err)
-	}
-
-	chapterURL = strings.TrimSpace(chapterURL)
-	if chapterURL == "" {
-		h.setCachedChapterURL(cacheKey, "", false, 30*time.Minute)
-		return trimmedSourceURL, fmt.Errorf("chapter url empty")
-	}
-
-	h.setCachedChapterURL(cacheKey, chapterURL, true, 12*time.Hour)
-	return chapterURL, nil
-}
-
-func buildChapterURLCacheKey(sourceKey, sourceURL string, chapter float64) string {
-	return strings.ToLower(strings.TrimSpace(sourceKey)) + "|" + strings.ToLower(strings.TrimSpace(sourceURL)) + "|" + strconv.FormatFloat(chapter, 'f', -1, 64)
+	return h.chapterURLService().Fetch(context.Background(), sourceKey, sourceURL, chapter)
 }
 
 func (h *DashboardHandler) getCachedChapterURL(cacheKey string) (chapterURL string, found bool, ok bool) {
-	h.chapterURLCacheMu.RLock()
-	entry, exists := h.chapterURLCache[cacheKey]
-	h.chapterURLCacheMu.RUnlock()
-	if !exists {
-		return "", false, false
-	}
-
-	if time.Now().UTC().After(entry.ExpiresAt) {
-		h.chapterURLCacheMu.Lock()
-		delete(h.chapterURLCache, cacheKey)
-		h.chapterURLCacheMu.Unlock()
-		return "", false, false
-	}
-
-	return entry.ChapterURL, entry.Found, true
+	return h.chapterURLService().Cached(cacheKey)
 }
 
 func (h *DashboardHandler) setCachedChapterURL(cacheKey, chapterURL string, found bool, ttl time.Duration) {
-	h.chapterURLCacheMu.Lock()
-	h.chapterURLCache[cacheKey] = chapterURLCacheEntry{
-		ChapterURL: chapterURL,
-		Found:      found,
-		ExpiresAt:  time.Now().UTC().Add(ttl),
-	}
-	h.chapterURLCacheMu.Unlock()
+	h.chapterURLService().SetCached(cacheKey, chapterURL, found, ttl)
 }
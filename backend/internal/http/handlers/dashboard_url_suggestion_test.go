@@ -0,0 +1,215 @@
+package handlers_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	apihttp "github.com/gabriel/cross-site-tracker/backend/internal/http"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	movedStaleURL = "https://mangadex.org/title/solo-blade-old"
+	movedLiveURL  = "https://mangadex.org/title/solo-blade"
+)
+
+// movedURLConnector resolves only movedLiveURL; every other URL fails with
+// resolveErr. Its title search returns results.
+type movedURLConnector struct {
+	resolveErr error
+	results    []connectors.MangaResult
+	searches   int
+}
+
+func (m *movedURLConnector) Key() string                       { return "mangadex" }
+func (m *movedURLConnector) Name() string                      { return "MangaDex" }
+func (m *movedURLConnector) Kind() string                      { return connectors.KindNative }
+func (m *movedURLConnector) HealthCheck(context.Context) error { return nil }
+func (m *movedURLConnector) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	if rawURL != movedLiveURL {
+		return nil, m.resolveErr
+	}
+	chapter := 88.0
+	updatedAt := time.Date(2026, 5, 6, 7, 8, 9, 0, time.UTC)
+	return &connectors.MangaResult{SourceKey: "mangadex", SourceItemID: "solo-blade", Title: "Solo Blade", URL: movedLiveURL, LatestChapter: &chapter, LastUpdatedAt: &updatedAt}, nil
+}
+func (m *movedURLConnector) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	m.searches++
+	return m.results, nil
+}
+
+func soloBladeSearchResult() []connectors.MangaResult {
+	chapter := 88.0
+	return []connectors.MangaResult{
+		{SourceKey: "mangadex", SourceItemID: "solo-blade", Title: "Solo Blade", URL: movedLiveURL, LatestChapter: &chapter},
+		{SourceKey: "mangadex", SourceItemID: "solo-blade-2", Title: "Solo Blade Side Stories", URL: movedLiveURL + "-side"},
+	}
+}
+
+func setupURLSuggestionApp(t *testing.T, connector *movedURLConnector) (*sql.DB, *fiber.App, func()) {
+	t.Helper()
+
+	registry := connectors.NewRegistry()
+	if err := registry.Register(connector); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+	return setupTestAppWithServer(t, config.Config{AppName: "test"}, func(cfg config.Config, db *sql.DB) *fiber.App {
+		return apihttp.NewServerWithRegistry(cfg, db, registry)
+	})
+}
+
+func postNewTracker(t *testing.T, app *fiber.App, form url.Values) (*http.Response, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/trackers", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("create tracker form request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+	}
+	return res, string(body)
+}
+
+func soloBladeForm(t *testing.T, db *sql.DB) url.Values {
+	t.Helper()
+
+	sourceID, _ := sourceMetaByKey(t, db, "mangadex")
+	form := url.Values{}
+	form.Set("title", "Solo Blade")
+	form.Set("source_id", strconv.FormatInt(sourceID, 10))
+	form.Set("source_url", movedStaleURL)
+	form.Set("status", "reading")
+	form.Set("view_mode", "grid")
+	return form
+}
+
+func trackerRowsByTitle(t *testing.T, db *sql.DB, title string) (count int, sourceURL string, sourceItemID sql.NullString, latest sql.NullFloat64) {
+	t.Helper()
+
+	if err := db.QueryRow(`SELECT COUNT(*) FROM trackers WHERE title = ?`, title).Scan(&count); err != nil {
+		t.Fatalf("count trackers: %v", err)
+	}
+	if count == 0 {
+		return count, "", sourceItemID, latest
+	}
+	if err := db.QueryRow(`SELECT source_url, source_item_id, latest_known_chapter FROM trackers WHERE title = ?`, title).Scan(&sourceURL, &sourceItemID, &latest); err != nil {
+		t.Fatalf("load tracker: %v", err)
+	}
+	return count, sourceURL, sourceItemID, latest
+}
+
+var urlSuggestionJSONPattern = regexp.MustCompile(`name="url_suggestion_json" value='([^']*)'`)
+
+func TestCreateFromFormSuggestsTitleMatchForUnresolvedURL(t *testing.T) {
+	connector := &movedURLConnector{
+		resolveErr: fmt.Errorf("mangadex returned status 404: %w", connectors.ErrNotFound),
+		results:    soloBladeSearchResult(),
+	}
+	db, app, cleanup := setupURLSuggestionApp(t, connector)
+	defer cleanup()
+
+	form := soloBladeForm(t, db)
+	res, body := postNewTracker(t, app, form)
+	if strings.Contains(res.Header.Get("HX-Trigger"), "trackerCreated") {
+		t.Fatalf("expected no tracker before the suggestion is confirmed, got HX-Trigger %q", res.Header.Get("HX-Trigger"))
+	}
+	if !strings.Contains(body, "URL didn't resolve; did you mean <strong>Solo Blade</strong>") || !strings.Contains(body, movedLiveURL) {
+		t.Fatalf("expected the form re-rendered with the suggestion, got: %s", body)
+	}
+	if !strings.Contains(body, `value="`+movedStaleURL+`"`) {
+		t.Fatalf("expected the entered URL kept in the form, got: %s", body)
+	}
+	if count, _, _, _ := trackerRowsByTitle(t, db, "Solo Blade"); count != 0 {
+		t.Fatalf("expected nothing saved before confirming, found %d trackers", count)
+	}
+
+	match := urlSuggestionJSONPattern.FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("expected the suggestion carried in the form, got: %s", body)
+	}
+	form.Set("url_suggestion", "accept")
+	form.Set("url_suggestion_json", html.UnescapeString(match[1]))
+	res, _ = postNewTracker(t, app, form)
+	if !strings.Contains(res.Header.Get("HX-Trigger"), "trackerCreated") {
+		t.Fatalf("expected the confirmed submit to create the tracker, got HX-Trigger %q", res.Header.Get("HX-Trigger"))
+	}
+
+	count, sourceURL, sourceItemID, latest := trackerRowsByTitle(t, db, "Solo Blade")
+	if count != 1 || sourceURL != movedLiveURL {
+		t.Fatalf("expected one tracker on the suggested URL, got %d on %q", count, sourceURL)
+	}
+	if !sourceItemID.Valid || sourceItemID.String != "solo-blade" || !latest.Valid || latest.Float64 != 88 {
+		t.Fatalf("expected the suggestion's item id and chapter, got %v and %v", sourceItemID, latest)
+	}
+}
+
+func TestCreateFromFormKeepsEnteredURLWhenSuggestionDeclined(t *testing.T) {
+	connector := &movedURLConnector{
+		resolveErr: fmt.Errorf("mangadex returned status 404: %w", connectors.ErrNotFound),
+		results:    soloBladeSearchResult(),
+	}
+	db, app, cleanup := setupURLSuggestionApp(t, connector)
+	defer cleanup()
+
+	form := soloBladeForm(t, db)
+	form.Set("url_suggestion", "keep")
+	res, _ := postNewTracker(t, app, form)
+	if !strings.Contains(res.Header.Get("HX-Trigger"), "trackerCreated") {
+		t.Fatalf("expected keep to create the tracker, got HX-Trigger %q", res.Header.Get("HX-Trigger"))
+	}
+	if connector.searches != 0 {
+		t.Fatalf("expected no title search once the suggestion was declined, got %d", connector.searches)
+	}
+	if count, sourceURL, _, _ := trackerRowsByTitle(t, db, "Solo Blade"); count != 1 || sourceURL != movedStaleURL {
+		t.Fatalf("expected one tracker on the entered URL, got %d on %q", count, sourceURL)
+	}
+}
+
+func TestCreateFromFormWithoutSuggestion(t *testing.T) {
+	cases := []struct {
+		name       string
+		resolveErr error
+		results    []connectors.MangaResult
+		searches   int
+	}{
+		{name: "lookup failure", resolveErr: errors.New("request failed: context deadline exceeded"), results: soloBladeSearchResult(), searches: 0},
+		{name: "no close match", resolveErr: fmt.Errorf("mangadex returned status 404: %w", connectors.ErrNotFound), results: []connectors.MangaResult{{Title: "Tower Of Ash", URL: "https://mangadex.org/title/tower"}}, searches: 1},
+		{name: "no results", resolveErr: fmt.Errorf("mangadex returned status 404: %w", connectors.ErrNotFound), searches: 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			connector := &movedURLConnector{resolveErr: tc.resolveErr, results: tc.results}
+			db, app, cleanup := setupURLSuggestionApp(t, connector)
+			defer cleanup()
+
+			res, body := postNewTracker(t, app, soloBladeForm(t, db))
+			if !strings.Contains(res.Header.Get("HX-Trigger"), "trackerCreated") || strings.Contains(body, "did you mean") {
+				t.Fatalf("expected the tracker created without a suggestion, got HX-Trigger %q (body: %s)", res.Header.Get("HX-Trigger"), body)
+			}
+			if connector.searches != tc.searches {
+				t.Fatalf("expected %d title searches, got %d", tc.searches, connector.searches)
+			}
+			if count, sourceURL, _, _ := trackerRowsByTitle(t, db, "Solo Blade"); count != 1 || sourceURL != movedStaleURL {
+				t.Fatalf("expected one tracker on the entered URL, got %d on %q", count, sourceURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package handlers_test
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func getDashboardHTML(t *testing.T, app *fiber.App, target string) string {
+	t.Helper()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+	if err != nil {
+		t.Fatalf("request %s failed: %v", target, err)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read %s response: %v", target, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from %s, got %d (body: %s)", target, res.StatusCode, string(body))
+	}
+	return string(body)
+}
+
+func seedWallTracker(t *testing.T, db *sql.DB, title string, lastRead float64, latest float64) int64 {
+	t.Helper()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, ?, 1, ?, 'reading', ?, ?)
+	`, title, "https://mangadex.org/title/"+strings.ToLower(strings.ReplaceAll(title, " ", "-")), lastRead, latest)
+	if err != nil {
+		t.Fatalf("seed tracker %q: %v", title, err)
+	}
+	trackerID, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("tracker id: %v", err)
+	}
+	return trackerID
+}
+
+func TestDashboardPageOffersWallViewMode(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	html := getDashboardHTML(t, app, "/dashboard")
+	for _, mode := range []string{"grid", "list", "wall"} {
+		if !strings.Contains(html, `data-view-mode="`+mode+`"`) {
+			t.Fatalf("expected view selector to offer %q mode", mode)
+		}
+	}
+}
+
+func TestTrackersPartialRendersWallTiles(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	trackerID := seedWallTracker(t, db, "Wall Series", 8, 10)
+
+	html := getDashboardHTML(t, app, "/dashboard/trackers?view=wall")
+	if !strings.Contains(html, `id="cards-container-wall"`) {
+		t.Fatalf("expected wall container, got %s", html)
+	}
+	if !strings.Contains(html, `id="tracker-card-`+strconv.FormatInt(trackerID, 10)+`" class="tracker-tile"`) {
+		t.Fatalf("expected tracker to render as a wall tile")
+	}
+	if !strings.Contains(html, `class="tracker-tile__unread" title="Unread chapters">&#43;2</span>`) {
+		t.Fatalf("expected wall tile to show the unread badge")
+	}
+	if strings.Contains(html, "card-actions") || strings.Contains(html, "Set last read") {
+		t.Fatalf("expected wall tiles to render without card buttons")
+	}
+}
+
+func TestTrackersPartialWallPageSize(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	for index := 1; index <= 70; index++ {
+		seedWallTracker(t, db, fmt.Sprintf("Wall Page %03d", index), 1, float64(index+1))
+	}
+
+	tests := []struct {
+		name      string
+		target    string
+		container string
+		wantCards int
+	}{
+		{name: "wall default", target: "/dashboard/trackers?view=wall", container: "tracker-tile", wantCards: 60},
+		{name: "wall override", target: "/dashboard/trackers?view=wall&page_size=30", container: "tracker-tile", wantCards: 30},
+		{name: "wall override capped", target: "/dashboard/trackers?view=wall&page_size=500", container: "tracker-tile", wantCards: 70},
+		{name: "grid ignores override", target: "/dashboard/trackers?view=grid&page_size=30", container: "tracker-card", wantCards: 24},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := getDashboardHTML(t, app, tt.target)
+			if got := strings.Count(html, `class="`+tt.container+`"`); got != tt.wantCards {
+				t.Fatalf("expected %d cards, got %d", tt.wantCards, got)
+			}
+		})
+	}
+}
+
+func TestTrackerCardFragmentRendersWallTile(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	trackerID := seedWallTracker(t, db, "Fragment Wall", 3, 5)
+
+	html := getDashboardHTML(t, app, "/dashboard/trackers/"+strconv.FormatInt(trackerID, 10)+"/card-fragment?view=wall")
+	if !strings.HasPrefix(strings.TrimSpace(html), `<article id="tracker-card-`+strconv.FormatInt(trackerID, 10)+`" class="tracker-tile" `) {
+		t.Fatalf("expected card fragment to be a single wall tile, got %s", html)
+	}
+	if strings.Contains(html, "tracker-card__stats") {
+		t.Fatalf("expected wall fragment to omit grid card stats")
+	}
+}
+
+func TestSetRatingFromCardReplacesWallTile(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	trackerID := seedWallTracker(t, db, "Rated Wall", 3, 5)
+
+	form := url.Values{}
+	form.Set("rating", "8")
+	form.Set("view_mode", "wall")
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/trackers/"+strconv.FormatInt(trackerID, 10)+"/rating", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("set rating request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+	}
+
+	html := string(body)
+	id := strconv.FormatInt(trackerID, 10)
+	if !strings.Contains(html, `<article id="tracker-card-`+id+`" class="tracker-tile" role="listitem" aria-label="Rated Wall" tabindex="-1" hx-swap-oob="outerHTML:#tracker-card-`+id+`">`) {
+		t.Fatalf("expected OOB replacement to render a wall tile, got %s", html)
+	}
+	if strings.Contains(html, "tracker-card__stats") {
+		t.Fatalf("expected wall OOB replacement to omit grid card stats")
+	}
+}
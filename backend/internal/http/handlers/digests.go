@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/digest"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DigestsHandler struct {
+	job             *digest.Job
+	profileResolver *profileContextResolver
+}
+
+// NewDigestsHandler builds the digest API. A nil sender means SMTP is not
+// configured and the test endpoint reports that instead of sending.
+func NewDigestsHandler(db *sql.DB, sender digest.Sender) *DigestsHandler {
+	handler := &DigestsHandler{profileResolver: newProfileContextResolver(db)}
+	if sender != nil {
+		handler.job = digest.NewJob(repository.NewDigestRepository(db), repository.NewProfileRepository(db), sender, digest.JobConfig{}, slog.Default())
+	}
+	return handler
+}
+
+func (h *DigestsHandler) SendTest(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	if h.job == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"message": "smtp is not configured"})
+	}
+
+	entries, err := h.job.SendTest(c.UserContext(), profile.ID)
+	if err != nil {
+		if errors.Is(err, digest.ErrNotConfigured) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+		}
+		requestLogger(c).Warn("test email digest failed", "profileId", profile.ID, "error", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"message": "failed to send test digest"})
+	}
+
+	return c.JSON(fiber.Map{"message": "test digest sent", "entries": entries})
+}
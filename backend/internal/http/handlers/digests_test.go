@@ -0,0 +1,227 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+func TestSaveDigestFromMenuPersistsSettings(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/profile/digest?profile=profile1", strings.NewReader("digest_email=reader%40example.com&digest_hour_utc=7&digest_enabled=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("save digest request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	html := string(body)
+	if !strings.Contains(html, "Email digest saved") {
+		t.Fatalf("expected saved message in profile menu")
+	}
+	if !strings.Contains(html, `value="reader@example.com"`) {
+		t.Fatalf("expected saved email to be rendered")
+	}
+	if !strings.Contains(html, `<option value="7" selected>`) {
+		t.Fatalf("expected saved hour to be selected")
+	}
+
+	var email string
+	var hourUTC int
+	var enabled bool
+	if err := db.QueryRow(`SELECT email, hour_utc, enabled FROM profile_email_digests WHERE profile_id = 1`).Scan(&email, &hourUTC, &enabled); err != nil {
+		t.Fatalf("load digest row: %v", err)
+	}
+	if email != "reader@example.com" || hourUTC != 7 || !enabled {
+		t.Fatalf("unexpected digest row: %q %d %v", email, hourUTC, enabled)
+	}
+}
+
+func TestSaveDigestFromMenuValidatesInput(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	cases := []string{
+		"digest_email=&digest_hour_utc=7",
+		"digest_email=not-an-email&digest_hour_utc=7",
+		"digest_email=reader%40example.com&digest_hour_utc=24",
+	}
+	for _, form := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/dashboard/profile/digest?profile=profile1", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("save digest request failed: %v", err)
+		}
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %q, got %d", form, res.StatusCode)
+		}
+	}
+}
+
+func TestSendTestDigestWithoutSMTPReturnsUnavailable(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/digests/test?profile=profile1", nil)
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("test digest request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", res.StatusCode)
+	}
+}
+
+func TestDigestRepositoryListEntriesFiltersByReleaseWindow(t *testing.T) {
+	db, _, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	now := time.Now().UTC()
+	_, err := db.Exec(`
+		INSERT INTO trackers (title, source_id, source_url, status, last_read_chapter, latest_known_chapter, latest_release_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?, ?)
+	`,
+		"Recent Release", 1, "https://mangadex.org/title/recent", "reading", 10.0, 12.0, now.Add(-2*time.Hour),
+		"Old Release", 1, "https://mangadex.org/title/old", "reading", 5.0, 6.0, now.Add(-48*time.Hour),
+		"No Release", 1, "https://mangadex.org/title/none", "reading", 1.0, 1.0, nil,
+	)
+	if err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+
+	if _, err := db.Exec(`INSERT INTO custom_tags (profile_id, name) VALUES (1, 'notify')`); err != nil {
+		t.Fatalf("seed tag: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO tracker_tags (tracker_id, tag_id)
+		SELECT t.id, ct.id FROM trackers t, custom_tags ct WHERE t.title = 'Recent Release' AND ct.name = 'notify'
+	`); err != nil {
+		t.Fatalf("seed tracker tag: %v", err)
+	}
+
+	entries, err := repository.NewDigestRepository(db).ListEntries(1, now.Add(-24*time.Hour), now)
+	if err != nil {
+		t.Fatalf("list digest entries: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].Title != "Recent Release" || entries[0].SourceName == "" {
+		t.Fatalf("unexpected digest entry: %#v", entries[0])
+	}
+	if entries[0].LatestKnownChapter == nil || *entries[0].LatestKnownChapter != 12 {
+		t.Fatalf("expected latest chapter 12, got %#v", entries[0].LatestKnownChapter)
+	}
+	if len(entries[0].TagIDs) != 1 {
+		t.Fatalf("expected the entry's tag, got %v", entries[0].TagIDs)
+	}
+}
+
+func TestDigestRepositoryListEntriesListsNewestFirst(t *testing.T) {
+	db, _, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	// The middle release is written the way CURRENT_TIMESTAMP writes.
+	_, err := db.Exec(`
+		INSERT INTO trackers (title, source_id, source_url, status, latest_release_at)
+		VALUES (?, 1, 'https://mangadex.org/title/older', 'reading', ?),
+		       (?, 1, 'https://mangadex.org/title/text', 'reading', '2026-05-10 09:00:00'),
+		       (?, 1, 'https://mangadex.org/title/newest', 'reading', ?),
+		       (?, 1, 'https://mangadex.org/title/ahead', 'reading', ?)
+	`,
+		"Older", now.Add(-6*time.Hour),
+		"Text Layout",
+		"Newest", now.Add(-time.Hour),
+		"Ahead", now.Add(time.Hour),
+	)
+	if err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+
+	entries, err := repository.NewDigestRepository(db).ListEntries(1, now.Add(-24*time.Hour), now)
+	if err != nil {
+		t.Fatalf("list digest entries: %v", err)
+	}
+	titles := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		titles = append(titles, entry.Title)
+	}
+	if strings.Join(titles, ",") != "Newest,Text Layout,Older" {
+		t.Fatalf("expected the window's releases newest first, got %v", titles)
+	}
+}
+
+func TestSaveDigestFromMenuStoresTagFiltersOfTheProfile(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	tagID := func(profileID int64, name string) string {
+		t.Helper()
+		result, err := db.Exec(`INSERT INTO custom_tags (profile_id, name) VALUES (?, ?)`, profileID, name)
+		if err != nil {
+			t.Fatalf("insert tag: %v", err)
+		}
+		id, _ := result.LastInsertId()
+		return strconv.FormatInt(id, 10)
+	}
+	notify := tagID(1, "notify")
+	mute := tagID(1, "mute")
+	otherProfile := tagID(2, "notify")
+
+	post := func(form string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/dashboard/profile/digest?profile=profile1", strings.NewReader("digest_email=reader%40example.com&digest_hour_utc=7&"+form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("save digest request failed: %v", err)
+		}
+		return res.StatusCode
+	}
+
+	for _, form := range []string{
+		"digest_include_tag_id=" + otherProfile,
+		"digest_exclude_tag_id=999999",
+		"digest_include_tag_id=" + notify + "&digest_exclude_tag_id=" + notify,
+	} {
+		if status := post(form); status != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %q, got %d", form, status)
+		}
+	}
+
+	if status := post("digest_include_tag_id=" + notify + "&digest_exclude_tag_id=" + mute); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	item, err := repository.NewDigestRepository(db).GetByProfileID(1)
+	if err != nil || item == nil {
+		t.Fatalf("load digest: %v", err)
+	}
+	if item.IncludeTagID == nil || strconv.FormatInt(*item.IncludeTagID, 10) != notify || item.ExcludeTagID == nil || strconv.FormatInt(*item.ExcludeTagID, 10) != mute {
+		t.Fatalf("expected the notify and mute filters, got %+v", item)
+	}
+
+	if status := post(""); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if item, _ = repository.NewDigestRepository(db).GetByProfileID(1); item == nil || item.IncludeTagID != nil || item.ExcludeTagID != nil {
+		t.Fatalf("expected empty choices to clear the filters, got %+v", item)
+	}
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// enrichmentRetryDelays are the waits before each retry of a new tracker's
+// source lookup. The tracker is given up on when the last retry fails.
+var enrichmentRetryDelays = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute}
+
+// enrichmentRetryQueue re-runs source enrichment in the background for
+// trackers whose lookup failed when they were created. A tracker is queued at
+// most once at a time. One goroutine, started by the first push, sleeps until
+// the next retry is due and keeps waiting while the queue is empty until stop
+// is called.
+type enrichmentRetryQueue struct {
+	mu      sync.Mutex
+	pending map[int64]*enrichmentRetry
+	delays  []time.Duration
+	retry   func(ctx context.Context, profileID, trackerID int64) error
+	giveUp  func(ctx context.Context, profileID, trackerID int64, attempts int, err error)
+	wake    chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	started bool
+	stopped bool
+}
+
+type enrichmentRetry struct {
+	profileID int64
+	attempts  int
+	dueAt     time.Time
+}
+
+func newEnrichmentRetryQueue(
+	delays []time.Duration,
+	retry func(ctx context.Context, profileID, trackerID int64) error,
+	giveUp func(ctx context.Context, profileID, trackerID int64, attempts int, err error),
+) *enrichmentRetryQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &enrichmentRetryQueue{
+		pending: make(map[int64]*enrichmentRetry),
+		delays:  delays,
+		retry:   retry,
+		giveUp:  giveUp,
+		wake:    make(chan struct{}, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+}
+
+func (q *enrichmentRetryQueue) push(profileID, trackerID int64) {
+	if trackerID <= 0 || len(q.delays) == 0 {
+		return
+	}
+
+	q.mu.Lock()
+	if q.stopped || q.pending[trackerID] != nil {
+		q.mu.Unlock()
+		return
+	}
+	q.pending[trackerID] = &enrichmentRetry{
+		profileID: profileID,
+		dueAt:     time.Now().Add(q.delays[0]),
+	}
+	startWorker := !q.started
+	q.started = true
+	q.mu.Unlock()
+
+	if startWorker {
+		go q.run()
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// stop ends the worker and drops pending retries. A retry that is running is
+// cancelled through its context.
+func (q *enrichmentRetryQueue) stop() {
+	q.mu.Lock()
+	q.stopped = true
+	started := q.started
+	q.pending = make(map[int64]*enrichmentRetry)
+	q.mu.Unlock()
+
+	q.cancel()
+	if started {
+		<-q.done
+	}
+}
+
+func (q *enrichmentRetryQueue) run() {
+	defer close(q.done)
+
+	for {
+		var timer *time.Timer
+		var due <-chan time.Time
+		if wait, ok := q.nextWait(); ok {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+
+		select {
+		case <-q.ctx.Done():
+		case <-q.wake:
+		case <-due:
+			q.runDue()
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if q.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (q *enrichmentRetryQueue) nextWait() (time.Duration, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var next time.Time
+	for _, item := range q.pending {
+		if next.IsZero() || item.dueAt.Before(next) {
+			next = item.dueAt
+		}
+	}
+	if next.IsZero() {
+		return 0, false
+	}
+	return max(time.Until(next), 0), true
+}
+
+func (q *enrichmentRetryQueue) runDue() {
+	now := time.Now()
+	q.mu.Lock()
+	dueIDs := make([]int64, 0)
+	for trackerID, item := range q.pending {
+		if !item.dueAt.After(now) {
+			dueIDs = append(dueIDs, trackerID)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, trackerID := range dueIDs {
+		q.mu.Lock()
+		item := q.pending[trackerID]
+		q.mu.Unlock()
+		if item == nil {
+			continue
+		}
+
+		err := q.retry(q.ctx, item.profileID, trackerID)
+		if q.ctx.Err() != nil {
+			return
+		}
+
+		q.mu.Lock()
+		item.attempts++
+		gaveUp := false
+		switch {
+		case err == nil:
+			delete(q.pending, trackerID)
+		case item.attempts >= len(q.delays):
+			delete(q.pending, trackerID)
+			gaveUp = true
+		default:
+			item.dueAt = time.Now().Add(q.delays[item.attempts])
+		}
+		q.mu.Unlock()
+
+		if gaveUp && q.giveUp != nil {
+			q.giveUp(q.ctx, item.profileID, trackerID, item.attempts, err)
+		}
+	}
+}
+
+// QueueEnrichmentRetry schedules background source lookups for a tracker
+// whose metadata could not be resolved when it was created.
+func (h *DashboardHandler) QueueEnrichmentRetry(profileID, trackerID int64) {
+	if h.enrichmentRetries == nil {
+		return
+	}
+	h.enrichmentRetries.push(profileID, trackerID)
+}
+
+// StopEnrichmentRetries stops the background retries on shutdown.
+func (h *DashboardHandler) StopEnrichmentRetries() {
+	if h.enrichmentRetries == nil {
+		return
+	}
+	h.enrichmentRetries.stop()
+}
+
+func (h *DashboardHandler) retryTrackerEnrichment(ctx context.Context, profileID, trackerID int64) error {
+	tracker, err := h.trackerRepo.GetByID(ctx, profileID, trackerID)
+	if err != nil {
+		return err
+	}
+	if tracker == nil || hasResolvedSourceMetadata(tracker) {
+		return nil
+	}
+
+	lang, err := h.primarySourceLang(ctx, profileID, tracker)
+	if err != nil {
+		return err
+	}
+	resolvedFromURL := tracker.SourceURL
+	if err := h.enrichTrackerFromSource(ctx, tracker, lang); err != nil {
+		return err
+	}
+	if _, err := h.trackerRepo.UpdateResolvedSource(ctx, profileID, trackerID, resolvedFromURL, tracker, time.Now().UTC()); err != nil {
+		return err
+	}
+	h.editForms.forgetProfile(profileID)
+	return nil
+}
+
+func (h *DashboardHandler) giveUpTrackerEnrichment(ctx context.Context, profileID, trackerID int64, attempts int, err error) {
+	slog.Warn("tracker source lookup given up", "tracker_id", trackerID, "attempts", attempts, "error", err)
+	note := fmt.Sprintf("Source lookup failed after %d retries: %v", attempts, err)
+	if setErr := h.trackerRepo.SetResolveFailure(ctx, profileID, trackerID, note); setErr != nil {
+		slog.Warn("record tracker resolve failure failed", "tracker_id", trackerID, "error", setErr)
+	}
+	h.editForms.forget(profileID, trackerID)
+}
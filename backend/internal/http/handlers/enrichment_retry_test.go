@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// flakyConnectorStub fails its first failures lookups and resolves after.
+type flakyConnectorStub struct {
+	calls    *atomic.Int64
+	failures int64
+}
+
+func (flakyConnectorStub) Key() string                       { return "mangadex" }
+func (flakyConnectorStub) Name() string                      { return "MangaDex" }
+func (flakyConnectorStub) Kind() string                      { return connectors.KindNative }
+func (flakyConnectorStub) HealthCheck(context.Context) error { return nil }
+
+func (s flakyConnectorStub) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	if s.calls.Add(1) <= s.failures {
+		return nil, errors.New("source unavailable")
+	}
+	chapter := 42.0
+	releasedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &connectors.MangaResult{
+		SourceKey:     "mangadex",
+		SourceItemID:  "flaky-item",
+		Title:         "Flaky Series",
+		URL:           rawURL,
+		CoverImageURL: "https://example.com/flaky.jpg",
+		LatestChapter: &chapter,
+		LastUpdatedAt: &releasedAt,
+	}, nil
+}
+
+func (flakyConnectorStub) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+
+func setupEnrichmentRetryTest(t *testing.T, failures int64) (*DashboardHandler, *atomic.Int64, int64) {
+	t.Helper()
+
+	var calls atomic.Int64
+	registry := connectors.NewRegistry()
+	if err := registry.Register(flakyConnectorStub{calls: &calls, failures: failures}); err != nil {
+		t.Fatalf("register flaky stub: %v", err)
+	}
+
+	db, h := setupInternalDashboardHandler(t, registry)
+	h.enrichmentRetries = newEnrichmentRetryQueue(
+		[]time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond},
+		h.retryTrackerEnrichment,
+		h.giveUpTrackerEnrichment,
+	)
+	t.Cleanup(h.StopEnrichmentRetries)
+
+	var sourceID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&sourceID); err != nil {
+		t.Fatalf("load mangadex source: %v", err)
+	}
+	return h, &calls, sourceID
+}
+
+func waitForTracker(t *testing.T, h *DashboardHandler, trackerID int64, done func(*models.Tracker) bool) *models.Tracker {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		tracker, err := h.trackerRepo.GetByID(context.Background(), 1, trackerID)
+		if err != nil {
+			t.Fatalf("load tracker: %v", err)
+		}
+		if tracker != nil && done(tracker) {
+			return tracker
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for tracker %d, last state %+v", trackerID, tracker)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCreateFromFormRetriesFailedEnrichment(t *testing.T) {
+	// The lookup during create and the first retry fail; the second retry
+	// resolves.
+	h, calls, sourceID := setupEnrichmentRetryTest(t, 2)
+
+	app := fiber.New()
+	app.Post("/dashboard/trackers", h.CreateFromForm)
+	form := url.Values{}
+	form.Set("title", "Flaky Series")
+	form.Set("source_id", strconv.FormatInt(sourceID, 10))
+	form.Set("source_url", "https://mangadex.org/title/6b1eb93e-473a-4ab3-9922-1a66d2a29a4a")
+	form.Set("status", "reading")
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/trackers", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("create tracker form request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from create, got %d", res.StatusCode)
+	}
+
+	created, err := h.trackerRepo.List(context.Background(), repository.TrackerListOptions{ProfileID: 1})
+	if err != nil || len(created) != 1 {
+		t.Fatalf("expected one created tracker, got %d (%v)", len(created), err)
+	}
+	trackerID := created[0].ID
+	if created[0].SourceItemID != nil || created[0].LastCheckedAt != nil {
+		t.Fatalf("expected failed create lookup to leave metadata and the check time empty, got %+v", created[0])
+	}
+	// Queueing again while the retry is pending must not add lookups.
+	h.QueueEnrichmentRetry(1, trackerID)
+
+	tracker := waitForTracker(t, h, trackerID, func(tracker *models.Tracker) bool {
+		return tracker.SourceItemID != nil
+	})
+	if *tracker.SourceItemID != "flaky-item" || tracker.LatestKnownChapter == nil || *tracker.LatestKnownChapter != 42 || tracker.LatestReleaseAt == nil {
+		t.Fatalf("expected retry to persist resolved metadata, got %+v", tracker)
+	}
+	if tracker.ResolveFailure != nil {
+		t.Fatalf("expected no resolve failure after a successful retry, got %q", *tracker.ResolveFailure)
+	}
+	if tracker.LastCheckedAt == nil {
+		t.Fatalf("expected the successful retry to record the check")
+	}
+	if coverURL, found, ok := h.getCachedCover(linkcache.CoverKey("mangadex", tracker.SourceURL, tracker.SourceItemID)); !ok || !found || coverURL != "https://example.com/flaky.jpg" {
+		t.Fatalf("expected retry to warm the cover cache, got %q found=%v ok=%v", coverURL, found, ok)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected create lookup plus two retries, got %d lookups", got)
+	}
+}
+
+func TestCreateFromFormRecordsTheCheckWhenTheLookupWorks(t *testing.T) {
+	h, _, sourceID := setupEnrichmentRetryTest(t, 0)
+
+	app := fiber.New()
+	app.Post("/dashboard/trackers", h.CreateFromForm)
+	form := url.Values{}
+	form.Set("title", "Flaky Series")
+	form.Set("source_id", strconv.FormatInt(sourceID, 10))
+	form.Set("source_url", "https://mangadex.org/title/6b1eb93e-473a-4ab3-9922-1a66d2a29a4a")
+	form.Set("status", "reading")
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/trackers", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if res, err := app.Test(req); err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from create, got %v (%v)", res, err)
+	}
+
+	created, err := h.trackerRepo.List(context.Background(), repository.TrackerListOptions{ProfileID: 1})
+	if err != nil || len(created) != 1 {
+		t.Fatalf("expected one created tracker, got %d (%v)", len(created), err)
+	}
+	if created[0].LastCheckedAt == nil || created[0].LatestKnownChapter == nil {
+		t.Fatalf("expected a resolved create to record the check, got %+v", created[0])
+	}
+}
+
+func TestEnrichmentRetryGivesUpAfterThirdFailure(t *testing.T) {
+	h, calls, sourceID := setupEnrichmentRetryTest(t, 100)
+
+	created, err := h.trackerRepo.Create(context.Background(), &models.Tracker{
+		ProfileID: 1,
+		Title:     "Unreachable Series",
+		SourceID:  sourceID,
+		SourceURL: "https://mangadex.org/title/0f6f7d1e-5d0c-4b8a-9d4e-3f2f5f0f8a11",
+		Status:    "reading",
+	})
+	if err != nil {
+		t.Fatalf("create tracker: %v", err)
+	}
+	h.QueueEnrichmentRetry(1, created.ID)
+
+	tracker := waitForTracker(t, h, created.ID, func(tracker *models.Tracker) bool {
+		return tracker.ResolveFailure != nil
+	})
+	if !strings.Contains(*tracker.ResolveFailure, "after 3 retries") || !strings.Contains(*tracker.ResolveFailure, "source unavailable") {
+		t.Fatalf("expected give-up note naming the attempts and error, got %q", *tracker.ResolveFailure)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected exactly three retries before giving up, got %d", got)
+	}
+
+	// The worker keeps running once the queue is empty, and stops on shutdown.
+	h.QueueEnrichmentRetry(1, created.ID)
+	waitForCalls := time.Now().Add(time.Second)
+	for calls.Load() < 4 && time.Now().Before(waitForCalls) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := calls.Load(); got < 4 {
+		t.Fatalf("expected a retry to run after the queue emptied, got %d lookups", got)
+	}
+
+	h.StopEnrichmentRetries()
+	stoppedAt := calls.Load()
+	h.QueueEnrichmentRetry(1, created.ID)
+	time.Sleep(80 * time.Millisecond)
+	if got := calls.Load(); got != stoppedAt {
+		t.Fatalf("expected no retries after stop, got %d more", got-stoppedAt)
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"container/heap"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// fetchQueue runs background cover and chapter URL lookups on a bounded
+// number of workers. Queued jobs start lowest priority first, so the rows at
+// the top of a trackers page resolve before the ones below the fold; equal
+// priorities keep their queueing order. Workers are started on demand and
+// exit once the queue is empty. A job that panics, as a connector can on
+// unexpected HTML, is logged and the worker moves on to the next job.
+type fetchQueue struct {
+	mu      sync.Mutex
+	jobs    fetchJobHeap
+	nextSeq uint64
+	workers int
+	running int
+}
+
+type fetchJob struct {
+	pageKey  string
+	priority int
+	seq      uint64
+	run      func()
+	// discard is called instead of run when the job is dropped before it
+	// starts, so callers can release whatever they reserved for it.
+	discard func()
+}
+
+func newFetchQueue(workers int) *fetchQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	return &fetchQueue{workers: workers}
+}
+
+func (q *fetchQueue) push(pageKey string, priority int, run func(), discard func()) {
+	q.mu.Lock()
+	heap.Push(&q.jobs, &fetchJob{
+		pageKey:  strings.TrimSpace(pageKey),
+		priority: priority,
+		seq:      q.nextSeq,
+		run:      run,
+		discard:  discard,
+	})
+	q.nextSeq++
+	startWorker := q.running < q.workers
+	if startWorker {
+		q.running++
+	}
+	q.mu.Unlock()
+
+	if startWorker {
+		go q.work()
+	}
+}
+
+func (q *fetchQueue) work() {
+	for {
+		q.mu.Lock()
+		if q.jobs.Len() == 0 {
+			q.running--
+			q.mu.Unlock()
+			return
+		}
+		job := heap.Pop(&q.jobs).(*fetchJob)
+		q.mu.Unlock()
+
+		runFetchJob(job)
+	}
+}
+
+// runFetchJob runs job, recovering a panic in it. Jobs release what they
+// reserved in a deferred call, so that still happens on a panic.
+func runFetchJob(job *fetchJob) {
+	defer func() {
+		if value := recover(); value != nil {
+			slog.Error("background fetch panicked", "page_key", job.pageKey, "panic", value, "stack", string(debug.Stack()))
+		}
+	}()
+	job.run()
+}
+
+// dropStalePages discards queued jobs that were queued for a page other than
+// activePageKey. Jobs queued without a page key (single-card renders) and
+// jobs that have already started are left alone.
+func (q *fetchQueue) dropStalePages(activePageKey string) {
+	activePageKey = strings.TrimSpace(activePageKey)
+
+	q.mu.Lock()
+	queued := q.jobs
+	kept := queued[:0]
+	dropped := make([]*fetchJob, 0)
+	for _, job := range queued {
+		if job.pageKey == "" || job.pageKey == activePageKey {
+			kept = append(kept, job)
+			continue
+		}
+		dropped = append(dropped, job)
+	}
+	for index := len(kept); index < len(queued); index++ {
+		queued[index] = nil
+	}
+	q.jobs = kept
+	heap.Init(&q.jobs)
+	q.mu.Unlock()
+
+	for _, job := range dropped {
+		if job.discard != nil {
+			job.discard()
+		}
+	}
+}
+
+type fetchJobHeap []*fetchJob
+
+func (h fetchJobHeap) Len() int {
+	return len(h)
+}
+
+func (h fetchJobHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority < h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h fetchJobHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *fetchJobHeap) Push(value any) {
+	*h = append(*h, value.(*fetchJob))
+}
+
+func (h *fetchJobHeap) Pop() any {
+	old := *h
+	last := len(old) - 1
+	job := old[last]
+	old[last] = nil
+	*h = old[:last]
+	return job
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+type coverOrderConnectorStub struct {
+	mu       sync.Mutex
+	resolved []string
+}
+
+func (*coverOrderConnectorStub) Key() string {
+	return "orderstub"
+}
+
+func (*coverOrderConnectorStub) Name() string {
+	return "Order Stub"
+}
+
+func (*coverOrderConnectorStub) Kind() string {
+	return connectors.KindNative
+}
+
+func (*coverOrderConnectorStub) HealthCheck(context.Context) error {
+	return nil
+}
+
+func (s *coverOrderConnectorStub) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	s.mu.Lock()
+	s.resolved = append(s.resolved, rawURL)
+	s.mu.Unlock()
+	return &connectors.MangaResult{SourceKey: "orderstub", URL: rawURL, CoverImageURL: rawURL + "/cover.jpg"}, nil
+}
+
+func (*coverOrderConnectorStub) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+
+func (s *coverOrderConnectorStub) resolvedURLs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.resolved...)
+}
+
+func TestCoverFetchesResolveInRowOrderAndDropStalePages(t *testing.T) {
+	stub := &coverOrderConnectorStub{}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(stub); err != nil {
+		t.Fatalf("register stub connector: %v", err)
+	}
+
+	h := &DashboardHandler{
+		registry:            registry,
+		links:               linkcache.NewResolver(registry, nil, nil),
+		coverInFlight:       make(map[string]bool),
+		coverFetchQueue:     newFetchQueue(1),
+		mangafireCoverQueue: newFetchQueue(1),
+	}
+	sourceByID := map[int64]models.Source{1: {ID: 1, Key: "orderstub", Name: "Order Stub"}}
+	page := func(name string) []models.Tracker {
+		items := make([]models.Tracker, 0, 24)
+		for row := 0; row < 24; row++ {
+			items = append(items, models.Tracker{
+				ID:        int64(row + 1),
+				Title:     name + " " + strconv.Itoa(row),
+				Status:    "reading",
+				SourceID:  1,
+				SourceURL: "https://example.com/" + name + "/" + strconv.Itoa(row),
+			})
+		}
+		return items
+	}
+
+	// Hold the single worker so both pages queue up before anything runs.
+	gate := make(chan struct{})
+	h.coverFetchQueue.push("", -1, func() { <-gate }, nil)
+
+	h.setActiveTrackersPageKey("/dashboard/trackers?page=1")
+	if _, pending := h.buildTrackerCards(context.Background(), page("first"), sourceByID, map[int64]string{}, "/dashboard/trackers?page=1"); !pending {
+		t.Fatalf("expected first page covers to be queued")
+	}
+
+	h.setActiveTrackersPageKey("/dashboard/trackers?page=2")
+	h.coverFetchMu.Lock()
+	inFlight := len(h.coverInFlight)
+	h.coverFetchMu.Unlock()
+	if inFlight != 0 {
+		t.Fatalf("expected dropped first page lookups to release their in-flight marks, got %d", inFlight)
+	}
+	if _, pending := h.buildTrackerCards(context.Background(), page("second"), sourceByID, map[int64]string{}, "/dashboard/trackers?page=2"); !pending {
+		t.Fatalf("expected second page covers to be queued")
+	}
+	close(gate)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(stub.resolvedURLs()) < 24 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	resolved := stub.resolvedURLs()
+	if len(resolved) != 24 {
+		t.Fatalf("expected exactly the 24 second page covers to resolve, got %d: %v", len(resolved), resolved)
+	}
+	for row, rawURL := range resolved {
+		if expected := "https://example.com/second/" + strconv.Itoa(row); rawURL != expected {
+			t.Fatalf("expected row %d to resolve %s, got order %v", row, expected, resolved)
+		}
+	}
+}
+
+func TestFetchQueueStartsLowestPriorityFirst(t *testing.T) {
+	queue := newFetchQueue(1)
+	gate := make(chan struct{})
+	queue.push("", -1, func() { <-gate }, nil)
+
+	var mu sync.Mutex
+	order := []int{}
+	done := make(chan struct{}, 5)
+	for _, priority := range []int{5, 3, 9, 1, 3} {
+		priority := priority
+		queue.push("page", priority, func() {
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+			done <- struct{}{}
+		}, nil)
+	}
+	close(gate)
+	for range 5 {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for queued jobs, ran %v", order)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for index, expected := range []int{1, 3, 3, 5, 9} {
+		if order[index] != expected {
+			t.Fatalf("expected jobs in priority order, got %v", order)
+		}
+	}
+}
+
+// panickyCoverConnectorStub crashes on series URLs ending in /broken, the
+// way a connector's parser can on HTML it does not expect.
+type panickyCoverConnectorStub struct {
+	coverOrderConnectorStub
+}
+
+func (s *panickyCoverConnectorStub) ResolveByURL(ctx context.Context, rawURL string) (*connectors.MangaResult, error) {
+	if strings.HasSuffix(rawURL, "/broken") {
+		var chapters []string
+		_ = chapters[3]
+	}
+	return s.coverOrderConnectorStub.ResolveByURL(ctx, rawURL)
+}
+
+func TestCoverFetchesSurviveAPanickingConnector(t *testing.T) {
+	stub := &panickyCoverConnectorStub{}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(stub); err != nil {
+		t.Fatalf("register stub connector: %v", err)
+	}
+	covers := NewCoverService(linkcache.NewResolver(registry, nil, nil), CoverServiceConfig{Workers: 1, MangaFireWorkers: 1})
+
+	gate := make(chan struct{})
+	covers.queue.push("", -1, func() { <-gate }, nil)
+	for row, slug := range []string{"first", "broken", "last"} {
+		if _, pending := covers.CachedOrQueue("orderstub", "https://example.com/"+slug, nil, "", row); !pending {
+			t.Fatalf("expected the %s cover to be queued", slug)
+		}
+	}
+	close(gate)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(stub.resolvedURLs()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if resolved := stub.resolvedURLs(); len(resolved) != 2 || resolved[1] != "https://example.com/last" {
+		t.Fatalf("expected the covers around the crash to resolve, got %v", resolved)
+	}
+
+	for time.Now().Before(deadline) {
+		covers.mu.Lock()
+		inFlight := len(covers.inFlight)
+		covers.mu.Unlock()
+		if inFlight == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected the crashed lookup to release its in-flight mark")
+}
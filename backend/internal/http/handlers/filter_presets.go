@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxFilterPresetNameLength is the longest preset name the dashboard and the
+// API accept.
+const maxFilterPresetNameLength = 40
+
+type filterPresetRequest struct {
+	Name string `json:"name"`
+	// Query is the dashboard's filter query string to save, such as
+	// "status=all&tags=favorite&sites=2".
+	Query string `json:"query"`
+}
+
+// FilterPresetsHandler serves the profile's saved filter presets over the
+// JSON API.
+type FilterPresetsHandler struct {
+	presets         *repository.FilterPresetRepository
+	trackers        *repository.TrackerRepository
+	sources         *repository.SourceRepository
+	profileResolver *profileContextResolver
+}
+
+func NewFilterPresetsHandler(db *sql.DB) *FilterPresetsHandler {
+	return &FilterPresetsHandler{
+		presets:         repository.NewFilterPresetRepository(db),
+		trackers:        repository.NewTrackerRepository(db),
+		sources:         repository.NewSourceRepository(db),
+		profileResolver: newProfileContextResolver(db),
+	}
+}
+
+func (h *FilterPresetsHandler) List(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	presets, err := h.presets.List(c.UserContext(), profile.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to list filter presets", err)
+	}
+
+	return c.JSON(fiber.Map{"items": presets})
+}
+
+// Create saves the filters of the query string in the body under a name.
+func (h *FilterPresetsHandler) Create(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	var req filterPresetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid json body"})
+	}
+	name, err := validateFilterPresetName(req.Name)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	values, err := url.ParseQuery(strings.TrimPrefix(strings.TrimSpace(req.Query), "?"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid query"})
+	}
+	params := filterPresetParamsFromArgs(valuesArgs(values))
+	if err := validateFilterPresetParams(params); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	created, err := h.presets.Create(c.UserContext(), profile.ID, name, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "a filter preset with that name already exists"})
+		}
+		return serverErrorJSON(c, "failed to create filter preset", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// Rename changes a preset's name; its filters stay as saved.
+func (h *FilterPresetsHandler) Rename(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid filter preset id"})
+	}
+
+	var req filterPresetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid json body"})
+	}
+	name, err := validateFilterPresetName(req.Name)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	renamed, err := h.presets.Rename(c.UserContext(), profile.ID, id, name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "a filter preset with that name already exists"})
+		}
+		return serverErrorJSON(c, "failed to rename filter preset", err)
+	}
+	if !renamed {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "filter preset not found"})
+	}
+
+	preset, err := h.presets.Get(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load filter preset", err)
+	}
+	if preset == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "filter preset not found"})
+	}
+	return c.JSON(preset)
+}
+
+func (h *FilterPresetsHandler) Delete(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid filter preset id"})
+	}
+
+	deleted, err := h.presets.Delete(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to delete filter preset", err)
+	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "filter preset not found"})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Apply returns the preset as the dashboard's filter query string, without
+// the tags the profile no longer has or the sites no longer enabled. Those
+// are listed in ignoredTags and ignoredSites.
+func (h *FilterPresetsHandler) Apply(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid filter preset id"})
+	}
+
+	preset, err := h.presets.Get(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load filter preset", err)
+	}
+	if preset == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "filter preset not found"})
+	}
+
+	enabledSources, err := h.sources.ListEnabled(c.UserContext())
+	if err != nil {
+		return serverErrorJSON(c, "failed to load sources", err)
+	}
+	applied, err := applyFilterPreset(c.UserContext(), h.trackers, profile.ID, enabledSources, preset.Params)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load profile tags", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"id":           preset.ID,
+		"name":         preset.Name,
+		"query":        filterPresetQuery(applied.Params),
+		"params":       applied.Params,
+		"ignoredTags":  applied.IgnoredTags,
+		"ignoredSites": applied.IgnoredSites,
+	})
+}
+
+func validateFilterPresetName(raw string) (string, error) {
+	name := strings.TrimSpace(raw)
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if len(name) > maxFilterPresetNameLength {
+		return "", fmt.Errorf("name must be %d characters or less", maxFilterPresetNameLength)
+	}
+	return name, nil
+}
+
+// validateFilterPresetParams accepts the statuses and sorts the dashboard's
+// filter form offers.
+func validateFilterPresetParams(params models.FilterPresetParams) error {
+	if params.Status != "" && !slices.Contains(dashboardStatuses, params.Status) {
+		return fmt.Errorf("invalid status")
+	}
+	if params.Sort != "" && !slices.Contains(dashboardSorts, params.Sort) {
+		return fmt.Errorf("invalid sort")
+	}
+	return nil
+}
+
+// valuesArgs reads parsed url.Values as filterArgs.
+type valuesArgs url.Values
+
+func (v valuesArgs) Peek(key string) []byte {
+	return []byte(url.Values(v).Get(key))
+}
+
+func (v valuesArgs) PeekMulti(key string) [][]byte {
+	values := make([][]byte, 0, len(v[key]))
+	for _, value := range v[key] {
+		values = append(values, []byte(value))
+	}
+	return values
+}
+
+// filterPresetParamsFromArgs reads the filters of a dashboard query string
+// or filter form body as the trackers partial does, each tag term and site
+// once. Empty inputs are left empty rather than set to the defaults.
+func filterPresetParamsFromArgs(args filterArgs) models.FilterPresetParams {
+	params := models.FilterPresetParams{
+		Query:  strings.TrimSpace(string(args.Peek("q"))),
+		Status: strings.TrimSpace(string(args.Peek("status"))),
+		Sort:   strings.TrimSpace(string(args.Peek("sort"))),
+	}
+	for _, filter := range parseTagFiltersFromArgs(args) {
+		params.Tags = append(params.Tags, tagFilterTerm(filter))
+	}
+	if sourceIDs := parseSourceIDsFromArgs(args); len(sourceIDs) > 0 {
+		params.Sites = sourceIDs
+	}
+	return params
+}
+
+// tagFilterTerm writes filter as the tags term parseTagFilters reads back
+// as the same filter.
+func tagFilterTerm(filter repository.TagFilter) string {
+	term := strings.Join(filter.AnyOf, "|")
+	if filter.Exclude {
+		return "-" + term
+	}
+	return term
+}
+
+// filterPresetQuery encodes params the way the dashboard's filter form
+// submits them: one tags value per term and one sites value per source.
+func filterPresetQuery(params models.FilterPresetParams) string {
+	values := url.Values{}
+	if params.Query != "" {
+		values.Set("q", params.Query)
+	}
+	if params.Status != "" {
+		values.Set("status", params.Status)
+	}
+	if params.Sort != "" {
+		values.Set("sort", params.Sort)
+	}
+	for _, term := range params.Tags {
+		values.Add("tags", term)
+	}
+	for _, sourceID := range params.Sites {
+		values.Add("sites", strconv.FormatInt(sourceID, 10))
+	}
+	return values.Encode()
+}
+
+// appliedFilterPreset is a preset's params without the filters that no
+// longer apply, and those filters.
+type appliedFilterPreset struct {
+	Params       models.FilterPresetParams
+	IgnoredTags  []string
+	IgnoredSites []int64
+}
+
+// applyFilterPreset drops the tag names the profile no longer has, as
+// dropUnknownTagFilters does for a bookmarked URL, and the sites that are
+// no longer enabled, so an old preset narrows the list by the filters left
+// rather than matching nothing. A tag named by several terms is reported
+// once.
+func applyFilterPreset(ctx context.Context, repo *repository.TrackerRepository, profileID int64, enabledSources []models.Source, params models.FilterPresetParams) (appliedFilterPreset, error) {
+	options := repository.TrackerListOptions{
+		ProfileID:  profileID,
+		TagFilters: parseTagFilters(strings.Join(params.Tags, ",")),
+	}
+	ignoredTags, err := dropUnknownTagFilters(ctx, repo, &options)
+	if err != nil {
+		return appliedFilterPreset{}, err
+	}
+
+	applied := appliedFilterPreset{Params: params, IgnoredTags: []string{}, IgnoredSites: []int64{}}
+	seenTags := make(map[string]bool, len(ignoredTags))
+	for _, name := range ignoredTags {
+		if normalized := searchutil.NormalizeTagName(name); !seenTags[normalized] {
+			seenTags[normalized] = true
+			applied.IgnoredTags = append(applied.IgnoredTags, name)
+		}
+	}
+	applied.Params.Tags = nil
+	for _, filter := range options.TagFilters {
+		applied.Params.Tags = append(applied.Params.Tags, tagFilterTerm(filter))
+	}
+
+	enabled := make(map[int64]bool, len(enabledSources))
+	for _, source := range enabledSources {
+		enabled[source.ID] = true
+	}
+	applied.Params.Sites = nil
+	for _, sourceID := range params.Sites {
+		if enabled[sourceID] {
+			applied.Params.Sites = append(applied.Params.Sites, sourceID)
+		} else {
+			applied.IgnoredSites = append(applied.IgnoredSites, sourceID)
+		}
+	}
+	return applied, nil
+}
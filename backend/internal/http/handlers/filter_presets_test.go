@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+func presetArgs(t *testing.T, raw string) valuesArgs {
+	t.Helper()
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		t.Fatalf("parse %q: %v", raw, err)
+	}
+	return valuesArgs(values)
+}
+
+func TestFilterPresetParamsRoundTripThroughTheQueryString(t *testing.T) {
+	queries := []string{
+		"",
+		"status=all",
+		"q=solo+leveling&status=reading&sort=rating",
+		"status=completed&tags=favorite&tags=priority&sites=3&sites=1",
+		"tags=favorite|priority,-stale&tags=  action &sites=2,2,x",
+		"tags=caf%C3%A9&tags=-dropped&q=%C3%A9t%C3%A9&sort=last_read_at",
+	}
+	for _, raw := range queries {
+		params := filterPresetParamsFromArgs(presetArgs(t, raw))
+		query := filterPresetQuery(params)
+		if again := filterPresetParamsFromArgs(presetArgs(t, query)); !reflect.DeepEqual(again, params) {
+			t.Fatalf("%q: expected %+v back from %q, got %+v", raw, params, query, again)
+		}
+		if again := filterPresetQuery(filterPresetParamsFromArgs(presetArgs(t, query))); again != query {
+			t.Fatalf("%q: expected the query %q to encode the same, got %q", raw, query, again)
+		}
+
+		want := trackerListOptionsFromArgs(presetArgs(t, raw), 1)
+		if got := trackerListOptionsFromArgs(presetArgs(t, query), 1); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%q: expected the saved query %q to list like the original, got %+v want %+v", raw, query, got, want)
+		}
+	}
+
+	params := filterPresetParamsFromArgs(presetArgs(t, "status=all&tags=favorite|priority,-stale&sites=2,2"))
+	want := models.FilterPresetParams{Status: "all", Tags: []string{"favorite|priority", "-stale"}, Sites: []int64{2}}
+	if !reflect.DeepEqual(params, want) {
+		t.Fatalf("expected one term per tag filter and each site once, got %+v", params)
+	}
+}
+
+func TestApplyFilterPresetSkipsDeletedTagsAndDisabledSites(t *testing.T) {
+	db, h := setupInternalDashboardHandler(t, nil)
+	ctx := context.Background()
+
+	for _, name := range []string{"Favorite", "-spoilers"} {
+		if _, err := h.trackerRepo.CreateProfileTag(ctx, 1, name, nil); err != nil {
+			t.Fatalf("create tag %s: %v", name, err)
+		}
+	}
+	var enabledID, disabledID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&enabledID); err != nil {
+		t.Fatalf("load mangadex: %v", err)
+	}
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangafire'`).Scan(&disabledID); err != nil {
+		t.Fatalf("load mangafire: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE sources SET enabled = 0 WHERE id = ?`, disabledID); err != nil {
+		t.Fatalf("disable mangafire: %v", err)
+	}
+	enabledSources, err := h.sourceRepo.ListEnabled(ctx)
+	if err != nil {
+		t.Fatalf("list sources: %v", err)
+	}
+
+	params := models.FilterPresetParams{
+		Query:  "tower",
+		Status: "all",
+		Tags:   []string{"favorite|gone", "deleted", "-stale", "-spoilers"},
+		Sites:  []int64{disabledID, enabledID},
+	}
+	applied, err := applyFilterPreset(ctx, h.trackerRepo, 1, enabledSources, params)
+	if err != nil {
+		t.Fatalf("apply preset: %v", err)
+	}
+
+	want := models.FilterPresetParams{
+		Query:  "tower",
+		Status: "all",
+		Tags:   []string{"favorite", "-spoilers"},
+		Sites:  []int64{enabledID},
+	}
+	if !reflect.DeepEqual(applied.Params, want) {
+		t.Fatalf("expected the filters that still apply, got %+v", applied.Params)
+	}
+	if !reflect.DeepEqual(applied.IgnoredTags, []string{"gone", "deleted", "stale"}) {
+		t.Fatalf("expected the deleted tags reported, got %v", applied.IgnoredTags)
+	}
+	if !reflect.DeepEqual(applied.IgnoredSites, []int64{disabledID}) {
+		t.Fatalf("expected the disabled site reported, got %v", applied.IgnoredSites)
+	}
+	if got := filterPresetQuery(applied.Params); got != "q=tower&sites="+strconv.FormatInt(enabledID, 10)+"&status=all&tags=favorite&tags=-spoilers" {
+		t.Fatalf("unexpected applied query %q", got)
+	}
+
+	// The stored preset itself is left as saved.
+	if !reflect.DeepEqual(params.Tags, []string{"favorite|gone", "deleted", "-stale", "-spoilers"}) {
+		t.Fatalf("expected the preset's own params untouched, got %+v", params)
+	}
+}
@@ -16,6 +16,16 @@ import (
 
 func setupTestApp(t *testing.T) (*sql.DB, *fiber.App, func()) {
 	t.Helper()
+	return setupTestAppWithConfig(t, config.Config{AppName: "test-app"})
+}
+
+func setupTestAppWithConfig(t *testing.T, cfg config.Config) (*sql.DB, *fiber.App, func()) {
+	t.Helper()
+	return setupTestAppWithServer(t, cfg, apihttp.NewServer)
+}
+
+func setupTestAppWithServer(t *testing.T, cfg config.Config, newServer func(config.Config, *sql.DB) *fiber.App) (*sql.DB, *fiber.App, func()) {
+	t.Helper()
 
 	tmpDir := t.TempDir()
 	dbPath := filepath.Join(tmpDir, "test.sqlite")
@@ -50,8 +60,7 @@ func setupTestApp(t *testing.T) (*sql.DB, *fiber.App, func()) {
 		t.Fatalf("seed defaults: %v", err)
 	}
 
-	cfg := config.Config{AppName: "test-app"}
-	app := apihttp.NewServer(cfg, db)
+	app := newServer(cfg, db)
 
 	cleanup := func() {
 		_ = app.Shutdown()
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	hxTriggerHeader          = "HX-Trigger"
+	hxTriggerAfterSwapHeader = "HX-Trigger-After-Swap"
+)
+
+// setHXTrigger adds events to the response's HX-Trigger header, which htmx
+// fires as soon as the response arrives. Events set earlier in the request
+// are kept; a later value for the same event replaces the earlier one.
+func setHXTrigger(c *fiber.Ctx, events map[string]any) {
+	mergeHXTriggerHeader(c, hxTriggerHeader, events)
+}
+
+// setHXTriggerAfterSwap is setHXTrigger for events that must fire once the
+// response has been swapped into the page.
+func setHXTriggerAfterSwap(c *fiber.Ctx, events map[string]any) {
+	mergeHXTriggerHeader(c, hxTriggerAfterSwapHeader, events)
+}
+
+func mergeHXTriggerHeader(c *fiber.Ctx, header string, events map[string]any) {
+	if len(events) == 0 {
+		return
+	}
+
+	merged := parseHXTriggerHeader(c.GetRespHeader(header))
+	for name, detail := range events {
+		merged[name] = detail
+	}
+	encoded, err := json.Marshal(merged)
+	if err != nil {
+		slog.Warn("encode htmx trigger failed", "header", header, "error", err)
+		return
+	}
+	c.Set(header, string(encoded))
+}
+
+// parseHXTriggerHeader reads a trigger header back into events. Besides
+// JSON it accepts htmx's plain comma-separated list of event names.
+func parseHXTriggerHeader(value string) map[string]any {
+	events := make(map[string]any)
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return events
+	}
+	if strings.HasPrefix(value, "{") {
+		if err := json.Unmarshal([]byte(value), &events); err != nil {
+			slog.Warn("drop unreadable htmx trigger", "value", value, "error", err)
+			return make(map[string]any)
+		}
+		return events
+	}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			events[name] = true
+		}
+	}
+	return events
+}
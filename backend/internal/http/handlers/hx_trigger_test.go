@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// hxTriggerHeaders runs handle in a request and returns the response's
+// HX-Trigger and HX-Trigger-After-Swap headers.
+func hxTriggerHeaders(t *testing.T, handle func(c *fiber.Ctx)) (string, string) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		handle(c)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return res.Header.Get(hxTriggerHeader), res.Header.Get(hxTriggerAfterSwapHeader)
+}
+
+func decodeHXTrigger(t *testing.T, value string) map[string]any {
+	t.Helper()
+
+	var events map[string]any
+	if err := json.Unmarshal([]byte(value), &events); err != nil {
+		t.Fatalf("expected valid JSON in %q: %v", value, err)
+	}
+	return events
+}
+
+func TestSetHXTriggerMergesEvents(t *testing.T) {
+	trigger, afterSwap := hxTriggerHeaders(t, func(c *fiber.Ctx) {
+		setHXTrigger(c, map[string]any{"trackersChanged": true, "trackerMoved": false})
+		setHXTrigger(c, map[string]any{"trackerMoved": true, "trackerCreated": map[string]any{"id": 7}})
+		setHXTrigger(c, nil)
+	})
+	if trigger != `{"trackerCreated":{"id":7},"trackerMoved":true,"trackersChanged":true}` {
+		t.Fatalf("expected merged events with the later value winning, got %q", trigger)
+	}
+	if afterSwap != "" {
+		t.Fatalf("expected no after-swap header, got %q", afterSwap)
+	}
+}
+
+func TestSetHXTriggerEscapesStrings(t *testing.T) {
+	name := `Say "hi", \ then leave`
+	trigger, _ := hxTriggerHeaders(t, func(c *fiber.Ctx) {
+		setHXTrigger(c, map[string]any{"tagsIgnored": map[string]any{"names": []string{name}}})
+	})
+	events := decodeHXTrigger(t, trigger)
+	detail, _ := events["tagsIgnored"].(map[string]any)
+	names, _ := detail["names"].([]any)
+	if len(names) != 1 || names[0] != name {
+		t.Fatalf("expected the tag name to round-trip, got %v", events)
+	}
+}
+
+func TestSetHXTriggerAfterSwapUsesItsOwnHeader(t *testing.T) {
+	trigger, afterSwap := hxTriggerHeaders(t, func(c *fiber.Ctx) {
+		setHXTrigger(c, map[string]any{"trackersChanged": true})
+		setHXTriggerAfterSwap(c, map[string]any{"trackerMoved": true})
+		setHXTriggerAfterSwap(c, map[string]any{"trackerCreated": map[string]any{"id": 3}})
+	})
+	if trigger != `{"trackersChanged":true}` {
+		t.Fatalf("expected HX-Trigger left alone, got %q", trigger)
+	}
+	if afterSwap != `{"trackerCreated":{"id":3},"trackerMoved":true}` {
+		t.Fatalf("expected merged after-swap events, got %q", afterSwap)
+	}
+}
+
+func TestParseHXTriggerHeaderReadsEventNames(t *testing.T) {
+	events := parseHXTriggerHeader("trackersChanged, profileTagsChanged")
+	if len(events) != 2 || events["trackersChanged"] != true || events["profileTagsChanged"] != true {
+		t.Fatalf("expected both names as events, got %v", events)
+	}
+	if events := parseHXTriggerHeader(`{"broken":`); len(events) != 0 {
+		t.Fatalf("expected unreadable JSON dropped, got %v", events)
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/mangadexsync"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+const mangaDexSyncRunsShown = 10
+
+type mangaDexLinkRequest struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	SyncEnabled *bool  `json:"syncEnabled"`
+}
+
+type MangaDexHandler struct {
+	repo            *repository.MangaDexSyncRepository
+	job             *mangadexsync.Job
+	profileResolver *profileContextResolver
+}
+
+// NewMangaDexHandler builds the MangaDex integration API. A nil job means
+// no MangaDex API client is configured: the link can still be read and
+// removed, but linking and syncing report that instead.
+func NewMangaDexHandler(db *sql.DB, job *mangadexsync.Job) *MangaDexHandler {
+	return &MangaDexHandler{
+		repo:            repository.NewMangaDexSyncRepository(db),
+		job:             job,
+		profileResolver: newProfileContextResolver(db),
+	}
+}
+
+func (h *MangaDexHandler) Get(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	link, err := h.repo.GetLink(c.UserContext(), profile.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load mangadex link", err)
+	}
+	runs, err := h.repo.ListRuns(c.UserContext(), profile.ID, mangaDexSyncRunsShown)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load mangadex sync log", err)
+	}
+
+	return c.JSON(fiber.Map{"configured": h.job != nil, "link": link, "runs": runs})
+}
+
+// Link links the profile to a MangaDex account when a username and password
+// are given. With only syncEnabled it toggles the background sync of the
+// existing link.
+func (h *MangaDexHandler) Link(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	var req mangaDexLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid json body"})
+	}
+	username := strings.TrimSpace(req.Username)
+
+	if username == "" && req.Password == "" {
+		if req.SyncEnabled == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "username and password, or syncEnabled, are required"})
+		}
+		updated, err := h.repo.SetSyncEnabled(c.UserContext(), profile.ID, *req.SyncEnabled)
+		if err != nil {
+			return serverErrorJSON(c, "failed to update mangadex sync", err)
+		}
+		if !updated {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": mangadexsync.ErrNotLinked.Error()})
+		}
+		return h.respondWithLink(c, profile.ID)
+	}
+
+	if username == "" || req.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "username and password are required"})
+	}
+	if h.job == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"message": "mangadex is not configured"})
+	}
+
+	syncEnabled := true
+	if req.SyncEnabled != nil {
+		syncEnabled = *req.SyncEnabled
+	}
+	if err := h.job.Link(c.UserContext(), profile.ID, username, req.Password, syncEnabled); err != nil {
+		if errors.Is(err, mangadexsync.ErrInvalidCredentials) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": err.Error()})
+		}
+		requestLogger(c).Warn("mangadex link failed", "profileId", profile.ID, "error", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"message": "failed to link mangadex account"})
+	}
+	return h.respondWithLink(c, profile.ID)
+}
+
+func (h *MangaDexHandler) Unlink(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	deleted, err := h.repo.DeleteLink(c.UserContext(), profile.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to unlink mangadex account", err)
+	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": mangadexsync.ErrNotLinked.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Sync pulls the profile's MangaDex read progress now and returns the run
+// it logged.
+func (h *MangaDexHandler) Sync(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	if h.job == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"message": "mangadex is not configured"})
+	}
+
+	run, err := h.job.SyncProfile(c.UserContext(), profile.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, mangadexsync.ErrNotLinked):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": err.Error()})
+		case errors.Is(err, connectors.ErrScrapingPaused):
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"message": err.Error()})
+		case errors.Is(err, mangadexsync.ErrInvalidCredentials):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"message": "mangadex rejected the stored login, link the account again", "run": run})
+		}
+		requestLogger(c).Warn("mangadex sync failed", "profileId", profile.ID, "error", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"message": "mangadex sync failed", "run": run})
+	}
+	return c.JSON(fiber.Map{"run": run})
+}
+
+func (h *MangaDexHandler) respondWithLink(c *fiber.Ctx, profileID int64) error {
+	link, err := h.repo.GetLink(c.UserContext(), profileID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load mangadex link", err)
+	}
+	return c.JSON(fiber.Map{"link": link})
+}
@@ -0,0 +1,77 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMangaDexIntegrationWithoutAPIClient(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/integrations/mangadex?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("get integration request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	var payload struct {
+		Configured bool            `json:"configured"`
+		Link       json.RawMessage `json:"link"`
+		Runs       []any           `json:"runs"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode integration: %v", err)
+	}
+	if payload.Configured || string(payload.Link) != "null" || len(payload.Runs) != 0 {
+		t.Fatalf("expected an unconfigured, unlinked integration, got %+v", payload)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodPost, "/v1/integrations/mangadex/sync?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("sync request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without an api client, got %d", res.StatusCode)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/integrations/mangadex?profile=profile1", strings.NewReader(`{"syncEnabled":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("toggle request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 toggling an unlinked profile, got %d", res.StatusCode)
+	}
+
+	if _, err := db.Exec(`INSERT INTO profile_mangadex_links (profile_id, username, sealed_refresh_token) VALUES (1, 'reader', 'sealed')`); err != nil {
+		t.Fatalf("seed link: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPut, "/v1/integrations/mangadex?profile=profile1", strings.NewReader(`{"syncEnabled":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("toggle request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), `"syncEnabled":false`) {
+		t.Fatalf("expected the toggle to turn sync off, got %d (body: %s)", res.StatusCode, string(body))
+	}
+	if strings.Contains(string(body), "sealed") {
+		t.Fatalf("expected the sealed token to stay out of the response, got %s", string(body))
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodDelete, "/v1/integrations/mangadex?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("unlink request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 unlinking, got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/gabriel/cross-site-tracker/backend/internal/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+// OpenAPIHandler serves the hand-maintained description of the /v1 API.
+type OpenAPIHandler struct {
+	basePath string
+}
+
+func NewOpenAPIHandler(basePath string) *OpenAPIHandler {
+	return &OpenAPIHandler{basePath: basePath}
+}
+
+func (h *OpenAPIHandler) Get(c *fiber.Ctx) error {
+	document, err := openapi.JSON(h.basePath)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load the openapi document", err)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(document)
+}
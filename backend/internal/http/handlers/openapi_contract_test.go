@@ -0,0 +1,236 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+// contractClient sends requests to the app and checks every response
+// against the operation the spec documents for it, recording which
+// operations were exercised.
+type contractClient struct {
+	t         *testing.T
+	app       *fiber.App
+	doc       *openapi.Document
+	exercised map[string]bool
+}
+
+// do sends method to target, documented as the template path, and expects
+// status. The response must match the spec either way.
+func (c *contractClient) do(method string, template string, target string, body string, status int, headers ...string) (http.Header, map[string]any) {
+	c.t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	req.Header.Set("Content-Type", "application/json")
+	for index := 0; index+1 < len(headers); index += 2 {
+		req.Header.Set(headers[index], headers[index+1])
+	}
+	res, err := c.app.Test(req, -1)
+	if err != nil {
+		c.t.Fatalf("%s %s failed: %v", method, target, err)
+	}
+	raw, _ := io.ReadAll(res.Body)
+	c.exercised[method+" "+template] = true
+
+	if err := c.doc.ValidateResponse(method, template, res.StatusCode, raw); err != nil {
+		c.t.Errorf("%s: %v", target, err)
+	}
+	if res.StatusCode != status {
+		c.t.Fatalf("%s %s: expected %d, got %d: %s", method, target, status, res.StatusCode, raw)
+	}
+	payload := map[string]any{}
+	if len(bytes.TrimSpace(raw)) > 0 && raw[0] == '{' {
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			c.t.Fatalf("decode %s %s response %q: %v", method, target, raw, err)
+		}
+	}
+	return res.Header, payload
+}
+
+func idOf(t *testing.T, payload map[string]any) string {
+	t.Helper()
+	id, ok := payload["id"].(float64)
+	if !ok {
+		t.Fatalf("expected an id in %v", payload)
+	}
+	return toString(int(id))
+}
+
+var routeParam = regexp.MustCompile(`:([A-Za-z]+)`)
+
+func TestOpenAPIContract(t *testing.T) {
+	_, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", BackupEnabled: true, BackupDir: t.TempDir(), BackupKeepCount: 5})
+	defer cleanup()
+
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	client := &contractClient{t: t, app: app, doc: doc, exercised: map[string]bool{}}
+
+	client.do(http.MethodGet, "/health", "/health", "", http.StatusOK)
+	client.do(http.MethodGet, "/v1/health", "/v1/health", "", http.StatusOK)
+	_, served := client.do(http.MethodGet, "/v1/openapi.json", "/v1/openapi.json", "", http.StatusOK)
+	if served["openapi"] == nil {
+		t.Fatalf("expected the served document, got %v", served)
+	}
+
+	client.do(http.MethodGet, "/v1/connectors", "/v1/connectors", "", http.StatusOK)
+	// The deep check reaches the live sites, so it is exercised while
+	// scraping is paused.
+	client.do(http.MethodPost, "/v1/settings/scraping-paused", "/v1/settings/scraping-paused", `{"paused":true}`, http.StatusOK)
+	client.do(http.MethodGet, "/v1/connectors/health", "/v1/connectors/health?deep=1", "", http.StatusServiceUnavailable)
+	client.do(http.MethodPost, "/v1/settings/scraping-paused", "/v1/settings/scraping-paused", `{"paused":false}`, http.StatusOK)
+	client.do(http.MethodGet, "/v1/settings/scraping-paused", "/v1/settings/scraping-paused", "", http.StatusOK)
+
+	client.do(http.MethodGet, "/v1/sources", "/v1/sources", "", http.StatusOK)
+	client.do(http.MethodPut, "/v1/sources/{id}/note", "/v1/sources/1/note", `{"note":"Chapters arrive late"}`, http.StatusOK)
+	client.do(http.MethodPut, "/v1/sources/{id}/note", "/v1/sources/999/note", `{"note":""}`, http.StatusNotFound)
+	client.do(http.MethodGet, "/v1/sources/{id}/search", "/v1/sources/6/search?q=solo", "", http.StatusUnprocessableEntity)
+
+	trackerBody := `{"title":"Blue Lock","sourceId":1,"sourceUrl":"https://asuracomic.net/series/blue-lock-1","status":"reading","lastReadChapter":2,"latestKnownChapter":3}`
+	_, tracker := client.do(http.MethodPost, "/v1/trackers", "/v1/trackers", trackerBody, http.StatusCreated)
+	trackerID := idOf(t, tracker)
+	client.do(http.MethodPost, "/v1/trackers", "/v1/trackers", `{"title":"","sourceId":1,"sourceUrl":"https://asuracomic.net/series/x","status":"reading"}`, http.StatusBadRequest)
+	client.do(http.MethodPost, "/v1/trackers", "/v1/trackers?profile=profile2", trackerBody, http.StatusCreated)
+
+	client.do(http.MethodGet, "/v1/trackers", "/v1/trackers", "", http.StatusOK)
+	client.do(http.MethodGet, "/v1/trackers", "/v1/trackers?limit=1", "", http.StatusOK)
+	client.do(http.MethodGet, "/v1/trackers", "/v1/trackers?page=1&tags=missing", "", http.StatusOK)
+	client.do(http.MethodGet, "/v1/trackers/{id}", "/v1/trackers/"+trackerID, "", http.StatusOK)
+	client.do(http.MethodGet, "/v1/trackers/{id}", "/v1/trackers/999", "", http.StatusNotFound)
+	client.do(http.MethodPut, "/v1/trackers/{id}", "/v1/trackers/"+trackerID, strings.Replace(trackerBody, `"lastReadChapter":2`, `"lastReadChapter":3`, 1), http.StatusOK)
+	client.do(http.MethodPost, "/v1/trackers/{id}/reread", "/v1/trackers/"+trackerID+"/reread", "", http.StatusConflict)
+	client.do(http.MethodPut, "/v1/trackers/{id}", "/v1/trackers/"+trackerID, strings.Replace(trackerBody, `"status":"reading"`, `"status":"completed"`, 1), http.StatusOK)
+	_, reread := client.do(http.MethodPost, "/v1/trackers/{id}/reread", "/v1/trackers/"+trackerID+"/reread", "", http.StatusOK)
+	if reread["rereading"] != true || reread["lastReadChapter"] != float64(0) {
+		t.Fatalf("expected the tracker to be re-read from chapter 0, got %v", reread)
+	}
+	client.do(http.MethodPost, "/v1/trackers/{id}/reread", "/v1/trackers/999/reread", "", http.StatusNotFound)
+
+	_, tag := client.do(http.MethodPost, "/v1/tags", "/v1/tags", `{"name":"Favorites","iconKey":"icon_2"}`, http.StatusCreated)
+	tagID := idOf(t, tag)
+	client.do(http.MethodPost, "/v1/tags", "/v1/tags", `{"name":"Favorites"}`, http.StatusConflict)
+	client.do(http.MethodGet, "/v1/tags", "/v1/tags", "", http.StatusOK)
+	client.do(http.MethodPut, "/v1/tags/{id}", "/v1/tags/"+tagID, `{"name":"Top Picks"}`, http.StatusOK)
+	_, tagged := client.do(http.MethodPut, "/v1/trackers/{id}/tags", "/v1/trackers/"+trackerID+"/tags", "["+tagID+"]", http.StatusOK)
+	if tags, _ := tagged["tags"].([]any); len(tags) != 1 {
+		t.Fatalf("expected the tag on the tracker, got %v", tagged["tags"])
+	}
+
+	_, preset := client.do(http.MethodPost, "/v1/filter-presets", "/v1/filter-presets", `{"name":"Picks","query":"status=all&tags=Top+Picks&tags=Gone"}`, http.StatusCreated)
+	presetID := idOf(t, preset)
+	client.do(http.MethodPost, "/v1/filter-presets", "/v1/filter-presets", `{"name":"picks","query":""}`, http.StatusConflict)
+	client.do(http.MethodGet, "/v1/filter-presets", "/v1/filter-presets", "", http.StatusOK)
+	client.do(http.MethodPut, "/v1/filter-presets/{id}", "/v1/filter-presets/"+presetID, `{"name":"Best"}`, http.StatusOK)
+	_, applied := client.do(http.MethodGet, "/v1/filter-presets/{id}/apply", "/v1/filter-presets/"+presetID+"/apply", "", http.StatusOK)
+	if applied["query"] != "status=all&tags=Top+Picks" {
+		t.Fatalf("expected the deleted tag left out, got %v", applied)
+	}
+	client.do(http.MethodDelete, "/v1/filter-presets/{id}", "/v1/filter-presets/"+presetID, "", http.StatusNoContent)
+	client.do(http.MethodGet, "/v1/filter-presets/{id}/apply", "/v1/filter-presets/"+presetID+"/apply", "", http.StatusNotFound)
+
+	// The first card request queues the cover and chapter link lookups, and
+	// their results change the card, so the ETag is compared once they land.
+	headers, card := client.do(http.MethodGet, "/v1/trackers/{id}/card", "/v1/trackers/"+trackerID+"/card", "", http.StatusOK)
+	for deadline := time.Now().Add(5 * time.Second); card["coverPending"] == true || card["latestKnownChapterUrlPending"] == true || card["lastReadChapterUrlPending"] == true; {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the card's background lookups to finish, got %v", card)
+		}
+		time.Sleep(20 * time.Millisecond)
+		headers, card = client.do(http.MethodGet, "/v1/trackers/{id}/card", "/v1/trackers/"+trackerID+"/card", "", http.StatusOK)
+	}
+	client.do(http.MethodGet, "/v1/trackers/{id}/card", "/v1/trackers/"+trackerID+"/card", "", http.StatusNotModified, fiber.HeaderIfNoneMatch, headers.Get(fiber.HeaderETag))
+	client.do(http.MethodGet, "/v1/trackers/{id}/reading-history", "/v1/trackers/"+trackerID+"/reading-history", "", http.StatusOK)
+	_, cadence := client.do(http.MethodGet, "/v1/trackers/{id}/cadence", "/v1/trackers/"+trackerID+"/cadence", "", http.StatusOK)
+	if months, _ := cadence["months"].([]any); len(months) != 12 {
+		t.Fatalf("expected twelve months of releases, got %v", cadence)
+	}
+	client.do(http.MethodGet, "/v1/trackers/{id}/cadence", "/v1/trackers/999/cadence", "", http.StatusNotFound)
+	_, explained := client.do(http.MethodGet, "/v1/trackers/{id}/explain", "/v1/trackers/"+trackerID+"/explain?status=reading&tags=Top+Picks&sites=1", "", http.StatusOK)
+	if explained["listed"] != true {
+		t.Fatalf("expected the re-read tracker in the reading list, got %v", explained)
+	}
+	client.do(http.MethodGet, "/v1/trackers/{id}/explain", "/v1/trackers/999/explain", "", http.StatusNotFound)
+	client.do(http.MethodGet, "/v1/trackers/release-schedule", "/v1/trackers/release-schedule", "", http.StatusOK)
+	client.do(http.MethodGet, "/v1/tracker-sources", "/v1/tracker-sources?sourceId=1&role=primary", "", http.StatusOK)
+	client.do(http.MethodGet, "/v1/tracker-sources", "/v1/tracker-sources?sourceId=abc", "", http.StatusBadRequest)
+	client.do(http.MethodGet, "/v1/stats", "/v1/stats", "", http.StatusOK)
+	_, overlap := client.do(http.MethodGet, "/v1/overlap", "/v1/overlap?profiles=profile1,profile2", "", http.StatusOK)
+	if items, _ := overlap["items"].([]any); len(items) != 1 {
+		t.Fatalf("expected the shared series in the overlap, got %v", overlap)
+	}
+	client.do(http.MethodGet, "/v1/overlap", "/v1/overlap", "", http.StatusBadRequest)
+
+	_, current := client.do(http.MethodGet, "/v1/resolve-url", "/v1/resolve-url?url=HTTP://asuracomic.net/Series/blue-lock-1/", "", http.StatusOK)
+	if current["matchedBy"] != "source_url" || current["reason"] != nil {
+		t.Fatalf("expected the current URL resolved, got %v", current)
+	}
+	client.do(http.MethodPut, "/v1/trackers/{id}", "/v1/trackers/"+trackerID, strings.Replace(trackerBody, "blue-lock-1", "blue-lock-2", 1), http.StatusOK)
+	_, moved := client.do(http.MethodGet, "/v1/resolve-url", "/v1/resolve-url?url=https://asuracomic.net/series/blue-lock-1", "", http.StatusOK)
+	if moved["trackerId"] != current["trackerId"] || moved["sourceUrl"] != "https://asuracomic.net/series/blue-lock-2" {
+		t.Fatalf("expected the old URL resolved to the moved tracker, got %v", moved)
+	}
+	client.do(http.MethodGet, "/v1/resolve-url", "/v1/resolve-url?url=https://asuracomic.net/series/unknown", "", http.StatusNotFound)
+	client.do(http.MethodGet, "/v1/resolve-url", "/v1/resolve-url", "", http.StatusBadRequest)
+
+	client.do(http.MethodPost, "/v1/digests/test", "/v1/digests/test", "", http.StatusServiceUnavailable)
+	client.do(http.MethodGet, "/v1/integrations/mangadex", "/v1/integrations/mangadex", "", http.StatusOK)
+	client.do(http.MethodPut, "/v1/integrations/mangadex", "/v1/integrations/mangadex", `{"username":"reader","password":"secret"}`, http.StatusServiceUnavailable)
+	client.do(http.MethodDelete, "/v1/integrations/mangadex", "/v1/integrations/mangadex", "", http.StatusNotFound)
+	client.do(http.MethodPost, "/v1/integrations/mangadex/sync", "/v1/integrations/mangadex/sync", "", http.StatusServiceUnavailable)
+	client.do(http.MethodGet, "/v1/polling/status", "/v1/polling/status", "", http.StatusOK)
+	client.do(http.MethodPut, "/v1/profile/polling", "/v1/profile/polling?profile=profile2", `{"enabled":false}`, http.StatusOK)
+	client.do(http.MethodPut, "/v1/profile/polling", "/v1/profile/polling", `{}`, http.StatusBadRequest)
+	client.do(http.MethodGet, "/v1/profile/polling", "/v1/profile/polling?profile=profile2", "", http.StatusOK)
+	client.do(http.MethodGet, "/v1/profile/polling", "/v1/profile/polling?profile=missing", "", http.StatusBadRequest)
+	client.do(http.MethodPost, "/v1/admin/backup", "/v1/admin/backup", "", http.StatusCreated)
+	client.do(http.MethodGet, "/v1/admin/backups", "/v1/admin/backups", "", http.StatusOK)
+
+	client.do(http.MethodDelete, "/v1/tags/{id}", "/v1/tags/"+tagID, "", http.StatusNoContent)
+	client.do(http.MethodDelete, "/v1/trackers/{id}", "/v1/trackers/"+trackerID, "", http.StatusNoContent)
+
+	// Every API route is documented, and every documented operation ran.
+	routed := map[string]bool{}
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == http.MethodHead || !(strings.HasPrefix(route.Path, "/v1/") || route.Path == "/health") {
+			continue
+		}
+		template := routeParam.ReplaceAllString(route.Path, "{$1}")
+		routed[route.Method+" "+template] = true
+		if doc.Operation(route.Method, template) == nil {
+			t.Errorf("%s %s is routed but not in the spec", route.Method, template)
+		}
+	}
+	var missed []string
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			key := strings.ToUpper(method) + " " + path
+			if !routed[key] {
+				t.Errorf("%s is in the spec but not routed", key)
+			}
+			if !client.exercised[key] {
+				missed = append(missed, key)
+			}
+		}
+	}
+	if len(missed) > 0 {
+		sort.Strings(missed)
+		t.Errorf("documented operations the contract test does not exercise: %s", strings.Join(missed, ", "))
+	}
+}
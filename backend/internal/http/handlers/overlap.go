@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+type overlapEntry struct {
+	Overlap    models.TrackerOverlap
+	DeltaLabel string
+}
+
+type overlapPageData struct {
+	ActiveProfile *models.Profile
+	Other         *models.Profile
+	// Profiles lists the profiles ActiveProfile can be compared with instead.
+	Profiles []models.Profile
+	Entries  []overlapEntry
+}
+
+// resolveOverlapProfiles reads the two profiles to compare from the
+// profiles query parameter, e.g. "profile1,profile2". Both are required
+// and must differ; there is no per-profile access control to check yet.
+func resolveOverlapProfiles(ctx context.Context, resolver *profileContextResolver, raw string) (*models.Profile, *models.Profile, error) {
+	keys := strings.Split(raw, ",")
+	if len(keys) != 2 || strings.TrimSpace(keys[0]) == "" || strings.TrimSpace(keys[1]) == "" {
+		return nil, nil, fmt.Errorf("profiles must name two profiles, e.g. profiles=profile1,profile2")
+	}
+
+	profiles := make([]*models.Profile, 0, 2)
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		profile, err := resolver.lookup(ctx, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if profile == nil {
+			return nil, nil, fmt.Errorf("invalid profile %q", key)
+		}
+		profiles = append(profiles, profile)
+	}
+	if profiles[0].ID == profiles[1].ID {
+		return nil, nil, fmt.Errorf("profiles must name two different profiles")
+	}
+	return profiles[0], profiles[1], nil
+}
+
+// overlapDeltaLabel describes how far the first profile's reader is from
+// the second's, or "" unless both have read the series.
+func overlapDeltaLabel(delta *float64) string {
+	if delta == nil {
+		return ""
+	}
+	distance := math.Abs(*delta)
+	if distance == 0 {
+		return "You're on the same chapter"
+	}
+	unit := "chapters"
+	if distance == 1 {
+		unit = "chapter"
+	}
+	direction := "ahead of"
+	if *delta < 0 {
+		direction = "behind"
+	}
+	return fmt.Sprintf("You're %s %s %s them", strconv.FormatFloat(distance, 'f', -1, 64), unit, direction)
+}
+
+// Overlap returns the trackers the two profiles named by profiles have in
+// common, matched by title or by a shared source link.
+func (h *TrackersHandler) Overlap(c *fiber.Ctx) error {
+	left, right, err := resolveOverlapProfiles(c.UserContext(), h.profileResolver, c.Query("profiles"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	items, err := h.repo.ListProfileOverlap(c.UserContext(), left.ID, right.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to list profile overlap", err)
+	}
+
+	return c.JSON(fiber.Map{"items": items})
+}
+
+// OverlapPage renders the overlap between two profiles as seen from the
+// first, with how far ahead or behind its reader is on each series.
+func (h *DashboardHandler) OverlapPage(c *fiber.Ctx) error {
+	left, right, err := resolveOverlapProfiles(c.UserContext(), h.profileResolver, c.Query("profiles"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	items, err := h.trackerRepo.ListProfileOverlap(c.UserContext(), left.ID, right.ID)
+	if err != nil {
+		return serverError(c, "Failed to list profile overlap", err)
+	}
+	profiles, err := h.profileResolver.ListProfiles(c.UserContext())
+	if err != nil {
+		return serverError(c, "Failed to load profiles", err)
+	}
+
+	data := overlapPageData{ActiveProfile: left, Other: right, Entries: make([]overlapEntry, 0, len(items))}
+	for _, profile := range profiles {
+		if profile.ID != left.ID && profile.ID != right.ID {
+			data.Profiles = append(data.Profiles, profile)
+		}
+	}
+	for _, item := range items {
+		data.Entries = append(data.Entries, overlapEntry{Overlap: item, DeltaLabel: overlapDeltaLabel(item.ChapterDelta)})
+	}
+
+	c.Set("Cache-Control", "no-store, no-cache, must-revalidate")
+	return h.render(c, "overlap_page.html", data)
+}
@@ -0,0 +1,99 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOverlapPairsTrackersAcrossProfiles(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if _, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES
+			(1, 'Shared Blade', 1, 'https://asuracomic.net/series/shared-blade', 'reading', 50, 60),
+			(2, 'shared blade', 1, 'https://asuracomic.net/series/shared-blade-other', 'reading', 20, 60),
+			(1, 'Solo Tower', 1, 'https://asuracomic.net/series/solo-tower', 'reading', 3, 9),
+			(2, 'Lonely Tower', 1, 'https://asuracomic.net/series/lonely-tower', 'on_hold', 1, 4),
+			(1, 'Renamed Here', 1, 'https://asuracomic.net/series/renamed', 'reading', 7, 9),
+			(2, 'Renamed There', 1, 'https://asuracomic.net/series/renamed/', 'reading', 7, 9)
+	`); err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+
+	for _, query := range []string{"", "?profiles=profile1", "?profiles=profile1,profile1", "?profiles=profile1,nobody"} {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/overlap"+query, nil))
+		if err != nil {
+			t.Fatalf("overlap request failed: %v", err)
+		}
+		if res.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %q, got %d", query, res.StatusCode)
+		}
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/overlap?profiles=profile1,profile2", nil))
+	if err != nil {
+		t.Fatalf("overlap request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	var payload struct {
+		Items []struct {
+			Left struct {
+				Title           string   `json:"title"`
+				Status          string   `json:"status"`
+				LastReadChapter *float64 `json:"lastReadChapter"`
+			} `json:"left"`
+			Right struct {
+				Title           string   `json:"title"`
+				LastReadChapter *float64 `json:"lastReadChapter"`
+			} `json:"right"`
+			MatchedBy    []string `json:"matchedBy"`
+			ChapterDelta *float64 `json:"chapterDelta"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode overlap: %v", err)
+	}
+	got := payload.Items
+	if len(got) != 2 {
+		t.Fatalf("expected two overlapping series, got %+v", got)
+	}
+	if got[0].Left.Title != "Renamed Here" || got[0].Right.Title != "Renamed There" || strings.Join(got[0].MatchedBy, ",") != "source_url" || *got[0].ChapterDelta != 0 {
+		t.Fatalf("expected the renamed series matched by source link, got %+v", got[0])
+	}
+	if got[1].Left.Title != "Shared Blade" || got[1].Left.Status != "reading" || *got[1].Right.LastReadChapter != 20 || strings.Join(got[1].MatchedBy, ",") != "title" || *got[1].ChapterDelta != 30 {
+		t.Fatalf("expected the shared title 30 chapters ahead, got %+v", got[1])
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/overlap?profiles=profile2,profile1", nil))
+	if err != nil {
+		t.Fatalf("overlap page request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from the overlap page, got %d", res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+	html := string(body)
+	if !strings.Contains(html, "You&#39;re 30 chapters behind them") || !strings.Contains(html, "You&#39;re on the same chapter") {
+		t.Fatalf("expected chapter deltas from profile2's side, got %s", html)
+	}
+	if strings.Contains(html, "Solo Tower") || strings.Contains(html, "Lonely Tower") {
+		t.Fatalf("expected series only one profile tracks to be left out, got %s", html)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("dashboard request failed: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	if !strings.Contains(string(body), "/dashboard/overlap?profiles=profile1,profile2") {
+		t.Fatalf("expected the dashboard to link the overlap with the other profile, got %s", string(body))
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+const pageContextLocalKey = "dashboardPageContext"
+
+// pageError is what pageContext fails with: the status and message sent
+// back, and the cause logged when it is a server error.
+type pageError struct {
+	status  int
+	message string
+	err     error
+}
+
+func (e *pageError) Error() string {
+	if e.err == nil {
+		return e.message
+	}
+	return e.message + ": " + e.err.Error()
+}
+
+func (e *pageError) Unwrap() error {
+	return e.err
+}
+
+// sendPageError answers a dashboard request whose pageContext failed.
+// Errors from anywhere else are reported as server errors.
+func sendPageError(c *fiber.Ctx, err error) error {
+	var pageErr *pageError
+	if !errors.As(err, &pageErr) {
+		return serverError(c, "Failed to load page", err)
+	}
+	if pageErr.status >= fiber.StatusInternalServerError {
+		return serverError(c, pageErr.message, pageErr.err)
+	}
+	return c.Status(pageErr.status).SendString(pageErr.message)
+}
+
+// sendPageErrorJSON is sendPageError for the JSON API, whose messages
+// start in lower case.
+func sendPageErrorJSON(c *fiber.Ctx, err error) error {
+	var pageErr *pageError
+	if !errors.As(err, &pageErr) {
+		return serverErrorJSON(c, "failed to load page", err)
+	}
+	message := lowerFirst(pageErr.message)
+	if pageErr.status >= fiber.StatusInternalServerError {
+		return serverErrorJSON(c, message, pageErr.err)
+	}
+	return c.Status(pageErr.status).JSON(fiber.Map{"message": message})
+}
+
+func lowerFirst(message string) string {
+	first, size := utf8.DecodeRuneInString(message)
+	return string(unicode.ToLower(first)) + message[size:]
+}
+
+// memo holds a value loaded at most once.
+type memo[T any] struct {
+	loaded bool
+	value  T
+	err    error
+}
+
+func (m *memo[T]) get(load func() (T, error)) (T, error) {
+	if !m.loaded {
+		m.value, m.err = load()
+		m.loaded = true
+	}
+	return m.value, m.err
+}
+
+// pageContext is the active profile of a dashboard request and the
+// collections most dashboard pages show. Each collection is loaded the
+// first time it is asked for and kept for the rest of the request, so
+// handlers and the helpers they call can each ask for what they need.
+type pageContext struct {
+	h       *DashboardHandler
+	c       *fiber.Ctx
+	profile *models.Profile
+
+	profiles    memo[[]models.Profile]
+	profileTags memo[[]models.CustomTag]
+	sources     memo[[]models.Source]
+	sourceByID  memo[map[int64]models.Source]
+	sourceLogos memo[map[int64]string]
+	blacklisted memo[map[int64]bool]
+}
+
+// pageContext returns the request's page context, resolving the active
+// profile the first time it is called.
+func (h *DashboardHandler) pageContext(c *fiber.Ctx) (*pageContext, error) {
+	if pageCtx, ok := c.Locals(pageContextLocalKey).(*pageContext); ok {
+		return pageCtx, nil
+	}
+
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return nil, &pageError{status: fiber.StatusBadRequest, message: "Invalid profile", err: err}
+	}
+
+	pageCtx := &pageContext{h: h, c: c, profile: profile}
+	c.Locals(pageContextLocalKey, pageCtx)
+	return pageCtx, nil
+}
+
+func loadFailed(message string, err error) error {
+	return &pageError{status: fiber.StatusInternalServerError, message: message, err: err}
+}
+
+// Profiles lists every profile, for the profile switcher.
+func (p *pageContext) Profiles() ([]models.Profile, error) {
+	profiles, err := p.profiles.get(func() ([]models.Profile, error) {
+		return p.h.profileResolver.ListProfiles(p.c.UserContext())
+	})
+	if err != nil {
+		return nil, loadFailed("Failed to load profiles", err)
+	}
+	return profiles, nil
+}
+
+// ProfileTags lists the active profile's tags.
+func (p *pageContext) ProfileTags() ([]models.CustomTag, error) {
+	tags, err := p.profileTags.get(func() ([]models.CustomTag, error) {
+		return p.h.trackerRepo.ListProfileTags(p.c.UserContext(), p.profile.ID)
+	})
+	if err != nil {
+		return nil, loadFailed("Failed to load profile tags", err)
+	}
+	return tags, nil
+}
+
+// Sources lists the enabled sources, for picking one in a form.
+func (p *pageContext) Sources() ([]models.Source, error) {
+	sources, err := p.enabledSources()
+	if err != nil {
+		return nil, loadFailed("Failed to load sources", err)
+	}
+	return sources, nil
+}
+
+// LinkedSites lists the sites the active profile can filter and link by,
+// which are the enabled sources.
+func (p *pageContext) LinkedSites() ([]models.Source, error) {
+	sources, err := p.enabledSources()
+	if err != nil {
+		return nil, loadFailed("Failed to load linked sites", err)
+	}
+	return sources, nil
+}
+
+func (p *pageContext) enabledSources() ([]models.Source, error) {
+	return p.sources.get(func() ([]models.Source, error) {
+		return p.h.sourceRepo.ListEnabled(p.c.UserContext())
+	})
+}
+
+// SourceByID is Sources keyed by source id, for building cards.
+func (p *pageContext) SourceByID() (map[int64]models.Source, error) {
+	sourceByID, err := p.sourceByID.get(func() (map[int64]models.Source, error) {
+		sources, err := p.enabledSources()
+		if err != nil {
+			return nil, err
+		}
+		sourceByID := make(map[int64]models.Source, len(sources))
+		for _, source := range sources {
+			sourceByID[source.ID] = source
+		}
+		return sourceByID, nil
+	})
+	if err != nil {
+		return nil, loadFailed("Failed to load sources", err)
+	}
+	return sourceByID, nil
+}
+
+// SourceLogos maps source ids to the logo the active profile set for them.
+func (p *pageContext) SourceLogos() (map[int64]string, error) {
+	logos, err := p.sourceLogos.get(func() (map[int64]string, error) {
+		return p.h.sourceRepo.ListProfileSourceLogoURLs(p.c.UserContext(), p.profile.ID)
+	})
+	if err != nil {
+		return nil, loadFailed("Failed to load linked site logos", err)
+	}
+	return logos, nil
+}
+
+// ForgetSourceLogos drops the loaded logos after the request changed them.
+func (p *pageContext) ForgetSourceLogos() {
+	p.sourceLogos = memo[map[int64]string]{}
+}
+
+// BlacklistedSources is the set of source ids the active profile no longer
+// wants offered when adding trackers.
+func (p *pageContext) BlacklistedSources() (map[int64]bool, error) {
+	blacklisted, err := p.blacklisted.get(func() (map[int64]bool, error) {
+		return p.h.sourceRepo.ListProfileBlacklistedSourceIDs(p.c.UserContext(), p.profile.ID)
+	})
+	if err != nil {
+		return nil, loadFailed("Failed to load site blacklist", err)
+	}
+	return blacklisted, nil
+}
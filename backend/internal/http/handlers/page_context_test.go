@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestPageContextLoadsEachCollectionOncePerRequest(t *testing.T) {
+	db, h := setupInternalDashboardHandler(t, nil)
+	if _, err := db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, 'favorite', 'favorite')`); err != nil {
+		t.Fatalf("seed tag: %v", err)
+	}
+
+	type counts struct {
+		profiles, tags, sources, linkedSites, sourceByID, logos, blacklisted int
+	}
+	load := func(c *fiber.Ctx) (counts, error) {
+		pageCtx, err := h.pageContext(c)
+		if err != nil {
+			return counts{}, err
+		}
+		profiles, err := pageCtx.Profiles()
+		if err != nil {
+			return counts{}, err
+		}
+		tags, err := pageCtx.ProfileTags()
+		if err != nil {
+			return counts{}, err
+		}
+		sources, err := pageCtx.Sources()
+		if err != nil {
+			return counts{}, err
+		}
+		linkedSites, err := pageCtx.LinkedSites()
+		if err != nil {
+			return counts{}, err
+		}
+		sourceByID, err := pageCtx.SourceByID()
+		if err != nil {
+			return counts{}, err
+		}
+		logos, err := pageCtx.SourceLogos()
+		if err != nil {
+			return counts{}, err
+		}
+		blacklisted, err := pageCtx.BlacklistedSources()
+		if err != nil {
+			return counts{}, err
+		}
+		return counts{len(profiles), len(tags), len(sources), len(linkedSites), len(sourceByID), len(logos), len(blacklisted)}, nil
+	}
+
+	var first, second, third counts
+	app := fiber.New()
+	app.Get("/page", func(c *fiber.Ctx) error {
+		var err error
+		if first, err = load(c); err != nil {
+			return err
+		}
+		pageCtx, _ := h.pageContext(c)
+		if again, _ := h.pageContext(c); again != pageCtx {
+			t.Errorf("expected one page context per request")
+		}
+
+		// Everything changes under the request; it keeps what it loaded.
+		for _, statement := range []string{
+			`INSERT INTO profiles (key, name) VALUES ('profile9', 'Profile 9')`,
+			`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, 'action', 'action')`,
+			`UPDATE sources SET enabled = 0 WHERE key = 'mangafire'`,
+			`INSERT INTO profile_source_logos (profile_id, source_id, logo_url) VALUES (1, 1, '/uploads/site-logos/one.png')`,
+			`INSERT INTO profile_source_blacklist (profile_id, source_id) VALUES (1, 1)`,
+		} {
+			if _, err := db.Exec(statement); err != nil {
+				t.Fatalf("change %q: %v", statement, err)
+			}
+		}
+		second, err = load(c)
+		return err
+	})
+	app.Get("/next", func(c *fiber.Ctx) error {
+		var err error
+		third, err = load(c)
+		return err
+	})
+
+	for _, path := range []string{"/page?profile=profile1", "/next?profile=profile1"} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("get %s: expected 200, got %d", path, resp.StatusCode)
+		}
+	}
+
+	if second != first {
+		t.Fatalf("expected the request to keep its loaded collections %+v, got %+v", first, second)
+	}
+	expected := counts{
+		profiles:    first.profiles + 1,
+		tags:        first.tags + 1,
+		sources:     first.sources - 1,
+		linkedSites: first.linkedSites - 1,
+		sourceByID:  first.sourceByID - 1,
+		logos:       first.logos + 1,
+		blacklisted: first.blacklisted + 1,
+	}
+	if third != expected {
+		t.Fatalf("expected the next request to load afresh %+v, got %+v", expected, third)
+	}
+}
+
+func TestPageContextErrorsMapToStatusAndMessage(t *testing.T) {
+	db, h := setupInternalDashboardHandler(t, nil)
+
+	app := fiber.New()
+	app.Get("/html", func(c *fiber.Ctx) error {
+		pageCtx, err := h.pageContext(c)
+		if err != nil {
+			return sendPageError(c, err)
+		}
+		if _, err := pageCtx.ProfileTags(); err != nil {
+			return sendPageError(c, err)
+		}
+		return c.SendString("ok")
+	})
+	app.Get("/json", func(c *fiber.Ctx) error {
+		pageCtx, err := h.pageContext(c)
+		if err != nil {
+			return sendPageErrorJSON(c, err)
+		}
+		if _, err := pageCtx.ProfileTags(); err != nil {
+			return sendPageErrorJSON(c, err)
+		}
+		return c.SendString("ok")
+	})
+	app.Get("/other", func(c *fiber.Ctx) error {
+		return sendPageError(c, errors.New("boom"))
+	})
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("get %s: %v", path, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	cases := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{path: "/html?profile=missing", status: fiber.StatusBadRequest, body: "Invalid profile"},
+		{path: "/json?profile=missing", status: fiber.StatusBadRequest, body: `{"message":"invalid profile"}`},
+		{path: "/html?profile=profile1", status: fiber.StatusOK, body: "ok"},
+		{path: "/other", status: fiber.StatusInternalServerError, body: "Failed to load page"},
+	}
+	for _, tc := range cases {
+		if status, body := get(tc.path); status != tc.status || body != tc.body {
+			t.Fatalf("get %s: expected %d %q, got %d %q", tc.path, tc.status, tc.body, status, body)
+		}
+	}
+
+	if _, err := db.Exec(`DROP TABLE tracker_tags`); err != nil {
+		t.Fatalf("drop tracker tags: %v", err)
+	}
+	if _, err := db.Exec(`DROP TABLE custom_tags`); err != nil {
+		t.Fatalf("drop custom tags: %v", err)
+	}
+	if status, body := get("/html?profile=profile1"); status != fiber.StatusInternalServerError || body != "Failed to load profile tags" {
+		t.Fatalf("expected a server error for failed tags, got %d %q", status, body)
+	}
+	if status, body := get("/json?profile=profile1"); status != fiber.StatusInternalServerError || body != `{"message":"failed to load profile tags"}` {
+		t.Fatalf("expected a JSON server error for failed tags, got %d %q", status, body)
+	}
+}
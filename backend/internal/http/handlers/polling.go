@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/scheduler"
+	"github.com/gabriel/cross-site-tracker/backend/internal/timefmt"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PollStatusReader exposes the poller's progress; *scheduler.Poller
+// implements it.
+type PollStatusReader interface {
+	Status() scheduler.Status
+}
+
+type PollingHandler struct {
+	status PollStatusReader
+}
+
+// NewPollingHandler builds the polling status API. With a nil reader, as
+// when the server runs without a poller, it reports an idle poller that has
+// never run.
+func NewPollingHandler(status PollStatusReader) *PollingHandler {
+	return &PollingHandler{status: status}
+}
+
+func (h *PollingHandler) Status(c *fiber.Ctx) error {
+	return c.JSON(readPollStatus(h.status))
+}
+
+type pollingStatusPartialData struct {
+	Running     bool
+	Processed   int
+	Total       int
+	HasLastRun  bool
+	LastRunAgo  string
+	NewChapters int
+}
+
+// SetPollStatus makes the dashboard header show the poller's progress.
+// Without it the header reports that no update has run yet.
+func (h *DashboardHandler) SetPollStatus(status PollStatusReader) {
+	h.pollStatus = status
+}
+
+// PollingStatusPartial renders the header line the dashboard refreshes every
+// 30 seconds: the running cycle's progress, or a summary of the last one.
+func (h *DashboardHandler) PollingStatusPartial(c *fiber.Ctx) error {
+	return h.render(c, "polling_status_partial.html", pollingStatusView(readPollStatus(h.pollStatus)))
+}
+
+func readPollStatus(reader PollStatusReader) scheduler.Status {
+	if reader == nil {
+		return scheduler.Status{}
+	}
+	return reader.Status()
+}
+
+func pollingStatusView(status scheduler.Status) pollingStatusPartialData {
+	data := pollingStatusPartialData{
+		Running:   status.Running,
+		Processed: status.Processed,
+		Total:     status.Total,
+	}
+	if status.LastRun != nil {
+		data.HasLastRun = true
+		data.NewChapters = status.LastRun.NewChapters
+		data.LastRunAgo = "just now"
+		if ago := timefmt.FromNow(status.LastRun.FinishedAt, timefmt.Compact); ago != "now" {
+			data.LastRunAgo = fmt.Sprintf("%s ago", ago)
+		}
+	}
+	return data
+}
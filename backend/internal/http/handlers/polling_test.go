@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	apihttp "github.com/gabriel/cross-site-tracker/backend/internal/http"
+	"github.com/gabriel/cross-site-tracker/backend/internal/scheduler"
+	"github.com/gofiber/fiber/v2"
+)
+
+type stubPollStatus struct {
+	status scheduler.Status
+}
+
+func (s *stubPollStatus) Status() scheduler.Status {
+	return s.status
+}
+
+func setupPollingTestApp(t *testing.T, reader *stubPollStatus) (*fiber.App, func()) {
+	t.Helper()
+	_, app, cleanup := setupTestAppWithServer(t, config.Config{AppName: "test-app"}, func(cfg config.Config, db *sql.DB) *fiber.App {
+		return apihttp.NewServerWithPollStatus(cfg, db, nil, reader)
+	})
+	return app, cleanup
+}
+
+func TestPollingStatusEndpointReportsRunningCycle(t *testing.T) {
+	startedAt := time.Now().Add(-time.Minute).UTC()
+	reader := &stubPollStatus{status: scheduler.Status{
+		Running:          true,
+		StartedAt:        &startedAt,
+		Processed:        112,
+		Total:            430,
+		CurrentSourceKey: "mangadex",
+	}}
+	app, cleanup := setupPollingTestApp(t, reader)
+	defer cleanup()
+
+	status, body := getBody(t, app, "/v1/polling/status")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var payload scheduler.Status
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if !payload.Running || payload.Processed != 112 || payload.Total != 430 || payload.CurrentSourceKey != "mangadex" || payload.LastRun != nil {
+		t.Fatalf("unexpected status payload %+v", payload)
+	}
+
+	_, partial := getBody(t, app, "/dashboard/polling-status")
+	if !strings.Contains(partial, "Updating 112/430…") {
+		t.Fatalf("expected running progress in partial, got %s", partial)
+	}
+}
+
+func TestPollingStatusPartialSummarizesLastRun(t *testing.T) {
+	finishedAt := time.Now().Add(-2*time.Hour - time.Minute)
+	reader := &stubPollStatus{status: scheduler.Status{LastRun: &scheduler.RunSummary{
+		StartedAt:   finishedAt.Add(-5 * time.Minute),
+		FinishedAt:  finishedAt,
+		Processed:   430,
+		Total:       430,
+		NewChapters: 14,
+	}}}
+	app, cleanup := setupPollingTestApp(t, reader)
+	defer cleanup()
+
+	_, partial := getBody(t, app, "/dashboard/polling-status")
+	if !strings.Contains(partial, "Last update 2h ago, 14 new chapters") {
+		t.Fatalf("expected last run summary in partial, got %s", partial)
+	}
+
+	reader.status.LastRun.NewChapters = 1
+	reader.status.LastRun.FinishedAt = time.Now()
+	_, partial = getBody(t, app, "/dashboard/polling-status")
+	if !strings.Contains(partial, "Last update just now, 1 new chapter") {
+		t.Fatalf("expected singular summary in partial, got %s", partial)
+	}
+}
+
+func TestPollingStatusWithoutPoller(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	status, body := getBody(t, app, "/v1/polling/status")
+	if status != http.StatusOK || !strings.Contains(body, `"running":false`) {
+		t.Fatalf("expected idle status, got %d: %s", status, body)
+	}
+	_, partial := getBody(t, app, "/dashboard/polling-status")
+	if !strings.Contains(partial, "No update has run yet") {
+		t.Fatalf("expected never-run message, got %s", partial)
+	}
+
+	_, page := getBody(t, app, "/dashboard")
+	if !strings.Contains(page, `hx-get="/dashboard/polling-status"`) {
+		t.Fatalf("expected dashboard to poll the status partial")
+	}
+}
@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/profilekey"
 	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
 	"github.com/gofiber/fiber/v2"
 )
@@ -21,7 +25,18 @@ func newProfileContextResolver(db *sql.DB) *profileContextResolver {
 	return &profileContextResolver{repo: repository.NewProfileRepository(db)}
 }
 
+// Resolve picks the active profile for the request and records its ID for
+// the request log line.
 func (r *profileContextResolver) Resolve(c *fiber.Ctx) (*models.Profile, error) {
+	profile, err := r.resolve(c)
+	if err != nil {
+		return nil, err
+	}
+	c.Locals(profileIDLocalKey, profile.ID)
+	return profile, nil
+}
+
+func (r *profileContextResolver) resolve(c *fiber.Ctx) (*models.Profile, error) {
 	if profile, err := r.resolveFromQuery(c); err != nil {
 		return nil, err
 	} else if profile != nil {
@@ -43,7 +58,7 @@ func (r *profileContextResolver) Resolve(c *fiber.Ctx) (*models.Profile, error)
 		return profile, nil
 	}
 
-	profile, err := r.repo.GetDefault()
+	profile, err := r.repo.GetDefault(c.UserContext())
 	if err != nil {
 		return nil, fmt.Errorf("resolve default profile: %w", err)
 	}
@@ -55,8 +70,8 @@ func (r *profileContextResolver) Resolve(c *fiber.Ctx) (*models.Profile, error)
 	return profile, nil
 }
 
-func (r *profileContextResolver) ListProfiles() ([]models.Profile, error) {
-	return r.repo.List()
+func (r *profileContextResolver) ListProfiles(ctx context.Context) ([]models.Profile, error) {
+	return r.repo.List(ctx)
 }
 
 func (r *profileContextResolver) resolveFromQuery(c *fiber.Ctx) (*models.Profile, error) {
@@ -65,7 +80,7 @@ func (r *profileContextResolver) resolveFromQuery(c *fiber.Ctx) (*models.Profile
 		return nil, nil
 	}
 
-	profile, err := r.lookup(raw)
+	profile, err := r.lookup(c.UserContext(), raw)
 	if err != nil {
 		return nil, err
 	}
@@ -77,7 +92,7 @@ func (r *profileContextResolver) resolveFromQuery(c *fiber.Ctx) (*models.Profile
 
 func (r *profileContextResolver) resolveFromHeaders(c *fiber.Ctx) (*models.Profile, error) {
 	if rawID := strings.TrimSpace(c.Get("X-Profile-ID")); rawID != "" {
-		profile, err := r.lookup(rawID)
+		profile, err := r.lookup(c.UserContext(), rawID)
 		if err != nil {
 			return nil, err
 		}
@@ -88,7 +103,7 @@ func (r *profileContextResolver) resolveFromHeaders(c *fiber.Ctx) (*models.Profi
 	}
 
 	if rawKey := strings.TrimSpace(c.Get("X-Profile-Key")); rawKey != "" {
-		profile, err := r.lookup(rawKey)
+		profile, err := r.lookup(c.UserContext(), rawKey)
 		if err != nil {
 			return nil, err
 		}
@@ -107,7 +122,11 @@ func (r *profileContextResolver) resolveFromCookie(c *fiber.Ctx) (*models.Profil
 		return nil, nil
 	}
 
-	profile, err := r.lookup(raw)
+	// A stale or tampered cookie falls back to the default profile.
+	profile, err := r.lookup(c.UserContext(), raw)
+	if errors.Is(err, profilekey.ErrInvalid) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -117,16 +136,32 @@ func (r *profileContextResolver) resolveFromCookie(c *fiber.Ctx) (*models.Profil
 	return profile, nil
 }
 
-func (r *profileContextResolver) lookup(value string) (*models.Profile, error) {
+// profileURL adds the profile key to path as the profile query parameter,
+// the form every handler redirecting to a profile's page uses.
+func profileURL(path string, key string) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "profile=" + url.QueryEscape(key)
+}
+
+// lookup finds a profile by ID or key. Keys are normalized and a key that
+// can never match, being malformed, fails with profilekey.ErrInvalid.
+func (r *profileContextResolver) lookup(ctx context.Context, value string) (*models.Profile, error) {
 	if id, err := strconv.ParseInt(value, 10, 64); err == nil && id > 0 {
-		item, lookupErr := r.repo.GetByID(id)
+		item, lookupErr := r.repo.GetByID(ctx, id)
 		if lookupErr != nil {
 			return nil, fmt.Errorf("lookup profile by id: %w", lookupErr)
 		}
 		return item, nil
 	}
 
-	item, err := r.repo.GetByKey(value)
+	key := profilekey.Normalize(value)
+	if err := profilekey.Validate(key); err != nil {
+		return nil, err
+	}
+	item, err := r.repo.GetByKey(ctx, key)
 	if err != nil {
 		return nil, fmt.Errorf("lookup profile by key: %w", err)
 	}
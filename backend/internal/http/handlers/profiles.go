@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"database/sql"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+type profilePollingRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+type ProfilesHandler struct {
+	repo            *repository.ProfileRepository
+	profileResolver *profileContextResolver
+}
+
+func NewProfilesHandler(db *sql.DB) *ProfilesHandler {
+	return &ProfilesHandler{
+		repo:            repository.NewProfileRepository(db),
+		profileResolver: newProfileContextResolver(db),
+	}
+}
+
+// GetPolling reports whether the profile's trackers are polled and when
+// the poller last checked one of them.
+func (h *ProfilesHandler) GetPolling(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+	return h.pollingJSON(c, profile.ID, profile.PollingEnabled)
+}
+
+func (h *ProfilesHandler) SetPolling(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	var req profilePollingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid json body"})
+	}
+	if req.Enabled == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "enabled is required"})
+	}
+
+	if _, err := h.repo.SetPollingEnabled(c.UserContext(), profile.ID, *req.Enabled); err != nil {
+		return serverErrorJSON(c, "failed to save profile polling", err)
+	}
+	return h.pollingJSON(c, profile.ID, *req.Enabled)
+}
+
+func (h *ProfilesHandler) pollingJSON(c *fiber.Ctx, profileID int64, enabled bool) error {
+	lastPolledAt, err := h.repo.LastPolledAt(c.UserContext(), profileID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load profile polling", err)
+	}
+	return c.JSON(fiber.Map{"enabled": enabled, "lastPolledAt": lastPolledAt})
+}
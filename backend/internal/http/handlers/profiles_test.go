@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSavePollingFromMenuTogglesProfilePolling(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if _, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_checked_at)
+		VALUES (2, 'Archived', 1, 'https://asuracomic.net/series/archived', 'completed', datetime('now', '-2 hours'))
+	`); err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/profile/polling?profile=profile2", strings.NewReader(""))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("save polling request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+	}
+	html := string(body)
+	if !strings.Contains(html, "Polling turned off") || !strings.Contains(html, "polling: off, last full check 2h ago") {
+		t.Fatalf("expected the disabled polling summary in the profile menu, got %s", html)
+	}
+
+	var enabled bool
+	if err := db.QueryRow(`SELECT polling_enabled FROM profiles WHERE id = 2`).Scan(&enabled); err != nil {
+		t.Fatalf("load profile: %v", err)
+	}
+	if enabled {
+		t.Fatalf("expected polling to be turned off for profile2")
+	}
+	if err := db.QueryRow(`SELECT polling_enabled FROM profiles WHERE id = 1`).Scan(&enabled); err != nil {
+		t.Fatalf("load profile: %v", err)
+	}
+	if !enabled {
+		t.Fatalf("expected profile1 to keep polling")
+	}
+
+	_, menu := getBody(t, app, "/dashboard/profile/menu?profile=profile1")
+	if !strings.Contains(menu, "polling: on, not checked yet") {
+		t.Fatalf("expected profile1's polling summary, got %s", menu)
+	}
+}
+
+func TestProfilePollingAPIRoundTrip(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodPut, "/v1/profile/polling?profile=profile2", strings.NewReader(`{"enabled":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("set polling request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+
+	status, body := getBody(t, app, "/v1/profile/polling?profile=profile2")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var payload struct {
+		Enabled      bool    `json:"enabled"`
+		LastPolledAt *string `json:"lastPolledAt"`
+	}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("decode polling: %v", err)
+	}
+	if payload.Enabled || payload.LastPolledAt != nil {
+		t.Fatalf("expected polling off and never polled, got %+v", payload)
+	}
+
+	_, body = getBody(t, app, "/v1/profile/polling?profile=profile1")
+	if !strings.Contains(body, `"enabled":true`) {
+		t.Fatalf("expected profile1 to keep polling, got %s", body)
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// rateLimitSweepInterval is how often idle client buckets are dropped.
+const rateLimitSweepInterval = 5 * time.Minute
+
+// RateLimiter is an in-memory token bucket per client IP for endpoints that
+// make outbound source requests, so one client looping on them cannot spend
+// a source's request budget for everyone else, the poller included. Each
+// client may make perMinute requests in a burst and regains them at the same
+// rate. A limiter with perMinute <= 0 lets every request through.
+type RateLimiter struct {
+	mu        sync.Mutex
+	perMinute int
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func NewRateLimiter(perMinute int) *RateLimiter {
+	return &RateLimiter{
+		perMinute: perMinute,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Middleware rejects requests over the limit with 429 and a Retry-After
+// header. onLimited renders the rejection; nil sends a plain text body.
+func (l *RateLimiter) Middleware(onLimited func(c *fiber.Ctx, retryAfter time.Duration) error) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if l == nil || l.perMinute <= 0 {
+			return c.Next()
+		}
+
+		allowed, retryAfter := l.allow(c.IP())
+		if allowed {
+			return c.Next()
+		}
+
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfterSeconds(retryAfter)))
+		c.Status(fiber.StatusTooManyRequests)
+		if onLimited != nil {
+			return onLimited(c, retryAfter)
+		}
+		return c.SendString("Too many requests, try again later")
+	}
+}
+
+// RateLimitedJSON renders a Middleware rejection for the JSON API.
+func RateLimitedJSON(c *fiber.Ctx, retryAfter time.Duration) error {
+	return c.JSON(fiber.Map{"message": "too many requests, try again in " + pluralize(retryAfterSeconds(retryAfter), "second")})
+}
+
+// allow takes a token from key's bucket, or reports how long until one is
+// available.
+func (l *RateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	capacity := float64(l.perMinute)
+	perSecond := capacity / 60
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		l.sweep(now, capacity, perSecond)
+	}
+
+	bucket := l.buckets[key]
+	if bucket == nil {
+		bucket = &tokenBucket{tokens: capacity, updatedAt: now}
+		l.buckets[key] = bucket
+	}
+	bucket.tokens = min(capacity, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*perSecond)
+	bucket.updatedAt = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - bucket.tokens) / perSecond * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely, which a new bucket
+// would start as anyway.
+func (l *RateLimiter) sweep(now time.Time, capacity float64, perSecond float64) {
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*perSecond >= capacity {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func retryAfterSeconds(wait time.Duration) int {
+	return max(1, int(math.Ceil(wait.Seconds())))
+}
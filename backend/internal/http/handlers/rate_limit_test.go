@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gofiber/fiber/v2"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestRateLimiter(perMinute int) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(perMinute)
+	limiter.now = clock.Now
+	limiter.lastSweep = clock.now
+	return limiter, clock
+}
+
+func rateLimitedApp(limiter *RateLimiter) *fiber.App {
+	app := fiber.New()
+	app.Get("/search", limiter.Middleware(nil), func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app
+}
+
+func TestRateLimiterRejectsOverLimitAndRecovers(t *testing.T) {
+	limiter, clock := newTestRateLimiter(3)
+	app := rateLimitedApp(limiter)
+
+	get := func() (int, string) {
+		t.Helper()
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/search", nil))
+		if err != nil {
+			t.Fatalf("search request failed: %v", err)
+		}
+		return res.StatusCode, res.Header.Get(fiber.HeaderRetryAfter)
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if status, _ := get(); status != fiber.StatusOK {
+			t.Fatalf("expected request %d within the limit to pass, got %d", attempt, status)
+		}
+	}
+	status, retryAfter := get()
+	if status != fiber.StatusTooManyRequests {
+		t.Fatalf("expected 429 over the limit, got %d", status)
+	}
+	if retryAfter != "20" {
+		t.Fatalf("expected Retry-After of 20 seconds for one token at 3/min, got %q", retryAfter)
+	}
+
+	clock.now = clock.now.Add(19 * time.Second)
+	if status, _ := get(); status != fiber.StatusTooManyRequests {
+		t.Fatalf("expected request before a token refilled to be rejected, got %d", status)
+	}
+	clock.now = clock.now.Add(time.Second)
+	if status, _ := get(); status != fiber.StatusOK {
+		t.Fatalf("expected request after the wait to pass, got %d", status)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	for attempt := 1; attempt <= 3; attempt++ {
+		if status, _ := get(); status != fiber.StatusOK {
+			t.Fatalf("expected full burst after a quiet minute, request %d got %d", attempt, status)
+		}
+	}
+}
+
+func TestRateLimiterDisabledPassesEverything(t *testing.T) {
+	limiter, _ := newTestRateLimiter(0)
+	app := rateLimitedApp(limiter)
+	for attempt := 0; attempt < 50; attempt++ {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/search", nil))
+		if err != nil {
+			t.Fatalf("search request failed: %v", err)
+		}
+		if res.StatusCode != fiber.StatusOK {
+			t.Fatalf("expected disabled limiter to pass request %d, got %d", attempt, res.StatusCode)
+		}
+	}
+}
+
+func TestRateLimiterSweepsIdleClients(t *testing.T) {
+	limiter, clock := newTestRateLimiter(2)
+	limiter.allow("10.0.0.1")
+	limiter.allow("10.0.0.2")
+	limiter.allow("10.0.0.2")
+
+	clock.now = clock.now.Add(rateLimitSweepInterval)
+	limiter.allow("10.0.0.3")
+	if len(limiter.buckets) != 1 || limiter.buckets["10.0.0.3"] == nil {
+		t.Fatalf("expected only the active client to keep a bucket, got %d buckets", len(limiter.buckets))
+	}
+}
+
+func TestSearchRateLimitedRendersSearchError(t *testing.T) {
+	_, h := setupInternalDashboardHandler(t, connectors.NewRegistry())
+	limiter, _ := newTestRateLimiter(1)
+
+	app := fiber.New()
+	app.Get("/dashboard/trackers/search", limiter.Middleware(h.SearchRateLimited), h.SearchSourceTitles)
+
+	for attempt := 0; attempt < 2; attempt++ {
+		res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/dashboard/trackers/search?q=solo&intent=link", nil))
+		if err != nil {
+			t.Fatalf("search request failed: %v", err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		if attempt == 0 {
+			continue
+		}
+		if res.StatusCode != fiber.StatusTooManyRequests || res.Header.Get(fiber.HeaderRetryAfter) != "60" {
+			t.Fatalf("expected 429 with Retry-After 60, got %d %q", res.StatusCode, res.Header.Get(fiber.HeaderRetryAfter))
+		}
+		if !strings.Contains(string(body), `search-message--error`) || !strings.Contains(string(body), "Try again in 60 seconds") {
+			t.Fatalf("expected search error partial, got %s", body)
+		}
+	}
+}
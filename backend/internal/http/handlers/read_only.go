@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	kioskCookieName     = "cst_kiosk"
+	kioskCookieLifetime = 365 * 24 * time.Hour
+	readOnlyLocalKey    = "dashboardReadOnly"
+	readOnlyMessage     = "This screen is read-only; changes are turned off here."
+)
+
+// readOnlyGuardedPrefixes are the route trees whose mutations read-only
+// mode blocks, relative to the base path. Login and logout stay usable.
+var readOnlyGuardedPrefixes = []string{"/dashboard", "/v1"}
+
+// readOnlyAllowedRoutes are mutating requests that change nothing stored
+// and so stay open in read-only mode, as "METHOD path" without the base
+// path. Switching profiles only redirects.
+var readOnlyAllowedRoutes = map[string]bool{
+	fiber.MethodPost + " /dashboard/profile/switch": true,
+}
+
+// ReadOnlyMode turns the dashboard and API into a read-only surface,
+// either for every client or for the browsers that opened a page with
+// ?kiosk=1. Reads behave as usual; mutations get a 403.
+type ReadOnlyMode struct {
+	global   bool
+	key      []byte
+	basePath string
+	now      func() time.Time
+
+	// auth asks for the password to leave kiosk mode; without a dashboard
+	// password a kiosk browser stays read-only until its cookie expires or
+	// SESSION_SECRET changes.
+	auth *AuthHandler
+}
+
+func NewReadOnlyMode(global bool, secret string, basePath string) *ReadOnlyMode {
+	secretBytes := []byte(secret)
+	if strings.TrimSpace(secret) == "" {
+		secretBytes = make([]byte, 32)
+		_, _ = rand.Read(secretBytes)
+	}
+
+	mac := hmac.New(sha256.New, secretBytes)
+	mac.Write([]byte("dashboard-kiosk"))
+
+	return &ReadOnlyMode{
+		global:   global,
+		key:      mac.Sum(nil),
+		basePath: basePath,
+		now:      time.Now,
+	}
+}
+
+// SetAuth lets a kiosk browser leave read-only mode by logging in again.
+func (m *ReadOnlyMode) SetAuth(auth *AuthHandler) {
+	m.auth = auth
+}
+
+// Middleware marks read-only requests for the templates and rejects the
+// mutations among them. A GET with ?kiosk=1 puts the browser in read-only
+// mode with a signed cookie. ?kiosk=0 sends a kiosk browser to the login
+// form, and only entering the password there takes it out again; without
+// a dashboard password it changes nothing. Neither affects a globally
+// read-only instance.
+func (m *ReadOnlyMode) Middleware(c *fiber.Ctx) error {
+	readOnly := m.global
+	kiosk := strings.TrimSpace(c.Query("kiosk"))
+	inKiosk := m.validKiosk(c.Cookies(kioskCookieName))
+	switch {
+	case c.Method() == fiber.MethodGet && kiosk == "1":
+		expiresAt := m.now().Add(kioskCookieLifetime)
+		c.Cookie(dashboardCookie(c, m.basePath, kioskCookieName, m.signKiosk(expiresAt), expiresAt))
+		readOnly = true
+	case c.Method() == fiber.MethodGet && kiosk == "0" && inKiosk && m.auth != nil && m.auth.Enabled():
+		return m.auth.redirectToLogin(c, true)
+	case inKiosk:
+		readOnly = true
+	}
+	if !readOnly {
+		return c.Next()
+	}
+
+	c.Locals(readOnlyLocalKey, true)
+	if !m.blocks(c.Method(), c.Path()) {
+		return c.Next()
+	}
+	if strings.HasPrefix(strings.TrimPrefix(c.Path(), m.basePath), "/v1/") {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": readOnlyMessage})
+	}
+	return c.Status(fiber.StatusForbidden).SendString(readOnlyMessage)
+}
+
+func (m *ReadOnlyMode) blocks(method string, path string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return false
+	}
+	path = strings.TrimPrefix(path, m.basePath)
+	if readOnlyAllowedRoutes[method+" "+strings.TrimSuffix(path, "/")] {
+		return false
+	}
+	for _, prefix := range readOnlyGuardedPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// signKiosk returns "<expiry unix seconds>.<hex hmac>".
+func (m *ReadOnlyMode) signKiosk(expiresAt time.Time) string {
+	payload := strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + m.sign(payload)
+}
+
+func (m *ReadOnlyMode) validKiosk(value string) bool {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(m.sign(payload))) {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return false
+	}
+	return m.now().Unix() < expiresAt
+}
+
+func (m *ReadOnlyMode) sign(payload string) string {
+	mac := hmac.New(sha256.New, m.key)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// isReadOnly reports whether ReadOnlyMode marked the request read-only.
+func isReadOnly(c *fiber.Ctx) bool {
+	return c.Locals(readOnlyLocalKey) == true
+}
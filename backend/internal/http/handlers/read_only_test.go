@@ -0,0 +1,275 @@
+package handlers_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	apihttp "github.com/gabriel/cross-site-tracker/backend/internal/http"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/scheduler"
+	"github.com/gofiber/fiber/v2"
+)
+
+func kioskCookie(t *testing.T, res *http.Response) *http.Cookie {
+	t.Helper()
+
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "cst_kiosk" {
+			return cookie
+		}
+	}
+	t.Fatalf("expected a cst_kiosk cookie, got %v", res.Header.Values("Set-Cookie"))
+	return nil
+}
+
+func postTagFromMenu(t *testing.T, app *fiber.App, name string, cookie *http.Cookie) (int, string) {
+	t.Helper()
+
+	form := url.Values{}
+	form.Set("tag_name", name)
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/profile/tags?profile=profile1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("create tag request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	return res.StatusCode, string(body)
+}
+
+func countTagsNamed(t *testing.T, db *sql.DB, name string) int {
+	t.Helper()
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM custom_tags WHERE name = ?`, name).Scan(&count); err != nil {
+		t.Fatalf("count tags: %v", err)
+	}
+	return count
+}
+
+func TestKioskCookieMakesTheBrowserReadOnly(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard?profile=profile1&kiosk=1", nil))
+	if err != nil {
+		t.Fatalf("kiosk page request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), `<body class="read-only">`) || strings.Contains(string(body), "+ Add Tracker") {
+		t.Fatalf("expected the read-only dashboard, got %d: %s", res.StatusCode, string(body))
+	}
+	cookie := kioskCookie(t, res)
+
+	req := httptest.NewRequest(http.MethodGet, "/dashboard?profile=profile1", nil)
+	req.AddCookie(cookie)
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("dashboard request failed: %v", err)
+	}
+	if body, _ := io.ReadAll(res.Body); res.StatusCode != http.StatusOK || !strings.Contains(string(body), "Read-only screen") {
+		t.Fatalf("expected GETs to work and stay read-only with the cookie, got %d", res.StatusCode)
+	}
+
+	if status, body := postTagFromMenu(t, app, "kiosk-tag", cookie); status != http.StatusForbidden || !strings.Contains(body, "read-only") {
+		t.Fatalf("expected 403 with a read-only message, got %d: %s", status, body)
+	}
+	if count := countTagsNamed(t, db, "kiosk-tag"); count != 0 {
+		t.Fatalf("expected the blocked request to save nothing, found %d tags", count)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/v1/tags/1", strings.NewReader(`{"name":"renamed"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(cookie)
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("api request failed: %v", err)
+	}
+	if body, _ := io.ReadAll(res.Body); res.StatusCode != http.StatusForbidden || !strings.Contains(string(body), `"message"`) {
+		t.Fatalf("expected a JSON 403 from the API, got %d: %s", res.StatusCode, string(body))
+	}
+
+	form := url.Values{}
+	form.Set("profile", "profile2")
+	req = httptest.NewRequest(http.MethodPost, "/dashboard/profile/switch", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(cookie)
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("profile switch request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected switching profiles to stay allowed, got %d", res.StatusCode)
+	}
+
+	forged := &http.Cookie{Name: cookie.Name, Value: "9999999999.forged"}
+	if status, _ := postTagFromMenu(t, app, "forged-tag", forged); status != http.StatusOK {
+		t.Fatalf("expected an unsigned cookie to be ignored, got %d", status)
+	}
+
+	// Without a dashboard password nobody can vouch for leaving, so
+	// ?kiosk=0 alone keeps the browser read-only.
+	req = httptest.NewRequest(http.MethodGet, "/dashboard?profile=profile1&kiosk=0", nil)
+	req.AddCookie(cookie)
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("leave kiosk request failed: %v", err)
+	}
+	if body, _ := io.ReadAll(res.Body); res.StatusCode != http.StatusOK || !strings.Contains(string(body), `<body class="read-only">`) {
+		t.Fatalf("expected ?kiosk=0 to leave the page read-only, got %d", res.StatusCode)
+	}
+	for _, set := range res.Cookies() {
+		if set.Name == "cst_kiosk" {
+			t.Fatalf("expected ?kiosk=0 to keep the kiosk cookie, got %v", set)
+		}
+	}
+	if status, _ := postTagFromMenu(t, app, "after-kiosk", cookie); status != http.StatusForbidden {
+		t.Fatalf("expected changes to stay blocked after ?kiosk=0, got %d", status)
+	}
+}
+
+func TestLeavingKioskModeNeedsThePassword(t *testing.T) {
+	db, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", DashboardPassword: "hunter2", SessionSecret: "test-secret"})
+	defer cleanup()
+
+	// The shared screen stays logged in while it is a kiosk.
+	session := sessionCookie(postLogin(t, app, openLoginForm(t, app), "hunter2", "/dashboard"))
+	if session == nil {
+		t.Fatalf("expected a session cookie after logging in")
+	}
+	req := httptest.NewRequest(http.MethodGet, "/dashboard?profile=profile1&kiosk=1", nil)
+	req.AddCookie(session)
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("kiosk page request failed: %v", err)
+	}
+	kiosk := kioskCookie(t, res)
+
+	req = httptest.NewRequest(http.MethodGet, "/dashboard?profile=profile1&kiosk=0", nil)
+	req.AddCookie(session)
+	req.AddCookie(kiosk)
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("leave kiosk request failed: %v", err)
+	}
+	location := res.Header.Get("Location")
+	if res.StatusCode != http.StatusSeeOther || !strings.HasPrefix(location, "/login?next=") || !strings.Contains(location, "leave_kiosk=1") {
+		t.Fatalf("expected ?kiosk=0 to ask for the password, got %d %q", res.StatusCode, location)
+	}
+	for _, set := range res.Cookies() {
+		if set.Name == "cst_kiosk" {
+			t.Fatalf("expected the kiosk cookie to be kept, got %v", set)
+		}
+	}
+	if status, _ := postTagFromMenu(t, app, "kiosk-tag", kiosk); status != http.StatusForbidden {
+		t.Fatalf("expected the kiosk to stay read-only, got %d", status)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, location, nil)
+	req.AddCookie(session)
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("login page request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	match := csrfTokenPattern.FindStringSubmatch(string(body))
+	if res.StatusCode != http.StatusOK || match == nil || !strings.Contains(string(body), `name="leave_kiosk" value="1"`) {
+		t.Fatalf("expected the login form despite the session, got %d: %s", res.StatusCode, body)
+	}
+	var csrf *http.Cookie
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "cst_login_csrf" {
+			csrf = cookie
+		}
+	}
+
+	login := func(password string) *http.Response {
+		form := url.Values{}
+		form.Set("csrf_token", match[1])
+		form.Set("password", password)
+		form.Set("next", "/dashboard?profile=profile1&kiosk=0")
+		form.Set("leave_kiosk", "1")
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(csrf)
+		req.AddCookie(kiosk)
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("login request failed: %v", err)
+		}
+		return res
+	}
+	if res := login("wrong"); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a wrong password to be refused, got %d", res.StatusCode)
+	} else {
+		for _, set := range res.Cookies() {
+			if set.Name == "cst_kiosk" {
+				t.Fatalf("expected a wrong password to keep the kiosk cookie, got %v", set)
+			}
+		}
+	}
+	res = login("hunter2")
+	if cleared := kioskCookie(t, res); res.StatusCode != http.StatusSeeOther || cleared.Value != "" {
+		t.Fatalf("expected the password to clear the kiosk cookie, got %d %q", res.StatusCode, cleared.Value)
+	}
+	if status, _ := postTagFromMenu(t, app, "after-kiosk", sessionCookie(res)); status != http.StatusOK || countTagsNamed(t, db, "after-kiosk") != 1 {
+		t.Fatalf("expected changes allowed after leaving kiosk mode, got %d", status)
+	}
+}
+
+func TestGlobalReadOnlyBlocksChangesButNotThePoller(t *testing.T) {
+	connector := &movedURLConnector{}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(connector); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+	db, app, cleanup := setupTestAppWithServer(t, config.Config{AppName: "test", ReadOnly: true}, func(cfg config.Config, db *sql.DB) *fiber.App {
+		return apihttp.NewServerWithRegistry(cfg, db, registry)
+	})
+	defer cleanup()
+
+	sourceID, _ := sourceMetaByKey(t, db, "mangadex")
+	if _, err := db.Exec(`INSERT INTO trackers (profile_id, title, source_id, source_url, status) VALUES (1, 'Solo Blade', ?, ?, 'reading')`, sourceID, movedLiveURL); err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("list trackers request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected reads to work, got %d", res.StatusCode)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/v1/trackers?profile=profile1", strings.NewReader(`{"title":"New","sourceId":1,"sourceUrl":"https://mangadex.org/title/new","status":"reading"}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("create tracker request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 on a read-only instance, got %d", res.StatusCode)
+	}
+	if status, _ := postTagFromMenu(t, app, "global-tag", nil); status != http.StatusForbidden {
+		t.Fatalf("expected 403 from the dashboard, got %d", status)
+	}
+
+	poller := scheduler.NewPoller(repository.NewTrackerRepository(db), registry, scheduler.PollerConfig{}, slog.Default())
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("poll run failed: %v", err)
+	}
+	if _, _, _, latest := trackerRowsByTitle(t, db, "Solo Blade"); !latest.Valid || latest.Float64 != 88 {
+		t.Fatalf("expected the poller to keep updating trackers, got %v", latest)
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// parseReadSourceID reads the optional source_id sent with a last read
+// change; zero means the caller did not say which source it read on.
+func parseReadSourceID(raw string) (int64, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, true
+	}
+	sourceID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || sourceID < 0 {
+		return 0, false
+	}
+	return sourceID, true
+}
+
+// recordReadSource counts a last read advance from previous to next against
+// the source it was read on. sourceID falls back to the tracker's primary
+// source when it is zero or not one of the tracker's sources. Failures are
+// logged; the last read change itself has already been saved.
+func recordReadSource(ctx context.Context, repo *repository.TrackerRepository, tracker *models.Tracker, sourceID int64, previous *float64, next *float64) {
+	if tracker == nil || next == nil || (previous != nil && *next <= *previous) {
+		return
+	}
+
+	if sourceID != tracker.SourceID {
+		linked := false
+		if sourceID > 0 {
+			sources, err := repo.ListTrackerSources(ctx, tracker.ProfileID, tracker.ID)
+			if err != nil {
+				slog.Warn("load tracker sources for read attribution failed", "tracker_id", tracker.ID, "error", err)
+			}
+			for _, source := range sources {
+				if source.SourceID == sourceID {
+					linked = true
+					break
+				}
+			}
+		}
+		if !linked {
+			sourceID = tracker.SourceID
+		}
+	}
+
+	if err := repo.RecordReadSource(ctx, tracker.ProfileID, tracker.ID, sourceID, time.Now().UTC()); err != nil {
+		slog.Warn("record read source failed", "tracker_id", tracker.ID, "source_id", sourceID, "error", err)
+	}
+}
+
+// chapterInPrimaryNumbering converts a chapter read on the linked source
+// sourceID into the tracker's primary numbering, which is what last read is
+// stored in, so unread counts stay right after reading on another site.
+// Chapters from the primary, or from a source that is not linked, are
+// returned unchanged.
+func chapterInPrimaryNumbering(ctx context.Context, repo *repository.TrackerRepository, tracker *models.Tracker, sourceID int64, chapter *float64) (*float64, error) {
+	if tracker == nil || chapter == nil || sourceID <= 0 || sourceID == tracker.SourceID {
+		return chapter, nil
+	}
+
+	sources, err := repo.ListTrackerSources(ctx, tracker.ProfileID, tracker.ID)
+	if err != nil {
+		return nil, err
+	}
+	primary := models.TrackerSource{SourceID: tracker.SourceID, SourceURL: tracker.SourceURL}
+	for _, source := range sources {
+		if source.SourceID == sourceID {
+			return offsetChapter(chapter, source.ChapterOffset-trackerSourceChapterOffset(sources, primary)), nil
+		}
+	}
+	return chapter, nil
+}
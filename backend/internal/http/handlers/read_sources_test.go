@@ -0,0 +1,236 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLastReadAdvancesAreCountedPerSource(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	mangaFireID, _ := sourceMetaByKey(t, db, "mangafire")
+	webtoonsID, _ := sourceMetaByKey(t, db, "webtoons")
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Where Blade', 1, 'https://asuracomic.net/series/where-blade', 'reading', 4, 12)
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	id := strconv.FormatInt(trackerID, 10)
+	if _, err := db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_url)
+		VALUES (?, 1, 'https://asuracomic.net/series/where-blade'), (?, ?, 'https://mangafire.to/manga/where-blade.abc')
+	`, trackerID, trackerID, mangaFireID); err != nil {
+		t.Fatalf("seed tracker sources: %v", err)
+	}
+
+	setLastRead := func(chapter string, sourceID string) {
+		t.Helper()
+		form := url.Values{}
+		form.Set("view_mode", "grid")
+		form.Set("chapter", chapter)
+		if sourceID != "" {
+			form.Set("source_id", sourceID)
+		}
+		postCardAction(t, app, "/dashboard/trackers/"+id+"/set-last-read", form)
+	}
+	setLastRead("5", strconv.FormatInt(mangaFireID, 10))
+	setLastRead("6", "")
+	// A source the tracker is not linked to falls back to the primary.
+	setLastRead("7", strconv.FormatInt(webtoonsID, 10))
+	// Moving last read back is not a read.
+	setLastRead("3", strconv.FormatInt(mangaFireID, 10))
+
+	form := url.Values{}
+	form.Set("chapter", "8")
+	form.Set("source_id", "mangafire")
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/trackers/"+id+"/set-last-read", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("set last read request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a malformed source_id, got %d", res.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/v1/trackers/"+id, strings.NewReader(`{"title":"Where Blade","sourceId":1,"sourceUrl":"https://asuracomic.net/series/where-blade","status":"reading","lastReadChapter":9,"latestKnownChapter":12}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("update request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from the API update, got %d", res.StatusCode)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/v1/stats?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("stats request failed: %v", err)
+	}
+	var payload struct {
+		ReadSources []struct {
+			SourceID int64 `json:"sourceId"`
+			Reads    int   `json:"reads"`
+		} `json:"readSources"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode stats response: %v", err)
+	}
+	got := payload.ReadSources
+	if len(got) != 2 || got[0].SourceID != 1 || got[0].Reads != 3 || got[1].SourceID != mangaFireID || got[1].Reads != 1 {
+		t.Fatalf("expected 3 reads on the primary and 1 on MangaFire, got %+v", got)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/"+id+"/edit?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("edit modal request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if !strings.Contains(string(body), "Read on") || !strings.Contains(string(body), `data-source-id="`+strconv.FormatInt(mangaFireID, 10)+`"`) {
+		t.Fatalf("expected the edit modal to break reads down per source, got %s", string(body))
+	}
+}
+
+func TestLastReadOnALinkedSourceIsStoredInThePrimaryNumbering(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	mangaFireID, _ := sourceMetaByKey(t, db, "mangafire")
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Split Blade', 1, 'https://asuracomic.net/series/split-blade', 'reading', 90, 96)
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	id := strconv.FormatInt(trackerID, 10)
+	// MangaFire splits four chapters in two, so its 100 is the primary's 96.
+	if _, err := db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_url, chapter_offset)
+		VALUES (?, 1, 'https://asuracomic.net/series/split-blade', 0), (?, ?, 'https://mangafire.to/manga/split-blade.abc', -4)
+	`, trackerID, trackerID, mangaFireID); err != nil {
+		t.Fatalf("seed tracker sources: %v", err)
+	}
+
+	lastRead := func() float64 {
+		t.Helper()
+		var chapter float64
+		if err := db.QueryRow(`SELECT last_read_chapter FROM trackers WHERE id = ?`, trackerID).Scan(&chapter); err != nil {
+			t.Fatalf("load last read chapter: %v", err)
+		}
+		return chapter
+	}
+
+	form := url.Values{}
+	form.Set("view_mode", "grid")
+	form.Set("chapter", "98")
+	form.Set("source_id", strconv.FormatInt(mangaFireID, 10))
+	_, body := postCardAction(t, app, "/dashboard/trackers/"+id+"/set-last-read", form)
+	if got := lastRead(); got != 94 {
+		t.Fatalf("expected MangaFire's 98 to be stored as 94, got %v", got)
+	}
+	if !strings.Contains(body, "Split Blade") {
+		t.Fatalf("expected the card to be replaced, got %s", body)
+	}
+
+	form.Set("chapter", "95")
+	form.Set("source_id", "1")
+	postCardAction(t, app, "/dashboard/trackers/"+id+"/set-last-read", form)
+	if got := lastRead(); got != 95 {
+		t.Fatalf("expected a chapter read on the primary to be kept as is, got %v", got)
+	}
+}
+
+func TestCardLastReadButtonSendsItsSource(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if _, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter)
+		VALUES (1, 'Button Blade', 1, 'https://asuracomic.net/series/button-blade', 'reading', 12)
+	`); err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("trackers partial request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if !strings.Contains(string(body), `js:{source_id: 1, view_mode:`) {
+		t.Fatalf("expected the set last read button to post its source, got %s", string(body))
+	}
+}
+
+func TestReadingHistoryListsLastReadAdvances(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'History Blade', 1, 'https://asuracomic.net/series/history-blade', 'reading', 4, 12)
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	id := strconv.FormatInt(trackerID, 10)
+
+	for _, chapter := range []string{"6", "5", "9"} {
+		form := url.Values{}
+		form.Set("view_mode", "grid")
+		form.Set("chapter", chapter)
+		postCardAction(t, app, "/dashboard/trackers/"+id+"/set-last-read", form)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers/"+id+"/reading-history?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("reading history request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	var payload struct {
+		Items []struct {
+			FromChapter *float64 `json:"fromChapter"`
+			ToChapter   float64  `json:"toChapter"`
+			Chapters    float64  `json:"chapters"`
+			ReadAt      string   `json:"readAt"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode reading history: %v", err)
+	}
+	got := payload.Items
+	if len(got) != 2 || *got[0].FromChapter != 4 || got[0].Chapters != 2 || *got[1].FromChapter != 5 || got[1].ToChapter != 9 || got[1].Chapters != 4 || got[1].ReadAt == "" {
+		t.Fatalf("expected the advances 4→6 and 5→9, got %+v", got)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers/999999/reading-history?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("reading history request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown tracker, got %d", res.StatusCode)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers/"+id+"/edit?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("edit modal request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if !strings.Contains(string(body), `class="reading-sparkline"`) || !strings.Contains(string(body), "6 chapters read over") {
+		t.Fatalf("expected the edit modal to draw the reading sparkline, got %s", string(body))
+	}
+}
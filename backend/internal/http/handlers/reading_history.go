@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/stats"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// readingHistoryWeeks is how many of the latest weeks the edit modal's
+	// sparkline covers.
+	readingHistoryWeeks = 52
+
+	sparklineWidth   = 120.0
+	sparklineHeight  = 24.0
+	sparklinePadding = 2.0
+)
+
+// readingHistorySeries buckets a tracker's read events into weekly chapter
+// totals, keeping the latest readingHistoryWeeks weeks.
+func readingHistorySeries(events []models.ReadEvent) []stats.ReadingWeek {
+	reads := make([]stats.Read, 0, len(events))
+	for _, event := range events {
+		reads = append(reads, stats.Read{At: event.ReadAt, Chapters: event.Chapters})
+	}
+	weeks := stats.BucketReadsByWeek(reads)
+	if len(weeks) > readingHistoryWeeks {
+		weeks = weeks[len(weeks)-readingHistoryWeeks:]
+	}
+	return weeks
+}
+
+// readingSparkline draws weekly chapter totals as an inline SVG line, the
+// busiest week at the top. It renders nothing without any weeks, and a
+// single week as a flat line with a dot.
+func readingSparkline(weeks []stats.ReadingWeek) template.HTML {
+	if len(weeks) == 0 {
+		return ""
+	}
+
+	busiest, total := 0.0, 0.0
+	for _, week := range weeks {
+		busiest = max(busiest, week.Chapters)
+		total += week.Chapters
+	}
+	y := func(chapters float64) float64 {
+		if busiest <= 0 {
+			return sparklineHeight - sparklinePadding
+		}
+		return sparklineHeight - sparklinePadding - chapters/busiest*(sparklineHeight-2*sparklinePadding)
+	}
+
+	var path strings.Builder
+	marker := ""
+	if len(weeks) == 1 {
+		level := y(weeks[0].Chapters)
+		fmt.Fprintf(&path, "M0,%s L%s,%s", svgNumber(level), svgNumber(sparklineWidth), svgNumber(level))
+		marker = fmt.Sprintf(`<circle cx="%s" cy="%s" r="2"></circle>`, svgNumber(sparklineWidth/2), svgNumber(level))
+	} else {
+		step := sparklineWidth / float64(len(weeks)-1)
+		for index, week := range weeks {
+			command := "L"
+			if index == 0 {
+				command = "M"
+			} else {
+				path.WriteByte(' ')
+			}
+			fmt.Fprintf(&path, "%s%s,%s", command, svgNumber(float64(index)*step), svgNumber(y(week.Chapters)))
+		}
+	}
+
+	label := fmt.Sprintf("%s chapters read over %s", svgNumber(total), pluralize(len(weeks), "week"))
+	return template.HTML(fmt.Sprintf(
+		`<svg class="reading-sparkline" viewBox="0 0 %s %s" width="%s" height="%s" role="img" aria-label="%s"><title>%s</title><path d="%s" fill="none" stroke="currentColor" stroke-width="1.5"></path>%s</svg>`,
+		svgNumber(sparklineWidth), svgNumber(sparklineHeight), svgNumber(sparklineWidth), svgNumber(sparklineHeight),
+		template.HTMLEscapeString(label), template.HTMLEscapeString(label), path.String(), marker,
+	))
+}
+
+// svgNumber formats value with at most one decimal, dropping a trailing
+// ".0".
+func svgNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64)
+}
+
+// ReadingHistory returns the tracker's read events, oldest first. Each
+// event gives the chapters it moved from and to and how many chapters that
+// advanced; a first read advances by 0.
+func (h *TrackersHandler) ReadingHistory(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid tracker id"})
+	}
+
+	tracker, err := h.repo.GetByID(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to get tracker", err)
+	}
+	if tracker == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
+	}
+
+	events, err := h.repo.ListReadEvents(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load reading history", err)
+	}
+
+	return c.JSON(fiber.Map{"items": events})
+}
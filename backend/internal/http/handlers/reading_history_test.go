@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+var sparklinePointPattern = regexp.MustCompile(`[ML]([0-9.]+),([0-9.]+)`)
+
+func sparklinePoints(t *testing.T, svg string) [][2]float64 {
+	t.Helper()
+	path := regexp.MustCompile(`<path d="([^"]*)"`).FindStringSubmatch(svg)
+	if path == nil {
+		t.Fatalf("expected a path in %s", svg)
+	}
+	points := [][2]float64{}
+	for _, match := range sparklinePointPattern.FindAllStringSubmatch(path[1], -1) {
+		x, _ := strconv.ParseFloat(match[1], 64)
+		y, _ := strconv.ParseFloat(match[2], 64)
+		points = append(points, [2]float64{x, y})
+	}
+	return points
+}
+
+func TestReadingSparklineWithoutHistoryRendersNothing(t *testing.T) {
+	if svg := readingSparkline(nil); svg != "" {
+		t.Fatalf("expected no sparkline, got %s", svg)
+	}
+	if weeks := readingHistorySeries(nil); len(weeks) != 0 {
+		t.Fatalf("expected no weeks, got %+v", weeks)
+	}
+}
+
+func TestReadingSparklineForASingleRead(t *testing.T) {
+	weeks := readingHistorySeries([]models.ReadEvent{
+		{ToChapter: 1, ReadAt: time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)},
+	})
+	svg := string(readingSparkline(weeks))
+
+	points := sparklinePoints(t, svg)
+	if len(points) != 2 || points[0][1] != points[1][1] || points[0][0] != 0 || points[1][0] != sparklineWidth {
+		t.Fatalf("expected a flat line across the sparkline, got %v in %s", points, svg)
+	}
+	if !strings.Contains(svg, "<circle") || !strings.Contains(svg, `aria-label="0 chapters read over 1 week"`) {
+		t.Fatalf("expected a dot and a label for the single week, got %s", svg)
+	}
+}
+
+func TestReadingSparklineScalesADenseBurst(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	events := []models.ReadEvent{}
+	// One read a week for ten weeks, with a 40-chapter binge in the fifth.
+	for week := 0; week < 10; week++ {
+		events = append(events, models.ReadEvent{Chapters: 1, ReadAt: start.AddDate(0, 0, 7*week)})
+	}
+	for hour := 0; hour < 40; hour++ {
+		events = append(events, models.ReadEvent{Chapters: 1, ReadAt: start.AddDate(0, 0, 28).Add(time.Duration(hour) * time.Minute)})
+	}
+	weeks := readingHistorySeries(events)
+	if len(weeks) != 10 || weeks[4].Chapters != 41 || weeks[4].Reads != 41 {
+		t.Fatalf("expected the burst bucketed into the fifth week, got %+v", weeks)
+	}
+
+	svg := string(readingSparkline(weeks))
+	points := sparklinePoints(t, svg)
+	if len(points) != 10 || strings.Contains(svg, "<circle") {
+		t.Fatalf("expected one point per week, got %v in %s", points, svg)
+	}
+	for index, point := range points {
+		if point[1] < sparklinePadding || point[1] > sparklineHeight-sparklinePadding {
+			t.Fatalf("point %d outside the sparkline: %v", index, point)
+		}
+		if index != 4 && point[1] <= points[4][1] {
+			t.Fatalf("expected the burst week to be the highest point, got %v", points)
+		}
+	}
+	if points[4][1] != sparklinePadding || points[9][0] != sparklineWidth {
+		t.Fatalf("expected the burst at the top and the last week at the right edge, got %v", points)
+	}
+}
+
+func TestReadingHistorySeriesKeepsTheLatestWeeks(t *testing.T) {
+	// 2024-01-01 is a Monday.
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	events := []models.ReadEvent{
+		{Chapters: 1, ReadAt: start},
+		{Chapters: 1, ReadAt: start.AddDate(0, 0, 7*80)},
+	}
+	weeks := readingHistorySeries(events)
+	if len(weeks) != readingHistoryWeeks || !weeks[len(weeks)-1].Start.Equal(start.AddDate(0, 0, 7*80).Truncate(24*time.Hour)) {
+		t.Fatalf("expected the latest %d weeks, got %d ending %v", readingHistoryWeeks, len(weeks), weeks[len(weeks)-1])
+	}
+}
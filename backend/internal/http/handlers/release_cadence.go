@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/stats"
+	"github.com/gofiber/fiber/v2"
+)
+
+// cadenceChartBarGap is the space between two months' bars.
+const cadenceChartBarGap = 2.0
+
+// releaseCadence is a tracker's chapters per month over the last year and
+// the pace stats drawn from its release history.
+type releaseCadence struct {
+	Months []stats.ReleaseMonth
+	Stats  stats.Cadence
+}
+
+func releaseCadenceOf(history []repository.ChapterRelease, now time.Time) releaseCadence {
+	releases := make([]stats.Release, 0, len(history))
+	for _, release := range history {
+		releases = append(releases, stats.Release{At: release.ReleasedAt, Chapter: release.Chapter})
+	}
+	return releaseCadence{
+		Months: stats.BucketReleasesByMonth(releases, now),
+		Stats:  stats.ReleaseCadence(releases, now),
+	}
+}
+
+// cadenceChart draws monthly release totals as inline SVG bars, the size of
+// the reading sparkline, the busiest month full height. It renders nothing
+// when no month had a release.
+func cadenceChart(months []stats.ReleaseMonth) template.HTML {
+	busiest, total := 0, 0
+	for _, month := range months {
+		busiest = max(busiest, month.Chapters)
+		total += month.Chapters
+	}
+	if busiest == 0 {
+		return ""
+	}
+
+	slot := sparklineWidth / float64(len(months))
+	var bars strings.Builder
+	for index, month := range months {
+		if month.Chapters == 0 {
+			continue
+		}
+		height := float64(month.Chapters) / float64(busiest) * (sparklineHeight - sparklinePadding)
+		title := fmt.Sprintf("%s: %s", month.Start.Format("Jan 2006"), pluralize(month.Chapters, "chapter"))
+		fmt.Fprintf(&bars, `<rect x="%s" y="%s" width="%s" height="%s"><title>%s</title></rect>`,
+			svgNumber(float64(index)*slot), svgNumber(sparklineHeight-height), svgNumber(slot-cadenceChartBarGap), svgNumber(height),
+			template.HTMLEscapeString(title))
+	}
+
+	label := fmt.Sprintf("%s released over %s", pluralize(total, "chapter"), pluralize(len(months), "month"))
+	return template.HTML(fmt.Sprintf(
+		`<svg class="cadence-chart" viewBox="0 0 %s %s" width="%s" height="%s" role="img" aria-label="%s">%s</svg>`,
+		svgNumber(sparklineWidth), svgNumber(sparklineHeight), svgNumber(sparklineWidth), svgNumber(sparklineHeight),
+		template.HTMLEscapeString(label), bars.String(),
+	))
+}
+
+// cadenceSummary words a cadence's stats for the edit modal, such as
+// "A chapter every 7 days · longest gap 41 days · slowing down". It is
+// empty when nothing is known.
+func cadenceSummary(cadence stats.Cadence) string {
+	parts := []string{}
+	if cadence.AverageKnown {
+		parts = append(parts, fmt.Sprintf("A chapter every %s days", svgNumber(cadence.AverageDaysBetween)))
+	}
+	if cadence.HasGap {
+		gap := "longest gap " + pluralize(int(math.Round(cadence.LongestGap.Days())), "day")
+		if cadence.LongestGap.Ongoing {
+			gap += " so far"
+		}
+		parts = append(parts, gap)
+	}
+	switch cadence.Trend {
+	case stats.TrendSpeedingUp:
+		parts = append(parts, "speeding up")
+	case stats.TrendSlowingDown:
+		parts = append(parts, "slowing down")
+	case stats.TrendSteady:
+		parts = append(parts, "steady")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	summary := strings.Join(parts, " · ")
+	return strings.ToUpper(summary[:1]) + summary[1:]
+}
+
+// Cadence returns the tracker's chapters released per month over the last
+// year, oldest first, and its pace: the average days between chapters over
+// the trailing 90 days, the longest gap and the trend. Releases come from
+// the recorded chapter history; a jump of several chapters counts as that
+// many released at once.
+func (h *TrackersHandler) Cadence(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid tracker id"})
+	}
+
+	tracker, err := h.repo.GetByID(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to get tracker", err)
+	}
+	if tracker == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
+	}
+
+	history, err := h.repo.ListChapterReleases(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load release history", err)
+	}
+	cadence := releaseCadenceOf(history, time.Now().UTC())
+
+	months := make([]fiber.Map, 0, len(cadence.Months))
+	for _, month := range cadence.Months {
+		months = append(months, fiber.Map{"month": month.Start.Format("2006-01"), "chapters": month.Chapters})
+	}
+	var averageDays any
+	if cadence.Stats.AverageKnown {
+		averageDays = math.Round(cadence.Stats.AverageDaysBetween*10) / 10
+	}
+	var longestGap any
+	if gap := cadence.Stats.LongestGap; cadence.Stats.HasGap {
+		longestGap = fiber.Map{
+			"from":    gap.From,
+			"to":      gap.To,
+			"days":    math.Round(gap.Days()*10) / 10,
+			"ongoing": gap.Ongoing,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"trackerId": tracker.ID,
+		"months":    months,
+		"stats": fiber.Map{
+			"averageDaysBetween": averageDays,
+			"longestGap":         longestGap,
+			"trend":              cadence.Stats.Trend,
+		},
+	})
+}
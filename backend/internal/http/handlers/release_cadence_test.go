@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/stats"
+)
+
+var cadenceBarPattern = regexp.MustCompile(`<rect x="([0-9.]+)" y="([0-9.]+)" width="[0-9.]+" height="([0-9.]+)"><title>([^<]*)</title>`)
+
+func TestCadenceChartWithoutReleasesRendersNothing(t *testing.T) {
+	cadence := releaseCadenceOf(nil, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC))
+	if svg := cadenceChart(cadence.Months); svg != "" {
+		t.Fatalf("expected no chart, got %s", svg)
+	}
+	if summary := cadenceSummary(cadence.Stats); summary != "" {
+		t.Fatalf("expected no summary, got %q", summary)
+	}
+}
+
+func TestCadenceChartDrawsABarPerMonthWithReleases(t *testing.T) {
+	now := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	history := []repository.ChapterRelease{
+		{Chapter: 10, ReleasedAt: time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{Chapter: 11, ReleasedAt: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)},
+		// Three chapters at once count as three.
+		{Chapter: 14, ReleasedAt: time.Date(2026, 9, 3, 0, 0, 0, 0, time.UTC)},
+	}
+	cadence := releaseCadenceOf(history, now)
+	svg := string(cadenceChart(cadence.Months))
+
+	bars := cadenceBarPattern.FindAllStringSubmatch(svg, -1)
+	if len(bars) != 2 {
+		t.Fatalf("expected bars for January and September only, got %s", svg)
+	}
+	if bars[0][4] != "Jan 2026: 2 chapters" || bars[1][4] != "Sep 2026: 3 chapters" {
+		t.Fatalf("expected month titles, got %q and %q", bars[0][4], bars[1][4])
+	}
+	january, _ := strconv.ParseFloat(bars[0][3], 64)
+	september, _ := strconv.ParseFloat(bars[1][3], 64)
+	if bars[1][2] != svgNumber(sparklinePadding) || january >= september {
+		t.Fatalf("expected September full height and January shorter, got %s", svg)
+	}
+	if !strings.Contains(svg, `aria-label="5 chapters released over 12 months"`) {
+		t.Fatalf("expected a label for the year, got %s", svg)
+	}
+
+	summary := cadenceSummary(cadence.Stats)
+	if summary != "A chapter every 77 days · longest gap 231 days · speeding up" {
+		t.Fatalf("unexpected summary %q", summary)
+	}
+}
+
+func TestCadenceSummaryOfAnOngoingGap(t *testing.T) {
+	summary := cadenceSummary(stats.Cadence{
+		HasGap: true,
+		LongestGap: stats.ReleaseGap{
+			From:    time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC),
+			To:      time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			Ongoing: true,
+		},
+		Trend: stats.TrendUnknown,
+	})
+	if summary != "Longest gap 61 days so far" {
+		t.Fatalf("unexpected summary %q", summary)
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/stats"
+	"github.com/gofiber/fiber/v2"
+)
+
+// releaseCalendarWeek is the calendar's column order.
+var releaseCalendarWeek = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+type releaseCalendarEntry struct {
+	Card            trackerCardView
+	ConfidenceLabel string
+	Samples         int
+}
+
+type releaseCalendarColumn struct {
+	Key     string
+	Label   string
+	Entries []releaseCalendarEntry
+}
+
+type releaseCalendarPageData struct {
+	ActiveProfile *models.Profile
+	Columns       []releaseCalendarColumn
+	Unknown       releaseCalendarColumn
+}
+
+// predictReleaseWeekdays predicts the release weekday of each of the
+// profile's reading trackers from its recorded releases. A current latest
+// release the history doesn't hold yet counts as one more sample.
+func predictReleaseWeekdays(ctx context.Context, repo *repository.TrackerRepository, profileID int64) ([]models.Tracker, map[int64]stats.WeekdayPrediction, error) {
+	trackers, err := repo.List(ctx, repository.TrackerListOptions{ProfileID: profileID, Statuses: []string{"reading"}})
+	if err != nil {
+		return nil, nil, err
+	}
+	history, err := repo.ListRecentReleaseTimes(ctx, profileID, "reading", stats.ReleaseWeekdayWindow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	predictions := make(map[int64]stats.WeekdayPrediction, len(trackers))
+	for _, tracker := range trackers {
+		releases := history[tracker.ID]
+		if tracker.LatestReleaseAt != nil {
+			releases = append(releases, *tracker.LatestReleaseAt)
+		}
+		predictions[tracker.ID] = stats.PredictReleaseWeekday(releases)
+	}
+	return trackers, predictions, nil
+}
+
+// ReleaseSchedule returns the predicted release weekday of each of the
+// profile's reading trackers.
+func (h *TrackersHandler) ReleaseSchedule(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	trackers, predictions, err := predictReleaseWeekdays(c.UserContext(), h.repo, profile.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to predict release schedule", err)
+	}
+
+	items := make([]models.TrackerReleaseSchedule, 0, len(trackers))
+	for _, tracker := range trackers {
+		prediction := predictions[tracker.ID]
+		item := models.TrackerReleaseSchedule{TrackerID: tracker.ID, Title: tracker.Title, Samples: prediction.Samples}
+		if prediction.Known {
+			weekday := strings.ToLower(prediction.Weekday.String())
+			item.Weekday = &weekday
+			item.Confidence = prediction.Confidence
+		}
+		items = append(items, item)
+	}
+
+	return c.JSON(fiber.Map{"items": items})
+}
+
+// ReleaseCalendarPage renders the profile's reading trackers in one column
+// per predicted release weekday, with the rest under irregular/unknown.
+func (h *DashboardHandler) ReleaseCalendarPage(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+
+	trackers, predictions, err := predictReleaseWeekdays(c.UserContext(), h.trackerRepo, activeProfile.ID)
+	if err != nil {
+		return serverError(c, "Failed to predict release schedule", err)
+	}
+	sourceByID, err := pageCtx.SourceByID()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	sourceLogoBySourceID, err := pageCtx.SourceLogos()
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	cards, _ := h.buildTrackerCards(c.UserContext(), trackers, sourceByID, sourceLogoBySourceID, "")
+
+	data := releaseCalendarPageData{
+		ActiveProfile: activeProfile,
+		Columns:       make([]releaseCalendarColumn, len(releaseCalendarWeek)),
+		Unknown:       releaseCalendarColumn{Key: "unknown", Label: "Irregular / unknown"},
+	}
+	columnByWeekday := make(map[time.Weekday]int, len(releaseCalendarWeek))
+	for index, weekday := range releaseCalendarWeek {
+		data.Columns[index] = releaseCalendarColumn{Key: strings.ToLower(weekday.String()), Label: weekday.String()}
+		columnByWeekday[weekday] = index
+	}
+	for index, tracker := range trackers {
+		prediction := predictions[tracker.ID]
+		entry := releaseCalendarEntry{Card: cards[index], Samples: prediction.Samples}
+		if !prediction.Known {
+			data.Unknown.Entries = append(data.Unknown.Entries, entry)
+			continue
+		}
+		entry.ConfidenceLabel = fmt.Sprintf("%d%%", int(math.Round(prediction.Confidence*100)))
+		column := &data.Columns[columnByWeekday[prediction.Weekday]]
+		column.Entries = append(column.Entries, entry)
+	}
+
+	c.Set("Cache-Control", "no-store, no-cache, must-revalidate")
+	return h.render(c, "release_calendar_page.html", data)
+}
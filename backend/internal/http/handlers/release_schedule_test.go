@@ -0,0 +1,161 @@
+package handlers_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/thumbnails"
+)
+
+// seedReleaseHistory adds three profile1 trackers: Weekly Wednesday
+// (reading, four Wednesday releases and one Friday), Fresh Start (reading,
+// only its current latest release) and Done Deal (completed, Wednesdays).
+func seedReleaseHistory(t *testing.T, db *sql.DB) (weeklyID int64, freshID int64) {
+	t.Helper()
+
+	sourceID, _ := sourceMetaByKey(t, db, "mangadex")
+	wednesday := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	insert := func(title string, status string, latestReleaseAt time.Time) int64 {
+		result, err := db.Exec(`
+			INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter, latest_release_at)
+			VALUES (1, ?, ?, ?, ?, 5, ?)
+		`, title, sourceID, "https://mangadex.org/title/"+strings.ReplaceAll(strings.ToLower(title), " ", "-"), status, latestReleaseAt)
+		if err != nil {
+			t.Fatalf("seed %s: %v", title, err)
+		}
+		id, _ := result.LastInsertId()
+		return id
+	}
+	weeklyID = insert("Weekly Wednesday", "reading", wednesday.AddDate(0, 0, 21))
+	freshID = insert("Fresh Start", "reading", wednesday)
+	doneID := insert("Done Deal", "completed", wednesday)
+
+	releases := []time.Time{wednesday, wednesday.AddDate(0, 0, 7), wednesday.AddDate(0, 0, 9), wednesday.AddDate(0, 0, 14), wednesday.AddDate(0, 0, 21)}
+	for index, releasedAt := range releases {
+		for _, trackerID := range []int64{weeklyID, doneID} {
+			if _, err := db.Exec(`INSERT INTO chapters (tracker_id, chapter_number, released_at) VALUES (?, ?, ?)`, trackerID, index+1, releasedAt); err != nil {
+				t.Fatalf("seed chapter: %v", err)
+			}
+		}
+	}
+	return weeklyID, freshID
+}
+
+func TestReleaseScheduleAPIPredictsWeekdays(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	weeklyID, freshID := seedReleaseHistory(t, db)
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers/release-schedule?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("release schedule request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	var payload struct {
+		Items []struct {
+			TrackerID  int64   `json:"trackerId"`
+			Weekday    *string `json:"weekday"`
+			Confidence float64 `json:"confidence"`
+			Samples    int     `json:"samples"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode release schedule: %v", err)
+	}
+	if len(payload.Items) != 2 {
+		t.Fatalf("expected the two reading trackers, got %+v", payload.Items)
+	}
+	for _, item := range payload.Items {
+		switch item.TrackerID {
+		case weeklyID:
+			if item.Weekday == nil || *item.Weekday != "wednesday" || item.Samples != 5 || item.Confidence != 0.8 {
+				t.Fatalf("expected wednesday from 4 of 5 releases, got %+v", item)
+			}
+		case freshID:
+			if item.Weekday != nil || item.Samples != 1 {
+				t.Fatalf("expected one sample to stay unknown, got %+v", item)
+			}
+		default:
+			t.Fatalf("unexpected tracker %d in schedule", item.TrackerID)
+		}
+	}
+}
+
+func TestReleaseCalendarPageGroupsByWeekday(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	seedReleaseHistory(t, db)
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/calendar?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("release calendar request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+	html := string(body)
+
+	if count := strings.Count(html, `class="release-calendar-day"`); count != 8 {
+		t.Fatalf("expected seven weekday columns and the unknown bucket, got %d", count)
+	}
+	column := func(key string) string {
+		start := strings.Index(html, fmt.Sprintf(`data-weekday="%s"`, key))
+		if start < 0 {
+			t.Fatalf("missing %s column in: %s", key, html)
+		}
+		end := strings.Index(html[start+1:], `data-weekday="`)
+		if end < 0 {
+			return html[start:]
+		}
+		return html[start : start+1+end]
+	}
+	if wednesday := column("wednesday"); !strings.Contains(wednesday, "Weekly Wednesday") || !strings.Contains(wednesday, "80% of 5 releases") {
+		t.Fatalf("expected Weekly Wednesday under wednesday, got: %s", wednesday)
+	}
+	if unknown := column("unknown"); !strings.Contains(unknown, "Fresh Start") || !strings.Contains(unknown, "1 release on record") {
+		t.Fatalf("expected Fresh Start under unknown, got: %s", unknown)
+	}
+	if strings.Contains(html, "Done Deal") {
+		t.Fatalf("expected completed trackers left off the calendar")
+	}
+}
+
+func TestReleaseCalendarPageUsesBasePathForThumbnails(t *testing.T) {
+	db, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", BasePath: "/manga", CoverThumbnailStorage: "db"})
+	defer cleanup()
+	weeklyID, _ := seedReleaseHistory(t, db)
+
+	key := thumbnails.KeyFor("mangadex", "https://mangadex.org/title/weekly-wednesday", nil)
+	if err := thumbnails.NewDBStore(repository.NewCoverThumbnailRepository(db)).Save(key, fixtureThumbnail(t), thumbnails.ContentType); err != nil {
+		t.Fatalf("store thumbnail: %v", err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/manga/dashboard/calendar?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("release calendar request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+	html := string(body)
+
+	if !strings.Contains(html, fmt.Sprintf(`src="/manga/covers/thumb/%d?v=`, weeklyID)) {
+		t.Fatalf("expected the calendar thumbnail under the base path, got %s", html)
+	}
+	if strings.Contains(html, `src="/covers/thumb/`) {
+		t.Fatalf("expected no thumbnail outside the base path")
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/logging"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	requestIDLocalKey  = "requestID"
+	requestLogLocalKey = "requestLogger"
+	profileIDLocalKey  = "profileID"
+	maxRequestIDLength = 64
+)
+
+// RequestLogger assigns every request an ID, honouring a well-formed incoming
+// X-Request-ID, echoes it on the response and logs one line per request once
+// the handler chain finishes. The request-scoped logger is stored on the
+// fiber context and on the user context, so connector calls made with
+// c.UserContext() carry the ID too.
+func RequestLogger(logger *slog.Logger) fiber.Handler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(c *fiber.Ctx) error {
+		startedAt := time.Now()
+		requestID := sanitizeRequestID(c.Get(requestIDHeader))
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+
+		requestLog := logger.With("requestId", requestID)
+		c.Locals(requestIDLocalKey, requestID)
+		c.Locals(requestLogLocalKey, requestLog)
+		c.SetUserContext(logging.WithRequest(c.UserContext(), requestID, requestLog))
+		c.Set(requestIDHeader, requestID)
+
+		chainErr := c.Next()
+		if chainErr != nil {
+			// Let the app's error handler pick the status now so the log line
+			// reports what the client actually receives.
+			if handlerErr := c.App().ErrorHandler(c, chainErr); handlerErr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		attrs := []any{
+			"method", c.Method(),
+			"path", c.Path(),
+			"status", status,
+			"durationMs", time.Since(startedAt).Milliseconds(),
+		}
+		if profileID, ok := c.Locals(profileIDLocalKey).(int64); ok {
+			attrs = append(attrs, "profileId", profileID)
+		}
+		if chainErr != nil {
+			attrs = append(attrs, "error", chainErr)
+		}
+
+		// Handlers that answer through serverError have already logged the
+		// cause at error level; an error returned up the chain has not.
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError && chainErr != nil:
+			level = slog.LevelError
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelWarn
+		}
+		requestLog.Log(c.UserContext(), level, "request completed", attrs...)
+		return nil
+	}
+}
+
+// requestLogger returns the logger RequestLogger attached to this request, or
+// slog.Default() when the middleware is not installed.
+func requestLogger(c *fiber.Ctx) *slog.Logger {
+	if logger, ok := c.Locals(requestLogLocalKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// serverError logs err against the request and responds with a plain-text
+// 500 carrying message, for the dashboard's htmx endpoints.
+func serverError(c *fiber.Ctx, message string, err error) error {
+	requestLogger(c).Error(message, "error", err)
+	return c.Status(fiber.StatusInternalServerError).SendString(message)
+}
+
+// serverErrorJSON is serverError for the JSON API.
+func serverErrorJSON(c *fiber.Ctx, message string, err error) error {
+	requestLogger(c).Error(message, "error", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": message})
+}
+
+func sanitizeRequestID(raw string) string {
+	if raw == "" || len(raw) > maxRequestIDLength {
+		return ""
+	}
+	for _, r := range raw {
+		isAlnum := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isAlnum && r != '-' && r != '_' && r != '.' {
+			return ""
+		}
+	}
+	return raw
+}
+
+func newRequestID() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/logging"
+	"github.com/gofiber/fiber/v2"
+)
+
+func newRequestLoggingTestApp(logs *bytes.Buffer) *fiber.App {
+	app := fiber.New()
+	app.Use(RequestLogger(slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+	app.Get("/request-id", func(c *fiber.Ctx) error {
+		return c.SendString(logging.RequestID(c.UserContext()))
+	})
+	return app
+}
+
+func decodeLogLines(t *testing.T, logs *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if raw == "" {
+			continue
+		}
+		var line map[string]any
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			t.Fatalf("decode log line %q: %v", raw, err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRequestLoggerPropagatesRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "honours incoming id", incoming: "abc-123.DEF_4", wantSame: true},
+		{name: "generates id when missing", incoming: ""},
+		{name: "replaces malformed id", incoming: "bad id\"}"},
+		{name: "replaces oversized id", incoming: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			app := newRequestLoggingTestApp(&logs)
+
+			req := httptest.NewRequest("GET", "/request-id", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestIDHeader, tt.incoming)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+
+			requestID := resp.Header.Get(requestIDHeader)
+			if tt.wantSame && requestID != tt.incoming {
+				t.Fatalf("expected incoming request id %q to be echoed, got %q", tt.incoming, requestID)
+			}
+			if !tt.wantSame && (requestID == tt.incoming || len(requestID) != 16) {
+				t.Fatalf("expected a generated request id, got %q", requestID)
+			}
+			if string(body) != requestID {
+				t.Fatalf("expected user context to carry request id %q, got %q", requestID, string(body))
+			}
+
+			lines := decodeLogLines(t, &logs)
+			if len(lines) != 1 {
+				t.Fatalf("expected one access log line, got %d: %s", len(lines), logs.String())
+			}
+			line := lines[0]
+			if line["requestId"] != requestID || line["method"] != "GET" || line["path"] != "/request-id" || line["status"] != float64(fiber.StatusOK) {
+				t.Fatalf("unexpected access log line: %v", line)
+			}
+			if _, ok := line["durationMs"]; !ok {
+				t.Fatalf("expected access log line to include duration, got %v", line)
+			}
+		})
+	}
+}
+
+func TestServerErrorLogsUnderlyingErrorOnce(t *testing.T) {
+	db, _ := setupInternalDashboardHandler(t, connectors.NewRegistry())
+	if _, err := db.Exec(`DROP TABLE app_settings`); err != nil {
+		t.Fatalf("drop settings table: %v", err)
+	}
+
+	var logs bytes.Buffer
+	app := newRequestLoggingTestApp(&logs)
+	app.Get("/v1/settings/scraping-paused", NewSettingsHandler(db).GetScrapingPaused)
+
+	req := httptest.NewRequest("GET", "/v1/settings/scraping-paused", nil)
+	req.Header.Set(requestIDHeader, "req-settings-1")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", resp.StatusCode)
+	}
+
+	var errorLines []map[string]any
+	for _, line := range decodeLogLines(t, &logs) {
+		if line["requestId"] != "req-settings-1" {
+			t.Fatalf("expected every log line to carry the request id, got %v", line)
+		}
+		if line["level"] == "ERROR" {
+			errorLines = append(errorLines, line)
+		}
+	}
+	if len(errorLines) != 1 {
+		t.Fatalf("expected exactly one error log line, got %d: %s", len(errorLines), logs.String())
+	}
+	if errText, _ := errorLines[0]["error"].(string); !strings.Contains(errText, "app_settings") {
+		t.Fatalf("expected error log line to include the underlying error, got %v", errorLines[0])
+	}
+}
+
+func TestRequestLoggerLogsReturnedHandlerErrors(t *testing.T) {
+	var logs bytes.Buffer
+	app := newRequestLoggingTestApp(&logs)
+	app.Get("/boom", func(c *fiber.Ctx) error {
+		return io.ErrUnexpectedEOF
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/boom", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", resp.StatusCode)
+	}
+
+	lines := decodeLogLines(t, &logs)
+	if len(lines) != 1 || lines[0]["level"] != "ERROR" || lines[0]["status"] != float64(fiber.StatusInternalServerError) {
+		t.Fatalf("expected one error access log line, got %s", logs.String())
+	}
+	if lines[0]["error"] != io.ErrUnexpectedEOF.Error() {
+		t.Fatalf("expected returned error in log line, got %v", lines[0])
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StartReread starts a completed tracker over from chapter 0 and returns
+// it. The finished read-through is kept in previousCompletions; setting the
+// last read chapter back to the latest known one, or the status back to
+// completed, finishes the re-read.
+func (h *TrackersHandler) StartReread(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid tracker id"})
+	}
+
+	started, err := h.repo.StartReread(c.UserContext(), profile.ID, id, time.Now())
+	if err != nil {
+		return serverErrorJSON(c, "failed to start re-read", err)
+	}
+
+	tracker, err := h.repo.GetByID(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to get tracker", err)
+	}
+	if tracker == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
+	}
+	if !started {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "only completed trackers can be re-read"})
+	}
+	if h.editForms != nil {
+		h.editForms.ForgetEditForm(profile.ID, id)
+	}
+
+	return c.JSON(tracker)
+}
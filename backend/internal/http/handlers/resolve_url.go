@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ResolveURL returns the tracker the url query parameter belongs to, by its
+// current primary or linked URLs or the URLs it was moved away from. URLs
+// match regardless of scheme, case, fragment and trailing slashes.
+func (h *TrackersHandler) ResolveURL(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	rawURL := strings.TrimSpace(c.Query("url"))
+	if rawURL == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "url is required"})
+	}
+
+	resolved, err := h.repo.ResolveTrackerURL(c.UserContext(), profile.ID, rawURL)
+	if err != nil {
+		return serverErrorJSON(c, "failed to resolve url", err)
+	}
+	if resolved == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "no tracker has this url"})
+	}
+
+	return c.JSON(resolved)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"database/sql"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+type scrapingPausedRequest struct {
+	Paused *bool `json:"paused"`
+}
+
+type SettingsHandler struct {
+	repo *repository.SettingsRepository
+}
+
+func NewSettingsHandler(db *sql.DB) *SettingsHandler {
+	return &SettingsHandler{repo: repository.NewSettingsRepository(db)}
+}
+
+func (h *SettingsHandler) GetScrapingPaused(c *fiber.Ctx) error {
+	paused, err := h.repo.ScrapingPaused()
+	if err != nil {
+		return serverErrorJSON(c, "failed to load scraping pause state", err)
+	}
+	return c.JSON(fiber.Map{"paused": paused})
+}
+
+func (h *SettingsHandler) SetScrapingPaused(c *fiber.Ctx) error {
+	var req scrapingPausedRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid json body"})
+	}
+	if req.Paused == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "paused is required"})
+	}
+
+	if err := h.repo.SetScrapingPaused(*req.Paused); err != nil {
+		return serverErrorJSON(c, "failed to save scraping pause state", err)
+	}
+	return c.JSON(fiber.Map{"paused": *req.Paused})
+}
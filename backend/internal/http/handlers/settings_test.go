@@ -0,0 +1,66 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestScrapingPausedSettingRoundTrip(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	readPaused := func(res *http.Response) bool {
+		t.Helper()
+		if res.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(res.Body)
+			t.Fatalf("expected 200, got %d (body: %s)", res.StatusCode, string(body))
+		}
+		var payload struct {
+			Paused bool `json:"paused"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return payload.Paused
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/settings/scraping-paused", nil))
+	if err != nil {
+		t.Fatalf("get setting request failed: %v", err)
+	}
+	if readPaused(res) {
+		t.Fatalf("expected scraping to start unpaused")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/settings/scraping-paused", strings.NewReader(`{"paused":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("set setting request failed: %v", err)
+	}
+	if !readPaused(res) {
+		t.Fatalf("expected paused in set response")
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/v1/settings/scraping-paused", nil))
+	if err != nil {
+		t.Fatalf("get setting request failed: %v", err)
+	}
+	if !readPaused(res) {
+		t.Fatalf("expected paused setting to persist")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/settings/scraping-paused", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("invalid set request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for missing paused flag, got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// sourceURLError is a source URL rejected by the selected source's connector.
+// Suggested is set when exactly one other enabled source claims the URL.
+type sourceURLError struct {
+	message   string
+	Suggested *models.Source
+}
+
+func (e *sourceURLError) Error() string {
+	return e.message
+}
+
+// canonicalSourceURL checks sourceURL against the connector of the source
+// with sourceID and returns the connector's canonical form of it. Rejections
+// are returned as *sourceURLError; any other error is a lookup failure.
+func canonicalSourceURL(ctx context.Context, registry Resolver, sourceRepo *repository.SourceRepository, sourceID int64, sourceURL string) (string, error) {
+	source, err := sourceRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		return "", err
+	}
+	if source == nil {
+		// Unknown sources are rejected by the caller's SourceExists check.
+		return strings.TrimSpace(sourceURL), nil
+	}
+
+	canonical, err := registry.ValidateSourceURL(source.Key, sourceURL)
+	if err == nil {
+		return canonical, nil
+	}
+
+	var mismatch *connectors.HostMismatchError
+	if !errors.As(err, &mismatch) {
+		return "", &sourceURLError{message: fmt.Sprintf("Source URL is not a valid %s link: %v", source.Name, err)}
+	}
+
+	urlErr := &sourceURLError{
+		message: fmt.Sprintf("Source URL does not belong to %s (expected %s)", source.Name, strings.Join(mismatch.ExpectedHosts, " or ")),
+	}
+
+	enabled, err := sourceRepo.ListEnabled(ctx)
+	if err != nil {
+		return "", err
+	}
+	var claims []models.Source
+	for _, candidate := range enabled {
+		if candidate.ID != source.ID && registry.URLBelongsTo(candidate.Key, sourceURL) {
+			claims = append(claims, candidate)
+		}
+	}
+	if len(claims) == 1 {
+		urlErr.Suggested = &claims[0]
+		urlErr.message += fmt.Sprintf("; it looks like a %s link, switch the source to %s", claims[0].Name, claims[0].Name)
+	}
+
+	return "", urlErr
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxSourceNoteLength is the longest source status note the dashboard and
+// the API accept.
+const maxSourceNoteLength = 280
+
+// Search result limits for GET /v1/sources/:id/search.
+const (
+	defaultSourceSearchLimit = 8
+	maxSourceSearchLimit     = 25
+)
+
+type sourceNoteRequest struct {
+	Note string `json:"note"`
+}
+
+// SourcesHandler serves the sources, their status notes and their title
+// search over the JSON API. Sources are shared by all profiles, and so are
+// their notes.
+type SourcesHandler struct {
+	repo         *repository.SourceRepository
+	settingsRepo *repository.SettingsRepository
+	registry     *connectors.Registry
+}
+
+func NewSourcesHandler(db *sql.DB, registry *connectors.Registry) *SourcesHandler {
+	if registry == nil {
+		registry = connectors.NewRegistry()
+	}
+	return &SourcesHandler{
+		repo:         repository.NewSourceRepository(db),
+		settingsRepo: repository.NewSettingsRepository(db),
+		registry:     registry,
+	}
+}
+
+func (h *SourcesHandler) List(c *fiber.Ctx) error {
+	sources, err := h.repo.ListEnabled(c.UserContext())
+	if err != nil {
+		return serverErrorJSON(c, "failed to list sources", err)
+	}
+	return c.JSON(fiber.Map{"items": sources})
+}
+
+// SetNote replaces the source's status note with a manual one; an empty
+// note clears it.
+func (h *SourcesHandler) SetNote(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid source id"})
+	}
+
+	var req sourceNoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid json body"})
+	}
+	note, err := validateSourceNote(req.Note)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	updated, err := h.repo.SetStatusNote(c.UserContext(), id, note)
+	if err != nil {
+		return serverErrorJSON(c, "failed to save source note", err)
+	}
+	if !updated {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "source not found"})
+	}
+
+	source, err := h.repo.GetByID(c.UserContext(), id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load source", err)
+	}
+	return c.JSON(source)
+}
+
+// Search runs the source connector's title search, the JSON counterpart of
+// the add-tracker search box. Failures the client can act on carry a code
+// under "error": url_required, scraping_paused, timeout, search_failed or rate_limited.
+func (h *SourcesHandler) Search(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid source id"})
+	}
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "q is required"})
+	}
+	limit, err := parseSourceSearchLimit(c.Query("limit"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	source, err := h.repo.GetByID(c.UserContext(), id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load source", err)
+	}
+	if source == nil || !source.Enabled {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "source not found or disabled"})
+	}
+	connector, ok := h.registry.Get(source.Key)
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "no connector registered for source"})
+	}
+	if source.SearchMode == connectors.SearchModeURLOnly {
+		return searchErrorJSON(c, fiber.StatusUnprocessableEntity, "url_required", source.Name+" cannot be searched by title; resolve the series URL instead")
+	}
+
+	paused, err := h.settingsRepo.ScrapingPaused()
+	if err != nil {
+		return serverErrorJSON(c, "failed to read scraping pause state", err)
+	}
+	if paused {
+		return searchErrorJSON(c, fiber.StatusServiceUnavailable, "scraping_paused", connectors.ErrScrapingPaused.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), sourceSearchTimeout(source.Key))
+	defer cancel()
+	results, err := connector.SearchByTitle(ctx, query, limit)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return searchErrorJSON(c, fiber.StatusGatewayTimeout, "timeout", source.Name+" search timed out")
+	case err != nil:
+		return searchErrorJSON(c, fiber.StatusBadGateway, "search_failed", "search failed for this source: "+err.Error())
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	if results == nil {
+		results = []connectors.MangaResult{}
+	}
+	return c.JSON(fiber.Map{"items": results})
+}
+
+// SearchRateLimited answers a search the rate limiter rejected, keeping the
+// limiter's 429 status and Retry-After header.
+func (h *SourcesHandler) SearchRateLimited(c *fiber.Ctx, retryAfter time.Duration) error {
+	return searchErrorJSON(c, fiber.StatusTooManyRequests, "rate_limited", "too many searches, try again in "+pluralize(retryAfterSeconds(retryAfter), "second"))
+}
+
+// parseSourceSearchLimit defaults a blank limit and clamps the rest to
+// 1..maxSourceSearchLimit.
+func parseSourceSearchLimit(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultSourceSearchLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, errors.New("limit must be a number")
+	}
+	return min(max(limit, 1), maxSourceSearchLimit), nil
+}
+
+func searchErrorJSON(c *fiber.Ctx, status int, code string, message string) error {
+	return c.Status(status).JSON(fiber.Map{"message": message, "error": fiber.Map{"code": code}})
+}
+
+func validateSourceNote(raw string) (string, error) {
+	note := strings.TrimSpace(raw)
+	if utf8.RuneCountInString(note) > maxSourceNoteLength {
+		return "", fmt.Errorf("note must be %d characters or less", maxSourceNoteLength)
+	}
+	return note, nil
+}
@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	apihttp "github.com/gabriel/cross-site-tracker/backend/internal/http"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// searchStubConnector returns count search results, or err once the search
+// has recorded its deadline.
+type searchStubConnector struct {
+	key       string
+	count     int
+	err       error
+	lastLimit int
+	deadline  time.Duration
+}
+
+func (s *searchStubConnector) Key() string                       { return s.key }
+func (s *searchStubConnector) Name() string                      { return "Stub " + s.key }
+func (s *searchStubConnector) Kind() string                      { return connectors.KindNative }
+func (s *searchStubConnector) HealthCheck(context.Context) error { return nil }
+func (s *searchStubConnector) ResolveByURL(context.Context, string) (*connectors.MangaResult, error) {
+	return nil, nil
+}
+func (s *searchStubConnector) SearchByTitle(ctx context.Context, title string, limit int) ([]connectors.MangaResult, error) {
+	s.lastLimit = limit
+	if deadline, ok := ctx.Deadline(); ok {
+		s.deadline = time.Until(deadline)
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	updatedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	chapter := 12.5
+	results := make([]connectors.MangaResult, 0, s.count)
+	for index := 0; index < s.count; index++ {
+		results = append(results, connectors.MangaResult{
+			SourceKey:     s.key,
+			SourceItemID:  strconv.Itoa(index),
+			Title:         title + " " + strconv.Itoa(index),
+			URL:           "https://example.com/" + strconv.Itoa(index),
+			LatestChapter: &chapter,
+			LastUpdatedAt: &updatedAt,
+		})
+	}
+	return results, nil
+}
+
+func TestSourceSearchAPI(t *testing.T) {
+	mangadex := &searchStubConnector{key: "mangadex", count: 40}
+	mangafire := &searchStubConnector{key: "mangafire", err: context.DeadlineExceeded}
+	registry := connectors.NewRegistry()
+	_ = registry.Register(mangadex)
+	_ = registry.Register(mangafire)
+	db, app, cleanup := setupTestAppWithServer(t, config.Config{AppName: "test"}, func(cfg config.Config, db *sql.DB) *fiber.App {
+		return apihttp.NewServerWithRegistry(cfg, db, registry)
+	})
+	defer cleanup()
+
+	sourceID := func(key string) string {
+		t.Helper()
+		var id int64
+		if err := db.QueryRow(`SELECT id FROM sources WHERE key = ?`, key).Scan(&id); err != nil {
+			t.Fatalf("load %s source: %v", key, err)
+		}
+		return strconv.FormatInt(id, 10)
+	}
+	search := func(path string) (int, map[string]any) {
+		t.Helper()
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil), -1)
+		if err != nil {
+			t.Fatalf("search request failed: %v", err)
+		}
+		payload := map[string]any{}
+		_ = json.NewDecoder(res.Body).Decode(&payload)
+		return res.StatusCode, payload
+	}
+	errorCode := func(payload map[string]any) string {
+		nested, _ := payload["error"].(map[string]any)
+		code, _ := nested["code"].(string)
+		return code
+	}
+
+	t.Run("returns results as JSON with a clamped limit", func(t *testing.T) {
+		status, payload := search("/v1/sources/" + sourceID("mangadex") + "/search?q=Solo&limit=100")
+		items, _ := payload["items"].([]any)
+		if status != http.StatusOK || len(items) != 25 || mangadex.lastLimit != 25 {
+			t.Fatalf("expected 25 results, got %d with %d items and limit %d", status, len(items), mangadex.lastLimit)
+		}
+		first, _ := items[0].(map[string]any)
+		if first["title"] != "Solo 0" || first["sourceItemId"] != "0" || first["latestChapter"] != 12.5 || first["lastUpdatedAt"] != "2026-03-04T05:06:07Z" {
+			t.Fatalf("unexpected result shape: %v", first)
+		}
+
+		if status, _ := search("/v1/sources/" + sourceID("mangadex") + "/search?q=Solo&limit=0"); status != http.StatusOK || mangadex.lastLimit != 1 {
+			t.Fatalf("expected limit 0 to clamp to 1, got %d with limit %d", status, mangadex.lastLimit)
+		}
+		if status, _ := search("/v1/sources/" + sourceID("mangadex") + "/search?q=Solo"); status != http.StatusOK || mangadex.lastLimit != 8 {
+			t.Fatalf("expected the default limit, got %d with limit %d", status, mangadex.lastLimit)
+		}
+	})
+
+	t.Run("rejects bad requests", func(t *testing.T) {
+		for name, path := range map[string]string{
+			"missing query": "/v1/sources/" + sourceID("mangadex") + "/search?q=%20",
+			"bad limit":     "/v1/sources/" + sourceID("mangadex") + "/search?q=Solo&limit=lots",
+			"bad id":        "/v1/sources/abc/search?q=Solo",
+		} {
+			if status, _ := search(path); status != http.StatusBadRequest {
+				t.Fatalf("%s: expected 400, got %d", name, status)
+			}
+		}
+		if status, _ := search("/v1/sources/9999/search?q=Solo"); status != http.StatusNotFound {
+			t.Fatalf("expected 404 for an unknown source, got %d", status)
+		}
+		if _, err := db.Exec(`UPDATE sources SET enabled = 0 WHERE key = 'mangadex'`); err != nil {
+			t.Fatalf("disable source: %v", err)
+		}
+		defer func() { _, _ = db.Exec(`UPDATE sources SET enabled = 1 WHERE key = 'mangadex'`) }()
+		if status, _ := search("/v1/sources/" + sourceID("mangadex") + "/search?q=Solo"); status != http.StatusNotFound {
+			t.Fatalf("expected 404 for a disabled source, got %d", status)
+		}
+	})
+
+	t.Run("maps a timeout to a coded error", func(t *testing.T) {
+		status, payload := search("/v1/sources/" + sourceID("mangafire") + "/search?q=Solo")
+		if status != http.StatusGatewayTimeout || errorCode(payload) != "timeout" {
+			t.Fatalf("expected a timeout error, got %d %v", status, payload)
+		}
+		if mangafire.deadline <= 5*time.Second || mangafire.deadline > 12*time.Second {
+			t.Fatalf("expected mangafire's longer search timeout, got %s", mangafire.deadline)
+		}
+
+		mangadex.err = errors.New("upstream 500")
+		defer func() { mangadex.err = nil }()
+		status, payload = search("/v1/sources/" + sourceID("mangadex") + "/search?q=Solo")
+		if status != http.StatusBadGateway || errorCode(payload) != "search_failed" {
+			t.Fatalf("expected a search_failed error, got %d %v", status, payload)
+		}
+	})
+
+	t.Run("url-only sources ask for a URL", func(t *testing.T) {
+		if _, err := db.Exec(`UPDATE sources SET search_mode = 'url_only' WHERE key = 'mangafire'`); err != nil {
+			t.Fatalf("set search mode: %v", err)
+		}
+		status, payload := search("/v1/sources/" + sourceID("mangafire") + "/search?q=Solo")
+		if status != http.StatusUnprocessableEntity || errorCode(payload) != "url_required" {
+			t.Fatalf("expected a url_required error, got %d %v", status, payload)
+		}
+	})
+
+	t.Run("honours the scraping pause", func(t *testing.T) {
+		if err := repository.NewSettingsRepository(db).SetScrapingPaused(true); err != nil {
+			t.Fatalf("pause scraping: %v", err)
+		}
+		status, payload := search("/v1/sources/" + sourceID("mangadex") + "/search?q=Solo")
+		if status != http.StatusServiceUnavailable || errorCode(payload) != "scraping_paused" {
+			t.Fatalf("expected a scraping_paused error, got %d %v", status, payload)
+		}
+	})
+}
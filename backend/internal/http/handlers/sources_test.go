@@ -0,0 +1,115 @@
+package handlers_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	apihttp "github.com/gabriel/cross-site-tracker/backend/internal/http"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSourceStatusNotesAreEditableAndShown(t *testing.T) {
+	registry := connectors.NewRegistry()
+	_ = registry.Register(&fakeConnector{key: "mangadex"})
+	_ = registry.Register(&fakeConnector{key: "mangafire"})
+	db, app, cleanup := setupTestAppWithServer(t, config.Config{AppName: "test"}, func(cfg config.Config, db *sql.DB) *fiber.App {
+		return apihttp.NewServerWithRegistry(cfg, db, registry)
+	})
+	defer cleanup()
+
+	var mangaDexID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mangadex'`).Scan(&mangaDexID); err != nil {
+		t.Fatalf("load mangadex source: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+		VALUES (1, 'Walled Series', ?, 'https://mangadex.org/title/walled', 'reading')
+	`, mangaDexID); err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	notePath := "/v1/sources/" + strconv.FormatInt(mangaDexID, 10) + "/note"
+
+	putNote := func(path string, note string) (*http.Response, map[string]any) {
+		t.Helper()
+		body, _ := json.Marshal(map[string]string{"note": note})
+		req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("put note: %v", err)
+		}
+		payload := map[string]any{}
+		_ = json.NewDecoder(res.Body).Decode(&payload)
+		return res, payload
+	}
+
+	res, payload := putNote(notePath, "  Cloudflare wall since Monday ")
+	if res.StatusCode != http.StatusOK || payload["statusNote"] != "Cloudflare wall since Monday" || payload["noteSource"] != "manual" {
+		t.Fatalf("expected saved manual note, got %d %v", res.StatusCode, payload)
+	}
+	if res, _ := putNote(notePath, strings.Repeat("x", 281)); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a long note, got %d", res.StatusCode)
+	}
+	if res, _ := putNote("/v1/sources/9999/note", "nope"); res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown source, got %d", res.StatusCode)
+	}
+
+	healthRes, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/connectors/health", nil), -1)
+	if err != nil {
+		t.Fatalf("health request failed: %v", err)
+	}
+	var health struct {
+		Items []struct {
+			Key        string `json:"key"`
+			StatusNote string `json:"statusNote"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(healthRes.Body).Decode(&health); err != nil {
+		t.Fatalf("decode health: %v", err)
+	}
+	notes := map[string]string{}
+	for _, item := range health.Items {
+		notes[item.Key] = item.StatusNote
+	}
+	if notes["mangadex"] != "Cloudflare wall since Monday" || notes["mangafire"] != "" {
+		t.Fatalf("expected the note on mangadex's health item only, got %v", notes)
+	}
+
+	trackersHTML := func() string {
+		t.Helper()
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/trackers?status=all", nil), -1)
+		if err != nil {
+			t.Fatalf("trackers request failed: %v", err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		return string(body)
+	}
+	if html := trackersHTML(); !strings.Contains(html, `class="tracker-source-note"`) || !strings.Contains(html, "Cloudflare wall since Monday") {
+		t.Fatalf("expected a note icon on the card, got %s", html)
+	}
+
+	form := url.Values{"status_note": {""}}
+	req := httptest.NewRequest(http.MethodPost, "/dashboard/sources/"+strconv.FormatInt(mangaDexID, 10)+"/note", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err = app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("clear note request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), "Site note cleared") || res.Header.Get("HX-Trigger") == "" {
+		t.Fatalf("expected the profile menu with a cleared message, got %d %s", res.StatusCode, body)
+	}
+	if html := trackersHTML(); strings.Contains(html, `class="tracker-source-note"`) {
+		t.Fatalf("expected the note icon to be gone, got %s", html)
+	}
+}
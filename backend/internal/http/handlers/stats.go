@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"database/sql"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StatsHandler serves the profile's reading statistics over the JSON API.
+type StatsHandler struct {
+	repo            *repository.TrackerRepository
+	profileResolver *profileContextResolver
+}
+
+func NewStatsHandler(db *sql.DB) *StatsHandler {
+	return &StatsHandler{
+		repo:            repository.NewTrackerRepository(db),
+		profileResolver: newProfileContextResolver(db),
+	}
+}
+
+// Get reports the profile's statistics. readSources counts, per source, how
+// often the last read chapter advanced from that source's links;
+// readChapters sums the chapters read, counting re-reads apart.
+func (h *StatsHandler) Get(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	readSources, err := h.repo.ListReadSourceTotals(c.UserContext(), profile.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load read sources", err)
+	}
+
+	readChapters, err := h.repo.ReadChapterTotals(c.UserContext(), profile.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load read chapters", err)
+	}
+
+	return c.JSON(fiber.Map{"readSources": readSources, "readChapters": readChapters})
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxTagNameLength is the longest tag name the dashboard and the API accept.
+const maxTagNameLength = 40
+
+type tagRequest struct {
+	Name    string  `json:"name"`
+	IconKey *string `json:"iconKey"`
+}
+
+// TagsHandler serves the profile's custom tags over the JSON API.
+type TagsHandler struct {
+	repo            *repository.TrackerRepository
+	profileResolver *profileContextResolver
+	editForms       editFormInvalidator
+}
+
+func NewTagsHandler(db *sql.DB) *TagsHandler {
+	return &TagsHandler{
+		repo:            repository.NewTrackerRepository(db),
+		profileResolver: newProfileContextResolver(db),
+	}
+}
+
+// SetEditFormInvalidator makes tag writes drop the dashboard's prefetched
+// edit forms, which list the profile's tags.
+func (h *TagsHandler) SetEditFormInvalidator(invalidator editFormInvalidator) {
+	h.editForms = invalidator
+}
+
+func (h *TagsHandler) List(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	tags, err := h.repo.ListProfileTags(c.UserContext(), profile.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to list tags", err)
+	}
+
+	return c.JSON(fiber.Map{"items": tags})
+}
+
+func (h *TagsHandler) Create(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	var req tagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid json body"})
+	}
+	name, err := validateTagName(req.Name)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	var iconKey *string
+	if req.IconKey != nil {
+		if rawIcon := strings.TrimSpace(*req.IconKey); rawIcon != "" {
+			if !allowedTagIconKeys[rawIcon] {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid iconKey"})
+			}
+			iconKey = &rawIcon
+		}
+	}
+
+	created, err := h.repo.CreateProfileTag(c.UserContext(), profile.ID, name, iconKey)
+	if err != nil {
+		if isUniqueViolation(err) {
+			if iconKey != nil {
+				return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "iconKey is already used by another tag"})
+			}
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "a tag with that name already exists"})
+		}
+		return serverErrorJSON(c, "failed to create tag", err)
+	}
+	if h.editForms != nil {
+		h.editForms.ForgetEditForms(profile.ID)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// Update renames a tag. Icons are set when a tag is created and cannot be
+// changed here, matching the dashboard.
+func (h *TagsHandler) Update(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid tag id"})
+	}
+
+	var req tagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid json body"})
+	}
+	name, err := validateTagName(req.Name)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	renamed, err := h.repo.RenameProfileTag(c.UserContext(), profile.ID, id, name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "a tag with that name already exists"})
+		}
+		return serverErrorJSON(c, "failed to rename tag", err)
+	}
+	if !renamed {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tag not found"})
+	}
+	if h.editForms != nil {
+		h.editForms.ForgetEditForms(profile.ID)
+	}
+
+	tags, err := h.repo.ListProfileTags(c.UserContext(), profile.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load tag", err)
+	}
+	for _, tag := range tags {
+		if tag.ID == id {
+			return c.JSON(tag)
+		}
+	}
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tag not found"})
+}
+
+func (h *TagsHandler) Delete(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid tag id"})
+	}
+
+	deleted, err := h.repo.DeleteProfileTag(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to delete tag", err)
+	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tag not found"})
+	}
+	if h.editForms != nil {
+		h.editForms.ForgetEditForms(profile.ID)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ReplaceTrackerTags sets a tracker's tags to the JSON array of tag ids in
+// the body and returns the updated tracker.
+func (h *TagsHandler) ReplaceTrackerTags(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid tracker id"})
+	}
+
+	var tagIDs []int64
+	if err := json.Unmarshal(c.Body(), &tagIDs); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "body must be a json array of tag ids"})
+	}
+
+	tracker, err := h.repo.GetByID(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to get tracker", err)
+	}
+	if tracker == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
+	}
+
+	tags, err := h.repo.ListProfileTags(c.UserContext(), profile.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to list tags", err)
+	}
+	if unknownID, ok := firstUnknownTagID(tagIDs, tags); ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": fmt.Sprintf("unknown tag id %d", unknownID)})
+	}
+
+	if err := h.repo.ReplaceTrackerTags(c.UserContext(), profile.ID, id, tagIDs); err != nil {
+		return serverErrorJSON(c, "failed to save tracker tags", err)
+	}
+	if h.editForms != nil {
+		h.editForms.ForgetEditForm(profile.ID, id)
+	}
+
+	updated, err := h.repo.GetByID(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to get tracker", err)
+	}
+	if updated == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
+	}
+
+	return c.JSON(updated)
+}
+
+func validateTagName(raw string) (string, error) {
+	name := strings.TrimSpace(raw)
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if len(name) > maxTagNameLength {
+		return "", fmt.Errorf("name must be %d characters or less", maxTagNameLength)
+	}
+	return name, nil
+}
+
+func firstUnknownTagID(tagIDs []int64, tags []models.CustomTag) (int64, bool) {
+	known := make(map[int64]bool, len(tags))
+	for _, tag := range tags {
+		known[tag.ID] = true
+	}
+	for _, tagID := range tagIDs {
+		if !known[tagID] {
+			return tagID, true
+		}
+	}
+	return 0, false
+}
+
+func isUniqueViolation(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "unique")
+}
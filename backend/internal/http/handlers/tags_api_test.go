@@ -0,0 +1,198 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func sendTagsJSON(t *testing.T, app *fiber.App, method string, target string, body string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, target, err)
+	}
+	raw, _ := io.ReadAll(res.Body)
+	payload := map[string]any{}
+	if len(bytes.TrimSpace(raw)) > 0 {
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			t.Fatalf("decode %s %s response %q: %v", method, target, raw, err)
+		}
+	}
+	return res.StatusCode, payload
+}
+
+func TestTagsAPICRUD(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	status, created := sendTagsJSON(t, app, http.MethodPost, "/v1/tags", `{"name":"  Favorites ","iconKey":"icon_2"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", status, created)
+	}
+	if created["name"] != "Favorites" || created["iconKey"] != "icon_2" {
+		t.Fatalf("expected trimmed name and icon, got %v", created)
+	}
+	if path, _ := created["iconPath"].(string); !strings.HasPrefix(path, "/assets/tag-icons/") {
+		t.Fatalf("expected an icon asset path, got %v", created["iconPath"])
+	}
+	id := int(created["id"].(float64))
+
+	status, listPayload := sendTagsJSON(t, app, http.MethodGet, "/v1/tags", "")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if items := listPayload["items"].([]any); len(items) != 1 {
+		t.Fatalf("expected 1 tag, got %d", len(items))
+	}
+
+	status, renamed := sendTagsJSON(t, app, http.MethodPut, "/v1/tags/"+toString(id), `{"name":"Top Picks"}`)
+	if status != http.StatusOK || renamed["name"] != "Top Picks" || renamed["iconKey"] != "icon_2" {
+		t.Fatalf("expected renamed tag keeping its icon, got %d %v", status, renamed)
+	}
+
+	if status, _ := sendTagsJSON(t, app, http.MethodDelete, "/v1/tags/"+toString(id), ""); status != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", status)
+	}
+	status, missing := sendTagsJSON(t, app, http.MethodDelete, "/v1/tags/"+toString(id), "")
+	if status != http.StatusNotFound || missing["message"] != "tag not found" {
+		t.Fatalf("expected 404 tag not found, got %d %v", status, missing)
+	}
+}
+
+func TestTagsAPIValidation(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	cases := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		status  int
+		message string
+	}{
+		{"missing name", http.MethodPost, "/v1/tags", `{"name":"   "}`, http.StatusBadRequest, "name is required"},
+		{"long name", http.MethodPost, "/v1/tags", `{"name":"` + strings.Repeat("a", 41) + `"}`, http.StatusBadRequest, "name must be 40 characters or less"},
+		{"invalid icon", http.MethodPost, "/v1/tags", `{"name":"Icons","iconKey":"icon_99"}`, http.StatusBadRequest, "invalid iconKey"},
+		{"invalid body", http.MethodPost, "/v1/tags", `{"name":`, http.StatusBadRequest, "invalid json body"},
+		{"invalid id", http.MethodPut, "/v1/tags/abc", `{"name":"Renamed"}`, http.StatusBadRequest, "invalid tag id"},
+		{"unknown tag", http.MethodPut, "/v1/tags/999", `{"name":"Renamed"}`, http.StatusNotFound, "tag not found"},
+	}
+	for _, tc := range cases {
+		status, payload := sendTagsJSON(t, app, tc.method, tc.target, tc.body)
+		if status != tc.status || payload["message"] != tc.message {
+			t.Fatalf("%s: expected %d %q, got %d %v", tc.name, tc.status, tc.message, status, payload)
+		}
+	}
+}
+
+func TestTagsAPIRejectsDuplicates(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	if status, _ := sendTagsJSON(t, app, http.MethodPost, "/v1/tags", `{"name":"Seasonal","iconKey":"icon_1"}`); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	status, other := sendTagsJSON(t, app, http.MethodPost, "/v1/tags", `{"name":"Other"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+
+	status, payload := sendTagsJSON(t, app, http.MethodPost, "/v1/tags", `{"name":"Seasonal"}`)
+	if status != http.StatusConflict || payload["message"] != "a tag with that name already exists" {
+		t.Fatalf("expected duplicate name conflict, got %d %v", status, payload)
+	}
+	status, payload = sendTagsJSON(t, app, http.MethodPost, "/v1/tags", `{"name":"Fresh","iconKey":"icon_1"}`)
+	if status != http.StatusConflict || payload["message"] != "iconKey is already used by another tag" {
+		t.Fatalf("expected duplicate icon conflict, got %d %v", status, payload)
+	}
+	status, payload = sendTagsJSON(t, app, http.MethodPut, "/v1/tags/"+toString(int(other["id"].(float64))), `{"name":"Seasonal"}`)
+	if status != http.StatusConflict || payload["message"] != "a tag with that name already exists" {
+		t.Fatalf("expected rename conflict, got %d %v", status, payload)
+	}
+}
+
+func TestTagsAPIIsolatedByProfile(t *testing.T) {
+	_, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	status, created := sendTagsJSON(t, app, http.MethodPost, "/v1/tags?profile=profile1", `{"name":"Mine"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	id := toString(int(created["id"].(float64)))
+
+	status, listPayload := sendTagsJSON(t, app, http.MethodGet, "/v1/tags?profile=profile2", "")
+	if status != http.StatusOK || len(listPayload["items"].([]any)) != 0 {
+		t.Fatalf("expected no tags for profile2, got %d %v", status, listPayload)
+	}
+	if status, _ := sendTagsJSON(t, app, http.MethodPut, "/v1/tags/"+id+"?profile=profile2", `{"name":"Stolen"}`); status != http.StatusNotFound {
+		t.Fatalf("expected 404 renaming another profile's tag, got %d", status)
+	}
+	if status, _ := sendTagsJSON(t, app, http.MethodDelete, "/v1/tags/"+id+"?profile=profile2", ""); status != http.StatusNotFound {
+		t.Fatalf("expected 404 deleting another profile's tag, got %d", status)
+	}
+}
+
+func TestTagsAPIReplacesTrackerTags(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (title, source_id, source_url, status)
+		VALUES (?, ?, ?, ?)
+	`, "Tagged Tracker", 1, "https://asuracomic.net/series/tagged-tracker", "reading")
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+	trackerPath := "/v1/trackers/" + toString(int(trackerID))
+
+	_, first := sendTagsJSON(t, app, http.MethodPost, "/v1/tags", `{"name":"First"}`)
+	_, second := sendTagsJSON(t, app, http.MethodPost, "/v1/tags", `{"name":"Second"}`)
+	firstID := toString(int(first["id"].(float64)))
+	secondID := toString(int(second["id"].(float64)))
+	_, foreign := sendTagsJSON(t, app, http.MethodPost, "/v1/tags?profile=profile2", `{"name":"Foreign"}`)
+	foreignID := toString(int(foreign["id"].(float64)))
+
+	status, updated := sendTagsJSON(t, app, http.MethodPut, trackerPath+"/tags", `[`+firstID+`,`+secondID+`]`)
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d %v", status, updated)
+	}
+	if tags := updated["tags"].([]any); len(tags) != 2 {
+		t.Fatalf("expected 2 tags on the updated tracker, got %v", updated["tags"])
+	}
+
+	status, fetched := sendTagsJSON(t, app, http.MethodGet, trackerPath, "")
+	if status != http.StatusOK || len(fetched["tags"].([]any)) != 2 {
+		t.Fatalf("expected tags in tracker get response, got %d %v", status, fetched["tags"])
+	}
+
+	status, payload := sendTagsJSON(t, app, http.MethodPut, trackerPath+"/tags", `[`+foreignID+`]`)
+	if status != http.StatusBadRequest || payload["message"] != "unknown tag id "+foreignID {
+		t.Fatalf("expected another profile's tag to be rejected, got %d %v", status, payload)
+	}
+	if status, payload := sendTagsJSON(t, app, http.MethodPut, trackerPath+"/tags", `{"ids":[1]}`); status != http.StatusBadRequest {
+		t.Fatalf("expected non-array body to be rejected, got %d %v", status, payload)
+	}
+	if status, _ := sendTagsJSON(t, app, http.MethodPut, "/v1/trackers/999/tags", `[]`); status != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown tracker, got %d", status)
+	}
+
+	status, cleared := sendTagsJSON(t, app, http.MethodPut, trackerPath+"/tags", `[]`)
+	if status != http.StatusOK {
+		t.Fatalf("expected 200 clearing tags, got %d", status)
+	}
+	if tags, _ := cleared["tags"].([]any); len(tags) != 0 {
+		t.Fatalf("expected tags cleared, got %v", cleared["tags"])
+	}
+}
@@ -0,0 +1,39 @@
+package handlers_test
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	apihttp "github.com/gabriel/cross-site-tracker/backend/internal/http"
+	"github.com/gabriel/cross-site-tracker/backend/internal/http/handlers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestDashboardRendersTemplatesParsedUpFront(t *testing.T) {
+	_, app, cleanup := setupTestAppWithServer(t, config.Config{AppName: "test-app"}, func(cfg config.Config, db *sql.DB) *fiber.App {
+		templates, err := handlers.ParseTemplates(handlers.TemplatesGlob, cfg.BasePath)
+		if err != nil {
+			t.Fatalf("parse templates: %v", err)
+		}
+		// A page only the handed-over set has shows it is the one rendered.
+		if _, err := templates.New("dashboard_page.html").Parse(`parsed up front`); err != nil {
+			t.Fatalf("override dashboard page: %v", err)
+		}
+		return apihttp.NewServerWithTemplates(cfg, db, nil, nil, templates)
+	})
+	defer cleanup()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("dashboard request failed: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), "parsed up front") {
+		t.Fatalf("expected the handed-over templates to render, got %d: %s", res.StatusCode, string(body))
+	}
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/timefmt"
+)
+
+// CoverLookup is what a TrackerCardBuilder needs from CoverService.
+type CoverLookup interface {
+	CachedOrQueue(sourceKey, sourceURL string, sourceItemID *string, pageKey string, row int) (string, bool)
+	ThumbnailURL(trackerID int64, sourceKey, sourceURL string, sourceItemID *string, coverURL string, pageKey string, row int) string
+}
+
+// ChapterURLLookup is what a TrackerCardBuilder needs from
+// ChapterURLService.
+type ChapterURLLookup interface {
+	CachedOrQueue(sourceKey, sourceURL string, chapter float64, pageKey string, row int) (string, bool)
+}
+
+// CoverRequest is one card's cover, as a CoverLookup is asked for it.
+type CoverRequest struct {
+	SourceKey    string
+	SourceURL    string
+	SourceItemID *string
+}
+
+// ChapterURLRequest is one card's chapter link, as a ChapterURLLookup is
+// asked for it.
+type ChapterURLRequest struct {
+	SourceKey string
+	SourceURL string
+	Chapter   float64
+}
+
+// pageCoverLookup is implemented by cover lookups that can read the cached
+// covers of a whole page at once. The lookup ForPage returns answers those
+// requests without locking the cache again and passes anything else on.
+type pageCoverLookup interface {
+	ForPage(requests []CoverRequest) CoverLookup
+}
+
+// pageChapterURLLookup is the chapter link counterpart of pageCoverLookup.
+type pageChapterURLLookup interface {
+	ForPage(requests []ChapterURLRequest) ChapterURLLookup
+}
+
+// TrackerCardBuilder turns trackers into card views. It does no I/O of its
+// own: covers and chapter links come from the lookups, which answer from
+// cache and queue anything missing. Without a lookup cards have no cover and
+// their chapter links point at the series page.
+type TrackerCardBuilder struct {
+	Covers      CoverLookup
+	ChapterURLs ChapterURLLookup
+}
+
+// Build returns the cards in the order of items, and whether any of them is
+// still waiting for a cover or chapter link. continuations holds the links of
+// the trackers the items continue in; pageKey and each card's row order the
+// queued lookups.
+func (b TrackerCardBuilder) Build(items []models.Tracker, sourceByID map[int64]models.Source, sourceLogoBySourceID map[int64]string, continuations map[int64]repository.TrackerLink, pageKey string) ([]trackerCardView, bool) {
+	sources := cardSourcesOf(items, sourceByID, sourceLogoBySourceID)
+	covers, chapterURLs := b.pageLookups(items, sources)
+	now := time.Now()
+	statusLabels := make(map[string]string, len(validStatuses))
+
+	cards := make([]trackerCardView, 0, len(items))
+	pendingCovers := false
+	for row, item := range items {
+		displayTags, hiddenTagCount := displayTrackerTags(item.Tags, 3)
+
+		card := trackerCardView{
+			ID:                     item.ID,
+			Title:                  item.Title,
+			Status:                 item.Status,
+			StatusLabel:            cardStatusLabel(statusLabels, item.Status),
+			Tags:                   displayTags,
+			HiddenTagCount:         hiddenTagCount,
+			TagIcons:               toTrackerTagIcons(item.Tags),
+			SourceID:               item.SourceID,
+			SourceURL:              item.SourceURL,
+			LatestKnownChapterURL:  item.SourceURL,
+			LastReadChapterURL:     item.SourceURL,
+			SourceItemID:           item.SourceItemID,
+			Rating:                 item.Rating,
+			LatestKnownChapterRaw:  item.LatestKnownChapter,
+			LastReadChapterRaw:     item.LastReadChapter,
+			LatestReleaseAgo:       "—",
+			LatestReleaseAgoShort:  "—",
+			LatestReleaseFormatted: "—",
+			UpdatedAtFormatted:     item.UpdatedAt.Format("2006-01-02 15:04"),
+			LastReadAgo:            "—",
+			LastReadAgoShort:       "—",
+			UnreadChapters:         unreadChapters(item.LatestKnownChapter, item.LastReadChapter),
+			LatestReleaseAtRaw:     item.LatestReleaseAt,
+			LastCheckedAtRaw:       item.LastCheckedAt,
+			LastReadAtRaw:          item.LastReadAt,
+			UpdatedAtRaw:           item.UpdatedAt,
+		}
+
+		if item.LastReadAt != nil {
+			card.LastReadAgo = timefmt.FromNow(*item.LastReadAt, timefmt.Long)
+			card.LastReadAgoShort = timefmt.FromNow(*item.LastReadAt, timefmt.Compact)
+		}
+
+		if item.LastCheckedAt != nil {
+			card.LastCheckedFormatted = item.LastCheckedAt.Format("2006-01-02 15:04")
+			card.LastCheckedAgo = timefmt.FromNow(*item.LastCheckedAt, timefmt.Long)
+		} else {
+			card.LastCheckedFormatted = "—"
+			card.LastCheckedAgo = "—"
+		}
+
+		if item.LatestReleaseAt != nil {
+			card.LatestReleaseFormatted = item.LatestReleaseAt.Format("2006-01-02 15:04")
+			card.LatestReleaseAgo = timefmt.FromNow(*item.LatestReleaseAt, timefmt.Long)
+			card.LatestReleaseAgoShort = timefmt.FromNow(*item.LatestReleaseAt, timefmt.Compact)
+			card.LatestReleaseUpcoming = item.LatestReleaseAt.After(now)
+		}
+
+		if item.NextScheduledChapter != nil && item.NextScheduledAt != nil && item.NextScheduledAt.After(now) &&
+			(item.LatestKnownChapter == nil || *item.NextScheduledChapter > *item.LatestKnownChapter) {
+			card.NextScheduledLabel = formatChapterLabel(*item.NextScheduledChapter) + " " + timefmt.FromNow(*item.NextScheduledAt, timefmt.Long)
+		}
+
+		switch {
+		case item.LatestKnownChapter != nil:
+			card.LatestKnownChapter = formatChapterLabel(*item.LatestKnownChapter)
+		case item.LastCheckedAt == nil:
+			card.LatestKnownChapter = "Not yet checked"
+			card.NeverChecked = true
+		default:
+			card.LatestKnownChapter = "—"
+		}
+
+		if item.LastReadChapter != nil {
+			card.LastReadChapter = formatChapterLabel(*item.LastReadChapter)
+		} else {
+			card.LastReadChapter = "—"
+		}
+
+		if item.Rating != nil {
+			card.RatingLabel = formatRatingLabel(*item.Rating)
+		}
+
+		if item.Rereading {
+			card.Rereading = true
+			card.RereadNumber = item.RereadCount + 2
+		}
+
+		if item.ContinuedByTrackerID != nil {
+			if link, ok := continuations[*item.ContinuedByTrackerID]; ok {
+				card.ContinuedByID = link.ID
+				card.ContinuedByTitle = link.Title
+				card.ContinuedByURL = link.SourceURL
+			}
+		}
+
+		source := sources[item.SourceID]
+		sourceKey := source.Key
+		card.SourceLogoURL = source.LogoURL
+		card.SourceLogoLabel = source.Name
+		card.SourceStatusNote = source.StatusNote
+
+		if chapterURLs != nil && item.LatestKnownChapter != nil {
+			latestChapterURL, waitingLatestChapterURL := chapterURLs.CachedOrQueue(sourceKey, item.SourceURL, *item.LatestKnownChapter, pageKey, row)
+			card.LatestKnownChapterURL = latestChapterURL
+			card.LatestKnownChapterURLPending = waitingLatestChapterURL
+			if waitingLatestChapterURL {
+				pendingCovers = true
+			}
+		}
+
+		if chapterURLs != nil && item.LastReadChapter != nil {
+			lastReadChapterURL, waitingLastReadChapterURL := chapterURLs.CachedOrQueue(sourceKey, item.SourceURL, *item.LastReadChapter, pageKey, row)
+			card.LastReadChapterURL = lastReadChapterURL
+			card.LastReadChapterURLPending = waitingLastReadChapterURL
+			if waitingLastReadChapterURL {
+				pendingCovers = true
+			}
+		}
+
+		if covers != nil {
+			coverURL, waitingCover := covers.CachedOrQueue(sourceKey, item.SourceURL, item.SourceItemID, pageKey, row)
+			card.CoverURL = coverURL
+			card.CoverPending = waitingCover
+			if waitingCover {
+				pendingCovers = true
+			}
+			card.ThumbnailURL = covers.ThumbnailURL(item.ID, sourceKey, item.SourceURL, item.SourceItemID, coverURL, pageKey, row)
+		}
+
+		cards = append(cards, card)
+	}
+
+	return cards, pendingCovers
+}
+
+// cardSource is a source as its cards show it, normalized once per render.
+type cardSource struct {
+	Key        string
+	Name       string
+	LogoURL    string
+	StatusNote string
+}
+
+func cardSourcesOf(items []models.Tracker, sourceByID map[int64]models.Source, sourceLogoBySourceID map[int64]string) map[int64]cardSource {
+	sources := make(map[int64]cardSource, len(sourceByID))
+	for _, item := range items {
+		if _, ok := sources[item.SourceID]; ok {
+			continue
+		}
+		source := sourceByID[item.SourceID]
+		key := strings.TrimSpace(source.Key)
+		name := strings.TrimSpace(source.Name)
+		if name == "" {
+			if key != "" {
+				name = humanizeValueLabel(key)
+			} else {
+				name = "Site"
+			}
+		}
+		sources[item.SourceID] = cardSource{
+			Key:        key,
+			Name:       name,
+			LogoURL:    strings.TrimSpace(sourceLogoBySourceID[item.SourceID]),
+			StatusNote: source.StatusNote,
+		}
+	}
+	return sources
+}
+
+// pageLookups returns the lookups the cards of items are filled from: for
+// lookups that support it, ones bound to a single read of the page's cached
+// links.
+func (b TrackerCardBuilder) pageLookups(items []models.Tracker, sources map[int64]cardSource) (CoverLookup, ChapterURLLookup) {
+	covers, chapterURLs := b.Covers, b.ChapterURLs
+
+	if page, ok := covers.(pageCoverLookup); ok {
+		requests := make([]CoverRequest, 0, len(items))
+		for _, item := range items {
+			requests = append(requests, CoverRequest{SourceKey: sources[item.SourceID].Key, SourceURL: item.SourceURL, SourceItemID: item.SourceItemID})
+		}
+		covers = page.ForPage(requests)
+	}
+
+	if page, ok := chapterURLs.(pageChapterURLLookup); ok {
+		requests := make([]ChapterURLRequest, 0, 2*len(items))
+		for _, item := range items {
+			sourceKey := sources[item.SourceID].Key
+			if item.LatestKnownChapter != nil {
+				requests = append(requests, ChapterURLRequest{SourceKey: sourceKey, SourceURL: item.SourceURL, Chapter: *item.LatestKnownChapter})
+			}
+			if item.LastReadChapter != nil {
+				requests = append(requests, ChapterURLRequest{SourceKey: sourceKey, SourceURL: item.SourceURL, Chapter: *item.LastReadChapter})
+			}
+		}
+		chapterURLs = page.ForPage(requests)
+	}
+
+	return covers, chapterURLs
+}
+
+// cardStatusLabel is statusLabel, worked out once per status of a render.
+func cardStatusLabel(labels map[string]string, status string) string {
+	label, ok := labels[status]
+	if !ok {
+		label = statusLabel(status)
+		labels[status] = label
+	}
+	return label
+}
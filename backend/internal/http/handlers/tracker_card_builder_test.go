@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/linkcache"
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// stubCoverLookup answers every card with the same cover and records the
+// rows it was asked for.
+type stubCoverLookup struct {
+	coverURL string
+	pending  bool
+	rows     []int
+}
+
+func (s *stubCoverLookup) CachedOrQueue(_, _ string, _ *string, _ string, row int) (string, bool) {
+	s.rows = append(s.rows, row)
+	return s.coverURL, s.pending
+}
+
+func (s *stubCoverLookup) ThumbnailURL(trackerID int64, _, _ string, _ *string, coverURL string, _ string, _ int) string {
+	if coverURL == "" {
+		return ""
+	}
+	return "/covers/thumb/" + strconv.FormatInt(trackerID, 10)
+}
+
+// stubChapterURLLookup knows the chapters in urls and reports the others
+// pending on the series URL.
+type stubChapterURLLookup struct {
+	urls map[float64]string
+}
+
+func (s stubChapterURLLookup) CachedOrQueue(_, sourceURL string, chapter float64, _ string, _ int) (string, bool) {
+	if chapterURL, ok := s.urls[chapter]; ok {
+		return chapterURL, false
+	}
+	return sourceURL, true
+}
+
+func TestTrackerCardBuilderFillsCardsFromLookups(t *testing.T) {
+	latest, lastRead := 12.0, 10.0
+	continuedBy := int64(9)
+	checkedAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	items := []models.Tracker{
+		{ID: 1, Title: "First", Status: "reading", SourceID: 1, SourceURL: "https://fakesite.test/first", LatestKnownChapter: &latest, LastReadChapter: &lastRead, ContinuedByTrackerID: &continuedBy},
+		{ID: 2, Title: "Second", Status: "on_hold", SourceID: 2, SourceURL: "https://other.test/second", LastCheckedAt: &checkedAt},
+	}
+	sourceByID := map[int64]models.Source{1: {ID: 1, Key: "fakesite", Name: "Fake Site"}, 2: {ID: 2, Key: "other_site"}}
+	continuations := map[int64]repository.TrackerLink{9: {ID: 9, Title: "First Season 2", SourceURL: "https://fakesite.test/first-2"}}
+	covers := &stubCoverLookup{coverURL: "https://cdn.test/cover.jpg"}
+	builder := TrackerCardBuilder{
+		Covers:      covers,
+		ChapterURLs: stubChapterURLLookup{urls: map[float64]string{12: "https://fakesite.test/first/12"}},
+	}
+
+	cards, pending := builder.Build(items, sourceByID, map[int64]string{1: " /logos/fake.png "}, continuations, "/dashboard/trackers")
+	if !pending {
+		t.Fatalf("expected the unresolved last-read chapter to keep the page pending")
+	}
+	if len(cards) != 2 || len(covers.rows) != 2 || covers.rows[0] != 0 || covers.rows[1] != 1 {
+		t.Fatalf("expected one cover lookup per card in row order, got %d cards and rows %v", len(cards), covers.rows)
+	}
+
+	first := cards[0]
+	if first.LatestKnownChapterURL != "https://fakesite.test/first/12" || first.LatestKnownChapterURLPending {
+		t.Fatalf("expected the cached latest chapter link, got %q pending=%v", first.LatestKnownChapterURL, first.LatestKnownChapterURLPending)
+	}
+	if first.LastReadChapterURL != "https://fakesite.test/first" || !first.LastReadChapterURLPending {
+		t.Fatalf("expected the series URL while the last-read chapter is pending, got %q pending=%v", first.LastReadChapterURL, first.LastReadChapterURLPending)
+	}
+	if first.CoverURL != "https://cdn.test/cover.jpg" || first.ThumbnailURL != "/covers/thumb/1" || first.SourceLogoURL != "/logos/fake.png" {
+		t.Fatalf("unexpected cover fields: %+v", first)
+	}
+	if first.ContinuedByTitle != "First Season 2" || first.ContinuedByURL != "https://fakesite.test/first-2" {
+		t.Fatalf("expected the continuation link, got %q %q", first.ContinuedByTitle, first.ContinuedByURL)
+	}
+	if first.LatestKnownChapter != "Ch. 12" || first.UnreadChapters == nil || *first.UnreadChapters != 2 {
+		t.Fatalf("unexpected chapter labels: %q unread=%v", first.LatestKnownChapter, first.UnreadChapters)
+	}
+
+	second := cards[1]
+	if second.SourceLogoLabel != "Other Site" || second.StatusLabel != "On hold" || second.LatestKnownChapter != "—" || second.NeverChecked {
+		t.Fatalf("unexpected second card: %+v", second)
+	}
+}
+
+func TestTrackerCardBuilderMarksNeverCheckedTrackers(t *testing.T) {
+	latest := 4.0
+	checkedAt := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	items := []models.Tracker{
+		{ID: 1, Title: "Fresh", Status: "reading", SourceID: 1, SourceURL: "https://fakesite.test/fresh"},
+		{ID: 2, Title: "Checked Empty", Status: "reading", SourceID: 1, SourceURL: "https://fakesite.test/empty", LastCheckedAt: &checkedAt},
+		{ID: 3, Title: "Typed In", Status: "reading", SourceID: 1, SourceURL: "https://fakesite.test/typed", LatestKnownChapter: &latest},
+	}
+
+	cards, _ := TrackerCardBuilder{}.Build(items, map[int64]models.Source{1: {ID: 1, Key: "fakesite"}}, nil, nil, "")
+	if !cards[0].NeverChecked || cards[0].LatestKnownChapter != "Not yet checked" {
+		t.Fatalf("expected a tracker without chapters or a check to be marked, got %+v", cards[0])
+	}
+	for _, card := range cards[1:] {
+		if card.NeverChecked {
+			t.Fatalf("expected %q not to be marked never checked, got %+v", card.Title, card)
+		}
+	}
+}
+
+func TestTrackerCardBuilderWithoutLookupsLinksTheSeries(t *testing.T) {
+	latest := 4.0
+	items := []models.Tracker{{ID: 1, Title: "Offline", Status: "reading", SourceID: 1, SourceURL: "https://fakesite.test/offline", LatestKnownChapter: &latest}}
+
+	cards, pending := TrackerCardBuilder{}.Build(items, map[int64]models.Source{1: {ID: 1, Key: "fakesite"}}, nil, nil, "")
+	if pending || len(cards) != 1 {
+		t.Fatalf("expected one card with nothing pending, got %d pending=%v", len(cards), pending)
+	}
+	if cards[0].LatestKnownChapterURL != "https://fakesite.test/offline" || cards[0].CoverURL != "" {
+		t.Fatalf("expected the series link and no cover, got %q %q", cards[0].LatestKnownChapterURL, cards[0].CoverURL)
+	}
+}
+
+func TestTrackerCardBuilderLabelsReleasesStillToCome(t *testing.T) {
+	latest := 10.0
+	scheduled := 11.0
+	releasedAt := time.Now().Add(-2 * time.Hour)
+	upcomingAt := time.Now().Add(30 * time.Hour)
+	items := []models.Tracker{
+		{ID: 1, Title: "Scheduled", Status: "reading", SourceID: 1, SourceURL: "https://fakesite.test/scheduled", LatestKnownChapter: &latest, LatestReleaseAt: &releasedAt, NextScheduledChapter: &scheduled, NextScheduledAt: &upcomingAt},
+		{ID: 2, Title: "Dated Ahead", Status: "reading", SourceID: 1, SourceURL: "https://fakesite.test/ahead", LatestKnownChapter: &latest, LatestReleaseAt: &upcomingAt},
+		{ID: 3, Title: "Caught Up", Status: "reading", SourceID: 1, SourceURL: "https://fakesite.test/caught", LatestKnownChapter: &scheduled, NextScheduledChapter: &latest, NextScheduledAt: &upcomingAt},
+	}
+
+	cards, _ := TrackerCardBuilder{}.Build(items, map[int64]models.Source{1: {ID: 1, Key: "fakesite"}}, nil, nil, "")
+	if cards[0].LatestReleaseUpcoming || cards[0].NextScheduledLabel != "Ch. 11 in 1 day" {
+		t.Fatalf("expected a released chapter and the next one scheduled, got %+v", cards[0])
+	}
+	if !cards[1].LatestReleaseUpcoming || !strings.HasPrefix(cards[1].LatestReleaseAgo, "in ") {
+		t.Fatalf("expected a release time in the future to read as upcoming, got %+v", cards[1])
+	}
+	if cards[2].NextScheduledLabel != "" {
+		t.Fatalf("expected no scheduled label for a chapter already reached, got %q", cards[2].NextScheduledLabel)
+	}
+}
+
+// cachedCardPage is a page of count trackers, with tags, whose covers and
+// chapter links are all cached in the builder's lookups.
+func cachedCardPage(count int) (TrackerCardBuilder, []models.Tracker, map[int64]models.Source) {
+	links := linkcache.NewResolver(fakeResolver{}, nil, nil)
+	sourceByID := map[int64]models.Source{
+		1: {ID: 1, Key: "fakesite", Name: "Fake Site"},
+		2: {ID: 2, Key: " other_site "},
+	}
+	iconPath := "/assets/tag-icons/star.svg"
+	items := make([]models.Tracker, 0, count)
+	for index := range count {
+		latest, lastRead := float64(100+index), float64(90+index)
+		itemID := fmt.Sprintf("item-%d", index)
+		item := models.Tracker{
+			ID:                 int64(index + 1),
+			Title:              fmt.Sprintf("Series %d", index),
+			Status:             "reading",
+			SourceID:           int64(1 + index%2),
+			SourceURL:          fmt.Sprintf("https://fakesite.test/series/%d", index),
+			SourceItemID:       &itemID,
+			LatestKnownChapter: &latest,
+			LastReadChapter:    &lastRead,
+			Tags: []models.CustomTag{
+				{ID: 1, Name: "favorite", IconPath: &iconPath},
+				{ID: 2, Name: "action"},
+				{ID: 3, Name: "weekly"},
+				{ID: 4, Name: "backlog"},
+			},
+		}
+		items = append(items, item)
+
+		sourceKey := strings.TrimSpace(sourceByID[item.SourceID].Key)
+		links.SetCover(linkcache.CoverKey(sourceKey, item.SourceURL, item.SourceItemID), item.SourceURL+"/cover.jpg", true, time.Hour)
+		links.SetChapterURL(linkcache.ChapterURLKey(sourceKey, item.SourceURL, latest), item.SourceURL+"/latest", true, time.Hour)
+		links.SetChapterURL(linkcache.ChapterURLKey(sourceKey, item.SourceURL, lastRead), item.SourceURL+"/read", true, time.Hour)
+	}
+
+	builder := TrackerCardBuilder{
+		Covers:      NewCoverService(links, CoverServiceConfig{}),
+		ChapterURLs: NewChapterURLService(links, ChapterURLServiceConfig{}),
+	}
+	return builder, items, sourceByID
+}
+
+func TestTrackerCardBuilderAnswersACachedPageWithoutQueueing(t *testing.T) {
+	builder, items, sourceByID := cachedCardPage(100)
+
+	cards, pending := builder.Build(items, sourceByID, nil, nil, "/dashboard/trackers?page=1")
+	if pending || len(cards) != len(items) {
+		t.Fatalf("expected %d cards answered from cache, got %d pending=%v", len(items), len(cards), pending)
+	}
+	for index, card := range cards {
+		item := items[index]
+		if card.CoverURL != item.SourceURL+"/cover.jpg" || card.LatestKnownChapterURL != item.SourceURL+"/latest" || card.LastReadChapterURL != item.SourceURL+"/read" {
+			t.Fatalf("card %d: expected the cached links, got %+v", index, card)
+		}
+		if len(card.Tags) != 3 || card.Tags[0].Name != "favorite" || card.HiddenTagCount != 1 {
+			t.Fatalf("card %d: expected the icon tag first and one hidden, got %+v", index, card.Tags)
+		}
+	}
+	if cards[1].SourceLogoLabel != "Other Site" {
+		t.Fatalf("expected the trimmed source key humanized, got %q", cards[1].SourceLogoLabel)
+	}
+}
+
+// TestTrackerCardBuilderRendersPagesInParallel builds the same cached page
+// from many goroutines; run under -race it checks the cache reads share no
+// unguarded state, and every render must match a lone one.
+func TestTrackerCardBuilderRendersPagesInParallel(t *testing.T) {
+	builder, items, sourceByID := cachedCardPage(100)
+	want, _ := builder.Build(items, sourceByID, nil, nil, "/dashboard/trackers?page=1")
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 16)
+	for worker := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				got, pending := builder.Build(items, sourceByID, nil, nil, "/dashboard/trackers?page=1")
+				if pending || !reflect.DeepEqual(got, want) {
+					errs <- fmt.Sprintf("worker %d: render differs from a lone one (pending=%v)", worker, pending)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkTrackerCardBuilderCachedPage(b *testing.B) {
+	builder, items, sourceByID := cachedCardPage(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		builder.Build(items, sourceByID, nil, nil, "/dashboard/trackers?page=1")
+	}
+}
+
+func BenchmarkTrackerCardBuilderCachedPageParallel(b *testing.B) {
+	builder, items, sourceByID := cachedCardPage(100)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			builder.Build(items, sourceByID, nil, nil, "/dashboard/trackers?page=1")
+		}
+	})
+}
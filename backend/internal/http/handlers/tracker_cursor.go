@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// trackerCursorPayload is the JSON inside an opaque list cursor. It carries
+// the sort it was issued for, so a cursor cannot be replayed against a
+// different ordering.
+type trackerCursorPayload struct {
+	Sort    string `json:"s"`
+	Order   string `json:"o"`
+	Values  []any  `json:"v"`
+	AfterID int64  `json:"id"`
+}
+
+func encodeTrackerCursor(sortBy string, order string, cursor repository.TrackerCursor) string {
+	values := make([]any, len(cursor.SortValues))
+	for index, value := range cursor.SortValues {
+		if raw, ok := value.([]byte); ok {
+			value = string(raw)
+		}
+		values[index] = value
+	}
+	body, _ := json.Marshal(trackerCursorPayload{Sort: sortBy, Order: order, Values: values, AfterID: cursor.ID})
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+func decodeTrackerCursor(raw string, sortBy string, order string) (repository.TrackerCursor, error) {
+	body, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return repository.TrackerCursor{}, errors.New("invalid cursor")
+	}
+	var payload trackerCursorPayload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.AfterID <= 0 {
+		return repository.TrackerCursor{}, errors.New("invalid cursor")
+	}
+	if len(payload.Values) != repository.TrackerSortValueCount(payload.Sort) {
+		return repository.TrackerCursor{}, errors.New("invalid cursor")
+	}
+	for _, value := range payload.Values {
+		switch value.(type) {
+		case nil, string, float64:
+		default:
+			return repository.TrackerCursor{}, errors.New("invalid cursor")
+		}
+	}
+	if payload.Sort != sortBy || payload.Order != order {
+		return repository.TrackerCursor{}, errors.New("cursor does not match sort and order")
+	}
+	return repository.TrackerCursor{SortValues: payload.Values, ID: payload.AfterID}, nil
+}
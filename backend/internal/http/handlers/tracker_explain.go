@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Explain says why the tracker is, or is not, in the list the same status,
+// tag, q and sites parameters give: each filter clause with whether the
+// tracker passes it. Tag names the profile does not have are dropped just
+// as the list drops them and reported in ignoredTags.
+func (h *TrackersHandler) Explain(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid tracker id"})
+	}
+
+	statuses := parseStatuses(c.Query("status"))
+	if err := validateStatuses(statuses); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	options := repository.TrackerListOptions{
+		ProfileID:  profile.ID,
+		Statuses:   statuses,
+		TagFilters: parseTagFiltersFromQuery(c),
+		SourceIDs:  parseSourceIDsFromArgs(c.Context().QueryArgs()),
+		Query:      c.Query("q"),
+	}
+	ignoredTags, err := dropUnknownTagFilters(c.UserContext(), h.repo, &options)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load profile tags", err)
+	}
+
+	explanation, err := h.repo.ExplainListMembership(c.UserContext(), id, options)
+	if err != nil {
+		return serverErrorJSON(c, "failed to explain tracker filters", err)
+	}
+	if explanation == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"trackerId":   explanation.TrackerID,
+		"title":       explanation.Title,
+		"listed":      explanation.Listed,
+		"clauses":     explanation.Clauses,
+		"ignoredTags": ignoredTags,
+	})
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// trackerSourceGroup is one source's rows in the dashboard's tracker
+// sources view.
+type trackerSourceGroup struct {
+	Count models.TrackerSourceCount
+	Items []models.ProfileTrackerSource
+}
+
+type trackerSourcesData struct {
+	ActiveProfile *models.Profile
+	Role          string
+	Groups        []trackerSourceGroup
+	// AutofocusID is the id of the element focused once the modal opens,
+	// the active role filter, so switching filters keeps focus in place.
+	AutofocusID string
+}
+
+// parseTrackerSourceFilters reads the sourceId and role query parameters
+// shared by the JSON list and the dashboard view. source_id, the name used
+// before the API settled on camelCase, is still accepted.
+func parseTrackerSourceFilters(c *fiber.Ctx) (repository.TrackerSourceListOptions, error) {
+	var options repository.TrackerSourceListOptions
+	rawSourceID := strings.TrimSpace(c.Query("sourceId"))
+	if rawSourceID == "" {
+		rawSourceID = strings.TrimSpace(c.Query("source_id"))
+	}
+	if rawSourceID != "" {
+		sourceID, err := strconv.ParseInt(rawSourceID, 10, 64)
+		if err != nil || sourceID <= 0 {
+			return options, fmt.Errorf("sourceId must be a positive integer")
+		}
+		options.SourceID = sourceID
+	}
+
+	options.Role = strings.ToLower(strings.TrimSpace(c.Query("role")))
+	switch options.Role {
+	case "", repository.TrackerSourceRolePrimary, repository.TrackerSourceRoleLinked:
+	default:
+		return options, fmt.Errorf("role must be %q or %q", repository.TrackerSourceRolePrimary, repository.TrackerSourceRoleLinked)
+	}
+	return options, nil
+}
+
+// trackerSourceTotal is how many rows the filters select, from the
+// per-source counts.
+func trackerSourceTotal(counts []models.TrackerSourceCount, options repository.TrackerSourceListOptions) int {
+	total := 0
+	for _, count := range counts {
+		if options.SourceID > 0 && count.SourceID != options.SourceID {
+			continue
+		}
+		switch options.Role {
+		case repository.TrackerSourceRolePrimary:
+			total += count.Primary
+		case repository.TrackerSourceRoleLinked:
+			total += count.Linked
+		default:
+			total += count.Primary + count.Linked
+		}
+	}
+	return total
+}
+
+// ListTrackerSources lists the profile's linked sources across every
+// tracker, sources first, a page at a time. The envelope carries the
+// per-source counts for the whole profile, whatever the filters.
+func (h *TrackersHandler) ListTrackerSources(c *fiber.Ctx) error {
+	profile, err := h.profileResolver.Resolve(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	options, err := parseTrackerSourceFilters(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+	}
+
+	limit := defaultAPIPageSize
+	if rawLimit := strings.TrimSpace(c.Query("limit")); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "limit must be a positive integer"})
+		}
+		limit = min(limit, maxAPIPageSize)
+	}
+	page := 1
+	if rawPage := strings.TrimSpace(c.Query("page")); rawPage != "" {
+		page, err = strconv.Atoi(rawPage)
+		if err != nil || page <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "page must be a positive integer"})
+		}
+	}
+
+	counts, err := h.repo.CountTrackerSourcesBySource(c.UserContext(), profile.ID)
+	if err != nil {
+		return serverErrorJSON(c, "failed to count tracker sources", err)
+	}
+	total := trackerSourceTotal(counts, options)
+	totalPages := max(1, (total+limit-1)/limit)
+	page = min(page, totalPages)
+	if (page-1)*limit > maxListOffset {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": fmt.Sprintf("page is too deep: offset pagination stops at %d rows, filter by sourceId instead", maxListOffset),
+		})
+	}
+
+	options.Limit = limit
+	options.Offset = (page - 1) * limit
+	items, err := h.repo.ListTrackerSourcesByProfile(c.UserContext(), profile.ID, options)
+	if err != nil {
+		return serverErrorJSON(c, "failed to list tracker sources", err)
+	}
+
+	return c.JSON(fiber.Map{"items": items, "counts": counts, "page": page, "totalPages": totalPages, "total": total})
+}
+
+// TrackerSourcesModal renders the profile's linked sources grouped by
+// source, each row opening its tracker's edit modal.
+func (h *DashboardHandler) TrackerSourcesModal(c *fiber.Ctx) error {
+	pageCtx, err := h.pageContext(c)
+	if err != nil {
+		return sendPageError(c, err)
+	}
+	activeProfile := pageCtx.profile
+	options, err := parseTrackerSourceFilters(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	counts, err := h.trackerRepo.CountTrackerSourcesBySource(c.UserContext(), activeProfile.ID)
+	if err != nil {
+		return serverError(c, "Failed to count tracker sources", err)
+	}
+	items, err := h.trackerRepo.ListTrackerSourcesByProfile(c.UserContext(), activeProfile.ID, options)
+	if err != nil {
+		return serverError(c, "Failed to load tracker sources", err)
+	}
+
+	return h.render(c, "tracker_sources_modal.html", trackerSourcesData{
+		ActiveProfile: activeProfile,
+		Role:          options.Role,
+		Groups:        groupTrackerSources(counts, items),
+		AutofocusID:   trackerSourcesFilterID(options.Role),
+	})
+}
+
+// trackerSourcesFilterID is the id of the modal's filter button for role.
+func trackerSourcesFilterID(role string) string {
+	if role == "" {
+		return "tracker-sources-filter-all"
+	}
+	return "tracker-sources-filter-" + role
+}
+
+// groupTrackerSources splits items, which come ordered by source, into one
+// group per source that has rows left after filtering.
+func groupTrackerSources(counts []models.TrackerSourceCount, items []models.ProfileTrackerSource) []trackerSourceGroup {
+	groups := make([]trackerSourceGroup, 0, len(counts))
+	bySource := make(map[int64]int, len(counts))
+	for _, item := range items {
+		index, ok := bySource[item.SourceID]
+		if !ok {
+			group := trackerSourceGroup{Count: models.TrackerSourceCount{SourceID: item.SourceID, SourceKey: item.SourceKey, SourceName: item.SourceName}}
+			for _, count := range counts {
+				if count.SourceID == item.SourceID {
+					group.Count = count
+					break
+				}
+			}
+			index = len(groups)
+			bySource[item.SourceID] = index
+			groups = append(groups, group)
+		}
+		groups[index].Items = append(groups[index].Items, item)
+	}
+	return groups
+}
@@ -0,0 +1,165 @@
+package handlers_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// seedMultiSourceTrackers adds two profile1 trackers: Alpha on MangaDex also
+// followed on MangaFire, and Beta on MangaFire alone.
+func seedMultiSourceTrackers(t *testing.T, db *sql.DB) (dexID int64, fireID int64, alphaID int64) {
+	t.Helper()
+
+	for key, id := range map[string]*int64{"mangadex": &dexID, "mangafire": &fireID} {
+		if err := db.QueryRow(`SELECT id FROM sources WHERE key = ?`, key).Scan(id); err != nil {
+			t.Fatalf("find %s source: %v", key, err)
+		}
+	}
+	result, err := db.Exec(`INSERT INTO trackers (profile_id, title, source_id, source_url, status) VALUES (1, 'Alpha', ?, 'https://mangadex.org/title/alpha', 'reading')`, dexID)
+	if err != nil {
+		t.Fatalf("seed alpha: %v", err)
+	}
+	alphaID, _ = result.LastInsertId()
+	result, err = db.Exec(`INSERT INTO trackers (profile_id, title, source_id, source_url, status) VALUES (1, 'Beta', ?, 'https://mangafire.to/manga/beta', 'on_hold')`, fireID)
+	if err != nil {
+		t.Fatalf("seed beta: %v", err)
+	}
+	betaID, _ := result.LastInsertId()
+	if _, err := db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_url)
+		VALUES (?, ?, 'https://mangadex.org/title/alpha'),
+		       (?, ?, 'https://mangafire.to/manga/alpha'),
+		       (?, ?, 'https://mangafire.to/manga/beta')
+	`, alphaID, dexID, alphaID, fireID, betaID, fireID); err != nil {
+		t.Fatalf("seed tracker sources: %v", err)
+	}
+	return dexID, fireID, alphaID
+}
+
+type trackerSourcesPayload struct {
+	Items []struct {
+		TrackerID    int64  `json:"trackerId"`
+		SourceID     int64  `json:"sourceId"`
+		SourceKey    string `json:"sourceKey"`
+		TrackerTitle string `json:"trackerTitle"`
+		Primary      bool   `json:"primary"`
+	} `json:"items"`
+	Counts []struct {
+		SourceKey string `json:"sourceKey"`
+		Primary   int    `json:"primary"`
+		Linked    int    `json:"linked"`
+	} `json:"counts"`
+	Page       int `json:"page"`
+	TotalPages int `json:"totalPages"`
+	Total      int `json:"total"`
+}
+
+func getTrackerSources(t *testing.T, app *fiber.App, query string) (int, trackerSourcesPayload) {
+	t.Helper()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/tracker-sources?profile=profile1"+query, nil))
+	if err != nil {
+		t.Fatalf("list tracker sources request failed: %v", err)
+	}
+	var payload trackerSourcesPayload
+	if res.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode tracker sources: %v", err)
+		}
+	}
+	return res.StatusCode, payload
+}
+
+func TestListTrackerSourcesAPIFiltersAndCounts(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	_, fireID, alphaID := seedMultiSourceTrackers(t, db)
+
+	status, payload := getTrackerSources(t, app, "")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if payload.Total != 3 || len(payload.Items) != 3 || payload.Page != 1 || payload.TotalPages != 1 {
+		t.Fatalf("expected all 3 rows on one page, got %+v", payload)
+	}
+	if len(payload.Counts) != 2 || payload.Counts[0].SourceKey != "mangadex" || payload.Counts[0].Primary != 1 || payload.Counts[0].Linked != 0 ||
+		payload.Counts[1].SourceKey != "mangafire" || payload.Counts[1].Primary != 1 || payload.Counts[1].Linked != 1 {
+		t.Fatalf("unexpected per-source counts: %+v", payload.Counts)
+	}
+
+	status, payload = getTrackerSources(t, app, fmt.Sprintf("&sourceId=%d&role=linked", fireID))
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if payload.Total != 1 || len(payload.Items) != 1 {
+		t.Fatalf("expected one linked mangafire row, got %+v", payload)
+	}
+	if item := payload.Items[0]; item.TrackerID != alphaID || item.Primary || item.TrackerTitle != "Alpha" {
+		t.Fatalf("expected Alpha's mangafire link, got %+v", item)
+	}
+	if len(payload.Counts) != 2 {
+		t.Fatalf("expected counts for the whole profile whatever the filters, got %+v", payload.Counts)
+	}
+
+	if status, legacy := getTrackerSources(t, app, fmt.Sprintf("&source_id=%d&role=linked", fireID)); status != http.StatusOK || legacy.Total != 1 {
+		t.Fatalf("expected the source_id spelling to keep working, got %d %+v", status, legacy)
+	}
+
+	status, payload = getTrackerSources(t, app, "&limit=2&page=9")
+	if status != http.StatusOK || payload.Page != 2 || payload.TotalPages != 2 || len(payload.Items) != 1 {
+		t.Fatalf("expected the page clamped to the last one, got %d %+v", status, payload)
+	}
+
+	for _, query := range []string{"&role=both", "&sourceId=abc", "&source_id=abc", "&page=0"} {
+		if status, _ := getTrackerSources(t, app, query); status != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %q, got %d", query, status)
+		}
+	}
+}
+
+func TestTrackerSourcesModalGroupsBySource(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	_, _, alphaID := seedMultiSourceTrackers(t, db)
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/sources/trackers?profile=profile1", nil))
+	if err != nil {
+		t.Fatalf("tracker sources modal request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+	body, _ := io.ReadAll(res.Body)
+	html := string(body)
+
+	dexAt := strings.Index(html, `data-source-key="mangadex"`)
+	fireAt := strings.Index(html, `data-source-key="mangafire"`)
+	if dexAt < 0 || fireAt < 0 || dexAt > fireAt {
+		t.Fatalf("expected a MangaDex group before a MangaFire group, got: %s", html)
+	}
+	fireGroup := html[fireAt:]
+	if !strings.Contains(fireGroup, "1 primary · 1 linked") || strings.Count(fireGroup, "tracker-sources-row__badge") != 2 ||
+		!strings.Contains(fireGroup, ">Linked<") || !strings.Contains(fireGroup, ">Primary<") {
+		t.Fatalf("expected the MangaFire group to hold one primary and one linked row, got: %s", fireGroup)
+	}
+	if !strings.Contains(html, fmt.Sprintf(`/dashboard/trackers/%d/edit"`, alphaID)) {
+		t.Fatalf("expected rows to open the tracker edit modal, got: %s", html)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/sources/trackers?profile=profile1&role=linked", nil))
+	if err != nil {
+		t.Fatalf("filtered modal request failed: %v", err)
+	}
+	body, _ = io.ReadAll(res.Body)
+	if html := string(body); strings.Contains(html, `data-source-key="mangadex"`) || strings.Count(html, "tracker-sources-row__badge") != 1 {
+		t.Fatalf("expected only the linked MangaFire row, got: %s", html)
+	}
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
 	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
 	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
@@ -37,17 +40,51 @@ type createTrackerRequest struct {
 type updateTrackerRequest = createTrackerRequest
 
 type TrackersHandler struct {
-	repo            *repository.TrackerRepository
-	profileResolver *profileContextResolver
+	repo              *repository.TrackerRepository
+	sourceRepo        *repository.SourceRepository
+	registry          *connectors.Registry
+	profileResolver   *profileContextResolver
+	enrichmentRetrier enrichmentRetrier
+	editForms         editFormInvalidator
 }
 
-func NewTrackersHandler(db *sql.DB) *TrackersHandler {
+// enrichmentRetrier looks up source metadata for new trackers in the
+// background; the dashboard handler owns the retry queue.
+type enrichmentRetrier interface {
+	QueueEnrichmentRetry(profileID, trackerID int64)
+}
+
+// editFormInvalidator drops the dashboard's prefetched edit forms after
+// writes made through the API.
+type editFormInvalidator interface {
+	ForgetEditForm(profileID, trackerID int64)
+	ForgetEditForms(profileID int64)
+}
+
+func NewTrackersHandler(db *sql.DB, registry *connectors.Registry) *TrackersHandler {
+	if registry == nil {
+		registry = connectors.NewRegistry()
+	}
 	return &TrackersHandler{
 		repo:            repository.NewTrackerRepository(db),
+		sourceRepo:      repository.NewSourceRepository(db),
+		registry:        registry,
 		profileResolver: newProfileContextResolver(db),
 	}
 }
 
+// SetEnrichmentRetrier makes Create queue a background source lookup for
+// trackers created without resolved metadata.
+func (h *TrackersHandler) SetEnrichmentRetrier(retrier enrichmentRetrier) {
+	h.enrichmentRetrier = retrier
+}
+
+// SetEditFormInvalidator makes writes drop the dashboard's prefetched edit
+// forms they change.
+func (h *TrackersHandler) SetEditFormInvalidator(invalidator editFormInvalidator) {
+	h.editForms = invalidator
+}
+
 func (h *TrackersHandler) Create(c *fiber.Ctx) error {
 	profile, err := h.profileResolver.Resolve(c)
 	if err != nil {
@@ -63,10 +100,13 @@ func (h *TrackersHandler) Create(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
+	if err := h.canonicalizeSourceURL(c.UserContext(), tracker); err != nil {
+		return h.sourceURLErrorResponse(c, err)
+	}
 
-	exists, err := h.repo.SourceExists(tracker.SourceID)
+	exists, err := h.repo.SourceExists(c.UserContext(), tracker.SourceID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "failed to validate source"})
+		return serverErrorJSON(c, "failed to validate source", err)
 	}
 	if !exists {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "sourceId does not exist"})
@@ -74,9 +114,16 @@ func (h *TrackersHandler) Create(c *fiber.Ctx) error {
 
 	tracker.ProfileID = profile.ID
 
-	created, err := h.repo.Create(tracker)
+	created, err := h.repo.Create(c.UserContext(), tracker)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "failed to create tracker"})
+		return serverErrorJSON(c, "failed to create tracker", err)
+	}
+	// Other trackers' forms can offer the new one as their continuation.
+	if h.editForms != nil {
+		h.editForms.ForgetEditForms(profile.ID)
+	}
+	if h.enrichmentRetrier != nil && created != nil && !hasResolvedSourceMetadata(created) {
+		h.enrichmentRetrier.QueueEnrichmentRetry(profile.ID, created.ID)
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(created)
@@ -94,20 +141,111 @@ func (h *TrackersHandler) List(c *fiber.Ctx) error {
 	}
 
 	options := repository.TrackerListOptions{
-		ProfileID: profile.ID,
-		Statuses:  statuses,
-		TagNames:  parseTagNames(c.Query("tags")),
-		SortBy:    c.Query("sort", "latest_known_chapter"),
-		Order:     c.Query("order", "desc"),
-		Query:     c.Query("q"),
+		ProfileID:  profile.ID,
+		Statuses:   statuses,
+		TagFilters: parseTagFiltersFromQuery(c),
+		SortBy:     c.Query("sort", "latest_known_chapter"),
+		Order:      c.Query("order", "desc"),
+		Query:      c.Query("q"),
+	}
+	ignoredTags, err := dropUnknownTagFilters(c.UserContext(), h.repo, &options)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load profile tags", err)
+	}
+
+	limit := 0
+	if rawLimit := strings.TrimSpace(c.Query("limit")); rawLimit != "" {
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "limit must be a positive integer"})
+		}
+		limit = min(limit, maxAPIPageSize)
+	}
+	rawPage := strings.TrimSpace(c.Query("page"))
+	rawCursor := strings.TrimSpace(c.Query("cursor"))
+
+	switch {
+	case rawPage != "" && rawCursor != "":
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "use either page or cursor, not both"})
+	case rawPage != "":
+		return h.listPage(c, options, ignoredTags, rawPage, limit)
+	case rawCursor != "" || limit > 0:
+		return h.listAfterCursor(c, options, ignoredTags, rawCursor, limit)
+	}
+
+	trackers, err := h.repo.List(c.UserContext(), options)
+	if err != nil {
+		return serverErrorJSON(c, "failed to list trackers", err)
+	}
+
+	return c.JSON(fiber.Map{"items": trackers, "ignoredTags": ignoredTags})
+}
+
+const (
+	defaultAPIPageSize = 50
+	maxAPIPageSize     = 200
+)
+
+// listPage serves OFFSET pagination. The page is clamped to the last page
+// before the offset is computed, and pages reaching past maxListOffset are
+// refused in favour of the cursor mode.
+func (h *TrackersHandler) listPage(c *fiber.Ctx, options repository.TrackerListOptions, ignoredTags []string, rawPage string, limit int) error {
+	page, err := strconv.Atoi(rawPage)
+	if err != nil || page <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "page must be a positive integer"})
+	}
+	if limit == 0 {
+		limit = defaultAPIPageSize
+	}
+
+	total, err := h.repo.Count(c.UserContext(), options)
+	if err != nil {
+		return serverErrorJSON(c, "failed to count trackers", err)
+	}
+	totalPages := max(1, (total+limit-1)/limit)
+	page = min(page, totalPages)
+	if (page-1)*limit > maxListOffset {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": fmt.Sprintf("page is too deep: offset pagination stops at %d trackers, use limit with cursor instead", maxListOffset),
+		})
 	}
 
-	trackers, err := h.repo.List(options)
+	options.Limit = limit
+	options.Offset = (page - 1) * limit
+	trackers, err := h.repo.List(c.UserContext(), options)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "failed to list trackers"})
+		return serverErrorJSON(c, "failed to list trackers", err)
 	}
 
-	return c.JSON(fiber.Map{"items": trackers})
+	return c.JSON(fiber.Map{"items": trackers, "ignoredTags": ignoredTags, "page": page, "totalPages": totalPages, "total": total})
+}
+
+// listAfterCursor serves keyset pagination: each response carries the
+// nextCursor to pass back as cursor, or null on the last page.
+func (h *TrackersHandler) listAfterCursor(c *fiber.Ctx, options repository.TrackerListOptions, ignoredTags []string, rawCursor string, limit int) error {
+	if limit == 0 {
+		limit = defaultAPIPageSize
+	}
+	sortBy, order := repository.NormalizeTrackerSort(options.SortBy, options.Order)
+	if rawCursor != "" {
+		cursor, err := decodeTrackerCursor(rawCursor, sortBy, order)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
+		}
+		options.After = &cursor
+	}
+
+	options.Limit = limit
+	trackers, next, err := h.repo.ListAfter(c.UserContext(), options)
+	if err != nil {
+		return serverErrorJSON(c, "failed to list trackers", err)
+	}
+
+	var nextCursor any
+	if next != nil {
+		nextCursor = encodeTrackerCursor(sortBy, order, *next)
+	}
+	return c.JSON(fiber.Map{"items": trackers, "ignoredTags": ignoredTags, "nextCursor": nextCursor})
 }
 
 func (h *TrackersHandler) GetByID(c *fiber.Ctx) error {
@@ -121,9 +259,9 @@ func (h *TrackersHandler) GetByID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid tracker id"})
 	}
 
-	tracker, err := h.repo.GetByID(profile.ID, id)
+	tracker, err := h.repo.GetByID(c.UserContext(), profile.ID, id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "failed to get tracker"})
+		return serverErrorJSON(c, "failed to get tracker", err)
 	}
 	if tracker == nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
@@ -152,10 +290,13 @@ func (h *TrackersHandler) Update(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error()})
 	}
+	if err := h.canonicalizeSourceURL(c.UserContext(), tracker); err != nil {
+		return h.sourceURLErrorResponse(c, err)
+	}
 
-	exists, err := h.repo.SourceExists(tracker.SourceID)
+	exists, err := h.repo.SourceExists(c.UserContext(), tracker.SourceID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "failed to validate source"})
+		return serverErrorJSON(c, "failed to validate source", err)
 	}
 	if !exists {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "sourceId does not exist"})
@@ -163,13 +304,28 @@ func (h *TrackersHandler) Update(c *fiber.Ctx) error {
 
 	tracker.ProfileID = profile.ID
 
-	updated, err := h.repo.Update(profile.ID, id, tracker)
+	existing, err := h.repo.GetByID(c.UserContext(), profile.ID, id)
+	if err != nil {
+		return serverErrorJSON(c, "failed to load tracker", err)
+	}
+	if existing == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
+	}
+
+	updated, err := h.repo.Update(c.UserContext(), profile.ID, id, tracker)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "failed to update tracker"})
+		return serverErrorJSON(c, "failed to update tracker", err)
 	}
 	if updated == nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
 	}
+	// API callers do not say where they read, so advances count against the
+	// primary source.
+	recordReadSource(c.UserContext(), h.repo, updated, 0, existing.LastReadChapter, updated.LastReadChapter)
+	// The title also shows in the forms of trackers it continues.
+	if h.editForms != nil {
+		h.editForms.ForgetEditForms(profile.ID)
+	}
 
 	return c.JSON(updated)
 }
@@ -185,17 +341,42 @@ func (h *TrackersHandler) Delete(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "invalid tracker id"})
 	}
 
-	deleted, err := h.repo.Delete(profile.ID, id)
+	deleted, err := h.repo.Delete(c.UserContext(), profile.ID, id)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "failed to delete tracker"})
+		return serverErrorJSON(c, "failed to delete tracker", err)
 	}
 	if !deleted {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "tracker not found"})
 	}
+	if h.editForms != nil {
+		h.editForms.ForgetEditForms(profile.ID)
+	}
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+func (h *TrackersHandler) canonicalizeSourceURL(ctx context.Context, tracker *models.Tracker) error {
+	canonical, err := canonicalSourceURL(ctx, h.registry, h.sourceRepo, tracker.SourceID, tracker.SourceURL)
+	if err != nil {
+		return err
+	}
+	tracker.SourceURL = canonical
+	return nil
+}
+
+func (h *TrackersHandler) sourceURLErrorResponse(c *fiber.Ctx, err error) error {
+	var urlErr *sourceURLError
+	if !errors.As(err, &urlErr) {
+		return serverErrorJSON(c, "failed to validate source url", err)
+	}
+	response := fiber.Map{"message": urlErr.Error()}
+	if urlErr.Suggested != nil {
+		response["suggestedSourceId"] = urlErr.Suggested.ID
+		response["suggestedSourceKey"] = urlErr.Suggested.Key
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(response)
+}
+
 func validateAndBuildTracker(req createTrackerRequest) (*models.Tracker, error) {
 	title := strings.TrimSpace(req.Title)
 	if title == "" {
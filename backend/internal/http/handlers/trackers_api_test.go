@@ -6,7 +6,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
 	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gofiber/fiber/v2"
 )
 
 func TestTrackersCRUD(t *testing.T) {
@@ -17,7 +23,7 @@ func TestTrackersCRUD(t *testing.T) {
 		"title":              "Blue Lock",
 		"relatedTitles":      []string{"Blue Lock: Episode Nagi"},
 		"sourceId":           1,
-		"sourceUrl":          "https://mangadex.org/title/1",
+		"sourceUrl":          "https://asuracomic.net/series/blue-lock-1",
 		"status":             "reading",
 		"lastReadChapter":    20.0,
 		"rating":             8.5,
@@ -72,7 +78,7 @@ func TestTrackersCRUD(t *testing.T) {
 		"title":              "Blue Lock Updated",
 		"relatedTitles":      []string{"Blue Lock Alt"},
 		"sourceId":           1,
-		"sourceUrl":          "https://mangadex.org/title/1",
+		"sourceUrl":          "https://asuracomic.net/series/blue-lock-1",
 		"status":             "completed",
 		"lastReadChapter":    30.0,
 		"rating":             9.5,
@@ -130,7 +136,7 @@ func TestTrackersRejectInvalidRatingStep(t *testing.T) {
 	createBody := map[string]any{
 		"title":              "Invalid Rating",
 		"sourceId":           1,
-		"sourceUrl":          "https://mangadex.org/title/invalid-rating",
+		"sourceUrl":          "https://asuracomic.net/series/invalid-rating",
 		"status":             "reading",
 		"lastReadChapter":    1.0,
 		"rating":             8.3,
@@ -205,6 +211,130 @@ func TestAPIReadingFilterExcludesCaughtUpTrackers(t *testing.T) {
 	}
 }
 
+func TestAPITagFilterReportsDeletedTags(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	_, err := db.Exec(`
+		INSERT INTO trackers (title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, "API Tagless Tracker", 1, "https://asuracomic.net/series/api-tagless", "reading", 1.0, 2.0)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers?tags=favorite", nil))
+	if err != nil {
+		t.Fatalf("api trackers request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+
+	var payload struct {
+		Items       []map[string]any `json:"items"`
+		IgnoredTags []string         `json:"ignoredTags"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode api list response: %v", err)
+	}
+	if len(payload.IgnoredTags) != 1 || payload.IgnoredTags[0] != "favorite" {
+		t.Fatalf("expected favorite in ignoredTags, got %v", payload.IgnoredTags)
+	}
+	if len(payload.Items) != 1 {
+		t.Fatalf("expected deleted tag to be dropped from the filter, got %d items", len(payload.Items))
+	}
+}
+
+func TestAPITagFilterSupportsOrGroupsAndExclusions(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	tagsByTitle := map[string][]string{
+		"API Favorite Tracker":     {"favorite"},
+		"API Priority Tracker":     {"priority", "dropped-scanlation"},
+		"API Favorite Wip Tracker": {"favorite", "-wip"},
+		"API Untagged Tracker":     nil,
+	}
+	tagIDs := map[string]int64{}
+	for _, name := range []string{"favorite", "priority", "dropped-scanlation", "-wip"} {
+		result, err := db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, ?, ?)`, name, name)
+		if err != nil {
+			t.Fatalf("seed tag %s: %v", name, err)
+		}
+		tagIDs[name], _ = result.LastInsertId()
+	}
+	for title, tags := range tagsByTitle {
+		result, err := db.Exec(`
+			INSERT INTO trackers (title, source_id, source_url, status)
+			VALUES (?, 1, ?, 'reading')
+		`, title, "https://asuracomic.net/series/"+strings.ReplaceAll(strings.ToLower(title), " ", "-"))
+		if err != nil {
+			t.Fatalf("seed tracker %s: %v", title, err)
+		}
+		trackerID, _ := result.LastInsertId()
+		for _, tag := range tags {
+			if _, err := db.Exec(`INSERT INTO tracker_tags (tracker_id, tag_id) VALUES (?, ?)`, trackerID, tagIDs[tag]); err != nil {
+				t.Fatalf("tag %s: %v", title, err)
+			}
+		}
+	}
+
+	list := func(query string) ([]string, []string) {
+		t.Helper()
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, "/v1/trackers?"+query, nil))
+		if err != nil {
+			t.Fatalf("api trackers request failed: %v", err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 for %s, got %d", query, res.StatusCode)
+		}
+		var payload struct {
+			Items []struct {
+				Title string `json:"title"`
+			} `json:"items"`
+			IgnoredTags []string `json:"ignoredTags"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode api list response: %v", err)
+		}
+		titles := make([]string, 0, len(payload.Items))
+		for _, item := range payload.Items {
+			titles = append(titles, item.Title)
+		}
+		sort.Strings(titles)
+		return titles, payload.IgnoredTags
+	}
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{query: "tags=" + url.QueryEscape("favorite|priority"), want: []string{"API Favorite Tracker", "API Favorite Wip Tracker", "API Priority Tracker"}},
+		{query: "tags=" + url.QueryEscape("favorite,-priority"), want: []string{"API Favorite Tracker", "API Favorite Wip Tracker"}},
+		{query: "tags=" + url.QueryEscape("-dropped-scanlation"), want: []string{"API Favorite Tracker", "API Favorite Wip Tracker", "API Untagged Tracker"}},
+		{query: "tags=dropped-scanlation", want: []string{"API Priority Tracker"}},
+		// Only a tag named "-wip" exists, so the dash is part of the name.
+		{query: "tags=-wip", want: []string{"API Favorite Wip Tracker"}},
+		{query: "tags=" + url.QueryEscape("favorite|priority") + "&tags=" + url.QueryEscape("-favorite"), want: []string{"API Priority Tracker"}},
+	}
+	for _, tc := range cases {
+		titles, ignored := list(tc.query)
+		if strings.Join(titles, ",") != strings.Join(tc.want, ",") {
+			t.Fatalf("expected %v for %s, got %v", tc.want, tc.query, titles)
+		}
+		if len(ignored) != 0 {
+			t.Fatalf("expected no ignored tags for %s, got %v", tc.query, ignored)
+		}
+	}
+
+	// An unknown alternative is reported and the rest of its group still applies.
+	titles, ignored := list("tags=" + url.QueryEscape("priority|deleted"))
+	if len(titles) != 1 || titles[0] != "API Priority Tracker" || len(ignored) != 1 || ignored[0] != "deleted" {
+		t.Fatalf("expected priority match with deleted ignored, got %v ignored %v", titles, ignored)
+	}
+}
+
 func TestAPIQueryMatchesWordsInAnyOrderAndRelatedTitles(t *testing.T) {
 	db, app, cleanup := setupTestApp(t)
 	defer cleanup()
@@ -259,7 +389,7 @@ func TestTrackersAreIsolatedByProfile(t *testing.T) {
 	createBody := map[string]any{
 		"title":              "Only Profile 1",
 		"sourceId":           1,
-		"sourceUrl":          "https://mangadex.org/title/isolated",
+		"sourceUrl":          "https://asuracomic.net/series/isolated",
 		"status":             "reading",
 		"lastReadChapter":    1.0,
 		"latestKnownChapter": 2.0,
@@ -311,3 +441,154 @@ func TestTrackersAreIsolatedByProfile(t *testing.T) {
 		t.Fatalf("expected 1 item in profile1, got %d", len(profile1Items))
 	}
 }
+
+func TestCreateTrackerRejectsURLFromAnotherSource(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	asuraID, _ := sourceMetaByKey(t, db, "asuracomic")
+	mangaDexID, _ := sourceMetaByKey(t, db, "mangadex")
+	createBody := map[string]any{
+		"title":     "Mismatched Source",
+		"sourceId":  asuraID,
+		"sourceUrl": "https://mangadex.org/title/mismatched",
+		"status":    "reading",
+	}
+	body, _ := json.Marshal(createBody)
+	req := httptest.NewRequest(http.MethodPost, "/v1/trackers", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("create request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", res.StatusCode)
+	}
+
+	var payload map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	message, _ := payload["message"].(string)
+	if !strings.Contains(message, "expected asurascans.com or asuracomic.net") {
+		t.Fatalf("expected message to name the expected host, got %q", message)
+	}
+	if payload["suggestedSourceKey"] != "mangadex" || int64(payload["suggestedSourceId"].(float64)) != mangaDexID {
+		t.Fatalf("expected mangadex suggestion, got %v", payload)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM trackers`).Scan(&count); err != nil {
+		t.Fatalf("count trackers: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected mismatched tracker not to be stored, got %d", count)
+	}
+}
+
+func TestCreateTrackerStoresCanonicalSourceURL(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+
+	asuraID, _ := sourceMetaByKey(t, db, "asuracomic")
+	createBody := map[string]any{
+		"title":     "Canonical Source",
+		"sourceId":  asuraID,
+		"sourceUrl": "http://ASURACOMIC.net/series/canonical-source?ref=home#chapters",
+		"status":    "reading",
+	}
+	body, _ := json.Marshal(createBody)
+	req := httptest.NewRequest(http.MethodPost, "/v1/trackers", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("create request failed: %v", err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", res.StatusCode)
+	}
+
+	var sourceURL string
+	if err := db.QueryRow(`SELECT source_url FROM trackers WHERE title = 'Canonical Source'`).Scan(&sourceURL); err != nil {
+		t.Fatalf("load tracker: %v", err)
+	}
+	if sourceURL != "https://asuracomic.net/series/canonical-source" {
+		t.Fatalf("expected canonical source url, got %q", sourceURL)
+	}
+}
+
+func TestTrackerCreationIsScrapeRateLimited(t *testing.T) {
+	_, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", ScrapeRateLimitPerMinute: 1})
+	defer cleanup()
+
+	post := func(target, contentType, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("post %s: %v", target, err)
+		}
+		return res
+	}
+
+	invalid := `{"title":"","sourceId":1,"sourceUrl":"https://asuracomic.net/series/x","status":"reading"}`
+	if res := post("/v1/trackers", "application/json", invalid); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the first create to reach the handler, got %d", res.StatusCode)
+	}
+
+	res := post("/v1/trackers", "application/json", invalid)
+	if res.StatusCode != http.StatusTooManyRequests || res.Header.Get("Retry-After") != "60" {
+		t.Fatalf("expected 429 with Retry-After 60, got %d %q", res.StatusCode, res.Header.Get("Retry-After"))
+	}
+	var payload map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode rate limited response: %v", err)
+	}
+	if message, _ := payload["message"].(string); !strings.Contains(message, "60 seconds") {
+		t.Fatalf("expected a retry message, got %v", payload)
+	}
+
+	// The dashboard form draws on the same budget.
+	form := url.Values{"title": {"Blue Lock"}, "source_id": {"1"}, "source_url": {"https://asuracomic.net/series/blue-lock-1"}}
+	if res := post("/dashboard/trackers", "application/x-www-form-urlencoded", form.Encode()); res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected the dashboard create to be limited too, got %d", res.StatusCode)
+	}
+}
+
+func TestScrapeRateLimitKeysOnTheForwardedAddressOfTrustedProxies(t *testing.T) {
+	post := func(app *fiber.App, forwardedFor string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/v1/trackers", strings.NewReader(`{"title":""}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		return res.StatusCode
+	}
+
+	// app.Test connects from 0.0.0.0, which stands in for the proxy here.
+	_, app, cleanup := setupTestAppWithConfig(t, config.Config{AppName: "test-app", ScrapeRateLimitPerMinute: 1, TrustedProxies: []string{"0.0.0.0"}})
+	defer cleanup()
+	if status := post(app, "203.0.113.7"); status != http.StatusBadRequest {
+		t.Fatalf("expected the first client through, got %d", status)
+	}
+	if status := post(app, "203.0.113.7"); status != http.StatusTooManyRequests {
+		t.Fatalf("expected the first client limited, got %d", status)
+	}
+	if status := post(app, "198.51.100.2, 0.0.0.0"); status != http.StatusBadRequest {
+		t.Fatalf("expected a second client behind the proxy to have its own budget, got %d", status)
+	}
+
+	// A peer that is not a trusted proxy cannot pick its address.
+	_, untrusted, cleanupUntrusted := setupTestAppWithConfig(t, config.Config{AppName: "test-app", ScrapeRateLimitPerMinute: 1, TrustedProxies: []string{"10.0.0.1"}})
+	defer cleanupUntrusted()
+	if status := post(untrusted, "203.0.113.7"); status != http.StatusBadRequest {
+		t.Fatalf("expected the first request through, got %d", status)
+	}
+	if status := post(untrusted, "198.51.100.2"); status != http.StatusTooManyRequests {
+		t.Fatalf("expected a forged forwarded address to share the peer's budget, got %d", status)
+	}
+}
@@ -0,0 +1,155 @@
+package handlers_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func getJSON(t *testing.T, app *fiber.App, target string) (int, map[string]any) {
+	t.Helper()
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil), -1)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", target, err)
+	}
+	payload := map[string]any{}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		t.Fatalf("decode %s response: %v", target, err)
+	}
+	return res.StatusCode, payload
+}
+
+func seedNumberedTrackers(t *testing.T, db *sql.DB, count int) {
+	t.Helper()
+	_, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, rating)
+		WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < ?)
+		SELECT 1, printf('Series %05d', i), 1, 'https://asuracomic.net/series/numbered-' || i, 'reading', i % 3
+		FROM n
+	`, count)
+	if err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+}
+
+func TestAPIListCursorPagination(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	seedNumberedTrackers(t, db, 7)
+
+	for _, sort := range []string{"title", "rating"} {
+		_, full := getJSON(t, app, "/v1/trackers?sort="+sort+"&order=asc")
+		expected := make([]float64, 0)
+		for _, item := range full["items"].([]any) {
+			expected = append(expected, item.(map[string]any)["id"].(float64))
+		}
+
+		got := make([]float64, 0)
+		target := "/v1/trackers?sort=" + sort + "&order=asc&limit=3"
+		for pages := 0; pages < 10; pages++ {
+			status, payload := getJSON(t, app, target)
+			if status != http.StatusOK {
+				t.Fatalf("%s: expected 200, got %d %v", sort, status, payload)
+			}
+			for _, item := range payload["items"].([]any) {
+				got = append(got, item.(map[string]any)["id"].(float64))
+			}
+			next, _ := payload["nextCursor"].(string)
+			if next == "" {
+				break
+			}
+			target = "/v1/trackers?sort=" + sort + "&order=asc&limit=3&cursor=" + url.QueryEscape(next)
+		}
+
+		if len(got) != len(expected) {
+			t.Fatalf("%s: expected %d trackers across cursor pages, got %d", sort, len(expected), len(got))
+		}
+		for index := range got {
+			if got[index] != expected[index] {
+				t.Fatalf("%s: position %d expected id %v, got %v", sort, index, expected[index], got[index])
+			}
+		}
+	}
+
+	_, first := getJSON(t, app, "/v1/trackers?sort=title&order=asc&limit=3")
+	cursor := url.QueryEscape(first["nextCursor"].(string))
+	status, payload := getJSON(t, app, "/v1/trackers?sort=rating&order=asc&limit=3&cursor="+cursor)
+	if status != http.StatusBadRequest || payload["message"] != "cursor does not match sort and order" {
+		t.Fatalf("expected cursor from another sort to be rejected, got %d %v", status, payload)
+	}
+	status, payload = getJSON(t, app, "/v1/trackers?limit=3&cursor=not-a-cursor")
+	if status != http.StatusBadRequest || payload["message"] != "invalid cursor" {
+		t.Fatalf("expected invalid cursor to be rejected, got %d %v", status, payload)
+	}
+}
+
+func TestAPIListPageIsClampedAndValidated(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	seedNumberedTrackers(t, db, 5)
+
+	status, payload := getJSON(t, app, "/v1/trackers?sort=title&order=asc&limit=2&page=99")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d %v", status, payload)
+	}
+	if payload["page"] != 3.0 || payload["totalPages"] != 3.0 || payload["total"] != 5.0 {
+		t.Fatalf("expected page clamped to 3 of 3, got %v", payload)
+	}
+	items := payload["items"].([]any)
+	if len(items) != 1 || items[0].(map[string]any)["title"] != "Series 00005" {
+		t.Fatalf("expected the last tracker on the clamped page, got %v", items)
+	}
+
+	cases := []struct {
+		target  string
+		message string
+	}{
+		{"/v1/trackers?page=0", "page must be a positive integer"},
+		{"/v1/trackers?limit=-1", "limit must be a positive integer"},
+		{"/v1/trackers?page=2&cursor=abc", "use either page or cursor, not both"},
+	}
+	for _, tc := range cases {
+		status, payload := getJSON(t, app, tc.target)
+		if status != http.StatusBadRequest || payload["message"] != tc.message {
+			t.Fatalf("%s: expected 400 %q, got %d %v", tc.target, tc.message, status, payload)
+		}
+	}
+}
+
+func TestAPIListRefusesDeepOffsets(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	seedNumberedTrackers(t, db, 10100)
+
+	status, payload := getJSON(t, app, "/v1/trackers?limit=50&page=300")
+	if status != http.StatusBadRequest {
+		t.Fatalf("expected 400 past the offset limit, got %d", status)
+	}
+	if message, _ := payload["message"].(string); !strings.Contains(message, "cursor") {
+		t.Fatalf("expected the error to point at cursor pagination, got %q", message)
+	}
+
+	if status, _ := getJSON(t, app, "/v1/trackers?limit=50&page=200"); status != http.StatusOK {
+		t.Fatalf("expected pages within the offset limit to work, got %d", status)
+	}
+}
+
+func TestDashboardClampsAbsurdPageNumbers(t *testing.T) {
+	db, app, cleanup := setupTestApp(t)
+	defer cleanup()
+	seedNumberedTrackers(t, db, 30)
+
+	html := getTrackersPartial(t, app, "/dashboard/trackers?view=grid&status=all&sort=title&order=asc&page=9223372036854775807")
+	if !strings.Contains(html, "Series 00030") || strings.Contains(html, "Series 00001") {
+		t.Fatalf("expected the last page for an absurd page number")
+	}
+	if !strings.Contains(html, `aria-current="page"`) || !strings.Contains(html, ">\n            2\n") {
+		t.Fatalf("expected page 2 to be marked current, got %s", html)
+	}
+}
@@ -2,71 +2,231 @@ package http
 
 import (
 	"database/sql"
+	"html/template"
+	"log/slog"
 
+	"github.com/gabriel/cross-site-tracker/backend/internal/backup"
 	"github.com/gabriel/cross-site-tracker/backend/internal/config"
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
 	connectordefaults "github.com/gabriel/cross-site-tracker/backend/internal/connectors/defaults"
+	"github.com/gabriel/cross-site-tracker/backend/internal/digest"
 	"github.com/gabriel/cross-site-tracker/backend/internal/http/handlers"
+	"github.com/gabriel/cross-site-tracker/backend/internal/mangadexsync"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/thumbnails"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
+// AssetsDir holds the dashboard's static assets, relative to the backend
+// directory the app runs from.
+const AssetsDir = "./web/assets"
+
 func NewServer(cfg config.Config, db *sql.DB) *fiber.App {
 	return NewServerWithRegistry(cfg, db, nil)
 }
 
 func NewServerWithRegistry(cfg config.Config, db *sql.DB, connectorRegistry *connectors.Registry) *fiber.App {
-	app := fiber.New(fiber.Config{
+	return NewServerWithPollStatus(cfg, db, connectorRegistry, nil)
+}
+
+// NewServerWithPollStatus also reports the poller's progress on the
+// dashboard and at /v1/polling/status; pollStatus may be nil.
+func NewServerWithPollStatus(cfg config.Config, db *sql.DB, connectorRegistry *connectors.Registry, pollStatus handlers.PollStatusReader) *fiber.App {
+	return NewServerWithTemplates(cfg, db, connectorRegistry, pollStatus, nil)
+}
+
+// NewServerWithTemplates also hands the dashboard templates parsed up
+// front, as by the startup self-check; with nil templates they are parsed
+// on the first render.
+func NewServerWithTemplates(cfg config.Config, db *sql.DB, connectorRegistry *connectors.Registry, pollStatus handlers.PollStatusReader, templates *template.Template) *fiber.App {
+	fiberConfig := fiber.Config{
 		AppName: cfg.AppName,
-	})
+	}
+	if len(cfg.TrustedProxies) > 0 {
+		// Behind a trusted proxy the client IP comes from the first valid
+		// address in X-Forwarded-For; other peers keep their own address.
+		fiberConfig.ProxyHeader = fiber.HeaderXForwardedFor
+		fiberConfig.EnableTrustedProxyCheck = true
+		fiberConfig.TrustedProxies = cfg.TrustedProxies
+		fiberConfig.EnableIPValidation = true
+	}
+	app := fiber.New(fiberConfig)
 
+	// The request logger sits outside recover so panics show up in its log
+	// line as errors.
+	app.Use(handlers.RequestLogger(slog.Default()))
 	app.Use(recover.New())
 
 	health := handlers.NewHealthHandler(db)
-	trackers := handlers.NewTrackersHandler(db)
 	if connectorRegistry == nil {
 		connectorRegistry = connectordefaults.NewRegistry()
 	}
-	dashboard := handlers.NewDashboardHandler(db, connectorRegistry)
+	trackers := handlers.NewTrackersHandler(db, connectorRegistry)
+	dashboard := handlers.NewDashboardHandler(db, connectorRegistry, cfg.BasePath)
 	connectorHandlers := handlers.NewConnectorsHandler(connectorRegistry)
-	app.Static("/assets", "./web/assets")
-	app.Static("/uploads", "./data/uploads")
-	app.Get("/favicon.ico", func(c *fiber.Ctx) error {
-		return c.SendFile("./web/assets/favicon.svg")
+	connectorHandlers.SetSourceNotes(repository.NewSourceRepository(db))
+	connectorHandlers.SetPauseState(repository.NewSettingsRepository(db))
+	sources := handlers.NewSourcesHandler(db, connectorRegistry)
+	var digestSender digest.Sender
+	if cfg.SMTPConfigured() {
+		digestSender = digest.NewSMTPSender(digest.SMTPConfigFrom(cfg))
+	}
+	digests := handlers.NewDigestsHandler(db, digestSender)
+	var mangaDexJob *mangadexsync.Job
+	if cfg.MangaDexConfigured() {
+		job, err := mangadexsync.NewJobFromConfig(db, cfg, slog.Default())
+		if err != nil {
+			slog.Warn("mangadex sync disabled", "error", err)
+		}
+		mangaDexJob = job
+	}
+	mangaDex := handlers.NewMangaDexHandler(db, mangaDexJob)
+	settings := handlers.NewSettingsHandler(db)
+	profiles := handlers.NewProfilesHandler(db)
+	tags := handlers.NewTagsHandler(db)
+	filterPresets := handlers.NewFilterPresetsHandler(db)
+	stats := handlers.NewStatsHandler(db)
+	backups := handlers.NewBackupsHandler(db, backup.JobConfigFrom(cfg))
+	polling := handlers.NewPollingHandler(pollStatus)
+	openAPI := handlers.NewOpenAPIHandler(cfg.BasePath)
+	auth := handlers.NewAuthHandler(cfg.DashboardPassword, cfg.SessionSecret, dashboard)
+	readOnly := handlers.NewReadOnlyMode(cfg.ReadOnly, cfg.SessionSecret, cfg.BasePath)
+	readOnly.SetAuth(auth)
+	scrapeLimiter := handlers.NewRateLimiter(cfg.ScrapeRateLimitPerMinute)
+	activity := handlers.NewActivityRecorder(repository.NewSettingsRepository(db))
+	trackers.SetEnrichmentRetrier(dashboard)
+	trackers.SetEditFormInvalidator(dashboard)
+	tags.SetEditFormInvalidator(dashboard)
+	dashboard.SetPollStatus(pollStatus)
+	dashboard.SetTemplates(templates)
+	dashboard.SetRecentAdditionsDays(cfg.RecentAdditionsDays)
+	dashboard.SetDebugTools(cfg.DebugTools)
+	if thumbnailStore, err := thumbnails.Open(cfg.CoverThumbnailStorage, cfg.CoverThumbnailDir, db); err != nil {
+		slog.Warn("cover thumbnails disabled", "storage", cfg.CoverThumbnailStorage, "error", err)
+	} else if thumbnailStore != nil {
+		dashboard.SetCoverThumbnails(thumbnails.NewGenerator(thumbnailStore, nil))
+	}
+	app.Hooks().OnShutdown(func() error {
+		dashboard.StopEnrichmentRetries()
+		return nil
 	})
-	app.Get("/", dashboard.Page)
-	app.Get("/dashboard", dashboard.Page)
-	app.Post("/dashboard/profile/rename", dashboard.RenameProfileFromForm)
-	app.Get("/dashboard/profile/menu", dashboard.ProfileMenuModal)
-	app.Get("/dashboard/profile/filter-tags", dashboard.ProfileFilterTagsPartial)
-	app.Get("/dashboard/profile/filter-linked-sites", dashboard.ProfileFilterLinkedSitesPartial)
-	app.Post("/dashboard/profile/switch", dashboard.SwitchProfileFromMenu)
-	app.Post("/dashboard/profile/source-logos", dashboard.SaveSourceLogosFromMenu)
-	app.Post("/dashboard/profile/tags", dashboard.CreateTagFromMenu)
-	app.Post("/dashboard/profile/tags/rename", dashboard.RenameTagFromMenu)
-	app.Post("/dashboard/profile/tags/delete", dashboard.DeleteTagFromMenu)
-	app.Get("/dashboard/trackers", dashboard.TrackersPartial)
-	app.Get("/dashboard/trackers/search", dashboard.SearchSourceTitles)
-	app.Get("/dashboard/trackers/empty-modal", dashboard.EmptyModal)
-	app.Get("/dashboard/trackers/new", dashboard.NewTrackerModal)
-	app.Get("/dashboard/trackers/:id/edit", dashboard.EditTrackerModal)
-	app.Get("/dashboard/trackers/:id/card-fragment", dashboard.CardFragment)
-	app.Post("/dashboard/trackers", dashboard.CreateFromForm)
-	app.Post("/dashboard/trackers/:id", dashboard.UpdateFromForm)
-	app.Post("/dashboard/trackers/:id/set-last-read", dashboard.SetLastReadFromCard)
-	app.Post("/dashboard/trackers/:id/rating", dashboard.SetRatingFromCard)
-	app.Post("/dashboard/trackers/:id/delete", dashboard.DeleteFromForm)
-	app.Get("/health", health.Check)
-	app.Get("/v1/health", health.Check)
 
-	v1 := app.Group("/v1")
+	var routes fiber.Router = app
+	if cfg.BasePath != "" {
+		routes = app.Group(cfg.BasePath)
+	}
+	routes.Static("/assets", AssetsDir)
+	routes.Static("/uploads", "./data/uploads")
+	routes.Get("/favicon.ico", func(c *fiber.Ctx) error {
+		return c.SendFile(AssetsDir + "/favicon.svg")
+	})
+	routes.Use(readOnly.Middleware)
+	routes.Use(activity.Middleware)
+	routes.Get("/login", auth.LoginPage)
+	routes.Post("/login", auth.Login)
+	routes.Post("/logout", auth.Logout)
+	routes.Use("/dashboard", auth.RequireSession)
+	routes.Get("/", auth.RequireSession, dashboard.Page)
+	routes.Get("/covers/thumb/:trackerId", auth.RequireSession, dashboard.CoverThumbnail)
+	routes.Get("/dashboard", dashboard.Page)
+	routes.Post("/dashboard/profile/rename", dashboard.RenameProfileFromForm)
+	routes.Get("/dashboard/calendar", dashboard.ReleaseCalendarPage)
+	routes.Get("/dashboard/overlap", dashboard.OverlapPage)
+	routes.Get("/dashboard/polling-status", dashboard.PollingStatusPartial)
+	routes.Get("/dashboard/summary-chips", dashboard.SummaryChipsPartial)
+	routes.Get("/dashboard/recent-additions", dashboard.RecentAdditionsPartial)
+	routes.Post("/dashboard/filter-presets", dashboard.SaveFilterPresetFromForm)
+	routes.Post("/dashboard/filter-presets/:id/delete", dashboard.DeleteFilterPresetFromForm)
+	routes.Get("/dashboard/profile/menu", dashboard.ProfileMenuModal)
+	routes.Get("/dashboard/profile/filter-tags", dashboard.ProfileFilterTagsPartial)
+	routes.Get("/dashboard/profile/filter-linked-sites", dashboard.ProfileFilterLinkedSitesPartial)
+	routes.Post("/dashboard/profile/switch", dashboard.SwitchProfileFromMenu)
+	routes.Post("/dashboard/profile/source-logos", dashboard.SaveSourceLogosFromMenu)
+	routes.Post("/dashboard/profile/tags", dashboard.CreateTagFromMenu)
+	routes.Post("/dashboard/profile/tags/rename", dashboard.RenameTagFromMenu)
+	routes.Post("/dashboard/profile/tags/delete", dashboard.DeleteTagFromMenu)
+	routes.Post("/dashboard/profile/tags/delete-unused", dashboard.DeleteUnusedTagsFromMenu)
+	routes.Post("/dashboard/profile/tags/from-genre", dashboard.CreateTagFromGenre)
+	routes.Post("/dashboard/profile/digest", dashboard.SaveDigestFromMenu)
+	routes.Post("/dashboard/profile/polling", dashboard.SavePollingFromMenu)
+	routes.Post("/dashboard/profile/sort-defaults", dashboard.SaveSortDefaultsFromMenu)
+	routes.Get("/dashboard/sources/trackers", dashboard.TrackerSourcesModal)
+	routes.Post("/dashboard/sources/:id/note", dashboard.SaveSourceNoteFromMenu)
+	routes.Post("/dashboard/sources/:id/blacklist", dashboard.SaveSourceBlacklistFromMenu)
+	routes.Get("/dashboard/trackers", dashboard.TrackersPartial)
+	routes.Get("/dashboard/trackers/search", scrapeLimiter.Middleware(dashboard.SearchRateLimited), dashboard.SearchSourceTitles)
+	routes.Get("/dashboard/trackers/export-view", dashboard.ExportView)
+	routes.Get("/dashboard/trackers/export-recommendations", dashboard.ExportRecommendations)
+	routes.Get("/dashboard/trackers/empty-modal", dashboard.EmptyModal)
+	routes.Get("/dashboard/trackers/new", dashboard.NewTrackerModal)
+	routes.Get("/dashboard/trackers/:id/edit", dashboard.EditTrackerModal)
+	routes.Get("/dashboard/trackers/:id/edit-prefetch", dashboard.EditTrackerPrefetch)
+	routes.Get("/dashboard/trackers/:id/card-fragment", dashboard.CardFragment)
+	routes.Get("/dashboard/trackers/:id/chapters", scrapeLimiter.Middleware(nil), dashboard.ChaptersModal)
+	routes.Get("/dashboard/trackers/:id/explain", dashboard.ExplainModal)
+	routes.Get("/dashboard/trackers/:id/continuation-options", dashboard.ContinuationOptions)
+	routes.Post("/dashboard/trackers", scrapeLimiter.Middleware(nil), dashboard.CreateFromForm)
+	routes.Post("/dashboard/trackers/:id", scrapeLimiter.Middleware(nil), dashboard.UpdateFromForm)
+	routes.Post("/dashboard/trackers/:id/set-last-read", dashboard.SetLastReadFromCard)
+	routes.Post("/dashboard/trackers/:id/rating", dashboard.SetRatingFromCard)
+	routes.Post("/dashboard/trackers/:id/delete", dashboard.DeleteFromForm)
+	routes.Post("/dashboard/trackers/:id/primary-source", scrapeLimiter.Middleware(nil), dashboard.SetPrimarySourceFromForm)
+	routes.Post("/dashboard/trackers/:id/manual-release", dashboard.ManualReleaseFromForm)
+	routes.Post("/dashboard/trackers/:id/start-reread", dashboard.StartRereadFromCard)
+	routes.Post("/dashboard/trackers/:id/setup-complete", dashboard.DismissRecentAddition)
+	routes.Post("/dashboard/trackers/:id/linked-sources/:sourceID/dismiss-mismatch", dashboard.DismissLinkedSourceMismatch)
+	routes.Post("/dashboard/trackers/:id/sources/reorder", dashboard.ReorderLinkedSourcesFromForm)
+	routes.Get("/health", health.Check)
+	routes.Get("/v1/health", health.Check)
+
+	v1 := routes.Group("/v1")
+	v1.Get("/openapi.json", openAPI.Get)
 	v1.Get("/connectors", connectorHandlers.List)
-	v1.Get("/connectors/health", connectorHandlers.Health)
-	v1.Post("/trackers", trackers.Create)
+	v1.Get("/connectors/health", scrapeLimiter.Middleware(handlers.RateLimitedJSON), connectorHandlers.Health)
+	v1.Get("/sources", sources.List)
+	v1.Put("/sources/:id/note", sources.SetNote)
+	v1.Get("/sources/:id/search", scrapeLimiter.Middleware(sources.SearchRateLimited), sources.Search)
+	v1.Post("/trackers", scrapeLimiter.Middleware(handlers.RateLimitedJSON), trackers.Create)
 	v1.Get("/trackers", trackers.List)
+	v1.Get("/trackers/release-schedule", trackers.ReleaseSchedule)
 	v1.Get("/trackers/:id", trackers.GetByID)
+	v1.Get("/trackers/:id/card", dashboard.CardJSON)
+	v1.Get("/trackers/:id/reading-history", trackers.ReadingHistory)
+	v1.Get("/trackers/:id/cadence", trackers.Cadence)
+	v1.Get("/trackers/:id/explain", trackers.Explain)
 	v1.Put("/trackers/:id", trackers.Update)
+	v1.Post("/trackers/:id/reread", trackers.StartReread)
 	v1.Delete("/trackers/:id", trackers.Delete)
+	v1.Put("/trackers/:id/tags", tags.ReplaceTrackerTags)
+	v1.Get("/tracker-sources", trackers.ListTrackerSources)
+	v1.Get("/tags", tags.List)
+	v1.Post("/tags", tags.Create)
+	v1.Put("/tags/:id", tags.Update)
+	v1.Delete("/tags/:id", tags.Delete)
+	v1.Get("/filter-presets", filterPresets.List)
+	v1.Post("/filter-presets", filterPresets.Create)
+	v1.Get("/filter-presets/:id/apply", filterPresets.Apply)
+	v1.Put("/filter-presets/:id", filterPresets.Rename)
+	v1.Delete("/filter-presets/:id", filterPresets.Delete)
+	v1.Get("/stats", stats.Get)
+	v1.Get("/overlap", trackers.Overlap)
+	v1.Get("/resolve-url", trackers.ResolveURL)
+	v1.Post("/digests/test", digests.SendTest)
+	v1.Get("/integrations/mangadex", mangaDex.Get)
+	v1.Put("/integrations/mangadex", mangaDex.Link)
+	v1.Delete("/integrations/mangadex", mangaDex.Unlink)
+	v1.Post("/integrations/mangadex/sync", mangaDex.Sync)
+	v1.Get("/settings/scraping-paused", settings.GetScrapingPaused)
+	v1.Post("/settings/scraping-paused", settings.SetScrapingPaused)
+	v1.Get("/polling/status", polling.Status)
+	v1.Get("/profile/polling", profiles.GetPolling)
+	v1.Put("/profile/polling", profiles.SetPolling)
+	if cfg.BackupEnabled {
+		admin := v1.Group("/admin", auth.RequireAPISession)
+		admin.Post("/backup", backups.Create)
+		admin.Get("/backups", backups.List)
+	}
 
 	return app
 }
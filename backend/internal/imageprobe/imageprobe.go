@@ -0,0 +1,146 @@
+// Package imageprobe checks whether a cover URL still serves an image
+// without downloading it: a HEAD request, or a ranged GET of its first
+// bytes for hosts that refuse HEAD. Requests to the same host are spaced
+// apart so a bulk check does not hammer one CDN. The verify-covers command
+// uses it to find stored covers that rotted, and anything serving covers
+// from upstream can use it to tell an expired cover from a passing failure.
+package imageprobe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/pacing"
+)
+
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36"
+
+// Result is what a check found.
+type Result int
+
+const (
+	// Alive means the URL answered with an image content type.
+	Alive Result = iota
+	// Dead means the URL is gone (a 4xx answer) or serves something other
+	// than an image, such as a CDN's HTML error page.
+	Dead
+	// Unknown means the check could not tell: the request failed, timed out
+	// or got a 5xx or rate-limit answer. The URL should be kept and checked
+	// again later.
+	Unknown
+)
+
+func (r Result) String() string {
+	switch r {
+	case Alive:
+		return "alive"
+	case Dead:
+		return "dead"
+	default:
+		return "unknown"
+	}
+}
+
+// Prober checks image URLs, spacing requests to the same host at least
+// interval apart across all goroutines.
+type Prober struct {
+	client  *http.Client
+	spacing *pacing.Limiter
+}
+
+// New builds a Prober. A nil client uses one with a 15 second timeout; an
+// interval of 0 or less does not space requests.
+func New(client *http.Client, interval time.Duration) *Prober {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &Prober{client: client, spacing: pacing.NewLimiter(interval)}
+}
+
+// Check reports whether rawURL still serves an image. The error explains a
+// Dead or Unknown result.
+func (p *Prober) Check(ctx context.Context, rawURL string) (Result, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Dead, fmt.Errorf("not an http(s) url: %q", rawURL)
+	}
+
+	result, err := p.probe(ctx, http.MethodHead, parsed)
+	if result != Unknown || ctx.Err() != nil {
+		return result, err
+	}
+	// Plenty of CDNs answer HEAD with 403 or 405, or leave the content type
+	// out, while serving GET fine.
+	return p.probe(ctx, http.MethodGet, parsed)
+}
+
+func (p *Prober) probe(ctx context.Context, method string, target *url.URL) (Result, error) {
+	if err := p.spacing.Wait(ctx, strings.ToLower(target.Host)); err != nil {
+		return Unknown, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), nil)
+	if err != nil {
+		return Dead, fmt.Errorf("build %s request: %w", method, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "image/webp,image/jpeg,image/png,image/*;q=0.8")
+	if method == http.MethodGet {
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", sniffBytes-1))
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return Unknown, fmt.Errorf("%s: %w", method, err)
+	}
+	defer res.Body.Close()
+	// Servers that ignore Range send the whole image; only its start is
+	// read.
+	head, _ := io.ReadAll(io.LimitReader(res.Body, sniffBytes))
+
+	return classify(method, res, head)
+}
+
+// sniffBytes is how much of the image a GET reads, enough for
+// http.DetectContentType when the server sends no useful content type.
+const sniffBytes = 512
+
+// classify turns a response into a Result. A HEAD answer that does not
+// clearly say "image" or "gone" is Unknown, which makes Check retry with GET.
+func classify(method string, res *http.Response, head []byte) (Result, error) {
+	switch status := res.StatusCode; {
+	case status == http.StatusOK || status == http.StatusPartialContent:
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return Dead, fmt.Errorf("%s: status %d", method, status)
+	case method == http.MethodHead:
+		return Unknown, fmt.Errorf("%s: status %d", method, status)
+	case status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500:
+		return Unknown, fmt.Errorf("%s: status %d", method, status)
+	case status >= 400:
+		return Dead, fmt.Errorf("%s: status %d", method, status)
+	default:
+		return Unknown, fmt.Errorf("%s: status %d", method, status)
+	}
+
+	rawType := res.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(rawType)
+	if strings.HasPrefix(mediaType, "image/") {
+		return Alive, nil
+	}
+	if method == http.MethodHead {
+		return Unknown, fmt.Errorf("%s: content type %q", method, rawType)
+	}
+	// Some CDNs label every file application/octet-stream.
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		if sniffed := http.DetectContentType(head); strings.HasPrefix(sniffed, "image/") {
+			return Alive, nil
+		}
+	}
+	return Dead, fmt.Errorf("%s: content type %q is not an image", method, rawType)
+}
@@ -0,0 +1,128 @@
+package imageprobe
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// newCDN serves covers the way real CDNs do, good and bad, and counts the
+// requests each path got by method.
+func newCDN(t *testing.T) (*httptest.Server, func(method, path string) int) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		calls = map[string]int{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/covers/ok.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+		case "/covers/no-head.png":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.Header.Get("Range") == "" {
+				t.Errorf("expected a ranged GET")
+			}
+			w.Header().Set("Content-Type", "image/png")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(pngHeader)
+			return
+		case "/covers/octet.png":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(pngHeader)
+			}
+			return
+		case "/covers/error-page.jpg":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html>not here</html>"))
+			return
+		case "/covers/busy.jpg":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte("\xff\xd8\xff"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func(method, path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls[method+" "+path]
+	}
+}
+
+func TestCheckTellsLiveCoversFromDeadOnes(t *testing.T) {
+	server, calls := newCDN(t)
+	prober := New(server.Client(), 0)
+
+	cases := []struct {
+		path string
+		want Result
+	}{
+		{path: "/covers/ok.jpg", want: Alive},
+		{path: "/covers/no-head.png", want: Alive},
+		{path: "/covers/octet.png", want: Alive},
+		{path: "/covers/rotated.jpg", want: Dead},
+		{path: "/covers/error-page.jpg", want: Dead},
+		{path: "/covers/busy.jpg", want: Unknown},
+	}
+	for _, tc := range cases {
+		got, err := prober.Check(context.Background(), server.URL+tc.path)
+		if got != tc.want {
+			t.Fatalf("Check(%s) = %v (%v), want %v", tc.path, got, err, tc.want)
+		}
+		if got != Alive && err == nil {
+			t.Fatalf("expected a reason for %s being %v", tc.path, got)
+		}
+	}
+
+	if calls(http.MethodGet, "/covers/ok.jpg") != 0 {
+		t.Fatalf("expected a HEAD to settle a cover that answers it")
+	}
+	if calls(http.MethodGet, "/covers/rotated.jpg") != 0 {
+		t.Fatalf("expected a 404 on HEAD to settle a dead cover")
+	}
+
+	if got, _ := prober.Check(context.Background(), "ftp://example.com/cover.jpg"); got != Dead {
+		t.Fatalf("expected a non-http url to be dead, got %v", got)
+	}
+}
+
+func TestCheckSpacesRequestsToTheSameHost(t *testing.T) {
+	server, _ := newCDN(t)
+	prober := New(server.Client(), 40*time.Millisecond)
+
+	startedAt := time.Now()
+	for range 3 {
+		if got, err := prober.Check(context.Background(), server.URL+"/covers/ok.jpg"); got != Alive {
+			t.Fatalf("expected alive, got %v (%v)", got, err)
+		}
+	}
+	if elapsed := time.Since(startedAt); elapsed < 80*time.Millisecond {
+		t.Fatalf("expected three checks of one host to take at least two intervals, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, _ := prober.Check(ctx, server.URL+"/covers/ok.jpg"); got != Unknown {
+		t.Fatalf("expected a cancelled check to be unknown, got %v", got)
+	}
+}
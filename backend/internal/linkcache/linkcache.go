@@ -0,0 +1,522 @@
+// Package linkcache resolves tracker cover images and chapter URLs through
+// the connectors and caches the results. The dashboard uses it to fill cards
+// lazily and the warm-caches command uses it to fill the cache up front; found
+// links are also written to the link_cache table when a store is configured,
+// so a warmed cache outlives the process.
+package linkcache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+const (
+	kindCover      = "cover"
+	kindChapterURL = "chapter_url"
+
+	// FoundTTL is how long a found link stays in memory before it is
+	// looked up again.
+	FoundTTL = 12 * time.Hour
+	// StoredTTL is how long a found link stays in the persistent store.
+	// Covers and per-chapter URLs rarely move, so stored links outlive the
+	// in-memory entries they reload.
+	StoredTTL = 7 * 24 * time.Hour
+)
+
+type entry struct {
+	URL       string
+	Found     bool
+	ExpiresAt time.Time
+}
+
+// Connectors finds the connector of a source key. *connectors.Registry
+// implements it.
+type Connectors interface {
+	Get(key string) (connectors.Connector, bool)
+}
+
+// mediaFilter is implemented by Connectors that vet the cover and chapter
+// links connectors scrape, as *connectors.Registry does.
+type mediaFilter interface {
+	MediaURL(key string, rawURL string) string
+}
+
+// Resolver looks up and caches cover and chapter URLs.
+type Resolver struct {
+	registry    Connectors
+	store       *repository.LinkCacheRepository
+	allowed     func() error
+	coversMu    sync.RWMutex
+	covers      map[string]entry
+	chaptersMu  sync.RWMutex
+	chapterURLs map[string]entry
+}
+
+// NewResolver builds a Resolver. store may be nil to cache in memory only.
+// allowed, when set, is checked before every outbound lookup; a non-nil error
+// (such as connectors.ErrScrapingPaused) is returned instead of fetching.
+func NewResolver(registry Connectors, store *repository.LinkCacheRepository, allowed func() error) *Resolver {
+	if registry == nil {
+		registry = connectors.NewRegistry()
+	}
+	return &Resolver{
+		registry:    registry,
+		store:       store,
+		allowed:     allowed,
+		covers:      make(map[string]entry),
+		chapterURLs: make(map[string]entry),
+	}
+}
+
+func (r *Resolver) lookupAllowed() error {
+	if r.allowed == nil {
+		return nil
+	}
+	return r.allowed()
+}
+
+// CoverKey is the cache key of a tracker's cover: the source item id when
+// known, else the series URL.
+func CoverKey(sourceKey, sourceURL string, sourceItemID *string) string {
+	itemID := ""
+	if sourceItemID != nil {
+		itemID = strings.TrimSpace(*sourceItemID)
+	}
+
+	base := strings.ToLower(strings.TrimSpace(sourceKey)) + "|"
+	if itemID != "" {
+		return base + "item:" + strings.ToLower(itemID)
+	}
+
+	trimmedURL := strings.TrimSpace(sourceURL)
+	if trimmedURL != "" {
+		return base + "url:" + strings.ToLower(trimmedURL)
+	}
+
+	return base + "missing"
+}
+
+// ChapterURLKey is the cache key of one chapter's reader URL.
+func ChapterURLKey(sourceKey, sourceURL string, chapter float64) string {
+	return ChapterURLKeyPrefix(sourceKey, sourceURL) + strconv.FormatFloat(chapter, 'f', -1, 64)
+}
+
+// ChapterURLKeyPrefix is the part of ChapterURLKey shared by every chapter
+// of a series, for callers building the keys of several chapters.
+func ChapterURLKeyPrefix(sourceKey, sourceURL string) string {
+	return strings.ToLower(strings.TrimSpace(sourceKey)) + "|" + strings.ToLower(strings.TrimSpace(sourceURL)) + "|"
+}
+
+// CachedCover returns the cached cover for key. ok is false when nothing is
+// cached, found is false for a cached miss.
+func (r *Resolver) CachedCover(key string) (coverURL string, found bool, ok bool) {
+	return r.cached(&r.coversMu, r.covers, kindCover, key)
+}
+
+// SetCover caches a cover lookup result for ttl.
+func (r *Resolver) SetCover(key, coverURL string, found bool, ttl time.Duration) {
+	r.set(&r.coversMu, r.covers, kindCover, key, coverURL, found, ttl)
+}
+
+// SetResolvedCover caches the cover from a connector result the caller
+// already resolved sourceURL for, so the cover is not looked up a second
+// time. An empty coverImageURL is cached as a miss, unless the URL's host
+// has a connector of its own that Cover would still ask.
+func (r *Resolver) SetResolvedCover(sourceKey, sourceURL string, sourceItemID *string, coverImageURL string) {
+	trimmedSourceKey := strings.TrimSpace(sourceKey)
+	if trimmedSourceKey == "" || strings.TrimSpace(sourceURL) == "" {
+		return
+	}
+	cacheKey := CoverKey(trimmedSourceKey, sourceURL, sourceItemID)
+	if coverURL := strings.TrimSpace(r.mediaURL(trimmedSourceKey, coverImageURL)); coverURL != "" {
+		r.SetCover(cacheKey, coverURL, true, FoundTTL)
+		return
+	}
+	if fallbackKey := InferSourceKey(sourceURL); fallbackKey != "" && fallbackKey != trimmedSourceKey {
+		return
+	}
+	r.SetCover(cacheKey, "", false, 2*time.Minute)
+}
+
+// StoredCover returns the persisted cover for key, expired or not. ok is
+// false when nothing is stored, the resolver has no store, or the cover is
+// not a link the registry would hand out.
+func (r *Resolver) StoredCover(key string) (repository.LinkCacheEntry, bool, error) {
+	if r.store == nil {
+		return repository.LinkCacheEntry{}, false, nil
+	}
+	stored, ok, err := r.store.GetEntry(kindCover, key)
+	if err != nil || !ok {
+		return stored, ok, err
+	}
+	if !r.servable(key, stored.URL) {
+		r.drop(&r.coversMu, r.covers, kindCover, key)
+		return repository.LinkCacheEntry{}, false, nil
+	}
+	return stored, true, nil
+}
+
+// MarkCoverVerified records that the stored cover for key still serves an
+// image.
+func (r *Resolver) MarkCoverVerified(key string, at time.Time) error {
+	if r.store == nil {
+		return nil
+	}
+	return r.store.MarkVerified(kindCover, key, at)
+}
+
+// ForgetCover drops the cover for key from memory and from the store, so
+// the next render looks it up again.
+func (r *Resolver) ForgetCover(key string) error {
+	r.coversMu.Lock()
+	delete(r.covers, key)
+	r.coversMu.Unlock()
+	if r.store == nil {
+		return nil
+	}
+	return r.store.Delete(kindCover, key)
+}
+
+// CachedChapterURL is the chapter URL counterpart of CachedCover.
+func (r *Resolver) CachedChapterURL(key string) (chapterURL string, found bool, ok bool) {
+	return r.cached(&r.chaptersMu, r.chapterURLs, kindChapterURL, key)
+}
+
+// SetChapterURL caches a chapter URL lookup result for ttl.
+func (r *Resolver) SetChapterURL(key, chapterURL string, found bool, ttl time.Duration) {
+	r.set(&r.chaptersMu, r.chapterURLs, kindChapterURL, key, chapterURL, found, ttl)
+}
+
+// Link is a cached lookup result: the URL, or a cached miss when Found is
+// false. Cached is false when nothing is cached.
+type Link struct {
+	URL    string
+	Found  bool
+	Cached bool
+}
+
+// CachedCovers returns the covers of keys held in memory, in the order of
+// keys, taking the cache lock once for all of them. A key that is not
+// cached in memory may still be in the store; CachedCover answers those.
+func (r *Resolver) CachedCovers(keys []string) []Link {
+	return r.snapshot(&r.coversMu, r.covers, kindCover, keys)
+}
+
+// CachedChapterURLs is the chapter URL counterpart of CachedCovers.
+func (r *Resolver) CachedChapterURLs(keys []string) []Link {
+	return r.snapshot(&r.chaptersMu, r.chapterURLs, kindChapterURL, keys)
+}
+
+func (r *Resolver) snapshot(mu *sync.RWMutex, items map[string]entry, kind string, keys []string) []Link {
+	links := make([]Link, len(keys))
+	now := time.Now().UTC()
+	dropped := make([]string, 0)
+	mu.RLock()
+	for index, key := range keys {
+		// Expired entries are left for cached to drop.
+		item, ok := items[key]
+		if !ok || now.After(item.ExpiresAt) {
+			continue
+		}
+		if item.Found && !r.servable(key, item.URL) {
+			dropped = append(dropped, key)
+			continue
+		}
+		links[index] = Link{URL: item.URL, Found: item.Found, Cached: true}
+	}
+	mu.RUnlock()
+	for _, key := range dropped {
+		r.drop(mu, items, kind, key)
+	}
+	return links
+}
+
+func (r *Resolver) cached(mu *sync.RWMutex, items map[string]entry, kind, key string) (string, bool, bool) {
+	now := time.Now().UTC()
+	mu.RLock()
+	item, exists := items[key]
+	mu.RUnlock()
+	if exists && now.After(item.ExpiresAt) {
+		mu.Lock()
+		delete(items, key)
+		mu.Unlock()
+		exists = false
+	}
+	if exists && item.Found && !r.servable(key, item.URL) {
+		r.drop(mu, items, kind, key)
+		return "", false, false
+	}
+	if exists {
+		return item.URL, item.Found, true
+	}
+
+	if r.store == nil {
+		return "", false, false
+	}
+	storedURL, expiresAt, ok, err := r.store.Get(kind, key)
+	if err != nil {
+		slog.Warn("read link cache failed", "kind", kind, "error", err)
+		return "", false, false
+	}
+	if !ok || now.After(expiresAt) {
+		return "", false, false
+	}
+	if !r.servable(key, storedURL) {
+		r.drop(mu, items, kind, key)
+		return "", false, false
+	}
+
+	memoryExpiry := now.Add(FoundTTL)
+	if expiresAt.Before(memoryExpiry) {
+		memoryExpiry = expiresAt
+	}
+	mu.Lock()
+	items[key] = entry{URL: storedURL, Found: true, ExpiresAt: memoryExpiry}
+	mu.Unlock()
+	return storedURL, true, true
+}
+
+func (r *Resolver) set(mu *sync.RWMutex, items map[string]entry, kind, key, value string, found bool, ttl time.Duration) {
+	now := time.Now().UTC()
+	mu.Lock()
+	items[key] = entry{URL: value, Found: found, ExpiresAt: now.Add(ttl)}
+	mu.Unlock()
+
+	if !found || r.store == nil {
+		return
+	}
+	if err := r.store.Put(kind, key, value, now.Add(max(ttl, StoredTTL))); err != nil {
+		slog.Warn("write link cache failed", "kind", kind, "error", err)
+	}
+}
+
+// Cover returns the cover image URL of a tracker's series, resolving it
+// through the connector on a cache miss. When the connector for sourceKey
+// finds nothing, the connector matching the URL's host is tried too.
+func (r *Resolver) Cover(parent context.Context, sourceKey, sourceURL string, sourceItemID *string) (string, error) {
+	trimmedSourceKey := strings.TrimSpace(sourceKey)
+	if trimmedSourceKey == "" {
+		return "", fmt.Errorf("missing source key")
+	}
+
+	cacheKey := CoverKey(trimmedSourceKey, sourceURL, sourceItemID)
+	if cachedURL, found, ok := r.CachedCover(cacheKey); ok {
+		if found {
+			return cachedURL, nil
+		}
+		return "", fmt.Errorf("cover not found")
+	}
+	if err := r.lookupAllowed(); err != nil {
+		return "", err
+	}
+
+	resolvedURL := strings.TrimSpace(sourceURL)
+	if resolvedURL == "" {
+		r.SetCover(cacheKey, "", false, 2*time.Minute)
+		return "", fmt.Errorf("missing source url")
+	}
+
+	if coverURL, ok := r.resolveCover(parent, trimmedSourceKey, resolvedURL); ok {
+		r.SetCover(cacheKey, coverURL, true, FoundTTL)
+		return coverURL, nil
+	}
+
+	r.SetCover(cacheKey, "", false, 2*time.Minute)
+	return "", fmt.Errorf("cover not found")
+}
+
+// ResolveCover looks the cover up through the connectors like Cover does,
+// but neither reads nor writes the cache, so a stored cover can be checked
+// against what the source serves now.
+func (r *Resolver) ResolveCover(parent context.Context, sourceKey, sourceURL string) (string, error) {
+	trimmedSourceKey := strings.TrimSpace(sourceKey)
+	if trimmedSourceKey == "" {
+		return "", fmt.Errorf("missing source key")
+	}
+	resolvedURL := strings.TrimSpace(sourceURL)
+	if resolvedURL == "" {
+		return "", fmt.Errorf("missing source url")
+	}
+	if err := r.lookupAllowed(); err != nil {
+		return "", err
+	}
+
+	if coverURL, ok := r.resolveCover(parent, trimmedSourceKey, resolvedURL); ok {
+		return coverURL, nil
+	}
+	return "", fmt.Errorf("cover not found")
+}
+
+// resolveCover asks the connector for sourceKey and then, when it finds
+// nothing, the connector matching the URL's host.
+func (r *Resolver) resolveCover(parent context.Context, sourceKey, sourceURL string) (string, bool) {
+	tryKeys := make([]string, 0, 2)
+	tryKeys = append(tryKeys, sourceKey)
+
+	if fallbackKey := InferSourceKey(sourceURL); fallbackKey != "" && fallbackKey != sourceKey {
+		tryKeys = append(tryKeys, fallbackKey)
+	}
+
+	for _, key := range tryKeys {
+		coverURL, err := r.resolveCoverFromConnector(parent, key, sourceURL)
+		if err != nil {
+			continue
+		}
+		if coverURL == "" {
+			continue
+		}
+		return coverURL, true
+	}
+	return "", false
+}
+
+func (r *Resolver) resolveCoverFromConnector(parent context.Context, sourceKey, sourceURL string) (string, error) {
+	connector, ok := r.registry.Get(strings.TrimSpace(sourceKey))
+	if !ok {
+		return "", fmt.Errorf("connector not found")
+	}
+
+	resolveTimeout := 8 * time.Second
+	if key := strings.ToLower(strings.TrimSpace(sourceKey)); key == "mangafire" || key == "freewebnovel" {
+		resolveTimeout = 15 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(parent, resolveTimeout)
+	defer cancel()
+
+	result, err := connector.ResolveByURL(ctx, sourceURL)
+	if err != nil {
+		return "", err
+	}
+	if result == nil {
+		return "", fmt.Errorf("empty result")
+	}
+
+	return strings.TrimSpace(r.mediaURL(sourceKey, result.CoverImageURL)), nil
+}
+
+// ChapterURL returns the reader URL of one chapter, resolving it through the
+// connector on a cache miss. The series URL is returned alongside any error
+// so callers always have a link to fall back on.
+func (r *Resolver) ChapterURL(parent context.Context, sourceKey, sourceURL string, chapter float64) (string, error) {
+	trimmedSourceURL := strings.TrimSpace(sourceURL)
+	if trimmedSourceURL == "" {
+		return "", fmt.Errorf("missing source url")
+	}
+
+	trimmedSourceKey := strings.TrimSpace(sourceKey)
+	if trimmedSourceKey == "" {
+		return trimmedSourceURL, nil
+	}
+
+	cacheKey := ChapterURLKey(trimmedSourceKey, trimmedSourceURL, chapter)
+	if cachedChapterURL, found, ok := r.CachedChapterURL(cacheKey); ok {
+		if found {
+			return cachedChapterURL, nil
+		}
+		return trimmedSourceURL, fmt.Errorf("chapter url not found")
+	}
+	if err := r.lookupAllowed(); err != nil {
+		return trimmedSourceURL, err
+	}
+
+	connector, ok := r.registry.Get(trimmedSourceKey)
+	if !ok {
+		r.SetChapterURL(cacheKey, "", false, 30*time.Minute)
+		return trimmedSourceURL, fmt.Errorf("connector not found")
+	}
+
+	resolver, ok := connector.(connectors.ChapterURLResolver)
+	if !ok {
+		r.SetChapterURL(cacheKey, "", false, 30*time.Minute)
+		return trimmedSourceURL, fmt.Errorf("chapter resolver not supported")
+	}
+
+	ctx, cancel := context.WithTimeout(parent, 8*time.Second)
+	defer cancel()
+
+	chapterURL, err := resolver.ResolveChapterURL(ctx, trimmedSourceURL, chapter)
+	if err != nil {
+		r.SetChapterURL(cacheKey, "", false, 2*time.Minute)
+		return trimmedSourceURL, fmt.Errorf("resolve chapter url: %w", err)
+	}
+
+	chapterURL = strings.TrimSpace(r.mediaURL(trimmedSourceKey, chapterURL))
+	if chapterURL == "" {
+		r.SetChapterURL(cacheKey, "", false, 30*time.Minute)
+		return trimmedSourceURL, fmt.Errorf("chapter url empty")
+	}
+
+	r.SetChapterURL(cacheKey, chapterURL, true, FoundTTL)
+	return chapterURL, nil
+}
+
+// servable reports whether a cached link may still be handed out. Links are
+// vetted when resolved, but ones cached before the vetting, or before strict
+// media hosts was turned on, are vetted again here, so they are neither
+// linked nor fetched for a thumbnail.
+func (r *Resolver) servable(key string, cachedURL string) bool {
+	if strings.TrimSpace(cachedURL) == "" {
+		return true
+	}
+	sourceKey, _, _ := strings.Cut(key, "|")
+	return strings.TrimSpace(r.mediaURL(sourceKey, cachedURL)) != ""
+}
+
+// drop removes a link that is no longer servable from memory and from the
+// store, so it is looked up again.
+func (r *Resolver) drop(mu *sync.RWMutex, items map[string]entry, kind, key string) {
+	mu.Lock()
+	delete(items, key)
+	mu.Unlock()
+	if r.store == nil {
+		return
+	}
+	if err := r.store.Delete(kind, key); err != nil {
+		slog.Warn("drop link cache entry failed", "kind", kind, "error", err)
+	}
+}
+
+func (r *Resolver) mediaURL(sourceKey string, rawURL string) string {
+	if filter, ok := r.registry.(mediaFilter); ok {
+		return filter.MediaURL(strings.TrimSpace(sourceKey), rawURL)
+	}
+	return rawURL
+}
+
+// InferSourceKey guesses the connector key from a series URL's host, or
+// returns "" for unknown hosts.
+func InferSourceKey(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+
+	host := strings.ToLower(strings.TrimSpace(parsed.Hostname()))
+	switch {
+	case strings.Contains(host, "mangadex"):
+		return "mangadex"
+	case strings.Contains(host, "mangafire"):
+		return "mangafire"
+	case strings.Contains(host, "mgeko"):
+		return "mgeko"
+	case strings.Contains(host, "asura"):
+		return "asuracomic"
+	case strings.Contains(host, "flame"):
+		return "flamecomics"
+	case strings.Contains(host, "webtoons"):
+		return "webtoons"
+	case strings.Contains(host, "freewebnovel"):
+		return "freewebnovel"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,239 @@
+package linkcache
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+type coverConnectorStub struct {
+	calls *atomic.Int64
+}
+
+func (coverConnectorStub) Key() string                       { return "mangadex" }
+func (coverConnectorStub) Name() string                      { return "MangaDex" }
+func (coverConnectorStub) Kind() string                      { return connectors.KindNative }
+func (coverConnectorStub) HealthCheck(context.Context) error { return nil }
+
+func (s coverConnectorStub) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	s.calls.Add(1)
+	return &connectors.MangaResult{SourceKey: "mangadex", URL: rawURL, CoverImageURL: rawURL + "/cover.jpg"}, nil
+}
+
+func (coverConnectorStub) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+
+func (s coverConnectorStub) ResolveChapterURL(_ context.Context, rawURL string, chapter float64) (string, error) {
+	s.calls.Add(1)
+	return rawURL + "/chapter/" + strconv.FormatFloat(chapter, 'f', -1, 64), nil
+}
+
+func setupStore(t *testing.T) *repository.LinkCacheRepository {
+	t.Helper()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, currentFile, _, _ := runtime.Caller(0)
+	migrationsPath := filepath.Join(filepath.Dir(currentFile), "..", "..", "migrations")
+	if err := database.ApplyMigrations(db, migrationsPath); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	return repository.NewLinkCacheRepository(db)
+}
+
+func TestStoredLinksSurviveANewResolver(t *testing.T) {
+	store := setupStore(t)
+	var calls atomic.Int64
+	registry := connectors.NewRegistry()
+	if err := registry.Register(coverConnectorStub{calls: &calls}); err != nil {
+		t.Fatalf("register stub: %v", err)
+	}
+	sourceURL := "https://mangadex.org/title/warm-series"
+
+	warm := NewResolver(registry, store, nil)
+	coverURL, err := warm.Cover(context.Background(), "mangadex", sourceURL, nil)
+	if err != nil || coverURL != sourceURL+"/cover.jpg" {
+		t.Fatalf("expected resolved cover, got %q (%v)", coverURL, err)
+	}
+	chapterURL, err := warm.ChapterURL(context.Background(), "mangadex", sourceURL, 12)
+	if err != nil {
+		t.Fatalf("resolve chapter url: %v", err)
+	}
+
+	fresh := NewResolver(registry, store, nil)
+	if cached, found, ok := fresh.CachedCover(CoverKey("mangadex", sourceURL, nil)); !ok || !found || cached != coverURL {
+		t.Fatalf("expected stored cover %q, got %q found=%v ok=%v", coverURL, cached, found, ok)
+	}
+	if cached, err := fresh.ChapterURL(context.Background(), "mangadex", sourceURL, 12); err != nil || cached != chapterURL {
+		t.Fatalf("expected stored chapter url %q, got %q (%v)", chapterURL, cached, err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected the fresh resolver to skip the connector, got %d lookups", got)
+	}
+}
+
+func TestStoreSkipsMissesAndExpiredLinks(t *testing.T) {
+	store := setupStore(t)
+	resolver := NewResolver(nil, store, nil)
+
+	resolver.SetCover("mangadex|url:missing", "", false, time.Hour)
+	if err := store.Put(kindCover, "mangadex|url:expired", "https://example.com/old.jpg", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("put expired entry: %v", err)
+	}
+
+	fresh := NewResolver(nil, store, nil)
+	if _, _, ok := fresh.CachedCover("mangadex|url:missing"); ok {
+		t.Fatalf("expected cached misses to stay in memory only")
+	}
+	if _, _, ok := fresh.CachedCover("mangadex|url:expired"); ok {
+		t.Fatalf("expected expired stored link to be ignored")
+	}
+
+	removed, err := store.DeleteExpired(time.Now())
+	if err != nil || removed != 1 {
+		t.Fatalf("expected one expired entry removed, got %d (%v)", removed, err)
+	}
+}
+
+func TestStoredCoverVerificationFollowsTheURL(t *testing.T) {
+	store := setupStore(t)
+	var calls atomic.Int64
+	registry := connectors.NewRegistry()
+	if err := registry.Register(coverConnectorStub{calls: &calls}); err != nil {
+		t.Fatalf("register stub: %v", err)
+	}
+	resolver := NewResolver(registry, store, nil)
+	sourceURL := "https://mangadex.org/title/verified-series"
+	key := CoverKey("mangadex", sourceURL, nil)
+
+	resolver.SetCover(key, "https://cdn.example/v1.jpg", true, FoundTTL)
+	verifiedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	if err := resolver.MarkCoverVerified(key, verifiedAt); err != nil {
+		t.Fatalf("mark verified: %v", err)
+	}
+	resolver.SetCover(key, "https://cdn.example/v1.jpg", true, FoundTTL)
+	if entry, ok, err := resolver.StoredCover(key); err != nil || !ok || entry.LastVerifiedAt == nil || !entry.LastVerifiedAt.Equal(verifiedAt) {
+		t.Fatalf("expected storing the same cover to keep its verification, got %+v ok=%v (%v)", entry, ok, err)
+	}
+	resolver.SetCover(key, "https://cdn.example/v2.jpg", true, FoundTTL)
+	if entry, _, _ := resolver.StoredCover(key); entry.URL != "https://cdn.example/v2.jpg" || entry.LastVerifiedAt != nil {
+		t.Fatalf("expected a new cover to need verifying again, got %+v", entry)
+	}
+
+	// ResolveCover goes to the connector even with a cover cached, and
+	// leaves the cache alone.
+	fresh, err := resolver.ResolveCover(context.Background(), "mangadex", sourceURL)
+	if err != nil || fresh != sourceURL+"/cover.jpg" || calls.Load() != 1 {
+		t.Fatalf("expected a connector lookup, got %q (%v) after %d calls", fresh, err, calls.Load())
+	}
+	if cached, _, _ := resolver.CachedCover(key); cached != "https://cdn.example/v2.jpg" {
+		t.Fatalf("expected the cached cover untouched, got %q", cached)
+	}
+
+	if err := resolver.ForgetCover(key); err != nil {
+		t.Fatalf("forget cover: %v", err)
+	}
+	if _, ok, _ := resolver.StoredCover(key); ok {
+		t.Fatalf("expected the forgotten cover gone from the store")
+	}
+	if _, _, ok := resolver.CachedCover(key); ok {
+		t.Fatalf("expected the forgotten cover gone from memory")
+	}
+}
+
+func TestLookupAllowedGuardsOutboundRequests(t *testing.T) {
+	var calls atomic.Int64
+	registry := connectors.NewRegistry()
+	if err := registry.Register(coverConnectorStub{calls: &calls}); err != nil {
+		t.Fatalf("register stub: %v", err)
+	}
+	resolver := NewResolver(registry, nil, func() error { return connectors.ErrScrapingPaused })
+
+	if _, err := resolver.Cover(context.Background(), "mangadex", "https://mangadex.org/title/paused", nil); err != connectors.ErrScrapingPaused {
+		t.Fatalf("expected paused error, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected no connector calls while paused")
+	}
+}
+
+func TestSetResolvedCoverSkipsTheLookup(t *testing.T) {
+	var calls atomic.Int64
+	registry := connectors.NewRegistry()
+	if err := registry.Register(coverConnectorStub{calls: &calls}); err != nil {
+		t.Fatalf("register stub: %v", err)
+	}
+	resolver := NewResolver(registry, nil, nil)
+
+	resolver.SetResolvedCover("mangadex", "https://mangadex.org/title/known", nil, "https://mangadex.org/covers/known.jpg")
+	if got, err := resolver.Cover(context.Background(), "mangadex", "https://mangadex.org/title/known", nil); err != nil || got != "https://mangadex.org/covers/known.jpg" {
+		t.Fatalf("expected the resolved cover, got %q, %v", got, err)
+	}
+	resolver.SetResolvedCover("mangadex", "https://mangadex.org/title/bare", nil, "")
+	if _, err := resolver.Cover(context.Background(), "mangadex", "https://mangadex.org/title/bare", nil); err == nil {
+		t.Fatalf("expected a cached miss for a result without a cover")
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected no connector calls, got %d", calls.Load())
+	}
+}
+
+func TestStrictMediaHostsDropsLinksCachedBefore(t *testing.T) {
+	store := setupStore(t)
+	var calls atomic.Int64
+	registry := connectors.NewRegistry()
+	if err := registry.Register(coverConnectorStub{calls: &calls}); err != nil {
+		t.Fatalf("register stub: %v", err)
+	}
+	resolver := NewResolver(registry, store, nil)
+	key := CoverKey("mangadex", "https://mangadex.org/title/cached", nil)
+	resolver.SetCover(key, "http://169.254.169.254/latest/cover.jpg", true, time.Hour)
+	chapterKey := ChapterURLKey("mangadex", "https://mangadex.org/title/cached", 3)
+	resolver.SetChapterURL(chapterKey, "http://intranet.example/chapter/3", true, time.Hour)
+
+	registry.SetStrictMediaHosts(true)
+	if links := resolver.CachedCovers([]string{key}); links[0].Cached {
+		t.Fatalf("expected the snapshot to leave out the cover, got %+v", links[0])
+	}
+	if _, _, ok := resolver.CachedCover(key); ok {
+		t.Fatalf("expected the cover cached before strict mode to be dropped")
+	}
+	if _, ok, err := resolver.StoredCover(key); ok || err != nil {
+		t.Fatalf("expected no stored cover, got ok=%v err=%v", ok, err)
+	}
+	if _, _, ok := resolver.CachedChapterURL(chapterKey); ok {
+		t.Fatalf("expected the chapter link cached before strict mode to be dropped")
+	}
+
+	registry.SetStrictMediaHosts(false)
+	if _, _, ok := NewResolver(registry, store, nil).CachedCover(key); ok {
+		t.Fatalf("expected the dropped cover gone from the store")
+	}
+}
+
+func TestInferSourceKeySupportsMgeko(t *testing.T) {
+	inferred := InferSourceKey("https://www.mgeko.cc/manga/sample-series/")
+	if inferred != "mgeko" {
+		t.Fatalf("expected inferred source key mgeko, got %q", inferred)
+	}
+}
+
+func TestInferSourceKeySupportsFreeWebNovel(t *testing.T) {
+	inferred := InferSourceKey("https://freewebnovel.com/novel/star-odyssey")
+	if inferred != "freewebnovel" {
+		t.Fatalf("expected inferred source key freewebnovel, got %q", inferred)
+	}
+}
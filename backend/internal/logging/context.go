@@ -0,0 +1,43 @@
+// Package logging carries a request-scoped logger and request ID through a
+// context.Context so code below the HTTP layer can tag its log lines with the
+// request that triggered them.
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// WithRequest returns a copy of ctx carrying the request ID and a logger that
+// already includes it.
+func WithRequest(ctx context.Context, requestID string, logger *slog.Logger) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey, requestID)
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger stored in ctx, or slog.Default() when there
+// is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// RequestID returns the request ID stored in ctx, or "" outside a request.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
@@ -0,0 +1,224 @@
+package mangadexsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAuthURL    = "https://auth.mangadex.org/realms/mangadex/protocol/openid-connect/token"
+	defaultAPIBaseURL = "https://api.mangadex.org"
+	// maxIDsPerRequest is the most ids[] MangaDex accepts in one request.
+	maxIDsPerRequest = 100
+)
+
+// ErrInvalidCredentials is returned when MangaDex rejects a login or a
+// refresh token; the profile has to be linked again.
+var ErrInvalidCredentials = errors.New("mangadex rejected the credentials")
+
+// Token is a MangaDex OAuth2 token pair.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// Client talks to the MangaDex auth server and API as one personal API
+// client.
+type Client struct {
+	authURL      string
+	apiBaseURL   string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+}
+
+func NewClient(clientID string, clientSecret string) *Client {
+	return NewClientWithOptions(defaultAuthURL, defaultAPIBaseURL, clientID, clientSecret, nil)
+}
+
+func NewClientWithOptions(authURL string, apiBaseURL string, clientID string, clientSecret string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &Client{
+		authURL:      authURL,
+		apiBaseURL:   strings.TrimRight(apiBaseURL, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   httpClient,
+	}
+}
+
+// Login exchanges the account's username and password for a token pair.
+// The password is not kept.
+func (c *Client) Login(ctx context.Context, username string, password string) (Token, error) {
+	return c.requestToken(ctx, url.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+	})
+}
+
+// Refresh trades a refresh token for a new token pair. MangaDex may rotate
+// the refresh token, so callers store the returned one.
+func (c *Client) Refresh(ctx context.Context, refreshToken string) (Token, error) {
+	return c.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func (c *Client) requestToken(ctx context.Context, form url.Values) (Token, error) {
+	form.Set("client_id", c.clientID)
+	form.Set("client_secret", c.clientSecret)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.authURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, fmt.Errorf("create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return Token{}, fmt.Errorf("request token: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusBadRequest || res.StatusCode == http.StatusUnauthorized {
+		return Token{}, ErrInvalidCredentials
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return Token{}, fmt.Errorf("token request: unexpected status %d", res.StatusCode)
+	}
+
+	var payload struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return Token{}, fmt.Errorf("decode token: %w", err)
+	}
+	if payload.AccessToken == "" || payload.RefreshToken == "" {
+		return Token{}, fmt.Errorf("token response is missing tokens")
+	}
+	return Token{AccessToken: payload.AccessToken, RefreshToken: payload.RefreshToken}, nil
+}
+
+// ReadingStatuses maps every title the account follows to its reading
+// status ("reading", "completed", ...).
+func (c *Client) ReadingStatuses(ctx context.Context, accessToken string) (map[string]string, error) {
+	var payload struct {
+		Statuses map[string]string `json:"statuses"`
+	}
+	if err := c.getJSON(ctx, accessToken, "/manga/status", nil, &payload); err != nil {
+		return nil, fmt.Errorf("load reading statuses: %w", err)
+	}
+	if payload.Statuses == nil {
+		payload.Statuses = map[string]string{}
+	}
+	return payload.Statuses, nil
+}
+
+// ReadChapterIDs returns the ids of the chapters marked read on each title.
+func (c *Client) ReadChapterIDs(ctx context.Context, accessToken string, titleIDs []string) (map[string][]string, error) {
+	read := make(map[string][]string, len(titleIDs))
+	for _, batch := range batches(titleIDs) {
+		query := url.Values{"grouped": {"true"}}
+		for _, id := range batch {
+			query.Add("ids[]", id)
+		}
+		var payload struct {
+			Data map[string][]string `json:"data"`
+		}
+		if err := c.getJSON(ctx, accessToken, "/manga/read", query, &payload); err != nil {
+			return nil, fmt.Errorf("load read markers: %w", err)
+		}
+		for titleID, chapterIDs := range payload.Data {
+			read[titleID] = append(read[titleID], chapterIDs...)
+		}
+	}
+	return read, nil
+}
+
+// ChapterNumbers returns the chapter number of each chapter id. Chapters
+// without a number (oneshots, extras) are left out.
+func (c *Client) ChapterNumbers(ctx context.Context, accessToken string, chapterIDs []string) (map[string]float64, error) {
+	numbers := make(map[string]float64, len(chapterIDs))
+	for _, batch := range batches(chapterIDs) {
+		query := url.Values{"limit": {strconv.Itoa(maxIDsPerRequest)}}
+		for _, rating := range []string{"safe", "suggestive", "erotica", "pornographic"} {
+			query.Add("contentRating[]", rating)
+		}
+		for _, id := range batch {
+			query.Add("ids[]", id)
+		}
+		var payload struct {
+			Data []struct {
+				ID         string `json:"id"`
+				Attributes struct {
+					Chapter *string `json:"chapter"`
+				} `json:"attributes"`
+			} `json:"data"`
+		}
+		if err := c.getJSON(ctx, accessToken, "/chapter", query, &payload); err != nil {
+			return nil, fmt.Errorf("load chapters: %w", err)
+		}
+		for _, chapter := range payload.Data {
+			if chapter.Attributes.Chapter == nil {
+				continue
+			}
+			number, err := strconv.ParseFloat(strings.TrimSpace(*chapter.Attributes.Chapter), 64)
+			if err != nil {
+				continue
+			}
+			numbers[chapter.ID] = number
+		}
+	}
+	return numbers, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, accessToken string, path string, query url.Values, target any) error {
+	endpoint := c.apiBaseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s: %w", path, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusUnauthorized {
+		return ErrInvalidCredentials
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return fmt.Errorf("unexpected status: %d", res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
+
+func batches(ids []string) [][]string {
+	result := make([][]string, 0, (len(ids)+maxIDsPerRequest-1)/maxIDsPerRequest)
+	for start := 0; start < len(ids); start += maxIDsPerRequest {
+		end := min(start+maxIDsPerRequest, len(ids))
+		result = append(result, ids[start:end])
+	}
+	return result
+}
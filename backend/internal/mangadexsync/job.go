@@ -0,0 +1,316 @@
+// Package mangadexsync pulls read progress from linked MangaDex accounts
+// into the matching trackers.
+package mangadexsync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// ErrNotLinked is returned when syncing a profile without a MangaDex link.
+var ErrNotLinked = errors.New("no mangadex account is linked to this profile")
+
+type syncRepository interface {
+	GetLink(ctx context.Context, profileID int64) (*models.MangaDexLink, error)
+	ListEnabledLinks(ctx context.Context) ([]models.MangaDexLink, error)
+	UpsertLink(ctx context.Context, profileID int64, username string, sealedToken string, syncEnabled bool) error
+	UpdateSealedToken(ctx context.Context, profileID int64, sealedToken string) error
+	ListMangaDexTrackers(ctx context.Context, profileID int64) ([]repository.MangaDexTracker, error)
+	RecordRun(ctx context.Context, run models.MangaDexSyncRun) error
+}
+
+// PauseState reports the global scraping pause. The settings repository
+// implements it.
+type PauseState interface {
+	ScrapingPaused() (bool, error)
+}
+
+type lastReadUpdater interface {
+	UpdateLastReadChapter(ctx context.Context, profileID int64, id int64, lastReadChapter *float64) (bool, error)
+}
+
+type Job struct {
+	repo     syncRepository
+	trackers lastReadUpdater
+	client   *Client
+	sealer   *Sealer
+	pause    PauseState
+	interval time.Duration
+	logger   *slog.Logger
+	now      func() time.Time
+	stopCh   chan struct{}
+}
+
+type JobConfig struct {
+	// Interval is how often every profile with sync enabled is pulled.
+	Interval time.Duration
+	// Pause, when set, holds off syncing while scraping is paused.
+	Pause PauseState
+}
+
+func JobConfigFrom(cfg config.Config) JobConfig {
+	return JobConfig{Interval: time.Duration(cfg.MangaDexSyncHours) * time.Hour}
+}
+
+func NewJob(repo syncRepository, trackers lastReadUpdater, client *Client, sealer *Sealer, cfg JobConfig, logger *slog.Logger) *Job {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 6 * time.Hour
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Job{
+		repo:     repo,
+		trackers: trackers,
+		client:   client,
+		sealer:   sealer,
+		pause:    cfg.Pause,
+		interval: cfg.Interval,
+		logger:   logger,
+		now:      time.Now,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// NewJobFromConfig builds the job from the app's MangaDex settings. It
+// expects cfg.MangaDexConfigured() to hold.
+func NewJobFromConfig(db *sql.DB, cfg config.Config, logger *slog.Logger) (*Job, error) {
+	sealer, err := NewSealer(cfg.MangaDexTokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	jobConfig := JobConfigFrom(cfg)
+	jobConfig.Pause = repository.NewSettingsRepository(db)
+	return NewJob(
+		repository.NewMangaDexSyncRepository(db),
+		repository.NewTrackerRepository(db),
+		NewClient(cfg.MangaDexClientID, cfg.MangaDexClientSecret),
+		sealer,
+		jobConfig,
+		logger,
+	), nil
+}
+
+func (j *Job) Start(ctx context.Context) {
+	j.logger.Info("mangadex sync job started", "interval", j.interval.String())
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				j.logger.Info("mangadex sync job stopped")
+				close(j.stopCh)
+				return
+			case <-ticker.C:
+				if err := j.RunOnce(ctx); errors.Is(err, connectors.ErrScrapingPaused) {
+					j.logger.Info("mangadex sync cycle skipped", "reason", err.Error())
+				} else if err != nil {
+					j.logger.Warn("mangadex sync cycle failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (j *Job) StopWait(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	select {
+	case <-j.stopCh:
+	case <-time.After(timeout):
+	}
+}
+
+// Link signs in to MangaDex as the account and stores its refresh token
+// for the profile.
+func (j *Job) Link(ctx context.Context, profileID int64, username string, password string, syncEnabled bool) error {
+	token, err := j.client.Login(ctx, username, password)
+	if err != nil {
+		return err
+	}
+	sealed, err := j.sealer.Seal(token.RefreshToken)
+	if err != nil {
+		return err
+	}
+	return j.repo.UpsertLink(ctx, profileID, username, sealed, syncEnabled)
+}
+
+// RunOnce pulls every profile with sync enabled. A failing profile is
+// logged and recorded in its sync log; it never stops the others. While
+// scraping is paused it returns connectors.ErrScrapingPaused instead.
+func (j *Job) RunOnce(ctx context.Context) error {
+	if err := j.allowed(); err != nil {
+		return err
+	}
+	links, err := j.repo.ListEnabledLinks(ctx)
+	if err != nil {
+		return fmt.Errorf("load mangadex links: %w", err)
+	}
+	for _, link := range links {
+		if _, err := j.sync(ctx, link); err != nil {
+			j.logger.Warn("mangadex sync failed", "profileId", link.ProfileID, "error", err)
+		}
+	}
+	return nil
+}
+
+// SyncProfile pulls the profile's read progress now, whether or not its
+// background sync is enabled. It returns connectors.ErrScrapingPaused while
+// scraping is paused.
+func (j *Job) SyncProfile(ctx context.Context, profileID int64) (models.MangaDexSyncRun, error) {
+	if err := j.allowed(); err != nil {
+		return models.MangaDexSyncRun{}, err
+	}
+	link, err := j.repo.GetLink(ctx, profileID)
+	if err != nil {
+		return models.MangaDexSyncRun{}, fmt.Errorf("load mangadex link: %w", err)
+	}
+	if link == nil {
+		return models.MangaDexSyncRun{}, ErrNotLinked
+	}
+	return j.sync(ctx, *link)
+}
+
+// allowed returns connectors.ErrScrapingPaused while scraping is paused. A
+// failed read of the pause state is logged and lets the sync run.
+func (j *Job) allowed() error {
+	if j.pause == nil {
+		return nil
+	}
+	paused, err := j.pause.ScrapingPaused()
+	if err != nil {
+		j.logger.Warn("read scraping pause state failed", "error", err)
+		return nil
+	}
+	if paused {
+		return connectors.ErrScrapingPaused
+	}
+	return nil
+}
+
+func (j *Job) sync(ctx context.Context, link models.MangaDexLink) (models.MangaDexSyncRun, error) {
+	run := models.MangaDexSyncRun{ProfileID: link.ProfileID, StartedAt: j.now().UTC()}
+	checked, updated, err := j.pull(ctx, link)
+	run.FinishedAt = j.now().UTC()
+	run.TrackersChecked = checked
+	run.TrackersUpdated = updated
+	if err != nil {
+		run.Error = err.Error()
+	}
+	if recordErr := j.repo.RecordRun(ctx, run); recordErr != nil {
+		j.logger.Warn("mangadex sync log failed", "profileId", link.ProfileID, "error", recordErr)
+	}
+	if err == nil {
+		j.logger.Info("mangadex sync processed", "profileId", link.ProfileID, "checked", checked, "updated", updated)
+	}
+	return run, err
+}
+
+// pull raises the last read chapter of each of the profile's MangaDex
+// trackers the account follows to the highest chapter marked read there.
+// Local progress ahead of MangaDex is kept: the highest chapter wins.
+func (j *Job) pull(ctx context.Context, link models.MangaDexLink) (int, int, error) {
+	refreshToken, err := j.sealer.Open(link.SealedToken)
+	if err != nil {
+		return 0, 0, err
+	}
+	token, err := j.client.Refresh(ctx, refreshToken)
+	if err != nil {
+		return 0, 0, err
+	}
+	if token.RefreshToken != refreshToken {
+		sealed, err := j.sealer.Seal(token.RefreshToken)
+		if err != nil {
+			return 0, 0, err
+		}
+		if err := j.repo.UpdateSealedToken(ctx, link.ProfileID, sealed); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	statuses, err := j.client.ReadingStatuses(ctx, token.AccessToken)
+	if err != nil {
+		return 0, 0, err
+	}
+	trackers, err := j.repo.ListMangaDexTrackers(ctx, link.ProfileID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	followed := make([]repository.MangaDexTracker, 0, len(trackers))
+	titleIDs := make([]string, 0, len(trackers))
+	seenTitles := make(map[string]bool, len(trackers))
+	for _, tracker := range trackers {
+		titleID := strings.ToLower(strings.TrimSpace(tracker.TitleID))
+		if _, ok := statuses[titleID]; !ok {
+			continue
+		}
+		tracker.TitleID = titleID
+		followed = append(followed, tracker)
+		if !seenTitles[titleID] {
+			seenTitles[titleID] = true
+			titleIDs = append(titleIDs, titleID)
+		}
+	}
+	if len(followed) == 0 {
+		return 0, 0, nil
+	}
+
+	readByTitle, err := j.client.ReadChapterIDs(ctx, token.AccessToken, titleIDs)
+	if err != nil {
+		return len(followed), 0, err
+	}
+	chapterIDs := make([]string, 0)
+	for _, ids := range readByTitle {
+		chapterIDs = append(chapterIDs, ids...)
+	}
+	numbers, err := j.client.ChapterNumbers(ctx, token.AccessToken, chapterIDs)
+	if err != nil {
+		return len(followed), 0, err
+	}
+
+	updated := 0
+	for _, tracker := range followed {
+		highest, ok := highestReadChapter(readByTitle[tracker.TitleID], numbers)
+		if !ok || (tracker.LastReadChapter != nil && *tracker.LastReadChapter >= highest) {
+			continue
+		}
+		changed, err := j.trackers.UpdateLastReadChapter(ctx, link.ProfileID, tracker.ID, &highest)
+		if err != nil {
+			return len(followed), updated, err
+		}
+		if changed {
+			updated++
+		}
+	}
+	return len(followed), updated, nil
+}
+
+func highestReadChapter(chapterIDs []string, numbers map[string]float64) (float64, bool) {
+	var highest float64
+	found := false
+	for _, id := range chapterIDs {
+		number, ok := numbers[id]
+		if !ok {
+			continue
+		}
+		if !found || number > highest {
+			highest = number
+			found = true
+		}
+	}
+	return highest, found
+}
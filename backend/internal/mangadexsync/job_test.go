@@ -0,0 +1,298 @@
+package mangadexsync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+const (
+	titleFollowedBehind = "a1a1a1a1-0000-4000-8000-000000000001"
+	titleFollowedAhead  = "b2b2b2b2-0000-4000-8000-000000000002"
+	titleNotFollowed    = "c3c3c3c3-0000-4000-8000-000000000003"
+	titleFollowedUnread = "d4d4d4d4-0000-4000-8000-000000000004"
+)
+
+type fakeRepo struct {
+	link     *models.MangaDexLink
+	trackers []repository.MangaDexTracker
+	runs     []models.MangaDexSyncRun
+}
+
+func (f *fakeRepo) GetLink(_ context.Context, profileID int64) (*models.MangaDexLink, error) {
+	if f.link == nil || f.link.ProfileID != profileID {
+		return nil, nil
+	}
+	link := *f.link
+	return &link, nil
+}
+
+func (f *fakeRepo) ListEnabledLinks(_ context.Context) ([]models.MangaDexLink, error) {
+	if f.link == nil || !f.link.SyncEnabled {
+		return nil, nil
+	}
+	return []models.MangaDexLink{*f.link}, nil
+}
+
+func (f *fakeRepo) UpsertLink(_ context.Context, profileID int64, username string, sealedToken string, syncEnabled bool) error {
+	f.link = &models.MangaDexLink{ProfileID: profileID, Username: username, SealedToken: sealedToken, SyncEnabled: syncEnabled}
+	return nil
+}
+
+func (f *fakeRepo) UpdateSealedToken(_ context.Context, _ int64, sealedToken string) error {
+	f.link.SealedToken = sealedToken
+	return nil
+}
+
+func (f *fakeRepo) ListMangaDexTrackers(_ context.Context, _ int64) ([]repository.MangaDexTracker, error) {
+	return f.trackers, nil
+}
+
+func (f *fakeRepo) RecordRun(_ context.Context, run models.MangaDexSyncRun) error {
+	f.runs = append(f.runs, run)
+	return nil
+}
+
+type fakeTrackers struct {
+	updates map[int64]float64
+}
+
+func (f *fakeTrackers) UpdateLastReadChapter(_ context.Context, _ int64, id int64, lastReadChapter *float64) (bool, error) {
+	if f.updates == nil {
+		f.updates = map[int64]float64{}
+	}
+	f.updates[id] = *lastReadChapter
+	return true, nil
+}
+
+// newFixtureServer replays the recorded MangaDex responses in testdata. It
+// rejects refresh tokens other than wantRefresh the way the auth server
+// does.
+func newFixtureServer(t *testing.T, wantRefresh string) *httptest.Server {
+	t.Helper()
+	fixture := func(name string) http.HandlerFunc {
+		body, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("read fixture %s: %v", name, err)
+		}
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(body)
+		}
+	}
+	token := fixture("token.json")
+	api := map[string]http.HandlerFunc{
+		"/manga/status": fixture("manga_status.json"),
+		"/manga/read":   fixture("manga_read.json"),
+		"/chapter":      fixture("chapters.json"),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("client_id") != "client" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.PostForm.Get("grant_type") == "refresh_token" && r.PostForm.Get("refresh_token") != wantRefresh {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			token(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer access-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler, ok := api[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newFixtureJob(t *testing.T, server *httptest.Server, repo *fakeRepo, trackers *fakeTrackers) (*Job, *Sealer) {
+	t.Helper()
+	sealer, err := NewSealer("test-secret")
+	if err != nil {
+		t.Fatalf("new sealer: %v", err)
+	}
+	client := NewClientWithOptions(server.URL+"/token", server.URL, "client", "secret", server.Client())
+	return NewJob(repo, trackers, client, sealer, JobConfig{}, nil), sealer
+}
+
+func linkedRepo(t *testing.T, sealer *Sealer, refreshToken string, trackers []repository.MangaDexTracker) *fakeRepo {
+	t.Helper()
+	sealed, err := sealer.Seal(refreshToken)
+	if err != nil {
+		t.Fatalf("seal token: %v", err)
+	}
+	return &fakeRepo{
+		link:     &models.MangaDexLink{ProfileID: 1, Username: "reader", SealedToken: sealed, SyncEnabled: true},
+		trackers: trackers,
+	}
+}
+
+func chapter(value float64) *float64 {
+	return &value
+}
+
+func TestSyncProfile_HighestChapterWins(t *testing.T) {
+	server := newFixtureServer(t, "refresh-1")
+	sealer, _ := NewSealer("test-secret")
+	repo := linkedRepo(t, sealer, "refresh-1", []repository.MangaDexTracker{
+		{ID: 1, TitleID: titleFollowedBehind, LastReadChapter: chapter(10)},
+		{ID: 2, TitleID: titleFollowedAhead, LastReadChapter: chapter(30)},
+		{ID: 3, TitleID: titleNotFollowed},
+		{ID: 4, TitleID: titleFollowedUnread, LastReadChapter: chapter(2)},
+	})
+	trackers := &fakeTrackers{}
+	job, _ := newFixtureJob(t, server, repo, trackers)
+
+	run, err := job.SyncProfile(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("sync profile: %v", err)
+	}
+
+	if len(trackers.updates) != 1 || trackers.updates[1] != 12.5 {
+		t.Fatalf("expected only tracker 1 to move to chapter 12.5, got %v", trackers.updates)
+	}
+	if run.TrackersChecked != 3 || run.TrackersUpdated != 1 || run.Error != "" {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+	if len(repo.runs) != 1 {
+		t.Fatalf("expected the run to be logged, got %d entries", len(repo.runs))
+	}
+}
+
+func TestSyncProfile_StoresRotatedRefreshToken(t *testing.T) {
+	server := newFixtureServer(t, "refresh-1")
+	job, sealer := newFixtureJob(t, server, nil, &fakeTrackers{})
+	repo := linkedRepo(t, sealer, "refresh-1", nil)
+	job.repo = repo
+
+	if _, err := job.SyncProfile(context.Background(), 1); err != nil {
+		t.Fatalf("sync profile: %v", err)
+	}
+
+	stored, err := sealer.Open(repo.link.SealedToken)
+	if err != nil {
+		t.Fatalf("open stored token: %v", err)
+	}
+	if stored != "refresh-2" {
+		t.Fatalf("expected the rotated refresh token to be stored, got %q", stored)
+	}
+}
+
+func TestSyncProfile_LogsRejectedRefreshToken(t *testing.T) {
+	server := newFixtureServer(t, "refresh-1")
+	job, sealer := newFixtureJob(t, server, nil, &fakeTrackers{})
+	repo := linkedRepo(t, sealer, "revoked", nil)
+	job.repo = repo
+
+	run, err := job.SyncProfile(context.Background(), 1)
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected invalid credentials, got %v", err)
+	}
+	if len(repo.runs) != 1 || repo.runs[0].Error == "" || run.Error == "" {
+		t.Fatalf("expected the failed run to be logged with its error, got %+v", repo.runs)
+	}
+}
+
+func TestSyncProfile_RequiresLink(t *testing.T) {
+	server := newFixtureServer(t, "refresh-1")
+	job, _ := newFixtureJob(t, server, &fakeRepo{}, &fakeTrackers{})
+
+	if _, err := job.SyncProfile(context.Background(), 1); !errors.Is(err, ErrNotLinked) {
+		t.Fatalf("expected not linked, got %v", err)
+	}
+}
+
+func TestRunOnce_SkipsProfilesWithSyncDisabled(t *testing.T) {
+	server := newFixtureServer(t, "refresh-1")
+	sealer, _ := NewSealer("test-secret")
+	repo := linkedRepo(t, sealer, "refresh-1", []repository.MangaDexTracker{
+		{ID: 1, TitleID: titleFollowedBehind, LastReadChapter: chapter(10)},
+	})
+	repo.link.SyncEnabled = false
+	trackers := &fakeTrackers{}
+	job, _ := newFixtureJob(t, server, repo, trackers)
+
+	if err := job.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	if len(trackers.updates) != 0 || len(repo.runs) != 0 {
+		t.Fatalf("expected a disabled profile to be left alone, got updates %v runs %d", trackers.updates, len(repo.runs))
+	}
+}
+
+type pausedSettings struct{}
+
+func (pausedSettings) ScrapingPaused() (bool, error) { return true, nil }
+
+func TestSyncHoldsOffWhileScrapingIsPaused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected mangadex request while paused: %s", r.URL.Path)
+		http.Error(w, "paused", http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+	sealer, _ := NewSealer("test-secret")
+	repo := linkedRepo(t, sealer, "refresh-1", []repository.MangaDexTracker{
+		{ID: 1, TitleID: titleFollowedBehind, LastReadChapter: chapter(10)},
+	})
+	trackers := &fakeTrackers{}
+	client := NewClientWithOptions(server.URL+"/token", server.URL, "client", "secret", server.Client())
+	job := NewJob(repo, trackers, client, sealer, JobConfig{Pause: pausedSettings{}}, nil)
+
+	if err := job.RunOnce(context.Background()); !errors.Is(err, connectors.ErrScrapingPaused) {
+		t.Fatalf("expected run once to report the pause, got %v", err)
+	}
+	if _, err := job.SyncProfile(context.Background(), repo.link.ProfileID); !errors.Is(err, connectors.ErrScrapingPaused) {
+		t.Fatalf("expected sync profile to report the pause, got %v", err)
+	}
+	if len(trackers.updates) != 0 || len(repo.runs) != 0 {
+		t.Fatalf("expected nothing synced or logged while paused, got updates %v runs %d", trackers.updates, len(repo.runs))
+	}
+}
+
+func TestLink_StoresSealedRefreshToken(t *testing.T) {
+	server := newFixtureServer(t, "refresh-1")
+	repo := &fakeRepo{}
+	job, sealer := newFixtureJob(t, server, repo, &fakeTrackers{})
+
+	if err := job.Link(context.Background(), 1, "reader", "hunter2", true); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	if repo.link == nil || repo.link.SealedToken == "refresh-2" {
+		t.Fatalf("expected a sealed token to be stored, got %+v", repo.link)
+	}
+	stored, err := sealer.Open(repo.link.SealedToken)
+	if err != nil || stored != "refresh-2" {
+		t.Fatalf("expected the stored token to open to refresh-2, got %q (%v)", stored, err)
+	}
+
+	other, _ := NewSealer("another-secret")
+	if _, err := other.Open(repo.link.SealedToken); err == nil {
+		t.Fatalf("expected a different secret to fail to open the token")
+	}
+}
+
+func TestJobConfigDefaultsInterval(t *testing.T) {
+	job := NewJob(&fakeRepo{}, &fakeTrackers{}, nil, nil, JobConfig{}, nil)
+	if job.interval != 6*time.Hour {
+		t.Fatalf("expected 6h default interval, got %s", job.interval)
+	}
+}
@@ -0,0 +1,61 @@
+package mangadexsync
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Sealer encrypts refresh tokens for storage with AES-GCM, keyed by a
+// SHA-256 of the configured secret.
+type Sealer struct {
+	aead cipher.AEAD
+}
+
+func NewSealer(secret string) (*Sealer, error) {
+	if secret == "" {
+		return nil, errors.New("token secret is required")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("create token cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create token cipher: %w", err)
+	}
+	return &Sealer{aead: aead}, nil
+}
+
+// Seal returns the token encrypted under a fresh nonce, base64 encoded.
+func (s *Sealer) Seal(token string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("read token nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(token), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a token sealed with the same secret. It fails when the
+// secret has changed since.
+func (s *Sealer) Open(sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("decode sealed token: %w", err)
+	}
+	if len(raw) < s.aead.NonceSize() {
+		return "", errors.New("sealed token is too short")
+	}
+	nonce, ciphertext := raw[:s.aead.NonceSize()], raw[s.aead.NonceSize():]
+	token, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("open sealed token: %w", err)
+	}
+	return string(token), nil
+}
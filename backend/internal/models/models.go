@@ -9,19 +9,37 @@ type Source struct {
 	ConnectorKind string    `json:"connectorKind"`
 	BaseURL       *string   `json:"baseUrl,omitempty"`
 	ConfigPath    *string   `json:"configPath,omitempty"`
+	SearchMode    string    `json:"searchMode"`
 	Enabled       bool      `json:"enabled"`
 	CreatedAt     time.Time `json:"createdAt"`
 	UpdatedAt     time.Time `json:"updatedAt"`
+
+	// StatusNote explains a known issue with the source; empty when there is
+	// none. NoteSource is "manual" or "auto" (written by the poller).
+	StatusNote          string     `json:"statusNote,omitempty"`
+	StatusNoteUpdatedAt *time.Time `json:"statusNoteUpdatedAt,omitempty"`
+	NoteSource          string     `json:"noteSource,omitempty"`
 }
 
 type Profile struct {
-	ID        int64     `json:"id"`
-	Key       string    `json:"key"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID             int64     `json:"id"`
+	Key            string    `json:"key"`
+	Name           string    `json:"name"`
+	PollingEnabled bool      `json:"pollingEnabled"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// TrackerSortDefault is the sort and order a dashboard status opens with.
+type TrackerSortDefault struct {
+	Sort  string `json:"sort"`
+	Order string `json:"order"`
 }
 
+// StatusSortDefaults maps a tracker status to its dashboard sort default.
+// Statuses without an entry use the global default.
+type StatusSortDefaults map[string]TrackerSortDefault
+
 type Tracker struct {
 	ID                 int64       `json:"id"`
 	ProfileID          int64       `json:"profileId"`
@@ -40,6 +58,53 @@ type Tracker struct {
 	Tags               []CustomTag `json:"tags,omitempty"`
 	CreatedAt          time.Time   `json:"createdAt"`
 	UpdatedAt          time.Time   `json:"updatedAt"`
+
+	// Reading milestones, set once by the repository: FirstReadAt when a last
+	// read chapter is first recorded, CaughtUpAt when the last read chapter
+	// first reaches the latest known one while reading or completed.
+	// CaughtUpAt keeps that first occurrence even after new chapters appear.
+	FirstReadAt *time.Time `json:"firstReadAt,omitempty"`
+	CaughtUpAt  *time.Time `json:"caughtUpAt,omitempty"`
+
+	// ResolveFailure explains why the source lookup was given up on after
+	// the tracker was created; nil once the source has resolved.
+	ResolveFailure *string `json:"resolveFailure,omitempty"`
+
+	// LastPollError is why the poller's last resolve of the source failed,
+	// and LastPollErrorAt when; both are nil after a successful poll.
+	LastPollError   *string    `json:"lastPollError,omitempty"`
+	LastPollErrorAt *time.Time `json:"lastPollErrorAt,omitempty"`
+
+	// ContinuedByTrackerID is the tracker that carries the series on, such
+	// as a Season 2 published under a new URL; nil when there is none.
+	ContinuedByTrackerID *int64 `json:"continuedByTrackerId,omitempty"`
+
+	// SourceGenres are the genres the primary source last listed for the
+	// series, kept when a lookup comes back without any.
+	SourceGenres []string `json:"sourceGenres,omitempty"`
+
+	// Rereading is set while a completed series is read again from chapter
+	// 0; RereadCount is how many re-reads were finished, and
+	// PreviousCompletions the read-throughs they started over from, oldest
+	// first.
+	Rereading           bool                `json:"rereading"`
+	RereadCount         int                 `json:"rereadCount"`
+	PreviousCompletions []TrackerCompletion `json:"previousCompletions,omitempty"`
+
+	// NextScheduledChapter is a chapter the source has announced but not
+	// released yet, out at NextScheduledAt; it is not counted as the latest
+	// known chapter until then.
+	NextScheduledChapter *float64   `json:"nextScheduledChapter,omitempty"`
+	NextScheduledAt      *time.Time `json:"nextScheduledAt,omitempty"`
+}
+
+// TrackerCompletion is a finished read-through kept when a re-read starts:
+// the last read chapter it reached, when that chapter was read, and when the
+// re-read replaced it.
+type TrackerCompletion struct {
+	LastReadChapter *float64   `json:"lastReadChapter,omitempty"`
+	LastReadAt      *time.Time `json:"lastReadAt,omitempty"`
+	RereadStartedAt time.Time  `json:"rereadStartedAt"`
 }
 
 type CustomTag struct {
@@ -52,6 +117,27 @@ type CustomTag struct {
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// FilterPreset is a named set of dashboard filters a profile saved.
+type FilterPreset struct {
+	ID        int64              `json:"id"`
+	ProfileID int64              `json:"profileId"`
+	Name      string             `json:"name"`
+	Params    FilterPresetParams `json:"params"`
+	CreatedAt time.Time          `json:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+}
+
+// FilterPresetParams are the dashboard filters of a preset, one field per
+// filter form input. Tags holds tag filter terms such as "favorite",
+// "a|b" or "-stale"; empty fields leave the dashboard default.
+type FilterPresetParams struct {
+	Query  string   `json:"q,omitempty"`
+	Status string   `json:"status,omitempty"`
+	Sort   string   `json:"sort,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Sites  []int64  `json:"sites,omitempty"`
+}
+
 type TrackerSource struct {
 	ID           int64     `json:"id"`
 	TrackerID    int64     `json:"trackerId"`
@@ -61,6 +147,31 @@ type TrackerSource struct {
 	SourceURL    string    `json:"sourceUrl"`
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
+
+	// Lang is the translation followed on sources that serve several; it
+	// defaults to "en".
+	Lang string `json:"lang"`
+
+	// ChapterOffset is added to the source's chapter numbers when they are
+	// compared with another source's, for sites that number the series
+	// differently; it defaults to 0.
+	ChapterOffset float64 `json:"chapterOffset"`
+
+	// Position orders the tracker's linked sources, lowest first; it breaks
+	// ties whenever one of them has to be picked over the others.
+	Position int `json:"position"`
+
+	// Poll statistics maintained by the scheduler when it resolves every
+	// linked source of a tracker.
+	SuccessCount    int      `json:"successCount"`
+	FailureCount    int      `json:"failureCount"`
+	LastLagChapters *float64 `json:"lastLagChapters,omitempty"`
+	LastPollFailed  bool     `json:"lastPollFailed"`
+	Reliability     string   `json:"reliability,omitempty"`
+
+	// MismatchSuspected flags a linked source that may be a different series
+	// than the tracker; it stays set until the user dismisses it.
+	MismatchSuspected bool `json:"mismatchSuspected"`
 }
 
 type Chapter struct {
@@ -78,3 +189,139 @@ type Setting struct {
 	Value     string    `json:"value"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+// ProfileTrackerSource is a tracker_sources row seen from the source side,
+// with the tracker it belongs to. Primary marks the row that is the
+// tracker's primary source.
+type ProfileTrackerSource struct {
+	TrackerSource
+	SourceKey     string `json:"sourceKey"`
+	TrackerTitle  string `json:"trackerTitle"`
+	TrackerStatus string `json:"trackerStatus"`
+	Primary       bool   `json:"primary"`
+}
+
+// TrackerSourceCount is how many of a profile's tracker_sources rows are on
+// one source, split into primary and linked-only rows.
+type TrackerSourceCount struct {
+	SourceID   int64  `json:"sourceId"`
+	SourceKey  string `json:"sourceKey"`
+	SourceName string `json:"sourceName"`
+	Primary    int    `json:"primary"`
+	Linked     int    `json:"linked"`
+}
+
+// ReadSourceCount is how many times last_read advanced from one source's
+// links, for a tracker or summed over a profile.
+type ReadSourceCount struct {
+	SourceID   int64     `json:"sourceId"`
+	SourceKey  string    `json:"sourceKey"`
+	SourceName string    `json:"sourceName"`
+	Reads      int       `json:"reads"`
+	LastReadAt time.Time `json:"lastReadAt"`
+}
+
+// ReadEvent is one advance of a tracker's last read chapter. FromChapter
+// is nil for the first chapter ever read; Chapters is the advance size,
+// and 0 for that first read, which marks where reading started rather than
+// how much was read.
+type ReadEvent struct {
+	ID          int64     `json:"id"`
+	TrackerID   int64     `json:"trackerId"`
+	FromChapter *float64  `json:"fromChapter"`
+	ToChapter   float64   `json:"toChapter"`
+	Chapters    float64   `json:"chapters"`
+	ReadAt      time.Time `json:"readAt"`
+	// Reread is set for reads made while re-reading the series.
+	Reread bool `json:"reread"`
+}
+
+// ReadChapterTotals sums a profile's read chapters, with the chapters read
+// during re-reads counted apart from first reads.
+type ReadChapterTotals struct {
+	Chapters       float64 `json:"chapters"`
+	RereadChapters float64 `json:"rereadChapters"`
+}
+
+// OverlapTracker is one side of a TrackerOverlap.
+type OverlapTracker struct {
+	ProfileID          int64    `json:"profileId"`
+	TrackerID          int64    `json:"trackerId"`
+	Title              string   `json:"title"`
+	Status             string   `json:"status"`
+	LastReadChapter    *float64 `json:"lastReadChapter"`
+	LatestKnownChapter *float64 `json:"latestKnownChapter"`
+}
+
+// TrackerOverlap pairs two profiles' trackers of the same series. MatchedBy
+// lists how they matched: "title", "source_url" or both. ChapterDelta is
+// Left's last read chapter minus Right's, nil unless both have one.
+type TrackerOverlap struct {
+	Left         OverlapTracker `json:"left"`
+	Right        OverlapTracker `json:"right"`
+	MatchedBy    []string       `json:"matchedBy"`
+	ChapterDelta *float64       `json:"chapterDelta"`
+}
+
+// ResolvedTrackerURL is the tracker a source URL belongs to. MatchedBy is
+// "source_url" for the tracker's primary URL, "linked_source" for one of
+// its linked URLs or "history" for a URL it was moved away from; Reason and
+// ReplacedAt are set for the last. SourceID and SourceURL are the tracker's
+// primary source as it is now.
+type ResolvedTrackerURL struct {
+	TrackerID  int64      `json:"trackerId"`
+	Title      string     `json:"title"`
+	SourceID   int64      `json:"sourceId"`
+	SourceURL  string     `json:"sourceUrl"`
+	MatchedURL string     `json:"matchedUrl"`
+	MatchedBy  string     `json:"matchedBy"`
+	Reason     *string    `json:"reason"`
+	ReplacedAt *time.Time `json:"replacedAt"`
+}
+
+// TrackerReleaseSchedule is the weekday a tracker usually releases on, in
+// UTC. Weekday is nil when the release history is too short or irregular.
+type TrackerReleaseSchedule struct {
+	TrackerID  int64   `json:"trackerId"`
+	Title      string  `json:"title"`
+	Weekday    *string `json:"weekday"`
+	Confidence float64 `json:"confidence"`
+	Samples    int     `json:"samples"`
+}
+
+// MangaDexLink is a profile's linked MangaDex account. The refresh token
+// is stored sealed and never leaves the server.
+type MangaDexLink struct {
+	ProfileID    int64      `json:"profileId"`
+	Username     string     `json:"username"`
+	SyncEnabled  bool       `json:"syncEnabled"`
+	LastSyncedAt *time.Time `json:"lastSyncedAt,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	SealedToken  string     `json:"-"`
+}
+
+// MangaDexSyncRun is one entry of a profile's MangaDex sync log.
+type MangaDexSyncRun struct {
+	ID              int64     `json:"id"`
+	ProfileID       int64     `json:"profileId"`
+	StartedAt       time.Time `json:"startedAt"`
+	FinishedAt      time.Time `json:"finishedAt"`
+	TrackersChecked int       `json:"trackersChecked"`
+	TrackersUpdated int       `json:"trackersUpdated"`
+	Error           string    `json:"error,omitempty"`
+}
+
+type ProfileEmailDigest struct {
+	ProfileID  int64      `json:"profileId"`
+	Email      string     `json:"email"`
+	HourUTC    int        `json:"hourUtc"`
+	Enabled    bool       `json:"enabled"`
+	LastSentAt *time.Time `json:"lastSentAt,omitempty"`
+	// IncludeTagID limits the digest to trackers with that tag, and
+	// ExcludeTagID leaves out the trackers with it.
+	IncludeTagID *int64    `json:"includeTagId,omitempty"`
+	ExcludeTagID *int64    `json:"excludeTagId,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,277 @@
+// Package openapi holds the hand-maintained OpenAPI 3 document for the /v1
+// JSON API and a small checker that validates response bodies against it.
+//
+// The checker covers the subset of JSON Schema the document uses: $ref,
+// type, nullable, enum, required, properties, additionalProperties, items
+// and the date-time format. Objects are closed: a property the schema does
+// not list is an error unless additionalProperties gives it a schema, so a
+// renamed or misspelled field fails validation instead of passing as extra.
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed openapi.json
+var document []byte
+
+// JSON returns the document as served, with servers pointing at basePath
+// (the root when empty) so clients behind a sub-path resolve the paths.
+func JSON(basePath string) ([]byte, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(document, &raw); err != nil {
+		return nil, fmt.Errorf("decode openapi document: %w", err)
+	}
+	url := basePath
+	if url == "" {
+		url = "/"
+	}
+	raw["servers"] = []any{map[string]any{"url": url}}
+	return json.Marshal(raw)
+}
+
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []any              `json:"enum"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+}
+
+// Load parses the embedded document and checks that every $ref in it
+// resolves.
+func Load() (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, fmt.Errorf("decode openapi document: %w", err)
+	}
+	var problems []string
+	visit := func(where string, schema *Schema) {
+		doc.walk(schema, func(s *Schema) {
+			if s.Ref != "" && doc.resolve(s.Ref) == nil {
+				problems = append(problems, fmt.Sprintf("%s: unresolved $ref %s", where, s.Ref))
+			}
+		})
+	}
+	for name, schema := range doc.Components.Schemas {
+		visit("components.schemas."+name, schema)
+	}
+	for path, operations := range doc.Paths {
+		for method, operation := range operations {
+			for status, response := range operation.Responses {
+				for _, media := range response.Content {
+					visit(fmt.Sprintf("%s %s %s", strings.ToUpper(method), path, status), media.Schema)
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid openapi document: %s", strings.Join(problems, "; "))
+	}
+	return &doc, nil
+}
+
+// Operation returns the operation documented for method on the path
+// template, such as "/v1/trackers/{id}", or nil.
+func (d *Document) Operation(method string, path string) *Operation {
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+// ValidateResponse checks a response against the operation's documented
+// response for status: the status must be documented, and the body must
+// match its JSON schema, or be empty when none is documented.
+func (d *Document) ValidateResponse(method string, path string, status int, body []byte) error {
+	operation := d.Operation(method, path)
+	if operation == nil {
+		return fmt.Errorf("%s %s is not documented", method, path)
+	}
+	response := operation.Responses[strconv.Itoa(status)]
+	if response == nil {
+		return fmt.Errorf("%s %s: status %d is not documented", method, path, status)
+	}
+	media, ok := response.Content["application/json"]
+	if !ok || media.Schema == nil {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return fmt.Errorf("%s %s: status %d documents no body, got %q", method, path, status, body)
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%s %s: status %d body is not JSON: %w", method, path, status, err)
+	}
+	if problems := d.Validate(media.Schema, value); len(problems) > 0 {
+		return fmt.Errorf("%s %s: status %d body does not match the spec:\n  %s", method, path, status, strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Validate returns every place value departs from schema, each prefixed
+// with its JSON path. value is decoded JSON with numbers as json.Number.
+func (d *Document) Validate(schema *Schema, value any) []string {
+	var problems []string
+	d.validate(schema, value, "$", &problems)
+	return problems
+}
+
+func (d *Document) validate(schema *Schema, value any, path string, problems *[]string) {
+	if schema == nil {
+		return
+	}
+	if schema.Ref != "" {
+		resolved := d.resolve(schema.Ref)
+		if resolved == nil {
+			*problems = append(*problems, fmt.Sprintf("%s: unresolved $ref %s", path, schema.Ref))
+			return
+		}
+		schema = resolved
+	}
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			fail("is null, expected %s", schema.Type)
+		}
+		return
+	}
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		fail("%v is not one of %v", value, schema.Enum)
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			fail("expected an object, got %T", value)
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			switch {
+			case ok:
+				d.validate(property, object[name], path+"."+name, problems)
+			case schema.AdditionalProperties != nil:
+				d.validate(schema.AdditionalProperties, object[name], path+"."+name, problems)
+			default:
+				fail("property %q is not in the spec", name)
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fail("expected an array, got %T", value)
+			return
+		}
+		for index, item := range items {
+			d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, index), problems)
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			fail("expected a string, got %T", value)
+			return
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+				fail("%q is not an RFC 3339 date-time", text)
+			}
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			fail("expected an integer, got %T", value)
+			return
+		}
+		if _, err := number.Int64(); err != nil {
+			fail("%s is not an integer", number)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			fail("expected a number, got %T", value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("expected a boolean, got %T", value)
+		}
+	}
+}
+
+func (d *Document) resolve(ref string) *Schema {
+	name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+	if !ok {
+		return nil
+	}
+	return d.Components.Schemas[name]
+}
+
+// walk calls visit on schema and every schema nested in it, without
+// following $refs.
+func (d *Document) walk(schema *Schema, visit func(*Schema)) {
+	if schema == nil {
+		return
+	}
+	visit(schema)
+	for _, property := range schema.Properties {
+		d.walk(property, visit)
+	}
+	d.walk(schema.AdditionalProperties, visit)
+	d.walk(schema.Items, visit)
+}
+
+func enumContains(enum []any, value any) bool {
+	for _, candidate := range enum {
+		if fmt.Sprint(candidate) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
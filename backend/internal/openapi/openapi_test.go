@@ -0,0 +1,81 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func loadTestDocument(t *testing.T) *Document {
+	t.Helper()
+	doc, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return doc
+}
+
+func TestLoadResolvesTheEmbeddedDocument(t *testing.T) {
+	doc := loadTestDocument(t)
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Fatalf("expected an OpenAPI 3 document, got %q", doc.OpenAPI)
+	}
+	if doc.Operation("get", "/v1/trackers/{id}") == nil || doc.Operation("PUT", "/v1/trackers/{id}") == nil {
+		t.Fatal("expected the tracker operations to be documented")
+	}
+}
+
+func TestValidateResponseReportsDrift(t *testing.T) {
+	doc := loadTestDocument(t)
+	valid := `{"id":1,"profileId":1,"name":"Fav","createdAt":"2026-03-01T12:00:00Z","updatedAt":"2026-03-01T12:00:00Z"}`
+	if err := doc.ValidateResponse("POST", "/v1/tags", 201, []byte(valid)); err != nil {
+		t.Fatalf("expected the tag to validate, got %v", err)
+	}
+
+	cases := map[string]struct {
+		status int
+		body   string
+		want   string
+	}{
+		"renamed field":       {201, `{"id":1,"profile_id":1,"name":"Fav","createdAt":"2026-03-01T12:00:00Z","updatedAt":"2026-03-01T12:00:00Z"}`, `property "profile_id" is not in the spec`},
+		"missing field":       {201, `{"id":1,"name":"Fav","createdAt":"2026-03-01T12:00:00Z","updatedAt":"2026-03-01T12:00:00Z"}`, `missing required property "profileId"`},
+		"wrong type":          {201, `{"id":"1","profileId":1,"name":"Fav","createdAt":"2026-03-01T12:00:00Z","updatedAt":"2026-03-01T12:00:00Z"}`, "$.id: expected an integer"},
+		"bad time":            {201, `{"id":1,"profileId":1,"name":"Fav","createdAt":"2026-03-01 12:00:00","updatedAt":"2026-03-01T12:00:00Z"}`, "not an RFC 3339 date-time"},
+		"unexpected null":     {201, `{"id":1,"profileId":1,"name":null,"createdAt":"2026-03-01T12:00:00Z","updatedAt":"2026-03-01T12:00:00Z"}`, "$.name: is null"},
+		"undocumented status": {200, valid, "status 200 is not documented"},
+	}
+	for name, tc := range cases {
+		err := doc.ValidateResponse("POST", "/v1/tags", tc.status, []byte(tc.body))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: expected an error containing %q, got %v", name, tc.want, err)
+		}
+	}
+
+	if err := doc.ValidateResponse("DELETE", "/v1/tags/{id}", 204, nil); err != nil {
+		t.Fatalf("expected an empty 204 to validate, got %v", err)
+	}
+	if err := doc.ValidateResponse("DELETE", "/v1/tags/{id}", 204, []byte(`{}`)); err == nil {
+		t.Fatal("expected a body on a 204 to fail")
+	}
+	if err := doc.ValidateResponse("GET", "/v1/nope", 200, nil); err == nil {
+		t.Fatal("expected an undocumented path to fail")
+	}
+}
+
+func TestJSONPointsServersAtTheBasePath(t *testing.T) {
+	raw, err := JSON("/tracker")
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var served struct {
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+	}
+	if err := json.Unmarshal(raw, &served); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(served.Servers) != 1 || served.Servers[0].URL != "/tracker" {
+		t.Fatalf("expected the base path as the only server, got %+v", served.Servers)
+	}
+}
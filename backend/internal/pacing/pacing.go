@@ -0,0 +1,49 @@
+// Package pacing spaces requests that share a key, such as a source or a
+// host, at least an interval apart across all goroutines, so bulk jobs do
+// not hammer one site.
+package pacing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter hands out one slot per key every interval.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time
+}
+
+// NewLimiter builds a Limiter. An interval of 0 or less does not space
+// requests.
+func NewLimiter(interval time.Duration) *Limiter {
+	return &Limiter{interval: interval, next: make(map[string]time.Time)}
+}
+
+// Wait blocks until key's next slot, or returns ctx's error if ctx ends
+// first.
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	if l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[key]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[key] = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
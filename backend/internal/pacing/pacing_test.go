@@ -0,0 +1,37 @@
+package pacing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterSpacesRequestsPerKey(t *testing.T) {
+	limiter := NewLimiter(40 * time.Millisecond)
+	ctx := context.Background()
+
+	startedAt := time.Now()
+	for range 3 {
+		if err := limiter.Wait(ctx, "mgeko"); err != nil {
+			t.Fatalf("wait: %v", err)
+		}
+	}
+	if elapsed := time.Since(startedAt); elapsed < 80*time.Millisecond {
+		t.Fatalf("expected three lookups to take at least two intervals, took %s", elapsed)
+	}
+
+	otherStartedAt := time.Now()
+	if err := limiter.Wait(ctx, "mangadex"); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if elapsed := time.Since(otherStartedAt); elapsed > 30*time.Millisecond {
+		t.Fatalf("expected another source not to wait, took %s", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_ = limiter.Wait(ctx, "mgeko")
+	if err := limiter.Wait(cancelled, "mgeko"); err == nil {
+		t.Fatalf("expected a cancelled wait to return the context error")
+	}
+}
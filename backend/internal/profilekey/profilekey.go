@@ -0,0 +1,78 @@
+// Package profilekey defines the canonical shape of profile keys: 3 to 32
+// lowercase ASCII letters, digits, dashes and underscores. Keys travel in
+// query strings, headers, redirects and share links, so anything outside
+// that set is refused where keys come in rather than escaped where they
+// go out.
+package profilekey
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	MinLength = 3
+	MaxLength = 32
+)
+
+// ErrInvalid is wrapped by every error Validate returns.
+var ErrInvalid = errors.New("invalid profile key")
+
+// Normalize trims and lowercases a key as typed, so " Profile1" finds
+// "profile1". It does not make a malformed key valid.
+func Normalize(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
+
+// Validate reports whether key is in canonical form. It does not normalize
+// first.
+func Validate(key string) error {
+	if len(key) < MinLength || len(key) > MaxLength {
+		return fmt.Errorf("%w: %q must be %d to %d characters", ErrInvalid, key, MinLength, MaxLength)
+	}
+	for _, r := range key {
+		if !allowed(r) {
+			return fmt.Errorf("%w: %q may only hold lowercase letters, digits, dashes and underscores", ErrInvalid, key)
+		}
+	}
+	return nil
+}
+
+// Suggest turns a non-conforming key into a canonical one: runs of other
+// characters become a dash, short keys are padded with "-profile" and long
+// ones cut. taken reports keys already in use; a number is appended until
+// the suggestion is free.
+func Suggest(raw string, taken func(key string) bool) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range Normalize(raw) {
+		if allowed(r) {
+			builder.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && builder.Len() > 0 {
+			builder.WriteByte('-')
+			dash = true
+		}
+	}
+	base := strings.Trim(builder.String(), "-_")
+	if len(base) < MinLength {
+		base = strings.TrimLeft(base+"-profile", "-")
+	}
+	if len(base) > MaxLength {
+		base = strings.TrimRight(base[:MaxLength], "-_")
+	}
+
+	candidate := base
+	for n := 2; taken != nil && taken(candidate); n++ {
+		suffix := fmt.Sprintf("-%d", n)
+		candidate = strings.TrimRight(base[:min(len(base), MaxLength-len(suffix))], "-_") + suffix
+	}
+	return candidate
+}
+
+func allowed(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_'
+}
@@ -0,0 +1,66 @@
+package profilekey
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{key: "profile1", valid: true},
+		{key: "my-list_2", valid: true},
+		{key: "abc", valid: true},
+		{key: strings.Repeat("a", 32), valid: true},
+		{key: "ab", valid: false},
+		{key: strings.Repeat("a", 33), valid: false},
+		{key: "Profile1", valid: false},
+		{key: "my list", valid: false},
+		{key: "café", valid: false},
+		{key: "a/b/c", valid: false},
+	}
+	for _, test := range tests {
+		err := Validate(test.key)
+		if test.valid && err != nil {
+			t.Errorf("expected %q to be valid, got %v", test.key, err)
+		}
+		if !test.valid && !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected %q to be invalid, got %v", test.key, err)
+		}
+	}
+}
+
+func TestNormalizeOnlyTrimsAndLowercases(t *testing.T) {
+	if got := Normalize("  Profile1 "); got != "profile1" {
+		t.Fatalf("expected profile1, got %q", got)
+	}
+	if got := Normalize("My List"); got != "my list" {
+		t.Fatalf("expected spaces to be kept, got %q", got)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	taken := map[string]bool{"reading-list": true, "reading-list-2": true}
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "Reading List!!", want: "reading-list-3"},
+		{raw: "Café Night", want: "caf-night"},
+		{raw: "x", want: "x-profile"},
+		{raw: "日本", want: "profile"},
+		{raw: strings.Repeat("ab ", 20), want: "ab-ab-ab-ab-ab-ab-ab-ab-ab-ab-ab"},
+	}
+	for _, test := range tests {
+		got := Suggest(test.raw, func(key string) bool { return taken[key] })
+		if got != test.want {
+			t.Errorf("Suggest(%q) = %q, want %q", test.raw, got, test.want)
+		}
+		if err := Validate(got); err != nil {
+			t.Errorf("Suggest(%q) is not canonical: %v", test.raw, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CoverThumbnailRepository stores downscaled cover images in the
+// cover_thumbnails table, for deployments where only the database is
+// writable.
+type CoverThumbnailRepository struct {
+	db *sql.DB
+}
+
+func NewCoverThumbnailRepository(db *sql.DB) *CoverThumbnailRepository {
+	return &CoverThumbnailRepository{db: db}
+}
+
+// UpdatedAt reports when the thumbnail for sourceKey and itemID was stored,
+// without loading the image. ok is false when none is stored.
+func (r *CoverThumbnailRepository) UpdatedAt(sourceKey string, itemID string) (updatedAt time.Time, ok bool, err error) {
+	err = r.db.QueryRow(`
+		SELECT updated_at FROM cover_thumbnails WHERE source_key = ? AND item_id = ?
+	`, sourceKey, itemID).Scan(&updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("get cover thumbnail time: %w", err)
+	}
+	return updatedAt.UTC(), true, nil
+}
+
+func (r *CoverThumbnailRepository) Get(sourceKey string, itemID string) (data []byte, contentType string, updatedAt time.Time, ok bool, err error) {
+	err = r.db.QueryRow(`
+		SELECT data, content_type, updated_at FROM cover_thumbnails WHERE source_key = ? AND item_id = ?
+	`, sourceKey, itemID).Scan(&data, &contentType, &updatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", time.Time{}, false, nil
+		}
+		return nil, "", time.Time{}, false, fmt.Errorf("get cover thumbnail: %w", err)
+	}
+	return data, contentType, updatedAt.UTC(), true, nil
+}
+
+func (r *CoverThumbnailRepository) Put(sourceKey string, itemID string, data []byte, contentType string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO cover_thumbnails (source_key, item_id, content_type, data)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(source_key, item_id)
+		DO UPDATE SET
+			content_type = excluded.content_type,
+			data = excluded.data,
+			updated_at = CURRENT_TIMESTAMP
+	`, sourceKey, itemID, contentType, data)
+	if err != nil {
+		return fmt.Errorf("put cover thumbnail: %w", err)
+	}
+	return nil
+}
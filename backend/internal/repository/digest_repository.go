@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+type DigestRepository struct {
+	db *sql.DB
+}
+
+// DigestEntry is one tracker that received a new chapter inside a digest
+// window.
+type DigestEntry struct {
+	TrackerID          int64
+	Title              string
+	SourceName         string
+	SourceURL          string
+	LastReadChapter    *float64
+	LatestKnownChapter *float64
+	LatestReleaseAt    time.Time
+	// TagIDs are the tracker's tags, for the digest's tag filters.
+	TagIDs []int64
+}
+
+// DigestTagFilter is the tag filter of a profile's digest; a nil id filters
+// nothing.
+type DigestTagFilter struct {
+	IncludeTagID *int64
+	ExcludeTagID *int64
+}
+
+func NewDigestRepository(db *sql.DB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+func (r *DigestRepository) GetByProfileID(profileID int64) (*models.ProfileEmailDigest, error) {
+	row := r.db.QueryRow(`
+		SELECT profile_id, email, hour_utc, enabled, last_sent_at, include_tag_id, exclude_tag_id, created_at, updated_at
+		FROM profile_email_digests
+		WHERE profile_id = ?
+	`, profileID)
+
+	item, err := scanProfileEmailDigest(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get profile email digest: %w", err)
+	}
+
+	return item, nil
+}
+
+func (r *DigestRepository) ListEnabled() ([]models.ProfileEmailDigest, error) {
+	rows, err := r.db.Query(`
+		SELECT profile_id, email, hour_utc, enabled, last_sent_at, include_tag_id, exclude_tag_id, created_at, updated_at
+		FROM profile_email_digests
+		WHERE enabled = 1
+		ORDER BY profile_id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list enabled email digests: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.ProfileEmailDigest, 0)
+	for rows.Next() {
+		item, err := scanProfileEmailDigest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan email digest: %w", err)
+		}
+		items = append(items, *item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate email digests: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *DigestRepository) Upsert(profileID int64, email string, hourUTC int, enabled bool, tags DigestTagFilter) error {
+	if _, err := r.db.Exec(`
+		INSERT INTO profile_email_digests (profile_id, email, hour_utc, enabled, include_tag_id, exclude_tag_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(profile_id)
+		DO UPDATE SET
+			email = excluded.email,
+			hour_utc = excluded.hour_utc,
+			enabled = excluded.enabled,
+			include_tag_id = excluded.include_tag_id,
+			exclude_tag_id = excluded.exclude_tag_id,
+			updated_at = CURRENT_TIMESTAMP
+	`, profileID, email, hourUTC, enabled, tags.IncludeTagID, tags.ExcludeTagID); err != nil {
+		return fmt.Errorf("upsert profile email digest: %w", err)
+	}
+	return nil
+}
+
+// ListProfileTagIDs returns the ids of the profile's tags, for telling
+// whether a digest's filter tag still exists.
+func (r *DigestRepository) ListProfileTagIDs(profileID int64) (map[int64]bool, error) {
+	rows, err := r.db.Query(`SELECT id FROM custom_tags WHERE profile_id = ?`, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("list digest profile tags: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan digest profile tag: %w", err)
+		}
+		ids[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate digest profile tags: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *DigestRepository) MarkSent(profileID int64, sentAt time.Time) error {
+	if _, err := r.db.Exec(`
+		UPDATE profile_email_digests
+		SET last_sent_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE profile_id = ?
+	`, sentAt.UTC(), profileID); err != nil {
+		return fmt.Errorf("mark email digest sent: %w", err)
+	}
+	return nil
+}
+
+// ListEntries returns the profile's trackers whose latest release falls in
+// (since, until], newest first. Release times are compared to the second:
+// julianday does not read the nanoseconds the driver writes.
+func (r *DigestRepository) ListEntries(profileID int64, since time.Time, until time.Time) ([]DigestEntry, error) {
+	const layout = "2006-01-02 15:04:05"
+	rows, err := r.db.Query(`
+		SELECT t.id, t.title, s.name, t.source_url, t.last_read_chapter, t.latest_known_chapter, t.latest_release_at
+		FROM trackers t
+		INNER JOIN sources s ON s.id = t.source_id
+		WHERE t.profile_id = ?
+		  AND julianday(substr(t.latest_release_at, 1, 19)) > julianday(?)
+		  AND julianday(substr(t.latest_release_at, 1, 19)) <= julianday(?)
+		ORDER BY julianday(substr(t.latest_release_at, 1, 19)) DESC, t.id ASC
+	`, profileID, since.UTC().Format(layout), until.UTC().Format(layout))
+	if err != nil {
+		return nil, fmt.Errorf("list digest entries: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]DigestEntry, 0)
+	for rows.Next() {
+		var item DigestEntry
+		var lastReadChapter sql.NullFloat64
+		var latestKnownChapter sql.NullFloat64
+		if err := rows.Scan(&item.TrackerID, &item.Title, &item.SourceName, &item.SourceURL, &lastReadChapter, &latestKnownChapter, &item.LatestReleaseAt); err != nil {
+			return nil, fmt.Errorf("scan digest entry: %w", err)
+		}
+		if lastReadChapter.Valid {
+			item.LastReadChapter = &lastReadChapter.Float64
+		}
+		if latestKnownChapter.Valid {
+			item.LatestKnownChapter = &latestKnownChapter.Float64
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate digest entries: %w", err)
+	}
+
+	if err := r.attachEntryTagIDs(profileID, items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *DigestRepository) attachEntryTagIDs(profileID int64, items []DigestEntry) error {
+	if len(items) == 0 {
+		return nil
+	}
+	rows, err := r.db.Query(`
+		SELECT tt.tracker_id, tt.tag_id
+		FROM tracker_tags tt
+		INNER JOIN trackers t ON t.id = tt.tracker_id
+		WHERE t.profile_id = ?
+	`, profileID)
+	if err != nil {
+		return fmt.Errorf("list digest entry tags: %w", err)
+	}
+	defer rows.Close()
+
+	tagIDsByTracker := make(map[int64][]int64)
+	for rows.Next() {
+		var trackerID, tagID int64
+		if err := rows.Scan(&trackerID, &tagID); err != nil {
+			return fmt.Errorf("scan digest entry tag: %w", err)
+		}
+		tagIDsByTracker[trackerID] = append(tagIDsByTracker[trackerID], tagID)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate digest entry tags: %w", err)
+	}
+
+	for index := range items {
+		items[index].TagIDs = tagIDsByTracker[items[index].TrackerID]
+	}
+	return nil
+}
+
+func scanProfileEmailDigest(scanner rowScanner) (*models.ProfileEmailDigest, error) {
+	var item models.ProfileEmailDigest
+	var lastSentAt sql.NullTime
+	var includeTagID, excludeTagID sql.NullInt64
+	if err := scanner.Scan(&item.ProfileID, &item.Email, &item.HourUTC, &item.Enabled, &lastSentAt, &includeTagID, &excludeTagID, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if lastSentAt.Valid {
+		item.LastSentAt = &lastSentAt.Time
+	}
+	if includeTagID.Valid {
+		item.IncludeTagID = &includeTagID.Int64
+	}
+	if excludeTagID.Valid {
+		item.ExcludeTagID = &excludeTagID.Int64
+	}
+	return &item, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+// FilterPresetRepository stores the named dashboard filter sets of each
+// profile.
+type FilterPresetRepository struct {
+	db *sql.DB
+}
+
+func NewFilterPresetRepository(db *sql.DB) *FilterPresetRepository {
+	return &FilterPresetRepository{db: db}
+}
+
+const filterPresetColumns = `id, profile_id, name, params, created_at, updated_at`
+
+// List returns the profile's presets by name.
+func (r *FilterPresetRepository) List(ctx context.Context, profileID int64) ([]models.FilterPreset, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+filterPresetColumns+`
+		FROM filter_presets
+		WHERE profile_id = ?
+		ORDER BY name ASC, id ASC
+	`, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("list filter presets: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.FilterPreset, 0)
+	for rows.Next() {
+		item, err := scanFilterPreset(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan filter preset: %w", err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate filter presets: %w", err)
+	}
+	return items, nil
+}
+
+// Get returns the preset, or nil when the profile has no preset by that id.
+func (r *FilterPresetRepository) Get(ctx context.Context, profileID int64, id int64) (*models.FilterPreset, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+filterPresetColumns+`
+		FROM filter_presets
+		WHERE id = ? AND profile_id = ?
+	`, id, profileID)
+
+	item, err := scanFilterPreset(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get filter preset: %w", err)
+	}
+	return item, nil
+}
+
+// Create saves params under name. Names are unique per profile regardless
+// of case; a taken name fails with the database's unique constraint error.
+func (r *FilterPresetRepository) Create(ctx context.Context, profileID int64, name string, params models.FilterPresetParams) (*models.FilterPreset, error) {
+	trimmedName := strings.TrimSpace(name)
+	if trimmedName == "" {
+		return nil, fmt.Errorf("filter preset name is required")
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("encode filter preset params: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO filter_presets (profile_id, name, params)
+		VALUES (?, ?, ?)
+	`, profileID, trimmedName, string(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("create filter preset: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("get created filter preset id: %w", err)
+	}
+
+	created, err := r.Get(ctx, profileID, id)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, fmt.Errorf("get created filter preset: %w", sql.ErrNoRows)
+	}
+	return created, nil
+}
+
+// Rename reports false when the profile has no preset by that id.
+func (r *FilterPresetRepository) Rename(ctx context.Context, profileID int64, id int64, name string) (bool, error) {
+	if id <= 0 {
+		return false, nil
+	}
+	trimmedName := strings.TrimSpace(name)
+	if trimmedName == "" {
+		return false, fmt.Errorf("filter preset name is required")
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE filter_presets
+		SET name = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND profile_id = ?
+	`, trimmedName, id, profileID)
+	if err != nil {
+		return false, fmt.Errorf("rename filter preset: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("filter preset rename rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Delete reports false when the profile has no preset by that id.
+func (r *FilterPresetRepository) Delete(ctx context.Context, profileID int64, id int64) (bool, error) {
+	if id <= 0 {
+		return false, nil
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM filter_presets WHERE id = ? AND profile_id = ?`, id, profileID)
+	if err != nil {
+		return false, fmt.Errorf("delete filter preset: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("filter preset delete rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+func scanFilterPreset(scanner rowScanner) (*models.FilterPreset, error) {
+	var item models.FilterPreset
+	var params string
+	if err := scanner.Scan(&item.ID, &item.ProfileID, &item.Name, &params, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(params), &item.Params); err != nil {
+		return nil, fmt.Errorf("decode filter preset params: %w", err)
+	}
+	return &item, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+func TestFilterPresetRepositoryCRUD(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewFilterPresetRepository(db)
+	ctx := context.Background()
+
+	params := models.FilterPresetParams{
+		Query:  "tower",
+		Status: "all",
+		Sort:   "rating",
+		Tags:   []string{"favorite|priority", "-stale"},
+		Sites:  []int64{2, 1},
+	}
+	created, err := repo.Create(ctx, 1, "  Weekend queue ", params)
+	if err != nil {
+		t.Fatalf("create preset: %v", err)
+	}
+	if created.Name != "Weekend queue" || !reflect.DeepEqual(created.Params, params) {
+		t.Fatalf("expected the trimmed name and the params back, got %+v", created)
+	}
+	if _, err := repo.Create(ctx, 1, "weekend QUEUE", models.FilterPresetParams{}); err == nil {
+		t.Fatalf("expected a name taken in another case to be rejected")
+	}
+	if _, err := repo.Create(ctx, 2, "Weekend queue", models.FilterPresetParams{}); err != nil {
+		t.Fatalf("expected another profile to reuse the name: %v", err)
+	}
+	if _, err := repo.Create(ctx, 1, "All unread", models.FilterPresetParams{Status: "reading"}); err != nil {
+		t.Fatalf("create second preset: %v", err)
+	}
+
+	items, err := repo.List(ctx, 1)
+	if err != nil {
+		t.Fatalf("list presets: %v", err)
+	}
+	if len(items) != 2 || items[0].Name != "All unread" || items[1].Name != "Weekend queue" {
+		t.Fatalf("expected profile 1's presets by name, got %+v", items)
+	}
+
+	if renamed, err := repo.Rename(ctx, 2, created.ID, "Stolen"); err != nil || renamed {
+		t.Fatalf("expected another profile's rename to miss, got %v %v", renamed, err)
+	}
+	if renamed, err := repo.Rename(ctx, 1, created.ID, "Catch up"); err != nil || !renamed {
+		t.Fatalf("rename preset: %v %v", renamed, err)
+	}
+	got, err := repo.Get(ctx, 1, created.ID)
+	if err != nil || got == nil || got.Name != "Catch up" || !reflect.DeepEqual(got.Params, params) {
+		t.Fatalf("expected the renamed preset with its params, got %+v %v", got, err)
+	}
+
+	if deleted, err := repo.Delete(ctx, 1, created.ID); err != nil || !deleted {
+		t.Fatalf("delete preset: %v %v", deleted, err)
+	}
+	if got, err := repo.Get(ctx, 1, created.ID); err != nil || got != nil {
+		t.Fatalf("expected the deleted preset gone, got %+v %v", got, err)
+	}
+	if deleted, err := repo.Delete(ctx, 1, created.ID); err != nil || deleted {
+		t.Fatalf("expected a second delete to miss, got %v %v", deleted, err)
+	}
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LinkCacheRepository persists resolved cover and chapter URLs in the
+// link_cache table so they survive restarts. Only found links are stored;
+// misses stay in memory.
+type LinkCacheRepository struct {
+	db *sql.DB
+}
+
+func NewLinkCacheRepository(db *sql.DB) *LinkCacheRepository {
+	return &LinkCacheRepository{db: db}
+}
+
+// LinkCacheEntry is one stored link. LastVerifiedAt is when the URL was last
+// found to still serve, or nil when it has not been checked since it was
+// stored.
+type LinkCacheEntry struct {
+	URL            string
+	ExpiresAt      time.Time
+	LastVerifiedAt *time.Time
+}
+
+// Get returns the stored URL for key and when it expires. ok is false when
+// nothing is stored; expired rows are returned as-is for the caller to skip.
+func (r *LinkCacheRepository) Get(kind string, key string) (url string, expiresAt time.Time, ok bool, err error) {
+	err = r.db.QueryRow(`
+		SELECT url, expires_at FROM link_cache WHERE kind = ? AND cache_key = ?
+	`, kind, key).Scan(&url, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", time.Time{}, false, nil
+		}
+		return "", time.Time{}, false, fmt.Errorf("get link cache entry: %w", err)
+	}
+	return url, expiresAt.UTC(), true, nil
+}
+
+// GetEntry is Get with the entry's verification time.
+func (r *LinkCacheRepository) GetEntry(kind string, key string) (LinkCacheEntry, bool, error) {
+	var (
+		entry      LinkCacheEntry
+		verifiedAt sql.NullTime
+	)
+	err := r.db.QueryRow(`
+		SELECT url, expires_at, last_verified_at FROM link_cache WHERE kind = ? AND cache_key = ?
+	`, kind, key).Scan(&entry.URL, &entry.ExpiresAt, &verifiedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return LinkCacheEntry{}, false, nil
+		}
+		return LinkCacheEntry{}, false, fmt.Errorf("get link cache entry: %w", err)
+	}
+	entry.ExpiresAt = entry.ExpiresAt.UTC()
+	if verifiedAt.Valid {
+		at := verifiedAt.Time.UTC()
+		entry.LastVerifiedAt = &at
+	}
+	return entry, true, nil
+}
+
+// Put stores url for key. Replacing the URL with a different one clears
+// its verification time.
+func (r *LinkCacheRepository) Put(kind string, key string, url string, expiresAt time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO link_cache (kind, cache_key, url, expires_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(kind, cache_key)
+		DO UPDATE SET
+			last_verified_at = CASE WHEN link_cache.url = excluded.url THEN link_cache.last_verified_at ELSE NULL END,
+			url = excluded.url,
+			expires_at = excluded.expires_at,
+			updated_at = CURRENT_TIMESTAMP
+	`, kind, key, url, expiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("put link cache entry: %w", err)
+	}
+	return nil
+}
+
+// MarkVerified records that the stored URL for key was found to still serve
+// at at.
+func (r *LinkCacheRepository) MarkVerified(kind string, key string, at time.Time) error {
+	if _, err := r.db.Exec(`
+		UPDATE link_cache SET last_verified_at = ? WHERE kind = ? AND cache_key = ?
+	`, at.UTC(), kind, key); err != nil {
+		return fmt.Errorf("mark link cache entry verified: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the stored URL for key, if any.
+func (r *LinkCacheRepository) Delete(kind string, key string) error {
+	if _, err := r.db.Exec(`DELETE FROM link_cache WHERE kind = ? AND cache_key = ?`, kind, key); err != nil {
+		return fmt.Errorf("delete link cache entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes entries that expired before now and returns how many
+// were removed.
+func (r *LinkCacheRepository) DeleteExpired(now time.Time) (int, error) {
+	rows, err := r.db.Query(`SELECT kind, cache_key, expires_at FROM link_cache`)
+	if err != nil {
+		return 0, fmt.Errorf("list link cache entries: %w", err)
+	}
+	type entryKey struct{ kind, key string }
+	expired := make([]entryKey, 0)
+	for rows.Next() {
+		var item entryKey
+		var expiresAt time.Time
+		if err := rows.Scan(&item.kind, &item.key, &expiresAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan link cache entry: %w", err)
+		}
+		if expiresAt.Before(now) {
+			expired = append(expired, item)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("iterate link cache entries: %w", err)
+	}
+	rows.Close()
+
+	for _, item := range expired {
+		if _, err := r.db.Exec(`DELETE FROM link_cache WHERE kind = ? AND cache_key = ?`, item.kind, item.key); err != nil {
+			return 0, fmt.Errorf("delete link cache entry: %w", err)
+		}
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+// mangaDexSyncRunsKept is how many sync log entries each profile keeps.
+const mangaDexSyncRunsKept = 50
+
+type MangaDexSyncRepository struct {
+	db *sql.DB
+}
+
+// MangaDexTracker is a tracker whose primary source is MangaDex, keyed by
+// the MangaDex title UUID stored in its source_item_id.
+type MangaDexTracker struct {
+	ID              int64
+	TitleID         string
+	LastReadChapter *float64
+}
+
+func NewMangaDexSyncRepository(db *sql.DB) *MangaDexSyncRepository {
+	return &MangaDexSyncRepository{db: db}
+}
+
+func (r *MangaDexSyncRepository) GetLink(ctx context.Context, profileID int64) (*models.MangaDexLink, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT profile_id, username, sealed_refresh_token, sync_enabled, last_synced_at, created_at, updated_at
+		FROM profile_mangadex_links
+		WHERE profile_id = ?
+	`, profileID)
+
+	link, err := scanMangaDexLink(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get mangadex link: %w", err)
+	}
+	return link, nil
+}
+
+func (r *MangaDexSyncRepository) ListEnabledLinks(ctx context.Context) ([]models.MangaDexLink, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT profile_id, username, sealed_refresh_token, sync_enabled, last_synced_at, created_at, updated_at
+		FROM profile_mangadex_links
+		WHERE sync_enabled = 1
+		ORDER BY profile_id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list enabled mangadex links: %w", err)
+	}
+	defer rows.Close()
+
+	links := make([]models.MangaDexLink, 0)
+	for rows.Next() {
+		link, err := scanMangaDexLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan mangadex link: %w", err)
+		}
+		links = append(links, *link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate mangadex links: %w", err)
+	}
+	return links, nil
+}
+
+// UpsertLink links the profile to a MangaDex account, replacing any earlier
+// link.
+func (r *MangaDexSyncRepository) UpsertLink(ctx context.Context, profileID int64, username string, sealedToken string, syncEnabled bool) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO profile_mangadex_links (profile_id, username, sealed_refresh_token, sync_enabled)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(profile_id)
+		DO UPDATE SET
+			username = excluded.username,
+			sealed_refresh_token = excluded.sealed_refresh_token,
+			sync_enabled = excluded.sync_enabled,
+			updated_at = CURRENT_TIMESTAMP
+	`, profileID, username, sealedToken, syncEnabled)
+	if err != nil {
+		return fmt.Errorf("upsert mangadex link: %w", err)
+	}
+	return nil
+}
+
+// UpdateSealedToken stores the refresh token MangaDex rotated in on the
+// last token refresh.
+func (r *MangaDexSyncRepository) UpdateSealedToken(ctx context.Context, profileID int64, sealedToken string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE profile_mangadex_links
+		SET sealed_refresh_token = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE profile_id = ?
+	`, sealedToken, profileID)
+	if err != nil {
+		return fmt.Errorf("update mangadex token: %w", err)
+	}
+	return nil
+}
+
+func (r *MangaDexSyncRepository) SetSyncEnabled(ctx context.Context, profileID int64, enabled bool) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE profile_mangadex_links
+		SET sync_enabled = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE profile_id = ?
+	`, enabled, profileID)
+	if err != nil {
+		return false, fmt.Errorf("set mangadex sync enabled: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("mangadex sync enabled rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// DeleteLink unlinks the profile's MangaDex account. Its sync log goes with
+// it.
+func (r *MangaDexSyncRepository) DeleteLink(ctx context.Context, profileID int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM profile_mangadex_links WHERE profile_id = ?`, profileID)
+	if err != nil {
+		return false, fmt.Errorf("delete mangadex link: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM mangadex_sync_runs WHERE profile_id = ?`, profileID); err != nil {
+		return false, fmt.Errorf("delete mangadex sync log: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("mangadex unlink rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ListMangaDexTrackers returns the profile's trackers whose primary source
+// is MangaDex and that have a resolved title id.
+func (r *MangaDexSyncRepository) ListMangaDexTrackers(ctx context.Context, profileID int64) ([]MangaDexTracker, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT t.id, t.source_item_id, t.last_read_chapter
+		FROM trackers t
+		JOIN sources s ON s.id = t.source_id
+		WHERE t.profile_id = ?
+		  AND s.key = 'mangadex'
+		  AND COALESCE(TRIM(t.source_item_id), '') <> ''
+		ORDER BY t.id ASC
+	`, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("list mangadex trackers: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]MangaDexTracker, 0)
+	for rows.Next() {
+		var item MangaDexTracker
+		var lastRead sql.NullFloat64
+		if err := rows.Scan(&item.ID, &item.TitleID, &lastRead); err != nil {
+			return nil, fmt.Errorf("scan mangadex tracker: %w", err)
+		}
+		if lastRead.Valid {
+			item.LastReadChapter = &lastRead.Float64
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate mangadex trackers: %w", err)
+	}
+	return items, nil
+}
+
+// RecordRun appends a run to the profile's sync log, trims the log to the
+// newest entries, and stamps the link's last sync time when the run
+// succeeded.
+func (r *MangaDexSyncRepository) RecordRun(ctx context.Context, run models.MangaDexSyncRun) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin mangadex sync log tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO mangadex_sync_runs (profile_id, started_at, finished_at, trackers_checked, trackers_updated, error)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, run.ProfileID, run.StartedAt.UTC(), run.FinishedAt.UTC(), run.TrackersChecked, run.TrackersUpdated, run.Error); err != nil {
+		return fmt.Errorf("insert mangadex sync run: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM mangadex_sync_runs
+		WHERE profile_id = ?
+		  AND id NOT IN (
+			SELECT id FROM mangadex_sync_runs WHERE profile_id = ? ORDER BY id DESC LIMIT ?
+		  )
+	`, run.ProfileID, run.ProfileID, mangaDexSyncRunsKept); err != nil {
+		return fmt.Errorf("trim mangadex sync log: %w", err)
+	}
+	if run.Error == "" {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE profile_mangadex_links SET last_synced_at = ? WHERE profile_id = ?
+		`, run.FinishedAt.UTC(), run.ProfileID); err != nil {
+			return fmt.Errorf("stamp mangadex last sync: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit mangadex sync log tx: %w", err)
+	}
+	return nil
+}
+
+// ListRuns returns the profile's newest sync log entries first.
+func (r *MangaDexSyncRepository) ListRuns(ctx context.Context, profileID int64, limit int) ([]models.MangaDexSyncRun, error) {
+	if limit <= 0 || limit > mangaDexSyncRunsKept {
+		limit = mangaDexSyncRunsKept
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, profile_id, started_at, finished_at, trackers_checked, trackers_updated, error
+		FROM mangadex_sync_runs
+		WHERE profile_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, profileID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list mangadex sync runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]models.MangaDexSyncRun, 0)
+	for rows.Next() {
+		var run models.MangaDexSyncRun
+		if err := rows.Scan(&run.ID, &run.ProfileID, &run.StartedAt, &run.FinishedAt, &run.TrackersChecked, &run.TrackersUpdated, &run.Error); err != nil {
+			return nil, fmt.Errorf("scan mangadex sync run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate mangadex sync runs: %w", err)
+	}
+	return runs, nil
+}
+
+func scanMangaDexLink(scanner rowScanner) (*models.MangaDexLink, error) {
+	var link models.MangaDexLink
+	var lastSyncedAt sql.NullTime
+	if err := scanner.Scan(&link.ProfileID, &link.Username, &link.SealedToken, &link.SyncEnabled, &lastSyncedAt, &link.CreatedAt, &link.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if lastSyncedAt.Valid {
+		at := lastSyncedAt.Time
+		link.LastSyncedAt = &at
+	}
+	return &link, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+func TestMangaDexSyncRepository_ListsOnlyResolvedMangaDexTrackers(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewMangaDexSyncRepository(db)
+	ctx := context.Background()
+
+	// Alpha Blade and Gamma Tower move to MangaDex; only Alpha Blade has a
+	// title id, and the other profile's tracker must stay out.
+	if _, err := db.Exec(`
+		UPDATE trackers
+		SET source_id = (SELECT id FROM sources WHERE key = 'mangadex'),
+			source_item_id = CASE title WHEN 'Alpha Blade' THEN 'alpha-uuid' WHEN 'Other Profile Blade' THEN 'other-uuid' ELSE NULL END
+		WHERE title IN ('Alpha Blade', 'Gamma Tower', 'Other Profile Blade')
+	`); err != nil {
+		t.Fatalf("move trackers to mangadex: %v", err)
+	}
+
+	items, err := repo.ListMangaDexTrackers(ctx, 1)
+	if err != nil {
+		t.Fatalf("list mangadex trackers: %v", err)
+	}
+	if len(items) != 1 || items[0].TitleID != "alpha-uuid" {
+		t.Fatalf("expected only Alpha Blade, got %+v", items)
+	}
+	if items[0].LastReadChapter == nil || *items[0].LastReadChapter != 10 {
+		t.Fatalf("expected last read chapter 10, got %v", items[0].LastReadChapter)
+	}
+}
+
+func TestMangaDexSyncRepository_RecordRunTrimsLogAndStampsSuccess(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewMangaDexSyncRepository(db)
+	ctx := context.Background()
+
+	if err := repo.UpsertLink(ctx, 1, "reader", "sealed", true); err != nil {
+		t.Fatalf("upsert link: %v", err)
+	}
+
+	started := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < mangaDexSyncRunsKept+3; i++ {
+		run := models.MangaDexSyncRun{
+			ProfileID:       1,
+			StartedAt:       started.Add(time.Duration(i) * time.Hour),
+			FinishedAt:      started.Add(time.Duration(i)*time.Hour + time.Minute),
+			TrackersChecked: i,
+		}
+		if err := repo.RecordRun(ctx, run); err != nil {
+			t.Fatalf("record run %d: %v", i, err)
+		}
+	}
+	if err := repo.RecordRun(ctx, models.MangaDexSyncRun{ProfileID: 1, StartedAt: started.Add(100 * time.Hour), FinishedAt: started.Add(100 * time.Hour), Error: "boom"}); err != nil {
+		t.Fatalf("record failed run: %v", err)
+	}
+
+	runs, err := repo.ListRuns(ctx, 1, 0)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != mangaDexSyncRunsKept {
+		t.Fatalf("expected the log to be trimmed to %d runs, got %d", mangaDexSyncRunsKept, len(runs))
+	}
+	if runs[0].Error != "boom" {
+		t.Fatalf("expected the newest run first, got %+v", runs[0])
+	}
+
+	link, err := repo.GetLink(ctx, 1)
+	if err != nil {
+		t.Fatalf("get link: %v", err)
+	}
+	lastOK := started.Add(time.Duration(mangaDexSyncRunsKept+2)*time.Hour + time.Minute)
+	if link.LastSyncedAt == nil || !link.LastSyncedAt.Equal(lastOK) {
+		t.Fatalf("expected last sync to be the last successful run %s, got %v", lastOK, link.LastSyncedAt)
+	}
+
+	if deleted, err := repo.DeleteLink(ctx, 1); err != nil || !deleted {
+		t.Fatalf("delete link: %v %v", deleted, err)
+	}
+	if runs, _ := repo.ListRuns(ctx, 1, 0); len(runs) != 0 {
+		t.Fatalf("expected unlinking to clear the sync log, got %d runs", len(runs))
+	}
+}
@@ -1,10 +1,14 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/profilekey"
 )
 
 type ProfileRepository struct {
@@ -15,9 +19,9 @@ func NewProfileRepository(db *sql.DB) *ProfileRepository {
 	return &ProfileRepository{db: db}
 }
 
-func (r *ProfileRepository) List() ([]models.Profile, error) {
-	rows, err := r.db.Query(`
-		SELECT id, key, name, created_at, updated_at
+func (r *ProfileRepository) List(ctx context.Context) ([]models.Profile, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, key, name, polling_enabled, created_at, updated_at
 		FROM profiles
 		ORDER BY id ASC
 	`)
@@ -29,7 +33,7 @@ func (r *ProfileRepository) List() ([]models.Profile, error) {
 	items := make([]models.Profile, 0)
 	for rows.Next() {
 		var item models.Profile
-		if err := rows.Scan(&item.ID, &item.Key, &item.Name, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		if err := rows.Scan(&item.ID, &item.Key, &item.Name, &item.PollingEnabled, &item.CreatedAt, &item.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan profile: %w", err)
 		}
 		items = append(items, item)
@@ -42,15 +46,15 @@ func (r *ProfileRepository) List() ([]models.Profile, error) {
 	return items, nil
 }
 
-func (r *ProfileRepository) GetByID(id int64) (*models.Profile, error) {
-	row := r.db.QueryRow(`
-		SELECT id, key, name, created_at, updated_at
+func (r *ProfileRepository) GetByID(ctx context.Context, id int64) (*models.Profile, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, key, name, polling_enabled, created_at, updated_at
 		FROM profiles
 		WHERE id = ?
 	`, id)
 
 	var item models.Profile
-	if err := row.Scan(&item.ID, &item.Key, &item.Name, &item.CreatedAt, &item.UpdatedAt); err != nil {
+	if err := row.Scan(&item.ID, &item.Key, &item.Name, &item.PollingEnabled, &item.CreatedAt, &item.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -60,15 +64,15 @@ func (r *ProfileRepository) GetByID(id int64) (*models.Profile, error) {
 	return &item, nil
 }
 
-func (r *ProfileRepository) GetByKey(key string) (*models.Profile, error) {
-	row := r.db.QueryRow(`
-		SELECT id, key, name, created_at, updated_at
+func (r *ProfileRepository) GetByKey(ctx context.Context, key string) (*models.Profile, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, key, name, polling_enabled, created_at, updated_at
 		FROM profiles
 		WHERE key = ?
 	`, key)
 
 	var item models.Profile
-	if err := row.Scan(&item.ID, &item.Key, &item.Name, &item.CreatedAt, &item.UpdatedAt); err != nil {
+	if err := row.Scan(&item.ID, &item.Key, &item.Name, &item.PollingEnabled, &item.CreatedAt, &item.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -78,16 +82,16 @@ func (r *ProfileRepository) GetByKey(key string) (*models.Profile, error) {
 	return &item, nil
 }
 
-func (r *ProfileRepository) GetDefault() (*models.Profile, error) {
-	row := r.db.QueryRow(`
-		SELECT id, key, name, created_at, updated_at
+func (r *ProfileRepository) GetDefault(ctx context.Context) (*models.Profile, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, key, name, polling_enabled, created_at, updated_at
 		FROM profiles
 		ORDER BY id ASC
 		LIMIT 1
 	`)
 
 	var item models.Profile
-	if err := row.Scan(&item.ID, &item.Key, &item.Name, &item.CreatedAt, &item.UpdatedAt); err != nil {
+	if err := row.Scan(&item.ID, &item.Key, &item.Name, &item.PollingEnabled, &item.CreatedAt, &item.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -97,8 +101,8 @@ func (r *ProfileRepository) GetDefault() (*models.Profile, error) {
 	return &item, nil
 }
 
-func (r *ProfileRepository) Rename(id int64, name string) (bool, error) {
-	result, err := r.db.Exec(`
+func (r *ProfileRepository) Rename(ctx context.Context, id int64, name string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
 		UPDATE profiles
 		SET name = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ? AND name IS NOT ?
@@ -114,3 +118,168 @@ func (r *ProfileRepository) Rename(id int64, name string) (bool, error) {
 
 	return rowsAffected > 0, nil
 }
+
+// ListNonConformingKeys returns the profiles whose key is not canonical,
+// such as keys stored before profilekey existed. The dashboard and the API
+// refuse those keys, so the profiles are only reachable by ID until renamed.
+func (r *ProfileRepository) ListNonConformingKeys(ctx context.Context) ([]models.Profile, error) {
+	profiles, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]models.Profile, 0)
+	for _, profile := range profiles {
+		if profilekey.Validate(profile.Key) != nil {
+			items = append(items, profile)
+		}
+	}
+	return items, nil
+}
+
+// RenameKey changes a profile's key. The key must be canonical; see
+// profilekey.Validate.
+func (r *ProfileRepository) RenameKey(ctx context.Context, id int64, key string) (bool, error) {
+	if err := profilekey.Validate(key); err != nil {
+		return false, err
+	}
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE profiles
+		SET key = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND key IS NOT ?
+	`, key, id, key)
+	if err != nil {
+		return false, fmt.Errorf("rename profile key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("profile key rename rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// MarkDashboardSeen records a full dashboard load at at. The load it
+// replaces becomes the one DashboardSeenBefore returns.
+func (r *ProfileRepository) MarkDashboardSeen(ctx context.Context, id int64, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE profiles
+		SET previous_dashboard_seen_at = last_dashboard_seen_at, last_dashboard_seen_at = ?
+		WHERE id = ?
+	`, at.UTC(), id); err != nil {
+		return fmt.Errorf("mark dashboard seen: %w", err)
+	}
+	return nil
+}
+
+// DashboardSeenBefore returns when the profile loaded the dashboard before
+// its current visit, or nil when the current visit is its first.
+func (r *ProfileRepository) DashboardSeenBefore(ctx context.Context, id int64) (*time.Time, error) {
+	var seenAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT previous_dashboard_seen_at
+		FROM profiles
+		WHERE id = ?
+	`, id).Scan(&seenAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get dashboard seen before: %w", err)
+	}
+	if !seenAt.Valid {
+		return nil, nil
+	}
+	return &seenAt.Time, nil
+}
+
+// SetPollingEnabled turns polling of the profile's trackers on or off. It
+// reports whether the profile exists.
+func (r *ProfileRepository) SetPollingEnabled(ctx context.Context, id int64, enabled bool) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE profiles
+		SET polling_enabled = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, enabled, id)
+	if err != nil {
+		return false, fmt.Errorf("set profile polling enabled: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("profile polling rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// StatusSortDefaults returns the profile's dashboard sort default per
+// status, empty when none is set or the profile does not exist.
+func (r *ProfileRepository) StatusSortDefaults(ctx context.Context, id int64) (models.StatusSortDefaults, error) {
+	var raw string
+	err := r.db.QueryRowContext(ctx, `SELECT status_sort_defaults FROM profiles WHERE id = ?`, id).Scan(&raw)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.StatusSortDefaults{}, nil
+		}
+		return nil, fmt.Errorf("get profile status sort defaults: %w", err)
+	}
+
+	defaults := models.StatusSortDefaults{}
+	if err := json.Unmarshal([]byte(raw), &defaults); err != nil {
+		return nil, fmt.Errorf("decode profile status sort defaults: %w", err)
+	}
+	return defaults, nil
+}
+
+// SetStatusSortDefaults replaces the profile's dashboard sort defaults. It
+// reports whether the profile exists.
+func (r *ProfileRepository) SetStatusSortDefaults(ctx context.Context, id int64, defaults models.StatusSortDefaults) (bool, error) {
+	if defaults == nil {
+		defaults = models.StatusSortDefaults{}
+	}
+	encoded, err := json.Marshal(defaults)
+	if err != nil {
+		return false, fmt.Errorf("encode profile status sort defaults: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE profiles
+		SET status_sort_defaults = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, string(encoded), id)
+	if err != nil {
+		return false, fmt.Errorf("set profile status sort defaults: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("profile status sort defaults rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// LastPolledAt returns the latest last_checked_at among the profile's
+// trackers, or nil when none has been checked.
+func (r *ProfileRepository) LastPolledAt(ctx context.Context, id int64) (*time.Time, error) {
+	var checkedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT last_checked_at
+		FROM trackers
+		WHERE profile_id = ? AND last_checked_at IS NOT NULL
+		ORDER BY last_checked_at DESC
+		LIMIT 1
+	`, id).Scan(&checkedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get profile last polled at: %w", err)
+	}
+	if !checkedAt.Valid {
+		return nil, nil
+	}
+	polledAt := checkedAt.Time.UTC()
+	return &polledAt, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRepositoryCallsGiveUpWhenTheirContextEnds(t *testing.T) {
+	// setupListingTestDB allows one open connection, so a transaction holding
+	// the write lock leaves every other statement waiting for it.
+	db := setupListingTestDB(t)
+	trackers := NewTrackerRepository(db)
+	sources := NewSourceRepository(db)
+	alpha := trackerIDByTitle(t, trackers, "Alpha Blade")
+
+	holder, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("begin holder transaction: %v", err)
+	}
+	if _, err := holder.Exec(`UPDATE trackers SET title = title WHERE id = ?`, alpha); err != nil {
+		t.Fatalf("take write lock: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err = trackers.UpdateRating(ctx, 1, alpha, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the blocked write to hit its deadline, got %v", err)
+	}
+	if waited := time.Since(started); waited > 2*time.Second {
+		t.Fatalf("expected the blocked write to give up near its deadline, waited %s", waited)
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if _, err := sources.ListEnabled(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancelled read to fail, got %v", err)
+	}
+
+	if err := holder.Rollback(); err != nil {
+		t.Fatalf("release write lock: %v", err)
+	}
+	if _, err := trackers.UpdateRating(context.Background(), 1, alpha, nil); err != nil {
+		t.Fatalf("expected the write to succeed once the lock is released: %v", err)
+	}
+}
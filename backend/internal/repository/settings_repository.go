@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	scrapingPausedSettingKey = "scraping_paused"
+	lastActivitySettingKey   = "last_activity_at"
+)
+
+// SettingsRepository stores app-wide runtime switches in the app_settings
+// key/value table.
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+func NewSettingsRepository(db *sql.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// ScrapingPaused reports whether outbound connector requests are globally
+// paused. A missing row means not paused.
+func (r *SettingsRepository) ScrapingPaused() (bool, error) {
+	var value string
+	err := r.db.QueryRow(`SELECT value FROM app_settings WHERE key = ?`, scrapingPausedSettingKey).Scan(&value)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("get scraping paused setting: %w", err)
+	}
+	return value == "1", nil
+}
+
+func (r *SettingsRepository) SetScrapingPaused(paused bool) error {
+	value := "0"
+	if paused {
+		value = "1"
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO app_settings (key, value)
+		VALUES (?, ?)
+		ON CONFLICT(key)
+		DO UPDATE SET
+			value = excluded.value,
+			updated_at = CURRENT_TIMESTAMP
+	`, scrapingPausedSettingKey, value)
+	if err != nil {
+		return fmt.Errorf("set scraping paused setting: %w", err)
+	}
+	return nil
+}
+
+// LastActivityAt returns when the dashboard or API was last used. ok is
+// false until the first request is recorded.
+func (r *SettingsRepository) LastActivityAt() (time.Time, bool, error) {
+	var value string
+	err := r.db.QueryRow(`SELECT value FROM app_settings WHERE key = ?`, lastActivitySettingKey).Scan(&value)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("get last activity setting: %w", err)
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("parse last activity setting: %w", err)
+	}
+	return at, true, nil
+}
+
+func (r *SettingsRepository) SetLastActivityAt(at time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO app_settings (key, value)
+		VALUES (?, ?)
+		ON CONFLICT(key)
+		DO UPDATE SET
+			value = excluded.value,
+			updated_at = CURRENT_TIMESTAMP
+	`, lastActivitySettingKey, at.UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("set last activity setting: %w", err)
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
 )
@@ -16,9 +18,56 @@ func NewSourceRepository(db *sql.DB) *SourceRepository {
 	return &SourceRepository{db: db}
 }
 
-func (r *SourceRepository) ListEnabled() ([]models.Source, error) {
-	rows, err := r.db.Query(`
-		SELECT id, key, name, connector_kind, base_url, config_path, enabled, created_at, updated_at
+// Note sources: manual notes are written from the dashboard or API, auto
+// notes by the poller when a source keeps failing.
+const (
+	NoteSourceManual = "manual"
+	NoteSourceAuto   = "auto"
+)
+
+const sourceColumns = `id, key, name, connector_kind, base_url, config_path, search_mode, enabled, created_at, updated_at,
+		status_note, status_note_updated_at, note_source`
+
+func scanSource(scanner rowScanner) (models.Source, error) {
+	var source models.Source
+	var baseURL sql.NullString
+	var configPath sql.NullString
+	var enabled bool
+	var noteUpdatedAt sql.NullTime
+	if err := scanner.Scan(
+		&source.ID,
+		&source.Key,
+		&source.Name,
+		&source.ConnectorKind,
+		&baseURL,
+		&configPath,
+		&source.SearchMode,
+		&enabled,
+		&source.CreatedAt,
+		&source.UpdatedAt,
+		&source.StatusNote,
+		&noteUpdatedAt,
+		&source.NoteSource,
+	); err != nil {
+		return source, err
+	}
+	source.Enabled = enabled
+	if baseURL.Valid {
+		source.BaseURL = &baseURL.String
+	}
+	if configPath.Valid {
+		source.ConfigPath = &configPath.String
+	}
+	if noteUpdatedAt.Valid {
+		updatedAt := noteUpdatedAt.Time.UTC()
+		source.StatusNoteUpdatedAt = &updatedAt
+	}
+	return source, nil
+}
+
+func (r *SourceRepository) ListEnabled(ctx context.Context) ([]models.Source, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+sourceColumns+`
 		FROM sources
 		WHERE enabled = 1
 		ORDER BY name ASC
@@ -30,30 +79,10 @@ func (r *SourceRepository) ListEnabled() ([]models.Source, error) {
 
 	items := make([]models.Source, 0)
 	for rows.Next() {
-		var source models.Source
-		var baseURL sql.NullString
-		var configPath sql.NullString
-		var enabled bool
-		if err := rows.Scan(
-			&source.ID,
-			&source.Key,
-			&source.Name,
-			&source.ConnectorKind,
-			&baseURL,
-			&configPath,
-			&enabled,
-			&source.CreatedAt,
-			&source.UpdatedAt,
-		); err != nil {
+		source, err := scanSource(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan source: %w", err)
 		}
-		source.Enabled = enabled
-		if baseURL.Valid {
-			source.BaseURL = &baseURL.String
-		}
-		if configPath.Valid {
-			source.ConfigPath = &configPath.String
-		}
 		items = append(items, source)
 	}
 
@@ -64,47 +93,171 @@ func (r *SourceRepository) ListEnabled() ([]models.Source, error) {
 	return items, nil
 }
 
-func (r *SourceRepository) GetByID(id int64) (*models.Source, error) {
-	row := r.db.QueryRow(`
-		SELECT id, key, name, connector_kind, base_url, config_path, enabled, created_at, updated_at
+func (r *SourceRepository) GetByID(ctx context.Context, id int64) (*models.Source, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+sourceColumns+`
 		FROM sources
 		WHERE id = ?
 	`, id)
 
-	var source models.Source
-	var baseURL sql.NullString
-	var configPath sql.NullString
-	var enabled bool
-	if err := row.Scan(
-		&source.ID,
-		&source.Key,
-		&source.Name,
-		&source.ConnectorKind,
-		&baseURL,
-		&configPath,
-		&enabled,
-		&source.CreatedAt,
-		&source.UpdatedAt,
-	); err != nil {
+	source, err := scanSource(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("get source by id: %w", err)
 	}
 
-	source.Enabled = enabled
-	if baseURL.Valid {
-		source.BaseURL = &baseURL.String
+	return &source, nil
+}
+
+// SetStatusNote writes a manual note, which the poller then leaves alone; an
+// empty note clears whatever note the source has. The row is marked as edited
+// by hand, so database.SeedDefaults no longer renames it.
+func (r *SourceRepository) SetStatusNote(ctx context.Context, id int64, note string) (bool, error) {
+	note = strings.TrimSpace(note)
+	noteSource := NoteSourceManual
+	var updatedAt any = time.Now().UTC()
+	if note == "" {
+		noteSource = ""
+		updatedAt = nil
 	}
-	if configPath.Valid {
-		source.ConfigPath = &configPath.String
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE sources
+		SET status_note = ?,
+			note_source = ?,
+			status_note_updated_at = ?,
+			user_modified = 1
+		WHERE id = ?
+	`, note, noteSource, updatedAt, id)
+	if err != nil {
+		return false, fmt.Errorf("set source status note: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("set source status note rows affected: %w", err)
 	}
+	return affected > 0, nil
+}
 
-	return &source, nil
+// SetAutoStatusNote writes the poller's note for the source with sourceKey
+// unless it has a manual note. It reports whether the note was written.
+func (r *SourceRepository) SetAutoStatusNote(ctx context.Context, sourceKey string, note string, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE sources
+		SET status_note = ?,
+			note_source = ?,
+			status_note_updated_at = ?
+		WHERE key = ?
+		  AND (note_source <> ? OR status_note = '')
+	`, strings.TrimSpace(note), NoteSourceAuto, at.UTC(), sourceKey, NoteSourceManual)
+	if err != nil {
+		return false, fmt.Errorf("set source auto status note: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("set source auto status note rows affected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ClearAutoStatusNote removes the poller's note for the source with
+// sourceKey; manual notes stay, as do auto notes while the source's last
+// deep check failed. It reports whether a note was cleared.
+func (r *SourceRepository) ClearAutoStatusNote(ctx context.Context, sourceKey string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE sources
+		SET status_note = '',
+			note_source = '',
+			status_note_updated_at = NULL
+		WHERE key = ?
+		  AND note_source = ?
+		  AND canary_failure = ''
+	`, sourceKey, NoteSourceAuto)
+	if err != nil {
+		return false, fmt.Errorf("clear source auto status note: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("clear source auto status note rows affected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// SetCanaryFailure records that the deep check of the source with sourceKey
+// failed and writes note as its auto note, unless it has a manual note. It
+// reports whether the source exists.
+func (r *SourceRepository) SetCanaryFailure(ctx context.Context, sourceKey string, failure string, note string, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE sources
+		SET canary_failure = ?,
+			canary_checked_at = ?,
+			status_note = CASE WHEN note_source = ? AND status_note <> '' THEN status_note ELSE ? END,
+			status_note_updated_at = CASE WHEN note_source = ? AND status_note <> '' THEN status_note_updated_at ELSE ? END,
+			note_source = CASE WHEN note_source = ? AND status_note <> '' THEN note_source ELSE ? END
+		WHERE key = ?
+	`, strings.TrimSpace(failure), at.UTC(),
+		NoteSourceManual, strings.TrimSpace(note),
+		NoteSourceManual, at.UTC(),
+		NoteSourceManual, NoteSourceAuto,
+		sourceKey)
+	if err != nil {
+		return false, fmt.Errorf("set source canary failure: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("set source canary failure rows affected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ClearCanaryFailure records a passing deep check for the source with
+// sourceKey. The auto note is cleared too when it was left by a failed deep
+// check. It reports whether the source exists.
+func (r *SourceRepository) ClearCanaryFailure(ctx context.Context, sourceKey string, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE sources
+		SET status_note = CASE WHEN note_source = ? AND canary_failure <> '' THEN '' ELSE status_note END,
+			status_note_updated_at = CASE WHEN note_source = ? AND canary_failure <> '' THEN NULL ELSE status_note_updated_at END,
+			note_source = CASE WHEN note_source = ? AND canary_failure <> '' THEN '' ELSE note_source END,
+			canary_failure = '',
+			canary_checked_at = ?
+		WHERE key = ?
+	`, NoteSourceAuto, NoteSourceAuto, NoteSourceAuto, at.UTC(), sourceKey)
+	if err != nil {
+		return false, fmt.Errorf("clear source canary failure: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("clear source canary failure rows affected: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ListStatusNotes maps source keys to their non-empty status notes.
+func (r *SourceRepository) ListStatusNotes(ctx context.Context) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT key, status_note FROM sources WHERE status_note <> ''`)
+	if err != nil {
+		return nil, fmt.Errorf("list source status notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := make(map[string]string)
+	for rows.Next() {
+		var key, note string
+		if err := rows.Scan(&key, &note); err != nil {
+			return nil, fmt.Errorf("scan source status note: %w", err)
+		}
+		notes[key] = note
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate source status notes: %w", err)
+	}
+	return notes, nil
 }
 
-func (r *SourceRepository) ListProfileSourceLogoURLs(profileID int64) (map[int64]string, error) {
-	rows, err := r.db.Query(`
+func (r *SourceRepository) ListProfileSourceLogoURLs(ctx context.Context, profileID int64) (map[int64]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT source_id, logo_url
 		FROM profile_source_logos
 		WHERE profile_id = ?
@@ -136,8 +289,8 @@ func (r *SourceRepository) ListProfileSourceLogoURLs(profileID int64) (map[int64
 	return logoBySourceID, nil
 }
 
-func (r *SourceRepository) UpsertProfileSourceLogoURLs(profileID int64, logoBySourceID map[int64]string) error {
-	tx, err := r.db.Begin()
+func (r *SourceRepository) UpsertProfileSourceLogoURLs(ctx context.Context, profileID int64, logoBySourceID map[int64]string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin source logo urls tx: %w", err)
 	}
@@ -149,7 +302,7 @@ func (r *SourceRepository) UpsertProfileSourceLogoURLs(profileID int64, logoBySo
 
 		trimmedLogoURL := strings.TrimSpace(logoURL)
 		if trimmedLogoURL == "" {
-			if _, err := tx.Exec(`
+			if _, err := tx.ExecContext(ctx, `
 				DELETE FROM profile_source_logos
 				WHERE profile_id = ? AND source_id = ?
 			`, profileID, sourceID); err != nil {
@@ -159,7 +312,7 @@ func (r *SourceRepository) UpsertProfileSourceLogoURLs(profileID int64, logoBySo
 			continue
 		}
 
-		if _, err := tx.Exec(`
+		if _, err := tx.ExecContext(ctx, `
 			INSERT INTO profile_source_logos (profile_id, source_id, logo_url)
 			VALUES (?, ?, ?)
 			ON CONFLICT(profile_id, source_id)
@@ -178,3 +331,54 @@ func (r *SourceRepository) UpsertProfileSourceLogoURLs(profileID int64, logoBySo
 
 	return nil
 }
+
+// ListProfileBlacklistedSourceIDs returns the sources the profile has
+// blacklisted. Blacklisted sources are left out of the profile's source
+// pickers and searches; trackers already on them keep working.
+func (r *SourceRepository) ListProfileBlacklistedSourceIDs(ctx context.Context, profileID int64) (map[int64]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT source_id
+		FROM profile_source_blacklist
+		WHERE profile_id = ?
+	`, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("list profile source blacklist: %w", err)
+	}
+	defer rows.Close()
+
+	blacklisted := make(map[int64]bool)
+	for rows.Next() {
+		var sourceID int64
+		if err := rows.Scan(&sourceID); err != nil {
+			return nil, fmt.Errorf("scan profile source blacklist: %w", err)
+		}
+		blacklisted[sourceID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate profile source blacklist: %w", err)
+	}
+	return blacklisted, nil
+}
+
+// SetProfileSourceBlacklisted adds the source to the profile's blacklist or
+// removes it. Either is a no-op when the source is already in that state.
+func (r *SourceRepository) SetProfileSourceBlacklisted(ctx context.Context, profileID int64, sourceID int64, blacklisted bool) error {
+	if !blacklisted {
+		if _, err := r.db.ExecContext(ctx, `
+			DELETE FROM profile_source_blacklist
+			WHERE profile_id = ? AND source_id = ?
+		`, profileID, sourceID); err != nil {
+			return fmt.Errorf("remove source from profile blacklist: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO profile_source_blacklist (profile_id, source_id)
+		VALUES (?, ?)
+		ON CONFLICT(profile_id, source_id) DO NOTHING
+	`, profileID, sourceID); err != nil {
+		return fmt.Errorf("add source to profile blacklist: %w", err)
+	}
+	return nil
+}
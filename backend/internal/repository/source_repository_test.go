@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSourceStatusNotesKeepManualNotesOverAutoOnes(t *testing.T) {
+	repo := NewSourceRepository(setupListingTestDB(t))
+	const sourceID = 1
+	source, err := repo.GetByID(context.Background(), sourceID)
+	if err != nil || source == nil {
+		t.Fatalf("load source: %v %v", source, err)
+	}
+	key := source.Key
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	noteOf := func() (string, string) {
+		t.Helper()
+		source, err := repo.GetByID(context.Background(), sourceID)
+		if err != nil {
+			t.Fatalf("load source: %v", err)
+		}
+		return source.StatusNote, source.NoteSource
+	}
+
+	// The poller writes and clears its own notes.
+	if written, err := repo.SetAutoStatusNote(context.Background(), key, "5 of 5 update checks failed", at); err != nil || !written {
+		t.Fatalf("set auto note: %v %v", written, err)
+	}
+	if note, from := noteOf(); note != "5 of 5 update checks failed" || from != NoteSourceAuto {
+		t.Fatalf("expected auto note, got %q from %q", note, from)
+	}
+	if cleared, err := repo.ClearAutoStatusNote(context.Background(), key); err != nil || !cleared {
+		t.Fatalf("clear auto note: %v %v", cleared, err)
+	}
+	if note, from := noteOf(); note != "" || from != "" {
+		t.Fatalf("expected auto note to be cleared, got %q from %q", note, from)
+	}
+
+	// A manual note replaces an auto one, and the poller then leaves it be.
+	if _, err := repo.SetAutoStatusNote(context.Background(), key, "auto", at); err != nil {
+		t.Fatalf("set auto note: %v", err)
+	}
+	if updated, err := repo.SetStatusNote(context.Background(), sourceID, "  Moved to a new domain  "); err != nil || !updated {
+		t.Fatalf("set manual note: %v %v", updated, err)
+	}
+	if written, err := repo.SetAutoStatusNote(context.Background(), key, "3 of 4 update checks failed", at); err != nil || written {
+		t.Fatalf("expected auto note to skip a manual one: %v %v", written, err)
+	}
+	if cleared, err := repo.ClearAutoStatusNote(context.Background(), key); err != nil || cleared {
+		t.Fatalf("expected clean run to keep a manual note: %v %v", cleared, err)
+	}
+	source, err = repo.GetByID(context.Background(), sourceID)
+	if err != nil {
+		t.Fatalf("load source: %v", err)
+	}
+	if source.StatusNote != "Moved to a new domain" || source.NoteSource != NoteSourceManual || source.StatusNoteUpdatedAt == nil {
+		t.Fatalf("expected manual note to stay, got %+v", source)
+	}
+
+	notes, err := repo.ListStatusNotes(context.Background())
+	if err != nil {
+		t.Fatalf("list notes: %v", err)
+	}
+	if len(notes) != 1 || notes[key] != "Moved to a new domain" {
+		t.Fatalf("expected only the manual note, got %v", notes)
+	}
+
+	// Clearing the manual note hands the source back to the poller.
+	if _, err := repo.SetStatusNote(context.Background(), sourceID, ""); err != nil {
+		t.Fatalf("clear manual note: %v", err)
+	}
+	if note, from := noteOf(); note != "" || from != "" {
+		t.Fatalf("expected note to be cleared, got %q from %q", note, from)
+	}
+	if written, err := repo.SetAutoStatusNote(context.Background(), key, "auto again", at); err != nil || !written {
+		t.Fatalf("expected auto note after manual one was cleared: %v %v", written, err)
+	}
+	if updated, err := repo.SetStatusNote(context.Background(), 9999, "nope"); err != nil || updated {
+		t.Fatalf("expected unknown source to report not updated: %v %v", updated, err)
+	}
+}
+
+func TestSourceCanaryFailureKeepsItsNoteUntilACanaryPasses(t *testing.T) {
+	repo := NewSourceRepository(setupListingTestDB(t))
+	const sourceID = 1
+	source, err := repo.GetByID(context.Background(), sourceID)
+	if err != nil || source == nil {
+		t.Fatalf("load source: %v %v", source, err)
+	}
+	key := source.Key
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	noteOf := func() (string, string) {
+		t.Helper()
+		source, err := repo.GetByID(ctx, sourceID)
+		if err != nil {
+			t.Fatalf("load source: %v", err)
+		}
+		return source.StatusNote, source.NoteSource
+	}
+
+	if ok, err := repo.SetCanaryFailure(ctx, key, "title is empty", "Deep check failed: title is empty", at); err != nil || !ok {
+		t.Fatalf("set canary failure: %v %v", ok, err)
+	}
+	if note, from := noteOf(); note != "Deep check failed: title is empty" || from != NoteSourceAuto {
+		t.Fatalf("expected the canary note, got %q from %q", note, from)
+	}
+	// A clean poll run does not hide a parser that returns garbage.
+	if cleared, err := repo.ClearAutoStatusNote(ctx, key); err != nil || cleared {
+		t.Fatalf("expected the poller to leave the canary note, got %v %v", cleared, err)
+	}
+	if ok, err := repo.ClearCanaryFailure(ctx, key, at.Add(24*time.Hour)); err != nil || !ok {
+		t.Fatalf("clear canary failure: %v %v", ok, err)
+	}
+	if note, from := noteOf(); note != "" || from != "" {
+		t.Fatalf("expected a passing canary to clear its note, got %q from %q", note, from)
+	}
+
+	// A passing canary leaves other notes alone, and a failing one never
+	// replaces a manual note.
+	if _, err := repo.SetAutoStatusNote(ctx, key, "4 of 4 update checks failed", at); err != nil {
+		t.Fatalf("set auto note: %v", err)
+	}
+	if _, err := repo.ClearCanaryFailure(ctx, key, at); err != nil {
+		t.Fatalf("clear canary failure: %v", err)
+	}
+	if note, _ := noteOf(); note != "4 of 4 update checks failed" {
+		t.Fatalf("expected the poller's note to stay, got %q", note)
+	}
+	if _, err := repo.SetStatusNote(ctx, sourceID, "Moved to a new domain"); err != nil {
+		t.Fatalf("set manual note: %v", err)
+	}
+	if _, err := repo.SetCanaryFailure(ctx, key, "title is empty", "Deep check failed", at); err != nil {
+		t.Fatalf("set canary failure: %v", err)
+	}
+	if note, from := noteOf(); note != "Moved to a new domain" || from != NoteSourceManual {
+		t.Fatalf("expected the manual note to stay, got %q from %q", note, from)
+	}
+}
+
+func TestProfileSourceBlacklistIsPerProfileAndKeepsTrackersPolling(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewSourceRepository(db)
+	trackers := NewTrackerRepository(db)
+	ctx := context.Background()
+	alpha, err := trackers.GetByID(ctx, 1, trackerIDByTitle(t, trackers, "Alpha Blade"))
+	if err != nil || alpha == nil {
+		t.Fatalf("load tracker: %v %v", alpha, err)
+	}
+
+	// Blacklisting twice is the same as once.
+	for range 2 {
+		if err := repo.SetProfileSourceBlacklisted(ctx, 1, alpha.SourceID, true); err != nil {
+			t.Fatalf("blacklist source: %v", err)
+		}
+	}
+	blacklisted, err := repo.ListProfileBlacklistedSourceIDs(ctx, 1)
+	if err != nil || len(blacklisted) != 1 || !blacklisted[alpha.SourceID] {
+		t.Fatalf("expected the source blacklisted for profile 1, got %v %v", blacklisted, err)
+	}
+	if other, err := repo.ListProfileBlacklistedSourceIDs(ctx, 2); err != nil || len(other) != 0 {
+		t.Fatalf("expected profile 2 to keep every source, got %v %v", other, err)
+	}
+
+	// The poller does not look at the blacklist.
+	items, err := trackers.ListForPolling(ctx)
+	if err != nil {
+		t.Fatalf("list for polling: %v", err)
+	}
+	polled := false
+	for _, item := range items {
+		polled = polled || item.ID == alpha.ID
+	}
+	if !polled {
+		t.Fatal("expected a tracker on a blacklisted source to still be polled")
+	}
+
+	if err := repo.SetProfileSourceBlacklisted(ctx, 1, alpha.SourceID, false); err != nil {
+		t.Fatalf("remove source from blacklist: %v", err)
+	}
+	if blacklisted, err := repo.ListProfileBlacklistedSourceIDs(ctx, 1); err != nil || len(blacklisted) != 0 {
+		t.Fatalf("expected an empty blacklist, got %v %v", blacklisted, err)
+	}
+}
+
+func TestSetStatusNoteMarksTheSourceEditedByHand(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewSourceRepository(db)
+	userModified := func() bool {
+		t.Helper()
+		var modified bool
+		if err := db.QueryRow(`SELECT user_modified FROM sources WHERE id = 1`).Scan(&modified); err != nil {
+			t.Fatalf("load user_modified: %v", err)
+		}
+		return modified
+	}
+
+	if _, err := repo.SetAutoStatusNote(context.Background(), "mangadex", "auto", time.Now()); err != nil {
+		t.Fatalf("set auto note: %v", err)
+	}
+	if userModified() {
+		t.Fatal("expected the poller's note not to mark the source edited")
+	}
+	if _, err := repo.SetStatusNote(context.Background(), 1, "Moved to a new domain"); err != nil {
+		t.Fatalf("set manual note: %v", err)
+	}
+	if !userModified() {
+		t.Fatal("expected a manual note to mark the source edited")
+	}
+}
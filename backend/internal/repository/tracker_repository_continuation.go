@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetContinuation points a tracker at the tracker that carries its series on,
+// or clears the link when continuedBy is nil. A continuation outside the
+// profile is stored as no continuation.
+func (r *TrackerRepository) SetContinuation(ctx context.Context, profileID int64, id int64, continuedBy *int64) error {
+	var target any
+	if continuedBy != nil {
+		target = *continuedBy
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE trackers
+		SET
+			continued_by_tracker_id = (SELECT target.id FROM trackers target WHERE target.id = ? AND target.profile_id = ? AND target.id <> trackers.id),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+		  AND profile_id = ?
+		  AND continued_by_tracker_id IS NOT (SELECT target.id FROM trackers target WHERE target.id = ? AND target.profile_id = ? AND target.id <> trackers.id)
+	`, target, profileID, id, profileID, target, profileID); err != nil {
+		return fmt.Errorf("set tracker continuation: %w", err)
+	}
+	return nil
+}
+
+// ContinuesInto reports whether following continuations from fromID reaches
+// targetID, which would make linking targetID to fromID a loop.
+func (r *TrackerRepository) ContinuesInto(ctx context.Context, profileID int64, fromID int64, targetID int64) (bool, error) {
+	var found bool
+	err := r.db.QueryRowContext(ctx, `
+		WITH RECURSIVE chain(id) AS (
+			SELECT continued_by_tracker_id FROM trackers WHERE id = ? AND profile_id = ?
+			UNION
+			SELECT trackers.continued_by_tracker_id
+			FROM trackers
+			JOIN chain ON trackers.id = chain.id
+			WHERE trackers.profile_id = ?
+		)
+		SELECT EXISTS(SELECT 1 FROM chain WHERE id = ?)
+	`, fromID, profileID, profileID, targetID).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("check tracker continuation chain: %w", err)
+	}
+	return found, nil
+}
+
+// ListTrackerLinks returns the title and source page of each tracker in ids
+// that belongs to the profile, keyed by tracker id.
+func (r *TrackerRepository) ListTrackerLinks(ctx context.Context, profileID int64, ids []int64) (map[int64]TrackerLink, error) {
+	links := make(map[int64]TrackerLink, len(ids))
+	if len(ids) == 0 {
+		return links, nil
+	}
+
+	args := make([]any, 0, len(ids)+1)
+	args = append(args, profileID)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, title, source_url
+		FROM trackers
+		WHERE profile_id = ? AND id IN (`+sqlPlaceholders(len(ids))+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list tracker links: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link TrackerLink
+		if err := rows.Scan(&link.ID, &link.Title, &link.SourceURL); err != nil {
+			return nil, fmt.Errorf("scan tracker link: %w", err)
+		}
+		links[link.ID] = link
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tracker links: %w", err)
+	}
+
+	return links, nil
+}
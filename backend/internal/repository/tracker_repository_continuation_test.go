@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetContinuationLinksTrackersOfTheSameProfile(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+	beta := trackerIDByTitle(t, repo, "Beta Blade")
+	other := trackerIDByTitle(t, repo, "Other Profile Blade")
+
+	if err := repo.SetContinuation(context.Background(), 1, alpha, &beta); err != nil {
+		t.Fatalf("set continuation: %v", err)
+	}
+	tracker := getMilestoneTracker(t, repo, alpha)
+	if tracker.ContinuedByTrackerID == nil || *tracker.ContinuedByTrackerID != beta {
+		t.Fatalf("expected alpha to continue in %d, got %v", beta, tracker.ContinuedByTrackerID)
+	}
+
+	links, err := repo.ListTrackerLinks(context.Background(), 1, []int64{beta, other})
+	if err != nil {
+		t.Fatalf("list tracker links: %v", err)
+	}
+	if len(links) != 1 || links[beta].Title != "Beta Blade" || links[beta].SourceURL != "https://mangafire.to/manga/beta" {
+		t.Fatalf("expected only the profile's tracker link, got %+v", links)
+	}
+
+	// Trackers of another profile and the tracker itself are not kept.
+	if err := repo.SetContinuation(context.Background(), 1, alpha, &other); err != nil {
+		t.Fatalf("set foreign continuation: %v", err)
+	}
+	if tracker := getMilestoneTracker(t, repo, alpha); tracker.ContinuedByTrackerID != nil {
+		t.Fatalf("expected foreign continuation to be dropped, got %v", *tracker.ContinuedByTrackerID)
+	}
+	if err := repo.SetContinuation(context.Background(), 1, alpha, &alpha); err != nil {
+		t.Fatalf("set self continuation: %v", err)
+	}
+	if tracker := getMilestoneTracker(t, repo, alpha); tracker.ContinuedByTrackerID != nil {
+		t.Fatalf("expected self continuation to be dropped, got %v", *tracker.ContinuedByTrackerID)
+	}
+}
+
+func TestContinuationIsClearedWhenTheContinuationIsDeleted(t *testing.T) {
+	repo := NewTrackerRepository(setupListingTestDB(t))
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+	beta := trackerIDByTitle(t, repo, "Beta Blade")
+
+	if err := repo.SetContinuation(context.Background(), 1, alpha, &beta); err != nil {
+		t.Fatalf("set continuation: %v", err)
+	}
+	if deleted, err := repo.Delete(context.Background(), 1, beta); err != nil || !deleted {
+		t.Fatalf("delete continuation: %v %v", deleted, err)
+	}
+	if tracker := getMilestoneTracker(t, repo, alpha); tracker.ContinuedByTrackerID != nil {
+		t.Fatalf("expected continuation to be cleared, got %v", *tracker.ContinuedByTrackerID)
+	}
+}
+
+func TestContinuesIntoFollowsTheChain(t *testing.T) {
+	repo := NewTrackerRepository(setupListingTestDB(t))
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+	beta := trackerIDByTitle(t, repo, "Beta Blade")
+	epsilon := trackerIDByTitle(t, repo, "Epsilon Blade")
+
+	if err := repo.SetContinuation(context.Background(), 1, alpha, &beta); err != nil {
+		t.Fatalf("set alpha continuation: %v", err)
+	}
+	if err := repo.SetContinuation(context.Background(), 1, beta, &epsilon); err != nil {
+		t.Fatalf("set beta continuation: %v", err)
+	}
+
+	cases := []struct {
+		from, target int64
+		want         bool
+	}{
+		{from: alpha, target: beta, want: true},
+		{from: alpha, target: epsilon, want: true},
+		{from: beta, target: alpha, want: false},
+		{from: epsilon, target: alpha, want: false},
+	}
+	for _, tc := range cases {
+		got, err := repo.ContinuesInto(context.Background(), 1, tc.from, tc.target)
+		if err != nil {
+			t.Fatalf("continues into: %v", err)
+		}
+		if got != tc.want {
+			t.Fatalf("expected ContinuesInto(%d, %d) = %v, got %v", tc.from, tc.target, tc.want, got)
+		}
+	}
+}
+
+func TestReadingFilterDropsFinishedContinuedTrackers(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	if _, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES
+			(1, 'Zeta Season 1', 3, 'https://www.webtoons.com/en/fantasy/zeta/list?title_no=1', 'reading', 80, NULL),
+			(1, 'Zeta Season 2', 3, 'https://www.webtoons.com/en/fantasy/zeta-s2/list?title_no=2', 'reading', 3, 10),
+			(1, 'Eta Season 1', 3, 'https://www.webtoons.com/en/fantasy/eta/list?title_no=3', 'reading', 40, NULL)
+	`); err != nil {
+		t.Fatalf("seed season trackers: %v", err)
+	}
+	seasonOne := trackerIDByTitle(t, repo, "Zeta Season 1")
+	seasonTwo := trackerIDByTitle(t, repo, "Zeta Season 2")
+
+	listReading := func() map[string]bool {
+		t.Helper()
+		items, err := repo.List(context.Background(), TrackerListOptions{ProfileID: 1, Statuses: []string{"reading"}, Query: "season"})
+		if err != nil {
+			t.Fatalf("list reading: %v", err)
+		}
+		titles := make(map[string]bool, len(items))
+		for _, item := range items {
+			titles[item.Title] = true
+		}
+		return titles
+	}
+
+	if titles := listReading(); !titles["Zeta Season 1"] || !titles["Eta Season 1"] {
+		t.Fatalf("expected trackers without a known latest chapter while not continued, got %v", titles)
+	}
+
+	if err := repo.SetContinuation(context.Background(), 1, seasonOne, &seasonTwo); err != nil {
+		t.Fatalf("set continuation: %v", err)
+	}
+	titles := listReading()
+	if titles["Zeta Season 1"] {
+		t.Fatalf("expected the finished continued season to leave the reading filter, got %v", titles)
+	}
+	if !titles["Zeta Season 2"] || !titles["Eta Season 1"] {
+		t.Fatalf("expected the continuation and unrelated trackers to stay, got %v", titles)
+	}
+
+	// A continued tracker with unread chapters still counts as reading.
+	if _, err := db.Exec(`UPDATE trackers SET latest_known_chapter = 82 WHERE id = ?`, seasonOne); err != nil {
+		t.Fatalf("set latest chapter: %v", err)
+	}
+	if titles := listReading(); !titles["Zeta Season 1"] {
+		t.Fatalf("expected continued tracker with unread chapters to stay, got %v", titles)
+	}
+}
@@ -1,29 +1,41 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
 )
 
-func (r *TrackerRepository) SourceExists(sourceID int64) (bool, error) {
+func (r *TrackerRepository) SourceExists(ctx context.Context, sourceID int64) (bool, error) {
 	var count int
-	err := r.db.QueryRow(`SELECT COUNT(1) FROM sources WHERE id = ?`, sourceID).Scan(&count)
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM sources WHERE id = ?`, sourceID).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("check source exists: %w", err)
 	}
 	return count > 0, nil
 }
 
-func (r *TrackerRepository) Create(tracker *models.Tracker) (*models.Tracker, error) {
+func (r *TrackerRepository) Create(ctx context.Context, tracker *models.Tracker) (*models.Tracker, error) {
 	relatedTitlesJSON := encodeRelatedTitlesJSON(tracker.RelatedTitles)
-	result, err := r.db.Exec(`
+	result, err := r.db.ExecContext(ctx, `
 		INSERT INTO trackers (
-			profile_id, title, related_titles, source_id, source_item_id, source_url, status, last_read_chapter, rating, latest_known_chapter, latest_release_at, last_checked_at, last_read_at
+			profile_id, title, related_titles, source_id, source_item_id, source_url, status, last_read_chapter, rating, latest_known_chapter, latest_release_at, last_checked_at, last_read_at,
+			first_read_at, caught_up_at, source_genres
 		)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CASE WHEN ? IS NULL THEN NULL ELSE CURRENT_TIMESTAMP END)
-	`, tracker.ProfileID, tracker.Title, relatedTitlesJSON, tracker.SourceID, tracker.SourceItemID, tracker.SourceURL, tracker.Status, tracker.LastReadChapter, tracker.Rating, tracker.LatestKnownChapter, tracker.LatestReleaseAt, tracker.LastCheckedAt, tracker.LastReadChapter)
+		VALUES (
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CASE WHEN ? IS NULL THEN NULL ELSE CURRENT_TIMESTAMP END,
+			CASE WHEN ? IS NULL THEN NULL ELSE CURRENT_TIMESTAMP END,
+			CASE WHEN ? IN ('reading', 'completed') AND ? >= ? THEN CURRENT_TIMESTAMP ELSE NULL END,
+			?
+		)
+	`, tracker.ProfileID, tracker.Title, relatedTitlesJSON, tracker.SourceID, tracker.SourceItemID, tracker.SourceURL, tracker.Status, tracker.LastReadChapter, tracker.Rating, tracker.LatestKnownChapter, tracker.LatestReleaseAt, tracker.LastCheckedAt, tracker.LastReadChapter,
+		tracker.LastReadChapter,
+		tracker.Status, tracker.LastReadChapter, tracker.LatestKnownChapter,
+		encodeSourceGenresJSON(tracker.SourceGenres))
 	if err != nil {
 		return nil, fmt.Errorf("insert tracker: %w", err)
 	}
@@ -33,7 +45,7 @@ func (r *TrackerRepository) Create(tracker *models.Tracker) (*models.Tracker, er
 		return nil, fmt.Errorf("get tracker last insert id: %w", err)
 	}
 
-	if err := r.ReplaceTrackerSources(tracker.ProfileID, id, []models.TrackerSource{{
+	if err := r.ReplaceTrackerSources(ctx, tracker.ProfileID, id, []models.TrackerSource{{
 		SourceID:     tracker.SourceID,
 		SourceItemID: tracker.SourceItemID,
 		SourceURL:    tracker.SourceURL,
@@ -41,15 +53,16 @@ func (r *TrackerRepository) Create(tracker *models.Tracker) (*models.Tracker, er
 		return nil, fmt.Errorf("create tracker sources: %w", err)
 	}
 
-	return r.GetByID(tracker.ProfileID, id)
+	return r.GetByID(ctx, tracker.ProfileID, id)
 }
 
-func (r *TrackerRepository) GetByID(profileID int64, id int64) (*models.Tracker, error) {
-	row := r.db.QueryRow(`
+func (r *TrackerRepository) GetByID(ctx context.Context, profileID int64, id int64) (*models.Tracker, error) {
+	row := r.db.QueryRowContext(ctx, `
 		SELECT
 			id, profile_id, title, related_titles, source_id, source_item_id, source_url, status,
 			last_read_chapter, rating, last_read_at, latest_known_chapter, latest_release_at, last_checked_at,
-			created_at, updated_at
+			first_read_at, caught_up_at, resolve_failure, last_poll_error, last_poll_error_at, continued_by_tracker_id,
+			source_genres, rereading, reread_count, previous_completions, next_scheduled_chapter, next_scheduled_at, created_at, updated_at
 		FROM trackers
 		WHERE id = ? AND profile_id = ?
 	`, id, profileID)
@@ -62,7 +75,7 @@ func (r *TrackerRepository) GetByID(profileID int64, id int64) (*models.Tracker,
 		return nil, fmt.Errorf("get tracker by id: %w", err)
 	}
 
-	tagsByTracker, err := r.ListTagsByTrackerIDs(profileID, []int64{tracker.ID})
+	tagsByTracker, err := r.ListTagsByTrackerIDs(ctx, profileID, []int64{tracker.ID})
 	if err != nil {
 		return nil, fmt.Errorf("get tracker tags: %w", err)
 	}
@@ -71,9 +84,18 @@ func (r *TrackerRepository) GetByID(profileID int64, id int64) (*models.Tracker,
 	return tracker, nil
 }
 
-func (r *TrackerRepository) Update(profileID int64, id int64, tracker *models.Tracker) (*models.Tracker, error) {
+func (r *TrackerRepository) Update(ctx context.Context, profileID int64, id int64, tracker *models.Tracker) (*models.Tracker, error) {
+	previousLastRead, err := r.lastReadChapterOf(ctx, profileID, id)
+	if err != nil {
+		return nil, err
+	}
+	previousSourceID, previousSourceURL, err := r.primarySourceOf(ctx, profileID, id)
+	if err != nil {
+		return nil, err
+	}
+
 	relatedTitlesJSON := encodeRelatedTitlesJSON(tracker.RelatedTitles)
-	result, err := r.db.Exec(`
+	result, err := r.db.ExecContext(ctx, `
 		UPDATE trackers
 		SET
 			title = ?,
@@ -85,6 +107,11 @@ func (r *TrackerRepository) Update(profileID int64, id int64, tracker *models.Tr
 			last_read_chapter = ?,
 			rating = ?,
 			last_read_at = CASE WHEN last_read_chapter IS NOT ? THEN CURRENT_TIMESTAMP ELSE last_read_at END,
+			first_read_at = CASE WHEN first_read_at IS NULL AND ? IS NOT NULL THEN CURRENT_TIMESTAMP ELSE first_read_at END,
+			caught_up_at = CASE
+				WHEN caught_up_at IS NULL AND ? IN ('reading', 'completed') AND ? >= ? THEN CURRENT_TIMESTAMP
+				ELSE caught_up_at
+			END,
 			latest_known_chapter = ?,
 			latest_release_at = ?,
 			last_checked_at = ?,
@@ -114,6 +141,10 @@ func (r *TrackerRepository) Update(profileID int64, id int64, tracker *models.Tr
 		tracker.LastReadChapter,
 		tracker.Rating,
 		tracker.LastReadChapter,
+		tracker.LastReadChapter,
+		tracker.Status,
+		tracker.LastReadChapter,
+		tracker.LatestKnownChapter,
 		tracker.LatestKnownChapter,
 		tracker.LatestReleaseAt,
 		tracker.LastCheckedAt,
@@ -140,10 +171,20 @@ func (r *TrackerRepository) Update(profileID int64, id int64, tracker *models.Tr
 		return nil, fmt.Errorf("tracker update rows affected: %w", err)
 	}
 	if rowsAffected == 0 {
-		return r.GetByID(profileID, id)
+		return r.GetByID(ctx, profileID, id)
 	}
 
-	if err := r.UpsertTrackerSource(profileID, id, models.TrackerSource{
+	if err := r.recordReadEvent(ctx, profileID, id, previousLastRead, tracker.LastReadChapter, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := r.finishReread(ctx, profileID, id); err != nil {
+		return nil, err
+	}
+	if err := recordReplacedURL(ctx, r.db, id, previousSourceID, previousSourceURL, tracker.SourceURL, URLHistoryEdit); err != nil {
+		return nil, err
+	}
+
+	if err := r.UpsertTrackerSource(ctx, profileID, id, models.TrackerSource{
 		SourceID:     tracker.SourceID,
 		SourceItemID: tracker.SourceItemID,
 		SourceURL:    tracker.SourceURL,
@@ -151,20 +192,30 @@ func (r *TrackerRepository) Update(profileID int64, id int64, tracker *models.Tr
 		return nil, fmt.Errorf("upsert primary tracker source: %w", err)
 	}
 
-	return r.GetByID(profileID, id)
+	return r.GetByID(ctx, profileID, id)
 }
 
-func (r *TrackerRepository) UpdateLastReadChapter(profileID int64, id int64, lastReadChapter *float64) (bool, error) {
-	result, err := r.db.Exec(`
+func (r *TrackerRepository) UpdateLastReadChapter(ctx context.Context, profileID int64, id int64, lastReadChapter *float64) (bool, error) {
+	previous, err := r.lastReadChapterOf(ctx, profileID, id)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
 		UPDATE trackers
 		SET
 			last_read_chapter = ?,
 			last_read_at = CURRENT_TIMESTAMP,
+			first_read_at = CASE WHEN first_read_at IS NULL AND ? IS NOT NULL THEN CURRENT_TIMESTAMP ELSE first_read_at END,
+			caught_up_at = CASE
+				WHEN caught_up_at IS NULL AND status IN ('reading', 'completed') AND ? >= latest_known_chapter THEN CURRENT_TIMESTAMP
+				ELSE caught_up_at
+			END,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 		  AND profile_id = ?
 		  AND last_read_chapter IS NOT ?
-	`, lastReadChapter, id, profileID, lastReadChapter)
+	`, lastReadChapter, lastReadChapter, lastReadChapter, id, profileID, lastReadChapter)
 	if err != nil {
 		return false, fmt.Errorf("update last read chapter: %w", err)
 	}
@@ -173,12 +224,51 @@ func (r *TrackerRepository) UpdateLastReadChapter(profileID int64, id int64, las
 	if err != nil {
 		return false, fmt.Errorf("last read update rows affected: %w", err)
 	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
 
-	return rowsAffected > 0, nil
+	if err := r.recordReadEvent(ctx, profileID, id, previous, lastReadChapter, time.Now()); err != nil {
+		return false, err
+	}
+	if err := r.finishReread(ctx, profileID, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetManualRelease stores a chapter logged by hand as the tracker's latest
+// known chapter and its release time.
+func (r *TrackerRepository) SetManualRelease(ctx context.Context, profileID int64, id int64, chapter float64, releasedAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE trackers
+		SET
+			latest_known_chapter = ?,
+			latest_release_at = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+		  AND profile_id = ?
+	`, chapter, releasedAt.UTC(), id, profileID)
+	if err != nil {
+		return false, fmt.Errorf("set manual release: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("manual release rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+	if err := r.recordChapterRelease(ctx, id, &chapter, &releasedAt); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
 
-func (r *TrackerRepository) UpdateRating(profileID int64, id int64, rating *float64) (bool, error) {
-	result, err := r.db.Exec(`
+func (r *TrackerRepository) UpdateRating(ctx context.Context, profileID int64, id int64, rating *float64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
 		UPDATE trackers
 		SET
 			rating = ?,
@@ -199,8 +289,93 @@ func (r *TrackerRepository) UpdateRating(profileID int64, id int64, rating *floa
 	return rowsAffected > 0, nil
 }
 
-func (r *TrackerRepository) Delete(profileID int64, id int64) (bool, error) {
-	result, err := r.db.Exec(`DELETE FROM trackers WHERE id = ? AND profile_id = ?`, id, profileID)
+// UpdateResolvedSource stores metadata from a background source lookup and
+// clears any recorded resolve failure. It only applies while the tracker
+// still points at resolvedFromURL, so an edit made during the lookup wins.
+// When the lookup answers with another URL, resolvedFromURL goes to the URL
+// history.
+func (r *TrackerRepository) UpdateResolvedSource(ctx context.Context, profileID int64, id int64, resolvedFromURL string, tracker *models.Tracker, checkedAt time.Time) (bool, error) {
+	relatedTitlesJSON := encodeRelatedTitlesJSON(tracker.RelatedTitles)
+	trimmedFromURL := strings.TrimSpace(resolvedFromURL)
+	trimmedSourceURL := strings.TrimSpace(tracker.SourceURL)
+	if trimmedSourceURL == "" {
+		trimmedSourceURL = trimmedFromURL
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE trackers
+		SET
+			source_item_id = ?,
+			source_url = ?,
+			related_titles = ?,
+			latest_known_chapter = ?,
+			latest_release_at = ?,
+			last_checked_at = ?,
+			source_genres = COALESCE(?, source_genres),
+			resolve_failure = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+		  AND profile_id = ?
+		  AND source_url = ?
+	`, tracker.SourceItemID, trimmedSourceURL, relatedTitlesJSON, tracker.LatestKnownChapter, tracker.LatestReleaseAt, checkedAt.UTC(), encodeSourceGenresJSON(tracker.SourceGenres), id, profileID, trimmedFromURL)
+	if err != nil {
+		return false, fmt.Errorf("update resolved source: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("resolved source update rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	var movedLang string
+	if !strings.EqualFold(trimmedFromURL, trimmedSourceURL) {
+		if err := recordReplacedURL(ctx, r.db, id, tracker.SourceID, trimmedFromURL, trimmedSourceURL, URLHistorySlugRotation); err != nil {
+			return false, err
+		}
+		if movedLang, err = r.trackerSourceLangAt(ctx, id, tracker.SourceID, trimmedFromURL); err != nil {
+			return false, err
+		}
+		if _, err := r.db.ExecContext(ctx, `
+			DELETE FROM tracker_sources
+			WHERE tracker_id = ? AND source_id = ? AND LOWER(source_url) = LOWER(?)
+		`, id, tracker.SourceID, trimmedFromURL); err != nil {
+			return false, fmt.Errorf("delete stale resolved tracker source: %w", err)
+		}
+	}
+	if err := r.UpsertTrackerSource(ctx, profileID, id, models.TrackerSource{
+		SourceID:     tracker.SourceID,
+		SourceItemID: tracker.SourceItemID,
+		SourceURL:    trimmedSourceURL,
+		Lang:         movedLang,
+	}); err != nil {
+		return false, fmt.Errorf("upsert resolved tracker source: %w", err)
+	}
+
+	return true, nil
+}
+
+// SetResolveFailure records why the source lookup for a tracker was given up
+// on. An empty note clears it.
+func (r *TrackerRepository) SetResolveFailure(ctx context.Context, profileID int64, id int64, note string) error {
+	var value any
+	if trimmed := strings.TrimSpace(note); trimmed != "" {
+		value = trimmed
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE trackers
+		SET resolve_failure = ?
+		WHERE id = ? AND profile_id = ?
+	`, value, id, profileID); err != nil {
+		return fmt.Errorf("set resolve failure: %w", err)
+	}
+	return nil
+}
+
+func (r *TrackerRepository) Delete(ctx context.Context, profileID int64, id int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM trackers WHERE id = ? AND profile_id = ?`, id, profileID)
 	if err != nil {
 		return false, fmt.Errorf("delete tracker: %w", err)
 	}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
+)
+
+// TrackerListExplanation says why a tracker is, or is not, in the list some
+// options select: each filter clause checked against it on its own. Listed
+// is true exactly when every clause passed.
+type TrackerListExplanation struct {
+	TrackerID int64                `json:"trackerId"`
+	Title     string               `json:"title"`
+	Listed    bool                 `json:"listed"`
+	Clauses   []TrackerFilterCheck `json:"clauses"`
+}
+
+// TrackerFilterCheck is one filter clause and whether the tracker passed it.
+// Detail, when set, gives the tracker's side of the check, e.g. the tags it
+// has.
+type TrackerFilterCheck struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Passed      bool   `json:"passed"`
+	Detail      string `json:"detail,omitempty"`
+}
+
+// trackerFacts is what the explanations of failed or passed clauses quote.
+type trackerFacts struct {
+	tracker         *models.Tracker
+	linkedSourceIDs []int64
+}
+
+// ExplainListMembership checks the tracker against each of the list's filter
+// clauses for options, using the same SQL List does so the answer cannot
+// drift from the list. Paging and sort options are ignored. It returns nil
+// when the tracker is not in options.ProfileID.
+func (r *TrackerRepository) ExplainListMembership(ctx context.Context, trackerID int64, options TrackerListOptions) (*TrackerListExplanation, error) {
+	tracker, err := r.GetByID(ctx, options.ProfileID, trackerID)
+	if err != nil {
+		return nil, fmt.Errorf("explain list membership: %w", err)
+	}
+	if tracker == nil {
+		return nil, nil
+	}
+
+	linkedSourceIDs, err := r.trackerLinkedSourceIDs(ctx, trackerID)
+	if err != nil {
+		return nil, fmt.Errorf("explain list membership: %w", err)
+	}
+	facts := trackerFacts{tracker: tracker, linkedSourceIDs: linkedSourceIDs}
+
+	// GetByID already checked the profile; the other clauses are evaluated
+	// together against the tracker's row.
+	clauses := trackerListClauses(options)[1:]
+	explanation := &TrackerListExplanation{
+		TrackerID: trackerID,
+		Title:     tracker.Title,
+		Listed:    true,
+		Clauses:   make([]TrackerFilterCheck, 0, len(clauses)+1),
+	}
+	explanation.Clauses = append(explanation.Clauses, TrackerFilterCheck{
+		Name:        TrackerClauseProfile,
+		Description: fmt.Sprintf("belongs to profile %d", options.ProfileID),
+		Passed:      true,
+	})
+	if len(clauses) == 0 {
+		return explanation, nil
+	}
+
+	columns := make([]string, 0, len(clauses))
+	args := make([]any, 0, len(clauses)+1)
+	for _, clause := range clauses {
+		columns = append(columns, "("+clause.sql+")")
+		args = append(args, clause.args...)
+	}
+	args = append(args, trackerID)
+
+	results := make([]sql.NullInt64, len(clauses))
+	dest := make([]any, len(clauses))
+	for i := range results {
+		dest[i] = &results[i]
+	}
+	query := `SELECT ` + strings.Join(columns, ", ") + ` FROM trackers WHERE trackers.id = ?`
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(dest...); err != nil {
+		return nil, fmt.Errorf("evaluate tracker filters: %w", err)
+	}
+
+	for i, clause := range clauses {
+		// A NULL result filters the row out just like false does.
+		passed := results[i].Valid && results[i].Int64 != 0
+		check := TrackerFilterCheck{Name: clause.name, Description: clause.description, Passed: passed}
+		if clause.explain != nil {
+			check.Detail = clause.explain(facts, passed)
+		}
+		if !passed {
+			explanation.Listed = false
+		}
+		explanation.Clauses = append(explanation.Clauses, check)
+	}
+	return explanation, nil
+}
+
+func (r *TrackerRepository) trackerLinkedSourceIDs(ctx context.Context, trackerID int64) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT source_id
+		FROM tracker_sources
+		WHERE tracker_id = ?
+		ORDER BY source_id ASC
+	`, trackerID)
+	if err != nil {
+		return nil, fmt.Errorf("list tracker source ids: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var sourceID int64
+		if err := rows.Scan(&sourceID); err != nil {
+			return nil, fmt.Errorf("scan tracker source id: %w", err)
+		}
+		ids = append(ids, sourceID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tracker source ids: %w", err)
+	}
+	return ids, nil
+}
+
+func explainCaughtUp(facts trackerFacts, passed bool) string {
+	tracker := facts.tracker
+	switch {
+	case tracker.Status != "reading":
+		return "not reading, so never hidden as caught up"
+	case tracker.LastReadChapter == nil:
+		return "nothing read yet"
+	case tracker.LatestKnownChapter == nil && tracker.ContinuedByTrackerID == nil:
+		return "latest chapter unknown"
+	case tracker.LatestKnownChapter == nil:
+		return fmt.Sprintf("excluded because it is continued by tracker %d and its latest chapter is unknown", *tracker.ContinuedByTrackerID)
+	case passed:
+		return fmt.Sprintf("last read %s < latest %s", formatExplainChapter(*tracker.LastReadChapter), formatExplainChapter(*tracker.LatestKnownChapter))
+	default:
+		return fmt.Sprintf("excluded because last read %s ≥ latest %s", formatExplainChapter(*tracker.LastReadChapter), formatExplainChapter(*tracker.LatestKnownChapter))
+	}
+}
+
+func explainSources(facts trackerFacts, _ bool) string {
+	detail := "primary site " + strconv.FormatInt(facts.tracker.SourceID, 10)
+	if len(facts.linkedSourceIDs) > 0 {
+		detail += ", linked sites " + joinInt64s(facts.linkedSourceIDs, ", ")
+	}
+	return detail
+}
+
+func explainTags(names []string) func(trackerFacts, bool) string {
+	return func(facts trackerFacts, _ bool) string {
+		wanted := make(map[string]struct{}, len(names))
+		for _, name := range names {
+			wanted[name] = struct{}{}
+		}
+		matched := make([]string, 0, len(names))
+		for _, tag := range facts.tracker.Tags {
+			if _, ok := wanted[searchutil.NormalizeTagName(tag.Name)]; ok {
+				matched = append(matched, tag.Name)
+			}
+		}
+		if len(matched) == 0 {
+			return "has none of these tags"
+		}
+		return "has " + strings.Join(matched, ", ")
+	}
+}
+
+func formatExplainChapter(chapter float64) string {
+	return strconv.FormatFloat(chapter, 'f', -1, 64)
+}
+
+func joinInt64s(values []int64, sep string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		parts = append(parts, strconv.FormatInt(value, 10))
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExplainListMembershipAgreesWithList(t *testing.T) {
+	db := setupListingTestDB(t)
+	seedTagMatrix(t, db)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	all, err := repo.List(ctx, TrackerListOptions{ProfileID: 1})
+	if err != nil {
+		t.Fatalf("list all trackers: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		options TrackerListOptions
+	}{
+		{name: "no filters", options: TrackerListOptions{ProfileID: 1}},
+		{name: "reading hides caught up", options: TrackerListOptions{ProfileID: 1, Statuses: []string{"reading"}}},
+		{name: "several statuses", options: TrackerListOptions{ProfileID: 1, Statuses: []string{"reading", "on_hold"}}},
+		{name: "query", options: TrackerListOptions{ProfileID: 1, Query: "blade"}},
+		{name: "linked site", options: TrackerListOptions{ProfileID: 1, SourceIDs: []int64{3}}},
+		{name: "tags", options: TrackerListOptions{ProfileID: 1, TagFilters: []TagFilter{anyOf("Favorite", "priority"), noneOf("stale")}}},
+		{name: "everything", options: TrackerListOptions{ProfileID: 1, Statuses: []string{"reading", "on_hold"}, Query: "a", SourceIDs: []int64{1, 3}, TagFilters: []TagFilter{anyOf("action")}}},
+	}
+	for _, tc := range cases {
+		listed, err := repo.List(ctx, tc.options)
+		if err != nil {
+			t.Fatalf("%s: list: %v", tc.name, err)
+		}
+		inList := make(map[int64]bool, len(listed))
+		for _, tracker := range listed {
+			inList[tracker.ID] = true
+		}
+
+		for _, tracker := range all {
+			explanation, err := repo.ExplainListMembership(ctx, tracker.ID, tc.options)
+			if err != nil {
+				t.Fatalf("%s: explain %s: %v", tc.name, tracker.Title, err)
+			}
+			if explanation.Listed != inList[tracker.ID] {
+				t.Fatalf("%s: %s listed=%v but in list=%v: %+v", tc.name, tracker.Title, explanation.Listed, inList[tracker.ID], explanation.Clauses)
+			}
+			failed := 0
+			for _, check := range explanation.Clauses {
+				if !check.Passed {
+					failed++
+				}
+			}
+			if explanation.Listed != (failed == 0) {
+				t.Fatalf("%s: %s listed=%v with %d failed clauses", tc.name, tracker.Title, explanation.Listed, failed)
+			}
+		}
+	}
+}
+
+func TestExplainListMembershipNamesTheFailingClause(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	var betaID, otherID int64
+	if err := db.QueryRow(`SELECT id FROM trackers WHERE title = 'Beta Blade'`).Scan(&betaID); err != nil {
+		t.Fatalf("find beta: %v", err)
+	}
+	if err := db.QueryRow(`SELECT id FROM trackers WHERE title = 'Other Profile Blade'`).Scan(&otherID); err != nil {
+		t.Fatalf("find other: %v", err)
+	}
+
+	explanation, err := repo.ExplainListMembership(ctx, betaID, TrackerListOptions{ProfileID: 1, Statuses: []string{"reading"}, TagFilters: []TagFilter{anyOf("favorite")}})
+	if err != nil {
+		t.Fatalf("explain beta: %v", err)
+	}
+	if explanation.Listed {
+		t.Fatalf("expected Beta Blade out of the list")
+	}
+	checks := make(map[string]TrackerFilterCheck, len(explanation.Clauses))
+	for _, check := range explanation.Clauses {
+		checks[check.Name] = check
+	}
+	if !checks[TrackerClauseProfile].Passed || !checks[TrackerClauseStatus].Passed {
+		t.Fatalf("expected profile and status to pass, got %+v", explanation.Clauses)
+	}
+	if caughtUp := checks[TrackerClauseCaughtUp]; caughtUp.Passed || caughtUp.Detail != "excluded because last read 12 ≥ latest 12" {
+		t.Fatalf("expected the caught-up clause to fail with its chapters, got %+v", caughtUp)
+	}
+	if tag := checks[TrackerClauseTag]; tag.Passed || tag.Detail != "has none of these tags" {
+		t.Fatalf("expected the tag clause to fail, got %+v", tag)
+	}
+
+	missing, err := repo.ExplainListMembership(ctx, otherID, TrackerListOptions{ProfileID: 1})
+	if err != nil {
+		t.Fatalf("explain other profile: %v", err)
+	}
+	if missing != nil {
+		t.Fatalf("expected no explanation for another profile's tracker, got %+v", missing)
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -10,86 +11,371 @@ import (
 	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
 )
 
-func (r *TrackerRepository) List(options TrackerListOptions) ([]models.Tracker, error) {
-	validSortFields := map[string]string{
-		"title":                "title",
-		"created_at":           "created_at",
-		"updated_at":           "updated_at",
-		"last_read_at":         "last_read_at",
-		"last_checked_at":      "last_checked_at",
-		"rating":               "rating",
-		"latest_known_chapter": "CASE WHEN latest_known_chapter IS NULL THEN NULL ELSE COALESCE(latest_release_at, last_checked_at, updated_at, created_at) END",
+func (r *TrackerRepository) List(ctx context.Context, options TrackerListOptions) ([]models.Tracker, error) {
+	query, args := buildTrackerListQuery(options, false)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list trackers: %w", err)
 	}
-	sortField, ok := validSortFields[options.SortBy]
-	if !ok {
-		sortField = validSortFields["latest_known_chapter"]
+	defer rows.Close()
+
+	trackers := make([]models.Tracker, 0)
+	for rows.Next() {
+		tracker, err := scanTracker(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan tracker row: %w", err)
+		}
+		trackers = append(trackers, *tracker)
 	}
 
-	order := strings.ToUpper(options.Order)
-	if order != "ASC" && order != "DESC" {
-		order = "DESC"
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tracker rows: %w", err)
 	}
 
-	query := `
-		SELECT
-			id, profile_id, title, related_titles, source_id, source_item_id, source_url, status,
-			last_read_chapter, rating, last_read_at, latest_known_chapter, latest_release_at, last_checked_at,
-			created_at, updated_at
-		FROM trackers
-	`
+	if err := r.attachTrackerTags(ctx, options.ProfileID, trackers); err != nil {
+		return nil, err
+	}
 
-	whereClauses, args := buildTrackerListFilters(options)
+	return trackers, nil
+}
 
-	if len(whereClauses) > 0 {
-		query += ` WHERE ` + strings.Join(whereClauses, " AND ")
+// ListWithTotal returns one page of trackers together with the total number
+// of matches, using COUNT(*) OVER () so both come from a single pass. If the
+// window query fails (SQLite builds older than 3.25 lack window functions)
+// it falls back to separate List and Count queries.
+func (r *TrackerRepository) ListWithTotal(ctx context.Context, options TrackerListOptions) ([]models.Tracker, int, error) {
+	trackers, total, err := r.listWithWindowTotal(ctx, options)
+	if err != nil {
+		return r.listWithSeparateCount(ctx, options)
 	}
 
-	query += ` ORDER BY ` + sortField + ` ` + order + `, id DESC`
-
-	if options.Limit > 0 {
-		query += ` LIMIT ?`
-		args = append(args, options.Limit)
-		if options.Offset > 0 {
-			query += ` OFFSET ?`
-			args = append(args, options.Offset)
+	// A page past the end yields no rows and therefore no window total.
+	if len(trackers) == 0 && options.Offset > 0 {
+		total, err = r.Count(ctx, options)
+		if err != nil {
+			return nil, 0, err
 		}
 	}
 
-	rows, err := r.db.Query(query, args...)
+	if err := r.attachTrackerTags(ctx, options.ProfileID, trackers); err != nil {
+		return nil, 0, err
+	}
+
+	return trackers, total, nil
+}
+
+func (r *TrackerRepository) listWithWindowTotal(ctx context.Context, options TrackerListOptions) ([]models.Tracker, int, error) {
+	query, args := buildTrackerListQuery(options, true)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("list trackers: %w", err)
+		return nil, 0, fmt.Errorf("list trackers with total: %w", err)
 	}
 	defer rows.Close()
 
+	total := 0
 	trackers := make([]models.Tracker, 0)
 	for rows.Next() {
-		tracker, err := scanTracker(rows)
+		tracker, err := scanTracker(totalCountScanner{rows: rows, total: &total})
 		if err != nil {
-			return nil, fmt.Errorf("scan tracker row: %w", err)
+			return nil, 0, fmt.Errorf("scan tracker row with total: %w", err)
 		}
 		trackers = append(trackers, *tracker)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate tracker rows: %w", err)
+		return nil, 0, fmt.Errorf("iterate tracker rows with total: %w", err)
+	}
+
+	return trackers, total, nil
+}
+
+func (r *TrackerRepository) listWithSeparateCount(ctx context.Context, options TrackerListOptions) ([]models.Tracker, int, error) {
+	total, err := r.Count(ctx, options)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	trackers, err := r.List(ctx, options)
+	if err != nil {
+		return nil, 0, err
 	}
 
+	return trackers, total, nil
+}
+
+// totalCountScanner lets scanTracker read a row that carries the trailing
+// COUNT(*) OVER () column.
+type totalCountScanner struct {
+	rows  *sql.Rows
+	total *int
+}
+
+func (s totalCountScanner) Scan(dest ...any) error {
+	return s.rows.Scan(append(dest, s.total)...)
+}
+
+func (r *TrackerRepository) attachTrackerTags(ctx context.Context, profileID int64, trackers []models.Tracker) error {
 	if len(trackers) == 0 {
-		return trackers, nil
+		return nil
 	}
 
-	tagsByTracker, err := r.ListTagsByTrackerIDs(options.ProfileID, trackerIDs(trackers))
+	tagsByTracker, err := r.ListTagsByTrackerIDs(ctx, profileID, trackerIDs(trackers))
 	if err != nil {
-		return nil, fmt.Errorf("list tracker tags: %w", err)
+		return fmt.Errorf("list tracker tags: %w", err)
 	}
 	for index := range trackers {
 		trackers[index].Tags = tagsByTracker[trackers[index].ID]
 	}
 
-	return trackers, nil
+	return nil
+}
+
+// ListAfter returns up to options.Limit trackers following options.After
+// (or the first page when After is nil) and the cursor of the next page, which
+// is nil once the list is exhausted.
+func (r *TrackerRepository) ListAfter(ctx context.Context, options TrackerListOptions) ([]models.Tracker, *TrackerCursor, error) {
+	limit := options.Limit
+	if limit <= 0 {
+		return nil, nil, fmt.Errorf("list trackers after cursor: limit is required")
+	}
+	options.Limit = limit + 1
+	options.Offset = 0
+	valueCount := TrackerSortValueCount(options.SortBy)
+	if options.After != nil && len(options.After.SortValues) != valueCount {
+		return nil, nil, fmt.Errorf("list trackers after cursor: expected %d sort values, got %d", valueCount, len(options.After.SortValues))
+	}
+	query, args := buildTrackerSelect(options, trackerSortValueColumns(options.SortBy))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list trackers after cursor: %w", err)
+	}
+	defer rows.Close()
+
+	trackers := make([]models.Tracker, 0, limit)
+	sortValues := make([][]any, 0, limit)
+	for rows.Next() {
+		values := make([]any, valueCount)
+		tracker, err := scanTracker(sortValueScanner{rows: rows, values: values})
+		if err != nil {
+			return nil, nil, fmt.Errorf("scan tracker row after cursor: %w", err)
+		}
+		trackers = append(trackers, *tracker)
+		sortValues = append(sortValues, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate tracker rows after cursor: %w", err)
+	}
+
+	var next *TrackerCursor
+	if len(trackers) > limit {
+		trackers = trackers[:limit]
+		next = &TrackerCursor{SortValues: sortValues[limit-1], ID: trackers[limit-1].ID}
+	}
+
+	if err := r.attachTrackerTags(ctx, options.ProfileID, trackers); err != nil {
+		return nil, nil, err
+	}
+
+	return trackers, next, nil
+}
+
+// sortValueScanner lets scanTracker read a row that carries the trailing
+// sort_value columns of a keyset query.
+type sortValueScanner struct {
+	rows   *sql.Rows
+	values []any
+}
+
+func (s sortValueScanner) Scan(dest ...any) error {
+	for index := range s.values {
+		dest = append(dest, &s.values[index])
+	}
+	return s.rows.Scan(dest...)
 }
 
-func (r *TrackerRepository) Count(options TrackerListOptions) (int, error) {
+// trackerSortKey is one ORDER BY term. A key with a direction keeps it
+// whichever order was requested; one without follows the requested order.
+type trackerSortKey struct {
+	expr      string
+	direction string
+}
+
+// latestReleaseSortExpr orders by when the latest chapter came out. A release
+// time still in the future, as some sources give a chapter before it is out,
+// does not count; such a tracker sorts as though its release time were
+// unknown, with the ones whose chapter is unknown too, rather than first.
+// Only the first 19 characters are compared: julianday does not read the
+// nanoseconds the driver writes.
+const latestReleaseSortExpr = "CASE WHEN latest_known_chapter IS NULL OR julianday(substr(latest_release_at, 1, 19)) > julianday('now') THEN NULL ELSE COALESCE(latest_release_at, last_checked_at, updated_at, created_at) END"
+
+// trackerSortFields lists each sort's ORDER BY terms. After the primary term
+// come tiebreakers that read sensibly for that sort, since a poll run leaves
+// many trackers with the same timestamps; id DESC always ends the order, so
+// the OFFSET and keyset paths page identically.
+var trackerSortFields = map[string][]trackerSortKey{
+	"title":           {{expr: "title"}},
+	"created_at":      {{expr: "created_at"}},
+	"updated_at":      {{expr: "updated_at"}, {expr: "title", direction: "ASC"}},
+	"last_read_at":    {{expr: "last_read_at"}, {expr: "title", direction: "ASC"}},
+	"last_checked_at": {{expr: "last_checked_at"}, {expr: "title", direction: "ASC"}},
+	"rating":          {{expr: "rating"}, {expr: "title", direction: "ASC"}},
+	"latest_known_chapter": {
+		{expr: latestReleaseSortExpr},
+		{expr: "latest_known_chapter", direction: "DESC"},
+		{expr: "title", direction: "ASC"},
+	},
+}
+
+// NormalizeTrackerSort maps a requested sort field and order onto the ones
+// List actually applies, so callers can tell which sort a page used.
+func NormalizeTrackerSort(sortBy string, order string) (string, string) {
+	if _, ok := trackerSortFields[sortBy]; !ok {
+		sortBy = "latest_known_chapter"
+	}
+	order = strings.ToLower(order)
+	if order != "asc" && order != "desc" {
+		order = "desc"
+	}
+	return sortBy, order
+}
+
+// TrackerSortValueCount is how many sort values a TrackerCursor for sortBy
+// carries, one per ORDER BY term before the id.
+func TrackerSortValueCount(sortBy string) int {
+	sortBy, _ = NormalizeTrackerSort(sortBy, "")
+	return len(trackerSortFields[sortBy])
+}
+
+// trackerSortValueColumns selects each sort expression with a unary plus,
+// which leaves the value untouched but drops the column's declared type, so
+// timestamps come back as the stored text and compare exactly against it.
+func trackerSortValueColumns(sortBy string) string {
+	sortBy, _ = NormalizeTrackerSort(sortBy, "")
+	columns := ""
+	for index, key := range trackerSortFields[sortBy] {
+		columns += fmt.Sprintf(`, +(%s) AS sort_value_%d`, key.expr, index)
+	}
+	return columns
+}
+
+// trackerOrderBy resolves each sort key's direction for the requested order.
+func trackerOrderBy(keys []trackerSortKey, order string) []trackerSortKey {
+	resolved := make([]trackerSortKey, len(keys))
+	for index, key := range keys {
+		if key.direction == "" {
+			key.direction = order
+		}
+		resolved[index] = key
+	}
+	return resolved
+}
+
+func buildTrackerListQuery(options TrackerListOptions, withTotal bool) (string, []any) {
+	extraColumns := ""
+	if withTotal {
+		extraColumns = `, COUNT(*) OVER () AS total_count`
+	}
+	return buildTrackerSelect(options, extraColumns)
+}
+
+func buildTrackerSelect(options TrackerListOptions, extraColumns string) (string, []any) {
+	sortBy, order := NormalizeTrackerSort(options.SortBy, options.Order)
+	sortKeys := trackerOrderBy(trackerSortFields[sortBy], strings.ToUpper(order))
+
+	query := `
+		SELECT
+			id, profile_id, title, related_titles, source_id, source_item_id, source_url, status,
+			last_read_chapter, rating, last_read_at, latest_known_chapter, latest_release_at, last_checked_at,
+			first_read_at, caught_up_at, resolve_failure, last_poll_error, last_poll_error_at, continued_by_tracker_id,
+			source_genres, rereading, reread_count, previous_completions, next_scheduled_chapter, next_scheduled_at, created_at, updated_at
+	`
+	query += extraColumns
+	query += `
+		FROM trackers
+	`
+
+	whereClauses, args := buildTrackerListFilters(options)
+	if options.After != nil {
+		clause, cursorArgs := trackerCursorClause(sortKeys, *options.After)
+		whereClauses = append(whereClauses, clause)
+		args = append(args, cursorArgs...)
+	}
+
+	if len(whereClauses) > 0 {
+		query += ` WHERE ` + strings.Join(whereClauses, " AND ")
+	}
+
+	orderTerms := make([]string, 0, len(sortKeys)+1)
+	for _, key := range sortKeys {
+		orderTerms = append(orderTerms, key.expr+` `+key.direction)
+	}
+	query += ` ORDER BY ` + strings.Join(append(orderTerms, `id DESC`), ", ")
+
+	if options.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, options.Limit)
+		if options.Offset > 0 && options.After == nil {
+			query += ` OFFSET ?`
+			args = append(args, options.Offset)
+		}
+	}
+
+	return query, args
+}
+
+// trackerCursorClause matches the rows ordered after cursor by sortKeys and
+// then id DESC: rows equal on the first keys and after the cursor on the next
+// one, for each key in turn, and finally rows equal on every key with a lower
+// id. SQLite sorts NULLs first ascending and last descending, so NULL values
+// need their own comparisons either way.
+func trackerCursorClause(sortKeys []trackerSortKey, cursor TrackerCursor) (string, []any) {
+	branches := make([]string, 0, len(sortKeys)+1)
+	args := make([]any, 0)
+	equalTerms := make([]string, 0, len(sortKeys))
+	equalArgs := make([]any, 0, len(sortKeys))
+
+	for index, key := range sortKeys {
+		field := `(` + key.expr + `)`
+		value := cursor.SortValues[index]
+
+		after, afterArgs := sortKeyAfter(field, key.direction, value)
+		if after != "" {
+			branches = append(branches, `(`+strings.Join(append(append([]string{}, equalTerms...), after), " AND ")+`)`)
+			args = append(append(args, equalArgs...), afterArgs...)
+		}
+
+		if value == nil {
+			equalTerms = append(equalTerms, field+` IS NULL`)
+		} else {
+			equalTerms = append(equalTerms, field+` = ?`)
+			equalArgs = append(equalArgs, value)
+		}
+	}
+
+	branches = append(branches, `(`+strings.Join(append(equalTerms, `id < ?`), " AND ")+`)`)
+	args = append(append(args, equalArgs...), cursor.ID)
+	return `(` + strings.Join(branches, " OR ") + `)`, args
+}
+
+// sortKeyAfter matches values ordered strictly after value in direction, or
+// returns "" when nothing can be (a NULL is last when descending).
+func sortKeyAfter(field string, direction string, value any) (string, []any) {
+	if value == nil {
+		if direction == "ASC" {
+			return field + ` IS NOT NULL`, nil
+		}
+		return "", nil
+	}
+	if direction == "ASC" {
+		return field + ` > ?`, []any{value}
+	}
+	return `(` + field + ` < ? OR ` + field + ` IS NULL)`, []any{value}
+}
+
+func (r *TrackerRepository) Count(ctx context.Context, options TrackerListOptions) (int, error) {
 	query := `SELECT COUNT(1) FROM trackers`
 	whereClauses, args := buildTrackerListFilters(options)
 	if len(whereClauses) > 0 {
@@ -97,7 +383,7 @@ func (r *TrackerRepository) Count(options TrackerListOptions) (int, error) {
 	}
 
 	var total int
-	if err := r.db.QueryRow(query, args...).Scan(&total); err != nil {
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
 		return 0, fmt.Errorf("count trackers: %w", err)
 	}
 
@@ -105,26 +391,80 @@ func (r *TrackerRepository) Count(options TrackerListOptions) (int, error) {
 }
 
 func buildTrackerListFilters(options TrackerListOptions) ([]string, []any) {
-	args := make([]any, 0, 1)
-	whereClauses := make([]string, 0, 1)
+	clauses := trackerListClauses(options)
+	whereClauses := make([]string, 0, len(clauses))
+	args := make([]any, 0, len(clauses))
+	for _, clause := range clauses {
+		whereClauses = append(whereClauses, clause.sql)
+		args = append(args, clause.args...)
+	}
+	return whereClauses, args
+}
 
-	whereClauses = append(whereClauses, `profile_id = ?`)
-	args = append(args, options.ProfileID)
+// Names of the tracker list's filter clauses, as ExplainListMembership
+// reports them.
+const (
+	TrackerClauseProfile    = "profile"
+	TrackerClauseIDs        = "ids"
+	TrackerClauseQuery      = "query"
+	TrackerClauseStatus     = "status"
+	TrackerClauseCaughtUp   = "caught_up"
+	TrackerClauseSource     = "source"
+	TrackerClauseTag        = "tag"
+	TrackerClauseExcludeTag = "exclude_tag"
+)
+
+// trackerFilterClause is one condition of the tracker list's WHERE clause.
+// The list ANDs them; ExplainListMembership checks a tracker against each
+// one on its own. explain, when set, says why a tracker passed or failed.
+type trackerFilterClause struct {
+	name        string
+	description string
+	sql         string
+	args        []any
+	explain     func(facts trackerFacts, passed bool) string
+}
+
+// trackerListClauses turns options into the list's filter clauses, in the
+// order they are applied.
+func trackerListClauses(options TrackerListOptions) []trackerFilterClause {
+	clauses := make([]trackerFilterClause, 0, 4)
+
+	clauses = append(clauses, trackerFilterClause{
+		name:        TrackerClauseProfile,
+		description: fmt.Sprintf("belongs to profile %d", options.ProfileID),
+		sql:         `profile_id = ?`,
+		args:        []any{options.ProfileID},
+	})
+
+	if len(options.IDs) > 0 {
+		args := make([]any, 0, len(options.IDs))
+		for _, id := range options.IDs {
+			args = append(args, id)
+		}
+		clauses = append(clauses, trackerFilterClause{
+			name:        TrackerClauseIDs,
+			description: "is one of the requested trackers",
+			sql:         `trackers.id IN (` + sqlPlaceholders(len(options.IDs)) + `)`,
+			args:        args,
+		})
+	}
 
 	if strings.TrimSpace(options.Query) != "" {
 		normalizedQuery := searchutil.Normalize(options.Query)
 		if normalizedQuery != "" {
 			queryTokens := searchutil.TokenizeNormalized(normalizedQuery)
 			if len(queryTokens) == 0 {
-				whereClauses = append(whereClauses, `(LOWER(trackers.title) LIKE ? OR LOWER(COALESCE(trackers.related_titles, '')) LIKE ?)`)
-				queryLike := "%" + normalizedQuery + "%"
-				args = append(args, queryLike, queryLike)
-			} else {
-				for _, token := range queryTokens {
-					tokenLike := "%" + token + "%"
-					whereClauses = append(whereClauses, `(LOWER(trackers.title) LIKE ? OR LOWER(COALESCE(trackers.related_titles, '')) LIKE ?)`)
-					args = append(args, tokenLike, tokenLike)
-				}
+				queryTokens = []string{normalizedQuery}
+			}
+			for _, token := range queryTokens {
+				tokenLike := "%" + token + "%"
+				clauses = append(clauses, trackerFilterClause{
+					name:        TrackerClauseQuery,
+					description: fmt.Sprintf("title or related titles contain %q", token),
+					sql:         `(LOWER(trackers.title) LIKE ? OR LOWER(COALESCE(trackers.related_titles, '')) LIKE ?)`,
+					args:        []any{tokenLike, tokenLike},
+				})
 			}
 		}
 	}
@@ -151,15 +491,31 @@ func buildTrackerListFilters(options TrackerListOptions) ([]string, []any) {
 		}
 
 		if len(statuses) > 0 {
-			placeholders := sqlPlaceholders(len(statuses))
-			whereClauses = append(whereClauses, `status IN (`+placeholders+`)`)
+			args := make([]any, 0, len(statuses))
 			for _, status := range statuses {
 				args = append(args, status)
 			}
+			clauses = append(clauses, trackerFilterClause{
+				name:        TrackerClauseStatus,
+				description: "status is " + strings.Join(statuses, " or "),
+				sql:         `status IN (` + sqlPlaceholders(len(statuses)) + `)`,
+				args:        args,
+				explain: func(facts trackerFacts, _ bool) string {
+					return "status is " + facts.tracker.Status
+				},
+			})
 		}
 
+		// Reading hides caught-up trackers. A tracker continued by another
+		// one is finished once read at all when its page no longer reports
+		// a latest chapter, since new chapters land on the continuation.
 		if hasReading {
-			whereClauses = append(whereClauses, `(status <> 'reading' OR latest_known_chapter IS NULL OR last_read_chapter IS NULL OR last_read_chapter < latest_known_chapter)`)
+			clauses = append(clauses, trackerFilterClause{
+				name:        TrackerClauseCaughtUp,
+				description: "reading trackers are hidden once caught up",
+				sql:         `(status <> 'reading' OR last_read_chapter IS NULL OR (latest_known_chapter IS NULL AND continued_by_tracker_id IS NULL) OR last_read_chapter < latest_known_chapter)`,
+				explain:     explainCaughtUp,
+			})
 		}
 	}
 
@@ -179,58 +535,100 @@ func buildTrackerListFilters(options TrackerListOptions) ([]string, []any) {
 
 		if len(filteredSourceIDs) > 0 {
 			placeholders := sqlPlaceholders(len(filteredSourceIDs))
-
-			whereClauses = append(whereClauses, `(trackers.source_id IN (`+placeholders+`) OR EXISTS (
-				SELECT 1
-				FROM tracker_sources ts
-				WHERE ts.tracker_id = trackers.id
-				  AND ts.source_id IN (`+placeholders+`)
-			))`)
+			args := make([]any, 0, 2*len(filteredSourceIDs))
 			for _, sourceID := range filteredSourceIDs {
 				args = append(args, sourceID)
 			}
 			for _, sourceID := range filteredSourceIDs {
 				args = append(args, sourceID)
 			}
+			clauses = append(clauses, trackerFilterClause{
+				name:        TrackerClauseSource,
+				description: "on site " + joinInt64s(filteredSourceIDs, " or ") + ", as the primary or a linked source",
+				sql: `(trackers.source_id IN (` + placeholders + `) OR EXISTS (
+				SELECT 1
+				FROM tracker_sources ts
+				WHERE ts.tracker_id = trackers.id
+				  AND ts.source_id IN (` + placeholders + `)
+			))`,
+				args:    args,
+				explain: explainSources,
+			})
 		}
 	}
 
-	if len(options.TagNames) > 0 {
-		seenTagNames := make(map[string]struct{}, len(options.TagNames))
-		for _, tagName := range options.TagNames {
-			normalized := strings.TrimSpace(strings.ToLower(tagName))
-			if normalized == "" {
-				continue
-			}
-			if _, exists := seenTagNames[normalized]; exists {
-				continue
-			}
-			seenTagNames[normalized] = struct{}{}
+	for _, filter := range options.TagFilters {
+		names := normalizedTagNames(filter.AnyOf)
+		if len(names) == 0 {
+			continue
+		}
 
-			whereClauses = append(whereClauses, `EXISTS (
-				SELECT 1
-				FROM tracker_tags tt
-				INNER JOIN custom_tags ct ON ct.id = tt.tag_id
-				WHERE tt.tracker_id = trackers.id
-				  AND ct.profile_id = ?
-				  AND LOWER(ct.name) = ?
-			)`)
-			args = append(args, options.ProfileID, normalized)
+		sql := `EXISTS (
+			SELECT 1
+			FROM tracker_tags tt
+			INNER JOIN custom_tags ct ON ct.id = tt.tag_id
+			WHERE tt.tracker_id = trackers.id
+			  AND ct.profile_id = ?
+			  AND ct.name_normalized IN (` + sqlPlaceholders(len(names)) + `)
+		)`
+		args := make([]any, 0, len(names)+1)
+		args = append(args, options.ProfileID)
+		for _, name := range names {
+			args = append(args, name)
 		}
+		clause := trackerFilterClause{
+			name:        TrackerClauseTag,
+			description: "tagged " + strings.Join(names, " or "),
+			sql:         sql,
+			args:        args,
+			explain:     explainTags(names),
+		}
+		if filter.Exclude {
+			clause.name = TrackerClauseExcludeTag
+			clause.description = "not tagged " + strings.Join(names, " or ")
+			clause.sql = "NOT " + sql
+		}
+		clauses = append(clauses, clause)
 	}
 
-	return whereClauses, args
+	return clauses
+}
+
+// normalizedTagNames normalizes names as custom_tags.name_normalized is,
+// dropping blanks and duplicates.
+func normalizedTagNames(names []string) []string {
+	out := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		normalized := searchutil.NormalizeTagName(name)
+		if normalized == "" {
+			continue
+		}
+		if _, exists := seen[normalized]; exists {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		out = append(out, normalized)
+	}
+	return out
 }
 
-func (r *TrackerRepository) ListForPolling() ([]PollingTracker, error) {
+// ListForPolling returns every tracker the poller may check, leaving out
+// profiles with polling turned off. Trackers that were never checked come
+// first, so a new tracker whose lookup failed gets its chapters at the
+// start of the next cycle.
+func (r *TrackerRepository) ListForPolling(ctx context.Context) ([]PollingTracker, error) {
 	query := `
 		SELECT
-			t.id, t.title, t.status, t.source_id, t.source_item_id, t.source_url, t.latest_known_chapter, s.key, t.last_checked_at
+			t.id, t.title, t.status, t.source_id, t.source_item_id, t.source_url, t.latest_known_chapter, s.key, t.last_checked_at, t.next_scheduled_chapter
 		FROM trackers t
 		INNER JOIN sources s ON s.id = t.source_id
+		INNER JOIN profiles p ON p.id = t.profile_id
+		WHERE p.polling_enabled = 1
+		ORDER BY (t.last_checked_at IS NULL) DESC, t.id ASC
 	`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("list trackers for polling: %w", err)
 	}
@@ -242,7 +640,8 @@ func (r *TrackerRepository) ListForPolling() ([]PollingTracker, error) {
 		var sourceItemID sql.NullString
 		var latest sql.NullFloat64
 		var lastCheckedAt sql.NullTime
-		if err := rows.Scan(&item.ID, &item.Title, &item.Status, &item.SourceID, &sourceItemID, &item.SourceURL, &latest, &item.SourceKey, &lastCheckedAt); err != nil {
+		var nextScheduled sql.NullFloat64
+		if err := rows.Scan(&item.ID, &item.Title, &item.Status, &item.SourceID, &sourceItemID, &item.SourceURL, &latest, &item.SourceKey, &lastCheckedAt, &nextScheduled); err != nil {
 			return nil, fmt.Errorf("scan polling tracker: %w", err)
 		}
 		if sourceItemID.Valid {
@@ -255,6 +654,9 @@ func (r *TrackerRepository) ListForPolling() ([]PollingTracker, error) {
 			checkedAt := lastCheckedAt.Time.UTC()
 			item.LastCheckedAt = &checkedAt
 		}
+		if nextScheduled.Valid {
+			item.NextScheduledChapter = &nextScheduled.Float64
+		}
 		items = append(items, item)
 	}
 
@@ -262,10 +664,69 @@ func (r *TrackerRepository) ListForPolling() ([]PollingTracker, error) {
 		return nil, fmt.Errorf("iterate polling trackers: %w", err)
 	}
 
+	if err := r.attachPollingTrackerSources(ctx, items); err != nil {
+		return nil, err
+	}
+
 	return items, nil
 }
 
-func (r *TrackerRepository) UpdatePollingState(id int64, sourceID int64, currentSourceURL string, sourceItemID *string, sourceURL string, latestKnownChapter *float64, latestReleaseAt *time.Time, clearLatestReleaseAt bool, checkedAt time.Time) error {
+func (r *TrackerRepository) attachPollingTrackerSources(ctx context.Context, items []PollingTracker) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	indexByID := make(map[int64]int, len(items))
+	for index, item := range items {
+		indexByID[item.ID] = index
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ts.tracker_id, ts.source_id, s.key, ts.source_url, ts.lang, ts.chapter_offset
+		FROM tracker_sources ts
+		INNER JOIN sources s ON s.id = ts.source_id
+		ORDER BY ts.tracker_id ASC, ts.position ASC, ts.id ASC
+	`)
+	if err != nil {
+		return fmt.Errorf("list polling tracker sources: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var trackerID int64
+		var source PollingTrackerSource
+		if err := rows.Scan(&trackerID, &source.SourceID, &source.SourceKey, &source.SourceURL, &source.Lang, &source.ChapterOffset); err != nil {
+			return fmt.Errorf("scan polling tracker source: %w", err)
+		}
+		index, ok := indexByID[trackerID]
+		if !ok {
+			continue
+		}
+		item := &items[index]
+		if item.SourceLang == "" && source.SourceID == item.SourceID && strings.EqualFold(strings.TrimSpace(source.SourceURL), strings.TrimSpace(item.SourceURL)) {
+			item.SourceLang = source.Lang
+		}
+		item.LinkedSources = append(item.LinkedSources, source)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate polling tracker sources: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePollingState stores what a poll found. The tracker row, its release
+// history and its linked source are written in one transaction, so a card
+// reloaded while the poller writes never shows half of a poll. A primary URL
+// the source moved to a new canonical one goes to the URL history.
+func (r *TrackerRepository) UpdatePollingState(ctx context.Context, id int64, sourceID int64, currentSourceURL string, sourceItemID *string, sourceURL string, latestKnownChapter *float64, latestReleaseAt *time.Time, clearLatestReleaseAt bool, checkedAt time.Time) error {
+	return r.WithTx(ctx, func(txRepo *TrackerRepository) error {
+		return txRepo.updatePollingState(ctx, id, sourceID, currentSourceURL, sourceItemID, sourceURL, latestKnownChapter, latestReleaseAt, clearLatestReleaseAt, checkedAt)
+	})
+}
+
+func (r *TrackerRepository) updatePollingState(ctx context.Context, id int64, sourceID int64, currentSourceURL string, sourceItemID *string, sourceURL string, latestKnownChapter *float64, latestReleaseAt *time.Time, clearLatestReleaseAt bool, checkedAt time.Time) error {
 	var latestReleaseValue any
 	if latestReleaseAt != nil {
 		latestReleaseValue = latestReleaseAt.UTC()
@@ -284,7 +745,7 @@ func (r *TrackerRepository) UpdatePollingState(id int64, sourceID int64, current
 		}
 	}
 
-	_, err := r.db.Exec(`
+	_, err := r.db.ExecContext(ctx, `
 		UPDATE trackers
 		SET source_item_id = COALESCE(?, source_item_id),
 			source_url = COALESCE(?, source_url),
@@ -294,16 +755,29 @@ func (r *TrackerRepository) UpdatePollingState(id int64, sourceID int64, current
 				WHEN ? IS NOT NULL THEN ?
 				ELSE latest_release_at
 			END,
-			last_checked_at = ?, updated_at = CURRENT_TIMESTAMP
+			last_checked_at = ?, resolve_failure = NULL, last_poll_error = NULL, last_poll_error_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`, sourceItemIDValue, sourceURLValue, latestKnownChapter, clearLatestReleaseAt, latestReleaseValue, latestReleaseValue, checkedAt.UTC(), id)
 	if err != nil {
 		return fmt.Errorf("update polling state: %w", err)
 	}
+	if !clearLatestReleaseAt {
+		if err := r.recordChapterRelease(ctx, id, latestKnownChapter, latestReleaseAt); err != nil {
+			return err
+		}
+	}
 
 	if sourceID > 0 && trimmedSourceURL != "" {
+		var movedLang string
 		if trimmedCurrentSourceURL != "" && !strings.EqualFold(trimmedCurrentSourceURL, trimmedSourceURL) {
-			if _, err := r.db.Exec(`
+			if err := recordReplacedURL(ctx, r.db, id, sourceID, trimmedCurrentSourceURL, trimmedSourceURL, URLHistorySlugRotation); err != nil {
+				return err
+			}
+			if movedLang, err = r.trackerSourceLangAt(ctx, id, sourceID, trimmedCurrentSourceURL); err != nil {
+				return err
+			}
+			if _, err := r.db.ExecContext(ctx, `
 				DELETE FROM tracker_sources
 				WHERE tracker_id = ? AND source_id = ? AND LOWER(source_url) = LOWER(?)
 			`, id, sourceID, trimmedCurrentSourceURL); err != nil {
@@ -311,16 +785,120 @@ func (r *TrackerRepository) UpdatePollingState(id int64, sourceID int64, current
 			}
 		}
 
-		if _, err := r.db.Exec(`
-			INSERT INTO tracker_sources (tracker_id, source_id, source_item_id, source_url)
-			VALUES (?, ?, ?, ?)
+		if _, err := r.db.ExecContext(ctx, `
+			INSERT INTO tracker_sources (tracker_id, source_id, source_item_id, source_url, lang, position)
+			VALUES (?, ?, ?, ?, ?, `+nextTrackerSourcePosition+`)
 			ON CONFLICT(tracker_id, source_id, source_url)
 			DO UPDATE SET
 				source_item_id = excluded.source_item_id,
 				updated_at = CURRENT_TIMESTAMP
-		`, id, sourceID, sourceItemID, trimmedSourceURL); err != nil {
+		`, id, sourceID, sourceItemID, trimmedSourceURL, trackerSourceLang(movedLang), id); err != nil {
 			return fmt.Errorf("upsert polling tracker source: %w", err)
 		}
 	}
 	return nil
 }
+
+// MigrateSourceURL rewrites one stored source URL of a tracker, on its
+// primary source and its tracker_sources row alike. A linked row already
+// stored at newURL wins over the old one. oldURL goes to the tracker's URL
+// history.
+func (r *TrackerRepository) MigrateSourceURL(ctx context.Context, trackerID int64, sourceID int64, oldURL string, newURL string) error {
+	oldURL = strings.TrimSpace(oldURL)
+	newURL = strings.TrimSpace(newURL)
+	if oldURL == "" || newURL == "" || oldURL == newURL {
+		return nil
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin migrate source url tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// updated_at is left alone: only where the tracker is polled from moved.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE trackers SET source_url = ? WHERE id = ? AND source_id = ? AND source_url = ?
+	`, newURL, trackerID, sourceID, oldURL); err != nil {
+		return fmt.Errorf("migrate tracker source url: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE OR IGNORE tracker_sources SET source_url = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE tracker_id = ? AND source_id = ? AND source_url = ?
+	`, newURL, trackerID, sourceID, oldURL); err != nil {
+		return fmt.Errorf("migrate linked source url: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM tracker_sources WHERE tracker_id = ? AND source_id = ? AND source_url = ?
+	`, trackerID, sourceID, oldURL); err != nil {
+		return fmt.Errorf("delete migrated linked source: %w", err)
+	}
+	if err := recordReplacedURL(ctx, tx, trackerID, sourceID, oldURL, newURL, URLHistoryDomainMigration); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migrate source url tx: %w", err)
+	}
+	return nil
+}
+
+// maxPollErrorLength bounds the stored poll error; connector errors can carry
+// whole response bodies.
+const maxPollErrorLength = 500
+
+// SetPollError records why polling a tracker's source failed at failedAt.
+// UpdatePollingState clears it on the next successful poll.
+func (r *TrackerRepository) SetPollError(ctx context.Context, id int64, message string, failedAt time.Time) error {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		message = "unknown error"
+	}
+	if len(message) > maxPollErrorLength {
+		message = strings.ToValidUTF8(message[:maxPollErrorLength], "") + "…"
+	}
+
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE trackers
+		SET last_poll_error = ?, last_poll_error_at = ?
+		WHERE id = ?
+	`, message, failedAt.UTC(), id); err != nil {
+		return fmt.Errorf("set poll error: %w", err)
+	}
+	return nil
+}
+
+// SetSourceGenres stores the genres a poll of the tracker's primary source
+// listed. No genres leaves the stored ones as they are.
+func (r *TrackerRepository) SetSourceGenres(ctx context.Context, id int64, genres []string) error {
+	encoded := encodeSourceGenresJSON(genres)
+	if encoded == nil {
+		return nil
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE trackers
+		SET source_genres = ?
+		WHERE id = ?
+	`, *encoded, id); err != nil {
+		return fmt.Errorf("set source genres: %w", err)
+	}
+	return nil
+}
+
+// SetScheduledChapter stores the next chapter a poll of the tracker's
+// primary source found announced but not yet released, and when it is out.
+// A nil chapter clears it.
+func (r *TrackerRepository) SetScheduledChapter(ctx context.Context, id int64, chapter *float64, scheduledAt *time.Time) error {
+	var scheduledAtValue any
+	if chapter != nil && scheduledAt != nil {
+		scheduledAtValue = scheduledAt.UTC()
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE trackers
+		SET next_scheduled_chapter = ?, next_scheduled_at = ?
+		WHERE id = ?
+	`, chapter, scheduledAtValue, id); err != nil {
+		return fmt.Errorf("set scheduled chapter: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,455 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"modernc.org/sqlite"
+)
+
+var (
+	registerCountingDriver sync.Once
+	trackerQueryCount      atomic.Int64
+)
+
+// countingDriver wraps the sqlite driver and counts prepared statements that
+// read from the trackers table, so tests can assert how many passes a
+// repository call makes.
+type countingDriver struct {
+	base sqlite.Driver
+}
+
+func (d *countingDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn}, nil
+}
+
+type countingConn struct {
+	driver.Conn
+}
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.Contains(query, "FROM trackers") {
+		trackerQueryCount.Add(1)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func setupListingTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	registerCountingDriver.Do(func() {
+		sql.Register("sqlite-counting", &countingDriver{})
+	})
+
+	db, err := sql.Open("sqlite-counting", filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	if _, err := db.Exec(`PRAGMA foreign_keys = ON;`); err != nil {
+		t.Fatalf("enable foreign keys: %v", err)
+	}
+
+	_, currentFile, _, _ := runtime.Caller(0)
+	migrationsPath := filepath.Join(filepath.Dir(currentFile), "..", "..", "migrations")
+	if err := database.ApplyMigrations(db, migrationsPath); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter, rating)
+		VALUES
+			(1, 'Alpha Blade', 1, 'https://mangadex.org/title/alpha', 'reading', 10, 12, 8),
+			(1, 'Beta Blade', 2, 'https://mangafire.to/manga/beta', 'reading', 12, 12, 7),
+			(1, 'Gamma Tower', 1, 'https://mangadex.org/title/gamma', 'completed', 50, 50, 9),
+			(1, 'Delta Tower', 3, 'https://asuracomic.net/series/delta', 'on_hold', 3, 20, NULL),
+			(1, 'Epsilon Blade', 2, 'https://mangafire.to/manga/epsilon', 'reading', NULL, 5, 6),
+			(2, 'Other Profile Blade', 1, 'https://mangadex.org/title/other', 'reading', 1, 2, 5)
+	`)
+	if err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_url)
+		SELECT id, 3, source_url || '/linked' FROM trackers WHERE title = 'Alpha Blade'
+	`)
+	if err != nil {
+		t.Fatalf("seed linked sources: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, 'favorite', 'favorite'), (1, 'action', 'action')`)
+	if err != nil {
+		t.Fatalf("seed tags: %v", err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO tracker_tags (tracker_id, tag_id)
+		SELECT t.id, ct.id
+		FROM trackers t, custom_tags ct
+		WHERE (t.title IN ('Alpha Blade', 'Gamma Tower') AND ct.name = 'favorite')
+		   OR (t.title IN ('Alpha Blade', 'Delta Tower', 'Epsilon Blade') AND ct.name = 'action')
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker tags: %v", err)
+	}
+
+	return db
+}
+
+func TestListWithTotal_UsesSingleTrackerQuery(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+
+	trackerQueryCount.Store(0)
+	items, total, err := repo.ListWithTotal(context.Background(), TrackerListOptions{ProfileID: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("list with total: %v", err)
+	}
+
+	if got := trackerQueryCount.Load(); got != 1 {
+		t.Fatalf("expected 1 trackers query, got %d", got)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	if total != 5 {
+		t.Fatalf("expected total 5, got %d", total)
+	}
+}
+
+func TestListWithTotal_MatchesListAndCount(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+
+	cases := []TrackerListOptions{
+		{ProfileID: 1},
+		{ProfileID: 1, Limit: 2},
+		{ProfileID: 1, Limit: 2, Offset: 2},
+		{ProfileID: 1, Limit: 2, Offset: 10},
+		{ProfileID: 1, Statuses: []string{"reading"}},
+		{ProfileID: 1, Statuses: []string{"completed", "on_hold"}, SortBy: "title", Order: "asc"},
+		{ProfileID: 1, TagFilters: []TagFilter{{AnyOf: []string{"action"}}}, SortBy: "rating"},
+		{ProfileID: 1, TagFilters: []TagFilter{{AnyOf: []string{"favorite"}}, {AnyOf: []string{"action"}}}},
+		{ProfileID: 1, SourceIDs: []int64{3}},
+		{ProfileID: 1, Query: "blade", Limit: 1, Offset: 1},
+		{ProfileID: 1, Query: "missing"},
+		{ProfileID: 2},
+	}
+
+	for _, options := range cases {
+		expectedItems, err := repo.List(context.Background(), options)
+		if err != nil {
+			t.Fatalf("list %+v: %v", options, err)
+		}
+		expectedTotal, err := repo.Count(context.Background(), options)
+		if err != nil {
+			t.Fatalf("count %+v: %v", options, err)
+		}
+
+		items, total, err := repo.ListWithTotal(context.Background(), options)
+		if err != nil {
+			t.Fatalf("list with total %+v: %v", options, err)
+		}
+
+		if total != expectedTotal {
+			t.Fatalf("options %+v: expected total %d, got %d", options, expectedTotal, total)
+		}
+		if len(items) != len(expectedItems) {
+			t.Fatalf("options %+v: expected %d items, got %d", options, len(expectedItems), len(items))
+		}
+		for index := range items {
+			if items[index].ID != expectedItems[index].ID {
+				t.Fatalf("options %+v: item %d expected id %d, got %d", options, index, expectedItems[index].ID, items[index].ID)
+			}
+			if len(items[index].Tags) != len(expectedItems[index].Tags) {
+				t.Fatalf("options %+v: item %d expected %d tags, got %d", options, index, len(expectedItems[index].Tags), len(items[index].Tags))
+			}
+		}
+	}
+}
+
+func TestListAfter_PagesMatchListForEverySort(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+
+	// Extra rows tie with the seeded ones on title, rating and timestamps, and
+	// leave sort columns NULL, so every page boundary leans on the id
+	// tiebreaker or the NULL branches.
+	_, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter, rating, last_read_at, last_checked_at)
+		VALUES
+			(1, 'Alpha Blade', 1, 'https://mangadex.org/title/alpha-2', 'reading', NULL, 7, '2025-01-02 10:00:00', '2025-01-03 10:00:00'),
+			(1, 'Zeta Blade', 1, 'https://mangadex.org/title/zeta', 'reading', 9, NULL, '2025-01-02 10:00:00', NULL),
+			(1, 'Eta Blade', 1, 'https://mangadex.org/title/eta', 'reading', NULL, 7, NULL, '2025-01-03 10:00:00')
+	`)
+	if err != nil {
+		t.Fatalf("seed tie rows: %v", err)
+	}
+
+	sorts := []string{"title", "created_at", "updated_at", "last_read_at", "last_checked_at", "rating", "latest_known_chapter"}
+	for _, sortBy := range sorts {
+		for _, order := range []string{"asc", "desc"} {
+			options := TrackerListOptions{ProfileID: 1, SortBy: sortBy, Order: order}
+			expected, err := repo.List(context.Background(), options)
+			if err != nil {
+				t.Fatalf("list %s %s: %v", sortBy, order, err)
+			}
+
+			got := make([]int64, 0, len(expected))
+			pageOptions := options
+			pageOptions.Limit = 2
+			for pages := 0; ; pages++ {
+				if pages > len(expected) {
+					t.Fatalf("%s %s: cursor pages did not terminate", sortBy, order)
+				}
+				items, next, err := repo.ListAfter(context.Background(), pageOptions)
+				if err != nil {
+					t.Fatalf("list after %s %s: %v", sortBy, order, err)
+				}
+				for _, item := range items {
+					got = append(got, item.ID)
+				}
+				if next == nil {
+					break
+				}
+				pageOptions.After = next
+			}
+
+			if len(got) != len(expected) {
+				t.Fatalf("%s %s: expected %d trackers across pages, got %d (%v)", sortBy, order, len(expected), len(got), got)
+			}
+			for index := range got {
+				if got[index] != expected[index].ID {
+					t.Fatalf("%s %s: position %d expected id %d, got %d (pages %v)", sortBy, order, index, expected[index].ID, got[index], got)
+				}
+			}
+		}
+	}
+}
+
+func TestListAfter_RespectsFilters(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+
+	items, next, err := repo.ListAfter(context.Background(), TrackerListOptions{ProfileID: 1, Query: "blade", SortBy: "title", Order: "asc", Limit: 2})
+	if err != nil {
+		t.Fatalf("list after: %v", err)
+	}
+	if len(items) != 2 || items[0].Title != "Alpha Blade" || items[1].Title != "Beta Blade" || next == nil {
+		t.Fatalf("unexpected first page %+v next=%v", items, next)
+	}
+
+	items, next, err = repo.ListAfter(context.Background(), TrackerListOptions{ProfileID: 1, Query: "blade", SortBy: "title", Order: "asc", Limit: 2, After: next})
+	if err != nil {
+		t.Fatalf("list after cursor: %v", err)
+	}
+	if len(items) != 1 || items[0].Title != "Epsilon Blade" || next != nil {
+		t.Fatalf("unexpected last page %+v next=%v", items, next)
+	}
+}
+
+// seedPollTies adds trackers to profile 2 that one poll run checked in the
+// same second, so they tie on last_checked_at and on the release sort.
+func seedPollTies(t *testing.T, db *sql.DB, count int) {
+	t.Helper()
+	titles := []string{"Tie Crimson", "Tie Amber", "Tie Blue", "Tie Amber"}
+	for index := 0; index < count; index++ {
+		var latest any
+		if index%5 != 4 {
+			latest = 10 + index%3
+		}
+		var rating any
+		if index%2 == 0 {
+			rating = 7
+		}
+		_, err := db.Exec(`
+			INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter, rating, last_read_at, last_checked_at)
+			VALUES (2, ?, 1, ?, 'reading', ?, ?, '2025-02-01 08:00:00', '2025-02-01 09:00:00')
+		`, titles[index%len(titles)], fmt.Sprintf("https://mangadex.org/title/tie-%d", index), latest, rating)
+		if err != nil {
+			t.Fatalf("seed tie tracker %d: %v", index, err)
+		}
+	}
+}
+
+func TestListPagesDoNotOverlapAcrossTies(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	const count = 25
+	seedPollTies(t, db, count)
+	pageSize := (count + 1) / 2
+
+	sorts := []string{"title", "created_at", "updated_at", "last_read_at", "last_checked_at", "rating", "latest_known_chapter"}
+	for _, sortBy := range sorts {
+		for _, order := range []string{"asc", "desc"} {
+			options := TrackerListOptions{ProfileID: 2, Query: "tie", SortBy: sortBy, Order: order, Limit: pageSize}
+
+			offsetIDs := make([]int64, 0, count)
+			for page := 0; page < 2; page++ {
+				pageOptions := options
+				pageOptions.Offset = page * pageSize
+				items, err := repo.List(context.Background(), pageOptions)
+				if err != nil {
+					t.Fatalf("list %s %s page %d: %v", sortBy, order, page+1, err)
+				}
+				offsetIDs = append(offsetIDs, trackerIDs(items)...)
+			}
+
+			first, next, err := repo.ListAfter(context.Background(), options)
+			if err != nil || next == nil {
+				t.Fatalf("list after %s %s: %v next=%v", sortBy, order, err, next)
+			}
+			options.After = next
+			second, last, err := repo.ListAfter(context.Background(), options)
+			if err != nil || last != nil {
+				t.Fatalf("list after cursor %s %s: %v next=%v", sortBy, order, err, last)
+			}
+			keysetIDs := append(trackerIDs(first), trackerIDs(second)...)
+
+			seen := make(map[int64]bool, count)
+			for _, id := range offsetIDs {
+				if seen[id] {
+					t.Fatalf("%s %s: tracker %d shows up on both pages (%v)", sortBy, order, id, offsetIDs)
+				}
+				seen[id] = true
+			}
+			if len(seen) != count {
+				t.Fatalf("%s %s: expected pages to cover %d trackers, got %d", sortBy, order, count, len(seen))
+			}
+			if len(keysetIDs) != len(offsetIDs) {
+				t.Fatalf("%s %s: keyset pages returned %d trackers, offset pages %d", sortBy, order, len(keysetIDs), len(offsetIDs))
+			}
+			for index := range offsetIDs {
+				if keysetIDs[index] != offsetIDs[index] {
+					t.Fatalf("%s %s: position %d keyset id %d, offset id %d", sortBy, order, index, keysetIDs[index], offsetIDs[index])
+				}
+			}
+		}
+	}
+}
+
+func TestReleaseSortBreaksTiesByChapterThenTitle(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	seedPollTies(t, db, 8)
+
+	items, err := repo.List(context.Background(), TrackerListOptions{ProfileID: 2, Query: "tie", SortBy: "latest_known_chapter", Order: "desc"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for index := 1; index < len(items); index++ {
+		previous, current := items[index-1], items[index]
+		if previous.LatestKnownChapter == nil || current.LatestKnownChapter == nil {
+			continue
+		}
+		if *previous.LatestKnownChapter < *current.LatestKnownChapter {
+			t.Fatalf("expected higher chapters first among ties, got %v before %v", *previous.LatestKnownChapter, *current.LatestKnownChapter)
+		}
+		if *previous.LatestKnownChapter == *current.LatestKnownChapter && previous.Title > current.Title {
+			t.Fatalf("expected titles ascending within chapter %v, got %q before %q", *current.LatestKnownChapter, previous.Title, current.Title)
+		}
+		if previous.Title == current.Title && *previous.LatestKnownChapter == *current.LatestKnownChapter && previous.ID < current.ID {
+			t.Fatalf("expected newer ids first among full ties, got %d before %d", previous.ID, current.ID)
+		}
+	}
+	if last := items[len(items)-1]; last.LatestKnownChapter != nil {
+		t.Fatalf("expected trackers without a latest chapter last, got %+v", last)
+	}
+}
+
+func TestReleaseSortKeepsFutureReleasesOffTheTop(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+	beta := trackerIDByTitle(t, repo, "Beta Blade")
+	latest := 12.0
+	now := time.Now()
+	releasedAt := now.Add(-time.Hour)
+	scheduledAt := now.Add(48 * time.Hour)
+	if err := repo.UpdatePollingState(context.Background(), beta, 0, "", nil, "", &latest, &releasedAt, false, now); err != nil {
+		t.Fatalf("update beta: %v", err)
+	}
+	if err := repo.UpdatePollingState(context.Background(), alpha, 0, "", nil, "", &latest, &scheduledAt, false, now); err != nil {
+		t.Fatalf("update alpha: %v", err)
+	}
+
+	items, err := repo.List(context.Background(), TrackerListOptions{ProfileID: 1, SortBy: "latest_known_chapter", Order: "desc"})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	position := make(map[int64]int, len(items))
+	for index, item := range items {
+		position[item.ID] = index
+	}
+	if position[alpha] < position[beta] {
+		t.Fatalf("expected the chapter released an hour ago before the one dated in two days, got %v", position)
+	}
+	if last := items[len(items)-1]; last.ID != alpha {
+		t.Fatalf("expected the release dated in two days to sort as undated, last, got %q last", last.Title)
+	}
+}
+
+func TestListForPollingPutsNeverCheckedTrackersFirst(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	gamma := trackerIDByTitle(t, repo, "Gamma Tower")
+	delta := trackerIDByTitle(t, repo, "Delta Tower")
+	if _, err := db.Exec(`UPDATE trackers SET last_checked_at = CURRENT_TIMESTAMP WHERE id NOT IN (?, ?)`, gamma, delta); err != nil {
+		t.Fatalf("mark trackers checked: %v", err)
+	}
+
+	items, err := repo.ListForPolling(context.Background())
+	if err != nil {
+		t.Fatalf("list for polling: %v", err)
+	}
+	if len(items) < 3 {
+		t.Fatalf("expected every seeded tracker, got %d", len(items))
+	}
+	first := map[int64]bool{items[0].ID: true, items[1].ID: true}
+	if !first[gamma] || !first[delta] {
+		t.Fatalf("expected the never-checked Gamma and Delta first, got %d and %d", items[0].ID, items[1].ID)
+	}
+	for _, item := range items[2:] {
+		if item.LastCheckedAt == nil {
+			t.Fatalf("expected only checked trackers after the never-checked ones, got %+v", item)
+		}
+	}
+}
+
+func TestSetSourceGenresKeepsGenresWhenAPollHasNone(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+
+	if err := repo.SetSourceGenres(context.Background(), alpha, []string{" Action ", "action", "Slice of Life"}); err != nil {
+		t.Fatalf("set source genres: %v", err)
+	}
+	if err := repo.SetSourceGenres(context.Background(), alpha, nil); err != nil {
+		t.Fatalf("set no source genres: %v", err)
+	}
+
+	tracker, err := repo.GetByID(context.Background(), 1, alpha)
+	if err != nil || tracker == nil {
+		t.Fatalf("get tracker: %v", err)
+	}
+	if got := tracker.SourceGenres; len(got) != 2 || got[0] != "Action" || got[1] != "Slice of Life" {
+		t.Fatalf("expected the cleaned genres to be kept, got %v", got)
+	}
+}
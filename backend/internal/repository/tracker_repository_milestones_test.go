@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+func trackerIDByTitle(t *testing.T, repo *TrackerRepository, title string) int64 {
+	t.Helper()
+
+	var id int64
+	if err := repo.conn.QueryRow(`SELECT id FROM trackers WHERE title = ?`, title).Scan(&id); err != nil {
+		t.Fatalf("load tracker %q: %v", title, err)
+	}
+	return id
+}
+
+func getMilestoneTracker(t *testing.T, repo *TrackerRepository, id int64) *models.Tracker {
+	t.Helper()
+
+	tracker, err := repo.GetByID(context.Background(), 1, id)
+	if err != nil || tracker == nil {
+		t.Fatalf("get tracker %d: %v", id, err)
+	}
+	return tracker
+}
+
+func TestReadingMilestonesStartNullForExistingTrackers(t *testing.T) {
+	repo := NewTrackerRepository(setupListingTestDB(t))
+
+	tracker := getMilestoneTracker(t, repo, trackerIDByTitle(t, repo, "Gamma Tower"))
+	if tracker.FirstReadAt != nil || tracker.CaughtUpAt != nil {
+		t.Fatalf("expected seeded tracker to have no milestones, got first=%v caught=%v", tracker.FirstReadAt, tracker.CaughtUpAt)
+	}
+}
+
+func TestUpdateLastReadChapterRecordsMilestones(t *testing.T) {
+	repo := NewTrackerRepository(setupListingTestDB(t))
+
+	epsilonID := trackerIDByTitle(t, repo, "Epsilon Blade")
+	partial := 2.0
+	if _, err := repo.UpdateLastReadChapter(context.Background(), 1, epsilonID, &partial); err != nil {
+		t.Fatalf("update last read: %v", err)
+	}
+	tracker := getMilestoneTracker(t, repo, epsilonID)
+	if tracker.FirstReadAt == nil {
+		t.Fatalf("expected first read milestone once a chapter is read")
+	}
+	if tracker.CaughtUpAt != nil {
+		t.Fatalf("expected no caught-up milestone while behind, got %v", tracker.CaughtUpAt)
+	}
+
+	latest := 5.0
+	if _, err := repo.UpdateLastReadChapter(context.Background(), 1, epsilonID, &latest); err != nil {
+		t.Fatalf("update last read: %v", err)
+	}
+	tracker = getMilestoneTracker(t, repo, epsilonID)
+	if tracker.CaughtUpAt == nil {
+		t.Fatalf("expected caught-up milestone after reaching the latest chapter")
+	}
+
+	deltaID := trackerIDByTitle(t, repo, "Delta Tower")
+	deltaLatest := 20.0
+	if _, err := repo.UpdateLastReadChapter(context.Background(), 1, deltaID, &deltaLatest); err != nil {
+		t.Fatalf("update last read: %v", err)
+	}
+	if tracker := getMilestoneTracker(t, repo, deltaID); tracker.CaughtUpAt != nil {
+		t.Fatalf("expected on-hold tracker not to record caught-up, got %v", tracker.CaughtUpAt)
+	}
+}
+
+// CaughtUpAt keeps its first occurrence: new chapters appearing later and
+// catching up again must not move it.
+func TestCaughtUpAtKeepsFirstOccurrence(t *testing.T) {
+	repo := NewTrackerRepository(setupListingTestDB(t))
+
+	alphaID := trackerIDByTitle(t, repo, "Alpha Blade")
+	caughtUp := 12.0
+	if _, err := repo.UpdateLastReadChapter(context.Background(), 1, alphaID, &caughtUp); err != nil {
+		t.Fatalf("update last read: %v", err)
+	}
+	firstCaughtUp := time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := repo.conn.Exec(`UPDATE trackers SET caught_up_at = ? WHERE id = ?`, firstCaughtUp, alphaID); err != nil {
+		t.Fatalf("pin caught-up milestone: %v", err)
+	}
+
+	if _, err := repo.conn.Exec(`UPDATE trackers SET latest_known_chapter = 15 WHERE id = ?`, alphaID); err != nil {
+		t.Fatalf("raise latest chapter: %v", err)
+	}
+	if tracker := getMilestoneTracker(t, repo, alphaID); tracker.CaughtUpAt == nil || !tracker.CaughtUpAt.Equal(firstCaughtUp) {
+		t.Fatalf("expected caught-up milestone to survive new chapters, got %v", tracker.CaughtUpAt)
+	}
+
+	caughtUpAgain := 15.0
+	if _, err := repo.UpdateLastReadChapter(context.Background(), 1, alphaID, &caughtUpAgain); err != nil {
+		t.Fatalf("update last read: %v", err)
+	}
+	if tracker := getMilestoneTracker(t, repo, alphaID); tracker.CaughtUpAt == nil || !tracker.CaughtUpAt.Equal(firstCaughtUp) {
+		t.Fatalf("expected caught-up milestone to keep its first occurrence, got %v", tracker.CaughtUpAt)
+	}
+}
+
+func TestUpdateAndCreateRecordMilestones(t *testing.T) {
+	repo := NewTrackerRepository(setupListingTestDB(t))
+
+	epsilonID := trackerIDByTitle(t, repo, "Epsilon Blade")
+	tracker := getMilestoneTracker(t, repo, epsilonID)
+	lastRead := 5.0
+	tracker.LastReadChapter = &lastRead
+	tracker.Status = "completed"
+	updated, err := repo.Update(context.Background(), 1, epsilonID, tracker)
+	if err != nil {
+		t.Fatalf("update tracker: %v", err)
+	}
+	if updated.FirstReadAt == nil || updated.CaughtUpAt == nil {
+		t.Fatalf("expected update to record both milestones, got first=%v caught=%v", updated.FirstReadAt, updated.CaughtUpAt)
+	}
+
+	latest := 30.0
+	created, err := repo.Create(context.Background(), &models.Tracker{
+		ProfileID:          1,
+		Title:              "Fresh Milestones",
+		SourceID:           1,
+		SourceURL:          "https://mangadex.org/title/fresh-milestones",
+		Status:             "reading",
+		LatestKnownChapter: &latest,
+	})
+	if err != nil {
+		t.Fatalf("create tracker: %v", err)
+	}
+	if created.FirstReadAt != nil || created.CaughtUpAt != nil {
+		t.Fatalf("expected unread tracker to have no milestones, got first=%v caught=%v", created.FirstReadAt, created.CaughtUpAt)
+	}
+
+	created, err = repo.Create(context.Background(), &models.Tracker{
+		ProfileID:          1,
+		Title:              "Caught Up On Create",
+		SourceID:           1,
+		SourceURL:          "https://mangadex.org/title/caught-up-on-create",
+		Status:             "reading",
+		LastReadChapter:    &latest,
+		LatestKnownChapter: &latest,
+	})
+	if err != nil {
+		t.Fatalf("create tracker: %v", err)
+	}
+	if created.FirstReadAt == nil || created.CaughtUpAt == nil {
+		t.Fatalf("expected caught-up tracker to record both milestones on create, got first=%v caught=%v", created.FirstReadAt, created.CaughtUpAt)
+	}
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+// Overlap match reasons, as listed in models.TrackerOverlap.MatchedBy.
+const (
+	OverlapMatchTitle     = "title"
+	OverlapMatchSourceURL = "source_url"
+)
+
+// ListProfileOverlap pairs each of the left profile's trackers with the
+// right profile's trackers of the same series: the same title ignoring case
+// and surrounding spaces, or a shared link on the same source ignoring case
+// and trailing slashes, primary or linked. Pairs are ordered by the left
+// tracker's title.
+func (r *TrackerRepository) ListProfileOverlap(ctx context.Context, leftProfileID int64, rightProfileID int64) ([]models.TrackerOverlap, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH urls AS (
+			SELECT t.id AS tracker_id, t.source_id, RTRIM(LOWER(t.source_url), '/') AS url
+			FROM trackers t
+			WHERE t.profile_id IN (?, ?) AND t.source_url != ''
+			UNION
+			SELECT ts.tracker_id, ts.source_id, RTRIM(LOWER(ts.source_url), '/')
+			FROM tracker_sources ts
+			INNER JOIN trackers t ON t.id = ts.tracker_id
+			WHERE t.profile_id IN (?, ?) AND ts.source_url != ''
+		),
+		pairs AS (
+			SELECT a.id AS left_id, b.id AS right_id, ? AS matched_by
+			FROM trackers a
+			INNER JOIN trackers b ON LOWER(TRIM(b.title)) = LOWER(TRIM(a.title))
+			WHERE a.profile_id = ? AND b.profile_id = ? AND TRIM(a.title) != ''
+			UNION
+			SELECT ua.tracker_id, ub.tracker_id, ?
+			FROM urls ua
+			INNER JOIN urls ub ON ub.source_id = ua.source_id AND ub.url = ua.url
+			INNER JOIN trackers a ON a.id = ua.tracker_id
+			INNER JOIN trackers b ON b.id = ub.tracker_id
+			WHERE a.profile_id = ? AND b.profile_id = ?
+		)
+		SELECT
+			p.matched_by,
+			a.id, a.profile_id, a.title, a.status, a.last_read_chapter, a.latest_known_chapter,
+			b.id, b.profile_id, b.title, b.status, b.last_read_chapter, b.latest_known_chapter
+		FROM pairs p
+		INNER JOIN trackers a ON a.id = p.left_id
+		INNER JOIN trackers b ON b.id = p.right_id
+		ORDER BY a.title COLLATE NOCASE ASC, a.id ASC, b.id ASC, p.matched_by DESC
+	`,
+		leftProfileID, rightProfileID,
+		leftProfileID, rightProfileID,
+		OverlapMatchTitle, leftProfileID, rightProfileID,
+		OverlapMatchSourceURL, leftProfileID, rightProfileID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list profile overlap: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.TrackerOverlap, 0)
+	for rows.Next() {
+		var matchedBy string
+		var left, right models.OverlapTracker
+		var leftRead, leftLatest, rightRead, rightLatest sql.NullFloat64
+		if err := rows.Scan(
+			&matchedBy,
+			&left.TrackerID, &left.ProfileID, &left.Title, &left.Status, &leftRead, &leftLatest,
+			&right.TrackerID, &right.ProfileID, &right.Title, &right.Status, &rightRead, &rightLatest,
+		); err != nil {
+			return nil, fmt.Errorf("scan profile overlap: %w", err)
+		}
+
+		// A pair matched both ways comes back as two adjacent rows.
+		if last := len(items) - 1; last >= 0 && items[last].Left.TrackerID == left.TrackerID && items[last].Right.TrackerID == right.TrackerID {
+			items[last].MatchedBy = append(items[last].MatchedBy, matchedBy)
+			continue
+		}
+
+		if leftRead.Valid {
+			left.LastReadChapter = &leftRead.Float64
+		}
+		if leftLatest.Valid {
+			left.LatestKnownChapter = &leftLatest.Float64
+		}
+		if rightRead.Valid {
+			right.LastReadChapter = &rightRead.Float64
+		}
+		if rightLatest.Valid {
+			right.LatestKnownChapter = &rightLatest.Float64
+		}
+		item := models.TrackerOverlap{Left: left, Right: right, MatchedBy: []string{matchedBy}}
+		if left.LastReadChapter != nil && right.LastReadChapter != nil {
+			delta := *left.LastReadChapter - *right.LastReadChapter
+			item.ChapterDelta = &delta
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate profile overlap: %w", err)
+	}
+	return items, nil
+}
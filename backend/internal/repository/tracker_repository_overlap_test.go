@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestListProfileOverlapMatchesTitlesAndSourceURLs(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	if _, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES
+			(2, ' alpha BLADE ', 2, 'https://mangafire.to/manga/alpha-fire', 'reading', 40, 45),
+			(2, 'Gamma Saga', 1, 'https://MangaDex.org/title/gamma/', 'dropped', NULL, 50),
+			(2, 'Delta Tower', 3, 'https://asuracomic.net/series/delta', 'reading', 8, 20),
+			(2, 'Linked Match', 2, 'https://mangafire.to/manga/linked-match', 'plan_to_read', NULL, 3),
+			(2, 'Beta Elsewhere', 1, 'https://mangafire.to/manga/beta', 'reading', 2, 12)
+	`); err != nil {
+		t.Fatalf("seed other profile trackers: %v", err)
+	}
+	// Linked Match shares Alpha Blade's linked source link.
+	if _, err := db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_url)
+		SELECT id, 3, 'https://mangadex.org/title/alpha/linked' FROM trackers WHERE title = 'Linked Match'
+	`); err != nil {
+		t.Fatalf("seed linked source: %v", err)
+	}
+
+	items, err := repo.ListProfileOverlap(ctx, 1, 2)
+	if err != nil {
+		t.Fatalf("list profile overlap: %v", err)
+	}
+	got := make([]string, 0, len(items))
+	for _, item := range items {
+		got = append(got, item.Left.Title+" ~ "+strings.TrimSpace(item.Right.Title)+" by "+strings.Join(item.MatchedBy, "+"))
+	}
+	want := []string{
+		"Alpha Blade ~ alpha BLADE by title",
+		"Alpha Blade ~ Linked Match by source_url",
+		"Delta Tower ~ Delta Tower by title+source_url",
+		"Gamma Tower ~ Gamma Saga by source_url",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected overlap:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	alpha := items[0]
+	if alpha.Left.ProfileID != 1 || alpha.Right.ProfileID != 2 || alpha.Right.Status != "reading" || alpha.ChapterDelta == nil || *alpha.ChapterDelta != -30 {
+		t.Fatalf("expected Alpha Blade 30 chapters behind, got %+v", alpha)
+	}
+	if gamma := items[3]; gamma.ChapterDelta != nil || gamma.Left.LastReadChapter == nil || gamma.Right.LastReadChapter != nil {
+		t.Fatalf("expected no delta without both last reads, got %+v", gamma)
+	}
+
+	reversed, err := repo.ListProfileOverlap(ctx, 2, 1)
+	if err != nil {
+		t.Fatalf("list reversed profile overlap: %v", err)
+	}
+	if len(reversed) != len(items) || reversed[0].Left.ProfileID != 2 || reversed[0].Right.ProfileID != 1 {
+		t.Fatalf("expected the same pairs with the sides swapped, got %+v", reversed)
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+// lastReadChapterOf returns the tracker's current last read chapter, or nil
+// when it has none or the tracker does not exist.
+func (r *TrackerRepository) lastReadChapterOf(ctx context.Context, profileID int64, trackerID int64) (*float64, error) {
+	var chapter sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT last_read_chapter FROM trackers WHERE id = ? AND profile_id = ?
+	`, trackerID, profileID).Scan(&chapter)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get last read chapter: %w", err)
+	}
+	if !chapter.Valid {
+		return nil, nil
+	}
+	return &chapter.Float64, nil
+}
+
+// recordReadEvent adds the move from one last read chapter to the next to
+// the tracker's reading history. Only advances are recorded; clearing the
+// chapter or moving it back is not a read. Reads made during a re-read are
+// flagged as such.
+func (r *TrackerRepository) recordReadEvent(ctx context.Context, profileID int64, trackerID int64, from *float64, to *float64, readAt time.Time) error {
+	if to == nil || (from != nil && *to <= *from) {
+		return nil
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO read_events (profile_id, tracker_id, from_chapter, to_chapter, read_at, reread)
+		SELECT profile_id, id, ?, ?, ?, rereading
+		FROM trackers
+		WHERE id = ? AND profile_id = ?
+	`, from, *to, readAt.UTC(), trackerID, profileID); err != nil {
+		return fmt.Errorf("record read event: %w", err)
+	}
+	return nil
+}
+
+// ListReadEvents returns the tracker's reading history, oldest first.
+func (r *TrackerRepository) ListReadEvents(ctx context.Context, profileID int64, trackerID int64) ([]models.ReadEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, tracker_id, from_chapter, to_chapter, read_at, reread
+		FROM read_events
+		WHERE profile_id = ? AND tracker_id = ?
+		ORDER BY read_at ASC, id ASC
+	`, profileID, trackerID)
+	if err != nil {
+		return nil, fmt.Errorf("list read events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]models.ReadEvent, 0)
+	for rows.Next() {
+		var event models.ReadEvent
+		var from sql.NullFloat64
+		if err := rows.Scan(&event.ID, &event.TrackerID, &from, &event.ToChapter, &event.ReadAt, &event.Reread); err != nil {
+			return nil, fmt.Errorf("scan read event: %w", err)
+		}
+		if from.Valid {
+			event.FromChapter = &from.Float64
+			event.Chapters = event.ToChapter - from.Float64
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate read events: %w", err)
+	}
+	return events, nil
+}
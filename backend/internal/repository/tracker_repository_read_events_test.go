@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLastReadAdvancesAreRecordedAsReadEvents(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	alphaID := trackerIDByTitle(t, repo, "Alpha Blade")
+	if _, err := db.Exec(`UPDATE trackers SET last_read_chapter = NULL WHERE id = ?`, alphaID); err != nil {
+		t.Fatalf("clear last read chapter: %v", err)
+	}
+	chapter := func(value float64) *float64 { return &value }
+	// Moving back, clearing and re-reading the same chapter are not reads.
+	for _, next := range []*float64{chapter(10), chapter(12), chapter(11), nil, chapter(11), chapter(11), chapter(15)} {
+		if _, err := repo.UpdateLastReadChapter(ctx, 1, alphaID, next); err != nil {
+			t.Fatalf("update last read chapter: %v", err)
+		}
+	}
+	tracker, err := repo.GetByID(ctx, 1, alphaID)
+	if err != nil || tracker == nil {
+		t.Fatalf("get tracker: %v", err)
+	}
+	tracker.LastReadChapter = chapter(18)
+	if _, err := repo.Update(ctx, 1, alphaID, tracker); err != nil {
+		t.Fatalf("update tracker: %v", err)
+	}
+	// Another profile's tracker id is ignored.
+	if _, err := repo.UpdateLastReadChapter(ctx, 2, alphaID, chapter(30)); err != nil {
+		t.Fatalf("update last read chapter: %v", err)
+	}
+
+	events, err := repo.ListReadEvents(ctx, 1, alphaID)
+	if err != nil {
+		t.Fatalf("list read events: %v", err)
+	}
+	want := []struct {
+		from     float64
+		to       float64
+		chapters float64
+	}{{-1, 10, 0}, {10, 12, 2}, {-1, 11, 0}, {11, 15, 4}, {15, 18, 3}}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d read events, got %+v", len(want), events)
+	}
+	for index, event := range events {
+		from := -1.0
+		if event.FromChapter != nil {
+			from = *event.FromChapter
+		}
+		if from != want[index].from || event.ToChapter != want[index].to || event.Chapters != want[index].chapters || event.ReadAt.IsZero() {
+			t.Fatalf("event %d: expected %+v, got %+v", index, want[index], event)
+		}
+	}
+
+	if other, err := repo.ListReadEvents(ctx, 2, alphaID); err != nil || len(other) != 0 {
+		t.Fatalf("expected no events for another profile, got %+v (%v)", other, err)
+	}
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+// RecordReadSource counts one advance of the tracker's last read chapter
+// against sourceID.
+func (r *TrackerRepository) RecordReadSource(ctx context.Context, profileID int64, trackerID int64, sourceID int64, readAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO read_source_stats (profile_id, tracker_id, source_id, reads, last_read_at)
+		SELECT profile_id, id, ?, 1, ?
+		FROM trackers
+		WHERE id = ? AND profile_id = ?
+		ON CONFLICT(tracker_id, source_id)
+		DO UPDATE SET
+			reads = reads + 1,
+			last_read_at = excluded.last_read_at
+	`, sourceID, readAt.UTC(), trackerID, profileID); err != nil {
+		return fmt.Errorf("record read source: %w", err)
+	}
+	return nil
+}
+
+// ListReadSourceTotals sums the profile's reads per source, most read
+// first.
+func (r *TrackerRepository) ListReadSourceTotals(ctx context.Context, profileID int64) ([]models.ReadSourceCount, error) {
+	return r.listReadSourceCounts(ctx, `WHERE rs.profile_id = ?`, profileID)
+}
+
+// ListTrackerReadSources returns the tracker's reads per source, most read
+// first.
+func (r *TrackerRepository) ListTrackerReadSources(ctx context.Context, profileID int64, trackerID int64) ([]models.ReadSourceCount, error) {
+	return r.listReadSourceCounts(ctx, `WHERE rs.profile_id = ? AND rs.tracker_id = ?`, profileID, trackerID)
+}
+
+func (r *TrackerRepository) listReadSourceCounts(ctx context.Context, where string, args ...any) ([]models.ReadSourceCount, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rs.source_id, s.key, s.name, rs.reads, rs.last_read_at
+		FROM read_source_stats rs
+		INNER JOIN sources s ON s.id = rs.source_id
+		`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list read sources: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]models.ReadSourceCount, 0)
+	indexBySource := make(map[int64]int)
+	for rows.Next() {
+		var row models.ReadSourceCount
+		if err := rows.Scan(&row.SourceID, &row.SourceKey, &row.SourceName, &row.Reads, &row.LastReadAt); err != nil {
+			return nil, fmt.Errorf("scan read source: %w", err)
+		}
+		index, ok := indexBySource[row.SourceID]
+		if !ok {
+			indexBySource[row.SourceID] = len(counts)
+			counts = append(counts, row)
+			continue
+		}
+		counts[index].Reads += row.Reads
+		if row.LastReadAt.After(counts[index].LastReadAt) {
+			counts[index].LastReadAt = row.LastReadAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate read sources: %w", err)
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Reads != counts[j].Reads {
+			return counts[i].Reads > counts[j].Reads
+		}
+		return counts[i].SourceName < counts[j].SourceName
+	})
+	return counts, nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReadSourceCountsSumPerSource(t *testing.T) {
+	repo := NewTrackerRepository(setupListingTestDB(t))
+	ctx := context.Background()
+
+	alphaID := trackerIDByTitle(t, repo, "Alpha Blade")
+	betaID := trackerIDByTitle(t, repo, "Beta Blade")
+	readAt := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	for _, read := range []struct {
+		trackerID int64
+		sourceID  int64
+		at        time.Time
+	}{
+		{alphaID, 1, readAt},
+		{alphaID, 1, readAt.Add(time.Hour)},
+		{alphaID, 3, readAt},
+		{betaID, 3, readAt.Add(time.Hour)},
+		{betaID, 3, readAt.Add(2 * time.Hour)},
+	} {
+		if err := repo.RecordReadSource(ctx, 1, read.trackerID, read.sourceID, read.at); err != nil {
+			t.Fatalf("record read source: %v", err)
+		}
+	}
+	// Another profile's tracker id is ignored.
+	otherID := trackerIDByTitle(t, repo, "Other Profile Blade")
+	if err := repo.RecordReadSource(ctx, 1, otherID, 1, readAt); err != nil {
+		t.Fatalf("record read source: %v", err)
+	}
+
+	totals, err := repo.ListReadSourceTotals(ctx, 1)
+	if err != nil {
+		t.Fatalf("list read source totals: %v", err)
+	}
+	if len(totals) != 2 || totals[0].SourceID != 3 || totals[0].Reads != 3 || totals[1].SourceID != 1 || totals[1].Reads != 2 {
+		t.Fatalf("expected source 3 with 3 reads before source 1 with 2, got %+v", totals)
+	}
+	if !totals[0].LastReadAt.Equal(readAt.Add(2 * time.Hour)) {
+		t.Fatalf("expected the latest read time across trackers, got %v", totals[0].LastReadAt)
+	}
+
+	alpha, err := repo.ListTrackerReadSources(ctx, 1, alphaID)
+	if err != nil {
+		t.Fatalf("list tracker read sources: %v", err)
+	}
+	if len(alpha) != 2 || alpha[0].SourceID != 1 || alpha[0].Reads != 2 || alpha[1].Reads != 1 {
+		t.Fatalf("expected Alpha Blade's own breakdown, got %+v", alpha)
+	}
+
+	if other, err := repo.ListReadSourceTotals(ctx, 2); err != nil || len(other) != 0 {
+		t.Fatalf("expected no reads recorded for profile 2, got %+v (%v)", other, err)
+	}
+}
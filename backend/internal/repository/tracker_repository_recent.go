@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ListRecentIncomplete returns up to limit of the profile's trackers added
+// after since, newest first, that still look unfinished: no tags, or no
+// linked source besides the primary. Trackers marked setup complete are
+// left out.
+func (r *TrackerRepository) ListRecentIncomplete(ctx context.Context, profileID int64, since time.Time, limit int) ([]RecentIncompleteTracker, error) {
+	items := make([]RecentIncompleteTracker, 0)
+	if limit <= 0 {
+		return items, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, title, source_url, created_at, tag_count, source_count
+		FROM (
+			SELECT
+				t.id,
+				t.title,
+				t.source_url,
+				t.created_at,
+				(SELECT COUNT(1) FROM tracker_tags tt WHERE tt.tracker_id = t.id) AS tag_count,
+				(SELECT COUNT(1) FROM tracker_sources ts WHERE ts.tracker_id = t.id) AS source_count
+			FROM trackers t
+			WHERE t.profile_id = ?
+			  AND t.setup_complete = 0
+		)
+		WHERE tag_count = 0 OR source_count <= 1
+		ORDER BY created_at DESC, id DESC
+	`, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("list recent incomplete trackers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item RecentIncompleteTracker
+		if err := rows.Scan(&item.ID, &item.Title, &item.SourceURL, &item.CreatedAt, &item.TagCount, &item.LinkedSourceCount); err != nil {
+			return nil, fmt.Errorf("scan recent incomplete tracker: %w", err)
+		}
+		// Rows come newest first, so the first one at or before since ends
+		// the window.
+		if !item.CreatedAt.After(since) {
+			break
+		}
+		items = append(items, item)
+		if len(items) == limit {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate recent incomplete trackers: %w", err)
+	}
+
+	return items, nil
+}
+
+// MarkSetupComplete takes the tracker out of the recently added strip. It
+// reports false when the tracker is not one of the profile's.
+func (r *TrackerRepository) MarkSetupComplete(ctx context.Context, profileID int64, id int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE trackers
+		SET setup_complete = 1
+		WHERE id = ? AND profile_id = ?
+	`, id, profileID)
+	if err != nil {
+		return false, fmt.Errorf("mark tracker setup complete: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("mark tracker setup complete rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// recordChapterRelease adds a chapter to the tracker's release history.
+// A chapter already in the history keeps its first recorded release time.
+func (r *TrackerRepository) recordChapterRelease(ctx context.Context, trackerID int64, chapter *float64, releasedAt *time.Time) error {
+	if chapter == nil || releasedAt == nil || releasedAt.IsZero() {
+		return nil
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO chapters (tracker_id, chapter_number, released_at)
+		SELECT ?, ?, ?
+		WHERE EXISTS (SELECT 1 FROM trackers WHERE id = ?)
+		  AND NOT EXISTS (
+			SELECT 1 FROM chapters WHERE tracker_id = ? AND chapter_number = ?
+		  )
+	`, trackerID, *chapter, releasedAt.UTC(), trackerID, trackerID, *chapter); err != nil {
+		return fmt.Errorf("record chapter release: %w", err)
+	}
+	return nil
+}
+
+// ListRecentReleaseTimes returns, for each of the profile's trackers in
+// status, the release times of its latest perTracker recorded chapters,
+// newest first. Trackers without history are absent from the map.
+func (r *TrackerRepository) ListRecentReleaseTimes(ctx context.Context, profileID int64, status string, perTracker int) (map[int64][]time.Time, error) {
+	if perTracker <= 0 {
+		return map[int64][]time.Time{}, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tracker_id, released_at
+		FROM (
+			SELECT c.tracker_id, c.released_at,
+				ROW_NUMBER() OVER (PARTITION BY c.tracker_id ORDER BY c.released_at DESC, c.id DESC) AS position
+			FROM chapters c
+			INNER JOIN trackers t ON t.id = c.tracker_id
+			WHERE t.profile_id = ?
+			  AND t.status = ?
+			  AND c.released_at IS NOT NULL
+		)
+		WHERE position <= ?
+		ORDER BY tracker_id ASC, released_at DESC
+	`, profileID, status, perTracker)
+	if err != nil {
+		return nil, fmt.Errorf("list recent release times: %w", err)
+	}
+	defer rows.Close()
+
+	releases := make(map[int64][]time.Time)
+	for rows.Next() {
+		var trackerID int64
+		var releasedAt sql.NullTime
+		if err := rows.Scan(&trackerID, &releasedAt); err != nil {
+			return nil, fmt.Errorf("scan recent release time: %w", err)
+		}
+		if releasedAt.Valid {
+			releases[trackerID] = append(releases[trackerID], releasedAt.Time)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate recent release times: %w", err)
+	}
+
+	return releases, nil
+}
+
+// CountReleasesSince returns, for each of trackerIDs in the profile, how
+// many of its recorded chapters were released after since. Trackers
+// without such chapters are absent from the map.
+func (r *TrackerRepository) CountReleasesSince(ctx context.Context, profileID int64, trackerIDs []int64, since time.Time) (map[int64]int, error) {
+	counts := make(map[int64]int)
+	if len(trackerIDs) == 0 {
+		return counts, nil
+	}
+	args := make([]any, 0, len(trackerIDs)+1)
+	args = append(args, profileID)
+	for _, id := range trackerIDs {
+		args = append(args, id)
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.tracker_id, c.released_at
+		FROM chapters c
+		INNER JOIN trackers t ON t.id = c.tracker_id
+		WHERE t.profile_id = ?
+		  AND c.tracker_id IN (`+sqlPlaceholders(len(trackerIDs))+`)
+		  AND c.released_at IS NOT NULL
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("count releases since: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var trackerID int64
+		var releasedAt sql.NullTime
+		if err := rows.Scan(&trackerID, &releasedAt); err != nil {
+			return nil, fmt.Errorf("scan release since: %w", err)
+		}
+		if releasedAt.Valid && releasedAt.Time.After(since) {
+			counts[trackerID]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate releases since: %w", err)
+	}
+
+	return counts, nil
+}
+
+// NewChapterCounts holds how many chapters of a profile came out today and
+// this week.
+type NewChapterCounts struct {
+	Today int
+	Week  int
+}
+
+// CountNewChapters counts the profile's chapters released from dayStart and
+// from weekStart up to now, both in one pass over the release history. A
+// tracker without a recorded chapter since weekStart, as one polled before
+// the history was kept, counts its latest release once instead when that
+// falls in a window. Only the first 19 characters of a release time are
+// compared: julianday does not read the nanoseconds the driver writes.
+func (r *TrackerRepository) CountNewChapters(ctx context.Context, profileID int64, dayStart, weekStart, now time.Time) (NewChapterCounts, error) {
+	const layout = "2006-01-02 15:04:05"
+	day := dayStart.UTC().Format(layout)
+	week := weekStart.UTC().Format(layout)
+	var counts NewChapterCounts
+	err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN released >= julianday(?) THEN 1 ELSE 0 END), 0),
+			COUNT(*)
+		FROM (
+			SELECT julianday(substr(c.released_at, 1, 19)) AS released
+			FROM chapters c
+			INNER JOIN trackers t ON t.id = c.tracker_id
+			WHERE t.profile_id = ?
+			  AND c.released_at IS NOT NULL
+			UNION ALL
+			SELECT julianday(substr(t.latest_release_at, 1, 19))
+			FROM trackers t
+			WHERE t.profile_id = ?
+			  AND t.latest_known_chapter IS NOT NULL
+			  AND t.latest_release_at IS NOT NULL
+			  AND NOT EXISTS (
+				SELECT 1 FROM chapters c
+				WHERE c.tracker_id = t.id
+				  AND julianday(substr(c.released_at, 1, 19)) >= julianday(?)
+			  )
+		)
+		WHERE released >= julianday(?)
+		  AND released <= julianday(?)
+	`, day, profileID, profileID, week, week, now.UTC().Format(layout)).Scan(&counts.Today, &counts.Week)
+	if err != nil {
+		return NewChapterCounts{}, fmt.Errorf("count new chapters: %w", err)
+	}
+	return counts, nil
+}
+
+// ChapterRelease is one chapter in a tracker's release history.
+type ChapterRelease struct {
+	Chapter    float64
+	ReleasedAt time.Time
+}
+
+// ListChapterReleases returns the tracker's recorded chapter releases,
+// oldest first. A tracker polled before the history was kept has none; its
+// latest known chapter and release time then stand in as the one release,
+// so a cadence still has somewhere to start.
+func (r *TrackerRepository) ListChapterReleases(ctx context.Context, profileID int64, trackerID int64) ([]ChapterRelease, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.chapter_number, c.released_at
+		FROM chapters c
+		INNER JOIN trackers t ON t.id = c.tracker_id
+		WHERE t.profile_id = ?
+		  AND c.tracker_id = ?
+		  AND c.released_at IS NOT NULL
+		UNION ALL
+		SELECT t.latest_known_chapter, t.latest_release_at
+		FROM trackers t
+		WHERE t.profile_id = ?
+		  AND t.id = ?
+		  AND t.latest_known_chapter IS NOT NULL
+		  AND t.latest_release_at IS NOT NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM chapters c
+			WHERE c.tracker_id = t.id AND c.released_at IS NOT NULL
+		  )
+		ORDER BY 2 ASC
+	`, profileID, trackerID, profileID, trackerID)
+	if err != nil {
+		return nil, fmt.Errorf("list chapter releases: %w", err)
+	}
+	defer rows.Close()
+
+	releases := make([]ChapterRelease, 0)
+	for rows.Next() {
+		var release ChapterRelease
+		var releasedAt sql.NullTime
+		if err := rows.Scan(&release.Chapter, &releasedAt); err != nil {
+			return nil, fmt.Errorf("scan chapter release: %w", err)
+		}
+		if releasedAt.Valid {
+			release.ReleasedAt = releasedAt.Time
+			releases = append(releases, release)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate chapter releases: %w", err)
+	}
+	return releases, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPollingAndManualReleasesBuildReleaseHistory(t *testing.T) {
+	repo := NewTrackerRepository(setupListingTestDB(t))
+	ctx := context.Background()
+
+	alphaID := trackerIDByTitle(t, repo, "Alpha Blade")
+	base := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	for index, chapter := range []float64{13, 14} {
+		releasedAt := base.AddDate(0, 0, 7*index)
+		if err := repo.UpdatePollingState(ctx, alphaID, 1, "", nil, "", &chapter, &releasedAt, false, releasedAt); err != nil {
+			t.Fatalf("update polling state: %v", err)
+		}
+	}
+	// A repeat poll of chapter 14 must not move its recorded release.
+	repeatChapter, repeatAt := 14.0, base.AddDate(0, 0, 9)
+	if err := repo.UpdatePollingState(ctx, alphaID, 1, "", nil, "", &repeatChapter, &repeatAt, false, repeatAt); err != nil {
+		t.Fatalf("repeat polling state: %v", err)
+	}
+	manualAt := base.AddDate(0, 0, 14)
+	if _, err := repo.SetManualRelease(ctx, 1, alphaID, 15, manualAt); err != nil {
+		t.Fatalf("set manual release: %v", err)
+	}
+
+	// Gamma Tower is completed, so it stays out of the reading history.
+	gammaID := trackerIDByTitle(t, repo, "Gamma Tower")
+	if _, err := repo.SetManualRelease(ctx, 1, gammaID, 51, manualAt); err != nil {
+		t.Fatalf("set manual release: %v", err)
+	}
+
+	releases, err := repo.ListRecentReleaseTimes(ctx, 1, "reading", 2)
+	if err != nil {
+		t.Fatalf("list recent release times: %v", err)
+	}
+	if len(releases) != 1 {
+		t.Fatalf("expected history for Alpha Blade only, got %v", releases)
+	}
+	alpha := releases[alphaID]
+	if len(alpha) != 2 || !alpha[0].Equal(manualAt) || !alpha[1].Equal(base.AddDate(0, 0, 7)) {
+		t.Fatalf("expected the latest two releases newest first, got %v", alpha)
+	}
+
+	if other, err := repo.ListRecentReleaseTimes(ctx, 2, "reading", 10); err != nil || len(other) != 0 {
+		t.Fatalf("expected no history for the other profile, got %v (%v)", other, err)
+	}
+}
+
+func TestCountReleasesSinceCountsOnlyNewerChapters(t *testing.T) {
+	repo := NewTrackerRepository(setupListingTestDB(t))
+	ctx := context.Background()
+
+	alphaID := trackerIDByTitle(t, repo, "Alpha Blade")
+	gammaID := trackerIDByTitle(t, repo, "Gamma Tower")
+	otherID := trackerIDByTitle(t, repo, "Other Profile Blade")
+	since := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	for index, chapter := range []float64{13, 14, 15} {
+		if _, err := repo.SetManualRelease(ctx, 1, alphaID, chapter, since.Add(time.Duration(index-1)*time.Hour)); err != nil {
+			t.Fatalf("set manual release: %v", err)
+		}
+	}
+	if _, err := repo.SetManualRelease(ctx, 2, otherID, 3, since.Add(time.Hour)); err != nil {
+		t.Fatalf("set manual release: %v", err)
+	}
+
+	counts, err := repo.CountReleasesSince(ctx, 1, []int64{alphaID, gammaID, otherID}, since)
+	if err != nil {
+		t.Fatalf("count releases since: %v", err)
+	}
+	if len(counts) != 1 || counts[alphaID] != 1 {
+		t.Fatalf("expected one chapter after the visit, on Alpha Blade only, got %v", counts)
+	}
+}
+
+func TestCountNewChaptersSplitsTheWindowsAtUTCMidnight(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	alphaID := trackerIDByTitle(t, repo, "Alpha Blade")
+	betaID := trackerIDByTitle(t, repo, "Beta Blade")
+	epsilonID := trackerIDByTitle(t, repo, "Epsilon Blade")
+	otherID := trackerIDByTitle(t, repo, "Other Profile Blade")
+	now := time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC)
+	dayStart := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+	weekStart := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+
+	chapters := []struct {
+		trackerID  int64
+		chapter    float64
+		releasedAt time.Time
+	}{
+		{alphaID, 12, weekStart.Add(-time.Minute)},
+		{alphaID, 13, dayStart.Add(-time.Minute)},
+		{alphaID, 14, dayStart},
+		{alphaID, 15, now.Add(-time.Hour)},
+		{alphaID, 16, now.Add(2 * time.Hour)},
+		{epsilonID, 5, dayStart.Add(-time.Hour)},
+		{otherID, 2, now.Add(-time.Hour)},
+	}
+	for _, row := range chapters {
+		if _, err := db.Exec(`INSERT INTO chapters (tracker_id, chapter_number, released_at) VALUES (?, ?, ?)`, row.trackerID, row.chapter, row.releasedAt); err != nil {
+			t.Fatalf("insert chapter: %v", err)
+		}
+	}
+	// Beta Blade has no history, so its latest release counts instead;
+	// Epsilon Blade's does not, as its history already covers the week.
+	for trackerID, releasedAt := range map[int64]time.Time{betaID: dayStart.Add(time.Hour), epsilonID: dayStart.Add(2 * time.Hour)} {
+		if _, err := db.Exec(`UPDATE trackers SET latest_release_at = ? WHERE id = ?`, releasedAt, trackerID); err != nil {
+			t.Fatalf("set latest release: %v", err)
+		}
+	}
+
+	counts, err := repo.CountNewChapters(ctx, 1, dayStart, weekStart, now)
+	if err != nil {
+		t.Fatalf("count new chapters: %v", err)
+	}
+	if counts.Today != 3 || counts.Week != 5 {
+		t.Fatalf("expected 3 today and 5 this week, got %+v", counts)
+	}
+
+	// A second before midnight, only the previous day's chapters are out.
+	counts, err = repo.CountNewChapters(ctx, 1, dayStart.AddDate(0, 0, -1), weekStart, dayStart.Add(-time.Second))
+	if err != nil {
+		t.Fatalf("count new chapters: %v", err)
+	}
+	if counts.Today != 2 || counts.Week != 2 {
+		t.Fatalf("expected 2 the day before and 2 this week, got %+v", counts)
+	}
+}
+
+func TestListChapterReleasesFallsBackToTheLatestRelease(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	alphaID := trackerIDByTitle(t, repo, "Alpha Blade")
+	latestAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	if _, err := db.Exec(`UPDATE trackers SET latest_release_at = ? WHERE id = ?`, latestAt, alphaID); err != nil {
+		t.Fatalf("set latest release: %v", err)
+	}
+
+	releases, err := repo.ListChapterReleases(ctx, 1, alphaID)
+	if err != nil {
+		t.Fatalf("list chapter releases: %v", err)
+	}
+	if len(releases) != 1 || releases[0].Chapter != 12 || !releases[0].ReleasedAt.Equal(latestAt) {
+		t.Fatalf("expected the latest release to stand in for the history, got %+v", releases)
+	}
+
+	base := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	for index, chapter := range []float64{14, 13} {
+		if _, err := repo.SetManualRelease(ctx, 1, alphaID, chapter, base.AddDate(0, 0, -7*index)); err != nil {
+			t.Fatalf("set manual release: %v", err)
+		}
+	}
+	releases, err = repo.ListChapterReleases(ctx, 1, alphaID)
+	if err != nil {
+		t.Fatalf("list chapter releases: %v", err)
+	}
+	if len(releases) != 2 || releases[0].Chapter != 13 || releases[1].Chapter != 14 || !releases[1].ReleasedAt.Equal(base) {
+		t.Fatalf("expected the recorded history oldest first, got %+v", releases)
+	}
+
+	if other, err := repo.ListChapterReleases(ctx, 2, alphaID); err != nil || len(other) != 0 {
+		t.Fatalf("expected no history from another profile, got %+v (%v)", other, err)
+	}
+}
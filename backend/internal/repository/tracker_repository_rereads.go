@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+// StartReread starts a completed tracker over: its last read chapter and
+// when it was read are kept in previous_completions, last_read goes back to
+// 0 and the tracker reads as reading until the re-read is finished. It
+// reports false when the tracker is not one of the profile's, is not
+// completed or is already being re-read.
+func (r *TrackerRepository) StartReread(ctx context.Context, profileID int64, id int64, startedAt time.Time) (bool, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin start reread tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var lastReadChapter sql.NullFloat64
+	var lastReadAt sql.NullTime
+	var previousRaw sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT last_read_chapter, last_read_at, previous_completions
+		FROM trackers
+		WHERE id = ? AND profile_id = ? AND status = 'completed' AND rereading = 0
+	`, id, profileID).Scan(&lastReadChapter, &lastReadAt, &previousRaw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("load tracker completion: %w", err)
+	}
+
+	completion := models.TrackerCompletion{RereadStartedAt: startedAt.UTC()}
+	if lastReadChapter.Valid {
+		completion.LastReadChapter = &lastReadChapter.Float64
+	}
+	if lastReadAt.Valid {
+		completion.LastReadAt = &lastReadAt.Time
+	}
+	completions := append(decodeCompletionsJSON(previousRaw.String), completion)
+	encoded, err := json.Marshal(completions)
+	if err != nil {
+		return false, fmt.Errorf("encode tracker completions: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE trackers
+		SET
+			status = 'reading',
+			rereading = 1,
+			previous_completions = ?,
+			last_read_chapter = 0,
+			last_read_at = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND profile_id = ?
+	`, string(encoded), startedAt.UTC(), id, profileID); err != nil {
+		return false, fmt.Errorf("start reread: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit start reread tx: %w", err)
+	}
+	return true, nil
+}
+
+// finishReread ends the tracker's re-read once it is caught up again, or
+// was set back to completed, and counts it: the tracker returns to
+// completed and reread_count goes up by one.
+func (r *TrackerRepository) finishReread(ctx context.Context, profileID int64, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE trackers
+		SET
+			status = 'completed',
+			rereading = 0,
+			reread_count = reread_count + 1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+		  AND profile_id = ?
+		  AND rereading = 1
+		  AND (
+			status = 'completed'
+			OR (last_read_chapter IS NOT NULL AND latest_known_chapter IS NOT NULL AND last_read_chapter >= latest_known_chapter)
+		  )
+	`, id, profileID); err != nil {
+		return fmt.Errorf("finish reread: %w", err)
+	}
+	return nil
+}
+
+// ReadChapterTotals sums the chapters the profile's read events advanced,
+// keeping the chapters read during re-reads apart. A first read marks where
+// reading started and adds nothing.
+func (r *TrackerRepository) ReadChapterTotals(ctx context.Context, profileID int64) (models.ReadChapterTotals, error) {
+	var totals models.ReadChapterTotals
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN reread = 0 THEN to_chapter - from_chapter END), 0),
+			COALESCE(SUM(CASE WHEN reread = 1 THEN to_chapter - from_chapter END), 0)
+		FROM read_events
+		WHERE profile_id = ? AND from_chapter IS NOT NULL
+	`, profileID).Scan(&totals.Chapters, &totals.RereadChapters); err != nil {
+		return models.ReadChapterTotals{}, fmt.Errorf("sum read chapters: %w", err)
+	}
+	return totals, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRereadCycleKeepsTheEarlierCompletion(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Reread Blade', 1, 'https://mangadex.org/title/reread-blade', 'completed', 15, 20)
+	`)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	id, _ := result.LastInsertId()
+	chapter := func(value float64) *float64 { return &value }
+	if _, err := repo.UpdateLastReadChapter(ctx, 1, id, chapter(20)); err != nil {
+		t.Fatalf("finish first read: %v", err)
+	}
+
+	if started, err := repo.StartReread(ctx, 2, id, time.Now()); err != nil || started {
+		t.Fatalf("expected another profile's tracker not to start a re-read, got %v (%v)", started, err)
+	}
+	startedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if started, err := repo.StartReread(ctx, 1, id, startedAt); err != nil || !started {
+		t.Fatalf("expected the re-read to start, got %v (%v)", started, err)
+	}
+	if started, err := repo.StartReread(ctx, 1, id, startedAt); err != nil || started {
+		t.Fatalf("expected a tracker already being re-read not to start again, got %v (%v)", started, err)
+	}
+
+	tracker, err := repo.GetByID(ctx, 1, id)
+	if err != nil || tracker == nil {
+		t.Fatalf("get tracker: %v", err)
+	}
+	if !tracker.Rereading || tracker.Status != "reading" || tracker.LastReadChapter == nil || *tracker.LastReadChapter != 0 {
+		t.Fatalf("expected the tracker to be re-read from chapter 0, got %+v", tracker)
+	}
+	if len(tracker.PreviousCompletions) != 1 {
+		t.Fatalf("expected one previous completion, got %+v", tracker.PreviousCompletions)
+	}
+	completion := tracker.PreviousCompletions[0]
+	if completion.LastReadChapter == nil || *completion.LastReadChapter != 20 || completion.LastReadAt == nil || !completion.RereadStartedAt.Equal(startedAt) {
+		t.Fatalf("expected the completion at chapter 20 to be kept, got %+v", completion)
+	}
+
+	reading, err := repo.List(ctx, TrackerListOptions{ProfileID: 1, Statuses: []string{"reading"}})
+	if err != nil {
+		t.Fatalf("list reading trackers: %v", err)
+	}
+	listed := false
+	for _, item := range reading {
+		listed = listed || item.ID == id
+	}
+	if !listed {
+		t.Fatal("expected the reading filter to include the re-read")
+	}
+
+	if _, err := repo.UpdateLastReadChapter(ctx, 1, id, chapter(10)); err != nil {
+		t.Fatalf("advance re-read: %v", err)
+	}
+	if tracker, _ = repo.GetByID(ctx, 1, id); !tracker.Rereading || tracker.RereadCount != 0 {
+		t.Fatalf("expected the re-read to go on until caught up, got %+v", tracker)
+	}
+	if _, err := repo.UpdateLastReadChapter(ctx, 1, id, chapter(20)); err != nil {
+		t.Fatalf("finish re-read: %v", err)
+	}
+	if tracker, _ = repo.GetByID(ctx, 1, id); tracker.Rereading || tracker.RereadCount != 1 || tracker.Status != "completed" || len(tracker.PreviousCompletions) != 1 {
+		t.Fatalf("expected the caught up re-read to be completed and counted, got %+v", tracker)
+	}
+
+	events, err := repo.ListReadEvents(ctx, 1, id)
+	if err != nil {
+		t.Fatalf("list read events: %v", err)
+	}
+	wantReread := []bool{false, true, true}
+	if len(events) != len(wantReread) {
+		t.Fatalf("expected %d read events, got %+v", len(wantReread), events)
+	}
+	for index, event := range events {
+		if event.Reread != wantReread[index] {
+			t.Fatalf("event %d: expected reread %v, got %+v", index, wantReread[index], event)
+		}
+	}
+
+	totals, err := repo.ReadChapterTotals(ctx, 1)
+	if err != nil {
+		t.Fatalf("read chapter totals: %v", err)
+	}
+	if totals.Chapters != 5 || totals.RereadChapters != 20 {
+		t.Fatalf("expected 5 chapters read first and 20 re-read, got %+v", totals)
+	}
+}
+
+func TestSettingARereadBackToCompletedFinishesIt(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, last_read_chapter, latest_known_chapter)
+		VALUES (1, 'Shelved Blade', 1, 'https://mangadex.org/title/shelved-blade', 'completed', 40, 40)
+	`)
+	if err != nil {
+		t.Fatalf("insert tracker: %v", err)
+	}
+	id, _ := result.LastInsertId()
+	if _, err := repo.StartReread(ctx, 1, id, time.Now()); err != nil {
+		t.Fatalf("start re-read: %v", err)
+	}
+
+	tracker, err := repo.GetByID(ctx, 1, id)
+	if err != nil || tracker == nil {
+		t.Fatalf("get tracker: %v", err)
+	}
+	tracker.Status = "completed"
+	updated, err := repo.Update(ctx, 1, id, tracker)
+	if err != nil {
+		t.Fatalf("update tracker: %v", err)
+	}
+	if updated.Rereading || updated.RereadCount != 1 || updated.Status != "completed" {
+		t.Fatalf("expected marking the re-read completed to finish it, got %+v", updated)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
 	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
 )
@@ -23,6 +24,16 @@ func scanTracker(scanner rowScanner) (*models.Tracker, error) {
 	var latestKnownChapter sql.NullFloat64
 	var latestReleaseAt sql.NullTime
 	var lastCheckedAt sql.NullTime
+	var firstReadAt sql.NullTime
+	var caughtUpAt sql.NullTime
+	var resolveFailure sql.NullString
+	var lastPollError sql.NullString
+	var lastPollErrorAt sql.NullTime
+	var continuedByTrackerID sql.NullInt64
+	var sourceGenresRaw sql.NullString
+	var previousCompletionsRaw sql.NullString
+	var nextScheduledChapter sql.NullFloat64
+	var nextScheduledAt sql.NullTime
 
 	err := scanner.Scan(
 		&tracker.ID,
@@ -39,6 +50,18 @@ func scanTracker(scanner rowScanner) (*models.Tracker, error) {
 		&latestKnownChapter,
 		&latestReleaseAt,
 		&lastCheckedAt,
+		&firstReadAt,
+		&caughtUpAt,
+		&resolveFailure,
+		&lastPollError,
+		&lastPollErrorAt,
+		&continuedByTrackerID,
+		&sourceGenresRaw,
+		&tracker.Rereading,
+		&tracker.RereadCount,
+		&previousCompletionsRaw,
+		&nextScheduledChapter,
+		&nextScheduledAt,
 		&tracker.CreatedAt,
 		&tracker.UpdatedAt,
 	)
@@ -50,7 +73,7 @@ func scanTracker(scanner rowScanner) (*models.Tracker, error) {
 		tracker.SourceItemID = &sourceItemID.String
 	}
 	if relatedTitlesRaw.Valid {
-		decodedRelatedTitles := decodeRelatedTitlesJSON(relatedTitlesRaw.String)
+		decodedRelatedTitles := decodeStringListJSON(relatedTitlesRaw.String)
 		tracker.RelatedTitles = sanitizeRelatedTitles(decodedRelatedTitles)
 	}
 	if lastReadChapter.Valid {
@@ -71,6 +94,36 @@ func scanTracker(scanner rowScanner) (*models.Tracker, error) {
 	if lastCheckedAt.Valid {
 		tracker.LastCheckedAt = &lastCheckedAt.Time
 	}
+	if firstReadAt.Valid {
+		tracker.FirstReadAt = &firstReadAt.Time
+	}
+	if caughtUpAt.Valid {
+		tracker.CaughtUpAt = &caughtUpAt.Time
+	}
+	if resolveFailure.Valid && strings.TrimSpace(resolveFailure.String) != "" {
+		tracker.ResolveFailure = &resolveFailure.String
+	}
+	if lastPollError.Valid && strings.TrimSpace(lastPollError.String) != "" {
+		tracker.LastPollError = &lastPollError.String
+		if lastPollErrorAt.Valid {
+			tracker.LastPollErrorAt = &lastPollErrorAt.Time
+		}
+	}
+	if continuedByTrackerID.Valid {
+		tracker.ContinuedByTrackerID = &continuedByTrackerID.Int64
+	}
+	if sourceGenresRaw.Valid {
+		tracker.SourceGenres = connectors.CleanGenres(decodeStringListJSON(sourceGenresRaw.String))
+	}
+	if previousCompletionsRaw.Valid {
+		tracker.PreviousCompletions = decodeCompletionsJSON(previousCompletionsRaw.String)
+	}
+	if nextScheduledChapter.Valid {
+		tracker.NextScheduledChapter = &nextScheduledChapter.Float64
+		if nextScheduledAt.Valid {
+			tracker.NextScheduledAt = &nextScheduledAt.Time
+		}
+	}
 
 	return &tracker, nil
 }
@@ -89,7 +142,23 @@ func encodeRelatedTitlesJSON(values []string) *string {
 	return &raw
 }
 
-func decodeRelatedTitlesJSON(raw string) []string {
+// encodeSourceGenresJSON returns nil for no genres, which the writes read as
+// keeping the genres already stored.
+func encodeSourceGenresJSON(values []string) *string {
+	genres := connectors.CleanGenres(values)
+	if len(genres) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(genres)
+	if err != nil {
+		return nil
+	}
+	raw := string(encoded)
+	return &raw
+}
+
+func decodeStringListJSON(raw string) []string {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
 		return nil
@@ -103,6 +172,25 @@ func decodeRelatedTitlesJSON(raw string) []string {
 	return values
 }
 
+// decodeCompletionsJSON reads previous_completions, treating a value that
+// does not decode as no completions.
+func decodeCompletionsJSON(raw string) []models.TrackerCompletion {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return nil
+	}
+
+	var completions []models.TrackerCompletion
+	if err := json.Unmarshal([]byte(trimmed), &completions); err != nil {
+		return nil
+	}
+
+	return completions
+}
+
+// sanitizeRelatedTitles runs on both read and write, so a row stored before
+// the limits existed is trimmed the next time the tracker is saved.
 func sanitizeRelatedTitles(values []string) []string {
-	return searchutil.FilterEnglishAlphabetNames(values)
+	titles, _ := searchutil.LimitRelatedTitles(values)
+	return titles
 }
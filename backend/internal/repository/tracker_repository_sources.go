@@ -1,15 +1,17 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
 )
 
-func (r *TrackerRepository) ListLinkedSourceIDs(profileID int64) ([]int64, error) {
-	rows, err := r.db.Query(`
+func (r *TrackerRepository) ListLinkedSourceIDs(ctx context.Context, profileID int64) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT source_id
 		FROM (
 			SELECT source_id
@@ -45,8 +47,10 @@ func (r *TrackerRepository) ListLinkedSourceIDs(profileID int64) ([]int64, error
 	return ids, nil
 }
 
-func (r *TrackerRepository) ListTrackerSources(profileID int64, trackerID int64) ([]models.TrackerSource, error) {
-	rows, err := r.db.Query(`
+// ListTrackerSources lists the tracker's linked sources in their position
+// order.
+func (r *TrackerRepository) ListTrackerSources(ctx context.Context, profileID int64, trackerID int64) ([]models.TrackerSource, error) {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT
 			ts.id,
 			ts.tracker_id,
@@ -54,14 +58,22 @@ func (r *TrackerRepository) ListTrackerSources(profileID int64, trackerID int64)
 			s.name,
 			ts.source_item_id,
 			ts.source_url,
+			ts.lang,
+			ts.chapter_offset,
+			ts.position,
 			ts.created_at,
-			ts.updated_at
+			ts.updated_at,
+			ts.success_count,
+			ts.failure_count,
+			ts.last_lag_chapters,
+			ts.last_poll_failed,
+			ts.mismatch_suspected
 		FROM tracker_sources ts
 		INNER JOIN trackers t ON t.id = ts.tracker_id
 		INNER JOIN sources s ON s.id = ts.source_id
 		WHERE ts.tracker_id = ?
 		  AND t.profile_id = ?
-		ORDER BY s.name ASC, ts.id ASC
+		ORDER BY ts.position ASC, ts.id ASC
 	`, trackerID, profileID)
 	if err != nil {
 		return nil, fmt.Errorf("list tracker sources: %w", err)
@@ -72,6 +84,7 @@ func (r *TrackerRepository) ListTrackerSources(profileID int64, trackerID int64)
 	for rows.Next() {
 		var item models.TrackerSource
 		var sourceItemID sql.NullString
+		var lastLag sql.NullFloat64
 		if err := rows.Scan(
 			&item.ID,
 			&item.TrackerID,
@@ -79,14 +92,25 @@ func (r *TrackerRepository) ListTrackerSources(profileID int64, trackerID int64)
 			&item.SourceName,
 			&sourceItemID,
 			&item.SourceURL,
+			&item.Lang,
+			&item.ChapterOffset,
+			&item.Position,
 			&item.CreatedAt,
 			&item.UpdatedAt,
+			&item.SuccessCount,
+			&item.FailureCount,
+			&lastLag,
+			&item.LastPollFailed,
+			&item.MismatchSuspected,
 		); err != nil {
 			return nil, fmt.Errorf("scan tracker source: %w", err)
 		}
 		if sourceItemID.Valid {
 			item.SourceItemID = &sourceItemID.String
 		}
+		if lastLag.Valid {
+			item.LastLagChapters = &lastLag.Float64
+		}
 		items = append(items, item)
 	}
 
@@ -97,32 +121,254 @@ func (r *TrackerRepository) ListTrackerSources(profileID int64, trackerID int64)
 	return items, nil
 }
 
-func (r *TrackerRepository) ReplaceTrackerSources(profileID int64, trackerID int64, sources []models.TrackerSource) error {
-	tx, err := r.db.Begin()
+// primaryChapterOffset is the chapter offset of the tracker_sources row that
+// is the trackers row's primary source, or 0 when it has none.
+const primaryChapterOffset = `COALESCE((
+	SELECT primary_ts.chapter_offset
+	FROM tracker_sources primary_ts
+	WHERE primary_ts.tracker_id = trackers.id
+	  AND primary_ts.source_id = trackers.source_id
+	  AND LOWER(primary_ts.source_url) = LOWER(trackers.source_url)
+	ORDER BY primary_ts.id ASC
+	LIMIT 1
+), 0)`
+
+// trackerSourceIsPrimary is true for the tracker_sources row ts that is its
+// tracker t's primary source.
+const trackerSourceIsPrimary = `(ts.source_id = t.source_id AND LOWER(ts.source_url) = LOWER(t.source_url))`
+
+// ListTrackerSourcesByProfile lists the profile's tracker_sources rows with
+// their trackers, grouped by source name and then by tracker title.
+func (r *TrackerRepository) ListTrackerSourcesByProfile(ctx context.Context, profileID int64, opts TrackerSourceListOptions) ([]models.ProfileTrackerSource, error) {
+	conditions := []string{"t.profile_id = ?"}
+	args := []any{profileID}
+	if opts.SourceID > 0 {
+		conditions = append(conditions, "ts.source_id = ?")
+		args = append(args, opts.SourceID)
+	}
+	switch opts.Role {
+	case TrackerSourceRolePrimary:
+		conditions = append(conditions, trackerSourceIsPrimary)
+	case TrackerSourceRoleLinked:
+		conditions = append(conditions, "NOT "+trackerSourceIsPrimary)
+	}
+	limitClause := ""
+	if opts.Limit > 0 {
+		limitClause = "LIMIT ? OFFSET ?"
+		args = append(args, opts.Limit, max(opts.Offset, 0))
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			ts.id,
+			ts.tracker_id,
+			ts.source_id,
+			s.key,
+			s.name,
+			ts.source_item_id,
+			ts.source_url,
+			ts.lang,
+			ts.chapter_offset,
+			ts.position,
+			ts.created_at,
+			ts.updated_at,
+			ts.success_count,
+			ts.failure_count,
+			ts.last_lag_chapters,
+			ts.last_poll_failed,
+			ts.mismatch_suspected,
+			t.title,
+			t.status,
+			%s
+		FROM tracker_sources ts
+		INNER JOIN trackers t ON t.id = ts.tracker_id
+		INNER JOIN sources s ON s.id = ts.source_id
+		WHERE %s
+		ORDER BY s.name COLLATE NOCASE ASC, ts.source_id ASC, t.title COLLATE NOCASE ASC, ts.id ASC
+		%s
+	`, trackerSourceIsPrimary, strings.Join(conditions, " AND "), limitClause), args...)
+	if err != nil {
+		return nil, fmt.Errorf("list profile tracker sources: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]models.ProfileTrackerSource, 0)
+	for rows.Next() {
+		var item models.ProfileTrackerSource
+		var sourceItemID sql.NullString
+		var lastLag sql.NullFloat64
+		if err := rows.Scan(
+			&item.ID,
+			&item.TrackerID,
+			&item.SourceID,
+			&item.SourceKey,
+			&item.SourceName,
+			&sourceItemID,
+			&item.SourceURL,
+			&item.Lang,
+			&item.ChapterOffset,
+			&item.Position,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+			&item.SuccessCount,
+			&item.FailureCount,
+			&lastLag,
+			&item.LastPollFailed,
+			&item.MismatchSuspected,
+			&item.TrackerTitle,
+			&item.TrackerStatus,
+			&item.Primary,
+		); err != nil {
+			return nil, fmt.Errorf("scan profile tracker source: %w", err)
+		}
+		if sourceItemID.Valid {
+			item.SourceItemID = &sourceItemID.String
+		}
+		if lastLag.Valid {
+			item.LastLagChapters = &lastLag.Float64
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate profile tracker sources: %w", err)
+	}
+
+	return items, nil
+}
+
+// CountTrackerSourcesBySource counts the profile's tracker_sources rows per
+// source, in the order ListTrackerSourcesByProfile groups them.
+func (r *TrackerRepository) CountTrackerSourcesBySource(ctx context.Context, profileID int64) ([]models.TrackerSourceCount, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			s.id,
+			s.key,
+			s.name,
+			COALESCE(SUM(CASE WHEN %[1]s THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN %[1]s THEN 0 ELSE 1 END), 0)
+		FROM tracker_sources ts
+		INNER JOIN trackers t ON t.id = ts.tracker_id
+		INNER JOIN sources s ON s.id = ts.source_id
+		WHERE t.profile_id = ?
+		GROUP BY s.id, s.key, s.name
+		ORDER BY s.name COLLATE NOCASE ASC, s.id ASC
+	`, trackerSourceIsPrimary), profileID)
+	if err != nil {
+		return nil, fmt.Errorf("count profile tracker sources: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]models.TrackerSourceCount, 0)
+	for rows.Next() {
+		var count models.TrackerSourceCount
+		if err := rows.Scan(&count.SourceID, &count.SourceKey, &count.SourceName, &count.Primary, &count.Linked); err != nil {
+			return nil, fmt.Errorf("scan tracker source count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tracker source counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (r *TrackerRepository) ReplaceTrackerSources(ctx context.Context, profileID int64, trackerID int64, sources []models.TrackerSource) error {
+	tx, err := r.begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin replace tracker sources tx: %w", err)
 	}
 
-	if _, err := tx.Exec(`
-		DELETE FROM tracker_sources
-		WHERE tracker_id = ?
-		  AND EXISTS (SELECT 1 FROM trackers t WHERE t.id = ? AND t.profile_id = ?)
-	`, trackerID, trackerID, profileID); err != nil {
+	var owned int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(1) FROM trackers WHERE id = ? AND profile_id = ?`, trackerID, profileID).Scan(&owned); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("check tracker ownership: %w", err)
+	}
+	if owned == 0 {
 		tx.Rollback()
-		return fmt.Errorf("delete tracker sources: %w", err)
+		return nil
+	}
+
+	// Rows that survive the replacement keep their poll statistics, so only
+	// links that were actually removed are deleted.
+	keep := make(map[string]bool, len(sources))
+	replacementURLs := make(map[int64]string, len(sources))
+	for _, source := range sources {
+		if strings.TrimSpace(source.SourceURL) == "" || source.SourceID <= 0 {
+			continue
+		}
+		keep[trackerSourceKey(source.SourceID, source.SourceURL)] = true
+		if _, ok := replacementURLs[source.SourceID]; !ok {
+			replacementURLs[source.SourceID] = source.SourceURL
+		}
+	}
+
+	existingRows, err := tx.QueryContext(ctx, `SELECT id, source_id, source_url FROM tracker_sources WHERE tracker_id = ?`, trackerID)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("list existing tracker sources: %w", err)
+	}
+	staleIDs := make([]int64, 0)
+	// A removed link whose site is still linked at another URL was rewritten
+	// rather than dropped, and its old URL goes to the URL history.
+	replaced := make([]models.TrackerSource, 0)
+	for existingRows.Next() {
+		var id, sourceID int64
+		var sourceURL string
+		if err := existingRows.Scan(&id, &sourceID, &sourceURL); err != nil {
+			existingRows.Close()
+			tx.Rollback()
+			return fmt.Errorf("scan existing tracker source: %w", err)
+		}
+		if !keep[trackerSourceKey(sourceID, sourceURL)] {
+			staleIDs = append(staleIDs, id)
+			if _, ok := replacementURLs[sourceID]; ok {
+				replaced = append(replaced, models.TrackerSource{SourceID: sourceID, SourceURL: sourceURL})
+			}
+		}
+	}
+	if err := existingRows.Err(); err != nil {
+		existingRows.Close()
+		tx.Rollback()
+		return fmt.Errorf("iterate existing tracker sources: %w", err)
+	}
+	existingRows.Close()
+
+	for _, id := range staleIDs {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tracker_sources WHERE id = ?`, id); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("delete tracker source: %w", err)
+		}
+	}
+	for _, source := range replaced {
+		if err := recordReplacedURL(ctx, tx, trackerID, source.SourceID, source.SourceURL, replacementURLs[source.SourceID], URLHistoryEdit); err != nil {
+			tx.Rollback()
+			return err
+		}
 	}
 
+	// The sources are positioned in the order given.
+	position := 0
 	for _, source := range sources {
 		if strings.TrimSpace(source.SourceURL) == "" || source.SourceID <= 0 {
 			continue
 		}
-		if _, err := tx.Exec(`
-			INSERT INTO tracker_sources (tracker_id, source_id, source_item_id, source_url)
-			VALUES (?, ?, ?, ?)
-		`, trackerID, source.SourceID, source.SourceItemID, strings.TrimSpace(source.SourceURL)); err != nil {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO tracker_sources (tracker_id, source_id, source_item_id, source_url, lang, chapter_offset, position)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(tracker_id, source_id, source_url)
+			DO UPDATE SET
+				source_item_id = excluded.source_item_id,
+				lang = excluded.lang,
+				chapter_offset = excluded.chapter_offset,
+				position = excluded.position,
+				updated_at = CURRENT_TIMESTAMP
+		`, trackerID, source.SourceID, source.SourceItemID, strings.TrimSpace(source.SourceURL), trackerSourceLang(source.Lang), source.ChapterOffset, position); err != nil {
 			tx.Rollback()
 			return fmt.Errorf("insert tracker source: %w", err)
 		}
+		position++
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -132,30 +378,272 @@ func (r *TrackerRepository) ReplaceTrackerSources(profileID int64, trackerID int
 	return nil
 }
 
-func (r *TrackerRepository) UpsertTrackerSource(profileID int64, trackerID int64, source models.TrackerSource) error {
+// UpsertTrackerSource links a source to the tracker, after its other linked
+// sources. An existing link keeps its language unless source.Lang is set,
+// and always keeps its chapter offset and position.
+func (r *TrackerRepository) UpsertTrackerSource(ctx context.Context, profileID int64, trackerID int64, source models.TrackerSource) error {
 	if source.SourceID <= 0 || strings.TrimSpace(source.SourceURL) == "" {
 		return nil
 	}
 
 	var exists int
-	if err := r.db.QueryRow(`SELECT COUNT(1) FROM trackers WHERE id = ? AND profile_id = ?`, trackerID, profileID).Scan(&exists); err != nil {
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM trackers WHERE id = ? AND profile_id = ?`, trackerID, profileID).Scan(&exists); err != nil {
 		return fmt.Errorf("check tracker ownership: %w", err)
 	}
 	if exists == 0 {
 		return nil
 	}
 
-	_, err := r.db.Exec(`
-		INSERT INTO tracker_sources (tracker_id, source_id, source_item_id, source_url)
-		VALUES (?, ?, ?, ?)
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO tracker_sources (tracker_id, source_id, source_item_id, source_url, lang, position)
+		VALUES (?, ?, ?, ?, ?, `+nextTrackerSourcePosition+`)
 		ON CONFLICT(tracker_id, source_id, source_url)
 		DO UPDATE SET
 			source_item_id = excluded.source_item_id,
+			lang = CASE WHEN ? <> '' THEN excluded.lang ELSE tracker_sources.lang END,
 			updated_at = CURRENT_TIMESTAMP
-	`, trackerID, source.SourceID, source.SourceItemID, strings.TrimSpace(source.SourceURL))
+	`, trackerID, source.SourceID, source.SourceItemID, strings.TrimSpace(source.SourceURL), trackerSourceLang(source.Lang), trackerID, strings.TrimSpace(source.Lang))
 	if err != nil {
 		return fmt.Errorf("upsert tracker source: %w", err)
 	}
 
 	return nil
 }
+
+// nextTrackerSourcePosition is the position after the last linked source of
+// the tracker bound to its placeholder.
+const nextTrackerSourcePosition = `(SELECT COALESCE(MAX(position) + 1, 0) FROM tracker_sources WHERE tracker_id = ?)`
+
+// ReorderTrackerSources positions the tracker's linked sources in the order
+// of trackerSourceIDs. It reports false, changing nothing, unless the ids
+// are exactly the tracker's tracker_sources rows, each once.
+func (r *TrackerRepository) ReorderTrackerSources(ctx context.Context, profileID int64, trackerID int64, trackerSourceIDs []int64) (bool, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin reorder tracker sources tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT ts.id
+		FROM tracker_sources ts
+		INNER JOIN trackers t ON t.id = ts.tracker_id
+		WHERE ts.tracker_id = ?
+		  AND t.profile_id = ?
+	`, trackerID, profileID)
+	if err != nil {
+		return false, fmt.Errorf("list tracker source ids: %w", err)
+	}
+	existing := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("scan tracker source id: %w", err)
+		}
+		existing[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return false, fmt.Errorf("iterate tracker source ids: %w", err)
+	}
+	rows.Close()
+
+	if len(existing) == 0 || len(trackerSourceIDs) != len(existing) {
+		return false, nil
+	}
+	for _, id := range trackerSourceIDs {
+		if !existing[id] {
+			return false, nil
+		}
+		// Each id counts once, so a repeated one leaves another unmatched.
+		delete(existing, id)
+	}
+
+	for position, id := range trackerSourceIDs {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE tracker_sources
+			SET position = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND tracker_id = ?
+		`, position, id, trackerID); err != nil {
+			return false, fmt.Errorf("update tracker source position: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit reorder tracker sources tx: %w", err)
+	}
+	return true, nil
+}
+
+// SetPrimarySource copies the source, item id and URL of the tracker's
+// linked source row trackerSourceID onto the tracker, leaving every
+// tracker_sources row as it was. The last read and latest chapters are moved
+// into the new primary's numbering by the difference of the two sources'
+// chapter offsets. The replaced primary URL goes to the URL history. It
+// reports false when the row is not one of the profile's tracker's linked
+// sources.
+func (r *TrackerRepository) SetPrimarySource(ctx context.Context, profileID int64, trackerID int64, trackerSourceID int64) (bool, error) {
+	previousSourceID, previousSourceURL, err := r.primarySourceOf(ctx, profileID, trackerID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE trackers
+		SET source_id = ts.source_id,
+			source_item_id = ts.source_item_id,
+			source_url = ts.source_url,
+			last_read_chapter = MAX(0, trackers.last_read_chapter + `+primaryChapterOffset+` - ts.chapter_offset),
+			latest_known_chapter = MAX(0, trackers.latest_known_chapter + `+primaryChapterOffset+` - ts.chapter_offset),
+			resolve_failure = NULL,
+			last_poll_error = NULL,
+			last_poll_error_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		FROM tracker_sources ts
+		WHERE ts.id = ?
+		  AND ts.tracker_id = trackers.id
+		  AND trackers.id = ?
+		  AND trackers.profile_id = ?
+	`, trackerSourceID, trackerID, profileID)
+	if err != nil {
+		return false, fmt.Errorf("set primary source: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("set primary source rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	_, sourceURL, err := r.primarySourceOf(ctx, profileID, trackerID)
+	if err != nil {
+		return false, err
+	}
+	if err := recordReplacedURL(ctx, r.db, trackerID, previousSourceID, previousSourceURL, sourceURL, URLHistoryPromotion); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RecordTrackerSourcePolls folds one poll cycle's outcome for each linked
+// source into its success/failure counters and lag versus the best source.
+func (r *TrackerRepository) RecordTrackerSourcePolls(ctx context.Context, trackerID int64, results []TrackerSourcePollResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin record tracker source polls tx: %w", err)
+	}
+
+	for _, result := range results {
+		sourceURL := strings.TrimSpace(result.SourceURL)
+		if result.SourceID <= 0 || sourceURL == "" {
+			continue
+		}
+
+		var execErr error
+		if result.OK {
+			_, execErr = tx.ExecContext(ctx, `
+				UPDATE tracker_sources
+				SET success_count = success_count + 1,
+					last_lag_chapters = ?,
+					last_poll_failed = 0,
+					mismatch_suspected = CASE WHEN ? THEN 1 ELSE mismatch_suspected END
+				WHERE tracker_id = ? AND source_id = ? AND LOWER(source_url) = LOWER(?)
+			`, result.LagChapters, result.MismatchSuspected, trackerID, result.SourceID, sourceURL)
+		} else {
+			_, execErr = tx.ExecContext(ctx, `
+				UPDATE tracker_sources
+				SET failure_count = failure_count + 1,
+					last_poll_failed = 1
+				WHERE tracker_id = ? AND source_id = ? AND LOWER(source_url) = LOWER(?)
+			`, trackerID, result.SourceID, sourceURL)
+		}
+		if execErr != nil {
+			tx.Rollback()
+			return fmt.Errorf("record tracker source poll: %w", execErr)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit record tracker source polls tx: %w", err)
+	}
+
+	return nil
+}
+
+// FlagTrackerSourceMismatch marks the linked source as possibly a different
+// series than its tracker.
+func (r *TrackerRepository) FlagTrackerSourceMismatch(ctx context.Context, profileID int64, trackerID int64, sourceID int64, sourceURL string) error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE tracker_sources
+		SET mismatch_suspected = 1
+		WHERE tracker_id = ?
+		  AND source_id = ?
+		  AND LOWER(source_url) = LOWER(?)
+		  AND tracker_id IN (SELECT id FROM trackers WHERE profile_id = ?)
+	`, trackerID, sourceID, strings.TrimSpace(sourceURL), profileID); err != nil {
+		return fmt.Errorf("flag tracker source mismatch: %w", err)
+	}
+	return nil
+}
+
+// DismissTrackerSourceMismatch clears the mismatch flag on one linked source
+// row and reports whether the row belongs to the tracker.
+func (r *TrackerRepository) DismissTrackerSourceMismatch(ctx context.Context, profileID int64, trackerID int64, trackerSourceID int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE tracker_sources
+		SET mismatch_suspected = 0
+		WHERE id = ?
+		  AND tracker_id = ?
+		  AND tracker_id IN (SELECT id FROM trackers WHERE profile_id = ?)
+	`, trackerSourceID, trackerID, profileID)
+	if err != nil {
+		return false, fmt.Errorf("dismiss tracker source mismatch: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("tracker source mismatch dismiss rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// trackerSourceLang stores a blank language as the column default.
+func trackerSourceLang(lang string) string {
+	if lang = strings.TrimSpace(lang); lang != "" {
+		return lang
+	}
+	return "en"
+}
+
+// trackerSourceLangAt returns the language of the tracker's link to
+// sourceURL, or "" when there is none, so a link that moves to a new URL can
+// keep it.
+func (r *TrackerRepository) trackerSourceLangAt(ctx context.Context, trackerID int64, sourceID int64, sourceURL string) (string, error) {
+	var lang string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT lang
+		FROM tracker_sources
+		WHERE tracker_id = ? AND source_id = ? AND LOWER(source_url) = LOWER(?)
+		ORDER BY id ASC
+		LIMIT 1
+	`, trackerID, sourceID, strings.TrimSpace(sourceURL)).Scan(&lang)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get tracker source lang: %w", err)
+	}
+	return lang, nil
+}
+
+func trackerSourceKey(sourceID int64, sourceURL string) string {
+	return fmt.Sprintf("%d|%s", sourceID, strings.TrimSpace(sourceURL))
+}
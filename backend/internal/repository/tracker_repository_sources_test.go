@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+func TestRecordTrackerSourcePolls_SurvivesReplace(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+
+	var trackerID int64
+	if err := db.QueryRow(`SELECT id FROM trackers WHERE title = 'Alpha Blade'`).Scan(&trackerID); err != nil {
+		t.Fatalf("load tracker: %v", err)
+	}
+	linkedURL := "https://mangadex.org/title/alpha/linked"
+
+	lag := 2.0
+	results := []TrackerSourcePollResult{{SourceID: 3, SourceURL: linkedURL, OK: true, LagChapters: &lag}}
+	if err := repo.RecordTrackerSourcePolls(context.Background(), trackerID, results); err != nil {
+		t.Fatalf("record success: %v", err)
+	}
+	if err := repo.RecordTrackerSourcePolls(context.Background(), trackerID, []TrackerSourcePollResult{{SourceID: 3, SourceURL: linkedURL}}); err != nil {
+		t.Fatalf("record failure: %v", err)
+	}
+
+	err := repo.ReplaceTrackerSources(context.Background(), 1, trackerID, []models.TrackerSource{
+		{SourceID: 3, SourceURL: linkedURL},
+		{SourceID: 2, SourceURL: "https://mangafire.to/manga/alpha"},
+	})
+	if err != nil {
+		t.Fatalf("replace tracker sources: %v", err)
+	}
+
+	sources, err := repo.ListTrackerSources(context.Background(), 1, trackerID)
+	if err != nil {
+		t.Fatalf("list tracker sources: %v", err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 linked sources, got %d", len(sources))
+	}
+
+	for _, source := range sources {
+		switch source.SourceID {
+		case 3:
+			if source.SuccessCount != 1 || source.FailureCount != 1 || !source.LastPollFailed {
+				t.Fatalf("expected preserved stats on kept source, got %+v", source)
+			}
+			if source.LastLagChapters == nil || *source.LastLagChapters != lag {
+				t.Fatalf("expected preserved lag %.0f, got %#v", lag, source.LastLagChapters)
+			}
+		case 2:
+			if source.SuccessCount != 0 || source.FailureCount != 0 || source.LastLagChapters != nil {
+				t.Fatalf("expected fresh stats on new source, got %+v", source)
+			}
+		default:
+			t.Fatalf("unexpected linked source %+v", source)
+		}
+	}
+}
+
+func TestTrackerSourceLangIsKeptAcrossUpdates(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+	primaryURL := "https://mangadex.org/title/alpha"
+
+	langOf := func(sourceURL string) string {
+		t.Helper()
+		var lang string
+		if err := db.QueryRow(`SELECT lang FROM tracker_sources WHERE tracker_id = ? AND source_url = ?`, alpha, sourceURL).Scan(&lang); err != nil {
+			t.Fatalf("load lang of %s: %v", sourceURL, err)
+		}
+		return lang
+	}
+
+	if err := repo.UpsertTrackerSource(context.Background(), 1, alpha, models.TrackerSource{SourceID: 1, SourceURL: primaryURL, Lang: "pt-br"}); err != nil {
+		t.Fatalf("upsert with lang: %v", err)
+	}
+	if err := repo.UpsertTrackerSource(context.Background(), 1, alpha, models.TrackerSource{SourceID: 1, SourceURL: primaryURL}); err != nil {
+		t.Fatalf("upsert without lang: %v", err)
+	}
+	if lang := langOf(primaryURL); lang != "pt-br" {
+		t.Fatalf("expected an upsert without lang to keep pt-br, got %q", lang)
+	}
+
+	items, err := repo.ListForPolling(context.Background())
+	if err != nil {
+		t.Fatalf("list for polling: %v", err)
+	}
+	for _, item := range items {
+		if item.ID != alpha {
+			continue
+		}
+		if item.SourceLang != "pt-br" {
+			t.Fatalf("expected primary source lang pt-br, got %q", item.SourceLang)
+		}
+		for _, source := range item.LinkedSources {
+			if source.SourceID == 3 && source.Lang != "en" {
+				t.Fatalf("expected the linked source to default to en, got %q", source.Lang)
+			}
+		}
+	}
+
+	movedURL := primaryURL + "-moved"
+	if err := repo.UpdatePollingState(context.Background(), alpha, 1, primaryURL, nil, movedURL, nil, nil, false, time.Now()); err != nil {
+		t.Fatalf("update polling state: %v", err)
+	}
+	if lang := langOf(movedURL); lang != "pt-br" {
+		t.Fatalf("expected the moved link to keep pt-br, got %q", lang)
+	}
+
+	if err := repo.ReplaceTrackerSources(context.Background(), 1, alpha, []models.TrackerSource{{SourceID: 1, SourceURL: movedURL, Lang: "es"}}); err != nil {
+		t.Fatalf("replace tracker sources: %v", err)
+	}
+	if lang := langOf(movedURL); lang != "es" {
+		t.Fatalf("expected replace to set es, got %q", lang)
+	}
+}
+
+func TestSetPrimarySourceMovesChaptersByChapterOffset(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+	primaryURL := "https://mangadex.org/title/alpha"
+	splitURL := "https://mangafire.to/manga/alpha-split"
+
+	// The split site counts four more chapters, so its 100 is chapter 96
+	// on the primary.
+	if err := repo.ReplaceTrackerSources(context.Background(), 1, alpha, []models.TrackerSource{
+		{SourceID: 1, SourceURL: primaryURL},
+		{SourceID: 2, SourceURL: splitURL, ChapterOffset: -4},
+	}); err != nil {
+		t.Fatalf("replace tracker sources: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE trackers SET source_id = 1, source_url = ?, last_read_chapter = 90, latest_known_chapter = 96 WHERE id = ?`, primaryURL, alpha); err != nil {
+		t.Fatalf("set chapters: %v", err)
+	}
+
+	sources, err := repo.ListTrackerSources(context.Background(), 1, alpha)
+	if err != nil {
+		t.Fatalf("list tracker sources: %v", err)
+	}
+	var splitID int64
+	for _, source := range sources {
+		if source.SourceURL == splitURL {
+			splitID = source.ID
+			if source.ChapterOffset != -4 {
+				t.Fatalf("expected the split source offset to round-trip, got %v", source.ChapterOffset)
+			}
+		}
+	}
+
+	items, err := repo.ListForPolling(context.Background())
+	if err != nil {
+		t.Fatalf("list for polling: %v", err)
+	}
+	for _, item := range items {
+		for _, source := range item.LinkedSources {
+			if item.ID == alpha && source.SourceURL == splitURL && source.ChapterOffset != -4 {
+				t.Fatalf("expected the poller to see the split source offset, got %v", source.ChapterOffset)
+			}
+		}
+	}
+
+	if changed, err := repo.SetPrimarySource(context.Background(), 1, alpha, splitID); err != nil || !changed {
+		t.Fatalf("set primary source: changed=%v err=%v", changed, err)
+	}
+	var lastRead, latest float64
+	if err := db.QueryRow(`SELECT last_read_chapter, latest_known_chapter FROM trackers WHERE id = ?`, alpha).Scan(&lastRead, &latest); err != nil {
+		t.Fatalf("load chapters: %v", err)
+	}
+	if lastRead != 94 || latest != 100 {
+		t.Fatalf("expected chapters in the split site's numbering (94/100), got %v/%v", lastRead, latest)
+	}
+}
+
+func TestMigrateSourceURLMovesTrackerAndLinkedRows(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+
+	oldURL := "https://mangadex.org/title/alpha"
+	newURL := "https://mangadex.org/title/alpha-moved"
+	if _, err := db.Exec(`INSERT INTO tracker_sources (tracker_id, source_id, source_url) VALUES (?, 1, ?)`, alpha, oldURL); err != nil {
+		t.Fatalf("seed primary link: %v", err)
+	}
+	var updatedBefore string
+	if err := db.QueryRow(`SELECT updated_at FROM trackers WHERE id = ?`, alpha).Scan(&updatedBefore); err != nil {
+		t.Fatalf("load updated_at: %v", err)
+	}
+
+	if err := repo.MigrateSourceURL(ctx, alpha, 1, oldURL, newURL); err != nil {
+		t.Fatalf("migrate primary url: %v", err)
+	}
+	var sourceURL, updatedAfter string
+	if err := db.QueryRow(`SELECT source_url, updated_at FROM trackers WHERE id = ?`, alpha).Scan(&sourceURL, &updatedAfter); err != nil {
+		t.Fatalf("load tracker: %v", err)
+	}
+	if sourceURL != newURL || updatedAfter != updatedBefore {
+		t.Fatalf("expected the url moved without touching updated_at, got %q (%s -> %s)", sourceURL, updatedBefore, updatedAfter)
+	}
+	var oldRows, newRows int
+	_ = db.QueryRow(`SELECT COUNT(1) FROM tracker_sources WHERE tracker_id = ? AND source_url = ?`, alpha, oldURL).Scan(&oldRows)
+	_ = db.QueryRow(`SELECT COUNT(1) FROM tracker_sources WHERE tracker_id = ? AND source_url = ?`, alpha, newURL).Scan(&newRows)
+	if oldRows != 0 || newRows != 1 {
+		t.Fatalf("expected the primary link to move, got old=%d new=%d", oldRows, newRows)
+	}
+
+	// A linked row that already exists at the new URL absorbs the old one.
+	linkedOld := oldURL + "/linked"
+	if _, err := db.Exec(`INSERT INTO tracker_sources (tracker_id, source_id, source_url) VALUES (?, 3, ?)`, alpha, newURL+"/linked"); err != nil {
+		t.Fatalf("seed moved link: %v", err)
+	}
+	if err := repo.MigrateSourceURL(ctx, alpha, 3, linkedOld, newURL+"/linked"); err != nil {
+		t.Fatalf("migrate linked url: %v", err)
+	}
+	var linkedRows int
+	_ = db.QueryRow(`SELECT COUNT(1) FROM tracker_sources WHERE tracker_id = ? AND source_id = 3`, alpha).Scan(&linkedRows)
+	if linkedRows != 1 {
+		t.Fatalf("expected the old linked row merged into the new one, got %d rows", linkedRows)
+	}
+}
+
+func TestListTrackerSourcesByProfileGroupsBySourceAndMarksPrimary(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	// Every tracker gets its primary row; Beta Blade is also followed on
+	// source 1, next to Alpha Blade's seeded link on source 3.
+	if _, err := db.Exec(`
+		INSERT INTO tracker_sources (tracker_id, source_id, source_url)
+		SELECT id, source_id, source_url FROM trackers
+	`); err != nil {
+		t.Fatalf("seed primary sources: %v", err)
+	}
+	betaID := trackerIDByTitle(t, repo, "Beta Blade")
+	if _, err := db.Exec(`INSERT INTO tracker_sources (tracker_id, source_id, source_url) VALUES (?, 1, 'https://mangadex.org/title/beta')`, betaID); err != nil {
+		t.Fatalf("seed beta link: %v", err)
+	}
+
+	counts, err := repo.CountTrackerSourcesBySource(ctx, 1)
+	if err != nil {
+		t.Fatalf("count tracker sources: %v", err)
+	}
+	wantCounts := map[int64][2]int{1: {2, 1}, 2: {2, 0}, 3: {1, 1}}
+	if len(counts) != len(wantCounts) {
+		t.Fatalf("expected counts for %d sources, got %+v", len(wantCounts), counts)
+	}
+	for i, count := range counts {
+		want := wantCounts[count.SourceID]
+		if count.Primary != want[0] || count.Linked != want[1] {
+			t.Fatalf("expected source %d to count %d primary and %d linked, got %+v", count.SourceID, want[0], want[1], count)
+		}
+		if i > 0 && strings.ToLower(counts[i-1].SourceName) > strings.ToLower(count.SourceName) {
+			t.Fatalf("expected counts ordered by source name, got %+v", counts)
+		}
+	}
+
+	items, err := repo.ListTrackerSourcesByProfile(ctx, 1, TrackerSourceListOptions{})
+	if err != nil {
+		t.Fatalf("list tracker sources: %v", err)
+	}
+	if len(items) != 7 {
+		t.Fatalf("expected 7 rows for profile 1, got %d", len(items))
+	}
+	seenSources := map[int64]bool{}
+	for i, item := range items {
+		if item.TrackerTitle == "Other Profile Blade" {
+			t.Fatalf("expected other profiles' rows left out, got %+v", item)
+		}
+		if i > 0 && items[i-1].SourceID != item.SourceID && seenSources[item.SourceID] {
+			t.Fatalf("expected rows grouped by source, source %d came back after another source", item.SourceID)
+		}
+		seenSources[item.SourceID] = true
+		if item.SourceKey == "" || item.TrackerStatus == "" {
+			t.Fatalf("expected the source key and tracker status joined in, got %+v", item)
+		}
+	}
+
+	linked, err := repo.ListTrackerSourcesByProfile(ctx, 1, TrackerSourceListOptions{Role: TrackerSourceRoleLinked})
+	if err != nil {
+		t.Fatalf("list linked tracker sources: %v", err)
+	}
+	if len(linked) != 2 {
+		t.Fatalf("expected 2 linked-only rows, got %+v", linked)
+	}
+	for _, item := range linked {
+		if item.Primary {
+			t.Fatalf("expected only linked rows, got %+v", item)
+		}
+		if (item.TrackerTitle == "Alpha Blade" && item.SourceID != 3) || (item.TrackerTitle == "Beta Blade" && item.SourceID != 1) {
+			t.Fatalf("unexpected linked row %+v", item)
+		}
+	}
+
+	primary, err := repo.ListTrackerSourcesByProfile(ctx, 1, TrackerSourceListOptions{SourceID: 1, Role: TrackerSourceRolePrimary})
+	if err != nil {
+		t.Fatalf("list primary tracker sources: %v", err)
+	}
+	if len(primary) != 2 || primary[0].TrackerTitle != "Alpha Blade" || primary[1].TrackerTitle != "Gamma Tower" || !primary[0].Primary {
+		t.Fatalf("expected Alpha Blade and Gamma Tower as source 1 primaries, got %+v", primary)
+	}
+
+	paged, err := repo.ListTrackerSourcesByProfile(ctx, 1, TrackerSourceListOptions{SourceID: 1, Limit: 1, Offset: 2})
+	if err != nil {
+		t.Fatalf("list paged tracker sources: %v", err)
+	}
+	if len(paged) != 1 || paged[0].TrackerTitle != "Gamma Tower" {
+		t.Fatalf("expected the third source 1 row to be Gamma Tower, got %+v", paged)
+	}
+}
+
+func TestTrackerSourcesKeepTheirPositions(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+
+	sourceIDsOf := func() []int64 {
+		t.Helper()
+		sources, err := repo.ListTrackerSources(ctx, 1, alpha)
+		if err != nil {
+			t.Fatalf("list tracker sources: %v", err)
+		}
+		ids := make([]int64, 0, len(sources))
+		for index, source := range sources {
+			if source.Position != index {
+				t.Fatalf("expected positions counting from 0, got %+v", sources)
+			}
+			ids = append(ids, source.ID)
+		}
+		return ids
+	}
+	sourceOrderOf := func() []int64 {
+		t.Helper()
+		sources, err := repo.ListTrackerSources(ctx, 1, alpha)
+		if err != nil {
+			t.Fatalf("list tracker sources: %v", err)
+		}
+		order := make([]int64, 0, len(sources))
+		for _, source := range sources {
+			order = append(order, source.SourceID)
+		}
+		return order
+	}
+
+	// The given order wins over source names.
+	if err := repo.ReplaceTrackerSources(ctx, 1, alpha, []models.TrackerSource{
+		{SourceID: 3, SourceURL: "https://mangadex.org/title/alpha/linked"},
+		{SourceID: 1, SourceURL: "https://mangadex.org/title/alpha"},
+		{SourceID: 2, SourceURL: "https://mangafire.to/manga/alpha"},
+	}); err != nil {
+		t.Fatalf("replace tracker sources: %v", err)
+	}
+	if order := sourceOrderOf(); !reflect.DeepEqual(order, []int64{3, 1, 2}) {
+		t.Fatalf("expected the replaced order, got %v", order)
+	}
+
+	ids := sourceIDsOf()
+	if reordered, err := repo.ReorderTrackerSources(ctx, 1, alpha, []int64{ids[2], ids[0]}); err != nil || reordered {
+		t.Fatalf("expected a partial order rejected, got %v %v", reordered, err)
+	}
+	if reordered, err := repo.ReorderTrackerSources(ctx, 1, alpha, []int64{ids[2], ids[0], ids[0]}); err != nil || reordered {
+		t.Fatalf("expected a repeated id rejected, got %v %v", reordered, err)
+	}
+	if reordered, err := repo.ReorderTrackerSources(ctx, 2, alpha, []int64{ids[2], ids[0], ids[1]}); err != nil || reordered {
+		t.Fatalf("expected another profile's reorder rejected, got %v %v", reordered, err)
+	}
+	if order := sourceOrderOf(); !reflect.DeepEqual(order, []int64{3, 1, 2}) {
+		t.Fatalf("expected rejected reorders to change nothing, got %v", order)
+	}
+
+	if reordered, err := repo.ReorderTrackerSources(ctx, 1, alpha, []int64{ids[2], ids[0], ids[1]}); err != nil || !reordered {
+		t.Fatalf("reorder tracker sources: %v %v", reordered, err)
+	}
+	if order := sourceOrderOf(); !reflect.DeepEqual(order, []int64{2, 3, 1}) {
+		t.Fatalf("expected the new order, got %v", order)
+	}
+
+	// A newly linked source goes last; relinking one leaves it in place.
+	if err := repo.UpsertTrackerSource(ctx, 1, alpha, models.TrackerSource{SourceID: 4, SourceURL: "https://example.com/alpha"}); err != nil {
+		t.Fatalf("upsert new tracker source: %v", err)
+	}
+	if err := repo.UpsertTrackerSource(ctx, 1, alpha, models.TrackerSource{SourceID: 2, SourceURL: "https://mangafire.to/manga/alpha"}); err != nil {
+		t.Fatalf("upsert existing tracker source: %v", err)
+	}
+	if order := sourceOrderOf(); !reflect.DeepEqual(order, []int64{2, 3, 1, 4}) {
+		t.Fatalf("expected the new link appended, got %v", order)
+	}
+	sourceIDsOf()
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+)
+
+// seedTagMatrix tags the listing fixtures so every operator mix has a
+// distinct answer:
+//
+//	Alpha Blade    favorite, action
+//	Beta Blade     priority
+//	Gamma Tower    favorite, stale
+//	Delta Tower    action, priority, stale
+//	Epsilon Blade  action
+//
+// Profile 2 has its own favorite tag on Other Profile Blade.
+func seedTagMatrix(t *testing.T, db *sql.DB) {
+	t.Helper()
+	if _, err := db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, 'priority', 'priority'), (1, 'stale', 'stale'), (2, 'favorite', 'favorite')`); err != nil {
+		t.Fatalf("seed matrix tags: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO tracker_tags (tracker_id, tag_id)
+		SELECT t.id, ct.id
+		FROM trackers t
+		INNER JOIN custom_tags ct ON ct.profile_id = t.profile_id
+		WHERE (t.title IN ('Beta Blade', 'Delta Tower') AND ct.name = 'priority')
+		   OR (t.title IN ('Gamma Tower', 'Delta Tower') AND ct.name = 'stale')
+		   OR (t.title = 'Other Profile Blade' AND ct.name = 'favorite')
+	`); err != nil {
+		t.Fatalf("seed matrix tracker tags: %v", err)
+	}
+}
+
+func anyOf(names ...string) TagFilter {
+	return TagFilter{AnyOf: names}
+}
+
+func noneOf(names ...string) TagFilter {
+	return TagFilter{AnyOf: names, Exclude: true}
+}
+
+func TestTagFiltersCombineOrGroupsExclusionsAndAnd(t *testing.T) {
+	db := setupListingTestDB(t)
+	seedTagMatrix(t, db)
+	repo := NewTrackerRepository(db)
+
+	cases := []struct {
+		name      string
+		profileID int64
+		filters   []TagFilter
+		want      []string
+	}{
+		{name: "single tag", filters: []TagFilter{anyOf("favorite")}, want: []string{"Alpha Blade", "Gamma Tower"}},
+		{name: "or group", filters: []TagFilter{anyOf("favorite", "priority")}, want: []string{"Alpha Blade", "Beta Blade", "Delta Tower", "Gamma Tower"}},
+		{name: "exclusion", filters: []TagFilter{noneOf("stale")}, want: []string{"Alpha Blade", "Beta Blade", "Epsilon Blade"}},
+		{name: "excluded or group", filters: []TagFilter{noneOf("stale", "favorite")}, want: []string{"Beta Blade", "Epsilon Blade"}},
+		{name: "and of tags", filters: []TagFilter{anyOf("action"), anyOf("priority")}, want: []string{"Delta Tower"}},
+		{name: "tag and exclusion", filters: []TagFilter{anyOf("action"), noneOf("priority")}, want: []string{"Alpha Blade", "Epsilon Blade"}},
+		{name: "two exclusions", filters: []TagFilter{noneOf("action"), noneOf("stale")}, want: []string{"Beta Blade"}},
+		{name: "or group and exclusion", filters: []TagFilter{anyOf("favorite", "priority"), noneOf("stale")}, want: []string{"Alpha Blade", "Beta Blade"}},
+		{name: "two or groups", filters: []TagFilter{anyOf("favorite", "priority"), anyOf("action", "stale")}, want: []string{"Alpha Blade", "Delta Tower", "Gamma Tower"}},
+		{name: "names ignore case", filters: []TagFilter{noneOf("ACTION"), anyOf(" Stale ")}, want: []string{"Gamma Tower"}},
+		{name: "unknown tag matches nothing", filters: []TagFilter{anyOf("missing")}, want: []string{}},
+		{name: "unknown exclusion matches everything", filters: []TagFilter{noneOf("missing")}, want: []string{"Alpha Blade", "Beta Blade", "Delta Tower", "Epsilon Blade", "Gamma Tower"}},
+		{name: "blank names are ignored", filters: []TagFilter{anyOf(" ", ""), noneOf("")}, want: []string{"Alpha Blade", "Beta Blade", "Delta Tower", "Epsilon Blade", "Gamma Tower"}},
+		{name: "other profile tag", profileID: 2, filters: []TagFilter{anyOf("favorite")}, want: []string{"Other Profile Blade"}},
+		{name: "other profile exclusion", profileID: 2, filters: []TagFilter{noneOf("favorite")}, want: []string{}},
+		{name: "tags of another profile do not match", profileID: 2, filters: []TagFilter{anyOf("action", "stale")}, want: []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			profileID := tc.profileID
+			if profileID == 0 {
+				profileID = 1
+			}
+			options := TrackerListOptions{ProfileID: profileID, TagFilters: tc.filters, SortBy: "title", Order: "asc"}
+
+			items, err := repo.List(context.Background(), options)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			titles := make([]string, 0, len(items))
+			for _, item := range items {
+				titles = append(titles, item.Title)
+			}
+			if !slices.Equal(titles, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, titles)
+			}
+
+			total, err := repo.Count(context.Background(), options)
+			if err != nil {
+				t.Fatalf("count: %v", err)
+			}
+			if total != len(tc.want) {
+				t.Fatalf("expected count %d, got %d", len(tc.want), total)
+			}
+		})
+	}
+}
+
+func TestTagFiltersIgnoreAccentsButKeepPunctuation(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	cafe, err := repo.CreateProfileTag(ctx, 1, "Café", nil)
+	if err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	scifi, err := repo.CreateProfileTag(ctx, 1, "Sci-Fi!", nil)
+	if err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO tracker_tags (tracker_id, tag_id)
+		SELECT id, ? FROM trackers WHERE title = 'Alpha Blade'
+		UNION ALL
+		SELECT id, ? FROM trackers WHERE title = 'Beta Blade'
+	`, cafe.ID, scifi.ID); err != nil {
+		t.Fatalf("tag trackers: %v", err)
+	}
+
+	cases := []struct {
+		filter string
+		want   []string
+	}{
+		{filter: "café", want: []string{"Alpha Blade"}},
+		{filter: "CAFE", want: []string{"Alpha Blade"}},
+		{filter: "cafe", want: []string{"Alpha Blade"}},
+		{filter: "sci-fi!", want: []string{"Beta Blade"}},
+		{filter: "SCÏ-FI!", want: []string{"Beta Blade"}},
+		{filter: "sci fi", want: []string{}},
+		{filter: "scifi", want: []string{}},
+	}
+	for _, tc := range cases {
+		items, err := repo.List(ctx, TrackerListOptions{ProfileID: 1, TagFilters: []TagFilter{anyOf(tc.filter)}, SortBy: "title", Order: "asc"})
+		if err != nil {
+			t.Fatalf("list %q: %v", tc.filter, err)
+		}
+		titles := make([]string, 0, len(items))
+		for _, item := range items {
+			titles = append(titles, item.Title)
+		}
+		if !slices.Equal(titles, tc.want) {
+			t.Fatalf("filter %q: expected %v, got %v", tc.filter, tc.want, titles)
+		}
+	}
+}
+
+func TestBackfillTagNamesNormalizedFoldsAccents(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	// What the migration leaves behind: lowercased, accents intact.
+	if _, err := db.Exec(`INSERT INTO custom_tags (profile_id, name, name_normalized) VALUES (1, 'Shōnen', 'shōnen'), (1, 'plain', 'plain')`); err != nil {
+		t.Fatalf("seed tags: %v", err)
+	}
+
+	updated, err := repo.BackfillTagNamesNormalized(ctx)
+	if err != nil {
+		t.Fatalf("backfill: %v", err)
+	}
+	if updated != 1 {
+		t.Fatalf("expected 1 tag updated, got %d", updated)
+	}
+	var normalized string
+	if err := db.QueryRow(`SELECT name_normalized FROM custom_tags WHERE name = 'Shōnen'`).Scan(&normalized); err != nil {
+		t.Fatalf("read normalized name: %v", err)
+	}
+	if normalized != "shonen" {
+		t.Fatalf("expected shonen, got %q", normalized)
+	}
+
+	updated, err = repo.BackfillTagNamesNormalized(ctx)
+	if err != nil {
+		t.Fatalf("second backfill: %v", err)
+	}
+	if updated != 0 {
+		t.Fatalf("expected the second backfill to update nothing, got %d", updated)
+	}
+}
+
+func TestListTagsByTrackerIDsTakesAPageOfAnySize(t *testing.T) {
+	db := setupListingTestDB(t)
+	seedTagMatrix(t, db)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+
+	delta := trackerIDByTitle(t, repo, "Delta Tower")
+	gamma := trackerIDByTitle(t, repo, "Gamma Tower")
+	other := trackerIDByTitle(t, repo, "Other Profile Blade")
+
+	// Past SQLite's bound parameter limit, with repeats and ids that match
+	// nothing mixed in.
+	ids := []int64{0, delta, -1, gamma, delta, other}
+	for id := int64(100000); len(ids) < 40000; id++ {
+		ids = append(ids, id)
+	}
+
+	tagsByTracker, err := repo.ListTagsByTrackerIDs(ctx, 1, ids)
+	if err != nil {
+		t.Fatalf("list tags: %v", err)
+	}
+	if len(tagsByTracker) != 2 {
+		t.Fatalf("expected tags for the two profile 1 trackers, got %+v", tagsByTracker)
+	}
+	names := func(trackerID int64) []string {
+		result := make([]string, 0)
+		for _, tag := range tagsByTracker[trackerID] {
+			result = append(result, tag.Name)
+		}
+		return result
+	}
+	if got := names(delta); !slices.Equal(got, []string{"action", "priority", "stale"}) {
+		t.Fatalf("expected Delta Tower's tags by name, got %v", got)
+	}
+	if got := names(gamma); !slices.Equal(got, []string{"favorite", "stale"}) {
+		t.Fatalf("expected Gamma Tower's tags by name, got %v", got)
+	}
+}
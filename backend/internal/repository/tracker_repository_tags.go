@@ -1,15 +1,18 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+	"github.com/gabriel/cross-site-tracker/backend/internal/searchutil"
 )
 
-func (r *TrackerRepository) ListProfileTags(profileID int64) ([]models.CustomTag, error) {
-	rows, err := r.db.Query(`
+func (r *TrackerRepository) ListProfileTags(ctx context.Context, profileID int64) ([]models.CustomTag, error) {
+	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, profile_id, name, icon_key, created_at, updated_at
 		FROM custom_tags
 		WHERE profile_id = ?
@@ -50,7 +53,54 @@ func (r *TrackerRepository) ListProfileTags(profileID int64) ([]models.CustomTag
 	return items, nil
 }
 
-func (r *TrackerRepository) UpsertProfileTag(profileID int64, name string, iconKey *string) (*models.CustomTag, error) {
+// ListProfileTagsWithUsage returns the profile's tags in ListProfileTags
+// order, each with how many trackers carry it.
+func (r *TrackerRepository) ListProfileTagsWithUsage(ctx context.Context, profileID int64) ([]ProfileTagUsage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT custom_tags.id, custom_tags.profile_id, custom_tags.name, custom_tags.icon_key,
+			custom_tags.created_at, custom_tags.updated_at, COUNT(tracker_tags.tracker_id)
+		FROM custom_tags
+		LEFT JOIN tracker_tags ON tracker_tags.tag_id = custom_tags.id
+		WHERE custom_tags.profile_id = ?
+		GROUP BY custom_tags.id
+		ORDER BY
+			CASE
+				WHEN TRIM(COALESCE(custom_tags.icon_key, '')) IN ('icon_1', 'icon_2', 'icon_3') THEN 0
+				ELSE 1
+			END ASC,
+			custom_tags.name ASC,
+			custom_tags.id ASC
+	`, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("list profile tags with usage: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]ProfileTagUsage, 0)
+	for rows.Next() {
+		var item ProfileTagUsage
+		var iconKey sql.NullString
+		if err := rows.Scan(&item.Tag.ID, &item.Tag.ProfileID, &item.Tag.Name, &iconKey, &item.Tag.CreatedAt, &item.Tag.UpdatedAt, &item.TrackerCount); err != nil {
+			return nil, fmt.Errorf("scan profile tag usage: %w", err)
+		}
+		if iconKey.Valid {
+			iconValue := strings.TrimSpace(iconKey.String)
+			if iconValue != "" {
+				item.Tag.IconKey = &iconValue
+				item.Tag.IconPath = iconPathFromKey(iconValue)
+			}
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate profile tag usage: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *TrackerRepository) UpsertProfileTag(ctx context.Context, profileID int64, name string, iconKey *string) (*models.CustomTag, error) {
 	trimmedName := strings.TrimSpace(name)
 	if trimmedName == "" {
 		return nil, fmt.Errorf("tag name is required")
@@ -64,19 +114,19 @@ func (r *TrackerRepository) UpsertProfileTag(profileID int64, name string, iconK
 		}
 	}
 
-	_, err := r.db.Exec(`
-		INSERT INTO custom_tags (profile_id, name, icon_key)
-		VALUES (?, ?, ?)
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO custom_tags (profile_id, name, name_normalized, icon_key)
+		VALUES (?, ?, ?, ?)
 		ON CONFLICT(profile_id, name)
 		DO UPDATE SET
 			icon_key = excluded.icon_key,
 			updated_at = CURRENT_TIMESTAMP
-	`, profileID, trimmedName, normalizedIconKey)
+	`, profileID, trimmedName, searchutil.NormalizeTagName(trimmedName), normalizedIconKey)
 	if err != nil {
 		return nil, fmt.Errorf("upsert profile tag: %w", err)
 	}
 
-	row := r.db.QueryRow(`
+	row := r.db.QueryRowContext(ctx, `
 		SELECT id, profile_id, name, icon_key, created_at, updated_at
 		FROM custom_tags
 		WHERE profile_id = ? AND name = ?
@@ -99,7 +149,7 @@ func (r *TrackerRepository) UpsertProfileTag(profileID int64, name string, iconK
 	return &tag, nil
 }
 
-func (r *TrackerRepository) CreateProfileTag(profileID int64, name string, iconKey *string) (*models.CustomTag, error) {
+func (r *TrackerRepository) CreateProfileTag(ctx context.Context, profileID int64, name string, iconKey *string) (*models.CustomTag, error) {
 	trimmedName := strings.TrimSpace(name)
 	if trimmedName == "" {
 		return nil, fmt.Errorf("tag name is required")
@@ -113,10 +163,10 @@ func (r *TrackerRepository) CreateProfileTag(profileID int64, name string, iconK
 		}
 	}
 
-	result, err := r.db.Exec(`
-		INSERT INTO custom_tags (profile_id, name, icon_key)
-		VALUES (?, ?, ?)
-	`, profileID, trimmedName, normalizedIconKey)
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO custom_tags (profile_id, name, name_normalized, icon_key)
+		VALUES (?, ?, ?, ?)
+	`, profileID, trimmedName, searchutil.NormalizeTagName(trimmedName), normalizedIconKey)
 	if err != nil {
 		return nil, fmt.Errorf("create profile tag: %w", err)
 	}
@@ -126,7 +176,7 @@ func (r *TrackerRepository) CreateProfileTag(profileID int64, name string, iconK
 		return nil, fmt.Errorf("get created profile tag id: %w", err)
 	}
 
-	row := r.db.QueryRow(`
+	row := r.db.QueryRowContext(ctx, `
 		SELECT id, profile_id, name, icon_key, created_at, updated_at
 		FROM custom_tags
 		WHERE id = ? AND profile_id = ?
@@ -149,7 +199,7 @@ func (r *TrackerRepository) CreateProfileTag(profileID int64, name string, iconK
 	return &tag, nil
 }
 
-func (r *TrackerRepository) RenameProfileTag(profileID int64, tagID int64, name string) (bool, error) {
+func (r *TrackerRepository) RenameProfileTag(ctx context.Context, profileID int64, tagID int64, name string) (bool, error) {
 	if tagID <= 0 {
 		return false, nil
 	}
@@ -159,11 +209,11 @@ func (r *TrackerRepository) RenameProfileTag(profileID int64, tagID int64, name
 		return false, fmt.Errorf("tag name is required")
 	}
 
-	result, err := r.db.Exec(`
+	result, err := r.db.ExecContext(ctx, `
 		UPDATE custom_tags
-		SET name = ?, updated_at = CURRENT_TIMESTAMP
+		SET name = ?, name_normalized = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ? AND profile_id = ?
-	`, trimmedName, tagID, profileID)
+	`, trimmedName, searchutil.NormalizeTagName(trimmedName), tagID, profileID)
 	if err != nil {
 		return false, fmt.Errorf("rename profile tag: %w", err)
 	}
@@ -176,12 +226,12 @@ func (r *TrackerRepository) RenameProfileTag(profileID int64, tagID int64, name
 	return rowsAffected > 0, nil
 }
 
-func (r *TrackerRepository) DeleteProfileTag(profileID int64, tagID int64) (bool, error) {
+func (r *TrackerRepository) DeleteProfileTag(ctx context.Context, profileID int64, tagID int64) (bool, error) {
 	if tagID <= 0 {
 		return false, nil
 	}
 
-	result, err := r.db.Exec(`DELETE FROM custom_tags WHERE id = ? AND profile_id = ?`, tagID, profileID)
+	result, err := r.db.ExecContext(ctx, `DELETE FROM custom_tags WHERE id = ? AND profile_id = ?`, tagID, profileID)
 	if err != nil {
 		return false, fmt.Errorf("delete profile tag: %w", err)
 	}
@@ -194,14 +244,76 @@ func (r *TrackerRepository) DeleteProfileTag(profileID int64, tagID int64) (bool
 	return rowsAffected > 0, nil
 }
 
-func (r *TrackerRepository) ReplaceTrackerTags(profileID int64, trackerID int64, tagIDs []int64) error {
-	tx, err := r.db.Begin()
+// BackfillTagNamesNormalized stores the normalized form of every tag name
+// whose stored one is out of date, such as a name with accents the
+// migration could only lowercase. It returns how many tags it updated.
+func (r *TrackerRepository) BackfillTagNamesNormalized(ctx context.Context) (int, error) {
+	stale, err := r.staleTagNames(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for id, normalized := range stale {
+		if _, err := r.db.ExecContext(ctx, `UPDATE custom_tags SET name_normalized = ? WHERE id = ?`, normalized, id); err != nil {
+			return 0, fmt.Errorf("backfill tag name: %w", err)
+		}
+	}
+	return len(stale), nil
+}
+
+// staleTagNames maps the id of each tag whose stored normalized name is out
+// of date to the one it should have.
+func (r *TrackerRepository) staleTagNames(ctx context.Context) (map[int64]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, name_normalized FROM custom_tags`)
+	if err != nil {
+		return nil, fmt.Errorf("list tag names: %w", err)
+	}
+	defer rows.Close()
+
+	stale := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var name, stored string
+		if err := rows.Scan(&id, &name, &stored); err != nil {
+			return nil, fmt.Errorf("scan tag name: %w", err)
+		}
+		if normalized := searchutil.NormalizeTagName(name); normalized != stored {
+			stale[id] = normalized
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tag names: %w", err)
+	}
+	return stale, nil
+}
+
+// DeleteUnusedProfileTags deletes every profile tag no tracker carries, in a
+// single statement, and returns how many were removed.
+func (r *TrackerRepository) DeleteUnusedProfileTags(ctx context.Context, profileID int64) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM custom_tags
+		WHERE profile_id = ?
+		  AND NOT EXISTS (SELECT 1 FROM tracker_tags WHERE tracker_tags.tag_id = custom_tags.id)
+	`, profileID)
+	if err != nil {
+		return 0, fmt.Errorf("delete unused profile tags: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("unused profile tags delete rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (r *TrackerRepository) ReplaceTrackerTags(ctx context.Context, profileID int64, trackerID int64, tagIDs []int64) error {
+	tx, err := r.begin(ctx)
 	if err != nil {
 		return fmt.Errorf("begin replace tracker tags tx: %w", err)
 	}
 
 	var trackerExists int
-	if err := tx.QueryRow(`SELECT COUNT(1) FROM trackers WHERE id = ? AND profile_id = ?`, trackerID, profileID).Scan(&trackerExists); err != nil {
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(1) FROM trackers WHERE id = ? AND profile_id = ?`, trackerID, profileID).Scan(&trackerExists); err != nil {
 		tx.Rollback()
 		return fmt.Errorf("check tracker ownership for tags: %w", err)
 	}
@@ -210,7 +322,7 @@ func (r *TrackerRepository) ReplaceTrackerTags(profileID int64, trackerID int64,
 		return nil
 	}
 
-	if _, err := tx.Exec(`DELETE FROM tracker_tags WHERE tracker_id = ?`, trackerID); err != nil {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tracker_tags WHERE tracker_id = ?`, trackerID); err != nil {
 		tx.Rollback()
 		return fmt.Errorf("delete tracker tags: %w", err)
 	}
@@ -223,7 +335,7 @@ func (r *TrackerRepository) ReplaceTrackerTags(profileID int64, trackerID int64,
 			lookupArgs = append(lookupArgs, tagID)
 		}
 
-		rows, err := tx.Query(`
+		rows, err := tx.QueryContext(ctx, `
 			SELECT id
 			FROM custom_tags
 			WHERE profile_id = ?
@@ -251,7 +363,7 @@ func (r *TrackerRepository) ReplaceTrackerTags(profileID int64, trackerID int64,
 		}
 		rows.Close()
 
-		insertStmt, err := tx.Prepare(`INSERT INTO tracker_tags (tracker_id, tag_id) VALUES (?, ?)`)
+		insertStmt, err := tx.PrepareContext(ctx, `INSERT INTO tracker_tags (tracker_id, tag_id) VALUES (?, ?)`)
 		if err != nil {
 			tx.Rollback()
 			return fmt.Errorf("prepare tracker tag insert: %w", err)
@@ -263,7 +375,7 @@ func (r *TrackerRepository) ReplaceTrackerTags(profileID int64, trackerID int64,
 				continue
 			}
 
-			if _, err := insertStmt.Exec(trackerID, tagID); err != nil {
+			if _, err := insertStmt.ExecContext(ctx, trackerID, tagID); err != nil {
 				tx.Rollback()
 				return fmt.Errorf("insert tracker tag: %w", err)
 			}
@@ -277,7 +389,7 @@ func (r *TrackerRepository) ReplaceTrackerTags(profileID int64, trackerID int64,
 	return nil
 }
 
-func (r *TrackerRepository) ListTagsByTrackerIDs(profileID int64, trackerIDs []int64) (map[int64][]models.CustomTag, error) {
+func (r *TrackerRepository) ListTagsByTrackerIDs(ctx context.Context, profileID int64, trackerIDs []int64) (map[int64][]models.CustomTag, error) {
 	result := make(map[int64][]models.CustomTag, len(trackerIDs))
 	if len(trackerIDs) == 0 {
 		return result, nil
@@ -288,12 +400,14 @@ func (r *TrackerRepository) ListTagsByTrackerIDs(profileID int64, trackerIDs []i
 		return result, nil
 	}
 
-	args := make([]any, 0, len(uniqueTrackerIDs)+1)
-	args = append(args, profileID)
-	for _, id := range uniqueTrackerIDs {
-		args = append(args, id)
+	encodedIDs, err := json.Marshal(uniqueTrackerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("encode tracker ids: %w", err)
 	}
 
+	// The ids go in as one JSON array: the query text stays the same for
+	// every page size and a large page never nears SQLite's bound
+	// parameter limit.
 	query := `
 		SELECT
 			tt.tracker_id,
@@ -306,11 +420,12 @@ func (r *TrackerRepository) ListTagsByTrackerIDs(profileID int64, trackerIDs []i
 		FROM tracker_tags tt
 		INNER JOIN custom_tags ct ON ct.id = tt.tag_id
 		WHERE ct.profile_id = ?
-		  AND tt.tracker_id IN (` + sqlPlaceholders(len(uniqueTrackerIDs)) + `)
+		  AND tt.tracker_id IN (SELECT value FROM json_each(?))
 		ORDER BY tt.tracker_id ASC, ct.name ASC, ct.id ASC
 	`
+	args := []any{profileID, string(encodedIDs)}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list tags by tracker ids: %w", err)
 	}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// dbtx is what the repository's queries run against, satisfied by both
+// *sql.DB and *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// WithTx runs fn with a repository bound to one transaction, so a handler
+// can compose several writes, such as a tracker update with its sources and
+// tags, that land together or not at all. The transaction commits when fn
+// returns nil and rolls back when it returns an error. Called on a
+// repository that is already bound, fn joins that transaction.
+//
+// Only call txRepo from within fn: on SQLite the transaction holds the
+// write lock, so a write through another repository would wait on it.
+func (r *TrackerRepository) WithTx(ctx context.Context, fn func(txRepo *TrackerRepository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tracker tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(&TrackerRepository{db: tx, tx: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tracker tx: %w", err)
+	}
+	return nil
+}
+
+// repositoryTx is the transaction of a write made of several statements.
+// Inside WithTx the write joins WithTx's transaction, and its Commit and
+// Rollback leave ending it to WithTx.
+type repositoryTx struct {
+	*sql.Tx
+	joined bool
+}
+
+func (t *repositoryTx) Commit() error {
+	if t.joined {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t *repositoryTx) Rollback() error {
+	if t.joined {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
+
+func (r *TrackerRepository) begin(ctx context.Context) (*repositoryTx, error) {
+	if r.tx != nil {
+		return &repositoryTx{Tx: r.tx, joined: true}, nil
+	}
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &repositoryTx{Tx: tx}, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+func TestWithTxRollsBackEveryStepOnError(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+
+	tracker, err := repo.GetByID(ctx, 1, alpha)
+	if err != nil || tracker == nil {
+		t.Fatalf("get tracker: %v", err)
+	}
+	renamed := *tracker
+	renamed.Title = "Renamed Blade"
+	sources := []models.TrackerSource{
+		{SourceID: tracker.SourceID, SourceURL: tracker.SourceURL},
+		{SourceID: 2, SourceURL: "https://example.com/series/alpha-linked"},
+	}
+
+	injected := errors.New("injected failure")
+	err = repo.WithTx(ctx, func(txRepo *TrackerRepository) error {
+		if _, err := txRepo.Update(ctx, 1, alpha, &renamed); err != nil {
+			return err
+		}
+		if err := txRepo.ReplaceTrackerSources(ctx, 1, alpha, sources); err != nil {
+			return err
+		}
+		// Joining from the bound repository stays in the same transaction.
+		return txRepo.WithTx(ctx, func(*TrackerRepository) error { return injected })
+	})
+	if !errors.Is(err, injected) {
+		t.Fatalf("expected the injected error back, got %v", err)
+	}
+
+	after, err := repo.GetByID(ctx, 1, alpha)
+	if err != nil || after == nil {
+		t.Fatalf("get tracker after rollback: %v", err)
+	}
+	linked, err := repo.ListTrackerSources(ctx, 1, alpha)
+	if err != nil {
+		t.Fatalf("list tracker sources: %v", err)
+	}
+	if after.Title != "Alpha Blade" || len(linked) != 1 {
+		t.Fatalf("expected the update and sources to be rolled back, got title %q and %d sources", after.Title, len(linked))
+	}
+
+	if err := repo.WithTx(ctx, func(txRepo *TrackerRepository) error {
+		if _, err := txRepo.Update(ctx, 1, alpha, &renamed); err != nil {
+			return err
+		}
+		return txRepo.ReplaceTrackerSources(ctx, 1, alpha, sources)
+	}); err != nil {
+		t.Fatalf("commit tx: %v", err)
+	}
+	after, _ = repo.GetByID(ctx, 1, alpha)
+	linked, _ = repo.ListTrackerSources(ctx, 1, alpha)
+	if after == nil || after.Title != "Renamed Blade" || len(linked) != 2 {
+		t.Fatalf("expected the committed update and sources, got %+v and %d sources", after, len(linked))
+	}
+}
@@ -3,22 +3,67 @@ package repository
 import (
 	"database/sql"
 	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
 )
 
 type TrackerListOptions struct {
 	ProfileID int64
 	Statuses  []string
-	TagNames  []string
-	SourceIDs []int64
-	SortBy    string
-	Order     string
-	Query     string
-	Limit     int
-	Offset    int
+	// TagFilters are ANDed: a tracker must match every one of them.
+	TagFilters []TagFilter
+	SourceIDs  []int64
+	SortBy     string
+	Order      string
+	Query      string
+	// IDs, when set, limits the list to these trackers, e.g. to check whether
+	// one tracker still matches the other filters.
+	IDs    []int64
+	Limit  int
+	Offset int
+	// After, when set, starts the list just past this row of the same sort
+	// (keyset pagination) instead of skipping Offset rows.
+	After *TrackerCursor
+}
+
+// TagFilter is one term of the tag filter. A tracker matches it when it has
+// any of the tags in AnyOf, or, when Exclude is set, none of them. Names are
+// compared case-insensitively.
+type TagFilter struct {
+	AnyOf   []string
+	Exclude bool
+}
+
+// TrackerCursor marks the last row of a keyset page: its value for each of
+// the sort's ORDER BY terms exactly as stored, and its id, which breaks ties
+// between rows equal on all of them.
+type TrackerCursor struct {
+	SortValues []any
+	ID         int64
+}
+
+const (
+	TrackerSourceRolePrimary = "primary"
+	TrackerSourceRoleLinked  = "linked"
+)
+
+// TrackerSourceListOptions filters ListTrackerSourcesByProfile. SourceID 0
+// lists every source; Role is "" for every row, or TrackerSourceRolePrimary
+// or TrackerSourceRoleLinked for rows that are, or are not, their tracker's
+// primary source.
+type TrackerSourceListOptions struct {
+	SourceID int64
+	Role     string
+	Limit    int
+	Offset   int
 }
 
 type TrackerRepository struct {
-	db *sql.DB
+	db dbtx
+	// conn is the database of a repository from NewTrackerRepository; tx is
+	// the transaction of one WithTx hands out. Exactly one of them is set.
+	conn *sql.DB
+	tx   *sql.Tx
 }
 
 type PollingTracker struct {
@@ -31,8 +76,61 @@ type PollingTracker struct {
 	LatestKnownChapter *float64
 	SourceKey          string
 	LastCheckedAt      *time.Time
+	// SourceLang is the language of the primary source's link, or "" when
+	// the primary has no tracker_sources row.
+	SourceLang    string
+	LinkedSources []PollingTrackerSource
+	// NextScheduledChapter is the announced chapter the last poll stored, so
+	// a poll that finds none knows whether there is one to clear.
+	NextScheduledChapter *float64
+}
+
+type PollingTrackerSource struct {
+	SourceID      int64
+	SourceKey     string
+	SourceURL     string
+	Lang          string
+	ChapterOffset float64
+}
+
+// TrackerSourcePollResult is the outcome of resolving one linked source during
+// a poll. LagChapters is how far the source trailed the best linked source and
+// is nil when the source reported no chapter. MismatchSuspected sets the
+// source's mismatch flag; a false value leaves it as it was.
+type TrackerSourcePollResult struct {
+	SourceID          int64
+	SourceURL         string
+	OK                bool
+	LagChapters       *float64
+	MismatchSuspected bool
+}
+
+// ProfileTagUsage is a profile tag with the number of trackers that carry it.
+type ProfileTagUsage struct {
+	Tag          models.CustomTag
+	TrackerCount int
 }
 
 func NewTrackerRepository(db *sql.DB) *TrackerRepository {
-	return &TrackerRepository{db: db}
+	return &TrackerRepository{db: db, conn: db}
+}
+
+// RecentIncompleteTracker is a recently added tracker that still needs tags
+// or linked sources set up. LinkedSourceCount counts the primary's row too,
+// so 1 means no other site is linked.
+type RecentIncompleteTracker struct {
+	ID                int64
+	Title             string
+	SourceURL         string
+	CreatedAt         time.Time
+	TagCount          int
+	LinkedSourceCount int
+}
+
+// TrackerLink is the title and source page of a tracker that another tracker
+// points at, such as the continuation shown on its card.
+type TrackerLink struct {
+	ID        int64
+	Title     string
+	SourceURL string
 }
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+// URL history reasons, as stored in tracker_url_history.reason.
+const (
+	URLHistoryEdit            = "edit"
+	URLHistoryPromotion       = "promotion"
+	URLHistorySlugRotation    = "slug_rotation"
+	URLHistoryDomainMigration = "domain_migration"
+)
+
+// Resolved URL match kinds, as reported in models.ResolvedTrackerURL.MatchedBy.
+const (
+	ResolvedBySourceURL    = "source_url"
+	ResolvedByLinkedSource = "linked_source"
+	ResolvedByHistory      = "history"
+)
+
+// recordReplacedURL appends oldURL to the tracker's URL history when it was
+// rewritten to newURL. Nothing is recorded when the two only differ in case
+// or when the history already holds oldURL for the source, as it does after
+// an edit moves the primary URL and its linked row alike.
+func recordReplacedURL(ctx context.Context, db dbtx, trackerID int64, sourceID int64, oldURL string, newURL string, reason string) error {
+	oldURL = strings.TrimSpace(oldURL)
+	if oldURL == "" || strings.EqualFold(oldURL, strings.TrimSpace(newURL)) {
+		return nil
+	}
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO tracker_url_history (tracker_id, source_id, old_url, reason)
+		SELECT ?, ?, ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM tracker_url_history
+			WHERE tracker_id = ? AND source_id = ? AND LOWER(old_url) = LOWER(?)
+		)
+	`, trackerID, sourceID, oldURL, reason, trackerID, sourceID, oldURL); err != nil {
+		return fmt.Errorf("record replaced tracker url: %w", err)
+	}
+	return nil
+}
+
+// primarySourceOf returns the tracker's primary source id and URL, or 0 and
+// "" when the profile has no such tracker.
+func (r *TrackerRepository) primarySourceOf(ctx context.Context, profileID int64, trackerID int64) (int64, string, error) {
+	var sourceID int64
+	var sourceURL string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT source_id, source_url FROM trackers WHERE id = ? AND profile_id = ?
+	`, trackerID, profileID).Scan(&sourceID, &sourceURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("get tracker primary source: %w", err)
+	}
+	return sourceID, sourceURL, nil
+}
+
+// TrackerURLKey is the form source URLs are compared in when resolving one
+// to its tracker: without scheme, fragment, case or trailing slashes.
+func TrackerURLKey(raw string) string {
+	key := strings.TrimSpace(raw)
+	if index := strings.Index(key, "#"); index >= 0 {
+		key = key[:index]
+	}
+	if index := strings.Index(key, "://"); index >= 0 {
+		key = key[index+len("://"):]
+	}
+	return strings.TrimRight(strings.ToLower(key), "/")
+}
+
+// trackerURLKeySQL is TrackerURLKey of a stored URL column. Stored URLs
+// carry no fragment and no surrounding spaces.
+func trackerURLKeySQL(column string) string {
+	return `RTRIM(LOWER(CASE WHEN INSTR(` + column + `, '://') > 0 THEN SUBSTR(` + column + `, INSTR(` + column + `, '://') + 3) ELSE ` + column + ` END), '/')`
+}
+
+// ResolveTrackerURL finds the profile's tracker a source URL belongs to:
+// the tracker with it as its primary URL, then one with it linked, then the
+// one it was most recently moved away from. It returns nil when none is.
+func (r *TrackerRepository) ResolveTrackerURL(ctx context.Context, profileID int64, rawURL string) (*models.ResolvedTrackerURL, error) {
+	key := TrackerURLKey(rawURL)
+	if key == "" {
+		return nil, nil
+	}
+
+	var resolved models.ResolvedTrackerURL
+	err := r.db.QueryRowContext(ctx, `
+		SELECT t.id, t.title, t.source_id, t.source_url, m.url, m.matched_by
+		FROM (
+			SELECT id AS tracker_id, source_url AS url, ? AS matched_by, 0 AS rank
+			FROM trackers
+			UNION ALL
+			SELECT tracker_id, source_url, ?, 1
+			FROM tracker_sources
+		) m
+		INNER JOIN trackers t ON t.id = m.tracker_id
+		WHERE t.profile_id = ?
+		  AND `+trackerURLKeySQL("m.url")+` = ?
+		ORDER BY m.rank ASC, t.id ASC
+		LIMIT 1
+	`, ResolvedBySourceURL, ResolvedByLinkedSource, profileID, key).Scan(
+		&resolved.TrackerID, &resolved.Title, &resolved.SourceID, &resolved.SourceURL, &resolved.MatchedURL, &resolved.MatchedBy,
+	)
+	if err == nil {
+		return &resolved, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("resolve tracker url: %w", err)
+	}
+
+	var reason string
+	var replacedAt sql.NullTime
+	err = r.db.QueryRowContext(ctx, `
+		SELECT t.id, t.title, t.source_id, t.source_url, h.old_url, h.reason, h.replaced_at
+		FROM tracker_url_history h
+		INNER JOIN trackers t ON t.id = h.tracker_id
+		WHERE t.profile_id = ?
+		  AND `+trackerURLKeySQL("h.old_url")+` = ?
+		ORDER BY h.replaced_at DESC, h.id DESC
+		LIMIT 1
+	`, profileID, key).Scan(
+		&resolved.TrackerID, &resolved.Title, &resolved.SourceID, &resolved.SourceURL, &resolved.MatchedURL, &reason, &replacedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolve tracker url history: %w", err)
+	}
+	resolved.MatchedBy = ResolvedByHistory
+	resolved.Reason = &reason
+	if replacedAt.Valid {
+		replacedAtUTC := replacedAt.Time.UTC()
+		resolved.ReplacedAt = &replacedAtUTC
+	}
+	return &resolved, nil
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/models"
+)
+
+type urlHistoryRow struct {
+	SourceID int64
+	OldURL   string
+	Reason   string
+}
+
+func urlHistoryOf(t *testing.T, db *sql.DB, trackerID int64) []urlHistoryRow {
+	t.Helper()
+	rows, err := db.Query(`SELECT source_id, old_url, reason FROM tracker_url_history WHERE tracker_id = ? ORDER BY id ASC`, trackerID)
+	if err != nil {
+		t.Fatalf("list url history: %v", err)
+	}
+	defer rows.Close()
+
+	history := make([]urlHistoryRow, 0)
+	for rows.Next() {
+		var row urlHistoryRow
+		if err := rows.Scan(&row.SourceID, &row.OldURL, &row.Reason); err != nil {
+			t.Fatalf("scan url history: %v", err)
+		}
+		history = append(history, row)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("iterate url history: %v", err)
+	}
+	return history
+}
+
+func TestURLHistoryRecordsEveryRewrite(t *testing.T) {
+	ctx := context.Background()
+	checkedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name    string
+		title   string
+		rewrite func(t *testing.T, repo *TrackerRepository, trackerID int64)
+		want    []urlHistoryRow
+	}{
+		{
+			name:  "edit",
+			title: "Beta Blade",
+			rewrite: func(t *testing.T, repo *TrackerRepository, trackerID int64) {
+				tracker, err := repo.GetByID(ctx, 1, trackerID)
+				if err != nil || tracker == nil {
+					t.Fatalf("load tracker: %v", err)
+				}
+				tracker.SourceURL = "https://mangafire.to/manga/beta-v2"
+				if _, err := repo.Update(ctx, 1, trackerID, tracker); err != nil {
+					t.Fatalf("update tracker: %v", err)
+				}
+			},
+			want: []urlHistoryRow{{SourceID: 2, OldURL: "https://mangafire.to/manga/beta", Reason: URLHistoryEdit}},
+		},
+		{
+			name:  "edited linked source",
+			title: "Alpha Blade",
+			rewrite: func(t *testing.T, repo *TrackerRepository, trackerID int64) {
+				if err := repo.ReplaceTrackerSources(ctx, 1, trackerID, []models.TrackerSource{
+					{SourceID: 1, SourceURL: "https://mangadex.org/title/alpha"},
+					{SourceID: 3, SourceURL: "https://asuracomic.net/series/alpha"},
+				}); err != nil {
+					t.Fatalf("replace tracker sources: %v", err)
+				}
+			},
+			want: []urlHistoryRow{{SourceID: 3, OldURL: "https://mangadex.org/title/alpha/linked", Reason: URLHistoryEdit}},
+		},
+		{
+			name:  "removed linked source",
+			title: "Alpha Blade",
+			rewrite: func(t *testing.T, repo *TrackerRepository, trackerID int64) {
+				if err := repo.ReplaceTrackerSources(ctx, 1, trackerID, []models.TrackerSource{
+					{SourceID: 1, SourceURL: "https://mangadex.org/title/alpha"},
+				}); err != nil {
+					t.Fatalf("replace tracker sources: %v", err)
+				}
+			},
+			want: []urlHistoryRow{},
+		},
+		{
+			name:  "promotion",
+			title: "Alpha Blade",
+			rewrite: func(t *testing.T, repo *TrackerRepository, trackerID int64) {
+				sources, err := repo.ListTrackerSources(ctx, 1, trackerID)
+				if err != nil || len(sources) != 1 {
+					t.Fatalf("expected the linked source, got %+v %v", sources, err)
+				}
+				if switched, err := repo.SetPrimarySource(ctx, 1, trackerID, sources[0].ID); err != nil || !switched {
+					t.Fatalf("set primary source: %v %v", switched, err)
+				}
+			},
+			want: []urlHistoryRow{{SourceID: 1, OldURL: "https://mangadex.org/title/alpha", Reason: URLHistoryPromotion}},
+		},
+		{
+			name:  "resolved to a new url",
+			title: "Gamma Tower",
+			rewrite: func(t *testing.T, repo *TrackerRepository, trackerID int64) {
+				tracker := &models.Tracker{SourceID: 1, SourceURL: "https://mangadex.org/title/gamma-uuid"}
+				if applied, err := repo.UpdateResolvedSource(ctx, 1, trackerID, "https://mangadex.org/title/gamma", tracker, checkedAt); err != nil || !applied {
+					t.Fatalf("update resolved source: %v %v", applied, err)
+				}
+			},
+			want: []urlHistoryRow{{SourceID: 1, OldURL: "https://mangadex.org/title/gamma", Reason: URLHistorySlugRotation}},
+		},
+		{
+			name:  "polled to a new url",
+			title: "Delta Tower",
+			rewrite: func(t *testing.T, repo *TrackerRepository, trackerID int64) {
+				latest := 21.0
+				if err := repo.UpdatePollingState(ctx, trackerID, 3, "https://asuracomic.net/series/delta", nil, "https://asuracomic.net/series/delta-1a2b", &latest, nil, false, checkedAt); err != nil {
+					t.Fatalf("update polling state: %v", err)
+				}
+			},
+			want: []urlHistoryRow{{SourceID: 3, OldURL: "https://asuracomic.net/series/delta", Reason: URLHistorySlugRotation}},
+		},
+		{
+			name:  "polled at the same url",
+			title: "Delta Tower",
+			rewrite: func(t *testing.T, repo *TrackerRepository, trackerID int64) {
+				latest := 21.0
+				if err := repo.UpdatePollingState(ctx, trackerID, 3, "https://asuracomic.net/series/delta", nil, "https://ASURACOMIC.net/series/delta", &latest, nil, false, checkedAt); err != nil {
+					t.Fatalf("update polling state: %v", err)
+				}
+			},
+			want: []urlHistoryRow{},
+		},
+		{
+			name:  "domain migration",
+			title: "Epsilon Blade",
+			rewrite: func(t *testing.T, repo *TrackerRepository, trackerID int64) {
+				if err := repo.MigrateSourceURL(ctx, trackerID, 2, "https://mangafire.to/manga/epsilon", "https://mangafire.io/manga/epsilon"); err != nil {
+					t.Fatalf("migrate source url: %v", err)
+				}
+			},
+			want: []urlHistoryRow{{SourceID: 2, OldURL: "https://mangafire.to/manga/epsilon", Reason: URLHistoryDomainMigration}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := setupListingTestDB(t)
+			repo := NewTrackerRepository(db)
+			trackerID := trackerIDByTitle(t, repo, tc.title)
+
+			tc.rewrite(t, repo, trackerID)
+
+			if got := urlHistoryOf(t, db, trackerID); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected history %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestURLHistoryKeepsAnEditedPrimaryOnce(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+	trackerID := trackerIDByTitle(t, repo, "Beta Blade")
+
+	// The edit form saves the tracker and then its linked sources, which
+	// both see the old primary URL go.
+	err := repo.WithTx(ctx, func(txRepo *TrackerRepository) error {
+		tracker, err := txRepo.GetByID(ctx, 1, trackerID)
+		if err != nil {
+			return err
+		}
+		if err := txRepo.UpsertTrackerSource(ctx, 1, trackerID, models.TrackerSource{SourceID: 2, SourceURL: tracker.SourceURL}); err != nil {
+			return err
+		}
+		tracker.SourceURL = "https://mangafire.to/manga/beta-v2"
+		if _, err := txRepo.Update(ctx, 1, trackerID, tracker); err != nil {
+			return err
+		}
+		return txRepo.ReplaceTrackerSources(ctx, 1, trackerID, []models.TrackerSource{{SourceID: 2, SourceURL: tracker.SourceURL}})
+	})
+	if err != nil {
+		t.Fatalf("edit tracker: %v", err)
+	}
+
+	want := []urlHistoryRow{{SourceID: 2, OldURL: "https://mangafire.to/manga/beta", Reason: URLHistoryEdit}}
+	if got := urlHistoryOf(t, db, trackerID); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the old URL once, got %+v", got)
+	}
+}
+
+func TestResolveTrackerURL(t *testing.T) {
+	db := setupListingTestDB(t)
+	repo := NewTrackerRepository(db)
+	ctx := context.Background()
+	alpha := trackerIDByTitle(t, repo, "Alpha Blade")
+	epsilon := trackerIDByTitle(t, repo, "Epsilon Blade")
+
+	if err := repo.MigrateSourceURL(ctx, epsilon, 2, "https://mangafire.to/manga/epsilon", "https://mangafire.io/manga/epsilon"); err != nil {
+		t.Fatalf("migrate source url: %v", err)
+	}
+
+	cases := []struct {
+		name      string
+		url       string
+		profileID int64
+		trackerID int64
+		matchedBy string
+	}{
+		{name: "primary", url: "https://mangadex.org/title/alpha", trackerID: alpha, matchedBy: ResolvedBySourceURL},
+		{name: "primary ignoring scheme, case and slash", url: " HTTP://MangaDex.org/title/Alpha/#chapters ", trackerID: alpha, matchedBy: ResolvedBySourceURL},
+		{name: "linked", url: "https://mangadex.org/title/alpha/linked", trackerID: alpha, matchedBy: ResolvedByLinkedSource},
+		{name: "history", url: "mangafire.to/manga/epsilon/", trackerID: epsilon, matchedBy: ResolvedByHistory},
+		{name: "unknown", url: "https://mangafire.to/manga/unknown"},
+		{name: "another profile", url: "https://mangadex.org/title/other"},
+		{name: "own profile", url: "https://mangadex.org/title/other", profileID: 2, trackerID: trackerIDByTitle(t, repo, "Other Profile Blade"), matchedBy: ResolvedBySourceURL},
+		{name: "blank", url: "  "},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			profileID := tc.profileID
+			if profileID == 0 {
+				profileID = 1
+			}
+			resolved, err := repo.ResolveTrackerURL(ctx, profileID, tc.url)
+			if err != nil {
+				t.Fatalf("resolve url: %v", err)
+			}
+			if tc.trackerID == 0 {
+				if resolved != nil {
+					t.Fatalf("expected no tracker, got %+v", resolved)
+				}
+				return
+			}
+			if resolved == nil || resolved.TrackerID != tc.trackerID || resolved.MatchedBy != tc.matchedBy {
+				t.Fatalf("expected tracker %d by %s, got %+v", tc.trackerID, tc.matchedBy, resolved)
+			}
+			if tc.matchedBy == ResolvedByHistory {
+				if resolved.Reason == nil || *resolved.Reason != URLHistoryDomainMigration || resolved.ReplacedAt == nil {
+					t.Fatalf("expected the history reason and time, got %+v", resolved)
+				}
+				if resolved.SourceURL != "https://mangafire.io/manga/epsilon" {
+					t.Fatalf("expected the tracker's current URL, got %q", resolved.SourceURL)
+				}
+			} else if resolved.Reason != nil || resolved.ReplacedAt != nil {
+				t.Fatalf("expected no history details on a current match, got %+v", resolved)
+			}
+		})
+	}
+}
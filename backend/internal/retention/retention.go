@@ -0,0 +1,239 @@
+// Package retention prunes the tables that only record what happened, such
+// as read history and sync runs, so a long-lived install does not grow
+// forever. Each table keeps rows for a number of days, a number of rows or
+// both, and rows are deleted in bounded batches so the write lock is never
+// held for long.
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+)
+
+// Table is one prunable table. Rows are aged by TimeColumn, a DATETIME
+// column; rowid breaks ties.
+type Table struct {
+	Name       string
+	TimeColumn string
+	// DefaultDays and DefaultMaxRows are used unless the environment
+	// overrides them; 0 keeps rows regardless of age or count.
+	DefaultDays    int
+	DefaultMaxRows int
+}
+
+// Tables is the registry of prunable tables. A new derived table plugs in
+// with one line here.
+var Tables = []Table{
+	// Reading history backs the stats pages, so it is kept unless asked.
+	{Name: "read_events", TimeColumn: "read_at"},
+	{Name: "mangadex_sync_runs", TimeColumn: "started_at", DefaultDays: 90},
+	{Name: "source_migrations", TimeColumn: "migrated_at", DefaultDays: 365},
+	// Old URLs are kept so old bookmarks keep resolving to their tracker.
+	{Name: "tracker_url_history", TimeColumn: "replaced_at"},
+	// Link cache rows are aged from when they expired.
+	{Name: "link_cache", TimeColumn: "expires_at", DefaultDays: 7},
+}
+
+const defaultBatchSize = 500
+
+// Policy is the retention applied to one table.
+type Policy struct {
+	Table   Table
+	Days    int
+	MaxRows int
+}
+
+// Enabled reports whether the policy prunes anything.
+func (p Policy) Enabled() bool {
+	return p.Days > 0 || p.MaxRows > 0
+}
+
+type Config struct {
+	Policies []Policy
+	// BatchSize caps the rows deleted by one statement (default 500).
+	BatchSize int
+}
+
+// ConfigFrom applies the environment overrides to the registry defaults.
+// An override for a table that is not registered is an error, so a typo in
+// a setting does not silently keep everything.
+func ConfigFrom(cfg config.Config) (Config, error) {
+	known := make(map[string]bool, len(Tables))
+	policies := make([]Policy, 0, len(Tables))
+	for _, table := range Tables {
+		known[table.Name] = true
+		policy := Policy{Table: table, Days: table.DefaultDays, MaxRows: table.DefaultMaxRows}
+		if override, ok := cfg.Retention[table.Name]; ok {
+			if override.Days != nil {
+				policy.Days = *override.Days
+			}
+			if override.MaxRows != nil {
+				policy.MaxRows = *override.MaxRows
+			}
+		}
+		policies = append(policies, policy)
+	}
+	for name := range cfg.Retention {
+		if !known[name] {
+			return Config{}, fmt.Errorf("retention is set for unknown table %q", name)
+		}
+	}
+	return Config{Policies: policies, BatchSize: cfg.RetentionBatchSize}, nil
+}
+
+// Result is what one prune, or one dry run, found for a table: rows older
+// than the table's days, rows over its row cap, and the delete statements
+// used to remove them.
+type Result struct {
+	Table   string
+	Expired int
+	Trimmed int
+	Batches int
+}
+
+// Rows is the total removed from the table.
+func (r Result) Rows() int {
+	return r.Expired + r.Trimmed
+}
+
+type Pruner struct {
+	db        *sql.DB
+	policies  []Policy
+	batchSize int
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+func NewPruner(db *sql.DB, cfg Config, logger *slog.Logger) *Pruner {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Pruner{
+		db:        db,
+		policies:  cfg.Policies,
+		batchSize: cfg.BatchSize,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Prune deletes what every enabled policy no longer keeps and logs one
+// summary line. When ctx ends or a statement fails, the tables done so far
+// are returned with the error; the rest is picked up by the next run.
+func (p *Pruner) Prune(ctx context.Context) ([]Result, error) {
+	results, err := p.run(ctx, true)
+	p.logSummary(results)
+	return results, err
+}
+
+// Pending reports what Prune would delete without deleting it.
+func (p *Pruner) Pending(ctx context.Context) ([]Result, error) {
+	return p.run(ctx, false)
+}
+
+func (p *Pruner) run(ctx context.Context, apply bool) ([]Result, error) {
+	results := make([]Result, 0, len(p.policies))
+	for _, policy := range p.policies {
+		if !policy.Enabled() {
+			continue
+		}
+		result, err := p.plan(ctx, policy)
+		if err != nil {
+			return results, err
+		}
+		expiredBatches := (result.Expired + p.batchSize - 1) / p.batchSize
+		trimmedBatches := (result.Trimmed + p.batchSize - 1) / p.batchSize
+		result.Batches = expiredBatches + trimmedBatches
+		if apply {
+			if err := p.deleteBatches(ctx, policy, expiredBatches, trimmedBatches); err != nil {
+				return results, fmt.Errorf("prune %s: %w", policy.Table.Name, err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// agedExpr reads a table's time column for comparison. Only the first 19
+// characters are compared: julianday does not read the nanoseconds and zone
+// the driver writes, and CURRENT_TIMESTAMP writes neither.
+func agedExpr(table Table) string {
+	return fmt.Sprintf("julianday(substr(%s, 1, 19))", table.TimeColumn)
+}
+
+// cutoff is the oldest time policy keeps, in the layout agedExpr reads.
+func (p *Pruner) cutoff(policy Policy) string {
+	return p.now().UTC().AddDate(0, 0, -policy.Days).Format("2006-01-02 15:04:05")
+}
+
+// plan counts the rows policy no longer keeps: those older than its days,
+// then the oldest of the rest over its cap.
+func (p *Pruner) plan(ctx context.Context, policy Policy) (Result, error) {
+	table := policy.Table
+	result := Result{Table: table.Name}
+
+	var total int
+	if err := p.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(1) FROM %s`, table.Name)).Scan(&total); err != nil {
+		return Result{}, fmt.Errorf("count %s rows: %w", table.Name, err)
+	}
+	if policy.Days > 0 {
+		query := fmt.Sprintf(`SELECT COUNT(1) FROM %s WHERE %s < julianday(?)`, table.Name, agedExpr(table))
+		if err := p.db.QueryRowContext(ctx, query, p.cutoff(policy)).Scan(&result.Expired); err != nil {
+			return Result{}, fmt.Errorf("count expired %s rows: %w", table.Name, err)
+		}
+	}
+	if kept := total - result.Expired; policy.MaxRows > 0 && kept > policy.MaxRows {
+		result.Trimmed = kept - policy.MaxRows
+	}
+	return result, nil
+}
+
+// deleteBatches removes the expired rows and then the rows over the cap,
+// each batch in its own statement so each write lock covers at most the
+// batch size.
+func (p *Pruner) deleteBatches(ctx context.Context, policy Policy, expiredBatches int, trimmedBatches int) error {
+	table := policy.Table
+	expired := fmt.Sprintf(`DELETE FROM %[1]s WHERE rowid IN (SELECT rowid FROM %[1]s WHERE %[2]s < julianday(?) ORDER BY %[2]s, rowid LIMIT ?)`, table.Name, agedExpr(table))
+	for batch := 0; batch < expiredBatches; batch++ {
+		if _, err := p.db.ExecContext(ctx, expired, p.cutoff(policy), p.batchSize); err != nil {
+			return err
+		}
+	}
+
+	// With the expired rows gone, every row past the newest MaxRows is over
+	// the cap.
+	trimmed := fmt.Sprintf(`DELETE FROM %[1]s WHERE rowid IN (SELECT rowid FROM %[1]s ORDER BY %[2]s DESC, rowid DESC LIMIT ? OFFSET ?)`, table.Name, agedExpr(table))
+	for batch := 0; batch < trimmedBatches; batch++ {
+		if _, err := p.db.ExecContext(ctx, trimmed, p.batchSize, policy.MaxRows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Pruner) logSummary(results []Result) {
+	attrs := make([]any, 0, 2*len(results))
+	total := 0
+	batches := 0
+	for _, result := range results {
+		if result.Rows() == 0 {
+			continue
+		}
+		total += result.Rows()
+		batches += result.Batches
+		attrs = append(attrs, result.Table, result.Rows())
+	}
+	if total == 0 {
+		p.logger.Debug("retention prune found nothing to delete")
+		return
+	}
+	p.logger.Info("retention prune completed", append([]any{"pruned_rows", total, "batches", batches}, attrs...)...)
+}
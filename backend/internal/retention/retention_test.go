@@ -0,0 +1,158 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/config"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func setupRetentionTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := database.Open(filepath.Join(t.TempDir(), "retention.sqlite"))
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := database.ApplyMigrations(db, filepath.Join("..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+	return db
+}
+
+func newTestPruner(db *sql.DB, batchSize int, policies ...Policy) *Pruner {
+	pruner := NewPruner(db, Config{Policies: policies, BatchSize: batchSize}, nil)
+	pruner.now = func() time.Time { return testNow }
+	return pruner
+}
+
+func tableByName(t *testing.T, name string) Table {
+	t.Helper()
+	for _, table := range Tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	t.Fatalf("table %s is not registered", name)
+	return Table{}
+}
+
+func countRows(t *testing.T, db *sql.DB, table string) int64 {
+	t.Helper()
+	var count int64
+	if err := db.QueryRow(fmt.Sprintf(`SELECT COUNT(1) FROM %s`, table)).Scan(&count); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return count
+}
+
+func TestPruneExpiresRowsInBatchesUpToTheBoundary(t *testing.T) {
+	db := setupRetentionTestDB(t)
+	for index := 0; index < 5; index++ {
+		if _, err := db.Exec(`INSERT INTO link_cache (kind, cache_key, url, expires_at) VALUES ('search', ?, 'https://example.com', ?)`,
+			fmt.Sprintf("old-%d", index), testNow.AddDate(0, 0, -10)); err != nil {
+			t.Fatalf("seed old link cache row: %v", err)
+		}
+	}
+	// One row written the way CURRENT_TIMESTAMP writes, and one either
+	// side of the seven day boundary.
+	if _, err := db.Exec(`INSERT INTO link_cache (kind, cache_key, url, expires_at) VALUES
+		('search', 'text', 'https://example.com', '2026-01-01 00:00:00'),
+		('search', 'past', 'https://example.com', ?),
+		('search', 'inside', 'https://example.com', ?)`,
+		testNow.AddDate(0, 0, -7).Add(-time.Minute), testNow.AddDate(0, 0, -7).Add(time.Minute)); err != nil {
+		t.Fatalf("seed boundary link cache rows: %v", err)
+	}
+
+	policy := Policy{Table: tableByName(t, "link_cache"), Days: 7}
+	pruner := newTestPruner(db, 2, policy)
+
+	pending, err := pruner.Pending(context.Background())
+	if err != nil {
+		t.Fatalf("count pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Expired != 7 || countRows(t, db, "link_cache") != 8 {
+		t.Fatalf("expected a dry run to count 7 expired rows and delete none, got %+v", pending)
+	}
+
+	results, err := pruner.Prune(context.Background())
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if len(results) != 1 || results[0].Expired != 7 || results[0].Trimmed != 0 || results[0].Batches != 4 {
+		t.Fatalf("expected 7 expired rows pruned in 4 batches of at most 2, got %+v", results)
+	}
+	var kept string
+	if err := db.QueryRow(`SELECT cache_key FROM link_cache`).Scan(&kept); err != nil || countRows(t, db, "link_cache") != 1 {
+		t.Fatalf("expected one row kept, got %q (err %v)", kept, err)
+	}
+	if kept != "inside" {
+		t.Fatalf("expected the row inside the boundary to be kept, got %q", kept)
+	}
+}
+
+func TestPruneTrimsTheOldestRowsOverTheCap(t *testing.T) {
+	db := setupRetentionTestDB(t)
+	for index := 0; index < 7; index++ {
+		startedAt := testNow.Add(-time.Duration(index) * time.Hour)
+		if _, err := db.Exec(`INSERT INTO mangadex_sync_runs (profile_id, started_at, finished_at) VALUES (1, ?, ?)`, startedAt, startedAt); err != nil {
+			t.Fatalf("seed sync run: %v", err)
+		}
+	}
+
+	policy := Policy{Table: tableByName(t, "mangadex_sync_runs"), MaxRows: 3}
+	pruner := newTestPruner(db, 2, policy)
+
+	results, err := pruner.Prune(context.Background())
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if len(results) != 1 || results[0].Trimmed != 4 || results[0].Batches != 2 {
+		t.Fatalf("expected the 4 rows over the cap pruned in 2 batches, got %+v", results)
+	}
+
+	var oldest time.Time
+	if err := db.QueryRow(`SELECT started_at FROM mangadex_sync_runs ORDER BY id DESC LIMIT 1`).Scan(&oldest); err != nil {
+		t.Fatalf("read oldest run: %v", err)
+	}
+	if countRows(t, db, "mangadex_sync_runs") != 3 || !oldest.Equal(testNow.Add(-2*time.Hour)) {
+		t.Fatalf("expected the 3 newest runs kept, oldest %v", oldest)
+	}
+}
+
+func TestConfigFromAppliesOverrides(t *testing.T) {
+	days := 30
+	off := 0
+	cfg, err := ConfigFrom(config.Config{
+		RetentionBatchSize: 100,
+		Retention: map[string]config.RetentionOverride{
+			"read_events": {Days: &days},
+			"link_cache":  {Days: &off},
+		},
+	})
+	if err != nil {
+		t.Fatalf("config from: %v", err)
+	}
+	policies := map[string]Policy{}
+	for _, policy := range cfg.Policies {
+		policies[policy.Table.Name] = policy
+	}
+	if policies["read_events"].Days != 30 || policies["link_cache"].Enabled() || policies["source_migrations"].Days != 365 {
+		t.Fatalf("expected overrides on top of the defaults, got %+v", cfg.Policies)
+	}
+
+	if _, err := ConfigFrom(config.Config{Retention: map[string]config.RetentionOverride{"trackers": {Days: &days}}}); err == nil {
+		t.Fatal("expected retention on an unregistered table to fail")
+	}
+}
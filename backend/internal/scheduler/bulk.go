@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// bulkResults keeps what a cycle's ResolveMany calls returned, by source key
+// and polled URL, and which trackers already went into a batch.
+type bulkResults struct {
+	attempted map[int64]bool
+	results   map[string]map[string]*connectors.MangaResult
+}
+
+func newBulkResults() *bulkResults {
+	return &bulkResults{
+		attempted: make(map[int64]bool),
+		results:   make(map[string]map[string]*connectors.MangaResult),
+	}
+}
+
+// lookup returns the batched result for rawURL on sourceKey, or nil when the
+// tracker has to be resolved on its own.
+func (b *bulkResults) lookup(sourceKey string, rawURL string) *connectors.MangaResult {
+	if b == nil {
+		return nil
+	}
+	return b.results[sourceKey][rawURL]
+}
+
+// prefetch resolves, in one ResolveMany call, the first of pending together
+// with the next pending trackers on the same source, up to the connector's
+// MaxBatchSize. It does nothing when the source is not a
+// connectors.BulkResolver or the tracker already went into a batch. A failed
+// or panicking call is logged and leaves its trackers to be resolved one by
+// one.
+func (p *Poller) prefetch(ctx context.Context, pending []repository.PollingTracker, bulk *bulkResults, panics *runPanics) {
+	first := pending[0]
+	if bulk.attempted[first.ID] {
+		return
+	}
+	connector, ok := p.registry.Get(first.SourceKey)
+	if !ok {
+		return
+	}
+	resolver, ok := connector.(connectors.BulkResolver)
+	if !ok || resolver.MaxBatchSize() < 2 || !bulkEligible(connector, first) {
+		return
+	}
+
+	size := resolver.MaxBatchSize()
+	urls := make([]string, 0, min(size, len(pending)))
+	queued := make(map[string]bool, cap(urls))
+	for _, tracker := range pending {
+		if tracker.SourceKey != first.SourceKey || bulk.attempted[tracker.ID] || !bulkEligible(connector, tracker) {
+			continue
+		}
+		rawURL := p.pollingURL(tracker.SourceKey, tracker.SourceURL)
+		if !queued[rawURL] {
+			if len(urls) == size {
+				break
+			}
+			queued[rawURL] = true
+			urls = append(urls, rawURL)
+		}
+		bulk.attempted[tracker.ID] = true
+	}
+
+	// One call stands in for up to MaxBatchSize lookups, so it gets longer
+	// than a single tracker's request.
+	requestCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	results, err := p.resolveMany(requestCtx, first.SourceKey, resolver, urls, panics)
+	cancel()
+	if err != nil {
+		p.logger.Warn("poll bulk resolve failed", "sourceKey", first.SourceKey, "count", len(urls), "error", err)
+		return
+	}
+	if bulk.results[first.SourceKey] == nil {
+		bulk.results[first.SourceKey] = make(map[string]*connectors.MangaResult, len(results))
+	}
+	for rawURL, result := range results {
+		if result != nil && queued[rawURL] {
+			bulk.results[first.SourceKey][rawURL] = result
+		}
+	}
+	p.logger.Debug("poll bulk resolved", "sourceKey", first.SourceKey, "count", len(urls), "resolved", len(results))
+}
+
+// pollingURL returns the URL pollTracker will resolve for a stored source
+// URL, once the connector's URL migration rules are applied.
+func (p *Poller) pollingURL(sourceKey string, rawURL string) string {
+	rules, ok := p.registry.URLMigrationRules(sourceKey)
+	if !ok {
+		return rawURL
+	}
+	migrated, _ := rules.Migrate(rawURL)
+	return migrated
+}
+
+// bulkEligible reports whether a tracker can be resolved in a batch. Batches
+// are resolved in connectors.DefaultLanguage, so a tracker followed in
+// another translation on a language-aware source is left out.
+func bulkEligible(connector connectors.Connector, tracker repository.PollingTracker) bool {
+	if _, ok := connector.(connectors.LanguageAwareResolver); !ok {
+		return true
+	}
+	lang, err := connectors.NormalizeLanguage(tracker.SourceLang)
+	return err != nil || lang == connectors.DefaultLanguage
+}
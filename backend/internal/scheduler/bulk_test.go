@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// bulkConnector answers batches for every URL except those in missing, and
+// counts the requests it was asked to make.
+type bulkConnector struct {
+	linkedSourceConnector
+	batchSize int
+	missing   map[string]bool
+	bulkErr   error
+	batches   *[][]string
+	singles   *[]string
+}
+
+func (f bulkConnector) MaxBatchSize() int { return f.batchSize }
+
+func (f bulkConnector) ResolveMany(_ context.Context, urls []string) (map[string]*connectors.MangaResult, error) {
+	*f.batches = append(*f.batches, urls)
+	if f.bulkErr != nil {
+		return nil, f.bulkErr
+	}
+	results := make(map[string]*connectors.MangaResult, len(urls))
+	for _, rawURL := range urls {
+		if !f.missing[rawURL] {
+			results[rawURL] = &connectors.MangaResult{SourceKey: f.key, Title: "T", URL: rawURL, LatestChapter: f.latest}
+		}
+	}
+	return results, nil
+}
+
+func (f bulkConnector) ResolveByURL(ctx context.Context, rawURL string) (*connectors.MangaResult, error) {
+	*f.singles = append(*f.singles, rawURL)
+	return f.linkedSourceConnector.ResolveByURL(ctx, rawURL)
+}
+
+func (f bulkConnector) ResolveByURLWithLang(ctx context.Context, rawURL string, _ string) (*connectors.MangaResult, error) {
+	return f.ResolveByURL(ctx, rawURL)
+}
+
+func newBulkConnector(batchSize int) bulkConnector {
+	latest := 30.0
+	return bulkConnector{
+		linkedSourceConnector: linkedSourceConnector{key: "bulksource", latest: &latest},
+		batchSize:             batchSize,
+		missing:               map[string]bool{},
+		batches:               &[][]string{},
+		singles:               &[]string{},
+	}
+}
+
+func bulkTrackers(count int) []repository.PollingTracker {
+	trackers := make([]repository.PollingTracker, 0, count)
+	for index := 1; index <= count; index++ {
+		trackers = append(trackers, repository.PollingTracker{
+			ID:        int64(index),
+			Title:     "Bulk " + strconv.Itoa(index),
+			Status:    "reading",
+			SourceID:  1,
+			SourceKey: "bulksource",
+			SourceURL: "https://bulk/" + strconv.Itoa(index),
+		})
+	}
+	return trackers
+}
+
+func TestPollerRunOnce_ResolvesBulkSourcesInBatches(t *testing.T) {
+	connector := newBulkConnector(3)
+	connector.missing["https://bulk/5"] = true
+	trackers := bulkTrackers(7)
+	trackers[6].SourceLang = "pt-br"
+	other := 4.0
+	// A tracker on another source between the bulk ones does not split the
+	// batches.
+	trackers = append(trackers[:2], append([]repository.PollingTracker{{ID: 100, Title: "Other", Status: "reading", SourceKey: "testsource", SourceURL: "https://example"}}, trackers[2:]...)...)
+	repo := &fakeRepo{items: trackers}
+
+	registry := connectors.NewRegistry()
+	for _, registered := range []connectors.Connector{connector, fakeConnector{latest: &other}} {
+		if err := registry.Register(registered); err != nil {
+			t.Fatalf("register connector: %v", err)
+		}
+	}
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	batches := *connector.batches
+	if len(batches) != 2 || len(batches[0]) != 3 || len(batches[1]) != 3 || batches[1][0] != "https://bulk/4" {
+		t.Fatalf("expected the six default-language trackers in two batches of three, got %v", batches)
+	}
+	singles := *connector.singles
+	if len(singles) != 2 || singles[0] != "https://bulk/5" || singles[1] != "https://bulk/7" {
+		t.Fatalf("expected only the unanswered and the pt-br tracker resolved on their own, got %v", singles)
+	}
+	if requests := len(batches) + len(singles); requests >= 7 {
+		t.Fatalf("expected fewer requests than trackers, got %d for 7", requests)
+	}
+	if repo.updatedCount != 8 {
+		t.Fatalf("expected every tracker's polling state stored, got %d", repo.updatedCount)
+	}
+	if status := poller.Status(); status.LastRun == nil || status.LastRun.Processed != 8 {
+		t.Fatalf("expected all trackers processed, got %+v", status.LastRun)
+	}
+}
+
+func TestPollerRunOnce_FallsBackWhenBulkResolveFails(t *testing.T) {
+	connector := newBulkConnector(10)
+	connector.bulkErr = errors.New("bulk endpoint down")
+	repo := &fakeRepo{items: bulkTrackers(3)}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(connector); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if len(*connector.batches) != 1 {
+		t.Fatalf("expected one batch attempt, got %v", *connector.batches)
+	}
+	if len(*connector.singles) != 3 || repo.updatedCount != 3 {
+		t.Fatalf("expected each tracker resolved on its own after the failed batch, got %v and %d updates", *connector.singles, repo.updatedCount)
+	}
+}
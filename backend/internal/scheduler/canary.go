@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+// CanaryNotes stores the outcome of deep checks on sources; see
+// repository.SourceRepository. A failure is written as the source's auto
+// note, which stays until a later deep check passes.
+type CanaryNotes interface {
+	SetCanaryFailure(ctx context.Context, sourceKey string, failure string, note string, at time.Time) (bool, error)
+	ClearCanaryFailure(ctx context.Context, sourceKey string, at time.Time) (bool, error)
+}
+
+// CanaryJob periodically runs the registry's deep health check, which
+// resolves each connector's canary series, and notes the sources that fail.
+type CanaryJob struct {
+	registry  *connectors.Registry
+	notes     CanaryNotes
+	pause     PauseState
+	interval  time.Duration
+	timeout   time.Duration
+	dbTimeout time.Duration
+	logger    *slog.Logger
+	stopCh    chan struct{}
+}
+
+type CanaryJobConfig struct {
+	// Interval is the time between deep checks (default 24h).
+	Interval time.Duration
+	// Timeout bounds each connector's check (default 30s).
+	Timeout time.Duration
+	// Pause, when set, skips a run while scraping is paused.
+	Pause PauseState
+	// Notes, when set, receives each canary's outcome.
+	Notes CanaryNotes
+	// DBTimeout bounds the note writes of a run (default 10s).
+	DBTimeout time.Duration
+}
+
+func NewCanaryJob(registry *connectors.Registry, cfg CanaryJobConfig, logger *slog.Logger) *CanaryJob {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.DBTimeout <= 0 {
+		cfg.DBTimeout = 10 * time.Second
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &CanaryJob{
+		registry:  registry,
+		notes:     cfg.Notes,
+		pause:     cfg.Pause,
+		interval:  cfg.Interval,
+		timeout:   cfg.Timeout,
+		dbTimeout: cfg.DBTimeout,
+		logger:    logger,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start runs a deep check right away and then every interval.
+func (j *CanaryJob) Start(ctx context.Context) {
+	j.logger.Info("canary job started", "interval", j.interval.String())
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		j.RunOnce(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				j.logger.Info("canary job stopped")
+				close(j.stopCh)
+				return
+			case <-ticker.C:
+				j.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (j *CanaryJob) StopWait(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	select {
+	case <-j.stopCh:
+	case <-time.After(timeout):
+	}
+}
+
+// RunOnce deep-checks every connector that declares a canary and records
+// the outcomes. It returns the statuses of those connectors, or nil when
+// scraping is paused.
+func (j *CanaryJob) RunOnce(ctx context.Context) []connectors.HealthStatus {
+	if j.pause != nil {
+		if paused, err := j.pause.ScrapingPaused(); err == nil && paused {
+			j.logger.Info("canary run skipped", "reason", connectors.ErrScrapingPaused.Error())
+			return nil
+		}
+	}
+
+	checked := make([]connectors.HealthStatus, 0)
+	for _, status := range j.registry.DeepHealth(ctx, j.timeout) {
+		if status.Canary != nil {
+			checked = append(checked, status)
+		}
+	}
+
+	at := time.Now().UTC()
+	dbCtx, cancel := context.WithTimeout(ctx, j.dbTimeout)
+	defer cancel()
+	for _, status := range checked {
+		if status.Canary.Passed {
+			if j.notes != nil {
+				if _, err := j.notes.ClearCanaryFailure(dbCtx, status.Key, at); err != nil {
+					j.logger.Warn("canary clear failure failed", "sourceKey", status.Key, "error", err)
+				}
+			}
+			continue
+		}
+
+		j.logger.Warn("canary check failed", "sourceKey", status.Key, "invariant", status.Canary.Invariant, "error", status.Canary.Error)
+		if j.notes != nil {
+			if _, err := j.notes.SetCanaryFailure(dbCtx, status.Key, status.Canary.Error, canaryNote(status.Canary, at), at); err != nil {
+				j.logger.Warn("canary write failure failed", "sourceKey", status.Key, "error", err)
+			}
+		}
+	}
+	return checked
+}
+
+func canaryNote(result *connectors.CanaryResult, at time.Time) string {
+	failure := result.Error
+	if runes := []rune(failure); len(runes) > maxSourceNoteErrorLength {
+		failure = string(runes[:maxSourceNoteErrorLength]) + "…"
+	}
+	return fmt.Sprintf("Deep check of a known series failed on %s: %s", at.UTC().Format("Jan 2, 15:04 UTC"), failure)
+}
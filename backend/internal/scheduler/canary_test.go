@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+type fakeCanaryNotes struct {
+	failures map[string]string
+	notes    map[string]string
+	cleared  []string
+}
+
+func (f *fakeCanaryNotes) SetCanaryFailure(_ context.Context, sourceKey string, failure string, note string, _ time.Time) (bool, error) {
+	if f.failures == nil {
+		f.failures = make(map[string]string)
+		f.notes = make(map[string]string)
+	}
+	f.failures[sourceKey] = failure
+	f.notes[sourceKey] = note
+	return true, nil
+}
+
+func (f *fakeCanaryNotes) ClearCanaryFailure(_ context.Context, sourceKey string, _ time.Time) (bool, error) {
+	f.cleared = append(f.cleared, sourceKey)
+	return true, nil
+}
+
+// canaryFlakyConnector resolves every URL to title.
+type canaryFlakyConnector struct {
+	flakyConnector
+	title string
+}
+
+func (c canaryFlakyConnector) Canary() connectors.Canary {
+	return connectors.Canary{URL: c.key + "/canary", MinLatestChapter: 10}
+}
+
+func (c canaryFlakyConnector) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	latest := 40.0
+	return &connectors.MangaResult{SourceKey: c.key, Title: c.title, URL: rawURL, LatestChapter: &latest}, nil
+}
+
+func TestCanaryJobRunOnce_NotesFailingCanaries(t *testing.T) {
+	registry := connectors.NewRegistry()
+	_ = registry.Register(canaryFlakyConnector{flakyConnector: flakyConnector{key: "broken"}})
+	_ = registry.Register(canaryFlakyConnector{flakyConnector: flakyConnector{key: "fine"}, title: "Known Series"})
+	_ = registry.Register(flakyConnector{key: "plain"})
+
+	notes := &fakeCanaryNotes{}
+	job := NewCanaryJob(registry, CanaryJobConfig{Notes: notes}, nil)
+	checked := job.RunOnce(context.Background())
+
+	if len(checked) != 2 {
+		t.Fatalf("expected only the connectors with canaries to be reported, got %+v", checked)
+	}
+	if notes.failures["broken"] != "title is empty" || !strings.Contains(notes.notes["broken"], "title is empty") {
+		t.Fatalf("expected the broken source to be noted, got %+v", notes)
+	}
+	if len(notes.cleared) != 1 || notes.cleared[0] != "fine" {
+		t.Fatalf("expected the passing source's failure to be cleared, got %v", notes.cleared)
+	}
+}
+
+func TestCanaryJobRunOnce_SkipsWhilePaused(t *testing.T) {
+	registry := connectors.NewRegistry()
+	_ = registry.Register(canaryFlakyConnector{flakyConnector: flakyConnector{key: "broken"}})
+
+	notes := &fakeCanaryNotes{}
+	job := NewCanaryJob(registry, CanaryJobConfig{Notes: notes, Pause: pauseStub{paused: true}}, nil)
+	if checked := job.RunOnce(context.Background()); checked != nil || notes.failures != nil {
+		t.Fatalf("expected a paused run to check nothing, got %+v %+v", checked, notes)
+	}
+}
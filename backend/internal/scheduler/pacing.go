@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoadSignal reports how busy the host is, as load per CPU: about 1 when
+// every CPU is in use. ok is false when no reading is available.
+type LoadSignal interface {
+	Load() (load float64, ok bool)
+}
+
+// Pacer spaces out a poll cycle's trackers by the host's load. While the
+// load is at or above the threshold the delay between trackers doubles, up
+// to MaxDelay; once it drops below, the delay halves back to MinDelay.
+type Pacer struct {
+	signal    LoadSignal
+	minDelay  time.Duration
+	maxDelay  time.Duration
+	threshold float64
+
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+type PacerConfig struct {
+	// Signal is sampled before each delay (default HostLoad).
+	Signal LoadSignal
+	// MinDelay is the delay on an idle host (default 0).
+	MinDelay time.Duration
+	// MaxDelay caps the delay on a busy host (default 5s).
+	MaxDelay time.Duration
+	// LoadThreshold is the load per CPU that counts as busy (default 0.8).
+	LoadThreshold float64
+}
+
+func NewPacer(cfg PacerConfig) *Pacer {
+	if cfg.Signal == nil {
+		cfg.Signal = HostLoad{}
+	}
+	if cfg.MinDelay < 0 {
+		cfg.MinDelay = 0
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = 5 * time.Second
+	}
+	if cfg.MaxDelay < cfg.MinDelay {
+		cfg.MaxDelay = cfg.MinDelay
+	}
+	if cfg.LoadThreshold <= 0 {
+		cfg.LoadThreshold = 0.8
+	}
+
+	return &Pacer{
+		signal:    cfg.Signal,
+		minDelay:  cfg.MinDelay,
+		maxDelay:  cfg.MaxDelay,
+		threshold: cfg.LoadThreshold,
+		delay:     cfg.MinDelay,
+	}
+}
+
+// Next samples the load and returns the delay to wait before the next
+// tracker. Without a reading the delay is left as it was.
+func (p *Pacer) Next() time.Duration {
+	load, ok := p.signal.Load()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !ok {
+		return p.delay
+	}
+
+	// Steps start and end at an eighth of the cap, so a zero minimum is
+	// neither doubled in place nor halved towards it forever.
+	step := p.maxDelay / 8
+	if load >= p.threshold {
+		p.delay = max(p.delay*2, step)
+	} else if p.delay /= 2; p.delay < step {
+		p.delay = p.minDelay
+	}
+	p.delay = min(max(p.delay, p.minDelay), p.maxDelay)
+	return p.delay
+}
+
+// Delay returns the delay Next last settled on.
+func (p *Pacer) Delay() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.delay
+}
+
+// HostLoad reads the one-minute load average from /proc/loadavg and divides
+// it by the CPU count. Where there is no /proc, as outside Linux, it has no
+// reading and pacing stays at its minimum.
+type HostLoad struct{}
+
+func (HostLoad) Load() (float64, bool) {
+	if runtime.GOOS != "linux" {
+		return 0, false
+	}
+	raw, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, false
+	}
+	return parseLoadAverage(string(raw), runtime.NumCPU())
+}
+
+func parseLoadAverage(raw string, cpus int) (float64, bool) {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 || cpus <= 0 {
+		return 0, false
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil || load < 0 {
+		return 0, false
+	}
+	return load / float64(cpus), true
+}
@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+type loadStub struct {
+	load float64
+	ok   bool
+}
+
+func (s *loadStub) Load() (float64, bool) { return s.load, s.ok }
+
+func TestPacerGrowsWhileBusyAndShrinksWhenIdle(t *testing.T) {
+	signal := &loadStub{load: 2, ok: true}
+	pacer := NewPacer(PacerConfig{Signal: signal, MinDelay: 100 * time.Millisecond, MaxDelay: 1600 * time.Millisecond, LoadThreshold: 1})
+	if got := pacer.Delay(); got != 100*time.Millisecond {
+		t.Fatalf("expected the pacer to start at its minimum, got %v", got)
+	}
+
+	want := []time.Duration{200, 400, 800, 1600, 1600}
+	for index, delay := range want {
+		if got := pacer.Next(); got != delay*time.Millisecond {
+			t.Fatalf("busy sample %d: expected %v, got %v", index+1, delay*time.Millisecond, got)
+		}
+	}
+
+	signal.ok = false
+	if got := pacer.Next(); got != 1600*time.Millisecond {
+		t.Fatalf("expected the delay to hold without a reading, got %v", got)
+	}
+
+	signal.load, signal.ok = 0.2, true
+	want = []time.Duration{800, 400, 200, 100, 100}
+	for index, delay := range want {
+		if got := pacer.Next(); got != delay*time.Millisecond {
+			t.Fatalf("idle sample %d: expected %v, got %v", index+1, delay*time.Millisecond, got)
+		}
+	}
+}
+
+func TestPacerGrowsFromAZeroMinimum(t *testing.T) {
+	signal := &loadStub{load: 1, ok: true}
+	pacer := NewPacer(PacerConfig{Signal: signal, MaxDelay: 800 * time.Millisecond, LoadThreshold: 1})
+	if got := pacer.Next(); got != 100*time.Millisecond {
+		t.Fatalf("expected a busy host to start at an eighth of the cap, got %v", got)
+	}
+	signal.load = 0
+	for range 4 {
+		pacer.Next()
+	}
+	if got := pacer.Delay(); got != 0 {
+		t.Fatalf("expected an idle host to shrink back to no delay, got %v", got)
+	}
+}
+
+func TestParseLoadAverage(t *testing.T) {
+	if load, ok := parseLoadAverage("3.20 2.10 1.00 2/345 6789\n", 4); !ok || load != 0.8 {
+		t.Fatalf("expected 0.8 per CPU, got %v (%v)", load, ok)
+	}
+	for _, raw := range []string{"", "busy 1 1", "-1 0 0"} {
+		if _, ok := parseLoadAverage(raw, 4); ok {
+			t.Fatalf("expected %q to have no reading", raw)
+		}
+	}
+}
+
+func TestPollerRunOnce_PacesTrackersAndReportsTheDelay(t *testing.T) {
+	latest := 5.0
+	repo := &fakeRepo{items: []repository.PollingTracker{
+		{ID: 1, Title: "A", Status: "reading", SourceURL: "https://example/a", SourceKey: "testsource"},
+		{ID: 2, Title: "B", Status: "reading", SourceURL: "https://example/b", SourceKey: "testsource"},
+		{ID: 3, Title: "C", Status: "reading", SourceURL: "https://example/c", SourceKey: "testsource"},
+	}}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(fakeConnector{latest: &latest}); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+	signal := &loadStub{load: 4, ok: true}
+	pacer := NewPacer(PacerConfig{Signal: signal, MinDelay: 10 * time.Millisecond, MaxDelay: 80 * time.Millisecond, LoadThreshold: 1})
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute, Pacer: pacer}, nil)
+	started := time.Now()
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	// Two waits between three trackers: 20ms, then 40ms.
+	if elapsed := time.Since(started); elapsed < 60*time.Millisecond {
+		t.Fatalf("expected the trackers to be spaced out, took %v", elapsed)
+	}
+	if status := poller.Status(); status.PollDelayMS != 40 {
+		t.Fatalf("expected the status to report the 40ms delay, got %+v", status)
+	}
+}
@@ -5,24 +5,63 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
 	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/retention"
 )
 
 type pollRepository interface {
-	ListForPolling() ([]repository.PollingTracker, error)
-	UpdatePollingState(id int64, sourceID int64, currentSourceURL string, sourceItemID *string, sourceURL string, latestKnownChapter *float64, latestReleaseAt *time.Time, clearLatestReleaseAt bool, checkedAt time.Time) error
+	ListForPolling(ctx context.Context) ([]repository.PollingTracker, error)
+	UpdatePollingState(ctx context.Context, id int64, sourceID int64, currentSourceURL string, sourceItemID *string, sourceURL string, latestKnownChapter *float64, latestReleaseAt *time.Time, clearLatestReleaseAt bool, checkedAt time.Time) error
+	RecordTrackerSourcePolls(ctx context.Context, trackerID int64, results []repository.TrackerSourcePollResult) error
+	SetPollError(ctx context.Context, id int64, message string, failedAt time.Time) error
+	MigrateSourceURL(ctx context.Context, trackerID int64, sourceID int64, oldURL string, newURL string) error
+	SetSourceGenres(ctx context.Context, id int64, genres []string) error
+	SetScheduledChapter(ctx context.Context, id int64, chapter *float64, scheduledAt *time.Time) error
+}
+
+// PauseState reports the global scraping pause switch; see
+// repository.SettingsRepository.
+type PauseState interface {
+	ScrapingPaused() (bool, error)
+}
+
+// ActivityState reports when the dashboard or API was last used; see
+// repository.SettingsRepository.
+type ActivityState interface {
+	LastActivityAt() (time.Time, bool, error)
+}
+
+// Pruner deletes rows the retention settings no longer keep; see
+// retention.Pruner.
+type Pruner interface {
+	Prune(ctx context.Context) ([]retention.Result, error)
 }
 
 type Poller struct {
 	repo         pollRepository
 	registry     *connectors.Registry
+	pause        PauseState
+	sourceNotes  SourceNotes
+	pacer        *Pacer
+	pruner       Pruner
 	interval     time.Duration
 	idleInterval time.Duration
+	dbTimeout    time.Duration
 	logger       *slog.Logger
 	stopCh       chan struct{}
+	status       atomic.Pointer[Status]
+	now          func() time.Time
+
+	activity       ActivityState
+	inactiveAfter  time.Duration
+	inactiveFactor int
+
+	noteFailureRate float64
+	noteMinChecks   int
 }
 
 type PollerConfig struct {
@@ -31,6 +70,33 @@ type PollerConfig struct {
 	// not in "reading" status; they rarely change, so polling them every
 	// cycle just burns the sources' rate limits.
 	IdleInterval time.Duration
+	// Pause, when set, is checked before each tracker; while it reports
+	// paused the cycle stops without contacting any source.
+	Pause PauseState
+	// SourceNotes, when set, receives a note on each source whose checks
+	// failed more often than SourceNoteFailureRate (default 0.5) in a run
+	// of at least SourceNoteMinChecks (default 3) checks; the note is
+	// cleared after a run without failures.
+	SourceNotes           SourceNotes
+	SourceNoteFailureRate float64
+	SourceNoteMinChecks   int
+	// DBTimeout bounds each database call, apart from the source request
+	// timeouts, so a locked database cannot stall a cycle (default 10s).
+	DBTimeout time.Duration
+	// Pacer, when set, spaces out the trackers of a cycle by the host's
+	// load; without it they are polled back to back.
+	Pacer *Pacer
+	// Pruner, when set, runs after each cycle that was not skipped, within
+	// DBTimeout; what it does not finish is left for the next cycle.
+	Pruner Pruner
+	// Activity, when set, slows the poller down on installs nobody uses:
+	// once InactiveAfter (default 7 days) passes without a dashboard or API
+	// request, a cycle runs every InactiveFactor intervals, or none runs
+	// while InactiveFactor is 0 or less. The next request brings the
+	// interval back at the following tick.
+	Activity       ActivityState
+	InactiveAfter  time.Duration
+	InactiveFactor int
 }
 
 func NewPoller(repo pollRepository, registry *connectors.Registry, cfg PollerConfig, logger *slog.Logger) *Poller {
@@ -43,6 +109,18 @@ func NewPoller(repo pollRepository, registry *connectors.Registry, cfg PollerCon
 	if cfg.IdleInterval < cfg.Interval {
 		cfg.IdleInterval = cfg.Interval
 	}
+	if cfg.SourceNoteFailureRate <= 0 || cfg.SourceNoteFailureRate >= 1 {
+		cfg.SourceNoteFailureRate = 0.5
+	}
+	if cfg.SourceNoteMinChecks <= 0 {
+		cfg.SourceNoteMinChecks = 3
+	}
+	if cfg.DBTimeout <= 0 {
+		cfg.DBTimeout = 10 * time.Second
+	}
+	if cfg.InactiveAfter <= 0 {
+		cfg.InactiveAfter = 7 * 24 * time.Hour
+	}
 	if logger == nil {
 		logger = slog.Default()
 	}
@@ -50,10 +128,23 @@ func NewPoller(repo pollRepository, registry *connectors.Registry, cfg PollerCon
 	return &Poller{
 		repo:         repo,
 		registry:     registry,
+		pause:        cfg.Pause,
+		sourceNotes:  cfg.SourceNotes,
+		pacer:        cfg.Pacer,
+		pruner:       cfg.Pruner,
 		interval:     cfg.Interval,
 		idleInterval: cfg.IdleInterval,
+		dbTimeout:    cfg.DBTimeout,
 		logger:       logger,
 		stopCh:       make(chan struct{}),
+		now:          time.Now,
+
+		activity:       cfg.Activity,
+		inactiveAfter:  cfg.InactiveAfter,
+		inactiveFactor: cfg.InactiveFactor,
+
+		noteFailureRate: cfg.SourceNoteFailureRate,
+		noteMinChecks:   cfg.SourceNoteMinChecks,
 	}
 }
 
@@ -62,7 +153,7 @@ func (p *Poller) Start(ctx context.Context) {
 	ticker := time.NewTicker(p.interval)
 	go func() {
 		defer ticker.Stop()
-		if err := p.RunOnce(ctx); err != nil {
+		if err := p.runIfDue(ctx); err != nil {
 			p.logger.Warn("poller initial run failed", "error", err)
 		}
 		for {
@@ -72,7 +163,7 @@ func (p *Poller) Start(ctx context.Context) {
 				close(p.stopCh)
 				return
 			case <-ticker.C:
-				if err := p.RunOnce(ctx); err != nil {
+				if err := p.runIfDue(ctx); err != nil {
 					p.logger.Warn("poller cycle failed", "error", err)
 				}
 			}
@@ -90,66 +181,394 @@ func (p *Poller) StopWait(timeout time.Duration) {
 	}
 }
 
+// runIfDue runs a cycle on a tick of the ticker unless inactivity stretched
+// the interval and the last cycle is more recent than that.
+func (p *Poller) runIfDue(ctx context.Context) error {
+	cadence := p.cadence()
+	if !cadence.inactive {
+		return p.RunOnce(ctx)
+	}
+	if cadence.interval <= 0 {
+		p.logger.Debug("poller cycle skipped", "reason", "no recent activity")
+		return nil
+	}
+	// Ticks drift a little behind the cycles they start, so the last cycle
+	// counts as due half an interval early.
+	if lastRun := p.Status().LastRun; lastRun != nil && p.now().Sub(lastRun.StartedAt)+p.interval/2 < cadence.interval {
+		p.logger.Debug("poller cycle skipped", "reason", "no recent activity", "interval", cadence.interval.String())
+		return nil
+	}
+	return p.RunOnce(ctx)
+}
+
 func (p *Poller) RunOnce(ctx context.Context) error {
-	trackers, err := p.repo.ListForPolling()
+	if p.scrapingPaused() {
+		p.logger.Info("poller cycle skipped", "reason", connectors.ErrScrapingPaused.Error())
+		return nil
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, p.dbTimeout)
+	trackers, err := p.repo.ListForPolling(listCtx)
+	cancel()
 	if err != nil {
 		return fmt.Errorf("load trackers for polling: %w", err)
 	}
 
-	skippedIdle := 0
+	due := make([]repository.PollingTracker, 0, len(trackers))
+	skippedManual := 0
 	for _, tracker := range trackers {
+		if p.isManualSource(tracker.SourceKey) {
+			skippedManual++
+			continue
+		}
 		if p.shouldSkipIdle(tracker) {
-			skippedIdle++
 			continue
 		}
+		due = append(due, tracker)
+	}
+	skippedIdle := len(trackers) - len(due) - skippedManual
 
-		connector, ok := p.registry.Get(tracker.SourceKey)
-		if !ok {
-			p.logger.Debug("connector missing for tracker", "trackerId", tracker.ID, "sourceKey", tracker.SourceKey)
-			continue
+	startedAt := p.now().UTC()
+	previous := p.Status()
+	lastRun := previous.LastRun
+	processed := 0
+	newChapters := 0
+	sourceStats := make(map[string]*sourceRunStats)
+	bulk := newBulkResults()
+	panics := newRunPanics()
+	defer func() {
+		p.publishStatus(Status{LastRun: &RunSummary{
+			StartedAt:   startedAt,
+			FinishedAt:  time.Now().UTC(),
+			Processed:   processed,
+			Total:       len(due),
+			NewChapters: newChapters,
+			Panics:      panics.count,
+		}, PollDelayMS: p.pollDelay().Milliseconds(), Generation: previous.Generation + 1})
+	}()
+
+	for index, tracker := range due {
+		p.publishStatus(Status{
+			Running:          true,
+			StartedAt:        &startedAt,
+			Processed:        processed,
+			Total:            len(due),
+			CurrentSourceKey: tracker.SourceKey,
+			PollDelayMS:      p.pollDelay().Milliseconds(),
+			LastRun:          lastRun,
+			Generation:       previous.Generation,
+		})
+		if p.scrapingPaused() {
+			p.logger.Info("poller cycle stopped", "reason", connectors.ErrScrapingPaused.Error())
+			break
+		}
+		p.prefetch(ctx, due[index:], bulk, panics)
+		newChapter, resolveErr := p.pollTracker(ctx, tracker, bulk, panics)
+		if newChapter {
+			newChapters++
+		}
+		if _, ok := p.registry.Get(tracker.SourceKey); ok {
+			if sourceStats[tracker.SourceKey] == nil {
+				sourceStats[tracker.SourceKey] = &sourceRunStats{}
+			}
+			sourceStats[tracker.SourceKey].record(resolveErr)
+		}
+		processed++
+		if index < len(due)-1 && !p.pace(ctx) {
+			break
 		}
+	}
+	p.updateSourceNotes(ctx, sourceStats, time.Now().UTC())
+	p.prune(ctx)
+
+	if skippedIdle > 0 {
+		p.logger.Debug("poll skipped idle trackers", "count", skippedIdle)
+	}
+	if skippedManual > 0 {
+		p.logger.Debug("poll skipped manual trackers", "count", skippedManual)
+	}
+
+	return nil
+}
+
+func (p *Poller) prune(ctx context.Context) {
+	if p.pruner == nil {
+		return
+	}
+	pruneCtx, cancel := context.WithTimeout(ctx, p.dbTimeout)
+	defer cancel()
+	if _, err := p.pruner.Prune(pruneCtx); err != nil {
+		p.logger.Warn("retention prune failed", "error", err)
+	}
+}
+
+// pace waits out the pacer's delay before the next tracker. It reports
+// false when ctx ended while waiting.
+func (p *Poller) pace(ctx context.Context) bool {
+	if p.pacer == nil {
+		return ctx.Err() == nil
+	}
+	delay := p.pacer.Next()
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
 
+func (p *Poller) pollDelay() time.Duration {
+	if p.pacer == nil {
+		return 0
+	}
+	return p.pacer.Delay()
+}
+
+// pollTracker resolves one tracker's primary source, taking the result from
+// bulk when its batch answered for it, and stores the result. It reports
+// whether the source had a chapter newer than the known latest, and the
+// error when the source could not be resolved; a connector that panicked
+// counts as one that failed.
+func (p *Poller) pollTracker(ctx context.Context, tracker repository.PollingTracker, bulk *bulkResults, panics *runPanics) (bool, error) {
+	connector, ok := p.registry.Get(tracker.SourceKey)
+	if !ok {
+		p.logger.Debug("connector missing for tracker", "trackerId", tracker.ID, "sourceKey", tracker.SourceKey)
+		return false, nil
+	}
+	p.migrateTrackerURLs(ctx, &tracker)
+
+	result := bulk.lookup(tracker.SourceKey, tracker.SourceURL)
+	var resolveErr error
+	if result == nil {
 		requestCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
-		result, resolveErr := connector.ResolveByURL(requestCtx, tracker.SourceURL)
+		result, resolveErr = p.resolve(requestCtx, connector, tracker.SourceURL, tracker.SourceLang, panics)
 		cancel()
+	}
 
-		if resolveErr != nil {
-			p.logger.Warn("poll resolve failed", "trackerId", tracker.ID, "sourceKey", tracker.SourceKey, "error", resolveErr)
-			continue
+	if resolveErr != nil {
+		p.logger.Warn("poll resolve failed", "trackerId", tracker.ID, "sourceKey", tracker.SourceKey, "error", resolveErr)
+		dbCtx, cancel := context.WithTimeout(ctx, p.dbTimeout)
+		if err := p.repo.SetPollError(dbCtx, tracker.ID, resolveErr.Error(), time.Now().UTC()); err != nil {
+			p.logger.Warn("poll record error failed", "trackerId", tracker.ID, "error", err)
 		}
+		cancel()
+		p.recordLinkedSources(ctx, tracker, nil, "", panics)
+		return false, resolveErr
+	}
 
-		now := time.Now().UTC()
-		latest := tracker.LatestKnownChapter
-		if result.LatestChapter != nil {
-			latest = result.LatestChapter
+	now := time.Now().UTC()
+	result = holdBackScheduledChapter(result, now)
+	latest := tracker.LatestKnownChapter
+	if result.LatestChapter != nil {
+		latest = result.LatestChapter
+	}
+
+	latestReleaseAt := result.LastUpdatedAt
+	clearLatestReleaseAt := latestReleaseAt == nil && isNewChapter(tracker.LatestKnownChapter, result.LatestChapter)
+
+	var canonicalSourceItemID *string
+	resolvedSourceItemID := strings.TrimSpace(result.SourceItemID)
+	if resolvedSourceItemID != "" {
+		canonicalSourceItemID = &resolvedSourceItemID
+	} else {
+		canonicalSourceItemID = tracker.SourceItemID
+	}
+	canonicalSourceURL := strings.TrimSpace(result.URL)
+	if canonicalSourceURL == "" {
+		canonicalSourceURL = tracker.SourceURL
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, p.dbTimeout)
+	err := p.repo.UpdatePollingState(dbCtx, tracker.ID, tracker.SourceID, tracker.SourceURL, canonicalSourceItemID, canonicalSourceURL, latest, latestReleaseAt, clearLatestReleaseAt, now)
+	cancel()
+	if err != nil {
+		p.logger.Warn("poll update state failed", "trackerId", tracker.ID, "error", err)
+		return false, nil
+	}
+	if len(result.Genres) > 0 {
+		dbCtx, cancel := context.WithTimeout(ctx, p.dbTimeout)
+		if err := p.repo.SetSourceGenres(dbCtx, tracker.ID, result.Genres); err != nil {
+			p.logger.Warn("poll update genres failed", "trackerId", tracker.ID, "error", err)
 		}
+		cancel()
+	}
+	if result.NextScheduledChapter != nil || tracker.NextScheduledChapter != nil {
+		scheduled, scheduledAt := result.NextScheduledChapter, result.NextScheduledAt
+		if scheduled != nil && latest != nil && *scheduled <= *latest {
+			scheduled, scheduledAt = nil, nil
+		}
+		dbCtx, cancel := context.WithTimeout(ctx, p.dbTimeout)
+		if err := p.repo.SetScheduledChapter(dbCtx, tracker.ID, scheduled, scheduledAt); err != nil {
+			p.logger.Warn("poll update scheduled chapter failed", "trackerId", tracker.ID, "error", err)
+		}
+		cancel()
+	}
 
-		latestReleaseAt := result.LastUpdatedAt
-		clearLatestReleaseAt := latestReleaseAt == nil && isNewChapter(tracker.LatestKnownChapter, result.LatestChapter)
+	p.recordLinkedSources(ctx, tracker, result, canonicalSourceURL, panics)
+	return isNewChapter(tracker.LatestKnownChapter, result.LatestChapter), nil
+}
 
-		var canonicalSourceItemID *string
-		resolvedSourceItemID := strings.TrimSpace(result.SourceItemID)
-		if resolvedSourceItemID != "" {
-			canonicalSourceItemID = &resolvedSourceItemID
-		} else {
-			canonicalSourceItemID = tracker.SourceItemID
+// recordLinkedSources resolves the non-primary linked sources of a tracker
+// that has more than one, and stores per-source success/failure and how many
+// chapters each trailed the best source by. Chapters are compared after
+// adding each source's chapter offset. primaryResult is nil when the
+// primary failed to resolve; primaryURL is the primary's stored URL after the
+// polling update, which is what its tracker_sources row is keyed by.
+func (p *Poller) recordLinkedSources(ctx context.Context, tracker repository.PollingTracker, primaryResult *connectors.MangaResult, primaryURL string, panics *runPanics) {
+	if len(tracker.LinkedSources) < 2 {
+		return
+	}
+	if primaryURL == "" {
+		primaryURL = tracker.SourceURL
+	}
+
+	results := make([]repository.TrackerSourcePollResult, 0, len(tracker.LinkedSources))
+	chapters := make([]*float64, 0, len(tracker.LinkedSources))
+	var best *float64
+	var primaryChapter *float64
+
+	for _, source := range tracker.LinkedSources {
+		isPrimary := source.SourceID == tracker.SourceID &&
+			(strings.EqualFold(source.SourceURL, tracker.SourceURL) || strings.EqualFold(source.SourceURL, primaryURL))
+
+		var resolved *connectors.MangaResult
+		if isPrimary {
+			resolved = primaryResult
+			source.SourceURL = primaryURL
+		} else if connector, ok := p.registry.Get(source.SourceKey); ok {
+			requestCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+			result, err := p.resolve(requestCtx, connector, source.SourceURL, source.Lang, panics)
+			cancel()
+			if err != nil {
+				p.logger.Debug("poll linked source failed", "trackerId", tracker.ID, "sourceKey", source.SourceKey, "error", err)
+			} else {
+				resolved = holdBackScheduledChapter(result, time.Now().UTC())
+			}
 		}
-		canonicalSourceURL := strings.TrimSpace(result.URL)
-		if canonicalSourceURL == "" {
-			canonicalSourceURL = tracker.SourceURL
+
+		results = append(results, repository.TrackerSourcePollResult{
+			SourceID:  source.SourceID,
+			SourceURL: source.SourceURL,
+			OK:        resolved != nil,
+		})
+
+		var chapter *float64
+		if resolved != nil && resolved.LatestChapter != nil {
+			value := *resolved.LatestChapter + source.ChapterOffset
+			chapter = &value
+			if best == nil || value > *best {
+				best = &value
+			}
+			if isPrimary {
+				primaryChapter = &value
+			}
 		}
+		chapters = append(chapters, chapter)
+	}
 
-		if err := p.repo.UpdatePollingState(tracker.ID, tracker.SourceID, tracker.SourceURL, canonicalSourceItemID, canonicalSourceURL, latest, latestReleaseAt, clearLatestReleaseAt, now); err != nil {
-			p.logger.Warn("poll update state failed", "trackerId", tracker.ID, "error", err)
+	for index := range results {
+		if !results[index].OK || chapters[index] == nil || best == nil {
 			continue
 		}
+		lag := *best - *chapters[index]
+		results[index].LagChapters = &lag
+		if primaryChapter != nil && chaptersDiverge(*primaryChapter, *chapters[index]) {
+			results[index].MismatchSuspected = true
+		}
 	}
 
-	if skippedIdle > 0 {
-		p.logger.Debug("poll skipped idle trackers", "count", skippedIdle)
+	dbCtx, cancel := context.WithTimeout(ctx, p.dbTimeout)
+	defer cancel()
+	if err := p.repo.RecordTrackerSourcePolls(dbCtx, tracker.ID, results); err != nil {
+		p.logger.Warn("poll record linked sources failed", "trackerId", tracker.ID, "error", err)
 	}
+}
 
-	return nil
+// migrateTrackerURLs rewrites the tracker's primary and linked source URLs
+// by their connectors' URL migration rules, persisting each rewrite once.
+func (p *Poller) migrateTrackerURLs(ctx context.Context, tracker *repository.PollingTracker) {
+	original := tracker.SourceURL
+	tracker.SourceURL = p.migrateSourceURL(ctx, tracker.ID, tracker.SourceID, tracker.SourceKey, tracker.SourceURL)
+
+	linked := make([]repository.PollingTrackerSource, len(tracker.LinkedSources))
+	for index, source := range tracker.LinkedSources {
+		if source.SourceID == tracker.SourceID && source.SourceURL == original {
+			// The primary's row moved with the tracker.
+			source.SourceURL = tracker.SourceURL
+		} else {
+			source.SourceURL = p.migrateSourceURL(ctx, tracker.ID, source.SourceID, source.SourceKey, source.SourceURL)
+		}
+		linked[index] = source
+	}
+	tracker.LinkedSources = linked
+}
+
+// migrateSourceURL applies the connector's URL migration rules to a stored
+// source URL and persists the rewrite. It returns the URL to poll, which is
+// the original one when no rule applies or the rewrite could not be saved.
+func (p *Poller) migrateSourceURL(ctx context.Context, trackerID int64, sourceID int64, sourceKey string, rawURL string) string {
+	rules, ok := p.registry.URLMigrationRules(sourceKey)
+	if !ok {
+		return rawURL
+	}
+	migrated, applied := rules.Migrate(rawURL)
+	if len(applied) == 0 {
+		return rawURL
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, p.dbTimeout)
+	defer cancel()
+	if err := p.repo.MigrateSourceURL(dbCtx, trackerID, sourceID, rawURL, migrated); err != nil {
+		p.logger.Warn("poll migrate source url failed", "trackerId", trackerID, "sourceKey", sourceKey, "error", err)
+		return rawURL
+	}
+	p.logger.Info("source url migrated", "trackerId", trackerID, "sourceKey", sourceKey, "from", rawURL, "to", migrated, "rules", applied)
+	return migrated
+}
+
+// Linked sources of the same series rarely disagree by more than this factor
+// on the latest chapter; past it the source is likely another series or a
+// season with its own numbering. The minimum gap keeps brand-new series,
+// where 1 vs 4 chapters is normal, from being flagged.
+const (
+	chapterDivergenceRatio  = 3.0
+	chapterDivergenceMinGap = 10.0
+)
+
+// chaptersDiverge reports whether a linked source's latest chapter is too far
+// from the primary's to be the same series.
+func chaptersDiverge(primary float64, linked float64) bool {
+	if primary <= 0 || linked <= 0 {
+		return false
+	}
+	low, high := min(primary, linked), max(primary, linked)
+	return high-low >= chapterDivergenceMinGap && high > low*chapterDivergenceRatio
+}
+
+// scrapingPaused reports whether the global pause switch is on. A failed
+// read is logged and treated as not paused so polling keeps working.
+func (p *Poller) scrapingPaused() bool {
+	if p.pause == nil {
+		return false
+	}
+	paused, err := p.pause.ScrapingPaused()
+	if err != nil {
+		p.logger.Warn("poll read pause state failed", "error", err)
+		return false
+	}
+	return paused
+}
+
+// isManualSource reports whether sourceKey's connector is a manual one, whose
+// trackers' chapters are only ever logged by hand.
+func (p *Poller) isManualSource(sourceKey string) bool {
+	connector, ok := p.registry.Get(sourceKey)
+	return ok && connector.Kind() == connectors.KindManual
 }
 
 // shouldSkipIdle reports whether a non-reading tracker was checked recently
@@ -164,6 +583,24 @@ func (p *Poller) shouldSkipIdle(tracker repository.PollingTracker) bool {
 	return time.Since(*tracker.LastCheckedAt) < p.idleInterval
 }
 
+// holdBackScheduledChapter returns result with a latest chapter whose release
+// time is still ahead of now moved to the scheduled chapter, so a source that
+// lists chapters before they are out does not bump the tracker to one. A
+// scheduled chapter the connector reported itself is kept.
+func holdBackScheduledChapter(result *connectors.MangaResult, now time.Time) *connectors.MangaResult {
+	if result.LatestChapter == nil || result.LastUpdatedAt == nil || !result.LastUpdatedAt.After(now) {
+		return result
+	}
+	heldBack := *result
+	if heldBack.NextScheduledChapter == nil {
+		heldBack.NextScheduledChapter = result.LatestChapter
+		heldBack.NextScheduledAt = result.LastUpdatedAt
+	}
+	heldBack.LatestChapter = nil
+	heldBack.LastUpdatedAt = nil
+	return &heldBack
+}
+
 func isNewChapter(previous *float64, current *float64) bool {
 	if current == nil {
 		return false
@@ -2,11 +2,18 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors/manual"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
 	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"github.com/gabriel/cross-site-tracker/backend/internal/retention"
 )
 
 type fakeRepo struct {
@@ -16,13 +23,18 @@ type fakeRepo struct {
 	updatedURL    string
 	updatedLatest *float64
 	updatedAt     *time.Time
+	sourcePolls   []repository.TrackerSourcePollResult
+	migrations    []string
+	genres        []string
+	scheduled     *float64
+	scheduledAt   *time.Time
 }
 
-func (f *fakeRepo) ListForPolling() ([]repository.PollingTracker, error) {
+func (f *fakeRepo) ListForPolling(context.Context) ([]repository.PollingTracker, error) {
 	return f.items, nil
 }
 
-func (f *fakeRepo) UpdatePollingState(_ int64, _ int64, _ string, sourceItemID *string, sourceURL string, latestKnownChapter *float64, latestReleaseAt *time.Time, _ bool, _ time.Time) error {
+func (f *fakeRepo) UpdatePollingState(_ context.Context, _ int64, _ int64, _ string, sourceItemID *string, sourceURL string, latestKnownChapter *float64, latestReleaseAt *time.Time, _ bool, _ time.Time) error {
 	f.updatedCount++
 	f.updatedItemID = sourceItemID
 	f.updatedURL = sourceURL
@@ -31,9 +43,35 @@ func (f *fakeRepo) UpdatePollingState(_ int64, _ int64, _ string, sourceItemID *
 	return nil
 }
 
+func (f *fakeRepo) RecordTrackerSourcePolls(_ context.Context, _ int64, results []repository.TrackerSourcePollResult) error {
+	f.sourcePolls = append(f.sourcePolls, results...)
+	return nil
+}
+
+func (f *fakeRepo) SetPollError(context.Context, int64, string, time.Time) error {
+	return nil
+}
+
+func (f *fakeRepo) SetSourceGenres(_ context.Context, _ int64, genres []string) error {
+	f.genres = genres
+	return nil
+}
+
+func (f *fakeRepo) SetScheduledChapter(_ context.Context, _ int64, chapter *float64, scheduledAt *time.Time) error {
+	f.scheduled = chapter
+	f.scheduledAt = scheduledAt
+	return nil
+}
+
+func (f *fakeRepo) MigrateSourceURL(_ context.Context, _ int64, _ int64, oldURL string, newURL string) error {
+	f.migrations = append(f.migrations, oldURL+" -> "+newURL)
+	return nil
+}
+
 type fakeConnector struct {
 	latest      *float64
 	releaseDate *time.Time
+	genres      []string
 }
 
 func (f fakeConnector) Key() string                       { return "testsource" }
@@ -44,7 +82,27 @@ func (f fakeConnector) SearchByTitle(context.Context, string, int) ([]connectors
 	return nil, nil
 }
 func (f fakeConnector) ResolveByURL(context.Context, string) (*connectors.MangaResult, error) {
-	return &connectors.MangaResult{SourceKey: f.Key(), SourceItemID: "a", Title: "T", URL: "u", LatestChapter: f.latest, LastUpdatedAt: f.releaseDate}, nil
+	return &connectors.MangaResult{SourceKey: f.Key(), SourceItemID: "a", Title: "T", URL: "u", LatestChapter: f.latest, LastUpdatedAt: f.releaseDate, Genres: f.genres}, nil
+}
+
+type linkedSourceConnector struct {
+	key    string
+	latest *float64
+	err    error
+}
+
+func (f linkedSourceConnector) Key() string                       { return f.key }
+func (f linkedSourceConnector) Name() string                      { return f.key }
+func (f linkedSourceConnector) Kind() string                      { return connectors.KindNative }
+func (f linkedSourceConnector) HealthCheck(context.Context) error { return nil }
+func (f linkedSourceConnector) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+func (f linkedSourceConnector) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &connectors.MangaResult{SourceKey: f.key, Title: "T", URL: rawURL, LatestChapter: f.latest}, nil
 }
 
 func TestPollerRunOnce_UpdatesPollingState(t *testing.T) {
@@ -52,7 +110,7 @@ func TestPollerRunOnce_UpdatesPollingState(t *testing.T) {
 	next := 11.0
 	repo := &fakeRepo{items: []repository.PollingTracker{{ID: 1, Title: "A", Status: "reading", SourceURL: "https://example", SourceKey: "testsource", LatestKnownChapter: &prev}}}
 	registry := connectors.NewRegistry()
-	if err := registry.Register(fakeConnector{latest: &next}); err != nil {
+	if err := registry.Register(fakeConnector{latest: &next, genres: []string{"Action", "Drama"}}); err != nil {
 		t.Fatalf("register connector: %v", err)
 	}
 
@@ -73,6 +131,9 @@ func TestPollerRunOnce_UpdatesPollingState(t *testing.T) {
 	if repo.updatedItemID == nil || *repo.updatedItemID != "a" {
 		t.Fatalf("expected canonical source item id to be saved, got %#v", repo.updatedItemID)
 	}
+	if len(repo.genres) != 2 || repo.genres[0] != "Action" {
+		t.Fatalf("expected the source genres to be saved, got %v", repo.genres)
+	}
 }
 
 func TestPollerRunOnce_LeavesReleaseDateUnsetWhenChapterNotAdvanced(t *testing.T) {
@@ -124,6 +185,74 @@ func TestPollerRunOnce_SkipsRecentlyCheckedIdleTrackers(t *testing.T) {
 	}
 }
 
+func TestPollerRunOnce_SkipsManualSourceTrackers(t *testing.T) {
+	latest := 12.0
+	manualLatest := 40.0
+	repo := &fakeRepo{items: []repository.PollingTracker{
+		{ID: 1, Title: "Scraped", Status: "reading", SourceURL: "https://example/1", SourceKey: "testsource"},
+		{ID: 2, Title: "Logged by hand", Status: "reading", SourceURL: "https://walled.example/series/2", SourceKey: manual.Key, LatestKnownChapter: &manualLatest},
+	}}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(fakeConnector{latest: &latest}); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+	if err := registry.Register(manual.NewConnector()); err != nil {
+		t.Fatalf("register manual connector: %v", err)
+	}
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if repo.updatedCount != 1 || repo.updatedURL != "u" {
+		t.Fatalf("expected only the scraped tracker to be updated, got %d updates (last url %q)", repo.updatedCount, repo.updatedURL)
+	}
+	if run := poller.Status().LastRun; run == nil || run.Total != 1 || run.Processed != 1 {
+		t.Fatalf("expected the manual tracker to be left out of the run, got %+v", run)
+	}
+}
+
+func TestPollerRunOnce_HoldsBackChaptersNotReleasedYet(t *testing.T) {
+	prev := 10.0
+	next := 11.0
+	releasesAt := time.Now().Add(18 * time.Hour).UTC()
+	repo := &fakeRepo{items: []repository.PollingTracker{{ID: 1, Title: "A", Status: "reading", SourceURL: "https://example", SourceKey: "testsource", LatestKnownChapter: &prev}}}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(fakeConnector{latest: &next, releaseDate: &releasesAt}); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if repo.updatedLatest == nil || *repo.updatedLatest != prev || repo.updatedAt != nil {
+		t.Fatalf("expected the latest chapter to stay at %.0f until released, got %v at %v", prev, repo.updatedLatest, repo.updatedAt)
+	}
+	if repo.scheduled == nil || *repo.scheduled != next || repo.scheduledAt == nil || !repo.scheduledAt.Equal(releasesAt) {
+		t.Fatalf("expected chapter %.0f stored as scheduled, got %v at %v", next, repo.scheduled, repo.scheduledAt)
+	}
+	if status := poller.Status(); status.LastRun == nil || status.LastRun.NewChapters != 0 {
+		t.Fatalf("expected a scheduled chapter not to count as new, got %+v", status.LastRun)
+	}
+
+	// Once out, the chapter is the latest and the scheduled one is cleared.
+	released := time.Now().Add(-time.Minute).UTC()
+	repo.items[0].NextScheduledChapter = &next
+	registry = connectors.NewRegistry()
+	if err := registry.Register(fakeConnector{latest: &next, releaseDate: &released}); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+	if err := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil).RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	if repo.updatedLatest == nil || *repo.updatedLatest != next || repo.scheduled != nil {
+		t.Fatalf("expected the released chapter to become latest and the schedule to clear, got %v and %v", repo.updatedLatest, repo.scheduled)
+	}
+}
+
 func TestPollerRunOnce_LeavesReleaseDateUnsetWhenNewChapterHasNoReleaseDate(t *testing.T) {
 	prev := 340.0
 	next := 341.0
@@ -142,3 +271,618 @@ func TestPollerRunOnce_LeavesReleaseDateUnsetWhenNewChapterHasNoReleaseDate(t *t
 		t.Fatalf("expected release date to remain unset when source does not provide one")
 	}
 }
+
+func TestPollerRunOnce_RecordsLinkedSourceReliability(t *testing.T) {
+	primaryLatest := 12.0
+	laggingLatest := 9.0
+	repo := &fakeRepo{items: []repository.PollingTracker{{
+		ID:        1,
+		Title:     "A",
+		Status:    "reading",
+		SourceID:  1,
+		SourceURL: "u",
+		SourceKey: "testsource",
+		LinkedSources: []repository.PollingTrackerSource{
+			{SourceID: 1, SourceKey: "testsource", SourceURL: "u"},
+			{SourceID: 2, SourceKey: "lagging", SourceURL: "https://lagging/a"},
+			{SourceID: 3, SourceKey: "broken", SourceURL: "https://broken/a"},
+		},
+	}}}
+	registry := connectors.NewRegistry()
+	for _, connector := range []connectors.Connector{
+		fakeConnector{latest: &primaryLatest},
+		linkedSourceConnector{key: "lagging", latest: &laggingLatest},
+		linkedSourceConnector{key: "broken", err: errors.New("upstream down")},
+	} {
+		if err := registry.Register(connector); err != nil {
+			t.Fatalf("register connector: %v", err)
+		}
+	}
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if len(repo.sourcePolls) != 3 {
+		t.Fatalf("expected 3 linked source results, got %d", len(repo.sourcePolls))
+	}
+	primary, lagging, broken := repo.sourcePolls[0], repo.sourcePolls[1], repo.sourcePolls[2]
+	if !primary.OK || primary.LagChapters == nil || *primary.LagChapters != 0 {
+		t.Fatalf("expected fresh primary result, got %#v", primary)
+	}
+	if !lagging.OK || lagging.LagChapters == nil || *lagging.LagChapters != 3 {
+		t.Fatalf("expected lagging source to trail by 3 chapters, got %#v", lagging)
+	}
+	if broken.OK || broken.LagChapters != nil {
+		t.Fatalf("expected failed result for broken source, got %#v", broken)
+	}
+}
+
+func TestPollerRunOnce_ComparesLinkedSourcesWithChapterOffsets(t *testing.T) {
+	primaryLatest := 96.0
+	splitLatest := 100.0
+	repo := &fakeRepo{items: []repository.PollingTracker{{
+		ID:        1,
+		Title:     "A",
+		Status:    "reading",
+		SourceID:  1,
+		SourceURL: "u",
+		SourceKey: "testsource",
+		LinkedSources: []repository.PollingTrackerSource{
+			// The primary merged four split chapters, so its 96 is the split
+			// site's 100.
+			{SourceID: 1, SourceKey: "testsource", SourceURL: "u", ChapterOffset: 4},
+			{SourceID: 2, SourceKey: "split", SourceURL: "https://split/a"},
+		},
+	}}}
+	registry := connectors.NewRegistry()
+	for _, connector := range []connectors.Connector{
+		fakeConnector{latest: &primaryLatest},
+		linkedSourceConnector{key: "split", latest: &splitLatest},
+	} {
+		if err := registry.Register(connector); err != nil {
+			t.Fatalf("register connector: %v", err)
+		}
+	}
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if len(repo.sourcePolls) != 2 {
+		t.Fatalf("expected 2 linked source results, got %d", len(repo.sourcePolls))
+	}
+	for _, result := range repo.sourcePolls {
+		if !result.OK || result.LagChapters == nil || *result.LagChapters != 0 {
+			t.Fatalf("expected offset sources to be level, got %#v", result)
+		}
+	}
+	if repo.updatedLatest == nil || *repo.updatedLatest != 96 {
+		t.Fatalf("expected the primary's raw latest chapter to be stored, got %v", repo.updatedLatest)
+	}
+}
+
+// movedConnector is a site that left old.example for new.example.
+type movedConnector struct {
+	linkedSourceConnector
+}
+
+func (movedConnector) CanonicalScheme() string { return "https" }
+func (movedConnector) HostMigrations() []connectors.HostMigration {
+	return []connectors.HostMigration{{From: "old.example", To: "new.example"}}
+}
+
+func TestPollerRunOnce_MigratesStoredSourceURLs(t *testing.T) {
+	latest := 5.0
+	repo := &fakeRepo{items: []repository.PollingTracker{{
+		ID:        1,
+		Title:     "A",
+		Status:    "reading",
+		SourceID:  1,
+		SourceURL: "http://old.example/series/a",
+		SourceKey: "moved",
+		LinkedSources: []repository.PollingTrackerSource{
+			{SourceID: 1, SourceKey: "moved", SourceURL: "http://old.example/series/a"},
+			{SourceID: 1, SourceKey: "moved", SourceURL: "https://www.old.example/series/a-alt"},
+			{SourceID: 2, SourceKey: "lagging", SourceURL: "http://lagging/a"},
+		},
+	}}}
+	registry := connectors.NewRegistry()
+	_ = registry.Register(movedConnector{linkedSourceConnector{key: "moved", latest: &latest}})
+	_ = registry.Register(linkedSourceConnector{key: "lagging", latest: &latest})
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	wantMigrations := []string{
+		"http://old.example/series/a -> https://new.example/series/a",
+		"https://www.old.example/series/a-alt -> https://www.new.example/series/a-alt",
+	}
+	if len(repo.migrations) != len(wantMigrations) {
+		t.Fatalf("expected each moved url to be persisted once, got %v", repo.migrations)
+	}
+	for index, want := range wantMigrations {
+		if repo.migrations[index] != want {
+			t.Fatalf("expected migration %q, got %q", want, repo.migrations[index])
+		}
+	}
+	if repo.updatedURL != "https://new.example/series/a" {
+		t.Fatalf("expected the migrated url to be polled, got %q", repo.updatedURL)
+	}
+	if len(repo.sourcePolls) != 3 || repo.sourcePolls[0].SourceURL != "https://new.example/series/a" || repo.sourcePolls[2].SourceURL != "http://lagging/a" {
+		t.Fatalf("expected linked results keyed by migrated urls only where rules apply, got %#v", repo.sourcePolls)
+	}
+}
+
+// langConnector records the language each URL was resolved in.
+type langConnector struct {
+	linkedSourceConnector
+	langs map[string]string
+}
+
+func (f langConnector) ResolveByURLWithLang(ctx context.Context, rawURL string, lang string) (*connectors.MangaResult, error) {
+	f.langs[rawURL] = lang
+	return f.ResolveByURL(ctx, rawURL)
+}
+
+func TestPollerRunOnce_ResolvesSourcesInTheirLanguage(t *testing.T) {
+	latest := 12.0
+	repo := &fakeRepo{items: []repository.PollingTracker{{
+		ID:         1,
+		Title:      "A",
+		Status:     "reading",
+		SourceID:   1,
+		SourceURL:  "https://polyglot/a",
+		SourceKey:  "polyglot",
+		SourceLang: "pt-br",
+		LinkedSources: []repository.PollingTrackerSource{
+			{SourceID: 1, SourceKey: "polyglot", SourceURL: "https://polyglot/a", Lang: "pt-br"},
+			{SourceID: 1, SourceKey: "polyglot", SourceURL: "https://polyglot/b", Lang: "es"},
+			{SourceID: 2, SourceKey: "plain", SourceURL: "https://plain/a", Lang: "fr"},
+		},
+	}}}
+	langs := make(map[string]string)
+	registry := connectors.NewRegistry()
+	for _, connector := range []connectors.Connector{
+		langConnector{linkedSourceConnector: linkedSourceConnector{key: "polyglot", latest: &latest}, langs: langs},
+		linkedSourceConnector{key: "plain", latest: &latest},
+	} {
+		if err := registry.Register(connector); err != nil {
+			t.Fatalf("register connector: %v", err)
+		}
+	}
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if langs["https://polyglot/a"] != "pt-br" || langs["https://polyglot/b"] != "es" {
+		t.Fatalf("expected each linked source resolved in its language, got %v", langs)
+	}
+	if _, ok := langs["https://plain/a"]; ok || len(repo.sourcePolls) != 3 || !repo.sourcePolls[2].OK {
+		t.Fatalf("expected the plain source resolved without a language, got %v %#v", langs, repo.sourcePolls)
+	}
+}
+
+func TestPollerRunOnce_FlagsLinkedSourceWithDivergentChapters(t *testing.T) {
+	primaryLatest := 120.0
+	closeLatest := 118.0
+	sequelLatest := 14.0
+	repo := &fakeRepo{items: []repository.PollingTracker{{
+		ID:        1,
+		Title:     "A",
+		Status:    "reading",
+		SourceID:  1,
+		SourceURL: "u",
+		SourceKey: "testsource",
+		LinkedSources: []repository.PollingTrackerSource{
+			{SourceID: 1, SourceKey: "testsource", SourceURL: "u"},
+			{SourceID: 2, SourceKey: "close", SourceURL: "https://close/a"},
+			{SourceID: 3, SourceKey: "sequel", SourceURL: "https://sequel/a"},
+		},
+	}}}
+	registry := connectors.NewRegistry()
+	for _, connector := range []connectors.Connector{
+		fakeConnector{latest: &primaryLatest},
+		linkedSourceConnector{key: "close", latest: &closeLatest},
+		linkedSourceConnector{key: "sequel", latest: &sequelLatest},
+	} {
+		if err := registry.Register(connector); err != nil {
+			t.Fatalf("register connector: %v", err)
+		}
+	}
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if len(repo.sourcePolls) != 3 {
+		t.Fatalf("expected 3 linked source results, got %d", len(repo.sourcePolls))
+	}
+	if repo.sourcePolls[0].MismatchSuspected || repo.sourcePolls[1].MismatchSuspected {
+		t.Fatalf("expected primary and close source to stay unflagged, got %#v", repo.sourcePolls[:2])
+	}
+	if !repo.sourcePolls[2].MismatchSuspected {
+		t.Fatalf("expected source more than 3x behind the primary to be flagged, got %#v", repo.sourcePolls[2])
+	}
+}
+
+func TestChaptersDivergeNeedsRatioAndGap(t *testing.T) {
+	cases := []struct {
+		primary, linked float64
+		want            bool
+	}{
+		{primary: 120, linked: 14, want: true},
+		{primary: 14, linked: 120, want: true},
+		{primary: 120, linked: 41, want: false},
+		{primary: 4, linked: 1, want: false},
+		{primary: 30, linked: 0, want: false},
+	}
+	for _, tc := range cases {
+		if got := chaptersDiverge(tc.primary, tc.linked); got != tc.want {
+			t.Errorf("chaptersDiverge(%v, %v) = %v, want %v", tc.primary, tc.linked, got, tc.want)
+		}
+	}
+}
+
+type pauseStub struct {
+	paused bool
+}
+
+func (p pauseStub) ScrapingPaused() (bool, error) {
+	return p.paused, nil
+}
+
+func TestPollerRunOnce_SkipsCycleWhileScrapingPaused(t *testing.T) {
+	previous := 10.0
+	next := 11.0
+	repo := &fakeRepo{items: []repository.PollingTracker{{ID: 1, Title: "A", Status: "reading", SourceURL: "https://example", SourceKey: "testsource", LatestKnownChapter: &previous}}}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(fakeConnector{latest: &next}); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute, Pause: pauseStub{paused: true}}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if repo.updatedCount != 0 {
+		t.Fatalf("expected no polling updates while paused, got %d", repo.updatedCount)
+	}
+}
+
+type prunerStub struct {
+	runs int
+}
+
+func (p *prunerStub) Prune(context.Context) ([]retention.Result, error) {
+	p.runs++
+	return nil, nil
+}
+
+func TestPollerRunOnce_PrunesAfterEachCycleThatRan(t *testing.T) {
+	previous := 10.0
+	repo := &fakeRepo{items: []repository.PollingTracker{{ID: 1, Title: "A", Status: "reading", SourceURL: "https://example", SourceKey: "testsource", LatestKnownChapter: &previous}}}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(fakeConnector{latest: &previous}); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+
+	pruner := &prunerStub{}
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute, Pruner: pruner}, nil)
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	if pruner.runs != 1 {
+		t.Fatalf("expected one prune after the cycle, got %d", pruner.runs)
+	}
+
+	paused := NewPoller(repo, registry, PollerConfig{Interval: time.Minute, Pause: pauseStub{paused: true}, Pruner: pruner}, nil)
+	if err := paused.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	if pruner.runs != 1 {
+		t.Fatalf("expected a skipped cycle not to prune, got %d runs", pruner.runs)
+	}
+}
+
+func TestPollerRunOnce_StoresLastPollErrorUntilNextSuccess(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "poller.sqlite"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	_, currentFile, _, _ := runtime.Caller(0)
+	if err := database.ApplyMigrations(db, filepath.Join(filepath.Dir(currentFile), "..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter)
+		SELECT 1, 'Failing Series', id, 'https://www.mgeko.cc/manga/failing-series/', 'reading', 3
+		FROM sources WHERE key = 'mgeko'
+	`)
+	if err != nil {
+		t.Fatalf("seed tracker: %v", err)
+	}
+	trackerID, _ := result.LastInsertId()
+
+	repo := repository.NewTrackerRepository(db)
+	latest := 4.0
+	longError := errors.New("unexpected status: 404 " + strings.Repeat("x", 600))
+	connector := &linkedSourceConnector{key: "mgeko", latest: &latest, err: longError}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(connector); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	tracker, err := repo.GetByID(context.Background(), 1, trackerID)
+	if err != nil || tracker == nil {
+		t.Fatalf("load tracker: %v", err)
+	}
+	if tracker.LastPollError == nil || !strings.HasPrefix(*tracker.LastPollError, "unexpected status: 404") {
+		t.Fatalf("expected stored poll error, got %#v", tracker.LastPollError)
+	}
+	if len(*tracker.LastPollError) > 510 {
+		t.Fatalf("expected long poll error to be truncated, got %d bytes", len(*tracker.LastPollError))
+	}
+	if tracker.LastPollErrorAt == nil || time.Since(*tracker.LastPollErrorAt) > time.Minute {
+		t.Fatalf("expected recent poll error time, got %#v", tracker.LastPollErrorAt)
+	}
+
+	connector.err = nil
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	tracker, err = repo.GetByID(context.Background(), 1, trackerID)
+	if err != nil || tracker == nil {
+		t.Fatalf("load tracker: %v", err)
+	}
+	if tracker.LastPollError != nil || tracker.LastPollErrorAt != nil {
+		t.Fatalf("expected poll error to be cleared after a successful poll, got %#v at %#v", tracker.LastPollError, tracker.LastPollErrorAt)
+	}
+	if tracker.LatestKnownChapter == nil || *tracker.LatestKnownChapter != latest {
+		t.Fatalf("expected successful poll to update the latest chapter, got %#v", tracker.LatestKnownChapter)
+	}
+}
+
+// recordingConnector remembers every URL it resolved.
+type recordingConnector struct {
+	linkedSourceConnector
+	resolved []string
+}
+
+func (f *recordingConnector) ResolveByURL(ctx context.Context, rawURL string) (*connectors.MangaResult, error) {
+	f.resolved = append(f.resolved, rawURL)
+	return f.linkedSourceConnector.ResolveByURL(ctx, rawURL)
+}
+
+func TestPollerRunOnce_SkipsProfilesWithPollingDisabled(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "poller.sqlite"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	_, currentFile, _, _ := runtime.Caller(0)
+	if err := database.ApplyMigrations(db, filepath.Join(filepath.Dir(currentFile), "..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+
+	if _, err := db.Exec(`
+		INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter)
+		SELECT 1, 'Main Series', id, 'https://www.mgeko.cc/manga/main-series/', 'reading', 3
+		FROM sources WHERE key = 'mgeko'
+		UNION ALL
+		SELECT 2, 'Archived Series', id, 'https://www.mgeko.cc/manga/archived-series/', 'completed', 120
+		FROM sources WHERE key = 'mgeko'
+	`); err != nil {
+		t.Fatalf("seed trackers: %v", err)
+	}
+	profiles := repository.NewProfileRepository(db)
+	if _, err := profiles.SetPollingEnabled(context.Background(), 2, false); err != nil {
+		t.Fatalf("disable polling: %v", err)
+	}
+
+	latest := 4.0
+	connector := &recordingConnector{linkedSourceConnector: linkedSourceConnector{key: "mgeko", latest: &latest}}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(connector); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+	poller := NewPoller(repository.NewTrackerRepository(db), registry, PollerConfig{Interval: time.Minute}, nil)
+
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	if len(connector.resolved) != 1 || connector.resolved[0] != "https://www.mgeko.cc/manga/main-series/" {
+		t.Fatalf("expected only the enabled profile's tracker to be resolved, got %v", connector.resolved)
+	}
+
+	polledAt, err := profiles.LastPolledAt(context.Background(), 1)
+	if err != nil || polledAt == nil {
+		t.Fatalf("expected the enabled profile to have a last poll, got %v (%v)", polledAt, err)
+	}
+	polledAt, err = profiles.LastPolledAt(context.Background(), 2)
+	if err != nil || polledAt != nil {
+		t.Fatalf("expected the disabled profile never to be polled, got %v (%v)", polledAt, err)
+	}
+}
+
+// slowConnector blocks each resolve until the test releases it, so the
+// poller's status can be read mid-cycle.
+type slowConnector struct {
+	started chan string
+	release chan struct{}
+	latest  map[string]float64
+}
+
+func (f slowConnector) Key() string                       { return "slowsource" }
+func (f slowConnector) Name() string                      { return "Slow Source" }
+func (f slowConnector) Kind() string                      { return connectors.KindNative }
+func (f slowConnector) HealthCheck(context.Context) error { return nil }
+func (f slowConnector) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+func (f slowConnector) ResolveByURL(ctx context.Context, rawURL string) (*connectors.MangaResult, error) {
+	f.started <- rawURL
+	select {
+	case <-f.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	latest := f.latest[rawURL]
+	return &connectors.MangaResult{SourceKey: f.Key(), Title: "T", URL: rawURL, LatestChapter: &latest}, nil
+}
+
+func TestPollerStatus_ReportsProgressDuringRun(t *testing.T) {
+	known := 10.0
+	repo := &fakeRepo{items: []repository.PollingTracker{
+		{ID: 1, Title: "A", Status: "reading", SourceURL: "https://example/1", SourceKey: "slowsource", LatestKnownChapter: &known},
+		{ID: 2, Title: "B", Status: "reading", SourceURL: "https://example/2", SourceKey: "slowsource", LatestKnownChapter: &known},
+		{ID: 3, Title: "C", Status: "reading", SourceURL: "https://example/3", SourceKey: "slowsource", LatestKnownChapter: &known},
+	}}
+	connector := slowConnector{
+		started: make(chan string),
+		release: make(chan struct{}),
+		latest:  map[string]float64{"https://example/1": 11, "https://example/2": 10, "https://example/3": 12},
+	}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(connector); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute}, nil)
+	if status := poller.Status(); status.Running || status.LastRun != nil {
+		t.Fatalf("expected an idle poller with no runs, got %+v", status)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- poller.RunOnce(context.Background()) }()
+
+	for index := 0; index < 3; index++ {
+		select {
+		case <-connector.started:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for resolve %d", index+1)
+		}
+		status := poller.Status()
+		if !status.Running || status.Processed != index || status.Total != 3 || status.CurrentSourceKey != "slowsource" || status.StartedAt == nil || status.Generation != 0 {
+			t.Fatalf("resolve %d: unexpected mid-run status %+v", index+1, status)
+		}
+		connector.release <- struct{}{}
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	status := poller.Status()
+	if status.Running || status.CurrentSourceKey != "" || status.Generation != 1 {
+		t.Fatalf("expected the poller to be idle after one finished cycle, got %+v", status)
+	}
+	if status.LastRun == nil || status.LastRun.Processed != 3 || status.LastRun.Total != 3 || status.LastRun.NewChapters != 2 {
+		t.Fatalf("unexpected last run summary %+v", status.LastRun)
+	}
+	if status.LastRun.FinishedAt.Before(status.LastRun.StartedAt) {
+		t.Fatalf("expected finish after start, got %+v", status.LastRun)
+	}
+}
+
+type fakeActivity struct {
+	at time.Time
+	ok bool
+}
+
+func (f *fakeActivity) LastActivityAt() (time.Time, bool, error) {
+	return f.at, f.ok, nil
+}
+
+func TestPollerStretchesItsIntervalWhileNobodyUsesTheInstall(t *testing.T) {
+	latest := 5.0
+	repo := &fakeRepo{items: []repository.PollingTracker{{ID: 1, Title: "A", Status: "reading", SourceURL: "https://example", SourceKey: "testsource", LatestKnownChapter: &latest}}}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(fakeConnector{latest: &latest}); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	activity := &fakeActivity{}
+	poller := NewPoller(repo, registry, PollerConfig{
+		Interval:       30 * time.Minute,
+		Activity:       activity,
+		InactiveAfter:  7 * 24 * time.Hour,
+		InactiveFactor: 4,
+	}, nil)
+	poller.now = func() time.Time { return now }
+
+	// tick advances the clock by one interval and reports whether the tick
+	// ran a cycle.
+	tick := func() bool {
+		t.Helper()
+		now = now.Add(30 * time.Minute)
+		before := repo.updatedCount
+		if err := poller.runIfDue(context.Background()); err != nil {
+			t.Fatalf("tick: %v", err)
+		}
+		return repo.updatedCount > before
+	}
+
+	// Without a recorded request the interval stays as set.
+	if !tick() {
+		t.Fatalf("expected a cycle before any request was recorded")
+	}
+	activity.at, activity.ok = now, true
+	if !tick() {
+		t.Fatalf("expected a cycle every interval while the install is used")
+	}
+	if status := poller.Status(); status.Inactive || status.IntervalSeconds != 1800 {
+		t.Fatalf("expected the normal interval, got %+v", status)
+	}
+
+	activity.at = now.Add(-8 * 24 * time.Hour)
+	status := poller.Status()
+	if !status.Inactive || status.IntervalSeconds != 7200 || status.LastActivityAt == nil || !status.LastActivityAt.Equal(activity.at) {
+		t.Fatalf("expected a stretched interval after a week without requests, got %+v", status)
+	}
+	ran := []bool{tick(), tick(), tick(), tick()}
+	if ran[0] || ran[1] || ran[2] || !ran[3] {
+		t.Fatalf("expected one cycle every four ticks while inactive, got %v", ran)
+	}
+
+	// The next request brings the interval back at the following tick.
+	activity.at = now
+	if !tick() {
+		t.Fatalf("expected a cycle at the first tick after a request")
+	}
+
+	activity.at = now.Add(-8 * 24 * time.Hour)
+	poller.inactiveFactor = 0
+	if status := poller.Status(); !status.Inactive || status.IntervalSeconds != 0 {
+		t.Fatalf("expected polling to wait for a request, got %+v", status)
+	}
+	for range 8 {
+		if tick() {
+			t.Fatalf("expected no cycle while paused for inactivity")
+		}
+	}
+	activity.at = now
+	if !tick() {
+		t.Fatalf("expected polling to resume after a request")
+	}
+}
@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+)
+
+// connectorPanic is the error a resolve returns in place of a panic in the
+// connector, such as an index out of range on unexpected HTML.
+type connectorPanic struct {
+	sourceKey string
+	value     any
+}
+
+func (e *connectorPanic) Error() string {
+	return fmt.Sprintf("%s connector crashed: %v", e.sourceKey, e.value)
+}
+
+// runPanics counts a cycle's connector panics and remembers which sources
+// already had their stack trace logged, so a connector that panics on every
+// tracker logs one trace per cycle.
+type runPanics struct {
+	count  int
+	logged map[string]bool
+}
+
+func newRunPanics() *runPanics {
+	return &runPanics{logged: make(map[string]bool)}
+}
+
+// resolve resolves rawURL like connectors.ResolveByURLWithLang, turning a
+// panic in the connector into a *connectorPanic error.
+func (p *Poller) resolve(ctx context.Context, connector connectors.Connector, rawURL string, lang string, panics *runPanics) (result *connectors.MangaResult, err error) {
+	defer p.recoverConnector(connector.Key(), "url", rawURL, panics, &err)
+	return connectors.ResolveByURLWithLang(ctx, connector, rawURL, lang)
+}
+
+// resolveMany is resolver.ResolveMany with the same panic recovery as
+// resolve.
+func (p *Poller) resolveMany(ctx context.Context, sourceKey string, resolver connectors.BulkResolver, urls []string, panics *runPanics) (results map[string]*connectors.MangaResult, err error) {
+	defer p.recoverConnector(sourceKey, "count", len(urls), panics, &err)
+	return resolver.ResolveMany(ctx, urls)
+}
+
+// recoverConnector is deferred by the resolve helpers. It stores a
+// recovered panic in *err, counts it and logs its stack trace the first
+// time the source panics in the cycle.
+func (p *Poller) recoverConnector(sourceKey string, detailKey string, detail any, panics *runPanics, err *error) {
+	value := recover()
+	if value == nil {
+		return
+	}
+	*err = &connectorPanic{sourceKey: sourceKey, value: value}
+	panics.count++
+	if panics.logged[sourceKey] {
+		p.logger.Warn("poll connector panicked", "sourceKey", sourceKey, detailKey, detail, "panic", value)
+		return
+	}
+	panics.logged[sourceKey] = true
+	p.logger.Error("poll connector panicked", "sourceKey", sourceKey, detailKey, detail, "panic", value, "stack", string(debug.Stack()))
+}
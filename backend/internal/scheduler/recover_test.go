@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// panickingConnector crashes on series URLs containing /broken-, the way a
+// parser indexing into unexpected HTML does, and resolves the rest.
+type panickingConnector struct {
+	linkedSourceConnector
+}
+
+func (f panickingConnector) ResolveByURL(ctx context.Context, rawURL string) (*connectors.MangaResult, error) {
+	if strings.Contains(rawURL, "/broken-") {
+		var matches []string
+		_ = matches[1]
+	}
+	return f.linkedSourceConnector.ResolveByURL(ctx, rawURL)
+}
+
+func TestPollerRunOnce_SurvivesAPanickingConnector(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "poller.sqlite"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	_, currentFile, _, _ := runtime.Caller(0)
+	if err := database.ApplyMigrations(db, filepath.Join(filepath.Dir(currentFile), "..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+
+	slugs := []string{"before", "broken-one", "broken-two", "broken-three", "after"}
+	trackerIDs := map[string]int64{}
+	for _, slug := range slugs {
+		result, err := db.Exec(`
+			INSERT INTO trackers (profile_id, title, source_id, source_url, status, latest_known_chapter)
+			SELECT 1, ?, id, ?, 'reading', 3
+			FROM sources WHERE key = 'mgeko'
+		`, slug, "https://www.mgeko.cc/manga/"+slug+"/")
+		if err != nil {
+			t.Fatalf("seed %s tracker: %v", slug, err)
+		}
+		trackerIDs[slug], _ = result.LastInsertId()
+	}
+
+	latest := 4.0
+	registry := connectors.NewRegistry()
+	if err := registry.Register(panickingConnector{linkedSourceConnector{key: "mgeko", latest: &latest}}); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	notes := &fakeSourceNotes{}
+	repo := repository.NewTrackerRepository(db)
+	poller := NewPoller(repo, registry, PollerConfig{Interval: time.Minute, SourceNotes: notes}, logger)
+
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	run := poller.Status().LastRun
+	if run == nil || run.Processed != len(slugs) || run.Panics != 3 {
+		t.Fatalf("expected every tracker processed and three panics counted, got %+v", run)
+	}
+	for _, slug := range slugs {
+		tracker, err := repo.GetByID(context.Background(), 1, trackerIDs[slug])
+		if err != nil || tracker == nil {
+			t.Fatalf("load %s tracker: %v", slug, err)
+		}
+		if !strings.HasPrefix(slug, "broken-") {
+			if tracker.LatestKnownChapter == nil || *tracker.LatestKnownChapter != latest || tracker.LastPollError != nil {
+				t.Fatalf("expected %s updated despite the panics, got %+v", slug, tracker)
+			}
+			continue
+		}
+		if tracker.LastPollError == nil || !strings.HasPrefix(*tracker.LastPollError, "mgeko connector crashed: runtime error: index out of range") {
+			t.Fatalf("expected the panic stored as %s's poll error, got %v", slug, tracker.LastPollError)
+		}
+	}
+
+	if note := notes.written["mgeko"]; !strings.HasPrefix(note, "3 of 5 update checks failed") {
+		t.Fatalf("expected the panics to count against the source, got %q", note)
+	}
+	if traces := strings.Count(logs.String(), "stack="); traces != 1 {
+		t.Fatalf("expected one stack trace for the source, got %d in %s", traces, logs.String())
+	}
+	if crashes := strings.Count(logs.String(), "poll connector panicked"); crashes != 3 {
+		t.Fatalf("expected each panic logged, got %d", crashes)
+	}
+
+	// The next cycle logs the source's trace again.
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+	if traces := strings.Count(logs.String(), "stack="); traces != 2 {
+		t.Fatalf("expected a fresh stack trace in the next cycle, got %d", traces)
+	}
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SourceNotes stores the notes the poller writes on sources that keep
+// failing; see repository.SourceRepository. Implementations must leave
+// manually written notes alone.
+type SourceNotes interface {
+	SetAutoStatusNote(ctx context.Context, sourceKey string, note string, at time.Time) (bool, error)
+	ClearAutoStatusNote(ctx context.Context, sourceKey string) (bool, error)
+}
+
+const maxSourceNoteErrorLength = 160
+
+// sourceRunStats counts one run's primary-source checks for a source.
+type sourceRunStats struct {
+	checks    int
+	failures  int
+	lastError string
+}
+
+func (s *sourceRunStats) record(err error) {
+	s.checks++
+	if err != nil {
+		s.failures++
+		s.lastError = err.Error()
+	}
+}
+
+// failing reports whether the run's failure rate for the source exceeds
+// threshold over at least minChecks checks.
+func (s *sourceRunStats) failing(threshold float64, minChecks int) bool {
+	return s.checks >= minChecks && float64(s.failures)/float64(s.checks) > threshold
+}
+
+// updateSourceNotes writes a note on each source that failed too often in
+// the run and clears the poller's note from sources that had a clean run.
+func (p *Poller) updateSourceNotes(ctx context.Context, stats map[string]*sourceRunStats, at time.Time) {
+	if p.sourceNotes == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.dbTimeout)
+	defer cancel()
+	for sourceKey, run := range stats {
+		switch {
+		case run.failing(p.noteFailureRate, p.noteMinChecks):
+			written, err := p.sourceNotes.SetAutoStatusNote(ctx, sourceKey, autoSourceNote(run, at), at)
+			if err != nil {
+				p.logger.Warn("poll write source note failed", "sourceKey", sourceKey, "error", err)
+			} else if written {
+				p.logger.Info("poll flagged failing source", "sourceKey", sourceKey, "failures", run.failures, "checks", run.checks)
+			}
+		case run.failures == 0:
+			if _, err := p.sourceNotes.ClearAutoStatusNote(ctx, sourceKey); err != nil {
+				p.logger.Warn("poll clear source note failed", "sourceKey", sourceKey, "error", err)
+			}
+		}
+	}
+}
+
+func autoSourceNote(run *sourceRunStats, at time.Time) string {
+	lastError := run.lastError
+	if runes := []rune(lastError); len(runes) > maxSourceNoteErrorLength {
+		lastError = string(runes[:maxSourceNoteErrorLength]) + "…"
+	}
+	return fmt.Sprintf("%d of %d update checks failed on %s. Last error: %s",
+		run.failures, run.checks, at.UTC().Format("Jan 2, 15:04 UTC"), lastError)
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/database"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+type fakeSourceNotes struct {
+	written map[string]string
+	cleared []string
+}
+
+func (f *fakeSourceNotes) SetAutoStatusNote(_ context.Context, sourceKey string, note string, _ time.Time) (bool, error) {
+	if f.written == nil {
+		f.written = make(map[string]string)
+	}
+	f.written[sourceKey] = note
+	return true, nil
+}
+
+func (f *fakeSourceNotes) ClearAutoStatusNote(_ context.Context, sourceKey string) (bool, error) {
+	f.cleared = append(f.cleared, sourceKey)
+	return true, nil
+}
+
+// flakyConnector fails to resolve the URLs listed in failing.
+type flakyConnector struct {
+	key     string
+	failing map[string]bool
+}
+
+func (f flakyConnector) Key() string                       { return f.key }
+func (f flakyConnector) Name() string                      { return f.key }
+func (f flakyConnector) Kind() string                      { return connectors.KindNative }
+func (f flakyConnector) HealthCheck(context.Context) error { return nil }
+func (f flakyConnector) SearchByTitle(context.Context, string, int) ([]connectors.MangaResult, error) {
+	return nil, nil
+}
+func (f flakyConnector) ResolveByURL(_ context.Context, rawURL string) (*connectors.MangaResult, error) {
+	if f.failing[rawURL] {
+		return nil, errors.New("cloudflare challenge")
+	}
+	return &connectors.MangaResult{SourceKey: f.key, Title: "T", URL: rawURL}, nil
+}
+
+func TestPollerRunOnce_NotesSourcesThatFailTooOften(t *testing.T) {
+	trackers := []repository.PollingTracker{}
+	failing := map[string]bool{}
+	addTrackers := func(sourceKey string, total, failures int) {
+		for index := 0; index < total; index++ {
+			url := sourceKey + "/" + string(rune('a'+index))
+			trackers = append(trackers, repository.PollingTracker{ID: int64(len(trackers) + 1), Status: "reading", SourceKey: sourceKey, SourceURL: url})
+			if index < failures {
+				failing[url] = true
+			}
+		}
+	}
+	addTrackers("walled", 4, 3)  // 75% failed: noted
+	addTrackers("wobbly", 4, 2)  // 50% failed: not over the threshold, left alone
+	addTrackers("few", 2, 2)     // too few checks to judge, left alone
+	addTrackers("healthy", 3, 0) // clean run: any auto note is cleared
+
+	registry := connectors.NewRegistry()
+	for _, key := range []string{"walled", "wobbly", "few", "healthy"} {
+		if err := registry.Register(flakyConnector{key: key, failing: failing}); err != nil {
+			t.Fatalf("register %s: %v", key, err)
+		}
+	}
+	notes := &fakeSourceNotes{}
+	poller := NewPoller(&fakeRepo{items: trackers}, registry, PollerConfig{Interval: time.Minute, SourceNotes: notes}, nil)
+
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+
+	if len(notes.written) != 1 {
+		t.Fatalf("expected a note on the walled source only, got %v", notes.written)
+	}
+	if note := notes.written["walled"]; !strings.HasPrefix(note, "3 of 4 update checks failed on ") || !strings.HasSuffix(note, "Last error: cloudflare challenge") {
+		t.Fatalf("unexpected note text %q", notes.written["walled"])
+	}
+	if len(notes.cleared) != 1 || notes.cleared[0] != "healthy" {
+		t.Fatalf("expected only the healthy source to be cleared, got %v", notes.cleared)
+	}
+}
+
+func TestPollerRunOnce_LeavesManualSourceNotesAlone(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "poller.sqlite"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	_, currentFile, _, _ := runtime.Caller(0)
+	if err := database.ApplyMigrations(db, filepath.Join(filepath.Dir(currentFile), "..", "..", "migrations")); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	if err := database.SeedDefaults(db); err != nil {
+		t.Fatalf("seed defaults: %v", err)
+	}
+	for _, slug := range []string{"one", "two", "three"} {
+		if _, err := db.Exec(`
+			INSERT INTO trackers (profile_id, title, source_id, source_url, status)
+			SELECT 1, ?, id, ?, 'reading' FROM sources WHERE key = 'mgeko'
+		`, slug, "https://www.mgeko.cc/manga/"+slug+"/"); err != nil {
+			t.Fatalf("seed tracker: %v", err)
+		}
+	}
+	var mgekoID int64
+	if err := db.QueryRow(`SELECT id FROM sources WHERE key = 'mgeko'`).Scan(&mgekoID); err != nil {
+		t.Fatalf("load source: %v", err)
+	}
+
+	sources := repository.NewSourceRepository(db)
+	connector := &linkedSourceConnector{key: "mgeko", err: errors.New("unexpected status: 503")}
+	registry := connectors.NewRegistry()
+	if err := registry.Register(connector); err != nil {
+		t.Fatalf("register connector: %v", err)
+	}
+	poller := NewPoller(repository.NewTrackerRepository(db), registry, PollerConfig{Interval: time.Minute, SourceNotes: sources}, nil)
+	noteOf := func() (string, string) {
+		t.Helper()
+		source, err := sources.GetByID(context.Background(), mgekoID)
+		if err != nil || source == nil {
+			t.Fatalf("load source: %v", err)
+		}
+		return source.StatusNote, source.NoteSource
+	}
+
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	if note, from := noteOf(); !strings.HasPrefix(note, "3 of 3 update checks failed") || from != repository.NoteSourceAuto {
+		t.Fatalf("expected an auto note after a failing run, got %q from %q", note, from)
+	}
+
+	if _, err := sources.SetStatusNote(context.Background(), mgekoID, "Moved to mgeko.cc, links being migrated"); err != nil {
+		t.Fatalf("set manual note: %v", err)
+	}
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	connector.err = nil
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	if note, from := noteOf(); note != "Moved to mgeko.cc, links being migrated" || from != repository.NoteSourceManual {
+		t.Fatalf("expected manual note to survive failing and clean runs, got %q from %q", note, from)
+	}
+
+	if _, err := sources.SetStatusNote(context.Background(), mgekoID, ""); err != nil {
+		t.Fatalf("clear manual note: %v", err)
+	}
+	connector.err = errors.New("unexpected status: 503")
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	connector.err = nil
+	if err := poller.RunOnce(context.Background()); err != nil {
+		t.Fatalf("run once failed: %v", err)
+	}
+	if note, from := noteOf(); note != "" || from != "" {
+		t.Fatalf("expected auto note to be cleared after a clean run, got %q from %q", note, from)
+	}
+}
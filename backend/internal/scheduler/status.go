@@ -0,0 +1,98 @@
+package scheduler
+
+import "time"
+
+// Status is a point-in-time view of the poller for the dashboard: the cycle
+// in progress, if any, and a summary of the last finished one.
+type Status struct {
+	Running   bool       `json:"running"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// Processed counts the trackers of the running cycle handled so far, out
+	// of Total due this cycle.
+	Processed int `json:"processed"`
+	Total     int `json:"total"`
+	// CurrentSourceKey is the source being contacted right now.
+	CurrentSourceKey string `json:"currentSourceKey,omitempty"`
+	// PollDelayMS is the pacer's current wait between trackers, which grows
+	// while the host is busy; 0 without pacing.
+	PollDelayMS int64       `json:"pollDelayMs"`
+	LastRun     *RunSummary `json:"lastRun,omitempty"`
+	// Inactive is set while nobody has used the dashboard or API for long
+	// enough that the poller slowed down. IntervalSeconds is the time
+	// between cycles then in effect; 0 means cycles wait for the next
+	// request. LastActivityAt is the last recorded request.
+	Inactive        bool       `json:"inactive"`
+	IntervalSeconds int64      `json:"intervalSeconds"`
+	LastActivityAt  *time.Time `json:"lastActivityAt,omitempty"`
+	// Generation counts the finished cycles, so a cache of what a poll can
+	// change knows to drop its entries once another cycle ends.
+	Generation uint64 `json:"-"`
+}
+
+// RunSummary describes a finished poll cycle. NewChapters counts trackers
+// whose latest chapter went up. Panics counts the resolves a connector
+// panicked in; each also counts as a failed check of its tracker.
+type RunSummary struct {
+	StartedAt   time.Time `json:"startedAt"`
+	FinishedAt  time.Time `json:"finishedAt"`
+	Processed   int       `json:"processed"`
+	Total       int       `json:"total"`
+	NewChapters int       `json:"newChapters"`
+	Panics      int       `json:"panics"`
+}
+
+// Status returns the latest snapshot with the cadence in effect now; it is
+// safe to call while a cycle runs.
+func (p *Poller) Status() Status {
+	var status Status
+	if snapshot := p.status.Load(); snapshot != nil {
+		status = *snapshot
+	}
+	cadence := p.cadence()
+	status.Inactive = cadence.inactive
+	status.IntervalSeconds = int64(cadence.interval / time.Second)
+	status.LastActivityAt = cadence.lastActivityAt
+	return status
+}
+
+// cadence is how often the poller runs cycles at a given moment.
+type cadence struct {
+	interval       time.Duration
+	inactive       bool
+	lastActivityAt *time.Time
+}
+
+// cadence stretches the interval by the inactive factor, or to 0, once the
+// last recorded request is older than the inactive period. Without an
+// activity record, or when it cannot be read, the interval stays as set.
+func (p *Poller) cadence() cadence {
+	current := cadence{interval: p.interval}
+	if p.activity == nil {
+		return current
+	}
+	lastActivityAt, ok, err := p.activity.LastActivityAt()
+	if err != nil {
+		p.logger.Warn("read last activity failed", "error", err)
+		return current
+	}
+	if !ok {
+		return current
+	}
+	lastActivityAt = lastActivityAt.UTC()
+	current.lastActivityAt = &lastActivityAt
+	if p.now().Sub(lastActivityAt) < p.inactiveAfter {
+		return current
+	}
+	current.inactive = true
+	current.interval = 0
+	if p.inactiveFactor > 0 {
+		current.interval = p.interval * time.Duration(p.inactiveFactor)
+	}
+	return current
+}
+
+// publishStatus stores a new snapshot. Snapshots are never modified once
+// stored, so readers can keep the one they loaded.
+func (p *Poller) publishStatus(status Status) {
+	p.status.Store(&status)
+}
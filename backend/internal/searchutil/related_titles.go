@@ -0,0 +1,38 @@
+package searchutil
+
+import "unicode/utf8"
+
+const (
+	// MaxRelatedTitles caps how many related titles a tracker stores; some
+	// sources list dozens of alternative titles.
+	MaxRelatedTitles = 15
+	// MaxRelatedTitleLength is the longest related title kept, in characters.
+	MaxRelatedTitleLength = 120
+)
+
+// LimitRelatedTitles is the filter every writer of a tracker's related titles
+// applies: English-alphabet names only, deduplicated by Normalize in their
+// original order, at most MaxRelatedTitles of them and none longer than
+// MaxRelatedTitleLength. It returns how many titles the limits dropped so
+// callers can log the trim instead of failing.
+func LimitRelatedTitles(values []string) ([]string, int) {
+	filtered := FilterEnglishAlphabetNames(values)
+	if len(filtered) == 0 {
+		return nil, 0
+	}
+
+	kept := make([]string, 0, min(len(filtered), MaxRelatedTitles))
+	dropped := 0
+	for _, title := range filtered {
+		if len(kept) == MaxRelatedTitles || utf8.RuneCountInString(title) > MaxRelatedTitleLength {
+			dropped++
+			continue
+		}
+		kept = append(kept, title)
+	}
+
+	if len(kept) == 0 {
+		return nil, dropped
+	}
+	return kept, dropped
+}
@@ -0,0 +1,65 @@
+package searchutil
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func numberedTitles(count int) []string {
+	titles := make([]string, 0, count)
+	for index := 1; index <= count; index++ {
+		titles = append(titles, fmt.Sprintf("Alt Title %d", index))
+	}
+	return titles
+}
+
+func TestLimitRelatedTitles(t *testing.T) {
+	long := strings.Repeat("a", MaxRelatedTitleLength+1)
+	exact := strings.Repeat("b", MaxRelatedTitleLength)
+
+	cases := []struct {
+		name        string
+		values      []string
+		want        []string
+		wantDropped int
+	}{
+		{name: "empty", values: nil, want: nil},
+		{
+			name:   "keeps order and first spelling of duplicates",
+			values: []string{"Solo Leveling", "  solo-leveling ", "Only I Level Up", "SOLO LEVELING"},
+			want:   []string{"Solo Leveling", "Only I Level Up"},
+		},
+		{
+			name:   "drops non-english and blank titles without counting them",
+			values: []string{"나 혼자만 레벨업", " ", "Solo Leveling"},
+			want:   []string{"Solo Leveling"},
+		},
+		{
+			name:        "rejects titles over the length limit",
+			values:      []string{long, exact, "Short"},
+			want:        []string{exact, "Short"},
+			wantDropped: 1,
+		},
+		{
+			name:        "caps the count after deduplicating",
+			values:      append([]string{"Alt Title 1", "alt title 1"}, numberedTitles(20)...),
+			want:        numberedTitles(MaxRelatedTitles),
+			wantDropped: 20 - MaxRelatedTitles,
+		},
+		{
+			name:        "only overlong titles",
+			values:      []string{long},
+			want:        nil,
+			wantDropped: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		got, dropped := LimitRelatedTitles(tc.values)
+		if !reflect.DeepEqual(got, tc.want) || dropped != tc.wantDropped {
+			t.Fatalf("%s: expected %v (dropped %d), got %v (dropped %d)", tc.name, tc.want, tc.wantDropped, got, dropped)
+		}
+	}
+}
@@ -133,6 +133,42 @@ func AnyCandidateMatches(candidates []string, normalizedQuery string, queryToken
 	return false
 }
 
+// TitleSimilarity scores how alike two titles are from 0 to 1, as the Dice
+// coefficient of their normalized word sets.
+func TitleSimilarity(left string, right string) float64 {
+	leftTokens := TokenizeNormalized(Normalize(left))
+	rightTokens := TokenizeNormalized(Normalize(right))
+	if len(leftTokens) == 0 || len(rightTokens) == 0 {
+		return 0
+	}
+
+	seen := make(map[string]struct{}, len(leftTokens))
+	for _, token := range leftTokens {
+		seen[token] = struct{}{}
+	}
+	shared := 0
+	for _, token := range rightTokens {
+		if _, ok := seen[token]; ok {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(leftTokens)+len(rightTokens))
+}
+
+// BestTitleSimilarity returns the highest TitleSimilarity between any title
+// in left and any title in right.
+func BestTitleSimilarity(left []string, right []string) float64 {
+	best := 0.0
+	for _, leftTitle := range left {
+		for _, rightTitle := range right {
+			if score := TitleSimilarity(leftTitle, rightTitle); score > best {
+				best = score
+			}
+		}
+	}
+	return best
+}
+
 func UniqueNonEmpty(values []string) []string {
 	if len(values) == 0 {
 		return nil
@@ -0,0 +1,22 @@
+package searchutil
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeTagName is the form tag names are matched on: lowercased, with
+// accents dropped and runs of whitespace collapsed, so "Café" and "cafe"
+// name the same tag. Punctuation is kept, as a leading dash tells "-stale"
+// apart from the exclusion of "stale".
+func NormalizeTagName(name string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err != nil {
+		folded = name
+	}
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}
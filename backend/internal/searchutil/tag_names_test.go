@@ -0,0 +1,22 @@
+package searchutil
+
+import "testing"
+
+func TestNormalizeTagName(t *testing.T) {
+	cases := map[string]string{
+		"Café":            "cafe",
+		"  CAFÉ  ":        "cafe",
+		"cafe":            "cafe",
+		"Sci-Fi!":         "sci-fi!",
+		"Ŝčî-fï!":         "sci-fi!",
+		"-Stale":          "-stale",
+		"Slice  of\tLife": "slice of life",
+		"漫画":              "漫画",
+		"":                "",
+	}
+	for name, want := range cases {
+		if got := NormalizeTagName(name); got != want {
+			t.Fatalf("NormalizeTagName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Package selfcheck verifies, before the app starts serving, that the paths
+// it reads at runtime exist relative to the working directory. A wrong
+// working directory otherwise only shows up later, as pages that all fail
+// to render or an opaque migration error.
+package selfcheck
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Paths names what to check; an empty path skips its check.
+type Paths struct {
+	// TemplatesGlob must match at least one template, and the matches must
+	// parse when Run is given a parser.
+	TemplatesGlob string
+	// MigrationsDir must be a directory holding at least one .sql file.
+	MigrationsDir string
+	// AssetsDir must be a directory.
+	AssetsDir string
+	// SQLitePath must be writable: an existing file, or a path whose
+	// nearest existing parent is a writable directory.
+	SQLitePath string
+}
+
+// TemplateParser parses the templates matched by glob.
+type TemplateParser func(glob string) (*template.Template, error)
+
+// Problem is one failed check.
+type Problem struct {
+	Check  string
+	Path   string
+	Detail string
+}
+
+// Report is the outcome of Run. Templates holds the parsed templates when
+// parsing succeeded.
+type Report struct {
+	WorkingDir string
+	Problems   []Problem
+	Templates  *template.Template
+}
+
+// OK reports whether every check passed.
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// String formats the failed checks as a multi-line diagnostic, or "" when
+// every check passed.
+func (r Report) String() string {
+	if r.OK() {
+		return ""
+	}
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "startup self-check failed (working directory %s):\n", r.WorkingDir)
+	for _, problem := range r.Problems {
+		fmt.Fprintf(&builder, "  - %s: %s: %s\n", problem.Check, problem.Path, problem.Detail)
+	}
+	builder.WriteString("Run from the backend directory or fix the paths in the environment; pass --skip-selfcheck to go ahead anyway.\n")
+	return builder.String()
+}
+
+// Run checks paths and, when parse is set, parses the templates eagerly.
+func Run(paths Paths, parse TemplateParser) Report {
+	report := Report{}
+	if wd, err := os.Getwd(); err == nil {
+		report.WorkingDir = wd
+	}
+	fail := func(check string, path string, format string, args ...any) {
+		report.Problems = append(report.Problems, Problem{Check: check, Path: path, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if glob := paths.TemplatesGlob; glob != "" {
+		matches, err := filepath.Glob(glob)
+		switch {
+		case err != nil:
+			fail("templates", glob, "invalid pattern: %v", err)
+		case len(matches) == 0:
+			fail("templates", glob, "no templates match")
+		case parse != nil:
+			templates, err := parse(glob)
+			if err != nil {
+				fail("templates", glob, "parse failed: %v", err)
+			} else {
+				report.Templates = templates
+			}
+		}
+	}
+
+	if dir := paths.MigrationsDir; dir != "" {
+		if err := checkDir(dir); err != nil {
+			fail("migrations", dir, "%v", err)
+		} else if matches, _ := filepath.Glob(filepath.Join(dir, "*.sql")); len(matches) == 0 {
+			fail("migrations", dir, "no .sql migrations in the directory")
+		}
+	}
+
+	if dir := paths.AssetsDir; dir != "" {
+		if err := checkDir(dir); err != nil {
+			fail("assets", dir, "%v", err)
+		}
+	}
+
+	if path := paths.SQLitePath; path != "" {
+		if err := checkWritable(path); err != nil {
+			fail("sqlite", path, "%v", err)
+		}
+	}
+
+	return report
+}
+
+func checkDir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.New("not a directory")
+	}
+	return nil
+}
+
+// checkWritable opens an existing database file for writing, or creates
+// and removes a scratch file in the nearest existing parent directory,
+// which database.Open would create the rest of.
+func checkWritable(path string) error {
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return errors.New("is a directory")
+		}
+		file, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			return err
+		}
+		return file.Close()
+	}
+
+	dir := filepath.Dir(path)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("parent %s is not a directory", dir)
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return err
+		}
+		dir = parent
+	}
+
+	scratch, err := os.CreateTemp(dir, ".selfcheck-*")
+	if err != nil {
+		return fmt.Errorf("parent %s is not writable: %w", dir, err)
+	}
+	name := scratch.Name()
+	_ = scratch.Close()
+	return os.Remove(name)
+}
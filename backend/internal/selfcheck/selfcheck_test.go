@@ -0,0 +1,118 @@
+package selfcheck
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeLayout builds a backend-style directory tree under a temp dir and
+// returns the paths to check in it.
+func writeLayout(t *testing.T, files map[string]string) (string, Paths) {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	return root, Paths{
+		TemplatesGlob: filepath.Join(root, "web", "templates", "*.html"),
+		MigrationsDir: filepath.Join(root, "migrations"),
+		AssetsDir:     filepath.Join(root, "web", "assets"),
+		SQLitePath:    filepath.Join(root, "data", "app.sqlite"),
+	}
+}
+
+func parseGlob(glob string) (*template.Template, error) {
+	return template.ParseGlob(glob)
+}
+
+var goodLayout = map[string]string{
+	"web/templates/page.html":     `{{define "page.html"}}ok{{end}}`,
+	"web/assets/dashboard.css":    "body {}",
+	"migrations/0001_initial.sql": "CREATE TABLE t (id INTEGER);",
+}
+
+func TestRunPassesOnAGoodLayout(t *testing.T) {
+	root, paths := writeLayout(t, goodLayout)
+
+	report := Run(paths, parseGlob)
+	if !report.OK() || report.String() != "" {
+		t.Fatalf("expected the layout to pass, got %s", report)
+	}
+	if report.Templates == nil || report.Templates.Lookup("page.html") == nil {
+		t.Fatalf("expected the parsed templates to be handed back")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(root, "data", ".selfcheck-*")); len(matches) != 0 {
+		t.Fatalf("expected the writability probe to clean up, found %v", matches)
+	}
+	if _, err := os.Stat(filepath.Join(root, "data")); err == nil {
+		t.Fatalf("expected the check not to create the sqlite directory")
+	}
+}
+
+func TestRunReportsEachBrokenPath(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		change func(t *testing.T, root string, paths *Paths)
+		check  string
+	}{
+		{name: "no templates", check: "templates", change: func(t *testing.T, root string, paths *Paths) {
+			paths.TemplatesGlob = filepath.Join(root, "templates", "*.html")
+		}},
+		{name: "template does not parse", check: "templates", change: func(t *testing.T, root string, paths *Paths) {
+			writeFile(t, filepath.Join(root, "web", "templates", "broken.html"), `{{define "broken"}}{{if}}`)
+		}},
+		{name: "missing migrations", check: "migrations", change: func(t *testing.T, root string, paths *Paths) {
+			paths.MigrationsDir = filepath.Join(root, "backend", "migrations")
+		}},
+		{name: "empty migrations", check: "migrations", change: func(t *testing.T, root string, paths *Paths) {
+			if err := os.Remove(filepath.Join(root, "migrations", "0001_initial.sql")); err != nil {
+				t.Fatalf("remove migration: %v", err)
+			}
+		}},
+		{name: "assets is a file", check: "assets", change: func(t *testing.T, root string, paths *Paths) {
+			paths.AssetsDir = filepath.Join(root, "web", "assets", "dashboard.css")
+		}},
+		{name: "sqlite parent is a file", check: "sqlite", change: func(t *testing.T, root string, paths *Paths) {
+			paths.SQLitePath = filepath.Join(root, "web", "assets", "dashboard.css", "app.sqlite")
+		}},
+		{name: "sqlite path is a directory", check: "sqlite", change: func(t *testing.T, root string, paths *Paths) {
+			paths.SQLitePath = filepath.Join(root, "migrations")
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			root, paths := writeLayout(t, goodLayout)
+			tc.change(t, root, &paths)
+
+			report := Run(paths, parseGlob)
+			if len(report.Problems) != 1 || report.Problems[0].Check != tc.check {
+				t.Fatalf("expected one %s problem, got %+v", tc.check, report.Problems)
+			}
+			diagnostic := report.String()
+			if !strings.Contains(diagnostic, "startup self-check failed") || !strings.Contains(diagnostic, "  - "+tc.check+": ") || !strings.Contains(diagnostic, "--skip-selfcheck") {
+				t.Fatalf("expected a diagnostic naming the %s check, got %s", tc.check, diagnostic)
+			}
+		})
+	}
+}
+
+func TestRunSkipsEmptyPaths(t *testing.T) {
+	if report := Run(Paths{}, parseGlob); !report.OK() {
+		t.Fatalf("expected no checks to run, got %s", report)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,200 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// CadenceMonths is how many calendar months, up to and including the
+	// current one, release buckets cover.
+	CadenceMonths = 12
+	// CadenceWindow is the trailing window a cadence's average and trend
+	// look at. The trend compares it with the window before it.
+	CadenceWindow = 90 * 24 * time.Hour
+	// MinTrendChapters is the fewest chapters, across both trend windows, a
+	// trend is judged from.
+	MinTrendChapters = 3
+)
+
+// Release is one recorded chapter release of a series. Chapter is the
+// number the release brought the series to; a jump of several chapters
+// between two releases counts as that many chapters released at once.
+type Release struct {
+	At      time.Time
+	Chapter float64
+}
+
+// ReleaseMonth totals the chapters released in one calendar month in UTC.
+type ReleaseMonth struct {
+	Start    time.Time
+	Chapters int
+}
+
+// Trend is which way a series' release pace is heading.
+type Trend string
+
+const (
+	TrendUnknown     Trend = "unknown"
+	TrendSpeedingUp  Trend = "speeding_up"
+	TrendSteady      Trend = "steady"
+	TrendSlowingDown Trend = "slowing_down"
+)
+
+// ReleaseGap is the time between two releases, or from the latest release
+// to now when Ongoing.
+type ReleaseGap struct {
+	From    time.Time
+	To      time.Time
+	Ongoing bool
+}
+
+// Days is the gap's length in days.
+func (g ReleaseGap) Days() float64 {
+	return g.To.Sub(g.From).Hours() / 24
+}
+
+// Cadence sums up a series' release pace. AverageDaysBetween is only
+// meaningful when AverageKnown, and LongestGap only when HasGap.
+type Cadence struct {
+	AverageDaysBetween float64
+	AverageKnown       bool
+	LongestGap         ReleaseGap
+	HasGap             bool
+	Trend              Trend
+}
+
+// BucketReleasesByMonth totals chapters released per month over the
+// CadenceMonths months ending with now's month, oldest first. Months
+// without releases are kept with zero totals so hiatuses show up.
+func BucketReleasesByMonth(releases []Release, now time.Time) []ReleaseMonth {
+	current := monthStart(now)
+	first := current.AddDate(0, 1-CadenceMonths, 0)
+	months := make([]ReleaseMonth, 0, CadenceMonths)
+	for start := first; !start.After(current); start = start.AddDate(0, 1, 0) {
+		months = append(months, ReleaseMonth{Start: start})
+	}
+
+	for _, release := range countReleases(releases) {
+		start := monthStart(release.at)
+		if start.Before(first) || start.After(current) {
+			continue
+		}
+		index := (start.Year()-first.Year())*12 + int(start.Month()) - int(first.Month())
+		months[index].Chapters += release.chapters
+	}
+	return months
+}
+
+// ReleaseCadence works out a series' pace as of now:
+//   - the average days per chapter over gaps ending in the trailing
+//     CadenceWindow, a gap's length shared among the chapters it brought;
+//   - the longest gap in the last CadenceMonths months, counting the time
+//     since the latest release as an ongoing gap;
+//   - the trend, from the chapters of the trailing window against the
+//     window before it. It is unknown until the history reaches back over
+//     both windows and they hold MinTrendChapters chapters between them.
+func ReleaseCadence(releases []Release, now time.Time) Cadence {
+	counted := countReleases(releases)
+	cadence := Cadence{Trend: TrendUnknown}
+	if len(counted) == 0 {
+		return cadence
+	}
+
+	windowStart := now.Add(-CadenceWindow)
+	var span time.Duration
+	chapters := 0
+	for index := 1; index < len(counted); index++ {
+		if counted[index].at.After(windowStart) && !counted[index].at.After(now) {
+			span += counted[index].at.Sub(counted[index-1].at)
+			chapters += counted[index].chapters
+		}
+	}
+	if chapters > 0 && span > 0 {
+		cadence.AverageDaysBetween = span.Hours() / 24 / float64(chapters)
+		cadence.AverageKnown = true
+	}
+
+	gapsSince := now.AddDate(0, -CadenceMonths, 0)
+	consider := func(gap ReleaseGap) {
+		if gap.To.After(gapsSince) && gap.To.After(gap.From) && (!cadence.HasGap || gap.Days() > cadence.LongestGap.Days()) {
+			cadence.LongestGap = gap
+			cadence.HasGap = true
+		}
+	}
+	for index := 1; index < len(counted); index++ {
+		consider(ReleaseGap{From: counted[index-1].at, To: counted[index].at})
+	}
+	if latest := counted[len(counted)-1].at; now.After(latest) {
+		consider(ReleaseGap{From: latest, To: now, Ongoing: true})
+	}
+
+	earlierStart := windowStart.Add(-CadenceWindow)
+	if counted[0].at.After(earlierStart) {
+		return cadence
+	}
+	recent, earlier := 0, 0
+	for _, release := range counted {
+		switch {
+		case release.at.After(windowStart) && !release.at.After(now):
+			recent += release.chapters
+		case release.at.After(earlierStart) && !release.at.After(windowStart):
+			earlier += release.chapters
+		}
+	}
+	cadence.Trend = releaseTrend(recent, earlier)
+	return cadence
+}
+
+// releaseTrend calls a change of more than a quarter either way a speed-up
+// or a slowdown.
+func releaseTrend(recent int, earlier int) Trend {
+	switch {
+	case recent+earlier < MinTrendChapters:
+		return TrendUnknown
+	case float64(recent) > float64(earlier)*1.25:
+		return TrendSpeedingUp
+	case float64(recent) < float64(earlier)*0.75:
+		return TrendSlowingDown
+	default:
+		return TrendSteady
+	}
+}
+
+type countedRelease struct {
+	at       time.Time
+	chapters int
+}
+
+// countReleases sorts releases and works out how many chapters each one
+// brought: the first counts one, later ones the rounded rise over the
+// highest chapter before them, at least one. A release that does not rise
+// past an earlier chapter, such as a corrected number, counts none.
+func countReleases(releases []Release) []countedRelease {
+	sorted := append([]Release(nil), releases...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].At.Before(sorted[j].At)
+	})
+
+	counted := make([]countedRelease, 0, len(sorted))
+	highest := math.Inf(-1)
+	for _, release := range sorted {
+		chapters := 1
+		if len(counted) > 0 {
+			rise := release.Chapter - highest
+			if rise <= 0 {
+				continue
+			}
+			chapters = max(1, int(math.Round(rise)))
+		}
+		highest = max(highest, release.Chapter)
+		counted = append(counted, countedRelease{at: release.At.UTC(), chapters: chapters})
+	}
+	return counted
+}
+
+func monthStart(at time.Time) time.Time {
+	at = at.UTC()
+	return time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,153 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var cadenceNow = time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+// releaseEvery releases count times, every days apart from start, rising
+// step chapters each time from after chapter.
+func releaseEvery(start time.Time, days int, count int, chapter float64, step float64) []Release {
+	releases := make([]Release, 0, count)
+	for index := range count {
+		chapter += step
+		releases = append(releases, Release{At: start.AddDate(0, 0, index*days), Chapter: chapter})
+	}
+	return releases
+}
+
+func monthTotals(months []ReleaseMonth) []int {
+	totals := make([]int, 0, len(months))
+	for _, month := range months {
+		totals = append(totals, month.Chapters)
+	}
+	return totals
+}
+
+func TestReleaseCadenceOfASteadySeries(t *testing.T) {
+	// A chapter every Thursday for a year, the latest this morning.
+	releases := releaseEvery(time.Date(2025, 10, 23, 9, 0, 0, 0, time.UTC), 7, 52, 0, 1)
+
+	months := BucketReleasesByMonth(releases, cadenceNow)
+	if len(months) != CadenceMonths || !months[0].Start.Equal(time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected twelve months from November, got %+v", months)
+	}
+	for _, month := range months[:len(months)-1] {
+		if month.Chapters < 4 || month.Chapters > 5 {
+			t.Fatalf("expected four or five chapters a month, got %v", monthTotals(months))
+		}
+	}
+
+	cadence := ReleaseCadence(releases, cadenceNow)
+	if !cadence.AverageKnown || cadence.AverageDaysBetween != 7 {
+		t.Fatalf("expected a chapter every 7 days, got %+v", cadence)
+	}
+	if !cadence.HasGap || cadence.LongestGap.Days() != 7 || cadence.LongestGap.Ongoing {
+		t.Fatalf("expected a longest gap of a week, got %+v", cadence.LongestGap)
+	}
+	if cadence.Trend != TrendSteady {
+		t.Fatalf("expected a steady trend, got %s", cadence.Trend)
+	}
+}
+
+func TestReleaseCadenceOfABurstySeries(t *testing.T) {
+	// Four chapters dropped at once every four weeks.
+	releases := releaseEvery(time.Date(2025, 9, 24, 9, 0, 0, 0, time.UTC), 28, 14, 100, 4)
+
+	months := BucketReleasesByMonth(releases, cadenceNow)
+	total := 0
+	for _, month := range months {
+		if month.Chapters%4 != 0 {
+			t.Fatalf("expected whole drops of four chapters, got %v", monthTotals(months))
+		}
+		total += month.Chapters
+	}
+	if total != 48 {
+		t.Fatalf("expected twelve drops in the last twelve months, got %v", monthTotals(months))
+	}
+
+	cadence := ReleaseCadence(releases, cadenceNow)
+	if !cadence.AverageKnown || cadence.AverageDaysBetween != 7 {
+		t.Fatalf("expected four chapters every 28 days to average 7 days, got %+v", cadence)
+	}
+	if cadence.LongestGap.Days() != 28 {
+		t.Fatalf("expected the longest gap between drops, got %+v", cadence.LongestGap)
+	}
+	if cadence.Trend != TrendSteady {
+		t.Fatalf("expected a steady trend, got %s", cadence.Trend)
+	}
+}
+
+func TestReleaseCadenceAcrossAHiatus(t *testing.T) {
+	before := releaseEvery(time.Date(2025, 10, 2, 9, 0, 0, 0, time.UTC), 7, 18, 0, 1)
+	hiatusStart := before[len(before)-1].At
+	returned := time.Date(2026, 8, 20, 9, 0, 0, 0, time.UTC)
+	after := releaseEvery(returned, 7, 8, 18, 1)
+	releases := append(append([]Release(nil), before...), after...)
+
+	// Mid-hiatus, the series is slowing down and the gap is still open.
+	midHiatus := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cadence := ReleaseCadence(before, midHiatus)
+	if cadence.Trend != TrendSlowingDown {
+		t.Fatalf("expected a slowdown during the hiatus, got %s", cadence.Trend)
+	}
+	if cadence.AverageKnown {
+		t.Fatalf("expected no average without a release in the last 90 days, got %+v", cadence)
+	}
+	if !cadence.LongestGap.Ongoing || !cadence.LongestGap.From.Equal(hiatusStart) || !cadence.LongestGap.To.Equal(midHiatus) {
+		t.Fatalf("expected the ongoing hiatus as the longest gap, got %+v", cadence.LongestGap)
+	}
+
+	months := BucketReleasesByMonth(releases, cadenceNow)
+	want := []int{4, 4, 5, 0, 0, 0, 0, 0, 0, 2, 4, 2}
+	for index, month := range months {
+		if month.Chapters != want[index] {
+			t.Fatalf("expected %v, got %v", want, monthTotals(months))
+		}
+	}
+
+	cadence = ReleaseCadence(releases, cadenceNow)
+	if cadence.Trend != TrendSpeedingUp {
+		t.Fatalf("expected a speed-up after the return, got %s", cadence.Trend)
+	}
+	if cadence.LongestGap.Ongoing || !cadence.LongestGap.From.Equal(hiatusStart) || !cadence.LongestGap.To.Equal(returned) {
+		t.Fatalf("expected the closed hiatus as the longest gap, got %+v", cadence.LongestGap)
+	}
+	// The hiatus ends inside the window, so it weighs on the average.
+	wantAverage := (returned.Sub(hiatusStart).Hours()/24 + 7*7) / 8
+	if !cadence.AverageKnown || math.Abs(cadence.AverageDaysBetween-wantAverage) > 1e-9 {
+		t.Fatalf("expected an average of %.2f days, got %+v", wantAverage, cadence)
+	}
+}
+
+func TestReleaseCadenceOfAShortHistory(t *testing.T) {
+	releases := releaseEvery(cadenceNow.AddDate(0, 0, -22), 7, 3, 40, 1)
+	// A corrected chapter number is not a release.
+	releases = append(releases, Release{At: cadenceNow.AddDate(0, 0, -1), Chapter: 41.5})
+
+	cadence := ReleaseCadence(releases, cadenceNow)
+	if cadence.Trend != TrendUnknown {
+		t.Fatalf("expected an unknown trend for three weeks of history, got %s", cadence.Trend)
+	}
+	if !cadence.AverageKnown || cadence.AverageDaysBetween != 7 {
+		t.Fatalf("expected a chapter every 7 days, got %+v", cadence)
+	}
+	if !cadence.LongestGap.Ongoing || cadence.LongestGap.Days() != 8 {
+		t.Fatalf("expected the eight days since the latest chapter as the longest gap, got %+v", cadence.LongestGap)
+	}
+}
+
+func TestReleaseCadenceWithoutReleases(t *testing.T) {
+	cadence := ReleaseCadence(nil, cadenceNow)
+	if cadence.AverageKnown || cadence.HasGap || cadence.Trend != TrendUnknown {
+		t.Fatalf("expected nothing known, got %+v", cadence)
+	}
+	for _, month := range BucketReleasesByMonth(nil, cadenceNow) {
+		if month.Chapters != 0 {
+			t.Fatalf("expected empty months, got %+v", month)
+		}
+	}
+}
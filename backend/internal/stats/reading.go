@@ -0,0 +1,52 @@
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+// Read is one advance of a tracker's last read chapter.
+type Read struct {
+	At       time.Time
+	Chapters float64
+}
+
+// ReadingWeek totals the reads of one week, which starts on Monday in UTC.
+type ReadingWeek struct {
+	Start    time.Time
+	Chapters float64
+	Reads    int
+}
+
+// BucketReadsByWeek totals reads per week, from the week of the earliest
+// read to the week of the latest, oldest first. Weeks without reads in
+// between are kept with zero totals so gaps show up in a timeline.
+func BucketReadsByWeek(reads []Read) []ReadingWeek {
+	if len(reads) == 0 {
+		return nil
+	}
+
+	sorted := append([]Read(nil), reads...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].At.Before(sorted[j].At)
+	})
+
+	first := weekStart(sorted[0].At)
+	last := weekStart(sorted[len(sorted)-1].At)
+	weeks := make([]ReadingWeek, 0, int(last.Sub(first).Hours()/(24*7))+1)
+	for start := first; !start.After(last); start = start.AddDate(0, 0, 7) {
+		weeks = append(weeks, ReadingWeek{Start: start})
+	}
+	for _, read := range sorted {
+		index := int(weekStart(read.At).Sub(first).Hours() / (24 * 7))
+		weeks[index].Chapters += read.Chapters
+		weeks[index].Reads++
+	}
+	return weeks
+}
+
+func weekStart(at time.Time) time.Time {
+	at = at.UTC()
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
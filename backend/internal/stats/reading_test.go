@@ -0,0 +1,36 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketReadsByWeekKeepsEmptyWeeks(t *testing.T) {
+	// 2026-03-02 is a Monday.
+	reads := []Read{
+		{At: time.Date(2026, 3, 18, 9, 0, 0, 0, time.UTC), Chapters: 4},
+		{At: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Chapters: 1},
+		{At: time.Date(2026, 3, 8, 23, 59, 0, 0, time.UTC), Chapters: 2},
+	}
+
+	weeks := BucketReadsByWeek(reads)
+	if len(weeks) != 3 {
+		t.Fatalf("expected 3 weeks, got %+v", weeks)
+	}
+	want := []ReadingWeek{
+		{Start: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Chapters: 3, Reads: 2},
+		{Start: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), Chapters: 4, Reads: 1},
+	}
+	for index, week := range weeks {
+		if !week.Start.Equal(want[index].Start) || week.Chapters != want[index].Chapters || week.Reads != want[index].Reads {
+			t.Fatalf("week %d: expected %+v, got %+v", index, want[index], week)
+		}
+	}
+}
+
+func TestBucketReadsByWeekWithoutReads(t *testing.T) {
+	if weeks := BucketReadsByWeek(nil); len(weeks) != 0 {
+		t.Fatalf("expected no weeks, got %+v", weeks)
+	}
+}
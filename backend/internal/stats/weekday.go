@@ -0,0 +1,90 @@
+// Package stats derives patterns, such as a series' usual release day or a
+// tracker's weekly reading pace, from the history the app records.
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// ReleaseWeekdayWindow is how many of the most recent releases a weekday
+	// prediction looks at.
+	ReleaseWeekdayWindow = 10
+	// MinReleaseWeekdaySamples is the fewest releases a weekday is predicted
+	// from; series with fewer are unknown.
+	MinReleaseWeekdaySamples = 3
+)
+
+// WeekdayPrediction is the weekday a series usually releases on. Known is
+// false when there are too few releases or no single weekday stands out;
+// Weekday is then meaningless. Confidence is the share of the sampled
+// releases that fell on Weekday.
+type WeekdayPrediction struct {
+	Weekday    time.Weekday
+	Known      bool
+	Confidence float64
+	Samples    int
+}
+
+// PredictReleaseWeekday takes the mode of the UTC weekdays of the latest
+// ReleaseWeekdayWindow releases. Identical timestamps count once, and a tie
+// between weekdays leaves the prediction unknown.
+func PredictReleaseWeekday(releases []time.Time) WeekdayPrediction {
+	return predictReleaseWeekday(releases, ReleaseWeekdayWindow, MinReleaseWeekdaySamples)
+}
+
+func predictReleaseWeekday(releases []time.Time, window int, minSamples int) WeekdayPrediction {
+	latest := dedupeLatestFirst(releases)
+	if len(latest) > window {
+		latest = latest[:window]
+	}
+
+	prediction := WeekdayPrediction{Samples: len(latest)}
+	if len(latest) < minSamples || len(latest) == 0 {
+		return prediction
+	}
+
+	var counts [7]int
+	for _, releasedAt := range latest {
+		counts[releasedAt.UTC().Weekday()]++
+	}
+	best, tied := time.Sunday, false
+	for weekday := time.Monday; weekday <= time.Saturday; weekday++ {
+		switch {
+		case counts[weekday] > counts[best]:
+			best, tied = weekday, false
+		case counts[weekday] == counts[best]:
+			tied = true
+		}
+	}
+	if tied {
+		return prediction
+	}
+
+	prediction.Weekday = best
+	prediction.Known = true
+	prediction.Confidence = float64(counts[best]) / float64(len(latest))
+	return prediction
+}
+
+// dedupeLatestFirst returns the non-zero releases newest first, each
+// instant once.
+func dedupeLatestFirst(releases []time.Time) []time.Time {
+	sorted := make([]time.Time, 0, len(releases))
+	for _, releasedAt := range releases {
+		if !releasedAt.IsZero() {
+			sorted = append(sorted, releasedAt)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].After(sorted[j]) })
+
+	unique := sorted[:0]
+	for _, releasedAt := range sorted {
+		if len(unique) > 0 && unique[len(unique)-1].Equal(releasedAt) {
+			continue
+		}
+		unique = append(unique, releasedAt)
+	}
+	return unique
+}
@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+// weeklyOn returns count releases on the given weekday, a week apart,
+// the newest in the week of 2026-03-02 (a Monday).
+func weeklyOn(weekday time.Weekday, count int) []time.Time {
+	monday := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	newest := monday.AddDate(0, 0, (int(weekday)+6)%7)
+	releases := make([]time.Time, 0, count)
+	for index := 0; index < count; index++ {
+		releases = append(releases, newest.AddDate(0, 0, -7*index))
+	}
+	return releases
+}
+
+func TestPredictReleaseWeekdayTakesTheMode(t *testing.T) {
+	releases := append(weeklyOn(time.Wednesday, 4), weeklyOn(time.Friday, 1)...)
+
+	prediction := PredictReleaseWeekday(releases)
+	if !prediction.Known || prediction.Weekday != time.Wednesday {
+		t.Fatalf("expected wednesday, got %+v", prediction)
+	}
+	if prediction.Samples != 5 || prediction.Confidence != 0.8 {
+		t.Fatalf("expected 4 of 5 samples, got %+v", prediction)
+	}
+}
+
+func TestPredictReleaseWeekdayNeedsMinimumSamples(t *testing.T) {
+	for _, releases := range [][]time.Time{nil, weeklyOn(time.Monday, 1), weeklyOn(time.Monday, 2)} {
+		if prediction := PredictReleaseWeekday(releases); prediction.Known {
+			t.Fatalf("expected %d releases to stay unknown, got %+v", len(releases), prediction)
+		}
+	}
+	if prediction := PredictReleaseWeekday(weeklyOn(time.Monday, 3)); !prediction.Known || prediction.Weekday != time.Monday {
+		t.Fatalf("expected three mondays to be enough, got %+v", prediction)
+	}
+}
+
+func TestPredictReleaseWeekdayCountsDuplicatesOnce(t *testing.T) {
+	release := weeklyOn(time.Sunday, 1)[0]
+	prediction := PredictReleaseWeekday([]time.Time{release, release, release})
+	if prediction.Known || prediction.Samples != 1 {
+		t.Fatalf("expected one sample from repeated timestamps, got %+v", prediction)
+	}
+}
+
+func TestPredictReleaseWeekdayTieIsUnknown(t *testing.T) {
+	releases := append(weeklyOn(time.Tuesday, 2), weeklyOn(time.Thursday, 2)...)
+	if prediction := PredictReleaseWeekday(releases); prediction.Known || prediction.Samples != 4 {
+		t.Fatalf("expected a tie to be irregular, got %+v", prediction)
+	}
+}
+
+func TestPredictReleaseWeekdayLooksAtRecentReleasesOnly(t *testing.T) {
+	// The series moved from Saturdays to Mondays; the old Saturday releases
+	// fall outside the window.
+	oldSaturdays := weeklyOn(time.Saturday, 8)
+	for index := range oldSaturdays {
+		oldSaturdays[index] = oldSaturdays[index].AddDate(-1, 0, 0)
+	}
+	releases := append(oldSaturdays, weeklyOn(time.Monday, 6)...)
+
+	prediction := predictReleaseWeekday(releases, 6, 3)
+	if !prediction.Known || prediction.Weekday != time.Monday || prediction.Confidence != 1 {
+		t.Fatalf("expected the recent mondays to win, got %+v", prediction)
+	}
+}
+
+func TestPredictReleaseWeekdayUsesUTC(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	// 02:00 on a Tuesday in Tokyo is 17:00 on Monday in UTC.
+	releases := []time.Time{
+		time.Date(2026, 3, 3, 2, 0, 0, 0, tokyo),
+		time.Date(2026, 3, 10, 2, 0, 0, 0, tokyo),
+		time.Date(2026, 3, 17, 2, 0, 0, 0, tokyo),
+	}
+	if prediction := PredictReleaseWeekday(releases); !prediction.Known || prediction.Weekday != time.Monday {
+		t.Fatalf("expected the UTC weekday, got %+v", prediction)
+	}
+}
@@ -0,0 +1,108 @@
+package thumbnails
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+)
+
+// DiskStore keeps thumbnails as files in a directory, named by a hash of
+// their key.
+type DiskStore struct {
+	dir string
+}
+
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if dir == "" {
+		return nil, errors.New("missing thumbnail directory")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create thumbnail directory: %w", err)
+	}
+	return &DiskStore{dir: dir}, nil
+}
+
+func (s *DiskStore) path(key Key) string {
+	sum := sha256.Sum256([]byte(key.SourceKey + "|" + key.ItemID))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+".jpg")
+}
+
+func (s *DiskStore) Stat(key Key) (time.Time, bool, error) {
+	info, err := os.Stat(s.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("stat thumbnail: %w", err)
+	}
+	return info.ModTime().UTC().Truncate(time.Second), true, nil
+}
+
+func (s *DiskStore) Load(key Key) (Thumbnail, bool, error) {
+	updatedAt, ok, err := s.Stat(key)
+	if err != nil || !ok {
+		return Thumbnail{}, false, err
+	}
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Thumbnail{}, false, nil
+		}
+		return Thumbnail{}, false, fmt.Errorf("read thumbnail: %w", err)
+	}
+	return Thumbnail{Data: data, ContentType: ContentType, UpdatedAt: updatedAt}, true, nil
+}
+
+// Save writes through a temporary file so readers never see a partial image.
+func (s *DiskStore) Save(key Key, data []byte, contentType string) error {
+	tmp, err := os.CreateTemp(s.dir, "thumb-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create thumbnail file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write thumbnail: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write thumbnail: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("save thumbnail: %w", err)
+	}
+	return nil
+}
+
+// DBStore keeps thumbnails in the cover_thumbnails table.
+type DBStore struct {
+	repo *repository.CoverThumbnailRepository
+}
+
+func NewDBStore(repo *repository.CoverThumbnailRepository) *DBStore {
+	return &DBStore{repo: repo}
+}
+
+func (s *DBStore) Stat(key Key) (time.Time, bool, error) {
+	return s.repo.UpdatedAt(key.SourceKey, key.ItemID)
+}
+
+func (s *DBStore) Load(key Key) (Thumbnail, bool, error) {
+	data, contentType, updatedAt, ok, err := s.repo.Get(key.SourceKey, key.ItemID)
+	if err != nil || !ok {
+		return Thumbnail{}, false, err
+	}
+	return Thumbnail{Data: data, ContentType: contentType, UpdatedAt: updatedAt}, true, nil
+}
+
+func (s *DBStore) Save(key Key, data []byte, contentType string) error {
+	return s.repo.Put(key.SourceKey, key.ItemID, data, contentType)
+}
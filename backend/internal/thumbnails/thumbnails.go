@@ -0,0 +1,229 @@
+// Package thumbnails downloads tracker covers, shrinks them into small JPEG
+// thumbnails and keeps them in a Store, either a directory on disk or the
+// cover_thumbnails table. The grid view serves the stored thumbnails instead
+// of hotlinking full-size covers.
+package thumbnails
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gabriel/cross-site-tracker/backend/internal/connectors"
+	"github.com/gabriel/cross-site-tracker/backend/internal/repository"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// MaxWidth and MaxHeight bound a thumbnail's size in pixels; covers are
+	// scaled down to fit and never scaled up.
+	MaxWidth  = 240
+	MaxHeight = 360
+	// MaxBytes is the largest encoded thumbnail that is stored.
+	MaxBytes = 40 * 1024
+
+	ContentType = "image/jpeg"
+
+	// Storage modes accepted by Open.
+	StorageOff  = "off"
+	StorageDisk = "disk"
+	StorageDB   = "db"
+
+	maxCoverBytes  = 8 << 20
+	maxCoverPixels = 40_000_000
+	minDimension   = 16
+)
+
+var qualitySteps = []int{82, 70, 58, 46}
+
+// Key identifies a series' thumbnail: the source key plus the source item id,
+// or the series URL when the item id is unknown.
+type Key struct {
+	SourceKey string
+	ItemID    string
+}
+
+// KeyFor builds the Key of a tracker's cover.
+func KeyFor(sourceKey, sourceURL string, sourceItemID *string) Key {
+	itemID := ""
+	if sourceItemID != nil {
+		itemID = strings.TrimSpace(*sourceItemID)
+	}
+	if itemID == "" {
+		itemID = "url:" + strings.TrimSpace(sourceURL)
+	}
+	return Key{SourceKey: strings.ToLower(strings.TrimSpace(sourceKey)), ItemID: strings.ToLower(itemID)}
+}
+
+// Thumbnail is a stored thumbnail image.
+type Thumbnail struct {
+	Data        []byte
+	ContentType string
+	UpdatedAt   time.Time
+}
+
+// Store keeps thumbnails. Stat and Load report ok=false when nothing is
+// stored for the key.
+type Store interface {
+	Stat(key Key) (updatedAt time.Time, ok bool, err error)
+	Load(key Key) (thumbnail Thumbnail, ok bool, err error)
+	Save(key Key, data []byte, contentType string) error
+}
+
+// Open returns the Store for a storage mode, or nil when thumbnails are off.
+// dir is only used by disk storage and db only by database storage.
+func Open(mode string, dir string, db *sql.DB) (Store, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", StorageOff:
+		return nil, nil
+	case StorageDisk:
+		return NewDiskStore(dir)
+	case StorageDB:
+		return NewDBStore(repository.NewCoverThumbnailRepository(db)), nil
+	default:
+		return nil, fmt.Errorf("unknown thumbnail storage %q", mode)
+	}
+}
+
+// Make decodes a JPEG, PNG, GIF or WEBP cover and re-encodes it as a JPEG
+// that fits within MaxWidth x MaxHeight and MaxBytes. Transparent areas are
+// filled with white.
+func Make(data []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode cover: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxCoverPixels {
+		return nil, fmt.Errorf("cover has unsupported size %dx%d", config.Width, config.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode cover: %w", err)
+	}
+
+	width, height := fitWithin(src.Bounds().Dx(), src.Bounds().Dy(), MaxWidth, MaxHeight)
+	var encoded bytes.Buffer
+	for {
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+		for _, quality := range qualitySteps {
+			encoded.Reset()
+			if err := jpeg.Encode(&encoded, dst, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, fmt.Errorf("encode thumbnail: %w", err)
+			}
+			if encoded.Len() <= MaxBytes {
+				return bytes.Clone(encoded.Bytes()), nil
+			}
+		}
+
+		if width <= minDimension || height <= minDimension {
+			return nil, errors.New("thumbnail does not fit the size limit")
+		}
+		width, height = max(width*3/4, 1), max(height*3/4, 1)
+	}
+}
+
+// fitWithin scales width x height down, keeping the aspect ratio, until it
+// fits within maxWidth x maxHeight.
+func fitWithin(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+	if width*maxHeight > height*maxWidth {
+		return maxWidth, max(height*maxWidth/width, 1)
+	}
+	return max(width*maxHeight/height, 1), maxHeight
+}
+
+// errUnsafeCoverURL is returned for a cover, or a redirect it leads to,
+// that points at this machine or the local network.
+var errUnsafeCoverURL = errors.New("cover url is not safe to fetch")
+
+// Generator downloads covers and stores their thumbnails.
+type Generator struct {
+	store  Store
+	client *http.Client
+	// urlSafe vets a cover URL and each redirect before it is fetched.
+	urlSafe func(rawURL string) bool
+}
+
+func NewGenerator(store Store, client *http.Client) *Generator {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	g := &Generator{store: store, urlSafe: connectors.MediaURLSafe}
+
+	vetted := *client
+	checkRedirect := client.CheckRedirect
+	vetted.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if !g.urlSafe(req.URL.String()) {
+			return errUnsafeCoverURL
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	g.client = &vetted
+	return g
+}
+
+// Store returns the store thumbnails are saved to.
+func (g *Generator) Store() Store {
+	return g.store
+}
+
+// Generate downloads coverURL, shrinks it and saves it under key.
+func (g *Generator) Generate(ctx context.Context, key Key, coverURL string) error {
+	coverURL = strings.TrimSpace(coverURL)
+	if !g.urlSafe(coverURL) {
+		return errUnsafeCoverURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, coverURL, nil)
+	if err != nil {
+		return fmt.Errorf("build cover request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36")
+	req.Header.Set("Accept", "image/webp,image/jpeg,image/png,image/*;q=0.8")
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("download cover: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("download cover: unexpected status %d", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxCoverBytes+1))
+	if err != nil {
+		return fmt.Errorf("read cover: %w", err)
+	}
+	if len(data) > maxCoverBytes {
+		return errors.New("cover is too large")
+	}
+
+	thumbnail, err := Make(data)
+	if err != nil {
+		return err
+	}
+	return g.store.Save(key, thumbnail, ContentType)
+}
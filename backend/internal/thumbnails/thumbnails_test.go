@@ -0,0 +1,191 @@
+package thumbnails
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fixturePNG draws a noisy image, which compresses badly and so exercises the
+// size limit.
+func fixturePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	seed := uint32(7)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			seed = seed*1664525 + 1013904223
+			img.Set(x, y, color.RGBA{R: uint8(seed >> 24), G: uint8(seed >> 16), B: uint8(x + y), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decodeJPEG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("thumbnail is not a jpeg: %v", err)
+	}
+	return img
+}
+
+func TestMakeFitsSizeBounds(t *testing.T) {
+	cases := []struct {
+		name                   string
+		width, height          int
+		wantWidth, wantHeight  int
+		allowSmallerWhenTooBig bool
+	}{
+		{name: "tall cover", width: 900, height: 1350, wantWidth: 240, wantHeight: 360, allowSmallerWhenTooBig: true},
+		{name: "wide banner", width: 1600, height: 400, wantWidth: 240, wantHeight: 60, allowSmallerWhenTooBig: true},
+		{name: "small cover is not upscaled", width: 80, height: 120, wantWidth: 80, wantHeight: 120},
+	}
+	for _, tc := range cases {
+		thumbnail, err := Make(fixturePNG(t, tc.width, tc.height))
+		if err != nil {
+			t.Fatalf("%s: make thumbnail: %v", tc.name, err)
+		}
+		if len(thumbnail) > MaxBytes {
+			t.Fatalf("%s: expected at most %d bytes, got %d", tc.name, MaxBytes, len(thumbnail))
+		}
+
+		bounds := decodeJPEG(t, thumbnail).Bounds()
+		if bounds.Dx() > MaxWidth || bounds.Dy() > MaxHeight {
+			t.Fatalf("%s: expected thumbnail within %dx%d, got %dx%d", tc.name, MaxWidth, MaxHeight, bounds.Dx(), bounds.Dy())
+		}
+		if bounds.Dx() == tc.wantWidth && bounds.Dy() == tc.wantHeight {
+			continue
+		}
+		// Noisy fixtures may need a further shrink to fit MaxBytes; the
+		// aspect ratio must still hold.
+		expectedHeight := bounds.Dx() * tc.wantHeight / tc.wantWidth
+		if !tc.allowSmallerWhenTooBig || max(expectedHeight-bounds.Dy(), bounds.Dy()-expectedHeight) > 1 {
+			t.Fatalf("%s: expected %dx%d, got %dx%d", tc.name, tc.wantWidth, tc.wantHeight, bounds.Dx(), bounds.Dy())
+		}
+	}
+}
+
+func TestMakeRejectsNonImages(t *testing.T) {
+	if _, err := Make([]byte("<html>not an image</html>")); err == nil {
+		t.Fatalf("expected an error for non-image data")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if store, err := Open("off", "", nil); err != nil || store != nil {
+		t.Fatalf("expected no store when off, got %v %v", store, err)
+	}
+	if _, err := Open("s3", "", nil); err == nil {
+		t.Fatalf("expected an error for an unknown storage mode")
+	}
+	store, err := Open("disk", t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("open disk store: %v", err)
+	}
+	if _, ok := store.(*DiskStore); !ok {
+		t.Fatalf("expected a disk store, got %T", store)
+	}
+}
+
+func TestGenerateStoresThumbnailOnDisk(t *testing.T) {
+	cover := fixturePNG(t, 600, 900)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cover.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(cover)
+	}))
+	defer server.Close()
+
+	store, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("open disk store: %v", err)
+	}
+	generator := NewGenerator(store, server.Client())
+	// The test server is on loopback, which covers may not point at.
+	generator.urlSafe = func(string) bool { return true }
+	itemID := "Series-42"
+	key := KeyFor("AsuraComic", "https://asuracomic.net/series/series-42", &itemID)
+
+	if _, ok, err := store.Stat(key); err != nil || ok {
+		t.Fatalf("expected no thumbnail before generating, got ok=%v err=%v", ok, err)
+	}
+	if err := generator.Generate(context.Background(), key, server.URL+"/cover.png"); err != nil {
+		t.Fatalf("generate thumbnail: %v", err)
+	}
+
+	thumbnail, ok, err := store.Load(key)
+	if err != nil || !ok {
+		t.Fatalf("expected stored thumbnail, got ok=%v err=%v", ok, err)
+	}
+	if thumbnail.ContentType != ContentType || thumbnail.UpdatedAt.IsZero() {
+		t.Fatalf("unexpected thumbnail metadata: %q %v", thumbnail.ContentType, thumbnail.UpdatedAt)
+	}
+	if bounds := decodeJPEG(t, thumbnail.Data).Bounds(); bounds.Dx() > MaxWidth || bounds.Dy() > MaxHeight {
+		t.Fatalf("expected stored thumbnail within bounds, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	if err := generator.Generate(context.Background(), KeyFor("asuracomic", "missing", nil), server.URL+"/missing.png"); err == nil {
+		t.Fatalf("expected an error for a missing cover")
+	}
+}
+
+func TestGenerateRefusesCoversOnTheLocalNetwork(t *testing.T) {
+	fetched := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = true
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	store, err := NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("open disk store: %v", err)
+	}
+	generator := NewGenerator(store, server.Client())
+	for _, coverURL := range []string{server.URL + "/cover.png", "http://169.254.169.254/latest/meta-data/", "file:///etc/passwd"} {
+		if err := generator.Generate(context.Background(), KeyFor("asuracomic", coverURL, nil), coverURL); !errors.Is(err, errUnsafeCoverURL) {
+			t.Fatalf("expected %q to be refused, got %v", coverURL, err)
+		}
+	}
+	if fetched {
+		t.Fatalf("expected no request to reach the local server")
+	}
+
+	// A public cover that redirects onto the local network is refused too.
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL+"/cover.png", http.StatusFound)
+	}))
+	defer redirect.Close()
+	generator = NewGenerator(store, redirect.Client())
+	generator.urlSafe = func(rawURL string) bool { return strings.HasPrefix(rawURL, redirect.URL) }
+	if err := generator.Generate(context.Background(), KeyFor("asuracomic", "redirect", nil), redirect.URL+"/cover.png"); !errors.Is(err, errUnsafeCoverURL) {
+		t.Fatalf("expected the redirect to be refused, got %v", err)
+	}
+	if fetched {
+		t.Fatalf("expected the redirect not to be followed")
+	}
+}
+
+func TestKeyForFallsBackToSourceURL(t *testing.T) {
+	blank := "  "
+	key := KeyFor(" MangaDex ", "https://mangadex.org/title/abc", &blank)
+	if key.SourceKey != "mangadex" || key.ItemID != "url:https://mangadex.org/title/abc" {
+		t.Fatalf("unexpected key %+v", key)
+	}
+}
@@ -0,0 +1,179 @@
+// Package timefmt formats timestamps relative to the current time for the
+// dashboard, the card API, digests and anything else that shows "3 hours
+// ago" style labels, so they all agree on thresholds and wording. It also
+// parses the relative labels scraped sources show back into timestamps.
+package timefmt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// relativeUnitPattern matches "3 hours", "an hour", "5h", "2mo" and the like;
+// longer unit names come first so "3 months" is not read as "3 m".
+var relativeUnitPattern = regexp.MustCompile(`\b(?:(\d+)\s*|(an?)\s+)(minutes?|mins?|hours?|hrs?|days?|weeks?|months?|mos?|years?|yrs?|m|h|d|w|y)\b`)
+
+// Style picks the wording of a relative time.
+type Style int
+
+const (
+	// Long spells units out: "3 hours ago", "in 2 days", "1 month ago".
+	Long Style = iota
+	// Compact abbreviates units for tight layouts: "3h", "in 2d", "1mo".
+	Compact
+)
+
+// FromNow formats value relative to the current time.
+func FromNow(value time.Time, style Style) string {
+	return Relative(value, time.Now(), style)
+}
+
+// Relative formats value relative to now. Anything within a minute either
+// side is "just now"; later values read "in ...". Minutes, hours and days
+// are elapsed time, while months and years are calendar months, so Jan 31 to
+// Feb 29 is a month and Feb 29 to Feb 28 of the next year is a year.
+func Relative(value, now time.Time, style Style) string {
+	from := value.UTC()
+	to := now.UTC()
+	future := from.After(to)
+	if future {
+		from, to = to, from
+	}
+
+	delta := to.Sub(from)
+	if delta < time.Minute {
+		if style == Compact {
+			return "now"
+		}
+		return "just now"
+	}
+
+	var count int
+	var unit string
+	switch {
+	case delta < time.Hour:
+		count, unit = int(delta/time.Minute), "minute"
+	case delta < 24*time.Hour:
+		count, unit = int(delta/time.Hour), "hour"
+	default:
+		months := monthsBetween(from, to)
+		switch {
+		case months < 1:
+			count, unit = int(delta/(24*time.Hour)), "day"
+		case months < 12:
+			count, unit = months, "month"
+		default:
+			count, unit = months/12, "year"
+		}
+	}
+
+	label := formatCount(count, unit, style)
+	if future {
+		return "in " + label
+	}
+	if style == Compact {
+		return label
+	}
+	return label + " ago"
+}
+
+var compactUnits = map[string]string{
+	"minute": "m",
+	"hour":   "h",
+	"day":    "d",
+	"month":  "mo",
+	"year":   "y",
+}
+
+func formatCount(count int, unit string, style Style) string {
+	if style == Compact {
+		return fmt.Sprintf("%d%s", count, compactUnits[unit])
+	}
+	if count == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
+
+// monthsBetween counts the whole calendar months from from to to, where
+// from is not after to. A month that starts on a day the target month does
+// not have ends on that month's last day.
+func monthsBetween(from, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+	for months > 0 && addMonths(from, months).After(to) {
+		months--
+	}
+	return months
+}
+
+func addMonths(value time.Time, months int) time.Time {
+	year, month, day := value.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, value.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day,
+		value.Hour(), value.Minute(), value.Second(), value.Nanosecond(), value.Location())
+}
+
+// ParseRelative reads a scraped relative time such as "just now", "Today",
+// "Yesterday", "3 hours ago", "an hour ago", "2d ago" or "1 day 4 hours"
+// back into a timestamp resolved against now, or nil when raw has none.
+// "Today" and "Yesterday" resolve to the start of that UTC day, matching the
+// midnight absolute dates parse to.
+func ParseRelative(raw string, now time.Time) *time.Time {
+	normalized := strings.TrimSpace(strings.ToLower(strings.ReplaceAll(raw, "\u00a0", " ")))
+	if normalized == "" {
+		return nil
+	}
+
+	now = now.UTC()
+	if strings.Contains(normalized, "just now") {
+		return &now
+	}
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if strings.Contains(normalized, "yesterday") {
+		result := startOfDay.AddDate(0, 0, -1)
+		return &result
+	}
+	if strings.Contains(normalized, "today") {
+		return &startOfDay
+	}
+
+	matches := relativeUnitPattern.FindAllStringSubmatch(normalized, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	result := now
+	for _, match := range matches {
+		quantity := 1
+		if match[1] != "" {
+			parsed, err := strconv.Atoi(match[1])
+			if err != nil {
+				continue
+			}
+			quantity = parsed
+		}
+
+		switch strings.TrimSuffix(match[3], "s") {
+		case "minute", "min", "m":
+			result = result.Add(-time.Duration(quantity) * time.Minute)
+		case "hour", "hr", "h":
+			result = result.Add(-time.Duration(quantity) * time.Hour)
+		case "day", "d":
+			result = result.AddDate(0, 0, -quantity)
+		case "week", "w":
+			result = result.AddDate(0, 0, -7*quantity)
+		case "month", "mo":
+			result = result.AddDate(0, -quantity, 0)
+		case "year", "yr", "y":
+			result = result.AddDate(-quantity, 0, 0)
+		}
+	}
+	return &result
+}
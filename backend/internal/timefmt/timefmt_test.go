@@ -0,0 +1,109 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelative(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name    string
+		value   time.Time
+		now     time.Time
+		long    string
+		compact string
+	}{
+		{name: "same instant", value: now, now: now, long: "just now", compact: "now"},
+		{name: "59 seconds ago", value: now.Add(-59 * time.Second), now: now, long: "just now", compact: "now"},
+		{name: "59 seconds ahead", value: now.Add(59 * time.Second), now: now, long: "just now", compact: "now"},
+		{name: "one minute ago", value: now.Add(-time.Minute), now: now, long: "1 minute ago", compact: "1m"},
+		{name: "61 seconds ago", value: now.Add(-61 * time.Second), now: now, long: "1 minute ago", compact: "1m"},
+		{name: "59 minutes ago", value: now.Add(-59*time.Minute - 59*time.Second), now: now, long: "59 minutes ago", compact: "59m"},
+		{name: "one hour ago", value: now.Add(-time.Hour), now: now, long: "1 hour ago", compact: "1h"},
+		{name: "two hours ago", value: now.Add(-2 * time.Hour), now: now, long: "2 hours ago", compact: "2h"},
+		{name: "23h59m ago", value: now.Add(-23*time.Hour - 59*time.Minute), now: now, long: "23 hours ago", compact: "23h"},
+		{name: "24 hours ago", value: now.Add(-24 * time.Hour), now: now, long: "1 day ago", compact: "1d"},
+		{name: "six days ago", value: now.AddDate(0, 0, -6), now: now, long: "6 days ago", compact: "6d"},
+		{name: "one calendar month ago", value: at(2025, 2, 15, 12, 0), now: now, long: "1 month ago", compact: "1mo"},
+		{name: "a minute short of a month", value: at(2025, 2, 15, 12, 1), now: now, long: "27 days ago", compact: "27d"},
+		{name: "30 days into a 31-day month", value: at(2025, 1, 1, 0, 0), now: at(2025, 1, 31, 0, 0), long: "30 days ago", compact: "30d"},
+		{name: "31-day month complete", value: at(2025, 1, 1, 0, 0), now: at(2025, 2, 1, 0, 0), long: "1 month ago", compact: "1mo"},
+		{name: "30-day month complete", value: at(2025, 4, 1, 0, 0), now: at(2025, 5, 1, 0, 0), long: "1 month ago", compact: "1mo"},
+		{name: "month end clamps to shorter month", value: at(2025, 1, 31, 0, 0), now: at(2025, 2, 28, 0, 0), long: "1 month ago", compact: "1mo"},
+		{name: "month end into leap february", value: at(2024, 1, 31, 0, 0), now: at(2024, 2, 28, 0, 0), long: "28 days ago", compact: "28d"},
+		{name: "leap february complete", value: at(2024, 1, 31, 0, 0), now: at(2024, 2, 29, 0, 0), long: "1 month ago", compact: "1mo"},
+		{name: "eleven months ago", value: at(2024, 4, 15, 12, 0), now: now, long: "11 months ago", compact: "11mo"},
+		{name: "one year ago", value: at(2024, 3, 15, 12, 0), now: now, long: "1 year ago", compact: "1y"},
+		{name: "leap day to next february 28", value: at(2024, 2, 29, 0, 0), now: at(2025, 2, 28, 0, 0), long: "1 year ago", compact: "1y"},
+		{name: "leap day a day short of a year", value: at(2024, 2, 29, 0, 0), now: at(2025, 2, 27, 0, 0), long: "11 months ago", compact: "11mo"},
+		{name: "three years ago", value: at(2021, 12, 1, 0, 0), now: now, long: "3 years ago", compact: "3y"},
+		{name: "in one minute", value: now.Add(time.Minute), now: now, long: "in 1 minute", compact: "in 1m"},
+		{name: "in three hours", value: now.Add(3 * time.Hour), now: now, long: "in 3 hours", compact: "in 3h"},
+		{name: "in two days", value: now.AddDate(0, 0, 2), now: now, long: "in 2 days", compact: "in 2d"},
+		{name: "in one month", value: at(2025, 4, 15, 12, 0), now: now, long: "in 1 month", compact: "in 1mo"},
+		{name: "in two years", value: at(2027, 3, 15, 12, 0), now: now, long: "in 2 years", compact: "in 2y"},
+		{name: "other time zones compare as instants", value: time.Date(2025, 3, 15, 13, 0, 0, 0, time.FixedZone("CET", 3600)), now: now, long: "just now", compact: "now"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Relative(tc.value, tc.now, Long); got != tc.long {
+				t.Errorf("Relative(%s, Long) = %q, want %q", tc.value, got, tc.long)
+			}
+			if got := Relative(tc.value, tc.now, Compact); got != tc.compact {
+				t.Errorf("Relative(%s, Compact) = %q, want %q", tc.value, got, tc.compact)
+			}
+		})
+	}
+}
+
+func TestFromNowUsesCurrentTime(t *testing.T) {
+	if got := FromNow(time.Now().Add(-2*time.Hour-time.Minute), Long); got != "2 hours ago" {
+		t.Fatalf("expected 2 hours ago, got %q", got)
+	}
+}
+
+func TestParseRelative(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		raw  string
+		want time.Time
+		none bool
+	}{
+		{raw: "just now", want: now},
+		{raw: "Today", want: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{raw: "Yesterday", want: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{raw: "3 hours ago", want: now.Add(-3 * time.Hour)},
+		{raw: "an hour ago", want: now.Add(-time.Hour)},
+		{raw: "a day ago", want: now.AddDate(0, 0, -1)},
+		{raw: "1 minute ago", want: now.Add(-time.Minute)},
+		{raw: "5h ago", want: now.Add(-5 * time.Hour)},
+		{raw: "2mo ago", want: now.AddDate(0, -2, 0)},
+		{raw: "1yr ago", want: now.AddDate(-1, 0, 0)},
+		{raw: "3 months", want: now.AddDate(0, -3, 0)},
+		{raw: "Updated 1 day 4 hours ago", want: now.AddDate(0, 0, -1).Add(-4 * time.Hour)},
+		{raw: "2 weeks ago", want: now.AddDate(0, 0, -14)},
+		{raw: "", none: true},
+		{raw: "unknown", none: true},
+		{raw: "Chapter 12", none: true},
+	}
+
+	for _, tt := range tests {
+		got := ParseRelative(tt.raw, now)
+		if tt.none {
+			if got != nil {
+				t.Fatalf("ParseRelative(%q): expected nil, got %s", tt.raw, got.Format(time.RFC3339))
+			}
+			continue
+		}
+		if got == nil || !got.Equal(tt.want) {
+			t.Fatalf("ParseRelative(%q): expected %s, got %v", tt.raw, tt.want.Format(time.RFC3339), got)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS profile_email_digests (
+    profile_id INTEGER PRIMARY KEY,
+    email TEXT NOT NULL,
+    hour_utc INTEGER NOT NULL DEFAULT 8,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    last_sent_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (profile_id) REFERENCES profiles(id) ON DELETE CASCADE,
+    CHECK (hour_utc >= 0 AND hour_utc <= 23)
+);
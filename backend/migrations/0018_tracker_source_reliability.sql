@@ -0,0 +1,4 @@
+ALTER TABLE tracker_sources ADD COLUMN success_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tracker_sources ADD COLUMN failure_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tracker_sources ADD COLUMN last_lag_chapters REAL;
+ALTER TABLE tracker_sources ADD COLUMN last_poll_failed INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+ALTER TABLE sources ADD COLUMN search_mode TEXT NOT NULL DEFAULT 'title'
+    CHECK (search_mode IN ('title', 'url_only', 'title_or_url'));
+
+UPDATE sources
+SET search_mode = 'title_or_url'
+WHERE key = 'mangafire';
@@ -0,0 +1,4 @@
+-- Existing trackers keep NULL milestones: there is no history to derive
+-- when they were first read or first caught up.
+ALTER TABLE trackers ADD COLUMN first_read_at DATETIME;
+ALTER TABLE trackers ADD COLUMN caught_up_at DATETIME;
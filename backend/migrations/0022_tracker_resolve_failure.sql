@@ -0,0 +1,3 @@
+-- Set when background retries of a new tracker's source lookup all fail;
+-- cleared once the source resolves again.
+ALTER TABLE trackers ADD COLUMN resolve_failure TEXT;
@@ -0,0 +1,4 @@
+-- Set when a linked source looks like a different series than its tracker,
+-- either by title when it is linked or by chapter count while polling.
+-- Only dismissing the warning clears it.
+ALTER TABLE tracker_sources ADD COLUMN mismatch_suspected INTEGER NOT NULL DEFAULT 0;
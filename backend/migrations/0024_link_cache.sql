@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS link_cache (
+    kind TEXT NOT NULL,
+    cache_key TEXT NOT NULL,
+    url TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kind, cache_key)
+);
@@ -0,0 +1,4 @@
+-- The poller records why the last resolve of a tracker's primary source
+-- failed; both columns are cleared by the next successful poll.
+ALTER TABLE trackers ADD COLUMN last_poll_error TEXT;
+ALTER TABLE trackers ADD COLUMN last_poll_error_at DATETIME;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS cover_thumbnails (
+    source_key TEXT NOT NULL,
+    item_id TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    data BLOB NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (source_key, item_id)
+);
@@ -0,0 +1,4 @@
+-- A tracker can point at the tracker that carries the series on, e.g. a
+-- "Season 2" published as a separate series page. Deleting the continuation
+-- clears the link.
+ALTER TABLE trackers ADD COLUMN continued_by_tracker_id INTEGER REFERENCES trackers(id) ON DELETE SET NULL;
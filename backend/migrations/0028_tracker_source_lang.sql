@@ -0,0 +1,3 @@
+-- The translation to follow on sites that serve a series in several
+-- languages; connectors without language support ignore it.
+ALTER TABLE tracker_sources ADD COLUMN lang TEXT NOT NULL DEFAULT 'en';
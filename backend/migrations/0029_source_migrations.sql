@@ -0,0 +1,13 @@
+-- cleanup-stale-sources records every primary source it replaces, so the
+-- URL of a tracker's removed source can still be found after the cleanup.
+CREATE TABLE IF NOT EXISTS source_migrations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tracker_id INTEGER NOT NULL REFERENCES trackers(id) ON DELETE CASCADE,
+    old_source_key TEXT NOT NULL,
+    old_source_url TEXT NOT NULL,
+    old_source_item_id TEXT,
+    new_source_id INTEGER NOT NULL,
+    migrated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_source_migrations_tracker_id ON source_migrations(tracker_id);
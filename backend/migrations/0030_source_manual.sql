@@ -0,0 +1,5 @@
+-- The Manual source tracks series on sites no connector can scrape; its
+-- chapters are logged by hand. connector_kind only allows 'native' and
+-- 'yaml', and the manual connector is built in.
+INSERT OR IGNORE INTO sources (key, name, connector_kind, search_mode, enabled)
+VALUES ('manual', 'Manual', 'native', 'url_only', 1);
@@ -0,0 +1,6 @@
+-- A short note on a source's current trouble (domain change, Cloudflare
+-- wall), shown on its trackers' cards. note_source records who wrote it:
+-- 'manual' notes are never replaced by the poller's 'auto' ones.
+ALTER TABLE sources ADD COLUMN status_note TEXT NOT NULL DEFAULT '';
+ALTER TABLE sources ADD COLUMN status_note_updated_at DATETIME;
+ALTER TABLE sources ADD COLUMN note_source TEXT NOT NULL DEFAULT '' CHECK (note_source IN ('', 'manual', 'auto'));
@@ -0,0 +1,25 @@
+-- A profile's linked MangaDex account. sealed_refresh_token is encrypted
+-- with MANGADEX_TOKEN_SECRET; the password is never stored.
+CREATE TABLE IF NOT EXISTS profile_mangadex_links (
+    profile_id INTEGER PRIMARY KEY,
+    username TEXT NOT NULL,
+    sealed_refresh_token TEXT NOT NULL,
+    sync_enabled INTEGER NOT NULL DEFAULT 1,
+    last_synced_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (profile_id) REFERENCES profiles(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS mangadex_sync_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    profile_id INTEGER NOT NULL,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME NOT NULL,
+    trackers_checked INTEGER NOT NULL DEFAULT 0,
+    trackers_updated INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (profile_id) REFERENCES profiles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_mangadex_sync_runs_profile ON mangadex_sync_runs(profile_id, started_at DESC);
@@ -0,0 +1,16 @@
+-- How often last_read advanced on each tracker from each source's links,
+-- to show which sites are actually used for reading. Profile totals are
+-- the sum over the profile's trackers.
+CREATE TABLE IF NOT EXISTS read_source_stats (
+    profile_id INTEGER NOT NULL,
+    tracker_id INTEGER NOT NULL,
+    source_id INTEGER NOT NULL,
+    reads INTEGER NOT NULL DEFAULT 0,
+    last_read_at DATETIME NOT NULL,
+    PRIMARY KEY (tracker_id, source_id),
+    FOREIGN KEY (profile_id) REFERENCES profiles(id) ON DELETE CASCADE,
+    FOREIGN KEY (tracker_id) REFERENCES trackers(id) ON DELETE CASCADE,
+    FOREIGN KEY (source_id) REFERENCES sources(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_read_source_stats_profile ON read_source_stats(profile_id, source_id);
@@ -0,0 +1,5 @@
+-- The outcome of the daily deep health check, which resolves a known series
+-- on each source. canary_failure is empty after a passing check; while it is
+-- set, the poller's clean runs leave the source's 'auto' note in place.
+ALTER TABLE sources ADD COLUMN canary_failure TEXT NOT NULL DEFAULT '';
+ALTER TABLE sources ADD COLUMN canary_checked_at DATETIME;
@@ -0,0 +1,14 @@
+-- One row per advance of a tracker's last read chapter, for the reading
+-- history timeline. from_chapter is NULL for the first chapter ever read.
+CREATE TABLE IF NOT EXISTS read_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    profile_id INTEGER NOT NULL,
+    tracker_id INTEGER NOT NULL,
+    from_chapter REAL,
+    to_chapter REAL NOT NULL,
+    read_at DATETIME NOT NULL,
+    FOREIGN KEY (profile_id) REFERENCES profiles(id) ON DELETE CASCADE,
+    FOREIGN KEY (tracker_id) REFERENCES trackers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_read_events_tracker ON read_events(tracker_id, read_at);
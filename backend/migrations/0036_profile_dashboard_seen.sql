@@ -0,0 +1,5 @@
+-- When each profile last loaded the full dashboard, and the load before
+-- that. Cards count chapters released since the previous load, so the
+-- badges stay put while the page refreshes its partials.
+ALTER TABLE profiles ADD COLUMN last_dashboard_seen_at DATETIME;
+ALTER TABLE profiles ADD COLUMN previous_dashboard_seen_at DATETIME;
@@ -0,0 +1,3 @@
+-- The genres the primary source lists for the series, as a JSON array. The
+-- edit form suggests profile tags from them.
+ALTER TABLE trackers ADD COLUMN source_genres TEXT;
@@ -0,0 +1,5 @@
+-- How far a linked source's chapter numbers sit from the series' own
+-- numbering: its chapter N is chapter N + chapter_offset elsewhere. Stored
+-- chapters stay as the source reports them; the offset is only applied when
+-- sources are compared.
+ALTER TABLE tracker_sources ADD COLUMN chapter_offset REAL NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Set once the user dismisses a new tracker from the dashboard's recently
+-- added strip, so it leaves the strip before its window runs out.
+ALTER TABLE trackers ADD COLUMN setup_complete INTEGER NOT NULL DEFAULT 0;
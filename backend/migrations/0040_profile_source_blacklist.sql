@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS profile_source_blacklist (
+    profile_id INTEGER NOT NULL,
+    source_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (profile_id, source_id),
+    FOREIGN KEY (profile_id) REFERENCES profiles(id) ON DELETE CASCADE,
+    FOREIGN KEY (source_id) REFERENCES sources(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_profile_source_blacklist_source_id ON profile_source_blacklist(source_id);
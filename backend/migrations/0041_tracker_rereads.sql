@@ -0,0 +1,10 @@
+-- Re-reading a completed series. rereading is set while the tracker is read
+-- again from chapter 0, reread_count counts the re-reads finished so far,
+-- and previous_completions is a JSON array of the read-throughs a re-read
+-- started over from, oldest first.
+ALTER TABLE trackers ADD COLUMN rereading INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE trackers ADD COLUMN reread_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE trackers ADD COLUMN previous_completions TEXT;
+
+-- Set on the reads made during a re-read, so stats can count them apart.
+ALTER TABLE read_events ADD COLUMN reread INTEGER NOT NULL DEFAULT 0;
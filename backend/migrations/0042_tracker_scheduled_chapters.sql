@@ -0,0 +1,4 @@
+-- The next chapter a source has announced but not released yet, and when it
+-- comes out. Scheduled chapters never count as the latest known chapter.
+ALTER TABLE trackers ADD COLUMN next_scheduled_chapter REAL;
+ALTER TABLE trackers ADD COLUMN next_scheduled_at DATETIME;
@@ -0,0 +1,3 @@
+-- Set once a source row has been edited by hand, after which SeedDefaults no
+-- longer renames it or changes its connector kind.
+ALTER TABLE sources ADD COLUMN user_modified INTEGER NOT NULL DEFAULT 0;
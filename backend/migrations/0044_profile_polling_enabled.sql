@@ -0,0 +1,3 @@
+-- Profiles with polling turned off keep their trackers out of the poller,
+-- so an archive profile does not spend the polling budget.
+ALTER TABLE profiles ADD COLUMN polling_enabled INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,5 @@
+-- Optional tag filters of the email digest: only trackers with the include
+-- tag, and never those with the exclude tag, are listed. A tag deleted
+-- later leaves its id behind, which the digest job treats as no filter.
+ALTER TABLE profile_email_digests ADD COLUMN include_tag_id INTEGER;
+ALTER TABLE profile_email_digests ADD COLUMN exclude_tag_id INTEGER;
@@ -0,0 +1,7 @@
+-- name_normalized is the form tag filters match on (see
+-- searchutil.NormalizeTagName). The repository writes it with every tag
+-- name; this lowercases existing names, and the startup backfill then
+-- folds their accents, which SQL cannot.
+ALTER TABLE custom_tags ADD COLUMN name_normalized TEXT NOT NULL DEFAULT '';
+UPDATE custom_tags SET name_normalized = LOWER(TRIM(name));
+CREATE INDEX IF NOT EXISTS idx_custom_tags_profile_name_normalized ON custom_tags(profile_id, name_normalized);
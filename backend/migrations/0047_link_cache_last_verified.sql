@@ -0,0 +1,4 @@
+-- When a stored link was last found to still serve, set by the
+-- verify-covers command. NULL until first checked, and reset whenever the
+-- link changes.
+ALTER TABLE link_cache ADD COLUMN last_verified_at DATETIME;
@@ -0,0 +1,13 @@
+-- Named dashboard filter sets of a profile. params holds the JSON of
+-- models.FilterPresetParams; tags and sites are kept by name and id, so a
+-- preset outlives the tags and sources it names and applying it skips them.
+CREATE TABLE IF NOT EXISTS filter_presets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    profile_id INTEGER NOT NULL,
+    name TEXT NOT NULL COLLATE NOCASE,
+    params TEXT NOT NULL DEFAULT '{}',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (profile_id, name),
+    FOREIGN KEY (profile_id) REFERENCES profiles(id) ON DELETE CASCADE
+);
@@ -0,0 +1,18 @@
+-- position orders a tracker's linked sources, lowest first, as the user
+-- arranged them in the edit form; among otherwise equal candidates the
+-- earlier one is preferred. Existing links start in the source name order
+-- they were listed in until now.
+ALTER TABLE tracker_sources ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+UPDATE tracker_sources
+SET position = ranked.position
+FROM (
+    SELECT
+        ts.id,
+        ROW_NUMBER() OVER (PARTITION BY ts.tracker_id ORDER BY s.name ASC, ts.id ASC) - 1 AS position
+    FROM tracker_sources ts
+    INNER JOIN sources s ON s.id = ts.source_id
+) AS ranked
+WHERE ranked.id = tracker_sources.id;
+
+CREATE INDEX IF NOT EXISTS idx_tracker_sources_tracker_position ON tracker_sources(tracker_id, position);
@@ -0,0 +1,23 @@
+-- Every URL a tracker's primary or linked source was stored at before it
+-- was rewritten, so old bookmarks and exports can still be traced back to
+-- their tracker. source_id has no foreign key: the cleanup tool deletes the
+-- sources it promotes trackers away from, and their old URLs stay useful.
+CREATE TABLE IF NOT EXISTS tracker_url_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tracker_id INTEGER NOT NULL REFERENCES trackers(id) ON DELETE CASCADE,
+    source_id INTEGER NOT NULL,
+    old_url TEXT NOT NULL,
+    replaced_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reason TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_tracker_url_history_tracker_id ON tracker_url_history(tracker_id);
+
+-- The cleanup tool's earlier promotions. Their source_id is 0 where the
+-- source was deleted by then, as it usually was.
+INSERT INTO tracker_url_history (tracker_id, source_id, old_url, replaced_at, reason)
+SELECT sm.tracker_id, COALESCE(s.id, 0), sm.old_source_url, sm.migrated_at, 'promotion'
+FROM source_migrations sm
+LEFT JOIN sources s ON s.key = sm.old_source_key
+WHERE TRIM(sm.old_source_url) != ''
+ORDER BY sm.id ASC;
@@ -0,0 +1,4 @@
+-- Dashboard sort defaults of a profile, as the JSON of
+-- models.StatusSortDefaults: a sort and order per tracker status, used when
+-- the dashboard is opened without an explicit sort.
+ALTER TABLE profiles ADD COLUMN status_sort_defaults TEXT NOT NULL DEFAULT '{}';
@@ -7,6 +7,22 @@ window.escapeHtml = function (value) {
         .replace(/'/g, '&#39;');
 };
 
+window.appURL = function (path) {
+    var root = document.documentElement;
+    var basePath = root ? String(root.getAttribute('data-base-path') || '') : '';
+    return basePath + path;
+};
+
+// Rate-limited requests answer 429 with a rendered message; swap it in like
+// a normal response instead of dropping it.
+document.addEventListener('htmx:beforeSwap', function (event) {
+    var xhr = event.detail && event.detail.xhr;
+    if (xhr && xhr.status === 429 && xhr.responseText) {
+        event.detail.shouldSwap = true;
+        event.detail.isError = false;
+    }
+});
+
 window.syncTrackerCardHoverState = function (clientX, clientY) {
     if (!document) {
         return;
@@ -80,7 +96,7 @@ window.dispatchTrackersChanged = function (reason) {
 };
 
 window.setDashboardViewMode = function (mode, shouldRefresh) {
-    var nextMode = (mode === 'list') ? 'list' : 'grid';
+    var nextMode = (mode === 'list' || mode === 'wall') ? mode : 'grid';
     var viewInput = document.getElementById('view-input');
     var currentMode = viewInput && viewInput.value ? viewInput.value : 'grid';
 
@@ -127,11 +143,11 @@ window.onProfileSwitch = function (select) {
 
     var renameForm = document.getElementById('profile-rename-form');
     if (renameForm) {
-        renameForm.setAttribute('action', '/dashboard/profile/rename?profile=' + encodeURIComponent(selectedProfile));
+        renameForm.setAttribute('action', window.appURL('/dashboard/profile/rename?profile=' + encodeURIComponent(selectedProfile)));
     }
 
     if (window.history && window.history.replaceState) {
-        var nextURL = '/dashboard?profile=' + encodeURIComponent(selectedProfile);
+        var nextURL = window.appURL('/dashboard?profile=' + encodeURIComponent(selectedProfile));
         window.history.replaceState({}, '', nextURL);
     }
 
@@ -164,7 +180,7 @@ window.renameProfileOnce = function () {
 
     var selectedProfile = (select.value || '').trim();
     if (selectedProfile) {
-        form.setAttribute('action', '/dashboard/profile/rename?profile=' + encodeURIComponent(selectedProfile));
+        form.setAttribute('action', window.appURL('/dashboard/profile/rename?profile=' + encodeURIComponent(selectedProfile)));
     }
 
     form.submit();
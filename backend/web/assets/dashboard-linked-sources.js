@@ -21,12 +21,61 @@ window.renderLinkedSources = function (form) {
         return;
     }
 
+    var languageSourceIDs = {};
+    var languageHidden = form.querySelector('#language-source-ids-json');
+    try {
+        (JSON.parse((languageHidden && languageHidden.value) || '[]') || []).forEach(function (id) {
+            languageSourceIDs[Number(id)] = true;
+        });
+    } catch (_) {
+        languageSourceIDs = {};
+    }
+
+    var blacklistedSourceIDs = window.readBlacklistedSourceIDs(form);
+
+    var primarySourceField = form.querySelector('select[name="source_id"]');
+    var primaryURLField = form.querySelector('input[name="source_url"]');
+    var primarySourceID = Number(primarySourceField && primarySourceField.value);
+    var primaryURL = String((primaryURLField && primaryURLField.value) || '').trim().toLowerCase();
+
     var html = items.map(function (item, index) {
         var sourceName = window.escapeHtml(item.sourceName || ('Source #' + item.sourceId));
         var sourceUrl = window.escapeHtml(item.sourceUrl || '');
+        var reliability = item.reliability
+            ? '<span class="linked-source-reliability" title="Based on recent polls">' + window.escapeHtml(item.reliability) + '</span>'
+            : '';
+        var blacklisted = blacklistedSourceIDs[Number(item.sourceId)]
+            ? '<span class="linked-source-blacklisted" title="Blacklisted for this profile; this link still updates">Blacklisted</span>'
+            : '';
+        var mismatch = item.mismatchSuspected && item.id
+            ? '<span class="linked-source-mismatch" title="Title or chapter count differs from the other linked sites">&#9888; May be a different series</span>' +
+                '<button type="button" class="linked-btn read-only-hidden" onclick="window.dismissLinkedSourceMismatch(' + index + ', this)">Dismiss</button>'
+            : '';
+        var language = languageSourceIDs[Number(item.sourceId)]
+            ? '<input type="text" class="linked-source-lang" aria-label="Language" title="Translation to follow, e.g. en or pt-br" maxlength="12" value="' + window.escapeHtml(item.lang || 'en') + '" onchange="window.setTrackerLinkedSourceLang(' + index + ', this)">'
+            : '';
+        var offset = items.length > 1
+            ? '<input type="number" class="linked-source-offset" aria-label="Chapter offset" title="Added to this site\'s chapter numbers when comparing it with the other linked sites, e.g. -4 if its chapter 100 is chapter 96 elsewhere" step="any" min="-1000" max="1000" value="' + window.escapeHtml(String(Number(item.chapterOffset) || 0)) + '" onchange="window.setTrackerLinkedSourceChapterOffset(' + index + ', this)">'
+            : '';
+        var handle = items.length > 1
+            ? '<span class="linked-source-handle read-only-hidden" draggable="true" tabindex="0" role="button" aria-label="Reorder ' + sourceName + ', use the arrow keys to move it" title="Drag to reorder; earlier sites are preferred when sites tie">&#8942;&#8942;</span>'
+            : '';
+        var isPrimary = Number(item.sourceId) === primarySourceID && String(item.sourceUrl || '').trim().toLowerCase() === primaryURL;
+        var primary = isPrimary
+            ? '<span class="linked-source-primary">Primary</span>'
+            : (item.id && item.trackerId
+                ? '<button type="button" class="linked-btn" title="Use this site for chapter updates" onclick="window.makeTrackerLinkedSourcePrimary(' + index + ', this)">Make primary</button>'
+                : '');
         return '' +
-            '<div class="linked-source-row">' +
+            '<div class="linked-source-row" data-linked-index="' + index + '">' +
+            handle +
             '<span class="linked-source-name">' + sourceName + '</span>' +
+            primary +
+            language +
+            offset +
+            reliability +
+            blacklisted +
+            mismatch +
             '<a class="linked-btn" href="' + sourceUrl + '" target="_blank" rel="noopener noreferrer">Open</a>' +
             '<button type="button" class="linked-btn linked-btn--danger" onclick="window.removeTrackerLinkedSource(' + index + ', this)">Remove</button>' +
             '</div>';
@@ -35,6 +84,21 @@ window.renderLinkedSources = function (form) {
     list.innerHTML = html;
 };
 
+// readBlacklistedSourceIDs returns the form's blacklisted sources as a set
+// of source ids.
+window.readBlacklistedSourceIDs = function (form) {
+    var ids = {};
+    var hidden = form && form.querySelector('#blacklisted-source-ids-json');
+    try {
+        Object.keys(JSON.parse((hidden && hidden.value) || '{}') || {}).forEach(function (id) {
+            ids[Number(id)] = true;
+        });
+    } catch (_) {
+        ids = {};
+    }
+    return ids;
+};
+
 window.parseRelatedTitlesDataset = function (rawValue) {
     var trimmed = String(rawValue || '').trim();
     if (!trimmed) {
@@ -86,6 +150,7 @@ window.syncLinkedSourceSelect = function (form) {
         allSources = [];
     }
 
+    var blacklistedSourceIDs = window.readBlacklistedSourceIDs(form);
     var linkedSourceIDs = {};
     linkedItems.forEach(function (item) {
         var sourceID = Number(item && item.sourceId);
@@ -100,7 +165,7 @@ window.syncLinkedSourceSelect = function (form) {
 
     allSources.forEach(function (source) {
         var sourceID = Number(source && source.id);
-        if (!sourceID || linkedSourceIDs[sourceID]) {
+        if (!sourceID || linkedSourceIDs[sourceID] || blacklistedSourceIDs[sourceID]) {
             return;
         }
 
@@ -109,13 +174,63 @@ window.syncLinkedSourceSelect = function (form) {
             hasPreviousValue = true;
         }
 
-        optionHtml += '<option value="' + value + '">' + window.escapeHtml(source.name || ('Source #' + value)) + '</option>';
+        optionHtml += '<option value="' + value + '" data-search-mode="' + window.escapeHtml(source.searchMode || 'title') + '">' + window.escapeHtml(source.name || ('Source #' + value)) + '</option>';
     });
 
     select.innerHTML = optionHtml;
     select.value = hasPreviousValue ? previousValue : '';
+    window.applySourceSearchMode(select);
 };
 
+// applySourceSearchMode updates the search box paired with a source select
+// (via data-search-input) to match the selected source's search mode.
+window.applySourceSearchMode = function (select) {
+    if (!select || !select.dataset || !select.dataset.searchInput) {
+        return;
+    }
+
+    var input = document.getElementById(select.dataset.searchInput);
+    if (!input) {
+        return;
+    }
+    var help = document.getElementById(select.dataset.searchInput + '-help');
+
+    var option = select.options[select.selectedIndex];
+    var mode = (option && option.dataset.searchMode) || 'title';
+    var name = option && option.value ? option.textContent.trim() : '';
+
+    var placeholder = input.dataset.titlePlaceholder || input.placeholder;
+    var helpText = '';
+    if (mode === 'url_only') {
+        placeholder = 'Paste a ' + name + ' series URL';
+        helpText = name + ' cannot be searched by title; paste the series page URL.';
+    } else if (mode === 'title_or_url') {
+        placeholder = 'Type a title or paste a ' + name + ' URL';
+        helpText = 'Pasting the series page URL picks the exact entry.';
+    }
+
+    input.placeholder = placeholder;
+    if (help) {
+        help.textContent = helpText;
+        help.hidden = helpText === '';
+    }
+};
+
+document.addEventListener('change', function (event) {
+    var target = event.target;
+    if (target && target.tagName === 'SELECT' && target.dataset.searchInput) {
+        window.applySourceSearchMode(target);
+    }
+});
+
+document.body.addEventListener('htmx:afterSwap', function (event) {
+    var root = event.detail && event.detail.target;
+    if (!root || !root.querySelectorAll) {
+        return;
+    }
+    root.querySelectorAll('select[data-search-input]').forEach(window.applySourceSearchMode);
+});
+
 window.removeTrackerLinkedSource = function (index, button) {
     var form = button && (button.closest('.tracker-form') || document.querySelector('#modal-zone .tracker-form'));
     if (!form) {
@@ -143,6 +258,249 @@ window.removeTrackerLinkedSource = function (index, button) {
     window.syncLinkedSourceSelect(form);
 };
 
+// moveTrackerLinkedSource moves the linked source at index from to index to.
+// When every linked source is already saved the new order is saved right
+// away; otherwise it is saved with the form.
+window.moveTrackerLinkedSource = function (form, from, to) {
+    var hidden = form && form.querySelector('#linked-sources-json');
+    if (!hidden) {
+        return;
+    }
+
+    var items = [];
+    try {
+        items = JSON.parse(hidden.value || '[]');
+    } catch (_) {
+        items = [];
+    }
+    if (!Array.isArray(items) || from === to || !items[from] || to < 0 || to >= items.length) {
+        return;
+    }
+
+    var moved = items.splice(from, 1)[0];
+    items.splice(to, 0, moved);
+    hidden.value = JSON.stringify(items);
+    window.renderLinkedSources(form);
+
+    var handle = form.querySelector('[data-linked-index="' + to + '"] .linked-source-handle');
+    if (handle && document.activeElement === document.body) {
+        handle.focus();
+    }
+
+    var trackerID = moved.trackerId;
+    var saved = items.every(function (item) {
+        return item.id && item.trackerId === trackerID;
+    });
+    if (!saved) {
+        return;
+    }
+
+    var profileInput = document.getElementById('profile-filter');
+    var profileKey = profileInput && profileInput.value ? String(profileInput.value).trim() : '';
+    var requestURL = window.appURL('/dashboard/trackers/' + encodeURIComponent(String(trackerID)) + '/sources/reorder');
+    if (profileKey) {
+        requestURL += '?profile=' + encodeURIComponent(profileKey);
+    }
+    var body = new URLSearchParams();
+    items.forEach(function (item) {
+        body.append('tracker_source_id', String(item.id));
+    });
+    fetch(requestURL, { method: 'POST', credentials: 'same-origin', body: body });
+};
+
+document.addEventListener('dragstart', function (event) {
+    var handle = event.target && event.target.closest && event.target.closest('.linked-source-handle');
+    var row = handle && handle.closest('.linked-source-row');
+    if (!row) {
+        return;
+    }
+    event.dataTransfer.effectAllowed = 'move';
+    event.dataTransfer.setData('text/plain', row.dataset.linkedIndex);
+    row.classList.add('is-dragging');
+});
+
+document.addEventListener('dragend', function (event) {
+    var row = event.target && event.target.closest && event.target.closest('.linked-source-row');
+    if (row) {
+        row.classList.remove('is-dragging');
+    }
+});
+
+document.addEventListener('dragover', function (event) {
+    var row = event.target && event.target.closest && event.target.closest('#linked-sources-list .linked-source-row');
+    if (row) {
+        event.preventDefault();
+        event.dataTransfer.dropEffect = 'move';
+    }
+});
+
+document.addEventListener('drop', function (event) {
+    var row = event.target && event.target.closest && event.target.closest('#linked-sources-list .linked-source-row');
+    if (!row) {
+        return;
+    }
+    event.preventDefault();
+    var from = parseInt(event.dataTransfer.getData('text/plain'), 10);
+    var to = parseInt(row.dataset.linkedIndex, 10);
+    if (isNaN(from) || isNaN(to)) {
+        return;
+    }
+    window.moveTrackerLinkedSource(row.closest('.tracker-form'), from, to);
+});
+
+document.addEventListener('keydown', function (event) {
+    var handle = event.target && event.target.closest && event.target.closest('.linked-source-handle');
+    if (!handle || (event.key !== 'ArrowUp' && event.key !== 'ArrowDown')) {
+        return;
+    }
+    event.preventDefault();
+    var row = handle.closest('.linked-source-row');
+    var from = parseInt(row.dataset.linkedIndex, 10);
+    window.moveTrackerLinkedSource(row.closest('.tracker-form'), from, event.key === 'ArrowUp' ? from - 1 : from + 1);
+});
+
+window.setTrackerLinkedSourceLang = function (index, input) {
+    var form = input && (input.closest('.tracker-form') || document.querySelector('#modal-zone .tracker-form'));
+    if (!form) {
+        return;
+    }
+
+    var hidden = form.querySelector('#linked-sources-json');
+    if (!hidden) {
+        return;
+    }
+
+    var items = [];
+    try {
+        items = JSON.parse(hidden.value || '[]');
+    } catch (_) {
+        items = [];
+    }
+    if (!Array.isArray(items) || !items[index]) {
+        return;
+    }
+
+    items[index].lang = String(input.value || '').trim().toLowerCase() || 'en';
+    hidden.value = JSON.stringify(items);
+};
+
+// setTrackerLinkedSourceChapterOffset stores the offset typed for a linked
+// source; a value out of range puts the previous one back.
+window.setTrackerLinkedSourceChapterOffset = function (index, input) {
+    var form = input && (input.closest('.tracker-form') || document.querySelector('#modal-zone .tracker-form'));
+    if (!form) {
+        return;
+    }
+
+    var hidden = form.querySelector('#linked-sources-json');
+    if (!hidden) {
+        return;
+    }
+
+    var items = [];
+    try {
+        items = JSON.parse(hidden.value || '[]');
+    } catch (_) {
+        items = [];
+    }
+    if (!Array.isArray(items) || !items[index]) {
+        return;
+    }
+
+    var raw = String(input.value || '').trim();
+    var offset = raw === '' ? 0 : Number(raw);
+    if (!isFinite(offset) || Math.abs(offset) > 1000) {
+        input.value = String(Number(items[index].chapterOffset) || 0);
+        return;
+    }
+    items[index].chapterOffset = offset;
+    hidden.value = JSON.stringify(items);
+};
+
+// makeTrackerLinkedSourcePrimary switches the tracker's primary source to a
+// saved linked source right away; the response replaces the card and closes
+// the modal.
+window.makeTrackerLinkedSourcePrimary = function (index, button) {
+    var form = button && (button.closest('.tracker-form') || document.querySelector('#modal-zone .tracker-form'));
+    if (!form || !window.htmx) {
+        return;
+    }
+
+    var hidden = form.querySelector('#linked-sources-json');
+    var items = [];
+    try {
+        items = JSON.parse((hidden && hidden.value) || '[]');
+    } catch (_) {
+        items = [];
+    }
+    var item = Array.isArray(items) ? items[index] : null;
+    if (!item || !item.id || !item.trackerId) {
+        return;
+    }
+
+    var profileInput = document.getElementById('profile-filter');
+    var profileKey = profileInput && profileInput.value ? String(profileInput.value).trim() : '';
+    var requestURL = window.appURL('/dashboard/trackers/' + encodeURIComponent(String(item.trackerId)) + '/primary-source');
+    if (profileKey) {
+        requestURL += '?profile=' + encodeURIComponent(profileKey);
+    }
+    var viewInput = document.getElementById('view-input');
+
+    button.disabled = true;
+    window.htmx.ajax('POST', requestURL, {
+        target: '#modal-zone',
+        swap: 'innerHTML',
+        values: {
+            tracker_source_id: String(item.id),
+            view_mode: (viewInput && viewInput.value) ? viewInput.value : 'grid'
+        }
+    });
+};
+
+window.dismissLinkedSourceMismatch = function (index, button) {
+    var form = button && (button.closest('.tracker-form') || document.querySelector('#modal-zone .tracker-form'));
+    if (!form) {
+        return;
+    }
+
+    var hidden = form.querySelector('#linked-sources-json');
+    if (!hidden) {
+        return;
+    }
+
+    var items = [];
+    try {
+        items = JSON.parse(hidden.value || '[]');
+    } catch (_) {
+        items = [];
+    }
+    var item = Array.isArray(items) ? items[index] : null;
+    if (!item || !item.id || !item.trackerId) {
+        return;
+    }
+
+    var profileInput = document.getElementById('profile-filter');
+    var profileKey = profileInput && profileInput.value ? String(profileInput.value).trim() : '';
+    var requestURL = window.appURL('/dashboard/trackers/' + encodeURIComponent(String(item.trackerId)) + '/linked-sources/' + encodeURIComponent(String(item.id)) + '/dismiss-mismatch');
+    if (profileKey) {
+        requestURL += '?profile=' + encodeURIComponent(profileKey);
+    }
+
+    button.disabled = true;
+    fetch(requestURL, { method: 'POST', credentials: 'same-origin' })
+        .then(function (response) {
+            if (!response.ok) {
+                throw new Error('dismiss mismatch request failed');
+            }
+            item.mismatchSuspected = false;
+            hidden.value = JSON.stringify(items);
+            window.renderLinkedSources(form);
+        })
+        .catch(function () {
+            button.disabled = false;
+        });
+};
+
 window.addTrackerLinkedSource = function (button) {
     if (!button) {
         return;
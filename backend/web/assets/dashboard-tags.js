@@ -21,13 +21,13 @@ window.readTrackerTagsFromForm = function (form) {
 window.getTagIconMeta = function (iconKey) {
     var key = String(iconKey || '').trim();
     if (key === 'icon_1') {
-        return { key: key, label: 'Star', path: '/assets/tag-icons/icon-star-gold.svg' };
+        return { key: key, label: 'Star', path: window.appURL('/assets/tag-icons/icon-star-gold.svg') };
     }
     if (key === 'icon_2') {
-        return { key: key, label: 'Heart', path: '/assets/tag-icons/icon-red-heart.svg' };
+        return { key: key, label: 'Heart', path: window.appURL('/assets/tag-icons/icon-red-heart.svg') };
     }
     if (key === 'icon_3') {
-        return { key: key, label: 'Flames', path: '/assets/tag-icons/icon-flames.svg' };
+        return { key: key, label: 'Flames', path: window.appURL('/assets/tag-icons/icon-flames.svg') };
     }
     return { key: key, label: key || 'Icon', path: '' };
 };
@@ -290,6 +290,31 @@ window.editProfileTagName = function (button) {
     form.requestSubmit();
 };
 
+window.applyProfileTagFilter = function (button) {
+    var tagName = String(button && button.dataset.tagName || '').trim();
+    var dropdown = document.getElementById('filter-tags-dropdown');
+    if (!tagName || !dropdown) {
+        return;
+    }
+
+    var matched = false;
+    dropdown.querySelectorAll('input[name="tags"]').forEach(function (check) {
+        check.checked = check.value === tagName;
+        matched = matched || check.checked;
+    });
+    if (!matched) {
+        return;
+    }
+
+    var pageInput = document.getElementById('page-input');
+    if (pageInput) {
+        pageInput.value = '1';
+    }
+    window.updateFilterTagsSummary();
+    window.dismissModalZone();
+    window.dispatchTrackersChanged('user');
+};
+
 document.body.addEventListener('htmx:afterSwap', function (event) {
     if (!event || !event.target || event.target.id !== 'modal-zone') {
         return;
@@ -10,9 +10,10 @@ document.body.addEventListener('trackerCreated', function (event) {
     }
 
     var listContainer = document.getElementById('cards-container-list');
+    var wallContainer = document.getElementById('cards-container-wall');
     var gridContainer = document.getElementById('cards-container-grid');
-    var activeContainer = listContainer || gridContainer;
-    var viewMode = listContainer ? 'list' : 'grid';
+    var activeContainer = listContainer || wallContainer || gridContainer;
+    var viewMode = listContainer ? 'list' : (wallContainer ? 'wall' : 'grid');
     if (!activeContainer) {
         window.dispatchTrackersChanged('system');
         return;
@@ -20,7 +21,7 @@ document.body.addEventListener('trackerCreated', function (event) {
 
     var profileInput = document.getElementById('profile-filter');
     var profileKey = profileInput && profileInput.value ? String(profileInput.value).trim() : '';
-    var requestURL = '/dashboard/trackers/' + encodeURIComponent(String(trackerID)) + '/card-fragment?view=' + encodeURIComponent(viewMode);
+    var requestURL = window.appURL('/dashboard/trackers/' + encodeURIComponent(String(trackerID)) + '/card-fragment?view=' + encodeURIComponent(viewMode));
     if (profileKey) {
         requestURL += '&profile=' + encodeURIComponent(profileKey);
     }
@@ -61,7 +62,7 @@ document.body.addEventListener('trackerCreated', function (event) {
                 window.htmx.process(card);
             }
 
-            var shouldRetryCover = (viewMode === 'grid');
+            var shouldRetryCover = (viewMode === 'grid' || viewMode === 'wall');
             if (!shouldRetryCover) {
                 return;
             }
@@ -114,7 +115,7 @@ document.body.addEventListener('trackerCreated', function (event) {
                             window.htmx.process(refreshedCard);
                         }
 
-                        var hasCoverImage = !!refreshedCard.querySelector('.tracker-card__cover img');
+                        var hasCoverImage = !!refreshedCard.querySelector('.tracker-card__cover img, .tracker-tile__link img');
                         if (!hasCoverImage && retryCount < maxRetries) {
                             window.setTimeout(retryLoadCard, retryDelayMs);
                         }
@@ -237,6 +238,40 @@ window.updateFilterTagsSummary = function () {
     summary.textContent = String(checks ? checks.length : 0);
 };
 
+window.saveFilterPreset = function (button) {
+    var form = button && button.closest ? button.closest('.filter-preset-save-form') : null;
+    var nameInput = form ? form.querySelector('input[name="preset_name"]') : null;
+    if (!nameInput) {
+        return;
+    }
+
+    var name = window.prompt('Name these filters', '');
+    if (name === null) {
+        return;
+    }
+
+    name = String(name).trim();
+    if (name === '') {
+        window.alert('Preset name is required');
+        return;
+    }
+    if (name.length > 40) {
+        window.alert('Preset name must be 40 characters or less');
+        return;
+    }
+    var taken = false;
+    document.querySelectorAll('#filter-presets [data-preset-name]').forEach(function (chip) {
+        taken = taken || String(chip.dataset.presetName || '').toLowerCase() === name.toLowerCase();
+    });
+    if (taken) {
+        window.alert('A preset with that name already exists');
+        return;
+    }
+
+    nameInput.value = name;
+    form.requestSubmit();
+};
+
 window.updateFilterSitesSummary = function () {
     var dropdown = document.getElementById('filter-sites-dropdown');
     var summary = document.getElementById('filter-sites-summary');
@@ -248,6 +283,37 @@ window.updateFilterSitesSummary = function () {
     summary.textContent = String(checks ? checks.length : 0);
 };
 
+// The sort select's default option names the sort the selected status
+// opens with, since that is what the list uses until a sort is picked.
+window.updateSortDefaultOption = function () {
+    var select = document.getElementById('filter-sort-select');
+    var form = document.getElementById('tracker-filters');
+    if (!select || !form) {
+        return;
+    }
+    var option = select.querySelector('option[data-sort-default]');
+    var statusSelect = form.querySelector('select[name="status"]');
+    var labels = {};
+    try {
+        labels = JSON.parse(select.dataset.defaultLabels || '{}');
+    } catch (error) {
+        labels = {};
+    }
+    var label = labels[statusSelect ? statusSelect.value : 'reading'];
+    if (option && label) {
+        option.textContent = 'Default: ' + label;
+    }
+};
+
+document.body.addEventListener('sortDefaultsChanged', function (event) {
+    var select = document.getElementById('filter-sort-select');
+    if (!select || !event.detail || !event.detail.labels) {
+        return;
+    }
+    select.dataset.defaultLabels = JSON.stringify(event.detail.labels);
+    window.updateSortDefaultOption();
+});
+
 document.addEventListener('change', function (event) {
     var target = event.target;
     if (!target) {
@@ -259,6 +325,10 @@ document.addEventListener('change', function (event) {
     }
     if (target.name === 'sites') {
         window.updateFilterSitesSummary();
+        return;
+    }
+    if (target.name === 'status' && target.closest('#tracker-filters')) {
+        window.updateSortDefaultOption();
     }
 });
 
@@ -548,6 +618,86 @@ document.body.addEventListener('htmx:afterSwap', function () {
     initializeVisibleRatingPopovers();
 });
 
+// Modal fragments name the element to focus in data-autofocus, so keyboard
+// and screen reader users start inside the dialog rather than behind it.
+document.body.addEventListener('htmx:afterSwap', function (event) {
+    var target = event && event.target;
+    if (!target || target.id !== 'modal-zone') {
+        return;
+    }
+    var dialog = target.querySelector('[data-autofocus]');
+    var focusTarget = dialog ? document.getElementById(dialog.getAttribute('data-autofocus')) : null;
+    if (focusTarget && typeof focusTarget.focus === 'function') {
+        focusTarget.focus();
+    }
+});
+
+var trackerCardContainerIDs = ['cards-container-list', 'cards-container-grid', 'cards-container-wall'];
+
+var trackerCardsIn = function (container) {
+    return Array.prototype.filter.call(container.children, function (node) {
+        return node.tagName === 'ARTICLE';
+    });
+};
+
+// The server numbers a page's cards; a card swapped in on its own comes
+// without a place, so the container is renumbered and keeps a single tab
+// stop, on the card that had it when there is one.
+var syncTrackerCardPositions = function () {
+    trackerCardContainerIDs.forEach(function (id) {
+        var container = document.getElementById(id);
+        if (!container) {
+            return;
+        }
+        var cards = trackerCardsIn(container);
+        var active = cards.filter(function (card) {
+            return card.getAttribute('tabindex') === '0';
+        })[0] || cards[0];
+        cards.forEach(function (card, index) {
+            card.setAttribute('aria-posinset', String(index + 1));
+            card.setAttribute('aria-setsize', String(cards.length));
+            card.setAttribute('data-card-position', String(index + 1));
+            card.setAttribute('tabindex', card === active ? '0' : '-1');
+        });
+    });
+};
+
+document.body.addEventListener('htmx:oobAfterSwap', syncTrackerCardPositions);
+document.body.addEventListener('htmx:afterSettle', syncTrackerCardPositions);
+
+// Arrow keys, Home and End move focus between the cards themselves; keys
+// pressed on the controls inside a card are left alone.
+document.addEventListener('keydown', function (event) {
+    var card = event && event.target;
+    var container = card && card.parentNode;
+    if (!card || card.tagName !== 'ARTICLE' || !container || trackerCardContainerIDs.indexOf(container.id) < 0) {
+        return;
+    }
+
+    var cards = trackerCardsIn(container);
+    var index = cards.indexOf(card);
+    var next = index;
+    if (event.key === 'ArrowRight' || event.key === 'ArrowDown') {
+        next = index + 1;
+    } else if (event.key === 'ArrowLeft' || event.key === 'ArrowUp') {
+        next = index - 1;
+    } else if (event.key === 'Home') {
+        next = 0;
+    } else if (event.key === 'End') {
+        next = cards.length - 1;
+    } else {
+        return;
+    }
+    event.preventDefault();
+    next = Math.min(Math.max(next, 0), cards.length - 1);
+    if (next === index) {
+        return;
+    }
+    card.setAttribute('tabindex', '-1');
+    cards[next].setAttribute('tabindex', '0');
+    cards[next].focus();
+});
+
 window.dismissModalZone = function () {
     var modalZone = document.getElementById('modal-zone');
     if (!modalZone) {
@@ -640,8 +790,8 @@ window.renderTrackersSkeleton = function (viewMode) {
         return;
     }
 
-    var mode = (viewMode === 'list') ? 'list' : 'grid';
-    var itemCount = 6;
+    var mode = (viewMode === 'list' || viewMode === 'wall') ? viewMode : 'grid';
+    var itemCount = (mode === 'wall') ? 20 : 6;
     var items = [];
 
     for (var i = 0; i < itemCount; i += 1) {
@@ -656,6 +806,10 @@ window.renderTrackersSkeleton = function (viewMode) {
                 '</article>');
             continue;
         }
+        if (mode === 'wall') {
+            items.push('<article class="tracker-tile tracker-tile--skeleton"><div class="skeleton skeleton--cover"></div></article>');
+            continue;
+        }
 
         items.push('' +
             '<article class="tracker-card tracker-card--skeleton">' +
@@ -668,7 +822,7 @@ window.renderTrackersSkeleton = function (viewMode) {
 
     trackersZone.innerHTML = '' +
         '<p class="trackers-loading__label">Loading trackers…</p>' +
-        '<div class="' + (mode === 'list' ? 'cards-list' : 'cards-grid') + '">' + items.join('') + '</div>';
+        '<div class="cards-' + mode + '">' + items.join('') + '</div>';
 };
 
 document.body.addEventListener('htmx:beforeRequest', function (event) {
@@ -687,6 +841,14 @@ document.body.addEventListener('htmx:beforeRequest', function (event) {
     window.renderTrackersSkeleton(mode);
 });
 
+// A card action moved its card within the sorted list: refresh in place,
+// without the loading skeleton, and keep the reader where they were.
+document.body.addEventListener('trackerMoved', function () {
+    window.__restoreTrackersScrollY = window.scrollY;
+    window.__silentCoverRefresh = true;
+    window.dispatchTrackersChanged('system');
+});
+
 document.body.addEventListener('htmx:afterSwap', function (event) {
     var target = event && event.target;
     if (!target) {
@@ -711,8 +873,122 @@ document.body.addEventListener('htmx:afterSwap', function (event) {
         }
         window.__scrollTrackersToTop = false;
     }
+    if (typeof window.__restoreTrackersScrollY === 'number') {
+        window.scrollTo(0, window.__restoreTrackersScrollY);
+        window.__restoreTrackersScrollY = null;
+    }
 
     if (typeof window.syncTrackerCardHoverState === 'function') {
         window.syncTrackerCardHoverState();
     }
 });
+
+window.buildTrackerExportURL = function (format, path) {
+    var form = document.getElementById('tracker-filters');
+    var params = new URLSearchParams(form ? new FormData(form) : undefined);
+    params.delete('page');
+    params.delete('view');
+    params.set('format', format);
+    return window.appURL((path || '/dashboard/trackers/export-view') + '?' + params.toString());
+};
+
+window.copyTrackerViewList = function (button) {
+    var originalLabel = button ? button.textContent : '';
+    fetch(window.buildTrackerExportURL('txt'), { credentials: 'same-origin' })
+        .then(function (response) {
+            if (!response.ok) {
+                throw new Error('export failed');
+            }
+            return response.text();
+        })
+        .then(function (text) {
+            return navigator.clipboard.writeText(text);
+        })
+        .then(function () {
+            if (button) {
+                button.textContent = 'Copied';
+                setTimeout(function () { button.textContent = originalLabel; }, 1500);
+            }
+        })
+        .catch(function () {
+            window.location.href = window.buildTrackerExportURL('txt');
+        });
+};
+
+window.downloadTrackerViewCSV = function () {
+    window.location.href = window.buildTrackerExportURL('csv');
+};
+
+// copyTrackerRecommendations copies the tag-filtered view as markdown to
+// paste into a chat. Without a tag filter the server refuses, and the button
+// says so instead of copying.
+window.copyTrackerRecommendations = function (button) {
+    var originalLabel = button ? button.textContent : '';
+    var showLabel = function (label) {
+        if (button) {
+            button.textContent = label;
+            setTimeout(function () { button.textContent = originalLabel; }, 1500);
+        }
+    };
+    fetch(window.buildTrackerExportURL('md', '/dashboard/trackers/export-recommendations'), { credentials: 'same-origin' })
+        .then(function (response) {
+            if (response.status === 400) {
+                showLabel('Pick a tag');
+                return null;
+            }
+            if (!response.ok) {
+                throw new Error('export failed');
+            }
+            return response.text();
+        })
+        .then(function (text) {
+            if (text === null) {
+                return;
+            }
+            return navigator.clipboard.writeText(text).then(function () { showLabel('Copied'); });
+        })
+        .catch(function () {
+            showLabel('Copy failed');
+        });
+};
+
+var initializeChapterBrowser = function () {
+    var list = document.getElementById('tracker-chapters-list');
+    var pager = document.getElementById('tracker-chapters-pager');
+    if (!list || !pager || list.dataset.pagerReady === '1') {
+        return;
+    }
+    list.dataset.pagerReady = '1';
+
+    var rows = Array.prototype.slice.call(list.querySelectorAll('.tracker-chapters-row'));
+    var pageSize = parseInt(list.dataset.pageSize, 10) || 25;
+    var pageCount = Math.max(1, Math.ceil(rows.length / pageSize));
+    var status = document.getElementById('tracker-chapters-pager-status');
+    var page = 0;
+
+    var render = function () {
+        rows.forEach(function (row, index) {
+            row.hidden = index < page * pageSize || index >= (page + 1) * pageSize;
+        });
+        if (status) {
+            status.textContent = 'Page ' + (page + 1) + ' of ' + pageCount;
+        }
+        pager.querySelector('[data-chapters-page="prev"]').disabled = page === 0;
+        pager.querySelector('[data-chapters-page="next"]').disabled = page >= pageCount - 1;
+    };
+
+    pager.addEventListener('click', function (event) {
+        var button = event.target.closest('[data-chapters-page]');
+        if (!button) {
+            return;
+        }
+        page += button.dataset.chaptersPage === 'next' ? 1 : -1;
+        page = Math.min(Math.max(page, 0), pageCount - 1);
+        render();
+    });
+
+    pager.hidden = pageCount <= 1;
+    render();
+};
+
+document.body.addEventListener('htmx:afterSwap', initializeChapterBrowser);
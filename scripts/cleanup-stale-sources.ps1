@@ -1,6 +1,8 @@
 [CmdletBinding()]
 param(
-    [switch]$Apply
+    [switch]$Apply,
+    [string]$Report,
+    [long]$TrackerId = 0
 )
 
 $ErrorActionPreference = "Stop"
@@ -21,6 +23,12 @@ $goArgs = @("run", "./cmd/cleanup-stale-sources")
 if ($Apply) {
     $goArgs += "--apply"
 }
+if ($Report) {
+    $goArgs += @("--report", $Report)
+}
+if ($TrackerId -gt 0) {
+    $goArgs += @("--tracker-id", "$TrackerId")
+}
 
 Push-Location $backendDir
 try {